@@ -0,0 +1,175 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/btcsuite/btcd/connmgr"
+)
+
+// addedNodesFilename is the name of the file in the data directory the nodes
+// added via the RPC server are saved to so they survive restarts.
+const addedNodesFilename = "addednodes.json"
+
+var (
+	// errNodeAlreadyAdded indicates a node was added which has already
+	// been added.
+	errNodeAlreadyAdded = errors.New("node already added")
+
+	// errNodeNotAdded indicates a node was removed which has not been
+	// added.
+	errNodeNotAdded = errors.New("node has not been added")
+)
+
+// addedNode describes a manually added node which is kept connected to.
+type addedNode struct {
+	// addr is the address the node was added with.  It is also used as its
+	// key.
+	addr string
+
+	// connReq is the permanent connection request for the node.  It is nil
+	// when the address could not be resolved.
+	connReq *connmgr.ConnReq
+
+	// config is set for the nodes added via the addpeer and connect
+	// options.  They are added again on every start, so they are not
+	// saved.
+	config bool
+}
+
+// addedNodeInfo describes an added node along with the peer connected to it,
+// which is nil when it is not connected.
+type addedNodeInfo struct {
+	addr string
+	peer rpcserverPeer
+}
+
+// serializedAddedNode is the form an added node is saved to the added nodes
+// file in.
+type serializedAddedNode struct {
+	Address string `json:"address"`
+}
+
+// addedNodeList houses the manually added nodes in the order they were added
+// along with the path of the file they are saved to.  It is not safe for
+// concurrent access and is owned by the peerHandler goroutine once the server
+// is started.
+type addedNodeList struct {
+	path  string
+	nodes []*addedNode
+}
+
+// newAddedNodeList returns an added node list which is saved to the file at the
+// passed path and loads the nodes saved to it.  The loaded nodes don't have a
+// connection request yet.  A missing file is not an error.  When the file can't
+// be read, the returned list is empty along with the error and replaces the
+// file once it is saved.  Passing an empty path disables saving.
+func newAddedNodeList(path string) (*addedNodeList, error) {
+	l := &addedNodeList{path: path}
+	if path == "" {
+		return l, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return l, err
+	}
+
+	var serialized []serializedAddedNode
+	if err := json.Unmarshal(data, &serialized); err != nil {
+		return l, err
+	}
+	for _, s := range serialized {
+		if l.find(s.Address) == nil {
+			l.nodes = append(l.nodes, &addedNode{addr: s.Address})
+		}
+	}
+	return l, nil
+}
+
+// save writes the nodes which were not added via the options to the added
+// nodes file.
+func (l *addedNodeList) save() error {
+	if l.path == "" {
+		return nil
+	}
+
+	serialized := make([]serializedAddedNode, 0, len(l.nodes))
+	for _, node := range l.nodes {
+		if node.config {
+			continue
+		}
+		serialized = append(serialized, serializedAddedNode{
+			Address: node.addr,
+		})
+	}
+	data, err := json.Marshal(serialized)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash can't leave a truncated
+	// file behind.
+	tmpPath := l.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, l.path)
+}
+
+// add adds the passed node.  An error is returned if a node with the same
+// address has already been added.
+func (l *addedNodeList) add(node *addedNode) error {
+	if l.find(node.addr) != nil {
+		return errNodeAlreadyAdded
+	}
+	l.nodes = append(l.nodes, node)
+	return nil
+}
+
+// remove removes the node with the passed address and returns it.  An error is
+// returned if no node with the address has been added.
+func (l *addedNodeList) remove(addr string) (*addedNode, error) {
+	for i, node := range l.nodes {
+		if node.addr == addr {
+			l.nodes = append(l.nodes[:i], l.nodes[i+1:]...)
+			return node, nil
+		}
+	}
+	return nil, errNodeNotAdded
+}
+
+// find returns the node with the passed address or nil if it has not been
+// added.
+func (l *addedNodeList) find(addr string) *addedNode {
+	for _, node := range l.nodes {
+		if node.addr == addr {
+			return node
+		}
+	}
+	return nil
+}
+
+// findConnReq returns the node with the passed connection request or nil if
+// there is none.
+func (l *addedNodeList) findConnReq(c *connmgr.ConnReq) *addedNode {
+	for _, node := range l.nodes {
+		if node.connReq != nil && node.connReq == c {
+			return node
+		}
+	}
+	return nil
+}
+
+// list returns the added nodes in the order they were added.
+func (l *addedNodeList) list() []*addedNode {
+	return append([]*addedNode(nil), l.nodes...)
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/connmgr"
+)
+
+// TestAddedNodeList ensures nodes are added and removed once, and that the
+// nodes which were not added via the options survive being saved to and loaded
+// from the added nodes file in the order they were added.
+func TestAddedNodeList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), addedNodesFilename)
+	l, err := newAddedNodeList(path)
+	if err != nil {
+		t.Fatalf("newAddedNodeList without file: unexpected error: %v",
+			err)
+	}
+
+	connReq := &connmgr.ConnReq{Permanent: true}
+	for _, node := range []*addedNode{
+		{addr: "192.168.1.1:9246", connReq: connReq},
+		{addr: "10.0.0.1:9246", config: true},
+		{addr: "node.example.com:9246"},
+	} {
+		if err := l.add(node); err != nil {
+			t.Fatalf("add(%q): unexpected error: %v", node.addr, err)
+		}
+	}
+	err = l.add(&addedNode{addr: "192.168.1.1:9246"})
+	if !errors.Is(err, errNodeAlreadyAdded) {
+		t.Fatalf("add of node which was already added: got %v, want %v",
+			err, errNodeAlreadyAdded)
+	}
+	if node := l.findConnReq(connReq); node == nil ||
+		node.addr != "192.168.1.1:9246" {
+
+		t.Fatalf("findConnReq: unexpected node %v", node)
+	}
+	if node := l.findConnReq(&connmgr.ConnReq{}); node != nil {
+		t.Fatalf("findConnReq: unexpected node %v", node)
+	}
+
+	// Nodes added via the options are not saved.
+	if err := l.save(); err != nil {
+		t.Fatalf("save: unexpected error: %v", err)
+	}
+	loaded, err := newAddedNodeList(path)
+	if err != nil {
+		t.Fatalf("newAddedNodeList: unexpected error: %v", err)
+	}
+	nodes := loaded.list()
+	if len(nodes) != 2 || nodes[0].addr != "192.168.1.1:9246" ||
+		nodes[1].addr != "node.example.com:9246" ||
+		nodes[0].connReq != nil {
+
+		t.Fatalf("newAddedNodeList: unexpected nodes %v", nodes)
+	}
+
+	// Removing a node which has not been added is an error.
+	if _, err := loaded.remove("192.168.1.1:9246"); err != nil {
+		t.Fatalf("remove: unexpected error: %v", err)
+	}
+	_, err = loaded.remove("192.168.1.1:9246")
+	if !errors.Is(err, errNodeNotAdded) {
+		t.Fatalf("remove of node which was not added: got %v, want %v",
+			err, errNodeNotAdded)
+	}
+	if loaded.find("node.example.com:9246") == nil {
+		t.Fatal("find: remaining node not found")
+	}
+
+	// A corrupt file is reported but results in a usable empty list which
+	// replaces it.
+	if err := os.WriteFile(path, []byte("{"), 0600); err != nil {
+		t.Fatalf("unable to write added nodes file: %v", err)
+	}
+	l, err = newAddedNodeList(path)
+	if err == nil {
+		t.Fatal("newAddedNodeList with corrupt file: expected error")
+	}
+	if len(l.list()) != 0 {
+		t.Fatal("newAddedNodeList with corrupt file: expected no nodes")
+	}
+	if err := l.save(); err != nil {
+		t.Fatalf("save: unexpected error: %v", err)
+	}
+	if _, err := newAddedNodeList(path); err != nil {
+		t.Fatalf("newAddedNodeList after replacing corrupt file: %v", err)
+	}
+}
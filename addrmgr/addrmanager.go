@@ -47,6 +47,7 @@ type AddrManager struct {
 	lamtx          sync.Mutex
 	localAddresses map[string]*localAddress
 	version        int
+	asmap          *ASMap
 }
 
 type serializedKnownAddress struct {
@@ -67,6 +68,7 @@ type serializedAddrManager struct {
 	Addresses    []*serializedKnownAddress
 	NewBuckets   [newBucketCount][]string // string is NetAddressKey
 	TriedBuckets [triedBucketCount][]string
+	ASMapHash    string `json:",omitempty"`
 }
 
 type localAddress struct {
@@ -85,7 +87,8 @@ const (
 	// BoundPrio signifies the address has been explicitly bounded to.
 	BoundPrio
 
-	// UpnpPrio signifies the address was obtained from UPnP.
+	// UpnpPrio signifies the address was obtained from UPnP, NAT-PMP or
+	// PCP.
 	UpnpPrio
 
 	// HTTPPrio signifies the address was obtained from an external HTTP service.
@@ -302,8 +305,8 @@ func (a *AddrManager) getNewBucket(netAddr, srcAddr *wire.NetAddressV2) int {
 
 	data1 := []byte{}
 	data1 = append(data1, a.key[:]...)
-	data1 = append(data1, []byte(GroupKey(netAddr))...)
-	data1 = append(data1, []byte(GroupKey(srcAddr))...)
+	data1 = append(data1, []byte(a.GroupKey(netAddr))...)
+	data1 = append(data1, []byte(a.GroupKey(srcAddr))...)
 	hash1 := chainhash.DoubleHashB(data1)
 	hash64 := binary.LittleEndian.Uint64(hash1)
 	hash64 %= newBucketsPerGroup
@@ -311,7 +314,7 @@ func (a *AddrManager) getNewBucket(netAddr, srcAddr *wire.NetAddressV2) int {
 	binary.LittleEndian.PutUint64(hashbuf[:], hash64)
 	data2 := []byte{}
 	data2 = append(data2, a.key[:]...)
-	data2 = append(data2, GroupKey(srcAddr)...)
+	data2 = append(data2, a.GroupKey(srcAddr)...)
 	data2 = append(data2, hashbuf[:]...)
 
 	hash2 := chainhash.DoubleHashB(data2)
@@ -331,7 +334,7 @@ func (a *AddrManager) getTriedBucket(netAddr *wire.NetAddressV2) int {
 	binary.LittleEndian.PutUint64(hashbuf[:], hash64)
 	data2 := []byte{}
 	data2 = append(data2, a.key[:]...)
-	data2 = append(data2, GroupKey(netAddr)...)
+	data2 = append(data2, a.GroupKey(netAddr)...)
 	data2 = append(data2, hashbuf[:]...)
 
 	hash2 := chainhash.DoubleHashB(data2)
@@ -369,6 +372,7 @@ func (a *AddrManager) savePeers() {
 	sam := new(serializedAddrManager)
 	sam.Version = a.version
 	copy(sam.Key[:], a.key[:])
+	sam.ASMapHash = a.asmapHash()
 
 	sam.Addresses = make([]*serializedKnownAddress, len(a.addrIndex))
 	i := 0
@@ -407,32 +411,56 @@ func (a *AddrManager) savePeers() {
 		}
 	}
 
-	w, err := os.Create(a.peersFile)
+	if err := writePeersFile(a.peersFile, sam); err != nil {
+		log.Errorf("Failed to write file %s: %v", a.peersFile, err)
+	}
+}
+
+// writePeersFile atomically replaces the file at the passed path with the
+// JSON encoding of the passed serialized address manager.  The encoding is
+// written to a temporary file which is synced to disk before it is renamed
+// over the old file, so a crash while saving never leaves a truncated file
+// behind.
+func writePeersFile(filePath string, sam *serializedAddrManager) error {
+	tmpPath := filePath + ".tmp"
+	w, err := os.Create(tmpPath)
 	if err != nil {
-		log.Errorf("Error opening file %s: %v", a.peersFile, err)
-		return
+		return err
 	}
-	enc := json.NewEncoder(w)
-	defer w.Close()
-	if err := enc.Encode(&sam); err != nil {
-		log.Errorf("Failed to encode file %s: %v", a.peersFile, err)
-		return
+	err = json.NewEncoder(w).Encode(sam)
+	if err == nil {
+		err = w.Sync()
+	}
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
 	}
+	if err == nil {
+		err = os.Rename(tmpPath, filePath)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
 }
 
-// loadPeers loads the known address from the saved file.  If empty, missing, or
-// malformed file, just don't load anything and start fresh
+// loadPeers loads the known address from the saved file.  Nothing is loaded
+// when the file is missing.  When it is malformed or uses an unknown version,
+// it is moved aside so it can be inspected and the address manager starts
+// fresh, rebuilding its addresses from the network.
 func (a *AddrManager) loadPeers() {
 	a.mtx.Lock()
 	defer a.mtx.Unlock()
 
 	err := a.deserializePeers(a.peersFile)
 	if err != nil {
-		log.Errorf("Failed to parse file %s: %v", a.peersFile, err)
-		// if it is invalid we nuke the old one unconditionally.
-		err = os.Remove(a.peersFile)
+		backupPath := a.peersFile + ".bak"
+		log.Errorf("Failed to parse file %s: %v -- moving it to %s and "+
+			"starting with an empty address manager", a.peersFile,
+			err, backupPath)
+		err = os.Rename(a.peersFile, backupPath)
 		if err != nil {
-			log.Warnf("Failed to remove corrupt peers file %s: %v",
+			log.Warnf("Failed to move invalid peers file %s: %v",
 				a.peersFile, err)
 		}
 		a.reset()
@@ -441,6 +469,30 @@ func (a *AddrManager) loadPeers() {
 	log.Infof("Loaded %d addresses from file '%s'", a.numAddresses(), a.peersFile)
 }
 
+// migratePeers upgrades the passed serialized address manager from the
+// version it was saved with to the current version.  It returns an error for
+// versions which are unknown, including ones newer than the current version.
+func migratePeers(sam *serializedAddrManager) error {
+	if sam.Version < 1 || sam.Version > serialisationVersion {
+		return fmt.Errorf("unknown version %v in serialized "+
+			"addrmanager", sam.Version)
+	}
+
+	if sam.Version == 1 {
+		// The first version of the serialized address manager was not
+		// aware of the service bits associated with the addresses and
+		// their sources, so we'll assign a default of SFNodeNetwork to
+		// them.
+		for _, v := range sam.Addresses {
+			v.Services = wire.SFNodeNetwork
+			v.SrcServices = wire.SFNodeNetwork
+		}
+		sam.Version = 2
+	}
+
+	return nil
+}
+
 func (a *AddrManager) deserializePeers(filePath string) error {
 
 	_, err := os.Stat(filePath)
@@ -461,36 +513,26 @@ func (a *AddrManager) deserializePeers(filePath string) error {
 	}
 
 	// Since decoding JSON is backwards compatible (i.e., only decodes
-	// fields it understands), we'll only return an error upon seeing a
-	// version past our latest supported version.
-	if sam.Version > serialisationVersion {
-		return fmt.Errorf("unknown version %v in serialized "+
-			"addrmanager", sam.Version)
+	// fields it understands), older versions only need the fields they
+	// lack to be filled in.
+	version := sam.Version
+	if err := migratePeers(&sam); err != nil {
+		return err
+	}
+	if version != sam.Version {
+		log.Infof("Upgraded %s from version %d to %d", filePath,
+			version, sam.Version)
 	}
 
 	copy(a.key[:], sam.Key[:])
 
 	for _, v := range sam.Addresses {
 		ka := new(KnownAddress)
-
-		// The first version of the serialized address manager was not
-		// aware of the service bits associated with this address, so
-		// we'll assign a default of SFNodeNetwork to it.
-		if sam.Version == 1 {
-			v.Services = wire.SFNodeNetwork
-		}
 		ka.na, err = a.DeserializeNetAddress(v.Addr, v.Services)
 		if err != nil {
 			return fmt.Errorf("failed to deserialize netaddress "+
 				"%s: %v", v.Addr, err)
 		}
-
-		// The first version of the serialized address manager was not
-		// aware of the service bits associated with the source address,
-		// so we'll assign a default of SFNodeNetwork to it.
-		if sam.Version == 1 {
-			v.SrcServices = wire.SFNodeNetwork
-		}
 		ka.srcAddr, err = a.DeserializeNetAddress(v.Src, v.SrcServices)
 		if err != nil {
 			return fmt.Errorf("failed to deserialize netaddress "+
@@ -503,6 +545,12 @@ func (a *AddrManager) deserializePeers(filePath string) error {
 		a.addrIndex[NetAddressKey(ka.na)] = ka
 	}
 
+	// The buckets depend on the asmap, so the addresses are placed into
+	// their buckets again when the asmap changed since they were saved.
+	if sam.ASMapHash != a.asmapHash() {
+		return a.rebucketPeers(&sam)
+	}
+
 	for i := range sam.NewBuckets {
 		for _, val := range sam.NewBuckets[i] {
 			ka, ok := a.addrIndex[val]
@@ -548,6 +596,44 @@ func (a *AddrManager) deserializePeers(filePath string) error {
 	return nil
 }
 
+// rebucketPeers places the deserialized addresses into the buckets the current
+// asmap assigns them to.  Tried addresses stay tried unless their new tried
+// bucket is full, in which case they are moved to the new buckets.  Addresses
+// whose new bucket is full are dropped.
+func (a *AddrManager) rebucketPeers(sam *serializedAddrManager) error {
+	tried := make(map[string]struct{})
+	for i := range sam.TriedBuckets {
+		for _, val := range sam.TriedBuckets[i] {
+			tried[val] = struct{}{}
+		}
+	}
+
+	for k, ka := range a.addrIndex {
+		if _, ok := tried[k]; ok {
+			bucket := a.getTriedBucket(ka.na)
+			if a.addrTried[bucket].Len() < triedBucketSize {
+				ka.tried = true
+				a.nTried++
+				a.addrTried[bucket].PushBack(ka)
+				continue
+			}
+		}
+
+		bucket := a.getNewBucket(ka.na, ka.srcAddr)
+		if len(a.addrNew[bucket]) >= newBucketSize {
+			delete(a.addrIndex, k)
+			continue
+		}
+		ka.refs = 1
+		a.nNew++
+		a.addrNew[bucket][k] = ka
+	}
+
+	log.Infof("Rebucketed %d addresses for the changed asmap",
+		len(a.addrIndex))
+	return nil
+}
+
 // DeserializeNetAddress converts a given address string to a *wire.NetAddress.
 func (a *AddrManager) DeserializeNetAddress(addr string,
 	services wire.ServiceFlag) (*wire.NetAddressV2, error) {
@@ -703,6 +789,23 @@ func (a *AddrManager) getAddresses() []*wire.NetAddressV2 {
 	return addrs
 }
 
+// GoodAddresses returns the addresses in the tried table which are not
+// considered bad.  These are the addresses the address manager connected to
+// successfully and which can be expected to be reachable.
+func (a *AddrManager) GoodAddresses() []*wire.NetAddressV2 {
+	a.mtx.RLock()
+	defer a.mtx.RUnlock()
+
+	addrs := make([]*wire.NetAddressV2, 0, a.nTried)
+	for _, ka := range a.addrIndex {
+		if ka.tried && !ka.isBad() {
+			addrs = append(addrs, ka.na)
+		}
+	}
+
+	return addrs
+}
+
 // reset resets the address manager by reinitialising the random source
 // and allocating fresh empty bucket storage.
 func (a *AddrManager) reset() {
@@ -827,34 +930,55 @@ func (a *AddrManager) GetAddress() *KnownAddress {
 			}
 			factor *= 1.2
 		}
-	} else {
-		// new node.
-		// XXX use a closure/function to avoid repeating this.
-		large := 1 << 30
-		factor := 1.0
-		for {
-			// Pick a random bucket.
-			bucket := a.rand.Intn(len(a.addrNew))
-			if len(a.addrNew[bucket]) == 0 {
-				continue
-			}
-			// Then, a random entry in it.
-			var ka *KnownAddress
-			nth := a.rand.Intn(len(a.addrNew[bucket]))
-			for _, value := range a.addrNew[bucket] {
-				if nth == 0 {
-					ka = value
-				}
-				nth--
-			}
-			randval := a.rand.Intn(large)
-			if float64(randval) < (factor * ka.chance() * float64(large)) {
-				log.Tracef("Selected %v from new bucket",
-					NetAddressKey(ka.na))
-				return ka
+	}
+
+	// New node.
+	return a.pickNewAddress()
+}
+
+// GetNewAddress returns a single address from the new table, which houses the
+// addresses that have never been connected to successfully, or nil when it is
+// empty.  It is used to pick the addresses feeler connections test so that
+// the ones which are reachable are moved to the tried table.
+func (a *AddrManager) GetNewAddress() *KnownAddress {
+	// Protect concurrent access.
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	if a.nNew == 0 {
+		return nil
+	}
+	return a.pickNewAddress()
+}
+
+// pickNewAddress picks a random address from the new table with preference
+// given to ones that have not been used recently.  The new table must not be
+// empty.  The address manager lock must be held.
+func (a *AddrManager) pickNewAddress() *KnownAddress {
+	large := 1 << 30
+	factor := 1.0
+	for {
+		// Pick a random bucket.
+		bucket := a.rand.Intn(len(a.addrNew))
+		if len(a.addrNew[bucket]) == 0 {
+			continue
+		}
+		// Then, a random entry in it.
+		var ka *KnownAddress
+		nth := a.rand.Intn(len(a.addrNew[bucket]))
+		for _, value := range a.addrNew[bucket] {
+			if nth == 0 {
+				ka = value
 			}
-			factor *= 1.2
+			nth--
+		}
+		randval := a.rand.Intn(large)
+		if float64(randval) < (factor * ka.chance() * float64(large)) {
+			log.Tracef("Selected %v from new bucket",
+				NetAddressKey(ka.na))
+			return ka
 		}
+		factor *= 1.2
 	}
 }
 
@@ -1046,6 +1170,16 @@ func (a *AddrManager) AddLocalAddress(na *wire.NetAddressV2, priority AddressPri
 	return nil
 }
 
+// RemoveLocalAddress removes the passed address from the local addresses
+// which are advertised to peers.  It is used when an address obtained from a
+// gateway, such as via UPnP, is no longer valid.
+func (a *AddrManager) RemoveLocalAddress(na *wire.NetAddressV2) {
+	a.lamtx.Lock()
+	defer a.lamtx.Unlock()
+
+	delete(a.localAddresses, NetAddressKey(na))
+}
+
 // getReachabilityFrom returns the relative reachability of the provided local
 // address to the provided remote address.
 func getReachabilityFrom(localAddr, remoteAddr *wire.NetAddressV2) int {
@@ -1202,6 +1336,41 @@ func (a *AddrManager) GetBestLocalAddress(remoteAddr *wire.NetAddressV2) *wire.N
 	return bestAddress
 }
 
+// SetASMap sets the asmap used to group addresses by the autonomous system
+// they belong to instead of their network prefix.  It must be called before
+// the address manager is started.
+func (a *AddrManager) SetASMap(asmap *ASMap) {
+	a.asmap = asmap
+}
+
+// asmapHash returns the hash of the asmap in use as a hex string or an empty
+// string when none is used.
+func (a *AddrManager) asmapHash() string {
+	if a.asmap == nil {
+		return ""
+	}
+	return a.asmap.Hash().String()
+}
+
+// GroupKey returns a string representing the network group an address is part
+// of.  It is the same as the package level GroupKey function unless an asmap
+// is set, in which case IP addresses with a known autonomous system are
+// grouped by it.
+func (a *AddrManager) GroupKey(na *wire.NetAddressV2) string {
+	if a.asmap == nil || na.IsTorV3() {
+		return GroupKey(na)
+	}
+
+	lna := na.ToLegacy()
+	if IsOnionCatTor(lna) || !IsRoutable(na) {
+		return GroupKey(na)
+	}
+	if asn := a.asmap.Lookup(lna.IP); asn != 0 {
+		return fmt.Sprintf("as%d", asn)
+	}
+	return GroupKey(na)
+}
+
 // New returns a new bitcoin address manager.
 // Use Start to begin processing asynchronous address updates.
 func New(dataDir string, lookupFunc func(string) ([]net.IP, error)) *AddrManager {
@@ -1,8 +1,11 @@
 package addrmgr
 
 import (
+	"bytes"
+	"encoding/json"
 	"math/rand"
 	"net"
+	"os"
 	"testing"
 	"time"
 
@@ -205,3 +208,131 @@ func TestAddrManagerV1ToV2(t *testing.T) {
 	addrMgr.loadPeers()
 	assertAddrs(t, addrMgr, expectedAddrs)
 }
+
+// TestAddrManagerASMapChange ensures the addresses are kept in the buckets the
+// asmap assigns them to when the asmap changes between restarts.
+func TestAddrManagerASMapChange(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	addrMgr := New(tempDir, nil)
+
+	const numAddrs = 5
+	expectedAddrs := make(map[string]*wire.NetAddressV2, numAddrs)
+	for i := 0; i < numAddrs; i++ {
+		addr := routableRandAddr(t)
+		expectedAddrs[NetAddressKey(addr)] = addr
+		addrMgr.AddAddress(addr, routableRandAddr(t))
+	}
+	addrMgr.savePeers()
+
+	// Restart with an asmap which assigns all addresses to the same AS.
+	asmap, err := DecodeASMap(new(asmapWriter).ret(1000).bytes())
+	if err != nil {
+		t.Fatalf("DecodeASMap: unexpected error: %v", err)
+	}
+	addrMgr = New(tempDir, nil)
+	addrMgr.SetASMap(asmap)
+	addrMgr.loadPeers()
+	assertAddrs(t, addrMgr, expectedAddrs)
+
+	for k, ka := range addrMgr.addrIndex {
+		bucket := addrMgr.getNewBucket(ka.na, ka.srcAddr)
+		if _, ok := addrMgr.addrNew[bucket][k]; !ok {
+			t.Fatalf("address %s not in new bucket %d", k, bucket)
+		}
+	}
+
+	// The asmap hash must be persisted so the addresses are not placed
+	// into their buckets again on the next restart.
+	addrMgr.savePeers()
+	addrMgr = New(tempDir, nil)
+	addrMgr.SetASMap(asmap)
+	addrMgr.loadPeers()
+	assertAddrs(t, addrMgr, expectedAddrs)
+}
+
+// TestAddrManagerRecovery ensures that a truncated peers file or one with an
+// unknown version is moved aside instead of failing, and that the address
+// manager starts fresh and is able to save its addresses again.
+func TestAddrManagerRecovery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		contents func(valid []byte) []byte
+	}{{
+		name: "truncated",
+		contents: func(valid []byte) []byte {
+			return valid[:len(valid)/2]
+		},
+	}, {
+		name: "future version",
+		contents: func(valid []byte) []byte {
+			return bytes.Replace(valid, []byte(`"Version":2`),
+				[]byte(`"Version":99`), 1)
+		},
+	}, {
+		name: "unknown address",
+		contents: func(valid []byte) []byte {
+			var sam serializedAddrManager
+			if err := json.Unmarshal(valid, &sam); err != nil {
+				t.Fatalf("unable to decode peers file: %v", err)
+			}
+			sam.NewBuckets[0] = append(sam.NewBuckets[0],
+				"1.2.3.4:8333")
+			invalid, err := json.Marshal(&sam)
+			if err != nil {
+				t.Fatalf("unable to encode peers file: %v", err)
+			}
+			return invalid
+		},
+	}}
+	for _, test := range tests {
+		tempDir := t.TempDir()
+		addrMgr := New(tempDir, nil)
+		addrMgr.AddAddress(routableRandAddr(t), routableRandAddr(t))
+		addrMgr.savePeers()
+
+		valid, err := os.ReadFile(addrMgr.peersFile)
+		if err != nil {
+			t.Fatalf("%s: unable to read peers file: %v", test.name,
+				err)
+		}
+		invalid := test.contents(valid)
+		if bytes.Equal(invalid, valid) {
+			t.Fatalf("%s: peers file was not modified", test.name)
+		}
+		err = os.WriteFile(addrMgr.peersFile, invalid, 0600)
+		if err != nil {
+			t.Fatalf("%s: unable to write peers file: %v",
+				test.name, err)
+		}
+
+		addrMgr = New(tempDir, nil)
+		addrMgr.loadPeers()
+		if n := addrMgr.NumAddresses(); n != 0 {
+			t.Fatalf("%s: got %d addresses, want 0", test.name, n)
+		}
+		backup, err := os.ReadFile(addrMgr.peersFile + ".bak")
+		if err != nil || !bytes.Equal(backup, invalid) {
+			t.Fatalf("%s: invalid peers file was not kept: %v",
+				test.name, err)
+		}
+
+		// The rebuilt addresses are saved and loaded again without
+		// leaving the temporary file behind.
+		addr := routableRandAddr(t)
+		addrMgr.AddAddress(addr, routableRandAddr(t))
+		addrMgr.savePeers()
+		if _, err := os.Stat(addrMgr.peersFile + ".tmp"); err == nil {
+			t.Fatalf("%s: temporary peers file was not removed",
+				test.name)
+		}
+		addrMgr = New(tempDir, nil)
+		addrMgr.loadPeers()
+		assertAddrs(t, addrMgr, map[string]*wire.NetAddressV2{
+			NetAddressKey(addr): addr,
+		})
+	}
+}
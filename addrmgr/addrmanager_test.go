@@ -369,6 +369,79 @@ func TestGetAddress(t *testing.T) {
 	}
 }
 
+func TestGetNewAddress(t *testing.T) {
+	n := addrmgr.New("testgetnewaddress", lookupFunc)
+
+	// Get an address from an empty new table.
+	if rv := n.GetNewAddress(); rv != nil {
+		t.Errorf("GetNewAddress failed: got: %v want: %v\n", rv, nil)
+	}
+
+	err := n.AddAddressByIP(someIP + ":9244")
+	if err != nil {
+		t.Fatalf("Adding address failed: %v", err)
+	}
+	ka := n.GetNewAddress()
+	if ka == nil {
+		t.Fatalf("Did not get an address where there is one in the new table")
+	}
+	if ka.NetAddress().Addr.String() != someIP {
+		t.Errorf("Wrong IP: got %v, want %v", ka.NetAddress().Addr.String(), someIP)
+	}
+
+	// Once the address is moved to the tried table, it is no longer
+	// returned.
+	n.Good(ka.NetAddress())
+	if rv := n.GetNewAddress(); rv != nil {
+		t.Errorf("GetNewAddress failed: got: %v want: %v\n", rv, nil)
+	}
+}
+
+func TestGoodAddresses(t *testing.T) {
+	n := addrmgr.New("testgoodaddresses", lookupFunc)
+
+	err := n.AddAddressByIP(someIP + ":9244")
+	if err != nil {
+		t.Fatalf("Adding address failed: %v", err)
+	}
+	if addrs := n.GoodAddresses(); len(addrs) != 0 {
+		t.Errorf("GoodAddresses: got %v, want none", addrs)
+	}
+
+	// Once the address is moved to the tried table, it is returned.
+	ka := n.GetNewAddress()
+	n.Good(ka.NetAddress())
+	addrs := n.GoodAddresses()
+	if len(addrs) != 1 || addrs[0].Addr.String() != someIP {
+		t.Errorf("GoodAddresses: got %v, want [%v]", addrs, someIP)
+	}
+}
+
+func TestRemoveLocalAddress(t *testing.T) {
+	amgr := addrmgr.New("testremovelocaladdress", nil)
+	localAddr := wire.NetAddressV2FromBytes(
+		time.Now(), 0, net.ParseIP("204.124.1.1"), 8333,
+	)
+	remoteAddr := wire.NetAddressV2FromBytes(
+		time.Now(), 0, net.ParseIP("204.124.8.100"), 0,
+	)
+	if err := amgr.AddLocalAddress(localAddr, addrmgr.UpnpPrio); err != nil {
+		t.Fatalf("AddLocalAddress: unexpected error: %v", err)
+	}
+	got := amgr.GetBestLocalAddress(remoteAddr)
+	if got.Addr.String() != "204.124.1.1" {
+		t.Fatalf("GetBestLocalAddress: got %v, want 204.124.1.1",
+			got.Addr)
+	}
+
+	// Once removed, the address is no longer suggested.
+	amgr.RemoveLocalAddress(localAddr)
+	got = amgr.GetBestLocalAddress(remoteAddr)
+	if got.Addr.String() != "0.0.0.0" {
+		t.Fatalf("GetBestLocalAddress: got %v, want 0.0.0.0", got.Addr)
+	}
+}
+
 func TestGetBestLocalAddress(t *testing.T) {
 	localAddrs := []wire.NetAddressV2{
 		*wire.NetAddressV2FromBytes(
@@ -0,0 +1,327 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package addrmgr
+
+import (
+	"errors"
+	"math/bits"
+	"net"
+	"os"
+
+	"github.com/btcsuite/btcd/chainhash/v2"
+)
+
+// asmapInvalid is returned by the decoding functions when the encoded value
+// does not fit into the remaining bits of the asmap.
+const asmapInvalid = 0xffffffff
+
+// asmapInstruction is an instruction of the program an asmap is encoded as.
+type asmapInstruction uint32
+
+const (
+	// asmapReturn returns the AS number which follows it.
+	asmapReturn asmapInstruction = 0
+
+	// asmapJump consumes one bit of the IP address and skips the number of
+	// bits of the asmap which follows it when the bit is set.
+	asmapJump asmapInstruction = 1
+
+	// asmapMatch consumes the bits of the IP address which follow it and
+	// returns the default AS number unless they are all equal.
+	asmapMatch asmapInstruction = 2
+
+	// asmapDefault sets the AS number which follows it as the default.
+	asmapDefault asmapInstruction = 3
+)
+
+var (
+	// The bit sizes of the exponent classes of the values encoded in the
+	// asmap.  See asmapDecodeBits.
+	asmapTypeBitSizes  = []uint8{0, 0, 1}
+	asmapASNBitSizes   = []uint8{15, 16, 17, 18, 19, 20, 21, 22, 23, 24}
+	asmapMatchBitSizes = []uint8{1, 2, 3, 4, 5, 6, 7, 8}
+	asmapJumpBitSizes  = []uint8{5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15,
+		16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30}
+)
+
+// ASMap maps IP addresses to the number of the autonomous system (AS) they are
+// announced by.  It uses the compressed binary trie format of the asmap files
+// used by Bitcoin Core so the same files can be used.
+type ASMap struct {
+	bits []bool
+	hash chainhash.Hash
+}
+
+// DecodeASMap decodes the passed serialized asmap and ensures it is
+// well-formed.
+func DecodeASMap(data []byte) (*ASMap, error) {
+	asmapBits := make([]bool, 0, len(data)*8)
+	for _, b := range data {
+		for bit := 0; bit < 8; bit++ {
+			asmapBits = append(asmapBits, (b>>bit)&1 == 1)
+		}
+	}
+	if !asmapSanityCheck(asmapBits, 128) {
+		return nil, errors.New("malformed asmap")
+	}
+
+	return &ASMap{
+		bits: asmapBits,
+		hash: chainhash.HashH(data),
+	}, nil
+}
+
+// LoadASMap reads and decodes the asmap file at the passed path.
+func LoadASMap(path string) (*ASMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeASMap(data)
+}
+
+// Hash returns the hash of the serialized asmap.
+func (m *ASMap) Hash() chainhash.Hash {
+	return m.hash
+}
+
+// Lookup returns the number of the autonomous system the passed IP address
+// belongs to or zero when it is unknown.  IPv4 addresses are looked up as
+// IPv4-mapped IPv6 addresses.
+func (m *ASMap) Lookup(ip net.IP) uint32 {
+	ip = ip.To16()
+	if ip == nil {
+		return 0
+	}
+	ipBits := make([]bool, 0, 128)
+	for _, b := range ip {
+		for bit := 7; bit >= 0; bit-- {
+			ipBits = append(ipBits, (b>>bit)&1 == 1)
+		}
+	}
+	return asmapInterpret(m.bits, ipBits)
+}
+
+// asmapDecodeBits decodes a variable length value from the asmap starting at
+// the passed position and advances the position past it.  The value is
+// encoded as the index of its exponent class in unary followed by the
+// mantissa with the number of bits of that class.  asmapInvalid is returned
+// when the value does not fit into the asmap.
+func asmapDecodeBits(asmap []bool, pos *int, minVal uint32,
+	bitSizes []uint8) uint32 {
+
+	val := minVal
+	for i, size := range bitSizes {
+		var bit bool
+		if i+1 != len(bitSizes) {
+			if *pos == len(asmap) {
+				break
+			}
+			bit = asmap[*pos]
+			*pos++
+		}
+		if bit {
+			val += 1 << size
+			continue
+		}
+
+		for b := 0; b < int(size); b++ {
+			if *pos == len(asmap) {
+				return asmapInvalid
+			}
+			if asmap[*pos] {
+				val += 1 << (int(size) - 1 - b)
+			}
+			*pos++
+		}
+		return val
+	}
+	return asmapInvalid
+}
+
+func asmapDecodeType(asmap []bool, pos *int) asmapInstruction {
+	return asmapInstruction(asmapDecodeBits(asmap, pos, 0,
+		asmapTypeBitSizes))
+}
+
+func asmapDecodeASN(asmap []bool, pos *int) uint32 {
+	return asmapDecodeBits(asmap, pos, 1, asmapASNBitSizes)
+}
+
+func asmapDecodeMatch(asmap []bool, pos *int) uint32 {
+	return asmapDecodeBits(asmap, pos, 2, asmapMatchBitSizes)
+}
+
+func asmapDecodeJump(asmap []bool, pos *int) uint32 {
+	return asmapDecodeBits(asmap, pos, 17, asmapJumpBitSizes)
+}
+
+// asmapInterpret runs the asmap program for the passed IP address bits and
+// returns the resulting AS number.  The asmap must have passed the sanity
+// check.
+func asmapInterpret(asmap []bool, ip []bool) uint32 {
+	pos := 0
+	remaining := len(ip)
+	var defaultASN uint32
+	for pos != len(asmap) {
+		switch asmapDecodeType(asmap, &pos) {
+		case asmapReturn:
+			asn := asmapDecodeASN(asmap, &pos)
+			if asn == asmapInvalid {
+				return 0
+			}
+			return asn
+
+		case asmapJump:
+			jump := asmapDecodeJump(asmap, &pos)
+			if jump == asmapInvalid || remaining == 0 ||
+				int64(jump) >= int64(len(asmap)-pos) {
+
+				return 0
+			}
+			if ip[len(ip)-remaining] {
+				pos += int(jump)
+			}
+			remaining--
+
+		case asmapMatch:
+			match := asmapDecodeMatch(asmap, &pos)
+			if match == asmapInvalid {
+				return 0
+			}
+			matchLen := bits.Len32(match) - 1
+			if remaining < matchLen {
+				return 0
+			}
+			for bit := 0; bit < matchLen; bit++ {
+				want := (match>>(matchLen-1-bit))&1 == 1
+				if ip[len(ip)-remaining] != want {
+					return defaultASN
+				}
+				remaining--
+			}
+
+		case asmapDefault:
+			defaultASN = asmapDecodeASN(asmap, &pos)
+			if defaultASN == asmapInvalid {
+				return 0
+			}
+
+		default:
+			return 0
+		}
+	}
+	return 0
+}
+
+// asmapSanityCheck returns whether the passed asmap is a well-formed program
+// for IP addresses with the given number of bits.  It ensures every possible
+// execution ends with a return instruction without reading past the end of
+// the asmap or the address.
+func asmapSanityCheck(asmap []bool, remaining int) bool {
+	// jumps houses the positions which might be jumped to along with the
+	// number of address bits which are left at that point.
+	type jumpTarget struct {
+		pos       int
+		remaining int
+	}
+	var jumps []jumpTarget
+
+	pos := 0
+	prevOpcode := asmapJump
+	hadIncompleteMatch := false
+	for pos != len(asmap) {
+		if len(jumps) > 0 && pos >= jumps[len(jumps)-1].pos {
+			// A jump into the middle of the previous instruction.
+			return false
+		}
+
+		switch asmapDecodeType(asmap, &pos) {
+		case asmapReturn:
+			// A return right after a default could be a single
+			// return.
+			if prevOpcode == asmapDefault {
+				return false
+			}
+			if asmapDecodeASN(asmap, &pos) == asmapInvalid {
+				return false
+			}
+			if len(jumps) == 0 {
+				// Nothing is left to execute, so only zero
+				// padding up to the next byte may follow.
+				if len(asmap)-pos > 7 {
+					return false
+				}
+				for ; pos != len(asmap); pos++ {
+					if asmap[pos] {
+						return false
+					}
+				}
+				return true
+			}
+
+			// Continue as if the most recent jump was taken.
+			target := jumps[len(jumps)-1]
+			if pos != target.pos {
+				// Unreachable code.
+				return false
+			}
+			remaining = target.remaining
+			jumps = jumps[:len(jumps)-1]
+			prevOpcode = asmapJump
+
+		case asmapJump:
+			jump := asmapDecodeJump(asmap, &pos)
+			if jump == asmapInvalid ||
+				int64(jump) > int64(len(asmap)-pos) || remaining == 0 {
+
+				return false
+			}
+			remaining--
+			target := pos + int(jump)
+			if len(jumps) > 0 && target >= jumps[len(jumps)-1].pos {
+				// Intersecting jumps.
+				return false
+			}
+			jumps = append(jumps, jumpTarget{target, remaining})
+			prevOpcode = asmapJump
+
+		case asmapMatch:
+			match := asmapDecodeMatch(asmap, &pos)
+			if match == asmapInvalid {
+				return false
+			}
+			matchLen := bits.Len32(match) - 1
+			if prevOpcode != asmapMatch {
+				hadIncompleteMatch = false
+			}
+			// At most one match of a sequence may be incomplete.
+			if matchLen < 8 && hadIncompleteMatch {
+				return false
+			}
+			hadIncompleteMatch = matchLen < 8
+			if remaining < matchLen {
+				return false
+			}
+			remaining -= matchLen
+			prevOpcode = asmapMatch
+
+		case asmapDefault:
+			if prevOpcode == asmapDefault {
+				return false
+			}
+			if asmapDecodeASN(asmap, &pos) == asmapInvalid {
+				return false
+			}
+			prevOpcode = asmapDefault
+
+		default:
+			return false
+		}
+	}
+
+	// The end was reached without a return instruction.
+	return false
+}
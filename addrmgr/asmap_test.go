@@ -0,0 +1,164 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package addrmgr
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/wire/v2"
+)
+
+// asmapWriter assembles asmap programs for tests.
+type asmapWriter struct {
+	bits []bool
+}
+
+// value appends the passed number of bits of the value, most significant bit
+// first.
+func (w *asmapWriter) value(v uint32, numBits int) *asmapWriter {
+	for i := numBits - 1; i >= 0; i-- {
+		w.bits = append(w.bits, (v>>i)&1 == 1)
+	}
+	return w
+}
+
+// asn appends an AS number using the first or second exponent class.
+func (w *asmapWriter) asn(asn uint32) *asmapWriter {
+	if asn <= 1<<15 {
+		return w.value(0, 1).value(asn-1, 15)
+	}
+	return w.value(0b10, 2).value(asn-1-1<<15, 16)
+}
+
+func (w *asmapWriter) ret(asn uint32) *asmapWriter {
+	return w.value(0, 1).asn(asn)
+}
+
+func (w *asmapWriter) def(asn uint32) *asmapWriter {
+	return w.value(0b11, 2).value(1, 1).asn(asn)
+}
+
+// jump appends a jump by less than 49 bits.
+func (w *asmapWriter) jump(jump uint32) *asmapWriter {
+	return w.value(0b10, 2).value(0, 1).value(jump-17, 5)
+}
+
+// match appends a match of a single address bit.
+func (w *asmapWriter) match(bit uint32) *asmapWriter {
+	return w.value(0b110, 3).value(0, 1).value(bit, 1)
+}
+
+// bytes returns the serialized program padded with zero bits.
+func (w *asmapWriter) bytes() []byte {
+	data := make([]byte, (len(w.bits)+7)/8)
+	for i, bit := range w.bits {
+		if bit {
+			data[i/8] |= 1 << (i % 8)
+		}
+	}
+	return data
+}
+
+// TestASMapLookup ensures IP addresses are mapped to the AS numbers the asmap
+// program assigns to them.
+func TestASMapLookup(t *testing.T) {
+	tests := []struct {
+		name    string
+		program *asmapWriter
+		lookups map[string]uint32
+	}{{
+		name:    "return",
+		program: new(asmapWriter).ret(1000),
+		lookups: map[string]uint32{
+			"1.2.3.4":     1000,
+			"2001:db8::1": 1000,
+		},
+	}, {
+		// The first address bit selects one of the two returns.
+		// Returning an AS number above 1<<15 takes 19 bits.
+		name:    "jump",
+		program: new(asmapWriter).jump(19).ret(40000).ret(50000),
+		lookups: map[string]uint32{
+			"1.2.3.4":     40000,
+			"2001:db8::1": 40000,
+			"8000::1":     50000,
+		},
+	}, {
+		name:    "match",
+		program: new(asmapWriter).def(100).match(1).ret(200),
+		lookups: map[string]uint32{
+			"1.2.3.4": 100,
+			"8000::1": 200,
+		},
+	}}
+
+	for _, test := range tests {
+		asmap, err := DecodeASMap(test.program.bytes())
+		if err != nil {
+			t.Fatalf("%s: DecodeASMap: unexpected error: %v",
+				test.name, err)
+		}
+		for ip, want := range test.lookups {
+			got := asmap.Lookup(net.ParseIP(ip))
+			if got != want {
+				t.Errorf("%s: Lookup(%s): got %d, want %d",
+					test.name, ip, got, want)
+			}
+		}
+	}
+}
+
+// TestDecodeASMapMalformed ensures malformed asmaps are rejected.
+func TestDecodeASMapMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"truncated asn", new(asmapWriter).ret(1000).bytes()[:1]},
+		{"no return", new(asmapWriter).def(100).bytes()},
+		{"excessive padding", append(new(asmapWriter).ret(1000).bytes(), 0)},
+		{"nonzero padding", new(asmapWriter).ret(1000).value(1, 3).bytes()},
+		{"unreachable code", new(asmapWriter).ret(1000).ret(2000).bytes()},
+		{"jump past end", new(asmapWriter).jump(40).ret(1000).bytes()},
+		{"return after default", new(asmapWriter).def(100).ret(200).bytes()},
+	}
+
+	for _, test := range tests {
+		if _, err := DecodeASMap(test.data); err == nil {
+			t.Errorf("%s: DecodeASMap: expected error", test.name)
+		}
+	}
+}
+
+// TestGroupKeyASMap ensures addresses are grouped by AS number when an asmap
+// is set and fall back to their network prefix otherwise.
+func TestGroupKeyASMap(t *testing.T) {
+	asmap, err := DecodeASMap(new(asmapWriter).jump(19).ret(40000).
+		ret(50000).bytes())
+	if err != nil {
+		t.Fatalf("DecodeASMap: unexpected error: %v", err)
+	}
+
+	a := New(t.TempDir(), nil)
+	now := time.Unix(1700000000, 0)
+	ipv4 := wire.NetAddressV2FromBytes(now, 0,
+		net.ParseIP("12.1.2.3").To4(), 9246)
+	local := wire.NetAddressV2FromBytes(now, 0,
+		net.ParseIP("127.0.0.1").To4(), 9246)
+
+	if got := a.GroupKey(ipv4); got != "12.1.0.0" {
+		t.Fatalf("GroupKey without asmap: got %s, want 12.1.0.0", got)
+	}
+	a.SetASMap(asmap)
+	if got := a.GroupKey(ipv4); got != "as40000" {
+		t.Fatalf("GroupKey with asmap: got %s, want as40000", got)
+	}
+	if got := a.GroupKey(local); got != "local" {
+		t.Fatalf("GroupKey of local address: got %s, want local", got)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/btcsuite/btcd/blockchain"
+)
+
+// alertNotifySafeChars are the characters of a warning which are kept when it
+// is passed to the alertnotify command.  None of them are interpreted by the
+// shell within single quotes.
+const alertNotifySafeChars = "abcdefghijklmnopqrstuvwxyz" +
+	"ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 .,;-_/:?@()"
+
+// alertNotifyCommand returns the passed alertnotify command with each %s
+// replaced by the passed warning.  The warning is stripped of all characters
+// which aren't safe to pass to the shell and quoted.
+func alertNotifyCommand(command, warning string) string {
+	safeWarning := strings.Map(func(r rune) rune {
+		if !strings.ContainsRune(alertNotifySafeChars, r) {
+			return -1
+		}
+		return r
+	}, warning)
+
+	return strings.ReplaceAll(command, "%s", "'"+safeWarning+"'")
+}
+
+// runAlertNotify runs the alertnotify command for the passed warning with the
+// shell of the operating system.
+func runAlertNotify(command, warning string) {
+	cmdLine := alertNotifyCommand(command, warning)
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", cmdLine)
+	} else {
+		cmd = exec.Command("/bin/sh", "-c", cmdLine)
+	}
+	if err := cmd.Run(); err != nil {
+		srvrLog.Warnf("Failed to run the alertnotify command %q: %v",
+			cmdLine, err)
+	}
+}
+
+// alertNotifyHandler returns a handler for the notifications of the block chain
+// which runs the passed alertnotify command whenever a warning about a fork of
+// the main chain is raised.
+func alertNotifyHandler(command string) blockchain.NotificationCallback {
+	return func(n *blockchain.Notification) {
+		if n.Type != blockchain.NTChainWarning {
+			return
+		}
+		warning, ok := n.Data.(string)
+		if !ok || warning == "" {
+			return
+		}
+
+		// Run the command in the background since the notification is
+		// sent while the block is being processed.
+		go runAlertNotify(command, warning)
+	}
+}
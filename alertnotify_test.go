@@ -0,0 +1,64 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestAlertNotifyCommand ensures the warning is quoted and stripped of the
+// characters which aren't safe to pass to the shell.
+func TestAlertNotifyCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		warning string
+		want    string
+	}{
+		{
+			command: "notify %s",
+			warning: "Found a side chain -- at height 10",
+			want:    "notify 'Found a side chain -- at height 10'",
+		},
+		{
+			command: "notify %s %s",
+			warning: "it's $(rm -rf /); `x` \"y\" | z > w & v\n",
+			want:    "notify 'its (rm -rf /); x y  z  w  v' 'its (rm -rf /); x y  z  w  v'",
+		},
+		{
+			command: "notify",
+			warning: "warning",
+			want:    "notify",
+		},
+	}
+
+	for _, test := range tests {
+		got := alertNotifyCommand(test.command, test.warning)
+		if got != test.want {
+			t.Errorf("alertNotifyCommand(%q, %q): got %q, want %q",
+				test.command, test.warning, got, test.want)
+		}
+	}
+}
+
+// TestRunAlertNotify ensures the alertnotify command is run with the warning.
+func TestRunAlertNotify(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test command requires a unix shell")
+	}
+
+	path := filepath.Join(t.TempDir(), "alert")
+	runAlertNotify("echo %s > "+path, "Found a side chain")
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: unexpected error: %v", err)
+	}
+	if strings.TrimSpace(string(got)) != "Found a side chain" {
+		t.Fatalf("unexpected alert: %q", got)
+	}
+}
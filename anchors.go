@@ -0,0 +1,95 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+)
+
+const (
+	// anchorsFilename is the name of the file in the data directory the
+	// anchor addresses are saved to on shutdown.
+	anchorsFilename = "anchors.json"
+
+	// maxAnchors is the maximum number of block-relay-only peers which are
+	// saved as anchors and reconnected to on startup.
+	maxAnchors = 2
+)
+
+// isAnchorCandidate returns whether the passed peer may be saved as an anchor.
+// Only block-relay-only peers chosen by the connection manager which completed
+// the handshake qualify.  Persistent peers are reconnected to anyway and the
+// addresses of block-relay-only peers are harder for an attacker to learn
+// since they don't take part in transaction and address relay.
+func isAnchorCandidate(sp *serverPeer) bool {
+	return sp.blockRelayOnly && !sp.persistent && sp.VerAckReceived()
+}
+
+// selectAnchors returns the addresses of up to maxAnchors of the passed peers
+// which qualify as anchors, preferring the ones which have been connected the
+// longest.
+func selectAnchors(peers map[int32]*serverPeer) []string {
+	var candidates []*serverPeer
+	for _, sp := range peers {
+		if isAnchorCandidate(sp) {
+			candidates = append(candidates, sp)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].TimeConnected().Before(
+			candidates[j].TimeConnected())
+	})
+	if len(candidates) > maxAnchors {
+		candidates = candidates[:maxAnchors]
+	}
+
+	anchors := make([]string, 0, len(candidates))
+	for _, sp := range candidates {
+		anchors = append(anchors, sp.Addr())
+	}
+	return anchors
+}
+
+// saveAnchors writes the passed anchor addresses to the file at the given
+// path.
+func saveAnchors(path string, anchors []string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(anchors); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// loadAnchors reads the anchor addresses from the file at the given path and
+// removes the file afterwards.  Removing it ensures a node which keeps
+// crashing does not reconnect to the same anchors forever.  A missing file is
+// not an error.
+func loadAnchors(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Remove(path); err != nil {
+		return nil, err
+	}
+
+	var anchors []string
+	if err := json.Unmarshal(data, &anchors); err != nil {
+		return nil, err
+	}
+	if len(anchors) > maxAnchors {
+		anchors = anchors[:maxAnchors]
+	}
+	return anchors, nil
+}
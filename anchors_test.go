@@ -0,0 +1,53 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestAnchorsFile ensures anchors are restored from the file they were saved
+// to, that the file is removed once loaded, and that at most maxAnchors
+// addresses are restored.
+func TestAnchorsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), anchorsFilename)
+
+	// A missing file means there are no anchors.
+	anchors, err := loadAnchors(path)
+	if err != nil || len(anchors) != 0 {
+		t.Fatalf("loadAnchors without file: got (%v, %v), want no anchors",
+			anchors, err)
+	}
+
+	saved := []string{"1.2.3.4:9246", "[2001:db8::1]:9246", "5.6.7.8:9246"}
+	if err := saveAnchors(path, saved); err != nil {
+		t.Fatalf("saveAnchors: unexpected error: %v", err)
+	}
+	anchors, err = loadAnchors(path)
+	if err != nil {
+		t.Fatalf("loadAnchors: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(anchors, saved[:maxAnchors]) {
+		t.Fatalf("loadAnchors: got %v, want %v", anchors,
+			saved[:maxAnchors])
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("anchors file not removed after loading: %v", err)
+	}
+
+	// A corrupt file is removed as well.
+	if err := os.WriteFile(path, []byte("{"), 0600); err != nil {
+		t.Fatalf("unable to write anchors file: %v", err)
+	}
+	if _, err := loadAnchors(path); err == nil {
+		t.Fatal("loadAnchors with corrupt file: expected error")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("corrupt anchors file not removed: %v", err)
+	}
+}
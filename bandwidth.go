@@ -0,0 +1,143 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/wire/v2"
+)
+
+const (
+	// uploadTargetTimeframe is the length of the cycles the upload target
+	// applies to.
+	uploadTargetTimeframe = time.Hour * 24
+
+	// historicalBlockAge is the age after which blocks are considered
+	// historical and are no longer served to peers without the noban
+	// permission once the upload target is reached.
+	historicalBlockAge = time.Hour * 24 * 7
+
+	// otherMsgCommand is the command bytes are accounted to when they
+	// do not belong to a known message, such as malformed messages.
+	otherMsgCommand = "*other*"
+)
+
+// uploadTarget describes the state of the upload target in the current cycle.
+type uploadTarget struct {
+	target    uint64
+	reached   bool
+	bytesLeft uint64
+	timeLeft  time.Duration
+}
+
+// bandwidthStats tracks the bytes sent and received per message command along
+// with the bytes sent during the current cycle of the upload target.  It is
+// safe for concurrent access.
+type bandwidthStats struct {
+	mtx            sync.Mutex
+	sentPerMsg     map[string]uint64
+	recvPerMsg     map[string]uint64
+	target         uint64
+	cycleStart     time.Time
+	cycleBytesSent uint64
+}
+
+// newBandwidthStats returns new bandwidth stats which enforce the passed
+// upload target in bytes per cycle.  An upload target of zero disables it.
+func newBandwidthStats(target uint64) *bandwidthStats {
+	return &bandwidthStats{
+		sentPerMsg: make(map[string]uint64),
+		recvPerMsg: make(map[string]uint64),
+		target:     target,
+	}
+}
+
+// msgCommand returns the command the bytes of the passed message are
+// accounted to.
+func msgCommand(msg wire.Message) string {
+	if msg == nil {
+		return otherMsgCommand
+	}
+	return msg.Command()
+}
+
+// rollCycle starts a new cycle of the upload target when the current one has
+// ended at the passed time.
+//
+// This function MUST be called with the mutex held (for writes).
+func (b *bandwidthStats) rollCycle(now time.Time) {
+	if now.Sub(b.cycleStart) >= uploadTargetTimeframe {
+		b.cycleStart = now
+		b.cycleBytesSent = 0
+	}
+}
+
+// AddSent accounts the passed number of bytes sent for the passed message at
+// the passed time.
+func (b *bandwidthStats) AddSent(msg wire.Message, bytesSent uint64,
+	now time.Time) {
+
+	b.mtx.Lock()
+	b.sentPerMsg[msgCommand(msg)] += bytesSent
+	b.rollCycle(now)
+	b.cycleBytesSent += bytesSent
+	b.mtx.Unlock()
+}
+
+// AddReceived accounts the passed number of bytes received for the passed
+// message.
+func (b *bandwidthStats) AddReceived(msg wire.Message, bytesReceived uint64) {
+	b.mtx.Lock()
+	b.recvPerMsg[msgCommand(msg)] += bytesReceived
+	b.mtx.Unlock()
+}
+
+// PerMsg returns copies of the bytes sent and received per message command.
+func (b *bandwidthStats) PerMsg() (map[string]uint64, map[string]uint64) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	sent := make(map[string]uint64, len(b.sentPerMsg))
+	for cmd, bytes := range b.sentPerMsg {
+		sent[cmd] = bytes
+	}
+	recv := make(map[string]uint64, len(b.recvPerMsg))
+	for cmd, bytes := range b.recvPerMsg {
+		recv[cmd] = bytes
+	}
+	return sent, recv
+}
+
+// UploadTarget returns the state of the upload target at the passed time.
+func (b *bandwidthStats) UploadTarget(now time.Time) uploadTarget {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if b.target == 0 {
+		return uploadTarget{}
+	}
+	b.rollCycle(now)
+	state := uploadTarget{
+		target:   b.target,
+		reached:  b.cycleBytesSent >= b.target,
+		timeLeft: b.cycleStart.Add(uploadTargetTimeframe).Sub(now),
+	}
+	if !state.reached {
+		state.bytesLeft = b.target - b.cycleBytesSent
+	}
+	return state
+}
+
+// ServeHistoricalBlock returns whether a block with the passed timestamp may
+// be served at the passed time to a peer without the noban permission.
+// Historical blocks are no longer served once the upload target is reached.
+func (b *bandwidthStats) ServeHistoricalBlock(timestamp, now time.Time) bool {
+	if now.Sub(timestamp) <= historicalBlockAge {
+		return true
+	}
+	return !b.UploadTarget(now).reached
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/wire/v2"
+)
+
+// TestBandwidthStats ensures the bytes are accounted per message command and
+// that historical blocks are only served until the upload target is reached
+// in the current cycle.
+func TestBandwidthStats(t *testing.T) {
+	b := newBandwidthStats(1000)
+	start := time.Unix(1700000000, 0)
+	recent := start.Add(-time.Hour)
+	historical := start.Add(-historicalBlockAge - time.Second)
+
+	b.AddSent(&wire.MsgPing{}, 400, start)
+	b.AddSent(&wire.MsgInv{}, 100, start)
+	b.AddSent(&wire.MsgPing{}, 200, start.Add(time.Minute))
+	b.AddReceived(&wire.MsgPong{}, 50)
+	b.AddReceived(nil, 10)
+
+	sent, recv := b.PerMsg()
+	wantSent := map[string]uint64{wire.CmdPing: 600, wire.CmdInv: 100}
+	wantRecv := map[string]uint64{wire.CmdPong: 50, otherMsgCommand: 10}
+	if !reflect.DeepEqual(sent, wantSent) {
+		t.Fatalf("sent per msg: got %v, want %v", sent, wantSent)
+	}
+	if !reflect.DeepEqual(recv, wantRecv) {
+		t.Fatalf("received per msg: got %v, want %v", recv, wantRecv)
+	}
+
+	now := start.Add(time.Hour)
+	target := b.UploadTarget(now)
+	want := uploadTarget{
+		target:    1000,
+		bytesLeft: 300,
+		timeLeft:  uploadTargetTimeframe - time.Hour,
+	}
+	if target != want {
+		t.Fatalf("upload target: got %+v, want %+v", target, want)
+	}
+	if !b.ServeHistoricalBlock(historical, now) {
+		t.Fatalf("historical block not served below the target")
+	}
+
+	// Historical blocks are no longer served once the target is reached
+	// while recent blocks still are.
+	b.AddSent(&wire.MsgBlock{}, 300, now)
+	if target := b.UploadTarget(now); !target.reached ||
+		target.bytesLeft != 0 {
+
+		t.Fatalf("upload target not reached: %+v", target)
+	}
+	if b.ServeHistoricalBlock(historical, now) {
+		t.Fatalf("historical block served above the target")
+	}
+	if !b.ServeHistoricalBlock(recent, now) {
+		t.Fatalf("recent block not served above the target")
+	}
+
+	// The target applies again once the cycle ended.
+	now = start.Add(uploadTargetTimeframe)
+	if !b.ServeHistoricalBlock(historical, now) {
+		t.Fatalf("historical block not served in a new cycle")
+	}
+	if target := b.UploadTarget(now); target.bytesLeft != 1000 {
+		t.Fatalf("new cycle: got %d bytes left, want 1000",
+			target.bytesLeft)
+	}
+
+	// Blocks are always served when there is no target.
+	b = newBandwidthStats(0)
+	b.AddSent(&wire.MsgBlock{}, 1<<30, now)
+	if !b.ServeHistoricalBlock(historical, now) {
+		t.Fatalf("historical block not served without a target")
+	}
+}
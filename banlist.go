@@ -0,0 +1,235 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// banlistFilename is the name of the file in the data directory the banned
+// subnets are saved to so bans survive restarts.
+const banlistFilename = "banlist.json"
+
+// banEntry describes a banned subnet or, for addresses which are not IP
+// addresses such as onion addresses, a single banned host.
+type banEntry struct {
+	// subnet is the banned subnet.  It is nil for host bans.
+	subnet *net.IPNet
+
+	// host is the canonical string form of the ban which is also used as
+	// its key.
+	host string
+
+	created time.Time
+	until   time.Time
+}
+
+// serializedBanEntry is the form a ban is saved to the banlist file in.
+type serializedBanEntry struct {
+	Address     string `json:"address"`
+	BanCreated  int64  `json:"ban_created"`
+	BannedUntil int64  `json:"banned_until"`
+}
+
+// banList houses the banned subnets and hosts along with the path of the file
+// they are saved to.  It is not safe for concurrent access and is owned by the
+// peerHandler goroutine.
+type banList struct {
+	path    string
+	entries map[string]*banEntry
+}
+
+// parseBanTarget parses the passed address, which is either a subnet in CIDR
+// notation, a single IP address, or a host name such as an onion address, into
+// a ban entry without times.
+func parseBanTarget(addr string) (*banEntry, error) {
+	if strings.Contains(addr, "/") {
+		_, subnet, err := net.ParseCIDR(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid subnet %q", addr)
+		}
+		return &banEntry{subnet: subnet, host: subnet.String()}, nil
+	}
+
+	if ip := net.ParseIP(addr); ip != nil {
+		bits := 128
+		if ip4 := ip.To4(); ip4 != nil {
+			ip, bits = ip4, 32
+		}
+		subnet := &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+		return &banEntry{subnet: subnet, host: subnet.String()}, nil
+	}
+
+	if addr == "" || strings.ContainsAny(addr, " :") {
+		return nil, fmt.Errorf("invalid address %q", addr)
+	}
+	return &banEntry{host: strings.ToLower(addr)}, nil
+}
+
+// newBanList returns a ban list which is saved to the file at the passed path
+// and loads the bans which have not yet expired from it.  A missing file is
+// not an error.  When the file can't be read, the returned ban list is empty
+// along with the error and replaces the file once it is saved.  Passing an
+// empty path disables saving.
+func newBanList(path string) (*banList, error) {
+	bl := &banList{
+		path:    path,
+		entries: make(map[string]*banEntry),
+	}
+	if path == "" {
+		return bl, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return bl, nil
+	}
+	if err != nil {
+		return bl, err
+	}
+
+	var serialized []serializedBanEntry
+	if err := json.Unmarshal(data, &serialized); err != nil {
+		return bl, err
+	}
+	now := time.Now()
+	for _, s := range serialized {
+		entry, err := parseBanTarget(s.Address)
+		if err != nil {
+			return bl, err
+		}
+		entry.created = time.Unix(s.BanCreated, 0)
+		entry.until = time.Unix(s.BannedUntil, 0)
+		if !now.Before(entry.until) {
+			continue
+		}
+		bl.entries[entry.host] = entry
+	}
+	return bl, nil
+}
+
+// save writes the bans to the banlist file.
+func (bl *banList) save() error {
+	if bl.path == "" {
+		return nil
+	}
+
+	entries := bl.list()
+	serialized := make([]serializedBanEntry, 0, len(entries))
+	for _, entry := range entries {
+		serialized = append(serialized, serializedBanEntry{
+			Address:     entry.host,
+			BanCreated:  entry.created.Unix(),
+			BannedUntil: entry.until.Unix(),
+		})
+	}
+	data, err := json.Marshal(serialized)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash can't leave a truncated
+	// banlist behind.
+	tmpPath := bl.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, bl.path)
+}
+
+// add bans the passed subnet or host until the given time.  An existing ban of
+// the same subnet or host is replaced.
+func (bl *banList) add(entry *banEntry, until time.Time) {
+	entry.created = time.Now()
+	entry.until = until
+	bl.entries[entry.host] = entry
+}
+
+// remove lifts the ban of the passed subnet or host.  An error is returned if
+// it is not banned.
+func (bl *banList) remove(entry *banEntry) error {
+	if _, ok := bl.entries[entry.host]; !ok {
+		return errors.New("address is not banned")
+	}
+	delete(bl.entries, entry.host)
+	return nil
+}
+
+// clear lifts all bans.
+func (bl *banList) clear() {
+	bl.entries = make(map[string]*banEntry)
+}
+
+// expire removes the bans which have expired by the passed time and returns
+// whether any were removed.
+func (bl *banList) expire(now time.Time) bool {
+	var expired bool
+	for key, entry := range bl.entries {
+		if !now.Before(entry.until) {
+			delete(bl.entries, key)
+			expired = true
+		}
+	}
+	return expired
+}
+
+// isBanned returns the ban which covers the passed host, which is either an IP
+// address or a host name, or nil if it is not banned at the passed time.  When
+// multiple bans cover an IP address, the one which lasts the longest is
+// returned.
+func (bl *banList) isBanned(host string, now time.Time) *banEntry {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		entry, ok := bl.entries[strings.ToLower(host)]
+		if !ok || !now.Before(entry.until) {
+			return nil
+		}
+		return entry
+	}
+
+	var ban *banEntry
+	for _, entry := range bl.entries {
+		if entry.subnet == nil || !entry.subnet.Contains(ip) ||
+			!now.Before(entry.until) {
+
+			continue
+		}
+		if ban == nil || entry.until.After(ban.until) {
+			ban = entry
+		}
+	}
+	return ban
+}
+
+// matches returns whether the ban covers the passed host.
+func (e *banEntry) matches(host string) bool {
+	if e.subnet == nil {
+		return e.host == strings.ToLower(host)
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && e.subnet.Contains(ip)
+}
+
+// list returns the bans sorted by their creation time.
+func (bl *banList) list() []*banEntry {
+	entries := make([]*banEntry, 0, len(bl.entries))
+	for _, entry := range bl.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].created.Equal(entries[j].created) {
+			return entries[i].host < entries[j].host
+		}
+		return entries[i].created.Before(entries[j].created)
+	})
+	return entries
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestBanList ensures bans cover the expected hosts, expire, and survive being
+// saved to and loaded from the banlist file.
+func TestBanList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), banlistFilename)
+	bl, err := newBanList(path)
+	if err != nil {
+		t.Fatalf("newBanList without file: unexpected error: %v", err)
+	}
+
+	now := time.Now()
+	for _, test := range []struct {
+		addr  string
+		until time.Time
+	}{
+		{"10.0.0.0/8", now.Add(time.Hour)},
+		{"192.168.1.1", now.Add(time.Hour * 2)},
+		{"2001:db8::1", now.Add(time.Hour)},
+		{"ExampleOnionAddress.onion", now.Add(time.Hour)},
+		{"172.16.0.1", now.Add(-time.Hour)},
+	} {
+		entry, err := parseBanTarget(test.addr)
+		if err != nil {
+			t.Fatalf("parseBanTarget(%q): unexpected error: %v",
+				test.addr, err)
+		}
+		bl.add(entry, test.until)
+	}
+
+	checkBans := func(bl *banList) {
+		t.Helper()
+		tests := []struct {
+			host   string
+			banned bool
+		}{
+			{"10.1.2.3", true},
+			{"11.0.0.1", false},
+			{"192.168.1.1", true},
+			{"192.168.1.2", false},
+			{"2001:db8::1", true},
+			{"2001:db8::2", false},
+			{"exampleonionaddress.onion", true},
+			{"other.onion", false},
+			{"172.16.0.1", false},
+		}
+		for _, test := range tests {
+			banned := bl.isBanned(test.host, now) != nil
+			if banned != test.banned {
+				t.Errorf("isBanned(%q): got %v, want %v", test.host,
+					banned, test.banned)
+			}
+		}
+	}
+	checkBans(bl)
+
+	// Expired bans are not saved.
+	if !bl.expire(now) {
+		t.Fatal("expire: expected the expired ban to be removed")
+	}
+	if err := bl.save(); err != nil {
+		t.Fatalf("save: unexpected error: %v", err)
+	}
+	loaded, err := newBanList(path)
+	if err != nil {
+		t.Fatalf("newBanList: unexpected error: %v", err)
+	}
+	if len(loaded.entries) != 4 {
+		t.Fatalf("newBanList: got %d bans, want 4", len(loaded.entries))
+	}
+	checkBans(loaded)
+
+	entries := loaded.list()
+	if entries[0].host != "10.0.0.0/8" || entries[1].host != "192.168.1.1/32" {
+		t.Fatalf("list: unexpected bans %v, %v", entries[0].host,
+			entries[1].host)
+	}
+
+	// Removing a ban which doesn't exist is an error.
+	entry, _ := parseBanTarget("192.168.1.1")
+	if err := loaded.remove(entry); err != nil {
+		t.Fatalf("remove: unexpected error: %v", err)
+	}
+	if err := loaded.remove(entry); err == nil {
+		t.Fatal("remove of address which is not banned: expected error")
+	}
+	loaded.clear()
+	if len(loaded.list()) != 0 {
+		t.Fatal("clear: bans remain")
+	}
+
+	// A corrupt file is reported but results in a usable empty ban list
+	// which replaces it.
+	if err := os.WriteFile(path, []byte("{"), 0600); err != nil {
+		t.Fatalf("unable to write banlist file: %v", err)
+	}
+	bl, err = newBanList(path)
+	if err == nil {
+		t.Fatal("newBanList with corrupt file: expected error")
+	}
+	if len(bl.entries) != 0 {
+		t.Fatal("newBanList with corrupt file: expected no bans")
+	}
+	if err := bl.save(); err != nil {
+		t.Fatalf("save: unexpected error: %v", err)
+	}
+	if _, err := newBanList(path); err != nil {
+		t.Fatalf("newBanList after replacing corrupt file: %v", err)
+	}
+}
+
+// TestParseBanTarget ensures invalid ban targets are rejected.
+func TestParseBanTarget(t *testing.T) {
+	for _, addr := range []string{"", "10.0.0.0/33", "1.2.3.4:9246",
+		"not an address"} {
+
+		if _, err := parseBanTarget(addr); err == nil {
+			t.Errorf("parseBanTarget(%q): expected error", addr)
+		}
+	}
+}
@@ -6,6 +6,7 @@ package blockchain
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/btcsuite/btcd/btcutil/v2"
 	"github.com/btcsuite/btcd/database"
@@ -31,6 +32,11 @@ func (b *BlockChain) maybeAcceptBlock(block *btcutil.Block, flags BehaviorFlags)
 		str := fmt.Sprintf("previous block %s is unknown", prevHash)
 		return false, ruleError(ErrPreviousBlockUnknown, str)
 	} else if b.index.NodeStatus(prevNode).KnownInvalid() {
+		// Keep track of the work of invalid chains which keep being
+		// extended.
+		b.checkForkWarning(newBlockNode(&block.MsgBlock().Header,
+			prevNode), true)
+
 		str := fmt.Sprintf("previous block %s is known to be invalid", prevHash)
 		return false, ruleError(ErrInvalidAncestorBlock, str)
 	}
@@ -38,12 +44,22 @@ func (b *BlockChain) maybeAcceptBlock(block *btcutil.Block, flags BehaviorFlags)
 	blockHeight := prevNode.height + 1
 	block.SetHeight(blockHeight)
 
+	// Orphans which are accepted once their parent is known start a new
+	// benchmark.
+	bench := b.benchmark(block)
+	if bench == nil {
+		bench = &BlockBenchmark{Hash: *block.Hash()}
+		b.bench = bench
+	}
+
 	// The block must pass all of the validation rules which depend on the
 	// position of the block within the block chain.
+	start := time.Now()
 	err := b.checkBlockContext(block, prevNode, flags)
 	if err != nil {
 		return false, err
 	}
+	bench.Check += time.Since(start)
 
 	// Insert the block into the database if it's not already there.  Even
 	// though it is possible the block will ultimately fail to connect, it
@@ -54,12 +70,14 @@ func (b *BlockChain) maybeAcceptBlock(block *btcutil.Block, flags BehaviorFlags)
 	// expensive connection logic.  It also has some other nice properties
 	// such as making blocks that never become part of the main chain or
 	// blocks that fail to connect available for further analysis.
+	start = time.Now()
 	err = b.db.Update(func(dbTx database.Tx) error {
 		return dbStoreBlock(dbTx, block)
 	})
 	if err != nil {
 		return false, err
 	}
+	bench.Store = time.Since(start)
 
 	// Create a new block node for the block and add it to the node index. Even
 	// if the block ultimately gets connected to the main chain, it starts out
@@ -87,6 +105,7 @@ func (b *BlockChain) maybeAcceptBlock(block *btcutil.Block, flags BehaviorFlags)
 	// selection according to the chain with the most proof of work.  This
 	// also handles validation of the transaction scripts.
 	isMainChain, err := b.connectBestChain(newNode, block, flags)
+	b.checkForkWarning(newNode, b.index.NodeStatus(newNode).KnownInvalid())
 	if err != nil {
 		return false, err
 	}
@@ -134,6 +153,8 @@ func (b *BlockChain) maybeAcceptBlockHeader(header *wire.BlockHeader,
 
 	// This header is invalid if its previous node is invalid.
 	if b.index.NodeStatus(prevNode).KnownInvalid() {
+		b.checkForkWarning(newBlockNode(header, prevNode), true)
+
 		str := fmt.Sprintf(
 			"previous block %s is known to be invalid", prevHash)
 		return false, ruleError(ErrInvalidAncestorBlock, str)
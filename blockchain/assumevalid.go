@@ -0,0 +1,56 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"math/big"
+	"time"
+)
+
+// assumeValidMinWorkTime is the minimum time the best header chain must be
+// ahead of a block, measured in the equivalent time it takes to produce its
+// work at the difficulty of the tip of the best header chain, for the scripts
+// of the block to be skipped when it is an ancestor of the assumed valid block.
+// This ensures scripts are only skipped for blocks which are buried deeply
+// enough that any invalid script would have been noticed by the network.
+const assumeValidMinWorkTime = time.Hour * 24 * 14
+
+// isAssumedValid returns whether the scripts of the passed block node can be
+// skipped since it is an ancestor of the block which is assumed to be valid.
+// This is the case when:
+//   - the assumed valid block is in the best header chain
+//   - the passed node is an ancestor of the assumed valid block
+//   - the best header chain has at least the minimum chain work
+//   - the best header chain is ahead of the passed node by at least
+//     assumeValidMinWorkTime worth of work
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) isAssumedValid(node *blockNode) bool {
+	if b.assumeValid == nil {
+		return false
+	}
+	assumeValidNode := b.index.LookupNode(b.assumeValid)
+	if assumeValidNode == nil || !b.bestHeader.Contains(assumeValidNode) {
+		return false
+	}
+	if assumeValidNode.Ancestor(node.height) != node {
+		return false
+	}
+
+	tip := b.bestHeader.Tip()
+	if b.minimumChainWork != nil &&
+		tip.workSum.Cmp(b.minimumChainWork) < 0 {
+
+		return false
+	}
+
+	// Convert the work the best header chain is ahead of the node by into
+	// the equivalent number of blocks at the difficulty of its tip.
+	workAhead := new(big.Int).Sub(tip.workSum, node.workSum)
+	blocksAhead := workAhead.Div(workAhead, CalcWork(tip.bits))
+	minBlocks := int64(assumeValidMinWorkTime /
+		b.chainParams.TargetTimePerBlock)
+	return blocksAhead.Cmp(big.NewInt(minBlocks)) >= 0
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/v2"
+)
+
+// TestIsAssumedValid ensures the scripts of a block are only assumed to be
+// valid when it is an ancestor of the assumed valid block in the best header
+// chain and the best header chain is far enough ahead of it.
+func TestIsAssumedValid(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	chain := newFakeChain(params)
+
+	// Create a best header chain of 2100 blocks along with a side chain
+	// which forks off after the 10th block.  The best header chain has to
+	// be 2016 blocks ahead of a block with the regression test params.
+	genesis := chain.bestChain.Tip()
+	timestamp := genesis.Header().Timestamp
+	bits := params.PowLimitBits
+	nodes := []*blockNode{genesis}
+	for i := 1; i <= 2100; i++ {
+		timestamp = timestamp.Add(time.Minute * 10)
+		node := newFakeNode(nodes[i-1], 1, bits, timestamp)
+		chain.index.AddNode(node)
+		nodes = append(nodes, node)
+	}
+	sideNode := newFakeNode(nodes[10], 2, bits, timestamp)
+	chain.index.AddNode(sideNode)
+	chain.bestHeader = newChainView(nodes[2100])
+
+	tests := []struct {
+		name             string
+		assumeValid      *blockNode
+		minimumChainWork *big.Int
+		node             *blockNode
+		want             bool
+	}{
+		{"disabled", nil, nil, nodes[50], false},
+		{"deep ancestor", nodes[2050], nil, nodes[50], true},
+		{"shallow ancestor", nodes[2050], nil, nodes[100], false},
+		{"not an ancestor", nodes[2050], nil, sideNode, false},
+		{"descendant", nodes[20], nil, nodes[50], false},
+		{"not in best header chain", sideNode, nil, nodes[5], false},
+		{"minimum chain work met", nodes[2050],
+			new(big.Int).Set(nodes[2100].workSum), nodes[50], true},
+		{"minimum chain work not met", nodes[2050],
+			new(big.Int).Add(nodes[2100].workSum, big.NewInt(1)),
+			nodes[50], false},
+	}
+	for _, test := range tests {
+		chain.assumeValid = nil
+		if test.assumeValid != nil {
+			chain.assumeValid = &test.assumeValid.hash
+		}
+		chain.minimumChainWork = test.minimumChainWork
+		if got := chain.isAssumedValid(test.node); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil/v2"
+	"github.com/btcsuite/btcd/chainhash/v2"
+)
+
+// maxBlockBenchmarks is the number of benchmarks of the most recent blocks
+// connected to the main chain which are kept.
+const maxBlockBenchmarks = 100
+
+// BlockBenchmark holds the time spent in each stage of processing a block which
+// was connected to the end of the main chain.  Stages which were skipped, such
+// as the validation of the scripts of blocks which are assumed to be valid, take
+// no time.
+type BlockBenchmark struct {
+	Hash      chainhash.Hash
+	Height    int32
+	NumTxns   int
+	NumInputs int
+
+	// Check is the time spent on the checks of the block which don't need
+	// the outputs it spends.
+	Check time.Duration
+
+	// Store is the time spent storing the block in the database.
+	Store time.Duration
+
+	// LoadInputs is the time spent loading the outputs spent by the block
+	// from the utxo cache and the database to validate it.  They are loaded
+	// as part of the utxo update instead when the block isn't validated.
+	LoadInputs time.Duration
+
+	// Scripts is the time spent validating the scripts of the block
+	// excluding the time spent loading the outputs they spend.
+	Scripts time.Duration
+
+	// UtxoUpdate is the time spent updating the utxo cache.
+	UtxoUpdate time.Duration
+
+	// Write is the time spent writing the best chain state and the spend
+	// journal of the block to the database.
+	Write time.Duration
+
+	// Index is the time spent updating the optional indexes.
+	Index time.Duration
+
+	// Flush is the time spent flushing the utxo cache to the database when
+	// it needed to be flushed.
+	Flush time.Duration
+
+	// Total is the sum of the time spent in all of the stages.
+	Total time.Duration
+}
+
+// blockBenchmarks keeps the benchmarks of the most recent blocks connected to
+// the main chain ordered from the oldest to the newest.  It has its own lock
+// since the benchmarks are recorded while the chain lock is held and are
+// queried independently of it.
+type blockBenchmarks struct {
+	sync.Mutex
+	benchmarks []BlockBenchmark
+}
+
+// record adds the passed benchmark as the newest one and drops the oldest one
+// when the maximum number of benchmarks are kept.
+func (s *blockBenchmarks) record(bench *BlockBenchmark) {
+	s.Lock()
+	if len(s.benchmarks) == maxBlockBenchmarks {
+		copy(s.benchmarks, s.benchmarks[1:])
+		s.benchmarks = s.benchmarks[:len(s.benchmarks)-1]
+	}
+	s.benchmarks = append(s.benchmarks, *bench)
+	s.Unlock()
+}
+
+// benchmark returns the benchmark of the block which is being processed when it
+// is the passed block and nil otherwise.
+//
+// This function MUST be called with the chain lock held.
+func (b *BlockChain) benchmark(block *btcutil.Block) *BlockBenchmark {
+	if b.bench == nil || b.bench.Hash != *block.Hash() {
+		return nil
+	}
+	return b.bench
+}
+
+// recordBenchmark completes the passed benchmark of the block which was just
+// connected to the end of the main chain, records it and logs it when block
+// benchmarks are to be logged.
+//
+// This function MUST be called with the chain lock held (for writes).
+func (b *BlockChain) recordBenchmark(bench *BlockBenchmark, block *btcutil.Block) {
+	bench.Height = block.Height()
+	bench.NumTxns = len(block.MsgBlock().Transactions)
+	bench.Total = bench.Check + bench.Store + bench.LoadInputs +
+		bench.Scripts + bench.UtxoUpdate + bench.Write + bench.Index +
+		bench.Flush
+	b.benchmarks.record(bench)
+
+	if b.logBenchmarks {
+		log.Infof("Connected block %v (height %d, %d txns, %d inputs) "+
+			"in %v: check %v, store %v, load inputs %v, scripts %v, "+
+			"utxo update %v, write %v, index %v, flush %v",
+			bench.Hash, bench.Height, bench.NumTxns,
+			bench.NumInputs, bench.Total, bench.Check, bench.Store,
+			bench.LoadInputs, bench.Scripts, bench.UtxoUpdate,
+			bench.Write, bench.Index, bench.Flush)
+	}
+}
+
+// BlockBenchmarks returns the benchmarks of up to the 100 most recent blocks
+// connected to the end of the main chain since the chain instance was created
+// ordered from the oldest to the newest.  Blocks connected during a
+// reorganization are not included.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) BlockBenchmarks() []BlockBenchmark {
+	b.benchmarks.Lock()
+	defer b.benchmarks.Unlock()
+	return append([]BlockBenchmark(nil), b.benchmarks.benchmarks...)
+}
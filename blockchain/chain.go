@@ -8,6 +8,7 @@ package blockchain
 import (
 	"container/list"
 	"fmt"
+	"math/big"
 	"sync"
 	"time"
 
@@ -68,6 +69,7 @@ type BestState struct {
 	NumTxns     uint64         // The number of txns in the block.
 	TotalTxns   uint64         // The total number of txns in the chain.
 	MedianTime  time.Time      // Median time as per CalcPastMedianTime.
+	WorkSum     *big.Int       // The total work of the chain.
 }
 
 // newBestState returns a new best stats instance for the given parameters.
@@ -83,6 +85,7 @@ func newBestState(node *blockNode, blockSize, blockWeight, numTxns,
 		NumTxns:     numTxns,
 		TotalTxns:   totalTxns,
 		MedianTime:  medianTime,
+		WorkSum:     new(big.Int).Set(node.workSum),
 	}
 }
 
@@ -102,6 +105,12 @@ type BlockChain struct {
 	sigCache            *txscript.SigCache
 	indexManager        IndexManager
 	hashCache           *txscript.HashCache
+	minimumChainWork    *big.Int
+	assumeValid         *chainhash.Hash
+	scriptWorkers       int
+	batchSigVerify      bool
+	spendJournalDepth   int32
+	logBenchmarks       bool
 
 	// The following fields are calculated based upon the provided chain
 	// parameters.  They are also set when the instance is created and
@@ -119,6 +128,11 @@ type BlockChain struct {
 	// is pruned.
 	pruneTarget uint64
 
+	// spendJournalPruneHeight is the height of the last block of the main
+	// chain whose spend journal has been pruned.  It is zero when no spend
+	// journals have been pruned.
+	spendJournalPruneHeight int32
+
 	// These fields are related to the memory block index.  They both have
 	// their own locks, however they are often also protected by the chain
 	// lock to help prevent logic races when blocks are being processed.
@@ -189,10 +203,36 @@ type BlockChain struct {
 	// activated.
 	unknownRulesWarned bool
 
+	// The following fields are used to warn about forks of the main chain
+	// which indicate the network may have split.  They are protected by the
+	// chain lock.
+	//
+	// bestInvalid is the invalid block with the most work.
+	//
+	// forkTip is the tip of the most recent side chain with a significant
+	// amount of work.
+	//
+	// chainWarning is the current warning about such forks, if any.
+	bestInvalid  *blockNode
+	forkTip      *blockNode
+	chainWarning string
+
 	// The notifications field stores a slice of callbacks to be executed on
 	// certain blockchain events.
 	notificationsLock sync.RWMutex
 	notifications     []NotificationCallback
+
+	// bench is the benchmark of the block which is being processed.  It is
+	// protected by the chain lock.
+	//
+	// benchmarks keeps the benchmarks of the most recent blocks connected
+	// to the main chain.  It has its own lock.
+	bench      *BlockBenchmark
+	benchmarks blockBenchmarks
+
+	// scriptStats tracks the time spent validating the scripts of the
+	// blocks connected to the main chain.  It has its own lock.
+	scriptStats scriptValidationStats
 }
 
 // HaveBlock returns whether or not the chain instance has the block data
@@ -209,6 +249,16 @@ func (b *BlockChain) HaveBlock(hash *chainhash.Hash) (bool, error) {
 	return exists || b.IsKnownOrphan(hash), nil
 }
 
+// IsKnownInvalidBlock returns whether or not the block represented by the
+// passed hash is in the block index and is known to be invalid, either because
+// it failed validation or because one of its ancestors did.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) IsKnownInvalidBlock(hash *chainhash.Hash) bool {
+	node := b.index.LookupNode(hash)
+	return node != nil && b.index.NodeStatus(node).KnownInvalid()
+}
+
 // IsKnownOrphan returns whether the passed hash is currently a known orphan.
 // Keep in mind that only a limited number of orphans are held onto for a
 // limited amount of time, so this function must not be used as an absolute
@@ -616,7 +666,19 @@ func (b *BlockChain) connectBlock(node *blockNode, block *btcutil.Block,
 		curTotalTxns+numTxns, CalcPastMedianTime(node),
 	)
 
+	// Determine the height up to which the spend journals are pruned once
+	// the block is connected.
+	spendJournalPruneHeight := b.spendJournalPruneHeight
+	if b.spendJournalDepth != 0 && node.height-b.spendJournalDepth >
+		spendJournalPruneHeight {
+
+		spendJournalPruneHeight = node.height - b.spendJournalDepth
+	}
+
 	// Atomically insert info into the database.
+	bench := b.benchmark(block)
+	start := time.Now()
+	var indexElapsed time.Duration
 	err = b.db.Update(func(dbTx database.Tx) error {
 		// If the pruneTarget isn't 0, we should attempt to delete older blocks
 		// from the database.
@@ -636,6 +698,13 @@ func (b *BlockChain) connectBlock(node *blockNode, block *btcutil.Block,
 					return err
 				}
 
+				// Let the indexes remove the entries of the pruned
+				// blocks.
+				err = b.dbPruneIndexes(dbTx, deletedHashes)
+				if err != nil {
+					return err
+				}
+
 				// We may need to flush if the prune will delete blocks that
 				// are past our last flush block.
 				//
@@ -676,14 +745,26 @@ func (b *BlockChain) connectBlock(node *blockNode, block *btcutil.Block,
 			return err
 		}
 
+		// Delete the spend journal of the block which is now deeper
+		// than the configured depth when the spend journal is pruned.
+		if spendJournalPruneHeight > b.spendJournalPruneHeight {
+			err = b.dbPruneSpendJournals(dbTx,
+				spendJournalPruneHeight)
+			if err != nil {
+				return err
+			}
+		}
+
 		// Allow the index manager to call each of the currently active
 		// optional indexes with the block being connected so they can
 		// update themselves accordingly.
 		if b.indexManager != nil {
+			indexStart := time.Now()
 			err := b.indexManager.ConnectBlock(dbTx, block, stxos)
 			if err != nil {
 				return err
 			}
+			indexElapsed = time.Since(indexStart)
 		}
 
 		return nil
@@ -691,9 +772,14 @@ func (b *BlockChain) connectBlock(node *blockNode, block *btcutil.Block,
 	if err != nil {
 		return err
 	}
+	if bench != nil {
+		bench.Write = time.Since(start) - indexElapsed
+		bench.Index = indexElapsed
+	}
 
 	// This node is now the end of the best chain.
 	b.bestChain.SetTip(node)
+	b.spendJournalPruneHeight = spendJournalPruneHeight
 
 	// Update the state for the best block.  Notice how this replaces the
 	// entire struct instead of updating the existing one.  This effectively
@@ -715,9 +801,14 @@ func (b *BlockChain) connectBlock(node *blockNode, block *btcutil.Block,
 
 	// Since we may have changed the UTXO cache, we make sure it didn't exceed its
 	// maximum size.  If we're pruned and have flushed already, this will be a no-op.
-	return b.db.Update(func(dbTx database.Tx) error {
+	start = time.Now()
+	err = b.db.Update(func(dbTx database.Tx) error {
 		return b.utxoCache.flush(dbTx, FlushIfNeeded, state)
 	})
+	if bench != nil {
+		bench.Flush = time.Since(start)
+	}
+	return err
 }
 
 // disconnectBlock handles disconnecting the passed node/block from the end of
@@ -925,6 +1016,7 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List) error
 
 		newBest = n.parent
 	}
+	forkPoint := newBest
 
 	// Set the fork point only if there are nodes to attach since otherwise
 	// blocks are only being disconnected and thus there is no fork point.
@@ -937,6 +1029,7 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List) error
 	// efficient and since we already checked that the blocks are correct and that
 	// the transactions connect properly, it's ok to access the cache.  If we suddenly
 	// crash here, we are able to recover as well.
+	attachSpentTxOuts := make([][]SpentTxOut, 0, attachNodes.Len())
 	for i, e := 0, attachNodes.Front(); e != nil; i, e = i+1, e.Next() {
 		n := e.Value.(*blockNode)
 		block := attachBlocks[i]
@@ -950,6 +1043,7 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List) error
 		if err != nil {
 			return err
 		}
+		attachSpentTxOuts = append(attachSpentTxOuts, stxos)
 
 		// Update the database and chain state.
 		err = b.connectBlock(n, block, stxos)
@@ -971,6 +1065,35 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List) error
 	log.Infof("REORGANIZE: New best chain head is %v (height %v)",
 		newBest.hash, newBest.height)
 
+	// Notify the caller about the reorganization as a whole when blocks
+	// were disconnected from the main chain.
+	if detachNodes.Len() > 0 {
+		ntfnData := &ReorganizationNtfnData{
+			OldHash:      oldBest.hash,
+			OldHeight:    oldBest.height,
+			NewHash:      newBest.hash,
+			NewHeight:    newBest.height,
+			ForkHash:     forkPoint.hash,
+			ForkHeight:   forkPoint.height,
+			Disconnected: make([]chainhash.Hash, 0, detachNodes.Len()),
+			Connected:    make([]chainhash.Hash, 0, attachNodes.Len()),
+		}
+		seenNames := make(map[string]struct{})
+		for i, e := 0, detachNodes.Front(); e != nil; i, e = i+1, e.Next() {
+			n := e.Value.(*blockNode)
+			ntfnData.Disconnected = append(ntfnData.Disconnected, n.hash)
+			ntfnData.addClaimNames(seenNames, detachBlocks[i],
+				detachSpentTxOuts[i])
+		}
+		for i, e := 0, attachNodes.Front(); e != nil; i, e = i+1, e.Next() {
+			n := e.Value.(*blockNode)
+			ntfnData.Connected = append(ntfnData.Connected, n.hash)
+			ntfnData.addClaimNames(seenNames, attachBlocks[i],
+				attachSpentTxOuts[i])
+		}
+		b.sendNotification(NTReorganization, ntfnData)
+	}
+
 	return nil
 }
 
@@ -1031,6 +1154,12 @@ func (b *BlockChain) verifyReorganizationValidity(detachNodes, attachNodes *list
 	view.SetBestHash(&tip.hash)
 	for e := detachNodes.Front(); e != nil; e = e.Next() {
 		n := e.Value.(*blockNode)
+
+		// The block can't be disconnected without its spend journal.
+		if b.spendJournalPruned(n) {
+			return nil, nil, nil, spendJournalPrunedError(n)
+		}
+
 		var block *btcutil.Block
 		err := b.db.View(func(dbTx database.Tx) error {
 			var err error
@@ -1160,6 +1289,7 @@ func (b *BlockChain) verifyReorganizationValidity(detachNodes, attachNodes *list
 // This function MUST be called with the chain state lock held (for writes).
 func (b *BlockChain) connectBestChain(node *blockNode, block *btcutil.Block, flags BehaviorFlags) (bool, error) {
 	fastAdd := flags&BFFastAdd == BFFastAdd
+	bench := b.benchmark(block)
 
 	flushIndexState := func() {
 		// Intentionally ignore errors writing updated node status to DB. If
@@ -1214,11 +1344,15 @@ func (b *BlockChain) connectBestChain(node *blockNode, block *btcutil.Block, fla
 
 		// Connect the transactions to the cache.  All the txs are considered valid
 		// at this point as they have passed validation or was considered valid already.
+		start := time.Now()
 		stxos := make([]SpentTxOut, 0, countSpentOutputs(block))
 		err := b.utxoCache.connectTransactions(block, &stxos)
 		if err != nil {
 			return false, err
 		}
+		if bench != nil {
+			bench.UtxoUpdate = time.Since(start)
+		}
 
 		// Connect the block to the main chain.
 		err = b.connectBlock(node, block, stxos)
@@ -1245,6 +1379,10 @@ func (b *BlockChain) connectBestChain(node *blockNode, block *btcutil.Block, fla
 			flushIndexState()
 		}
 
+		if bench != nil {
+			b.recordBenchmark(bench, block)
+		}
+
 		return true, nil
 	}
 	if fastAdd {
@@ -1503,6 +1641,34 @@ func (b *BlockChain) MainChainHasBlock(hash *chainhash.Hash) bool {
 	return node != nil && b.bestChain.Contains(node)
 }
 
+// ChainWorkByHash returns the total cumulative work of the chain up to and
+// including the block with the given hash.  The block does not have to be in
+// the main chain.  The work of the block itself can be obtained with CalcWork
+// from the difficulty bits of its header.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ChainWorkByHash(hash *chainhash.Hash) (*big.Int, error) {
+	node := b.index.LookupNode(hash)
+	if node == nil {
+		return nil, fmt.Errorf("block %s is not known", hash)
+	}
+
+	return new(big.Int).Set(node.workSum), nil
+}
+
+// MedianTimeByHash returns the median time of the block with the given hash as
+// per CalcPastMedianTime.  The block does not have to be in the main chain.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) MedianTimeByHash(hash *chainhash.Hash) (time.Time, error) {
+	node := b.index.LookupNode(hash)
+	if node == nil {
+		return time.Time{}, fmt.Errorf("block %s is not known", hash)
+	}
+
+	return CalcPastMedianTime(node), nil
+}
+
 // BlockLocatorFromHash returns a block locator for the passed block hash.
 // See BlockLocator for details on the algorithm used to create a block locator.
 //
@@ -2141,6 +2307,15 @@ type IndexManager interface {
 	DisconnectBlock(database.Tx, *btcutil.Block, []SpentTxOut) error
 }
 
+// IndexPruner provides an interface for an index manager whose indexes need to
+// remove the entries of the blocks which are deleted when the database is
+// pruned.
+type IndexPruner interface {
+	// PruneBlocks is invoked with the hashes of the blocks which are
+	// deleted from the database when it is pruned.
+	PruneBlocks(database.Tx, []chainhash.Hash) error
+}
+
 // Config is a descriptor which specifies the blockchain instance configuration.
 type Config struct {
 	// DB defines the database which houses the blocks and will be used to
@@ -2154,6 +2329,20 @@ type Config struct {
 	// This field is required.
 	UtxoCacheMaxSize uint64
 
+	// UtxoCacheFlushInterval is the interval at which the UTXO cache is
+	// flushed to the database when a periodic flush is requested, which
+	// happens once the chain is current.  The cache is always flushed when
+	// it exceeds its maximum size regardless of the interval.
+	//
+	// This field can be zero to use the default interval of five minutes.
+	UtxoCacheFlushInterval time.Duration
+
+	// ReindexChainState specifies whether the UTXO state is rebuilt from the
+	// blocks of the main chain in the database.  The block index is kept as
+	// is.  An interrupted rebuild is resumed the next time the chain is
+	// created regardless of this field.
+	ReindexChainState bool
+
 	// Interrupt specifies a channel the caller can close to signal that
 	// long running operations, such as catching up indexes or performing
 	// database migrations, should be interrupted.
@@ -2213,6 +2402,54 @@ type Config struct {
 	// will target for with block files.  Prune at 0 specifies that no
 	// blocks will be deleted.
 	Prune uint64
+
+	// SpendJournalDepth is the number of blocks at the tip of the main
+	// chain whose spend journals are kept.  The spend journals of deeper
+	// blocks are deleted while the blocks themselves are kept.  A spend
+	// journal is needed to disconnect its block, so a reorganization
+	// deeper than this depth is refused.  The optional indexes which need
+	// the spent outputs can't be caught up past pruned spend journals
+	// either.
+	//
+	// This field can be zero to keep all of the spend journals.
+	SpendJournalDepth int32
+
+	// MinimumChainWork is the minimum cumulative work a chain of headers
+	// must have before its headers are stored in the block index.  Chains
+	// of headers with less work are first synced without being stored
+	// to prevent peers from filling the block index with low-work headers.
+	//
+	// This field can be nil if the caller does not wish to require a
+	// minimum amount of chain work.
+	MinimumChainWork *big.Int
+
+	// AssumeValid is the hash of a block whose ancestors are assumed to
+	// have valid scripts.  The scripts of its ancestors are not verified
+	// while it is part of the best header chain and that chain is far
+	// enough ahead of the block being connected, which is the case during
+	// the initial block download.
+	//
+	// This field can be nil if the caller wishes to verify all scripts.
+	AssumeValid *chainhash.Hash
+
+	// ScriptWorkers is the maximum number of goroutines used to validate
+	// the scripts of a block concurrently.
+	//
+	// This field can be zero to use DefaultScriptWorkers.
+	ScriptWorkers int
+
+	// BatchSigVerify specifies whether the Schnorr signatures of the
+	// taproot inputs of a block are verified in batches, which is faster
+	// than verifying them one at a time.  The inputs of a batch which
+	// fails are validated again one signature at a time to find the
+	// invalid one.
+	BatchSigVerify bool
+
+	// LogBlockBenchmarks specifies whether the time spent in each stage of
+	// processing a block is logged for every block connected to the end of
+	// the main chain.  The benchmarks of the most recent blocks are
+	// available from BlockBenchmarks either way.
+	LogBlockBenchmarks bool
 }
 
 // New returns a BlockChain instance using the provided configuration details.
@@ -2262,8 +2499,14 @@ func New(config *Config) (*BlockChain, error) {
 		maxRetargetTimespan: targetTimespan * adjustmentFactor,
 		blocksPerRetarget:   int32(targetTimespan / targetTimePerBlock),
 		index:               newBlockIndex(config.DB, params),
-		utxoCache:           newUtxoCache(config.DB, config.UtxoCacheMaxSize),
+		utxoCache:           newUtxoCache(config.DB, config.UtxoCacheMaxSize, config.UtxoCacheFlushInterval),
 		hashCache:           config.HashCache,
+		minimumChainWork:    config.MinimumChainWork,
+		assumeValid:         config.AssumeValid,
+		scriptWorkers:       config.ScriptWorkers,
+		batchSigVerify:      config.BatchSigVerify,
+		spendJournalDepth:   config.SpendJournalDepth,
+		logBenchmarks:       config.LogBlockBenchmarks,
 		bestChain:           newChainView(nil),
 		bestHeader:          newChainView(nil),
 		orphans:             make(map[chainhash.Hash]*orphanBlock),
@@ -2293,6 +2536,13 @@ func New(config *Config) (*BlockChain, error) {
 	if err := b.initChainState(); err != nil {
 		return nil, err
 	}
+	err := b.db.View(func(dbTx database.Tx) error {
+		b.spendJournalPruneHeight = dbFetchSpendJournalPruneHeight(dbTx)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
 	// Perform any upgrades to the various chain-specific buckets as needed.
 	if err := b.maybeUpgradeDbBuckets(config.Interrupt); err != nil {
@@ -2313,12 +2563,25 @@ func New(config *Config) (*BlockChain, error) {
 		return nil, err
 	}
 
+	// Rebuild the utxo state from scratch when requested.
+	if config.ReindexChainState {
+		if err := b.resetUtxoState(); err != nil {
+			return nil, err
+		}
+	}
+
 	// Make sure the utxo state is catched up if it was left in an inconsistent
 	// state.
 	bestNode := b.bestChain.Tip()
 	if err := b.InitConsistentState(bestNode, config.Interrupt); err != nil {
 		return nil, err
 	}
+
+	// Delete the spend journals which are deeper than the configured depth,
+	// which is a lot of them when the depth was just configured.
+	if err := b.pruneSpendJournals(config.Interrupt); err != nil {
+		return nil, err
+	}
 	log.Infof("Chain state (height %d, hash %v, totaltx %d, work %v)",
 		bestNode.height, bestNode.hash, b.stateSnapshot.TotalTxns,
 		bestNode.workSum)
@@ -5,9 +5,12 @@
 package blockchain
 
 import (
+	"bytes"
 	"fmt"
+	"math/big"
 	"math/rand"
 	"reflect"
+	"slices"
 	"testing"
 	"time"
 
@@ -15,6 +18,7 @@ import (
 	"github.com/btcsuite/btcd/btcutil/v2"
 	"github.com/btcsuite/btcd/chaincfg/v2"
 	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/txscript/v2"
 	"github.com/btcsuite/btcd/wire/v2"
 )
 
@@ -994,6 +998,61 @@ func TestIntervalBlockHashes(t *testing.T) {
 	}
 }
 
+// TestChainWorkAndMedianTimeByHash ensures the chain work and median time of
+// blocks in and out of the main chain are reported as expected.
+func TestChainWorkAndMedianTimeByHash(t *testing.T) {
+	// Construct a synthetic block chain with a block index consisting of
+	// the following structure.
+	// 	genesis -> 1 -> 2 -> ... -> 15 -> 16  -> 17  -> 18
+	// 	                              \-> 16a -> 17a
+	tip := tstTip
+	chain := newFakeChain(&chaincfg.MainNetParams)
+	branch0Nodes := chainedNodes(chain.bestChain.Genesis(), 18)
+	branch1Nodes := chainedNodes(branch0Nodes[14], 2)
+	for _, node := range branch0Nodes {
+		chain.index.AddNode(node)
+	}
+	for _, node := range branch1Nodes {
+		chain.index.AddNode(node)
+	}
+	chain.bestChain.SetTip(tip(branch0Nodes))
+
+	for _, node := range []*blockNode{tip(branch0Nodes), tip(branch1Nodes)} {
+		// The chain work is the sum of the work of the block and all
+		// of its ancestors.
+		wantWork := new(big.Int)
+		for n := node; n != nil; n = n.parent {
+			wantWork.Add(wantWork, CalcWork(n.bits))
+		}
+		work, err := chain.ChainWorkByHash(&node.hash)
+		if err != nil {
+			t.Fatalf("ChainWorkByHash: unexpected error: %v", err)
+		}
+		if work.Cmp(wantWork) != 0 {
+			t.Fatalf("ChainWorkByHash(%d): got %v, want %v",
+				node.height, work, wantWork)
+		}
+
+		medianTime, err := chain.MedianTimeByHash(&node.hash)
+		if err != nil {
+			t.Fatalf("MedianTimeByHash: unexpected error: %v", err)
+		}
+		if want := CalcPastMedianTime(node); !medianTime.Equal(want) {
+			t.Fatalf("MedianTimeByHash(%d): got %v, want %v",
+				node.height, medianTime, want)
+		}
+	}
+
+	// Unknown blocks are reported as errors.
+	var unknownHash chainhash.Hash
+	if _, err := chain.ChainWorkByHash(&unknownHash); err == nil {
+		t.Fatal("ChainWorkByHash: expected error for unknown block")
+	}
+	if _, err := chain.MedianTimeByHash(&unknownHash); err == nil {
+		t.Fatal("MedianTimeByHash: expected error for unknown block")
+	}
+}
+
 func TestChainTips(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -1882,3 +1941,466 @@ func TestReconsiderBlock(t *testing.T) {
 		}()
 	}
 }
+
+// TestIsKnownInvalidBlock ensures blocks are only reported as known invalid
+// when they or one of their ancestors failed validation.
+func TestIsKnownInvalidBlock(t *testing.T) {
+	// Construct a synthetic block chain with a block index consisting of
+	// the following structure.
+	// 	genesis -> 1 -> 2 -> 3
+	chain := newFakeChain(&chaincfg.MainNetParams)
+	nodes := chainedNodes(chain.bestChain.Genesis(), 3)
+	for _, node := range nodes {
+		chain.index.AddNode(node)
+	}
+	chain.index.SetStatusFlags(nodes[0], statusValid)
+	chain.index.SetStatusFlags(nodes[1], statusValidateFailed)
+	chain.index.SetStatusFlags(nodes[2], statusInvalidAncestor)
+
+	tests := []struct {
+		name string
+		hash chainhash.Hash
+		want bool
+	}{
+		{"valid block", nodes[0].hash, false},
+		{"failed validation", nodes[1].hash, true},
+		{"invalid ancestor", nodes[2].hash, true},
+		{"unknown block", chainhash.Hash{0x01}, false},
+	}
+	for _, test := range tests {
+		got := chain.IsKnownInvalidBlock(&test.hash)
+		if got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got,
+				test.want)
+		}
+	}
+}
+
+// TestForEachBlock ensures ForEachBlock visits the requested blocks of the main
+// chain in order along with the outputs they spend.
+func TestForEachBlock(t *testing.T) {
+	chain, tearDown, err := chainSetup("TestForEachBlock",
+		&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("failed to setup chain instance: %v", err)
+	}
+	defer tearDown()
+
+	blocks, err := loadBlocks("blk_0_to_14131.dat")
+	if err != nil {
+		t.Fatalf("failed to read block from file. %v", err)
+	}
+
+	const numBlocks = 500
+	for _, block := range blocks[1 : numBlocks+1] {
+		_, _, err := chain.ProcessBlock(block, BFNone)
+		if err != nil {
+			t.Fatalf("Failed to process block %v(%v). %v",
+				block.Hash(), block.Height(), err)
+		}
+	}
+
+	// Keep track of the outputs created by the visited blocks so the spent
+	// outputs can be checked against them.
+	outputs := make(map[wire.OutPoint]*wire.TxOut)
+	nextHeight := int32(0)
+	var numSpent int
+	err = chain.ForEachBlock(0, numBlocks, func(block *btcutil.Block,
+		stxos []SpentTxOut) error {
+
+		if block.Height() != nextHeight {
+			t.Fatalf("got block %d, want block %d", block.Height(),
+				nextHeight)
+		}
+		if *block.Hash() != *blocks[nextHeight].Hash() {
+			t.Fatalf("block %d: got hash %v, want %v", nextHeight,
+				block.Hash(), blocks[nextHeight].Hash())
+		}
+		nextHeight++
+
+		var i int
+		for txIdx, tx := range block.Transactions() {
+			if txIdx != 0 {
+				for _, txIn := range tx.MsgTx().TxIn {
+					want := outputs[txIn.PreviousOutPoint]
+					if want == nil || i >= len(stxos) {
+						t.Fatalf("block %d: unexpected spent "+
+							"output %v", block.Height(),
+							txIn.PreviousOutPoint)
+					}
+					stxo := &stxos[i]
+					if stxo.Amount != want.Value ||
+						!reflect.DeepEqual(stxo.PkScript,
+							want.PkScript) {
+
+						t.Fatalf("block %d: got spent output "+
+							"%v, want %v", block.Height(),
+							stxo, want)
+					}
+					i++
+				}
+			}
+
+			for outIdx, txOut := range tx.MsgTx().TxOut {
+				op := wire.OutPoint{Hash: *tx.Hash(),
+					Index: uint32(outIdx)}
+				outputs[op] = txOut
+			}
+		}
+		if i != len(stxos) {
+			t.Fatalf("block %d: got %d spent outputs, want %d",
+				block.Height(), len(stxos), i)
+		}
+		numSpent += i
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachBlock: unexpected error: %v", err)
+	}
+	if nextHeight != numBlocks+1 {
+		t.Fatalf("ForEachBlock: visited %d blocks, want %d", nextHeight,
+			numBlocks+1)
+	}
+	if numSpent == 0 {
+		t.Fatal("ForEachBlock: no spent outputs were visited")
+	}
+
+	// Errors returned by the function stop the iteration.
+	errStop := fmt.Errorf("stop")
+	var visited int
+	err = chain.ForEachBlock(10, 20, func(*btcutil.Block, []SpentTxOut) error {
+		visited++
+		return errStop
+	})
+	if err != errStop || visited != 1 {
+		t.Fatalf("ForEachBlock: got error %v after %d blocks, want %v "+
+			"after 1 block", err, visited, errStop)
+	}
+
+	// Invalid ranges and blocks which aren't in the main chain are reported
+	// as errors.
+	noop := func(*btcutil.Block, []SpentTxOut) error { return nil }
+	for _, test := range []struct{ from, to int32 }{
+		{-1, 10},
+		{20, 10},
+		{numBlocks, numBlocks + 1},
+	} {
+		if err := chain.ForEachBlock(test.from, test.to, noop); err == nil {
+			t.Fatalf("ForEachBlock(%d, %d): expected error",
+				test.from, test.to)
+		}
+	}
+}
+
+// TestForEachUtxo ensures ForEachUtxo visits the outputs of the main chain
+// which are unspent as of the best block, including those which are still only
+// in the utxo cache.
+func TestForEachUtxo(t *testing.T) {
+	chain, tearDown, err := chainSetup("TestForEachUtxo",
+		&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("failed to setup chain instance: %v", err)
+	}
+	defer tearDown()
+
+	blocks, err := loadBlocks("blk_0_to_14131.dat")
+	if err != nil {
+		t.Fatalf("failed to read block from file. %v", err)
+	}
+
+	// Keep track of the unspent outputs created by the processed blocks.
+	// The output of the genesis block can't be spent and isn't part of the
+	// utxo set.
+	const numBlocks = 500
+	unspent := make(map[wire.OutPoint]*wire.TxOut)
+	heights := make(map[wire.OutPoint]int32)
+	for _, block := range blocks[1 : numBlocks+1] {
+		_, _, err := chain.ProcessBlock(block, BFNone)
+		if err != nil {
+			t.Fatalf("Failed to process block %v(%v). %v",
+				block.Hash(), block.Height(), err)
+		}
+
+		for txIdx, tx := range block.Transactions() {
+			if txIdx != 0 {
+				for _, txIn := range tx.MsgTx().TxIn {
+					delete(unspent, txIn.PreviousOutPoint)
+				}
+			}
+			for outIdx, txOut := range tx.MsgTx().TxOut {
+				op := wire.OutPoint{Hash: *tx.Hash(),
+					Index: uint32(outIdx)}
+				unspent[op] = txOut
+				heights[op] = block.Height()
+			}
+		}
+	}
+
+	var visited int
+	err = chain.ForEachUtxo(func(outpoint wire.OutPoint, entry *UtxoEntry) error {
+		want := unspent[outpoint]
+		if want == nil {
+			t.Fatalf("unexpected unspent output %v", outpoint)
+		}
+		if entry.Amount() != want.Value ||
+			!reflect.DeepEqual(entry.PkScript(), want.PkScript) ||
+			entry.BlockHeight() != heights[outpoint] {
+
+			t.Fatalf("unspent output %v: got %v at height %d, want "+
+				"%v at height %d", outpoint, entry,
+				entry.BlockHeight(), want, heights[outpoint])
+		}
+		visited++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachUtxo: unexpected error: %v", err)
+	}
+	if visited != len(unspent) {
+		t.Fatalf("ForEachUtxo: visited %d unspent outputs, want %d",
+			visited, len(unspent))
+	}
+
+	// Errors returned by the function stop the iteration.
+	errStop := fmt.Errorf("stop")
+	visited = 0
+	err = chain.ForEachUtxo(func(wire.OutPoint, *UtxoEntry) error {
+		visited++
+		return errStop
+	})
+	if err != errStop || visited != 1 {
+		t.Fatalf("ForEachUtxo: got error %v after %d outputs, want %v "+
+			"after 1 output", err, visited, errStop)
+	}
+}
+
+// TestFetchUtxoSetStats ensures the statistics about the utxo set match the
+// unspent outputs and the serialized hash is computed over the outputs ordered
+// by outpoint.
+func TestFetchUtxoSetStats(t *testing.T) {
+	chain, tearDown, err := chainSetup("TestFetchUtxoSetStats",
+		&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("failed to setup chain instance: %v", err)
+	}
+	defer tearDown()
+
+	blocks, err := loadBlocks("blk_0_to_14131.dat")
+	if err != nil {
+		t.Fatalf("failed to read block from file. %v", err)
+	}
+	for _, block := range blocks[1:500] {
+		_, _, err := chain.ProcessBlock(block, BFNone)
+		if err != nil {
+			t.Fatalf("Failed to process block %v(%v). %v",
+				block.Hash(), block.Height(), err)
+		}
+	}
+
+	// Collect the unspent outputs and serialize them by hand in the order
+	// of their outpoints.
+	type utxo struct {
+		outpoint wire.OutPoint
+		entry    *UtxoEntry
+	}
+	var utxos []utxo
+	err = chain.ForEachUtxo(func(outpoint wire.OutPoint, entry *UtxoEntry) error {
+		utxos = append(utxos, utxo{outpoint, entry})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachUtxo: unexpected error: %v", err)
+	}
+	slices.SortFunc(utxos, func(a, b utxo) int {
+		if c := bytes.Compare(a.outpoint.Hash[:], b.outpoint.Hash[:]); c != 0 {
+			return c
+		}
+		return int(a.outpoint.Index) - int(b.outpoint.Index)
+	})
+
+	best := chain.BestSnapshot()
+	var buf bytes.Buffer
+	buf.Write(best.Hash[:])
+	writeVLQ := func(n uint64) {
+		serialized := make([]byte, serializeSizeVLQ(n))
+		putVLQ(serialized, n)
+		buf.Write(serialized)
+	}
+	var wantTxns, wantTotal, wantP2PK int64
+	for i, u := range utxos {
+		if i == 0 || u.outpoint.Hash != utxos[i-1].outpoint.Hash {
+			if i != 0 {
+				writeVLQ(0)
+			}
+			buf.Write(u.outpoint.Hash[:])
+			code := uint64(u.entry.BlockHeight()) * 2
+			if u.entry.IsCoinBase() {
+				code++
+			}
+			writeVLQ(code)
+			wantTxns++
+		}
+		writeVLQ(uint64(u.outpoint.Index) + 1)
+		wire.WriteVarBytes(&buf, 0, u.entry.PkScript())
+		writeVLQ(uint64(u.entry.Amount()))
+		wantTotal += u.entry.Amount()
+		if txscript.GetScriptClass(u.entry.PkScript()) == txscript.PubKeyTy {
+			wantP2PK += u.entry.Amount()
+		}
+	}
+	writeVLQ(0)
+	wantHash := chainhash.DoubleHashH(buf.Bytes())
+
+	stats, err := chain.FetchUtxoSetStats()
+	if err != nil {
+		t.Fatalf("FetchUtxoSetStats: unexpected error: %v", err)
+	}
+	if stats.Height != best.Height || stats.Hash != best.Hash {
+		t.Fatalf("got stats for block %v (%d), want %v (%d)",
+			stats.Hash, stats.Height, best.Hash, best.Height)
+	}
+	if stats.TxOuts != int64(len(utxos)) || stats.Transactions != wantTxns ||
+		stats.TotalAmount != wantTotal {
+
+		t.Fatalf("got %d outputs of %d transactions with %d, want %d "+
+			"outputs of %d transactions with %d", stats.TxOuts,
+			stats.Transactions, stats.TotalAmount, len(utxos),
+			wantTxns, wantTotal)
+	}
+	if stats.SerializedHash != wantHash {
+		t.Fatalf("got serialized hash %v, want %v",
+			stats.SerializedHash, wantHash)
+	}
+
+	// The outputs of the script classes add up to all outputs.  The early
+	// blocks pay to public keys.
+	var classTxOuts, classTotal int64
+	for _, classStats := range stats.ScriptClasses {
+		classTxOuts += classStats.TxOuts
+		classTotal += classStats.Amount
+	}
+	if classTxOuts != stats.TxOuts || classTotal != stats.TotalAmount {
+		t.Fatalf("script classes add up to %d outputs with %d, want %d "+
+			"outputs with %d", classTxOuts, classTotal, stats.TxOuts,
+			stats.TotalAmount)
+	}
+	p2pk := stats.ScriptClasses[txscript.PubKeyTy]
+	if p2pk == nil || p2pk.Amount != wantP2PK || wantP2PK == 0 {
+		t.Fatalf("got pubkey stats %+v, want amount %d", p2pk, wantP2PK)
+	}
+}
+
+// TestUtxoSetStatsClaims ensures the unspent outputs with a claim, support or
+// update prefix are counted by the kind of prefix and in the nonstandard script
+// class.
+func TestUtxoSetStatsClaims(t *testing.T) {
+	p2pkh := []byte{
+		txscript.OP_DUP, txscript.OP_HASH160, txscript.OP_DATA_20,
+		0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a,
+		0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10, 0x11, 0x12, 0x13, 0x14,
+		txscript.OP_EQUALVERIFY, txscript.OP_CHECKSIG,
+	}
+	claimID := bytes.Repeat([]byte{0x01}, txscript.ClaimIDSize)
+	withPrefix := func(prefix []byte, err error) []byte {
+		if err != nil {
+			t.Fatalf("unable to create claim prefix: %v", err)
+		}
+		return append(prefix, p2pkh...)
+	}
+	claim := withPrefix(txscript.NewClaimScript([]byte("name"),
+		[]byte("value")))
+	support := withPrefix(txscript.NewSupportScript([]byte("name"),
+		claimID, nil))
+	update := withPrefix(txscript.NewUpdateScript([]byte("name"),
+		claimID, []byte("value")))
+
+	stats := &UtxoSetStats{
+		ScriptClasses: make(map[txscript.ScriptClass]*ScriptClassStats),
+	}
+	stats.addScript(p2pkh, 1)
+	stats.addScript(claim, 2)
+	stats.addScript(claim, 4)
+	stats.addScript(support, 8)
+	stats.addScript(update, 16)
+
+	tests := []struct {
+		name  string
+		stats ScriptClassStats
+		want  ScriptClassStats
+	}{
+		{"claims", stats.Claims, ScriptClassStats{2, 6}},
+		{"supports", stats.Supports, ScriptClassStats{1, 8}},
+		{"updates", stats.Updates, ScriptClassStats{1, 16}},
+		{"pubkeyhash", *stats.ScriptClasses[txscript.PubKeyHashTy],
+			ScriptClassStats{1, 1}},
+		{"nonstandard", *stats.ScriptClasses[txscript.NonStandardTy],
+			ScriptClassStats{4, 30}},
+	}
+	for _, test := range tests {
+		if test.stats != test.want {
+			t.Errorf("%s: got %+v, want %+v", test.name, test.stats,
+				test.want)
+		}
+	}
+}
+
+// TestBlockBenchmarks ensures the benchmarks of the most recent blocks
+// connected to the main chain are kept.
+func TestBlockBenchmarks(t *testing.T) {
+	chain, tearDown, err := chainSetup("TestBlockBenchmarks",
+		&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("failed to setup chain instance: %v", err)
+	}
+	defer tearDown()
+
+	blocks, err := loadBlocks("blk_0_to_14131.dat")
+	if err != nil {
+		t.Fatalf("failed to read block from file. %v", err)
+	}
+
+	const numBlocks = maxBlockBenchmarks + 80
+	for _, block := range blocks[1 : numBlocks+1] {
+		_, _, err := chain.ProcessBlock(block, BFNone)
+		if err != nil {
+			t.Fatalf("Failed to process block %v(%v). %v",
+				block.Hash(), block.Height(), err)
+		}
+	}
+
+	// Only the most recent blocks are kept ordered from the oldest to the
+	// newest.
+	benchmarks := chain.BlockBenchmarks()
+	if len(benchmarks) != maxBlockBenchmarks {
+		t.Fatalf("got %d benchmarks, want %d", len(benchmarks),
+			maxBlockBenchmarks)
+	}
+	var numInputs int
+	for i, bench := range benchmarks {
+		height := int32(numBlocks - maxBlockBenchmarks + 1 + i)
+		block := blocks[height]
+		if bench.Height != height || bench.Hash != *block.Hash() {
+			t.Fatalf("benchmark %d: got block %v (height %d), want "+
+				"%v (height %d)", i, bench.Hash, bench.Height,
+				block.Hash(), height)
+		}
+		if bench.NumTxns != len(block.Transactions()) {
+			t.Fatalf("benchmark %d: got %d transactions, want %d",
+				i, bench.NumTxns, len(block.Transactions()))
+		}
+		total := bench.Check + bench.Store + bench.LoadInputs +
+			bench.Scripts + bench.UtxoUpdate + bench.Write +
+			bench.Index + bench.Flush
+		if bench.Total != total || bench.Check == 0 ||
+			bench.Store == 0 || bench.Write == 0 {
+
+			t.Fatalf("benchmark %d: unexpected durations %+v", i,
+				bench)
+		}
+		numInputs += bench.NumInputs
+	}
+	if numInputs == 0 {
+		t.Fatal("no inputs were validated")
+	}
+}
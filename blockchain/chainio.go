@@ -63,6 +63,11 @@ var (
 	// transactions outputs that are spent in each block.
 	spendJournalBucketName = []byte("spendjournal")
 
+	// spendJournalPruneHeightKeyName is the name of the db key used to
+	// store the height of the last block of the main chain whose spend
+	// journal has been pruned.
+	spendJournalPruneHeightKeyName = []byte("spendjournalpruneheight")
+
 	// utxoSetVersionKeyName is the name of the db key used to store the
 	// version of the utxo set currently in the database.
 	utxoSetVersionKeyName = []byte("utxosetversion")
@@ -267,6 +272,11 @@ func (b *BlockChain) FetchSpendJournal(targetBlock *btcutil.Block) ([]SpentTxOut
 	b.chainLock.RLock()
 	defer b.chainLock.RUnlock()
 
+	node := b.index.LookupNode(targetBlock.Hash())
+	if node != nil && b.spendJournalPruned(node) {
+		return nil, spendJournalPrunedError(node)
+	}
+
 	var spendEntries []SpentTxOut
 	err := b.db.View(func(dbTx database.Tx) error {
 		var err error
@@ -498,6 +508,26 @@ func dbRemoveSpendJournalEntry(dbTx database.Tx, blockHash *chainhash.Hash) erro
 	return spendBucket.Delete(blockHash[:])
 }
 
+// dbPutSpendJournalPruneHeight uses an existing database transaction to store
+// the height of the last block of the main chain whose spend journal has been
+// pruned.
+func dbPutSpendJournalPruneHeight(dbTx database.Tx, height int32) error {
+	var serialized [4]byte
+	byteOrder.PutUint32(serialized[:], uint32(height))
+	return dbTx.Metadata().Put(spendJournalPruneHeightKeyName, serialized[:])
+}
+
+// dbFetchSpendJournalPruneHeight uses an existing database transaction to
+// retrieve the height of the last block of the main chain whose spend journal
+// has been pruned.  It is zero when no spend journals have been pruned.
+func dbFetchSpendJournalPruneHeight(dbTx database.Tx) int32 {
+	serialized := dbTx.Metadata().Get(spendJournalPruneHeightKeyName)
+	if len(serialized) != 4 {
+		return 0
+	}
+	return int32(byteOrder.Uint32(serialized))
+}
+
 // dbPruneSpendJournalEntry uses an existing database transaction to remove all
 // the spend journal entries for the pruned blocks.
 func dbPruneSpendJournalEntry(dbTx database.Tx, blockHashes []chainhash.Hash) error {
@@ -945,6 +975,19 @@ func dbFetchHashByHeight(dbTx database.Tx, height int32) (*chainhash.Hash, error
 	return &hash, nil
 }
 
+// DBFetchMainChainHash uses an existing database transaction to retrieve the
+// hash of the block at the provided height in the main chain, or nil when the
+// main chain is shorter.  Unlike BlockHashByHeight, the result is consistent
+// with the rest of the state in the database transaction, which allows callers
+// to keep their own state in the database in step with the main chain.
+func DBFetchMainChainHash(dbTx database.Tx, height int32) *chainhash.Hash {
+	hash, err := dbFetchHashByHeight(dbTx, height)
+	if err != nil {
+		return nil
+	}
+	return hash
+}
+
 // -----------------------------------------------------------------------------
 // The best chain state consists of the best block hash and height, the total
 // number of transactions up to and including those in the best block, and the
@@ -1476,3 +1519,171 @@ func (b *BlockChain) BlockByHash(hash *chainhash.Hash) (*btcutil.Block, error) {
 	})
 	return block, err
 }
+
+// fetchBlockAndSpendJournal loads the block at the given height in the main
+// chain along with the outputs spent by its transactions.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) fetchBlockAndSpendJournal(height int32) (*btcutil.Block, []SpentTxOut, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	node := b.bestChain.NodeByHeight(height)
+	if node == nil {
+		str := fmt.Sprintf("no block at height %d exists", height)
+		return nil, nil, errNotInMainChain(str)
+	}
+	if b.spendJournalPruned(node) {
+		return nil, nil, spendJournalPrunedError(node)
+	}
+
+	var block *btcutil.Block
+	var stxos []SpentTxOut
+	err := b.db.View(func(dbTx database.Tx) error {
+		var err error
+		block, err = dbFetchBlockByNode(dbTx, node)
+		if err != nil {
+			return err
+		}
+
+		stxos, err = dbFetchSpendJournalEntry(dbTx, block)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return block, stxos, nil
+}
+
+// ForEachBlock calls the passed function with each block of the main chain from
+// the from height up to and including the to height in order.  The function is
+// also passed the outputs spent by the block's transactions, which are in the
+// order of the inputs of all of the transactions except the coinbase, so the
+// previous outputs are available without looking up the transactions which
+// created them.
+//
+// The iteration stops at the first error returned by the function, which is
+// then returned.  An error is also returned when a block or its spend journal
+// has been pruned or when the main chain is reorganized below the height of
+// the next block during the iteration.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ForEachBlock(from, to int32, fn func(block *btcutil.Block, stxos []SpentTxOut) error) error {
+	if from < 0 || from > to {
+		return fmt.Errorf("invalid block range from %d to %d", from, to)
+	}
+
+	var prevHash *chainhash.Hash
+	for height := from; height <= to; height++ {
+		block, stxos, err := b.fetchBlockAndSpendJournal(height)
+		if err != nil {
+			return err
+		}
+
+		// The chain lock isn't held while the function is called, so the
+		// next block has to be checked to still extend the previous one.
+		header := &block.MsgBlock().Header
+		if prevHash != nil && header.PrevBlock != *prevHash {
+			return fmt.Errorf("the main chain was reorganized while "+
+				"iterating to block %d", height)
+		}
+		prevHash = block.Hash()
+
+		if err := fn(block, stxos); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ForEachUtxo calls the passed function with the outpoint and the entry of each
+// unspent transaction output of the main chain as of the best block.  The utxo
+// cache is flushed to the database first and the outputs are read from a
+// snapshot of the database, so the utxo set doesn't change while it is
+// iterated even though the chain lock is only held while flushing.
+//
+// The iteration stops at the first error returned by the function, which is
+// then returned.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ForEachUtxo(fn func(outpoint wire.OutPoint, entry *UtxoEntry) error) error {
+	dbTx, _, err := b.utxoSetSnapshot()
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback()
+
+	return dbForEachUtxo(dbTx, func(outpoint wire.OutPoint,
+		entry *UtxoEntry, _ int) error {
+
+		return fn(outpoint, entry)
+	})
+}
+
+// utxoSetSnapshot flushes the utxo cache to the database and returns a
+// read-only database transaction, which is a snapshot of the utxo set as of the
+// returned best state.  The chain lock is held until the transaction is opened
+// so no block is connected in between.  The caller must roll the transaction
+// back when done with it.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) utxoSetSnapshot() (database.Tx, *BestState, error) {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	best := b.BestSnapshot()
+	err := b.db.Update(func(dbTx database.Tx) error {
+		return b.utxoCache.flush(dbTx, FlushRequired, best)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dbTx, err := b.db.Begin(false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dbTx, best, nil
+}
+
+// dbForEachUtxo calls the passed function with the outpoint, the entry and the
+// size of the database key and value of each unspent transaction output stored
+// in the utxo set bucket.  The outputs are visited in the order of their keys,
+// so all outputs of a transaction are visited in a row in the order of their
+// indexes.
+//
+// The iteration stops at the first error returned by the function, which is
+// then returned.
+func dbForEachUtxo(dbTx database.Tx, fn func(outpoint wire.OutPoint, entry *UtxoEntry, diskSize int) error) error {
+	cursor := dbTx.Metadata().Bucket(utxoSetBucketName).Cursor()
+	for ok := cursor.First(); ok; ok = cursor.Next() {
+		// The key is the hash of the transaction followed by the
+		// VLQ-encoded index of the output.
+		key := cursor.Key()
+		var outpoint wire.OutPoint
+		copy(outpoint.Hash[:], key)
+		index, _ := deserializeVLQ(key[chainhash.HashSize:])
+		outpoint.Index = uint32(index)
+
+		serialized := cursor.Value()
+		entry, err := deserializeUtxoEntry(serialized)
+		if err != nil {
+			if isDeserializeErr(err) {
+				return database.Error{
+					ErrorCode: database.ErrCorruption,
+					Description: fmt.Sprintf("corrupt utxo "+
+						"entry for %v: %v", outpoint, err),
+				}
+			}
+			return err
+		}
+
+		err = fn(outpoint, entry, len(key)+len(serialized))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
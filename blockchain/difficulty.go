@@ -230,6 +230,48 @@ func calcNextRequiredDifficulty(lastNode HeaderCtx, newBlockTime time.Time,
 	return newTargetBits, nil
 }
 
+// permittedDifficultyTransition returns whether the difficulty of a block at
+// the passed height may change from the passed bits of its parent to the passed
+// bits of the block under the difficulty retarget rules.  It only needs the
+// bits, rather than the timestamps of the chain, so it can be used to check
+// headers which are not stored, such as while presyncing them.  Any transition
+// is permitted on networks which allow the difficulty to be reduced to the
+// minimum or don't retarget.
+//
+// It matches PermittedDifficultyTransition of the reference implementation.
+func permittedDifficultyTransition(c ChainCtx, height int32, oldBits,
+	newBits uint32) bool {
+
+	params := c.ChainParams()
+	if params.ReduceMinDifficulty || params.PoWNoRetargeting {
+		return true
+	}
+
+	// The difficulty can only change at a retarget interval.
+	if height%c.BlocksPerRetarget() != 0 {
+		return oldBits == newBits
+	}
+
+	// At a retarget interval, the new target must be within the bounds the
+	// adjustment is limited to, rounded to the compact representation the
+	// same way the new target is.
+	oldTarget := CompactToBig(oldBits)
+	targetTimespan := int64(params.TargetTimespan / time.Second)
+	boundTarget := func(timespan int64) *big.Int {
+		target := new(big.Int).Mul(oldTarget, big.NewInt(timespan))
+		target.Div(target, big.NewInt(targetTimespan))
+		if target.Cmp(params.PowLimit) > 0 {
+			target.Set(params.PowLimit)
+		}
+		return CompactToBig(BigToCompact(target))
+	}
+	newTarget := CompactToBig(newBits)
+	if newTarget.Cmp(boundTarget(c.MaxRetargetTimespan())) > 0 {
+		return false
+	}
+	return newTarget.Cmp(boundTarget(c.MinRetargetTimespan())) >= 0
+}
+
 // CalcNextRequiredDifficulty calculates the required difficulty for the block
 // after the end of the current best chain based on the difficulty retarget
 // rules.
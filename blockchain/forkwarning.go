@@ -0,0 +1,132 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+)
+
+const (
+	// invalidChainWarningBlocks is the number of blocks worth of work at the
+	// difficulty of the best block an invalid chain must have beyond the
+	// main chain to be warned about.
+	invalidChainWarningBlocks = 6
+
+	// forkWarningBlocks is the number of blocks worth of work at the
+	// difficulty of the best block a side chain must have after the point
+	// it forks from the main chain to be warned about.
+	forkWarningBlocks = 7
+
+	// forkWarningMaxDepth is the maximum number of blocks the tip of a side
+	// chain may be below the best block for the side chain to be warned
+	// about.  Older side chains are no longer relevant.
+	forkWarningMaxDepth = 72
+)
+
+// ChainWarning returns a warning about an invalid chain with substantially
+// more work than the main chain or about a long side chain, either of which
+// indicates the network may have split due to a disagreement about the
+// consensus rules.  An empty string is returned when there is nothing to warn
+// about.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ChainWarning() string {
+	b.chainLock.RLock()
+	warning := b.chainWarning
+	b.chainLock.RUnlock()
+	return warning
+}
+
+// checkForkWarning keeps track of the invalid block with the most work and the
+// most recent side chain with a significant amount of work given the passed
+// block node, which is known to be invalid when the invalid flag is set.  The
+// chain warning is then updated, which is logged and sent as a
+// NTChainWarning notification when it changes.
+//
+// No warnings are given until the chain is current.
+//
+// This function MUST be called with the chain lock held (for writes).
+func (b *BlockChain) checkForkWarning(node *blockNode, invalid bool) {
+	if !b.isCurrent() {
+		return
+	}
+
+	tip := b.bestChain.Tip()
+	blockWork := CalcWork(tip.bits)
+	switch {
+	case invalid:
+		if b.bestInvalid == nil ||
+			node.workSum.Cmp(b.bestInvalid.workSum) > 0 {
+
+			b.bestInvalid = node
+		}
+
+	case !b.bestChain.Contains(node):
+		fork := b.bestChain.FindFork(node)
+		if fork == nil {
+			break
+		}
+		minWork := new(big.Int).Mul(blockWork,
+			big.NewInt(forkWarningBlocks))
+		branchWork := new(big.Int).Sub(node.workSum, fork.workSum)
+		if branchWork.Cmp(minWork) >= 0 && (b.forkTip == nil ||
+			node.workSum.Cmp(b.forkTip.workSum) > 0) {
+
+			b.forkTip = node
+		}
+	}
+
+	// Forget the invalid block once it has been reconsidered and the side
+	// chain once it has become part of the main chain, has been found to be
+	// invalid, or is too old to matter.
+	if b.bestInvalid != nil {
+		n := b.index.LookupNode(&b.bestInvalid.hash)
+		if n != nil && !b.index.NodeStatus(n).KnownInvalid() {
+			b.bestInvalid = nil
+		}
+	}
+	if b.forkTip != nil && (b.bestChain.Contains(b.forkTip) ||
+		b.index.NodeStatus(b.forkTip).KnownInvalid() ||
+		tip.height-b.forkTip.height > forkWarningMaxDepth) {
+
+		b.forkTip = nil
+	}
+
+	var warning string
+	invalidWork := new(big.Int).Mul(blockWork,
+		big.NewInt(invalidChainWarningBlocks))
+	invalidWork.Add(invalidWork, tip.workSum)
+	switch {
+	case b.bestInvalid != nil && b.bestInvalid.workSum.Cmp(invalidWork) > 0:
+		warning = fmt.Sprintf("Found an invalid chain with more work "+
+			"than the main chain at block %v (height %d) -- the "+
+			"network may have split due to a disagreement about the "+
+			"consensus rules and this node may need to be upgraded",
+			b.bestInvalid.hash, b.bestInvalid.height)
+
+	case b.forkTip != nil:
+		fork := b.bestChain.FindFork(b.forkTip)
+		warning = fmt.Sprintf("Found a side chain of %d blocks which "+
+			"forks from the main chain at height %d -- the network "+
+			"may have split", b.forkTip.height-fork.height,
+			fork.height)
+	}
+	if warning == b.chainWarning {
+		return
+	}
+	b.chainWarning = warning
+
+	if warning != "" {
+		log.Warnf("%s", warning)
+	} else {
+		log.Infof("The fork of the main chain that was warned about " +
+			"has been resolved")
+	}
+
+	b.chainLock.Unlock()
+	b.sendNotification(NTChainWarning, warning)
+	b.chainLock.Lock()
+}
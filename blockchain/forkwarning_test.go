@@ -0,0 +1,107 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/v2"
+)
+
+// TestForkWarning ensures long side chains and invalid chains with more work
+// than the main chain are warned about and that the warning is cleared once the
+// fork is resolved.
+func TestForkWarning(t *testing.T) {
+	params := chaincfg.RegressionNetParams
+	chain := newFakeChain(&params)
+
+	var warnings []string
+	chain.Subscribe(func(n *Notification) {
+		if n.Type == NTChainWarning {
+			warnings = append(warnings, n.Data.(string))
+		}
+	})
+
+	// addNodes adds the given number of recent block nodes on top of the
+	// passed parent to the block index.
+	addNodes := func(parent *blockNode, numNodes int) []*blockNode {
+		nodes := make([]*blockNode, 0, numNodes)
+		for i := 0; i < numNodes; i++ {
+			node := newFakeNode(parent, 1, params.PowLimitBits,
+				time.Now())
+			chain.index.AddNode(node)
+			nodes = append(nodes, node)
+			parent = node
+		}
+		return nodes
+	}
+	checkForkWarning := func(node *blockNode, invalid bool) string {
+		chain.chainLock.Lock()
+		chain.checkForkWarning(node, invalid)
+		chain.chainLock.Unlock()
+		return chain.ChainWarning()
+	}
+
+	// Create a main chain with the following structure.
+	// 	genesis -> 1 -> 2 -> ... -> 20
+	mainNodes := addNodes(chain.bestChain.Genesis(), 20)
+	chain.bestChain.SetTip(tstTip(mainNodes))
+
+	// Short side chains are not warned about.
+	shortFork := addNodes(mainNodes[14], 3)
+	if warning := checkForkWarning(tstTip(shortFork), false); warning != "" {
+		t.Fatalf("unexpected warning for a short side chain: %q",
+			warning)
+	}
+
+	// A side chain with at least seven blocks worth of work is.
+	//	genesis -> ... -> 10 -> 11  -> ... -> 20
+	//	                   \-> 11a -> ... -> 18a
+	longFork := addNodes(mainNodes[9], 8)
+	warning := checkForkWarning(tstTip(longFork), false)
+	if !strings.Contains(warning, "side chain of 8 blocks") ||
+		!strings.Contains(warning, "at height 10") {
+
+		t.Fatalf("unexpected warning for a long side chain: %q",
+			warning)
+	}
+
+	// An invalid chain with more than six blocks worth of work beyond the
+	// main chain takes precedence.  Invalid chains with less work are not
+	// warned about.
+	invalidNodes := addNodes(tstTip(mainNodes), 7)
+	for _, node := range invalidNodes {
+		chain.index.SetStatusFlags(node, statusValidateFailed)
+	}
+	warning = checkForkWarning(invalidNodes[5], true)
+	if strings.Contains(warning, "invalid chain") {
+		t.Fatalf("unexpected warning for an invalid chain with six "+
+			"blocks of work: %q", warning)
+	}
+	warning = checkForkWarning(invalidNodes[6], true)
+	if !strings.Contains(warning, "invalid chain") ||
+		!strings.Contains(warning, invalidNodes[6].hash.String()) {
+
+		t.Fatalf("unexpected warning for an invalid chain: %q",
+			warning)
+	}
+
+	// The warnings are cleared once the main chain has enough work again
+	// and the side chain is too old to matter.
+	mainNodes = append(mainNodes, addNodes(tstTip(mainNodes),
+		forkWarningMaxDepth)...)
+	chain.bestChain.SetTip(tstTip(mainNodes))
+	if warning := checkForkWarning(tstTip(mainNodes), false); warning != "" {
+		t.Fatalf("unexpected warning after the fork was resolved: %q",
+			warning)
+	}
+
+	// Each change of the warning is sent as a notification.
+	if len(warnings) != 3 || warnings[2] != "" {
+		t.Fatalf("unexpected warning notifications: %q", warnings)
+	}
+}
@@ -0,0 +1,419 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/wire/v2"
+)
+
+const (
+	// headerCommitmentPeriod is the number of headers between the
+	// commitments stored while presyncing a chain of headers.
+	headerCommitmentPeriod = 600
+
+	// redownloadBufferSize is the number of redownloaded headers which are
+	// kept in memory until enough commitments following them have been
+	// verified.  The buffer spans 24 commitments, so a peer which sends a
+	// different chain when redownloading has a chance of about 2^-24 to
+	// get a header released before being detected.
+	redownloadBufferSize = 24 * headerCommitmentPeriod
+
+	// headersSyncWorkBufferBlocks is the number of blocks, at the
+	// difficulty of the best header, the work of a chain of headers may be
+	// below the work of the best header chain and still be stored without
+	// presyncing it.
+	headersSyncWorkBufferBlocks = 144
+
+	// maxHeadersPerSecond is the maximum rate at which headers can be
+	// created on a valid chain.  It bounds the number of commitments
+	// stored while presyncing, and therefore the memory used, since a
+	// chain can't be longer than permitted by the time elapsed since the
+	// header it builds on.
+	maxHeadersPerSecond = 6
+)
+
+// headersSyncPhase describes the phase of a HeadersSync.
+type headersSyncPhase int
+
+const (
+	// headersSyncPresync is the phase in which the headers are only
+	// checked and their cumulative work is tallied.
+	headersSyncPresync headersSyncPhase = iota
+
+	// headersSyncRedownload is the phase in which the headers are
+	// downloaded again and released to be stored.
+	headersSyncRedownload
+
+	// headersSyncDone is the phase of a HeadersSync that ended.
+	headersSyncDone
+)
+
+// HeadersSyncResult is the result of processing a batch of headers with a
+// HeadersSync.
+type HeadersSyncResult struct {
+	// Headers are the headers which have been released to be processed
+	// with ProcessBlockHeader, in order.
+	Headers []*wire.BlockHeader
+
+	// Locator is the block locator to request the next batch of headers
+	// from the peer with.  It is nil once the sync ended.
+	Locator BlockLocator
+}
+
+// HeadersSync syncs a chain of headers from a peer whose cumulative work is
+// below the work required to store it without the headers being stored until
+// the chain is known to have enough work.
+//
+// The sync happens in two phases.  During the presync phase, the headers are
+// only checked to connect and to have valid proof of work while their
+// cumulative work is tallied.  Only one commitment bit, derived from a salted
+// hash of the header, is stored per headerCommitmentPeriod headers, so the
+// memory used does not depend on the number of headers sent.  Once the chain
+// reaches the required work, the same headers are downloaded again during the
+// redownload phase and checked against the commitments.  The redownloaded
+// headers are released to be stored once enough commitments following them
+// have been verified, or as soon as the redownloaded chain itself reached the
+// required work.
+//
+// A HeadersSync is not safe for concurrent access.
+type HeadersSync struct {
+	chainCtx         ChainCtx
+	powLimit         *big.Int
+	threshold        *big.Int
+	commitmentPeriod int32
+	commitmentOffset int32
+	bufferSize       int
+	salt             [8]byte
+	maxCommitments   int64
+	phase            headersSyncPhase
+
+	// startHash is the hash of the known header the chain builds on.
+	startHash   chainhash.Hash
+	startHeight int32
+	startWork   *big.Int
+
+	// commitments holds one bit per headerCommitmentPeriod headers seen
+	// during the presync phase.
+	commitments    []uint64
+	numCommitments int64
+
+	// The following fields track the tip of the chain during the presync
+	// phase.
+	lastHash   chainhash.Hash
+	lastHeight int32
+	lastBits   uint32
+	workSum    *big.Int
+
+	// The following fields track the tip of the chain during the
+	// redownload phase.
+	redownloadHash       chainhash.Hash
+	redownloadHeight     int32
+	redownloadWork       *big.Int
+	redownloadCommitment int64
+	processAll           bool
+	buffer               []*wire.BlockHeader
+}
+
+// newHeadersSync returns a headers sync for a chain building on the passed
+// node which uses the passed commitment period and redownload buffer size.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) newHeadersSync(node *blockNode, commitmentPeriod int32,
+	bufferSize int) (*HeadersSync, error) {
+
+	var random [12]byte
+	if _, err := rand.Read(random[:]); err != nil {
+		return nil, err
+	}
+
+	s := &HeadersSync{
+		chainCtx:         b,
+		powLimit:         b.chainParams.PowLimit,
+		threshold:        b.headersSyncThreshold(),
+		commitmentPeriod: commitmentPeriod,
+		commitmentOffset: int32(binary.LittleEndian.Uint32(random[8:]) %
+			uint32(commitmentPeriod)),
+		bufferSize:  bufferSize,
+		startHash:   node.hash,
+		startHeight: node.height,
+		startWork:   node.workSum,
+		lastHash:    node.hash,
+		lastHeight:  node.height,
+		lastBits:    node.bits,
+		workSum:     new(big.Int).Set(node.workSum),
+	}
+	copy(s.salt[:], random[:8])
+
+	// A valid chain can't have more headers than could have been created
+	// since the header it builds on, so bound the number of commitments
+	// accordingly.
+	maxSeconds := b.timeSource.AdjustedTime().Unix() + MaxTimeOffsetSeconds -
+		CalcPastMedianTime(node).Unix()
+	if maxSeconds < 0 {
+		maxSeconds = 0
+	}
+	s.maxCommitments = maxHeadersPerSecond * maxSeconds /
+		int64(commitmentPeriod)
+
+	return s, nil
+}
+
+// NewHeadersSync returns a headers sync for a chain of headers building on the
+// known header with the passed hash.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) NewHeadersSync(startHash *chainhash.Hash) (*HeadersSync,
+	error) {
+
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	node := b.index.LookupNode(startHash)
+	if node == nil {
+		str := fmt.Sprintf("previous block %s is not known", startHash)
+		return nil, ruleError(ErrPreviousBlockUnknown, str)
+	}
+	return b.newHeadersSync(node, headerCommitmentPeriod,
+		redownloadBufferSize)
+}
+
+// headersSyncThreshold returns the cumulative work a chain of headers must
+// have to be stored without presyncing it.  It is the minimum chain work, or
+// the work of the best header chain minus headersSyncWorkBufferBlocks blocks
+// when that is higher.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) headersSyncThreshold() *big.Int {
+	tip := b.bestHeader.Tip()
+	threshold := new(big.Int).Mul(CalcWork(tip.bits),
+		big.NewInt(headersSyncWorkBufferBlocks))
+	threshold.Sub(tip.workSum, threshold)
+	if b.minimumChainWork != nil && b.minimumChainWork.Cmp(threshold) > 0 {
+		threshold.Set(b.minimumChainWork)
+	}
+	return threshold
+}
+
+// IsLowWorkHeaderChain returns whether the cumulative work of the chain ending
+// with the passed headers is below the work required to store them without
+// presyncing them with a HeadersSync.  The first header must connect to a
+// known header.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) IsLowWorkHeaderChain(headers []*wire.BlockHeader) (bool,
+	error) {
+
+	if len(headers) == 0 {
+		return false, nil
+	}
+
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	prevHash := &headers[0].PrevBlock
+	prevNode := b.index.LookupNode(prevHash)
+	if prevNode == nil {
+		str := fmt.Sprintf("previous block %s is not known", prevHash)
+		return false, ruleError(ErrPreviousBlockUnknown, str)
+	}
+
+	workSum := new(big.Int).Set(prevNode.workSum)
+	for _, header := range headers {
+		workSum.Add(workSum, CalcWork(header.Bits))
+	}
+	return workSum.Cmp(b.headersSyncThreshold()) < 0, nil
+}
+
+// commitment returns the commitment bit for the header with the passed hash.
+func (s *HeadersSync) commitment(hash *chainhash.Hash) bool {
+	var buf [8 + chainhash.HashSize]byte
+	copy(buf[:], s.salt[:])
+	copy(buf[8:], hash[:])
+	return chainhash.HashB(buf[:])[0]&1 == 1
+}
+
+// isCommitmentHeight returns whether a commitment is stored for the header at
+// the passed height.
+func (s *HeadersSync) isCommitmentHeight(height int32) bool {
+	return height%s.commitmentPeriod == s.commitmentOffset
+}
+
+// presyncHeader checks the passed header connects to the presynced chain, has
+// valid proof of work and a difficulty the retarget rules permit following its
+// parent, and adds it to the presynced chain.  Checking the difficulty
+// transitions keeps a peer from presyncing a long chain of headers with a
+// fraction of the work by lowering the difficulty at will.
+func (s *HeadersSync) presyncHeader(header *wire.BlockHeader) error {
+	if header.PrevBlock != s.lastHash {
+		return fmt.Errorf("header %v does not connect to the previous "+
+			"header %v", header.BlockHash(), s.lastHash)
+	}
+	if err := checkProofOfWork(header, s.powLimit, BFNone); err != nil {
+		return err
+	}
+	height := s.lastHeight + 1
+	if !permittedDifficultyTransition(s.chainCtx, height, s.lastBits,
+		header.Bits) {
+
+		return fmt.Errorf("header %v at height %d has difficulty bits "+
+			"%08x which are not permitted after bits %08x",
+			header.BlockHash(), height, header.Bits, s.lastBits)
+	}
+
+	s.lastHash = header.BlockHash()
+	s.lastHeight = height
+	s.lastBits = header.Bits
+	s.workSum.Add(s.workSum, CalcWork(header.Bits))
+	if !s.isCommitmentHeight(s.lastHeight) {
+		return nil
+	}
+
+	if s.numCommitments >= s.maxCommitments {
+		return fmt.Errorf("chain of headers exceeds the maximum length of "+
+			"a valid chain at height %d", s.lastHeight)
+	}
+	if s.numCommitments%64 == 0 {
+		s.commitments = append(s.commitments, 0)
+	}
+	if s.commitment(&s.lastHash) {
+		s.commitments[s.numCommitments/64] |= 1 << (s.numCommitments % 64)
+	}
+	s.numCommitments++
+	return nil
+}
+
+// redownloadHeader checks the passed header connects to the redownloaded chain
+// and matches the commitments stored during the presync phase, and adds it to
+// the redownload buffer.
+func (s *HeadersSync) redownloadHeader(header *wire.BlockHeader) error {
+	hash := header.BlockHash()
+	if header.PrevBlock != s.redownloadHash {
+		return fmt.Errorf("header %v does not connect to the previous "+
+			"header %v", hash, s.redownloadHash)
+	}
+
+	s.redownloadHash = hash
+	s.redownloadHeight++
+	s.redownloadWork.Add(s.redownloadWork, CalcWork(header.Bits))
+	if s.redownloadWork.Cmp(s.threshold) >= 0 {
+		s.processAll = true
+	}
+
+	// The commitments no longer need to be checked once the redownloaded
+	// chain has enough work on its own.
+	if !s.processAll && s.isCommitmentHeight(s.redownloadHeight) {
+		if s.redownloadCommitment >= s.numCommitments {
+			return fmt.Errorf("redownloaded chain of headers is longer "+
+				"than the presynced chain at height %d",
+				s.redownloadHeight)
+		}
+		i := s.redownloadCommitment
+		want := s.commitments[i/64]&(1<<(i%64)) != 0
+		if s.commitment(&hash) != want {
+			return fmt.Errorf("redownloaded header %v at height %d "+
+				"does not match the presynced chain", hash,
+				s.redownloadHeight)
+		}
+		s.redownloadCommitment++
+	}
+
+	s.buffer = append(s.buffer, header)
+	return nil
+}
+
+// releaseHeaders removes and returns the redownloaded headers which may be
+// stored.
+func (s *HeadersSync) releaseHeaders() []*wire.BlockHeader {
+	n := len(s.buffer)
+	if !s.processAll {
+		n -= s.bufferSize
+	}
+	if n <= 0 {
+		return nil
+	}
+	released := s.buffer[:n:n]
+	s.buffer = s.buffer[n:]
+	return released
+}
+
+// ProcessHeaders processes the passed batch of headers received from the peer
+// and returns the headers which may be stored along with the locator to
+// request the next batch with.  The full flag specifies whether the batch
+// holds the maximum number of headers, which means the peer has more headers
+// to send.
+//
+// An error is returned when the peer sent headers which are invalid or which
+// do not match the headers sent during the presync phase, after which the
+// sync ended.  The sync also ends without an error when the peer's chain of
+// headers does not have enough work to be stored.
+func (s *HeadersSync) ProcessHeaders(headers []*wire.BlockHeader,
+	full bool) (*HeadersSyncResult, error) {
+
+	switch s.phase {
+	case headersSyncPresync:
+		for _, header := range headers {
+			if err := s.presyncHeader(header); err != nil {
+				s.phase = headersSyncDone
+				return nil, err
+			}
+			if s.workSum.Cmp(s.threshold) < 0 {
+				continue
+			}
+
+			// The chain has enough work, so download it again from
+			// the start to store it.
+			log.Debugf("Presynced headers to %v(%d) -- "+
+				"redownloading from %v(%d)", s.lastHash,
+				s.lastHeight, s.startHash, s.startHeight)
+			s.phase = headersSyncRedownload
+			s.redownloadHash = s.startHash
+			s.redownloadHeight = s.startHeight
+			s.redownloadWork = new(big.Int).Set(s.startWork)
+			return &HeadersSyncResult{
+				Locator: BlockLocator{&s.startHash},
+			}, nil
+		}
+		if !full {
+			log.Debugf("Chain of headers ending at %v(%d) does not "+
+				"have enough work to be stored", s.lastHash,
+				s.lastHeight)
+			s.phase = headersSyncDone
+			return &HeadersSyncResult{}, nil
+		}
+		return &HeadersSyncResult{Locator: BlockLocator{&s.lastHash}}, nil
+
+	case headersSyncRedownload:
+		for _, header := range headers {
+			if err := s.redownloadHeader(header); err != nil {
+				s.phase = headersSyncDone
+				s.buffer = nil
+				return nil, err
+			}
+		}
+		result := &HeadersSyncResult{Headers: s.releaseHeaders()}
+		if !full {
+			s.phase = headersSyncDone
+			if !s.processAll {
+				s.buffer = nil
+				return nil, fmt.Errorf("redownloaded chain of "+
+					"headers ending at %v(%d) does not have "+
+					"enough work", s.redownloadHash,
+					s.redownloadHeight)
+			}
+			return result, nil
+		}
+		result.Locator = BlockLocator{&s.redownloadHash}
+		return result, nil
+
+	default:
+		return nil, fmt.Errorf("headers sync already ended")
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/v2"
+	"github.com/btcsuite/btcd/wire/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHeadersSync ensures chains of headers with less than the minimum chain
+// work are presynced before being released to be stored, and that peers which
+// send a different chain when redownloading are detected.
+func TestHeadersSync(t *testing.T) {
+	chain, params, tearDown := utxoCacheTestChain("TestHeadersSync")
+	defer tearDown()
+
+	// Store the first 10 headers and require the work of 40 more.
+	headers := chainedHeaders(&params.GenesisBlock.Header, params, 0, 10)
+	for _, header := range headers {
+		_, err := chain.ProcessBlockHeader(header, BFNone, false)
+		require.NoError(t, err)
+	}
+	tip := chain.bestHeader.Tip()
+	headers = chainedHeaders(headers[9], params, 10, 40)
+	require.False(t, mustIsLowWork(t, chain, headers))
+
+	chain.minimumChainWork = new(big.Int).Mul(
+		CalcWork(params.PowLimitBits), big.NewInt(40))
+	chain.minimumChainWork.Add(chain.minimumChainWork, tip.workSum)
+	require.True(t, mustIsLowWork(t, chain, headers[:39]))
+	require.False(t, mustIsLowWork(t, chain, headers))
+
+	// Presync the headers in batches of 8 followed by the redownload in
+	// which the headers are released once 2 commitments following them
+	// were verified.
+	sync, err := chain.newHeadersSync(tip, 5, 10)
+	require.NoError(t, err)
+	for i := 0; i < len(headers); i += 8 {
+		result, err := sync.ProcessHeaders(headers[i:i+8], true)
+		require.NoError(t, err)
+		require.Empty(t, result.Headers)
+		if i+8 < len(headers) {
+			require.Equal(t, headers[i+7].BlockHash(), *result.Locator[0])
+		} else {
+			require.Equal(t, tip.hash, *result.Locator[0])
+		}
+	}
+
+	var released []*wire.BlockHeader
+	wantReleased := []int{0, 6, 8, 8, 18}
+	for i := 0; i < len(headers); i += 8 {
+		full := i+8 < len(headers)
+		result, err := sync.ProcessHeaders(headers[i:i+8], full)
+		require.NoError(t, err)
+		require.Len(t, result.Headers, wantReleased[i/8])
+		require.Equal(t, full, result.Locator != nil)
+		released = append(released, result.Headers...)
+	}
+	require.Equal(t, headers, released)
+	_, err = sync.ProcessHeaders(headers[:1], true)
+	require.Error(t, err)
+
+	for _, header := range released {
+		_, err := chain.ProcessBlockHeader(header, BFNone, false)
+		require.NoError(t, err)
+	}
+	require.Equal(t, headers[39].BlockHash(), chain.bestHeader.Tip().hash)
+
+	// A chain which ends before reaching the threshold is not stored.
+	chain.minimumChainWork.Add(chain.minimumChainWork,
+		chain.bestHeader.Tip().workSum)
+	lowWork := chainedHeaders(headers[39], params, 50, 20)
+	require.True(t, mustIsLowWork(t, chain, lowWork))
+	sync, err = chain.NewHeadersSync(&lowWork[0].PrevBlock)
+	require.NoError(t, err)
+	result, err := sync.ProcessHeaders(lowWork, false)
+	require.NoError(t, err)
+	require.Empty(t, result.Headers)
+	require.Nil(t, result.Locator)
+
+	// A chain of headers which does not connect is rejected.
+	sync, err = chain.NewHeadersSync(&lowWork[0].PrevBlock)
+	require.NoError(t, err)
+	_, err = sync.ProcessHeaders(lowWork[1:], true)
+	require.Error(t, err)
+
+	// A different chain sent when redownloading is detected.
+	chain.minimumChainWork = new(big.Int).Mul(
+		CalcWork(params.PowLimitBits), big.NewInt(70))
+	tip = chain.bestHeader.Tip()
+	other := chainedHeaders(headers[39], params, 50, 20)
+	sync, err = chain.newHeadersSync(tip, 1, 100)
+	require.NoError(t, err)
+	result, err = sync.ProcessHeaders(lowWork, true)
+	require.NoError(t, err)
+	require.Equal(t, tip.hash, *result.Locator[0])
+	_, err = sync.ProcessHeaders(other, true)
+	require.Error(t, err)
+
+	// A chain of headers which changes the difficulty between retarget
+	// intervals is rejected on networks which don't allow it.
+	noMinDiffParams := *params
+	noMinDiffParams.ReduceMinDifficulty = false
+	noMinDiffParams.PoWNoRetargeting = false
+	sync, err = chain.newHeadersSync(tip, 1, 100)
+	require.NoError(t, err)
+	sync.chainCtx = newFakeChain(&noMinDiffParams)
+	_, err = sync.ProcessHeaders(lowWork, true)
+	require.NoError(t, err)
+	sync, err = chain.newHeadersSync(tip, 1, 100)
+	require.NoError(t, err)
+	sync.chainCtx = newFakeChain(&noMinDiffParams)
+	harder := *lowWork[0]
+	harder.Bits = 0x203fffff
+	for checkProofOfWork(&harder, params.PowLimit, BFNone) != nil {
+		harder.Nonce++
+	}
+	_, err = sync.ProcessHeaders([]*wire.BlockHeader{&harder}, true)
+	require.Error(t, err)
+}
+
+// mustIsLowWork returns whether the passed headers form a low-work chain and
+// fails the test on error.
+func mustIsLowWork(t *testing.T, chain *BlockChain,
+	headers []*wire.BlockHeader) bool {
+
+	t.Helper()
+
+	lowWork, err := chain.IsLowWorkHeaderChain(headers)
+	require.NoError(t, err)
+	return lowWork
+}
+
+// TestPermittedDifficultyTransition ensures the difficulty of headers may only
+// change at retarget intervals and within the bounds of the adjustment.
+func TestPermittedDifficultyTransition(t *testing.T) {
+	chain := newFakeChain(&chaincfg.MainNetParams)
+	powLimitBits := chaincfg.MainNetParams.PowLimitBits
+
+	tests := []struct {
+		name    string
+		height  int32
+		oldBits uint32
+		newBits uint32
+		want    bool
+	}{
+		{"unchanged", 32255, 0x1b0404cb, 0x1b0404cb, true},
+		{"changed between retargets", 32255, 0x1b0404cb, 0x1b0404ca, false},
+		{"unchanged at retarget", 32256, 0x1b0404cb, 0x1b0404cb, true},
+		{"maximum increase", 32256, 0x1b0404cb, 0x1b010132, true},
+		{"excessive increase", 32256, 0x1b0404cb, 0x1b010131, false},
+		{"maximum decrease", 32256, 0x1b0404cb, 0x1b10132c, true},
+		{"excessive decrease", 32256, 0x1b0404cb, 0x1b10132d, false},
+		{"decrease to the limit", 2016, powLimitBits, powLimitBits, true},
+		{"decrease past the limit", 2016, powLimitBits, 0x1d01fffe, false},
+	}
+	for _, test := range tests {
+		got := permittedDifficultyTransition(chain, test.height,
+			test.oldBits, test.newBits)
+		require.Equal(t, test.want, got, test.name)
+	}
+
+	// Any transition is permitted on networks which allow the difficulty
+	// to be reduced to the minimum.
+	chain = newFakeChain(&chaincfg.TestNet3Params)
+	require.True(t, permittedDifficultyTransition(chain, 1, 0x1b0404cb,
+		chaincfg.TestNet3Params.PowLimitBits))
+}
@@ -5,6 +5,7 @@
 package indexers
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"sync"
@@ -634,6 +635,9 @@ var _ Indexer = (*AddrIndex)(nil)
 // Ensure the AddrIndex type implements the NeedsInputser interface.
 var _ NeedsInputser = (*AddrIndex)(nil)
 
+// Ensure the AddrIndex type implements the Verifier interface.
+var _ Verifier = (*AddrIndex)(nil)
+
 // NeedsInputs signals that the index requires the referenced inputs in order
 // to properly create the index.
 //
@@ -810,6 +814,61 @@ func (idx *AddrIndex) DisconnectBlock(dbTx database.Tx, block *btcutil.Block,
 	return nil
 }
 
+// VerifyBlock checks that the address index holds an entry for each address the
+// transactions in the passed block involve.
+//
+// This is part of the Verifier interface.
+func (idx *AddrIndex) VerifyBlock(dbTx database.Tx, block *btcutil.Block,
+	stxos []blockchain.SpentTxOut) error {
+
+	txLocs, err := block.TxLoc()
+	if err != nil {
+		return err
+	}
+	blockID, err := dbFetchBlockIDByHash(dbTx, block.Hash())
+	if err != nil {
+		return MismatchError(fmt.Sprintf("%s has no internal block id for "+
+			"block %v", addrIndexName, block.Hash()))
+	}
+
+	addrsToTxns := make(writeIndexData)
+	idx.indexBlock(addrsToTxns, block, stxos)
+
+	// Look for the entries of each address starting with the most recent
+	// level since the block is usually near the tip of the index.
+	bucket := dbTx.Metadata().Bucket(addrIndexKey)
+	for addrKey, txIdxs := range addrsToTxns {
+		missing := make(map[int]struct{}, len(txIdxs))
+		for _, txIdx := range txIdxs {
+			missing[txIdx] = struct{}{}
+		}
+		for level := uint8(0); len(missing) > 0; level++ {
+			levelKey := keyForLevel(addrKey, level)
+			levelData := bucket.Get(levelKey[:])
+			if levelData == nil {
+				break
+			}
+			for txIdx := range missing {
+				entry := serializeAddrIndexEntry(blockID,
+					txLocs[txIdx])
+				for offset := 0; offset+txEntrySize <= len(levelData); offset += txEntrySize {
+					if bytes.Equal(levelData[offset:offset+txEntrySize], entry) {
+						delete(missing, txIdx)
+						break
+					}
+				}
+			}
+		}
+		for txIdx := range missing {
+			return MismatchError(fmt.Sprintf("%s has no entry for "+
+				"transaction %v in block %v", addrIndexName,
+				block.Transactions()[txIdx].Hash(), block.Hash()))
+		}
+	}
+
+	return nil
+}
+
 // TxRegionsForAddress returns a slice of block regions which identify each
 // transaction that involves the passed address according to the specified
 // number to skip, number requested, and whether or not the results should be
@@ -5,7 +5,9 @@
 package indexers
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 
 	"github.com/btcsuite/btcd/blockchain"
 	"github.com/btcsuite/btcd/btcutil/v2"
@@ -86,6 +88,12 @@ var _ Indexer = (*CfIndex)(nil)
 // Ensure the CfIndex type implements the NeedsInputser interface.
 var _ NeedsInputser = (*CfIndex)(nil)
 
+// Ensure the CfIndex type implements the Verifier interface.
+var _ Verifier = (*CfIndex)(nil)
+
+// Ensure the CfIndex type implements the Pruner interface.
+var _ Pruner = (*CfIndex)(nil)
+
 // NeedsInputs signals that the index requires the referenced inputs in order
 // to properly create the index.
 //
@@ -225,6 +233,70 @@ func (idx *CfIndex) ConnectBlock(dbTx database.Tx, block *btcutil.Block,
 	return storeFilter(dbTx, block, f, wire.GCSFilterRegular)
 }
 
+// VerifyBlock checks that the committed filter index holds the filter of the
+// passed block along with its hash and the filter header which commits to it.
+//
+// This is part of the Verifier interface.
+func (idx *CfIndex) VerifyBlock(dbTx database.Tx, block *btcutil.Block,
+	stxos []blockchain.SpentTxOut) error {
+
+	prevScripts := make([][]byte, len(stxos))
+	for i, stxo := range stxos {
+		prevScripts[i] = stxo.PkScript
+	}
+
+	f, err := builder.BuildBasicFilter(block.MsgBlock(), prevScripts)
+	if err != nil {
+		return err
+	}
+	filterBytes, err := f.NBytes()
+	if err != nil {
+		return err
+	}
+	filterHash, err := builder.GetFilterHash(f)
+	if err != nil {
+		return err
+	}
+	prevHeader := zeroHash
+	prevHash := &block.MsgBlock().Header.PrevBlock
+	if !prevHash.IsEqual(&zeroHash) {
+		pfh, err := dbFetchFilterIdxEntry(dbTx,
+			cfHeaderKeys[wire.GCSFilterRegular], prevHash)
+		if err != nil {
+			return err
+		}
+		copy(prevHeader[:], pfh)
+	}
+	filterHeader, err := builder.MakeHeaderForFilter(f, prevHeader)
+	if err != nil {
+		return err
+	}
+
+	h := block.Hash()
+	entries := []struct {
+		keys [][]byte
+		want []byte
+		name string
+	}{
+		{cfIndexKeys, filterBytes, "filter"},
+		{cfHashKeys, filterHash[:], "filter hash"},
+		{cfHeaderKeys, filterHeader[:], "filter header"},
+	}
+	for _, entry := range entries {
+		got, err := dbFetchFilterIdxEntry(dbTx,
+			entry.keys[wire.GCSFilterRegular], h)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(got, entry.want) {
+			return MismatchError(fmt.Sprintf("%s has the wrong %s "+
+				"for block %v", cfIndexName, entry.name, h))
+		}
+	}
+
+	return nil
+}
+
 // DisconnectBlock is invoked by the index manager when a block has been
 // disconnected from the main chain.  This indexer removes the hash-to-cf
 // mapping for every passed block. This is part of the Indexer interface.
@@ -255,6 +327,26 @@ func (idx *CfIndex) DisconnectBlock(dbTx database.Tx, block *btcutil.Block,
 	return nil
 }
 
+// PruneBlocks is invoked by the index manager when blocks have been deleted
+// from the database by pruning.  This indexer removes the filters of the
+// deleted blocks, so only the filters of the retained blocks are served, while
+// it keeps their filter hashes and headers since the filter headers of all
+// blocks are needed to serve the filter header chain.
+//
+// This is part of the Pruner interface.
+func (idx *CfIndex) PruneBlocks(dbTx database.Tx, deletedHashes []chainhash.Hash) error {
+	for _, key := range cfIndexKeys {
+		for i := range deletedHashes {
+			err := dbDeleteFilterIdxEntry(dbTx, key, &deletedHashes[i])
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 // entryByBlockHash fetches a filter index entry of a particular type
 // (eg. filter, filter header, etc) for a filter type and block hash.
 func (idx *CfIndex) entryByBlockHash(filterTypeKeys [][]byte,
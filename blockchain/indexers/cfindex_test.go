@@ -0,0 +1,98 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg/v2"
+	"github.com/btcsuite/btcd/database"
+	"github.com/btcsuite/btcd/database/ffldb"
+	"github.com/btcsuite/btcd/wire/v2"
+)
+
+// TestCfIndexPrune ensures the committed filter index removes the filters of
+// pruned blocks while keeping the filter hashes and headers of all blocks.
+func TestCfIndexPrune(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	db, err := database.Create("ffldb", filepath.Join(t.TempDir(), "db"),
+		params.Net)
+	if err != nil {
+		t.Fatalf("unable to create database: %v", err)
+	}
+	defer db.Close()
+
+	cfIndex := NewCfIndex(db, params)
+	manager := NewManager(db, []Indexer{cfIndex})
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:               db,
+		ChainParams:      params,
+		TimeSource:       blockchain.NewMedianTime(),
+		UtxoCacheMaxSize: 1024 * 1024,
+		IndexManager:     manager,
+	})
+	if err != nil {
+		t.Fatalf("unable to create chain: %v", err)
+	}
+
+	// Use small block files so that there are many of them to prune.
+	ffldb.TstRunWithMaxBlockFileSize(db, 1024, func() {
+		connectBlocks(t, chain, params, 40)
+	})
+	if err := chain.PruneBlocks(20); err != nil {
+		t.Fatalf("PruneBlocks: unexpected error: %v", err)
+	}
+	pruneHeight, err := chain.PruneHeight()
+	if err != nil {
+		t.Fatalf("PruneHeight: unexpected error: %v", err)
+	}
+	if pruneHeight == 0 {
+		t.Fatal("PruneHeight: no blocks were pruned")
+	}
+
+	for height := int32(0); height <= 40; height++ {
+		hash, err := chain.BlockHashByHeight(height)
+		if err != nil {
+			t.Fatalf("unable to fetch block hash: %v", err)
+		}
+		filter, err := cfIndex.FilterByBlockHash(hash,
+			wire.GCSFilterRegular)
+		if err != nil {
+			t.Fatalf("FilterByBlockHash: unexpected error: %v", err)
+		}
+		if (len(filter) != 0) != (height >= pruneHeight) {
+			t.Fatalf("FilterByBlockHash(%d): got filter %x with "+
+				"prune height %d", height, filter, pruneHeight)
+		}
+		header, err := cfIndex.FilterHeaderByBlockHash(hash,
+			wire.GCSFilterRegular)
+		if err != nil || len(header) == 0 {
+			t.Fatalf("FilterHeaderByBlockHash(%d): got %x, %v",
+				height, header, err)
+		}
+		filterHash, err := cfIndex.FilterHashByBlockHash(hash,
+			wire.GCSFilterRegular)
+		if err != nil || len(filterHash) == 0 {
+			t.Fatalf("FilterHashByBlockHash(%d): got %x, %v",
+				height, filterHash, err)
+		}
+	}
+
+	// Only the stored blocks are verified and the index can't be rebuilt
+	// from the pruned blocks.
+	result, err := manager.VerifyIndex(cfIndexName, 0, nil)
+	if err != nil {
+		t.Fatalf("VerifyIndex: unexpected error: %v", err)
+	}
+	if result.Checked != 40-pruneHeight+1 || result.Mismatch != "" {
+		t.Fatalf("VerifyIndex: got %+v with prune height %d", result,
+			pruneHeight)
+	}
+	if err := manager.RebuildIndex(cfIndexName, nil); err == nil {
+		t.Fatal("RebuildIndex: expected error for pruned blocks")
+	}
+}
@@ -0,0 +1,413 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil/v2"
+	"github.com/btcsuite/btcd/btcutil/v2/gcs"
+	"github.com/btcsuite/btcd/btcutil/v2/gcs/builder"
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/database"
+	"github.com/btcsuite/btcd/txscript/v2"
+	"github.com/btcsuite/btcd/wire/v2"
+)
+
+const (
+	// claimFilterIndexName is the human-readable name for the index.
+	claimFilterIndexName = "claim name filter index"
+
+	// GCSFilterClaimName is the filter type of the committed filters of
+	// the claim names.  It follows the regular filter type of BIP0158 and
+	// is requested with getcfilters, getcfheaders and getcfcheckpt in the
+	// same way.
+	GCSFilterClaimName wire.FilterType = 1
+)
+
+var (
+	// claimFilterIndexKey is the key of the claim name filter index and
+	// the db bucket used to house it.
+	claimFilterIndexKey = []byte("claimfilteridx")
+
+	// claimFilterKey, claimFilterHashKey and claimFilterHeaderKey are the
+	// names of the db buckets within the claim name filter index bucket
+	// which house the filters, filter hashes and filter headers by block
+	// hash.
+	claimFilterKey       = []byte("filterbyhashidx")
+	claimFilterHashKey   = []byte("hashbyhashidx")
+	claimFilterHeaderKey = []byte("headerbyhashidx")
+)
+
+// BuildClaimNameFilter builds the claim name filter of the passed block.  It
+// holds the names of the claim, support and update outputs created by the
+// block along with the names of the ones spent by it, which are taken from
+// the passed scripts of the outputs spent by the block.  The filter uses the
+// same parameters and key as the basic filter of BIP0158.
+func BuildClaimNameFilter(block *wire.MsgBlock,
+	prevOutScripts [][]byte) (*gcs.Filter, error) {
+
+	blockHash := block.BlockHash()
+	b := builder.WithKeyHash(&blockHash)
+	addName := func(pkScript []byte) {
+		if !txscript.IsClaimScript(pkScript) {
+			return
+		}
+		claim, err := txscript.DecodeClaimScript(pkScript)
+		if err != nil {
+			return
+		}
+		b.AddEntry(claim.Name)
+	}
+	for _, tx := range block.Transactions {
+		for _, txOut := range tx.TxOut {
+			addName(txOut.PkScript)
+		}
+	}
+	for _, pkScript := range prevOutScripts {
+		addName(pkScript)
+	}
+
+	return b.Build()
+}
+
+// dbFetchClaimFilterEntry retrieves an entry from the passed bucket of the
+// claim name filter index.  An entry's absence is not considered an error.
+func dbFetchClaimFilterEntry(dbTx database.Tx, key []byte, h *chainhash.Hash) []byte {
+	bucket := dbTx.Metadata().Bucket(claimFilterIndexKey).Bucket(key)
+	return bucket.Get(h[:])
+}
+
+// dbStoreClaimFilterEntry stores an entry in the passed bucket of the claim
+// name filter index.
+func dbStoreClaimFilterEntry(dbTx database.Tx, key []byte, h *chainhash.Hash, entry []byte) error {
+	bucket := dbTx.Metadata().Bucket(claimFilterIndexKey).Bucket(key)
+	return bucket.Put(h[:], entry)
+}
+
+// dbDeleteClaimFilterEntry deletes an entry from the passed bucket of the
+// claim name filter index.
+func dbDeleteClaimFilterEntry(dbTx database.Tx, key []byte, h *chainhash.Hash) error {
+	bucket := dbTx.Metadata().Bucket(claimFilterIndexKey).Bucket(key)
+	return bucket.Delete(h[:])
+}
+
+// claimFilterEntries returns the serialized claim name filter of the passed
+// block along with its hash and the filter header which commits to it.  The
+// header of the filter of the previous block is read from the index.
+func claimFilterEntries(dbTx database.Tx, block *btcutil.Block,
+	stxos []blockchain.SpentTxOut) ([]byte, []byte, []byte, error) {
+
+	prevScripts := make([][]byte, len(stxos))
+	for i, stxo := range stxos {
+		prevScripts[i] = stxo.PkScript
+	}
+	f, err := BuildClaimNameFilter(block.MsgBlock(), prevScripts)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	filterBytes, err := f.NBytes()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	filterHash, err := builder.GetFilterHash(f)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var prevHeader chainhash.Hash
+	prevHash := &block.MsgBlock().Header.PrevBlock
+	if !prevHash.IsEqual(&zeroHash) {
+		pfh := dbFetchClaimFilterEntry(dbTx, claimFilterHeaderKey, prevHash)
+		if len(pfh) != chainhash.HashSize {
+			return nil, nil, nil, fmt.Errorf("%s has no filter "+
+				"header for block %v", claimFilterIndexName,
+				prevHash)
+		}
+		copy(prevHeader[:], pfh)
+	}
+	filterHeader, err := builder.MakeHeaderForFilter(f, prevHeader)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return filterBytes, filterHash[:], filterHeader[:], nil
+}
+
+// ClaimFilterIndex implements an index of the committed filters of the claim
+// names of each block by block hash.  The filters let light clients find the
+// blocks which touch the names they follow while only syncing the headers and
+// the filters.  Like the basic filters, they are chained by filter headers.
+type ClaimFilterIndex struct {
+	db database.DB
+}
+
+// Ensure the ClaimFilterIndex type implements the Indexer interface.
+var _ Indexer = (*ClaimFilterIndex)(nil)
+
+// Ensure the ClaimFilterIndex type implements the NeedsInputser interface.
+var _ NeedsInputser = (*ClaimFilterIndex)(nil)
+
+// Ensure the ClaimFilterIndex type implements the Verifier interface.
+var _ Verifier = (*ClaimFilterIndex)(nil)
+
+// Ensure the ClaimFilterIndex type implements the Pruner interface.
+var _ Pruner = (*ClaimFilterIndex)(nil)
+
+// NeedsInputs signals that the index requires the referenced inputs in order
+// to add the names of the spent claims to the filters.
+//
+// This implements the NeedsInputser interface.
+func (idx *ClaimFilterIndex) NeedsInputs() bool {
+	return true
+}
+
+// Init initializes the claim name filter index.
+//
+// This is part of the Indexer interface.
+func (idx *ClaimFilterIndex) Init() error {
+	return nil // Nothing to do.
+}
+
+// Key returns the database key to use for the index as a byte slice.
+//
+// This is part of the Indexer interface.
+func (idx *ClaimFilterIndex) Key() []byte {
+	return claimFilterIndexKey
+}
+
+// Name returns the human-readable name of the index.
+//
+// This is part of the Indexer interface.
+func (idx *ClaimFilterIndex) Name() string {
+	return claimFilterIndexName
+}
+
+// Create is invoked when the indexer manager determines the index needs to be
+// created for the first time.  It creates the bucket for the index along with
+// the buckets of the filters, filter hashes and filter headers.
+//
+// This is part of the Indexer interface.
+func (idx *ClaimFilterIndex) Create(dbTx database.Tx) error {
+	bucket, err := dbTx.Metadata().CreateBucket(claimFilterIndexKey)
+	if err != nil {
+		return err
+	}
+	for _, key := range [][]byte{claimFilterKey, claimFilterHashKey,
+		claimFilterHeaderKey} {
+
+		if _, err := bucket.CreateBucket(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConnectBlock is invoked by the index manager when a new block has been
+// connected to the main chain.  This indexer stores the claim name filter of
+// the block along with its hash and filter header.
+//
+// This is part of the Indexer interface.
+func (idx *ClaimFilterIndex) ConnectBlock(dbTx database.Tx, block *btcutil.Block,
+	stxos []blockchain.SpentTxOut) error {
+
+	filter, filterHash, filterHeader, err := claimFilterEntries(dbTx,
+		block, stxos)
+	if err != nil {
+		return err
+	}
+
+	h := block.Hash()
+	err = dbStoreClaimFilterEntry(dbTx, claimFilterKey, h, filter)
+	if err != nil {
+		return err
+	}
+	err = dbStoreClaimFilterEntry(dbTx, claimFilterHashKey, h, filterHash)
+	if err != nil {
+		return err
+	}
+	return dbStoreClaimFilterEntry(dbTx, claimFilterHeaderKey, h,
+		filterHeader)
+}
+
+// DisconnectBlock is invoked by the index manager when a block has been
+// disconnected from the main chain.  This indexer removes the claim name
+// filter of the block along with its hash and filter header.
+//
+// This is part of the Indexer interface.
+func (idx *ClaimFilterIndex) DisconnectBlock(dbTx database.Tx, block *btcutil.Block,
+	_ []blockchain.SpentTxOut) error {
+
+	for _, key := range [][]byte{claimFilterKey, claimFilterHashKey,
+		claimFilterHeaderKey} {
+
+		if err := dbDeleteClaimFilterEntry(dbTx, key, block.Hash()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VerifyBlock checks that the claim name filter index holds the filter of the
+// passed block along with its hash and the filter header which commits to it.
+//
+// This is part of the Verifier interface.
+func (idx *ClaimFilterIndex) VerifyBlock(dbTx database.Tx, block *btcutil.Block,
+	stxos []blockchain.SpentTxOut) error {
+
+	filter, filterHash, filterHeader, err := claimFilterEntries(dbTx,
+		block, stxos)
+	if err != nil {
+		return err
+	}
+
+	h := block.Hash()
+	entries := []struct {
+		key  []byte
+		want []byte
+		name string
+	}{
+		{claimFilterKey, filter, "filter"},
+		{claimFilterHashKey, filterHash, "filter hash"},
+		{claimFilterHeaderKey, filterHeader, "filter header"},
+	}
+	for _, entry := range entries {
+		got := dbFetchClaimFilterEntry(dbTx, entry.key, h)
+		if !bytes.Equal(got, entry.want) {
+			return MismatchError(fmt.Sprintf("%s has the wrong %s "+
+				"for block %v", claimFilterIndexName, entry.name, h))
+		}
+	}
+	return nil
+}
+
+// PruneBlocks is invoked by the index manager when blocks have been deleted
+// from the database by pruning.  Like the committed filter index, this indexer
+// removes the filters of the deleted blocks while it keeps their filter hashes
+// and headers, which are needed to serve the filter header chain.
+//
+// This is part of the Pruner interface.
+func (idx *ClaimFilterIndex) PruneBlocks(dbTx database.Tx, deletedHashes []chainhash.Hash) error {
+	for i := range deletedHashes {
+		err := dbDeleteClaimFilterEntry(dbTx, claimFilterKey,
+			&deletedHashes[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// entriesByBlockHashes fetches the entries of the passed bucket of the index
+// for a set of blocks by hash.  The passed filter type must be
+// GCSFilterClaimName.
+func (idx *ClaimFilterIndex) entriesByBlockHashes(key []byte,
+	filterType wire.FilterType, blockHashes []*chainhash.Hash) ([][]byte, error) {
+
+	if filterType != GCSFilterClaimName {
+		return nil, errors.New("unsupported filter type")
+	}
+
+	entries := make([][]byte, 0, len(blockHashes))
+	err := idx.db.View(func(dbTx database.Tx) error {
+		for _, blockHash := range blockHashes {
+			entries = append(entries, dbFetchClaimFilterEntry(dbTx,
+				key, blockHash))
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// entryByBlockHash fetches the entry of the passed bucket of the index for a
+// block by hash.  The passed filter type must be GCSFilterClaimName.
+func (idx *ClaimFilterIndex) entryByBlockHash(key []byte,
+	filterType wire.FilterType, h *chainhash.Hash) ([]byte, error) {
+
+	entries, err := idx.entriesByBlockHashes(key, filterType,
+		[]*chainhash.Hash{h})
+	if err != nil {
+		return nil, err
+	}
+	return entries[0], nil
+}
+
+// FilterByBlockHash returns the serialized claim name filter of a block.  The
+// filter type is taken to match the methods of the committed filter index.
+func (idx *ClaimFilterIndex) FilterByBlockHash(h *chainhash.Hash,
+	filterType wire.FilterType) ([]byte, error) {
+	return idx.entryByBlockHash(claimFilterKey, filterType, h)
+}
+
+// FiltersByBlockHashes returns the serialized claim name filters of a set of
+// blocks by hash.
+func (idx *ClaimFilterIndex) FiltersByBlockHashes(blockHashes []*chainhash.Hash,
+	filterType wire.FilterType) ([][]byte, error) {
+	return idx.entriesByBlockHashes(claimFilterKey, filterType, blockHashes)
+}
+
+// FilterHeaderByBlockHash returns the serialized claim name filter header of a
+// block.
+func (idx *ClaimFilterIndex) FilterHeaderByBlockHash(h *chainhash.Hash,
+	filterType wire.FilterType) ([]byte, error) {
+	return idx.entryByBlockHash(claimFilterHeaderKey, filterType, h)
+}
+
+// FilterHeadersByBlockHashes returns the serialized claim name filter headers
+// of a set of blocks by hash.
+func (idx *ClaimFilterIndex) FilterHeadersByBlockHashes(blockHashes []*chainhash.Hash,
+	filterType wire.FilterType) ([][]byte, error) {
+	return idx.entriesByBlockHashes(claimFilterHeaderKey, filterType,
+		blockHashes)
+}
+
+// FilterHashByBlockHash returns the serialized claim name filter hash of a
+// block.
+func (idx *ClaimFilterIndex) FilterHashByBlockHash(h *chainhash.Hash,
+	filterType wire.FilterType) ([]byte, error) {
+	return idx.entryByBlockHash(claimFilterHashKey, filterType, h)
+}
+
+// FilterHashesByBlockHashes returns the serialized claim name filter hashes
+// of a set of blocks by hash.
+func (idx *ClaimFilterIndex) FilterHashesByBlockHashes(blockHashes []*chainhash.Hash,
+	filterType wire.FilterType) ([][]byte, error) {
+	return idx.entriesByBlockHashes(claimFilterHashKey, filterType,
+		blockHashes)
+}
+
+// NewClaimFilterIndex returns a new instance of an indexer that is used to
+// create a mapping of the hashes of all blocks in the main chain to the
+// committed filters of their claim names.
+//
+// It implements the Indexer interface which plugs into the IndexManager that
+// in turn is used by the blockchain package.  This allows the index to be
+// seamlessly maintained along with the chain.
+func NewClaimFilterIndex(db database.DB) *ClaimFilterIndex {
+	return &ClaimFilterIndex{db: db}
+}
+
+// DropClaimFilterIndex drops the claim name filter index from the provided
+// database if it exists.
+func DropClaimFilterIndex(db database.DB, interrupt <-chan struct{}) error {
+	return dropIndex(db, claimFilterIndexKey, claimFilterIndexName,
+		interrupt)
+}
+
+// ClaimFilterIndexInitialized returns true if the claim name filter index has
+// been created previously.
+func ClaimFilterIndexInitialized(db database.DB) bool {
+	var exists bool
+	db.View(func(dbTx database.Tx) error {
+		bucket := dbTx.Metadata().Bucket(claimFilterIndexKey)
+		exists = bucket != nil
+		return nil
+	})
+
+	return exists
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil/v2"
+	"github.com/btcsuite/btcd/btcutil/v2/gcs"
+	"github.com/btcsuite/btcd/btcutil/v2/gcs/builder"
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/database"
+	_ "github.com/btcsuite/btcd/database/ffldb"
+	"github.com/btcsuite/btcd/txscript/v2"
+	"github.com/btcsuite/btcd/wire/v2"
+)
+
+// TestClaimFilterIndex ensures the claim name filter index stores filters of
+// the names of the claims created and spent by connected blocks, chains their
+// filter headers, and removes them again when the blocks are disconnected.
+func TestClaimFilterIndex(t *testing.T) {
+	db, err := database.Create("ffldb", filepath.Join(t.TempDir(), "db"),
+		wire.MainNet)
+	if err != nil {
+		t.Fatalf("unable to create database: %v", err)
+	}
+	defer db.Close()
+
+	idx := NewClaimFilterIndex(db)
+	err = db.Update(func(dbTx database.Tx) error {
+		return idx.Create(dbTx)
+	})
+	if err != nil {
+		t.Fatalf("Create: unexpected error: %v", err)
+	}
+
+	pkScript := []byte{txscript.OP_TRUE}
+	claimScript := func(name string) []byte {
+		t.Helper()
+		prefix, err := txscript.NewClaimScript([]byte(name),
+			[]byte("value"))
+		if err != nil {
+			t.Fatalf("unable to create claim script: %v", err)
+		}
+		return append(prefix, pkScript...)
+	}
+	newBlock := func(prevBlock chainhash.Hash, tx *wire.MsgTx) *btcutil.Block {
+		coinbase := wire.NewMsgTx(wire.TxVersion)
+		coinbase.AddTxIn(wire.NewTxIn(wire.NewOutPoint(
+			&chainhash.Hash{}, wire.MaxPrevOutIndex), nil, nil))
+		coinbase.AddTxOut(wire.NewTxOut(5000000000, pkScript))
+		return btcutil.NewBlock(&wire.MsgBlock{
+			Header:       wire.BlockHeader{PrevBlock: prevBlock},
+			Transactions: []*wire.MsgTx{coinbase, tx},
+		})
+	}
+
+	// The first block claims "alice" and the second one spends a claim
+	// of "bob" and claims "carol".
+	tx1 := wire.NewMsgTx(wire.TxVersion)
+	tx1.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: chainhash.Hash{1}},
+		nil, nil))
+	tx1.AddTxOut(wire.NewTxOut(1000, claimScript("alice")))
+	block1 := newBlock(chainhash.Hash{}, tx1)
+
+	tx2 := wire.NewMsgTx(wire.TxVersion)
+	tx2.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: chainhash.Hash{2}},
+		nil, nil))
+	tx2.AddTxOut(wire.NewTxOut(1000, claimScript("carol")))
+	block2 := newBlock(*block1.Hash(), tx2)
+	stxos2 := []blockchain.SpentTxOut{{
+		Amount:   1000,
+		PkScript: claimScript("bob"),
+	}}
+
+	blocks := []struct {
+		block  *btcutil.Block
+		stxos  []blockchain.SpentTxOut
+		names  []string
+		absent []string
+	}{
+		{block1, nil, []string{"alice"}, []string{"bob", "carol"}},
+		{block2, stxos2, []string{"bob", "carol"}, []string{"alice"}},
+	}
+	var prevHeader chainhash.Hash
+	for _, test := range blocks {
+		err = db.Update(func(dbTx database.Tx) error {
+			return idx.ConnectBlock(dbTx, test.block, test.stxos)
+		})
+		if err != nil {
+			t.Fatalf("ConnectBlock: unexpected error: %v", err)
+		}
+		err = db.View(func(dbTx database.Tx) error {
+			return idx.VerifyBlock(dbTx, test.block, test.stxos)
+		})
+		if err != nil {
+			t.Fatalf("VerifyBlock: unexpected error: %v", err)
+		}
+
+		h := test.block.Hash()
+		filterBytes, err := idx.FilterByBlockHash(h, GCSFilterClaimName)
+		if err != nil {
+			t.Fatalf("FilterByBlockHash: unexpected error: %v", err)
+		}
+		f, err := gcs.FromNBytes(builder.DefaultP, builder.DefaultM,
+			filterBytes)
+		if err != nil {
+			t.Fatalf("unable to deserialize filter: %v", err)
+		}
+		key := builder.DeriveKey(h)
+		for _, name := range test.names {
+			if match, _ := f.Match(key, []byte(name)); !match {
+				t.Fatalf("filter of block %v does not match %q",
+					h, name)
+			}
+		}
+		if f.N() != uint32(len(test.names)) {
+			t.Fatalf("filter of block %v has %d entries, want %d",
+				h, f.N(), len(test.names))
+		}
+
+		// The filter header commits to the filter and the previous
+		// filter header.
+		wantHeader, err := builder.MakeHeaderForFilter(f, prevHeader)
+		if err != nil {
+			t.Fatalf("unable to make filter header: %v", err)
+		}
+		header, err := idx.FilterHeaderByBlockHash(h, GCSFilterClaimName)
+		if err != nil {
+			t.Fatalf("FilterHeaderByBlockHash: unexpected error: %v",
+				err)
+		}
+		if !bytes.Equal(header, wantHeader[:]) {
+			t.Fatalf("FilterHeaderByBlockHash: got %x, want %x",
+				header, wantHeader[:])
+		}
+		prevHeader = wantHeader
+	}
+
+	// Only the claim name filter type is served by the index.
+	_, err = idx.FilterByBlockHash(block1.Hash(), wire.GCSFilterRegular)
+	if err == nil {
+		t.Fatal("FilterByBlockHash: expected error for regular filter")
+	}
+
+	// Disconnecting the second block removes its entries.
+	err = db.Update(func(dbTx database.Tx) error {
+		return idx.DisconnectBlock(dbTx, block2, stxos2)
+	})
+	if err != nil {
+		t.Fatalf("DisconnectBlock: unexpected error: %v", err)
+	}
+	entries, err := idx.FilterHashesByBlockHashes([]*chainhash.Hash{
+		block1.Hash(), block2.Hash()}, GCSFilterClaimName)
+	if err != nil {
+		t.Fatalf("FilterHashesByBlockHashes: unexpected error: %v", err)
+	}
+	if len(entries[0]) == 0 || len(entries[1]) != 0 {
+		t.Fatalf("FilterHashesByBlockHashes: got %x after disconnect",
+			entries)
+	}
+}
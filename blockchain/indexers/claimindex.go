@@ -0,0 +1,521 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/btcsuite/btcd/address/v2"
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil/v2"
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/database"
+	"github.com/btcsuite/btcd/txscript/v2"
+	"github.com/btcsuite/btcd/wire/v2"
+)
+
+const (
+	// claimIndexName is the human-readable name for the index.
+	claimIndexName = "claim index"
+
+	// claimIndexLocSize is the size of the location of a claim operation
+	// which ends the keys of the claim index.  It consists of the height
+	// of the block, the hash of the transaction and the index of the
+	// output.
+	claimIndexLocSize = 4 + chainhash.HashSize + 4
+)
+
+var (
+	// claimIndexKey is the key of the claim index and the db bucket used
+	// to house it.
+	claimIndexKey = []byte("claimidx")
+
+	// claimByIDBucketName is the name of the db bucket within the claim
+	// index bucket which houses the operations keyed by claim ID.
+	claimByIDBucketName = []byte("byid")
+
+	// claimByNameBucketName is the name of the db bucket within the claim
+	// index bucket which houses the operations keyed by name.
+	claimByNameBucketName = []byte("byname")
+)
+
+// -----------------------------------------------------------------------------
+// The claim index consists of an entry for every claim, support and update
+// operation made by an output in the main chain, both keyed by the ID of the
+// claim and by the name.  The keys end with the location of the operation so
+// a cursor seeking to the ID or name returns its history in the order of the
+// heights of the blocks.  The heights and output indexes of the locations are
+// big endian for that reason.
+//
+// The serialized location format is:
+//
+//   <block height><hash><index>
+//
+//   Field           Type             Size
+//   block height    uint32           4
+//   hash            chainhash.Hash   32
+//   index           uint32           4
+//   -----
+//   Total: 40 bytes
+//
+// The serialized key and value formats of the by ID bucket are:
+//
+//   <claim id><location> = <operation><name>
+//
+//   Field           Type             Size
+//   claim id        []byte           20
+//   location        see above        40
+//   operation       ClaimOp          1
+//   name            []byte           variable
+//
+// The serialized key and value formats of the by name bucket are:
+//
+//   <name length><name><location> = <operation><claim id>
+//
+//   Field           Type             Size
+//   name length     uint8            1
+//   name            []byte           variable
+//   location        see above        40
+//   operation       ClaimOp          1
+//   claim id        []byte           20
+// -----------------------------------------------------------------------------
+
+// ClaimOp identifies the kind of operation an output makes on a claim.
+type ClaimOp byte
+
+// These constants define the kinds of claim operations.
+const (
+	// ClaimOpClaim claims a name, which creates a new claim.
+	ClaimOpClaim ClaimOp = iota
+
+	// ClaimOpSupport supports an existing claim.
+	ClaimOpSupport
+
+	// ClaimOpUpdate updates an existing claim.
+	ClaimOpUpdate
+)
+
+// claimOpStrings is a map of claim operations back to their constant names for
+// pretty printing.
+var claimOpStrings = map[ClaimOp]string{
+	ClaimOpClaim:   "claim",
+	ClaimOpSupport: "support",
+	ClaimOpUpdate:  "update",
+}
+
+// String returns the ClaimOp in human-readable form.
+func (op ClaimOp) String() string {
+	if s, ok := claimOpStrings[op]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown ClaimOp (%d)", byte(op))
+}
+
+// ClaimEntry describes a claim, support or update operation made by an output
+// in the main chain.
+type ClaimEntry struct {
+	// Op is the kind of the operation.
+	Op ClaimOp
+
+	// ClaimID is the ID of the claim the operation applies to.  It is the
+	// ID of the new claim for claim operations.
+	ClaimID []byte
+
+	// Name is the claimed or supported name.
+	Name []byte
+
+	// TxHash is the hash of the transaction and Index is the index of the
+	// output making the operation.
+	TxHash chainhash.Hash
+	Index  uint32
+
+	// Height is the height of the block containing the transaction.
+	Height int32
+}
+
+// NewClaimID returns the ID of the claim made by the output with the passed
+// outpoint, which is the hash160 of the hash of its transaction followed by
+// its big endian output index.
+func NewClaimID(outPoint *wire.OutPoint) []byte {
+	var buf [chainhash.HashSize + 4]byte
+	copy(buf[:], outPoint.Hash[:])
+	binary.BigEndian.PutUint32(buf[chainhash.HashSize:], outPoint.Index)
+	return address.Hash160(buf[:])
+}
+
+// blockClaimEntries returns the claim operations made by the outputs of the
+// passed block.
+func blockClaimEntries(block *btcutil.Block) []ClaimEntry {
+	var entries []ClaimEntry
+	for _, tx := range block.Transactions() {
+		for i, txOut := range tx.MsgTx().TxOut {
+			claim, err := txscript.DecodeClaimScript(txOut.PkScript)
+			if err != nil {
+				continue
+			}
+
+			entry := ClaimEntry{
+				ClaimID: claim.ClaimID,
+				Name:    claim.Name,
+				TxHash:  *tx.Hash(),
+				Index:   uint32(i),
+				Height:  block.Height(),
+			}
+			switch claim.Opcode {
+			case txscript.OP_CLAIMNAME:
+				entry.Op = ClaimOpClaim
+				entry.ClaimID = NewClaimID(&wire.OutPoint{
+					Hash:  entry.TxHash,
+					Index: entry.Index,
+				})
+			case txscript.OP_SUPPORTCLAIM:
+				entry.Op = ClaimOpSupport
+			case txscript.OP_UPDATECLAIM:
+				entry.Op = ClaimOpUpdate
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+// putClaimIndexLoc serializes the location of the passed entry into the passed
+// target byte slice.  The target byte slice must be at least large enough to
+// handle the number of bytes defined by the claimIndexLocSize constant or it
+// will panic.
+func putClaimIndexLoc(target []byte, entry *ClaimEntry) {
+	binary.BigEndian.PutUint32(target, uint32(entry.Height))
+	copy(target[4:], entry.TxHash[:])
+	binary.BigEndian.PutUint32(target[4+chainhash.HashSize:], entry.Index)
+}
+
+// deserializeClaimIndexLoc deserializes the passed location into the passed
+// entry.
+func deserializeClaimIndexLoc(serialized []byte, entry *ClaimEntry) error {
+	if len(serialized) != claimIndexLocSize {
+		return errDeserialize("unexpected location size")
+	}
+
+	entry.Height = int32(binary.BigEndian.Uint32(serialized))
+	copy(entry.TxHash[:], serialized[4:])
+	entry.Index = binary.BigEndian.Uint32(serialized[4+chainhash.HashSize:])
+	return nil
+}
+
+// claimIDIndexKey returns the key of the passed entry in the by ID bucket.
+func claimIDIndexKey(entry *ClaimEntry) []byte {
+	key := make([]byte, txscript.ClaimIDSize+claimIndexLocSize)
+	copy(key, entry.ClaimID)
+	putClaimIndexLoc(key[txscript.ClaimIDSize:], entry)
+	return key
+}
+
+// claimNamePrefix returns the prefix of the keys of the passed name in the by
+// name bucket.
+func claimNamePrefix(name []byte) []byte {
+	prefix := make([]byte, 1+len(name))
+	prefix[0] = byte(len(name))
+	copy(prefix[1:], name)
+	return prefix
+}
+
+// claimNameIndexKey returns the key of the passed entry in the by name bucket.
+func claimNameIndexKey(entry *ClaimEntry) []byte {
+	prefix := claimNamePrefix(entry.Name)
+	key := make([]byte, len(prefix)+claimIndexLocSize)
+	copy(key, prefix)
+	putClaimIndexLoc(key[len(prefix):], entry)
+	return key
+}
+
+// dbAddClaimIndexEntries uses an existing database transaction to add the
+// passed entries to both buckets of the claim index.
+func dbAddClaimIndexEntries(dbTx database.Tx, entries []ClaimEntry) error {
+	indexBucket := dbTx.Metadata().Bucket(claimIndexKey)
+	byID := indexBucket.Bucket(claimByIDBucketName)
+	byName := indexBucket.Bucket(claimByNameBucketName)
+	for i := range entries {
+		entry := &entries[i]
+
+		value := make([]byte, 1+len(entry.Name))
+		value[0] = byte(entry.Op)
+		copy(value[1:], entry.Name)
+		if err := byID.Put(claimIDIndexKey(entry), value); err != nil {
+			return err
+		}
+
+		value = make([]byte, 1+txscript.ClaimIDSize)
+		value[0] = byte(entry.Op)
+		copy(value[1:], entry.ClaimID)
+		if err := byName.Put(claimNameIndexKey(entry), value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dbRemoveClaimIndexEntries uses an existing database transaction to remove the
+// passed entries from both buckets of the claim index.
+func dbRemoveClaimIndexEntries(dbTx database.Tx, entries []ClaimEntry) error {
+	indexBucket := dbTx.Metadata().Bucket(claimIndexKey)
+	byID := indexBucket.Bucket(claimByIDBucketName)
+	byName := indexBucket.Bucket(claimByNameBucketName)
+	for i := range entries {
+		entry := &entries[i]
+		if err := byID.Delete(claimIDIndexKey(entry)); err != nil {
+			return err
+		}
+		if err := byName.Delete(claimNameIndexKey(entry)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dbFetchClaimHistoryByID uses an existing database transaction to fetch the
+// operations on the claim with the passed ID in the order of the heights of
+// their blocks.
+func dbFetchClaimHistoryByID(dbTx database.Tx, claimID []byte) ([]ClaimEntry, error) {
+	if len(claimID) != txscript.ClaimIDSize {
+		return nil, nil
+	}
+	bucket := dbTx.Metadata().Bucket(claimIndexKey).Bucket(claimByIDBucketName)
+
+	var entries []ClaimEntry
+	cursor := bucket.Cursor()
+	for ok := cursor.Seek(claimID); ok; ok = cursor.Next() {
+		key := cursor.Key()
+		if !bytes.HasPrefix(key, claimID) {
+			break
+		}
+
+		entry := ClaimEntry{ClaimID: key[:len(claimID):len(claimID)]}
+		err := deserializeClaimIndexLoc(key[len(claimID):], &entry)
+		value := cursor.Value()
+		if err == nil && len(value) < 1 {
+			err = errDeserialize("unexpected end of data")
+		}
+		if err != nil {
+			return nil, database.Error{
+				ErrorCode: database.ErrCorruption,
+				Description: fmt.Sprintf("corrupt %s entry "+
+					"for claim %x: %v", claimIndexName,
+					claimID, err),
+			}
+		}
+		entry.Op = ClaimOp(value[0])
+		entry.Name = append([]byte(nil), value[1:]...)
+		entry.ClaimID = append([]byte(nil), entry.ClaimID...)
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// dbFetchClaimHistoryByName uses an existing database transaction to fetch the
+// operations on the passed name in the order of the heights of their blocks.
+func dbFetchClaimHistoryByName(dbTx database.Tx, name []byte) ([]ClaimEntry, error) {
+	if len(name) > txscript.MaxClaimNameSize {
+		return nil, nil
+	}
+	bucket := dbTx.Metadata().Bucket(claimIndexKey).Bucket(claimByNameBucketName)
+	prefix := claimNamePrefix(name)
+
+	var entries []ClaimEntry
+	cursor := bucket.Cursor()
+	for ok := cursor.Seek(prefix); ok; ok = cursor.Next() {
+		key := cursor.Key()
+		if !bytes.HasPrefix(key, prefix) {
+			break
+		}
+
+		entry := ClaimEntry{Name: append([]byte(nil), name...)}
+		err := deserializeClaimIndexLoc(key[len(prefix):], &entry)
+		value := cursor.Value()
+		if err == nil && len(value) != 1+txscript.ClaimIDSize {
+			err = errDeserialize("unexpected value size")
+		}
+		if err != nil {
+			return nil, database.Error{
+				ErrorCode: database.ErrCorruption,
+				Description: fmt.Sprintf("corrupt %s entry "+
+					"for name %q: %v", claimIndexName,
+					name, err),
+			}
+		}
+		entry.Op = ClaimOp(value[0])
+		entry.ClaimID = append([]byte(nil), value[1:]...)
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// ClaimIndex implements a claim index.  That is to say, it supports querying
+// the history of the claim, support and update operations of a claim by its ID
+// and of a name.
+type ClaimIndex struct {
+	db database.DB
+}
+
+// Ensure the ClaimIndex type implements the Indexer interface.
+var _ Indexer = (*ClaimIndex)(nil)
+
+// Ensure the ClaimIndex type implements the Verifier interface.
+var _ Verifier = (*ClaimIndex)(nil)
+
+// Init is only provided to satisfy the Indexer interface as there is nothing to
+// initialize for this index.
+//
+// This is part of the Indexer interface.
+func (idx *ClaimIndex) Init() error {
+	// Nothing to do.
+	return nil
+}
+
+// Key returns the database key to use for the index as a byte slice.
+//
+// This is part of the Indexer interface.
+func (idx *ClaimIndex) Key() []byte {
+	return claimIndexKey
+}
+
+// Name returns the human-readable name of the index.
+//
+// This is part of the Indexer interface.
+func (idx *ClaimIndex) Name() string {
+	return claimIndexName
+}
+
+// Create is invoked when the indexer manager determines the index needs to be
+// created for the first time.  It creates the bucket for the claim index along
+// with the buckets keyed by claim ID and by name within it.
+//
+// This is part of the Indexer interface.
+func (idx *ClaimIndex) Create(dbTx database.Tx) error {
+	bucket, err := dbTx.Metadata().CreateBucket(claimIndexKey)
+	if err != nil {
+		return err
+	}
+	if _, err := bucket.CreateBucket(claimByIDBucketName); err != nil {
+		return err
+	}
+	_, err = bucket.CreateBucket(claimByNameBucketName)
+	return err
+}
+
+// ConnectBlock is invoked by the index manager when a new block has been
+// connected to the main chain.  This indexer adds the claim, support and update
+// operations made by the outputs of the passed block.
+//
+// This is part of the Indexer interface.
+func (idx *ClaimIndex) ConnectBlock(dbTx database.Tx, block *btcutil.Block,
+	stxos []blockchain.SpentTxOut) error {
+
+	return dbAddClaimIndexEntries(dbTx, blockClaimEntries(block))
+}
+
+// DisconnectBlock is invoked by the index manager when a block has been
+// disconnected from the main chain.  This indexer removes the operations made
+// by the outputs of the passed block.
+//
+// This is part of the Indexer interface.
+func (idx *ClaimIndex) DisconnectBlock(dbTx database.Tx, block *btcutil.Block,
+	stxos []blockchain.SpentTxOut) error {
+
+	return dbRemoveClaimIndexEntries(dbTx, blockClaimEntries(block))
+}
+
+// VerifyBlock checks that the claim index has both entries of every claim,
+// support and update operation made by the outputs of the passed block.
+//
+// This is part of the Verifier interface.
+func (idx *ClaimIndex) VerifyBlock(dbTx database.Tx, block *btcutil.Block,
+	stxos []blockchain.SpentTxOut) error {
+
+	indexBucket := dbTx.Metadata().Bucket(claimIndexKey)
+	byID := indexBucket.Bucket(claimByIDBucketName)
+	byName := indexBucket.Bucket(claimByNameBucketName)
+	for _, entry := range blockClaimEntries(block) {
+		wantID := append([]byte{byte(entry.Op)}, entry.Name...)
+		wantName := append([]byte{byte(entry.Op)}, entry.ClaimID...)
+		gotID := byID.Get(claimIDIndexKey(&entry))
+		gotName := byName.Get(claimNameIndexKey(&entry))
+		if !bytes.Equal(gotID, wantID) || !bytes.Equal(gotName, wantName) {
+			return MismatchError(fmt.Sprintf("%s entries of the "+
+				"%v of output %d of transaction %v in block "+
+				"%v are missing or don't match", claimIndexName,
+				entry.Op, entry.Index, entry.TxHash,
+				block.Hash()))
+		}
+	}
+
+	return nil
+}
+
+// ClaimHistoryByID returns the claim, support and update operations made on the
+// claim with the passed ID in the main chain in the order of the heights of
+// their blocks, starting with the operation which made the claim.
+//
+// This function is safe for concurrent access.
+func (idx *ClaimIndex) ClaimHistoryByID(claimID []byte) ([]ClaimEntry, error) {
+	var entries []ClaimEntry
+	err := idx.db.View(func(dbTx database.Tx) error {
+		var err error
+		entries, err = dbFetchClaimHistoryByID(dbTx, claimID)
+		return err
+	})
+	return entries, err
+}
+
+// ClaimHistoryByName returns the claim, support and update operations made on
+// the passed name in the main chain in the order of the heights of their
+// blocks.
+//
+// This function is safe for concurrent access.
+func (idx *ClaimIndex) ClaimHistoryByName(name []byte) ([]ClaimEntry, error) {
+	var entries []ClaimEntry
+	err := idx.db.View(func(dbTx database.Tx) error {
+		var err error
+		entries, err = dbFetchClaimHistoryByName(dbTx, name)
+		return err
+	})
+	return entries, err
+}
+
+// NewClaimIndex returns a new instance of an indexer that is used to create a
+// mapping of the IDs of all claims and all names in the blockchain to the
+// claim, support and update operations made on them.
+//
+// It implements the Indexer interface which plugs into the IndexManager that in
+// turn is used by the blockchain package.  This allows the index to be
+// seamlessly maintained along with the chain.
+func NewClaimIndex(db database.DB) *ClaimIndex {
+	return &ClaimIndex{db: db}
+}
+
+// DropClaimIndex drops the claim index from the provided database if it
+// exists.
+func DropClaimIndex(db database.DB, interrupt <-chan struct{}) error {
+	return dropIndex(db, claimIndexKey, claimIndexName, interrupt)
+}
+
+// ClaimIndexInitialized returns true if the claim index has been created
+// previously.
+func ClaimIndexInitialized(db database.DB) bool {
+	var exists bool
+	db.View(func(dbTx database.Tx) error {
+		bucket := dbTx.Metadata().Bucket(claimIndexKey)
+		exists = bucket != nil
+		return nil
+	})
+
+	return exists
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/v2"
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/database"
+	_ "github.com/btcsuite/btcd/database/ffldb"
+	"github.com/btcsuite/btcd/txscript/v2"
+	"github.com/btcsuite/btcd/wire/v2"
+)
+
+// TestClaimIndex ensures the claim index records the claim, support and update
+// operations of connected blocks by claim ID and by name, and forgets them
+// again when the blocks are disconnected.
+func TestClaimIndex(t *testing.T) {
+	db, err := database.Create("ffldb", filepath.Join(t.TempDir(), "db"),
+		wire.MainNet)
+	if err != nil {
+		t.Fatalf("unable to create database: %v", err)
+	}
+	defer db.Close()
+
+	idx := NewClaimIndex(db)
+	err = db.Update(func(dbTx database.Tx) error {
+		return idx.Create(dbTx)
+	})
+	if err != nil {
+		t.Fatalf("Create: unexpected error: %v", err)
+	}
+
+	// claimOutput returns an output with the passed claim prefix.
+	pkScript := []byte{txscript.OP_TRUE}
+	claimOutput := func(prefix []byte, err error) *wire.TxOut {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("unable to create claim script: %v", err)
+		}
+		return wire.NewTxOut(1000, append(prefix, pkScript...))
+	}
+	newBlock := func(height int32, txns ...*wire.MsgTx) *btcutil.Block {
+		coinbase := wire.NewMsgTx(wire.TxVersion)
+		coinbase.AddTxIn(wire.NewTxIn(wire.NewOutPoint(
+			&chainhash.Hash{}, wire.MaxPrevOutIndex), nil, nil))
+		coinbase.AddTxOut(wire.NewTxOut(5000000000, pkScript))
+		block := btcutil.NewBlock(&wire.MsgBlock{
+			Transactions: append([]*wire.MsgTx{coinbase}, txns...),
+		})
+		block.SetHeight(height)
+		return block
+	}
+
+	// The first block claims the names "a" and "ab" and the second one
+	// supports and updates the claim of "a".
+	claimTx := wire.NewMsgTx(wire.TxVersion)
+	claimTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: chainhash.Hash{1}},
+		nil, nil))
+	claimTx.AddTxOut(wire.NewTxOut(1000, pkScript))
+	claimTx.AddTxOut(claimOutput(txscript.NewClaimScript([]byte("a"),
+		[]byte("value"))))
+	claimTx.AddTxOut(claimOutput(txscript.NewClaimScript([]byte("ab"),
+		[]byte("value"))))
+	claimID := NewClaimID(&wire.OutPoint{Hash: claimTx.TxHash(), Index: 1})
+	otherID := NewClaimID(&wire.OutPoint{Hash: claimTx.TxHash(), Index: 2})
+	block1 := newBlock(100, claimTx)
+
+	updateTx := wire.NewMsgTx(wire.TxVersion)
+	updateTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: claimTx.TxHash(),
+		Index: 1}, nil, nil))
+	updateTx.AddTxOut(claimOutput(txscript.NewSupportScript([]byte("a"),
+		claimID, nil)))
+	updateTx.AddTxOut(claimOutput(txscript.NewUpdateScript([]byte("a"),
+		claimID, []byte("new value"))))
+	block2 := newBlock(105, updateTx)
+
+	for _, block := range []*btcutil.Block{block1, block2} {
+		err = db.Update(func(dbTx database.Tx) error {
+			return idx.ConnectBlock(dbTx, block, nil)
+		})
+		if err != nil {
+			t.Fatalf("ConnectBlock: unexpected error: %v", err)
+		}
+		err = db.View(func(dbTx database.Tx) error {
+			return idx.VerifyBlock(dbTx, block, nil)
+		})
+		if err != nil {
+			t.Fatalf("VerifyBlock: unexpected error: %v", err)
+		}
+	}
+
+	claimEntry := ClaimEntry{
+		Op:      ClaimOpClaim,
+		ClaimID: claimID,
+		Name:    []byte("a"),
+		TxHash:  claimTx.TxHash(),
+		Index:   1,
+		Height:  100,
+	}
+	supportEntry := ClaimEntry{
+		Op:      ClaimOpSupport,
+		ClaimID: claimID,
+		Name:    []byte("a"),
+		TxHash:  updateTx.TxHash(),
+		Index:   0,
+		Height:  105,
+	}
+	updateEntry := ClaimEntry{
+		Op:      ClaimOpUpdate,
+		ClaimID: claimID,
+		Name:    []byte("a"),
+		TxHash:  updateTx.TxHash(),
+		Index:   1,
+		Height:  105,
+	}
+	otherEntry := ClaimEntry{
+		Op:      ClaimOpClaim,
+		ClaimID: otherID,
+		Name:    []byte("ab"),
+		TxHash:  claimTx.TxHash(),
+		Index:   2,
+		Height:  100,
+	}
+
+	checkHistory := func(claimID, name []byte, want []ClaimEntry) {
+		t.Helper()
+
+		byID, err := idx.ClaimHistoryByID(claimID)
+		if err != nil {
+			t.Fatalf("ClaimHistoryByID: unexpected error: %v", err)
+		}
+		byName, err := idx.ClaimHistoryByName(name)
+		if err != nil {
+			t.Fatalf("ClaimHistoryByName: unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(byID, want) {
+			t.Fatalf("ClaimHistoryByID(%x): got %+v, want %+v",
+				claimID, byID, want)
+		}
+		if !reflect.DeepEqual(byName, want) {
+			t.Fatalf("ClaimHistoryByName(%q): got %+v, want %+v",
+				name, byName, want)
+		}
+	}
+	checkHistory(claimID, []byte("a"), []ClaimEntry{claimEntry,
+		supportEntry, updateEntry})
+	checkHistory(otherID, []byte("ab"), []ClaimEntry{otherEntry})
+
+	// Unknown claims and names and malformed IDs have no history.
+	unknownID := bytes.Repeat([]byte{0xff}, txscript.ClaimIDSize)
+	checkHistory(unknownID, []byte("b"), nil)
+	checkHistory(claimID[:4], []byte(""), nil)
+
+	// Ensure a missing entry is detected.
+	err = db.Update(func(dbTx database.Tx) error {
+		bucket := dbTx.Metadata().Bucket(claimIndexKey).
+			Bucket(claimByNameBucketName)
+		return bucket.Delete(claimNameIndexKey(&updateEntry))
+	})
+	if err != nil {
+		t.Fatalf("unable to delete entry: %v", err)
+	}
+	err = db.View(func(dbTx database.Tx) error {
+		return idx.VerifyBlock(dbTx, block2, nil)
+	})
+	if _, ok := err.(MismatchError); !ok {
+		t.Fatalf("VerifyBlock: got %v, want MismatchError", err)
+	}
+
+	// Disconnecting the second block leaves the history of the first.
+	err = db.Update(func(dbTx database.Tx) error {
+		return idx.DisconnectBlock(dbTx, block2, nil)
+	})
+	if err != nil {
+		t.Fatalf("DisconnectBlock: unexpected error: %v", err)
+	}
+	checkHistory(claimID, []byte("a"), []ClaimEntry{claimEntry})
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/btcsuite/btcd/blockchain"
 	"github.com/btcsuite/btcd/btcutil/v2"
+	"github.com/btcsuite/btcd/chainhash/v2"
 	"github.com/btcsuite/btcd/database"
 )
 
@@ -32,6 +33,25 @@ type NeedsInputser interface {
 	NeedsInputs() bool
 }
 
+// Pruner provides a generic interface for an indexer which removes the entries
+// of the blocks which are deleted when the database is pruned.
+type Pruner interface {
+	// PruneBlocks is invoked with the hashes of the blocks which are
+	// deleted from the database when it is pruned.
+	PruneBlocks(database.Tx, []chainhash.Hash) error
+}
+
+// Verifier provides a generic interface for an indexer which is able to check
+// its entries against the blocks they were created from.
+type Verifier interface {
+	// VerifyBlock checks that the entries of the index for the passed
+	// block, which is in the main chain and was connected to the index,
+	// match the block.  A MismatchError is returned when they don't.  The
+	// set of outputs spent within the block is also passed in for indexers
+	// which need the previous output scripts.
+	VerifyBlock(database.Tx, *btcutil.Block, []blockchain.SpentTxOut) error
+}
+
 // Indexer provides a generic interface for an indexer that is managed by an
 // index manager such as the Manager type provided by this package.
 type Indexer interface {
@@ -73,6 +93,16 @@ func (e AssertError) Error() string {
 	return "assertion failed: " + string(e)
 }
 
+// MismatchError identifies an entry of an index which doesn't match the block it
+// was created from.  It describes the first mismatch which was found.
+type MismatchError string
+
+// Error returns the mismatch error as a human-readable string and satisfies the
+// error interface.
+func (e MismatchError) Error() string {
+	return string(e)
+}
+
 // errDeserialize signifies that a problem was encountered when deserializing
 // data.
 type errDeserialize string
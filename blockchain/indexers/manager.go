@@ -6,7 +6,10 @@ package indexers
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/btcsuite/btcd/blockchain"
 	"github.com/btcsuite/btcd/btcutil/v2"
@@ -15,6 +18,12 @@ import (
 	"github.com/btcsuite/btcd/wire/v2"
 )
 
+const (
+	// catchUpRetryInterval is the interval at which the next block to
+	// index is looked up again when the chain doesn't know about it yet.
+	catchUpRetryInterval = 100 * time.Millisecond
+)
+
 var (
 	// indexTipsBucketName is the name of the db bucket used to house the
 	// current tip of each index.
@@ -132,11 +141,30 @@ func dbIndexDisconnectBlock(dbTx database.Tx, indexer Indexer, block *btcutil.Bl
 type Manager struct {
 	db             database.DB
 	enabledIndexes []Indexer
+
+	// background is whether the indexes which are behind the best chain
+	// tip are caught up by BuildIndexes instead of Init.
+	background bool
+
+	// chain is the chain the indexes are kept in step with.  It is set by
+	// Init.
+	chain *blockchain.BlockChain
+
+	// building tracks which of the enabled indexes are still being caught
+	// up to the best chain tip and dropping tracks which of them are being
+	// dropped in order to be rebuilt.  Indexes which are being dropped
+	// aren't updated at all.
+	mtx      sync.Mutex
+	building []bool
+	dropping []bool
 }
 
 // Ensure the Manager type implements the blockchain.IndexManager interface.
 var _ blockchain.IndexManager = (*Manager)(nil)
 
+// Ensure the Manager type implements the blockchain.IndexPruner interface.
+var _ blockchain.IndexPruner = (*Manager)(nil)
+
 // indexDropKey returns the key for an index which indicates it is in the
 // process of being dropped.
 func indexDropKey(idxKey []byte) []byte {
@@ -361,14 +389,17 @@ func (m *Manager) Init(chain *blockchain.BlockChain, interrupt <-chan struct{})
 		}
 	}
 
-	// Fetch the current tip heights for each index along with tracking the
-	// lowest one so the catchup code only needs to start at the earliest
-	// block and is able to skip connecting the block for the indexes that
-	// don't need it.
+	// Mark the indexes which are behind the current best chain tip as
+	// being built so they are caught up below or in the background.  The
+	// indexes which need the spent outputs can't be caught up past pruned
+	// spend journals, so refuse to start with them rather than failing
+	// part way through catching them up.
 	bestHeight := chain.BestSnapshot().Height
 	lowestHeight := bestHeight
-	indexerHeights := make([]int32, len(m.enabledIndexes))
+	spendJournalPruneHeight := chain.SpendJournalPruneHeight()
 	err = m.db.View(func(dbTx database.Tx) error {
+		m.mtx.Lock()
+		defer m.mtx.Unlock()
 		for i, indexer := range m.enabledIndexes {
 			idxKey := indexer.Key()
 			hash, height, err := dbFetchIndexerTip(dbTx, idxKey)
@@ -378,7 +409,17 @@ func (m *Manager) Init(chain *blockchain.BlockChain, interrupt <-chan struct{})
 
 			log.Debugf("Current %s tip (height %d, hash %v)",
 				indexer.Name(), height, hash)
-			indexerHeights[i] = height
+			if indexNeedsInputs(indexer) &&
+				spendJournalPruneHeight > 0 &&
+				height < spendJournalPruneHeight {
+
+				return fmt.Errorf("the %s at height %d can't be "+
+					"caught up since the spend journals up to "+
+					"height %d have been pruned -- disable "+
+					"the index", indexer.Name(), height,
+					spendJournalPruneHeight)
+			}
+			m.building[i] = height < bestHeight
 			if height < lowestHeight {
 				lowestHeight = height
 			}
@@ -388,71 +429,465 @@ func (m *Manager) Init(chain *blockchain.BlockChain, interrupt <-chan struct{})
 	if err != nil {
 		return err
 	}
+	m.chain = chain
 
 	// Nothing to index if all of the indexes are caught up.
 	if lowestHeight == bestHeight {
 		return nil
 	}
 
-	// Create a progress logger for the indexing process below.
-	progressLogger := newBlockProgressLogger("Indexed", log)
+	// Leave the indexes to BuildIndexes when they are built in the
+	// background.
+	if m.background {
+		log.Infof("Building indexes from height %d to %d in the "+
+			"background", lowestHeight, bestHeight)
+		return nil
+	}
 
 	// At this point, one or more indexes are behind the current best chain
-	// tip and need to be caught up, so log the details and loop through
-	// each block that needs to be indexed.
+	// tip and need to be caught up, so log the details and catch them up.
 	log.Infof("Catching up indexes from height %d to %d", lowestHeight,
 		bestHeight)
-	for height := lowestHeight + 1; height <= bestHeight; height++ {
-		// Load the block for the height since it is required to index
-		// it.
-		block, err := chain.BlockByHeight(height)
+	if err := m.catchUp(interrupt); err != nil {
+		return err
+	}
+
+	log.Infof("Indexes caught up to height %d", bestHeight)
+	return nil
+}
+
+// isBuilding returns whether the enabled index with the passed position is
+// still being caught up to the best chain tip.
+//
+// This function is safe for concurrent access.
+func (m *Manager) isBuilding(i int) bool {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.building[i]
+}
+
+// isDropping returns whether the enabled index with the passed position is
+// being dropped in order to be rebuilt.
+//
+// This function is safe for concurrent access.
+func (m *Manager) isDropping(i int) bool {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.dropping[i]
+}
+
+// catchUp connects the blocks of the main chain to the indexes which are being
+// built until all of them are caught up to the best chain tip.  The chain may
+// be extended or reorganized while the indexes are caught up since the indexes
+// which are being built are only updated by ConnectBlock and DisconnectBlock
+// when their tips line up with the block.
+func (m *Manager) catchUp(interrupt <-chan struct{}) error {
+	progressLogger := newBlockProgressLogger("Indexed", log)
+	for {
+		if interruptRequested(interrupt) {
+			return errInterruptRequested
+		}
+
+		// Mark the indexes whose tips are the best chain tip as of the
+		// database as caught up and find the lowest tip of the rest.
+		// The indexes which are caught up are kept in step with the
+		// chain by ConnectBlock and DisconnectBlock from then on.
+		lowestHeight := int32(-2)
+		var needsInputs bool
+		err := m.db.View(func(dbTx database.Tx) error {
+			m.mtx.Lock()
+			defer m.mtx.Unlock()
+			for i, indexer := range m.enabledIndexes {
+				if !m.building[i] || m.dropping[i] {
+					continue
+				}
+
+				_, height, err := dbFetchIndexerTip(dbTx, indexer.Key())
+				if err != nil {
+					return err
+				}
+				if blockchain.DBFetchMainChainHash(dbTx, height+1) == nil {
+					log.Infof("Caught up %s to height %d",
+						indexer.Name(), height)
+					m.building[i] = false
+					continue
+				}
+				if lowestHeight == -2 || height < lowestHeight {
+					lowestHeight = height
+				}
+				needsInputs = needsInputs || indexNeedsInputs(indexer)
+			}
+			return nil
+		})
 		if err != nil {
 			return err
 		}
 
-		if interruptRequested(interrupt) {
-			return errInterruptRequested
+		// Nothing left to do once all of the indexes are caught up.
+		if lowestHeight == -2 {
+			return nil
 		}
 
-		// Connect the block for all indexes that need it.
+		// Load the next block along with the outputs it spends when
+		// any of the indexes requires them.  The chain only learns
+		// about a new best chain tip once it is committed to the
+		// database, so wait for it when the block isn't known yet.
+		height := lowestHeight + 1
+		block, err := m.chain.BlockByHeight(height)
+		if err != nil {
+			if height <= m.chain.BestSnapshot().Height {
+				return err
+			}
+			select {
+			case <-interrupt:
+				return errInterruptRequested
+			case <-time.After(catchUpRetryInterval):
+			}
+			continue
+		}
 		var spentTxos []blockchain.SpentTxOut
-		for i, indexer := range m.enabledIndexes {
-			// Skip indexes that don't need to be updated with this
-			// block.
-			if indexerHeights[i] >= height {
-				continue
+		if needsInputs {
+			spentTxos, err = m.chain.FetchSpendJournal(block)
+			if err != nil {
+				// The block was disconnected in the meantime.
+				if !m.chain.MainChainHasBlock(block.Hash()) {
+					continue
+				}
+				return err
+			}
+		}
+
+		err = m.db.Update(func(dbTx database.Tx) error {
+			// Skip the block when the chain was reorganized in the
+			// meantime.
+			hash := blockchain.DBFetchMainChainHash(dbTx, height)
+			if hash == nil || *hash != *block.Hash() {
+				return nil
 			}
 
-			// When the index requires all of the referenced txouts
-			// and they haven't been loaded yet, they need to be
-			// retrieved from the spend journal.
-			if spentTxos == nil && indexNeedsInputs(indexer) {
-				spentTxos, err = chain.FetchSpendJournal(block)
+			// Connect the block for all indexes that need it.
+			prevHash := &block.MsgBlock().Header.PrevBlock
+			for i, indexer := range m.enabledIndexes {
+				if !m.isBuilding(i) || m.isDropping(i) {
+					continue
+				}
+				tipHash, _, err := dbFetchIndexerTip(dbTx,
+					indexer.Key())
+				if err != nil {
+					return err
+				}
+				if *tipHash != *prevHash {
+					continue
+				}
+
+				err = dbIndexConnectBlock(dbTx, indexer, block,
+					spentTxos)
 				if err != nil {
 					return err
 				}
 			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
 
-			err := m.db.Update(func(dbTx database.Tx) error {
-				return dbIndexConnectBlock(
-					dbTx, indexer, block, spentTxos,
-				)
-			})
+		// Log indexing progress.
+		progressLogger.LogBlockHeight(block)
+	}
+}
+
+// BuildIndexes catches up the indexes which were behind the best chain tip when
+// the manager was initialized while blocks continue to be connected to and
+// disconnected from the main chain.  It returns once all of them are caught up
+// or the passed channel is closed.  It is only useful for managers created with
+// NewBackgroundManager since the indexes are already caught up by Init
+// otherwise.
+func (m *Manager) BuildIndexes(interrupt <-chan struct{}) error {
+	if m.chain == nil {
+		return nil
+	}
+
+	err := m.catchUp(interrupt)
+	if err != nil {
+		return err
+	}
+	log.Infof("Indexes caught up to height %d",
+		m.chain.BestSnapshot().Height)
+	return nil
+}
+
+// IndexInfo describes the state of an index.
+type IndexInfo struct {
+	// Name is the human-readable name of the index.
+	Name string
+
+	// Height is the height of the last block which was indexed.
+	Height int32
+
+	// Synced is whether the index is caught up to the best chain tip.
+	// Indexes which are still being built in the background aren't.
+	Synced bool
+}
+
+// VerifyResult describes the outcome of verifying an index.
+type VerifyResult struct {
+	// Name is the human-readable name of the index.
+	Name string
+
+	// Height is the height of the last block which was indexed and Checked
+	// is the number of blocks up to it which were verified.
+	Height  int32
+	Checked int32
+
+	// Mismatch describes the first entry of the index which doesn't match
+	// its block.  It is empty when all of the checked blocks match.
+	Mismatch string
+}
+
+// IndexInfo returns the state of the enabled indexes.
+//
+// This function is safe for concurrent access.
+func (m *Manager) IndexInfo() ([]IndexInfo, error) {
+	infos := make([]IndexInfo, 0, len(m.enabledIndexes))
+	err := m.db.View(func(dbTx database.Tx) error {
+		for i, indexer := range m.enabledIndexes {
+			// Indexes which are being dropped have no tip.
+			if m.isDropping(i) {
+				infos = append(infos, IndexInfo{
+					Name:   indexer.Name(),
+					Height: -1,
+				})
+				continue
+			}
+
+			_, height, err := dbFetchIndexerTip(dbTx, indexer.Key())
 			if err != nil {
 				return err
 			}
-			indexerHeights[i] = height
+			infos = append(infos, IndexInfo{
+				Name:   indexer.Name(),
+				Height: height,
+				Synced: !m.isBuilding(i),
+			})
 		}
+		return nil
+	})
+	return infos, err
+}
 
-		// Log indexing progress.
-		progressLogger.LogBlockHeight(block)
+// indexByName returns the position of the enabled index with the passed
+// human-readable name.
+func (m *Manager) indexByName(name string) (int, error) {
+	for i, indexer := range m.enabledIndexes {
+		if indexer.Name() == name {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("the %s is not enabled", name)
+}
 
+// VerifyIndex checks the entries of the enabled index with the passed
+// human-readable name for the last depth blocks it indexed, or for all of them
+// when depth is zero, against the blocks in the database.  The first entry
+// which doesn't match its block is described by the Mismatch field of the
+// result.  Blocks which are disconnected from the main chain while the index is
+// verified are skipped.  On pruned nodes, only the blocks which are still stored
+// are verified, along with their spend journals when the index needs them.
+//
+// This function is safe for concurrent access.
+func (m *Manager) VerifyIndex(name string, depth int32,
+	interrupt <-chan struct{}) (*VerifyResult, error) {
+
+	i, err := m.indexByName(name)
+	if err != nil {
+		return nil, err
+	}
+	indexer := m.enabledIndexes[i]
+	verifier, ok := indexer.(Verifier)
+	if !ok {
+		return nil, fmt.Errorf("the %s can't be verified", name)
+	}
+	if m.chain == nil {
+		return nil, fmt.Errorf("the %s is not initialized", name)
+	}
+	if m.isDropping(i) {
+		return nil, fmt.Errorf("the %s is being rebuilt", name)
+	}
+
+	var tipHeight int32
+	err = m.db.View(func(dbTx database.Tx) error {
+		var err error
+		_, tipHeight, err = dbFetchIndexerTip(dbTx, indexer.Key())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &VerifyResult{Name: name, Height: tipHeight}
+	startHeight := int32(0)
+	if depth > 0 && tipHeight-depth+1 > 0 {
+		startHeight = tipHeight - depth + 1
+	}
+	retainedHeight, err := m.chain.PruneHeight()
+	if err != nil {
+		return nil, err
+	}
+	spendJournalPruneHeight := m.chain.SpendJournalPruneHeight()
+	if indexNeedsInputs(indexer) && spendJournalPruneHeight > 0 &&
+		spendJournalPruneHeight+1 > retainedHeight {
+
+		retainedHeight = spendJournalPruneHeight + 1
+	}
+	if startHeight < retainedHeight {
+		startHeight = retainedHeight
+	}
+	for height := startHeight; height <= tipHeight; height++ {
 		if interruptRequested(interrupt) {
-			return errInterruptRequested
+			return nil, errInterruptRequested
+		}
+
+		// Load the block along with the outputs it spends when the
+		// index requires them.
+		block, err := m.chain.BlockByHeight(height)
+		if err != nil {
+			// The block was disconnected in the meantime.
+			if height > m.chain.BestSnapshot().Height {
+				break
+			}
+			return nil, err
+		}
+		var spentTxos []blockchain.SpentTxOut
+		if indexNeedsInputs(indexer) {
+			spentTxos, err = m.chain.FetchSpendJournal(block)
+			if err != nil {
+				if !m.chain.MainChainHasBlock(block.Hash()) {
+					continue
+				}
+				return nil, err
+			}
+		}
+
+		var checked bool
+		err = m.db.View(func(dbTx database.Tx) error {
+			// Skip the block when the chain was reorganized or the
+			// index was rebuilt in the meantime.
+			hash := blockchain.DBFetchMainChainHash(dbTx, height)
+			if hash == nil || *hash != *block.Hash() ||
+				m.isDropping(i) {
+
+				return nil
+			}
+			_, curHeight, err := dbFetchIndexerTip(dbTx,
+				indexer.Key())
+			if err != nil || curHeight < height {
+				return err
+			}
+
+			checked = true
+			return verifier.VerifyBlock(dbTx, block, spentTxos)
+		})
+		var mismatch MismatchError
+		if errors.As(err, &mismatch) {
+			result.Mismatch = mismatch.Error()
+			result.Checked++
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if checked {
+			result.Checked++
 		}
 	}
 
-	log.Infof("Indexes caught up to height %d", bestHeight)
+	return result, nil
+}
+
+// RebuildIndex drops the enabled index with the passed human-readable name and
+// builds it again from the blocks of the main chain while the other indexes
+// are kept in step with the chain as usual.  Since the address index relies on
+// the internal block IDs of the transaction index, the address index is rebuilt
+// as well when the transaction index is.  An interrupted rebuild is finished
+// the next time the manager is initialized.  Indexes can't be rebuilt once the
+// blocks, or the spend journals they need, have been pruned.
+//
+// This function is safe for concurrent access.
+func (m *Manager) RebuildIndex(name string, interrupt <-chan struct{}) error {
+	i, err := m.indexByName(name)
+	if err != nil {
+		return err
+	}
+	if m.chain == nil {
+		return fmt.Errorf("the %s is not initialized", name)
+	}
+	rebuild := []int{i}
+	if name == txIndexName {
+		if addrIdx, err := m.indexByName(addrIndexName); err == nil {
+			rebuild = append([]int{addrIdx}, rebuild...)
+		}
+	}
+	pruneHeight, err := m.chain.PruneHeight()
+	if err != nil {
+		return err
+	}
+	for _, j := range rebuild {
+		indexer := m.enabledIndexes[j]
+		if pruneHeight > 0 || (indexNeedsInputs(indexer) &&
+			m.chain.SpendJournalPruneHeight() > 0) {
+
+			return fmt.Errorf("the %s can't be rebuilt from pruned "+
+				"blocks", indexer.Name())
+		}
+	}
+
+	// Stop updating the indexes while they are dropped.
+	m.mtx.Lock()
+	for _, j := range rebuild {
+		if m.dropping[j] {
+			m.mtx.Unlock()
+			return fmt.Errorf("the %s is already being rebuilt",
+				m.enabledIndexes[j].Name())
+		}
+	}
+	for _, j := range rebuild {
+		m.building[j] = true
+		m.dropping[j] = true
+	}
+	m.mtx.Unlock()
+
+	for _, j := range rebuild {
+		indexer := m.enabledIndexes[j]
+		err := dropIndex(m.db, indexer.Key(), indexer.Name(), interrupt)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Create the indexes again and catch them up to the best chain tip.
+	err = m.db.Update(func(dbTx database.Tx) error {
+		return m.maybeCreateIndexes(dbTx)
+	})
+	if err != nil {
+		return err
+	}
+	for _, j := range rebuild {
+		if err := m.enabledIndexes[j].Init(); err != nil {
+			return err
+		}
+	}
+	m.mtx.Lock()
+	for _, j := range rebuild {
+		m.dropping[j] = false
+	}
+	m.mtx.Unlock()
+
+	log.Infof("Rebuilding %s", name)
+	if err := m.catchUp(interrupt); err != nil {
+		return err
+	}
+	log.Infof("Rebuilt %s", name)
 	return nil
 }
 
@@ -503,8 +938,24 @@ func (m *Manager) ConnectBlock(dbTx database.Tx, block *btcutil.Block,
 	stxos []blockchain.SpentTxOut) error {
 
 	// Call each of the currently active optional indexes with the block
-	// being connected so they can update accordingly.
-	for _, index := range m.enabledIndexes {
+	// being connected so they can update accordingly.  The indexes which
+	// are still being built are skipped unless they are caught up to the
+	// previous block.
+	prevHash := &block.MsgBlock().Header.PrevBlock
+	for i, index := range m.enabledIndexes {
+		if m.isDropping(i) {
+			continue
+		}
+		if m.isBuilding(i) {
+			tipHash, _, err := dbFetchIndexerTip(dbTx, index.Key())
+			if err != nil {
+				return err
+			}
+			if *tipHash != *prevHash {
+				continue
+			}
+		}
+
 		err := dbIndexConnectBlock(dbTx, index, block, stxos)
 		if err != nil {
 			return err
@@ -523,8 +974,22 @@ func (m *Manager) DisconnectBlock(dbTx database.Tx, block *btcutil.Block,
 	stxo []blockchain.SpentTxOut) error {
 
 	// Call each of the currently active optional indexes with the block
-	// being disconnected so they can update accordingly.
-	for _, index := range m.enabledIndexes {
+	// being disconnected so they can update accordingly.  The indexes which
+	// are still being built are skipped unless the block is their tip.
+	for i, index := range m.enabledIndexes {
+		if m.isDropping(i) {
+			continue
+		}
+		if m.isBuilding(i) {
+			tipHash, _, err := dbFetchIndexerTip(dbTx, index.Key())
+			if err != nil {
+				return err
+			}
+			if *tipHash != *block.Hash() {
+				continue
+			}
+		}
+
 		err := dbIndexDisconnectBlock(dbTx, index, block, stxo)
 		if err != nil {
 			return err
@@ -533,6 +998,25 @@ func (m *Manager) DisconnectBlock(dbTx database.Tx, block *btcutil.Block,
 	return nil
 }
 
+// PruneBlocks must be invoked when blocks are deleted from the database by
+// pruning.  It invokes each index which implements the Pruner interface so it
+// can remove the entries of the deleted blocks.
+//
+// This is part of the blockchain.IndexPruner interface.
+func (m *Manager) PruneBlocks(dbTx database.Tx, deletedHashes []chainhash.Hash) error {
+	for i, index := range m.enabledIndexes {
+		pruner, ok := index.(Pruner)
+		if !ok || m.isDropping(i) {
+			continue
+		}
+
+		if err := pruner.PruneBlocks(dbTx, deletedHashes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // NewManager returns a new index manager with the provided indexes enabled.
 //
 // The manager returned satisfies the blockchain.IndexManager interface and thus
@@ -541,9 +1025,22 @@ func NewManager(db database.DB, enabledIndexes []Indexer) *Manager {
 	return &Manager{
 		db:             db,
 		enabledIndexes: enabledIndexes,
+		building:       make([]bool, len(enabledIndexes)),
+		dropping:       make([]bool, len(enabledIndexes)),
 	}
 }
 
+// NewBackgroundManager returns a new index manager with the provided indexes
+// enabled which doesn't catch up the indexes that are behind the best chain tip
+// when it is initialized.  BuildIndexes must be invoked to catch them up in the
+// background instead, which allows indexes to be enabled on a synced node
+// without delaying its startup.
+func NewBackgroundManager(db database.DB, enabledIndexes []Indexer) *Manager {
+	m := NewManager(db, enabledIndexes)
+	m.background = true
+	return m
+}
+
 // dropIndex drops the passed index from the database.  Since indexes can be
 // massive, it deletes the index in multiple database transactions in order to
 // keep memory usage to reasonable levels.  It also marks the drop in progress
@@ -0,0 +1,281 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/blockchain/internal/testhelper"
+	"github.com/btcsuite/btcd/btcutil/v2"
+	"github.com/btcsuite/btcd/chaincfg/v2"
+	"github.com/btcsuite/btcd/database"
+	_ "github.com/btcsuite/btcd/database/ffldb"
+	"github.com/btcsuite/btcd/wire/v2"
+)
+
+// connectBlocks extends the main chain of the passed chain by the passed number
+// of blocks which only contain a coinbase transaction.
+func connectBlocks(t *testing.T, chain *blockchain.BlockChain,
+	params *chaincfg.Params, count int) {
+
+	t.Helper()
+
+	for i := 0; i < count; i++ {
+		best := chain.BestSnapshot()
+		prev, err := chain.BlockByHash(&best.Hash)
+		if err != nil {
+			t.Fatalf("unable to fetch best block: %v", err)
+		}
+
+		height := best.Height + 1
+		coinbase := testhelper.CreateCoinbaseTx(height,
+			blockchain.CalcBlockSubsidy(height, params))
+		txns := []*btcutil.Tx{btcutil.NewTx(coinbase)}
+		block := wire.MsgBlock{
+			Header: wire.BlockHeader{
+				Version:    4,
+				PrevBlock:  best.Hash,
+				MerkleRoot: blockchain.CalcMerkleRoot(txns, false),
+				Timestamp: prev.MsgBlock().Header.Timestamp.Add(
+					time.Second),
+				Bits: params.PowLimitBits,
+			},
+			Transactions: []*wire.MsgTx{coinbase},
+		}
+		if !testhelper.SolveBlock(&block.Header) {
+			t.Fatalf("unable to solve block at height %d", height)
+		}
+
+		_, isOrphan, err := chain.ProcessBlock(btcutil.NewBlock(&block),
+			blockchain.BFNone)
+		if err != nil || isOrphan {
+			t.Fatalf("unable to connect block at height %d: %v "+
+				"(orphan %v)", height, err, isOrphan)
+		}
+	}
+}
+
+// TestBackgroundManager ensures an index which is enabled on a chain that
+// already has blocks is only caught up by BuildIndexes while the chain keeps
+// being extended.
+func TestBackgroundManager(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	db, err := database.Create("ffldb", filepath.Join(t.TempDir(), "db"),
+		params.Net)
+	if err != nil {
+		t.Fatalf("unable to create database: %v", err)
+	}
+	defer db.Close()
+
+	// Build a chain without any indexes.
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:               db,
+		ChainParams:      params,
+		TimeSource:       blockchain.NewMedianTime(),
+		UtxoCacheMaxSize: 1024 * 1024,
+	})
+	if err != nil {
+		t.Fatalf("unable to create chain: %v", err)
+	}
+	connectBlocks(t, chain, params, 20)
+	if err := chain.FlushUtxoCache(blockchain.FlushRequired); err != nil {
+		t.Fatalf("unable to flush utxo cache: %v", err)
+	}
+
+	// Enable the transaction index.  It must not be caught up when the
+	// chain is loaded.
+	txIndex := NewTxIndex(db)
+	manager := NewBackgroundManager(db, []Indexer{txIndex})
+	chain, err = blockchain.New(&blockchain.Config{
+		DB:               db,
+		ChainParams:      params,
+		TimeSource:       blockchain.NewMedianTime(),
+		UtxoCacheMaxSize: 1024 * 1024,
+		IndexManager:     manager,
+	})
+	if err != nil {
+		t.Fatalf("unable to create chain: %v", err)
+	}
+	checkInfo := func(height int32, synced bool) {
+		t.Helper()
+
+		infos, err := manager.IndexInfo()
+		if err != nil {
+			t.Fatalf("IndexInfo: unexpected error: %v", err)
+		}
+		want := IndexInfo{Name: txIndexName, Height: height, Synced: synced}
+		if len(infos) != 1 || infos[0] != want {
+			t.Fatalf("IndexInfo: got %+v, want %+v", infos, want)
+		}
+	}
+	checkInfo(-1, false)
+
+	// Blocks connected while the index is being built are left to
+	// BuildIndexes.
+	connectBlocks(t, chain, params, 5)
+	checkInfo(-1, false)
+
+	if err := manager.BuildIndexes(nil); err != nil {
+		t.Fatalf("BuildIndexes: unexpected error: %v", err)
+	}
+	checkInfo(25, true)
+	block, err := chain.BlockByHeight(3)
+	if err != nil {
+		t.Fatalf("unable to fetch block: %v", err)
+	}
+	region, err := txIndex.TxBlockRegion(block.Transactions()[0].Hash())
+	if err != nil || region == nil {
+		t.Fatalf("TxBlockRegion: got %v, %v for indexed transaction",
+			region, err)
+	}
+
+	// The index is kept in step with the chain once it is caught up.
+	connectBlocks(t, chain, params, 1)
+	checkInfo(26, true)
+}
+
+// TestVerifyRebuildIndex ensures VerifyIndex finds entries of an index which
+// don't match their blocks and RebuildIndex builds the index again.
+func TestVerifyRebuildIndex(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	db, err := database.Create("ffldb", filepath.Join(t.TempDir(), "db"),
+		params.Net)
+	if err != nil {
+		t.Fatalf("unable to create database: %v", err)
+	}
+	defer db.Close()
+
+	indexes := []Indexer{
+		NewTxIndex(db),
+		NewAddrIndex(db, params),
+		NewCfIndex(db, params),
+		NewSpentIndex(db),
+	}
+	manager := NewManager(db, indexes)
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:               db,
+		ChainParams:      params,
+		TimeSource:       blockchain.NewMedianTime(),
+		UtxoCacheMaxSize: 1024 * 1024,
+		IndexManager:     manager,
+	})
+	if err != nil {
+		t.Fatalf("unable to create chain: %v", err)
+	}
+	connectBlocks(t, chain, params, 20)
+
+	verify := func(name string, depth int32, wantChecked int32,
+		wantMismatch bool) {
+
+		t.Helper()
+
+		result, err := manager.VerifyIndex(name, depth, nil)
+		if err != nil {
+			t.Fatalf("VerifyIndex(%s): unexpected error: %v", name, err)
+		}
+		if result.Name != name || result.Height != 20 ||
+			result.Checked != wantChecked ||
+			(result.Mismatch != "") != wantMismatch {
+
+			t.Fatalf("VerifyIndex(%s, %d): got %+v, want %d blocks "+
+				"checked (mismatch %v)", name, depth, result,
+				wantChecked, wantMismatch)
+		}
+	}
+	for _, indexer := range indexes {
+		verify(indexer.Name(), 0, 21, false)
+	}
+	if _, err := manager.VerifyIndex("unknown index", 0, nil); err == nil {
+		t.Fatal("VerifyIndex: expected error for unknown index")
+	}
+
+	// Remove the transaction index entry of the coinbase of block 5.  Only
+	// verifying the whole index finds it.
+	block, err := chain.BlockByHeight(5)
+	if err != nil {
+		t.Fatalf("unable to fetch block: %v", err)
+	}
+	err = db.Update(func(dbTx database.Tx) error {
+		bucket := dbTx.Metadata().Bucket(txIndexKey)
+		return bucket.Delete(block.Transactions()[0].Hash()[:])
+	})
+	if err != nil {
+		t.Fatalf("unable to remove index entry: %v", err)
+	}
+	verify(txIndexName, 10, 10, false)
+	verify(txIndexName, 0, 6, true)
+
+	// Rebuilding the transaction index also rebuilds the address index
+	// and the rebuilt indexes are kept in step with the chain.
+	if err := manager.RebuildIndex(txIndexName, nil); err != nil {
+		t.Fatalf("RebuildIndex: unexpected error: %v", err)
+	}
+	for _, indexer := range indexes {
+		verify(indexer.Name(), 0, 21, false)
+	}
+	connectBlocks(t, chain, params, 1)
+	infos, err := manager.IndexInfo()
+	if err != nil {
+		t.Fatalf("IndexInfo: unexpected error: %v", err)
+	}
+	for _, info := range infos {
+		if info.Height != 21 || !info.Synced {
+			t.Fatalf("IndexInfo: got %+v after rebuild", info)
+		}
+	}
+}
+
+// TestPrunedSpendJournalIndex ensures an index which needs the spent outputs is
+// refused when it is behind the pruned spend journals, while it is kept up to
+// date when it is enabled before they are pruned.
+func TestPrunedSpendJournalIndex(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	db, err := database.Create("ffldb", filepath.Join(t.TempDir(), "db"),
+		params.Net)
+	if err != nil {
+		t.Fatalf("unable to create database: %v", err)
+	}
+	defer db.Close()
+
+	// Build a chain with the committed filter index while pruning the
+	// spend journals.
+	newChain := func(indexers ...Indexer) (*blockchain.BlockChain, error) {
+		return blockchain.New(&blockchain.Config{
+			DB:                db,
+			ChainParams:       params,
+			TimeSource:        blockchain.NewMedianTime(),
+			UtxoCacheMaxSize:  1024 * 1024,
+			SpendJournalDepth: 5,
+			IndexManager:      NewManager(db, indexers),
+		})
+	}
+	chain, err := newChain(NewCfIndex(db, params))
+	if err != nil {
+		t.Fatalf("unable to create chain: %v", err)
+	}
+	connectBlocks(t, chain, params, 20)
+	if chain.SpendJournalPruneHeight() == 0 {
+		t.Fatal("spend journals were not pruned")
+	}
+	if err := chain.FlushUtxoCache(blockchain.FlushRequired); err != nil {
+		t.Fatalf("unable to flush utxo cache: %v", err)
+	}
+
+	// The index which was kept up to date is loaded again.
+	if _, err := newChain(NewCfIndex(db, params)); err != nil {
+		t.Fatalf("unable to load chain with caught up index: %v", err)
+	}
+
+	// The address index which was never built is refused.
+	if _, err := newChain(NewCfIndex(db, params),
+		NewAddrIndex(db, params)); err == nil {
+
+		t.Fatal("chain loaded with an index behind the pruned spend " +
+			"journals")
+	}
+}
@@ -0,0 +1,308 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil/v2"
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/database"
+	"github.com/btcsuite/btcd/wire/v2"
+)
+
+const (
+	// spentIndexName is the human-readable name for the index.
+	spentIndexName = "spent output index"
+
+	// spentIndexKeySize is the size of the serialized outpoints which are
+	// the keys of the spent output index.  It consists of the hash of the
+	// transaction and the index of the output.
+	spentIndexKeySize = chainhash.HashSize + 4
+
+	// spentIndexEntrySize is the size of a spent output index entry.  It
+	// consists of the hash of the spending transaction, the index of the
+	// spending input and the height of the block containing the spending
+	// transaction.
+	spentIndexEntrySize = chainhash.HashSize + 4 + 4
+)
+
+var (
+	// spentIndexKey is the key of the spent output index and the db bucket
+	// used to house it.
+	spentIndexKey = []byte("spentbyoutpointidx")
+)
+
+// -----------------------------------------------------------------------------
+// The spent output index consists of an entry for every output spent in the
+// main chain which maps the outpoint of the output to the input which spent it.
+//
+// The serialized key format is:
+//
+//   <hash><index>
+//
+//   Field           Type             Size
+//   hash            chainhash.Hash   32
+//   index           uint32           4
+//   -----
+//   Total: 36 bytes
+//
+// The serialized value format is:
+//
+//   <spending hash><input index><block height>
+//
+//   Field           Type             Size
+//   spending hash   chainhash.Hash   32
+//   input index     uint32           4
+//   block height    uint32           4
+//   -----
+//   Total: 40 bytes
+// -----------------------------------------------------------------------------
+
+// SpentInfo identifies the input which spent an output.
+type SpentInfo struct {
+	// TxHash is the hash of the spending transaction and InputIndex is the
+	// index of the spending input within it.
+	TxHash     chainhash.Hash
+	InputIndex uint32
+
+	// Height is the height of the block containing the spending
+	// transaction.
+	Height int32
+}
+
+// spentIndexKeyFor returns the spent output index key of the passed outpoint.
+func spentIndexKeyFor(outPoint *wire.OutPoint) [spentIndexKeySize]byte {
+	var key [spentIndexKeySize]byte
+	copy(key[:], outPoint.Hash[:])
+	byteOrder.PutUint32(key[chainhash.HashSize:], outPoint.Index)
+	return key
+}
+
+// putSpentIndexEntry serializes the passed spent info into the passed target
+// byte slice.  The target byte slice must be at least large enough to handle
+// the number of bytes defined by the spentIndexEntrySize constant or it will
+// panic.
+func putSpentIndexEntry(target []byte, info *SpentInfo) {
+	copy(target, info.TxHash[:])
+	byteOrder.PutUint32(target[chainhash.HashSize:], info.InputIndex)
+	byteOrder.PutUint32(target[chainhash.HashSize+4:], uint32(info.Height))
+}
+
+// deserializeSpentIndexEntry deserializes the passed spent output index entry.
+func deserializeSpentIndexEntry(serialized []byte) (*SpentInfo, error) {
+	if len(serialized) < spentIndexEntrySize {
+		return nil, errDeserialize("unexpected end of data")
+	}
+
+	var info SpentInfo
+	copy(info.TxHash[:], serialized[:chainhash.HashSize])
+	info.InputIndex = byteOrder.Uint32(serialized[chainhash.HashSize:])
+	info.Height = int32(byteOrder.Uint32(serialized[chainhash.HashSize+4:]))
+	return &info, nil
+}
+
+// dbFetchSpentIndexEntry uses an existing database transaction to fetch the
+// input which spent the passed outpoint.  When there is no entry for the
+// outpoint, nil will be returned for both the entry and the error.
+func dbFetchSpentIndexEntry(dbTx database.Tx, outPoint *wire.OutPoint) (*SpentInfo, error) {
+	key := spentIndexKeyFor(outPoint)
+	serializedData := dbTx.Metadata().Bucket(spentIndexKey).Get(key[:])
+	if len(serializedData) == 0 {
+		return nil, nil
+	}
+
+	info, err := deserializeSpentIndexEntry(serializedData)
+	if err != nil {
+		return nil, database.Error{
+			ErrorCode: database.ErrCorruption,
+			Description: fmt.Sprintf("corrupt spent output index "+
+				"entry for %v: %v", outPoint, err),
+		}
+	}
+	return info, nil
+}
+
+// dbAddSpentIndexEntries uses an existing database transaction to add a spent
+// output index entry for every output spent by the passed block.
+func dbAddSpentIndexEntries(bucket internalBucket, block *btcutil.Block) error {
+	var info SpentInfo
+	info.Height = block.Height()
+	for _, tx := range block.Transactions()[1:] {
+		info.TxHash = *tx.Hash()
+		for i, txIn := range tx.MsgTx().TxIn {
+			info.InputIndex = uint32(i)
+			key := spentIndexKeyFor(&txIn.PreviousOutPoint)
+			var serialized [spentIndexEntrySize]byte
+			putSpentIndexEntry(serialized[:], &info)
+			if err := bucket.Put(key[:], serialized[:]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// dbRemoveSpentIndexEntries uses an existing database transaction to remove the
+// spent output index entries of all outputs spent by the passed block.
+func dbRemoveSpentIndexEntries(bucket internalBucket, block *btcutil.Block) error {
+	for _, tx := range block.Transactions()[1:] {
+		for _, txIn := range tx.MsgTx().TxIn {
+			key := spentIndexKeyFor(&txIn.PreviousOutPoint)
+			if err := bucket.Delete(key[:]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// SpentIndex implements a spent output index.  That is to say, it supports
+// querying the input which spent an output by the outpoint of the output.
+type SpentIndex struct {
+	db database.DB
+}
+
+// Ensure the SpentIndex type implements the Indexer interface.
+var _ Indexer = (*SpentIndex)(nil)
+
+// Ensure the SpentIndex type implements the Verifier interface.
+var _ Verifier = (*SpentIndex)(nil)
+
+// Init is only provided to satisfy the Indexer interface as there is nothing to
+// initialize for this index.
+//
+// This is part of the Indexer interface.
+func (idx *SpentIndex) Init() error {
+	// Nothing to do.
+	return nil
+}
+
+// Key returns the database key to use for the index as a byte slice.
+//
+// This is part of the Indexer interface.
+func (idx *SpentIndex) Key() []byte {
+	return spentIndexKey
+}
+
+// Name returns the human-readable name of the index.
+//
+// This is part of the Indexer interface.
+func (idx *SpentIndex) Name() string {
+	return spentIndexName
+}
+
+// Create is invoked when the indexer manager determines the index needs to be
+// created for the first time.  It creates the bucket for the spent output
+// index.
+//
+// This is part of the Indexer interface.
+func (idx *SpentIndex) Create(dbTx database.Tx) error {
+	_, err := dbTx.Metadata().CreateBucket(spentIndexKey)
+	return err
+}
+
+// ConnectBlock is invoked by the index manager when a new block has been
+// connected to the main chain.  This indexer adds a mapping from the outpoint
+// of every output spent by the passed block to the input which spent it.
+//
+// This is part of the Indexer interface.
+func (idx *SpentIndex) ConnectBlock(dbTx database.Tx, block *btcutil.Block,
+	stxos []blockchain.SpentTxOut) error {
+
+	bucket := dbTx.Metadata().Bucket(spentIndexKey)
+	return dbAddSpentIndexEntries(bucket, block)
+}
+
+// DisconnectBlock is invoked by the index manager when a block has been
+// disconnected from the main chain.  This indexer removes the mappings of the
+// outputs spent by the passed block.
+//
+// This is part of the Indexer interface.
+func (idx *SpentIndex) DisconnectBlock(dbTx database.Tx, block *btcutil.Block,
+	stxos []blockchain.SpentTxOut) error {
+
+	bucket := dbTx.Metadata().Bucket(spentIndexKey)
+	return dbRemoveSpentIndexEntries(bucket, block)
+}
+
+// VerifyBlock checks that the spent output index maps every output spent by the
+// passed block to the input which spent it.
+//
+// This is part of the Verifier interface.
+func (idx *SpentIndex) VerifyBlock(dbTx database.Tx, block *btcutil.Block,
+	stxos []blockchain.SpentTxOut) error {
+
+	for _, tx := range block.Transactions()[1:] {
+		for i, txIn := range tx.MsgTx().TxIn {
+			outPoint := &txIn.PreviousOutPoint
+			info, err := dbFetchSpentIndexEntry(dbTx, outPoint)
+			if err != nil {
+				return err
+			}
+			want := SpentInfo{
+				TxHash:     *tx.Hash(),
+				InputIndex: uint32(i),
+				Height:     block.Height(),
+			}
+			if info == nil || *info != want {
+				return MismatchError(fmt.Sprintf("%s entry of "+
+					"output %v spent by block %v is %+v, "+
+					"want %+v", spentIndexName, outPoint,
+					block.Hash(), info, want))
+			}
+		}
+	}
+
+	return nil
+}
+
+// SpentInfo returns the input which spent the output with the passed outpoint
+// in the main chain.  When the output is unspent or unknown, nil will be
+// returned for both the entry and the error.
+//
+// This function is safe for concurrent access.
+func (idx *SpentIndex) SpentInfo(outPoint *wire.OutPoint) (*SpentInfo, error) {
+	var info *SpentInfo
+	err := idx.db.View(func(dbTx database.Tx) error {
+		var err error
+		info, err = dbFetchSpentIndexEntry(dbTx, outPoint)
+		return err
+	})
+	return info, err
+}
+
+// NewSpentIndex returns a new instance of an indexer that is used to create a
+// mapping of the outpoints of all outputs spent in the blockchain to the inputs
+// which spent them.
+//
+// It implements the Indexer interface which plugs into the IndexManager that in
+// turn is used by the blockchain package.  This allows the index to be
+// seamlessly maintained along with the chain.
+func NewSpentIndex(db database.DB) *SpentIndex {
+	return &SpentIndex{db: db}
+}
+
+// DropSpentIndex drops the spent output index from the provided database if it
+// exists.
+func DropSpentIndex(db database.DB, interrupt <-chan struct{}) error {
+	return dropIndex(db, spentIndexKey, spentIndexName, interrupt)
+}
+
+// SpentIndexInitialized returns true if the spent output index has been created
+// previously.
+func SpentIndexInitialized(db database.DB) bool {
+	var exists bool
+	db.View(func(dbTx database.Tx) error {
+		bucket := dbTx.Metadata().Bucket(spentIndexKey)
+		exists = bucket != nil
+		return nil
+	})
+
+	return exists
+}
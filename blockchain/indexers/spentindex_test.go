@@ -0,0 +1,97 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/v2"
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/database"
+	_ "github.com/btcsuite/btcd/database/ffldb"
+	"github.com/btcsuite/btcd/wire/v2"
+)
+
+// TestSpentIndex ensures the spent output index maps the outputs spent by a
+// connected block to the inputs which spent them and forgets them again when
+// the block is disconnected.
+func TestSpentIndex(t *testing.T) {
+	db, err := database.Create("ffldb", filepath.Join(t.TempDir(), "db"),
+		wire.MainNet)
+	if err != nil {
+		t.Fatalf("unable to create database: %v", err)
+	}
+	defer db.Close()
+
+	idx := NewSpentIndex(db)
+	err = db.Update(func(dbTx database.Tx) error {
+		return idx.Create(dbTx)
+	})
+	if err != nil {
+		t.Fatalf("Create: unexpected error: %v", err)
+	}
+
+	// Create a block whose second transaction spends two outputs.  The
+	// input of the coinbase doesn't spend an output and must not be
+	// indexed.
+	coinbase := wire.NewMsgTx(wire.TxVersion)
+	coinbase.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{},
+		wire.MaxPrevOutIndex), nil, nil))
+	coinbase.AddTxOut(wire.NewTxOut(5000000000, nil))
+	spends := []wire.OutPoint{
+		{Hash: chainhash.Hash{1}, Index: 0},
+		{Hash: chainhash.Hash{2}, Index: 3},
+	}
+	spender := wire.NewMsgTx(wire.TxVersion)
+	for i := range spends {
+		spender.AddTxIn(wire.NewTxIn(&spends[i], nil, nil))
+	}
+	spender.AddTxOut(wire.NewTxOut(1000, nil))
+	block := btcutil.NewBlock(&wire.MsgBlock{
+		Transactions: []*wire.MsgTx{coinbase, spender},
+	})
+	block.SetHeight(120)
+
+	err = db.Update(func(dbTx database.Tx) error {
+		return idx.ConnectBlock(dbTx, block, nil)
+	})
+	if err != nil {
+		t.Fatalf("ConnectBlock: unexpected error: %v", err)
+	}
+	for i := range spends {
+		info, err := idx.SpentInfo(&spends[i])
+		if err != nil {
+			t.Fatalf("SpentInfo: unexpected error: %v", err)
+		}
+		want := SpentInfo{
+			TxHash:     spender.TxHash(),
+			InputIndex: uint32(i),
+			Height:     120,
+		}
+		if info == nil || *info != want {
+			t.Fatalf("SpentInfo(%v): got %+v, want %+v", spends[i],
+				info, want)
+		}
+	}
+	info, err := idx.SpentInfo(&coinbase.TxIn[0].PreviousOutPoint)
+	if err != nil || info != nil {
+		t.Fatalf("SpentInfo: got %+v, %v for coinbase input", info, err)
+	}
+
+	err = db.Update(func(dbTx database.Tx) error {
+		return idx.DisconnectBlock(dbTx, block, nil)
+	})
+	if err != nil {
+		t.Fatalf("DisconnectBlock: unexpected error: %v", err)
+	}
+	for i := range spends {
+		info, err := idx.SpentInfo(&spends[i])
+		if err != nil || info != nil {
+			t.Fatalf("SpentInfo(%v): got %+v, %v after disconnect",
+				spends[i], info, err)
+		}
+	}
+}
@@ -5,6 +5,7 @@
 package indexers
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 
@@ -289,6 +290,9 @@ type TxIndex struct {
 // Ensure the TxIndex type implements the Indexer interface.
 var _ Indexer = (*TxIndex)(nil)
 
+// Ensure the TxIndex type implements the Verifier interface.
+var _ Verifier = (*TxIndex)(nil)
+
 // Init initializes the hash-based transaction index.  In particular, it finds
 // the highest used block ID and stores it for later use when connecting or
 // disconnecting blocks.
@@ -319,8 +323,9 @@ func (idx *TxIndex) Init() error {
 		log.Tracef("Forward scan (highest known %d, next unknown %d)",
 			highestKnown, nextUnknown)
 
-		// No used block IDs due to new database.
+		// No used block IDs due to new database or a dropped index.
 		if nextUnknown == 1 {
+			idx.curBlockID = 0
 			return nil
 		}
 
@@ -430,6 +435,48 @@ func (idx *TxIndex) DisconnectBlock(dbTx database.Tx, block *btcutil.Block,
 	return nil
 }
 
+// VerifyBlock checks that the transaction index locates every transaction of
+// the passed block.  Transactions which are duplicated by a later block, which
+// is only possible before BIP0030, are located in the later block instead.
+//
+// This is part of the Verifier interface.
+func (idx *TxIndex) VerifyBlock(dbTx database.Tx, block *btcutil.Block,
+	stxos []blockchain.SpentTxOut) error {
+
+	if _, err := dbFetchBlockIDByHash(dbTx, block.Hash()); err != nil {
+		return MismatchError(fmt.Sprintf("%s has no internal block id "+
+			"for block %v", txIndexName, block.Hash()))
+	}
+
+	for _, tx := range block.Transactions() {
+		region, err := dbFetchTxIndexEntry(dbTx, tx.Hash())
+		if err != nil {
+			return err
+		}
+		if region == nil {
+			return MismatchError(fmt.Sprintf("%s has no entry for "+
+				"transaction %v of block %v", txIndexName,
+				tx.Hash(), block.Hash()))
+		}
+
+		// A region which doesn't lie within its block can't be fetched
+		// and is a mismatch as well.
+		var msgTx wire.MsgTx
+		txBytes, err := dbTx.FetchBlockRegion(region)
+		if err == nil {
+			err = msgTx.Deserialize(bytes.NewReader(txBytes))
+		}
+		if err != nil || msgTx.TxHash() != *tx.Hash() {
+			return MismatchError(fmt.Sprintf("%s entry of "+
+				"transaction %v of block %v locates another "+
+				"transaction", txIndexName, tx.Hash(),
+				block.Hash()))
+		}
+	}
+
+	return nil
+}
+
 // TxBlockRegion returns the block region for the provided transaction hash
 // from the transaction index.  The block region can in turn be used to load the
 // raw transaction bytes.  When there is no entry for the provided hash, nil
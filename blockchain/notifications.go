@@ -6,6 +6,10 @@ package blockchain
 
 import (
 	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/v2"
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/txscript/v2"
 )
 
 // NotificationType represents the type of a notification message.
@@ -29,6 +33,16 @@ const (
 	// NTBlockDisconnected indicates the associated block was disconnected
 	// from the main chain.
 	NTBlockDisconnected
+
+	// NTReorganization indicates the main chain was reorganized.  It is
+	// sent once the blocks of the reorganization have been disconnected
+	// and connected.
+	NTReorganization
+
+	// NTChainWarning indicates the warning about forks of the main chain
+	// which indicate the network may have split changed.  The warning is
+	// empty once there is nothing to warn about anymore.
+	NTChainWarning
 )
 
 // notificationTypeStrings is a map of notification types back to their constant
@@ -37,6 +51,8 @@ var notificationTypeStrings = map[NotificationType]string{
 	NTBlockAccepted:     "NTBlockAccepted",
 	NTBlockConnected:    "NTBlockConnected",
 	NTBlockDisconnected: "NTBlockDisconnected",
+	NTReorganization:    "NTReorganization",
+	NTChainWarning:      "NTChainWarning",
 }
 
 // String returns the NotificationType in human-readable form.
@@ -53,11 +69,63 @@ func (n NotificationType) String() string {
 //   - NTBlockAccepted:     *btcutil.Block
 //   - NTBlockConnected:    *btcutil.Block
 //   - NTBlockDisconnected: *btcutil.Block
+//   - NTReorganization:    *ReorganizationNtfnData
+//   - NTChainWarning:      string
 type Notification struct {
 	Type NotificationType
 	Data interface{}
 }
 
+// ReorganizationNtfnData describes a reorganization of the main chain.  The
+// disconnected blocks start with the old best block and the connected blocks
+// end with the new best block.  There are no connected blocks when the main
+// chain was only rolled back, in which case the fork point is the new best
+// block.
+//
+// ClaimNames holds the distinct names of the claims, supports and updates which
+// are created or spent by the disconnected and connected blocks, in the order
+// they are first seen.
+type ReorganizationNtfnData struct {
+	OldHash      chainhash.Hash
+	OldHeight    int32
+	NewHash      chainhash.Hash
+	NewHeight    int32
+	ForkHash     chainhash.Hash
+	ForkHeight   int32
+	Disconnected []chainhash.Hash
+	Connected    []chainhash.Hash
+	ClaimNames   [][]byte
+}
+
+// addClaimNames adds the names of the claims, supports and updates created by
+// the outputs of the passed block and spent by its inputs, as described by the
+// passed spent outputs, to the claim names of the reorganization.  Names which
+// were already added are skipped using the passed set.
+func (n *ReorganizationNtfnData) addClaimNames(seen map[string]struct{},
+	block *btcutil.Block, stxos []SpentTxOut) {
+
+	add := func(pkScript []byte) {
+		claim, err := txscript.DecodeClaimScript(pkScript)
+		if err != nil {
+			return
+		}
+		if _, ok := seen[string(claim.Name)]; ok {
+			return
+		}
+		seen[string(claim.Name)] = struct{}{}
+		n.ClaimNames = append(n.ClaimNames, claim.Name)
+	}
+
+	for _, tx := range block.Transactions() {
+		for _, txOut := range tx.MsgTx().TxOut {
+			add(txOut.PkScript)
+		}
+	}
+	for i := range stxos {
+		add(stxos[i].PkScript)
+	}
+}
+
 // Subscribe to block chain notifications. Registers a callback to be executed
 // when various events take place. See the documentation on Notification and
 // NotificationType for details on the types and contents of notifications.
@@ -5,9 +5,16 @@
 package blockchain
 
 import (
+	"bytes"
+	"reflect"
 	"testing"
 
+	"github.com/btcsuite/btcd/blockchain/internal/testhelper"
+	"github.com/btcsuite/btcd/btcutil/v2"
 	"github.com/btcsuite/btcd/chaincfg/v2"
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/txscript/v2"
+	"github.com/btcsuite/btcd/wire/v2"
 )
 
 // TestNotifications ensures that notification callbacks are fired on events.
@@ -49,3 +56,107 @@ func TestNotifications(t *testing.T) {
 			"times, found %d", numSubscribers, notificationCount)
 	}
 }
+
+// TestReorganizationNotification ensures a reorganization of the main chain is
+// notified along with the blocks which were disconnected and connected.
+func TestReorganizationNotification(t *testing.T) {
+	chain, params, tearDown := utxoCacheTestChain(
+		"TestReorganizationNotification")
+	defer tearDown()
+
+	var ntfns []*ReorganizationNtfnData
+	chain.Subscribe(func(notification *Notification) {
+		if notification.Type == NTReorganization {
+			ntfns = append(ntfns,
+				notification.Data.(*ReorganizationNtfnData))
+		}
+	})
+
+	// Create a main chain of 5 blocks and a longer side chain which forks
+	// off after the 2nd block.
+	tip := btcutil.NewBlock(params.GenesisBlock)
+	tip.SetHeight(0)
+	mainHashes, spendableOuts, err := addBlocks(5, chain, tip,
+		[]*testhelper.SpendableOut{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ntfns) != 0 {
+		t.Fatalf("got %d reorganization notifications while extending "+
+			"the main chain", len(ntfns))
+	}
+	forkBlock, err := chain.BlockByHeight(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sideHashes, _, err := addBlocks(4, chain, forkBlock, spendableOuts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ntfns) != 1 {
+		t.Fatalf("got %d reorganization notifications, want 1",
+			len(ntfns))
+	}
+
+	// The disconnected blocks start with the old tip and the connected
+	// blocks end with the new tip.
+	want := &ReorganizationNtfnData{
+		OldHash:    *mainHashes[4],
+		OldHeight:  5,
+		NewHash:    *sideHashes[3],
+		NewHeight:  6,
+		ForkHash:   *forkBlock.Hash(),
+		ForkHeight: 2,
+		Disconnected: []chainhash.Hash{*mainHashes[4], *mainHashes[3],
+			*mainHashes[2]},
+		Connected: []chainhash.Hash{*sideHashes[0], *sideHashes[1],
+			*sideHashes[2], *sideHashes[3]},
+	}
+	if !reflect.DeepEqual(ntfns[0], want) {
+		t.Fatalf("got notification %+v, want %+v", ntfns[0], want)
+	}
+}
+
+// TestReorganizationClaimNames ensures the names of the claims, supports and
+// updates created and spent by the blocks of a reorganization are added once
+// each in the order they are first seen.
+func TestReorganizationClaimNames(t *testing.T) {
+	pkScript := []byte{txscript.OP_TRUE}
+	claimID := bytes.Repeat([]byte{0x01}, txscript.ClaimIDSize)
+	withPrefix := func(prefix []byte, err error) []byte {
+		if err != nil {
+			t.Fatalf("unable to create claim prefix: %v", err)
+		}
+		return append(prefix, pkScript...)
+	}
+	claimA := withPrefix(txscript.NewClaimScript([]byte("a"), nil))
+	supportB := withPrefix(txscript.NewSupportScript([]byte("b"), claimID,
+		nil))
+	updateA := withPrefix(txscript.NewUpdateScript([]byte("a"), claimID,
+		[]byte("value")))
+	claimC := withPrefix(txscript.NewClaimScript([]byte("c"), nil))
+
+	newBlock := func(pkScripts ...[]byte) *btcutil.Block {
+		tx := wire.NewMsgTx(wire.TxVersion)
+		for _, pkScript := range pkScripts {
+			tx.AddTxOut(wire.NewTxOut(1, pkScript))
+		}
+		return btcutil.NewBlock(&wire.MsgBlock{
+			Transactions: []*wire.MsgTx{tx},
+		})
+	}
+
+	var ntfnData ReorganizationNtfnData
+	seen := make(map[string]struct{})
+	ntfnData.addClaimNames(seen, newBlock(pkScript, claimA, supportB),
+		[]SpentTxOut{{PkScript: updateA}, {PkScript: pkScript}})
+	ntfnData.addClaimNames(seen, newBlock(updateA),
+		[]SpentTxOut{{PkScript: claimC}})
+	ntfnData.addClaimNames(seen, newBlock(pkScript), nil)
+
+	want := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+	if !reflect.DeepEqual(ntfnData.ClaimNames, want) {
+		t.Fatalf("got claim names %q, want %q", ntfnData.ClaimNames,
+			want)
+	}
+}
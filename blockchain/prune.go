@@ -0,0 +1,214 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/database"
+)
+
+// spendJournalPruneBatchSize is the number of blocks whose spend journals are
+// deleted in a single database transaction when catching up with the
+// configured spend journal depth.
+const spendJournalPruneBatchSize = 10000
+
+// spendJournalPrunedError returns the error for a block of the main chain whose
+// spend journal has been pruned.
+func spendJournalPrunedError(node *blockNode) error {
+	return fmt.Errorf("the spend journal of block %v (height %d) has been "+
+		"pruned", node.hash, node.height)
+}
+
+// PruneBlocks deletes the stored blocks up to and including the passed height
+// along with their spend journals.  Since blocks are deleted by whole block
+// files, blocks which are stored in the same file as a block above the height
+// are kept until that file can be deleted as well.  The utxo set is not
+// affected.
+//
+// The caller is responsible for keeping enough blocks to handle reorgs and to
+// only prune the blocks of a node which is in prune mode since a pruned node
+// can't serve the deleted blocks anymore.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) PruneBlocks(height int32) error {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	return b.db.Update(func(dbTx database.Tx) error {
+		deletedHashes, err := dbTx.PruneBlockFiles(func(hash *chainhash.Hash) bool {
+			node := b.index.LookupNode(hash)
+			return node != nil && node.height <= height
+		})
+		if err != nil {
+			return err
+		}
+		if len(deletedHashes) == 0 {
+			return nil
+		}
+
+		// Delete the spend journals of the pruned blocks and let the
+		// indexes remove their entries.
+		err = dbPruneSpendJournalEntry(dbTx, deletedHashes)
+		if err != nil {
+			return err
+		}
+		err = b.dbPruneIndexes(dbTx, deletedHashes)
+		if err != nil {
+			return err
+		}
+
+		// The utxo cache must be flushed when blocks past the last flush
+		// are deleted since they are needed to reconstruct it after an
+		// unclean shutdown.
+		needsFlush, err := b.flushNeededAfterPrune(deletedHashes)
+		if err != nil {
+			return err
+		}
+		if needsFlush {
+			return b.utxoCache.flush(dbTx, FlushRequired,
+				b.BestSnapshot())
+		}
+		return nil
+	})
+}
+
+// dbPruneIndexes passes the hashes of the blocks which were deleted by pruning
+// to the index manager when it implements the IndexPruner interface.
+func (b *BlockChain) dbPruneIndexes(dbTx database.Tx, deletedHashes []chainhash.Hash) error {
+	pruner, ok := b.indexManager.(IndexPruner)
+	if !ok {
+		return nil
+	}
+	return pruner.PruneBlocks(dbTx, deletedHashes)
+}
+
+// PruneHeight returns the height of the lowest block of the main chain which is
+// still stored.  It is zero when no blocks have been pruned.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) PruneHeight() (int32, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	// Blocks are pruned from the oldest block file on, so the stored blocks
+	// of the main chain are found with a binary search for the lowest one.
+	low, high := int32(0), b.bestChain.Tip().height
+	err := b.db.View(func(dbTx database.Tx) error {
+		for low < high {
+			mid := low + (high-low)/2
+			node := b.bestChain.NodeByHeight(mid)
+			exists, err := dbTx.HasBlock(&node.hash)
+			if err != nil {
+				return err
+			}
+			if exists {
+				high = mid
+			} else {
+				low = mid + 1
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return low, nil
+}
+
+// spendJournalPruned returns whether or not the spend journal of the passed
+// block has been pruned.  Only the spend journals of the blocks of the main
+// chain are pruned and the genesis block has none to begin with.
+//
+// This function MUST be called with the chain lock held (for reads).
+func (b *BlockChain) spendJournalPruned(node *blockNode) bool {
+	return node.height > 0 && node.height <= b.spendJournalPruneHeight &&
+		b.bestChain.Contains(node)
+}
+
+// dbPruneSpendJournals uses an existing database transaction to delete the
+// spend journals of the blocks of the main chain after the last pruned one up
+// to and including the passed height and to store the new prune height.  The
+// in-memory prune height has to be updated by the caller once the transaction
+// is committed.
+//
+// This function MUST be called with the chain lock held (for writes).
+func (b *BlockChain) dbPruneSpendJournals(dbTx database.Tx, height int32) error {
+	for h := b.spendJournalPruneHeight + 1; h <= height; h++ {
+		node := b.bestChain.NodeByHeight(h)
+		if node == nil {
+			return AssertError(fmt.Sprintf("no block at height %d "+
+				"exists in the main chain", h))
+		}
+		err := dbRemoveSpendJournalEntry(dbTx, &node.hash)
+		if err != nil {
+			return err
+		}
+	}
+
+	return dbPutSpendJournalPruneHeight(dbTx, height)
+}
+
+// pruneSpendJournals deletes the spend journals of the blocks of the main chain
+// which are deeper than the configured spend journal depth.  This is normally
+// done as blocks are connected, so this only has to catch up when the depth was
+// just configured or lowered.  The spend journals are deleted in batches so
+// the work is not lost when it is interrupted.
+func (b *BlockChain) pruneSpendJournals(interrupt <-chan struct{}) error {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	if b.spendJournalDepth == 0 {
+		return nil
+	}
+	pruneHeight := b.bestChain.Tip().height - b.spendJournalDepth
+	if pruneHeight <= b.spendJournalPruneHeight {
+		return nil
+	}
+
+	log.Infof("Pruning the spend journals of %d blocks",
+		pruneHeight-b.spendJournalPruneHeight)
+	lastLogTime := time.Now()
+	for b.spendJournalPruneHeight < pruneHeight {
+		if interruptRequested(interrupt) {
+			return errInterruptRequested
+		}
+
+		height := b.spendJournalPruneHeight + spendJournalPruneBatchSize
+		if height > pruneHeight {
+			height = pruneHeight
+		}
+		err := b.db.Update(func(dbTx database.Tx) error {
+			return b.dbPruneSpendJournals(dbTx, height)
+		})
+		if err != nil {
+			return err
+		}
+		b.spendJournalPruneHeight = height
+
+		if time.Since(lastLogTime) >= utxoStateLogInterval {
+			log.Infof("Pruned the spend journals up to height %d "+
+				"of %d", height, pruneHeight)
+			lastLogTime = time.Now()
+		}
+	}
+	log.Infof("Pruned the spend journals up to height %d", pruneHeight)
+
+	return nil
+}
+
+// SpendJournalPruneHeight returns the height of the last block of the main
+// chain whose spend journal has been pruned.  It is zero when no spend journals
+// have been pruned.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) SpendJournalPruneHeight() int32 {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	return b.spendJournalPruneHeight
+}
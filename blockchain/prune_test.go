@@ -0,0 +1,183 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"container/list"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/v2"
+	"github.com/btcsuite/btcd/database"
+	"github.com/btcsuite/btcd/database/ffldb"
+)
+
+// TestPruneBlocks ensures blocks are only pruned up to the requested height and
+// that the prune height reports the lowest block of the main chain which is
+// still stored.
+func TestPruneBlocks(t *testing.T) {
+	chain, tearDown, err := chainSetup("TestPruneBlocks",
+		&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("failed to setup chain instance: %v", err)
+	}
+	defer tearDown()
+
+	blocks, err := loadBlocks("blk_0_to_14131.dat")
+	if err != nil {
+		t.Fatalf("failed to read block from file. %v", err)
+	}
+
+	// Use small block files so that there are many of them to prune.
+	ffldb.TstRunWithMaxBlockFileSize(chain.db, 8192, func() {
+		for _, block := range blocks[1:] {
+			_, _, err := chain.ProcessBlock(block, BFNone)
+			if err != nil {
+				t.Fatalf("Failed to process block %v(%v). %v",
+					block.Hash(), block.Height(), err)
+			}
+		}
+	})
+
+	pruneHeight, err := chain.PruneHeight()
+	if err != nil {
+		t.Fatalf("PruneHeight: unexpected error: %v", err)
+	}
+	if pruneHeight != 0 {
+		t.Fatalf("PruneHeight before pruning: got %d, want 0",
+			pruneHeight)
+	}
+
+	const height = 1000
+	if err := chain.PruneBlocks(height); err != nil {
+		t.Fatalf("PruneBlocks: unexpected error: %v", err)
+	}
+	pruneHeight, err = chain.PruneHeight()
+	if err != nil {
+		t.Fatalf("PruneHeight: unexpected error: %v", err)
+	}
+	if pruneHeight == 0 || pruneHeight > height+1 {
+		t.Fatalf("PruneHeight after pruning to %d: got %d", height,
+			pruneHeight)
+	}
+
+	// The blocks below the prune height are gone while all of the others
+	// are still stored.
+	err = chain.db.View(func(dbTx database.Tx) error {
+		for _, block := range blocks {
+			exists, err := dbTx.HasBlock(block.Hash())
+			if err != nil {
+				return err
+			}
+			if exists != (block.Height() >= pruneHeight) {
+				t.Errorf("block %d: got exists %v with prune "+
+					"height %d", block.Height(), exists,
+					pruneHeight)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("View: unexpected error: %v", err)
+	}
+}
+
+// TestPruneSpendJournals ensures the spend journals of the blocks deeper than
+// the configured depth are deleted while the blocks are kept and that blocks
+// whose spend journals have been pruned can't be disconnected.
+func TestPruneSpendJournals(t *testing.T) {
+	chain, tearDown, err := chainSetup("TestPruneSpendJournals",
+		&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("failed to setup chain instance: %v", err)
+	}
+	defer tearDown()
+
+	blocks, err := loadBlocks("blk_0_to_14131.dat")
+	if err != nil {
+		t.Fatalf("failed to read block from file. %v", err)
+	}
+
+	const numBlocks = 300
+	chain.spendJournalDepth = 100
+	for _, block := range blocks[1 : numBlocks+1] {
+		_, _, err := chain.ProcessBlock(block, BFNone)
+		if err != nil {
+			t.Fatalf("Failed to process block %v(%v). %v",
+				block.Hash(), block.Height(), err)
+		}
+	}
+	if height := chain.SpendJournalPruneHeight(); height != numBlocks-100 {
+		t.Fatalf("SpendJournalPruneHeight: got %d, want %d", height,
+			numBlocks-100)
+	}
+
+	// Lowering the depth prunes the spend journals which are now deeper
+	// than it.
+	chain.spendJournalDepth = 50
+	if err := chain.pruneSpendJournals(nil); err != nil {
+		t.Fatalf("pruneSpendJournals: unexpected error: %v", err)
+	}
+	pruneHeight := chain.SpendJournalPruneHeight()
+	if pruneHeight != numBlocks-50 {
+		t.Fatalf("SpendJournalPruneHeight: got %d, want %d",
+			pruneHeight, numBlocks-50)
+	}
+
+	// The prune height is stored in the database and the blocks are kept
+	// while only the spend journals above the prune height remain.
+	err = chain.db.View(func(dbTx database.Tx) error {
+		height := dbFetchSpendJournalPruneHeight(dbTx)
+		if height != pruneHeight {
+			t.Errorf("stored prune height: got %d, want %d",
+				height, pruneHeight)
+		}
+
+		spendBucket := dbTx.Metadata().Bucket(spendJournalBucketName)
+		for _, block := range blocks[1 : numBlocks+1] {
+			exists, err := dbTx.HasBlock(block.Hash())
+			if err != nil {
+				return err
+			}
+			if !exists {
+				t.Errorf("block %d was deleted", block.Height())
+			}
+			pruned := spendBucket.Get(block.Hash()[:]) == nil
+			if pruned && block.Height() > pruneHeight {
+				t.Errorf("spend journal of block %d was deleted",
+					block.Height())
+			}
+			if !pruned && block.Height() <= pruneHeight {
+				t.Errorf("spend journal of block %d was kept",
+					block.Height())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("View: unexpected error: %v", err)
+	}
+
+	// The spend journals of the pruned blocks can't be fetched anymore.
+	if _, err := chain.FetchSpendJournal(blocks[pruneHeight]); err == nil {
+		t.Fatal("FetchSpendJournal: expected error for pruned block")
+	}
+	_, err = chain.FetchSpendJournal(blocks[pruneHeight+1])
+	if err != nil {
+		t.Fatalf("FetchSpendJournal: unexpected error: %v", err)
+	}
+
+	// Blocks whose spend journals have been pruned can't be disconnected.
+	detachNodes := list.New()
+	for height := int32(numBlocks); height >= pruneHeight; height-- {
+		detachNodes.PushBack(chain.bestChain.NodeByHeight(height))
+	}
+	_, _, _, err = chain.verifyReorganizationValidity(detachNodes,
+		list.New())
+	if err == nil || !strings.Contains(err.Error(), "has been pruned") {
+		t.Fatalf("verifyReorganizationValidity: unexpected error for "+
+			"pruned spend journal: %v", err)
+	}
+}
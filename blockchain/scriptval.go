@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"math"
 	"runtime"
+	"sync"
 	"time"
 
 	"github.com/btcsuite/btcd/btcutil/v2"
@@ -15,6 +16,31 @@ import (
 	"github.com/btcsuite/btcd/wire/v2"
 )
 
+const (
+	// maxTxValidateBatch is the maximum number of transaction inputs which
+	// are handed to a validation goroutine at once.  Handing out inputs in
+	// batches reduces the synchronization overhead of validating blocks
+	// with many inputs, while keeping the batches small enough that the
+	// work is still spread evenly over all of the goroutines.
+	maxTxValidateBatch = 32
+
+	// txValidateBatchesPerWorker is the number of batches the inputs are
+	// split into per validation goroutine when there are only a few inputs
+	// to validate.
+	txValidateBatchesPerWorker = 4
+)
+
+// DefaultScriptWorkers returns the default number of goroutines used to
+// validate the scripts of a block, which is three times the number of
+// processor cores.
+func DefaultScriptWorkers() int {
+	numWorkers := runtime.NumCPU() * 3
+	if numWorkers <= 0 {
+		numWorkers = 1
+	}
+	return numWorkers
+}
+
 // txValidateItem holds a transaction along with which input to validate.
 type txValidateItem struct {
 	txInIndex int
@@ -27,13 +53,15 @@ type txValidateItem struct {
 // inputs.  It provides several channels for communication and a processing
 // function that is intended to be in run multiple goroutines.
 type txValidator struct {
-	validateChan chan *txValidateItem
+	validateChan chan []*txValidateItem
 	quitChan     chan struct{}
 	resultChan   chan error
 	utxoView     *UtxoViewpoint
 	flags        txscript.ScriptFlags
 	sigCache     *txscript.SigCache
 	hashCache    *txscript.HashCache
+	numWorkers   int
+	batchVerify  bool
 }
 
 // sendResult sends the result of a script pair validation on the internal
@@ -47,61 +75,107 @@ func (v *txValidator) sendResult(result error) {
 	}
 }
 
-// validateHandler consumes items to validate from the internal validate channel
-// and returns the result of the validation on the internal result channel. It
-// must be run as a goroutine.
+// validateItem validates the script pair of the passed transaction input.  The
+// taproot signatures of the input are added to the passed batch instead of
+// verifying them when it is not nil, in which case the input is only valid
+// once the batch has been verified.
+func (v *txValidator) validateItem(txVI *txValidateItem,
+	batch *txscript.BatchVerifier) error {
+
+	// Ensure the referenced input utxo is available.
+	txIn := txVI.txIn
+	utxo := v.utxoView.LookupEntry(txIn.PreviousOutPoint)
+	if utxo == nil {
+		str := fmt.Sprintf("unable to find unspent output %v "+
+			"referenced from transaction %s:%d",
+			txIn.PreviousOutPoint, txVI.tx.Hash(), txVI.txInIndex)
+		return ruleError(ErrMissingTxOut, str)
+	}
+
+	// Create a new script engine for the script pair.
+	sigScript := txIn.SignatureScript
+	witness := txIn.Witness
+	pkScript := utxo.PkScript()
+	inputAmount := utxo.Amount()
+	vm, err := txscript.NewBatchEngine(
+		pkScript, txVI.tx.MsgTx(), txVI.txInIndex,
+		v.flags, v.sigCache, txVI.sigHashes,
+		inputAmount, v.utxoView, batch,
+	)
+	if err != nil {
+		str := fmt.Sprintf("failed to parse input "+
+			"%s:%d which references output %v - "+
+			"%v (input witness %x, input script "+
+			"bytes %x, prev output script bytes %x)",
+			txVI.tx.Hash(), txVI.txInIndex,
+			txIn.PreviousOutPoint, err, witness,
+			sigScript, pkScript)
+		return ruleError(ErrScriptMalformed, str)
+	}
+
+	// Execute the script pair.
+	if err := vm.Execute(); err != nil {
+		str := fmt.Sprintf("failed to validate input "+
+			"%s:%d which references output %v - "+
+			"%v (input witness %x, input script "+
+			"bytes %x, prev output script bytes %x)",
+			txVI.tx.Hash(), txVI.txInIndex,
+			txIn.PreviousOutPoint, err, witness,
+			sigScript, pkScript)
+		return ruleError(ErrScriptValidation, str)
+	}
+
+	return nil
+}
+
+// validateItems validates the script pairs of the passed transaction inputs.
+// When batch verification is enabled, the taproot signatures of all of the
+// inputs are added to the passed batch and verified together once the scripts
+// have been executed.
+func (v *txValidator) validateItems(items []*txValidateItem,
+	sigBatch *txscript.BatchVerifier) error {
+
+	for _, txVI := range items {
+		if err := v.validateItem(txVI, sigBatch); err != nil {
+			return err
+		}
+	}
+	if sigBatch == nil || sigBatch.Verify() {
+		return nil
+	}
+
+	// At least one of the signatures of the batch is invalid, but the
+	// batch doesn't tell which one, so validate the inputs again while
+	// verifying each signature on its own to find the offending input.
+	for _, txVI := range items {
+		if err := v.validateItem(txVI, nil); err != nil {
+			return err
+		}
+	}
+
+	// The signatures are valid when verified on their own, so the batch
+	// failure isn't caused by the block and must not mark it invalid.
+	log.Warnf("Batch signature verification failed while the signatures "+
+		"of all %d inputs are valid on their own", len(items))
+	return nil
+}
+
+// validateHandler consumes batches of items to validate from the internal
+// validate channel and returns the result of the validation of each batch on
+// the internal result channel. It must be run as a goroutine.
 func (v *txValidator) validateHandler() {
+	// Each goroutine has its own signature batch since they are not safe
+	// for concurrent access.
+	var sigBatch *txscript.BatchVerifier
+	if v.batchVerify {
+		sigBatch = txscript.NewBatchVerifier(v.sigCache)
+	}
+
 out:
 	for {
 		select {
-		case txVI := <-v.validateChan:
-			// Ensure the referenced input utxo is available.
-			txIn := txVI.txIn
-			utxo := v.utxoView.LookupEntry(txIn.PreviousOutPoint)
-			if utxo == nil {
-				str := fmt.Sprintf("unable to find unspent "+
-					"output %v referenced from "+
-					"transaction %s:%d",
-					txIn.PreviousOutPoint, txVI.tx.Hash(),
-					txVI.txInIndex)
-				err := ruleError(ErrMissingTxOut, str)
-				v.sendResult(err)
-				break out
-			}
-
-			// Create a new script engine for the script pair.
-			sigScript := txIn.SignatureScript
-			witness := txIn.Witness
-			pkScript := utxo.PkScript()
-			inputAmount := utxo.Amount()
-			vm, err := txscript.NewEngine(
-				pkScript, txVI.tx.MsgTx(), txVI.txInIndex,
-				v.flags, v.sigCache, txVI.sigHashes,
-				inputAmount, v.utxoView,
-			)
-			if err != nil {
-				str := fmt.Sprintf("failed to parse input "+
-					"%s:%d which references output %v - "+
-					"%v (input witness %x, input script "+
-					"bytes %x, prev output script bytes %x)",
-					txVI.tx.Hash(), txVI.txInIndex,
-					txIn.PreviousOutPoint, err, witness,
-					sigScript, pkScript)
-				err := ruleError(ErrScriptMalformed, str)
-				v.sendResult(err)
-				break out
-			}
-
-			// Execute the script pair.
-			if err := vm.Execute(); err != nil {
-				str := fmt.Sprintf("failed to validate input "+
-					"%s:%d which references output %v - "+
-					"%v (input witness %x, input script "+
-					"bytes %x, prev output script bytes %x)",
-					txVI.tx.Hash(), txVI.txInIndex,
-					txIn.PreviousOutPoint, err, witness,
-					sigScript, pkScript)
-				err := ruleError(ErrScriptValidation, str)
+		case batch := <-v.validateChan:
+			if err := v.validateItems(batch, sigBatch); err != nil {
 				v.sendResult(err)
 				break out
 			}
@@ -122,46 +196,63 @@ func (v *txValidator) Validate(items []*txValidateItem) error {
 		return nil
 	}
 
-	// Limit the number of goroutines to do script validation based on the
-	// number of processor cores.  This helps ensure the system stays
-	// reasonably responsive under heavy load.
-	maxGoRoutines := runtime.NumCPU() * 3
+	// Limit the number of goroutines to do script validation to the
+	// configured number, which defaults to a multiple of the number of
+	// processor cores.  This helps ensure the system stays reasonably
+	// responsive under heavy load.
+	maxGoRoutines := v.numWorkers
 	if maxGoRoutines <= 0 {
-		maxGoRoutines = 1
+		maxGoRoutines = DefaultScriptWorkers()
 	}
 	if maxGoRoutines > len(items) {
 		maxGoRoutines = len(items)
 	}
 
+	// Split the inputs into batches which are small enough to keep all of
+	// the goroutines busy until the end.
+	batchSize := len(items) / (maxGoRoutines * txValidateBatchesPerWorker)
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	if batchSize > maxTxValidateBatch {
+		batchSize = maxTxValidateBatch
+	}
+	batches := make([][]*txValidateItem, 0, (len(items)+batchSize-1)/batchSize)
+	for len(items) > batchSize {
+		batches = append(batches, items[:batchSize])
+		items = items[batchSize:]
+	}
+	batches = append(batches, items)
+
 	// Start up validation handlers that are used to asynchronously
 	// validate each transaction input.
 	for i := 0; i < maxGoRoutines; i++ {
 		go v.validateHandler()
 	}
 
-	// Validate each of the inputs.  The quit channel is closed when any
+	// Validate each batch of inputs.  The quit channel is closed when any
 	// errors occur so all processing goroutines exit regardless of which
 	// input had the validation error.
-	numInputs := len(items)
-	currentItem := 0
-	processedItems := 0
-	for processedItems < numInputs {
-		// Only send items while there are still items that need to
+	numBatches := len(batches)
+	currentBatch := 0
+	processedBatches := 0
+	for processedBatches < numBatches {
+		// Only send batches while there are still batches that need to
 		// be processed.  The select statement will never select a nil
 		// channel.
-		var validateChan chan *txValidateItem
-		var item *txValidateItem
-		if currentItem < numInputs {
+		var validateChan chan []*txValidateItem
+		var batch []*txValidateItem
+		if currentBatch < numBatches {
 			validateChan = v.validateChan
-			item = items[currentItem]
+			batch = batches[currentBatch]
 		}
 
 		select {
-		case validateChan <- item:
-			currentItem++
+		case validateChan <- batch:
+			currentBatch++
 
 		case err := <-v.resultChan:
-			processedItems++
+			processedBatches++
 			if err != nil {
 				close(v.quitChan)
 				return err
@@ -174,22 +265,31 @@ func (v *txValidator) Validate(items []*txValidateItem) error {
 }
 
 // newTxValidator returns a new instance of txValidator to be used for
-// validating transaction scripts asynchronously.
+// validating transaction scripts asynchronously with up to the given number of
+// goroutines.  The default number of goroutines is used when it is zero.  The
+// taproot signatures of each batch of inputs are verified together when
+// batchVerify is set.
 func newTxValidator(utxoView *UtxoViewpoint, flags txscript.ScriptFlags,
-	sigCache *txscript.SigCache, hashCache *txscript.HashCache) *txValidator {
+	sigCache *txscript.SigCache, hashCache *txscript.HashCache,
+	numWorkers int, batchVerify bool) *txValidator {
+
 	return &txValidator{
-		validateChan: make(chan *txValidateItem),
+		validateChan: make(chan []*txValidateItem),
 		quitChan:     make(chan struct{}),
 		resultChan:   make(chan error),
 		utxoView:     utxoView,
 		sigCache:     sigCache,
 		hashCache:    hashCache,
 		flags:        flags,
+		numWorkers:   numWorkers,
+		batchVerify:  batchVerify,
 	}
 }
 
 // ValidateTransactionScripts validates the scripts for the passed transaction
-// using multiple goroutines.
+// using multiple goroutines.  The sighash midstate of the transaction is
+// computed once and shared by all of its inputs.  It is kept in the passed
+// hash cache, when there is one, as long as the transaction is valid.
 func ValidateTransactionScripts(tx *btcutil.Tx, utxoView *UtxoViewpoint,
 	flags txscript.ScriptFlags, sigCache *txscript.SigCache,
 	hashCache *txscript.HashCache) error {
@@ -200,10 +300,16 @@ func ValidateTransactionScripts(tx *btcutil.Tx, utxoView *UtxoViewpoint,
 
 	// If the hashcache doesn't yet has the sighash midstate for this
 	// transaction, then we'll compute them now so we can re-use them
-	// amongst all worker validation goroutines.
-	if segwitActive && tx.MsgTx().HasWitness() &&
+	// amongst all worker validation goroutines.  Keep track of whether
+	// they were added here so they can be purged again when the
+	// transaction turns out to be invalid, since it won't be seen again in
+	// a block which would purge them otherwise.
+	var addedHashes bool
+	if segwitActive && tx.MsgTx().HasWitness() && hashCache != nil &&
 		!hashCache.ContainsHashes(tx.Hash()) {
+
 		hashCache.AddSigHashes(tx.MsgTx(), utxoView)
+		addedHashes = true
 	}
 
 	var cachedHashes *txscript.TxSigHashes
@@ -211,9 +317,15 @@ func ValidateTransactionScripts(tx *btcutil.Tx, utxoView *UtxoViewpoint,
 		// The same pointer to the transaction's sighash midstate will
 		// be re-used amongst all validation goroutines. By
 		// pre-computing the sighash here instead of during validation,
-		// we ensure the sighashes
-		// are only computed once.
-		cachedHashes, _ = hashCache.GetSigHashes(tx.Hash())
+		// we ensure the sighashes are only computed once per
+		// transaction rather than once per input.
+		if hashCache != nil {
+			cachedHashes, _ = hashCache.GetSigHashes(tx.Hash())
+		} else {
+			cachedHashes = txscript.NewTxSigHashes(
+				tx.MsgTx(), utxoView,
+			)
+		}
 	}
 
 	// Collect all of the transaction inputs and required information for
@@ -236,15 +348,22 @@ func ValidateTransactionScripts(tx *btcutil.Tx, utxoView *UtxoViewpoint,
 	}
 
 	// Validate all of the inputs.
-	validator := newTxValidator(utxoView, flags, sigCache, hashCache)
-	return validator.Validate(txValItems)
+	validator := newTxValidator(utxoView, flags, sigCache, hashCache, 0,
+		false)
+	err := validator.Validate(txValItems)
+	if err != nil && addedHashes {
+		hashCache.PurgeSigHashes(tx.Hash())
+	}
+	return err
 }
 
 // checkBlockScripts executes and validates the scripts for all transactions in
-// the passed block using multiple goroutines.
+// the passed block using up to the given number of goroutines, or the default
+// number when it is zero.  The taproot signatures are verified in batches when
+// batchVerify is set.
 func checkBlockScripts(block *btcutil.Block, utxoView *UtxoViewpoint,
 	scriptFlags txscript.ScriptFlags, sigCache *txscript.SigCache,
-	hashCache *txscript.HashCache) error {
+	hashCache *txscript.HashCache, numWorkers int, batchVerify bool) error {
 
 	// First determine if segwit is active according to the scriptFlags. If
 	// it isn't then we don't need to interact with the HashCache.
@@ -299,14 +418,11 @@ func checkBlockScripts(block *btcutil.Block, utxoView *UtxoViewpoint,
 	}
 
 	// Validate all of the inputs.
-	validator := newTxValidator(utxoView, scriptFlags, sigCache, hashCache)
-	start := time.Now()
+	validator := newTxValidator(utxoView, scriptFlags, sigCache, hashCache,
+		numWorkers, batchVerify)
 	if err := validator.Validate(txValItems); err != nil {
 		return err
 	}
-	elapsed := time.Since(start)
-
-	log.Tracef("block %v took %v to verify", block.Hash(), elapsed)
 
 	// If the HashCache is present, once we have validated the block, we no
 	// longer need the cached hashes for these transactions, so we purge
@@ -321,3 +437,53 @@ func checkBlockScripts(block *btcutil.Block, utxoView *UtxoViewpoint,
 
 	return nil
 }
+
+// ScriptValidationStats houses timing statistics about the validation of the
+// scripts of the blocks connected to the main chain.
+type ScriptValidationStats struct {
+	// Blocks is the total number of blocks whose scripts were validated.
+	Blocks int64
+
+	// Inputs is the total number of transaction inputs whose scripts were
+	// validated.
+	Inputs int64
+
+	// Duration is the total time spent validating scripts.
+	Duration time.Duration
+
+	// LastBlockInputs and LastBlockDuration are the number of inputs and
+	// the time spent validating the scripts of the most recent block.
+	LastBlockInputs   int
+	LastBlockDuration time.Duration
+}
+
+// scriptValidationStats tracks the script validation statistics of a chain
+// instance.  It has its own lock since the statistics are updated while the
+// chain lock is held and are queried independently of it.
+type scriptValidationStats struct {
+	sync.Mutex
+	stats ScriptValidationStats
+}
+
+// record adds the validation of the scripts of a block with the given number
+// of inputs which took the given duration to the statistics.
+func (s *scriptValidationStats) record(numInputs int, elapsed time.Duration) {
+	s.Lock()
+	s.stats.Blocks++
+	s.stats.Inputs += int64(numInputs)
+	s.stats.Duration += elapsed
+	s.stats.LastBlockInputs = numInputs
+	s.stats.LastBlockDuration = elapsed
+	s.Unlock()
+}
+
+// ScriptValidationStats returns the timing statistics about the validation of
+// the scripts of the blocks connected to the main chain since the chain
+// instance was created.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ScriptValidationStats() ScriptValidationStats {
+	b.scriptStats.Lock()
+	defer b.scriptStats.Unlock()
+	return b.scriptStats.stats
+}
@@ -5,10 +5,18 @@
 package blockchain
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil/v2"
 	"github.com/btcsuite/btcd/txscript/v2"
+	"github.com/btcsuite/btcd/wire/v2"
 )
 
 // TestCheckBlockScripts ensures that validating the all of the scripts in a
@@ -37,10 +45,246 @@ func TestCheckBlockScripts(t *testing.T) {
 		return
 	}
 
+	// Validate the scripts with the default number of goroutines as well as
+	// with a single one and with more goroutines than there are batches of
+	// inputs.
 	scriptFlags := txscript.ScriptBip16
-	err = checkBlockScripts(blocks[0], view, scriptFlags, nil, nil)
+	for _, numWorkers := range []int{0, 1, 1000} {
+		err = checkBlockScripts(blocks[0], view, scriptFlags, nil, nil,
+			numWorkers, false)
+		if err != nil {
+			t.Errorf("Transaction script validation with %d workers "+
+				"failed: %v\n", numWorkers, err)
+			return
+		}
+	}
+}
+
+// taprootScriptFlags are the script flags the blocks returned by
+// newTaprootSpendBlock are validated with.
+const taprootScriptFlags = txscript.ScriptBip16 |
+	txscript.ScriptVerifyWitness | txscript.ScriptVerifyTaproot
+
+// newTaprootSpendBlock returns a block with transactions which spend the passed
+// number of taproot outputs with key path signatures, along with a view which
+// holds the spent outputs.  The signature of the input with the passed index
+// is invalid, unless the index is negative.
+func newTaprootSpendBlock(t testing.TB, numInputs,
+	badInput int) (*btcutil.Block, *UtxoViewpoint) {
+
+	t.Helper()
+
+	// Create a transaction with an output for each input of the block,
+	// each of which pays to its own key.
+	keys := make([]*btcec.PrivateKey, numInputs)
+	fundTx := wire.NewMsgTx(2)
+	fundTx.AddTxIn(&wire.TxIn{})
+	for i := range keys {
+		key, err := btcec.NewPrivateKey()
+		if err != nil {
+			t.Fatalf("unable to generate key: %v", err)
+		}
+		keys[i] = key
+
+		pkScript, err := txscript.PayToTaprootScript(
+			txscript.ComputeTaprootKeyNoScript(key.PubKey()),
+		)
+		if err != nil {
+			t.Fatalf("unable to create script: %v", err)
+		}
+		fundTx.AddTxOut(wire.NewTxOut(1000, pkScript))
+	}
+	view := NewUtxoViewpoint()
+	view.AddTxOuts(btcutil.NewTx(fundTx), 100)
+
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Index: math.MaxUint32},
+	})
+	coinbase.AddTxOut(wire.NewTxOut(0, []byte{txscript.OP_TRUE}))
+	block := wire.MsgBlock{Transactions: []*wire.MsgTx{coinbase}}
+
+	fundHash := fundTx.TxHash()
+	for i, key := range keys {
+		tx := wire.NewMsgTx(2)
+		tx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: wire.OutPoint{
+				Hash:  fundHash,
+				Index: uint32(i),
+			},
+		})
+		tx.AddTxOut(wire.NewTxOut(900, []byte{txscript.OP_TRUE}))
+
+		prevOut := fundTx.TxOut[i]
+		witness, err := txscript.TaprootWitnessSignature(
+			tx, txscript.NewTxSigHashes(tx, view), 0, prevOut.Value,
+			prevOut.PkScript, txscript.SigHashDefault, key,
+		)
+		if err != nil {
+			t.Fatalf("unable to sign input: %v", err)
+		}
+		if i == badInput {
+			witness[0][0] ^= 0x01
+		}
+		tx.TxIn[0].Witness = witness
+
+		block.Transactions = append(block.Transactions, tx)
+	}
+
+	return btcutil.NewBlock(&block), view
+}
+
+// TestCheckBlockScriptsBatch ensures that verifying the taproot signatures of a
+// block in batches accepts the same blocks as verifying them one at a time.
+func TestCheckBlockScriptsBatch(t *testing.T) {
+	block, view := newTaprootSpendBlock(t, 50, -1)
+	badBlock, badView := newTaprootSpendBlock(t, 50, 37)
+
+	for _, batchVerify := range []bool{false, true} {
+		for _, numWorkers := range []int{0, 1} {
+			err := checkBlockScripts(block, view, taprootScriptFlags,
+				nil, nil, numWorkers, batchVerify)
+			if err != nil {
+				t.Fatalf("validation with %d workers and batch "+
+					"verification %v failed: %v", numWorkers,
+					batchVerify, err)
+			}
+
+			// The block with the invalid signature must be rejected
+			// with the error of the offending input.
+			err = checkBlockScripts(badBlock, badView,
+				taprootScriptFlags, nil, nil, numWorkers,
+				batchVerify)
+			var rerr RuleError
+			if !errors.As(err, &rerr) ||
+				rerr.ErrorCode != ErrScriptValidation {
+
+				t.Fatalf("validation with %d workers and batch "+
+					"verification %v: got error %v, want %v",
+					numWorkers, batchVerify, err,
+					ErrScriptValidation)
+			}
+			wantInput := badBlock.Transactions()[38].Hash().String()
+			if !strings.Contains(rerr.Description, wantInput) {
+				t.Fatalf("error %q doesn't name the invalid "+
+					"input %s", rerr.Description, wantInput)
+			}
+		}
+	}
+}
+
+// TestValidateItemsBatchFailure ensures a failed batch doesn't reject the
+// inputs when all of their signatures are valid on their own, so a fault of the
+// batch verification can't mark a valid block invalid.
+func TestValidateItemsBatchFailure(t *testing.T) {
+	block, view := newTaprootSpendBlock(t, 2, -1)
+
+	var items []*txValidateItem
+	for _, tx := range block.Transactions()[1:] {
+		sigHashes := txscript.NewTxSigHashes(tx.MsgTx(), view)
+		for i, txIn := range tx.MsgTx().TxIn {
+			items = append(items, &txValidateItem{
+				txInIndex: i,
+				txIn:      txIn,
+				tx:        tx,
+				sigHashes: sigHashes,
+			})
+		}
+	}
+
+	// Add a signature which doesn't match its message to the batch so it
+	// fails regardless of the signatures of the inputs.
+	key, err := btcec.NewPrivateKey()
 	if err != nil {
-		t.Errorf("Transaction script validation failed: %v\n", err)
-		return
+		t.Fatalf("NewPrivateKey: unexpected error: %v", err)
+	}
+	var msg [32]byte
+	sig, err := schnorr.Sign(key, msg[:])
+	if err != nil {
+		t.Fatalf("Sign: unexpected error: %v", err)
+	}
+	msg[0] ^= 0x01
+
+	validator := newTxValidator(view, taprootScriptFlags, nil, nil, 1,
+		true)
+	sigBatch := txscript.NewBatchVerifier(nil)
+	sigBatch.Add(msg[:], sig, key.PubKey())
+	if err := validator.validateItems(items, sigBatch); err != nil {
+		t.Fatalf("validateItems: unexpected error: %v", err)
+	}
+}
+
+// TestValidateTransactionScripts ensures transactions are validated with and
+// without a hash cache and that only the sighash midstates of valid
+// transactions are kept in the cache.
+func TestValidateTransactionScripts(t *testing.T) {
+	block, view := newTaprootSpendBlock(t, 2, 1)
+	goodTx, badTx := block.Transactions()[1], block.Transactions()[2]
+
+	err := ValidateTransactionScripts(goodTx, view, taprootScriptFlags,
+		nil, nil)
+	if err != nil {
+		t.Fatalf("validation without hash cache failed: %v", err)
+	}
+
+	hashCache := txscript.NewHashCache(10)
+	err = ValidateTransactionScripts(goodTx, view, taprootScriptFlags,
+		nil, hashCache)
+	if err != nil {
+		t.Fatalf("validation with hash cache failed: %v", err)
+	}
+	if !hashCache.ContainsHashes(goodTx.Hash()) {
+		t.Fatalf("midstate of valid transaction wasn't kept")
+	}
+
+	err = ValidateTransactionScripts(badTx, view, taprootScriptFlags,
+		nil, hashCache)
+	if err == nil {
+		t.Fatalf("invalid transaction was accepted")
+	}
+	if hashCache.ContainsHashes(badTx.Hash()) {
+		t.Fatalf("midstate of invalid transaction was kept")
+	}
+}
+
+// BenchmarkCheckBlockScripts benchmarks validating the scripts of a block
+// which spends many taproot outputs, which resembles the signature heavy
+// ranges of the initial block download, with and without verifying the
+// signatures in batches.  The validation uses a single goroutine so the
+// results reflect the processing time spent on the block.
+func BenchmarkCheckBlockScripts(b *testing.B) {
+	block, view := newTaprootSpendBlock(b, 1000, -1)
+
+	for _, batchVerify := range []bool{false, true} {
+		name := fmt.Sprintf("batch=%v", batchVerify)
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				err := checkBlockScripts(block, view,
+					taprootScriptFlags, nil, nil, 1,
+					batchVerify)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// TestScriptValidationStats ensures the script validation statistics add up
+// the validated blocks and keep track of the most recent one.
+func TestScriptValidationStats(t *testing.T) {
+	var s scriptValidationStats
+	s.record(10, time.Millisecond*3)
+	s.record(5, time.Millisecond*2)
+
+	want := ScriptValidationStats{
+		Blocks:            2,
+		Inputs:            15,
+		Duration:          time.Millisecond * 5,
+		LastBlockInputs:   5,
+		LastBlockDuration: time.Millisecond * 2,
+	}
+	if s.stats != want {
+		t.Fatalf("stats: got %+v, want %+v", s.stats, want)
 	}
 }
@@ -370,6 +370,82 @@ func (b *BlockChain) thresholdState(prevNode *blockNode,
 	return state, nil
 }
 
+// ThresholdStats houses statistics about the signalling for a rule change in
+// the confirmation window which contains a given block.
+type ThresholdStats struct {
+	// Period is the number of blocks in each confirmation window.
+	Period uint32
+
+	// Threshold is the number of blocks in a confirmation window which
+	// need to signal for the rule change in order to lock it in.
+	Threshold uint32
+
+	// Elapsed is the number of blocks of the confirmation window which
+	// precede the block.
+	Elapsed uint32
+
+	// Count is the number of blocks of the confirmation window preceding
+	// the block which signal for the rule change.
+	Count uint32
+
+	// Possible indicates whether or not the threshold can still be reached
+	// in the confirmation window.
+	Possible bool
+}
+
+// thresholdStats returns the signalling statistics for the confirmation window
+// which contains the block AFTER the given node.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func thresholdStats(prevNode *blockNode,
+	checker thresholdConditionChecker) (*ThresholdStats, error) {
+
+	stats := &ThresholdStats{
+		Period:    checker.MinerConfirmationWindow(),
+		Threshold: checker.RuleChangeActivationThreshold(),
+	}
+	if prevNode != nil {
+		stats.Elapsed = uint32(prevNode.height+1) % stats.Period
+	}
+
+	// Iterate backwards through the blocks of the window which precede
+	// the block to count the ones which signal.
+	countNode := prevNode
+	for i := uint32(0); i < stats.Elapsed; i++ {
+		condition, err := checker.Condition(countNode)
+		if err != nil {
+			return nil, err
+		}
+		if condition {
+			stats.Count++
+		}
+		countNode = countNode.parent
+	}
+
+	stats.Possible = stats.Period-stats.Threshold >= stats.Elapsed-stats.Count
+	return stats, nil
+}
+
+// ThresholdStats returns the signalling statistics of the given deployment ID
+// for the confirmation window which contains the block AFTER the end of the
+// current best chain.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ThresholdStats(deploymentID uint32) (*ThresholdStats, error) {
+	if deploymentID >= uint32(len(b.chainParams.Deployments)) {
+		return nil, DeploymentError(deploymentID)
+	}
+
+	deployment := &b.chainParams.Deployments[deploymentID]
+	checker := deploymentChecker{deployment: deployment, chain: b}
+
+	b.chainLock.Lock()
+	stats, err := thresholdStats(b.bestChain.Tip(), checker)
+	b.chainLock.Unlock()
+
+	return stats, err
+}
+
 // ThresholdState returns the current rule change threshold state of the given
 // deployment ID for the block AFTER the end of the current best chain.
 //
@@ -6,7 +6,9 @@ package blockchain
 
 import (
 	"testing"
+	"time"
 
+	"github.com/btcsuite/btcd/chaincfg/v2"
 	"github.com/btcsuite/btcd/chainhash/v2"
 )
 
@@ -320,3 +322,66 @@ func TestThresholdStateTransition(t *testing.T) {
 		}
 	}
 }
+
+// TestThresholdStats ensures the signalling statistics of a deployment are
+// calculated for the confirmation window which contains the next block.
+func TestThresholdStats(t *testing.T) {
+	t.Parallel()
+
+	params := chaincfg.RegressionNetParams
+	chain := newFakeChain(&params)
+	deployment := &params.Deployments[chaincfg.DeploymentTestDummy]
+	signalVersion := int32(vbTopBits | 1<<deployment.BitNumber)
+
+	// Create a chain which ends a confirmation window and add blocks of
+	// the next window where only every other one signals.
+	window := int32(params.MinerConfirmationWindow)
+	node := chain.bestChain.Tip()
+	timestamp := time.Unix(1700000000, 0)
+	addNodes := func(numNodes int32, signalEvery int32) {
+		for i := int32(0); i < numNodes; i++ {
+			version := int32(vbTopBits)
+			if signalEvery != 0 && i%signalEvery == 0 {
+				version = signalVersion
+			}
+			timestamp = timestamp.Add(time.Minute)
+			node = newFakeNode(node, version, 0x207fffff, timestamp)
+			chain.index.AddNode(node)
+		}
+		chain.bestChain.SetTip(node)
+	}
+
+	tests := []struct {
+		name        string
+		numNodes    int32
+		signalEvery int32
+		want        ThresholdStats
+	}{{
+		name:     "window boundary",
+		numNodes: window - 1,
+		want:     ThresholdStats{Elapsed: 0, Count: 0, Possible: true},
+	}, {
+		name:        "half signalling",
+		numNodes:    20,
+		signalEvery: 2,
+		want:        ThresholdStats{Elapsed: 20, Count: 10, Possible: true},
+	}, {
+		name:     "threshold unreachable",
+		numNodes: 30,
+		want:     ThresholdStats{Elapsed: 50, Count: 10, Possible: false},
+	}}
+
+	for _, test := range tests {
+		addNodes(test.numNodes, test.signalEvery)
+		stats, err := chain.ThresholdStats(chaincfg.DeploymentTestDummy)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		test.want.Period = params.MinerConfirmationWindow
+		test.want.Threshold = params.RuleChangeActivationThreshold
+		if *stats != test.want {
+			t.Fatalf("%s: got %+v, want %+v", test.name, *stats,
+				test.want)
+		}
+	}
+}
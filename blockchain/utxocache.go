@@ -179,11 +179,16 @@ func (ms *mapSlice) deleteMaps() {
 }
 
 const (
-	// utxoFlushPeriodicInterval is the interval at which a flush is performed
-	// when the flush mode FlushPeriodic is used.  This is used when the initial
-	// block download is complete and it's useful to flush periodically in case
-	// of unforeseen shutdowns.
+	// utxoFlushPeriodicInterval is the default interval at which a flush is
+	// performed when the flush mode FlushPeriodic is used.  This is used when
+	// the initial block download is complete and it's useful to flush
+	// periodically in case of unforeseen shutdowns.
 	utxoFlushPeriodicInterval = time.Minute * 5
+
+	// utxoStateLogInterval is the minimum time between progress messages
+	// while the utxo state is reconstructed from the blocks of the main
+	// chain.
+	utxoStateLogInterval = time.Second * 10
 )
 
 // FlushMode is used to indicate the different urgency types for a flush.
@@ -214,6 +219,10 @@ type utxoCache struct {
 	// should contain in normal circumstances.
 	maxTotalMemoryUsage uint64
 
+	// flushInterval is the interval at which a flush is performed when the
+	// flush mode FlushPeriodic is used.
+	flushInterval time.Duration
+
 	// cachedEntries keeps the internal cache of the utxo state.  The tfModified
 	// flag indicates that the state of the entry (potentially) deviates from the
 	// state in the database.  Explicit nil values in the map are used to
@@ -227,8 +236,16 @@ type utxoCache struct {
 }
 
 // newUtxoCache initiates a new utxo cache instance with its memory usage limited
-// to the given maximum.
-func newUtxoCache(db database.DB, maxTotalMemoryUsage uint64) *utxoCache {
+// to the given maximum.  The cache is flushed at the given interval when the
+// flush mode FlushPeriodic is used, or at utxoFlushPeriodicInterval when it is
+// zero.
+func newUtxoCache(db database.DB, maxTotalMemoryUsage uint64,
+	flushInterval time.Duration) *utxoCache {
+
+	if flushInterval == 0 {
+		flushInterval = utxoFlushPeriodicInterval
+	}
+
 	// While the entry isn't included in the map size, add the average size to the
 	// bucket size so we get some leftover space for entries to take up.
 	numMaxElements := calculateMinEntries(int(maxTotalMemoryUsage), bucketSize+avgEntrySize)
@@ -241,6 +258,7 @@ func newUtxoCache(db database.DB, maxTotalMemoryUsage uint64) *utxoCache {
 	return &utxoCache{
 		db:                  db,
 		maxTotalMemoryUsage: maxTotalMemoryUsage,
+		flushInterval:       flushInterval,
 		cachedEntries: mapSlice{
 			maps:                []map[wire.OutPoint]*UtxoEntry{m},
 			maxEntries:          []int{numMaxElements},
@@ -565,7 +583,7 @@ func (s *utxoCache) flush(dbTx database.Tx, mode FlushMode, bestState *BestState
 	case FlushPeriodic:
 		// If the time since the last flush is over the periodic interval,
 		// force a flush.  Otherwise just flush when the cache is full.
-		if time.Since(s.lastFlushTime) > utxoFlushPeriodicInterval {
+		if time.Since(s.lastFlushTime) > s.flushInterval {
 			threshold = 0
 		} else {
 			threshold = s.maxTotalMemoryUsage
@@ -597,6 +615,54 @@ func (b *BlockChain) FlushUtxoCache(mode FlushMode) error {
 	})
 }
 
+// clearUtxoSet removes all of the entries of the utxo set from the database.
+// The entries are removed in batches so that the memory needed by a database
+// transaction stays bounded.  The caller must ensure the utxo consistency status
+// is set to the genesis block beforehand, so that an interrupted removal is
+// resumed the next time the utxo state is initialized.
+func (s *utxoCache) clearUtxoSet(interrupt <-chan struct{}) error {
+	const maxDeletions = 500000
+	var totalDeleted uint64
+	for numDeleted := maxDeletions; numDeleted == maxDeletions; {
+		numDeleted = 0
+		err := s.db.Update(func(dbTx database.Tx) error {
+			cursor := dbTx.Metadata().Bucket(utxoSetBucketName).Cursor()
+			for ok := cursor.First(); ok && numDeleted < maxDeletions; ok = cursor.Next() {
+				if err := cursor.Delete(); err != nil {
+					return err
+				}
+				numDeleted++
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if numDeleted > 0 {
+			totalDeleted += uint64(numDeleted)
+			log.Infof("Deleted %d keys (%d total) from the UTXO set",
+				numDeleted, totalDeleted)
+		}
+
+		if interruptRequested(interrupt) {
+			return errInterruptRequested
+		}
+	}
+
+	return nil
+}
+
+// resetUtxoState marks the utxo state as being consistent at the genesis block
+// so that it is rebuilt from the blocks of the main chain when the consistency
+// of the utxo state is initialized.
+func (b *BlockChain) resetUtxoState() error {
+	log.Infof("Resetting the UTXO state")
+	return b.db.Update(func(dbTx database.Tx) error {
+		return dbPutUtxoStateConsistency(dbTx, b.chainParams.GenesisHash)
+	})
+}
+
 // InitConsistentState checks the consistency status of the utxo state and
 // replays blocks if it lags behind the best state of the blockchain.
 //
@@ -648,10 +714,27 @@ func (b *BlockChain) InitConsistentState(tip *blockNode, interrupt <-chan struct
 	}
 
 	lastFlushNode := b.index.LookupNode(statusHash)
-	log.Infof("Reconstructing UTXO state after an unclean shutdown. The UTXO state is "+
-		"consistent at block %s (%d) but the chainstate is at block %s (%d),  This may "+
-		"take a long time...", statusHash.String(), lastFlushNode.height,
-		tip.hash.String(), tip.height)
+	if lastFlushNode == nil {
+		return AssertError(fmt.Sprintf("last utxo consistency status contains "+
+			"hash that is not in the block index: %v", statusHash))
+	}
+	if lastFlushNode.height == 0 {
+		// The utxo set is empty when it is consistent at the genesis
+		// block since the outputs of the genesis block are not
+		// spendable.  Remove any entries left behind by an interrupted
+		// reset of the utxo state before rebuilding it.
+		log.Infof("Rebuilding the UTXO state from the genesis block up to "+
+			"block %s (%d).  This may take a long time...",
+			tip.hash.String(), tip.height)
+		if err := s.clearUtxoSet(interrupt); err != nil {
+			return err
+		}
+	} else {
+		log.Infof("Reconstructing UTXO state after an unclean shutdown. The UTXO state is "+
+			"consistent at block %s (%d) but the chainstate is at block %s (%d),  This may "+
+			"take a long time...", statusHash.String(), lastFlushNode.height,
+			tip.hash.String(), tip.height)
+	}
 
 	// Even though this should always be true, make sure the fetched hash is in
 	// the best chain.
@@ -677,6 +760,7 @@ func (b *BlockChain) InitConsistentState(tip *blockNode, interrupt <-chan struct
 		attachNodes.PushFront(n)
 	}
 
+	lastLogTime := time.Now()
 	for e := attachNodes.Front(); e != nil; e = e.Next() {
 		node = e.Value.(*blockNode)
 
@@ -712,6 +796,13 @@ func (b *BlockChain) InitConsistentState(tip *blockNode, interrupt <-chan struct
 
 			return errInterruptRequested
 		}
+
+		// Log the progress of the reconstruction periodically.
+		if now := time.Now(); now.Sub(lastLogTime) >= utxoStateLogInterval {
+			log.Infof("Reconstructed UTXO state up to block %s (%d/%d)",
+				node.hash.String(), node.height, tip.height)
+			lastLogTime = now
+		}
 	}
 	log.Debug("UTXO state reconstruction done")
 
@@ -315,7 +315,7 @@ func TestUtxoCacheEntrySize(t *testing.T) {
 	for _, test := range tests {
 		// Size is just something big enough so that the mapslice doesn't
 		// run out of memory.
-		s := newUtxoCache(nil, 1*1024*1024)
+		s := newUtxoCache(nil, 1*1024*1024, 0)
 
 		for height, block := range test.blocks {
 			for i, out := range block.txOuts {
@@ -590,6 +590,21 @@ func TestUtxoCacheFlush(t *testing.T) {
 	// Arbitrarily set the last flush time to 6 minutes ago.
 	cache.lastFlushTime = time.Now().Add(-time.Minute * 6)
 
+	// Attempt to flush with flush periodic and a longer configured flush
+	// interval.  Shouldn't flush.
+	cache.flushInterval = time.Minute * 10
+	err = chain.db.Update(func(dbTx database.Tx) error {
+		return cache.flush(dbTx, FlushPeriodic, chain.stateSnapshot)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error while flushing cache: %v", err)
+	}
+	if cache.cachedEntries.length() == 0 {
+		t.Fatalf("Expected %d entries, has %d instead",
+			len(outPoints1), cache.cachedEntries.length())
+	}
+	cache.flushInterval = utxoFlushPeriodicInterval
+
 	// Attempt to flush with flush periodic.  Should flush now.
 	err = chain.db.Update(func(dbTx database.Tx) error {
 		return cache.flush(dbTx, FlushPeriodic, chain.stateSnapshot)
@@ -617,7 +632,7 @@ func TestFlushNeededAfterPrune(t *testing.T) {
 	// 	genesis -> 1 -> 2 -> ... -> 15 -> 16  -> 17  -> 18
 	tip := tstTip
 	chain := newFakeChain(&chaincfg.MainNetParams)
-	chain.utxoCache = newUtxoCache(nil, 0)
+	chain.utxoCache = newUtxoCache(nil, 0, 0)
 	branchNodes := chainedNodes(chain.bestChain.Genesis(), 18)
 	for _, node := range branchNodes {
 		chain.index.SetStatusFlags(node, statusValid)
@@ -961,3 +976,71 @@ func TestInitConsistentState(t *testing.T) {
 			blocks[len(blocks)-1].Height())
 	}
 }
+
+// TestReindexChainState ensures the utxo state is rebuilt from the blocks of
+// the main chain once it is reset, and that stale entries of the utxo set are
+// removed before it is rebuilt.
+func TestReindexChainState(t *testing.T) {
+	chain, tearDown, err := chainSetup("reindexchainstate",
+		&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("failed to setup chain instance: %v", err)
+	}
+	defer tearDown()
+
+	blocks, err := loadBlocks("blk_0_to_14131.dat")
+	if err != nil {
+		t.Fatalf("failed to read block from file. %v", err)
+	}
+	for _, block := range blocks[1:500] {
+		_, _, err := chain.ProcessBlock(block, BFNone)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := chain.FlushUtxoCache(FlushRequired); err != nil {
+		t.Fatal(err)
+	}
+
+	// fetchUtxoSet returns the serialized entries of the utxo set.
+	fetchUtxoSet := func() map[string]string {
+		utxoSet := make(map[string]string)
+		chain.db.View(func(dbTx database.Tx) error {
+			bucket := dbTx.Metadata().Bucket(utxoSetBucketName)
+			return bucket.ForEach(func(k, v []byte) error {
+				utxoSet[string(k)] = string(v)
+				return nil
+			})
+		})
+		return utxoSet
+	}
+	want := fetchUtxoSet()
+	if len(want) == 0 {
+		t.Fatal("expected a non-empty utxo set")
+	}
+
+	// Reset the utxo state and add an entry which is not part of the utxo
+	// set as though an earlier removal of the entries was interrupted.
+	if err := chain.resetUtxoState(); err != nil {
+		t.Fatal(err)
+	}
+	err = chain.db.Update(func(dbTx database.Tx) error {
+		bucket := dbTx.Metadata().Bucket(utxoSetBucketName)
+		return bucket.Put([]byte("stale"), []byte("entry"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tip := chain.bestChain.Tip()
+	if err := chain.InitConsistentState(tip, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.FlushUtxoCache(FlushRequired); err != nil {
+		t.Fatal(err)
+	}
+	if got := fetchUtxoSet(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("rebuilt utxo set has %d entries, want %d", len(got),
+			len(want))
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"crypto/sha256"
+	"hash"
+
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/txscript/v2"
+	"github.com/btcsuite/btcd/wire/v2"
+)
+
+// ScriptClassStats houses the number and the total value of the unspent
+// transaction outputs whose public key scripts are of a script class.
+type ScriptClassStats struct {
+	TxOuts int64
+	Amount int64
+}
+
+// UtxoSetStats houses statistics about the unspent transaction output set as of
+// a block of the main chain.
+type UtxoSetStats struct {
+	// Height and Hash identify the block the statistics are for.
+	Height int32
+	Hash   chainhash.Hash
+
+	// Transactions is the number of transactions with unspent outputs and
+	// TxOuts is the number of unspent outputs.
+	Transactions int64
+	TxOuts       int64
+
+	// BogoSize is a database independent metric for the size of the utxo
+	// set, while DiskSize is the size of the keys and values of the utxo
+	// set in the database.
+	BogoSize int64
+	DiskSize int64
+
+	// SerializedHash is the hash of the serialized utxo set.  It is
+	// computed the same way as the hash_serialized_2 field of the
+	// gettxoutsetinfo RPC of Bitcoin Core, so the utxo sets of both
+	// implementations can be compared.
+	SerializedHash chainhash.Hash
+
+	// TotalAmount is the total value of all unspent outputs.
+	TotalAmount int64
+
+	// ScriptClasses breaks the unspent outputs down by the class of their
+	// public key scripts.
+	ScriptClasses map[txscript.ScriptClass]*ScriptClassStats
+
+	// Claims, Supports and Updates are the unspent outputs whose public
+	// key scripts carry a claim, support or update prefix.  These outputs
+	// are also counted in the script class of the whole script, which is
+	// nonstandard.
+	Claims   ScriptClassStats
+	Supports ScriptClassStats
+	Updates  ScriptClassStats
+}
+
+// addScript adds an unspent output with the passed public key script and value
+// to the script class and claim statistics.
+func (s *UtxoSetStats) addScript(pkScript []byte, amount int64) {
+	class := txscript.GetScriptClass(pkScript)
+	classStats, ok := s.ScriptClasses[class]
+	if !ok {
+		classStats = &ScriptClassStats{}
+		s.ScriptClasses[class] = classStats
+	}
+	classStats.TxOuts++
+	classStats.Amount += amount
+
+	// Scripts without a claim prefix fail to decode.
+	claim, err := txscript.DecodeClaimScript(pkScript)
+	if err != nil {
+		return
+	}
+	claimStats := &s.Claims
+	switch claim.Opcode {
+	case txscript.OP_SUPPORTCLAIM:
+		claimStats = &s.Supports
+	case txscript.OP_UPDATECLAIM:
+		claimStats = &s.Updates
+	}
+	claimStats.TxOuts++
+	claimStats.Amount += amount
+}
+
+// utxoSetHasher computes the serialized hash of the utxo set.  The outputs
+// must be added ordered by transaction hash and, within a transaction, by
+// index.  Each transaction with unspent outputs is serialized as:
+//
+//	<hash> <VLQ height*2+coinbase> [<VLQ index+1> <script> <VLQ amount>...] <VLQ 0>
+//
+// The serialization is preceded by the hash of the block the utxo set is for.
+type utxoSetHasher struct {
+	h      hash.Hash
+	buf    [10]byte // Large enough for any VLQ-encoded uint64.
+	inTx   bool
+	prevTx chainhash.Hash
+}
+
+// newUtxoSetHasher returns a utxo set hasher for the block with the passed
+// hash.
+func newUtxoSetHasher(blockHash *chainhash.Hash) *utxoSetHasher {
+	h := sha256.New()
+	h.Write(blockHash[:])
+	return &utxoSetHasher{h: h}
+}
+
+// writeVLQ writes the passed value to the hash in the VLQ format.
+func (u *utxoSetHasher) writeVLQ(n uint64) {
+	size := putVLQ(u.buf[:], n)
+	u.h.Write(u.buf[:size])
+}
+
+// add adds the passed unspent output to the hash and reports whether it is the
+// first output of a transaction.
+func (u *utxoSetHasher) add(outpoint wire.OutPoint, entry *UtxoEntry) bool {
+	newTx := !u.inTx || outpoint.Hash != u.prevTx
+	if newTx {
+		if u.inTx {
+			u.writeVLQ(0)
+		}
+		u.h.Write(outpoint.Hash[:])
+		code := uint64(entry.BlockHeight()) << 1
+		if entry.IsCoinBase() {
+			code |= 1
+		}
+		u.writeVLQ(code)
+		u.inTx = true
+		u.prevTx = outpoint.Hash
+	}
+
+	u.writeVLQ(uint64(outpoint.Index) + 1)
+	_ = wire.WriteVarBytes(u.h, 0, entry.PkScript())
+	u.writeVLQ(uint64(entry.Amount()))
+	return newTx
+}
+
+// sum returns the double SHA256 hash of the serialized utxo set.
+func (u *utxoSetHasher) sum() chainhash.Hash {
+	if u.inTx {
+		u.writeVLQ(0)
+		u.inTx = false
+	}
+	return chainhash.HashH(u.h.Sum(nil))
+}
+
+// FetchUtxoSetStats returns statistics about the unspent transaction output set
+// as of the best block of the main chain.  The utxo cache is flushed to the
+// database first and the whole utxo set is read, so this is expensive.  The
+// chain lock is only held while flushing, so blocks keep being processed while
+// the utxo set is read.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) FetchUtxoSetStats() (*UtxoSetStats, error) {
+	dbTx, best, err := b.utxoSetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	defer dbTx.Rollback()
+
+	stats := &UtxoSetStats{
+		Height:        best.Height,
+		Hash:          best.Hash,
+		ScriptClasses: make(map[txscript.ScriptClass]*ScriptClassStats),
+	}
+	hasher := newUtxoSetHasher(&best.Hash)
+	err = dbForEachUtxo(dbTx, func(outpoint wire.OutPoint,
+		entry *UtxoEntry, diskSize int) error {
+
+		if hasher.add(outpoint, entry) {
+			stats.Transactions++
+		}
+
+		pkScript := entry.PkScript()
+		stats.TxOuts++
+		stats.TotalAmount += entry.Amount()
+		stats.DiskSize += int64(diskSize)
+
+		// The size is made up of the hash and index of the
+		// outpoint, the height and coinbase flag, the amount
+		// and the length and bytes of the script.
+		stats.BogoSize += chainhash.HashSize + 4 + 4 + 8 + 2 +
+			int64(len(pkScript))
+
+		stats.addScript(pkScript, entry.Amount())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	stats.SerializedHash = hasher.sum()
+
+	return stats, nil
+}
@@ -1171,10 +1171,15 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *btcutil.Block, vi
 	//
 	// These utxo entries are needed for verification of things such as
 	// transaction inputs, counting pay-to-script-hashes, and scripts.
+	bench := b.benchmark(block)
+	start := time.Now()
 	err := view.fetchInputUtxos(b.utxoCache, block)
 	if err != nil {
 		return err
 	}
+	if bench != nil {
+		bench.LoadInputs = time.Since(start)
+	}
 
 	// BIP0016 describes a pay-to-script-hash type that is considered a
 	// "standard" type.  The rules for this BIP only apply to transactions
@@ -1288,6 +1293,12 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *btcutil.Block, vi
 		runScripts = false
 	}
 
+	// Likewise, don't run scripts for the ancestors of the block which is
+	// assumed to be valid.
+	if runScripts && b.isAssumedValid(node) {
+		runScripts = false
+	}
+
 	// Blocks created after the BIP0016 activation time need to have the
 	// pay-to-script-hash checks enabled.
 	var scriptFlags txscript.ScriptFlags
@@ -1371,11 +1382,22 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *btcutil.Block, vi
 	// expensive ECDSA signature check scripts.  Doing this last helps
 	// prevent CPU exhaustion attacks.
 	if runScripts {
+		start := time.Now()
 		err := checkBlockScripts(block, view, scriptFlags, b.sigCache,
-			b.hashCache)
+			b.hashCache, b.scriptWorkers, b.batchSigVerify)
 		if err != nil {
 			return err
 		}
+		elapsed := time.Since(start)
+		numInputs := countSpentOutputs(block)
+		b.scriptStats.record(numInputs, elapsed)
+		if bench != nil {
+			bench.NumInputs = numInputs
+			bench.Scripts = elapsed
+		}
+
+		log.Tracef("Validated the scripts of %d inputs of block %v in %v",
+			numInputs, block.Hash(), elapsed)
 	}
 
 	// Update the best hash for view to include this block since all of its
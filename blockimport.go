@@ -0,0 +1,387 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil/v2"
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/wire/v2"
+	"github.com/golang/snappy"
+)
+
+const (
+	// blockRecordHeaderSize is the size of the header which precedes each
+	// block in a block file.  It consists of the network magic followed by
+	// the length of the serialized block.
+	blockRecordHeaderSize = 8
+
+	// ffldbBlockTrailerSize is the size of the checksum which follows each
+	// block in the block files of the ffldb database.
+	ffldbBlockTrailerSize = 4
+
+	// ffldbCompressedBlockFlag is set in the block length of the blocks
+	// which are stored compressed with snappy in the block files of the
+	// ffldb database.
+	ffldbCompressedBlockFlag = 1 << 31
+
+	// blockImportLogInterval is the minimum time between progress messages
+	// while importing a block file.
+	blockImportLogInterval = time.Second * 10
+)
+
+// errBlockImportInterrupted is returned when a block import is interrupted by
+// the server shutting down.
+var errBlockImportInterrupted = errors.New("block import interrupted")
+
+// blockImportResult houses the number of blocks read from a block file by how
+// they were handled.
+type blockImportResult struct {
+	blocks   int // Blocks read from the file.
+	imported int // Blocks accepted by the chain.
+	known    int // Blocks the chain already had.
+	rejected int // Blocks rejected by the chain rules.
+	orphans  int // Blocks whose parent is neither known nor in the file.
+}
+
+// blockFilePos is the position of a block record in a block file.
+type blockFilePos struct {
+	offset int64
+	size   uint32 // Size of the record excluding the trailer.
+}
+
+// readBlockRecord reads the next block from a block file in the format used by
+// bootstrap.dat files as well as the blk*.dat files of other nodes:
+//
+//	<network> <block length> <serialized block>
+//
+// Blocks which are compressed in the block files of the ffldb database are
+// decompressed.  The size of the record is returned along with the block.
+//
+// It returns nil without an error when there are no more blocks to read, which
+// is also the case when the rest of the file is zero padding.
+func readBlockRecord(r io.Reader, net wire.BitcoinNet) ([]byte, uint32, error) {
+	var header [blockRecordHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.EOF {
+			return nil, 0, nil
+		}
+		return nil, 0, err
+	}
+
+	// Block files of other nodes are preallocated, so a zero network
+	// marks the end of the blocks in the file.
+	magic := binary.LittleEndian.Uint32(header[:4])
+	if magic == 0 {
+		return nil, 0, nil
+	}
+	if magic != uint32(net) {
+		return nil, 0, fmt.Errorf("network mismatch -- got %x, want %x",
+			magic, uint32(net))
+	}
+
+	// Read the block length and ensure it is sane.
+	blockLen := binary.LittleEndian.Uint32(header[4:])
+	compressed := blockLen&ffldbCompressedBlockFlag != 0
+	blockLen &^= ffldbCompressedBlockFlag
+	if blockLen > wire.MaxBlockPayload {
+		return nil, 0, fmt.Errorf("block payload of %d bytes is larger "+
+			"than the max allowed %d bytes", blockLen,
+			wire.MaxBlockPayload)
+	}
+
+	serializedBlock := make([]byte, blockLen)
+	if _, err := io.ReadFull(r, serializedBlock); err != nil {
+		return nil, 0, err
+	}
+	recordSize := blockRecordHeaderSize + blockLen
+	if !compressed {
+		return serializedBlock, recordSize, nil
+	}
+
+	serializedBlock, err := snappy.Decode(nil, serializedBlock)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to decompress block: %w", err)
+	}
+	return serializedBlock, recordSize, nil
+}
+
+// blockImporter imports the blocks of a block file into the block chain.
+type blockImporter struct {
+	chain       *blockchain.BlockChain
+	path        string
+	trailerSize int // Bytes which follow each block in the file.
+	quit        <-chan struct{}
+
+	result      blockImportResult
+	lastLogTime time.Time
+}
+
+// importBlock runs the passed block through the chain rules unless it is
+// already known.  Blocks which violate the chain rules are skipped.  It returns
+// whether the block is available in the chain afterwards.
+func (bi *blockImporter) importBlock(block *btcutil.Block) (bool, error) {
+	exists, err := bi.chain.HaveBlock(block.Hash())
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		bi.result.known++
+		return true, nil
+	}
+
+	_, isOrphan, err := bi.chain.ProcessBlock(block, blockchain.BFNone)
+	if err != nil {
+		var ruleErr blockchain.RuleError
+		if !errors.As(err, &ruleErr) {
+			return false, err
+		}
+		srvrLog.Warnf("Rejected block %v from %s: %v", block.Hash(),
+			bi.path, err)
+		bi.result.rejected++
+		return false, nil
+	}
+	if isOrphan {
+		bi.result.orphans++
+		return false, nil
+	}
+	bi.result.imported++
+
+	if now := time.Now(); now.Sub(bi.lastLogTime) >= blockImportLogInterval {
+		srvrLog.Infof("Imported %d blocks from %s (height %d)",
+			bi.result.imported, bi.path,
+			bi.chain.BestSnapshot().Height)
+		bi.lastLogTime = now
+	}
+	return true, nil
+}
+
+// importFile imports all of the blocks of the passed block file.
+//
+// The blocks are not necessarily stored in order since other nodes download
+// blocks in parallel, so the blocks whose parent is not known yet are deferred
+// until the parent has been read.  Only their position is kept, so they are
+// read again from the file once they can be connected.
+func (bi *blockImporter) importFile(f *os.File) error {
+	pending := make(map[chainhash.Hash][]blockFilePos)
+	r := bufio.NewReaderSize(f, 1<<20)
+	var offset int64
+	for {
+		select {
+		case <-bi.quit:
+			return errBlockImportInterrupted
+		default:
+		}
+
+		serializedBlock, recordSize, err := readBlockRecord(r,
+			bi.chain.ChainParams().Net)
+		if err != nil {
+			return fmt.Errorf("unable to read block at offset %d: %w",
+				offset, err)
+		}
+		if serializedBlock == nil {
+			break
+		}
+		if _, err := r.Discard(bi.trailerSize); err != nil {
+			return fmt.Errorf("unable to read block at offset %d: %w",
+				offset, err)
+		}
+		pos := blockFilePos{offset: offset, size: recordSize}
+		offset = pos.offset + int64(pos.size) + int64(bi.trailerSize)
+		bi.result.blocks++
+
+		block, err := btcutil.NewBlockFromBytes(serializedBlock)
+		if err != nil {
+			return fmt.Errorf("unable to deserialize block at "+
+				"offset %d: %w", pos.offset, err)
+		}
+
+		// Defer the block until its parent has been read when the
+		// parent is not known yet.
+		prevHash := block.MsgBlock().Header.PrevBlock
+		if prevHash != (chainhash.Hash{}) {
+			haveParent, err := bi.chain.HaveBlock(&prevHash)
+			if err != nil {
+				return err
+			}
+			if !haveParent {
+				pending[prevHash] = append(pending[prevHash], pos)
+				continue
+			}
+		}
+
+		available, err := bi.importBlock(block)
+		if err != nil {
+			return err
+		}
+		if !available {
+			continue
+		}
+
+		// Import the deferred descendants of the block.
+		parents := []chainhash.Hash{*block.Hash()}
+		for len(parents) > 0 {
+			parent := parents[0]
+			parents = parents[1:]
+			children := pending[parent]
+			delete(pending, parent)
+			for _, childPos := range children {
+				sr := io.NewSectionReader(f, childPos.offset,
+					int64(childPos.size))
+				serializedBlock, _, err := readBlockRecord(sr,
+					bi.chain.ChainParams().Net)
+				if err != nil {
+					return err
+				}
+				child, err := btcutil.NewBlockFromBytes(serializedBlock)
+				if err != nil {
+					return err
+				}
+				available, err := bi.importBlock(child)
+				if err != nil {
+					return err
+				}
+				if available {
+					parents = append(parents, *child.Hash())
+				}
+			}
+		}
+	}
+
+	for _, positions := range pending {
+		bi.result.orphans += len(positions)
+	}
+	return nil
+}
+
+// importBlockFile fully validates the blocks of the passed block file and adds
+// them to the block chain.  The file may be a bootstrap.dat file or one of the
+// blk*.dat files of another node, for which the trailer size is 0, or a block
+// file of the ffldb database, for which it is ffldbBlockTrailerSize.  Blocks
+// which are already known are skipped.  The import stops early when the quit
+// channel is closed.
+func importBlockFile(chain *blockchain.BlockChain, path string, trailerSize int,
+	quit <-chan struct{}) (*blockImportResult, error) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	bi := blockImporter{
+		chain:       chain,
+		path:        path,
+		trailerSize: trailerSize,
+		quit:        quit,
+		lastLogTime: time.Now(),
+	}
+	err = bi.importFile(f)
+	return &bi.result, err
+}
+
+// logBlockImportResult logs how the blocks read from the passed block file were
+// handled.
+func logBlockImportResult(result *blockImportResult, path string) {
+	srvrLog.Infof("Imported %d of %d blocks from %s (%d already known, %d "+
+		"rejected, %d without parent)", result.imported, result.blocks,
+		path, result.known, result.rejected, result.orphans)
+}
+
+// loadBlockFiles imports the blocks of the passed block files one after
+// another.  It returns early when the server is shutting down.
+func (s *server) loadBlockFiles(paths []string) {
+	for _, path := range paths {
+		srvrLog.Infof("Importing blocks from %s", path)
+		result, err := importBlockFile(s.chain, path, 0, s.quit)
+		if result != nil {
+			logBlockImportResult(result, path)
+		}
+		if errors.Is(err, errBlockImportInterrupted) {
+			return
+		}
+		if err != nil {
+			srvrLog.Errorf("Unable to import blocks from %s: %v", path,
+				err)
+		}
+	}
+}
+
+// reindexBlockFiles imports the blocks of the block files of the block database
+// which was moved to the passed directory by the reindex option.  The files are
+// imported in the order they were written, and each of them is removed once its
+// blocks are imported so that an interrupted reindex resumes with the remaining
+// files.  Blocks which can't be read from a damaged file are downloaded from the
+// network instead.  The directory is removed once all of the files are imported.
+func (s *server) reindexBlockFiles(dir string) error {
+	// The names of the block files are zero padded, so the sorted matches
+	// are in the order the files were written.
+	paths, err := filepath.Glob(filepath.Join(dir, "*.fdb"))
+	if err != nil {
+		return err
+	}
+	for i, path := range paths {
+		srvrLog.Infof("Reindexing blocks from %s (file %d of %d, height %d)",
+			path, i+1, len(paths), s.chain.BestSnapshot().Height)
+		result, err := importBlockFile(s.chain, path,
+			ffldbBlockTrailerSize, s.quit)
+		if result != nil {
+			logBlockImportResult(result, path)
+		}
+		if errors.Is(err, errBlockImportInterrupted) {
+			return err
+		}
+		if err != nil {
+			srvrLog.Errorf("Unable to reindex all blocks from %s: %v",
+				path, err)
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+
+	srvrLog.Infof("Reindexed the block database up to height %d",
+		s.chain.BestSnapshot().Height)
+	return os.RemoveAll(dir)
+}
+
+// startBlockImport runs the passed function, which imports blocks, in the
+// background.  Only a single import may run at a time.
+//
+// This function is safe for concurrent access.
+func (s *server) startBlockImport(importFn func()) error {
+	if !atomic.CompareAndSwapInt32(&s.importing, 0, 1) {
+		return errors.New("a block import is already running")
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer atomic.StoreInt32(&s.importing, 0)
+
+		importFn()
+	}()
+	return nil
+}
+
+// importBlockFiles starts importing the blocks of the passed block files one
+// after another in the background.  Only a single import may run at a time.
+//
+// This function is safe for concurrent access.
+func (s *server) importBlockFiles(paths []string) error {
+	return s.startBlockImport(func() {
+		s.loadBlockFiles(paths)
+	})
+}
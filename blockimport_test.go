@@ -0,0 +1,121 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg/v2"
+	"github.com/btcsuite/btcd/database"
+	"github.com/btcsuite/btcd/wire/v2"
+	"github.com/golang/snappy"
+)
+
+// TestImportBlockFile ensures the blocks of a block file are imported even when
+// they are stored out of order or compressed, that known blocks are skipped and
+// that the zero padding at the end of a preallocated block file is ignored.
+func TestImportBlockFile(t *testing.T) {
+	params := &chaincfg.MainNetParams
+
+	// Read the genesis block and the first blocks of the main network.
+	const numBlocks = 20
+	f, err := os.Open(filepath.Join("blockchain", "testdata",
+		"blk_0_to_14131.dat"))
+	if err != nil {
+		t.Fatalf("unable to open test blocks: %v", err)
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	var blocks [][]byte
+	for len(blocks) <= numBlocks {
+		serializedBlock, _, err := readBlockRecord(r, params.Net)
+		if err != nil {
+			t.Fatalf("readBlockRecord: unexpected error: %v", err)
+		}
+		blocks = append(blocks, serializedBlock)
+	}
+
+	// Write a block file with the genesis block first and the other blocks
+	// in reverse order followed by zero padding.  Every other block is
+	// compressed the way ffldb stores compressed blocks.
+	var buf bytes.Buffer
+	writeBlock := func(serializedBlock []byte, compress bool) {
+		blockLen := uint32(len(serializedBlock))
+		if compress {
+			serializedBlock = snappy.Encode(nil, serializedBlock)
+			blockLen = uint32(len(serializedBlock)) |
+				ffldbCompressedBlockFlag
+		}
+		var header [blockRecordHeaderSize]byte
+		binary.LittleEndian.PutUint32(header[:4], uint32(params.Net))
+		binary.LittleEndian.PutUint32(header[4:], blockLen)
+		buf.Write(header[:])
+		buf.Write(serializedBlock)
+	}
+	writeBlock(blocks[0], false)
+	for i := numBlocks; i > 0; i-- {
+		writeBlock(blocks[i], i%2 == 0)
+	}
+	buf.Write(make([]byte, 4096))
+	path := filepath.Join(t.TempDir(), "blk00000.dat")
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		t.Fatalf("unable to write block file: %v", err)
+	}
+
+	db, err := database.Create("ffldb", filepath.Join(t.TempDir(), "db"),
+		params.Net)
+	if err != nil {
+		t.Fatalf("unable to create database: %v", err)
+	}
+	defer db.Close()
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:               db,
+		ChainParams:      params,
+		TimeSource:       blockchain.NewMedianTime(),
+		UtxoCacheMaxSize: 1024 * 1024,
+	})
+	if err != nil {
+		t.Fatalf("unable to create chain: %v", err)
+	}
+
+	result, err := importBlockFile(chain, path, 0, make(chan struct{}))
+	if err != nil {
+		t.Fatalf("importBlockFile: unexpected error: %v", err)
+	}
+	want := blockImportResult{
+		blocks:   numBlocks + 1,
+		imported: numBlocks,
+		known:    1,
+	}
+	if *result != want {
+		t.Fatalf("importBlockFile: got %+v, want %+v", *result, want)
+	}
+	if height := chain.BestSnapshot().Height; height != numBlocks {
+		t.Fatalf("best height: got %d, want %d", height, numBlocks)
+	}
+
+	// Importing the file again skips all of the blocks.
+	result, err = importBlockFile(chain, path, 0, make(chan struct{}))
+	if err != nil {
+		t.Fatalf("importBlockFile: unexpected error: %v", err)
+	}
+	want = blockImportResult{blocks: numBlocks + 1, known: numBlocks + 1}
+	if *result != want {
+		t.Fatalf("importBlockFile again: got %+v, want %+v", *result,
+			want)
+	}
+
+	// Block files of other networks are rejected.
+	_, _, err = readBlockRecord(bytes.NewReader(buf.Bytes()), wire.TestNet3)
+	if err == nil {
+		t.Fatal("readBlockRecord: expected network mismatch error")
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil/v2"
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/database"
+	"github.com/btcsuite/btcd/database/ffldb"
+)
+
+// maxBlockRepairEvents is the maximum number of block repair events which are
+// kept.  The oldest events are discarded first.
+const maxBlockRepairEvents = 100
+
+// blockRepairEvent records a block whose stored copy was found to be damaged.
+type blockRepairEvent struct {
+	hash chainhash.Hash
+
+	// height is the height of the block in the main chain or -1 when it
+	// isn't part of the main chain.
+	height int32
+
+	// detected is the time the damage was detected and repaired is the
+	// time the block was repaired.  repaired is zero while the block isn't
+	// repaired yet.
+	detected time.Time
+	repaired time.Time
+}
+
+// blockRepairer repairs the blocks whose stored copies are found to be damaged
+// when they are read from the database by downloading them from peers again.
+// The repairs are recorded so they can be inspected by operators.
+type blockRepairer struct {
+	db    database.DB
+	chain *blockchain.BlockChain
+
+	// requestBlock requests the block with the passed hash from peers.
+	requestBlock func(hash chainhash.Hash)
+
+	mtx    sync.Mutex
+	events []blockRepairEvent
+}
+
+// newBlockRepairer returns a block repairer for the blocks stored in the passed
+// database which requests the damaged blocks by means of the passed function.
+func newBlockRepairer(db database.DB, chain *blockchain.BlockChain,
+	requestBlock func(hash chainhash.Hash)) *blockRepairer {
+
+	return &blockRepairer{
+		db:           db,
+		chain:        chain,
+		requestBlock: requestBlock,
+	}
+}
+
+// pendingEvent returns the event of the passed block while it isn't repaired
+// yet or nil when there is no such event.
+//
+// This function MUST be called with the mutex held.
+func (r *blockRepairer) pendingEvent(hash *chainhash.Hash) *blockRepairEvent {
+	for i := range r.events {
+		event := &r.events[i]
+		if event.hash == *hash && event.repaired.IsZero() {
+			return event
+		}
+	}
+	return nil
+}
+
+// corruptBlock records that the stored copy of the block with the passed hash
+// is damaged and requests the block from peers in order to repair it.  It is
+// the corrupt block handler of the database.
+//
+// This function is safe for concurrent access.
+func (r *blockRepairer) corruptBlock(hash chainhash.Hash) {
+	r.mtx.Lock()
+	if r.pendingEvent(&hash) != nil {
+		r.mtx.Unlock()
+		return
+	}
+	height, err := r.chain.BlockHeightByHash(&hash)
+	if err != nil {
+		height = -1
+	}
+	if len(r.events) >= maxBlockRepairEvents {
+		r.events = append(r.events[:0], r.events[1:]...)
+	}
+	r.events = append(r.events, blockRepairEvent{
+		hash:     hash,
+		height:   height,
+		detected: time.Now(),
+	})
+	r.mtx.Unlock()
+
+	srvrLog.Warnf("The stored copy of block %v (height %d) is damaged -- "+
+		"requesting it from peers to repair it", hash, height)
+
+	// The handler is invoked while the database is read, which may happen
+	// from within the sync manager, so the block is requested without
+	// waiting for it.
+	go r.requestBlock(hash)
+}
+
+// repairBlock replaces the damaged stored copy of the passed block, which was
+// downloaded from a peer, after making sure the downloaded copy is intact.
+//
+// This function is safe for concurrent access.
+func (r *blockRepairer) repairBlock(block *btcutil.Block) error {
+	// The hash of the block only commits to its header, so make sure the
+	// transactions and witnesses weren't tampered with.
+	header := &block.MsgBlock().Header
+	merkleRoot := blockchain.CalcMerkleRoot(block.Transactions(), false)
+	if merkleRoot != header.MerkleRoot {
+		return fmt.Errorf("block merkle root is invalid - block "+
+			"header indicates %v, but calculated value is %v",
+			header.MerkleRoot, merkleRoot)
+	}
+	if err := blockchain.ValidateWitnessCommitment(block); err != nil {
+		return err
+	}
+
+	if err := ffldb.RepairBlock(r.db, block); err != nil {
+		return err
+	}
+
+	r.mtx.Lock()
+	if event := r.pendingEvent(block.Hash()); event != nil {
+		event.repaired = time.Now()
+	}
+	r.mtx.Unlock()
+
+	srvrLog.Infof("Repaired block %v", block.Hash())
+	return nil
+}
+
+// repairs returns the recorded block repair events from oldest to newest.
+//
+// This function is safe for concurrent access.
+func (r *blockRepairer) repairs() []blockRepairEvent {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return append([]blockRepairEvent(nil), r.events...)
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil/v2"
+	"github.com/btcsuite/btcd/chaincfg/v2"
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/database"
+)
+
+// TestBlockRepairer ensures damaged blocks are requested and recorded once and
+// that they are only repaired with intact copies.
+func TestBlockRepairer(t *testing.T) {
+	params := &chaincfg.MainNetParams
+	db, err := database.Create("ffldb", filepath.Join(t.TempDir(), "db"),
+		params.Net)
+	if err != nil {
+		t.Fatalf("unable to create database: %v", err)
+	}
+	defer db.Close()
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:               db,
+		ChainParams:      params,
+		TimeSource:       blockchain.NewMedianTime(),
+		UtxoCacheMaxSize: 1024 * 1024,
+	})
+	if err != nil {
+		t.Fatalf("unable to create chain: %v", err)
+	}
+
+	requested := make(chan chainhash.Hash, maxBlockRepairEvents*2)
+	r := newBlockRepairer(db, chain, func(hash chainhash.Hash) {
+		requested <- hash
+	})
+
+	// The damaged genesis block is requested once even when the damage is
+	// detected again while it is pending.
+	genesis := btcutil.NewBlock(params.GenesisBlock)
+	r.corruptBlock(*genesis.Hash())
+	r.corruptBlock(*genesis.Hash())
+	select {
+	case hash := <-requested:
+		if hash != *genesis.Hash() {
+			t.Fatalf("requested block %v, want %v", hash,
+				genesis.Hash())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("damaged block was not requested")
+	}
+	repairs := r.repairs()
+	if len(repairs) != 1 || repairs[0].hash != *genesis.Hash() ||
+		repairs[0].height != 0 || !repairs[0].repaired.IsZero() {
+
+		t.Fatalf("unexpected repairs %+v", repairs)
+	}
+
+	// A copy whose transactions don't match the header is rejected.
+	genesisBytes, err := genesis.Bytes()
+	if err != nil {
+		t.Fatalf("unable to serialize block: %v", err)
+	}
+	tampered, err := btcutil.NewBlockFromBytes(genesisBytes)
+	if err != nil {
+		t.Fatalf("unable to deserialize block: %v", err)
+	}
+	tampered.MsgBlock().Transactions[0].LockTime = 1
+	tampered = btcutil.NewBlock(tampered.MsgBlock())
+	if err := r.repairBlock(tampered); err == nil {
+		t.Fatal("repairBlock: did not reject tampered block")
+	}
+	if repairs := r.repairs(); !repairs[0].repaired.IsZero() {
+		t.Fatal("tampered block was recorded as repaired")
+	}
+
+	// An intact copy repairs the block.
+	if err := r.repairBlock(genesis); err != nil {
+		t.Fatalf("repairBlock: unexpected error: %v", err)
+	}
+	if repairs := r.repairs(); repairs[0].repaired.IsZero() {
+		t.Fatal("repaired block was not recorded as repaired")
+	}
+
+	// The oldest repairs are discarded once the limit is reached.
+	for i := 0; i < maxBlockRepairEvents; i++ {
+		r.corruptBlock(chainhash.Hash{byte(i), byte(i >> 8), 1})
+	}
+	repairs = r.repairs()
+	if len(repairs) != maxBlockRepairEvents {
+		t.Fatalf("got %d repairs, want %d", len(repairs),
+			maxBlockRepairEvents)
+	}
+	if repairs[0].hash == *genesis.Hash() {
+		t.Fatal("oldest repair was not discarded")
+	}
+	if repairs[0].height != -1 {
+		t.Fatalf("got height %d for unknown block, want -1",
+			repairs[0].height)
+	}
+}
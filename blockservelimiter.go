@@ -0,0 +1,84 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// blockServeBurst is the duration worth of bytes at the configured rate which
+// may be served in a burst after the limiter was idle.
+const blockServeBurst = time.Second * 5
+
+// blockServeLimiter limits the rate at which historical blocks are served to
+// peers without the noban permission so that serving many peers performing
+// the initial block download does not starve the relay of new blocks and the
+// RPC clients of the node.  It is a token bucket of bytes which is refilled at
+// a fixed rate up to a maximum burst size and shared by all peers.
+//
+// Since the size of a block is not known before it is sent, historical blocks
+// are served as long as the bucket is not in debt and the bytes of every block
+// which is written to a peer without the noban permission are taken from the
+// bucket afterwards, which may leave it in debt.  Blocks relayed as they are
+// found take from the bucket as well, but never wait for it.
+//
+// The limiter is safe for concurrent access.
+type blockServeLimiter struct {
+	mtx    sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newBlockServeLimiter returns a new block serving limiter which allows the
+// passed rate of bytes per second.  The bucket starts full.
+func newBlockServeLimiter(rate uint64) *blockServeLimiter {
+	burst := float64(rate) * blockServeBurst.Seconds()
+	return &blockServeLimiter{
+		rate:   float64(rate),
+		burst:  burst,
+		tokens: burst,
+	}
+}
+
+// refill adds the tokens accumulated since the last refill at the passed time.
+//
+// This function MUST be called with the limiter lock held.
+func (l *blockServeLimiter) refill(now time.Time) {
+	if !l.last.IsZero() {
+		elapsed := now.Sub(l.last).Seconds()
+		if elapsed > 0 {
+			l.tokens += elapsed * l.rate
+			if l.tokens > l.burst {
+				l.tokens = l.burst
+			}
+		}
+	}
+	l.last = now
+}
+
+// Consume takes the passed number of bytes which were written at the passed
+// time from the bucket.
+func (l *blockServeLimiter) Consume(bytes uint64, now time.Time) {
+	l.mtx.Lock()
+	l.refill(now)
+	l.tokens -= float64(bytes)
+	l.mtx.Unlock()
+}
+
+// Delay returns how long to wait from the passed time on before the next
+// historical block may be served.  It is zero unless the bucket is in debt.
+func (l *blockServeLimiter) Delay(now time.Time) time.Duration {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	l.refill(now)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
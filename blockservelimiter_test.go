@@ -0,0 +1,42 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// TestBlockServeLimiter ensures blocks may be served while the bucket is not
+// in debt, that the bytes written may leave it in debt and that it is refilled
+// at the configured rate up to the burst size.
+func TestBlockServeLimiter(t *testing.T) {
+	l := newBlockServeLimiter(1000)
+	now := time.Unix(1700000000, 0)
+
+	if delay := l.Delay(now); delay != 0 {
+		t.Fatalf("Delay with full bucket: got %v, want 0", delay)
+	}
+
+	// A block larger than the bucket leaves it in debt.
+	l.Consume(8000, now)
+	if delay := l.Delay(now).Round(time.Millisecond); delay != time.Second*3 {
+		t.Fatalf("Delay in debt: got %v, want 3s", delay)
+	}
+	delay := l.Delay(now.Add(time.Second * 2)).Round(time.Millisecond)
+	if delay != time.Second {
+		t.Fatalf("Delay after refill: got %v, want 1s", delay)
+	}
+	if delay := l.Delay(now.Add(time.Second * 4)); delay != 0 {
+		t.Fatalf("Delay after debt is paid: got %v, want 0", delay)
+	}
+
+	// The bucket is refilled up to the burst size only.
+	l.Consume(5500, now.Add(time.Hour))
+	delay = l.Delay(now.Add(time.Hour)).Round(time.Millisecond)
+	if delay != time.Millisecond*500 {
+		t.Fatalf("Delay after idle period: got %v, want 500ms", delay)
+	}
+}
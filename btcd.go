@@ -18,6 +18,7 @@ import (
 
 	"github.com/btcsuite/btcd/blockchain/indexers"
 	"github.com/btcsuite/btcd/database"
+	"github.com/btcsuite/btcd/database/ffldb"
 	"github.com/btcsuite/btcd/limits"
 	"github.com/btcsuite/btcd/ossec"
 )
@@ -27,6 +28,10 @@ const (
 	// database type is appended to this value to form the full block
 	// database name.
 	blockDbNamePrefix = "blocks"
+
+	// reindexDbName is the name of the directory the block database is
+	// moved to while the block database is rebuilt from its block files.
+	reindexDbName = "reindex"
 )
 
 var (
@@ -56,10 +61,24 @@ func btcdMain(serverChan chan<- *server) error {
 		}
 	}()
 
+	// Limit the memory of the Go runtime to the memory budget when one is
+	// given, unless the limit is set explicitly by GOMEMLIMIT.
+	if cfg.MaxMemoryMiB != 0 {
+		if os.Getenv("GOMEMLIMIT") == "" {
+			debug.SetMemoryLimit(int64(cfg.MaxMemoryMiB) * 1024 * 1024)
+		}
+		btcdLog.Infof("Memory budget of %d MiB with a %d MiB UTXO cache "+
+			"and %d signature cache entries", cfg.MaxMemoryMiB,
+			cfg.UtxoCacheMaxSizeMiB, cfg.SigCacheMaxSize)
+	}
+
 	// Get a channel that will be closed when a shutdown signal has been
 	// triggered either from an OS signal such as SIGINT (Ctrl+C) or from
 	// another subsystem such as the RPC server.
 	interrupt := interruptListener()
+	shutdown := newShutdownMonitor(cfg.ShutdownTimeout)
+	go shutdown.Run(interrupt)
+	defer shutdown.Done()
 	defer btcdLog.Info("Shutdown complete")
 
 	// Show version at startup.
@@ -77,6 +96,9 @@ func btcdMain(serverChan chan<- *server) error {
 		}()
 	}
 
+	// Dump the profiles to the data directory when requested by a signal.
+	profileDumpListener(interrupt)
+
 	// Write cpu profile if requested.
 	if cfg.CPUProfile != "" {
 		f, err := os.Create(cfg.CPUProfile)
@@ -133,6 +155,7 @@ func btcdMain(serverChan chan<- *server) error {
 	defer func() {
 		// Ensure the database is sync'd and closed on shutdown.
 		btcdLog.Infof("Gracefully shutting down the database...")
+		shutdown.SetStage("flushing and closing the database")
 		db.Close()
 	}()
 
@@ -169,6 +192,30 @@ func btcdMain(serverChan chan<- *server) error {
 
 		return nil
 	}
+	if cfg.DropSpentIndex {
+		if err := indexers.DropSpentIndex(db, interrupt); err != nil {
+			btcdLog.Errorf("%v", err)
+			return err
+		}
+
+		return nil
+	}
+	if cfg.DropClaimFilters {
+		if err := indexers.DropClaimFilterIndex(db, interrupt); err != nil {
+			btcdLog.Errorf("%v", err)
+			return err
+		}
+
+		return nil
+	}
+	if cfg.DropClaimIndex {
+		if err := indexers.DropClaimIndex(db, interrupt); err != nil {
+			btcdLog.Errorf("%v", err)
+			return err
+		}
+
+		return nil
+	}
 
 	// Check if the database had previously been pruned.  If it had been, it's
 	// not possible to newly generate the tx index and addr index.
@@ -195,6 +242,13 @@ func btcdMain(serverChan chan<- *server) error {
 		btcdLog.Errorf("%v", err)
 		return err
 	}
+	if beenPruned && cfg.ReindexChainState {
+		err = fmt.Errorf("--reindexchainstate cannot be used as the node has "+
+			"been previously pruned. You must delete the files in the datadir: "+
+			"\"%s\" and sync from the beginning", cfg.DataDir)
+		btcdLog.Errorf("%v", err)
+		return err
+	}
 	if beenPruned && cfg.AddrIndex {
 		err = fmt.Errorf("--addrindex cannot be enabled as the node has been "+
 			"previously pruned. You must delete the files in the datadir: \"%s\" "+
@@ -202,6 +256,29 @@ func btcdMain(serverChan chan<- *server) error {
 		btcdLog.Errorf("%v", err)
 		return err
 	}
+	// The spent output index doesn't refer to the block files, so it may
+	// be kept while pruning, but it can't be built from pruned blocks.
+	if beenPruned && cfg.SpentIndex && !indexers.SpentIndexInitialized(db) {
+		err = fmt.Errorf("--spentindex cannot be enabled as the node has been "+
+			"previously pruned. You must delete the files in the datadir: \"%s\" "+
+			"and sync from the beginning to enable the desired index", cfg.DataDir)
+		btcdLog.Errorf("%v", err)
+		return err
+	}
+	if beenPruned && cfg.ClaimIndex && !indexers.ClaimIndexInitialized(db) {
+		err = fmt.Errorf("--claimindex cannot be enabled as the node has been "+
+			"previously pruned. You must delete the files in the datadir: \"%s\" "+
+			"and sync from the beginning to enable the desired index", cfg.DataDir)
+		btcdLog.Errorf("%v", err)
+		return err
+	}
+	if beenPruned && cfg.ClaimFilters && !indexers.ClaimFilterIndexInitialized(db) {
+		err = fmt.Errorf("--claimfilters cannot be enabled as the node has been "+
+			"previously pruned. You must delete the files in the datadir: \"%s\" "+
+			"and sync from the beginning to enable the desired index", cfg.DataDir)
+		btcdLog.Errorf("%v", err)
+		return err
+	}
 	// If we've previously been pruned and the cfindex isn't present, it means that the
 	// user wants to enable the cfindex after the node has already synced up and been
 	// pruned.
@@ -258,6 +335,9 @@ func btcdMain(serverChan chan<- *server) error {
 	unveilx(cfg.RPCKey, "rwc")
 	unveilx(cfg.RPCCert, "rwc")
 	unveilx(cfg.DataDir, "rwc")
+	if cfg.BlocksDir != "" {
+		unveilx(cfg.BlocksDir, "rwc")
+	}
 
 	// drop unveil and tty
 	pledgex("stdio rpath wpath cpath flock dns inet")
@@ -273,7 +353,14 @@ func btcdMain(serverChan chan<- *server) error {
 	}
 	defer func() {
 		btcdLog.Infof("Gracefully shutting down the server...")
+		if err := sdNotify("STOPPING=1"); err != nil {
+			btcdLog.Warnf("Unable to notify the service manager: %v",
+				err)
+		}
+		shutdown.SetStage("stopping the RPC server and the peers")
 		server.Stop()
+		shutdown.SetStage("waiting for the peers to disconnect and " +
+			"the UTXO cache to be flushed")
 		server.WaitForShutdown()
 		srvrLog.Infof("Server shutdown complete")
 	}()
@@ -282,6 +369,12 @@ func btcdMain(serverChan chan<- *server) error {
 		serverChan <- server
 	}
 
+	// Tell the service manager that btcd is ready now that the chain has
+	// been loaded and the server is running.
+	if err := sdNotify("READY=1\nSTATUS=Running"); err != nil {
+		btcdLog.Warnf("Unable to notify the service manager: %v", err)
+	}
+
 	// Wait until the interrupt signal is received from an OS signal or
 	// shutdown is requested through one of the subsystems such as the RPC
 	// server.
@@ -314,6 +407,16 @@ func removeRegressionDB(dbPath string) error {
 		}
 	}
 
+	// Remove the block files of the old database as well when they are
+	// stored separately.
+	if cfg.BlocksDir != "" && fileExists(cfg.BlocksDir) {
+		btcdLog.Infof("Removing regression test block files from '%s'",
+			cfg.BlocksDir)
+		if err := os.RemoveAll(cfg.BlocksDir); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -328,6 +431,108 @@ func blockDbPath(dbType string) string {
 	return dbPath
 }
 
+// reindexDbPath returns the path the block database is moved to while it is
+// rebuilt from its block files.
+func reindexDbPath() string {
+	return filepath.Join(cfg.DataDir, reindexDbName)
+}
+
+// reindexBlocksPath returns the path the block files of the block database are
+// moved to while it is rebuilt from them.  They are moved along with the block
+// database unless they are stored separately, in which case they are moved to
+// a directory within the blocks directory so they stay on the same disk.
+func reindexBlocksPath() string {
+	if cfg.BlocksDir == "" {
+		return reindexDbPath()
+	}
+	return filepath.Join(cfg.BlocksDir, reindexDbName)
+}
+
+// dbOptions returns the options the block database is opened with.
+func dbOptions() ffldb.Options {
+	return ffldb.Options{
+		CompressBlocks: cfg.CompressBlocks,
+		BlocksDir:      cfg.BlocksDir,
+	}
+}
+
+// moveBlockFiles moves the block files in the passed directory to the passed
+// destination directory, which is created if needed.
+func moveBlockFiles(dir, destDir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.fdb"))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(destDir, 0700); err != nil {
+		return err
+	}
+	for _, path := range paths {
+		err := os.Rename(path, filepath.Join(destDir, filepath.Base(path)))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prepareReindex moves the block database at the passed path aside so that a
+// new block database is created in its place, which is then rebuilt from the
+// block files of the old one once the server is started.  Block files which are
+// stored separately are moved aside first, which is repeated when it was
+// interrupted.  Nothing is moved when an earlier reindex is still in progress.
+func prepareReindex(dbPath string) error {
+	reindexPath := reindexDbPath()
+	if fileExists(reindexPath) {
+		btcdLog.Infof("Resuming the reindex of the block database from '%s'",
+			reindexPath)
+		return nil
+	}
+	if !fileExists(dbPath) {
+		btcdLog.Infof("There is no block database to reindex")
+		return nil
+	}
+
+	// The block files of a pruned block database don't contain all of the
+	// blocks of the chain, so it can't be rebuilt from them.  The database
+	// was already checked when its block files have been moved aside.
+	blocksPath := reindexBlocksPath()
+	if !fileExists(blocksPath) {
+		db, err := database.Open(cfg.DbType, dbPath,
+			activeNetParams.Net, dbOptions())
+		if err != nil {
+			return err
+		}
+		var beenPruned bool
+		err = db.View(func(dbTx database.Tx) error {
+			var err error
+			beenPruned, err = dbTx.BeenPruned()
+			return err
+		})
+		db.Close()
+		if err != nil {
+			return err
+		}
+		if beenPruned {
+			return fmt.Errorf("--reindex cannot be used as the node "+
+				"has been previously pruned. You must delete the "+
+				"files in the datadir: \"%s\" and sync from the "+
+				"beginning", cfg.DataDir)
+		}
+	}
+
+	if cfg.BlocksDir != "" {
+		btcdLog.Infof("Moving the block files to '%s' to reindex them",
+			blocksPath)
+		if err := moveBlockFiles(cfg.BlocksDir, blocksPath); err != nil {
+			return err
+		}
+	}
+
+	btcdLog.Infof("Moving the block database to '%s' to reindex it",
+		reindexPath)
+	return os.Rename(dbPath, reindexPath)
+}
+
 // warnMultipleDBs shows a warning if multiple block database types are detected.
 // This is not a situation most users want.  It is handy for development however
 // to support multiple side-by-side databases.
@@ -388,8 +593,18 @@ func loadBlockDB() (database.DB, error) {
 	// each run, so remove it now if it already exists.
 	removeRegressionDB(dbPath)
 
+	// Move the block database aside to rebuild it from its block files when
+	// requested.
+	if cfg.Reindex {
+		if err := prepareReindex(dbPath); err != nil {
+			return nil, err
+		}
+	}
+
 	btcdLog.Infof("Loading block database from '%s'", dbPath)
-	db, err := database.Open(cfg.DbType, dbPath, activeNetParams.Net)
+	dbOpts := dbOptions()
+	db, err := database.Open(cfg.DbType, dbPath, activeNetParams.Net,
+		dbOpts)
 	if err != nil {
 		// Return the error if it's not because the database doesn't
 		// exist.
@@ -404,7 +619,8 @@ func loadBlockDB() (database.DB, error) {
 		if err != nil {
 			return nil, err
 		}
-		db, err = database.Create(cfg.DbType, dbPath, activeNetParams.Net)
+		db, err = database.Create(cfg.DbType, dbPath,
+			activeNetParams.Net, dbOpts)
 		if err != nil {
 			return nil, err
 		}
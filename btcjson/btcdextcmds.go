@@ -89,6 +89,21 @@ func NewGenerateCmd(numBlocks uint32) *GenerateCmd {
 	}
 }
 
+// GenerateBlockCmd defines the generateblock JSON-RPC command.
+type GenerateBlockCmd struct {
+	Output       string
+	Transactions []string
+}
+
+// NewGenerateBlockCmd returns a new instance which can be used to issue a
+// generateblock JSON-RPC command.
+func NewGenerateBlockCmd(output string, transactions []string) *GenerateBlockCmd {
+	return &GenerateBlockCmd{
+		Output:       output,
+		Transactions: transactions,
+	}
+}
+
 // GetBestBlockCmd defines the getbestblock JSON-RPC command.
 type GetBestBlockCmd struct{}
 
@@ -98,6 +113,15 @@ func NewGetBestBlockCmd() *GetBestBlockCmd {
 	return &GetBestBlockCmd{}
 }
 
+// GetConnectionTargetsCmd defines the getconnectiontargets JSON-RPC command.
+type GetConnectionTargetsCmd struct{}
+
+// NewGetConnectionTargetsCmd returns a new instance which can be used to issue
+// a getconnectiontargets JSON-RPC command.
+func NewGetConnectionTargetsCmd() *GetConnectionTargetsCmd {
+	return &GetConnectionTargetsCmd{}
+}
+
 // GetCurrentNetCmd defines the getcurrentnet JSON-RPC command.
 type GetCurrentNetCmd struct{}
 
@@ -128,6 +152,61 @@ func NewGetHeadersCmd(blockLocators []string, hashStop string) *GetHeadersCmd {
 	}
 }
 
+// GetNATInfoCmd defines the getnatinfo JSON-RPC command.
+type GetNATInfoCmd struct{}
+
+// NewGetNATInfoCmd returns a new instance which can be used to issue a
+// getnatinfo JSON-RPC command.
+func NewGetNATInfoCmd() *GetNATInfoCmd {
+	return &GetNATInfoCmd{}
+}
+
+// GetStratumInfoCmd defines the getstratuminfo JSON-RPC command.
+type GetStratumInfoCmd struct{}
+
+// NewGetStratumInfoCmd returns a new instance which can be used to issue a
+// getstratuminfo JSON-RPC command.
+func NewGetStratumInfoCmd() *GetStratumInfoCmd {
+	return &GetStratumInfoCmd{}
+}
+
+// SetConnectionTargetsCmd defines the setconnectiontargets JSON-RPC command.
+type SetConnectionTargetsCmd struct {
+	Outbound       *uint32
+	BlockRelayOnly *uint32
+	Onion          *uint32
+}
+
+// NewSetConnectionTargetsCmd returns a new instance which can be used to issue
+// a setconnectiontargets JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters keeps the current target.
+func NewSetConnectionTargetsCmd(outbound, blockRelayOnly,
+	onion *uint32) *SetConnectionTargetsCmd {
+
+	return &SetConnectionTargetsCmd{
+		Outbound:       outbound,
+		BlockRelayOnly: blockRelayOnly,
+		Onion:          onion,
+	}
+}
+
+// SetSignalBitCmd defines the setsignalbit JSON-RPC command.
+type SetSignalBitCmd struct {
+	Bit    uint32
+	Signal bool
+}
+
+// NewSetSignalBitCmd returns a new instance which can be used to issue a
+// setsignalbit JSON-RPC command.
+func NewSetSignalBitCmd(bit uint32, signal bool) *SetSignalBitCmd {
+	return &SetSignalBitCmd{
+		Bit:    bit,
+		Signal: signal,
+	}
+}
+
 // VersionCmd defines the version JSON-RPC command.
 //
 // NOTE: This is a btcsuite extension ported from
@@ -148,9 +227,15 @@ func init() {
 	MustRegisterCmd("debuglevel", (*DebugLevelCmd)(nil), flags)
 	MustRegisterCmd("node", (*NodeCmd)(nil), flags)
 	MustRegisterCmd("generate", (*GenerateCmd)(nil), flags)
+	MustRegisterCmd("generateblock", (*GenerateBlockCmd)(nil), flags)
 	MustRegisterCmd("generatetoaddress", (*GenerateToAddressCmd)(nil), flags)
 	MustRegisterCmd("getbestblock", (*GetBestBlockCmd)(nil), flags)
+	MustRegisterCmd("getconnectiontargets", (*GetConnectionTargetsCmd)(nil), flags)
 	MustRegisterCmd("getcurrentnet", (*GetCurrentNetCmd)(nil), flags)
 	MustRegisterCmd("getheaders", (*GetHeadersCmd)(nil), flags)
+	MustRegisterCmd("getnatinfo", (*GetNATInfoCmd)(nil), flags)
+	MustRegisterCmd("getstratuminfo", (*GetStratumInfoCmd)(nil), flags)
+	MustRegisterCmd("setconnectiontargets", (*SetConnectionTargetsCmd)(nil), flags)
+	MustRegisterCmd("setsignalbit", (*SetSignalBitCmd)(nil), flags)
 	MustRegisterCmd("version", (*VersionCmd)(nil), flags)
 }
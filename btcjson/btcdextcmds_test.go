@@ -114,6 +114,22 @@ func TestBtcdExtCmds(t *testing.T) {
 				NumBlocks: 1,
 			},
 		},
+		{
+			name: "generateblock",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("generateblock", "1Address",
+					[]string{"0102", "abcd"})
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGenerateBlockCmd("1Address",
+					[]string{"0102", "abcd"})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"generateblock","params":["1Address",["0102","abcd"]],"id":1}`,
+			unmarshalled: &btcjson.GenerateBlockCmd{
+				Output:       "1Address",
+				Transactions: []string{"0102", "abcd"},
+			},
+		},
 		{
 			name: "generatetoaddress",
 			newCmd: func() (interface{}, error) {
@@ -143,6 +159,17 @@ func TestBtcdExtCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"getbestblock","params":[],"id":1}`,
 			unmarshalled: &btcjson.GetBestBlockCmd{},
 		},
+		{
+			name: "getconnectiontargets",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getconnectiontargets")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetConnectionTargetsCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getconnectiontargets","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetConnectionTargetsCmd{},
+		},
 		{
 			name: "getcurrentnet",
 			newCmd: func() (interface{}, error) {
@@ -194,6 +221,70 @@ func TestBtcdExtCmds(t *testing.T) {
 				HashStop: "000000000000000000ba33b33e1fad70b69e234fc24414dd47113bff38f523f7",
 			},
 		},
+		{
+			name: "getnatinfo",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getnatinfo")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetNATInfoCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getnatinfo","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetNATInfoCmd{},
+		},
+		{
+			name: "getstratuminfo",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getstratuminfo")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetStratumInfoCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getstratuminfo","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetStratumInfoCmd{},
+		},
+		{
+			name: "setconnectiontargets",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("setconnectiontargets")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSetConnectionTargetsCmd(nil, nil, nil)
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"setconnectiontargets","params":[],"id":1}`,
+			unmarshalled: &btcjson.SetConnectionTargetsCmd{},
+		},
+		{
+			name: "setconnectiontargets optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("setconnectiontargets", 10, 2, 4)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSetConnectionTargetsCmd(
+					btcjson.Uint32(10), btcjson.Uint32(2),
+					btcjson.Uint32(4))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setconnectiontargets","params":[10,2,4],"id":1}`,
+			unmarshalled: &btcjson.SetConnectionTargetsCmd{
+				Outbound:       btcjson.Uint32(10),
+				BlockRelayOnly: btcjson.Uint32(2),
+				Onion:          btcjson.Uint32(4),
+			},
+		},
+		{
+			name: "setsignalbit",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("setsignalbit", 5, true)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSetSignalBitCmd(5, true)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setsignalbit","params":[5,true],"id":1}`,
+			unmarshalled: &btcjson.SetSignalBitCmd{
+				Bit:    5,
+				Signal: true,
+			},
+		},
 		{
 			name: "version",
 			newCmd: func() (interface{}, error) {
@@ -18,3 +18,48 @@ type VersionResult struct {
 	Prerelease    string `json:"prerelease"`
 	BuildMetadata string `json:"buildmetadata"`
 }
+
+// GenerateBlockResult models the data returned from the generateblock command.
+type GenerateBlockResult struct {
+	Hash string `json:"hash"`
+}
+
+// ConnectionTargetsResult models the data returned from the
+// getconnectiontargets and setconnectiontargets commands.
+type ConnectionTargetsResult struct {
+	Outbound       uint32 `json:"outbound"`
+	BlockRelayOnly uint32 `json:"blockrelayonly"`
+	Onion          uint32 `json:"onion"`
+	MaxPeers       int    `json:"maxpeers"`
+}
+
+// GetNATInfoResult models the data returned from the getnatinfo command.
+type GetNATInfoResult struct {
+	Protocol     string `json:"protocol"`
+	ExternalIP   string `json:"externalip,omitempty"`
+	ExternalPort uint16 `json:"externalport,omitempty"`
+	InternalPort uint16 `json:"internalport"`
+	Expires      int64  `json:"expires,omitempty"`
+	LastError    string `json:"lasterror,omitempty"`
+}
+
+// StratumWorkerResult models the share statistics of a single worker returned
+// by the getstratuminfo command.
+type StratumWorkerResult struct {
+	Name         string  `json:"name"`
+	Connections  int     `json:"connections"`
+	Difficulty   float64 `json:"difficulty"`
+	HashesPerSec float64 `json:"hashespersec"`
+	Accepted     uint64  `json:"accepted"`
+	Rejected     uint64  `json:"rejected"`
+	Stale        uint64  `json:"stale"`
+	Blocks       uint64  `json:"blocks"`
+	LastShare    int64   `json:"lastshare"`
+}
+
+// GetStratumInfoResult models the data returned from the getstratuminfo
+// command.
+type GetStratumInfoResult struct {
+	Clients int                   `json:"clients"`
+	Workers []StratumWorkerResult `json:"workers"`
+}
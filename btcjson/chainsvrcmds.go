@@ -59,6 +59,59 @@ type TransactionInput struct {
 	Vout uint32 `json:"vout"`
 }
 
+// ClearBannedCmd defines the clearbanned JSON-RPC command.
+type ClearBannedCmd struct{}
+
+// NewClearBannedCmd returns a new instance which can be used to issue a
+// clearbanned JSON-RPC command.
+func NewClearBannedCmd() *ClearBannedCmd {
+	return &ClearBannedCmd{}
+}
+
+// CombinePsbtCmd defines the combinepsbt JSON-RPC command.
+type CombinePsbtCmd struct {
+	Psbts []string
+}
+
+// NewCombinePsbtCmd returns a new instance which can be used to issue a
+// combinepsbt JSON-RPC command.
+func NewCombinePsbtCmd(psbts []string) *CombinePsbtCmd {
+	return &CombinePsbtCmd{
+		Psbts: psbts,
+	}
+}
+
+// CompactDBCmd defines the compactdb JSON-RPC command.
+type CompactDBCmd struct{}
+
+// NewCompactDBCmd returns a new instance which can be used to issue a compactdb
+// JSON-RPC command.
+func NewCompactDBCmd() *CompactDBCmd {
+	return &CompactDBCmd{}
+}
+
+// ConvertToPsbtCmd defines the converttopsbt JSON-RPC command.
+type ConvertToPsbtCmd struct {
+	HexTx         string
+	PermitSigData *bool `jsonrpcdefault:"false"`
+	IsWitness     *bool
+}
+
+// NewConvertToPsbtCmd returns a new instance which can be used to issue a
+// converttopsbt JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewConvertToPsbtCmd(hexTx string, permitSigData *bool,
+	isWitness *bool) *ConvertToPsbtCmd {
+
+	return &ConvertToPsbtCmd{
+		HexTx:         hexTx,
+		PermitSigData: permitSigData,
+		IsWitness:     isWitness,
+	}
+}
+
 // CreateRawTransactionCmd defines the createrawtransaction JSON-RPC command.
 type CreateRawTransactionCmd struct {
 	Inputs   []TransactionInput
@@ -85,6 +138,19 @@ func NewCreateRawTransactionCmd(inputs []TransactionInput, amounts map[string]fl
 	}
 }
 
+// DecodePsbtCmd defines the decodepsbt JSON-RPC command.
+type DecodePsbtCmd struct {
+	Psbt string
+}
+
+// NewDecodePsbtCmd returns a new instance which can be used to issue a
+// decodepsbt JSON-RPC command.
+func NewDecodePsbtCmd(psbt string) *DecodePsbtCmd {
+	return &DecodePsbtCmd{
+		Psbt: psbt,
+	}
+}
+
 // DecodeRawTransactionCmd defines the decoderawtransaction JSON-RPC command.
 type DecodeRawTransactionCmd struct {
 	HexTx string
@@ -126,6 +192,22 @@ func NewDeriveAddressesCmd(descriptor string, descriptorRange *DescriptorRange)
 	}
 }
 
+// DumpProfilesCmd defines the dumpprofiles JSON-RPC command.
+type DumpProfilesCmd struct {
+	CPUSeconds *int `jsonrpcdefault:"30"`
+}
+
+// NewDumpProfilesCmd returns a new instance which can be used to issue a
+// dumpprofiles JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewDumpProfilesCmd(cpuSeconds *int) *DumpProfilesCmd {
+	return &DumpProfilesCmd{
+		CPUSeconds: cpuSeconds,
+	}
+}
+
 // ChangeType defines the different output types to use for the change address
 // of a transaction built by the node.
 type ChangeType string
@@ -154,6 +236,24 @@ type FundRawTransactionOpts struct {
 	IncludeUnsafe          *bool                 `json:"include_unsafe,omitempty"`
 }
 
+// FinalizePsbtCmd defines the finalizepsbt JSON-RPC command.
+type FinalizePsbtCmd struct {
+	Psbt    string
+	Extract *bool `jsonrpcdefault:"true"`
+}
+
+// NewFinalizePsbtCmd returns a new instance which can be used to issue a
+// finalizepsbt JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewFinalizePsbtCmd(psbt string, extract *bool) *FinalizePsbtCmd {
+	return &FinalizePsbtCmd{
+		Psbt:    psbt,
+		Extract: extract,
+	}
+}
+
 // FundRawTransactionCmd defines the fundrawtransaction JSON-RPC command
 type FundRawTransactionCmd struct {
 	HexTx     string
@@ -189,6 +289,86 @@ func NewGetAddedNodeInfoCmd(dns bool, node *string) *GetAddedNodeInfoCmd {
 	}
 }
 
+// AddressIndexRequest selects the addresses queried by the getaddresstxids,
+// getaddressbalance and getaddressutxos JSON-RPC commands.  For compatibility
+// with the insight API, a single address may be passed as a string instead.
+type AddressIndexRequest struct {
+	Addresses []string `json:"addresses"`
+
+	// Start and End limit the transactions returned by getaddresstxids to
+	// the blocks within the range of heights.
+	Start *int32 `json:"start,omitempty"`
+	End   *int32 `json:"end,omitempty"`
+
+	// ChainInfo includes the best block in the result of getaddressutxos.
+	ChainInfo *bool `json:"chainInfo,omitempty"`
+}
+
+// UnmarshalJSON provides a custom Unmarshal method for AddressIndexRequest.
+// This is necessary because a single address may be passed as a string.
+func (r *AddressIndexRequest) UnmarshalJSON(data []byte) error {
+	var addr string
+	if err := json.Unmarshal(data, &addr); err == nil {
+		*r = AddressIndexRequest{Addresses: []string{addr}}
+		return nil
+	}
+
+	type addressIndexRequest AddressIndexRequest
+	return json.Unmarshal(data, (*addressIndexRequest)(r))
+}
+
+// GetAddressBalanceCmd defines the getaddressbalance JSON-RPC command.
+type GetAddressBalanceCmd struct {
+	Request AddressIndexRequest
+}
+
+// NewGetAddressBalanceCmd returns a new instance which can be used to issue a
+// getaddressbalance JSON-RPC command.
+func NewGetAddressBalanceCmd(addresses []string) *GetAddressBalanceCmd {
+	return &GetAddressBalanceCmd{
+		Request: AddressIndexRequest{Addresses: addresses},
+	}
+}
+
+// GetAddressTxIDsCmd defines the getaddresstxids JSON-RPC command.
+type GetAddressTxIDsCmd struct {
+	Request AddressIndexRequest
+}
+
+// NewGetAddressTxIDsCmd returns a new instance which can be used to issue a
+// getaddresstxids JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetAddressTxIDsCmd(addresses []string, start, end *int32) *GetAddressTxIDsCmd {
+	return &GetAddressTxIDsCmd{
+		Request: AddressIndexRequest{
+			Addresses: addresses,
+			Start:     start,
+			End:       end,
+		},
+	}
+}
+
+// GetAddressUtxosCmd defines the getaddressutxos JSON-RPC command.
+type GetAddressUtxosCmd struct {
+	Request AddressIndexRequest
+}
+
+// NewGetAddressUtxosCmd returns a new instance which can be used to issue a
+// getaddressutxos JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetAddressUtxosCmd(addresses []string, chainInfo *bool) *GetAddressUtxosCmd {
+	return &GetAddressUtxosCmd{
+		Request: AddressIndexRequest{
+			Addresses: addresses,
+			ChainInfo: chainInfo,
+		},
+	}
+}
+
 // GetBestBlockHashCmd defines the getbestblockhash JSON-RPC command.
 type GetBestBlockHashCmd struct{}
 
@@ -234,6 +414,15 @@ func NewGetBlockCountCmd() *GetBlockCountCmd {
 	return &GetBlockCountCmd{}
 }
 
+// GetDeploymentInfoCmd defines the getdeploymentinfo JSON-RPC command.
+type GetDeploymentInfoCmd struct{}
+
+// NewGetDeploymentInfoCmd returns a new instance which can be used to issue a
+// getdeploymentinfo JSON-RPC command.
+func NewGetDeploymentInfoCmd() *GetDeploymentInfoCmd {
+	return &GetDeploymentInfoCmd{}
+}
+
 // FilterTypeName defines the type used in the getblockfilter JSON-RPC command for the
 // filter type field.
 type FilterTypeName string
@@ -261,6 +450,22 @@ func NewGetBlockFilterCmd(blockHash string, filterType *FilterTypeName) *GetBloc
 	}
 }
 
+// GetBlockBenchmarksCmd defines the getblockbenchmarks JSON-RPC command.
+type GetBlockBenchmarksCmd struct {
+	Count *int `jsonrpcdefault:"10"`
+}
+
+// NewGetBlockBenchmarksCmd returns a new instance which can be used to issue a
+// getblockbenchmarks JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetBlockBenchmarksCmd(count *int) *GetBlockBenchmarksCmd {
+	return &GetBlockBenchmarksCmd{
+		Count: count,
+	}
+}
+
 // GetBlockHashCmd defines the getblockhash JSON-RPC command.
 type GetBlockHashCmd struct {
 	Index int64
@@ -274,6 +479,30 @@ func NewGetBlockHashCmd(index int64) *GetBlockHashCmd {
 	}
 }
 
+// GetBlockRangeCmd defines the getblockrange JSON-RPC command.
+type GetBlockRangeCmd struct {
+	StartHeight int32
+	EndHeight   int32
+}
+
+// NewGetBlockRangeCmd returns a new instance which can be used to issue a
+// getblockrange JSON-RPC command.
+func NewGetBlockRangeCmd(startHeight, endHeight int32) *GetBlockRangeCmd {
+	return &GetBlockRangeCmd{
+		StartHeight: startHeight,
+		EndHeight:   endHeight,
+	}
+}
+
+// GetBlockRepairsCmd defines the getblockrepairs JSON-RPC command.
+type GetBlockRepairsCmd struct{}
+
+// NewGetBlockRepairsCmd returns a new instance which can be used to issue a
+// getblockrepairs JSON-RPC command.
+func NewGetBlockRepairsCmd() *GetBlockRepairsCmd {
+	return &GetBlockRepairsCmd{}
+}
+
 // GetBlockHeaderCmd defines the getblockheader JSON-RPC command.
 type GetBlockHeaderCmd struct {
 	Hash    string
@@ -465,6 +694,15 @@ func NewGetChainTipsCmd() *GetChainTipsCmd {
 	return &GetChainTipsCmd{}
 }
 
+// GetCheckpointsCmd defines the getcheckpoints JSON-RPC command.
+type GetCheckpointsCmd struct{}
+
+// NewGetCheckpointsCmd returns a new instance which can be used to issue a
+// getcheckpoints JSON-RPC command.
+func NewGetCheckpointsCmd() *GetCheckpointsCmd {
+	return &GetCheckpointsCmd{}
+}
+
 // GetChainTxStatsCmd defines the getchaintxstats JSON-RPC command.
 type GetChainTxStatsCmd struct {
 	NBlocks   *int32
@@ -483,6 +721,32 @@ func NewGetChainTxStatsCmd(nBlocks *int32, blockHash *string) *GetChainTxStatsCm
 	}
 }
 
+// GetClaimHistoryCmd defines the getclaimhistory JSON-RPC command.
+type GetClaimHistoryCmd struct {
+	ClaimID string
+}
+
+// NewGetClaimHistoryCmd returns a new instance which can be used to issue a
+// getclaimhistory JSON-RPC command.
+func NewGetClaimHistoryCmd(claimID string) *GetClaimHistoryCmd {
+	return &GetClaimHistoryCmd{
+		ClaimID: claimID,
+	}
+}
+
+// GetConflictsCmd defines the getconflicts JSON-RPC command.
+type GetConflictsCmd struct {
+	TxID string
+}
+
+// NewGetConflictsCmd returns a new instance which can be used to issue a
+// getconflicts JSON-RPC command.
+func NewGetConflictsCmd(txHash string) *GetConflictsCmd {
+	return &GetConflictsCmd{
+		TxID: txHash,
+	}
+}
+
 // GetConnectionCountCmd defines the getconnectioncount JSON-RPC command.
 type GetConnectionCountCmd struct{}
 
@@ -492,6 +756,15 @@ func NewGetConnectionCountCmd() *GetConnectionCountCmd {
 	return &GetConnectionCountCmd{}
 }
 
+// GetDBInfoCmd defines the getdbinfo JSON-RPC command.
+type GetDBInfoCmd struct{}
+
+// NewGetDBInfoCmd returns a new instance which can be used to issue a getdbinfo
+// JSON-RPC command.
+func NewGetDBInfoCmd() *GetDBInfoCmd {
+	return &GetDBInfoCmd{}
+}
+
 // GetDescriptorInfoCmd defines the getdescriptorinfo JSON-RPC command.
 type GetDescriptorInfoCmd struct {
 	Descriptor string
@@ -532,6 +805,22 @@ func NewGetHashesPerSecCmd() *GetHashesPerSecCmd {
 	return &GetHashesPerSecCmd{}
 }
 
+// GetIndexInfoCmd defines the getindexinfo JSON-RPC command.
+type GetIndexInfoCmd struct {
+	IndexName *string
+}
+
+// NewGetIndexInfoCmd returns a new instance which can be used to issue a
+// getindexinfo JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetIndexInfoCmd(indexName *string) *GetIndexInfoCmd {
+	return &GetIndexInfoCmd{
+		IndexName: indexName,
+	}
+}
+
 // GetInfoCmd defines the getinfo JSON-RPC command.
 type GetInfoCmd struct{}
 
@@ -572,6 +861,19 @@ func NewGetMiningInfoCmd() *GetMiningInfoCmd {
 	return &GetMiningInfoCmd{}
 }
 
+// GetNameHistoryCmd defines the getnamehistory JSON-RPC command.
+type GetNameHistoryCmd struct {
+	Name string
+}
+
+// NewGetNameHistoryCmd returns a new instance which can be used to issue a
+// getnamehistory JSON-RPC command.
+func NewGetNameHistoryCmd(name string) *GetNameHistoryCmd {
+	return &GetNameHistoryCmd{
+		Name: name,
+	}
+}
+
 // GetNetworkInfoCmd defines the getnetworkinfo JSON-RPC command.
 type GetNetworkInfoCmd struct{}
 
@@ -670,6 +972,29 @@ func NewGetRawTransactionCmd(txHash string, verbose *int) *GetRawTransactionCmd
 	}
 }
 
+// SpentInfoRequest identifies the output to query for the getspentinfo
+// JSON-RPC command.
+type SpentInfoRequest struct {
+	TxID  string `json:"txid"`
+	Index uint32 `json:"index"`
+}
+
+// GetSpentInfoCmd defines the getspentinfo JSON-RPC command.
+type GetSpentInfoCmd struct {
+	Request SpentInfoRequest
+}
+
+// NewGetSpentInfoCmd returns a new instance which can be used to issue a
+// getspentinfo JSON-RPC command.
+func NewGetSpentInfoCmd(txHash string, index uint32) *GetSpentInfoCmd {
+	return &GetSpentInfoCmd{
+		Request: SpentInfoRequest{
+			TxID:  txHash,
+			Index: index,
+		},
+	}
+}
+
 // GetTxOutCmd defines the gettxout JSON-RPC command.
 type GetTxOutCmd struct {
 	Txid           string
@@ -709,12 +1034,19 @@ func NewGetTxOutProofCmd(txIDs []string, blockHash *string) *GetTxOutProofCmd {
 }
 
 // GetTxOutSetInfoCmd defines the gettxoutsetinfo JSON-RPC command.
-type GetTxOutSetInfoCmd struct{}
+type GetTxOutSetInfoCmd struct {
+	Verbose *bool `jsonrpcdefault:"false"`
+}
 
 // NewGetTxOutSetInfoCmd returns a new instance which can be used to issue a
 // gettxoutsetinfo JSON-RPC command.
-func NewGetTxOutSetInfoCmd() *GetTxOutSetInfoCmd {
-	return &GetTxOutSetInfoCmd{}
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetTxOutSetInfoCmd(verbose *bool) *GetTxOutSetInfoCmd {
+	return &GetTxOutSetInfoCmd{
+		Verbose: verbose,
+	}
 }
 
 // GetWorkCmd defines the getwork JSON-RPC command.
@@ -749,6 +1081,19 @@ func NewHelpCmd(command *string) *HelpCmd {
 	}
 }
 
+// ImportBlocksCmd defines the importblocks JSON-RPC command.
+type ImportBlocksCmd struct {
+	Files []string
+}
+
+// NewImportBlocksCmd returns a new instance which can be used to issue an
+// importblocks JSON-RPC command.
+func NewImportBlocksCmd(files []string) *ImportBlocksCmd {
+	return &ImportBlocksCmd{
+		Files: files,
+	}
+}
+
 // InvalidateBlockCmd defines the invalidateblock JSON-RPC command.
 type InvalidateBlockCmd struct {
 	BlockHash string
@@ -762,6 +1107,15 @@ func NewInvalidateBlockCmd(blockHash string) *InvalidateBlockCmd {
 	}
 }
 
+// ListBannedCmd defines the listbanned JSON-RPC command.
+type ListBannedCmd struct{}
+
+// NewListBannedCmd returns a new instance which can be used to issue a
+// listbanned JSON-RPC command.
+func NewListBannedCmd() *ListBannedCmd {
+	return &ListBannedCmd{}
+}
+
 // PingCmd defines the ping JSON-RPC command.
 type PingCmd struct{}
 
@@ -784,6 +1138,32 @@ func NewPreciousBlockCmd(blockHash string) *PreciousBlockCmd {
 	}
 }
 
+// PruneBlockchainCmd defines the pruneblockchain JSON-RPC command.
+type PruneBlockchainCmd struct {
+	Height int64
+}
+
+// NewPruneBlockchainCmd returns a new instance which can be used to issue a
+// pruneblockchain JSON-RPC command.
+func NewPruneBlockchainCmd(height int64) *PruneBlockchainCmd {
+	return &PruneBlockchainCmd{
+		Height: height,
+	}
+}
+
+// RebuildIndexCmd defines the rebuildindex JSON-RPC command.
+type RebuildIndexCmd struct {
+	IndexName string
+}
+
+// NewRebuildIndexCmd returns a new instance which can be used to issue a
+// rebuildindex JSON-RPC command.
+func NewRebuildIndexCmd(indexName string) *RebuildIndexCmd {
+	return &RebuildIndexCmd{
+		IndexName: indexName,
+	}
+}
+
 // ReconsiderBlockCmd defines the reconsiderblock JSON-RPC command.
 type ReconsiderBlockCmd struct {
 	BlockHash string
@@ -909,6 +1289,43 @@ func NewBitcoindSendRawTransactionCmd(hexTx string, maxFeeRate BTCPerkvB) *SendR
 	}
 }
 
+// SetBanSubCmd defines the type used in the setban JSON-RPC command for the
+// sub command field.
+type SetBanSubCmd string
+
+const (
+	// SBAdd indicates the specified subnet should be banned.
+	SBAdd SetBanSubCmd = "add"
+
+	// SBRemove indicates the ban of the specified subnet should be
+	// lifted.
+	SBRemove SetBanSubCmd = "remove"
+)
+
+// SetBanCmd defines the setban JSON-RPC command.
+type SetBanCmd struct {
+	SubNet   string
+	Command  SetBanSubCmd `jsonrpcusage:"\"add|remove\""`
+	BanTime  *int64       `jsonrpcdefault:"0"`
+	Absolute *bool        `jsonrpcdefault:"false"`
+}
+
+// NewSetBanCmd returns a new instance which can be used to issue a setban
+// JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewSetBanCmd(subNet string, command SetBanSubCmd, banTime *int64,
+	absolute *bool) *SetBanCmd {
+
+	return &SetBanCmd{
+		SubNet:   subNet,
+		Command:  command,
+		BanTime:  banTime,
+		Absolute: absolute,
+	}
+}
+
 // SetGenerateCmd defines the setgenerate JSON-RPC command.
 type SetGenerateCmd struct {
 	Generate     bool
@@ -979,6 +1396,21 @@ func NewSubmitBlockCmd(hexBlock string, options *SubmitBlockOptions) *SubmitBloc
 	}
 }
 
+// TraceScriptCmd defines the tracescript JSON-RPC command.
+type TraceScriptCmd struct {
+	HexTx string
+	Vin   uint32
+}
+
+// NewTraceScriptCmd returns a new instance which can be used to issue a
+// tracescript JSON-RPC command.
+func NewTraceScriptCmd(hexTx string, vin uint32) *TraceScriptCmd {
+	return &TraceScriptCmd{
+		HexTx: hexTx,
+		Vin:   vin,
+	}
+}
+
 // UptimeCmd defines the uptime JSON-RPC command.
 type UptimeCmd struct{}
 
@@ -1018,6 +1450,24 @@ func NewVerifyChainCmd(checkLevel, checkDepth *int32) *VerifyChainCmd {
 	}
 }
 
+// VerifyIndexCmd defines the verifyindex JSON-RPC command.
+type VerifyIndexCmd struct {
+	IndexName  string
+	CheckDepth *int32 `jsonrpcdefault:"288"` // 0 = all
+}
+
+// NewVerifyIndexCmd returns a new instance which can be used to issue a
+// verifyindex JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewVerifyIndexCmd(indexName string, checkDepth *int32) *VerifyIndexCmd {
+	return &VerifyIndexCmd{
+		IndexName:  indexName,
+		CheckDepth: checkDepth,
+	}
+}
+
 // VerifyMessageCmd defines the verifymessage JSON-RPC command.
 type VerifyMessageCmd struct {
 	Address   string
@@ -1106,34 +1556,54 @@ func init() {
 	flags := UsageFlag(0)
 
 	MustRegisterCmd("addnode", (*AddNodeCmd)(nil), flags)
+	MustRegisterCmd("clearbanned", (*ClearBannedCmd)(nil), flags)
+	MustRegisterCmd("combinepsbt", (*CombinePsbtCmd)(nil), flags)
+	MustRegisterCmd("compactdb", (*CompactDBCmd)(nil), flags)
+	MustRegisterCmd("converttopsbt", (*ConvertToPsbtCmd)(nil), flags)
 	MustRegisterCmd("createrawtransaction", (*CreateRawTransactionCmd)(nil), flags)
+	MustRegisterCmd("decodepsbt", (*DecodePsbtCmd)(nil), flags)
 	MustRegisterCmd("decoderawtransaction", (*DecodeRawTransactionCmd)(nil), flags)
 	MustRegisterCmd("decodescript", (*DecodeScriptCmd)(nil), flags)
 	MustRegisterCmd("deriveaddresses", (*DeriveAddressesCmd)(nil), flags)
+	MustRegisterCmd("dumpprofiles", (*DumpProfilesCmd)(nil), flags)
+	MustRegisterCmd("finalizepsbt", (*FinalizePsbtCmd)(nil), flags)
 	MustRegisterCmd("fundrawtransaction", (*FundRawTransactionCmd)(nil), flags)
 	MustRegisterCmd("getaddednodeinfo", (*GetAddedNodeInfoCmd)(nil), flags)
+	MustRegisterCmd("getaddressbalance", (*GetAddressBalanceCmd)(nil), flags)
+	MustRegisterCmd("getaddresstxids", (*GetAddressTxIDsCmd)(nil), flags)
+	MustRegisterCmd("getaddressutxos", (*GetAddressUtxosCmd)(nil), flags)
 	MustRegisterCmd("getbestblockhash", (*GetBestBlockHashCmd)(nil), flags)
 	MustRegisterCmd("getblock", (*GetBlockCmd)(nil), flags)
 	MustRegisterCmd("getblockchaininfo", (*GetBlockChainInfoCmd)(nil), flags)
 	MustRegisterCmd("getblockcount", (*GetBlockCountCmd)(nil), flags)
 	MustRegisterCmd("getblockfilter", (*GetBlockFilterCmd)(nil), flags)
+	MustRegisterCmd("getblockbenchmarks", (*GetBlockBenchmarksCmd)(nil), flags)
 	MustRegisterCmd("getblockhash", (*GetBlockHashCmd)(nil), flags)
 	MustRegisterCmd("getblockheader", (*GetBlockHeaderCmd)(nil), flags)
+	MustRegisterCmd("getblockrange", (*GetBlockRangeCmd)(nil), flags)
+	MustRegisterCmd("getblockrepairs", (*GetBlockRepairsCmd)(nil), flags)
 	MustRegisterCmd("getblockstats", (*GetBlockStatsCmd)(nil), flags)
 	MustRegisterCmd("getblocktemplate", (*GetBlockTemplateCmd)(nil), flags)
 	MustRegisterCmd("getcfilter", (*GetCFilterCmd)(nil), flags)
 	MustRegisterCmd("getcfilterheader", (*GetCFilterHeaderCmd)(nil), flags)
 	MustRegisterCmd("getchaintips", (*GetChainTipsCmd)(nil), flags)
 	MustRegisterCmd("getchaintxstats", (*GetChainTxStatsCmd)(nil), flags)
+	MustRegisterCmd("getcheckpoints", (*GetCheckpointsCmd)(nil), flags)
+	MustRegisterCmd("getclaimhistory", (*GetClaimHistoryCmd)(nil), flags)
+	MustRegisterCmd("getconflicts", (*GetConflictsCmd)(nil), flags)
 	MustRegisterCmd("getconnectioncount", (*GetConnectionCountCmd)(nil), flags)
+	MustRegisterCmd("getdbinfo", (*GetDBInfoCmd)(nil), flags)
+	MustRegisterCmd("getdeploymentinfo", (*GetDeploymentInfoCmd)(nil), flags)
 	MustRegisterCmd("getdescriptorinfo", (*GetDescriptorInfoCmd)(nil), flags)
 	MustRegisterCmd("getdifficulty", (*GetDifficultyCmd)(nil), flags)
 	MustRegisterCmd("getgenerate", (*GetGenerateCmd)(nil), flags)
 	MustRegisterCmd("gethashespersec", (*GetHashesPerSecCmd)(nil), flags)
+	MustRegisterCmd("getindexinfo", (*GetIndexInfoCmd)(nil), flags)
 	MustRegisterCmd("getinfo", (*GetInfoCmd)(nil), flags)
 	MustRegisterCmd("getmempoolentry", (*GetMempoolEntryCmd)(nil), flags)
 	MustRegisterCmd("getmempoolinfo", (*GetMempoolInfoCmd)(nil), flags)
 	MustRegisterCmd("getmininginfo", (*GetMiningInfoCmd)(nil), flags)
+	MustRegisterCmd("getnamehistory", (*GetNameHistoryCmd)(nil), flags)
 	MustRegisterCmd("getnetworkinfo", (*GetNetworkInfoCmd)(nil), flags)
 	MustRegisterCmd("getnettotals", (*GetNetTotalsCmd)(nil), flags)
 	MustRegisterCmd("getnetworkhashps", (*GetNetworkHashPSCmd)(nil), flags)
@@ -1141,25 +1611,33 @@ func init() {
 	MustRegisterCmd("getpeerinfo", (*GetPeerInfoCmd)(nil), flags)
 	MustRegisterCmd("getrawmempool", (*GetRawMempoolCmd)(nil), flags)
 	MustRegisterCmd("getrawtransaction", (*GetRawTransactionCmd)(nil), flags)
+	MustRegisterCmd("getspentinfo", (*GetSpentInfoCmd)(nil), flags)
 	MustRegisterCmd("gettxout", (*GetTxOutCmd)(nil), flags)
 	MustRegisterCmd("gettxoutproof", (*GetTxOutProofCmd)(nil), flags)
 	MustRegisterCmd("gettxoutsetinfo", (*GetTxOutSetInfoCmd)(nil), flags)
 	MustRegisterCmd("getwork", (*GetWorkCmd)(nil), flags)
 	MustRegisterCmd("help", (*HelpCmd)(nil), flags)
+	MustRegisterCmd("importblocks", (*ImportBlocksCmd)(nil), flags)
 	MustRegisterCmd("invalidateblock", (*InvalidateBlockCmd)(nil), flags)
+	MustRegisterCmd("listbanned", (*ListBannedCmd)(nil), flags)
 	MustRegisterCmd("ping", (*PingCmd)(nil), flags)
 	MustRegisterCmd("preciousblock", (*PreciousBlockCmd)(nil), flags)
+	MustRegisterCmd("pruneblockchain", (*PruneBlockchainCmd)(nil), flags)
+	MustRegisterCmd("rebuildindex", (*RebuildIndexCmd)(nil), flags)
 	MustRegisterCmd("reconsiderblock", (*ReconsiderBlockCmd)(nil), flags)
 	MustRegisterCmd("searchrawtransactions", (*SearchRawTransactionsCmd)(nil), flags)
 	MustRegisterCmd("sendrawtransaction", (*SendRawTransactionCmd)(nil), flags)
 	MustRegisterCmd("submitpackage", (*JsonSubmitPackageCmd)(nil), flags)
+	MustRegisterCmd("setban", (*SetBanCmd)(nil), flags)
 	MustRegisterCmd("setgenerate", (*SetGenerateCmd)(nil), flags)
 	MustRegisterCmd("signmessagewithprivkey", (*SignMessageWithPrivKeyCmd)(nil), flags)
 	MustRegisterCmd("stop", (*StopCmd)(nil), flags)
 	MustRegisterCmd("submitblock", (*SubmitBlockCmd)(nil), flags)
+	MustRegisterCmd("tracescript", (*TraceScriptCmd)(nil), flags)
 	MustRegisterCmd("uptime", (*UptimeCmd)(nil), flags)
 	MustRegisterCmd("validateaddress", (*ValidateAddressCmd)(nil), flags)
 	MustRegisterCmd("verifychain", (*VerifyChainCmd)(nil), flags)
+	MustRegisterCmd("verifyindex", (*VerifyIndexCmd)(nil), flags)
 	MustRegisterCmd("verifymessage", (*VerifyMessageCmd)(nil), flags)
 	MustRegisterCmd("verifytxoutproof", (*VerifyTxOutProofCmd)(nil), flags)
 	MustRegisterCmd("testmempoolaccept", (*TestMempoolAcceptCmd)(nil), flags)
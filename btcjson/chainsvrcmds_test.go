@@ -43,6 +43,71 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"addnode","params":["127.0.0.1","remove"],"id":1}`,
 			unmarshalled: &btcjson.AddNodeCmd{Addr: "127.0.0.1", SubCmd: btcjson.ANRemove},
 		},
+		{
+			name: "clearbanned",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("clearbanned")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewClearBannedCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"clearbanned","params":[],"id":1}`,
+			unmarshalled: &btcjson.ClearBannedCmd{},
+		},
+		{
+			name: "combinepsbt",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("combinepsbt", `["cHNidP8B","cHNidP8C"]`)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewCombinePsbtCmd([]string{"cHNidP8B", "cHNidP8C"})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"combinepsbt","params":[["cHNidP8B","cHNidP8C"]],"id":1}`,
+			unmarshalled: &btcjson.CombinePsbtCmd{
+				Psbts: []string{"cHNidP8B", "cHNidP8C"},
+			},
+		},
+		{
+			name: "compactdb",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("compactdb")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewCompactDBCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"compactdb","params":[],"id":1}`,
+			unmarshalled: &btcjson.CompactDBCmd{},
+		},
+		{
+			name: "converttopsbt",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("converttopsbt", "deadbeef")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewConvertToPsbtCmd("deadbeef", nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"converttopsbt","params":["deadbeef"],"id":1}`,
+			unmarshalled: &btcjson.ConvertToPsbtCmd{
+				HexTx:         "deadbeef",
+				PermitSigData: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "converttopsbt optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("converttopsbt", "deadbeef", true, false)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewConvertToPsbtCmd("deadbeef",
+					btcjson.Bool(true), btcjson.Bool(false))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"converttopsbt","params":["deadbeef",true,false],"id":1}`,
+			unmarshalled: &btcjson.ConvertToPsbtCmd{
+				HexTx:         "deadbeef",
+				PermitSigData: btcjson.Bool(true),
+				IsWitness:     btcjson.Bool(false),
+			},
+		},
 		{
 			name: "createrawtransaction",
 			newCmd: func() (interface{}, error) {
@@ -97,6 +162,34 @@ func TestChainSvrCmds(t *testing.T) {
 				LockTime: btcjson.Int64(12312333333),
 			},
 		},
+		{
+			name: "finalizepsbt",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("finalizepsbt", "cHNidP8B")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewFinalizePsbtCmd("cHNidP8B", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"finalizepsbt","params":["cHNidP8B"],"id":1}`,
+			unmarshalled: &btcjson.FinalizePsbtCmd{
+				Psbt:    "cHNidP8B",
+				Extract: btcjson.Bool(true),
+			},
+		},
+		{
+			name: "finalizepsbt optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("finalizepsbt", "cHNidP8B", false)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewFinalizePsbtCmd("cHNidP8B", btcjson.Bool(false))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"finalizepsbt","params":["cHNidP8B",false],"id":1}`,
+			unmarshalled: &btcjson.FinalizePsbtCmd{
+				Psbt:    "cHNidP8B",
+				Extract: btcjson.Bool(false),
+			},
+		},
 		{
 			name: "fundrawtransaction - empty opts",
 			newCmd: func() (i interface{}, e error) {
@@ -199,6 +292,17 @@ func TestChainSvrCmds(t *testing.T) {
 				}(),
 			},
 		},
+		{
+			name: "decodepsbt",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("decodepsbt", "cHNidP8B")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewDecodePsbtCmd("cHNidP8B")
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"decodepsbt","params":["cHNidP8B"],"id":1}`,
+			unmarshalled: &btcjson.DecodePsbtCmd{Psbt: "cHNidP8B"},
+		},
 		{
 			name: "decoderawtransaction",
 			newCmd: func() (interface{}, error) {
@@ -266,6 +370,32 @@ func TestChainSvrCmds(t *testing.T) {
 				Range:      &btcjson.DescriptorRange{Value: []int{0, 2}},
 			},
 		},
+		{
+			name: "dumpprofiles",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("dumpprofiles")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewDumpProfilesCmd(nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"dumpprofiles","params":[],"id":1}`,
+			unmarshalled: &btcjson.DumpProfilesCmd{
+				CPUSeconds: btcjson.Int(30),
+			},
+		},
+		{
+			name: "dumpprofiles optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("dumpprofiles", 0)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewDumpProfilesCmd(btcjson.Int(0))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"dumpprofiles","params":[0],"id":1}`,
+			unmarshalled: &btcjson.DumpProfilesCmd{
+				CPUSeconds: btcjson.Int(0),
+			},
+		},
 		{
 			name: "getaddednodeinfo",
 			newCmd: func() (interface{}, error) {
@@ -291,6 +421,65 @@ func TestChainSvrCmds(t *testing.T) {
 				Node: btcjson.String("127.0.0.1"),
 			},
 		},
+		{
+			name: "getaddressbalance",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getaddressbalance",
+					btcjson.AddressIndexRequest{Addresses: []string{"1Address"}})
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetAddressBalanceCmd([]string{"1Address"})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getaddressbalance","params":[{"addresses":["1Address"]}],"id":1}`,
+			unmarshalled: &btcjson.GetAddressBalanceCmd{
+				Request: btcjson.AddressIndexRequest{Addresses: []string{"1Address"}},
+			},
+		},
+		{
+			name: "getaddresstxids",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getaddresstxids",
+					btcjson.AddressIndexRequest{
+						Addresses: []string{"1Address", "3Address"},
+						Start:     btcjson.Int32(100),
+						End:       btcjson.Int32(200),
+					})
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetAddressTxIDsCmd(
+					[]string{"1Address", "3Address"},
+					btcjson.Int32(100), btcjson.Int32(200))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getaddresstxids","params":[{"addresses":["1Address","3Address"],"start":100,"end":200}],"id":1}`,
+			unmarshalled: &btcjson.GetAddressTxIDsCmd{
+				Request: btcjson.AddressIndexRequest{
+					Addresses: []string{"1Address", "3Address"},
+					Start:     btcjson.Int32(100),
+					End:       btcjson.Int32(200),
+				},
+			},
+		},
+		{
+			name: "getaddressutxos",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getaddressutxos",
+					btcjson.AddressIndexRequest{
+						Addresses: []string{"1Address"},
+						ChainInfo: btcjson.Bool(true),
+					})
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetAddressUtxosCmd([]string{"1Address"},
+					btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getaddressutxos","params":[{"addresses":["1Address"],"chainInfo":true}],"id":1}`,
+			unmarshalled: &btcjson.GetAddressUtxosCmd{
+				Request: btcjson.AddressIndexRequest{
+					Addresses: []string{"1Address"},
+					ChainInfo: btcjson.Bool(true),
+				},
+			},
+		},
 		{
 			name: "getbestblockhash",
 			newCmd: func() (interface{}, error) {
@@ -369,6 +558,17 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"getblockchaininfo","params":[],"id":1}`,
 			unmarshalled: &btcjson.GetBlockChainInfoCmd{},
 		},
+		{
+			name: "getdeploymentinfo",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getdeploymentinfo")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetDeploymentInfoCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getdeploymentinfo","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetDeploymentInfoCmd{},
+		},
 		{
 			name: "getblockcount",
 			newCmd: func() (interface{}, error) {
@@ -427,6 +627,57 @@ func TestChainSvrCmds(t *testing.T) {
 				Verbose: btcjson.Bool(true),
 			},
 		},
+		{
+			name: "getblockbenchmarks",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblockbenchmarks")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetBlockBenchmarksCmd(nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblockbenchmarks","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetBlockBenchmarksCmd{
+				Count: btcjson.Int(10),
+			},
+		},
+		{
+			name: "getblockbenchmarks optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblockbenchmarks", 5)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetBlockBenchmarksCmd(btcjson.Int(5))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblockbenchmarks","params":[5],"id":1}`,
+			unmarshalled: &btcjson.GetBlockBenchmarksCmd{
+				Count: btcjson.Int(5),
+			},
+		},
+		{
+			name: "getblockrange",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblockrange", 100, 200)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetBlockRangeCmd(100, 200)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblockrange","params":[100,200],"id":1}`,
+			unmarshalled: &btcjson.GetBlockRangeCmd{
+				StartHeight: 100,
+				EndHeight:   200,
+			},
+		},
+		{
+			name: "getblockrepairs",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblockrepairs")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetBlockRepairsCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getblockrepairs","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetBlockRepairsCmd{},
+		},
 		{
 			name: "getblockstats height",
 			newCmd: func() (interface{}, error) {
@@ -607,6 +858,17 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"getchaintips","params":[],"id":1}`,
 			unmarshalled: &btcjson.GetChainTipsCmd{},
 		},
+		{
+			name: "getcheckpoints",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getcheckpoints")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetCheckpointsCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getcheckpoints","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetCheckpointsCmd{},
+		},
 		{
 			name: "getchaintxstats",
 			newCmd: func() (interface{}, error) {
@@ -645,6 +907,32 @@ func TestChainSvrCmds(t *testing.T) {
 				BlockHash: btcjson.String("0000afaf"),
 			},
 		},
+		{
+			name: "getclaimhistory",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getclaimhistory", "claimid")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetClaimHistoryCmd("claimid")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getclaimhistory","params":["claimid"],"id":1}`,
+			unmarshalled: &btcjson.GetClaimHistoryCmd{
+				ClaimID: "claimid",
+			},
+		},
+		{
+			name: "getconflicts",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getconflicts", "txhash")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetConflictsCmd("txhash")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getconflicts","params":["txhash"],"id":1}`,
+			unmarshalled: &btcjson.GetConflictsCmd{
+				TxID: "txhash",
+			},
+		},
 		{
 			name: "getconnectioncount",
 			newCmd: func() (interface{}, error) {
@@ -656,6 +944,17 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"getconnectioncount","params":[],"id":1}`,
 			unmarshalled: &btcjson.GetConnectionCountCmd{},
 		},
+		{
+			name: "getdbinfo",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getdbinfo")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetDBInfoCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getdbinfo","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetDBInfoCmd{},
+		},
 		{
 			name: "getdifficulty",
 			newCmd: func() (interface{}, error) {
@@ -689,6 +988,33 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"gethashespersec","params":[],"id":1}`,
 			unmarshalled: &btcjson.GetHashesPerSecCmd{},
 		},
+		{
+			name: "getindexinfo",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getindexinfo")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetIndexInfoCmd(nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getindexinfo","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetIndexInfoCmd{
+				IndexName: nil,
+			},
+		},
+		{
+			name: "getindexinfo optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getindexinfo", "transaction index")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetIndexInfoCmd(
+					btcjson.String("transaction index"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getindexinfo","params":["transaction index"],"id":1}`,
+			unmarshalled: &btcjson.GetIndexInfoCmd{
+				IndexName: btcjson.String("transaction index"),
+			},
+		},
 		{
 			name: "getinfo",
 			newCmd: func() (interface{}, error) {
@@ -735,6 +1061,19 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"getmininginfo","params":[],"id":1}`,
 			unmarshalled: &btcjson.GetMiningInfoCmd{},
 		},
+		{
+			name: "getnamehistory",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getnamehistory", "name")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetNameHistoryCmd("name")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getnamehistory","params":["name"],"id":1}`,
+			unmarshalled: &btcjson.GetNameHistoryCmd{
+				Name: "name",
+			},
+		},
 		{
 			name: "getnetworkinfo",
 			newCmd: func() (interface{}, error) {
@@ -890,6 +1229,23 @@ func TestChainSvrCmds(t *testing.T) {
 				Verbose: btcjson.Int(1),
 			},
 		},
+		{
+			name: "getspentinfo",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getspentinfo",
+					`{"txid":"123","index":1}`)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetSpentInfoCmd("123", 1)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getspentinfo","params":[{"txid":"123","index":1}],"id":1}`,
+			unmarshalled: &btcjson.GetSpentInfoCmd{
+				Request: btcjson.SpentInfoRequest{
+					TxID:  "123",
+					Index: 1,
+				},
+			},
+		},
 		{
 			name: "gettxout",
 			newCmd: func() (interface{}, error) {
@@ -956,10 +1312,25 @@ func TestChainSvrCmds(t *testing.T) {
 				return btcjson.NewCmd("gettxoutsetinfo")
 			},
 			staticCmd: func() interface{} {
-				return btcjson.NewGetTxOutSetInfoCmd()
+				return btcjson.NewGetTxOutSetInfoCmd(nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"gettxoutsetinfo","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetTxOutSetInfoCmd{
+				Verbose: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "gettxoutsetinfo verbose",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("gettxoutsetinfo", true)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetTxOutSetInfoCmd(btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"gettxoutsetinfo","params":[true],"id":1}`,
+			unmarshalled: &btcjson.GetTxOutSetInfoCmd{
+				Verbose: btcjson.Bool(true),
 			},
-			marshalled:   `{"jsonrpc":"1.0","method":"gettxoutsetinfo","params":[],"id":1}`,
-			unmarshalled: &btcjson.GetTxOutSetInfoCmd{},
 		},
 		{
 			name: "getwork",
@@ -1013,6 +1384,19 @@ func TestChainSvrCmds(t *testing.T) {
 				Command: btcjson.String("getblock"),
 			},
 		},
+		{
+			name: "importblocks",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("importblocks", []string{"blk00000.dat", "blk00001.dat"})
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewImportBlocksCmd([]string{"blk00000.dat", "blk00001.dat"})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"importblocks","params":[["blk00000.dat","blk00001.dat"]],"id":1}`,
+			unmarshalled: &btcjson.ImportBlocksCmd{
+				Files: []string{"blk00000.dat", "blk00001.dat"},
+			},
+		},
 		{
 			name: "invalidateblock",
 			newCmd: func() (interface{}, error) {
@@ -1026,6 +1410,17 @@ func TestChainSvrCmds(t *testing.T) {
 				BlockHash: "123",
 			},
 		},
+		{
+			name: "listbanned",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("listbanned")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewListBannedCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"listbanned","params":[],"id":1}`,
+			unmarshalled: &btcjson.ListBannedCmd{},
+		},
 		{
 			name: "ping",
 			newCmd: func() (interface{}, error) {
@@ -1050,6 +1445,32 @@ func TestChainSvrCmds(t *testing.T) {
 				BlockHash: "0123",
 			},
 		},
+		{
+			name: "pruneblockchain",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("pruneblockchain", 1000)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewPruneBlockchainCmd(1000)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"pruneblockchain","params":[1000],"id":1}`,
+			unmarshalled: &btcjson.PruneBlockchainCmd{
+				Height: 1000,
+			},
+		},
+		{
+			name: "rebuildindex",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("rebuildindex", "transaction index")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewRebuildIndexCmd("transaction index")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"rebuildindex","params":["transaction index"],"id":1}`,
+			unmarshalled: &btcjson.RebuildIndexCmd{
+				IndexName: "transaction index",
+			},
+		},
 		{
 			name: "reconsiderblock",
 			newCmd: func() (interface{}, error) {
@@ -1270,6 +1691,41 @@ func TestChainSvrCmds(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "setban",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("setban", "192.168.0.0/24", btcjson.SBAdd)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSetBanCmd("192.168.0.0/24", btcjson.SBAdd,
+					nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setban","params":["192.168.0.0/24","add"],"id":1}`,
+			unmarshalled: &btcjson.SetBanCmd{
+				SubNet:   "192.168.0.0/24",
+				Command:  btcjson.SBAdd,
+				BanTime:  btcjson.Int64(0),
+				Absolute: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "setban optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("setban", "10.0.0.1", btcjson.SBAdd,
+					1700000000, true)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSetBanCmd("10.0.0.1", btcjson.SBAdd,
+					btcjson.Int64(1700000000), btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setban","params":["10.0.0.1","add",1700000000,true],"id":1}`,
+			unmarshalled: &btcjson.SetBanCmd{
+				SubNet:   "10.0.0.1",
+				Command:  btcjson.SBAdd,
+				BanTime:  btcjson.Int64(1700000000),
+				Absolute: btcjson.Bool(true),
+			},
+		},
 		{
 			name: "setgenerate",
 			newCmd: func() (interface{}, error) {
@@ -1356,6 +1812,20 @@ func TestChainSvrCmds(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "tracescript",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("tracescript", "001122", 1)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewTraceScriptCmd("001122", 1)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"tracescript","params":["001122",1],"id":1}`,
+			unmarshalled: &btcjson.TraceScriptCmd{
+				HexTx: "001122",
+				Vin:   1,
+			},
+		},
 		{
 			name: "uptime",
 			newCmd: func() (interface{}, error) {
@@ -1422,6 +1892,35 @@ func TestChainSvrCmds(t *testing.T) {
 				CheckDepth: btcjson.Int32(500),
 			},
 		},
+		{
+			name: "verifyindex",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("verifyindex", "spent output index")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewVerifyIndexCmd("spent output index", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"verifyindex","params":["spent output index"],"id":1}`,
+			unmarshalled: &btcjson.VerifyIndexCmd{
+				IndexName:  "spent output index",
+				CheckDepth: btcjson.Int32(288),
+			},
+		},
+		{
+			name: "verifyindex optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("verifyindex", "spent output index", 0)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewVerifyIndexCmd("spent output index",
+					btcjson.Int32(0))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"verifyindex","params":["spent output index",0],"id":1}`,
+			unmarshalled: &btcjson.VerifyIndexCmd{
+				IndexName:  "spent output index",
+				CheckDepth: btcjson.Int32(0),
+			},
+		},
 		{
 			name: "verifymessage",
 			newCmd: func() (interface{}, error) {
@@ -1623,6 +2122,12 @@ func TestChainSvrCmdErrors(t *testing.T) {
 			marshalled: `{"sizelimit":"invalid"}`,
 			err:        btcjson.Error{ErrorCode: btcjson.ErrInvalidType},
 		},
+		{
+			name:       "address index request with invalid addresses",
+			result:     &btcjson.AddressIndexRequest{},
+			marshalled: `{"addresses":"1Address"}`,
+			err:        &json.UnmarshalTypeError{},
+		},
 	}
 
 	t.Logf("Running %d tests", len(tests))
@@ -1645,3 +2150,23 @@ func TestChainSvrCmdErrors(t *testing.T) {
 		}
 	}
 }
+
+// TestAddressIndexRequestString ensures a single address passed as a string is
+// accepted in place of an address index request.
+func TestAddressIndexRequestString(t *testing.T) {
+	t.Parallel()
+
+	var request btcjson.Request
+	marshalled := `{"jsonrpc":"1.0","method":"getaddressbalance","params":["1Address"],"id":1}`
+	if err := json.Unmarshal([]byte(marshalled), &request); err != nil {
+		t.Fatalf("unexpected error unmarshalling request: %v", err)
+	}
+	cmd, err := btcjson.UnmarshalCmd(&request)
+	if err != nil {
+		t.Fatalf("UnmarshalCmd: unexpected error: %v", err)
+	}
+	want := btcjson.NewGetAddressBalanceCmd([]string{"1Address"})
+	if !reflect.DeepEqual(cmd, want) {
+		t.Fatalf("unexpected command - got %+v, want %+v", cmd, want)
+	}
+}
@@ -30,10 +30,59 @@ type GetBlockHeaderVerboseResult struct {
 	Nonce         uint64  `json:"nonce"`
 	Bits          string  `json:"bits"`
 	Difficulty    float64 `json:"difficulty"`
+	MedianTime    int64   `json:"mediantime"`
+	ChainWork     string  `json:"chainwork"`
+	Work          string  `json:"work"`
 	PreviousHash  string  `json:"previousblockhash,omitempty"`
 	NextHash      string  `json:"nextblockhash,omitempty"`
 }
 
+// GetAddressBalanceResult models the data from the getaddressbalance command.
+// The amounts are in satoshi.
+type GetAddressBalanceResult struct {
+	Balance  int64 `json:"balance"`
+	Received int64 `json:"received"`
+}
+
+// AddressUtxoResult models an unspent output returned by the getaddressutxos
+// command.
+type AddressUtxoResult struct {
+	Address     string `json:"address"`
+	TxID        string `json:"txid"`
+	OutputIndex uint32 `json:"outputIndex"`
+	Script      string `json:"script"`
+	Satoshis    int64  `json:"satoshis"`
+	Height      int32  `json:"height"`
+}
+
+// GetAddressUtxosChainInfoResult models the data from the getaddressutxos
+// command when the chainInfo field of the request is set.
+type GetAddressUtxosChainInfoResult struct {
+	Utxos  []AddressUtxoResult `json:"utxos"`
+	Hash   string              `json:"hash"`
+	Height int32               `json:"height"`
+}
+
+// GetSpentInfoResult models the data from the getspentinfo command.  Height is
+// -1 when the output is spent by a transaction in the memory pool.
+type GetSpentInfoResult struct {
+	TxID   string `json:"txid"`
+	Index  uint32 `json:"index"`
+	Height int32  `json:"height"`
+}
+
+// ClaimHistoryResult models an operation on a claim returned by the
+// getclaimhistory and getnamehistory commands.  Type is one of claim, support
+// or update.
+type ClaimHistoryResult struct {
+	ClaimID string `json:"claimid"`
+	Name    string `json:"name"`
+	TxID    string `json:"txid"`
+	Vout    uint32 `json:"vout"`
+	Height  int32  `json:"height"`
+	Type    string `json:"type"`
+}
+
 // GetBlockStatsResult models the data from the getblockstats command.
 type GetBlockStatsResult struct {
 	AverageFee         int64   `json:"avgfee"`
@@ -126,6 +175,78 @@ type GetChainTipsResult struct {
 	Status    string `json:"status"`
 }
 
+// GetBlockBenchmarksResult models a block returned by the getblockbenchmarks
+// command along with the time in milliseconds spent in each stage of processing
+// it.
+type GetBlockBenchmarksResult struct {
+	Hash       string  `json:"hash"`
+	Height     int32   `json:"height"`
+	Txns       int     `json:"txns"`
+	Inputs     int     `json:"inputs"`
+	Check      float64 `json:"check"`
+	Store      float64 `json:"store"`
+	LoadInputs float64 `json:"loadinputs"`
+	Scripts    float64 `json:"scripts"`
+	UtxoUpdate float64 `json:"utxoupdate"`
+	Write      float64 `json:"write"`
+	Index      float64 `json:"index"`
+	Flush      float64 `json:"flush"`
+	Total      float64 `json:"total"`
+}
+
+// SpentOutputResult models an output spent by a block returned by the
+// getblockrange command.
+type SpentOutputResult struct {
+	Value        float64 `json:"value"`
+	ScriptPubKey string  `json:"scriptpubkey"`
+	Height       int32   `json:"height"`
+	Coinbase     bool    `json:"coinbase"`
+}
+
+// GetBlockRangeResult models a block returned by the getblockrange command
+// along with the outputs spent by its transactions.
+type GetBlockRangeResult struct {
+	Hash         string              `json:"hash"`
+	Height       int32               `json:"height"`
+	Hex          string              `json:"hex"`
+	SpentOutputs []SpentOutputResult `json:"spentoutputs"`
+}
+
+// BlockRepairResult models a block whose stored copy was found to be damaged
+// returned by the getblockrepairs command.  The times are unix timestamps and
+// Repaired is omitted while the block isn't repaired yet.
+type BlockRepairResult struct {
+	Hash     string `json:"hash"`
+	Height   int32  `json:"height"`
+	Detected int64  `json:"detected"`
+	Repaired int64  `json:"repaired,omitempty"`
+	Status   string `json:"status"`
+}
+
+// DBBucketResult models the approximate disk space in bytes used by a top-level
+// bucket of the block database returned by the getdbinfo command.
+type DBBucketResult struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// GetDBInfoResult models the data from the getdbinfo command.  The sizes are in
+// bytes.
+type GetDBInfoResult struct {
+	BlockFiles     int              `json:"blockfiles"`
+	BlockFilesSize int64            `json:"blockfilessize"`
+	MetadataSize   int64            `json:"metadatasize"`
+	Buckets        []DBBucketResult `json:"buckets"`
+	Compacting     bool             `json:"compacting"`
+}
+
+// GetCheckpointsResult models the data from the getcheckpoints command.
+type GetCheckpointsResult struct {
+	Height      int32  `json:"height"`
+	Hash        string `json:"hash"`
+	InMainChain bool   `json:"inmainchain"`
+}
+
 // GetChainTxStatsResult models the data from the getchaintxstats command.
 type GetChainTxStatsResult struct {
 	Time                   int64   `json:"time"`
@@ -189,6 +310,24 @@ type Bip9SoftForkDescription struct {
 	Timeout             int64  `json:"timeout"`
 	Since               int32  `json:"since"`
 	MinActivationHeight int32  `json:"min_activation_height"`
+
+	// Statistics is only set by the getdeploymentinfo command while the
+	// soft-fork is being voted on.
+	Statistics *Bip9Statistics `json:"statistics,omitempty"`
+}
+
+// Bip9Statistics describes the signalling for a BIP0009 version bits soft-fork
+// in the confirmation window which contains the next block.
+type Bip9Statistics struct {
+	Period    uint32 `json:"period"`
+	Threshold uint32 `json:"threshold"`
+	Elapsed   uint32 `json:"elapsed"`
+	Count     uint32 `json:"count"`
+	Possible  bool   `json:"possible"`
+
+	// Percentage is a btcd extension which is the share of the elapsed
+	// blocks of the window which signal for the soft-fork in percent.
+	Percentage float64 `json:"percentage"`
 }
 
 // StartTime returns the starting time of the softfork as a Unix epoch.
@@ -223,6 +362,30 @@ type UnifiedSoftForks struct {
 	SoftForks map[string]*UnifiedSoftFork `json:"softforks"`
 }
 
+// DeploymentInfo describes the state of a soft-fork as returned by the
+// getdeploymentinfo command.  The type is either "buried" for the soft-forks
+// whose activation height is fixed, which is set as the height, or "bip9" for
+// the BIP0009 version bits soft-forks, whose state is described by Bip9.
+type DeploymentInfo struct {
+	Type   string                   `json:"type"`
+	Active bool                     `json:"active"`
+	Height *int32                   `json:"height,omitempty"`
+	Bip9   *Bip9SoftForkDescription `json:"bip9,omitempty"`
+}
+
+// GetDeploymentInfoResult models the data returned from the getdeploymentinfo
+// command.
+//
+// NOTE: SignalBits is a btcd extension which lists the version bits signalled
+// in generated blocks in addition to the bits of the deployments which are
+// being voted on.
+type GetDeploymentInfoResult struct {
+	Hash        string                     `json:"hash"`
+	Height      int32                      `json:"height"`
+	Deployments map[string]*DeploymentInfo `json:"deployments"`
+	SignalBits  []uint32                   `json:"signalbits,omitempty"`
+}
+
 // GetBlockChainInfoResult models the data returned from the getblockchaininfo
 // command.
 type GetBlockChainInfoResult struct {
@@ -237,6 +400,7 @@ type GetBlockChainInfoResult struct {
 	Pruned               bool          `json:"pruned"`
 	PruneHeight          int32         `json:"pruneheight,omitempty"`
 	ChainWork            string        `json:"chainwork,omitempty"`
+	BestBlockWork        string        `json:"bestblockwork,omitempty"`
 	SizeOnDisk           int64         `json:"size_on_disk,omitempty"`
 	Warnings             StringOrArray `json:"warnings"`
 	*SoftForks
@@ -308,6 +472,18 @@ type GetBlockTemplateResult struct {
 	// Block proposal from BIP 0023.
 	Capabilities []string `json:"capabilities,omitempty"`
 	RejectReason string   `json:"reject-reason,omitempty"`
+
+	// Claim transaction counts, a btcd extension.
+	ClaimTxs *GetBlockTemplateResultClaims `json:"claimtxs,omitempty"`
+}
+
+// GetBlockTemplateResultClaims models the claimtxs field of the
+// getblocktemplate command which holds the number of transactions of the
+// template making each kind of claim operation.
+type GetBlockTemplateResultClaims struct {
+	Claims   int64 `json:"claims"`
+	Supports int64 `json:"supports"`
+	Updates  int64 `json:"updates"`
 }
 
 // GetMempoolEntryResult models the data returned from the getmempoolentry's
@@ -320,6 +496,15 @@ type MempoolFees struct {
 	Descendant float64 `json:"descendant"`
 }
 
+// GetConflictsResult models a single double spending transaction returned
+// from the getconflicts command.
+type GetConflictsResult struct {
+	TxID      string   `json:"txid"`
+	Hex       string   `json:"hex"`
+	Time      int64    `json:"time"`
+	Outpoints []string `json:"outpoints"`
+}
+
 // GetMempoolEntryResult models the data returned from the getmempoolentry
 // command.
 type GetMempoolEntryResult struct {
@@ -344,8 +529,14 @@ type GetMempoolEntryResult struct {
 // GetMempoolInfoResult models the data returned from the getmempoolinfo
 // command.
 type GetMempoolInfoResult struct {
-	Size  int64 `json:"size"`
-	Bytes int64 `json:"bytes"`
+	Size               int64  `json:"size"`
+	Bytes              int64  `json:"bytes"`
+	Orphans            int64  `json:"orphans"`
+	OrphanWeight       int64  `json:"orphanweight"`
+	OrphanPeers        int64  `json:"orphanpeers"`
+	OrphansExpired     uint64 `json:"orphansexpired"`
+	OrphansEvictedPeer uint64 `json:"orphansevictedpeer"`
+	OrphansEvictedPool uint64 `json:"orphansevictedpool"`
 }
 
 // NetworksResult models the networks data from the getnetworkinfo command.
@@ -445,28 +636,55 @@ type GetNodeAddressesResult struct {
 
 // GetPeerInfoResult models the data returned from the getpeerinfo command.
 type GetPeerInfoResult struct {
-	ID             int32   `json:"id"`
-	Addr           string  `json:"addr"`
-	AddrLocal      string  `json:"addrlocal,omitempty"`
-	Services       string  `json:"services"`
-	RelayTxes      bool    `json:"relaytxes"`
-	LastSend       int64   `json:"lastsend"`
-	LastRecv       int64   `json:"lastrecv"`
-	BytesSent      uint64  `json:"bytessent"`
-	BytesRecv      uint64  `json:"bytesrecv"`
-	ConnTime       int64   `json:"conntime"`
-	TimeOffset     int64   `json:"timeoffset"`
-	PingTime       float64 `json:"pingtime"`
-	PingWait       float64 `json:"pingwait,omitempty"`
-	Version        uint32  `json:"version"`
-	SubVer         string  `json:"subver"`
-	Inbound        bool    `json:"inbound"`
-	StartingHeight int32   `json:"startingheight"`
-	CurrentHeight  int32   `json:"currentheight,omitempty"`
-	BanScore       int32   `json:"banscore"`
-	FeeFilter      int64   `json:"feefilter"`
-	SyncNode       bool    `json:"syncnode"`
-	V2Connection   bool    `json:"v2_connection"`
+	ID             int32             `json:"id"`
+	Addr           string            `json:"addr"`
+	AddrLocal      string            `json:"addrlocal,omitempty"`
+	Services       string            `json:"services"`
+	RelayTxes      bool              `json:"relaytxes"`
+	LastSend       int64             `json:"lastsend"`
+	LastRecv       int64             `json:"lastrecv"`
+	BytesSent      uint64            `json:"bytessent"`
+	BytesRecv      uint64            `json:"bytesrecv"`
+	ConnTime       int64             `json:"conntime"`
+	TimeOffset     int64             `json:"timeoffset"`
+	PingTime       float64           `json:"pingtime"`
+	PingWait       float64           `json:"pingwait,omitempty"`
+	Version        uint32            `json:"version"`
+	SubVer         string            `json:"subver"`
+	Inbound        bool              `json:"inbound"`
+	StartingHeight int32             `json:"startingheight"`
+	CurrentHeight  int32             `json:"currentheight,omitempty"`
+	BanScore       int32             `json:"banscore"`
+	Misbehavior    []PeerMisbehavior `json:"misbehavior"`
+	FeeFilter      int64             `json:"feefilter"`
+	SyncNode       bool              `json:"syncnode"`
+	V2Connection   bool              `json:"v2_connection"`
+	TxInvRecv      uint64            `json:"txinvrecv"`
+	TxInvThrottled uint64            `json:"txinvthrottled"`
+	TxRecv         uint64            `json:"txrecv"`
+	UnknownMsgs    uint64            `json:"unknownmsgs"`
+	OversizedMsgs  uint64            `json:"oversizedmsgs"`
+	MalformedMsgs  uint64            `json:"malformedmsgs"`
+	Permissions    []string          `json:"permissions"`
+	ConnectionType string            `json:"connection_type"`
+}
+
+// PeerMisbehavior models an instance of misbehavior of a peer returned as part
+// of the getpeerinfo command.
+type PeerMisbehavior struct {
+	Time     int64  `json:"time"`
+	Category string `json:"category"`
+	Reason   string `json:"reason"`
+	BanScore int32  `json:"banscore"`
+}
+
+// ListBannedResult models the data returned from the listbanned command.
+type ListBannedResult struct {
+	Address       string `json:"address"`
+	BanCreated    int64  `json:"ban_created"`
+	BannedUntil   int64  `json:"banned_until"`
+	BanDuration   int64  `json:"ban_duration"`
+	TimeRemaining int64  `json:"time_remaining"`
 }
 
 // GetRawMempoolVerboseResult models the data returned from the getrawmempool
@@ -482,6 +700,7 @@ type GetRawMempoolVerboseResult struct {
 	StartingPriority float64  `json:"startingpriority"`
 	CurrentPriority  float64  `json:"currentpriority"`
 	Depends          []string `json:"depends"`
+	Annotations      []string `json:"annotations,omitempty"`
 }
 
 // ScriptPubKeyResult models the scriptPubKey data of a tx script.  It is
@@ -504,16 +723,54 @@ type GetTxOutResult struct {
 	Coinbase      bool               `json:"coinbase"`
 }
 
+// TxOutSetScriptClass models the number and the total value of the unspent
+// outputs of a script class returned as part of the verbose gettxoutsetinfo
+// command.
+type TxOutSetScriptClass struct {
+	TxOuts      int64   `json:"txouts"`
+	TotalAmount float64 `json:"total_amount"`
+}
+
 // GetTxOutSetInfoResult models the data from the gettxoutsetinfo command.
 type GetTxOutSetInfoResult struct {
-	Height         int64          `json:"height"`
-	BestBlock      chainhash.Hash `json:"bestblock"`
-	Transactions   int64          `json:"transactions"`
-	TxOuts         int64          `json:"txouts"`
-	BogoSize       int64          `json:"bogosize"`
-	HashSerialized chainhash.Hash `json:"hash_serialized_2"`
-	DiskSize       int64          `json:"disk_size"`
-	TotalAmount    btcutil.Amount `json:"total_amount"`
+	Height         int64                          `json:"height"`
+	BestBlock      chainhash.Hash                 `json:"bestblock"`
+	Transactions   int64                          `json:"transactions"`
+	TxOuts         int64                          `json:"txouts"`
+	BogoSize       int64                          `json:"bogosize"`
+	HashSerialized chainhash.Hash                 `json:"hash_serialized_2"`
+	DiskSize       int64                          `json:"disk_size"`
+	TotalAmount    btcutil.Amount                 `json:"total_amount"`
+	ScriptClasses  map[string]TxOutSetScriptClass `json:"script_classes,omitempty"`
+	ClaimScripts   map[string]TxOutSetScriptClass `json:"claim_scripts,omitempty"`
+}
+
+// MarshalJSON marshals the result of the gettxoutsetinfo JSON-RPC call with the
+// total amount in BTC, which is the form UnmarshalJSON expects.
+func (g GetTxOutSetInfoResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Height         int64                          `json:"height"`
+		BestBlock      chainhash.Hash                 `json:"bestblock"`
+		Transactions   int64                          `json:"transactions"`
+		TxOuts         int64                          `json:"txouts"`
+		BogoSize       int64                          `json:"bogosize"`
+		HashSerialized chainhash.Hash                 `json:"hash_serialized_2"`
+		DiskSize       int64                          `json:"disk_size"`
+		TotalAmount    float64                        `json:"total_amount"`
+		ScriptClasses  map[string]TxOutSetScriptClass `json:"script_classes,omitempty"`
+		ClaimScripts   map[string]TxOutSetScriptClass `json:"claim_scripts,omitempty"`
+	}{
+		Height:         g.Height,
+		BestBlock:      g.BestBlock,
+		Transactions:   g.Transactions,
+		TxOuts:         g.TxOuts,
+		BogoSize:       g.BogoSize,
+		HashSerialized: g.HashSerialized,
+		DiskSize:       g.DiskSize,
+		TotalAmount:    g.TotalAmount.ToBTC(),
+		ScriptClasses:  g.ScriptClasses,
+		ClaimScripts:   g.ClaimScripts,
+	})
 }
 
 // UnmarshalJSON unmarshals the result of the gettxoutsetinfo JSON-RPC call
@@ -562,11 +819,25 @@ func (g *GetTxOutSetInfoResult) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// GetNetTotalsUploadTarget models the state of the upload target returned as
+// part of the getnettotals command.
+type GetNetTotalsUploadTarget struct {
+	TimeFrame             int64  `json:"timeframe"`
+	Target                uint64 `json:"target"`
+	TargetReached         bool   `json:"target_reached"`
+	ServeHistoricalBlocks bool   `json:"serve_historical_blocks"`
+	BytesLeftInCycle      uint64 `json:"bytes_left_in_cycle"`
+	TimeLeftInCycle       int64  `json:"time_left_in_cycle"`
+}
+
 // GetNetTotalsResult models the data returned from the getnettotals command.
 type GetNetTotalsResult struct {
-	TotalBytesRecv uint64 `json:"totalbytesrecv"`
-	TotalBytesSent uint64 `json:"totalbytessent"`
-	TimeMillis     int64  `json:"timemillis"`
+	TotalBytesRecv  uint64                   `json:"totalbytesrecv"`
+	TotalBytesSent  uint64                   `json:"totalbytessent"`
+	TimeMillis      int64                    `json:"timemillis"`
+	BytesRecvPerMsg map[string]uint64        `json:"bytesrecv_per_msg"`
+	BytesSentPerMsg map[string]uint64        `json:"bytessent_per_msg"`
+	UploadTarget    GetNetTotalsUploadTarget `json:"uploadtarget"`
 }
 
 // ScriptSig models a signature script.  It is defined separately since it only
@@ -754,6 +1025,41 @@ type GetWorkResult struct {
 	Target   string `json:"target"`
 }
 
+// GetIndexInfoResult models the state of an index returned by the getindexinfo
+// command.
+type GetIndexInfoResult struct {
+	Synced          bool  `json:"synced"`
+	BestBlockHeight int32 `json:"best_block_height"`
+}
+
+// VerifyIndexResult models the data returned by the verifyindex command.
+type VerifyIndexResult struct {
+	Name     string `json:"name"`
+	Height   int32  `json:"height"`
+	Checked  int32  `json:"checked"`
+	Valid    bool   `json:"valid"`
+	Mismatch string `json:"mismatch,omitempty"`
+}
+
+// TraceScriptStep models the state of the script engine before an opcode is
+// executed, or after the last one, returned as part of the tracescript command.
+type TraceScriptStep struct {
+	Script   int      `json:"script"`
+	Opcode   int      `json:"opcode"`
+	Next     string   `json:"next,omitempty"`
+	Stack    []string `json:"stack"`
+	AltStack []string `json:"altstack,omitempty"`
+}
+
+// TraceScriptResult models the data returned by the tracescript command.
+type TraceScriptResult struct {
+	Vin     uint32            `json:"vin"`
+	Scripts []string          `json:"scripts"`
+	Steps   []TraceScriptStep `json:"steps"`
+	Valid   bool              `json:"valid"`
+	Error   string            `json:"error,omitempty"`
+}
+
 // InfoChainResult models the data returned by the chain server getinfo command.
 type InfoChainResult struct {
 	Version         int32   `json:"version"`
@@ -808,12 +1114,148 @@ type SearchRawTransactionsResult struct {
 // TxRawDecodeResult models the data from the decoderawtransaction command.
 type TxRawDecodeResult struct {
 	Txid     string `json:"txid"`
+	Hash     string `json:"hash"`
+	Size     int32  `json:"size"`
+	Vsize    int32  `json:"vsize"`
+	Weight   int32  `json:"weight"`
 	Version  int32  `json:"version"`
 	Locktime uint32 `json:"locktime"`
 	Vin      []Vin  `json:"vin"`
 	Vout     []Vout `json:"vout"`
 }
 
+// PsbtScript models a redeem or witness script of a PSBT input or output.
+type PsbtScript struct {
+	Asm  string `json:"asm"`
+	Hex  string `json:"hex"`
+	Type string `json:"type"`
+}
+
+// PsbtWitnessUtxo models the output spent by a PSBT input.
+type PsbtWitnessUtxo struct {
+	Amount       float64            `json:"amount"`
+	ScriptPubKey ScriptPubKeyResult `json:"scriptPubKey"`
+}
+
+// PsbtBip32Deriv models the BIP32 derivation path of a public key of a PSBT.
+type PsbtBip32Deriv struct {
+	PubKey            string `json:"pubkey"`
+	MasterFingerprint string `json:"master_fingerprint"`
+	Path              string `json:"path"`
+}
+
+// PsbtTaprootBip32Deriv models the BIP32 derivation path of a taproot public
+// key of a PSBT along with the leaves the key is used in.
+type PsbtTaprootBip32Deriv struct {
+	PubKey            string   `json:"pubkey"`
+	MasterFingerprint string   `json:"master_fingerprint"`
+	Path              string   `json:"path"`
+	LeafHashes        []string `json:"leaf_hashes"`
+}
+
+// PsbtTaprootScriptPathSig models a signature of a PSBT input for a taproot
+// script path spend.
+type PsbtTaprootScriptPathSig struct {
+	PubKey   string `json:"pubkey"`
+	LeafHash string `json:"leaf_hash"`
+	Sig      string `json:"sig"`
+}
+
+// PsbtTaprootScript models a taproot leaf script of a PSBT input along with
+// its control blocks.
+type PsbtTaprootScript struct {
+	Script        string   `json:"script"`
+	LeafVer       int      `json:"leaf_ver"`
+	ControlBlocks []string `json:"control_blocks"`
+}
+
+// PsbtTaprootTreeLeaf models a leaf of the taproot tree of a PSBT output.
+type PsbtTaprootTreeLeaf struct {
+	Depth   int    `json:"depth"`
+	LeafVer int    `json:"leaf_ver"`
+	Script  string `json:"script"`
+}
+
+// PsbtProprietary models a proprietary key-value pair of a PSBT.  The
+// identifier, key and value are hex encoded.
+type PsbtProprietary struct {
+	Identifier string `json:"identifier"`
+	Subtype    uint64 `json:"subtype"`
+	Key        string `json:"key"`
+	Value      string `json:"value"`
+}
+
+// PsbtClaim models the claim, support or update a PSBT output makes as
+// described by its proprietary claim key-value pairs.  The claim ID and value
+// are hex encoded.
+type PsbtClaim struct {
+	Name    string `json:"name"`
+	ClaimID string `json:"claimid,omitempty"`
+	Value   string `json:"value,omitempty"`
+}
+
+// DecodePsbtInput models an input of the data returned from the decodepsbt
+// command.
+type DecodePsbtInput struct {
+	NonWitnessUtxo        *TxRawDecodeResult         `json:"non_witness_utxo,omitempty"`
+	WitnessUtxo           *PsbtWitnessUtxo           `json:"witness_utxo,omitempty"`
+	PartialSignatures     map[string]string          `json:"partial_signatures,omitempty"`
+	Sighash               string                     `json:"sighash,omitempty"`
+	RedeemScript          *PsbtScript                `json:"redeem_script,omitempty"`
+	WitnessScript         *PsbtScript                `json:"witness_script,omitempty"`
+	Bip32Derivs           []PsbtBip32Deriv           `json:"bip32_derivs,omitempty"`
+	FinalScriptSig        *ScriptSig                 `json:"final_scriptSig,omitempty"`
+	FinalScriptWitness    []string                   `json:"final_scriptwitness,omitempty"`
+	TaprootKeyPathSig     string                     `json:"taproot_key_path_sig,omitempty"`
+	TaprootScriptPathSigs []PsbtTaprootScriptPathSig `json:"taproot_script_path_sigs,omitempty"`
+	TaprootScripts        []PsbtTaprootScript        `json:"taproot_scripts,omitempty"`
+	TaprootBip32Derivs    []PsbtTaprootBip32Deriv    `json:"taproot_bip32_derivs,omitempty"`
+	TaprootInternalKey    string                     `json:"taproot_internal_key,omitempty"`
+	TaprootMerkleRoot     string                     `json:"taproot_merkle_root,omitempty"`
+	Proprietary           []PsbtProprietary          `json:"proprietary,omitempty"`
+	Unknown               map[string]string          `json:"unknown,omitempty"`
+}
+
+// DecodePsbtOutput models an output of the data returned from the decodepsbt
+// command.
+type DecodePsbtOutput struct {
+	RedeemScript       *PsbtScript             `json:"redeem_script,omitempty"`
+	WitnessScript      *PsbtScript             `json:"witness_script,omitempty"`
+	Bip32Derivs        []PsbtBip32Deriv        `json:"bip32_derivs,omitempty"`
+	TaprootInternalKey string                  `json:"taproot_internal_key,omitempty"`
+	TaprootTree        []PsbtTaprootTreeLeaf   `json:"taproot_tree,omitempty"`
+	TaprootBip32Derivs []PsbtTaprootBip32Deriv `json:"taproot_bip32_derivs,omitempty"`
+	Claim              *PsbtClaim              `json:"claim,omitempty"`
+	Proprietary        []PsbtProprietary       `json:"proprietary,omitempty"`
+	Unknown            map[string]string       `json:"unknown,omitempty"`
+}
+
+// PsbtGlobalXPub models a global extended public key of a PSBT.
+type PsbtGlobalXPub struct {
+	XPub              string `json:"xpub"`
+	MasterFingerprint string `json:"master_fingerprint"`
+	Path              string `json:"path"`
+}
+
+// DecodePsbtResult models the data returned from the decodepsbt command.
+type DecodePsbtResult struct {
+	Tx          TxRawDecodeResult  `json:"tx"`
+	GlobalXPubs []PsbtGlobalXPub   `json:"global_xpubs"`
+	Proprietary []PsbtProprietary  `json:"proprietary"`
+	Unknown     map[string]string  `json:"unknown"`
+	Inputs      []DecodePsbtInput  `json:"inputs"`
+	Outputs     []DecodePsbtOutput `json:"outputs"`
+	Fee         *float64           `json:"fee,omitempty"`
+}
+
+// FinalizePsbtResult models the data returned from the finalizepsbt command.
+// Hex is set instead of Psbt when the transaction was extracted.
+type FinalizePsbtResult struct {
+	Psbt     string `json:"psbt,omitempty"`
+	Hex      string `json:"hex,omitempty"`
+	Complete bool   `json:"complete"`
+}
+
 // ValidateAddressChainResult models the data returned by the chain server
 // validateaddress command.
 //
@@ -896,6 +1338,13 @@ type GetDescriptorInfoResult struct {
 // DeriveAddressesResult models the data from the deriveaddresses command.
 type DeriveAddressesResult []string
 
+// DumpProfilesResult models the data from the dumpprofiles command.
+type DumpProfilesResult struct {
+	Heap      string `json:"heap"`
+	Goroutine string `json:"goroutine"`
+	CPU       string `json:"cpu,omitempty"`
+}
+
 // LoadWalletResult models the data from the loadwallet command
 type LoadWalletResult struct {
 	Name    string `json:"name"`
@@ -108,8 +108,8 @@ func TestChainSvrCustomResults(t *testing.T) {
 	}
 }
 
-// TestGetTxOutSetInfoResult ensures that custom unmarshalling of
-// GetTxOutSetInfoResult works as intended.
+// TestGetTxOutSetInfoResult ensures that custom marshalling and unmarshalling
+// of GetTxOutSetInfoResult works as intended.
 func TestGetTxOutSetInfoResult(t *testing.T) {
 	t.Parallel()
 
@@ -153,12 +153,63 @@ func TestGetTxOutSetInfoResult(t *testing.T) {
 				}(),
 			},
 		},
+		{
+			name:   "GetTxOutSetInfoResult - verbose",
+			result: `{"height":123,"bestblock":"000000000000005f94116250e2407310463c0a7cf950f1af9ebe935b1c0687ab","transactions":1,"txouts":2,"bogosize":1,"hash_serialized_2":"9a0a561203ff052182993bc5d0cb2c620880bfafdbd80331f65fd9546c3e5c3e","disk_size":1,"total_amount":50.5,"script_classes":{"nonstandard":{"txouts":1,"total_amount":0.5},"pubkeyhash":{"txouts":1,"total_amount":50}},"claim_scripts":{"claim":{"txouts":1,"total_amount":0.5},"support":{"txouts":0,"total_amount":0},"update":{"txouts":0,"total_amount":0}}}`,
+			want: btcjson.GetTxOutSetInfoResult{
+				Height: 123,
+				BestBlock: func() chainhash.Hash {
+					h, err := chainhash.NewHashFromStr("000000000000005f94116250e2407310463c0a7cf950f1af9ebe935b1c0687ab")
+					if err != nil {
+						panic(err)
+					}
+
+					return *h
+				}(),
+				Transactions: 1,
+				TxOuts:       2,
+				BogoSize:     1,
+				HashSerialized: func() chainhash.Hash {
+					h, err := chainhash.NewHashFromStr("9a0a561203ff052182993bc5d0cb2c620880bfafdbd80331f65fd9546c3e5c3e")
+					if err != nil {
+						panic(err)
+					}
+
+					return *h
+				}(),
+				DiskSize:    1,
+				TotalAmount: 50.5 * btcutil.SatoshiPerBitcoin,
+				ScriptClasses: map[string]btcjson.TxOutSetScriptClass{
+					"nonstandard": {TxOuts: 1, TotalAmount: 0.5},
+					"pubkeyhash":  {TxOuts: 1, TotalAmount: 50},
+				},
+				ClaimScripts: map[string]btcjson.TxOutSetScriptClass{
+					"claim":   {TxOuts: 1, TotalAmount: 0.5},
+					"support": {},
+					"update":  {},
+				},
+			},
+		},
 	}
 
 	t.Logf("Running %d tests", len(tests))
 	for i, test := range tests {
+		// The result marshals back to the same JSON.
+		marshalled, err := json.Marshal(test.want)
+		if err != nil {
+			t.Errorf("Test #%d (%s) unexpected marshal error: %v", i,
+				test.name, err)
+			continue
+		}
+		if string(marshalled) != test.result {
+			t.Errorf("Test #%d (%s) unexpected marshalled data - "+
+				"got %s, want %s", i, test.name, marshalled,
+				test.result)
+			continue
+		}
+
 		var out btcjson.GetTxOutSetInfoResult
-		err := json.Unmarshal([]byte(test.result), &out)
+		err = json.Unmarshal([]byte(test.result), &out)
 		if err != nil {
 			t.Errorf("Test #%d (%s) unexpected error: %v", i,
 				test.name, err)
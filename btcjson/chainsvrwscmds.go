@@ -57,6 +57,44 @@ func NewNotifyNewTransactionsCmd(verbose *bool) *NotifyNewTransactionsCmd {
 	}
 }
 
+// NotifyDoubleSpendsCmd defines the notifydoublespends JSON-RPC command.
+type NotifyDoubleSpendsCmd struct{}
+
+// NewNotifyDoubleSpendsCmd returns a new instance which can be used to issue
+// a notifydoublespends JSON-RPC command.
+func NewNotifyDoubleSpendsCmd() *NotifyDoubleSpendsCmd {
+	return &NotifyDoubleSpendsCmd{}
+}
+
+// StopNotifyDoubleSpendsCmd defines the stopnotifydoublespends JSON-RPC
+// command.
+type StopNotifyDoubleSpendsCmd struct{}
+
+// NewStopNotifyDoubleSpendsCmd returns a new instance which can be used to
+// issue a stopnotifydoublespends JSON-RPC command.
+func NewStopNotifyDoubleSpendsCmd() *StopNotifyDoubleSpendsCmd {
+	return &StopNotifyDoubleSpendsCmd{}
+}
+
+// NotifyMempoolEventsCmd defines the notifymempoolevents JSON-RPC command.
+type NotifyMempoolEventsCmd struct{}
+
+// NewNotifyMempoolEventsCmd returns a new instance which can be used to issue
+// a notifymempoolevents JSON-RPC command.
+func NewNotifyMempoolEventsCmd() *NotifyMempoolEventsCmd {
+	return &NotifyMempoolEventsCmd{}
+}
+
+// StopNotifyMempoolEventsCmd defines the stopnotifymempoolevents JSON-RPC
+// command.
+type StopNotifyMempoolEventsCmd struct{}
+
+// NewStopNotifyMempoolEventsCmd returns a new instance which can be used to
+// issue a stopnotifymempoolevents JSON-RPC command.
+func NewStopNotifyMempoolEventsCmd() *StopNotifyMempoolEventsCmd {
+	return &StopNotifyMempoolEventsCmd{}
+}
+
 // SessionCmd defines the session JSON-RPC command.
 type SessionCmd struct{}
 
@@ -228,11 +266,15 @@ func init() {
 	MustRegisterCmd("authenticate", (*AuthenticateCmd)(nil), flags)
 	MustRegisterCmd("loadtxfilter", (*LoadTxFilterCmd)(nil), flags)
 	MustRegisterCmd("notifyblocks", (*NotifyBlocksCmd)(nil), flags)
+	MustRegisterCmd("notifydoublespends", (*NotifyDoubleSpendsCmd)(nil), flags)
+	MustRegisterCmd("notifymempoolevents", (*NotifyMempoolEventsCmd)(nil), flags)
 	MustRegisterCmd("notifynewtransactions", (*NotifyNewTransactionsCmd)(nil), flags)
 	MustRegisterCmd("notifyreceived", (*NotifyReceivedCmd)(nil), flags)
 	MustRegisterCmd("notifyspent", (*NotifySpentCmd)(nil), flags)
 	MustRegisterCmd("session", (*SessionCmd)(nil), flags)
 	MustRegisterCmd("stopnotifyblocks", (*StopNotifyBlocksCmd)(nil), flags)
+	MustRegisterCmd("stopnotifydoublespends", (*StopNotifyDoubleSpendsCmd)(nil), flags)
+	MustRegisterCmd("stopnotifymempoolevents", (*StopNotifyMempoolEventsCmd)(nil), flags)
 	MustRegisterCmd("stopnotifynewtransactions", (*StopNotifyNewTransactionsCmd)(nil), flags)
 	MustRegisterCmd("stopnotifyspent", (*StopNotifySpentCmd)(nil), flags)
 	MustRegisterCmd("stopnotifyreceived", (*StopNotifyReceivedCmd)(nil), flags)
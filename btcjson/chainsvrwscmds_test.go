@@ -63,6 +63,50 @@ func TestChainSvrWsCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"stopnotifyblocks","params":[],"id":1}`,
 			unmarshalled: &btcjson.StopNotifyBlocksCmd{},
 		},
+		{
+			name: "notifydoublespends",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("notifydoublespends")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewNotifyDoubleSpendsCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"notifydoublespends","params":[],"id":1}`,
+			unmarshalled: &btcjson.NotifyDoubleSpendsCmd{},
+		},
+		{
+			name: "stopnotifydoublespends",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("stopnotifydoublespends")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewStopNotifyDoubleSpendsCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"stopnotifydoublespends","params":[],"id":1}`,
+			unmarshalled: &btcjson.StopNotifyDoubleSpendsCmd{},
+		},
+		{
+			name: "notifymempoolevents",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("notifymempoolevents")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewNotifyMempoolEventsCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"notifymempoolevents","params":[],"id":1}`,
+			unmarshalled: &btcjson.NotifyMempoolEventsCmd{},
+		},
+		{
+			name: "stopnotifymempoolevents",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("stopnotifymempoolevents")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewStopNotifyMempoolEventsCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"stopnotifymempoolevents","params":[],"id":1}`,
+			unmarshalled: &btcjson.StopNotifyMempoolEventsCmd{},
+		},
 		{
 			name: "notifynewtransactions",
 			newCmd: func() (interface{}, error) {
@@ -75,6 +75,49 @@ const (
 	// from the chain server that inform a client that a transaction that
 	// matches the loaded filter was accepted by the mempool.
 	RelevantTxAcceptedNtfnMethod = "relevanttxaccepted"
+
+	// MempoolEventNtfnMethod is the method used for notifications from the
+	// chain server that a transaction has been added to or removed from
+	// the mempool.
+	MempoolEventNtfnMethod = "mempoolevent"
+
+	// DoubleSpendNtfnMethod is the method used for notifications from the
+	// chain server that a transaction double spending a transaction in
+	// the mempool was received and rejected.
+	DoubleSpendNtfnMethod = "doublespend"
+
+	// ReorganizationNtfnMethod is the method used for notifications from
+	// the chain server that the main chain has been reorganized.
+	ReorganizationNtfnMethod = "reorganization"
+)
+
+// Mempool event types sent in the Event field of a mempoolevent
+// notification.
+const (
+	// MempoolEventAccepted indicates the transaction was accepted into the
+	// mempool.
+	MempoolEventAccepted = "accepted"
+
+	// MempoolEventRemovedBlock indicates the transaction was removed from
+	// the mempool because it was included in a connected block.
+	MempoolEventRemovedBlock = "removedblock"
+
+	// MempoolEventRemovedConflict indicates the transaction was removed
+	// from the mempool because it conflicts with a transaction included in
+	// a connected block.
+	MempoolEventRemovedConflict = "removedconflict"
+
+	// MempoolEventRemovedExpiry indicates the transaction was removed from
+	// the mempool because it expired.
+	MempoolEventRemovedExpiry = "removedexpiry"
+
+	// MempoolEventReplaced indicates the transaction was replaced by a
+	// Replace-By-Fee transaction.
+	MempoolEventReplaced = "replaced"
+
+	// MempoolEventRemoved indicates the transaction was removed from the
+	// mempool for any other reason.
+	MempoolEventRemoved = "removed"
 )
 
 // BlockConnectedNtfn defines the blockconnected JSON-RPC notification.
@@ -285,6 +328,55 @@ func NewRelevantTxAcceptedNtfn(txHex string) *RelevantTxAcceptedNtfn {
 	return &RelevantTxAcceptedNtfn{Transaction: txHex}
 }
 
+// MempoolEventNtfn defines the mempoolevent JSON-RPC notification.  Conflict
+// is the hash of the transaction responsible for the removal of a replaced or
+// conflicting transaction and empty otherwise.
+type MempoolEventNtfn struct {
+	Event    string
+	TxID     string
+	Conflict string
+}
+
+// NewMempoolEventNtfn returns a new instance which can be used to issue a
+// mempoolevent JSON-RPC notification.
+func NewMempoolEventNtfn(event, txHash, conflict string) *MempoolEventNtfn {
+	return &MempoolEventNtfn{
+		Event:    event,
+		TxID:     txHash,
+		Conflict: conflict,
+	}
+}
+
+// DoubleSpendNtfn defines the doublespend JSON-RPC notification.  TxID is the
+// hash of the transaction in the mempool and DoubleSpendTxID is the hash of
+// the rejected transaction which double spends it.
+type DoubleSpendNtfn struct {
+	TxID            string
+	DoubleSpendTxID string
+}
+
+// NewDoubleSpendNtfn returns a new instance which can be used to issue a
+// doublespend JSON-RPC notification.
+func NewDoubleSpendNtfn(txHash, doubleSpendHash string) *DoubleSpendNtfn {
+	return &DoubleSpendNtfn{
+		TxID:            txHash,
+		DoubleSpendTxID: doubleSpendHash,
+	}
+}
+
+// ReorganizationNtfn defines the reorganization JSON-RPC notification.
+type ReorganizationNtfn struct {
+	Reorganization ReorganizationResult
+}
+
+// NewReorganizationNtfn returns a new instance which can be used to issue a
+// reorganization JSON-RPC notification.
+func NewReorganizationNtfn(reorg ReorganizationResult) *ReorganizationNtfn {
+	return &ReorganizationNtfn{
+		Reorganization: reorg,
+	}
+}
+
 func init() {
 	// The commands in this file are only usable by websockets and are
 	// notifications.
@@ -301,4 +393,7 @@ func init() {
 	MustRegisterCmd(TxAcceptedNtfnMethod, (*TxAcceptedNtfn)(nil), flags)
 	MustRegisterCmd(TxAcceptedVerboseNtfnMethod, (*TxAcceptedVerboseNtfn)(nil), flags)
 	MustRegisterCmd(RelevantTxAcceptedNtfnMethod, (*RelevantTxAcceptedNtfn)(nil), flags)
+	MustRegisterCmd(MempoolEventNtfnMethod, (*MempoolEventNtfn)(nil), flags)
+	MustRegisterCmd(DoubleSpendNtfnMethod, (*DoubleSpendNtfn)(nil), flags)
+	MustRegisterCmd(ReorganizationNtfnMethod, (*ReorganizationNtfn)(nil), flags)
 }
@@ -212,6 +212,68 @@ func TestChainSvrWsNtfns(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "mempoolevent",
+			newNtfn: func() (interface{}, error) {
+				return btcjson.NewCmd("mempoolevent", "replaced", "123", "456")
+			},
+			staticNtfn: func() interface{} {
+				return btcjson.NewMempoolEventNtfn("replaced", "123", "456")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"mempoolevent","params":["replaced","123","456"],"id":null}`,
+			unmarshalled: &btcjson.MempoolEventNtfn{
+				Event:    "replaced",
+				TxID:     "123",
+				Conflict: "456",
+			},
+		},
+		{
+			name: "doublespend",
+			newNtfn: func() (interface{}, error) {
+				return btcjson.NewCmd("doublespend", "123", "456")
+			},
+			staticNtfn: func() interface{} {
+				return btcjson.NewDoubleSpendNtfn("123", "456")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"doublespend","params":["123","456"],"id":null}`,
+			unmarshalled: &btcjson.DoubleSpendNtfn{
+				TxID:            "123",
+				DoubleSpendTxID: "456",
+			},
+		},
+		{
+			name: "reorganization",
+			newNtfn: func() (interface{}, error) {
+				return btcjson.NewCmd("reorganization", `{"oldhash":"123","oldheight":5,"newhash":"456","newheight":6,"forkhash":"789","forkheight":4,"disconnected":["123"],"connected":["abc","456"],"claimnames":["name"]}`)
+			},
+			staticNtfn: func() interface{} {
+				return btcjson.NewReorganizationNtfn(btcjson.ReorganizationResult{
+					OldHash:      "123",
+					OldHeight:    5,
+					NewHash:      "456",
+					NewHeight:    6,
+					ForkHash:     "789",
+					ForkHeight:   4,
+					Disconnected: []string{"123"},
+					Connected:    []string{"abc", "456"},
+					ClaimNames:   []string{"name"},
+				})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"reorganization","params":[{"oldhash":"123","oldheight":5,"newhash":"456","newheight":6,"forkhash":"789","forkheight":4,"disconnected":["123"],"connected":["abc","456"],"claimnames":["name"]}],"id":null}`,
+			unmarshalled: &btcjson.ReorganizationNtfn{
+				Reorganization: btcjson.ReorganizationResult{
+					OldHash:      "123",
+					OldHeight:    5,
+					NewHash:      "456",
+					NewHeight:    6,
+					ForkHash:     "789",
+					ForkHeight:   4,
+					Disconnected: []string{"123"},
+					Connected:    []string{"abc", "456"},
+					ClaimNames:   []string{"name"},
+				},
+			},
+		},
 		{
 			name: "relevanttxaccepted",
 			newNtfn: func() (interface{}, error) {
@@ -19,3 +19,19 @@ type RescannedBlock struct {
 	Hash         string   `json:"hash"`
 	Transactions []string `json:"transactions"`
 }
+
+// ReorganizationResult models the data of a reorganization notification.  The
+// disconnected blocks start with the old best block and the connected blocks
+// end with the new best block.  The claim names are those of the claims,
+// supports and updates created or spent by these blocks.
+type ReorganizationResult struct {
+	OldHash      string   `json:"oldhash"`
+	OldHeight    int32    `json:"oldheight"`
+	NewHash      string   `json:"newhash"`
+	NewHeight    int32    `json:"newheight"`
+	ForkHash     string   `json:"forkhash"`
+	ForkHeight   int32    `json:"forkheight"`
+	Disconnected []string `json:"disconnected"`
+	Connected    []string `json:"connected"`
+	ClaimNames   []string `json:"claimnames"`
+}
@@ -247,3 +247,59 @@ func MethodUsageText(method string) (string, error) {
 	registerLock.Unlock()
 	return usage, nil
 }
+
+// ParamInfo describes a parameter of a registered command.
+type ParamInfo struct {
+	// Name is the lowercase name of the parameter.
+	Name string
+
+	// Usage is the usage of the parameter as it appears in the one-line
+	// usage of the method.
+	Usage string
+
+	// Optional specifies whether or not the parameter may be omitted.
+	Optional bool
+
+	// Kind is the kind of the parameter type with any pointer indirected.
+	Kind reflect.Kind
+}
+
+// MethodParams returns a description of each parameter of the provided method
+// in the order they are passed.  The provided method must be associated with a
+// registered type.
+func MethodParams(method string) ([]ParamInfo, error) {
+	// Look up details about the provided method and error out if not
+	// registered.
+	registerLock.RLock()
+	rtp, ok := methodToConcreteType[method]
+	info := methodToInfo[method]
+	registerLock.RUnlock()
+	if !ok {
+		str := fmt.Sprintf("%q is not registered", method)
+		return nil, makeError(ErrUnregisteredMethod, str)
+	}
+
+	rt := rtp.Elem()
+	params := make([]ParamInfo, 0, rt.NumField())
+	for i := 0; i < rt.NumField(); i++ {
+		rtf := rt.Field(i)
+
+		var defaultVal *reflect.Value
+		if defVal, ok := info.defaults[i]; ok {
+			defaultVal = &defVal
+		}
+
+		fieldType := rtf.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		params = append(params, ParamInfo{
+			Name:     strings.ToLower(rtf.Name),
+			Usage:    fieldUsage(rtf, defaultVal),
+			Optional: rtf.Type.Kind() == reflect.Ptr,
+			Kind:     fieldType.Kind(),
+		})
+	}
+
+	return params, nil
+}
@@ -428,3 +428,71 @@ func TestFieldUsage(t *testing.T) {
 		}
 	}
 }
+
+// TestMethodParams tests the MethodParams function to ensure it returns the
+// expected parameters and errors.
+func TestMethodParams(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		method   string
+		err      error
+		expected []btcjson.ParamInfo
+	}{
+		{
+			name:   "unregistered type",
+			method: "bogusmethod",
+			err:    btcjson.Error{ErrorCode: btcjson.ErrUnregisteredMethod},
+		},
+		{
+			name:     "no parameters",
+			method:   "getblockcount",
+			expected: []btcjson.ParamInfo{},
+		},
+		{
+			name:   "required and optional parameters",
+			method: "getblockheader",
+			expected: []btcjson.ParamInfo{
+				{
+					Name:  "hash",
+					Usage: `"hash"`,
+					Kind:  reflect.String,
+				},
+				{
+					Name:     "verbose",
+					Usage:    "verbose=true",
+					Optional: true,
+					Kind:     reflect.Bool,
+				},
+			},
+		},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		params, err := btcjson.MethodParams(test.method)
+		if reflect.TypeOf(err) != reflect.TypeOf(test.err) {
+			t.Errorf("Test #%d (%s) wrong error - got %T (%[3]v), "+
+				"want %T", i, test.name, err, test.err)
+			continue
+		}
+		if err != nil {
+			gotErrorCode := err.(btcjson.Error).ErrorCode
+			if gotErrorCode != test.err.(btcjson.Error).ErrorCode {
+				t.Errorf("Test #%d (%s) mismatched error code "+
+					"- got %v (%v), want %v", i, test.name,
+					gotErrorCode, err,
+					test.err.(btcjson.Error).ErrorCode)
+			}
+			continue
+		}
+
+		// Ensure the parameters match the expected values.
+		if !reflect.DeepEqual(params, test.expected) {
+			t.Errorf("Test #%d (%s) mismatched parameters - got "+
+				"%+v, want %+v", i, test.name, params,
+				test.expected)
+		}
+	}
+}
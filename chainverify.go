@@ -0,0 +1,113 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/blockchain"
+)
+
+// errChainVerifyInterrupted is returned when the verification of the chain is
+// interrupted by the server shutting down.
+var errChainVerifyInterrupted = errors.New("chain verification interrupted")
+
+// verifyChainBlocks verifies the blocks of the main chain from the best block
+// down to, but not including, the block at the passed finish height.  The level
+// determines how thorough the verification is:
+//
+//	0: the blocks are loaded from the database
+//	1: the blocks also have to pass the context free sanity checks
+//
+// The verification stops early when the quit channel is closed.
+func verifyChainBlocks(chain *blockchain.BlockChain,
+	timeSource blockchain.MedianTimeSource, level, finishHeight int32,
+	quit <-chan struct{}) error {
+
+	best := chain.BestSnapshot()
+	for height := best.Height; height > finishHeight; height-- {
+		select {
+		case <-quit:
+			return errChainVerifyInterrupted
+		default:
+		}
+
+		// Level 0 just looks up the block.
+		block, err := chain.BlockByHeight(height)
+		if err != nil {
+			return fmt.Errorf("unable to fetch block at height %d: %w",
+				height, err)
+		}
+
+		// Level 1 does basic chain sanity checks.
+		if level > 0 {
+			err := blockchain.CheckBlockSanity(block,
+				chain.ChainParams().PowLimit, timeSource)
+			if err != nil {
+				return fmt.Errorf("unable to validate block at hash "+
+					"%v height %d: %w", block.Hash(), height, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// verifyChainHandler verifies the last blocks of the main chain in the
+// background once the server is started, so that corruption of the block
+// database is noticed before the blocks are served.  The problem that is found,
+// if any, is logged and reported as a warning by the RPC server.
+//
+// It must be run as a goroutine.
+func (s *server) verifyChainHandler() {
+	defer s.wg.Done()
+
+	// Don't attempt to verify blocks which have been pruned.
+	bestHeight := s.chain.BestSnapshot().Height
+	finishHeight := bestHeight - int32(cfg.CheckBlocks)
+	if cfg.Prune != 0 {
+		pruneHeight, err := s.chain.PruneHeight()
+		if err != nil {
+			srvrLog.Errorf("Unable to determine the prune height: %v",
+				err)
+			return
+		}
+		if finishHeight < pruneHeight-1 {
+			finishHeight = pruneHeight - 1
+		}
+	}
+	if finishHeight < 0 {
+		finishHeight = 0
+	}
+
+	srvrLog.Infof("Verifying the last %d blocks at level %d",
+		bestHeight-finishHeight, cfg.CheckLevel)
+	err := verifyChainBlocks(s.chain, s.timeSource, int32(cfg.CheckLevel),
+		finishHeight, s.quit)
+	if errors.Is(err, errChainVerifyInterrupted) {
+		return
+	}
+	if err != nil {
+		srvrLog.Errorf("Chain verification failed: %v", err)
+		s.chainVerifyMtx.Lock()
+		s.chainVerifyWarning = "Chain verification failed, the block " +
+			"database may be corrupted -- " + err.Error()
+		s.chainVerifyMtx.Unlock()
+		return
+	}
+	srvrLog.Infof("Chain verification completed successfully")
+}
+
+// ChainVerifyWarning returns the warning about the problem found by the
+// verification of the chain at startup or an empty string when no problem was
+// found.
+//
+// This function is safe for concurrent access.
+func (s *server) ChainVerifyWarning() string {
+	s.chainVerifyMtx.Lock()
+	defer s.chainVerifyMtx.Unlock()
+	return s.chainVerifyWarning
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg/v2"
+	"github.com/btcsuite/btcd/database"
+)
+
+// TestVerifyChainBlocks ensures the blocks of the main chain verify and that
+// the verification stops when the quit channel is closed.
+func TestVerifyChainBlocks(t *testing.T) {
+	params := &chaincfg.MainNetParams
+	db, err := database.Create("ffldb", filepath.Join(t.TempDir(), "db"),
+		params.Net)
+	if err != nil {
+		t.Fatalf("unable to create database: %v", err)
+	}
+	defer db.Close()
+	timeSource := blockchain.NewMedianTime()
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:               db,
+		ChainParams:      params,
+		TimeSource:       timeSource,
+		UtxoCacheMaxSize: 1024 * 1024,
+	})
+	if err != nil {
+		t.Fatalf("unable to create chain: %v", err)
+	}
+	path := filepath.Join("blockchain", "testdata", "blk_0_to_14131.dat")
+	if _, err := importBlockFile(chain, path, 0, make(chan struct{})); err != nil {
+		t.Fatalf("importBlockFile: unexpected error: %v", err)
+	}
+
+	for level := int32(0); level <= 1; level++ {
+		err := verifyChainBlocks(chain, timeSource, level, 14000, nil)
+		if err != nil {
+			t.Fatalf("verifyChainBlocks level %d: unexpected error: %v",
+				level, err)
+		}
+	}
+
+	quit := make(chan struct{})
+	close(quit)
+	err = verifyChainBlocks(chain, timeSource, 1, 0, quit)
+	if !errors.Is(err, errChainVerifyInterrupted) {
+		t.Fatalf("verifyChainBlocks: got %v, want %v", err,
+			errChainVerifyInterrupted)
+	}
+}
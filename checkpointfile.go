@@ -0,0 +1,77 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg/v2"
+)
+
+// checkpointSignaturePrefix is the prefix of the line of a checkpoint file
+// which holds the signature of the checkpoints.
+const checkpointSignaturePrefix = "signature "
+
+// parseCheckpointFile parses the checkpoints of a checkpoint file and ensures
+// they are signed by the key of the passed pay-to-pubkey-hash address.
+//
+// Each line of the file is either empty, a comment starting with '#' or a
+// checkpoint in the '<height>:<hash>' format.  The last line holds the
+// signature of the file in the form 'signature <base64 signature>', where the
+// signature is the one produced by the signmessage and signmessagewithprivkey
+// commands for all of the preceding content of the file as the message.
+func parseCheckpointFile(content []byte, signer string,
+	params *chaincfg.Params) ([]chaincfg.Checkpoint, error) {
+
+	// Split off the signature which is on the last line.
+	message := bytes.TrimRight(content, "\r\n")
+	sigLine := message
+	if i := bytes.LastIndexByte(message, '\n'); i != -1 {
+		message, sigLine = message[:i+1], message[i+1:]
+	} else {
+		message = nil
+	}
+	sigStr, ok := strings.CutPrefix(strings.TrimSpace(string(sigLine)),
+		checkpointSignaturePrefix)
+	if !ok {
+		return nil, errors.New("the checkpoint file is not signed")
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(sigStr))
+	if err != nil {
+		return nil, fmt.Errorf("malformed checkpoint file signature: %w",
+			err)
+	}
+	if !verifyMessage(signer, sig, string(message), params) {
+		return nil, fmt.Errorf("the checkpoint file is not signed by %s",
+			signer)
+	}
+
+	var checkpointStrings []string
+	for _, line := range strings.Split(string(message), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		checkpointStrings = append(checkpointStrings, line)
+	}
+	return parseCheckpoints(checkpointStrings)
+}
+
+// loadCheckpointFile reads the checkpoints of the checkpoint file at the passed
+// path.  See parseCheckpointFile for the format of the file.
+func loadCheckpointFile(path, signer string,
+	params *chaincfg.Params) ([]chaincfg.Checkpoint, error) {
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseCheckpointFile(content, signer, params)
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/address/v2"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/chaincfg/v2"
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/wire/v2"
+)
+
+// signCheckpointFile returns the passed content followed by the signature line
+// for it made with the passed key.
+func signCheckpointFile(key *btcec.PrivateKey, content string) []byte {
+	var buf bytes.Buffer
+	wire.WriteVarString(&buf, 0, messageSignatureHeader)
+	wire.WriteVarString(&buf, 0, content)
+	messageHash := chainhash.DoubleHashB(buf.Bytes())
+	sig := ecdsa.SignCompact(key, messageHash, true)
+
+	return []byte(content + checkpointSignaturePrefix +
+		base64.StdEncoding.EncodeToString(sig) + "\n")
+}
+
+// TestParseCheckpointFile ensures the checkpoints of a checkpoint file are only
+// accepted when the file is signed by the key of the expected signer.
+func TestParseCheckpointFile(t *testing.T) {
+	params := &chaincfg.MainNetParams
+
+	// Create the keys and the addresses of the signer and another party.
+	p2pkh := func(key *btcec.PrivateKey) string {
+		pkHash := address.Hash160(key.PubKey().SerializeCompressed())
+		addr, err := address.NewAddressPubKeyHash(pkHash, params)
+		if err != nil {
+			t.Fatalf("unable to create address: %v", err)
+		}
+		return addr.EncodeAddress()
+	}
+	signerKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("unable to create key: %v", err)
+	}
+	otherKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("unable to create key: %v", err)
+	}
+	signer, other := p2pkh(signerKey), p2pkh(otherKey)
+
+	const content = "# Checkpoints of the main network\n" +
+		"\n" +
+		"11111:0000000069e244f73d78e8fd29ba2fd2ed618bd6fa2ee92559f542fdb26e7c1d\n" +
+		"33333:000000002dd5588a74784eaa7ab0507a18ad16a236e7b1ce69f00d7ddfb5d0a6\n"
+	signed := signCheckpointFile(signerKey, content)
+
+	// A file signed by the signer is accepted.
+	checkpoints, err := parseCheckpointFile(signed, signer, params)
+	if err != nil {
+		t.Fatalf("parseCheckpointFile: unexpected error: %v", err)
+	}
+	if len(checkpoints) != 2 {
+		t.Fatalf("parseCheckpointFile: got %d checkpoints, want 2",
+			len(checkpoints))
+	}
+	wantHash := "000000002dd5588a74784eaa7ab0507a18ad16a236e7b1ce69f00d7ddfb5d0a6"
+	if checkpoints[1].Height != 33333 ||
+		checkpoints[1].Hash.String() != wantHash {
+
+		t.Fatalf("parseCheckpointFile: unexpected checkpoint %d:%v",
+			checkpoints[1].Height, checkpoints[1].Hash)
+	}
+
+	tests := []struct {
+		name    string
+		content []byte
+		signer  string
+	}{
+		{
+			name:    "other signer",
+			content: signed,
+			signer:  other,
+		},
+		{
+			name:    "signed by other key",
+			content: signCheckpointFile(otherKey, content),
+			signer:  signer,
+		},
+		{
+			name: "tampered",
+			content: []byte(strings.Replace(string(signed), "33333",
+				"33334", 1)),
+			signer: signer,
+		},
+		{
+			name:    "unsigned",
+			content: []byte(content),
+			signer:  signer,
+		},
+		{
+			name: "malformed checkpoint",
+			content: signCheckpointFile(signerKey,
+				content+"44444:nothash\n"),
+			signer: signer,
+		},
+	}
+	for _, test := range tests {
+		_, err := parseCheckpointFile(test.content, test.signer, params)
+		if err == nil {
+			t.Errorf("%s: expected error", test.name)
+		}
+	}
+}
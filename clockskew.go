@@ -0,0 +1,239 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// clockSkewMinSamples is the minimum number of outbound peers the clock
+	// skew is estimated from.
+	clockSkewMinSamples = 5
+
+	// clockSkewWarnThreshold is the clock skew above which a warning is
+	// logged and reported via the RPC server.
+	clockSkewWarnThreshold = time.Minute * 5
+
+	// clockSkewOutlierFactor is the number of median absolute deviations
+	// an offset may be away from the median offset before it is rejected
+	// as an outlier.
+	clockSkewOutlierFactor = 3
+
+	// clockSkewMinDeviation is the smallest deviation from the median
+	// offset which is rejected as an outlier.  It keeps offsets which are
+	// only off by the truncation of the timestamps to seconds when most
+	// peers agree exactly.
+	clockSkewMinDeviation = time.Second * 2
+)
+
+// clockSkewState describes how far the local clock is off from the time of the
+// network.
+type clockSkewState int
+
+const (
+	// clockSkewUnknown indicates there are not enough samples to estimate
+	// the clock skew.
+	clockSkewUnknown clockSkewState = iota
+
+	// clockSkewOK indicates the local clock agrees with the network.
+	clockSkewOK
+
+	// clockSkewWarning indicates the local clock is off by more than
+	// clockSkewWarnThreshold.
+	clockSkewWarning
+
+	// clockSkewExceeded indicates the local clock is off by more than the
+	// configured maximum clock skew.
+	clockSkewExceeded
+)
+
+// clockSkewSample is the time offset of a peer along with the latency of the
+// connection to it.
+type clockSkewSample struct {
+	// offset is the time the peer advertised in its version message minus
+	// the local time the message was received at.
+	offset time.Duration
+
+	// latency is half the round trip time of the last ping to the peer,
+	// which is the time its version message is assumed to have been in
+	// transit.
+	latency time.Duration
+
+	outbound bool
+}
+
+// clockSkewMonitor estimates how far the local clock is off from the time of
+// the network from the timestamps the peers advertise in their version
+// messages, corrected by the latency of the connections to them.
+//
+// Unlike the median time source used by the consensus rules, the estimate only
+// uses the samples of the outbound peers which are currently connected, which
+// can't be chosen by an attacker as easily, and rejects the samples which are
+// outliers before averaging the others.
+type clockSkewMonitor struct {
+	mtx     sync.Mutex
+	samples map[int32]*clockSkewSample
+	maxSkew time.Duration
+
+	// state and lastOffset are the results of the last evaluation.
+	state      clockSkewState
+	lastOffset time.Duration
+}
+
+// newClockSkewMonitor returns a clock skew monitor which considers the local
+// clock to be unacceptable when it is off by more than the passed maximum
+// skew.  A maximum skew of zero never considers it to be unacceptable.
+func newClockSkewMonitor(maxSkew time.Duration) *clockSkewMonitor {
+	return &clockSkewMonitor{
+		samples: make(map[int32]*clockSkewSample),
+		maxSkew: maxSkew,
+	}
+}
+
+// addSample adds the timestamp advertised by the peer with the passed id in its
+// version message which was received at the passed local time.
+func (m *clockSkewMonitor) addSample(id int32, outbound bool,
+	timestamp, received time.Time) {
+
+	m.mtx.Lock()
+	m.samples[id] = &clockSkewSample{
+		offset:   timestamp.Sub(received),
+		outbound: outbound,
+	}
+	m.mtx.Unlock()
+}
+
+// updateLatency updates the latency of the connection to the peer with the
+// passed id from the round trip time of a ping.
+func (m *clockSkewMonitor) updateLatency(id int32, rtt time.Duration) {
+	m.mtx.Lock()
+	if sample, ok := m.samples[id]; ok {
+		sample.latency = rtt / 2
+	}
+	m.mtx.Unlock()
+}
+
+// removeSample removes the sample of the peer with the passed id.
+func (m *clockSkewMonitor) removeSample(id int32) {
+	m.mtx.Lock()
+	delete(m.samples, id)
+	m.mtx.Unlock()
+}
+
+// offset returns the estimated offset of the time of the network from the
+// local clock along with the number of outbound samples.  The offset is zero
+// when there are fewer than clockSkewMinSamples of them.
+//
+// This function MUST be called with the monitor lock held.
+func (m *clockSkewMonitor) offset() (time.Duration, int) {
+	offsets := make([]time.Duration, 0, len(m.samples))
+	for _, sample := range m.samples {
+		if sample.outbound {
+			offsets = append(offsets, sample.offset+sample.latency)
+		}
+	}
+	if len(offsets) < clockSkewMinSamples {
+		return 0, len(offsets)
+	}
+
+	// Reject the offsets which are further away from the median than a
+	// multiple of the median absolute deviation and average the rest.
+	median := medianDuration(offsets)
+	deviations := make([]time.Duration, len(offsets))
+	for i, offset := range offsets {
+		deviations[i] = absDuration(offset - median)
+	}
+	maxDeviation := clockSkewOutlierFactor * medianDuration(deviations)
+	if maxDeviation < clockSkewMinDeviation {
+		maxDeviation = clockSkewMinDeviation
+	}
+	var sum time.Duration
+	var count int
+	for _, offset := range offsets {
+		if absDuration(offset-median) <= maxDeviation {
+			sum += offset
+			count++
+		}
+	}
+	return sum / time.Duration(count), len(offsets)
+}
+
+// State returns how far the local clock was off from the time of the network
+// along with the estimated offset of the time of the network from it when it
+// was last evaluated.
+//
+// This function is safe for concurrent access.
+func (m *clockSkewMonitor) State() (clockSkewState, time.Duration) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	return m.state, m.lastOffset
+}
+
+// evaluate estimates the offset of the time of the network from the local
+// clock and returns it along with the resulting state and the state of the
+// previous evaluation.  The state remains unknown until there are enough
+// samples and is kept when there no longer are.
+//
+// This function is safe for concurrent access.
+func (m *clockSkewMonitor) evaluate() (time.Duration, clockSkewState,
+	clockSkewState) {
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	offset, count := m.offset()
+	if count < clockSkewMinSamples {
+		return m.lastOffset, m.state, m.state
+	}
+
+	state := clockSkewOK
+	switch skew := absDuration(offset); {
+	case m.maxSkew > 0 && skew > m.maxSkew:
+		state = clockSkewExceeded
+	case skew > clockSkewWarnThreshold:
+		state = clockSkewWarning
+	}
+	prevState := m.state
+	m.state = state
+	m.lastOffset = offset
+	return offset, state, prevState
+}
+
+// describeClockSkew returns a description of how far the local clock is off
+// from the time of the network given the estimated offset of the latter.
+func describeClockSkew(offset time.Duration) string {
+	offset = offset.Round(time.Second)
+	if offset < 0 {
+		return fmt.Sprintf("the local clock is %v ahead of the network",
+			-offset)
+	}
+	return fmt.Sprintf("the local clock is %v behind the network", offset)
+}
+
+// medianDuration returns the median of the passed durations, which are sorted
+// in place.
+func medianDuration(durations []time.Duration) time.Duration {
+	sort.Slice(durations, func(i, j int) bool {
+		return durations[i] < durations[j]
+	})
+	n := len(durations)
+	if n%2 == 0 {
+		return (durations[n/2-1] + durations[n/2]) / 2
+	}
+	return durations[n/2]
+}
+
+// absDuration returns the absolute value of the passed duration.
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// TestClockSkewMonitor ensures the offset of the time of the network is only
+// estimated from the outbound peers once there are enough of them, that the
+// samples are corrected by the latency and outliers are rejected, and that the
+// state follows the skew.
+func TestClockSkewMonitor(t *testing.T) {
+	m := newClockSkewMonitor(time.Minute * 70)
+	now := time.Unix(1700000000, 0)
+
+	// Inbound peers are ignored and the state stays unknown until there
+	// are enough outbound peers.
+	m.addSample(100, false, now.Add(-time.Hour*2), now)
+	for id := int32(0); id < clockSkewMinSamples-1; id++ {
+		m.addSample(id, true, now.Add(time.Second*10), now)
+	}
+	if _, state, _ := m.evaluate(); state != clockSkewUnknown {
+		t.Fatalf("evaluate: got state %v, want %v", state,
+			clockSkewUnknown)
+	}
+
+	// The outlier is rejected and the latency is added to the offsets.
+	m.addSample(clockSkewMinSamples, true, now.Add(time.Hour), now)
+	m.addSample(clockSkewMinSamples+1, true, now.Add(time.Second*10), now)
+	for id := int32(0); id < clockSkewMinSamples-1; id++ {
+		m.updateLatency(id, time.Second*2)
+	}
+	m.updateLatency(clockSkewMinSamples+1, time.Second*2)
+	offset, state, prevState := m.evaluate()
+	if offset != time.Second*11 || state != clockSkewOK ||
+		prevState != clockSkewUnknown {
+
+		t.Fatalf("evaluate: got (%v, %v, %v), want (11s, %v, %v)",
+			offset, state, prevState, clockSkewOK, clockSkewUnknown)
+	}
+
+	// The state follows the skew in either direction.
+	tests := []struct {
+		offset time.Duration
+		state  clockSkewState
+	}{
+		{time.Minute * 10, clockSkewWarning},
+		{-time.Minute * 10, clockSkewWarning},
+		{-time.Hour * 2, clockSkewExceeded},
+		{time.Minute, clockSkewOK},
+	}
+	for _, test := range tests {
+		for id := int32(0); id < clockSkewMinSamples+2; id++ {
+			m.addSample(id, true, now.Add(test.offset), now)
+		}
+		offset, state, _ := m.evaluate()
+		if offset != test.offset || state != test.state {
+			t.Fatalf("evaluate with offset %v: got (%v, %v), want "+
+				"(%v, %v)", test.offset, offset, state,
+				test.offset, test.state)
+		}
+	}
+
+	// The last state is kept when there are no longer enough samples.
+	for id := int32(0); id < clockSkewMinSamples; id++ {
+		m.removeSample(id)
+	}
+	if _, state, _ := m.evaluate(); state != clockSkewOK {
+		t.Fatalf("evaluate: got state %v, want %v", state, clockSkewOK)
+	}
+	if state, offset := m.State(); state != clockSkewOK ||
+		offset != time.Minute {
+
+		t.Fatalf("State: got (%v, %v), want (%v, 1m0s)", state, offset,
+			clockSkewOK)
+	}
+
+	// A maximum skew of zero never considers the clock unacceptable.
+	m = newClockSkewMonitor(0)
+	for id := int32(0); id < clockSkewMinSamples; id++ {
+		m.addSample(id, true, now.Add(time.Hour*24), now)
+	}
+	if _, state, _ := m.evaluate(); state != clockSkewWarning {
+		t.Fatalf("evaluate: got state %v, want %v", state,
+			clockSkewWarning)
+	}
+}
@@ -0,0 +1,315 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil/v2"
+	"github.com/btcsuite/btcd/database"
+	"github.com/btcsuite/btcd/limits"
+	"github.com/btcsuite/btcd/txscript/v2"
+	"github.com/btcsuite/btcd/wire/v2"
+	"github.com/btcsuite/btclog"
+)
+
+const (
+	// blockDbNamePrefix is the prefix for the btcd block database.
+	blockDbNamePrefix = "blocks"
+)
+
+var (
+	cfg *config
+	log btclog.Logger
+)
+
+// loadBlockDB opens the block database and returns a handle to it.
+func loadBlockDB() (database.DB, error) {
+	// The database name is based on the database type.
+	dbName := blockDbNamePrefix + "_" + cfg.DbType
+	dbPath := filepath.Join(cfg.DataDir, dbName)
+
+	log.Infof("Loading block database from '%s'", dbPath)
+	db, err := database.Open(cfg.DbType, dbPath, activeNetParams.Net)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("Block database loaded")
+	return db, nil
+}
+
+// balance houses the total value and the number of unspent outputs paying to
+// a single address.
+type balance struct {
+	address string
+	amount  int64
+	utxos   int64
+}
+
+// balanceTally accumulates the balances of all addresses from the outputs
+// added to it.
+type balanceTally struct {
+	balances map[string]*balance
+
+	// numSkipped and skippedAmount track the outputs which don't pay to
+	// exactly one address, such as bare multisig and nonstandard scripts,
+	// and therefore can't be attributed to an address.
+	numSkipped    int64
+	skippedAmount int64
+}
+
+// add attributes the passed output to the address it pays to.
+func (t *balanceTally) add(amount int64, pkScript []byte) {
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(pkScript,
+		activeNetParams)
+	if err != nil || len(addrs) != 1 {
+		t.numSkipped++
+		t.skippedAmount += amount
+		return
+	}
+
+	addr := addrs[0].EncodeAddress()
+	b, ok := t.balances[addr]
+	if !ok {
+		b = &balance{address: addr}
+		t.balances[addr] = b
+	}
+	b.amount += amount
+	b.utxos++
+}
+
+// sorted returns the balances ordered by amount, largest first, with ties
+// broken by address so the output is deterministic.
+func (t *balanceTally) sorted() []*balance {
+	balances := make([]*balance, 0, len(t.balances))
+	for _, b := range t.balances {
+		balances = append(balances, b)
+	}
+	slices.SortFunc(balances, func(a, b *balance) int {
+		if a.amount != b.amount {
+			if a.amount > b.amount {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.address, b.address)
+	})
+	return balances
+}
+
+// tallyBalances determines the balances of all addresses as of the block at
+// the passed height.  The outputs in the current utxo set which were created at
+// or before the height are added first.  Then, since outputs which existed at
+// the height may have been spent since, the spend journals of the blocks after
+// it are used to add back the spent outputs which were created at or before
+// the height.
+func tallyBalances(chain *blockchain.BlockChain, height, bestHeight int32) (*balanceTally, error) {
+	t := &balanceTally{balances: make(map[string]*balance)}
+
+	lastLogTime := time.Now()
+	var numUtxos, receivedLogUtxos int64
+	err := chain.ForEachUtxo(func(_ wire.OutPoint, entry *blockchain.UtxoEntry) error {
+		if entry.BlockHeight() <= height {
+			t.add(entry.Amount(), entry.PkScript())
+			numUtxos++
+		}
+
+		// Show progress when enabled.
+		receivedLogUtxos++
+		now := time.Now()
+		duration := now.Sub(lastLogTime)
+		if cfg.Progress != 0 &&
+			duration >= time.Second*time.Duration(cfg.Progress) {
+
+			log.Infof("Scanned %d unspent outputs in the last %s",
+				receivedLogUtxos, duration.Truncate(time.Millisecond))
+			receivedLogUtxos = 0
+			lastLogTime = now
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Added %d unspent outputs from the utxo set", numUtxos)
+
+	if height == bestHeight {
+		return t, nil
+	}
+
+	log.Infof("Adding back outputs spent in blocks %d to %d", height+1,
+		bestHeight)
+	var numSpent, receivedLogBlocks int64
+	err = chain.ForEachBlock(height+1, bestHeight, func(block *btcutil.Block,
+		stxos []blockchain.SpentTxOut) error {
+
+		for i := range stxos {
+			if stxos[i].Height <= height {
+				t.add(stxos[i].Amount, stxos[i].PkScript)
+				numSpent++
+			}
+		}
+
+		// Show progress when enabled.
+		receivedLogBlocks++
+		now := time.Now()
+		duration := now.Sub(lastLogTime)
+		if cfg.Progress != 0 &&
+			duration >= time.Second*time.Duration(cfg.Progress) {
+
+			log.Infof("Processed %d blocks in the last %s (height %d)",
+				receivedLogBlocks, duration.Truncate(time.Millisecond),
+				block.Height())
+			receivedLogBlocks = 0
+			lastLogTime = now
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Added back %d spent outputs", numSpent)
+
+	return t, nil
+}
+
+// writeBalances writes the passed balances to the writer as CSV with a header
+// row.  The balances are in satoshis.
+func writeBalances(w io.Writer, balances []*balance) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"address", "balance", "utxos"}); err != nil {
+		return err
+	}
+	for _, b := range balances {
+		err := cw.Write([]string{
+			b.address,
+			strconv.FormatInt(b.amount, 10),
+			strconv.FormatInt(b.utxos, 10),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// realMain is the real main function for the utility.  It is necessary to work
+// around the fact that deferred functions do not run when os.Exit() is called.
+func realMain() error {
+	// Load configuration and parse command line.
+	tcfg, _, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	cfg = tcfg
+
+	// Setup logging.
+	backendLogger := btclog.NewBackend(os.Stdout)
+	defer os.Stdout.Sync()
+	log = backendLogger.Logger("MAIN")
+	database.UseLogger(backendLogger.Logger("BCDB"))
+	blockchain.UseLogger(backendLogger.Logger("CHAN"))
+
+	// Load the block database.
+	db, err := loadBlockDB()
+	if err != nil {
+		log.Errorf("Failed to load database: %v", err)
+		return err
+	}
+	defer db.Close()
+
+	// Setup chain.  Ignore notifications since they aren't needed for this
+	// util.
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:          db,
+		ChainParams: activeNetParams,
+		TimeSource:  blockchain.NewMedianTime(),
+	})
+	if err != nil {
+		log.Errorf("Failed to initialize chain: %v", err)
+		return err
+	}
+
+	// Determine the balances as of the best block by default.  Going back
+	// from the best block requires the spend journals of all blocks after
+	// the height, so they must not have been pruned.
+	best := chain.BestSnapshot()
+	height := cfg.Height
+	if height == -1 {
+		height = best.Height
+	}
+	if height > best.Height {
+		err := fmt.Errorf("the block database is only at height %d "+
+			"which is less than the requested height of %d",
+			best.Height, height)
+		log.Error(err)
+		return err
+	}
+	if pruneHeight := chain.SpendJournalPruneHeight(); height < pruneHeight {
+		err := fmt.Errorf("the spend journals are pruned up to height "+
+			"%d so the balances can't be determined as of height %d",
+			pruneHeight, height)
+		log.Error(err)
+		return err
+	}
+
+	f, err := os.OpenFile(cfg.OutFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL,
+		0644)
+	if err != nil {
+		log.Errorf("Failed to create output file: %v", err)
+		return err
+	}
+	defer f.Close()
+
+	log.Infof("Determining address balances as of height %d", height)
+	t, err := tallyBalances(chain, height, best.Height)
+	if err != nil {
+		log.Errorf("Failed to determine balances: %v", err)
+		return err
+	}
+	if t.numSkipped > 0 {
+		log.Infof("Skipped %d outputs with a total of %v which don't pay "+
+			"to a single address", t.numSkipped,
+			btcutil.Amount(t.skippedAmount))
+	}
+
+	bw := bufio.NewWriterSize(f, 1<<20)
+	err = writeBalances(bw, t.sorted())
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err != nil {
+		log.Errorf("Failed to write balances: %v", err)
+		return err
+	}
+
+	log.Infof("Wrote the balances of %d addresses to %s", len(t.balances),
+		cfg.OutFile)
+	return nil
+}
+
+func main() {
+	// up some limits.
+	if err := limits.SetLimits(); err != nil {
+		os.Exit(1)
+	}
+
+	// Work around defer not working after os.Exit()
+	if err := realMain(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/btcsuite/btcd/address/v2"
+	"github.com/btcsuite/btcd/chaincfg/v2"
+	"github.com/btcsuite/btcd/txscript/v2"
+)
+
+// TestBalanceTally ensures the outputs are attributed to the address they pay
+// to, that outputs which don't pay to a single address are skipped, and that
+// the balances are sorted by amount and then by address.
+func TestBalanceTally(t *testing.T) {
+	activeNetParams = &chaincfg.MainNetParams
+
+	newScript := func(hash byte) ([]byte, string) {
+		addr, err := address.NewAddressPubKeyHash(
+			bytes.Repeat([]byte{hash}, 20), activeNetParams)
+		if err != nil {
+			t.Fatalf("unable to create address: %v", err)
+		}
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			t.Fatalf("unable to create script: %v", err)
+		}
+		return pkScript, addr.EncodeAddress()
+	}
+	script1, addr1 := newScript(0x01)
+	script2, addr2 := newScript(0x02)
+	script3, addr3 := newScript(0x03)
+
+	// A claim pays to the address of the script following its prefix.
+	claimPrefix, err := txscript.NewClaimScript([]byte("name"),
+		[]byte("value"))
+	if err != nil {
+		t.Fatalf("unable to create claim script: %v", err)
+	}
+	claimScript := append(claimPrefix, script2...)
+
+	tally := &balanceTally{balances: make(map[string]*balance)}
+	tally.add(100, script1)
+	tally.add(50, script2)
+	tally.add(50, claimScript)
+	tally.add(30, script3)
+	tally.add(25, []byte{txscript.OP_TRUE})
+	tally.add(75, []byte{txscript.OP_RETURN})
+
+	// The balances of the first and second address are tied, so they are
+	// ordered by address.
+	want := []balance{
+		{address: addr1, amount: 100, utxos: 1},
+		{address: addr2, amount: 100, utxos: 2},
+		{address: addr3, amount: 30, utxos: 1},
+	}
+	if addr2 < addr1 {
+		want[0], want[1] = want[1], want[0]
+	}
+	var got []balance
+	for _, b := range tally.sorted() {
+		got = append(got, *b)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected balances -- got %+v, want %+v", got, want)
+	}
+	if tally.numSkipped != 2 || tally.skippedAmount != 100 {
+		t.Fatalf("got %d skipped outputs with %d, want 2 with 100",
+			tally.numSkipped, tally.skippedAmount)
+	}
+}
+
+// TestWriteBalances ensures the balances are written as CSV with a header row.
+func TestWriteBalances(t *testing.T) {
+	balances := []*balance{
+		{address: "addr1", amount: 150, utxos: 2},
+		{address: "addr2", amount: 5, utxos: 1},
+	}
+
+	var b bytes.Buffer
+	if err := writeBalances(&b, balances); err != nil {
+		t.Fatalf("writeBalances: unexpected error: %v", err)
+	}
+	want := "address,balance,utxos\naddr1,150,2\naddr2,5,1\n"
+	if b.String() != want {
+		t.Fatalf("writeBalances: got %q, want %q", b.String(), want)
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcjson"
+)
+
+// maxBatchRequests is the maximum number of commands sent to the server in a
+// single batched request.  Larger batches are split into several requests.
+const maxBatchRequests = 100
+
+// batchCmd is a command read from a batch file.
+type batchCmd struct {
+	line    int
+	command string
+	cmd     interface{}
+}
+
+// batchResult is written for each command of a batch as a line of JSON.  It
+// holds either the result of the command or the error the server returned
+// for it.
+type batchResult struct {
+	Line    int               `json:"line"`
+	Command string            `json:"command"`
+	Result  json.RawMessage   `json:"result,omitempty"`
+	Error   *btcjson.RPCError `json:"error,omitempty"`
+}
+
+// readBatch reads the commands of a batch from the passed reader.  Each line
+// holds a command followed by its parameters, which are separated and quoted
+// as in the interactive shell.  Empty lines and lines starting with # are
+// skipped.  An error is returned for the first line which doesn't hold a
+// valid command.
+func readBatch(r io.Reader) ([]batchCmd, error) {
+	var cmds []batchCmd
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<26)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		args, err := splitArgs(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNum, err)
+		}
+		method := args[0]
+		if err := checkMethod(method); err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNum, err)
+		}
+		params := make([]interface{}, 0, len(args[1:]))
+		for _, arg := range args[1:] {
+			params = append(params, arg)
+		}
+		cmd, err := newCmd(method, params)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNum, err)
+		}
+
+		cmds = append(cmds, batchCmd{
+			line:    lineNum,
+			command: line,
+			cmd:     cmd,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return cmds, nil
+}
+
+// sendBatch sends the passed commands to the RPC server described in the
+// passed config struct in a single batched request and returns the responses
+// in the order of the commands.
+func sendBatch(cmds []batchCmd, cfg *config) ([]btcjson.Response, error) {
+	// The commands are identified by their index in the batch since the
+	// server may return the responses in any order.
+	var b bytes.Buffer
+	b.WriteByte('[')
+	for i, bc := range cmds {
+		marshalledJSON, err := btcjson.MarshalCmd(btcjson.RpcVersion2,
+			i, bc.cmd)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", bc.line, err)
+		}
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.Write(marshalledJSON)
+	}
+	b.WriteByte(']')
+
+	respBytes, err := postRequest(b.Bytes(), cfg)
+	if err != nil {
+		return nil, err
+	}
+	var resps []btcjson.Response
+	if err := json.Unmarshal(respBytes, &resps); err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal batched response: "+
+			"%v", err)
+	}
+
+	ordered := make([]btcjson.Response, len(cmds))
+	found := make([]bool, len(cmds))
+	for _, resp := range resps {
+		if resp.ID == nil {
+			continue
+		}
+		id, ok := (*resp.ID).(float64)
+		if !ok || id < 0 || int(id) >= len(cmds) || id != float64(int(id)) {
+			continue
+		}
+		ordered[int(id)] = resp
+		found[int(id)] = true
+	}
+	for i, ok := range found {
+		if !ok {
+			return nil, fmt.Errorf("line %d: no response from the "+
+				"server", cmds[i].line)
+		}
+	}
+
+	return ordered, nil
+}
+
+// runBatch runs the commands of the batch file named by the batch option and
+// writes the result of each of them to stdout as a line of JSON, limited to
+// the field selected by the field option, if any.  It returns whether or not
+// all of the commands succeeded.
+func runBatch(cfg *config) (bool, error) {
+	in := os.Stdin
+	if cfg.Batch != "-" {
+		f, err := os.Open(cfg.Batch)
+		if err != nil {
+			return false, err
+		}
+		defer f.Close()
+		in = f
+	}
+	cmds, err := readBatch(in)
+	if err != nil {
+		return false, err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	success := true
+	for len(cmds) > 0 {
+		chunk := cmds
+		if len(chunk) > maxBatchRequests {
+			chunk = chunk[:maxBatchRequests]
+		}
+		cmds = cmds[len(chunk):]
+
+		resps, err := sendBatch(chunk, cfg)
+		if err != nil {
+			return false, err
+		}
+		for i, resp := range resps {
+			result := batchResult{
+				Line:    chunk[i].line,
+				Command: chunk[i].command,
+				Result:  resp.Result,
+				Error:   resp.Error,
+			}
+			if result.Error == nil && cfg.Field != "" {
+				result.Result, err = batchField(resp.Result,
+					cfg.Field)
+				if err != nil {
+					result.Error = &btcjson.RPCError{
+						Code:    btcjson.ErrRPCMisc,
+						Message: err.Error(),
+					}
+				}
+			}
+			if result.Error == nil && result.Result == nil {
+				result.Result = json.RawMessage("null")
+			}
+			if result.Error != nil {
+				result.Result = nil
+				success = false
+			}
+
+			if err := enc.Encode(&result); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	return success, nil
+}
+
+// batchField returns the value at the passed dot separated path in the passed
+// JSON result.  See extractField for details.
+func batchField(result json.RawMessage, path string) (json.RawMessage, error) {
+	v, err := decodeResult(result)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal result: %v", err)
+	}
+	v, err = extractField(v, path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to extract field %q: %v", path,
+			err)
+	}
+	return encodeResult(v)
+}
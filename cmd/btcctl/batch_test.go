@@ -0,0 +1,105 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcjson"
+)
+
+// TestReadBatch ensures the commands of a batch are read along with their
+// line numbers while empty lines and comments are skipped.
+func TestReadBatch(t *testing.T) {
+	const batch = `# Commands to run.
+getblockcount
+
+  getblockhash 10
+getblock "00ab" 0
+`
+	cmds, err := readBatch(strings.NewReader(batch))
+	if err != nil {
+		t.Fatalf("readBatch: unexpected error: %v", err)
+	}
+
+	want := []batchCmd{
+		{
+			line:    2,
+			command: "getblockcount",
+			cmd:     &btcjson.GetBlockCountCmd{},
+		},
+		{
+			line:    4,
+			command: "getblockhash 10",
+			cmd:     &btcjson.GetBlockHashCmd{Index: 10},
+		},
+		{
+			line:    5,
+			command: `getblock "00ab" 0`,
+			cmd: &btcjson.GetBlockCmd{
+				Hash:      "00ab",
+				Verbosity: btcjson.Int(0),
+			},
+		},
+	}
+	if !reflect.DeepEqual(cmds, want) {
+		t.Fatalf("readBatch: got %+v, want %+v", cmds, want)
+	}
+}
+
+// TestReadBatchErrors ensures the line of an invalid command is reported.
+func TestReadBatchErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		batch string
+		want  string
+	}{
+		{
+			name:  "unknown method",
+			batch: "getblockcount\nnosuchcommand\n",
+			want:  "line 2:",
+		},
+		{
+			name:  "invalid parameter",
+			batch: "\ngetblockhash notanumber\n",
+			want:  "line 2:",
+		},
+		{
+			name:  "unterminated quote",
+			batch: `getblock "00ab`,
+			want:  "line 1:",
+		},
+	}
+
+	for _, test := range tests {
+		_, err := readBatch(strings.NewReader(test.batch))
+		if err == nil {
+			t.Errorf("%s: expected error", test.name)
+			continue
+		}
+		if !strings.HasPrefix(err.Error(), test.want) {
+			t.Errorf("%s: got error %q, want prefix %q", test.name,
+				err, test.want)
+		}
+	}
+}
+
+// TestBatchField ensures fields are extracted from the results of a batch.
+func TestBatchField(t *testing.T) {
+	result := []byte(`{"vout":[{"value":1.5},{"value":0.00000001}]}`)
+	field, err := batchField(result, "vout.value")
+	if err != nil {
+		t.Fatalf("batchField: unexpected error: %v", err)
+	}
+	if want := `[1.5,0.00000001]`; string(field) != want {
+		t.Fatalf("batchField: got %s, want %s", field, want)
+	}
+
+	if _, err := batchField(result, "vin"); err == nil {
+		t.Fatal("batchField: expected error for missing field")
+	}
+}
@@ -2,8 +2,6 @@ package main
 
 import (
 	"bufio"
-	"bytes"
-	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -46,12 +44,102 @@ func usage(errorMessage string) {
 	fmt.Fprintln(os.Stderr, listCmdMessage)
 }
 
+// checkMethod returns an error if the passed method doesn't identify a valid
+// registered command which is one of the usable types.
+func checkMethod(method string) error {
+	usageFlags, err := btcjson.MethodUsageFlags(method)
+	if err != nil {
+		return fmt.Errorf("Unrecognized command '%s'", method)
+	}
+	if usageFlags&unusableFlags != 0 {
+		return fmt.Errorf("The '%s' command can only be used via "+
+			"websockets", method)
+	}
+	return nil
+}
+
+// newCmd creates the command for the passed method from the passed parameters.
+// The error describes why the parameters don't fit the command in a form
+// suitable to show along with the usage of the command.
+func newCmd(method string, params []interface{}) (interface{}, error) {
+	cmd, err := btcjson.NewCmd(method, params...)
+	if err != nil {
+		// Show the error along with its error code when it's a
+		// btcjson.Error as it reallistcally will always be since the
+		// NewCmd function is only supposed to return errors of that
+		// type.
+		if jerr, ok := err.(btcjson.Error); ok {
+			return nil, fmt.Errorf("%s command: %v (code: %s)",
+				method, err, jerr.ErrorCode)
+		}
+
+		// The error is not a btcjson.Error and this really should not
+		// happen.  Nevertheless, fallback to just showing the error
+		// if it should happen due to a bug in the package.
+		return nil, fmt.Errorf("%s command: %v", method, err)
+	}
+	return cmd, nil
+}
+
+// sendCmd sends the passed command to the RPC server described in the passed
+// config struct and returns the result.
+func sendCmd(cmd interface{}, cfg *config) ([]byte, error) {
+	// Marshal the command into a JSON-RPC byte slice in preparation for
+	// sending it to the RPC server.
+	marshalledJSON, err := btcjson.MarshalCmd(btcjson.RpcVersion1, 1, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the JSON-RPC request to the server using the user-specified
+	// connection configuration.
+	return sendPostRequest(marshalledJSON, cfg)
+}
+
 func main() {
 	cfg, args, err := loadConfig()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "Failed to load config:", err)
 		os.Exit(1)
 	}
+	if cfg.Batch != "" {
+		if len(args) > 0 {
+			usage("No command may be specified along with the " +
+				"batch option")
+			os.Exit(1)
+		}
+		if cfg.Interactive || cfg.Watch != 0 {
+			usage("The batch option may not be used along with " +
+				"the interactive or watch options")
+			os.Exit(1)
+		}
+		success, err := runBatch(cfg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if !success {
+			os.Exit(1)
+		}
+		return
+	}
+	if cfg.Interactive {
+		if len(args) > 0 {
+			usage("No command may be specified along with the " +
+				"interactive option")
+			os.Exit(1)
+		}
+		if cfg.Watch != 0 {
+			usage("The watch option may not be used along with " +
+				"the interactive option")
+			os.Exit(1)
+		}
+		if err := runShell(cfg); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 	if len(args) < 1 {
 		usage("No command specified")
 		os.Exit(1)
@@ -60,15 +148,8 @@ func main() {
 	// Ensure the specified method identifies a valid registered command and
 	// is one of the usable types.
 	method := args[0]
-	usageFlags, err := btcjson.MethodUsageFlags(method)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Unrecognized command '%s'\n", method)
-		fmt.Fprintln(os.Stderr, listCmdMessage)
-		os.Exit(1)
-	}
-	if usageFlags&unusableFlags != 0 {
-		fmt.Fprintf(os.Stderr, "The '%s' command can only be used via "+
-			"websockets\n", method)
+	if err := checkMethod(method); err != nil {
+		fmt.Fprintln(os.Stderr, err)
 		fmt.Fprintln(os.Stderr, listCmdMessage)
 		os.Exit(1)
 	}
@@ -105,64 +186,27 @@ func main() {
 
 	// Attempt to create the appropriate command using the arguments
 	// provided by the user.
-	cmd, err := btcjson.NewCmd(method, params...)
+	cmd, err := newCmd(method, params)
 	if err != nil {
-		// Show the error along with its error code when it's a
-		// btcjson.Error as it reallistcally will always be since the
-		// NewCmd function is only supposed to return errors of that
-		// type.
-		if jerr, ok := err.(btcjson.Error); ok {
-			fmt.Fprintf(os.Stderr, "%s command: %v (code: %s)\n",
-				method, err, jerr.ErrorCode)
-			commandUsage(method)
-			os.Exit(1)
-		}
-
-		// The error is not a btcjson.Error and this really should not
-		// happen.  Nevertheless, fallback to just showing the error
-		// if it should happen due to a bug in the package.
-		fmt.Fprintf(os.Stderr, "%s command: %v\n", method, err)
+		fmt.Fprintln(os.Stderr, err)
 		commandUsage(method)
 		os.Exit(1)
 	}
 
-	// Marshal the command into a JSON-RPC byte slice in preparation for
-	// sending it to the RPC server.
-	marshalledJSON, err := btcjson.MarshalCmd(btcjson.RpcVersion1, 1, cmd)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	// Run the command repeatedly when watching it.
+	if cfg.Watch != 0 {
+		watch(strings.Join(args, " "), cmd, cfg)
+		return
 	}
 
-	// Send the JSON-RPC request to the server using the user-specified
-	// connection configuration.
-	result, err := sendPostRequest(marshalledJSON, cfg)
+	// Send the command and display the result.
+	result, err := sendCmd(cmd, cfg)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
-
-	// Choose how to display the result based on its type.
-	strResult := string(result)
-	if strings.HasPrefix(strResult, "{") || strings.HasPrefix(strResult, "[") {
-		var dst bytes.Buffer
-		if err := json.Indent(&dst, result, "", "  "); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to format result: %v",
-				err)
-			os.Exit(1)
-		}
-		fmt.Println(dst.String())
-
-	} else if strings.HasPrefix(strResult, `"`) {
-		var str string
-		if err := json.Unmarshal(result, &str); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to unmarshal result: %v",
-				err)
-			os.Exit(1)
-		}
-		fmt.Println(str)
-
-	} else if strResult != "null" {
-		fmt.Println(strResult)
+	if err := printResult(os.Stdout, result, cfg); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 }
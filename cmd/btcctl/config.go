@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/btcutil/v2"
@@ -31,6 +32,7 @@ var (
 	btcctlHomeDir         = btcutil.AppDataDir("btcctl", false)
 	btcwalletHomeDir      = btcutil.AppDataDir("btcwallet", false)
 	defaultConfigFile     = filepath.Join(btcctlHomeDir, "btcctl.conf")
+	defaultFormat         = formatJSON
 	defaultRPCServer      = "localhost"
 	defaultRPCCertFile    = filepath.Join(btcdHomeDir, "rpc.cert")
 	defaultWalletCertFile = filepath.Join(btcwalletHomeDir, "rpc.cert")
@@ -93,24 +95,30 @@ func listCommands() {
 //
 // See loadConfig for details on the configuration load process.
 type config struct {
-	ConfigFile     string `short:"C" long:"configfile" description:"Path to configuration file"`
-	ListCommands   bool   `short:"l" long:"listcommands" description:"List all of the supported commands and exit"`
-	NoTLS          bool   `long:"notls" description:"Disable TLS"`
-	Proxy          string `long:"proxy" description:"Connect via SOCKS5 proxy (eg. 127.0.0.1:9050)"`
-	ProxyPass      string `long:"proxypass" default-mask:"-" description:"Password for proxy server"`
-	ProxyUser      string `long:"proxyuser" description:"Username for proxy server"`
-	RegressionTest bool   `long:"regtest" description:"Connect to the regression test network"`
-	RPCCert        string `short:"c" long:"rpccert" description:"RPC server certificate chain for validation"`
-	RPCPassword    string `short:"P" long:"rpcpass" default-mask:"-" description:"RPC password"`
-	RPCServer      string `short:"s" long:"rpcserver" description:"RPC server to connect to"`
-	RPCUser        string `short:"u" long:"rpcuser" description:"RPC username"`
-	SimNet         bool   `long:"simnet" description:"Connect to the simulation test network"`
-	TLSSkipVerify  bool   `long:"skipverify" description:"Do not verify tls certificates (not recommended!)"`
-	TestNet3       bool   `long:"testnet" description:"Connect to testnet (version 3)"`
-	TestNet4       bool   `long:"testnet4" description:"Connect to testnet (version 4)"`
-	SigNet         bool   `long:"signet" description:"Connect to signet"`
-	ShowVersion    bool   `short:"V" long:"version" description:"Display version information and exit"`
-	Wallet         bool   `long:"wallet" description:"Connect to wallet"`
+	Batch          string        `long:"batch" description:"Run the commands of the passed file, one per line, in batched requests and write the result of each as a line of JSON -- use - to read the commands from standard input"`
+	ConfigFile     string        `short:"C" long:"configfile" description:"Path to configuration file"`
+	Field          string        `long:"field" description:"Only show the value at the dot separated path in the result, such as blocks or vout.0.value -- a name applied to an array selects the member of each of its elements"`
+	Format         string        `long:"format" description:"Format of the result {json, table, csv}"`
+	Interactive    bool          `short:"i" long:"interactive" description:"Start an interactive shell which reads commands from standard input -- offers completion of the commands and their parameters with the tab key and keeps a history of the commands"`
+	ListCommands   bool          `short:"l" long:"listcommands" description:"List all of the supported commands and exit"`
+	NoTLS          bool          `long:"notls" description:"Disable TLS"`
+	Profile        string        `long:"profile" description:"Use the options of the named [profile <name>] section of the configuration file, which override those outside of any profile"`
+	Proxy          string        `long:"proxy" description:"Connect via SOCKS5 proxy (eg. 127.0.0.1:9050)"`
+	ProxyPass      string        `long:"proxypass" default-mask:"-" description:"Password for proxy server"`
+	ProxyUser      string        `long:"proxyuser" description:"Username for proxy server"`
+	RegressionTest bool          `long:"regtest" description:"Connect to the regression test network"`
+	RPCCert        string        `short:"c" long:"rpccert" description:"RPC server certificate chain for validation"`
+	RPCPassword    string        `short:"P" long:"rpcpass" default-mask:"-" description:"RPC password"`
+	RPCServer      string        `short:"s" long:"rpcserver" description:"RPC server to connect to"`
+	RPCUser        string        `short:"u" long:"rpcuser" description:"RPC username"`
+	SimNet         bool          `long:"simnet" description:"Connect to the simulation test network"`
+	TLSSkipVerify  bool          `long:"skipverify" description:"Do not verify tls certificates (not recommended!)"`
+	TestNet3       bool          `long:"testnet" description:"Connect to testnet (version 3)"`
+	TestNet4       bool          `long:"testnet4" description:"Connect to testnet (version 4)"`
+	Watch          time.Duration `long:"watch" description:"Run the command repeatedly at the passed interval, such as 5s, and show the changes to its result until interrupted"`
+	SigNet         bool          `long:"signet" description:"Connect to signet"`
+	ShowVersion    bool          `short:"V" long:"version" description:"Display version information and exit"`
+	Wallet         bool          `long:"wallet" description:"Connect to wallet"`
 }
 
 // normalizeAddress returns addr with the passed default port appended if
@@ -193,6 +201,7 @@ func loadConfig() (*config, []string, error) {
 	// Default config.
 	cfg := config{
 		ConfigFile: defaultConfigFile,
+		Format:     defaultFormat,
 		RPCServer:  defaultRPCServer,
 		RPCCert:    defaultRPCCertFile,
 	}
@@ -249,7 +258,7 @@ func loadConfig() (*config, []string, error) {
 
 	// Load additional config from file.
 	parser := flags.NewParser(&cfg, flags.Default)
-	err = flags.NewIniParser(parser).ParseFile(preCfg.ConfigFile)
+	err = parseConfigFile(parser, &cfg, preCfg.ConfigFile, preCfg.Profile)
 	if err != nil {
 		if _, ok := err.(*os.PathError); !ok {
 			fmt.Fprintf(os.Stderr, "Error parsing config file: %v\n",
@@ -302,6 +311,38 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// The watch interval must not be negative.
+	if cfg.Watch < 0 {
+		str := "%s: The watch option may not be negative -- parsed [%v]"
+		err := fmt.Errorf(str, "loadConfig", cfg.Watch)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Batch results are always written as lines of JSON.
+	if cfg.Batch != "" && cfg.Format != formatJSON {
+		str := "%s: The format option may not be used along with " +
+			"the batch option -- batch results are always JSON"
+		err := fmt.Errorf(str, "loadConfig")
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Validate the format of the result.
+	switch cfg.Format {
+	case formatJSON, formatTable, formatCSV:
+	default:
+		str := "%s: The specified format [%v] is invalid -- " +
+			"supported formats are %s, %s and %s"
+		err := fmt.Errorf(str, "loadConfig", cfg.Format, formatJSON,
+			formatTable, formatCSV)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Override the RPC certificate if the --wallet flag was specified and
 	// the user did not specify one.
 	if cfg.Wallet && cfg.RPCCert == defaultRPCCertFile {
@@ -321,6 +362,103 @@ func loadConfig() (*config, []string, error) {
 	return &cfg, remainingArgs, nil
 }
 
+// splitProfiles splits the passed contents of a configuration file into the
+// options outside of any profile and the options of each of the profiles,
+// keyed by their names.  A profile starts with a [profile <name>] section
+// header and ends at the next section header.  The lines of the profiles are
+// left empty in the returned options outside of them so the line numbers of
+// any errors in the latter remain accurate.
+func splitProfiles(content string) (string, map[string]string, error) {
+	var base strings.Builder
+	profiles := make(map[string]string)
+	profile := ""
+	for _, line := range strings.SplitAfter(content, "\n") {
+		// Content ending with a newline has an empty final line.
+		if line == "" {
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			section := strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+			profile = ""
+			if fields := strings.Fields(section); len(fields) > 0 &&
+				fields[0] == "profile" {
+
+				if len(fields) != 2 {
+					return "", nil, fmt.Errorf("invalid "+
+						"profile section [%s] -- use "+
+						"[profile <name>]", section)
+				}
+				profile = fields[1]
+				if _, ok := profiles[profile]; ok {
+					return "", nil, fmt.Errorf("profile "+
+						"%s is defined more than once",
+						profile)
+				}
+				profiles[profile] = ""
+				base.WriteString("\n")
+				continue
+			}
+		}
+
+		if profile != "" {
+			profiles[profile] += line
+			base.WriteString("\n")
+			continue
+		}
+		base.WriteString(line)
+	}
+
+	return base.String(), profiles, nil
+}
+
+// parseConfigFile parses the options of the passed configuration file outside
+// of any profile into the passed config followed by those of the passed
+// profile, so they override the former.  The profile named by the profile
+// option of the file is used when no profile is passed.  It is an error for the
+// profile to be missing from the file.
+func parseConfigFile(parser *flags.Parser, cfg *config, path,
+	profile string) error {
+
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) && profile != "" {
+		return fmt.Errorf("profile %s is not defined since the "+
+			"configuration file %s does not exist", profile, path)
+	}
+	if err != nil {
+		return err
+	}
+	base, profiles, err := splitProfiles(string(content))
+	if err != nil {
+		return err
+	}
+
+	iniParser := flags.NewIniParser(parser)
+	err = iniParser.Parse(strings.NewReader(base))
+	if err != nil {
+		return err
+	}
+
+	if profile == "" {
+		profile = cfg.Profile
+	}
+	if profile == "" {
+		return nil
+	}
+	options, ok := profiles[profile]
+	if !ok {
+		return fmt.Errorf("profile %s is not defined in %s", profile,
+			path)
+	}
+	err = iniParser.Parse(strings.NewReader("[Application Options]\n" +
+		options))
+	if err != nil {
+		return fmt.Errorf("profile %s: %v", profile, err)
+	}
+	return nil
+}
+
 // createDefaultConfig creates a basic config file at the given destination path.
 // For this it tries to read the config file for the RPC server (either btcd or
 // btcwallet), and extract the RPC user and password from it.
@@ -0,0 +1,138 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	flags "github.com/jessevdk/go-flags"
+)
+
+// testProfilesConfig is a configuration file with options outside of any
+// profile, a default profile and two named profiles.
+const testProfilesConfig = `rpcuser=user
+rpcpass=pass
+profile=local
+
+[profile local]
+rpcserver=localhost:8334
+
+[profile remote]
+rpcserver=remote.example.com:8334
+rpcuser=remoteuser
+`
+
+// TestSplitProfiles ensures the options of the profiles are split from the
+// options outside of them while keeping the line numbers of the latter.
+func TestSplitProfiles(t *testing.T) {
+	base, profiles, err := splitProfiles(testProfilesConfig)
+	if err != nil {
+		t.Fatalf("splitProfiles: unexpected error: %v", err)
+	}
+
+	wantBase := "rpcuser=user\nrpcpass=pass\nprofile=local\n\n\n\n\n\n\n\n"
+	if base != wantBase {
+		t.Fatalf("splitProfiles: got base %q, want %q", base, wantBase)
+	}
+	wantProfiles := map[string]string{
+		"local":  "rpcserver=localhost:8334\n\n",
+		"remote": "rpcserver=remote.example.com:8334\nrpcuser=remoteuser\n",
+	}
+	if len(profiles) != len(wantProfiles) {
+		t.Fatalf("splitProfiles: got %d profiles, want %d",
+			len(profiles), len(wantProfiles))
+	}
+	for name, want := range wantProfiles {
+		if profiles[name] != want {
+			t.Fatalf("splitProfiles: got profile %s %q, want %q",
+				name, profiles[name], want)
+		}
+	}
+
+	// Ensure invalid and duplicate profile sections are rejected.
+	for _, content := range []string{
+		"[profile]\n",
+		"[profile a b]\n",
+		"[profile a]\n[profile a]\n",
+	} {
+		if _, _, err := splitProfiles(content); err == nil {
+			t.Fatalf("splitProfiles(%q): expected error", content)
+		}
+	}
+}
+
+// TestParseConfigFile ensures the options of the selected profile override
+// those outside of any profile.
+func TestParseConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "btcctl.conf")
+	err := os.WriteFile(path, []byte(testProfilesConfig), 0600)
+	if err != nil {
+		t.Fatalf("unable to write config file: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		profile    string
+		wantServer string
+		wantUser   string
+		err        bool
+	}{
+		{
+			name:       "default profile of the file",
+			wantServer: "localhost:8334",
+			wantUser:   "user",
+		},
+		{
+			name:       "passed profile",
+			profile:    "remote",
+			wantServer: "remote.example.com:8334",
+			wantUser:   "remoteuser",
+		},
+		{
+			name:    "undefined profile",
+			profile: "missing",
+			err:     true,
+		},
+	}
+
+	for _, test := range tests {
+		var cfg config
+		parser := flags.NewParser(&cfg, flags.None)
+		err := parseConfigFile(parser, &cfg, path, test.profile)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if cfg.RPCServer != test.wantServer {
+			t.Errorf("%s: got rpcserver %q, want %q", test.name,
+				cfg.RPCServer, test.wantServer)
+		}
+		if cfg.RPCUser != test.wantUser {
+			t.Errorf("%s: got rpcuser %q, want %q", test.name,
+				cfg.RPCUser, test.wantUser)
+		}
+		if cfg.RPCPassword != "pass" {
+			t.Errorf("%s: got rpcpass %q, want %q", test.name,
+				cfg.RPCPassword, "pass")
+		}
+	}
+
+	// A profile can't be selected without a configuration file.
+	var cfg config
+	parser := flags.NewParser(&cfg, flags.None)
+	err = parseConfigFile(parser, &cfg,
+		filepath.Join(t.TempDir(), "missing.conf"), "remote")
+	if err == nil {
+		t.Fatal("parseConfigFile: expected error for missing file")
+	}
+}
@@ -0,0 +1,270 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// The formats the results of the commands can be written in.
+const (
+	formatJSON  = "json"
+	formatTable = "table"
+	formatCSV   = "csv"
+)
+
+// decodeResult decodes the passed JSON result of a command.  Numbers are kept
+// as they were sent by the server so amounts don't lose their precision.
+func decodeResult(result []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(result))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// encodeResult encodes the passed value decoded by decodeResult back to JSON.
+func encodeResult(v interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
+
+// extractField returns the value at the passed dot separated path in the
+// passed value.  Each element of the path selects the member of an object with
+// that name or the element of an array with that index.  An element which is
+// not an index applies the remainder of the path to each element of an array,
+// so vout.value selects the values of all of the outputs of a transaction.
+func extractField(v interface{}, path string) (interface{}, error) {
+	return extractPath(v, strings.Split(path, "."))
+}
+
+// extractPath returns the value at the passed path elements in the passed
+// value.  See extractField for details.
+func extractPath(v interface{}, elems []string) (interface{}, error) {
+	if len(elems) == 0 {
+		return v, nil
+	}
+
+	elem := elems[0]
+	switch v := v.(type) {
+	case map[string]interface{}:
+		member, ok := v[elem]
+		if !ok {
+			return nil, fmt.Errorf("no member named %q", elem)
+		}
+		return extractPath(member, elems[1:])
+
+	case []interface{}:
+		if idx, err := strconv.Atoi(elem); err == nil {
+			if idx < 0 || idx >= len(v) {
+				return nil, fmt.Errorf("index %d is out of "+
+					"range for an array of %d elements",
+					idx, len(v))
+			}
+			return extractPath(v[idx], elems[1:])
+		}
+
+		values := make([]interface{}, 0, len(v))
+		for _, e := range v {
+			value, err := extractPath(e, elems)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	}
+
+	return nil, fmt.Errorf("no member named %q in %s", elem, formatCell(v))
+}
+
+// formatCell returns the passed value as the content of a cell of a table.
+// Strings are shown without quotes and objects and arrays as compact JSON.
+func formatCell(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+
+	b, err := encodeResult(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// tabulate lays out the passed value as rows of cells.  An array of objects has
+// a row per object with a column per member name, an object has a row per
+// member with its name and value and an array of other values has a row per
+// value.  Any other value is a single cell, while null has no rows at all.
+//
+// The returned header names the columns of an array of objects.  It is nil
+// for the other values.
+func tabulate(v interface{}) ([]string, [][]string) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+
+	case map[string]interface{}:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		rows := make([][]string, 0, len(names))
+		for _, name := range names {
+			rows = append(rows, []string{name, formatCell(v[name])})
+		}
+		return nil, rows
+
+	case []interface{}:
+		// Collect the member names of the objects.  The array is laid
+		// out as a list of values unless all of its elements are
+		// objects.
+		seen := make(map[string]struct{})
+		var header []string
+		for _, e := range v {
+			obj, ok := e.(map[string]interface{})
+			if !ok {
+				header = nil
+				break
+			}
+			for name := range obj {
+				if _, ok := seen[name]; !ok {
+					seen[name] = struct{}{}
+					header = append(header, name)
+				}
+			}
+		}
+		sort.Strings(header)
+
+		rows := make([][]string, 0, len(v))
+		for _, e := range v {
+			if header == nil {
+				rows = append(rows, []string{formatCell(e)})
+				continue
+			}
+
+			obj := e.(map[string]interface{})
+			row := make([]string, 0, len(header))
+			for _, name := range header {
+				row = append(row, formatCell(obj[name]))
+			}
+			rows = append(rows, row)
+		}
+		return header, rows
+	}
+
+	return nil, [][]string{{formatCell(v)}}
+}
+
+// printTable writes the passed value to the passed writer as a table with
+// aligned columns.
+func printTable(w io.Writer, v interface{}) error {
+	header, rows := tabulate(v)
+	if header != nil {
+		rows = append([][]string{header}, rows...)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// printCSV writes the passed value to the passed writer as comma separated
+// values.
+func printCSV(w io.Writer, v interface{}) error {
+	header, rows := tabulate(v)
+	if header != nil {
+		rows = append([][]string{header}, rows...)
+	}
+
+	return csv.NewWriter(w).WriteAll(rows)
+}
+
+// printJSON writes the passed JSON result of a command to the passed writer.
+// Objects and arrays are indented and strings are shown without quotes.
+func printJSON(w io.Writer, result []byte) error {
+	// Choose how to display the result based on its type.
+	strResult := string(result)
+	if strings.HasPrefix(strResult, "{") || strings.HasPrefix(strResult, "[") {
+		var dst bytes.Buffer
+		if err := json.Indent(&dst, result, "", "  "); err != nil {
+			return fmt.Errorf("Failed to format result: %v", err)
+		}
+		fmt.Fprintln(w, dst.String())
+
+	} else if strings.HasPrefix(strResult, `"`) {
+		var str string
+		if err := json.Unmarshal(result, &str); err != nil {
+			return fmt.Errorf("Failed to unmarshal result: %v", err)
+		}
+		fmt.Fprintln(w, str)
+
+	} else if strResult != "null" {
+		fmt.Fprintln(w, strResult)
+	}
+	return nil
+}
+
+// printResult writes the passed result of a command to the passed writer in
+// the format selected by the passed config, limited to the field it selects, if
+// any.
+func printResult(w io.Writer, result []byte, cfg *config) error {
+	// Show the result as it was sent by the server when possible.
+	if cfg.Field == "" && cfg.Format == formatJSON {
+		return printJSON(w, result)
+	}
+
+	v, err := decodeResult(result)
+	if err != nil {
+		return fmt.Errorf("Failed to unmarshal result: %v", err)
+	}
+	if cfg.Field != "" {
+		v, err = extractField(v, cfg.Field)
+		if err != nil {
+			return fmt.Errorf("Failed to extract field %q: %v",
+				cfg.Field, err)
+		}
+	}
+
+	switch cfg.Format {
+	case formatTable:
+		return printTable(w, v)
+
+	case formatCSV:
+		return printCSV(w, v)
+	}
+
+	result, err = encodeResult(v)
+	if err != nil {
+		return fmt.Errorf("Failed to format result: %v", err)
+	}
+	return printJSON(w, result)
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestExtractField ensures values are selected from results by their dot
+// separated paths.
+func TestExtractField(t *testing.T) {
+	const result = `{"hash":"00ab","height":10,"vout":[` +
+		`{"value":1.5,"n":0},{"value":0.00000001,"n":1}]}`
+
+	tests := []struct {
+		name  string
+		field string
+		want  string
+		err   bool
+	}{
+		{name: "member", field: "hash", want: `"00ab"`},
+		{name: "number", field: "height", want: `10`},
+		{name: "index", field: "vout.1", want: `{"n":1,"value":0.00000001}`},
+		{name: "index member", field: "vout.0.value", want: `1.5`},
+		{name: "each element", field: "vout.value", want: `[1.5,0.00000001]`},
+		{name: "missing member", field: "size", err: true},
+		{name: "index out of range", field: "vout.2", err: true},
+		{name: "member of number", field: "height.value", err: true},
+	}
+
+	v, err := decodeResult([]byte(result))
+	if err != nil {
+		t.Fatalf("decodeResult: unexpected error: %v", err)
+	}
+	for _, test := range tests {
+		field, err := extractField(v, test.field)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		got, err := encodeResult(field)
+		if err != nil {
+			t.Errorf("%s: encodeResult: unexpected error: %v",
+				test.name, err)
+			continue
+		}
+		if string(got) != test.want {
+			t.Errorf("%s: got %s, want %s", test.name, got,
+				test.want)
+		}
+	}
+}
+
+// TestPrintResult ensures results are written in each of the formats.
+func TestPrintResult(t *testing.T) {
+	tests := []struct {
+		name   string
+		result string
+		format string
+		field  string
+		want   string
+	}{
+		{
+			name:   "json object",
+			result: `{"a":1,"b":"x"}`,
+			format: formatJSON,
+			want:   "{\n  \"a\": 1,\n  \"b\": \"x\"\n}\n",
+		},
+		{
+			name:   "json string",
+			result: `"hello"`,
+			format: formatJSON,
+			want:   "hello\n",
+		},
+		{
+			name:   "json null",
+			result: `null`,
+			format: formatJSON,
+			want:   "",
+		},
+		{
+			name:   "json field",
+			result: `{"a":{"b":"x"}}`,
+			format: formatJSON,
+			field:  "a.b",
+			want:   "x\n",
+		},
+		{
+			name:   "table object",
+			result: `{"bb":1,"a":"x"}`,
+			format: formatTable,
+			want:   "a   x\nbb  1\n",
+		},
+		{
+			name:   "table array of objects",
+			result: `[{"n":0,"value":1.5},{"n":1,"addr":"y"}]`,
+			format: formatTable,
+			want: "addr  n  value\n" +
+				"      0  1.5\n" +
+				"y     1  \n",
+		},
+		{
+			name:   "table array of values",
+			result: `["a",[1,2]]`,
+			format: formatTable,
+			want:   "a\n[1,2]\n",
+		},
+		{
+			name:   "csv array of objects",
+			result: `[{"n":0,"value":1.5},{"n":1,"value":"a,b"}]`,
+			format: formatCSV,
+			want:   "n,value\n0,1.5\n1,\"a,b\"\n",
+		},
+		{
+			name:   "csv field",
+			result: `{"vout":[{"value":1.5},{"value":2}]}`,
+			format: formatCSV,
+			field:  "vout.value",
+			want:   "1.5\n2\n",
+		},
+	}
+
+	for _, test := range tests {
+		var b bytes.Buffer
+		cfg := &config{Format: test.format, Field: test.field}
+		if err := printResult(&b, []byte(test.result), cfg); err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if b.String() != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, b.String(),
+				test.want)
+		}
+	}
+}
@@ -62,11 +62,10 @@ func newHTTPClient(cfg *config) (*http.Client, error) {
 	return &client, nil
 }
 
-// sendPostRequest sends the marshalled JSON-RPC command using HTTP-POST mode
-// to the server described in the passed config struct.  It also attempts to
-// unmarshal the response as a JSON-RPC response and returns either the result
-// field or the error field depending on whether or not there is an error.
-func sendPostRequest(marshalledJSON []byte, cfg *config) ([]byte, error) {
+// postRequest sends the marshalled JSON-RPC request using HTTP-POST mode to the
+// server described in the passed config struct and returns the body of the
+// response.
+func postRequest(marshalledJSON []byte, cfg *config) ([]byte, error) {
 	// Generate a request to the configured RPC server.
 	protocol := "http"
 	if !cfg.NoTLS {
@@ -116,6 +115,19 @@ func sendPostRequest(marshalledJSON []byte, cfg *config) ([]byte, error) {
 		return nil, fmt.Errorf("%s", respBytes)
 	}
 
+	return respBytes, nil
+}
+
+// sendPostRequest sends the marshalled JSON-RPC command using HTTP-POST mode
+// to the server described in the passed config struct.  It also attempts to
+// unmarshal the response as a JSON-RPC response and returns either the result
+// field or the error field depending on whether or not there is an error.
+func sendPostRequest(marshalledJSON []byte, cfg *config) ([]byte, error) {
+	respBytes, err := postRequest(marshalledJSON, cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	// Unmarshal the response.
 	var resp btcjson.Response
 	if err := json.Unmarshal(respBytes, &resp); err != nil {
@@ -0,0 +1,384 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// Control characters which are handled by the line editor.
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyCtrlH     = 8
+	keyTab       = 9
+	keyLineFeed  = 10
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyEnter     = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyBackspace = 127
+)
+
+const (
+	// maxEditorHistory is the maximum number of lines kept in the history
+	// of a line editor.
+	maxEditorHistory = 1000
+
+	// completionWidth is the width the list of completion candidates is
+	// laid out in.
+	completionWidth = 80
+)
+
+// errInterrupted is returned by readLine when the user discards the line with
+// ctrl+c.
+var errInterrupted = errors.New("interrupted")
+
+// completer returns the candidates to complete the word which ends at the
+// passed position of the passed line along with the position the word starts
+// at.  When there are no candidates, the returned hint, if any, is shown to
+// the user instead.
+type completer func(line []rune, pos int) (start int, candidates []string,
+	hint string)
+
+// lineEditor reads lines from a terminal which it puts into raw mode while a
+// line is typed.  This allows it to offer editing of the line, navigation of
+// the previously entered lines and tab completion.
+type lineEditor struct {
+	fd       int
+	in       *bufio.Reader
+	out      io.Writer
+	complete completer
+	history  []string
+}
+
+// newLineEditor returns a line editor for the terminal connected to the passed
+// file descriptor which reads keys from the passed reader and echoes the line
+// to the passed writer.
+func newLineEditor(fd int, in io.Reader, out io.Writer,
+	complete completer) *lineEditor {
+
+	return &lineEditor{
+		fd:       fd,
+		in:       bufio.NewReader(in),
+		out:      out,
+		complete: complete,
+	}
+}
+
+// addHistory appends the passed line to the history unless it is empty or the
+// same as the most recent line.  The oldest lines are dropped once the history
+// holds maxEditorHistory lines.
+func (e *lineEditor) addHistory(line string) {
+	if line == "" {
+		return
+	}
+	if n := len(e.history); n > 0 && e.history[n-1] == line {
+		return
+	}
+	e.history = append(e.history, line)
+	if len(e.history) > maxEditorHistory {
+		e.history = e.history[len(e.history)-maxEditorHistory:]
+	}
+}
+
+// refresh redraws the passed prompt and line and moves the cursor to the
+// passed position in the line.
+func (e *lineEditor) refresh(prompt string, line []rune, pos int) {
+	var b strings.Builder
+	b.WriteString("\r")
+	b.WriteString(prompt)
+	b.WriteString(string(line))
+	b.WriteString("\x1b[K")
+	if n := len(line) - pos; n > 0 {
+		fmt.Fprintf(&b, "\x1b[%dD", n)
+	}
+	io.WriteString(e.out, b.String())
+}
+
+// printAbove prints the passed text on the lines below the line being edited,
+// after which the line is redrawn below it.
+func (e *lineEditor) printAbove(text string) {
+	text = strings.ReplaceAll(text, "\n", "\r\n")
+	fmt.Fprintf(e.out, "\r\n%s\r\n", text)
+}
+
+// formatCandidates lays out the passed completion candidates in columns.
+func formatCandidates(candidates []string) string {
+	width := 0
+	for _, c := range candidates {
+		if len(c) > width {
+			width = len(c)
+		}
+	}
+	width += 2
+	columns := completionWidth / width
+	if columns < 1 {
+		columns = 1
+	}
+
+	var b strings.Builder
+	for i, c := range candidates {
+		if i > 0 && i%columns == 0 {
+			b.WriteString("\n")
+		}
+		if (i+1)%columns == 0 || i == len(candidates)-1 {
+			b.WriteString(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%-*s", width, c)
+	}
+	return b.String()
+}
+
+// commonPrefix returns the longest prefix shared by all of the passed strings.
+func commonPrefix(strs []string) string {
+	prefix := strs[0]
+	for _, s := range strs[1:] {
+		for !strings.HasPrefix(s, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// completeLine completes the word which ends at the passed position of the
+// passed line and returns the resulting line and position.  A word with a
+// single candidate is replaced by it, followed by a space, and a word with
+// several candidates is extended to their common prefix.  The candidates are
+// listed when the word can't be extended any further.
+func (e *lineEditor) completeLine(line []rune, pos int) ([]rune, int) {
+	if e.complete == nil {
+		return line, pos
+	}
+
+	start, candidates, hint := e.complete(line, pos)
+	switch len(candidates) {
+	case 0:
+		if hint != "" {
+			e.printAbove(hint)
+		}
+		return line, pos
+
+	case 1:
+		return replaceWord(line, start, pos, candidates[0]+" ")
+	}
+
+	prefix := commonPrefix(candidates)
+	if len([]rune(prefix)) > pos-start {
+		return replaceWord(line, start, pos, prefix)
+	}
+	e.printAbove(formatCandidates(candidates))
+	return line, pos
+}
+
+// replaceWord replaces the runes of the passed line between the passed start
+// and end positions with the passed word and returns the resulting line along
+// with the position following the word.
+func replaceWord(line []rune, start, end int, word string) ([]rune, int) {
+	newLine := make([]rune, 0, len(line)+len(word))
+	newLine = append(newLine, line[:start]...)
+	newLine = append(newLine, []rune(word)...)
+	pos := len(newLine)
+	newLine = append(newLine, line[end:]...)
+	return newLine, pos
+}
+
+// readEscape reads the remainder of an escape sequence following the escape
+// character and returns its final character along with its numeric parameter,
+// if any.  Only the CSI and SS3 sequences sent by the cursor and editing keys
+// are recognized.  Zero is returned for any other sequence.
+func (e *lineEditor) readEscape() (rune, string, error) {
+	r, _, err := e.in.ReadRune()
+	if err != nil {
+		return 0, "", err
+	}
+	if r != '[' && r != 'O' {
+		return 0, "", nil
+	}
+
+	var param strings.Builder
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return 0, "", err
+		}
+		if r >= '0' && r <= '9' || r == ';' {
+			param.WriteRune(r)
+			continue
+		}
+		return r, param.String(), nil
+	}
+}
+
+// readLine shows the passed prompt and reads a line typed by the user, who may
+// edit it, recall earlier lines from the history and complete words with the
+// tab key before entering it.  io.EOF is returned when the user presses ctrl+d
+// on an empty line and errInterrupted when the user presses ctrl+c.
+//
+// The line is not added to the history.  Use addHistory for that.
+func (e *lineEditor) readLine(prompt string) (string, error) {
+	state, err := makeRaw(e.fd)
+	if err != nil {
+		return "", err
+	}
+	defer restoreTerminal(e.fd, state)
+
+	var line, edited []rune
+	pos := 0
+	historyIdx := len(e.history)
+
+	// recall replaces the line with the entry of the history with the
+	// passed index, or the line being edited before browsing the history
+	// when the index is past the last entry.
+	recall := func(idx int) {
+		if historyIdx == len(e.history) {
+			edited = line
+		}
+		historyIdx = idx
+		if idx == len(e.history) {
+			line = edited
+		} else {
+			line = []rune(e.history[idx])
+		}
+		pos = len(line)
+	}
+
+	e.refresh(prompt, line, pos)
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch r {
+		case keyEnter, keyLineFeed:
+			io.WriteString(e.out, "\r\n")
+			return string(line), nil
+
+		case keyCtrlC:
+			io.WriteString(e.out, "^C\r\n")
+			return "", errInterrupted
+
+		case keyCtrlD:
+			if len(line) == 0 {
+				io.WriteString(e.out, "\r\n")
+				return "", io.EOF
+			}
+			if pos < len(line) {
+				line = append(line[:pos:pos], line[pos+1:]...)
+			}
+
+		case keyBackspace, keyCtrlH:
+			if pos > 0 {
+				line = append(line[:pos-1:pos-1], line[pos:]...)
+				pos--
+			}
+
+		case keyTab:
+			line, pos = e.completeLine(line, pos)
+
+		case keyCtrlA:
+			pos = 0
+
+		case keyCtrlE:
+			pos = len(line)
+
+		case keyCtrlB:
+			if pos > 0 {
+				pos--
+			}
+
+		case keyCtrlF:
+			if pos < len(line) {
+				pos++
+			}
+
+		case keyCtrlK:
+			line = line[:pos]
+
+		case keyCtrlU:
+			line = append([]rune(nil), line[pos:]...)
+			pos = 0
+
+		case keyCtrlW:
+			// Delete the word before the cursor along with the
+			// spaces following it.
+			start := pos
+			for start > 0 && line[start-1] == ' ' {
+				start--
+			}
+			for start > 0 && line[start-1] != ' ' {
+				start--
+			}
+			line = append(line[:start:start], line[pos:]...)
+			pos = start
+
+		case keyCtrlL:
+			io.WriteString(e.out, "\x1b[H\x1b[2J")
+
+		case keyCtrlP:
+			if historyIdx > 0 {
+				recall(historyIdx - 1)
+			}
+
+		case keyCtrlN:
+			if historyIdx < len(e.history) {
+				recall(historyIdx + 1)
+			}
+
+		case keyEscape:
+			key, param, err := e.readEscape()
+			if err != nil {
+				return "", err
+			}
+			switch {
+			case key == 'A' && historyIdx > 0:
+				recall(historyIdx - 1)
+
+			case key == 'B' && historyIdx < len(e.history):
+				recall(historyIdx + 1)
+
+			case key == 'C' && pos < len(line):
+				pos++
+
+			case key == 'D' && pos > 0:
+				pos--
+
+			case key == 'H', key == '~' && (param == "1" || param == "7"):
+				pos = 0
+
+			case key == 'F', key == '~' && (param == "4" || param == "8"):
+				pos = len(line)
+
+			case key == '~' && param == "3" && pos < len(line):
+				line = append(line[:pos:pos], line[pos+1:]...)
+			}
+
+		default:
+			if !unicode.IsPrint(r) {
+				break
+			}
+			line = append(line[:pos:pos], append([]rune{r},
+				line[pos:]...)...)
+			pos++
+		}
+
+		e.refresh(prompt, line, pos)
+	}
+}
@@ -0,0 +1,442 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcjson"
+)
+
+const (
+	// shellWelcomeMessage is shown when the interactive shell starts on a
+	// terminal.
+	shellWelcomeMessage = "Type 'help' to list the commands, 'help " +
+		"<command>' to show the usage of a command and 'exit' to quit."
+
+	// shellHelpMessage lists the commands of the shell itself.
+	shellHelpMessage = `Shell Commands:
+exit
+help ("command")
+history
+quit`
+)
+
+var (
+	// defaultHistoryFile is the file the lines entered into the
+	// interactive shell are kept in between sessions.
+	defaultHistoryFile = filepath.Join(btcctlHomeDir, "history")
+
+	// shellCommands are the commands handled by the interactive shell
+	// itself rather than sent to the RPC server.
+	shellCommands = []string{"exit", "help", "history", "quit"}
+
+	// sensitiveMethods are the commands whose parameters include
+	// passphrases or private keys.  They are kept out of the history file.
+	sensitiveMethods = map[string]struct{}{
+		"encryptwallet":          {},
+		"importprivkey":          {},
+		"signrawtransaction":     {},
+		"walletpassphrase":       {},
+		"walletpassphrasechange": {},
+	}
+)
+
+// shell is an interactive shell which reads commands from standard input,
+// sends them to the RPC server and prints the results.  When standard input is
+// a terminal, the lines are read with a line editor which offers completion of
+// the commands and their parameters along with a persistent history.
+type shell struct {
+	cfg     *config
+	methods []string
+
+	// editor reads the lines when standard input is a terminal, while in
+	// reads them otherwise.
+	editor *lineEditor
+	in     *bufio.Reader
+}
+
+// usableMethods returns the sorted methods of the registered commands which
+// are usable from this utility.
+func usableMethods() []string {
+	var methods []string
+	for _, method := range btcjson.RegisteredCmdMethods() {
+		if checkMethod(method) == nil {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
+
+// filterPrefix returns the passed strings which start with the passed prefix.
+func filterPrefix(strs []string, prefix string) []string {
+	var matches []string
+	for _, s := range strs {
+		if strings.HasPrefix(s, prefix) {
+			matches = append(matches, s)
+		}
+	}
+	return matches
+}
+
+// splitArgs splits the passed line into arguments at unquoted whitespace.
+// Arguments may be quoted with single quotes, which keep their content as is,
+// or double quotes, in which a backslash escapes a double quote or backslash.
+// A backslash outside of quotes escapes the following character.  This allows
+// passing JSON parameters with spaces, such as '{"a": 1}'.
+func splitArgs(line string) ([]string, error) {
+	var (
+		args    []string
+		arg     strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range line {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+				continue
+			}
+			arg.WriteRune(r)
+
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				arg.WriteRune(r)
+			}
+
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+
+		case r == '\\':
+			escaped = true
+			inArg = true
+
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, errors.New("line ends with an escape character")
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+
+	return args, nil
+}
+
+// complete returns the candidates to complete the word which ends at the
+// passed position of the passed line.  The first word is completed with the
+// commands, including those of the shell, and the following ones with the
+// values of boolean parameters.  The usage of the command and the parameter
+// being typed is returned as a hint for other parameters.
+//
+// This is a completer for the line editor.
+func (sh *shell) complete(line []rune, pos int) (int, []string, string) {
+	text := string(line[:pos])
+	start := strings.LastIndexAny(text, " \t") + 1
+	words := strings.Fields(text[:start])
+	prefix := text[start:]
+	runeStart := len([]rune(text[:start]))
+
+	switch {
+	case len(words) == 0:
+		candidates := filterPrefix(shellCommands, prefix)
+		candidates = append(candidates, filterPrefix(sh.methods, prefix)...)
+		return runeStart, candidates, ""
+
+	case words[0] == "help" && len(words) == 1:
+		return runeStart, filterPrefix(sh.methods, prefix), ""
+	}
+
+	method := words[0]
+	params, err := btcjson.MethodParams(method)
+	if err != nil || checkMethod(method) != nil {
+		return runeStart, nil, ""
+	}
+	usage, _ := btcjson.MethodUsageText(method)
+
+	idx := len(words) - 1
+	if idx >= len(params) {
+		return runeStart, nil, fmt.Sprintf("%s\n%s takes no more "+
+			"parameters", usage, method)
+	}
+	param := params[idx]
+	if param.Kind == reflect.Bool {
+		candidates := filterPrefix([]string{"false", "true"}, prefix)
+		return runeStart, candidates, ""
+	}
+	return runeStart, nil, fmt.Sprintf("%s\nparameter %d: %s", usage,
+		idx+1, param.Usage)
+}
+
+// help shows the usage of the passed command along with its parameters, or
+// lists all of the commands when none is passed.
+func (sh *shell) help(args []string) {
+	if len(args) == 0 {
+		listCommands()
+		fmt.Println(shellHelpMessage)
+		return
+	}
+
+	method := args[0]
+	if err := checkMethod(method); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	usage, err := btcjson.MethodUsageText(method)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	params, err := btcjson.MethodParams(method)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	fmt.Println("Usage:")
+	fmt.Printf("  %s\n", usage)
+	if len(params) == 0 {
+		return
+	}
+	fmt.Println("Parameters:")
+	for i, param := range params {
+		required := "required"
+		if param.Optional {
+			required = "optional"
+		}
+		fmt.Printf("  %d. %s (%s)\n", i+1, param.Usage, required)
+	}
+}
+
+// history lists the lines entered into the shell.
+func (sh *shell) history() {
+	if sh.editor == nil {
+		return
+	}
+	for i, line := range sh.editor.history {
+		fmt.Printf("%5d  %s\n", i+1, line)
+	}
+}
+
+// execute runs the command with the passed arguments and returns whether or
+// not the shell should exit afterwards.  Errors are shown to the user, but
+// don't end the shell.
+func (sh *shell) execute(args []string) bool {
+	method := args[0]
+	switch method {
+	case "exit", "quit":
+		return true
+
+	case "help":
+		sh.help(args[1:])
+		return false
+
+	case "history":
+		sh.history()
+		return false
+	}
+
+	if err := checkMethod(method); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, "Type 'help' to list the commands")
+		return false
+	}
+
+	params := make([]interface{}, 0, len(args[1:]))
+	for _, arg := range args[1:] {
+		params = append(params, arg)
+	}
+	cmd, err := newCmd(method, params)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		commandUsage(method)
+		return false
+	}
+
+	result, err := sendCmd(cmd, sh.cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return false
+	}
+	if err := printResult(os.Stdout, result, sh.cfg); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	return false
+}
+
+// readLine reads the next line with the line editor, or from standard input
+// when it isn't a terminal.
+func (sh *shell) readLine(prompt string) (string, error) {
+	if sh.editor != nil {
+		return sh.editor.readLine(prompt)
+	}
+
+	line, err := sh.in.ReadString('\n')
+	if err == io.EOF && len(line) > 0 {
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+// loadHistory returns the lines of the passed history file.  A missing file is
+// an empty history.
+func loadHistory(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// appendHistory appends the passed line to the passed history file, creating
+// the file along with its directory when needed.
+func appendHistory(path, line string) error {
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeHistory replaces the contents of the passed history file with the
+// passed lines.
+func writeHistory(path string, lines []string) error {
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return os.WriteFile(path, []byte(b.String()), 0600)
+}
+
+// runShell runs the interactive shell until the user exits it or standard
+// input ends.
+func runShell(cfg *config) error {
+	sh := &shell{
+		cfg:     cfg,
+		methods: usableMethods(),
+		in:      bufio.NewReader(os.Stdin),
+	}
+
+	// Use the line editor when standard input is a terminal, which is the
+	// case when it can be put into raw mode.
+	fd := int(os.Stdin.Fd())
+	if state, err := makeRaw(fd); err == nil {
+		restoreTerminal(fd, state)
+		sh.editor = newLineEditor(fd, os.Stdin, os.Stdout, sh.complete)
+
+		history, err := loadHistory(defaultHistoryFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load history: %v\n",
+				err)
+		}
+		for _, line := range history {
+			sh.editor.addHistory(line)
+		}
+
+		// Drop the lines which no longer fit in the history from the
+		// file so it doesn't grow without bounds.
+		if len(history) > len(sh.editor.history) {
+			err := writeHistory(defaultHistoryFile,
+				sh.editor.history)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to save "+
+					"history: %v\n", err)
+			}
+		}
+
+		fmt.Println(shellWelcomeMessage)
+	}
+
+	appName := filepath.Base(os.Args[0])
+	appName = strings.TrimSuffix(appName, filepath.Ext(appName))
+	prompt := appName + "> "
+	for {
+		line, err := sh.readLine(prompt)
+		switch {
+		case err == io.EOF:
+			return nil
+		case errors.Is(err, errInterrupted):
+			continue
+		case err != nil:
+			return err
+		}
+
+		args, err := splitArgs(line)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		// Remember the line unless it holds secrets.
+		if sh.editor != nil {
+			sh.editor.addHistory(line)
+			if _, ok := sensitiveMethods[args[0]]; !ok {
+				err := appendHistory(defaultHistoryFile, line)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to save "+
+						"history: %v\n", err)
+				}
+			}
+		}
+
+		if sh.execute(args) {
+			return nil
+		}
+	}
+}
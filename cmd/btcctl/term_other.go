@@ -0,0 +1,35 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+
+package main
+
+import (
+	"errors"
+)
+
+// terminalState houses the settings of a terminal which are restored when it
+// leaves raw mode.
+type terminalState struct{}
+
+// makeRaw always returns an error since putting a terminal into raw mode isn't
+// supported on this platform.  The interactive shell falls back to reading
+// plain lines.
+func makeRaw(fd int) (*terminalState, error) {
+	return nil, errors.New("raw terminal mode is not supported on this " +
+		"platform")
+}
+
+// restoreTerminal does nothing since terminals are never put into raw mode on
+// this platform.
+func restoreTerminal(fd int, state *terminalState) error {
+	return nil
+}
+
+// isTerminal always returns false since terminals can't be detected on this
+// platform.
+func isTerminal(fd int) bool {
+	return false
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package main
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// terminalState houses the settings of a terminal which are restored when it
+// leaves raw mode.
+type terminalState struct {
+	termios unix.Termios
+}
+
+// makeRaw puts the terminal connected to the passed file descriptor into raw
+// mode and returns its previous state.  An error is returned when the file
+// descriptor isn't connected to a terminal.
+//
+// Unlike a fully raw terminal, output processing is left enabled so printing a
+// newline still returns the cursor to the start of the line.
+func makeRaw(fd int) (*terminalState, error) {
+	termios, err := unix.IoctlGetTermios(fd, ioctlReadTermios)
+	if err != nil {
+		return nil, err
+	}
+	state := &terminalState{termios: *termios}
+
+	termios.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK |
+		unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	termios.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG |
+		unix.IEXTEN
+	termios.Cflag &^= unix.CSIZE | unix.PARENB
+	termios.Cflag |= unix.CS8
+	termios.Cc[unix.VMIN] = 1
+	termios.Cc[unix.VTIME] = 0
+	err = unix.IoctlSetTermios(fd, ioctlWriteTermios, termios)
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// restoreTerminal restores the passed state of the terminal connected to the
+// passed file descriptor.
+func restoreTerminal(fd int, state *terminalState) error {
+	return unix.IoctlSetTermios(fd, ioctlWriteTermios, &state.termios)
+}
+
+// isTerminal returns whether or not the passed file descriptor is connected to
+// a terminal.
+func isTerminal(fd int) bool {
+	_, err := unix.IoctlGetTermios(fd, ioctlReadTermios)
+	return err == nil
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+const (
+	// maxDiffCells is the maximum number of cells of the table used to
+	// find the lines shared by two results.  Larger results are compared
+	// line by line instead.
+	maxDiffCells = 1 << 22
+
+	// watchTimeFormat is the format of the time shown along with each
+	// result in watch mode.
+	watchTimeFormat = "2006-01-02 15:04:05"
+)
+
+// diffLine is a line of the difference between two results.  Its kind is ' '
+// for a line of both results, '-' for a line only of the old result and '+'
+// for a line only of the new result.
+type diffLine struct {
+	kind byte
+	text string
+}
+
+// diffLines returns the difference between the passed old and new lines.  The
+// lines shared by both are found with the longest common subsequence of them,
+// unless they are too large, in which case lines at the same position are
+// compared.
+func diffLines(oldLines, newLines []string) []diffLine {
+	n, m := len(oldLines), len(newLines)
+	diff := make([]diffLine, 0, max(n, m))
+
+	if n*m > maxDiffCells {
+		for i := 0; i < max(n, m); i++ {
+			switch {
+			case i < n && i < m && oldLines[i] == newLines[i]:
+				diff = append(diff, diffLine{' ', newLines[i]})
+				continue
+			case i < n:
+				diff = append(diff, diffLine{'-', oldLines[i]})
+			}
+			if i < m {
+				diff = append(diff, diffLine{'+', newLines[i]})
+			}
+		}
+		return diff
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of the
+	// old lines from i and the new lines from j.
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && oldLines[i] == newLines[j]:
+			diff = append(diff, diffLine{' ', newLines[j]})
+			i++
+			j++
+		case j < m && (i == n || lcs[i][j+1] >= lcs[i+1][j]):
+			diff = append(diff, diffLine{'+', newLines[j]})
+			j++
+		default:
+			diff = append(diff, diffLine{'-', oldLines[i]})
+			i++
+		}
+	}
+	return diff
+}
+
+// watchOutput runs the passed command and returns its result as it is shown to
+// the user.  Errors are returned as the output so a server which is briefly
+// unavailable doesn't end watch mode.
+func watchOutput(cmd interface{}, cfg *config) []string {
+	var b bytes.Buffer
+	result, err := sendCmd(cmd, cfg)
+	if err == nil {
+		err = printResult(&b, result, cfg)
+	}
+	if err != nil {
+		b.Reset()
+		fmt.Fprintln(&b, err)
+	}
+
+	return strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+}
+
+// watch runs the passed command at the interval of the watch option until the
+// process is interrupted.  When stdout is a terminal, the screen is redrawn
+// with each result and the lines which changed since the previous one are
+// highlighted.  Otherwise, the first result is written in full followed by the
+// lines removed from and added to each of the later results, prefixed by - and
+// + respectively.  The passed title, which describes the command, is shown
+// along with the time of each result.
+func watch(title string, cmd interface{}, cfg *config) {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	ticker := time.NewTicker(cfg.Watch)
+	defer ticker.Stop()
+
+	terminal := isTerminal(int(os.Stdout.Fd()))
+	var prevLines []string
+	for first := true; ; first = false {
+		lines := watchOutput(cmd, cfg)
+		header := fmt.Sprintf("Every %v: %s", cfg.Watch, title)
+		now := time.Now().Format(watchTimeFormat)
+
+		var b strings.Builder
+		switch {
+		case terminal:
+			// Clear the screen and show the result with the lines
+			// which are new to it in reverse video.
+			fmt.Fprintf(&b, "\x1b[H\x1b[2J%s  %s\n\n", header, now)
+			for _, line := range diffLines(prevLines, lines) {
+				switch {
+				case line.kind == '-':
+					// The removed lines aren't shown.
+				case line.kind == '+' && !first:
+					fmt.Fprintf(&b, "\x1b[7m%s\x1b[0m\n",
+						line.text)
+				default:
+					fmt.Fprintln(&b, line.text)
+				}
+			}
+
+		case first:
+			fmt.Fprintf(&b, "%s  %s\n", header, now)
+			for _, line := range lines {
+				fmt.Fprintln(&b, line)
+			}
+
+		default:
+			// Only show the time when the result changed.
+			var changes strings.Builder
+			for _, line := range diffLines(prevLines, lines) {
+				if line.kind != ' ' {
+					fmt.Fprintf(&changes, "%c %s\n",
+						line.kind, line.text)
+				}
+			}
+			if changes.Len() > 0 {
+				fmt.Fprintf(&b, "%s\n%s", now, changes.String())
+			}
+		}
+		os.Stdout.WriteString(b.String())
+		prevLines = lines
+
+		select {
+		case <-ticker.C:
+		case <-interrupt:
+			return
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestDiffLines ensures the difference between two results keeps the lines
+// shared by both and marks the removed and added ones.
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldLines []string
+		newLines []string
+		want     []diffLine
+	}{
+		{
+			name:     "first result",
+			newLines: []string{"a", "b"},
+			want:     []diffLine{{'+', "a"}, {'+', "b"}},
+		},
+		{
+			name:     "unchanged",
+			oldLines: []string{"a", "b"},
+			newLines: []string{"a", "b"},
+			want:     []diffLine{{' ', "a"}, {' ', "b"}},
+		},
+		{
+			name:     "changed line",
+			oldLines: []string{"{", `"blocks": 10`, "}"},
+			newLines: []string{"{", `"blocks": 11`, "}"},
+			want: []diffLine{
+				{' ', "{"},
+				{'+', `"blocks": 11`},
+				{'-', `"blocks": 10`},
+				{' ', "}"},
+			},
+		},
+		{
+			name:     "inserted and removed lines",
+			oldLines: []string{"a", "b", "c"},
+			newLines: []string{"x", "a", "c"},
+			want: []diffLine{
+				{'+', "x"},
+				{' ', "a"},
+				{'-', "b"},
+				{' ', "c"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		got := diffLines(test.oldLines, test.newLines)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %q, want %q", test.name, got,
+				test.want)
+		}
+	}
+}
+
+// TestDiffLinesLarge ensures results which are too large to find the longest
+// common subsequence of are compared line by line.
+func TestDiffLinesLarge(t *testing.T) {
+	const numLines = 4096
+	oldLines := make([]string, numLines)
+	newLines := make([]string, numLines+1)
+	for i := range oldLines {
+		oldLines[i] = "same"
+		newLines[i] = "same"
+	}
+	oldLines[1] = "old"
+	newLines[1] = "new"
+	newLines[numLines] = "extra"
+
+	got := diffLines(oldLines, newLines)
+	want := []diffLine{{' ', "same"}, {'-', "old"}, {'+', "new"}}
+	if !reflect.DeepEqual(got[:3], want) {
+		t.Fatalf("got %q, want %q", got[:3], want)
+	}
+	if len(got) != numLines+2 {
+		t.Fatalf("got %d lines, want %d", len(got), numLines+2)
+	}
+	if last := got[len(got)-1]; last != (diffLine{'+', "extra"}) {
+		t.Fatalf("got last line %q, want %q", last,
+			diffLine{'+', "extra"})
+	}
+}
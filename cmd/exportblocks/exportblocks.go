@@ -0,0 +1,217 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/database"
+	"github.com/btcsuite/btcd/limits"
+	"github.com/btcsuite/btclog"
+)
+
+const (
+	// blockDbNamePrefix is the prefix for the btcd block database.
+	blockDbNamePrefix = "blocks"
+)
+
+var (
+	cfg *config
+	log btclog.Logger
+)
+
+// loadBlockDB opens the block database and returns a handle to it.
+func loadBlockDB() (database.DB, error) {
+	// The database name is based on the database type.
+	dbName := blockDbNamePrefix + "_" + cfg.DbType
+	dbPath := filepath.Join(cfg.DataDir, dbName)
+
+	log.Infof("Loading block database from '%s'", dbPath)
+	db, err := database.Open(cfg.DbType, dbPath, activeNetParams.Net)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("Block database loaded")
+	return db, nil
+}
+
+// writeFramedBlock writes the passed serialized block in the framed format used
+// by bootstrap.dat files, which is also read by addblock and the loadblock
+// option of btcd:
+//
+//	<network> <block length> <serialized block>
+//
+// The network is the 4-byte magic of the network and the block length is the
+// 4-byte length of the serialized block, both in little-endian byte order.
+func writeFramedBlock(w io.Writer, serializedBlock []byte) error {
+	var header [8]byte
+	binary.LittleEndian.PutUint32(header[:4], uint32(activeNetParams.Net))
+	binary.LittleEndian.PutUint32(header[4:], uint32(len(serializedBlock)))
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(serializedBlock)
+	return err
+}
+
+// exportBlocks writes the blocks of the main chain from the from height up to
+// and including the to height to the passed writer in the framed format and,
+// when the output directory is not empty, to a separate file per block.  It
+// returns the number of exported blocks.
+func exportBlocks(db database.DB, chain *blockchain.BlockChain, from, to int32,
+	w io.Writer, outDir string) (int32, error) {
+
+	lastLogTime := time.Now()
+	var numExported, receivedLogBlocks int32
+	for height := from; height <= to; height++ {
+		hash, err := chain.BlockHashByHeight(height)
+		if err != nil {
+			return numExported, err
+		}
+
+		err = db.View(func(dbTx database.Tx) error {
+			serializedBlock, err := dbTx.FetchBlock(hash)
+			if err != nil {
+				return fmt.Errorf("block %v at height %d is not "+
+					"available: %w", hash, height, err)
+			}
+			if w != nil {
+				err := writeFramedBlock(w, serializedBlock)
+				if err != nil {
+					return err
+				}
+			}
+			if outDir != "" {
+				name := fmt.Sprintf("%d-%v.blk", height, hash)
+				path := filepath.Join(outDir, name)
+				return os.WriteFile(path, serializedBlock, 0644)
+			}
+			return nil
+		})
+		if err != nil {
+			return numExported, err
+		}
+		numExported++
+
+		// Show progress when enabled.
+		receivedLogBlocks++
+		now := time.Now()
+		duration := now.Sub(lastLogTime)
+		if cfg.Progress != 0 &&
+			duration >= time.Second*time.Duration(cfg.Progress) {
+
+			log.Infof("Exported %d blocks in the last %s (height %d)",
+				receivedLogBlocks, duration.Truncate(time.Millisecond),
+				height)
+			receivedLogBlocks = 0
+			lastLogTime = now
+		}
+	}
+	return numExported, nil
+}
+
+// realMain is the real main function for the utility.  It is necessary to work
+// around the fact that deferred functions do not run when os.Exit() is called.
+func realMain() error {
+	// Load configuration and parse command line.
+	tcfg, _, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	cfg = tcfg
+
+	// Setup logging.
+	backendLogger := btclog.NewBackend(os.Stdout)
+	defer os.Stdout.Sync()
+	log = backendLogger.Logger("MAIN")
+	database.UseLogger(backendLogger.Logger("BCDB"))
+	blockchain.UseLogger(backendLogger.Logger("CHAN"))
+
+	// Load the block database.
+	db, err := loadBlockDB()
+	if err != nil {
+		log.Errorf("Failed to load database: %v", err)
+		return err
+	}
+	defer db.Close()
+
+	// Setup chain.  Ignore notifications since they aren't needed for this
+	// util.
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:          db,
+		ChainParams: activeNetParams,
+		TimeSource:  blockchain.NewMedianTime(),
+	})
+	if err != nil {
+		log.Errorf("Failed to initialize chain: %v", err)
+		return err
+	}
+
+	// Export up to the best block by default.
+	best := chain.BestSnapshot()
+	to := cfg.To
+	if to == -1 {
+		to = best.Height
+	}
+	if to > best.Height {
+		err := fmt.Errorf("the block database is only at height %d "+
+			"which is less than the to height of %d", best.Height, to)
+		log.Error(err)
+		return err
+	}
+
+	if cfg.OutDir != "" {
+		if err := os.MkdirAll(cfg.OutDir, 0755); err != nil {
+			log.Errorf("Failed to create output directory: %v", err)
+			return err
+		}
+	}
+	var w io.Writer
+	var bw *bufio.Writer
+	if cfg.OutFile != "" {
+		f, err := os.OpenFile(cfg.OutFile,
+			os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			log.Errorf("Failed to create output file: %v", err)
+			return err
+		}
+		defer f.Close()
+		bw = bufio.NewWriterSize(f, 1<<20)
+		w = bw
+	}
+
+	log.Infof("Exporting blocks %d to %d", cfg.From, to)
+	numExported, err := exportBlocks(db, chain, cfg.From, to, w, cfg.OutDir)
+	if err == nil && bw != nil {
+		err = bw.Flush()
+	}
+	if err != nil {
+		log.Errorf("Failed to export blocks: %v", err)
+		return err
+	}
+
+	log.Infof("Exported a total of %d blocks", numExported)
+	return nil
+}
+
+func main() {
+	// up some limits.
+	if err := limits.SetLimits(); err != nil {
+		os.Exit(1)
+	}
+
+	// Work around defer not working after os.Exit()
+	if err := realMain(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil/v2"
+	"github.com/btcsuite/btcd/chaincfg/v2"
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/database"
+	_ "github.com/btcsuite/btcd/database/ffldb"
+	"github.com/btcsuite/btcd/mining"
+	"github.com/btcsuite/btcd/txscript/v2"
+	"github.com/btcsuite/btcd/wire/v2"
+	"github.com/btcsuite/btclog"
+)
+
+// emptyTxSource is a mining.TxSource without any transactions.
+type emptyTxSource struct{}
+
+func (emptyTxSource) LastUpdated() time.Time               { return time.Time{} }
+func (emptyTxSource) MiningDescs() []*mining.TxDesc        { return nil }
+func (emptyTxSource) HaveTransaction(*chainhash.Hash) bool { return false }
+
+// newTestChain returns a regression test chain with the passed number of
+// blocks after the genesis block along with its database.
+func newTestChain(t *testing.T, numBlocks int) (database.DB,
+	*blockchain.BlockChain) {
+
+	t.Helper()
+
+	db, err := database.Create("ffldb", filepath.Join(t.TempDir(), "db"),
+		activeNetParams.Net)
+	if err != nil {
+		t.Fatalf("unable to create database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	timeSource := blockchain.NewMedianTime()
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:          db,
+		ChainParams: activeNetParams,
+		TimeSource:  timeSource,
+	})
+	if err != nil {
+		t.Fatalf("unable to create chain: %v", err)
+	}
+
+	policy := mining.Policy{BlockMaxWeight: blockchain.MaxBlockWeight}
+	g := mining.NewBlkTmplGenerator(&policy, activeNetParams,
+		emptyTxSource{}, chain, timeSource, txscript.NewSigCache(0),
+		txscript.NewHashCache(0))
+	for i := 0; i < numBlocks; i++ {
+		template, err := g.NewBlockTemplate(nil)
+		if err != nil {
+			t.Fatalf("unable to create block template: %v", err)
+		}
+		block := template.Block
+		target := blockchain.CompactToBig(block.Header.Bits)
+		for {
+			hash := block.Header.BlockHash()
+			if blockchain.HashToBig(&hash).Cmp(target) <= 0 {
+				break
+			}
+			block.Header.Nonce++
+		}
+		_, _, err = chain.ProcessBlock(btcutil.NewBlock(block),
+			blockchain.BFNone)
+		if err != nil {
+			t.Fatalf("unable to process block: %v", err)
+		}
+	}
+
+	return db, chain
+}
+
+// TestExportBlocks ensures the blocks of the main chain are exported in the
+// framed format and to a file per block.
+func TestExportBlocks(t *testing.T) {
+	activeNetParams = &chaincfg.RegressionNetParams
+	cfg = &config{}
+	log = btclog.Disabled
+
+	db, chain := newTestChain(t, 5)
+
+	var b bytes.Buffer
+	outDir := t.TempDir()
+	numExported, err := exportBlocks(db, chain, 2, 4, &b, outDir)
+	if err != nil {
+		t.Fatalf("exportBlocks: unexpected error: %v", err)
+	}
+	if numExported != 3 {
+		t.Fatalf("exportBlocks: exported %d blocks, want 3", numExported)
+	}
+
+	for height := int32(2); height <= 4; height++ {
+		hash, err := chain.BlockHashByHeight(height)
+		if err != nil {
+			t.Fatalf("BlockHashByHeight: unexpected error: %v", err)
+		}
+
+		// Read the framed block.
+		var header [8]byte
+		if _, err := b.Read(header[:]); err != nil {
+			t.Fatalf("height %d: unable to read frame: %v", height,
+				err)
+		}
+		net := binary.LittleEndian.Uint32(header[:4])
+		if net != uint32(activeNetParams.Net) {
+			t.Fatalf("height %d: got network %x, want %x", height,
+				net, uint32(activeNetParams.Net))
+		}
+		serialized := make([]byte, binary.LittleEndian.Uint32(header[4:]))
+		if _, err := b.Read(serialized); err != nil {
+			t.Fatalf("height %d: unable to read block: %v", height,
+				err)
+		}
+		var block wire.MsgBlock
+		err = block.Deserialize(bytes.NewReader(serialized))
+		if err != nil {
+			t.Fatalf("height %d: unable to deserialize block: %v",
+				height, err)
+		}
+		if block.BlockHash() != *hash {
+			t.Fatalf("height %d: got block %v, want %v", height,
+				block.BlockHash(), hash)
+		}
+
+		// The file of the block holds the same serialized block.
+		name := fmt.Sprintf("%d-%v.blk", height, hash)
+		contents, err := os.ReadFile(filepath.Join(outDir, name))
+		if err != nil {
+			t.Fatalf("height %d: unable to read block file: %v",
+				height, err)
+		}
+		if !bytes.Equal(contents, serialized) {
+			t.Fatalf("height %d: block file differs from the "+
+				"framed block", height)
+		}
+	}
+	if b.Len() != 0 {
+		t.Fatalf("exportBlocks: %d unexpected trailing bytes", b.Len())
+	}
+
+	// Heights beyond the best block can't be exported.
+	if _, err := exportBlocks(db, chain, 5, 6, nil, ""); err == nil {
+		t.Fatal("exportBlocks: expected error beyond the best block")
+	}
+}
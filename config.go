@@ -6,15 +6,20 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"math/big"
 	"net"
+	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"slices"
 	"sort"
@@ -24,14 +29,19 @@ import (
 
 	"github.com/btcsuite/btcd/address/v2"
 	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
 	"github.com/btcsuite/btcd/btcutil/v2"
 	"github.com/btcsuite/btcd/chaincfg/v2"
 	"github.com/btcsuite/btcd/chainhash/v2"
 	"github.com/btcsuite/btcd/connmgr"
 	"github.com/btcsuite/btcd/database"
 	_ "github.com/btcsuite/btcd/database/ffldb"
+	"github.com/btcsuite/btcd/dnsseeder"
 	"github.com/btcsuite/btcd/mempool"
+	"github.com/btcsuite/btcd/mining"
 	"github.com/btcsuite/btcd/peer"
+	"github.com/btcsuite/btcd/txscript/v2"
 	"github.com/btcsuite/btcd/wire/v2"
 	"github.com/btcsuite/go-socks/socks"
 	flags "github.com/jessevdk/go-flags"
@@ -43,7 +53,14 @@ const (
 	defaultLogLevel              = "info"
 	defaultLogDirname            = "logs"
 	defaultLogFilename           = "btcd.log"
+	defaultLogMaxSizeMiB         = 10
+	defaultLogMaxFiles           = 3
 	defaultMaxPeers              = 125
+	defaultBlockRelayOnlyPeers   = 2
+	defaultMaxClockSkew          = time.Minute * 70
+	defaultMinDiskSpaceMiB       = 1024
+	defaultOutboundPeers         = 8
+	defaultOnionPeers            = 0
 	defaultBanDuration           = time.Hour * 24
 	defaultBanThreshold          = 100
 	defaultConnectTimeout        = time.Second * 30
@@ -53,6 +70,11 @@ const (
 	defaultDbType                = "ffldb"
 	defaultFreeTxRelayLimit      = 15.0
 	defaultTrickleInterval       = peer.DefaultTrickleInterval
+	defaultTrickleScalePeers     = 0
+	defaultTxAnnounceDelay       = 0
+	defaultMaxInvBatch           = peer.DefaultMaxInvTrickleSize
+	defaultMaxPeerTxRate         = 100
+	defaultPeerTxBurst           = 1000
 	defaultBlockMinSize          = 0
 	defaultBlockMaxSize          = 750000
 	defaultBlockMinWeight        = 0
@@ -61,15 +83,47 @@ const (
 	blockMaxSizeMax              = blockchain.MaxBlockBaseSize - 1000
 	blockMaxWeightMin            = 4000
 	blockMaxWeightMax            = blockchain.MaxBlockWeight - 4000
+	maxStdTxWeightMin            = 4000
 	defaultGenerate              = false
+	defaultStratumPort           = "3333"
+	defaultStratumDifficulty     = 1.0
+	defaultStratumMinDifficulty  = 0.001
+	defaultStratumShareTime      = time.Second * 15
+	defaultDNSSeederPort         = "53"
 	defaultMaxOrphanTransactions = 100
 	defaultMaxOrphanTxSize       = 100000
+	defaultMaxOrphanTxsPerPeer   = 25
+	defaultMaxOrphanWeight       = 4000000
+	defaultOrphanTTL             = time.Minute * 15
+	defaultMempoolExpiry         = time.Hour * 336
 	defaultSigCacheMaxSize       = 100000
+	defaultSigCacheEviction      = "random"
+	defaultStaleTipFactor        = 3
+	defaultCheckBlocks           = 6
+	defaultCheckLevel            = 1
 	defaultUtxoCacheMaxSizeMiB   = 250
+	defaultUtxoFlushInterval     = time.Minute * 5
 	sampleConfigFilename         = "sample-btcd.conf"
 	defaultTxIndex               = false
 	defaultAddrIndex             = false
 	pruneMinSize                 = 1536
+	pruneSpendJournalMinDepth    = 288
+	maxMemoryMinMiB              = 256
+
+	// utxoCacheMemoryShare and sigCacheMemoryShare are the fractions of
+	// the memory budget set by the maxmemory option which are given to
+	// the UTXO cache and the signature cache, as divisors of the budget.
+	// A budget of 1 GiB results in about the default sizes of the caches.
+	utxoCacheMemoryShare = 4
+	sigCacheMemoryShare  = 16
+
+	// sigCacheEntryBytes is the estimated number of bytes of memory used
+	// by an entry of the signature cache together with an entry of each
+	// of its ECDSA and Schnorr public key caches and an entry of the hash
+	// cache, which all hold as many entries.  Measured, the signature
+	// entries take about 270 bytes, the key entries about 215 and the
+	// hash entries about 320.
+	sigCacheEntryBytes = 800
 )
 
 var (
@@ -100,18 +154,39 @@ func minUint32(a, b uint32) uint32 {
 // See loadConfig for details on the configuration load process.
 type config struct {
 	AddCheckpoints       []string      `long:"addcheckpoint" description:"Add a custom checkpoint.  Format: '<height>:<hash>'"`
+	CheckpointFile       string        `long:"checkpointfile" description:"Add the checkpoints of a checkpoint file signed by the checkpointsigner address -- checkpoints given with addcheckpoint take precedence"`
+	CheckpointSigner     string        `long:"checkpointsigner" description:"Pay-to-pubkey-hash address whose key has to sign the checkpoint file"`
 	AddPeers             []string      `short:"a" long:"addpeer" description:"Add a peer to connect with at startup"`
 	AddrIndex            bool          `long:"addrindex" description:"Maintain a full address-based transaction index which makes the searchrawtransactions RPC available"`
 	AgentBlacklist       []string      `long:"agentblacklist" description:"A comma separated list of user-agent substrings which will cause btcd to reject any peers whose user-agent contains any of the blacklisted substrings."`
 	AgentWhitelist       []string      `long:"agentwhitelist" description:"A comma separated list of user-agent substrings which will cause btcd to require all peers' user-agents to contain one of the whitelisted substrings. The blacklist is applied before the whitelist, and an empty whitelist will allow all agents that do not fail the blacklist."`
+	AlertNotify          string        `long:"alertnotify" description:"Execute this command when a fork of the main chain which indicates the network may have split is found (%s in the command is replaced by the warning)"`
+	AssumeValid          string        `long:"assumevalid" description:"Skip the script verification of the ancestors of this block while they are buried deeply enough in the best header chain, which speeds up the initial block download -- 0 to verify all scripts (default: a recent block of the active network)"`
+	ASMap                string        `long:"asmap" description:"Path to an asmap file in the format used by Bitcoin Core to group peer addresses by autonomous system instead of network prefix"`
 	BanDuration          time.Duration `long:"banduration" description:"How long to ban misbehaving peers.  Valid time units are {s, m, h}.  Minimum 1 second"`
 	BanThreshold         uint32        `long:"banthreshold" description:"Maximum allowed ban score before disconnecting and banning misbehaving peers."`
+	BatchSigVerify       bool          `long:"batchsigverify" description:"Verify the Schnorr signatures of the taproot inputs of blocks in batches, which is faster than verifying them one at a time"`
+	Bench                bool          `long:"bench" description:"Log the time spent in each stage of processing every block connected to the main chain"`
+	BlockRelayOnlyPeers  int           `long:"blockrelayonlypeers" description:"Number of outbound connections which only relay blocks to maintain in addition to the regular outbound connections"`
+	BlockServeRate       uint64        `long:"blockservingrate" description:"Maximum rate in KiB/s at which historical blocks are served to peers without the noban permission -- blocks relayed as they are found are not delayed (0 = no limit)"`
 	BlockMaxSize         uint32        `long:"blockmaxsize" description:"Maximum block size in bytes to be used when creating a block"`
 	BlockMinSize         uint32        `long:"blockminsize" description:"Minimum block size in bytes to be used when creating a block"`
 	BlockMaxWeight       uint32        `long:"blockmaxweight" description:"Maximum block weight to be used when creating a block"`
 	BlockMinWeight       uint32        `long:"blockminweight" description:"Minimum block weight to be used when creating a block"`
 	BlockPrioritySize    uint32        `long:"blockprioritysize" description:"Size in bytes for high-priority/low-fee transactions when creating a block"`
+	BlockMaxSigOpCost    uint32        `long:"blockmaxsigopcost" description:"Maximum signature operation cost of the transactions to be used when creating a block -- 0 for the consensus limit"`
+	BlockMinTxFee        float64       `long:"blockmintxfee" description:"The minimum transaction fee in BTC/kB a transaction must pay to be included when creating a block"`
+	BlocksDir            string        `long:"blocksdir" description:"Directory to store the block files in separately from the rest of the data, such as on a larger and slower disk"`
 	BlocksOnly           bool          `long:"blocksonly" description:"Do not accept transactions from remote peers."`
+	CheckBlocks          int           `long:"checkblocks" description:"Number of blocks at the tip of the main chain to verify in the background at startup -- 0 to disable"`
+	AcceptClaimScripts   bool          `long:"acceptclaimscripts" description:"Accept transactions with claim, support or update outputs as standard -- the script engine doesn't strip the claim prefix, so spends of these outputs can't be verified"`
+	CheckClaimMetadata   bool          `long:"checkclaimmetadata" description:"Reject transactions with claim, support or update outputs whose values aren't well-formed LBRY metadata as non-standard"`
+	CheckLevel           int           `long:"checklevel" description:"How thorough the verification of the blocks at startup is {0: load the blocks, 1: also check their sanity}"`
+	ClaimFilters         bool          `long:"claimfilters" description:"Maintain and serve committed filters of the names of the claims created and spent by each block as filter type 1 in addition to the basic filters"`
+	ClaimIndex           bool          `long:"claimindex" description:"Maintain an index of the claim, support and update outputs by claim ID and by name which makes the getclaimhistory and getnamehistory RPCs available"`
+	ClaimPriorityWeight  uint32        `long:"claimpriorityweight" description:"Weight of a block reserved for transactions which update or support claims when creating a block -- they are selected ahead of the other transactions, the ones spending the oldest claims first -- 0 to disable"`
+	CompressBlocks       bool          `long:"compressblocks" description:"Compress the blocks written to the block database -- use the rewriteblocks command of dbtool to compress the blocks which are already stored"`
+	EmptyBlockFirst      bool          `long:"emptyblockfirst" description:"Hand out a block template without any transactions right away when a new block arrives while the transactions for the full template are selected"`
 	ConfigFile           string        `short:"C" long:"configfile" description:"Path to configuration file"`
 	ConnectPeers         []string      `long:"connect" description:"Connect only to the specified peers at startup"`
 	CPUProfile           string        `long:"cpuprofile" description:"Write CPU profile to the specified file"`
@@ -122,22 +197,56 @@ type config struct {
 	DebugLevel           string        `short:"d" long:"debuglevel" description:"Logging level for all subsystems {trace, debug, info, warn, error, critical} -- You may also specify <subsystem>=<level>,<subsystem2>=<level>,... to set the log level for individual subsystems -- Use show to list available subsystems"`
 	DropAddrIndex        bool          `long:"dropaddrindex" description:"Deletes the address-based transaction index from the database on start up and then exits."`
 	DropCfIndex          bool          `long:"dropcfindex" description:"Deletes the index used for committed filtering (CF) support from the database on start up and then exits."`
+	DropClaimFilters     bool          `long:"dropclaimfilters" description:"Deletes the index of the committed filters of the claim names from the database on start up and then exits."`
+	DropClaimIndex       bool          `long:"dropclaimindex" description:"Deletes the claim index from the database on start up and then exits."`
+	DropSpentIndex       bool          `long:"dropspentindex" description:"Deletes the spent output index from the database on start up and then exits."`
 	DropTxIndex          bool          `long:"droptxindex" description:"Deletes the hash-based transaction index from the database on start up and then exits."`
+	DustRelayFee         float64       `long:"dustrelayfee" description:"The fee rate in BTC/kB used to determine whether a transaction output is considered dust (default: minrelaytxfee)"`
+	DataCarrierSize      int           `long:"datacarriersize" description:"Maximum number of bytes of data pushed by a null data (OP_RETURN) output that is considered standard"`
 	ExternalIPs          []string      `long:"externalip" description:"Add an ip to the list of local addresses we claim to listen on to peers"`
 	Generate             bool          `long:"generate" description:"Generate (mine) bitcoins using the CPU"`
 	FreeTxRelayLimit     float64       `long:"limitfreerelay" description:"Limit relay of transactions with no transaction fee to the given amount in thousands of bytes per minute"`
+	LoadBlocks           []string      `long:"loadblock" description:"Import the blocks of the specified block file at startup, such as a bootstrap.dat file or a blk*.dat file of another node -- may be specified multiple times"`
 	Listeners            []string      `long:"listen" description:"Add an interface/port to listen for connections (default all interfaces port: 8333, testnet: 18333)"`
 	LogDir               string        `long:"logdir" description:"Directory to log output."`
+	LogMaxAge            time.Duration `long:"logmaxage" description:"Delete rotated log files older than this duration.  Valid time units are {s, m, h}.  0 to keep them regardless of their age"`
+	LogMaxFiles          int           `long:"logmaxfiles" description:"Maximum number of rotated log files to keep -- 0 to keep all of them"`
+	LogMaxSize           int           `long:"logmaxsize" description:"Maximum size in MiB of the log file before it is rotated"`
 	MaxOrphanTxs         int           `long:"maxorphantx" description:"Max number of orphan transactions to keep in memory"`
+	MaxOrphanTxsPerPeer  int           `long:"maxorphantxperpeer" description:"Max number of orphan transactions to keep in memory for a single peer -- 0 to disable the per-peer limit"`
+	MaxOrphanWeight      int64         `long:"maxorphanweight" description:"Max total weight of orphan transactions to keep in memory -- 0 to disable the weight limit"`
+	OrphanTTL            time.Duration `long:"orphanttl" description:"How long to keep orphan transactions in memory before they expire.  Valid time units are {s, m, h}.  Minimum 1 minute"`
+	MempoolExpiry        time.Duration `long:"mempoolexpiry" description:"How long to keep transactions in the mempool before they expire.  Valid time units are {s, m, h}.  0 to disable expiry"`
+	PolicyHooks          []string      `long:"policyhook" description:"Enable the named compiled-in mempool policy hook -- may be specified multiple times"`
+	PolicySocket         string        `long:"policysocket" description:"Path to a unix socket of an external policy service consulted before accepting transactions into the mempool"`
+	MaxClaimValueSize    int           `long:"maxclaimvaluesize" description:"Maximum number of bytes of the value of a claim, support or update output that is considered standard"`
+	MaxClockSkew         time.Duration `long:"maxclockskew" description:"Shut down when the local clock is off by more than the specified duration from the time of the network as estimated from the outbound peers -- Valid time units are {s, m, h}.  0 disables the check"`
+	MinDiskSpaceMiB      uint          `long:"mindiskspace" description:"Minimum free disk space in MiB of the data and blocks directories below which the download and processing of blocks is suspended -- 0 disables the check"`
 	MaxPeers             int           `long:"maxpeers" description:"Max number of inbound and outbound peers"`
-	MiningAddrs          []string      `long:"miningaddr" description:"Add the specified payment address to the list of addresses to use for generated blocks -- At least one address is required if the generate option is set"`
+	MaxUploadTarget      uint64        `long:"maxuploadtarget" description:"Try to keep outbound traffic under the given target in MiB per 24h -- historical blocks are no longer served to peers without the noban permission once it is reached (0 = no limit)"`
+	MaxStdTxWeight       int           `long:"maxstdtxweight" description:"Maximum weight of a transaction that is considered standard"`
+	BytesPerSigOp        int           `long:"bytespersigop" description:"Number of virtual bytes each unit of signature operation cost is considered to occupy when calculating transaction fee rates"`
+	MDNS                 bool          `long:"mdns" description:"Discover and connect to peers on the local network with multicast DNS and advertise the listening port to them"`
+	MiningAddrs          []string      `long:"miningaddr" description:"Add the specified payment address to the list of addresses to use for generated blocks -- At least one address is required if the generate option is set.  Use <address>:<percent> for every address to instead split the coinbase of each generated block between all of them"`
 	MinRelayTxFee        float64       `long:"minrelaytxfee" description:"The minimum transaction fee in BTC/kB to be considered a non-zero fee."`
+	MinimumChainWork     string        `long:"minimumchainwork" description:"Minimum cumulative work in hex a chain of headers must have for its headers to be stored -- chains of headers with less work are first synced without being stored"`
+	Misbehavior          []string      `long:"misbehavior" description:"Set how to react to a category of peer misbehavior in the form category:reaction -- may be specified multiple times.  Categories are flood, notfound, protocol and malformed.  Reactions are ban (default), disconnect and ignore"`
 	DisableBanning       bool          `long:"nobanning" description:"Disable banning of misbehaving peers"`
 	NoCFilters           bool          `long:"nocfilters" description:"Disable committed filtering (CF) support"`
 	DisableCheckpoints   bool          `long:"nocheckpoints" description:"Disable built-in checkpoints.  Don't do this unless you know what you're doing."`
 	DisableDNSSeed       bool          `long:"nodnsseed" description:"Disable DNS seeding for peers"`
+	DNSSeeds             []string      `long:"dnsseed" description:"Add a DNS seed to query for peers in addition to the built-in seeds of the network -- may be specified multiple times"`
+	DNSSeeder            bool          `long:"dnsseeder" description:"Crawl the network and serve the addresses of good peers as an authoritative DNS seed for dnsseederhost"`
+	DNSSeederHost        string        `long:"dnsseederhost" description:"Host name the DNS seeder serves -- It must be delegated to this node with an NS record"`
+	DNSSeederListeners   []string      `long:"dnsseederlisten" description:"Add an interface/port to listen for DNS queries when running as a DNS seeder (default port: 53)"`
+	DNSSeederNS          string        `long:"dnsseederns" description:"Host name of this node served in the NS records of the DNS seeder"`
 	DisableListen        bool          `long:"nolisten" description:"Disable listening for incoming connections -- NOTE: Listening is automatically disabled if the --connect or --proxy options are used without also specifying listen interfaces via --listen"`
 	NoOnion              bool          `long:"noonion" description:"Disable connecting to tor hidden services"`
+	OnionPeers           int           `long:"onionpeers" description:"Number of outbound connections to tor hidden services to maintain in addition to the regular outbound connections -- Requires the --proxy or --onion option"`
+	OutboundPeers        int           `long:"outboundpeers" description:"Number of regular outbound connections to maintain"`
+	OnlyNets             []string      `long:"onlynet" description:"Only make automatic outbound connections to peers on the specified network {ipv4, ipv6, onion} -- may be specified multiple times"`
+	NoFileLogging        bool          `long:"nofilelogging" description:"Disable logging to files and only log to standard output, such as when running in a container"`
+	NoLogCompress        bool          `long:"nologcompress" description:"Do not compress rotated log files with gzip"`
 	NoPeerBloomFilters   bool          `long:"nopeerbloomfilters" description:"Disable bloom filtering support"`
 	NoRelayPriority      bool          `long:"norelaypriority" description:"Do not require free or low-fee transactions to have high priority for relaying"`
 	NoWinService         bool          `long:"nowinservice" description:"Do not start as a background service on Windows -- NOTE: This flag only works on the command line, not in the config file"`
@@ -149,10 +258,16 @@ type config struct {
 	OnionProxyUser       string        `long:"onionuser" description:"Username for onion proxy server"`
 	Profile              string        `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
 	Proxy                string        `long:"proxy" description:"Connect via SOCKS5 proxy (eg. 127.0.0.1:9050)"`
+	IPv4Proxy            string        `long:"ipv4proxy" description:"Connect to IPv4 peers via this SOCKS5 proxy instead of the one specified by --proxy"`
+	IPv6Proxy            string        `long:"ipv6proxy" description:"Connect to IPv6 peers via this SOCKS5 proxy instead of the one specified by --proxy"`
 	ProxyPass            string        `long:"proxypass" default-mask:"-" description:"Password for proxy server"`
 	ProxyUser            string        `long:"proxyuser" description:"Username for proxy server"`
 	Prune                uint64        `long:"prune" description:"Prune already validated blocks from the database. Must specify a target size in MiB (minimum value of 1536, default value of 0 will disable pruning)"`
+	PruneSpendJournal    int32         `long:"prunespendjournal" description:"Keep the spend journals, which are only needed to disconnect blocks, for this number of blocks at the tip of the main chain and delete the older ones while keeping the blocks -- reorganizations deeper than this are refused (minimum value of 288, default value of 0 will keep all spend journals)"`
 	RegressionTest       bool          `long:"regtest" description:"Use the regression test network"`
+	Reindex              bool          `long:"reindex" description:"Rebuild the block database, including the block index, chain state, and optional indexes, from its block files at startup -- only supported by the ffldb database type"`
+	ReindexChainState    bool          `long:"reindexchainstate" description:"Rebuild the chain state from the blocks in the database at startup while keeping the block index"`
+	RejectBareMultisig   bool          `long:"rejectbaremultisig" description:"Reject transactions with bare (non-P2SH) multisig outputs as non-standard."`
 	RejectNonStd         bool          `long:"rejectnonstd" description:"Reject non-standard transactions regardless of the default settings for the active network."`
 	RejectReplacement    bool          `long:"rejectreplacement" description:"Reject transactions that attempt to replace existing transactions within the mempool through the Replace-By-Fee (RBF) signaling policy."`
 	RelayNonStd          bool          `long:"relaynonstd" description:"Relay non-standard transactions regardless of the default settings for the active network."`
@@ -167,29 +282,68 @@ type config struct {
 	RPCQuirks            bool          `long:"rpcquirks" description:"Mirror some JSON-RPC quirks of Bitcoin Core -- NOTE: Discouraged unless interoperability issues need to be worked around"`
 	RPCPass              string        `short:"P" long:"rpcpass" default-mask:"-" description:"Password for RPC connections"`
 	RPCUser              string        `short:"u" long:"rpcuser" description:"Username for RPC connections"`
+	SeedListURL          string        `long:"seedlisturl" description:"HTTPS URL of a signed seed list to fetch peers from when none of the DNS seeds return any"`
+	SeedListPubKey       string        `long:"seedlistpubkey" description:"Hex-encoded 32-byte x-only public key the seed list must be signed with"`
+	SignalBits           []uint32      `long:"signalbit" description:"Signal the specified version bit (0-28) in generated blocks in addition to the bits of known deployments which are being voted on -- may be specified multiple times"`
 	SigCacheMaxSize      uint          `long:"sigcachemaxsize" description:"The maximum number of entries in the signature verification cache"`
+	SigCacheEviction     string        `long:"sigcacheeviction" description:"Which entry to evict from the full signature verification cache to make room for a new one {random, oldest}"`
+	ScriptWorkers        int           `long:"scriptworkers" description:"Max number of goroutines used to validate the scripts of a block concurrently -- 0 for three times the number of processor cores"`
+	ShutdownTimeout      time.Duration `long:"shutdowntimeout" description:"Maximum time to wait for a graceful shutdown to complete before forcing an exit with exit code 3.  Valid time units are {s, m, h}.  0 to wait as long as needed"`
+	StaleTipFactor       int           `long:"staletipfactor" description:"Multiple of the target time between blocks after which the best chain tip is considered stale when no new block arrived -- headers are then requested from all peers and the sync peer is replaced.  0 to disable"`
 	SimNet               bool          `long:"simnet" description:"Use the simulation test network"`
+	SpentIndex           bool          `long:"spentindex" description:"Maintain an index of the inputs which spent each output which makes the getspentinfo RPC available"`
 	SigNet               bool          `long:"signet" description:"Use the signet test network"`
 	SigNetChallenge      string        `long:"signetchallenge" description:"Connect to a custom signet network defined by this challenge instead of using the global default signet test network -- Can be specified multiple times"`
 	SigNetSeedNode       []string      `long:"signetseednode" description:"Specify a seed node for the signet network instead of using the global default signet network seed nodes"`
+	StratumListeners     []string      `long:"stratumlisten" description:"Add an interface/port to listen for stratum mining connections -- The stratum server is only enabled when at least one is specified (default port: 3333)"`
+	StratumPass          string        `long:"stratumpass" default-mask:"-" description:"Password required to authorize stratum workers -- Any password is accepted if not specified"`
+	StratumDifficulty    float64       `long:"stratumdiff" description:"Initial share difficulty for stratum clients"`
+	StratumMinDifficulty float64       `long:"stratummindiff" description:"Minimum share difficulty for stratum clients"`
+	StratumMaxDifficulty float64       `long:"stratummaxdiff" description:"Maximum share difficulty for stratum clients -- 0 for no limit"`
+	StratumShareTime     time.Duration `long:"stratumsharetime" description:"Target time between shares used to adjust the share difficulty of each stratum client.  Valid time units are {s, m, h}.  Minimum 1 second"`
 	TestNet3             bool          `long:"testnet" description:"Use the test network (version 3)"`
 	TestNet4             bool          `long:"testnet4" description:"Use the test network (version 4)"`
+	TorControl           string        `long:"torcontrol" description:"Create an onion service for incoming connections via the specified tor control port (eg. 127.0.0.1:9051)"`
 	TorIsolation         bool          `long:"torisolation" description:"Enable Tor stream isolation by randomizing user credentials for each connection."`
+	TorPassword          string        `long:"torpassword" default-mask:"-" description:"Password for the tor control port"`
 	TrickleInterval      time.Duration `long:"trickleinterval" description:"Minimum time between attempts to send new inventory to a connected peer"`
+	TrickleScalePeers    int           `long:"tricklescalepeers" description:"Number of connected peers above which the trickle interval is scaled up in proportion to the number of peers, up to four times the trickleinterval option, to reduce redundant announcements -- 0 to disable"`
+	TxAnnounceDelay      time.Duration `long:"txannouncedelay" description:"Minimum time new transactions are held back before they are announced to a connected peer, which gives the peer a chance to announce them first"`
+	MaxInvBatch          int           `long:"maxinvbatch" description:"Max number of inventory items to send to a connected peer in a single inv message"`
+	MaxPeerTxRate        float64       `long:"maxpeertxrate" description:"Max number of announced transactions per second to request from a single peer -- 0 to disable the limit"`
+	PeerTxBurst          int           `long:"peertxburst" description:"Max number of announced transactions to request from a single peer in a burst above the maxpeertxrate limit"`
 	UtxoCacheMaxSizeMiB  uint          `long:"utxocachemaxsize" description:"The maximum size in MiB of the UTXO cache"`
+	MaxMemoryMiB         uint          `long:"maxmemory" description:"Soft limit in MiB of the memory used by btcd, which sets the memory limit of the Go runtime unless GOMEMLIMIT is set and sizes the UTXO cache and the signature cache which are not set explicitly to fit -- 0 for no limit"`
+	DbCacheMiB           uint          `long:"dbcache" description:"Alias for utxocachemaxsize which takes precedence over it when set"`
+	UtxoFlushInterval    time.Duration `long:"utxocacheflushinterval" description:"Interval at which the UTXO cache is flushed to the database once the chain is synced -- the cache is always flushed when it is full and on shutdown"`
 	TxIndex              bool          `long:"txindex" description:"Maintain a full hash-based transaction index which makes all transactions available via the getrawtransaction RPC"`
 	V2Transport          bool          `long:"v2transport" description:"Enable P2P v2 encrypted transport protocol (BIP324) (default: false)"`
 	UserAgentComments    []string      `long:"uacomment" description:"Comment to add to the user agent -- See BIP 14 for more information."`
 	Upnp                 bool          `long:"upnp" description:"Use UPnP to map our listening port outside of NAT"`
+	NATPMP               bool          `long:"natpmp" description:"Use NAT-PMP or PCP to map our listening port outside of NAT"`
 	ShowVersion          bool          `short:"V" long:"version" description:"Display version information and exit"`
-	Whitelists           []string      `long:"whitelist" description:"Add an IP network or IP that will not be banned. (eg. 192.168.1.0/24 or ::1)"`
+	WhiteBinds           []string      `long:"whitebind" description:"Add an interface/port to listen for connections and grant permissions to the peers connecting to it.  Permissions are given as a comma separated list before an @ and default to noban,relay,mempool (eg. noban,bloomfilter@127.0.0.1:8333)"`
+	Whitelists           []string      `long:"whitelist" description:"Add an IP network or IP and grant permissions to the peers in it.  Valid permissions are noban, forcerelay, relay, mempool, and bloomfilter.  They are given as a comma separated list before an @ and default to noban,relay,mempool (eg. 192.168.1.0/24, ::1, or relay,mempool@10.0.0.1)"`
 	lookup               func(string) ([]net.IP, error)
 	oniondial            func(string, string, time.Duration) (net.Conn, error)
+	ipv4dial             func(string, string, time.Duration) (net.Conn, error)
+	ipv6dial             func(string, string, time.Duration) (net.Conn, error)
 	dial                 func(string, string, time.Duration) (net.Conn, error)
 	addCheckpoints       []chaincfg.Checkpoint
 	miningAddrs          []address.Address
+	miningPayouts        []mining.Payout
+	signalBits           uint32
 	minRelayTxFee        btcutil.Amount
-	whitelists           []*net.IPNet
+	minimumChainWork     *big.Int
+	assumeValid          *chainhash.Hash
+	misbehaviorReactions [numMisbehaviorCategories]misbehaviorReaction
+	blockMinTxFee        btcutil.Amount
+	dustRelayFee         btcutil.Amount
+	whitelists           []whitelist
+	whitebinds           []whitebind
+	onlyNets             map[string]struct{}
+	seedListPubKey       *btcec.PublicKey
+	sigCacheEviction     txscript.EvictionPolicy
 }
 
 // serviceOptions defines the configuration options for the daemon as a service on
@@ -383,6 +537,42 @@ func parseCheckpoints(checkpointStrings []string) ([]chaincfg.Checkpoint, error)
 	return checkpoints, nil
 }
 
+// parseMiningAddr parses mining addresses in the '<address>[:<percent>]'
+// format.  It returns the decoded address along with the share of the coinbase
+// value it receives as a payout weight and whether or not a share was
+// specified.  The share is a percentage with at most two decimal places.
+func parseMiningAddr(miningAddr string, params *chaincfg.Params) (address.Address,
+	uint32, bool, error) {
+
+	strAddr, strPercent, hasWeight := strings.Cut(miningAddr, ":")
+	addr, err := address.DecodeAddress(strAddr, params)
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("mining address '%s' failed "+
+			"to decode: %v", strAddr, err)
+	}
+	if !addr.IsForNet(params) {
+		return nil, 0, false, fmt.Errorf("mining address '%s' is on "+
+			"the wrong network", strAddr)
+	}
+	if !hasWeight {
+		return addr, 0, false, nil
+	}
+
+	percent, err := strconv.ParseFloat(strPercent, 64)
+	if err != nil || percent <= 0 || percent > 100 {
+		return nil, 0, false, fmt.Errorf("mining address '%s' has an "+
+			"invalid share of '%s' -- use a percentage greater than "+
+			"0 and at most 100", strAddr, strPercent)
+	}
+	weight := math.Round(percent * mining.PayoutWeightTotal / 100)
+	if math.Abs(weight-percent*mining.PayoutWeightTotal/100) > 1e-6 {
+		return nil, 0, false, fmt.Errorf("mining address '%s' has a "+
+			"share of '%s' with more than two decimal places",
+			strAddr, strPercent)
+	}
+	return addr, uint32(weight), true, nil
+}
+
 // fileExists reports whether the named file or directory exists.
 func fileExists(name string) bool {
 	if _, err := os.Stat(name); err != nil {
@@ -402,24 +592,102 @@ func newConfigParser(cfg *config, so *serviceOptions, options flags.Options) *fl
 	return parser
 }
 
+// envOptionPrefix is the prefix of the names of the environment variables which
+// set options.  The name of the variable for an option is the prefix followed
+// by the long name of the option in upper case, such as LBCD_RPCPASS.
+const envOptionPrefix = "LBCD_"
+
+// envListSeparator separates the values of an environment variable which sets
+// an option that may be specified multiple times.  Commas can't be used since
+// they are part of the values of some of these options, such as the
+// permissions of a whitelisted address.
+const envListSeparator = ";"
+
+// applyEnvOptions sets the options of the passed parser from the environment
+// variables named after them.  The value of a variable is interpreted the same
+// way as the value of the option in the config file.  Options which may be
+// specified multiple times take a list of values separated by envListSeparator,
+// which replaces any values set before.
+//
+// The names of the variables with the prefix which don't name an option are
+// returned so the caller can warn about them.
+func applyEnvOptions(parser *flags.Parser) ([]string, error) {
+	var unknown []string
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		if !strings.HasPrefix(name, envOptionPrefix) {
+			continue
+		}
+		longName := strings.ToLower(strings.TrimPrefix(name,
+			envOptionPrefix))
+		option := parser.FindOptionByLongName(longName)
+		if option == nil {
+			unknown = append(unknown, name)
+			continue
+		}
+
+		// Set the option as if it was read from a config file so its
+		// value is parsed the same way.  Each variable is parsed on its
+		// own so the values of a list replace the values set before
+		// rather than being added to them.
+		values := []string{value}
+		if option.Field().Type.Kind() == reflect.Slice {
+			values = strings.Split(value, envListSeparator)
+		}
+		var b strings.Builder
+		for _, v := range values {
+			fmt.Fprintf(&b, "%s=%s\n", longName,
+				strconv.Quote(strings.TrimSpace(v)))
+		}
+		err := flags.NewIniParser(parser).Parse(strings.NewReader(b.String()))
+		if err != nil {
+			if e, ok := err.(*flags.IniError); ok {
+				err = errors.New(e.Message)
+			}
+			return nil, fmt.Errorf("invalid value for the %s "+
+				"environment variable: %v", name, err)
+		}
+	}
+
+	return unknown, nil
+}
+
+// memoryBudgetCacheSizes returns the size in MiB of the UTXO cache and the
+// number of entries of the signature cache which fit in the passed memory
+// budget in MiB.
+func memoryBudgetCacheSizes(maxMemoryMiB uint) (uint, uint) {
+	utxoCacheMiB := maxMemoryMiB / utxoCacheMemoryShare
+	sigCacheSize := uint(uint64(maxMemoryMiB) * 1024 * 1024 /
+		sigCacheMemoryShare / sigCacheEntryBytes)
+	return utxoCacheMiB, sigCacheSize
+}
+
 // loadConfig initializes and parses the config using a config file and command
 // line options.
 //
 // The configuration proceeds as follows:
 //  1. Start with a default config with sane settings
-//  2. Pre-parse the command line to check for an alternative config file
+//  2. Pre-parse the environment variables and the command line to check for an
+//     alternative config file
 //  3. Load configuration file overwriting defaults with any specified options
-//  4. Parse CLI options and overwrite/add any specified options
+//  4. Apply the options set by LBCD_* environment variables
+//  5. Parse CLI options and overwrite/add any specified options
 //
 // The above results in btcd functioning properly without any config settings
-// while still allowing the user to override settings with config files and
-// command line options.  Command line options always take precedence.
+// while still allowing the user to override settings with config files,
+// environment variables and command line options.  Command line options always
+// take precedence, followed by environment variables.
 func loadConfig() (*config, []string, error) {
 	// Default config.
 	cfg := config{
 		ConfigFile:           defaultConfigFile,
 		DebugLevel:           defaultLogLevel,
 		MaxPeers:             defaultMaxPeers,
+		BlockRelayOnlyPeers:  defaultBlockRelayOnlyPeers,
+		MaxClockSkew:         defaultMaxClockSkew,
+		MinDiskSpaceMiB:      defaultMinDiskSpaceMiB,
+		OutboundPeers:        defaultOutboundPeers,
+		OnionPeers:           defaultOnionPeers,
 		BanDuration:          defaultBanDuration,
 		BanThreshold:         defaultBanThreshold,
 		RPCMaxClients:        defaultMaxRPCClients,
@@ -427,21 +695,45 @@ func loadConfig() (*config, []string, error) {
 		RPCMaxConcurrentReqs: defaultMaxRPCConcurrentReqs,
 		DataDir:              defaultDataDir,
 		LogDir:               defaultLogDir,
+		LogMaxFiles:          defaultLogMaxFiles,
+		LogMaxSize:           defaultLogMaxSizeMiB,
 		DbType:               defaultDbType,
 		RPCKey:               defaultRPCKeyFile,
 		RPCCert:              defaultRPCCertFile,
 		MinRelayTxFee:        mempool.DefaultMinRelayTxFee.ToBTC(),
+		MaxStdTxWeight:       mempool.DefaultMaxStandardTxWeight,
+		BytesPerSigOp:        mempool.DefaultBytesPerSigOp,
+		DataCarrierSize:      mempool.DefaultMaxDataCarrierSize,
+		MaxClaimValueSize:    mempool.DefaultMaxClaimValueSize,
 		FreeTxRelayLimit:     defaultFreeTxRelayLimit,
 		TrickleInterval:      defaultTrickleInterval,
+		TrickleScalePeers:    defaultTrickleScalePeers,
+		TxAnnounceDelay:      defaultTxAnnounceDelay,
+		MaxInvBatch:          defaultMaxInvBatch,
+		MaxPeerTxRate:        defaultMaxPeerTxRate,
+		PeerTxBurst:          defaultPeerTxBurst,
 		BlockMinSize:         defaultBlockMinSize,
 		BlockMaxSize:         defaultBlockMaxSize,
 		BlockMinWeight:       defaultBlockMinWeight,
 		BlockMaxWeight:       defaultBlockMaxWeight,
 		BlockPrioritySize:    mempool.DefaultBlockPrioritySize,
+		BlockMaxSigOpCost:    blockchain.MaxBlockSigOpsCost,
 		MaxOrphanTxs:         defaultMaxOrphanTransactions,
+		MaxOrphanTxsPerPeer:  defaultMaxOrphanTxsPerPeer,
+		MaxOrphanWeight:      defaultMaxOrphanWeight,
+		OrphanTTL:            defaultOrphanTTL,
+		MempoolExpiry:        defaultMempoolExpiry,
 		SigCacheMaxSize:      defaultSigCacheMaxSize,
+		SigCacheEviction:     defaultSigCacheEviction,
+		StaleTipFactor:       defaultStaleTipFactor,
+		CheckBlocks:          defaultCheckBlocks,
+		CheckLevel:           defaultCheckLevel,
 		UtxoCacheMaxSizeMiB:  defaultUtxoCacheMaxSizeMiB,
+		UtxoFlushInterval:    defaultUtxoFlushInterval,
 		Generate:             defaultGenerate,
+		StratumDifficulty:    defaultStratumDifficulty,
+		StratumMinDifficulty: defaultStratumMinDifficulty,
+		StratumShareTime:     defaultStratumShareTime,
 		TxIndex:              defaultTxIndex,
 		AddrIndex:            defaultAddrIndex,
 		V2Transport:          false,
@@ -450,12 +742,18 @@ func loadConfig() (*config, []string, error) {
 	// Service options which are only added on Windows.
 	serviceOpts := serviceOptions{}
 
-	// Pre-parse the command line options to see if an alternative config
-	// file or the version flag was specified.  Any errors aside from the
-	// help message error can be ignored here since they will be caught by
-	// the final parse below.
+	// Pre-parse the environment variables and the command line options to
+	// see if an alternative config file or the version flag was specified.
+	// Invalid environment variables are reported right away since the
+	// config file they select would be wrong.  Any command line errors
+	// aside from the help message error can be ignored here since they
+	// will be caught by the final parse below.
 	preCfg := cfg
 	preParser := newConfigParser(&preCfg, &serviceOpts, flags.HelpFlag)
+	if _, err := applyEnvOptions(preParser); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, err
+	}
 	_, err := preParser.Parse()
 	if err != nil {
 		if e, ok := err.(*flags.Error); ok && e.Type == flags.ErrHelp {
@@ -515,6 +813,15 @@ func loadConfig() (*config, []string, error) {
 		cfg.AddPeers = nil
 	}
 
+	// Apply the environment variables after the config file so they take
+	// precedence over it.
+	unknownEnv, err := applyEnvOptions(parser)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Parse command line options again to ensure they take precedence.
 	remainingArgs, err := parser.Parse()
 	if err != nil {
@@ -654,20 +961,71 @@ func loadConfig() (*config, []string, error) {
 	cfg.DataDir = cleanAndExpandPath(cfg.DataDir)
 	cfg.DataDir = filepath.Join(cfg.DataDir, netName(activeNetParams))
 
+	// Namespace the directory of the block files per network in the same
+	// fashion when they are stored separately.
+	if cfg.BlocksDir != "" {
+		cfg.BlocksDir = cleanAndExpandPath(cfg.BlocksDir)
+		cfg.BlocksDir = filepath.Join(cfg.BlocksDir,
+			netName(activeNetParams))
+	}
+
 	// Append the network type to the log directory so it is "namespaced"
 	// per network in the same fashion as the data directory.
 	cfg.LogDir = cleanAndExpandPath(cfg.LogDir)
 	cfg.LogDir = filepath.Join(cfg.LogDir, netName(activeNetParams))
 
+	if cfg.ASMap != "" {
+		cfg.ASMap = cleanAndExpandPath(cfg.ASMap)
+	}
+	if cfg.CheckpointFile != "" {
+		cfg.CheckpointFile = cleanAndExpandPath(cfg.CheckpointFile)
+	}
+	for i, path := range cfg.LoadBlocks {
+		cfg.LoadBlocks[i] = cleanAndExpandPath(path)
+	}
+
 	// Special show command to list supported subsystems and exit.
 	if cfg.DebugLevel == "show" {
 		fmt.Println("Supported subsystems", supportedSubsystems())
 		os.Exit(0)
 	}
 
-	// Initialize log rotation.  After log rotation has been initialized, the
-	// logger variables may be used.
-	initLogRotator(filepath.Join(cfg.LogDir, defaultLogFilename))
+	// Validate the log rotation options.
+	if cfg.LogMaxSize < 1 {
+		str := "%s: The logmaxsize option must be at least 1 MiB -- " +
+			"parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.LogMaxSize)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.LogMaxFiles < 0 {
+		str := "%s: The logmaxfiles option may not be negative -- " +
+			"parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.LogMaxFiles)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.LogMaxAge < 0 {
+		str := "%s: The logmaxage option may not be negative -- " +
+			"parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.LogMaxAge)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Initialize log rotation unless logging to files is disabled.  After
+	// log rotation has been initialized, the logger variables may be used.
+	if !cfg.NoFileLogging {
+		logFile := filepath.Join(cfg.LogDir, defaultLogFilename)
+		initLogRotator(logFile, cfg.LogMaxSize, cfg.LogMaxFiles,
+			!cfg.NoLogCompress)
+		if cfg.LogMaxAge > 0 {
+			go logPruneHandler(logFile, cfg.LogMaxAge)
+		}
+	}
 
 	// Parse, validate, and set debug log level(s).
 	if err := parseAndSetDebugLevels(cfg.DebugLevel); err != nil {
@@ -708,18 +1066,91 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Validate the reactions to peer misbehavior.
+	for _, value := range cfg.Misbehavior {
+		category, reaction, err := parseMisbehaviorReaction(value)
+		if err != nil {
+			str := "%s: The misbehavior value of '%s' is invalid: %v"
+			err = fmt.Errorf(str, funcName, value, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		cfg.misbehaviorReactions[category] = reaction
+	}
+
+	// Don't allow a negative number of block-relay-only peers.
+	if cfg.BlockRelayOnlyPeers < 0 {
+		str := "%s: The blockrelayonlypeers option may not be " +
+			"negative -- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.BlockRelayOnlyPeers)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Don't allow a negative shutdown timeout.
+	if cfg.ShutdownTimeout < 0 {
+		str := "%s: The shutdowntimeout option may not be negative " +
+			"-- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.ShutdownTimeout)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Don't allow a negative maximum clock skew.
+	if cfg.MaxClockSkew < 0 {
+		str := "%s: The maxclockskew option may not be negative " +
+			"-- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.MaxClockSkew)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Don't allow a negative number of regular outbound peers.
+	if cfg.OutboundPeers < 0 {
+		str := "%s: The outboundpeers option may not be negative " +
+			"-- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.OutboundPeers)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Don't allow a negative number of onion peers.
+	if cfg.OnionPeers < 0 {
+		str := "%s: The onionpeers option may not be negative " +
+			"-- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.OnionPeers)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Validate any given whitelisted IP addresses and networks.
 	if len(cfg.Whitelists) > 0 {
 		var ip net.IP
-		cfg.whitelists = make([]*net.IPNet, 0, len(cfg.Whitelists))
+		cfg.whitelists = make([]whitelist, 0, len(cfg.Whitelists))
+
+		for _, value := range cfg.Whitelists {
+			perms, addr, err := parsePermissions(value)
+			if err != nil {
+				str := "%s: The whitelist value of '%s' is " +
+					"invalid: %v"
+				err = fmt.Errorf(str, funcName, value, err)
+				fmt.Fprintln(os.Stderr, err)
+				fmt.Fprintln(os.Stderr, usageMessage)
+				return nil, nil, err
+			}
 
-		for _, addr := range cfg.Whitelists {
 			_, ipnet, err := net.ParseCIDR(addr)
 			if err != nil {
 				ip = net.ParseIP(addr)
 				if ip == nil {
 					str := "%s: The whitelist value of '%s' is invalid"
-					err = fmt.Errorf(str, funcName, addr)
+					err = fmt.Errorf(str, funcName, value)
 					fmt.Fprintln(os.Stderr, err)
 					fmt.Fprintln(os.Stderr, usageMessage)
 					return nil, nil, err
@@ -736,7 +1167,78 @@ func loadConfig() (*config, []string, error) {
 					Mask: net.CIDRMask(bits, bits),
 				}
 			}
-			cfg.whitelists = append(cfg.whitelists, ipnet)
+			cfg.whitelists = append(cfg.whitelists, whitelist{
+				ipnet:       ipnet,
+				permissions: perms,
+			})
+		}
+	}
+
+	// Validate any given whitebind addresses and listen on them in
+	// addition to the other listeners.
+	if len(cfg.WhiteBinds) > 0 {
+		if cfg.DisableListen {
+			str := "%s: the --whitebind and --nolisten options can " +
+				"not be mixed"
+			err := fmt.Errorf(str, funcName)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+
+		cfg.whitebinds = make([]whitebind, 0, len(cfg.WhiteBinds))
+		for _, value := range cfg.WhiteBinds {
+			perms, addr, err := parsePermissions(value)
+			if err == nil && addr == "" {
+				err = errors.New("missing address")
+			}
+			if err != nil {
+				str := "%s: The whitebind value of '%s' is " +
+					"invalid: %v"
+				err = fmt.Errorf(str, funcName, value, err)
+				fmt.Fprintln(os.Stderr, err)
+				fmt.Fprintln(os.Stderr, usageMessage)
+				return nil, nil, err
+			}
+
+			addr = normalizeAddress(addr, activeNetParams.DefaultPort)
+			cfg.whitebinds = append(cfg.whitebinds, whitebind{
+				addr:        addr,
+				permissions: perms,
+			})
+			cfg.Listeners = append(cfg.Listeners, addr)
+		}
+	}
+
+	// Validate the seed list options.  The URL and the public key it must
+	// be signed with have to be specified together.
+	if (cfg.SeedListURL == "") != (cfg.SeedListPubKey == "") {
+		str := "%s: the --seedlisturl and --seedlistpubkey options " +
+			"must be specified together"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.SeedListURL != "" {
+		if !strings.HasPrefix(cfg.SeedListURL, "https://") {
+			str := "%s: the seed list URL '%s' must use https"
+			err := fmt.Errorf(str, funcName, cfg.SeedListURL)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+
+		pubKeyBytes, err := hex.DecodeString(cfg.SeedListPubKey)
+		if err == nil {
+			cfg.seedListPubKey, err = schnorr.ParsePubKey(pubKeyBytes)
+		}
+		if err != nil {
+			str := "%s: the seed list public key '%s' is invalid: %v"
+			err := fmt.Errorf(str, funcName, cfg.SeedListPubKey, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
 		}
 	}
 
@@ -750,6 +1252,15 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// --torcontrol with --proxy and without --listen only listens on the
+	// loopback interface for connections forwarded by the onion service so
+	// no clearnet connections are accepted.
+	if cfg.TorControl != "" && cfg.Proxy != "" && len(cfg.Listeners) == 0 {
+		cfg.Listeners = []string{
+			net.JoinHostPort("127.0.0.1", activeNetParams.DefaultPort),
+		}
+	}
+
 	// --proxy or --connect without --listen disables listening.
 	if (cfg.Proxy != "" || len(cfg.ConnectPeers) > 0) &&
 		len(cfg.Listeners) == 0 {
@@ -832,6 +1343,216 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Validate the minimumchainwork.
+	if cfg.MinimumChainWork != "" {
+		work, ok := new(big.Int).SetString(
+			strings.TrimPrefix(cfg.MinimumChainWork, "0x"), 16)
+		if !ok || work.Sign() < 0 {
+			str := "%s: invalid minimumchainwork: %q is not a " +
+				"hex number"
+			err := fmt.Errorf(str, funcName, cfg.MinimumChainWork)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		cfg.minimumChainWork = work
+	}
+
+	// Validate the blockmintxfee.
+	cfg.blockMinTxFee, err = btcutil.NewAmount(cfg.BlockMinTxFee)
+	if err != nil || cfg.blockMinTxFee < 0 {
+		str := "%s: invalid blockmintxfee: %v"
+		err := fmt.Errorf(str, funcName, cfg.BlockMinTxFee)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Validate the dustrelayfee.
+	cfg.dustRelayFee, err = btcutil.NewAmount(cfg.DustRelayFee)
+	if err != nil || cfg.dustRelayFee < 0 {
+		str := "%s: invalid dustrelayfee: %v"
+		err := fmt.Errorf(str, funcName, cfg.DustRelayFee)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Limit the max standard transaction weight to a sane value.
+	if cfg.MaxStdTxWeight < maxStdTxWeightMin ||
+		cfg.MaxStdTxWeight > blockchain.MaxBlockWeight {
+
+		str := "%s: The maxstdtxweight option must be in between %d " +
+			"and %d -- parsed [%d]"
+		err := fmt.Errorf(str, funcName, maxStdTxWeightMin,
+			blockchain.MaxBlockWeight, cfg.MaxStdTxWeight)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Don't allow a negative number of bytes per signature operation.
+	if cfg.BytesPerSigOp < 0 {
+		str := "%s: The bytespersigop option may not be negative " +
+			"-- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.BytesPerSigOp)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Limit the data carrier size to a sane value.
+	if cfg.DataCarrierSize < 0 ||
+		cfg.DataCarrierSize > txscript.MaxScriptSize {
+
+		str := "%s: The datacarriersize option must be in between 0 " +
+			"and %d -- parsed [%d]"
+		err := fmt.Errorf(str, funcName, txscript.MaxScriptSize,
+			cfg.DataCarrierSize)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Limit the claim value size to what fits in a claim script.
+	if cfg.MaxClaimValueSize < 0 ||
+		cfg.MaxClaimValueSize > txscript.MaxClaimScriptSize {
+
+		str := "%s: The maxclaimvaluesize option must be in between 0 " +
+			"and %d -- parsed [%d]"
+		err := fmt.Errorf(str, funcName, txscript.MaxClaimScriptSize,
+			cfg.MaxClaimValueSize)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Don't allow a negative trickle peer threshold or announcement delay.
+	if cfg.TrickleScalePeers < 0 {
+		str := "%s: The tricklescalepeers option may not be negative " +
+			"-- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.TrickleScalePeers)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.TxAnnounceDelay < 0 {
+		str := "%s: The txannouncedelay option may not be negative " +
+			"-- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.TxAnnounceDelay)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	if cfg.ScriptWorkers < 0 {
+		str := "%s: The scriptworkers option may not be negative " +
+			"-- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.ScriptWorkers)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Validate the signature cache eviction policy.
+	switch cfg.SigCacheEviction {
+	case txscript.EvictRandom.String():
+		cfg.sigCacheEviction = txscript.EvictRandom
+	case txscript.EvictOldest.String():
+		cfg.sigCacheEviction = txscript.EvictOldest
+	default:
+		str := "%s: The sigcacheeviction option must be either " +
+			"random or oldest -- parsed [%s]"
+		err := fmt.Errorf(str, funcName, cfg.SigCacheEviction)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	if cfg.CheckBlocks < 0 {
+		str := "%s: The checkblocks option may not be negative " +
+			"-- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.CheckBlocks)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.CheckLevel < 0 || cfg.CheckLevel > 1 {
+		str := "%s: The checklevel option must be 0 or 1 -- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.CheckLevel)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// The dbcache option is an alias for the UTXO cache size.
+	if cfg.DbCacheMiB != 0 {
+		cfg.UtxoCacheMaxSizeMiB = cfg.DbCacheMiB
+	}
+
+	// Size the caches which are not set explicitly to fit in the memory
+	// budget when one is given.
+	if cfg.MaxMemoryMiB != 0 {
+		if cfg.MaxMemoryMiB < maxMemoryMinMiB {
+			str := "%s: The maxmemory option must be at least %d " +
+				"MiB -- parsed [%d]"
+			err := fmt.Errorf(str, funcName, maxMemoryMinMiB,
+				cfg.MaxMemoryMiB)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+
+		utxoCacheMiB, sigCacheSize := memoryBudgetCacheSizes(
+			cfg.MaxMemoryMiB)
+		if !parser.FindOptionByLongName("utxocachemaxsize").IsSet() &&
+			cfg.DbCacheMiB == 0 {
+
+			cfg.UtxoCacheMaxSizeMiB = utxoCacheMiB
+		}
+		if !parser.FindOptionByLongName("sigcachemaxsize").IsSet() {
+			cfg.SigCacheMaxSize = sigCacheSize
+		}
+	}
+	if cfg.UtxoFlushInterval <= 0 {
+		str := "%s: The utxocacheflushinterval option must be positive " +
+			"-- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.UtxoFlushInterval)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Limit the inventory batch size to the max allowed by the protocol.
+	if cfg.MaxInvBatch < 1 || cfg.MaxInvBatch > wire.MaxInvPerMsg {
+		str := "%s: The maxinvbatch option must be in between 1 " +
+			"and %d -- parsed [%d]"
+		err := fmt.Errorf(str, funcName, wire.MaxInvPerMsg,
+			cfg.MaxInvBatch)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Don't allow a negative transaction relay rate or a burst which
+	// wouldn't allow any transactions when the rate limit is enabled.
+	if cfg.MaxPeerTxRate < 0 {
+		str := "%s: The maxpeertxrate option may not be negative " +
+			"-- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.MaxPeerTxRate)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.MaxPeerTxRate > 0 && cfg.PeerTxBurst < 1 {
+		str := "%s: The peertxburst option must be at least 1 " +
+			"-- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.PeerTxBurst)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Limit the max block size to a sane value.
 	if cfg.BlockMaxSize < blockMaxSizeMin || cfg.BlockMaxSize >
 		blockMaxSizeMax {
@@ -858,6 +1579,17 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Limit the max block sigop cost to the consensus limit.
+	if cfg.BlockMaxSigOpCost > blockchain.MaxBlockSigOpsCost {
+		str := "%s: The blockmaxsigopcost option must not exceed %d " +
+			"-- parsed [%d]"
+		err := fmt.Errorf(str, funcName, blockchain.MaxBlockSigOpsCost,
+			cfg.BlockMaxSigOpCost)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Limit the max orphan count to a sane vlue.
 	if cfg.MaxOrphanTxs < 0 {
 		str := "%s: The maxorphantx option may not be less than 0 " +
@@ -868,10 +1600,71 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Limit the per-peer orphan count and orphan weight to sane values.
+	if cfg.MaxOrphanTxsPerPeer < 0 {
+		str := "%s: The maxorphantxperpeer option may not be less " +
+			"than 0 -- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.MaxOrphanTxsPerPeer)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.StaleTipFactor < 0 {
+		str := "%s: The staletipfactor option may not be less than 0 " +
+			"-- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.StaleTipFactor)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.MaxOrphanWeight < 0 {
+		str := "%s: The maxorphanweight option may not be less than 0 " +
+			"-- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.MaxOrphanWeight)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Don't allow orphan expiry durations that are too short.
+	if cfg.OrphanTTL < time.Minute {
+		str := "%s: The orphanttl option may not be less than 1m -- " +
+			"parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.OrphanTTL)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Don't allow negative mempool expiry durations.
+	if cfg.MempoolExpiry < 0 {
+		str := "%s: The mempoolexpiry option may not be negative -- " +
+			"parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.MempoolExpiry)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Ensure all of the requested mempool policy hooks are registered.
+	for _, name := range cfg.PolicyHooks {
+		if mempool.LookupPolicyHook(name) == nil {
+			str := "%s: The policyhook option must be one of %v " +
+				"-- parsed [%v]"
+			err := fmt.Errorf(str, funcName,
+				mempool.PolicyHookNames(), name)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
+
 	// Limit the block priority and minimum block sizes to max block size.
 	cfg.BlockPrioritySize = minUint32(cfg.BlockPrioritySize, cfg.BlockMaxSize)
 	cfg.BlockMinSize = minUint32(cfg.BlockMinSize, cfg.BlockMaxSize)
 	cfg.BlockMinWeight = minUint32(cfg.BlockMinWeight, cfg.BlockMaxWeight)
+	cfg.ClaimPriorityWeight = minUint32(cfg.ClaimPriorityWeight,
+		cfg.BlockMaxWeight)
 
 	switch {
 	// If the max block size isn't set, but the max weight is, then we'll
@@ -934,25 +1727,89 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
-	// Check mining addresses are valid and saved parsed versions.
+	// --claimfilters and --dropclaimfilters do not mix.
+	if cfg.ClaimFilters && cfg.DropClaimFilters {
+		err := fmt.Errorf("%s: the --claimfilters and --dropclaimfilters "+
+			"options may not be activated at the same time",
+			funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// --claimindex and --dropclaimindex do not mix.
+	if cfg.ClaimIndex && cfg.DropClaimIndex {
+		err := fmt.Errorf("%s: the --claimindex and --dropclaimindex "+
+			"options may not be activated at the same time",
+			funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// --spentindex and --dropspentindex do not mix.
+	if cfg.SpentIndex && cfg.DropSpentIndex {
+		err := fmt.Errorf("%s: the --spentindex and --dropspentindex "+
+			"options may not be activated at the same time",
+			funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Check mining addresses are valid and saved parsed versions.  When
+	// the addresses specify their share of the coinbase value, generated
+	// blocks split the coinbase between all of them, so the shares must be
+	// specified for every address and add up to 100%.
 	cfg.miningAddrs = make([]address.Address, 0, len(cfg.MiningAddrs))
-	for _, strAddr := range cfg.MiningAddrs {
-		addr, err := address.DecodeAddress(strAddr, activeNetParams.Params)
+	var numWeighted int
+	var totalWeight uint32
+	for _, miningAddr := range cfg.MiningAddrs {
+		addr, weight, hasWeight, err := parseMiningAddr(miningAddr,
+			activeNetParams.Params)
 		if err != nil {
-			str := "%s: mining address '%s' failed to decode: %v"
-			err := fmt.Errorf(str, funcName, strAddr, err)
+			err := fmt.Errorf("%s: %v", funcName, err)
 			fmt.Fprintln(os.Stderr, err)
 			fmt.Fprintln(os.Stderr, usageMessage)
 			return nil, nil, err
 		}
-		if !addr.IsForNet(activeNetParams.Params) {
-			str := "%s: mining address '%s' is on the wrong network"
-			err := fmt.Errorf(str, funcName, strAddr)
+		cfg.miningAddrs = append(cfg.miningAddrs, addr)
+		if hasWeight {
+			cfg.miningPayouts = append(cfg.miningPayouts,
+				mining.Payout{Address: addr, Weight: weight})
+			numWeighted++
+			totalWeight += weight
+		}
+	}
+	if numWeighted > 0 && numWeighted != len(cfg.MiningAddrs) {
+		str := "%s: either all or none of the mining addresses must " +
+			"specify their share of the coinbase"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if numWeighted > 0 && totalWeight != mining.PayoutWeightTotal {
+		str := "%s: the shares of the mining addresses must add up " +
+			"to 100%% -- parsed [%.2f%%]"
+		err := fmt.Errorf(str, funcName,
+			float64(totalWeight)*100/mining.PayoutWeightTotal)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Validate the version bits to signal in generated blocks.
+	for _, bit := range cfg.SignalBits {
+		if bit > mining.MaxSignalBit {
+			str := "%s: The signalbit option must be between 0 " +
+				"and %d -- parsed [%d]"
+			err := fmt.Errorf(str, funcName, mining.MaxSignalBit, bit)
 			fmt.Fprintln(os.Stderr, err)
 			fmt.Fprintln(os.Stderr, usageMessage)
 			return nil, nil, err
 		}
-		cfg.miningAddrs = append(cfg.miningAddrs, addr)
+		cfg.signalBits |= 1 << bit
 	}
 
 	// Ensure there is at least one mining address when the generate flag is
@@ -966,6 +1823,89 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Ensure there is at least one mining address when the stratum server
+	// is enabled.
+	if len(cfg.StratumListeners) > 0 && len(cfg.MiningAddrs) == 0 {
+		str := "%s: the stratumlisten option is set, but there are no " +
+			"mining addresses specified "
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Validate the stratum share difficulty settings.
+	if cfg.StratumMinDifficulty <= 0 {
+		str := "%s: The stratummindiff option must be greater than 0 " +
+			"-- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.StratumMinDifficulty)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.StratumMaxDifficulty < 0 || (cfg.StratumMaxDifficulty > 0 &&
+		cfg.StratumMaxDifficulty < cfg.StratumMinDifficulty) {
+
+		str := "%s: The stratummaxdiff option must be 0 or at least " +
+			"stratummindiff -- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.StratumMaxDifficulty)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.StratumDifficulty < cfg.StratumMinDifficulty ||
+		(cfg.StratumMaxDifficulty > 0 &&
+			cfg.StratumDifficulty > cfg.StratumMaxDifficulty) {
+
+		str := "%s: The stratumdiff option must be between " +
+			"stratummindiff and stratummaxdiff -- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.StratumDifficulty)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.StratumShareTime < time.Second {
+		str := "%s: The stratumsharetime option may not be less than " +
+			"1s -- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.StratumShareTime)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Validate the DNS seeder settings.
+	if cfg.DNSSeeder {
+		if !dnsseeder.ValidHostname(cfg.DNSSeederHost) {
+			str := "%s: The dnsseeder option requires a valid " +
+				"dnsseederhost -- parsed [%v]"
+			err := fmt.Errorf(str, funcName, cfg.DNSSeederHost)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		if cfg.DNSSeederNS != "" &&
+			!dnsseeder.ValidHostname(cfg.DNSSeederNS) {
+
+			str := "%s: The dnsseederns option is not a valid host " +
+				"name -- parsed [%v]"
+			err := fmt.Errorf(str, funcName, cfg.DNSSeederNS)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		if len(cfg.ConnectPeers) > 0 || cfg.SimNet {
+			str := "%s: The dnsseeder option can't be used in " +
+				"connect-only mode"
+			err := fmt.Errorf(str, funcName)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		if len(cfg.DNSSeederListeners) == 0 {
+			cfg.DNSSeederListeners = []string{":" + defaultDNSSeederPort}
+		}
+	}
+
 	// Add default port to all listener addresses if needed and remove
 	// duplicate addresses.
 	cfg.Listeners = normalizeAddresses(cfg.Listeners,
@@ -976,6 +1916,16 @@ func loadConfig() (*config, []string, error) {
 	cfg.RPCListeners = normalizeAddresses(cfg.RPCListeners,
 		activeNetParams.rpcPort)
 
+	// Add default port to all stratum listener addresses if needed and
+	// remove duplicate addresses.
+	cfg.StratumListeners = normalizeAddresses(cfg.StratumListeners,
+		defaultStratumPort)
+
+	// Add default port to all DNS seeder listener addresses if needed and
+	// remove duplicate addresses.
+	cfg.DNSSeederListeners = normalizeAddresses(cfg.DNSSeederListeners,
+		defaultDNSSeederPort)
+
 	// Only allow TLS to be disabled if the RPC is bound to localhost
 	// addresses.
 	if !cfg.DisableRPC && cfg.DisableTLS {
@@ -1032,10 +1982,68 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
-	// Tor stream isolation requires either proxy or onion proxy to be set.
-	if cfg.TorIsolation && cfg.Proxy == "" && cfg.OnionProxy == "" {
-		str := "%s: Tor stream isolation requires either proxy or " +
-			"onionproxy to be set"
+	// Load the checkpoints of the checkpoint file, which have to be signed
+	// by the key of the checkpoint signer.  The checkpoints given on the
+	// command line come last so they take precedence.
+	if cfg.CheckpointFile != "" {
+		signer, err := address.DecodeAddress(cfg.CheckpointSigner,
+			activeNetParams.Params)
+		if err == nil {
+			_, isP2PKH := signer.(*address.AddressPubKeyHash)
+			switch {
+			case !signer.IsForNet(activeNetParams.Params):
+				err = errors.New("the address is for another " +
+					"network")
+			case !isP2PKH:
+				err = errors.New("the address is not a " +
+					"pay-to-pubkey-hash address")
+			}
+		}
+		if err != nil {
+			str := "%s: The checkpointsigner option must be a " +
+				"pay-to-pubkey-hash address of the active network " +
+				"when a checkpoint file is given: %v"
+			err := fmt.Errorf(str, funcName, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+
+		checkpoints, err := loadCheckpointFile(cfg.CheckpointFile,
+			signer.EncodeAddress(), activeNetParams.Params)
+		if err != nil {
+			str := "%s: Unable to load the checkpoint file %s: %v"
+			err := fmt.Errorf(str, funcName, cfg.CheckpointFile, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		cfg.addCheckpoints = append(checkpoints, cfg.addCheckpoints...)
+	}
+
+	// Parse the block whose ancestors are assumed to have valid scripts,
+	// which defaults to the one of the active network.
+	assumeValid := cfg.AssumeValid
+	if assumeValid == "" {
+		assumeValid = activeNetParams.assumeValid
+	}
+	if assumeValid != "" && assumeValid != "0" {
+		cfg.assumeValid, err = chainhash.NewHashFromStr(assumeValid)
+		if err != nil {
+			str := "%s: invalid assumevalid: %v"
+			err := fmt.Errorf(str, funcName, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
+
+	// Tor stream isolation requires a proxy to be set.
+	if cfg.TorIsolation && cfg.Proxy == "" && cfg.OnionProxy == "" &&
+		cfg.IPv4Proxy == "" && cfg.IPv6Proxy == "" {
+
+		str := "%s: Tor stream isolation requires a proxy, " +
+			"onionproxy, ipv4proxy or ipv6proxy to be set"
 		err := fmt.Errorf(str, funcName)
 		fmt.Fprintln(os.Stderr, err)
 		fmt.Fprintln(os.Stderr, usageMessage)
@@ -1090,6 +2098,46 @@ func loadConfig() (*config, []string, error) {
 		}
 	}
 
+	// Setup the IPv4 and IPv6 address dial functions depending on the
+	// specified options.  The default is to use the same dial function
+	// selected above.  However, when a network-specific proxy is specified,
+	// addresses of that network are dialed through it instead.  This allows,
+	// for example, IPv6 traffic to be routed through a different proxy than
+	// IPv4 traffic.  The credentials of --proxyuser and --proxypass are used
+	// for the network-specific proxies as well.
+	cfg.ipv4dial = cfg.dial
+	cfg.ipv6dial = cfg.dial
+	netProxies := []struct {
+		option string
+		addr   string
+		dial   *func(string, string, time.Duration) (net.Conn, error)
+	}{
+		{"IPv4 proxy", cfg.IPv4Proxy, &cfg.ipv4dial},
+		{"IPv6 proxy", cfg.IPv6Proxy, &cfg.ipv6dial},
+	}
+	for _, netProxy := range netProxies {
+		if netProxy.addr == "" {
+			continue
+		}
+		_, _, err := net.SplitHostPort(netProxy.addr)
+		if err != nil {
+			str := "%s: %s address '%s' is invalid: %v"
+			err := fmt.Errorf(str, funcName, netProxy.option,
+				netProxy.addr, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+
+		proxy := &socks.Proxy{
+			Addr:         netProxy.addr,
+			Username:     cfg.ProxyUser,
+			Password:     cfg.ProxyPass,
+			TorIsolation: cfg.TorIsolation,
+		}
+		*netProxy.dial = proxy.DialTimeout
+	}
+
 	// Setup onion address dial function depending on the specified options.
 	// The default is to use the same dial function selected above.  However,
 	// when an onion-specific proxy is specified, the onion address dial
@@ -1146,6 +2194,73 @@ func loadConfig() (*config, []string, error) {
 		}
 	}
 
+	// Onion peers can only be reached through tor.
+	if cfg.OnionPeers > 0 && (cfg.NoOnion || (cfg.Proxy == "" &&
+		cfg.OnionProxy == "")) {
+
+		str := "%s: the --onionpeers option requires the --proxy or " +
+			"--onion option and may not be combined with --noonion"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Validate the networks outbound connections are restricted to.  Onion
+	// peers can only be reached through tor.
+	if len(cfg.OnlyNets) > 0 {
+		cfg.onlyNets = make(map[string]struct{})
+		for _, network := range cfg.OnlyNets {
+			switch network {
+			case "ipv4", "ipv6":
+			case "onion":
+				if cfg.NoOnion || (cfg.Proxy == "" &&
+					cfg.OnionProxy == "") {
+
+					str := "%s: the --onlynet=onion option " +
+						"requires the --proxy or --onion " +
+						"option and may not be combined " +
+						"with --noonion"
+					err := fmt.Errorf(str, funcName)
+					fmt.Fprintln(os.Stderr, err)
+					fmt.Fprintln(os.Stderr, usageMessage)
+					return nil, nil, err
+				}
+			default:
+				str := "%s: The specified network [%v] for " +
+					"--onlynet is invalid -- supported networks " +
+					"[ipv4 ipv6 onion]"
+				err := fmt.Errorf(str, funcName, network)
+				fmt.Fprintln(os.Stderr, err)
+				fmt.Fprintln(os.Stderr, usageMessage)
+				return nil, nil, err
+			}
+			cfg.onlyNets[network] = struct{}{}
+		}
+	}
+
+	// The onion service forwards incoming connections, so it requires
+	// listening and may not be combined with --noonion.
+	if cfg.TorControl != "" {
+		_, _, err := net.SplitHostPort(cfg.TorControl)
+		if err != nil {
+			str := "%s: Tor control address '%s' is invalid: %v"
+			err := fmt.Errorf(str, funcName, cfg.TorControl, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		if cfg.DisableListen || cfg.NoOnion {
+			str := "%s: the --torcontrol option requires " +
+				"listening for incoming connections and may " +
+				"not be combined with --noonion"
+			err := fmt.Errorf(str, funcName)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
+
 	if cfg.Prune != 0 && cfg.Prune < pruneMinSize {
 		err := fmt.Errorf("%s: the minimum value for --prune is %d. Got %d",
 			funcName, pruneMinSize, cfg.Prune)
@@ -1154,6 +2269,17 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	if cfg.PruneSpendJournal < 0 || (cfg.PruneSpendJournal != 0 &&
+		cfg.PruneSpendJournal < pruneSpendJournalMinDepth) {
+
+		err := fmt.Errorf("%s: the minimum value for "+
+			"--prunespendjournal is %d. Got %d", funcName,
+			pruneSpendJournalMinDepth, cfg.PruneSpendJournal)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	if cfg.Prune != 0 && cfg.TxIndex {
 		err := fmt.Errorf("%s: the --prune and --txindex options may "+
 			"not be activated at the same time", funcName)
@@ -1170,12 +2296,34 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	if cfg.Prune != 0 && cfg.Reindex {
+		err := fmt.Errorf("%s: the --prune and --reindex options may "+
+			"not be activated at the same time", funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Only the block files of the ffldb database can be reindexed.
+	if cfg.Reindex && cfg.DbType != "ffldb" {
+		str := "%s: the --reindex option is not supported by the %s " +
+			"database type"
+		err := fmt.Errorf(str, funcName, cfg.DbType)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Warn about missing config file only after all other configuration is
 	// done.  This prevents the warning on help messages and invalid
 	// options.  Note this should go directly before the return.
 	if configFileError != nil {
 		btcdLog.Warnf("%v", configFileError)
 	}
+	for _, name := range unknownEnv {
+		btcdLog.Warnf("Ignoring the %s environment variable which "+
+			"doesn't name an option", name)
+	}
 
 	return &cfg, remainingArgs, nil
 }
@@ -1251,13 +2399,37 @@ func createDefaultConfigFile(destinationPath string) error {
 // dial function depending on the address and configuration options.  For
 // example, .onion addresses will be dialed using the onion specific proxy if
 // one was specified, but will otherwise use the normal dial function (which
-// could itself use a proxy or not).
+// could itself use a proxy or not).  Likewise, IPv4 and IPv6 addresses are
+// dialed using the proxy specific to their network if one was specified.
 func btcdDial(addr net.Addr) (net.Conn, error) {
 	if strings.Contains(addr.String(), ".onion:") {
 		return cfg.oniondial(addr.Network(), addr.String(),
 			defaultConnectTimeout)
 	}
-	return cfg.dial(addr.Network(), addr.String(), defaultConnectTimeout)
+	dial := cfg.dial
+	host, _, err := net.SplitHostPort(addr.String())
+	if ip := net.ParseIP(host); err == nil && ip != nil {
+		dial = cfg.ipv6dial
+		if ip.To4() != nil {
+			dial = cfg.ipv4dial
+		}
+	}
+	return dial(addr.Network(), addr.String(), defaultConnectTimeout)
+}
+
+// seedListClient returns an HTTP client which fetches the seed list using the
+// dial function of the configuration so the proxy options are honored.
+func seedListClient() *http.Client {
+	return &http.Client{
+		Timeout: defaultConnectTimeout,
+		Transport: &http.Transport{
+			DialContext: func(_ context.Context, network,
+				addr string) (net.Conn, error) {
+
+				return cfg.dial(network, addr, defaultConnectTimeout)
+			},
+		},
+	}
 }
 
 // btcdLookup resolves the IP of the given host using the correct DNS lookup
@@ -1,11 +1,18 @@
 package main
 
 import (
+	"net"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"runtime"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/v2"
+	flags "github.com/jessevdk/go-flags"
 )
 
 var (
@@ -59,3 +66,170 @@ func TestCreateDefaultConfigFile(t *testing.T) {
 		t.Error("Could not find rpcpass in generated default config file.")
 	}
 }
+
+// TestParseMiningAddr ensures mining addresses with and without coinbase shares
+// are parsed as expected.
+func TestParseMiningAddr(t *testing.T) {
+	const addr = "1L6fd93zGmtzkK6CsZFVVoCwzZV3MUtJ4F"
+	tests := []struct {
+		miningAddr string
+		weight     uint32
+		hasWeight  bool
+		wantErr    bool
+	}{
+		{miningAddr: addr},
+		{miningAddr: addr + ":100", weight: 10000, hasWeight: true},
+		{miningAddr: addr + ":12.5", weight: 1250, hasWeight: true},
+		{miningAddr: addr + ":0.01", weight: 1, hasWeight: true},
+		{miningAddr: addr + ":0", wantErr: true},
+		{miningAddr: addr + ":100.5", wantErr: true},
+		{miningAddr: addr + ":33.333", wantErr: true},
+		{miningAddr: addr + ":half", wantErr: true},
+		{miningAddr: "1L6fd93zGmtzkK6CsZFVVoCwzZV3MUtJ4G:50", wantErr: true},
+		{miningAddr: "mrX9vMRYLfVy1BnZbc5gZjuyaqH3ZW2ZHz", wantErr: true},
+	}
+
+	for _, test := range tests {
+		_, weight, hasWeight, err := parseMiningAddr(test.miningAddr,
+			&chaincfg.MainNetParams)
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: unexpected error %v", test.miningAddr, err)
+			continue
+		}
+		if weight != test.weight || hasWeight != test.hasWeight {
+			t.Errorf("%s: got weight (%d, %v), want (%d, %v)",
+				test.miningAddr, weight, hasWeight, test.weight,
+				test.hasWeight)
+		}
+	}
+}
+
+// TestApplyEnvOptions ensures options set by environment variables take
+// precedence over the config file and are overridden by the command line.
+func TestApplyEnvOptions(t *testing.T) {
+	t.Setenv("LBCD_MAXPEERS", "9")
+	t.Setenv("LBCD_ADDPEER", "5.6.7.8; 9.9.9.9")
+	t.Setenv("LBCD_WHITELIST", "noban,relay@10.0.0.1;10.0.0.2")
+	t.Setenv("LBCD_NOLISTEN", "1")
+	t.Setenv("LBCD_NOONION", "false")
+	t.Setenv("LBCD_RPCPASS", `"pass word"`)
+	t.Setenv("LBCD_RPCUSER", "envuser")
+	t.Setenv("LBCD_BOGUS", "1")
+
+	var cfg config
+	var serviceOpts serviceOptions
+	parser := newConfigParser(&cfg, &serviceOpts, flags.None)
+	confFile := "maxpeers=7\naddpeer=1.2.3.4\nnoonion=1\nrpcuser=confuser\n"
+	err := flags.NewIniParser(parser).Parse(strings.NewReader(confFile))
+	if err != nil {
+		t.Fatalf("unable to parse config file: %v", err)
+	}
+
+	unknown, err := applyEnvOptions(parser)
+	if err != nil {
+		t.Fatalf("applyEnvOptions: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(unknown, []string{"LBCD_BOGUS"}) {
+		t.Errorf("unexpected unknown variables %v", unknown)
+	}
+
+	_, err = parser.ParseArgs([]string{"--rpcuser=user"})
+	if err != nil {
+		t.Fatalf("unable to parse command line: %v", err)
+	}
+
+	if cfg.MaxPeers != 9 {
+		t.Errorf("got maxpeers %d, want 9", cfg.MaxPeers)
+	}
+	wantPeers := []string{"5.6.7.8", "9.9.9.9"}
+	if !reflect.DeepEqual(cfg.AddPeers, wantPeers) {
+		t.Errorf("got addpeer %v, want %v", cfg.AddPeers, wantPeers)
+	}
+	wantWhitelists := []string{"noban,relay@10.0.0.1", "10.0.0.2"}
+	if !reflect.DeepEqual(cfg.Whitelists, wantWhitelists) {
+		t.Errorf("got whitelist %v, want %v", cfg.Whitelists,
+			wantWhitelists)
+	}
+	if !cfg.DisableListen || cfg.NoOnion {
+		t.Errorf("got nolisten %v and noonion %v, want true and false",
+			cfg.DisableListen, cfg.NoOnion)
+	}
+	if cfg.RPCUser != "user" || cfg.RPCPass != `"pass word"` {
+		t.Errorf("got rpcuser %q and rpcpass %q", cfg.RPCUser,
+			cfg.RPCPass)
+	}
+
+	// Invalid values are rejected.
+	t.Setenv("LBCD_MAXPEERS", "many")
+	parser = newConfigParser(&cfg, &serviceOpts, flags.None)
+	if _, err := applyEnvOptions(parser); err == nil {
+		t.Errorf("applyEnvOptions: expected error for invalid value")
+	}
+}
+
+// TestMemoryBudgetCacheSizes ensures the caches are sized in proportion to the
+// memory budget and that a budget of 1 GiB results in about their defaults.
+func TestMemoryBudgetCacheSizes(t *testing.T) {
+	tests := []struct {
+		maxMemoryMiB uint
+		utxoCacheMiB uint
+		sigCacheSize uint
+	}{
+		{maxMemoryMiB: 256, utxoCacheMiB: 64, sigCacheSize: 20971},
+		{maxMemoryMiB: 1024, utxoCacheMiB: 256, sigCacheSize: 83886},
+		{maxMemoryMiB: 8192, utxoCacheMiB: 2048, sigCacheSize: 671088},
+	}
+
+	for _, test := range tests {
+		utxoCacheMiB, sigCacheSize := memoryBudgetCacheSizes(
+			test.maxMemoryMiB)
+		if utxoCacheMiB != test.utxoCacheMiB ||
+			sigCacheSize != test.sigCacheSize {
+
+			t.Errorf("%d MiB: got (%d MiB, %d entries), want (%d "+
+				"MiB, %d entries)", test.maxMemoryMiB,
+				utxoCacheMiB, sigCacheSize, test.utxoCacheMiB,
+				test.sigCacheSize)
+		}
+	}
+}
+
+// TestBtcdDial ensures addresses are dialed using the dial function of their
+// network.
+func TestBtcdDial(t *testing.T) {
+	origCfg := cfg
+	defer func() { cfg = origCfg }()
+
+	var dialed string
+	dialFunc := func(name string) func(string, string,
+		time.Duration) (net.Conn, error) {
+
+		return func(string, string, time.Duration) (net.Conn, error) {
+			dialed = name
+			return nil, nil
+		}
+	}
+	cfg = &config{
+		dial:      dialFunc("default"),
+		oniondial: dialFunc("onion"),
+		ipv4dial:  dialFunc("ipv4"),
+		ipv6dial:  dialFunc("ipv6"),
+	}
+
+	tests := []struct {
+		addr net.Addr
+		want string
+	}{
+		{&net.TCPAddr{IP: net.ParseIP("1.2.3.4"), Port: 9246}, "ipv4"},
+		{&net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 9246}, "ipv6"},
+		{&onionAddr{addr: "3g2upl4pq6kufc4m.onion:9246"}, "onion"},
+		{simpleAddr{net: "tcp", addr: "seed.example.com:9246"}, "default"},
+	}
+	for _, test := range tests {
+		btcdDial(test.addr)
+		if dialed != test.want {
+			t.Errorf("%s: dialed using %s, want %s", test.addr, dialed,
+				test.want)
+		}
+	}
+}
@@ -61,6 +61,20 @@ type ConnReq struct {
 	Addr      net.Addr
 	Permanent bool
 
+	// BlockRelayOnly marks the connection as one which only relays blocks.
+	// Such connections fill the block-relay-only slots rather than the
+	// regular outbound ones.
+	BlockRelayOnly bool
+
+	// Onion marks the connection as one made to an onion address to fill
+	// the onion slots rather than the regular outbound ones.
+	Onion bool
+
+	// Feeler marks a short-lived connection made to test whether an
+	// address is reachable.  Feeler connections do not count toward any
+	// target and are never retried.
+	Feeler bool
+
 	conn       net.Conn
 	state      ConnState
 	stateMtx   sync.RWMutex
@@ -124,6 +138,16 @@ type Config struct {
 	// maintain. Defaults to 8.
 	TargetOutbound uint32
 
+	// TargetBlockRelayOnly is the number of outbound block-relay-only
+	// connections to maintain in addition to TargetOutbound.  Defaults to
+	// 0.
+	TargetBlockRelayOnly uint32
+
+	// TargetOnion is the number of outbound connections to onion addresses
+	// to maintain in addition to TargetOutbound and TargetBlockRelayOnly.
+	// Defaults to 0.
+	TargetOnion uint32
+
 	// RetryDuration is the duration to wait before retrying connection
 	// requests. Defaults to 5s.
 	RetryDuration time.Duration
@@ -140,10 +164,65 @@ type Config struct {
 	// to.  If nil, no new connections will be made automatically.
 	GetNewAddress func() (net.Addr, error)
 
+	// GetNewOnionAddress is a way to get an onion address to make a network
+	// connection to.  If nil, no onion connections will be made
+	// automatically.
+	GetNewOnionAddress func() (net.Addr, error)
+
+	// FeelerInterval is the interval at which feeler connections are made.
+	// Feeler connections are not made if it is zero or GetFeelerAddress is
+	// nil.
+	FeelerInterval time.Duration
+
+	// GetFeelerAddress is a way to get an address to make a feeler
+	// connection to.
+	GetFeelerAddress func() (net.Addr, error)
+
 	// Dial connects to the address on the named network. It cannot be nil.
 	Dial func(net.Addr) (net.Conn, error)
 }
 
+// Targets holds the number of connections of each kind the connection manager
+// maintains automatically.
+type Targets struct {
+	Outbound       uint32
+	BlockRelayOnly uint32
+	Onion          uint32
+}
+
+// connKind identifies the kinds of connections which are maintained up to a
+// target.
+type connKind int
+
+const (
+	kindOutbound connKind = iota
+	kindBlockRelayOnly
+	kindOnion
+	numConnKinds
+)
+
+// kind returns the kind of connection the connection request fills a slot of.
+func (c *ConnReq) kind() connKind {
+	switch {
+	case c.BlockRelayOnly:
+		return kindBlockRelayOnly
+	case c.Onion:
+		return kindOnion
+	}
+	return kindOutbound
+}
+
+// target returns the target number of connections of the passed kind.
+func (t *Targets) target(kind connKind) uint32 {
+	switch kind {
+	case kindBlockRelayOnly:
+		return t.BlockRelayOnly
+	case kindOnion:
+		return t.Onion
+	}
+	return t.Outbound
+}
+
 // registerPending is used to register a pending connection attempt. By
 // registering pending connection attempts we allow callers to cancel pending
 // connection attempts before their successful or in the case they're not
@@ -188,12 +267,27 @@ type handleFailed struct {
 	err error
 }
 
+// setTargets is used to change the number of connections of each kind to
+// maintain.
+type setTargets struct {
+	targets Targets
+	done    chan struct{}
+}
+
+// getTargets is used to query the number of connections of each kind which
+// are maintained.
+type getTargets struct {
+	reply chan Targets
+}
+
 // ConnManager provides a manager to handle network connections.
 type ConnManager struct {
 	// The following variables must only be used atomically.
-	connReqCount uint64
-	start        int32
-	stop         int32
+	connReqCount           uint64
+	blockRelayOnlyReqCount uint64
+	onionReqCount          uint64
+	start                  int32
+	stop                   int32
 
 	cfg            Config
 	wg             sync.WaitGroup
@@ -211,6 +305,10 @@ func (cm *ConnManager) handleFailedConn(c *ConnReq, triggerReconnect bool) {
 	if atomic.LoadInt32(&cm.stop) != 0 {
 		return
 	}
+	if c.Feeler {
+		go cm.Remove(c.id)
+		return
+	}
 	if c.Permanent || triggerReconnect {
 		c.retryCount++
 		d := time.Duration(c.retryCount) * cm.cfg.RetryDuration
@@ -221,7 +319,7 @@ func (cm *ConnManager) handleFailedConn(c *ConnReq, triggerReconnect bool) {
 		time.AfterFunc(d, func() {
 			cm.Connect(c)
 		})
-	} else if cm.cfg.GetNewAddress != nil {
+	} else if cm.addressFunc(c.kind()) != nil {
 		cm.failedAttempts++
 		if cm.failedAttempts >= maxFailedAttempts {
 			log.Debugf("Max failed connection attempts reached: [%d] "+
@@ -230,12 +328,12 @@ func (cm *ConnManager) handleFailedConn(c *ConnReq, triggerReconnect bool) {
 			theId := c.id
 			time.AfterFunc(cm.cfg.RetryDuration, func() {
 				cm.Remove(theId)
-				cm.NewConnReq()
+				cm.newConnReq(c.kind())
 			})
 		} else {
 			go func(theId uint64) {
 				cm.Remove(theId)
-				cm.NewConnReq()
+				cm.newConnReq(c.kind())
 			}(c.id)
 		}
 	}
@@ -256,6 +354,14 @@ func (cm *ConnManager) connHandler() {
 
 		// conns represents the set of all actively connected peers.
 		conns = make(map[uint64]*ConnReq, cm.cfg.TargetOutbound)
+
+		// targets holds the number of connections of each kind to
+		// maintain.
+		targets = Targets{
+			Outbound:       cm.cfg.TargetOutbound,
+			BlockRelayOnly: cm.cfg.TargetBlockRelayOnly,
+			Onion:          cm.cfg.TargetOnion,
+		}
 	)
 
 out:
@@ -338,12 +444,18 @@ out:
 				}
 
 				// Otherwise, we will attempt a reconnection if
-				// we do not have enough peers, or if this is a
-				// persistent peer. The connection request is
+				// we do not have enough peers of the same kind,
+				// or if this is a persistent peer. Feelers are
+				// never reconnected. The connection request is
 				// re added to the pending map, so that
 				// subsequent processing of connections and
 				// failures do not ignore the request.
-				if uint32(len(conns)) < cm.cfg.TargetOutbound ||
+				if connReq.Feeler {
+					connReq.updateState(ConnDisconnected)
+					continue
+				}
+				kind := connReq.kind()
+				if countConns(conns, kind) < targets.target(kind) ||
 					connReq.Permanent {
 
 					connReq.updateState(ConnPending)
@@ -365,7 +477,30 @@ out:
 				connReq.updateState(ConnFailing)
 				log.Debugf("Failed to connect to %v: %v",
 					connReq, msg.err)
+
+				// Don't replace automatic connections which
+				// exceed a target that has been lowered.
+				kind := connReq.kind()
+				if !connReq.Permanent && !connReq.Feeler &&
+					countConns(conns, kind)+countConns(pending,
+						kind) > targets.target(kind) {
+
+					connReq.updateState(ConnCanceled)
+					delete(pending, connReq.id)
+					continue
+				}
 				cm.handleFailedConn(connReq, false)
+
+			case setTargets:
+				targets = msg.targets
+				for kind := connKind(0); kind < numConnKinds; kind++ {
+					cm.adjustPending(conns, pending, kind,
+						targets.target(kind))
+				}
+				close(msg.done)
+
+			case getTargets:
+				msg.reply <- targets
 			}
 
 		case <-cm.quit:
@@ -377,18 +512,90 @@ out:
 	log.Trace("Connection handler done")
 }
 
+// adjustPending requests new connections of the passed kind when the
+// established and pending ones fall short of the passed target, or cancels
+// pending automatic ones when they exceed it.  Established connections are
+// never dropped to meet a lowered target, they just aren't replaced.  It must
+// only be called from the connection handler.
+func (cm *ConnManager) adjustPending(conns, pending map[uint64]*ConnReq,
+	kind connKind, target uint32) {
+
+	count := countConns(conns, kind) + countConns(pending, kind)
+	for ; count < target; count++ {
+		go cm.newConnReq(kind)
+	}
+	for id, connReq := range pending {
+		if count <= target {
+			break
+		}
+		if connReq.Permanent || connReq.Feeler ||
+			connReq.kind() != kind {
+
+			continue
+		}
+		connReq.updateState(ConnCanceled)
+		log.Debugf("Canceling: %v", connReq)
+		delete(pending, id)
+		count--
+	}
+}
+
+// countConns returns the number of the passed connections which fill a slot of
+// the passed kind.  Feeler connections are not counted.
+func countConns(conns map[uint64]*ConnReq, kind connKind) uint32 {
+	var count uint32
+	for _, connReq := range conns {
+		if !connReq.Feeler && connReq.kind() == kind {
+			count++
+		}
+	}
+	return count
+}
+
 // NewConnReq creates a new connection request and connects to the
 // corresponding address.
 func (cm *ConnManager) NewConnReq() {
+	cm.newConnReq(kindOutbound)
+}
+
+// NewBlockRelayOnlyConnReq creates a new block-relay-only connection request
+// and connects to the corresponding address.
+func (cm *ConnManager) NewBlockRelayOnlyConnReq() {
+	cm.newConnReq(kindBlockRelayOnly)
+}
+
+// NewOnionConnReq creates a new connection request to an onion address and
+// connects to it.
+func (cm *ConnManager) NewOnionConnReq() {
+	cm.newConnReq(kindOnion)
+}
+
+// addressFunc returns the function which provides the addresses for automatic
+// connections of the passed kind.
+func (cm *ConnManager) addressFunc(kind connKind) func() (net.Addr, error) {
+	if kind == kindOnion {
+		return cm.cfg.GetNewOnionAddress
+	}
+	return cm.cfg.GetNewAddress
+}
+
+// newConnReq creates a new connection request of the given kind and connects
+// to the corresponding address.
+func (cm *ConnManager) newConnReq(kind connKind) {
 	if atomic.LoadInt32(&cm.stop) != 0 {
 		return
 	}
-	if cm.cfg.GetNewAddress == nil {
+	getAddress := cm.addressFunc(kind)
+	if getAddress == nil {
 		return
 	}
 
-	c := &ConnReq{}
+	c := &ConnReq{
+		BlockRelayOnly: kind == kindBlockRelayOnly,
+		Onion:          kind == kindOnion,
+	}
 	atomic.StoreUint64(&c.id, atomic.AddUint64(&cm.connReqCount, 1))
+	cm.countKind(c)
 
 	// Submit a request of a pending connection attempt to the connection
 	// manager. By registering the id before the connection is even
@@ -409,7 +616,7 @@ func (cm *ConnManager) NewConnReq() {
 		return
 	}
 
-	addr, err := cm.cfg.GetNewAddress()
+	addr, err := getAddress()
 	if err != nil {
 		select {
 		case cm.requests <- handleFailed{c, err}:
@@ -423,6 +630,17 @@ func (cm *ConnManager) NewConnReq() {
 	cm.Connect(c)
 }
 
+// countKind counts the passed connection request towards the number of
+// requests made of its kind when it isn't a regular outbound one.
+func (cm *ConnManager) countKind(c *ConnReq) {
+	switch c.kind() {
+	case kindBlockRelayOnly:
+		atomic.AddUint64(&cm.blockRelayOnlyReqCount, 1)
+	case kindOnion:
+		atomic.AddUint64(&cm.onionReqCount, 1)
+	}
+}
+
 // Connect assigns an id and dials a connection to the address of the
 // connection request.
 func (cm *ConnManager) Connect(c *ConnReq) {
@@ -439,6 +657,7 @@ func (cm *ConnManager) Connect(c *ConnReq) {
 
 	if atomic.LoadUint64(&c.id) == 0 {
 		atomic.StoreUint64(&c.id, atomic.AddUint64(&cm.connReqCount, 1))
+		cm.countKind(c)
 
 		// Submit a request of a pending connection attempt to the
 		// connection manager. By registering the id before the
@@ -534,6 +753,32 @@ func (cm *ConnManager) listenHandler(listener net.Listener) {
 	log.Tracef("Listener handler done for %s", listener.Addr())
 }
 
+// feelerHandler periodically makes feeler connections to addresses returned
+// by GetFeelerAddress.  It must be run as a goroutine.
+func (cm *ConnManager) feelerHandler() {
+	ticker := time.NewTicker(cm.cfg.FeelerInterval)
+	defer ticker.Stop()
+
+out:
+	for {
+		select {
+		case <-ticker.C:
+			addr, err := cm.cfg.GetFeelerAddress()
+			if err != nil {
+				log.Debugf("Skipping feeler connection: %v", err)
+				continue
+			}
+			go cm.Connect(&ConnReq{Addr: addr, Feeler: true})
+
+		case <-cm.quit:
+			break out
+		}
+	}
+
+	cm.wg.Done()
+	log.Trace("Feeler handler done")
+}
+
 // Start launches the connection manager and begins connecting to the network.
 func (cm *ConnManager) Start() {
 	// Already started?
@@ -554,9 +799,60 @@ func (cm *ConnManager) Start() {
 		}
 	}
 
-	for i := atomic.LoadUint64(&cm.connReqCount); i < uint64(cm.cfg.TargetOutbound); i++ {
+	if cm.cfg.FeelerInterval > 0 && cm.cfg.GetFeelerAddress != nil {
+		cm.wg.Add(1)
+		go cm.feelerHandler()
+	}
+
+	blockRelayOnly := atomic.LoadUint64(&cm.blockRelayOnlyReqCount)
+	onion := atomic.LoadUint64(&cm.onionReqCount)
+	regular := atomic.LoadUint64(&cm.connReqCount) - blockRelayOnly - onion
+	for i := regular; i < uint64(cm.cfg.TargetOutbound); i++ {
 		go cm.NewConnReq()
 	}
+	for i := blockRelayOnly; i < uint64(cm.cfg.TargetBlockRelayOnly); i++ {
+		go cm.NewBlockRelayOnlyConnReq()
+	}
+	for i := onion; i < uint64(cm.cfg.TargetOnion); i++ {
+		go cm.NewOnionConnReq()
+	}
+}
+
+// SetTargets changes the number of connections of each kind to maintain.  New
+// connections are requested right away when a target is raised.  When a target
+// is lowered, the pending connections exceeding it are canceled, while the
+// established ones are kept but not replaced once they are lost.  Connections
+// made via Connect count towards the outbound target unless they are marked
+// otherwise.
+func (cm *ConnManager) SetTargets(targets Targets) {
+	done := make(chan struct{})
+	select {
+	case cm.requests <- setTargets{targets, done}:
+	case <-cm.quit:
+		return
+	}
+
+	select {
+	case <-done:
+	case <-cm.quit:
+	}
+}
+
+// Targets returns the number of connections of each kind which are maintained.
+func (cm *ConnManager) Targets() Targets {
+	reply := make(chan Targets, 1)
+	select {
+	case cm.requests <- getTargets{reply}:
+	case <-cm.quit:
+		return Targets{}
+	}
+
+	select {
+	case targets := <-reply:
+		return targets
+	case <-cm.quit:
+		return Targets{}
+	}
 }
 
 // Wait blocks until the connection manager halts gracefully.
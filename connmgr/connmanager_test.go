@@ -216,6 +216,170 @@ func TestTargetOutbound(t *testing.T) {
 	cmgr.Stop()
 }
 
+// TestTargetBlockRelayOnly tests that the block-relay-only slots are filled in
+// addition to the regular outbound ones and that a disconnected
+// block-relay-only connection is replaced by another one.
+func TestTargetBlockRelayOnly(t *testing.T) {
+	targetOutbound := uint32(3)
+	targetBlockRelayOnly := uint32(2)
+	connected := make(chan *ConnReq)
+	cmgr, err := New(&Config{
+		TargetOutbound:       targetOutbound,
+		TargetBlockRelayOnly: targetBlockRelayOnly,
+		Dial:                 mockDialer,
+		GetNewAddress: func() (net.Addr, error) {
+			return &net.TCPAddr{
+				IP:   net.ParseIP("127.0.0.1"),
+				Port: 18555,
+			}, nil
+		},
+		OnConnection: func(c *ConnReq, conn net.Conn) {
+			connected <- c
+		},
+	})
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	cmgr.Start()
+	var blockRelayOnly []*ConnReq
+	for i := uint32(0); i < targetOutbound+targetBlockRelayOnly; i++ {
+		c := <-connected
+		if c.BlockRelayOnly {
+			blockRelayOnly = append(blockRelayOnly, c)
+		}
+	}
+	if uint32(len(blockRelayOnly)) != targetBlockRelayOnly {
+		t.Fatalf("target block relay only: got %d block-relay-only "+
+			"connections, want %d", len(blockRelayOnly),
+			targetBlockRelayOnly)
+	}
+
+	select {
+	case c := <-connected:
+		t.Fatalf("target block relay only: got unexpected connection - %v",
+			c.Addr)
+	case <-time.After(time.Millisecond):
+		break
+	}
+
+	cmgr.Disconnect(blockRelayOnly[0].ID())
+	c := <-connected
+	if !c.BlockRelayOnly {
+		t.Fatalf("target block relay only: replacement connection %v is "+
+			"not block relay only", c)
+	}
+	cmgr.Stop()
+}
+
+// TestSetTargets tests that onion connections fill their own slots, that
+// raising a target makes new connections right away and that connections
+// exceeding a lowered target are not replaced once they are lost.
+func TestSetTargets(t *testing.T) {
+	connected := make(chan *ConnReq)
+	newAddress := func() (net.Addr, error) {
+		return &net.TCPAddr{
+			IP:   net.ParseIP("127.0.0.1"),
+			Port: 18555,
+		}, nil
+	}
+	cmgr, err := New(&Config{
+		TargetOutbound:     2,
+		TargetOnion:        1,
+		Dial:               mockDialer,
+		GetNewAddress:      newAddress,
+		GetNewOnionAddress: newAddress,
+		OnConnection: func(c *ConnReq, conn net.Conn) {
+			connected <- c
+		},
+	})
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	cmgr.Start()
+	var onion []*ConnReq
+	for i := 0; i < 3; i++ {
+		c := <-connected
+		if c.Onion {
+			onion = append(onion, c)
+		}
+	}
+	if len(onion) != 1 {
+		t.Fatalf("set targets: got %d onion connections, want 1",
+			len(onion))
+	}
+
+	want := Targets{Outbound: 3, BlockRelayOnly: 1}
+	cmgr.SetTargets(want)
+	if got := cmgr.Targets(); got != want {
+		t.Fatalf("set targets: got targets %+v, want %+v", got, want)
+	}
+	var outbound, blockRelayOnly int
+	for i := 0; i < 2; i++ {
+		c := <-connected
+		switch {
+		case c.BlockRelayOnly:
+			blockRelayOnly++
+		case !c.Onion:
+			outbound++
+		}
+	}
+	if outbound != 1 || blockRelayOnly != 1 {
+		t.Fatalf("set targets: got %d outbound and %d block-relay-only "+
+			"connections, want 1 of each", outbound, blockRelayOnly)
+	}
+
+	cmgr.Disconnect(onion[0].ID())
+	select {
+	case c := <-connected:
+		t.Fatalf("set targets: got unexpected connection - %v", c)
+	case <-time.After(time.Millisecond * 10):
+		break
+	}
+	cmgr.Stop()
+}
+
+// TestFeelerConnections tests that feeler connections are made periodically
+// to the addresses returned by GetFeelerAddress and that they are not retried.
+func TestFeelerConnections(t *testing.T) {
+	feelerAddr := &net.TCPAddr{
+		IP:   net.ParseIP("127.0.0.2"),
+		Port: 18555,
+	}
+	connected := make(chan *ConnReq)
+	cmgr, err := New(&Config{
+		Dial:           mockDialer,
+		FeelerInterval: time.Millisecond * 10,
+		GetFeelerAddress: func() (net.Addr, error) {
+			return feelerAddr, nil
+		},
+		OnConnection: func(c *ConnReq, conn net.Conn) {
+			connected <- c
+		},
+	})
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	cmgr.Start()
+	c := <-connected
+	if !c.Feeler || c.Addr != feelerAddr {
+		t.Fatalf("feeler connections: got %v (feeler %v), want feeler "+
+			"connection to %v", c, c.Feeler, feelerAddr)
+	}
+
+	// Disconnecting a feeler does not retry it, so the next connection is
+	// a new feeler.
+	cmgr.Disconnect(c.ID())
+	next := <-connected
+	if next.ID() == c.ID() {
+		t.Fatalf("feeler connections: feeler %v was retried", c)
+	}
+	if state := c.State(); state != ConnDisconnected {
+		t.Fatalf("feeler connections: got state %v, want %v", state,
+			ConnDisconnected)
+	}
+	cmgr.Stop()
+}
+
 // TestRetryPermanent tests that permanent connection requests are retried.
 //
 // We make a permanent connection request using Connect, disconnect it using
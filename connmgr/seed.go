@@ -5,13 +5,25 @@
 package connmgr
 
 import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	mrand "math/rand"
 	"net"
+	"net/http"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
 	"github.com/btcsuite/btcd/chaincfg/v2"
+	"github.com/btcsuite/btcd/chainhash/v2"
 	"github.com/btcsuite/btcd/wire/v2"
 )
 
@@ -20,8 +32,24 @@ const (
 	// seen time.
 	secondsIn3Days int32 = 24 * 60 * 60 * 3
 	secondsIn4Days int32 = 24 * 60 * 60 * 4
+
+	// defaultSeedLookupTimeout is the default duration to wait for a DNS
+	// seed to respond before it is skipped.
+	defaultSeedLookupTimeout = time.Second * 10
+
+	// maxSeedListSize is the maximum size in bytes of a seed list which is
+	// read.
+	maxSeedListSize = 1 << 20
+
+	// maxSeedListAge is the maximum age of a seed list which is accepted.
+	// It prevents an attacker from serving an old list of addresses which
+	// are no longer reachable.
+	maxSeedListAge = time.Hour * 24 * 30
 )
 
+// seedListTag is the tag of the tagged hash signed by seed list signatures.
+var seedListTag = []byte("SeedList")
+
 // OnSeed is the signature of the callback function which is invoked when DNS
 // seeding is successful.
 type OnSeed func(addrs []*wire.NetAddressV2)
@@ -29,22 +57,113 @@ type OnSeed func(addrs []*wire.NetAddressV2)
 // LookupFunc is the signature of the DNS lookup function.
 type LookupFunc func(string) ([]net.IP, error)
 
+// SeedConfig holds the configuration options related to seeding.
+type SeedConfig struct {
+	// ChainParams identifies the DNS seeds to query and the default port
+	// of the network.
+	ChainParams *chaincfg.Params
+
+	// ExtraSeeds are DNS seeds which are queried in addition to the ones of
+	// the chain parameters.
+	ExtraSeeds []chaincfg.DNSSeed
+
+	// ReqServices are the services the returned peers are required to
+	// support when the DNS seeds support filtering.
+	ReqServices wire.ServiceFlag
+
+	// Lookup is the DNS lookup function.
+	Lookup LookupFunc
+
+	// LookupTimeout is the duration to wait for a DNS seed to respond
+	// before it is skipped.  Defaults to 10s.
+	LookupTimeout time.Duration
+
+	// SeedListURL is the URL of a signed seed list which is fetched when
+	// none of the DNS seeds returned any addresses.  The seed list is not
+	// used if it is empty.
+	SeedListURL string
+
+	// SeedListPubKey is the public key the seed list must be signed with.
+	SeedListPubKey *btcec.PublicKey
+
+	// HTTPClient is the client used to fetch the seed list.  Defaults to
+	// http.DefaultClient.
+	HTTPClient *http.Client
+
+	// OnSeed is invoked with the addresses found by each seed.
+	OnSeed OnSeed
+}
+
+// SeedList is the format of a signed seed list.  The signature is a BIP 340
+// signature of the hash returned by SeedListHash.
+type SeedList struct {
+	Timestamp int64    `json:"timestamp"`
+	Addresses []string `json:"addresses"`
+	Signature string   `json:"signature"`
+}
+
+// SeedListHash returns the hash signed by the signature of a seed list with
+// the passed timestamp and addresses.  It is the tagged hash with the tag
+// "SeedList" of the timestamp as an 8-byte little-endian integer followed by
+// the addresses, each of which is terminated by a newline.
+func SeedListHash(timestamp int64, addresses []string) *chainhash.Hash {
+	var buf strings.Builder
+	var ts [8]byte
+	binary.LittleEndian.PutUint64(ts[:], uint64(timestamp))
+	buf.Write(ts[:])
+	for _, addr := range addresses {
+		buf.WriteString(addr)
+		buf.WriteByte('\n')
+	}
+	return chainhash.TaggedHash(seedListTag, []byte(buf.String()))
+}
+
 // SeedFromDNS uses DNS seeding to populate the address manager with peers.
 func SeedFromDNS(chainParams *chaincfg.Params, reqServices wire.ServiceFlag,
 	lookupFn LookupFunc, seedFn OnSeed) {
 
-	for _, dnsseed := range chainParams.DNSSeeds {
+	Seed(&SeedConfig{
+		ChainParams: chainParams,
+		ReqServices: reqServices,
+		Lookup:      lookupFn,
+		OnSeed:      seedFn,
+	})
+}
+
+// Seed queries the DNS seeds of the chain parameters along with the extra
+// seeds concurrently and passes the addresses each of them returns to OnSeed.
+// Seeds which don't respond within the lookup timeout are skipped.  When a
+// seed list URL is configured and none of the DNS seeds returned any
+// addresses, the seed list is fetched and its addresses are passed to OnSeed
+// instead.  It returns immediately.
+func Seed(cfg *SeedConfig) {
+	timeout := cfg.LookupTimeout
+	if timeout <= 0 {
+		timeout = defaultSeedLookupTimeout
+	}
+
+	seeds := make([]chaincfg.DNSSeed, 0,
+		len(cfg.ChainParams.DNSSeeds)+len(cfg.ExtraSeeds))
+	seeds = append(seeds, cfg.ChainParams.DNSSeeds...)
+	seeds = append(seeds, cfg.ExtraSeeds...)
+
+	var wg sync.WaitGroup
+	var numFound int64
+	for _, dnsseed := range seeds {
 		var host string
-		if !dnsseed.HasFiltering || reqServices == wire.SFNodeNetwork {
+		if !dnsseed.HasFiltering || cfg.ReqServices == wire.SFNodeNetwork {
 			host = dnsseed.Host
 		} else {
-			host = fmt.Sprintf("x%x.%s", uint64(reqServices), dnsseed.Host)
+			host = fmt.Sprintf("x%x.%s", uint64(cfg.ReqServices),
+				dnsseed.Host)
 		}
 
+		wg.Add(1)
 		go func(host string) {
-			randSource := mrand.New(mrand.NewSource(time.Now().UnixNano()))
+			defer wg.Done()
 
-			seedpeers, err := lookupFn(host)
+			seedpeers, err := lookupWithTimeout(cfg.Lookup, host,
+				timeout)
 			if err != nil {
 				log.Infof("DNS discovery failed on seed %s: %v", host, err)
 				return
@@ -56,20 +175,140 @@ func SeedFromDNS(chainParams *chaincfg.Params, reqServices wire.ServiceFlag,
 			if numPeers == 0 {
 				return
 			}
-			addresses := make([]*wire.NetAddressV2, len(seedpeers))
-			// if this errors then we have *real* problems
-			intPort, _ := strconv.Atoi(chainParams.DefaultPort)
-			for i, peer := range seedpeers {
-				addresses[i] = wire.NetAddressV2FromBytes(
-					// bitcoind seeds with addresses from
-					// a time randomly selected between 3
-					// and 7 days ago.
-					time.Now().Add(-1*time.Second*time.Duration(secondsIn3Days+
-						randSource.Int31n(secondsIn4Days))),
-					0, peer, uint16(intPort))
-			}
+			atomic.AddInt64(&numFound, int64(numPeers))
 
-			seedFn(addresses)
+			// if this errors then we have *real* problems
+			intPort, _ := strconv.Atoi(cfg.ChainParams.DefaultPort)
+			cfg.OnSeed(seedAddresses(seedpeers, uint16(intPort)))
 		}(host)
 	}
+
+	if cfg.SeedListURL == "" {
+		return
+	}
+	go func() {
+		wg.Wait()
+		if atomic.LoadInt64(&numFound) > 0 {
+			return
+		}
+
+		log.Infof("No addresses found from DNS seeds -- fetching seed "+
+			"list from %s", cfg.SeedListURL)
+		client := cfg.HTTPClient
+		if client == nil {
+			client = http.DefaultClient
+		}
+		addrs, err := FetchSeedList(client, cfg.SeedListURL,
+			cfg.SeedListPubKey, time.Now())
+		if err != nil {
+			log.Warnf("Unable to fetch seed list from %s: %v",
+				cfg.SeedListURL, err)
+			return
+		}
+
+		log.Infof("%d addresses found from seed list %s", len(addrs),
+			cfg.SeedListURL)
+		if len(addrs) > 0 {
+			cfg.OnSeed(addrs)
+		}
+	}()
+}
+
+// lookupWithTimeout resolves the passed host with the passed lookup function
+// and returns an error when it doesn't respond within the given timeout.
+func lookupWithTimeout(lookupFn LookupFunc, host string,
+	timeout time.Duration) ([]net.IP, error) {
+
+	type lookupResult struct {
+		ips []net.IP
+		err error
+	}
+	result := make(chan lookupResult, 1)
+	go func() {
+		ips, err := lookupFn(host)
+		result <- lookupResult{ips, err}
+	}()
+
+	select {
+	case r := <-result:
+		return r.ips, r.err
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("no response within %v", timeout)
+	}
+}
+
+// seedAddresses returns addresses for the passed IPs and port with a last
+// seen time randomly selected between 3 and 7 days ago like bitcoind does for
+// seeded addresses.
+func seedAddresses(ips []net.IP, port uint16) []*wire.NetAddressV2 {
+	randSource := mrand.New(mrand.NewSource(time.Now().UnixNano()))
+	addresses := make([]*wire.NetAddressV2, len(ips))
+	for i, ip := range ips {
+		addresses[i] = wire.NetAddressV2FromBytes(
+			time.Now().Add(-1*time.Second*time.Duration(secondsIn3Days+
+				randSource.Int31n(secondsIn4Days))),
+			0, ip, port)
+	}
+	return addresses
+}
+
+// FetchSeedList fetches the seed list at the passed URL with the passed HTTP
+// client, verifies it is signed with the passed public key and not older than
+// 30 days at the passed time, and returns its addresses.  Addresses in the
+// list which are not in the form ip:port are skipped.
+func FetchSeedList(client *http.Client, url string, pubKey *btcec.PublicKey,
+	now time.Time) ([]*wire.NetAddressV2, error) {
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSeedListSize))
+	if err != nil {
+		return nil, err
+	}
+	var list SeedList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+
+	sigBytes, err := hex.DecodeString(list.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("malformed signature: %v", err)
+	}
+	sig, err := schnorr.ParseSignature(sigBytes)
+	if err != nil {
+		return nil, fmt.Errorf("malformed signature: %v", err)
+	}
+	hash := SeedListHash(list.Timestamp, list.Addresses)
+	if !sig.Verify(hash[:], pubKey) {
+		return nil, errors.New("invalid signature")
+	}
+	if now.Sub(time.Unix(list.Timestamp, 0)) > maxSeedListAge {
+		return nil, fmt.Errorf("seed list from %v is too old",
+			time.Unix(list.Timestamp, 0))
+	}
+
+	addresses := make([]*wire.NetAddressV2, 0, len(list.Addresses))
+	for _, addr := range list.Addresses {
+		host, portStr, err := net.SplitHostPort(addr)
+		if err != nil {
+			log.Debugf("Skipping seed list address %s: %v", addr, err)
+			continue
+		}
+		ip := net.ParseIP(host)
+		port, err := strconv.ParseUint(portStr, 10, 16)
+		if ip == nil || err != nil {
+			log.Debugf("Skipping invalid seed list address %s", addr)
+			continue
+		}
+		addresses = append(addresses, seedAddresses(
+			[]net.IP{ip}, uint16(port))...)
+	}
+	return addresses, nil
 }
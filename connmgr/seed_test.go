@@ -0,0 +1,177 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package connmgr
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/v2"
+	"github.com/btcsuite/btcd/wire/v2"
+)
+
+// signSeedList returns a seed list with the passed timestamp and addresses
+// signed with the passed private key.
+func signSeedList(t *testing.T, privKey *btcec.PrivateKey, timestamp int64,
+	addresses []string) *SeedList {
+
+	t.Helper()
+	hash := SeedListHash(timestamp, addresses)
+	sig, err := schnorr.Sign(privKey, hash[:])
+	if err != nil {
+		t.Fatalf("unable to sign seed list: %v", err)
+	}
+	return &SeedList{
+		Timestamp: timestamp,
+		Addresses: addresses,
+		Signature: hex.EncodeToString(sig.Serialize()),
+	}
+}
+
+// newSeedListServer returns a test server which serves the passed seed list.
+func newSeedListServer(t *testing.T, list *SeedList) *httptest.Server {
+	t.Helper()
+	server := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(list)
+		}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestFetchSeedList ensures seed lists are only accepted when they are signed
+// with the expected key and recent, and that invalid addresses are skipped.
+func TestFetchSeedList(t *testing.T) {
+	privKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+	otherKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+
+	now := time.Unix(1700000000, 0)
+	addresses := []string{"1.2.3.4:9246", "[2001:db8::1]:9246",
+		"not an address", "5.6.7.8:99999"}
+	valid := signSeedList(t, privKey, now.Unix(), addresses)
+	tampered := *valid
+	tampered.Addresses = []string{"6.6.6.6:9246"}
+
+	tests := []struct {
+		name    string
+		list    *SeedList
+		wantErr bool
+	}{
+		{"valid", valid, false},
+		{"other key", signSeedList(t, otherKey, now.Unix(), addresses), true},
+		{"tampered", &tampered, true},
+		{"too old", signSeedList(t, privKey,
+			now.Add(-maxSeedListAge-time.Hour).Unix(), addresses), true},
+		{"malformed signature", &SeedList{Signature: "zz"}, true},
+	}
+	for _, test := range tests {
+		server := newSeedListServer(t, test.list)
+		addrs, err := FetchSeedList(server.Client(), server.URL,
+			privKey.PubKey(), now)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if len(addrs) != 2 || addrs[0].Addr.String() != "1.2.3.4" ||
+			addrs[1].Addr.String() != "2001:db8::1" ||
+			addrs[1].Port != 9246 {
+
+			t.Errorf("%s: unexpected addresses %v", test.name, addrs)
+		}
+	}
+}
+
+// TestSeed ensures the DNS seeds of the chain parameters and the extra seeds
+// are queried, that unresponsive seeds are skipped, and that the seed list is
+// only fetched when none of the DNS seeds returned any addresses.
+func TestSeed(t *testing.T) {
+	privKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+	list := signSeedList(t, privKey, time.Now().Unix(),
+		[]string{"9.9.9.9:9246"})
+	server := newSeedListServer(t, list)
+
+	params := chaincfg.MainNetParams
+	params.DNSSeeds = []chaincfg.DNSSeed{
+		{Host: "dead.example.com"},
+		{Host: "hanging.example.com"},
+	}
+
+	runSeed := func(extraSeeds []chaincfg.DNSSeed) []string {
+		hang := make(chan struct{})
+		defer close(hang)
+
+		seeded := make(chan []*wire.NetAddressV2)
+		Seed(&SeedConfig{
+			ChainParams: &params,
+			ExtraSeeds:  extraSeeds,
+			ReqServices: wire.SFNodeNetwork,
+			Lookup: func(host string) ([]net.IP, error) {
+				switch host {
+				case "hanging.example.com":
+					<-hang
+				case "good.example.com":
+					return []net.IP{net.ParseIP("1.2.3.4")}, nil
+				}
+				return nil, errors.New("no such host")
+			},
+			LookupTimeout:  time.Millisecond * 50,
+			SeedListURL:    server.URL,
+			SeedListPubKey: privKey.PubKey(),
+			HTTPClient:     server.Client(),
+			OnSeed: func(addrs []*wire.NetAddressV2) {
+				seeded <- addrs
+			},
+		})
+
+		var hosts []string
+		for {
+			select {
+			case addrs := <-seeded:
+				for _, addr := range addrs {
+					hosts = append(hosts, addr.Addr.String())
+				}
+			case <-time.After(time.Millisecond * 500):
+				return hosts
+			}
+		}
+	}
+
+	// The seed list is used when none of the DNS seeds respond with any
+	// addresses.
+	hosts := runSeed(nil)
+	if len(hosts) != 1 || hosts[0] != "9.9.9.9" {
+		t.Fatalf("seed without DNS addresses: got %v, want [9.9.9.9]",
+			hosts)
+	}
+
+	// Otherwise the seed list isn't fetched.
+	hosts = runSeed([]chaincfg.DNSSeed{{Host: "good.example.com"}})
+	if len(hosts) != 1 || hosts[0] != "1.2.3.4" {
+		t.Fatalf("seed with extra seed: got %v, want [1.2.3.4]", hosts)
+	}
+}
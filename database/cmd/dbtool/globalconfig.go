@@ -32,6 +32,7 @@ var (
 
 // config defines the global configuration options.
 type config struct {
+	BlocksDir      string `long:"blocksdir" description:"Location of the block files when they are stored separately from the block database"`
 	DataDir        string `short:"b" long:"datadir" description:"Location of the btcd data directory"`
 	DbType         string `long:"dbtype" description:"Database backend to use for the Block Chain"`
 	RegressionTest bool   `long:"regtest" description:"Use the regression test network"`
@@ -116,6 +117,10 @@ func setupGlobalConfig() error {
 	// means each individual piece of serialized data does not have to
 	// worry about changing names per network and such.
 	cfg.DataDir = filepath.Join(cfg.DataDir, netName(activeNetParams))
+	if cfg.BlocksDir != "" {
+		cfg.BlocksDir = filepath.Join(cfg.BlocksDir,
+			netName(activeNetParams))
+	}
 
 	return nil
 }
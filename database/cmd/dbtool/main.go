@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/btcsuite/btcd/database"
+	"github.com/btcsuite/btcd/database/ffldb"
 	"github.com/btcsuite/btclog"
 	flags "github.com/jessevdk/go-flags"
 )
@@ -31,7 +32,8 @@ func loadBlockDB() (database.DB, error) {
 	dbPath := filepath.Join(cfg.DataDir, dbName)
 
 	log.Infof("Loading block database from '%s'", dbPath)
-	db, err := database.Open(cfg.DbType, dbPath, activeNetParams.Net)
+	dbOpts := ffldb.Options{BlocksDir: cfg.BlocksDir}
+	db, err := database.Open(cfg.DbType, dbPath, activeNetParams.Net, dbOpts)
 	if err != nil {
 		// Return the error if it's not because the database doesn't
 		// exist.
@@ -46,7 +48,8 @@ func loadBlockDB() (database.DB, error) {
 		if err != nil {
 			return nil, err
 		}
-		db, err = database.Create(cfg.DbType, dbPath, activeNetParams.Net)
+		db, err = database.Create(cfg.DbType, dbPath,
+			activeNetParams.Net, dbOpts)
 		if err != nil {
 			return nil, err
 		}
@@ -88,6 +91,12 @@ func realMain() error {
 	parser.AddCommand("fetchblockregion",
 		"Fetch the specified block region from the database", "",
 		&blockRegionCfg)
+	parser.AddCommand("rewriteblocks",
+		"Compress or decompress the blocks stored in the database",
+		"Rewrite the block files so the blocks stored in them are "+
+			"compressed, or uncompressed with --decompress.  The "+
+			"database must not be in use by btcd while the blocks "+
+			"are rewritten.", &rewriteBlocksCfg)
 
 	// Parse command line and invoke the Execute function for the specified
 	// command.
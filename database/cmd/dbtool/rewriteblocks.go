@@ -0,0 +1,67 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/btcsuite/btcd/database"
+	"github.com/btcsuite/btcd/database/ffldb"
+)
+
+// rewriteBlocksCmd defines the configuration options for the rewriteblocks
+// command.
+type rewriteBlocksCmd struct {
+	Decompress bool `long:"decompress" description:"Store the blocks uncompressed instead of compressing them"`
+}
+
+var (
+	// rewriteBlocksCfg defines the configuration options for the command.
+	rewriteBlocksCfg = rewriteBlocksCmd{}
+)
+
+// Execute is the main entry point for the command.  It's invoked by the parser.
+func (cmd *rewriteBlocksCmd) Execute(args []string) error {
+	// Setup the global config options and ensure they are valid.
+	if err := setupGlobalConfig(); err != nil {
+		return err
+	}
+
+	// Open the block database with the block compression the blocks are
+	// rewritten with.
+	dbName := blockDbNamePrefix + "_" + cfg.DbType
+	dbPath := filepath.Join(cfg.DataDir, dbName)
+	log.Infof("Loading block database from '%s'", dbPath)
+	db, err := database.Open(cfg.DbType, dbPath, activeNetParams.Net,
+		ffldb.Options{
+			CompressBlocks: !cmd.Decompress,
+			BlocksDir:      cfg.BlocksDir,
+		})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// Stop once the block file which is being rewritten is done on Ctrl+C.
+	// The blocks of the remaining files are rewritten by running the
+	// command again.
+	interrupt := make(chan struct{})
+	addInterruptHandler(func() {
+		log.Infof("Stopping after the current block file...")
+		close(interrupt)
+	})
+
+	if cmd.Decompress {
+		log.Info("Decompressing the blocks")
+	} else {
+		log.Info("Compressing the blocks")
+	}
+	startTime := time.Now()
+	numRewritten, err := ffldb.RewriteBlocks(db, interrupt)
+	log.Infof("Rewrote %d blocks in %v", numRewritten,
+		time.Since(startTime).Truncate(time.Millisecond))
+	return err
+}
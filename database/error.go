@@ -117,6 +117,13 @@ const (
 	// ErrBlockNotFound instead.
 	ErrBlockRegionInvalid
 
+	// ErrBlockCorrupt indicates a checksum failure occurred when reading a
+	// stored block or it could not be decoded, which means the stored copy
+	// of the block is damaged while the rest of the database is intact.
+	// The block can be repaired by replacing it with a copy obtained
+	// elsewhere.
+	ErrBlockCorrupt
+
 	// ***********************************
 	// Support for driver-specific errors.
 	// ***********************************
@@ -153,6 +160,7 @@ var errorCodeStrings = map[ErrorCode]string{
 	ErrBlockNotFound:      "ErrBlockNotFound",
 	ErrBlockExists:        "ErrBlockExists",
 	ErrBlockRegionInvalid: "ErrBlockRegionInvalid",
+	ErrBlockCorrupt:       "ErrBlockCorrupt",
 	ErrDriverSpecific:     "ErrDriverSpecific",
 }
 
@@ -25,6 +25,7 @@ import (
 	"github.com/btcsuite/btcd/chainhash/v2"
 	"github.com/btcsuite/btcd/database"
 	"github.com/btcsuite/btcd/wire/v2"
+	"github.com/golang/snappy"
 )
 
 const (
@@ -65,6 +66,12 @@ const (
 	//  [4:8]  File offset (4 bytes)
 	//  [8:12] Block length (4 bytes)
 	blockLocSize = 12
+
+	// compressedBlockFlag is set in the block length of a block record, as
+	// well as in the block length of its location in the block index, when
+	// the serialized block is stored compressed with snappy.  Neither can
+	// come close to 2^31 bytes, so the bit is never set otherwise.
+	compressedBlockFlag = 1 << 31
 )
 
 var (
@@ -117,7 +124,7 @@ type blockStore struct {
 	// block.
 	network wire.BitcoinNet
 
-	// basePath is the base path used for the flat block files and metadata.
+	// basePath is the base path used for the flat block files.
 	basePath string
 
 	// maxBlockFileSize is the maximum size for each file used to store
@@ -125,6 +132,11 @@ type blockStore struct {
 	// override the value.
 	maxBlockFileSize uint32
 
+	// compressBlocks specifies whether new blocks are compressed when they
+	// are written to the flat files.  Blocks are read the same either way,
+	// since each block record identifies whether it is compressed.
+	compressBlocks bool
+
 	// The following fields are related to the flat files which hold the
 	// actual blocks.   The number of open files is limited by maxOpenFiles.
 	//
@@ -190,6 +202,7 @@ type blockLocation struct {
 	blockFileNum uint32
 	fileOffset   uint32
 	blockLen     uint32
+	compressed   bool
 }
 
 // deserializeBlockLoc deserializes the passed serialized block location
@@ -204,10 +217,14 @@ func deserializeBlockLoc(serializedLoc []byte) blockLocation {
 	//  [0:4]  Block file (4 bytes)
 	//  [4:8]  File offset (4 bytes)
 	//  [8:12] Block length (4 bytes)
+	//
+	// The high bit of the block length is set for compressed blocks.
+	blockLen := byteOrder.Uint32(serializedLoc[8:12])
 	return blockLocation{
 		blockFileNum: byteOrder.Uint32(serializedLoc[0:4]),
 		fileOffset:   byteOrder.Uint32(serializedLoc[4:8]),
-		blockLen:     byteOrder.Uint32(serializedLoc[8:12]),
+		blockLen:     blockLen &^ compressedBlockFlag,
+		compressed:   blockLen&compressedBlockFlag != 0,
 	}
 }
 
@@ -219,10 +236,16 @@ func serializeBlockLoc(loc blockLocation) []byte {
 	//  [0:4]  Block file (4 bytes)
 	//  [4:8]  File offset (4 bytes)
 	//  [8:12] Block length (4 bytes)
+	//
+	// The high bit of the block length is set for compressed blocks.
+	blockLen := loc.blockLen
+	if loc.compressed {
+		blockLen |= compressedBlockFlag
+	}
 	var serializedData [12]byte
 	byteOrder.PutUint32(serializedData[0:4], loc.blockFileNum)
 	byteOrder.PutUint32(serializedData[4:8], loc.fileOffset)
-	byteOrder.PutUint32(serializedData[8:12], loc.blockLen)
+	byteOrder.PutUint32(serializedData[8:12], blockLen)
 	return serializedData[:]
 }
 
@@ -419,6 +442,20 @@ func (s *blockStore) writeData(data []byte, fieldName string) error {
 	return nil
 }
 
+// encodeBlock returns the passed serialized block in the form it is stored in a
+// block record along with whether it is compressed.  Blocks are only compressed
+// when block compression is enabled and compressing them makes them smaller.
+func (s *blockStore) encodeBlock(rawBlock []byte) ([]byte, bool) {
+	if !s.compressBlocks {
+		return rawBlock, false
+	}
+	compressedBlock := snappy.Encode(nil, rawBlock)
+	if len(compressedBlock) >= len(rawBlock) {
+		return rawBlock, false
+	}
+	return compressedBlock, true
+}
+
 // writeBlock appends the specified raw block bytes to the store's write cursor
 // location and increments it accordingly.  When the block would exceed the max
 // file size for the current flat file, this function will close the current
@@ -428,8 +465,14 @@ func (s *blockStore) writeData(data []byte, fieldName string) error {
 // The write cursor will also be advanced the number of bytes actually written
 // in the event of failure.
 //
+// When block compression is enabled, the block is stored compressed unless that
+// doesn't make it any smaller, and the high bit of the block length is set to
+// mark the record as compressed.
+//
 // Format: <network><block length><serialized block><checksum>
 func (s *blockStore) writeBlock(rawBlock []byte) (blockLocation, error) {
+	rawBlock, compressed := s.encodeBlock(rawBlock)
+
 	// Compute how many bytes will be written.
 	// 4 bytes each for block network + 4 bytes for block length +
 	// length of raw block + 4 bytes for checksum.
@@ -497,7 +540,11 @@ func (s *blockStore) writeBlock(rawBlock []byte) (blockLocation, error) {
 	_, _ = hasher.Write(scratch[:])
 
 	// Block length.
-	byteOrder.PutUint32(scratch[:], blockLen)
+	serializedLen := blockLen
+	if compressed {
+		serializedLen |= compressedBlockFlag
+	}
+	byteOrder.PutUint32(scratch[:], serializedLen)
 	if err := s.writeData(scratch[:], "block length"); err != nil {
 		return blockLocation{}, err
 	}
@@ -518,6 +565,7 @@ func (s *blockStore) writeBlock(rawBlock []byte) (blockLocation, error) {
 		blockFileNum: wc.curFileNum,
 		fileOffset:   origOffset,
 		blockLen:     fullLen,
+		compressed:   compressed,
 	}
 	return loc, nil
 }
@@ -530,9 +578,13 @@ func (s *blockStore) writeBlock(rawBlock []byte) (blockLocation, error) {
 // and closing files as necessary to stay within the maximum allowed open files
 // limit.
 //
+// Compressed blocks are decompressed, so the serialized block is returned
+// regardless of how it is stored.
+//
 // Returns ErrDriverSpecific if the data fails to read for any reason and
-// ErrCorruption if the checksum of the read data doesn't match the checksum
-// read from the file.
+// ErrBlockCorrupt if the block file ends before the block, the checksum of the
+// read data doesn't match the checksum read from the file, or a compressed
+// block can't be decompressed.
 //
 // Format: <network><block length><serialized block><checksum>
 func (s *blockStore) readBlock(hash *chainhash.Hash, loc blockLocation) ([]byte, error) {
@@ -551,6 +603,9 @@ func (s *blockStore) readBlock(hash *chainhash.Hash, loc blockLocation) ([]byte,
 		str := fmt.Sprintf("failed to read block %s from file %d, "+
 			"offset %d: %v", hash, loc.blockFileNum, loc.fileOffset,
 			err)
+		if errors.Is(err, io.EOF) {
+			return nil, makeDbErr(database.ErrBlockCorrupt, str, err)
+		}
 		return nil, makeDbErr(database.ErrDriverSpecific, str, err)
 	}
 
@@ -564,7 +619,7 @@ func (s *blockStore) readBlock(hash *chainhash.Hash, loc blockLocation) ([]byte,
 		str := fmt.Sprintf("block data for block %s checksum "+
 			"does not match - got %x, want %x", hash,
 			calculatedChecksum, serializedChecksum)
-		return nil, makeDbErr(database.ErrCorruption, str, nil)
+		return nil, makeDbErr(database.ErrBlockCorrupt, str, nil)
 	}
 
 	// The network associated with the block must match the current active
@@ -580,7 +635,18 @@ func (s *blockStore) readBlock(hash *chainhash.Hash, loc blockLocation) ([]byte,
 
 	// The raw block excludes the network, length of the block, and
 	// checksum.
-	return serializedData[8 : n-4], nil
+	rawBlock := serializedData[8 : n-4]
+	serializedLen := byteOrder.Uint32(serializedData[4:8])
+	if serializedLen&compressedBlockFlag == 0 {
+		return rawBlock, nil
+	}
+	rawBlock, err = snappy.Decode(nil, rawBlock)
+	if err != nil {
+		str := fmt.Sprintf("unable to decompress block %s: %v", hash,
+			err)
+		return nil, makeDbErr(database.ErrBlockCorrupt, str, err)
+	}
+	return rawBlock, nil
 }
 
 // readBlockRegion reads the specified amount of data at the provided offset for
@@ -590,6 +656,10 @@ func (s *blockStore) readBlock(hash *chainhash.Hash, loc blockLocation) ([]byte,
 // closing files as necessary to stay within the maximum allowed open files
 // limit.
 //
+// The region is read directly from the file, so the block must not be stored
+// compressed.  The regions of compressed blocks are taken from the block
+// returned by readBlock instead.
+//
 // Returns ErrDriverSpecific if the data fails to read for any reason.
 func (s *blockStore) readBlockRegion(loc blockLocation, offset, numBytes uint32) ([]byte, error) {
 	// Get the referenced block file handle opening the file as needed.  The
@@ -792,7 +862,9 @@ func scanBlockFiles(dbPath string) (int, int, uint32, error) {
 
 // newBlockStore returns a new block store with the current block file number
 // and offset set and all fields initialized.
-func newBlockStore(basePath string, network wire.BitcoinNet) (*blockStore, error) {
+func newBlockStore(basePath string, network wire.BitcoinNet,
+	compressBlocks bool) (*blockStore, error) {
+
 	// Look for the end of the latest block to file to determine what the
 	// write cursor position is from the viewpoint of the block files on
 	// disk.
@@ -809,6 +881,7 @@ func newBlockStore(basePath string, network wire.BitcoinNet) (*blockStore, error
 		network:          network,
 		basePath:         basePath,
 		maxBlockFileSize: maxBlockFileSize,
+		compressBlocks:   compressBlocks,
 		openBlockFiles:   make(map[uint32]*lockableFile),
 		openBlocksLRU:    list.New(),
 		fileNumToLRUElem: make(map[uint32]*list.Element),
@@ -1176,21 +1176,38 @@ func (tx *transaction) StoreBlock(block *btcutil.Block) error {
 		return makeDbErr(database.ErrDriverSpecific, str, err)
 	}
 
-	// Add the block to be stored to the list of pending blocks to store
-	// when the transaction is committed.  Also, add it to pending blocks
-	// map so it is easy to determine the block is pending based on the
-	// block hash.
+	tx.addPendingBlock(blockHash, blockBytes)
+	return nil
+}
+
+// addPendingBlock adds the passed serialized block to the list of pending
+// blocks to store when the transaction is committed.  Also, it is added to the
+// pending blocks map so it is easy to determine the block is pending based on
+// the block hash.
+func (tx *transaction) addPendingBlock(hash *chainhash.Hash, blockBytes []byte) {
 	if tx.pendingBlocks == nil {
 		tx.pendingBlocks = make(map[chainhash.Hash]int)
 	}
-	tx.pendingBlocks[*blockHash] = len(tx.pendingBlockData)
+	tx.pendingBlocks[*hash] = len(tx.pendingBlockData)
 	tx.pendingBlockData = append(tx.pendingBlockData, pendingBlock{
-		hash:  blockHash,
+		hash:  hash,
 		bytes: blockBytes,
 	})
-	log.Tracef("Added block %s to pending blocks", blockHash)
+	log.Tracef("Added block %s to pending blocks", hash)
+}
 
-	return nil
+// readBlock reads the block with the passed hash from the passed location in
+// the block files.  Blocks which are found to be damaged are reported to the
+// corrupt block handler, if any.
+func (tx *transaction) readBlock(hash *chainhash.Hash, loc blockLocation) ([]byte, error) {
+	blockBytes, err := tx.db.store.readBlock(hash, loc)
+	if dbErr, ok := err.(database.Error); ok &&
+		dbErr.ErrorCode == database.ErrBlockCorrupt &&
+		tx.db.corruptBlockHandler != nil {
+
+		tx.db.corruptBlockHandler(*hash)
+	}
+	return blockBytes, err
 }
 
 // HasBlock returns whether or not a block with the given hash exists in the
@@ -1299,6 +1316,7 @@ func (tx *transaction) FetchBlockHeaders(hashes []chainhash.Hash) ([][]byte, err
 //   - ErrBlockNotFound if the requested block hash does not exist
 //   - ErrTxClosed if the transaction has already been closed
 //   - ErrCorruption if the database has somehow become corrupted
+//   - ErrBlockCorrupt if the stored copy of a requested block is damaged
 //
 // In addition, returns ErrDriverSpecific if any failures occur when reading the
 // block files.
@@ -1330,7 +1348,7 @@ func (tx *transaction) FetchBlock(hash *chainhash.Hash) ([]byte, error) {
 
 	// Read the block from the appropriate location.  The function also
 	// performs a checksum over the data to detect data corruption.
-	blockBytes, err := tx.db.store.readBlock(hash, location)
+	blockBytes, err := tx.readBlock(hash, location)
 	if err != nil {
 		return nil, err
 	}
@@ -1346,6 +1364,7 @@ func (tx *transaction) FetchBlock(hash *chainhash.Hash) ([]byte, error) {
 //   - ErrBlockNotFound if any of the requested block hashed do not exist
 //   - ErrTxClosed if the transaction has already been closed
 //   - ErrCorruption if the database has somehow become corrupted
+//   - ErrBlockCorrupt if the stored copy of a requested block is damaged
 //
 // In addition, returns ErrDriverSpecific if any failures occur when reading the
 // block files.
@@ -1392,8 +1411,14 @@ func (tx *transaction) fetchPendingRegion(region *database.BlockRegion) ([]byte,
 		return nil, nil
 	}
 
+	// Return the bytes from the pending block.
+	return blockRegion(tx.pendingBlockData[idx].bytes, region)
+}
+
+// blockRegion returns the provided region of the passed serialized block.  The
+// region is bounds checked and ErrBlockRegionInvalid is returned if invalid.
+func blockRegion(blockBytes []byte, region *database.BlockRegion) ([]byte, error) {
 	// Ensure the region is within the bounds of the block.
-	blockBytes := tx.pendingBlockData[idx].bytes
 	blockLen := uint32(len(blockBytes))
 	endOffset := region.Offset + region.Len
 	if endOffset < region.Offset || endOffset > blockLen {
@@ -1403,7 +1428,6 @@ func (tx *transaction) fetchPendingRegion(region *database.BlockRegion) ([]byte,
 		return nil, makeDbErr(database.ErrBlockRegionInvalid, str, nil)
 	}
 
-	// Return the bytes from the pending block.
 	return blockBytes[region.Offset:endOffset:endOffset], nil
 }
 
@@ -1424,6 +1448,7 @@ func (tx *transaction) fetchPendingRegion(region *database.BlockRegion) ([]byte,
 //     block
 //   - ErrTxClosed if the transaction has already been closed
 //   - ErrCorruption if the database has somehow become corrupted
+//   - ErrBlockCorrupt if the stored copy of a requested block is damaged
 //
 // In addition, returns ErrDriverSpecific if any failures occur when reading the
 // block files.
@@ -1459,6 +1484,16 @@ func (tx *transaction) FetchBlockRegion(region *database.BlockRegion) ([]byte, e
 	}
 	location := deserializeBlockLoc(blockRow)
 
+	// A compressed block can only be decompressed as a whole, so the region
+	// is taken from the entire block.
+	if location.compressed {
+		blockBytes, err := tx.readBlock(region.Hash, location)
+		if err != nil {
+			return nil, err
+		}
+		return blockRegion(blockBytes, region)
+	}
+
 	// Ensure the region is within the bounds of the block.
 	endOffset := region.Offset + region.Len
 	if endOffset < region.Offset || endOffset > location.blockLen {
@@ -1497,6 +1532,7 @@ func (tx *transaction) FetchBlockRegion(region *database.BlockRegion) ([]byte, e
 //     associated block
 //   - ErrTxClosed if the transaction has already been closed
 //   - ErrCorruption if the database has somehow become corrupted
+//   - ErrBlockCorrupt if the stored copy of a requested block is damaged
 //
 // In addition, returns ErrDriverSpecific if any failures occur when reading the
 // block files.
@@ -1557,9 +1593,13 @@ func (tx *transaction) FetchBlockRegions(regions []database.BlockRegion) ([][]by
 		}
 		location := deserializeBlockLoc(blockRow)
 
-		// Ensure the region is within the bounds of the block.
+		// Ensure the region is within the bounds of the block.  The
+		// regions of compressed blocks are checked once the block is
+		// decompressed.
 		endOffset := region.Offset + region.Len
-		if endOffset < region.Offset || endOffset > location.blockLen {
+		if !location.compressed && (endOffset < region.Offset ||
+			endOffset > location.blockLen) {
+
 			str := fmt.Sprintf("block %s region offset %d, length "+
 				"%d exceeds block length of %d", region.Hash,
 				region.Offset, region.Len, location.blockLen)
@@ -1571,11 +1611,35 @@ func (tx *transaction) FetchBlockRegions(regions []database.BlockRegion) ([][]by
 	sort.Sort(bulkFetchDataSorter(fetchList))
 
 	// Read all of the regions in the fetch list and set the results.
+	//
+	// The regions of compressed blocks are taken from the decompressed
+	// block.  The fetch list is sorted by location, so the regions of the
+	// same block are adjacent and the block only has to be decompressed
+	// once for all of them.
+	var lastBlockLoc *blockLocation
+	var lastBlockBytes []byte
 	for i := range fetchList {
 		fetchData := &fetchList[i]
 		ri := fetchData.replyIndex
 		region := &regions[ri]
 		location := fetchData.blockLocation
+		if location.compressed {
+			if lastBlockLoc == nil || *lastBlockLoc != *location {
+				blockBytes, err := tx.readBlock(region.Hash,
+					*location)
+				if err != nil {
+					return nil, err
+				}
+				lastBlockLoc, lastBlockBytes = location, blockBytes
+			}
+			regionBytes, err := blockRegion(lastBlockBytes, region)
+			if err != nil {
+				return nil, err
+			}
+			blockRegions[ri] = regionBytes
+			continue
+		}
+
 		regionBytes, err := tx.db.store.readBlockRegion(*location,
 			region.Offset, region.Len)
 		if err != nil {
@@ -1767,12 +1831,82 @@ func (tx *transaction) PruneBlocks(targetSize uint64) ([]chainhash.Hash, error)
 	}
 
 	// Delete the indexed block locations for the files that we've just deleted.
+	deletedBlockHashes, err := tx.deleteBlockLocs(deletedFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Tracef("Finished pruning. Database now at %d bytes", totalSize)
+
+	return deletedBlockHashes, nil
+}
+
+// PruneBlockFiles deletes the oldest block files as long as all of the blocks
+// they contain are prunable according to the passed function and returns the
+// hashes of the deleted blocks.  The most recent block file is never deleted.
+//
+// This function is part of the database.Tx interface implementation.
+func (tx *transaction) PruneBlockFiles(prunable func(hash *chainhash.Hash) bool) ([]chainhash.Hash, error) {
+	// Ensure transaction state is valid.
+	if err := tx.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	// Ensure the transaction is writable.
+	if !tx.writable {
+		str := "prune block files requires a writable database transaction"
+		return nil, makeDbErr(database.ErrTxNotWritable, str, nil)
+	}
+
+	first, last, _, err := scanBlockFiles(tx.db.store.basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// If we have no files on disk or just a single file on disk, return early.
+	if first == last {
+		return nil, nil
+	}
+
+	// Find the block files which contain blocks that must be kept.
+	keptFiles := make(map[uint32]struct{})
+	cursor := tx.blockIdxBucket.Cursor()
+	for ok := cursor.First(); ok; ok = cursor.Next() {
+		if !prunable((*chainhash.Hash)(cursor.Key())) {
+			loc := deserializeBlockLoc(cursor.Value())
+			keptFiles[loc.blockFileNum] = struct{}{}
+		}
+	}
+
+	// Delete the oldest files up to the first one which has to be kept so
+	// the remaining files stay contiguous.  The last file is never deleted.
+	deletedFiles := make(map[uint32]struct{})
+	for i := uint32(first); i < uint32(last); i++ {
+		if _, ok := keptFiles[i]; ok {
+			break
+		}
+		tx.pendingDelFileNums = append(tx.pendingDelFileNums, i)
+		deletedFiles[i] = struct{}{}
+	}
+	if len(deletedFiles) == 0 {
+		return nil, nil
+	}
+
+	log.Tracef("Pruning block files %d to %d", first,
+		first+len(deletedFiles)-1)
+
+	return tx.deleteBlockLocs(deletedFiles)
+}
+
+// deleteBlockLocs deletes the indexed block locations of the blocks in the
+// passed block files and returns the hashes of the blocks.
+func (tx *transaction) deleteBlockLocs(files map[uint32]struct{}) ([]chainhash.Hash, error) {
 	var deletedBlockHashes []chainhash.Hash
 	cursor := tx.blockIdxBucket.Cursor()
 	for ok := cursor.First(); ok; ok = cursor.Next() {
 		loc := deserializeBlockLoc(cursor.Value())
 
-		_, found := deletedFiles[loc.blockFileNum]
+		_, found := files[loc.blockFileNum]
 		if found {
 			deletedBlockHashes = append(deletedBlockHashes, *(*chainhash.Hash)(cursor.Key()))
 			err := cursor.Delete()
@@ -1782,8 +1916,6 @@ func (tx *transaction) PruneBlocks(targetSize uint64) ([]chainhash.Hash, error)
 		}
 	}
 
-	log.Tracef("Finished pruning. Database now at %d bytes", totalSize)
-
 	return deletedBlockHashes, nil
 }
 
@@ -1865,6 +1997,11 @@ type db struct {
 	closed    bool         // Is the database closed?
 	store     *blockStore  // Handles read/writing blocks to flat files.
 	cache     *dbCache     // Cache layer which wraps underlying leveldb DB.
+
+	// corruptBlockHandler is invoked with the hash of each stored block
+	// which is found to be damaged when it is read.  It is only changed
+	// while the close lock is held for writes.
+	corruptBlockHandler func(hash chainhash.Hash)
 }
 
 // Enforce db implements the database.DB interface.
@@ -2113,9 +2250,18 @@ func initDB(ldb *leveldb.DB) error {
 	return nil
 }
 
+// hasBlockFiles returns whether or not the passed directory contains any flat
+// block files.
+func hasBlockFiles(dirPath string) bool {
+	_, lastFile, _, err := scanBlockFiles(dirPath)
+	return err == nil && lastFile != -1
+}
+
 // openDB opens the database at the provided path.  database.ErrDbDoesNotExist
 // is returned if the database doesn't exist and the create flag is not set.
-func openDB(dbPath string, network wire.BitcoinNet, create bool) (database.DB, error) {
+func openDB(dbPath string, network wire.BitcoinNet, options Options,
+	create bool) (database.DB, error) {
+
 	// Error if the database doesn't exist and the create flag is not set.
 	metadataDbPath := filepath.Join(dbPath, metadataDbName)
 	dbExists := fileExists(metadataDbPath)
@@ -2132,6 +2278,29 @@ func openDB(dbPath string, network wire.BitcoinNet, create bool) (database.DB, e
 		_ = os.MkdirAll(dbPath, 0700)
 	}
 
+	// The flat block files are stored in the database directory unless a
+	// separate directory is configured for them.  Refuse to open an
+	// existing database whose block files are still in the database
+	// directory when they are expected in the separate one since it would
+	// otherwise appear as if they were lost.
+	blocksPath := dbPath
+	if options.BlocksDir != "" {
+		blocksPath = options.BlocksDir
+		if dbExists && hasBlockFiles(dbPath) &&
+			!hasBlockFiles(blocksPath) {
+
+			str := fmt.Sprintf("the block files of database %q are "+
+				"stored in the database directory -- move them to "+
+				"%q to store them separately", dbPath, blocksPath)
+			return nil, makeDbErr(database.ErrDriverSpecific, str, nil)
+		}
+		if err := os.MkdirAll(blocksPath, 0700); err != nil {
+			str := fmt.Sprintf("unable to create block files "+
+				"directory %q: %v", blocksPath, err)
+			return nil, makeDbErr(database.ErrDriverSpecific, str, err)
+		}
+	}
+
 	// Open the metadata database (will create it if needed).
 	opts := opt.Options{
 		ErrorIfExist: create,
@@ -2149,7 +2318,7 @@ func openDB(dbPath string, network wire.BitcoinNet, create bool) (database.DB, e
 	// according to the data that is actually on disk.  Also create the
 	// database cache which wraps the underlying leveldb database to provide
 	// write caching.
-	store, err := newBlockStore(dbPath, network)
+	store, err := newBlockStore(blocksPath, network, options.CompressBlocks)
 	if err != nil {
 		return nil, convertErr(err.Error(), err)
 	}
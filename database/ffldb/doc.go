@@ -25,5 +25,22 @@ database path as a string and the block network:
 	if err != nil {
 		// Handle error
 	}
+
+Both functions optionally take database options after the block network.  For
+example, the blocks written to the flat files are compressed with:
+
+	opts := ffldb.Options{CompressBlocks: true}
+	db, err := database.Open("ffldb", "path/to/database", wire.MainNet, opts)
+	if err != nil {
+		// Handle error
+	}
+
+Each block records whether it is compressed, so the blocks are read the same
+way regardless of the options.  RewriteBlocks rewrites the blocks which were
+stored with different options.
+
+The flat block files are stored in the database directory along with the
+metadata unless the BlocksDir option names a separate directory for them, such
+as one on a larger and slower disk.
 */
 package ffldb
@@ -18,49 +18,74 @@ const (
 	dbType = "ffldb"
 )
 
+// Options houses optional settings of the database.  They may be passed to the
+// Open and Create methods after the block network.
+type Options struct {
+	// CompressBlocks compresses the blocks which are written to the flat
+	// block files.  Blocks which are already stored are left as they are
+	// and can be rewritten with RewriteBlocks.
+	CompressBlocks bool
+
+	// BlocksDir is the directory the flat block files are stored in.  They
+	// are stored in the database directory along with the metadata when it
+	// is empty.
+	BlocksDir string
+}
+
 // parseArgs parses the arguments from the database Open/Create methods.
-func parseArgs(funcName string, args ...interface{}) (string, wire.BitcoinNet, error) {
-	if len(args) != 2 {
-		return "", 0, fmt.Errorf("invalid arguments to %s.%s -- "+
-			"expected database path and block network", dbType,
-			funcName)
+func parseArgs(funcName string, args ...interface{}) (string, wire.BitcoinNet, Options, error) {
+	if len(args) != 2 && len(args) != 3 {
+		return "", 0, Options{}, fmt.Errorf("invalid arguments to "+
+			"%s.%s -- expected database path, block network, and "+
+			"optionally database options", dbType, funcName)
 	}
 
 	dbPath, ok := args[0].(string)
 	if !ok {
-		return "", 0, fmt.Errorf("first argument to %s.%s is invalid -- "+
-			"expected database path string", dbType, funcName)
+		return "", 0, Options{}, fmt.Errorf("first argument to %s.%s "+
+			"is invalid -- expected database path string", dbType,
+			funcName)
 	}
 
 	network, ok := args[1].(wire.BitcoinNet)
 	if !ok {
-		return "", 0, fmt.Errorf("second argument to %s.%s is invalid -- "+
-			"expected block network", dbType, funcName)
+		return "", 0, Options{}, fmt.Errorf("second argument to %s.%s "+
+			"is invalid -- expected block network", dbType, funcName)
+	}
+
+	var opts Options
+	if len(args) == 3 {
+		opts, ok = args[2].(Options)
+		if !ok {
+			return "", 0, Options{}, fmt.Errorf("third argument to "+
+				"%s.%s is invalid -- expected database options",
+				dbType, funcName)
+		}
 	}
 
-	return dbPath, network, nil
+	return dbPath, network, opts, nil
 }
 
 // openDBDriver is the callback provided during driver registration that opens
 // an existing database for use.
 func openDBDriver(args ...interface{}) (database.DB, error) {
-	dbPath, network, err := parseArgs("Open", args...)
+	dbPath, network, opts, err := parseArgs("Open", args...)
 	if err != nil {
 		return nil, err
 	}
 
-	return openDB(dbPath, network, false)
+	return openDB(dbPath, network, opts, false)
 }
 
 // createDBDriver is the callback provided during driver registration that
 // creates, initializes, and opens a database for use.
 func createDBDriver(args ...interface{}) (database.DB, error) {
-	dbPath, network, err := parseArgs("Create", args...)
+	dbPath, network, opts, err := parseArgs("Create", args...)
 	if err != nil {
 		return nil, err
 	}
 
-	return openDB(dbPath, network, true)
+	return openDB(dbPath, network, opts, true)
 }
 
 // useLogger is the callback provided during driver registration that sets the
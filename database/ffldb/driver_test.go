@@ -38,8 +38,9 @@ func TestCreateOpenFail(t *testing.T) {
 	// Ensure that attempting to open a database with the wrong number of
 	// parameters returns the expected error.
 	wantErr := fmt.Errorf("invalid arguments to %s.Open -- expected "+
-		"database path and block network", dbType)
-	_, err = database.Open(dbType, 1, 2, 3)
+		"database path, block network, and optionally database "+
+		"options", dbType)
+	_, err = database.Open(dbType, 1, 2, 3, 4)
 	if err.Error() != wantErr.Error() {
 		t.Errorf("Open: did not receive expected error - got %v, "+
 			"want %v", err, wantErr)
@@ -68,11 +69,23 @@ func TestCreateOpenFail(t *testing.T) {
 		return
 	}
 
+	// Ensure that attempting to open a database with an invalid type for
+	// the third parameter returns the expected error.
+	wantErr = fmt.Errorf("third argument to %s.Open is invalid -- "+
+		"expected database options", dbType)
+	_, err = database.Open(dbType, "noexist", blockDataNet, "invalid")
+	if err.Error() != wantErr.Error() {
+		t.Errorf("Open: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+		return
+	}
+
 	// Ensure that attempting to create a database with the wrong number of
 	// parameters returns the expected error.
 	wantErr = fmt.Errorf("invalid arguments to %s.Create -- expected "+
-		"database path and block network", dbType)
-	_, err = database.Create(dbType, 1, 2, 3)
+		"database path, block network, and optionally database "+
+		"options", dbType)
+	_, err = database.Create(dbType, 1, 2, 3, 4)
 	if err.Error() != wantErr.Error() {
 		t.Errorf("Create: did not receive expected error - got %v, "+
 			"want %v", err, wantErr)
@@ -101,6 +114,17 @@ func TestCreateOpenFail(t *testing.T) {
 		return
 	}
 
+	// Ensure that attempting to create a database with an invalid type for
+	// the third parameter returns the expected error.
+	wantErr = fmt.Errorf("third argument to %s.Create is invalid -- "+
+		"expected database options", dbType)
+	_, err = database.Create(dbType, "noexist", blockDataNet, "invalid")
+	if err.Error() != wantErr.Error() {
+		t.Errorf("Create: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+		return
+	}
+
 	// Ensure operations against a closed database return the expected
 	// error.
 	dbPath := filepath.Join(t.TempDir(), "ffldb-createfail")
@@ -440,6 +464,118 @@ func TestPrune(t *testing.T) {
 	})
 }
 
+// TestPruneBlockFiles tests that only the oldest .fdb files which contain
+// nothing but prunable blocks are deleted with a call to prune block files.
+func TestPruneBlockFiles(t *testing.T) {
+	t.Parallel()
+
+	// Create a new database to run tests against.
+	dbPath := t.TempDir()
+	db, err := database.Create(dbType, dbPath, blockDataNet)
+	if err != nil {
+		t.Errorf("Failed to create test database (%s) %v", dbType, err)
+		return
+	}
+	defer db.Close()
+
+	testfn := func(t *testing.T, db database.DB) {
+		blocks, err := loadBlocks(t, blockDataFile, blockDataNet)
+		if err != nil {
+			t.Errorf("loadBlocks: Unexpected error: %v", err)
+			return
+		}
+		err = db.Update(func(tx database.Tx) error {
+			for i, block := range blocks {
+				err := tx.StoreBlock(block)
+				if err != nil {
+					return fmt.Errorf("StoreBlock #%d: unexpected error: "+
+						"%v", i, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The first half of the blocks is prunable.
+		prunable := make(map[chainhash.Hash]struct{})
+		for _, block := range blocks[:len(blocks)/2] {
+			prunable[*block.Hash()] = struct{}{}
+		}
+		isPrunable := func(hash *chainhash.Hash) bool {
+			_, ok := prunable[*hash]
+			return ok
+		}
+
+		// Nothing is deleted when the blocks of the first file must be
+		// kept.
+		err = db.Update(func(tx database.Tx) error {
+			deleted, err := tx.PruneBlockFiles(func(*chainhash.Hash) bool {
+				return false
+			})
+			if err != nil {
+				return err
+			}
+			if len(deleted) != 0 {
+				return fmt.Errorf("PruneBlockFiles: deleted %d "+
+					"blocks which had to be kept", len(deleted))
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var deletedBlocks []chainhash.Hash
+		err = db.Update(func(tx database.Tx) error {
+			deletedBlocks, err = tx.PruneBlockFiles(isPrunable)
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(deletedBlocks) == 0 {
+			t.Fatal("PruneBlockFiles: no blocks were deleted")
+		}
+
+		// Only prunable blocks were deleted and all of the other blocks
+		// are still present.
+		deleted := make(map[chainhash.Hash]struct{})
+		for _, hash := range deletedBlocks {
+			if !isPrunable(&hash) {
+				t.Fatalf("PruneBlockFiles: deleted block %v which "+
+					"had to be kept", hash)
+			}
+			deleted[hash] = struct{}{}
+		}
+		err = db.View(func(tx database.Tx) error {
+			for _, block := range blocks {
+				_, err := tx.FetchBlock(block.Hash())
+				_, wasDeleted := deleted[*block.Hash()]
+				dbErr, ok := err.(database.Error)
+				if wasDeleted && (!ok ||
+					dbErr.ErrorCode != database.ErrBlockNotFound) {
+
+					return fmt.Errorf("Expected ErrBlockNotFound "+
+						"for block %v but got %v", block.Hash(),
+						err)
+				}
+				if !wasDeleted && err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	ffldb.TstRunWithMaxBlockFileSize(db, 2048, func() {
+		testfn(t, db)
+	})
+}
+
 // TestInterface performs all interfaces tests for this database driver.
 func TestInterface(t *testing.T) {
 	t.Parallel()
@@ -470,3 +606,471 @@ func TestInterface(t *testing.T) {
 		testInterface(t, db)
 	})
 }
+
+// TestInterfaceCompressed performs all interfaces tests for this database
+// driver with block compression enabled.
+func TestInterfaceCompressed(t *testing.T) {
+	t.Parallel()
+
+	// Create a new database to run tests against.
+	dbPath := filepath.Join(t.TempDir(), "ffldb-interfacetest")
+	db, err := database.Create(dbType, dbPath, blockDataNet,
+		ffldb.Options{CompressBlocks: true})
+	if err != nil {
+		t.Errorf("Failed to create test database (%s) %v", dbType, err)
+		return
+	}
+	defer db.Close()
+
+	// Change the maximum file size to a small value to force multiple flat
+	// files with the test data set.
+	ffldb.TstRunWithMaxBlockFileSize(db, 2048, func() {
+		testInterface(t, db)
+	})
+}
+
+// blockFilesSize returns the number and the total size of the block files of
+// the database at the passed path.
+func blockFilesSize(t *testing.T, dbPath string) (int, int64) {
+	paths, err := filepath.Glob(filepath.Join(dbPath, "*.fdb"))
+	if err != nil {
+		t.Fatalf("unable to list block files: %v", err)
+	}
+	var size int64
+	for _, path := range paths {
+		fi, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("unable to stat block file: %v", err)
+		}
+		size += fi.Size()
+	}
+	return len(paths), size
+}
+
+// TestRewriteBlocks ensures the blocks which were stored before block
+// compression was enabled or disabled are rewritten accordingly and can still
+// be fetched afterwards.
+func TestRewriteBlocks(t *testing.T) {
+	t.Parallel()
+
+	blocks, err := loadBlocks(t, blockDataFile, blockDataNet)
+	if err != nil {
+		t.Fatalf("loadBlocks: Unexpected error: %v", err)
+	}
+
+	// Store the blocks uncompressed in several block files.
+	dbPath := filepath.Join(t.TempDir(), "ffldb-rewrite")
+	db, err := database.Create(dbType, dbPath, blockDataNet)
+	if err != nil {
+		t.Fatalf("Failed to create test database (%s) %v", dbType, err)
+	}
+	ffldb.TstRunWithMaxBlockFileSize(db, 8192, func() {
+		err = db.Update(func(tx database.Tx) error {
+			for _, block := range blocks {
+				if err := tx.StoreBlock(block); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	db.Close()
+	if err != nil {
+		t.Fatalf("StoreBlock: unexpected error: %v", err)
+	}
+	numFiles, uncompressedSize := blockFilesSize(t, dbPath)
+
+	// rewrite opens the database with the passed options, rewrites its
+	// blocks, and ensures all of the blocks and their regions can still be
+	// fetched.
+	rewrite := func(opts ffldb.Options) int {
+		db, err := database.Open(dbType, dbPath, blockDataNet, opts)
+		if err != nil {
+			t.Fatalf("Failed to open test database (%s) %v", dbType,
+				err)
+		}
+		defer db.Close()
+
+		numRewritten, err := ffldb.RewriteBlocks(db, nil)
+		if err != nil {
+			t.Fatalf("RewriteBlocks: unexpected error: %v", err)
+		}
+
+		err = db.View(func(tx database.Tx) error {
+			for i, block := range blocks {
+				want, err := block.Bytes()
+				if err != nil {
+					return err
+				}
+				got, err := tx.FetchBlock(block.Hash())
+				if err != nil {
+					return err
+				}
+				if !bytes.Equal(got, want) {
+					return fmt.Errorf("block #%d does not match", i)
+				}
+
+				regions := []database.BlockRegion{
+					{Hash: block.Hash(), Offset: 0, Len: 80},
+					{Hash: block.Hash(), Offset: 80,
+						Len: uint32(len(want)) - 80},
+				}
+				region, err := tx.FetchBlockRegion(&regions[1])
+				if err != nil {
+					return err
+				}
+				if !bytes.Equal(region, want[80:]) {
+					return fmt.Errorf("region of block #%d does "+
+						"not match", i)
+				}
+				regionBytes, err := tx.FetchBlockRegions(regions)
+				if err != nil {
+					return err
+				}
+				if !bytes.Equal(append(regionBytes[0], regionBytes[1]...), want) {
+					return fmt.Errorf("regions of block #%d do "+
+						"not match", i)
+				}
+			}
+
+			beenPruned, err := tx.BeenPruned()
+			if err != nil {
+				return err
+			}
+			if beenPruned {
+				return fmt.Errorf("database is reported as pruned")
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return numRewritten
+	}
+
+	// Compressing the blocks rewrites all but the current write file and
+	// leaves the same block files in place.
+	if n := rewrite(ffldb.Options{CompressBlocks: true}); n == 0 {
+		t.Fatal("RewriteBlocks: no blocks were compressed")
+	}
+	gotNumFiles, compressedSize := blockFilesSize(t, dbPath)
+	if gotNumFiles != numFiles {
+		t.Fatalf("got %d block files after compressing, want %d",
+			gotNumFiles, numFiles)
+	}
+	if compressedSize >= uncompressedSize {
+		t.Fatalf("block files did not shrink -- got %d bytes, had %d",
+			compressedSize, uncompressedSize)
+	}
+
+	// Rewriting again with the same options doesn't change anything.
+	if n := rewrite(ffldb.Options{CompressBlocks: true}); n != 0 {
+		t.Fatalf("RewriteBlocks: rewrote %d already compressed blocks", n)
+	}
+
+	// Disabling compression restores the original block files.
+	if n := rewrite(ffldb.Options{}); n == 0 {
+		t.Fatal("RewriteBlocks: no blocks were decompressed")
+	}
+	gotNumFiles, size := blockFilesSize(t, dbPath)
+	if gotNumFiles != numFiles || size != uncompressedSize {
+		t.Fatalf("got %d block files of %d bytes after decompressing, "+
+			"want %d files of %d bytes", gotNumFiles, size, numFiles,
+			uncompressedSize)
+	}
+}
+
+// TestBlocksDir ensures the block files are stored in a separate directory
+// when one is configured and that a database whose block files are still in the
+// database directory is not opened with a separate one.
+func TestBlocksDir(t *testing.T) {
+	t.Parallel()
+
+	blocks, err := loadBlocks(t, blockDataFile, blockDataNet)
+	if err != nil {
+		t.Fatalf("loadBlocks: Unexpected error: %v", err)
+	}
+
+	// Store the blocks with the block files in a separate directory.
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "ffldb-blocksdir")
+	blocksDir := filepath.Join(tempDir, "blocks")
+	opts := ffldb.Options{BlocksDir: blocksDir}
+	db, err := database.Create(dbType, dbPath, blockDataNet, opts)
+	if err != nil {
+		t.Fatalf("Failed to create test database (%s) %v", dbType, err)
+	}
+	err = db.Update(func(tx database.Tx) error {
+		for _, block := range blocks {
+			if err := tx.StoreBlock(block); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	db.Close()
+	if err != nil {
+		t.Fatalf("StoreBlock: unexpected error: %v", err)
+	}
+	if numFiles, _ := blockFilesSize(t, dbPath); numFiles != 0 {
+		t.Fatalf("got %d block files in the database directory, want 0",
+			numFiles)
+	}
+	if numFiles, _ := blockFilesSize(t, blocksDir); numFiles == 0 {
+		t.Fatal("no block files in the blocks directory")
+	}
+
+	// The blocks can be fetched after reopening the database.
+	db, err = database.Open(dbType, dbPath, blockDataNet, opts)
+	if err != nil {
+		t.Fatalf("Failed to open test database (%s) %v", dbType, err)
+	}
+	err = db.View(func(tx database.Tx) error {
+		for i, block := range blocks {
+			want, err := block.Bytes()
+			if err != nil {
+				return err
+			}
+			got, err := tx.FetchBlock(block.Hash())
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal(got, want) {
+				return fmt.Errorf("block #%d does not match", i)
+			}
+		}
+		return nil
+	})
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A database with its block files in the database directory is not
+	// opened with an empty separate blocks directory.
+	dbPath = filepath.Join(tempDir, "ffldb-blocksdir-move")
+	db, err = database.Create(dbType, dbPath, blockDataNet)
+	if err != nil {
+		t.Fatalf("Failed to create test database (%s) %v", dbType, err)
+	}
+	err = db.Update(func(tx database.Tx) error {
+		return tx.StoreBlock(blocks[0])
+	})
+	db.Close()
+	if err != nil {
+		t.Fatalf("StoreBlock: unexpected error: %v", err)
+	}
+	opts.BlocksDir = filepath.Join(tempDir, "moved-blocks")
+	_, err = database.Open(dbType, dbPath, blockDataNet, opts)
+	checkDbError(t, "Open", err, database.ErrDriverSpecific)
+}
+
+// TestDiskUsageCompact ensures the disk usage of the block files and of the
+// buckets of the metadata is reported and that the metadata can be compacted
+// while the database is open.
+func TestDiskUsageCompact(t *testing.T) {
+	t.Parallel()
+
+	blocks, err := loadBlocks(t, blockDataFile, blockDataNet)
+	if err != nil {
+		t.Fatalf("loadBlocks: Unexpected error: %v", err)
+	}
+
+	// Store the blocks along with a bucket which has a nested bucket.
+	dbPath := filepath.Join(t.TempDir(), "ffldb-usage")
+	db, err := database.Create(dbType, dbPath, blockDataNet)
+	if err != nil {
+		t.Fatalf("Failed to create test database (%s) %v", dbType, err)
+	}
+	value := bytes.Repeat([]byte{0x01}, 1024)
+	err = db.Update(func(tx database.Tx) error {
+		for _, block := range blocks {
+			if err := tx.StoreBlock(block); err != nil {
+				return err
+			}
+		}
+		parent, err := tx.Metadata().CreateBucket([]byte("usage"))
+		if err != nil {
+			return err
+		}
+		nested, err := parent.CreateBucket([]byte("nested"))
+		if err != nil {
+			return err
+		}
+		for i := 0; i < 1000; i++ {
+			key := []byte(fmt.Sprintf("key%d", i))
+			if err := nested.Put(key, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	db.Close()
+	if err != nil {
+		t.Fatalf("Update: unexpected error: %v", err)
+	}
+
+	// Reopen the database so the metadata is written to its tables.
+	db, err = database.Open(dbType, dbPath, blockDataNet)
+	if err != nil {
+		t.Fatalf("Failed to open test database (%s) %v", dbType, err)
+	}
+	defer db.Close()
+
+	usage, err := ffldb.DiskUsage(db)
+	if err != nil {
+		t.Fatalf("DiskUsage: unexpected error: %v", err)
+	}
+	numFiles, size := blockFilesSize(t, dbPath)
+	if usage.BlockFiles != numFiles || usage.BlockFilesSize != size {
+		t.Fatalf("got %d block files of %d bytes, want %d files of %d "+
+			"bytes", usage.BlockFiles, usage.BlockFilesSize, numFiles,
+			size)
+	}
+	if usage.MetadataSize == 0 {
+		t.Fatal("DiskUsage: no metadata size reported")
+	}
+	var bucketSize int64
+	for _, bucket := range usage.Buckets {
+		if bucket.Name == "usage" {
+			bucketSize = bucket.Size
+		}
+	}
+	if bucketSize < int64(len(value))*1000/2 {
+		t.Fatalf("got size %d for the bucket with 1000 values of %d "+
+			"bytes", bucketSize, len(value))
+	}
+
+	// Delete the bucket and ensure compacting the metadata reclaims the
+	// space it used.
+	err = db.Update(func(tx database.Tx) error {
+		return tx.Metadata().DeleteBucket([]byte("usage"))
+	})
+	if err != nil {
+		t.Fatalf("DeleteBucket: unexpected error: %v", err)
+	}
+	if err := ffldb.Compact(db); err != nil {
+		t.Fatalf("Compact: unexpected error: %v", err)
+	}
+	compacted, err := ffldb.DiskUsage(db)
+	if err != nil {
+		t.Fatalf("DiskUsage: unexpected error: %v", err)
+	}
+	if compacted.MetadataSize >= usage.MetadataSize {
+		t.Fatalf("metadata did not shrink -- got %d bytes, had %d",
+			compacted.MetadataSize, usage.MetadataSize)
+	}
+}
+
+// TestRepairBlock ensures damaged stored blocks are reported to the corrupt
+// block handler when they are read and that they can be repaired.
+func TestRepairBlock(t *testing.T) {
+	t.Parallel()
+
+	blocks, err := loadBlocks(t, blockDataFile, blockDataNet)
+	if err != nil {
+		t.Fatalf("loadBlocks: Unexpected error: %v", err)
+	}
+
+	// Store all but the last block.
+	dbPath := filepath.Join(t.TempDir(), "ffldb-repair")
+	db, err := database.Create(dbType, dbPath, blockDataNet)
+	if err != nil {
+		t.Fatalf("Failed to create test database (%s) %v", dbType, err)
+	}
+	err = db.Update(func(tx database.Tx) error {
+		for _, block := range blocks[:len(blocks)-1] {
+			if err := tx.StoreBlock(block); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	db.Close()
+	if err != nil {
+		t.Fatalf("StoreBlock: unexpected error: %v", err)
+	}
+
+	// Damage the first block, which is at the start of the first block
+	// file.
+	filePath := filepath.Join(dbPath, "000000000.fdb")
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("unable to read block file: %v", err)
+	}
+	data[100] ^= 0x10
+	if err := os.WriteFile(filePath, data, 0600); err != nil {
+		t.Fatalf("unable to write block file: %v", err)
+	}
+
+	db, err = database.Open(dbType, dbPath, blockDataNet)
+	if err != nil {
+		t.Fatalf("Failed to open test database (%s) %v", dbType, err)
+	}
+	defer db.Close()
+	var reported []chainhash.Hash
+	err = ffldb.SetCorruptBlockHandler(db, func(hash chainhash.Hash) {
+		reported = append(reported, hash)
+	})
+	if err != nil {
+		t.Fatalf("SetCorruptBlockHandler: unexpected error: %v", err)
+	}
+
+	// fetchBlock fetches the passed block and returns the error, if any,
+	// after ensuring the fetched block matches it.
+	fetchBlock := func(block *btcutil.Block) error {
+		return db.View(func(tx database.Tx) error {
+			got, err := tx.FetchBlock(block.Hash())
+			if err != nil {
+				return err
+			}
+			want, err := block.Bytes()
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal(got, want) {
+				return fmt.Errorf("block %s does not match",
+					block.Hash())
+			}
+			return nil
+		})
+	}
+
+	// The damaged block is reported when it is read.
+	err = fetchBlock(blocks[0])
+	if !checkDbError(t, "FetchBlock", err, database.ErrBlockCorrupt) {
+		return
+	}
+	if len(reported) != 1 || reported[0] != *blocks[0].Hash() {
+		t.Fatalf("got reported blocks %v, want %v", reported,
+			blocks[0].Hash())
+	}
+
+	// Repairing an intact block doesn't write anything.
+	_, size := blockFilesSize(t, dbPath)
+	if err := ffldb.RepairBlock(db, blocks[1]); err != nil {
+		t.Fatalf("RepairBlock: unexpected error: %v", err)
+	}
+	if _, gotSize := blockFilesSize(t, dbPath); gotSize != size {
+		t.Fatalf("block files grew from %d to %d bytes when repairing "+
+			"an intact block", size, gotSize)
+	}
+
+	// Blocks which aren't stored can't be repaired.
+	err = ffldb.RepairBlock(db, blocks[len(blocks)-1])
+	checkDbError(t, "RepairBlock", err, database.ErrBlockNotFound)
+
+	// The repaired block can be fetched again along with the other blocks.
+	if err := ffldb.RepairBlock(db, blocks[0]); err != nil {
+		t.Fatalf("RepairBlock: unexpected error: %v", err)
+	}
+	for _, block := range blocks[:len(blocks)-1] {
+		if err := fetchBlock(block); err != nil {
+			t.Fatalf("FetchBlock: unexpected error: %v", err)
+		}
+	}
+	if len(reported) != 1 {
+		t.Fatalf("got %d reported blocks after the repair, want 1",
+			len(reported))
+	}
+}
@@ -113,5 +113,11 @@ func reconcileDB(pdb *db, create bool) (database.DB, error) {
 		return nil, makeDbErr(database.ErrCorruption, str, nil)
 	}
 
+	// Complete or discard the rewrite of a block file which was interrupted
+	// by an unclean shutdown.
+	if err := reconcileRewrite(pdb); err != nil {
+		return nil, err
+	}
+
 	return pdb, nil
 }
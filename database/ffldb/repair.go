@@ -0,0 +1,81 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ffldb
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/v2"
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/database"
+)
+
+// SetCorruptBlockHandler sets the function which is invoked with the hash of
+// each stored block of the passed database, which must be an ffldb database,
+// that is found to be damaged when it is read, so that it can be repaired with
+// RepairBlock.  A nil handler removes the current one.
+//
+// The handler is invoked during the database transaction which read the block,
+// so it must return quickly and must not access the database.  It may be
+// invoked several times for the same block.
+func SetCorruptBlockHandler(idb database.DB, handler func(hash chainhash.Hash)) error {
+	pdb, ok := idb.(*db)
+	if !ok {
+		return fmt.Errorf("database is not an %s database", dbType)
+	}
+
+	pdb.closeLock.Lock()
+	pdb.corruptBlockHandler = handler
+	pdb.closeLock.Unlock()
+	return nil
+}
+
+// RepairBlock replaces the damaged stored copy of the passed block in the
+// passed database, which must be an ffldb database, with the passed block.  The
+// block is written to the end of the block files like a new block, and the
+// block index is updated to refer to it.  The space used by the damaged copy is
+// not reclaimed.  Nothing is written when the stored copy turns out to be
+// intact.
+//
+// The passed block must have been checked to match the damaged one, such as by
+// its merkle root, since the stored copy can't be compared with it.
+//
+// Returns ErrBlockNotFound when the block isn't stored in the database.
+func RepairBlock(idb database.DB, block *btcutil.Block) error {
+	pdb, ok := idb.(*db)
+	if !ok {
+		return fmt.Errorf("database is not an %s database", dbType)
+	}
+
+	blockHash := block.Hash()
+	blockBytes, err := block.Bytes()
+	if err != nil {
+		str := fmt.Sprintf("failed to get serialized bytes for block %s",
+			blockHash)
+		return makeDbErr(database.ErrDriverSpecific, str, err)
+	}
+
+	return pdb.Update(func(dbTx database.Tx) error {
+		tx := dbTx.(*transaction)
+		blockRow, err := tx.fetchBlockRow(blockHash)
+		if err != nil {
+			return err
+		}
+
+		// The stored copy is read directly from the block files so the
+		// damage isn't reported again.
+		location := deserializeBlockLoc(blockRow)
+		storedBytes, err := pdb.store.readBlock(blockHash, location)
+		if err == nil && bytes.Equal(storedBytes, blockBytes) {
+			return nil
+		}
+
+		log.Infof("Replacing the damaged stored copy of block %s",
+			blockHash)
+		tx.addPendingBlock(blockHash, blockBytes)
+		return nil
+	})
+}
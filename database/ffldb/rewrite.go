@@ -0,0 +1,371 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// This file contains the functions which rewrite the flat block files so all of
+// the blocks are stored according to the current block compression setting.
+
+package ffldb
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/database"
+)
+
+// rewriteFileExtension is the extension which is appended to the path of a
+// block file for the temporary file it is rewritten to.
+const rewriteFileExtension = ".tmp"
+
+var (
+	// rewriteFileKeyName is the key used to store the number of the block
+	// file whose rewritten temporary file has to replace it.  It is stored
+	// together with the new locations of the blocks of the file, so the
+	// replacement can be completed when it is interrupted.
+	rewriteFileKeyName = []byte("ffldb-rewritefile")
+
+	// errRewriteInterrupted is returned when the rewrite of the blocks is
+	// interrupted.
+	errRewriteInterrupted = errors.New("block rewrite interrupted")
+)
+
+// rewriteBlock identifies a block which is stored in a block file that is being
+// rewritten.
+type rewriteBlock struct {
+	hash chainhash.Hash
+	loc  blockLocation
+}
+
+// rewriteFilePath returns the path of the temporary file the provided block
+// file is rewritten to.
+func rewriteFilePath(dbPath string, fileNum uint32) string {
+	return blockFilePath(dbPath, fileNum) + rewriteFileExtension
+}
+
+// needsRewrite returns whether the passed block is not stored the way it would
+// be written with the current block compression setting.
+func (s *blockStore) needsRewrite(block *rewriteBlock) (bool, error) {
+	if block.loc.compressed || !s.compressBlocks {
+		return block.loc.compressed != s.compressBlocks, nil
+	}
+
+	// Blocks which don't get any smaller are stored uncompressed even when
+	// block compression is enabled.
+	rawBlock, err := s.readBlock(&block.hash, block.loc)
+	if err != nil {
+		return false, err
+	}
+	_, compressed := s.encodeBlock(rawBlock)
+	return compressed, nil
+}
+
+// writeRewriteFile writes the passed blocks, which must be ordered by their
+// offset in the block file, to the temporary file the block file is rewritten
+// to.  It returns the locations of the blocks in the rewritten file.
+//
+// Format: <network><block length><serialized block><checksum>
+func (s *blockStore) writeRewriteFile(fileNum uint32,
+	blocks []rewriteBlock) ([]blockLocation, error) {
+
+	filePath := rewriteFilePath(s.basePath, fileNum)
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC,
+		0666)
+	if err != nil {
+		str := fmt.Sprintf("failed to open file %q: %v", filePath, err)
+		return nil, makeDbErr(database.ErrDriverSpecific, str, err)
+	}
+	defer file.Close()
+
+	w := bufio.NewWriterSize(file, 1<<20)
+	locs := make([]blockLocation, 0, len(blocks))
+	var offset uint32
+	for i := range blocks {
+		block := &blocks[i]
+		rawBlock, err := s.readBlock(&block.hash, block.loc)
+		if err != nil {
+			return nil, err
+		}
+		rawBlock, compressed := s.encodeBlock(rawBlock)
+
+		blockLen := uint32(len(rawBlock))
+		serializedLen := blockLen
+		if compressed {
+			serializedLen |= compressedBlockFlag
+		}
+		var header [8]byte
+		byteOrder.PutUint32(header[0:4], uint32(s.network))
+		byteOrder.PutUint32(header[4:8], serializedLen)
+		hasher := crc32.New(castagnoli)
+		_, _ = hasher.Write(header[:])
+		_, _ = hasher.Write(rawBlock)
+
+		// The buffered writer keeps the first error that occurs, so it
+		// is enough to check the last write.
+		_, _ = w.Write(header[:])
+		_, _ = w.Write(rawBlock)
+		if _, err := w.Write(hasher.Sum(nil)); err != nil {
+			str := fmt.Sprintf("failed to write block %s to %q: %v",
+				block.hash, filePath, err)
+			return nil, makeDbErr(database.ErrDriverSpecific, str, err)
+		}
+
+		locs = append(locs, blockLocation{
+			blockFileNum: fileNum,
+			fileOffset:   offset,
+			blockLen:     blockLen + 12,
+			compressed:   compressed,
+		})
+		offset += blockLen + 12
+	}
+
+	// Make sure the rewritten file is fully on disk before the new block
+	// locations are stored.
+	err = w.Flush()
+	if err == nil {
+		err = file.Sync()
+	}
+	if err != nil {
+		str := fmt.Sprintf("failed to write %q: %v", filePath, err)
+		return nil, makeDbErr(database.ErrDriverSpecific, str, err)
+	}
+
+	return locs, nil
+}
+
+// replaceWithRewriteFile replaces the provided block file with the temporary
+// file it was rewritten to.  Any open handle of the block file is closed first
+// so the blocks are read from the rewritten file from then on.
+func (s *blockStore) replaceWithRewriteFile(fileNum uint32) error {
+	s.obfMutex.Lock()
+	defer s.obfMutex.Unlock()
+
+	s.lruMutex.Lock()
+	if elem, ok := s.fileNumToLRUElem[fileNum]; ok {
+		s.openBlocksLRU.Remove(elem)
+	}
+	s.closeFile(fileNum)
+	s.lruMutex.Unlock()
+
+	err := os.Rename(rewriteFilePath(s.basePath, fileNum),
+		blockFilePath(s.basePath, fileNum))
+	if err != nil {
+		return makeDbErr(database.ErrDriverSpecific, err.Error(), err)
+	}
+
+	return nil
+}
+
+// storeRewriteLocs stores the locations of the passed blocks in the rewritten
+// version of the provided block file along with the number of the file, and
+// flushes them to disk so the file can be replaced.
+func (db *db) storeRewriteLocs(fileNum uint32, blocks []rewriteBlock,
+	locs []blockLocation) error {
+
+	err := db.Update(func(dbTx database.Tx) error {
+		tx := dbTx.(*transaction)
+		for i := range blocks {
+			hash := blocks[i].hash[:]
+			blockRow := tx.blockIdxBucket.Get(hash)
+			newRow := append(serializeBlockLoc(locs[i]),
+				blockRow[blockLocSize:]...)
+			if err := tx.blockIdxBucket.Put(hash, newRow); err != nil {
+				return err
+			}
+		}
+
+		var serializedFileNum [4]byte
+		byteOrder.PutUint32(serializedFileNum[:], fileNum)
+		return tx.metaBucket.Put(rewriteFileKeyName, serializedFileNum[:])
+	})
+	if err != nil {
+		return err
+	}
+
+	return db.Update(func(dbTx database.Tx) error {
+		return dbTx.(*transaction).db.cache.flush()
+	})
+}
+
+// rewriteBlockFile rewrites the provided block file when any of the passed
+// blocks, which are all of the blocks stored in it, is not stored the way it
+// would be written with the current block compression setting.  It returns the
+// number of rewritten blocks.
+func (db *db) rewriteBlockFile(fileNum uint32, blocks []rewriteBlock) (int, error) {
+	var needed bool
+	for i := range blocks {
+		var err error
+		needed, err = db.store.needsRewrite(&blocks[i])
+		if err != nil {
+			return 0, err
+		}
+		if needed {
+			break
+		}
+	}
+	if !needed {
+		return 0, nil
+	}
+
+	// Write the blocks to a temporary file in the same order they are
+	// stored in the block file.
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].loc.fileOffset < blocks[j].loc.fileOffset
+	})
+	locs, err := db.store.writeRewriteFile(fileNum, blocks)
+	if err != nil {
+		_ = os.Remove(rewriteFilePath(db.store.basePath, fileNum))
+		return 0, err
+	}
+
+	// Should the replacement of the file be interrupted once the new
+	// locations are stored, it is completed when the database is opened the
+	// next time.  Otherwise, the temporary file is removed then.
+	if err := db.storeRewriteLocs(fileNum, blocks, locs); err != nil {
+		return 0, err
+	}
+	if err := db.store.replaceWithRewriteFile(fileNum); err != nil {
+		return 0, err
+	}
+	err = db.Update(func(dbTx database.Tx) error {
+		return dbTx.(*transaction).metaBucket.Delete(rewriteFileKeyName)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(blocks), nil
+}
+
+// RewriteBlocks rewrites the block files whose blocks are not all stored the way
+// they would be written with the block compression option the database was
+// opened with.  This migrates the blocks which were stored before the option
+// was changed.  It returns the number of rewritten blocks.
+//
+// Each block file is rewritten to a temporary file which then replaces it, so
+// the blocks stay in the same block files.  The file that new blocks are
+// written to is not rewritten.  The rewrite stops after the current file when
+// the interrupt channel is closed.
+//
+// The blocks must not be fetched while their block file is replaced, so this
+// should only be used when there are no other users of the database.
+func RewriteBlocks(idb database.DB, interrupt <-chan struct{}) (int, error) {
+	pdb, ok := idb.(*db)
+	if !ok {
+		return 0, fmt.Errorf("database is not an %s database", dbType)
+	}
+
+	// Group the blocks by the block file they are stored in.  New blocks are
+	// already written the way they should be, so the current write file is
+	// left as it is.
+	wc := pdb.store.writeCursor
+	wc.RLock()
+	writeFileNum := wc.curFileNum
+	wc.RUnlock()
+	fileBlocks := make(map[uint32][]rewriteBlock)
+	err := pdb.View(func(dbTx database.Tx) error {
+		cursor := dbTx.(*transaction).blockIdxBucket.Cursor()
+		for ok := cursor.First(); ok; ok = cursor.Next() {
+			loc := deserializeBlockLoc(cursor.Value())
+			if loc.blockFileNum >= writeFileNum {
+				continue
+			}
+			block := rewriteBlock{loc: loc}
+			copy(block.hash[:], cursor.Key())
+			fileBlocks[loc.blockFileNum] = append(
+				fileBlocks[loc.blockFileNum], block)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	fileNums := make([]uint32, 0, len(fileBlocks))
+	for fileNum := range fileBlocks {
+		fileNums = append(fileNums, fileNum)
+	}
+	sort.Slice(fileNums, func(i, j int) bool {
+		return fileNums[i] < fileNums[j]
+	})
+
+	var numRewritten int
+	for _, fileNum := range fileNums {
+		select {
+		case <-interrupt:
+			return numRewritten, errRewriteInterrupted
+		default:
+		}
+
+		n, err := pdb.rewriteBlockFile(fileNum, fileBlocks[fileNum])
+		if err != nil {
+			return numRewritten, err
+		}
+		if n != 0 {
+			log.Infof("Rewrote %d blocks of block file %d", n, fileNum)
+		}
+		numRewritten += n
+	}
+
+	return numRewritten, nil
+}
+
+// reconcileRewrite completes the replacement of a block file by the temporary
+// file it was rewritten to when it was interrupted after the new locations of
+// the blocks were stored.  The temporary files of any rewrites which were
+// interrupted before that are removed since the blocks are still read from the
+// original block files.
+func reconcileRewrite(pdb *db) error {
+	var fileNum uint32
+	var pending bool
+	err := pdb.View(func(tx database.Tx) error {
+		serializedFileNum := tx.Metadata().Get(rewriteFileKeyName)
+		if serializedFileNum != nil {
+			fileNum = byteOrder.Uint32(serializedFileNum)
+			pending = true
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if pending {
+		filePath := rewriteFilePath(pdb.store.basePath, fileNum)
+		if fileExists(filePath) {
+			log.Infof("Completing the interrupted rewrite of block "+
+				"file %d", fileNum)
+			err := os.Rename(filePath,
+				blockFilePath(pdb.store.basePath, fileNum))
+			if err != nil {
+				return err
+			}
+		}
+		err := pdb.Update(func(tx database.Tx) error {
+			return tx.Metadata().Delete(rewriteFileKeyName)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	filePaths, err := filepath.Glob(filepath.Join(pdb.store.basePath,
+		"*"+blockFileExtension+rewriteFileExtension))
+	if err != nil {
+		return err
+	}
+	for _, filePath := range filePaths {
+		log.Infof("Removing the incomplete rewritten block file %q",
+			filePath)
+		if err := os.Remove(filePath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ffldb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/btcsuite/btcd/database"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// BucketUsage is the approximate disk space used by a top-level bucket of the
+// metadata, including the buckets nested within it.
+type BucketUsage struct {
+	Name string
+	Size int64
+}
+
+// Usage describes the disk space used by a database.
+type Usage struct {
+	// BlockFiles is the number of flat block files and BlockFilesSize is
+	// their total size.
+	BlockFiles     int
+	BlockFilesSize int64
+
+	// MetadataSize is the total size of the tables of the leveldb database
+	// which holds the metadata.  Recent changes which are still cached in
+	// memory or only written to its journal are not included.
+	MetadataSize int64
+
+	// Buckets is the approximate disk space used by each top-level bucket
+	// of the metadata ordered by name.
+	Buckets []BucketUsage
+}
+
+// bucketIDs returns the IDs of the passed bucket and all of the buckets nested
+// within it.
+func bucketIDs(b *bucket) ([][4]byte, error) {
+	ids := [][4]byte{b.id}
+	err := b.ForEachBucket(func(k []byte) error {
+		child, ok := b.Bucket(k).(*bucket)
+		if !ok {
+			return fmt.Errorf("unable to open nested bucket %q", k)
+		}
+		childIDs, err := bucketIDs(child)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, childIDs...)
+		return nil
+	})
+	return ids, err
+}
+
+// DiskUsage returns the disk space used by the flat block files and the
+// metadata of the passed database, which must be an ffldb database.
+//
+// This function is safe for concurrent access.
+func DiskUsage(idb database.DB) (*Usage, error) {
+	pdb, ok := idb.(*db)
+	if !ok {
+		return nil, fmt.Errorf("database is not an %s database", dbType)
+	}
+
+	var usage Usage
+	err := pdb.View(func(dbTx database.Tx) error {
+		paths, err := filepath.Glob(filepath.Join(pdb.store.basePath,
+			"*"+blockFileExtension))
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			fi, err := os.Stat(path)
+			if err != nil {
+				return err
+			}
+			usage.BlockFiles++
+			usage.BlockFilesSize += fi.Size()
+		}
+
+		ldb := pdb.cache.ldb
+		var stats leveldb.DBStats
+		if err := ldb.Stats(&stats); err != nil {
+			return convertErr(err.Error(), err)
+		}
+		usage.MetadataSize = stats.LevelSizes.Sum()
+
+		// The keys of each bucket are prefixed with its ID, so the space
+		// used by a bucket is the size of the key ranges of its ID and
+		// the IDs of its nested buckets.  The buckets are iterated in
+		// the order of their names.
+		meta := dbTx.Metadata().(*bucket)
+		return meta.ForEachBucket(func(k []byte) error {
+			b, ok := meta.Bucket(k).(*bucket)
+			if !ok {
+				return fmt.Errorf("unable to open bucket %q", k)
+			}
+			ids, err := bucketIDs(b)
+			if err != nil {
+				return err
+			}
+			ranges := make([]util.Range, 0, len(ids))
+			for _, id := range ids {
+				ranges = append(ranges, *util.BytesPrefix(id[:]))
+			}
+			sizes, err := ldb.SizeOf(ranges)
+			if err != nil {
+				return convertErr(err.Error(), err)
+			}
+			usage.Buckets = append(usage.Buckets, BucketUsage{
+				Name: string(k),
+				Size: sizes.Sum(),
+			})
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+// Compact compacts the whole leveldb database which holds the metadata of the
+// passed database, which must be an ffldb database.  This discards the data
+// which was overwritten or deleted, such as spent outputs and the data of
+// dropped indexes, and reclaims the disk space it used.
+//
+// The database remains usable while it is compacted, but compacting it takes a
+// long time for large databases and considerably slows down other accesses
+// meanwhile.  ErrDbNotOpen is returned when the database is closed before the
+// compaction is done.
+//
+// This function is safe for concurrent access.
+func Compact(idb database.DB) error {
+	pdb, ok := idb.(*db)
+	if !ok {
+		return fmt.Errorf("database is not an %s database", dbType)
+	}
+
+	// Flush the cache first so the data which was recently overwritten or
+	// deleted is discarded as well.
+	pdb.closeLock.RLock()
+	if pdb.closed {
+		pdb.closeLock.RUnlock()
+		return makeDbErr(database.ErrDbNotOpen, errDbNotOpenStr, nil)
+	}
+	pdb.writeLock.Lock()
+	err := pdb.cache.flush()
+	pdb.writeLock.Unlock()
+	pdb.closeLock.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	// The locks aren't held while compacting, so closing the database isn't
+	// delayed until the compaction is done.  Instead, the compaction is
+	// aborted when the database is closed.
+	err = pdb.cache.ldb.CompactRange(util.Range{})
+	if err == leveldb.ErrClosed {
+		return makeDbErr(database.ErrDbNotOpen, errDbNotOpenStr, nil)
+	}
+	if err != nil {
+		return convertErr(err.Error(), err)
+	}
+	return nil
+}
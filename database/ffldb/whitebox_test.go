@@ -15,6 +15,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"testing"
 
 	"github.com/btcsuite/btcd/btcutil/v2"
@@ -179,7 +180,7 @@ func TestCornerCases(t *testing.T) {
 	// directory is needed.
 	testName := "openDB: fail due to file at target location"
 	wantErrCode := database.ErrDriverSpecific
-	idb, err := openDB(dbPath, blockDataNet, true)
+	idb, err := openDB(dbPath, blockDataNet, Options{}, true)
 	if !checkDbError(t, testName, err, wantErrCode) {
 		if err == nil {
 			idb.Close()
@@ -191,7 +192,7 @@ func TestCornerCases(t *testing.T) {
 	// Remove the file and create the database to run tests against.  It
 	// should be successful this time.
 	_ = os.RemoveAll(dbPath)
-	idb, err = openDB(dbPath, blockDataNet, true)
+	idb, err = openDB(dbPath, blockDataNet, Options{}, true)
 	if err != nil {
 		t.Errorf("openDB: unexpected error: %v", err)
 		return
@@ -249,6 +250,124 @@ func TestCornerCases(t *testing.T) {
 	}
 }
 
+// TestReconcileRewrite ensures the rewrite of a block file which is interrupted
+// is completed when the new locations of its blocks were already stored and
+// discarded otherwise once the database is opened again.
+func TestReconcileRewrite(t *testing.T) {
+	t.Parallel()
+
+	blocks, err := loadBlocks(t, blockDataFile, blockDataNet)
+	if err != nil {
+		t.Fatalf("loadBlocks: Unexpected error: %v", err)
+	}
+
+	// Store the blocks in several block files.
+	dbPath := filepath.Join(t.TempDir(), "ffldb-reconcilerewrite")
+	idb, err := openDB(dbPath, blockDataNet, Options{}, true)
+	if err != nil {
+		t.Fatalf("openDB: unexpected error: %v", err)
+	}
+	idb.(*db).store.maxBlockFileSize = 8192
+	err = idb.Update(func(tx database.Tx) error {
+		for _, block := range blocks {
+			if err := tx.StoreBlock(block); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	idb.Close()
+	if err != nil {
+		t.Fatalf("StoreBlock: unexpected error: %v", err)
+	}
+
+	// checkBlocks opens the database and ensures none of the temporary files
+	// are left and all of the blocks can be fetched.  It returns the opened
+	// database along with the blocks of the first block file.
+	checkBlocks := func(testName string) (*db, []rewriteBlock) {
+		idb, err := openDB(dbPath, blockDataNet,
+			Options{CompressBlocks: true}, false)
+		if err != nil {
+			t.Fatalf("%s: openDB: unexpected error: %v", testName, err)
+		}
+		pdb := idb.(*db)
+		if fileExists(rewriteFilePath(dbPath, 0)) {
+			t.Fatalf("%s: temporary file was not removed", testName)
+		}
+
+		var fileBlocks []rewriteBlock
+		err = pdb.View(func(tx database.Tx) error {
+			for i, block := range blocks {
+				if _, err := tx.FetchBlock(block.Hash()); err != nil {
+					return fmt.Errorf("block #%d: %v", i, err)
+				}
+			}
+
+			cursor := tx.(*transaction).blockIdxBucket.Cursor()
+			for ok := cursor.First(); ok; ok = cursor.Next() {
+				loc := deserializeBlockLoc(cursor.Value())
+				if loc.blockFileNum != 0 {
+					continue
+				}
+				block := rewriteBlock{loc: loc}
+				copy(block.hash[:], cursor.Key())
+				fileBlocks = append(fileBlocks, block)
+			}
+			return nil
+		})
+		if err != nil {
+			pdb.Close()
+			t.Fatalf("%s: %v", testName, err)
+		}
+		sort.Slice(fileBlocks, func(i, j int) bool {
+			return fileBlocks[i].loc.fileOffset <
+				fileBlocks[j].loc.fileOffset
+		})
+		return pdb, fileBlocks
+	}
+
+	// A temporary file whose block locations were not stored is removed.
+	pdb, fileBlocks := checkBlocks("initial")
+	locs, err := pdb.store.writeRewriteFile(0, fileBlocks)
+	if err != nil {
+		t.Fatalf("writeRewriteFile: unexpected error: %v", err)
+	}
+	pdb.Close()
+	pdb, fileBlocks = checkBlocks("locations not stored")
+	for i := range fileBlocks {
+		if fileBlocks[i].loc.compressed {
+			t.Fatalf("block %v was compressed", fileBlocks[i].hash)
+		}
+	}
+
+	// A temporary file whose block locations were stored replaces the
+	// block file.
+	locs, err = pdb.store.writeRewriteFile(0, fileBlocks)
+	if err != nil {
+		t.Fatalf("writeRewriteFile: unexpected error: %v", err)
+	}
+	if err := pdb.storeRewriteLocs(0, fileBlocks, locs); err != nil {
+		t.Fatalf("storeRewriteLocs: unexpected error: %v", err)
+	}
+	pdb.Close()
+	pdb, fileBlocks = checkBlocks("locations stored")
+	defer pdb.Close()
+	for i := range fileBlocks {
+		if !fileBlocks[i].loc.compressed {
+			t.Fatalf("block %v was not compressed", fileBlocks[i].hash)
+		}
+	}
+	err = pdb.View(func(tx database.Tx) error {
+		if tx.Metadata().Get(rewriteFileKeyName) != nil {
+			return fmt.Errorf("rewritten file is still recorded")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 // resetDatabase removes everything from the opened database associated with the
 // test context including all metadata and the mock files.
 func resetDatabase(tc *testContext) bool {
@@ -542,19 +661,19 @@ func testCorruption(tc *testContext) bool {
 
 		// The same network byte, but this time don't fix the checksum
 		// to ensure the corruption is detected.
-		{2, false, database.ErrCorruption},
+		{2, false, database.ErrBlockCorrupt},
 
 		// One of the block length bytes.
-		{6, false, database.ErrCorruption},
+		{6, false, database.ErrBlockCorrupt},
 
 		// Random header byte.
-		{17, false, database.ErrCorruption},
+		{17, false, database.ErrBlockCorrupt},
 
 		// Random transaction byte.
-		{90, false, database.ErrCorruption},
+		{90, false, database.ErrBlockCorrupt},
 
 		// Random checksum byte.
-		{uint32(len(block0Bytes)) + 10, false, database.ErrCorruption},
+		{uint32(len(block0Bytes)) + 10, false, database.ErrBlockCorrupt},
 	}
 	err = tc.db.View(func(tx database.Tx) error {
 		data := tc.files[0].file.(*mockFile).data
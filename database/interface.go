@@ -308,6 +308,7 @@ type Tx interface {
 	//   - ErrBlockNotFound if the requested block hash does not exist
 	//   - ErrTxClosed if the transaction has already been closed
 	//   - ErrCorruption if the database has somehow become corrupted
+	//   - ErrBlockCorrupt if the stored copy of a requested block is damaged
 	//
 	// NOTE: The data returned by this function is only valid during a
 	// database transaction.  Attempting to access it after a transaction
@@ -326,6 +327,7 @@ type Tx interface {
 	//     exist
 	//   - ErrTxClosed if the transaction has already been closed
 	//   - ErrCorruption if the database has somehow become corrupted
+	//   - ErrBlockCorrupt if the stored copy of a requested block is damaged
 	//
 	// NOTE: The data returned by this function is only valid during a
 	// database transaction.  Attempting to access it after a transaction
@@ -353,6 +355,7 @@ type Tx interface {
 	//     associated block
 	//   - ErrTxClosed if the transaction has already been closed
 	//   - ErrCorruption if the database has somehow become corrupted
+	//   - ErrBlockCorrupt if the stored copy of a requested block is damaged
 	//
 	// NOTE: The data returned by this function is only valid during a
 	// database transaction.  Attempting to access it after a transaction
@@ -381,6 +384,7 @@ type Tx interface {
 	//     the associated block
 	//   - ErrTxClosed if the transaction has already been closed
 	//   - ErrCorruption if the database has somehow become corrupted
+	//   - ErrBlockCorrupt if the stored copy of a requested block is damaged
 	//
 	// NOTE: The data returned by this function is only valid during a
 	// database transaction.  Attempting to access it after a transaction
@@ -404,6 +408,20 @@ type Tx interface {
 	// implementations.
 	PruneBlocks(targetSize uint64) ([]chainhash.Hash, error)
 
+	// PruneBlockFiles deletes the oldest block files as long as all of the
+	// blocks they contain are prunable according to the passed function
+	// and returns the hashes of the deleted blocks.  The most recent block
+	// file is never deleted.
+	//
+	// The interface contract guarantees at least the following errors will
+	// be returned (other implementation-specific errors are possible):
+	//   - ErrTxNotWritable if attempted against a read-only transaction
+	//   - ErrTxClosed if the transaction has already been closed
+	//
+	// NOTE: The hash passed to the prunable function is only valid during
+	// the call.
+	PruneBlockFiles(prunable func(hash *chainhash.Hash) bool) ([]chainhash.Hash, error)
+
 	// BeenPruned returns if the block storage has ever been pruned.
 	//
 	// Implementation specific errors are possible.
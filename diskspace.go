@@ -0,0 +1,141 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// diskSpaceCheckInterval is the interval at which the free disk space
+	// of the data and blocks directories is checked.
+	diskSpaceCheckInterval = time.Second * 10
+
+	// diskSpaceResumeMargin is the number of bytes of free disk space in
+	// addition to the minimum which are required to resume the processing
+	// of blocks once it was suspended.  It keeps the processing from being
+	// suspended and resumed repeatedly while the free disk space hovers
+	// around the minimum.
+	diskSpaceResumeMargin = 100 * 1024 * 1024
+)
+
+// lowestDiskSpace returns the passed path which has the least free disk space
+// along with the number of bytes available on it.
+func lowestDiskSpace(paths []string) (string, uint64, error) {
+	var lowestPath string
+	var lowest uint64
+	for i, path := range paths {
+		free, err := freeDiskSpace(path)
+		if err != nil {
+			return "", 0, fmt.Errorf("%s: %w", path, err)
+		}
+		if i == 0 || free < lowest {
+			lowestPath, lowest = path, free
+		}
+	}
+	return lowestPath, lowest, nil
+}
+
+// diskSpaceMonitor tracks whether the free disk space is below the minimum
+// across consecutive checks and suspends or resumes the processing of blocks
+// when it crosses the thresholds.
+type diskSpaceMonitor struct {
+	minFree uint64
+	low     bool
+	failed  bool
+
+	// suspendBlocks is invoked to suspend or resume the download and
+	// processing of blocks.
+	suspendBlocks func(suspend bool)
+
+	// setWarning is invoked with the warning to report about the lack of
+	// free disk space, or an empty string once enough space is available.
+	setWarning func(warning string)
+}
+
+// check updates the monitor with the result of a check of the free disk space
+// of the passed path.  The processing of blocks is suspended once the free
+// space falls below the minimum, and is resumed once it reaches the minimum
+// plus diskSpaceResumeMargin again.
+func (m *diskSpaceMonitor) check(path string, free uint64, err error) {
+	switch {
+	case err != nil:
+		// Only log the first of consecutive failures.
+		if !m.failed {
+			srvrLog.Warnf("Unable to determine the free disk space: "+
+				"%v", err)
+		}
+
+	case !m.low && free < m.minFree:
+		m.low = true
+		warning := fmt.Sprintf("The free disk space of %s is %d MiB "+
+			"which is below the minimum of %d MiB -- blocks are "+
+			"not processed until more space is available", path,
+			free/(1024*1024), m.minFree/(1024*1024))
+		srvrLog.Errorf("%s", warning)
+		m.setWarning(warning)
+		m.suspendBlocks(true)
+
+	case m.low && free >= m.minFree+diskSpaceResumeMargin:
+		m.low = false
+		srvrLog.Infof("The free disk space is %d MiB again",
+			free/(1024*1024))
+		m.setWarning("")
+		m.suspendBlocks(false)
+	}
+	m.failed = err != nil
+}
+
+// diskSpaceHandler monitors the free disk space of the data directory and, when
+// the block files are stored separately, the blocks directory.  Once it falls
+// below the minimum, the download and processing of blocks is suspended so the
+// database isn't left damaged by running out of space while it is written,
+// and a warning is logged and reported by the RPC server.  The processing of
+// blocks is resumed once enough space is available again.
+//
+// It must be run as a goroutine.
+func (s *server) diskSpaceHandler() {
+	paths := []string{cfg.DataDir}
+	if cfg.BlocksDir != "" {
+		paths = append(paths, cfg.BlocksDir)
+	}
+	monitor := diskSpaceMonitor{
+		minFree:       uint64(cfg.MinDiskSpaceMiB) * 1024 * 1024,
+		suspendBlocks: s.syncManager.SuspendBlocks,
+		setWarning: func(warning string) {
+			s.diskSpaceMtx.Lock()
+			s.diskSpaceWarning = warning
+			s.diskSpaceMtx.Unlock()
+		},
+	}
+
+	ticker := time.NewTicker(diskSpaceCheckInterval)
+	defer ticker.Stop()
+
+out:
+	for {
+		monitor.check(lowestDiskSpace(paths))
+
+		select {
+		case <-ticker.C:
+		case <-s.quit:
+			break out
+		}
+	}
+
+	s.wg.Done()
+	srvrLog.Tracef("Disk space handler done")
+}
+
+// DiskSpaceWarning returns the warning about the free disk space being below
+// the minimum or an empty string when enough space is available.
+//
+// This function is safe for concurrent access.
+func (s *server) DiskSpaceWarning() string {
+	s.diskSpaceMtx.Lock()
+	defer s.diskSpaceMtx.Unlock()
+	return s.diskSpaceWarning
+}
@@ -0,0 +1,18 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+//go:build !darwin && !dragonfly && !freebsd && !linux && !openbsd && !windows
+
+package main
+
+import (
+	"errors"
+)
+
+// freeDiskSpace always returns an error since determining the free disk space
+// isn't supported on this platform.
+func freeDiskSpace(path string) (uint64, error) {
+	return 0, errors.New("determining the free disk space is not " +
+		"supported on this platform")
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestDiskSpaceMonitor ensures the processing of blocks is suspended once the
+// free disk space falls below the minimum, is only resumed once the free space
+// exceeds the minimum by the resume margin, and that the warning is set and
+// cleared along with it.
+func TestDiskSpaceMonitor(t *testing.T) {
+	const mib = 1024 * 1024
+	const minFree = 1024 * mib
+
+	var suspends []bool
+	var warning string
+	monitor := diskSpaceMonitor{
+		minFree: minFree,
+		suspendBlocks: func(suspend bool) {
+			suspends = append(suspends, suspend)
+		},
+		setWarning: func(w string) {
+			warning = w
+		},
+	}
+
+	errCheck := errors.New("check failed")
+	tests := []struct {
+		name     string
+		free     uint64
+		err      error
+		suspends []bool
+		warning  bool
+	}{{
+		name: "enough space",
+		free: minFree + diskSpaceResumeMargin,
+	}, {
+		name: "at the minimum",
+		free: minFree,
+	}, {
+		name:     "below the minimum",
+		free:     minFree - 1,
+		suspends: []bool{true},
+		warning:  true,
+	}, {
+		name:     "still below the minimum",
+		free:     minFree - 100*mib,
+		suspends: []bool{true},
+		warning:  true,
+	}, {
+		name:     "failed check",
+		err:      errCheck,
+		suspends: []bool{true},
+		warning:  true,
+	}, {
+		name:     "above the minimum within the margin",
+		free:     minFree + diskSpaceResumeMargin - 1,
+		suspends: []bool{true},
+		warning:  true,
+	}, {
+		name:     "above the minimum and the margin",
+		free:     minFree + diskSpaceResumeMargin,
+		suspends: []bool{true, false},
+	}, {
+		name:     "failed check after resuming",
+		err:      errCheck,
+		suspends: []bool{true, false},
+	}, {
+		name:     "below the minimum again",
+		free:     0,
+		suspends: []bool{true, false, true},
+		warning:  true,
+	}}
+
+	for _, test := range tests {
+		monitor.check("/data", test.free, test.err)
+		if !reflect.DeepEqual(suspends, test.suspends) {
+			t.Fatalf("%s: unexpected suspensions -- got %v, want %v",
+				test.name, suspends, test.suspends)
+		}
+		if (warning != "") != test.warning {
+			t.Fatalf("%s: unexpected warning %q", test.name, warning)
+		}
+	}
+}
+
+// TestLowestDiskSpace ensures the free disk space of the passed paths is
+// determined and that an error is returned for paths which don't exist.
+func TestLowestDiskSpace(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := freeDiskSpace(dir); err != nil {
+		t.Skipf("free disk space not supported: %v", err)
+	}
+
+	path, free, err := lowestDiskSpace([]string{dir, dir})
+	if err != nil {
+		t.Fatalf("lowestDiskSpace: unexpected error: %v", err)
+	}
+	if path != dir || free == 0 {
+		t.Fatalf("lowestDiskSpace: unexpected result %q, %d", path,
+			free)
+	}
+
+	missing := filepath.Join(dir, "missing")
+	if _, _, err := lowestDiskSpace([]string{dir, missing}); err == nil {
+		t.Fatal("lowestDiskSpace: expected error for missing path")
+	}
+}
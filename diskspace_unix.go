@@ -0,0 +1,21 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+//go:build darwin || dragonfly || freebsd || linux
+
+package main
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// freeDiskSpace returns the number of bytes available to btcd on the file
+// system the passed path is stored on.
+func freeDiskSpace(path string) (uint64, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
@@ -0,0 +1,24 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// freeDiskSpace returns the number of bytes available to btcd on the volume
+// the passed path is stored on.
+func freeDiskSpace(path string) (uint64, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	var free uint64
+	err = windows.GetDiskFreeSpaceEx(pathPtr, &free, nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	return free, nil
+}
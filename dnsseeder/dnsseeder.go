@@ -0,0 +1,335 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package dnsseeder
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/btcsuite/btcd/wire/v2"
+)
+
+const (
+	// DefaultTTL is the default time to live of the records served.
+	DefaultTTL = 60
+
+	// addrRefreshInterval is the interval at which the cached good
+	// addresses are refreshed.
+	addrRefreshInterval = time.Minute
+
+	// maxIPv4Answers and maxIPv6Answers are the maximum number of A and
+	// AAAA records in a response.  Both keep responses within the maximum
+	// UDP message length for the longest names.
+	maxIPv4Answers = 25
+	maxIPv6Answers = 15
+
+	// maxQueryLen is the maximum length of a query which is read.
+	maxQueryLen = 1500
+
+	// These constants define the timers of the SOA record.
+	soaRefresh = 604800
+	soaRetry   = 86400
+	soaExpire  = 86400
+)
+
+// Config is a descriptor containing the DNS seeder configuration.
+type Config struct {
+	// Hostname is the host name the seeder is the authority for.
+	Hostname string
+
+	// Nameserver is the host name of the name server the seeder runs on.
+	// It is served in NS records and as the primary name server of the
+	// SOA record.  No NS records are served when it is empty.
+	Nameserver string
+
+	// Mbox is the mailbox of the person responsible for the zone served in
+	// the SOA record with the @ replaced by a dot.  Defaults to hostmaster
+	// at the host name.
+	Mbox string
+
+	// DefaultPort is the default port of the network.  Addresses with any
+	// other port are not served.
+	DefaultPort uint16
+
+	// DefaultServices are the services queries without a service filter
+	// require.
+	DefaultServices wire.ServiceFlag
+
+	// TTL is the time to live of the records served.  Defaults to
+	// DefaultTTL.
+	TTL uint32
+
+	// Listeners defines a slice of packet connections on which the seeder
+	// answers queries.
+	Listeners []net.PacketConn
+
+	// GoodAddresses defines the function which returns the addresses which
+	// may be served.
+	GoodAddresses func() []*wire.NetAddressV2
+}
+
+// seedAddr is an address which may be served along with its services.
+type seedAddr struct {
+	ip       net.IP
+	services wire.ServiceFlag
+}
+
+// Server is an authoritative DNS server for the seed host name which answers
+// queries with the addresses of good peers.
+type Server struct {
+	cfg        Config
+	zone       string
+	nameserver []byte
+	soa        []byte
+	shutdown   int32
+	wg         sync.WaitGroup
+
+	// The following fields are protected by mtx.
+	mtx         sync.Mutex
+	rand        *rand.Rand
+	addrs       []seedAddr
+	lastRefresh time.Time
+}
+
+// isShuttingDown returns whether or not the server is shutting down.
+func (s *Server) isShuttingDown() bool {
+	return atomic.LoadInt32(&s.shutdown) != 0
+}
+
+// addresses returns up to max randomly selected IPv4 or IPv6 addresses which
+// support the passed services.  The cached addresses are refreshed when they
+// are older than the refresh interval.
+func (s *Server) addresses(ipv6 bool, services wire.ServiceFlag,
+	max int) []net.IP {
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastRefresh) >= addrRefreshInterval {
+		s.refreshAddresses()
+		s.lastRefresh = now
+	}
+
+	var ips []net.IP
+	for _, i := range s.rand.Perm(len(s.addrs)) {
+		addr := s.addrs[i]
+		if (addr.ip.To4() == nil) != ipv6 ||
+			addr.services&services != services {
+
+			continue
+		}
+		ips = append(ips, addr.ip)
+		if len(ips) == max {
+			break
+		}
+	}
+	return ips
+}
+
+// refreshAddresses replaces the cached addresses with the routable good
+// addresses which use the default port.  It must be called with mtx held.
+func (s *Server) refreshAddresses() {
+	good := s.cfg.GoodAddresses()
+	addrs := make([]seedAddr, 0, len(good))
+	for _, na := range good {
+		if na.Port != s.cfg.DefaultPort {
+			continue
+		}
+		ip := net.ParseIP(na.Addr.String())
+		if ip == nil {
+			continue
+		}
+		addrs = append(addrs, seedAddr{ip: ip, services: na.Services})
+	}
+	s.addrs = addrs
+	log.Debugf("Serving %d of %d good addresses", len(addrs), len(good))
+}
+
+// subdomainServices returns the services required by the passed subdomain of
+// the zone and whether it is served.  The zone itself is represented by an
+// empty subdomain.
+func (s *Server) subdomainServices(subdomain string) (wire.ServiceFlag, bool) {
+	if subdomain == "" {
+		return s.cfg.DefaultServices, true
+	}
+	if len(subdomain) < 2 || subdomain[0] != 'x' {
+		return 0, false
+	}
+	services, err := strconv.ParseUint(subdomain[1:], 16, 64)
+	if err != nil {
+		return 0, false
+	}
+	return wire.ServiceFlag(services), true
+}
+
+// handleQuery returns the response to the passed query or nil when it must
+// not be answered.
+func (s *Server) handleQuery(query []byte) []byte {
+	// Ignore anything which isn't a query so the seeder can't be used to
+	// create loops.
+	if len(query) < headerLen {
+		return nil
+	}
+	id := binary.BigEndian.Uint16(query[0:2])
+	flags := binary.BigEndian.Uint16(query[2:4])
+	if flags&flagQR != 0 {
+		return nil
+	}
+
+	if flags&opcodeMask != 0 {
+		return newResponse(id, flags, false, rcodeNotImplemented,
+			nil).bytes()
+	}
+	q, err := parseQuestion(query)
+	if err != nil {
+		log.Tracef("Malformed query: %v", err)
+		return newResponse(id, flags, false, rcodeFormatError,
+			nil).bytes()
+	}
+	if q.qclass != classIN && q.qclass != classANY {
+		return newResponse(id, flags, false, rcodeNotImplemented,
+			q).bytes()
+	}
+
+	// Refuse queries for names outside of the zone.
+	var subdomain string
+	switch {
+	case q.name == s.zone:
+	case strings.HasSuffix(q.name, "."+s.zone):
+		subdomain = strings.TrimSuffix(q.name, "."+s.zone)
+	default:
+		return newResponse(id, flags, false, rcodeRefused, q).bytes()
+	}
+
+	services, ok := s.subdomainServices(subdomain)
+	if !ok {
+		resp := newResponse(id, flags, true, rcodeNameError, q)
+		resp.addRecord(typeSOA, s.cfg.TTL, s.soa, true)
+		return resp.bytes()
+	}
+
+	// ANY queries are answered with the A records only as allowed by RFC
+	// 8482.
+	resp := newResponse(id, flags, true, rcodeSuccess, q)
+	switch {
+	case q.qtype == typeA || q.qtype == typeANY:
+		for _, ip := range s.addresses(false, services, maxIPv4Answers) {
+			resp.addRecord(typeA, s.cfg.TTL, ip.To4(), false)
+		}
+
+	case q.qtype == typeAAAA:
+		for _, ip := range s.addresses(true, services, maxIPv6Answers) {
+			resp.addRecord(typeAAAA, s.cfg.TTL, ip.To16(), false)
+		}
+
+	case q.qtype == typeNS && subdomain == "" && s.nameserver != nil:
+		resp.addRecord(typeNS, s.cfg.TTL, s.nameserver, false)
+
+	case q.qtype == typeSOA && subdomain == "":
+		resp.addRecord(typeSOA, s.cfg.TTL, s.soa, false)
+	}
+
+	// Include the SOA record in responses without any answers so they can
+	// be cached as described by RFC 2308.
+	if resp.answers == 0 {
+		resp.addRecord(typeSOA, s.cfg.TTL, s.soa, true)
+	}
+	return resp.bytes()
+}
+
+// serve answers the queries received on the passed packet connection.  It
+// must be run as a goroutine.
+func (s *Server) serve(conn net.PacketConn) {
+	log.Infof("DNS seeder listening on %s", conn.LocalAddr())
+	buf := make([]byte, maxQueryLen)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			// Only log the error if not forcibly shutting down.
+			if !s.isShuttingDown() {
+				log.Errorf("Can't read DNS query: %v", err)
+			}
+			break
+		}
+
+		resp := s.handleQuery(buf[:n])
+		if resp == nil {
+			continue
+		}
+		if _, err := conn.WriteTo(resp, addr); err != nil {
+			log.Debugf("Can't send DNS response to %s: %v", addr,
+				err)
+		}
+	}
+	log.Tracef("DNS seeder listener done for %s", conn.LocalAddr())
+
+	s.wg.Done()
+}
+
+// Start begins answering queries.
+func (s *Server) Start() {
+	for _, conn := range s.cfg.Listeners {
+		s.wg.Add(1)
+		go s.serve(conn)
+	}
+}
+
+// Stop stops answering queries and waits for the listeners to finish.
+func (s *Server) Stop() {
+	if !atomic.CompareAndSwapInt32(&s.shutdown, 0, 1) {
+		return
+	}
+
+	for _, conn := range s.cfg.Listeners {
+		conn.Close()
+	}
+	s.wg.Wait()
+	log.Infof("DNS seeder stopped")
+}
+
+// New returns a new DNS seeder for the passed configuration.  The host names
+// of the configuration must be valid.
+func New(cfg *Config) *Server {
+	s := &Server{
+		cfg:  *cfg,
+		zone: strings.ToLower(strings.TrimSuffix(cfg.Hostname, ".")),
+		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	if s.cfg.TTL == 0 {
+		s.cfg.TTL = DefaultTTL
+	}
+
+	mname := s.zone
+	if cfg.Nameserver != "" {
+		s.nameserver = encodeName(cfg.Nameserver)
+		mname = cfg.Nameserver
+	}
+	mbox := cfg.Mbox
+	if mbox == "" {
+		mbox = "hostmaster." + s.zone
+	}
+
+	// The serial only has to increase when the zone changes, which never
+	// happens since the records aren't transferred.
+	s.soa = append(encodeName(mname), encodeName(mbox)...)
+	for _, v := range []uint32{1, soaRefresh, soaRetry, soaExpire,
+		s.cfg.TTL} {
+
+		s.soa = binary.BigEndian.AppendUint32(s.soa, v)
+	}
+	return s
+}
+
+// ValidHostname returns whether the passed host name can be served.
+func ValidHostname(name string) bool {
+	return validName(strings.TrimSuffix(name, "."))
+}
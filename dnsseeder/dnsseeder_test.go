@@ -0,0 +1,241 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package dnsseeder
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/wire/v2"
+)
+
+// buildQuery returns a query with the passed ID for the passed name and type.
+func buildQuery(id uint16, name string, qtype uint16) []byte {
+	query := make([]byte, headerLen)
+	binary.BigEndian.PutUint16(query[0:2], id)
+	binary.BigEndian.PutUint16(query[2:4], flagRD)
+	binary.BigEndian.PutUint16(query[4:6], 1)
+	query = append(query, encodeName(name)...)
+	query = binary.BigEndian.AppendUint16(query, qtype)
+	return binary.BigEndian.AppendUint16(query, classIN)
+}
+
+// testResponse houses the parts of a response checked by the tests.
+type testResponse struct {
+	id      uint16
+	flags   uint16
+	answers [][]byte
+	authns  int
+}
+
+// parseResponse parses the passed response to a query built by buildQuery
+// with the passed name.
+func parseResponse(t *testing.T, resp []byte, name string) *testResponse {
+	t.Helper()
+	if len(resp) < headerLen || len(resp) > maxUDPMessageLen {
+		t.Fatalf("invalid response length %d", len(resp))
+	}
+	r := &testResponse{
+		id:     binary.BigEndian.Uint16(resp[0:2]),
+		flags:  binary.BigEndian.Uint16(resp[2:4]),
+		authns: int(binary.BigEndian.Uint16(resp[8:10])),
+	}
+	offset := headerLen
+	if binary.BigEndian.Uint16(resp[4:6]) == 1 {
+		offset += len(encodeName(name)) + 4
+	}
+	numAnswers := int(binary.BigEndian.Uint16(resp[6:8]))
+	for i := 0; i < numAnswers+r.authns; i++ {
+		if offset+12 > len(resp) ||
+			binary.BigEndian.Uint16(resp[offset:]) != questionNamePtr {
+
+			t.Fatalf("malformed record at offset %d", offset)
+		}
+		dataLen := int(binary.BigEndian.Uint16(resp[offset+10:]))
+		data := resp[offset+12 : offset+12+dataLen]
+		if i < numAnswers {
+			r.answers = append(r.answers, data)
+		}
+		offset += 12 + dataLen
+	}
+	if offset != len(resp) {
+		t.Fatalf("response has %d trailing bytes", len(resp)-offset)
+	}
+	return r
+}
+
+// testServer returns a seeder for seed.example.com which serves 30 IPv4 and
+// 30 IPv6 addresses, every other one of which supports SFNodeWitness, along
+// with an address with another port.
+func testServer() *Server {
+	var good []*wire.NetAddressV2
+	for i := 0; i < 30; i++ {
+		services := wire.SFNodeNetwork
+		if i%2 == 0 {
+			services |= wire.SFNodeWitness
+		}
+		good = append(good,
+			wire.NetAddressV2FromBytes(time.Now(), services,
+				net.ParseIP(fmt.Sprintf("1.2.3.%d", i)), 9246),
+			wire.NetAddressV2FromBytes(time.Now(), services,
+				net.ParseIP(fmt.Sprintf("2001:db8::%d", i)), 9246))
+	}
+	good = append(good, wire.NetAddressV2FromBytes(time.Now(),
+		wire.SFNodeNetwork, net.ParseIP("5.6.7.8"), 1234))
+
+	return New(&Config{
+		Hostname:        "Seed.Example.com.",
+		Nameserver:      "ns.example.com",
+		DefaultPort:     9246,
+		DefaultServices: wire.SFNodeNetwork,
+		GoodAddresses: func() []*wire.NetAddressV2 {
+			return good
+		},
+	})
+}
+
+// TestHandleQuery ensures queries are answered with the expected response
+// codes and records.
+func TestHandleQuery(t *testing.T) {
+	s := testServer()
+
+	tests := []struct {
+		name        string
+		qname       string
+		qtype       uint16
+		rcode       uint16
+		numAnswers  int
+		answerLen   int
+		witnessOnly bool
+	}{
+		{"A", "seed.example.com", typeA, rcodeSuccess, maxIPv4Answers,
+			4, false},
+		{"AAAA", "SEED.example.com", typeAAAA, rcodeSuccess,
+			maxIPv6Answers, 16, false},
+		{"ANY", "seed.example.com", typeANY, rcodeSuccess,
+			maxIPv4Answers, 4, false},
+		{"filtered A", "x9.seed.example.com", typeA, rcodeSuccess, 15,
+			4, true},
+		{"NS", "seed.example.com", typeNS, rcodeSuccess, 1, 0, false},
+		{"SOA", "seed.example.com", typeSOA, rcodeSuccess, 1, 0, false},
+		{"no data", "seed.example.com", 16, rcodeSuccess, 0, 0, false},
+		{"NS of subdomain", "x9.seed.example.com", typeNS, rcodeSuccess,
+			0, 0, false},
+		{"unknown subdomain", "www.seed.example.com", typeA,
+			rcodeNameError, 0, 0, false},
+		{"invalid filter", "xz.seed.example.com", typeA, rcodeNameError,
+			0, 0, false},
+		{"other zone", "example.com", typeA, rcodeRefused, 0, 0, false},
+		{"suffix of other zone", "badseed.example.com", typeA,
+			rcodeRefused, 0, 0, false},
+	}
+	for i, test := range tests {
+		id := uint16(i + 1)
+		resp := s.handleQuery(buildQuery(id, test.qname, test.qtype))
+		r := parseResponse(t, resp, test.qname)
+
+		if r.id != id || r.flags&flagQR == 0 || r.flags&flagRD == 0 {
+			t.Errorf("%s: unexpected header id %d flags %x", test.name,
+				r.id, r.flags)
+		}
+		if rcode := r.flags & 0xf; rcode != test.rcode {
+			t.Errorf("%s: got rcode %d, want %d", test.name, rcode,
+				test.rcode)
+			continue
+		}
+		if len(r.answers) != test.numAnswers {
+			t.Errorf("%s: got %d answers, want %d", test.name,
+				len(r.answers), test.numAnswers)
+			continue
+		}
+		if test.rcode != rcodeRefused && r.flags&flagAA == 0 {
+			t.Errorf("%s: response is not authoritative", test.name)
+		}
+		if len(r.answers) == 0 && test.rcode != rcodeRefused &&
+			r.authns != 1 {
+
+			t.Errorf("%s: got %d authority records, want SOA",
+				test.name, r.authns)
+		}
+
+		// Ensure the addresses are distinct, use the default port and
+		// support the requested services.
+		if test.answerLen == 0 {
+			continue
+		}
+		seen := make(map[string]struct{})
+		for _, answer := range r.answers {
+			ip := net.IP(answer)
+			if len(answer) != test.answerLen || ip.Equal(
+				net.ParseIP("5.6.7.8")) {
+
+				t.Errorf("%s: unexpected answer %v", test.name, ip)
+			}
+			if _, ok := seen[ip.String()]; ok {
+				t.Errorf("%s: duplicate answer %v", test.name, ip)
+			}
+			seen[ip.String()] = struct{}{}
+			if test.witnessOnly && answer[len(answer)-1]%2 != 0 {
+				t.Errorf("%s: answer %v does not support the "+
+					"requested services", test.name, ip)
+			}
+		}
+	}
+
+	// Responses are ignored and malformed queries are rejected.
+	query := buildQuery(1, "seed.example.com", typeA)
+	binary.BigEndian.PutUint16(query[2:4], flagQR)
+	if resp := s.handleQuery(query); resp != nil {
+		t.Errorf("response was answered")
+	}
+	if resp := s.handleQuery(query[:headerLen-1]); resp != nil {
+		t.Errorf("truncated header was answered")
+	}
+	query = buildQuery(1, "seed.example.com", typeA)
+	resp := s.handleQuery(query[:len(query)-2])
+	rcode := parseResponse(t, resp, "").flags & 0xf
+	if rcode != rcodeFormatError {
+		t.Errorf("truncated question: got rcode %d, want %d", rcode,
+			rcodeFormatError)
+	}
+}
+
+// TestServer ensures the seeder answers queries received on its listeners.
+func TestServer(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	s := testServer()
+	s.cfg.Listeners = []net.PacketConn{conn}
+	s.Start()
+	defer s.Stop()
+
+	client, err := net.Dial("udp", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("unable to dial: %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.Write(buildQuery(7, "seed.example.com",
+		typeA)); err != nil {
+
+		t.Fatalf("unable to send query: %v", err)
+	}
+	client.SetReadDeadline(time.Now().Add(time.Second * 5))
+	buf := make([]byte, maxQueryLen)
+	n, err := client.Read(buf)
+	if err != nil {
+		t.Fatalf("unable to read response: %v", err)
+	}
+	r := parseResponse(t, buf[:n], "seed.example.com")
+	if r.id != 7 || len(r.answers) != maxIPv4Answers {
+		t.Fatalf("unexpected response id %d with %d answers", r.id,
+			len(r.answers))
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package dnsseeder implements a DNS server which answers queries for a seed host
+name with the addresses of good peers.
+
+# DNS Seeder Overview
+
+New nodes find their first peers by resolving the host names of the DNS seeds
+of their network.  The seeder answers A and AAAA queries for its host name with
+a random selection of addresses, which are typically the addresses the address
+manager of a running node connected to successfully.  Since DNS responses can't
+carry ports, only addresses which use the default port of the network are
+served.
+
+Queries for a subdomain of the form x<services>, where <services> is the
+hexadecimal service flags the addresses must support, are answered with
+addresses which support those services, as described by the HasFiltering field
+of chaincfg.DNSSeed.
+
+The seeder must be made the authority for its host name by delegating it to
+the host the seeder runs on with an NS record in the parent zone.
+*/
+package dnsseeder
@@ -0,0 +1,218 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package dnsseeder
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+)
+
+// These constants define the parts of DNS messages which are used by the
+// seeder as described by RFC 1035.
+const (
+	// headerLen is the length of the header of a DNS message.
+	headerLen = 12
+
+	// maxUDPMessageLen is the maximum length of a DNS message sent over
+	// UDP without EDNS.
+	maxUDPMessageLen = 512
+
+	// maxLabelLen is the maximum length of a label of a domain name.
+	maxLabelLen = 63
+
+	// maxNameLen is the maximum length of an encoded domain name.
+	maxNameLen = 255
+
+	// questionNamePtr is a compression pointer to the name of the first
+	// question, which always directly follows the header.
+	questionNamePtr = 0xc000 | headerLen
+)
+
+// Header flags.
+const (
+	flagQR     = 1 << 15
+	flagAA     = 1 << 10
+	flagRD     = 1 << 8
+	opcodeMask = 0xf << 11
+)
+
+// Response codes.
+const (
+	rcodeSuccess        = 0
+	rcodeFormatError    = 1
+	rcodeNameError      = 3
+	rcodeNotImplemented = 4
+	rcodeRefused        = 5
+)
+
+// Record types and classes.
+const (
+	typeA    = 1
+	typeNS   = 2
+	typeSOA  = 6
+	typeAAAA = 28
+	typeANY  = 255
+
+	classIN  = 1
+	classANY = 255
+)
+
+// errMalformedName indicates a domain name in a DNS message could not be
+// parsed.
+var errMalformedName = errors.New("malformed domain name")
+
+// question is the question of a DNS query.
+type question struct {
+	// name is the queried domain name in lower case without the trailing
+	// dot.
+	name   string
+	qtype  uint16
+	qclass uint16
+
+	// raw is the question as it appeared in the query so it can be copied
+	// to the response.
+	raw []byte
+}
+
+// parseName parses the uncompressed domain name starting at the passed offset
+// of the message and returns it in lower case without the trailing dot along
+// with the offset directly following it.  Queries never need to compress the
+// single name they contain, so compressed names are rejected.
+func parseName(msg []byte, offset int) (string, int, error) {
+	var labels []string
+	start := offset
+	for {
+		if offset >= len(msg) {
+			return "", 0, errMalformedName
+		}
+		labelLen := int(msg[offset])
+		offset++
+		if labelLen == 0 {
+			break
+		}
+		if labelLen > maxLabelLen || offset+labelLen > len(msg) {
+			return "", 0, errMalformedName
+		}
+		labels = append(labels, string(msg[offset:offset+labelLen]))
+		offset += labelLen
+		if offset-start > maxNameLen {
+			return "", 0, errMalformedName
+		}
+	}
+	return strings.ToLower(strings.Join(labels, ".")), offset, nil
+}
+
+// parseQuestion parses the question of the passed query, which must contain
+// exactly one.
+func parseQuestion(msg []byte) (*question, error) {
+	if binary.BigEndian.Uint16(msg[4:6]) != 1 {
+		return nil, errors.New("query does not contain exactly one " +
+			"question")
+	}
+	name, offset, err := parseName(msg, headerLen)
+	if err != nil {
+		return nil, err
+	}
+	if offset+4 > len(msg) {
+		return nil, errors.New("truncated question")
+	}
+	return &question{
+		name:   name,
+		qtype:  binary.BigEndian.Uint16(msg[offset : offset+2]),
+		qclass: binary.BigEndian.Uint16(msg[offset+2 : offset+4]),
+		raw:    msg[headerLen : offset+4],
+	}, nil
+}
+
+// encodeName encodes the passed domain name, which must be valid, without
+// compression.
+func encodeName(name string) []byte {
+	encoded := make([]byte, 0, len(name)+2)
+	for _, label := range strings.Split(name, ".") {
+		if label == "" {
+			continue
+		}
+		encoded = append(encoded, byte(len(label)))
+		encoded = append(encoded, label...)
+	}
+	return append(encoded, 0)
+}
+
+// validName returns whether the passed domain name can be encoded.
+func validName(name string) bool {
+	if name == "" || len(name) > maxNameLen-2 {
+		return false
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || len(label) > maxLabelLen {
+			return false
+		}
+	}
+	return true
+}
+
+// response builds a DNS response.
+type response struct {
+	msg     []byte
+	answers uint16
+	authns  uint16
+}
+
+// newResponse returns a response to the query with the passed ID and flags
+// with the passed response code.  The question is copied to the response when
+// it is not nil.
+func newResponse(id, flags uint16, authoritative bool, rcode uint16,
+	q *question) *response {
+
+	respFlags := flagQR | flags&(opcodeMask|flagRD) | rcode
+	if authoritative {
+		respFlags |= flagAA
+	}
+
+	msg := make([]byte, headerLen, maxUDPMessageLen)
+	binary.BigEndian.PutUint16(msg[0:2], id)
+	binary.BigEndian.PutUint16(msg[2:4], respFlags)
+	if q != nil {
+		binary.BigEndian.PutUint16(msg[4:6], 1)
+		msg = append(msg, q.raw...)
+	}
+	return &response{msg: msg}
+}
+
+// addRecord appends a record for the queried name with the passed type, TTL
+// and data to the answer section, or to the authority section once authority
+// is set, and returns whether it fit within the maximum UDP message length.
+// Answers must not be added after authority records.
+func (r *response) addRecord(rtype uint16, ttl uint32, data []byte,
+	authority bool) bool {
+
+	if len(r.msg)+12+len(data) > maxUDPMessageLen {
+		return false
+	}
+
+	var rr [10]byte
+	binary.BigEndian.PutUint16(rr[0:2], questionNamePtr)
+	binary.BigEndian.PutUint16(rr[2:4], rtype)
+	binary.BigEndian.PutUint16(rr[4:6], classIN)
+	binary.BigEndian.PutUint32(rr[6:10], ttl)
+	r.msg = append(r.msg, rr[:]...)
+	r.msg = binary.BigEndian.AppendUint16(r.msg, uint16(len(data)))
+	r.msg = append(r.msg, data...)
+
+	if authority {
+		r.authns++
+	} else {
+		r.answers++
+	}
+	return true
+}
+
+// bytes returns the encoded response.
+func (r *response) bytes() []byte {
+	binary.BigEndian.PutUint16(r.msg[6:8], r.answers)
+	binary.BigEndian.PutUint16(r.msg[8:10], r.authns)
+	return r.msg
+}
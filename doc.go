@@ -17,6 +17,11 @@ parsed when btcd starts up.  By default, the configuration file is located at
 on Windows.  The -C (--configfile) flag, as shown below, can be used to override
 this location.
 
+Each option can also be set by an environment variable named after its long
+form in upper case with an LBCD_ prefix, such as LBCD_RPCPASS.  Environment
+variables take precedence over the configuration file, while the command line
+takes precedence over both.
+
 Usage:
 
 	btcd [OPTIONS]
@@ -29,11 +34,36 @@ Application Options:
 	    --addrindex             Maintain a full address-based transaction index
 	                            which makes the searchrawtransactions RPC
 	                            available
+	    --alertnotify=          Execute this command when a fork of the main
+	                            chain which indicates the network may have
+	                            split is found (%s in the command is replaced
+	                            by the warning)
+	    --assumevalid=          Skip the script verification of the ancestors of
+	                            this block while they are buried deeply enough
+	                            in the best header chain, which speeds up the
+	                            initial block download -- 0 to verify all
+	                            scripts (default: a recent block of the active
+	                            network)
+	    --asmap=                Path to an asmap file in the format used by
+	                            Bitcoin Core to group peer addresses by
+	                            autonomous system instead of network prefix
 	    --banduration=          How long to ban misbehaving peers.  Valid time
 	                            units are {s, m, h}.  Minimum 1 second (default:
 	                            24h0m0s)
 	    --banthreshold=         Maximum allowed ban score before disconnecting
 	                            and banning misbehaving peers. (default: 100)
+	    --batchsigverify        Verify the Schnorr signatures of the taproot
+	                            inputs of blocks in batches, which is faster
+	                            than verifying them one at a time
+	    --bench                 Log the time spent in each stage of processing
+	                            every block connected to the main chain
+	    --blockrelayonlypeers=  Number of outbound connections which only relay
+	                            blocks to maintain in addition to the regular
+	                            outbound connections (default: 2)
+	    --blockservingrate=     Maximum rate in KiB/s at which historical blocks
+	                            are served to peers without the noban permission
+	                            -- blocks relayed as they are found are not
+	                            delayed (0 = no limit)
 	    --blockmaxsize=         Maximum block size in bytes to be used when
 	                            creating a block (default: 750000)
 	    --blockminsize=         Minimum block size in bytes to be used when
@@ -42,14 +72,59 @@ Application Options:
 	                            block (default: 3000000)
 	    --blockminweight=       Minimum block weight to be used when creating a
 	                            block
+	    --blockmaxsigopcost=    Maximum signature operation cost of the
+	                            transactions to be used when creating a block
+	                            -- 0 for the consensus limit (default: 80000)
+	    --blockmintxfee=        The minimum transaction fee in BTC/kB a
+	                            transaction must pay to be included when
+	                            creating a block
 	    --blockprioritysize=    Size in bytes for high-priority/low-fee
 	                            transactions when creating a block (default:
 	                            50000)
+	    --blocksdir=            Directory to store the block files in
+	                            separately from the rest of the data, such as
+	                            on a larger and slower disk
 	    --blocksonly            Do not accept transactions from remote peers.
+	    --checkblocks=          Number of blocks at the tip of the main chain to
+	                            verify in the background at startup -- 0 to
+	                            disable (default: 6)
+	    --acceptclaimscripts    Accept transactions with claim, support or update
+	                            outputs as standard -- the script engine
+	                            doesn't strip the claim prefix, so spends of
+	                            these outputs can't be verified
+	    --checkclaimmetadata    Reject transactions with claim, support or update
+	                            outputs whose values aren't well-formed LBRY
+	                            metadata as non-standard
+	    --checklevel=           How thorough the verification of the blocks at
+	                            startup is {0: load the blocks, 1: also check
+	                            their sanity} (default: 1)
+	    --checkpointfile=       Add the checkpoints of a checkpoint file signed
+	                            by the checkpointsigner address -- checkpoints
+	                            given with addcheckpoint take precedence
+	    --checkpointsigner=     Pay-to-pubkey-hash address whose key has to sign
+	                            the checkpoint file
+	    --claimfilters          Maintain and serve committed filters of the names
+	                            of the claims created and spent by each block as
+	                            filter type 1 in addition to the basic filters
+	    --claimindex            Maintain an index of the claim, support and update
+	                            outputs by claim ID and by name which makes the
+	                            getclaimhistory and getnamehistory RPCs
+	                            available
+	    --claimpriorityweight=  Weight of a block reserved for transactions which
+	                            update or support claims when creating a block
+	                            -- they are selected ahead of the other
+	                            transactions, the ones spending the oldest
+	                            claims first -- 0 to disable
+	    --compressblocks        Compress the blocks written to the block
+	                            database -- use the rewriteblocks command of
+	                            dbtool to compress the blocks which are already
+	                            stored
 	-C, --configfile=           Path to configuration file
 	    --connect=              Connect only to the specified peers at startup
 	    --cpuprofile=           Write CPU profile to the specified file
 	-b, --datadir=              Directory to store data
+	    --dbcache=              Alias for utxocachemaxsize which takes
+	                            precedence over it when set
 	    --dbtype=               Database backend to use for the Block Chain
 	                            (default: ffldb)
 	-d, --debuglevel=           Logging level for all subsystems {trace, debug,
@@ -64,37 +139,110 @@ Application Options:
 	    --dropcfindex           Deletes the index used for committed filtering
 	                            (CF) support from the database on start up and
 	                            then exits.
+	    --dropclaimfilters      Deletes the index of the committed filters of the
+	                            claim names from the database on start up and
+	                            then exits.
+	    --dropclaimindex        Deletes the claim index from the database on start
+	                            up and then exits.
+	    --dropspentindex        Deletes the spent output index from the database
+	                            on start up and then exits.
 	    --droptxindex           Deletes the hash-based transaction index from the
 	                            database on start up and then exits.
 	    --externalip=           Add an ip to the list of local addresses we claim
 	                            to listen on to peers
 	    --generate              Generate (mine) bitcoins using the CPU
+	    --ipv4proxy=            Connect to IPv4 peers via this SOCKS5 proxy
+	                            instead of the one specified by --proxy
+	    --ipv6proxy=            Connect to IPv6 peers via this SOCKS5 proxy
+	                            instead of the one specified by --proxy
 	    --limitfreerelay=       Limit relay of transactions with no transaction
 	                            fee to the given amount in thousands of bytes per
 	                            minute (default: 15)
 	    --listen=               Add an interface/port to listen for connections
 	                            (default all interfaces port: 8333, testnet:
 	                            18333, signet: 38333)
+	    --loadblock=            Import the blocks of the specified block file at
+	                            startup, such as a bootstrap.dat file or a
+	                            blk*.dat file of another node -- may be
+	                            specified multiple times
 	    --logdir=               Directory to log output
+	    --logmaxage=            Delete rotated log files older than this
+	                            duration.  Valid time units are {s, m, h}.  0 to
+	                            keep them regardless of their age
+	    --logmaxfiles=          Maximum number of rotated log files to keep -- 0
+	                            to keep all of them (default: 3)
+	    --logmaxsize=           Maximum size in MiB of the log file before it is
+	                            rotated (default: 10)
+	    --maxclaimvaluesize=    Maximum number of bytes of the value of a claim,
+	                            support or update output that is considered
+	                            standard (default: 4096)
+	    --maxclockskew=         Shut down when the local clock is off by more
+	                            than the specified duration from the time of the
+	                            network as estimated from the outbound peers --
+	                            Valid time units are {s, m, h}.  0 disables the
+	                            check (default: 1h10m0s)
+	    --maxmemory=            Soft limit in MiB of the memory used by btcd,
+	                            which sets the memory limit of the Go runtime
+	                            unless GOMEMLIMIT is set and sizes the UTXO cache
+	                            and the signature cache which are not set
+	                            explicitly to fit -- 0 for no limit
 	    --maxorphantx=          Max number of orphan transactions to keep in
 	                            memory (default: 100)
 	    --maxpeers=             Max number of inbound and outbound peers
 	                            (default: 125)
+	    --maxuploadtarget=      Try to keep outbound traffic under the given
+	                            target in MiB per 24h -- historical blocks are
+	                            no longer served to peers without the noban
+	                            permission once it is reached (0 = no limit)
+	    --mdns                  Discover and connect to peers on the local
+	                            network with multicast DNS and advertise the
+	                            listening port to them
 	    --miningaddr=           Add the specified payment address to the list of
 	                            addresses to use for generated blocks -- At least
 	                            one address is required if the generate option is
 	                            set
+	    --mindiskspace=         Minimum free disk space in MiB of the data and
+	                            blocks directories below which the download and
+	                            processing of blocks is suspended -- 0 disables
+	                            the check (default: 1024)
 	    --minrelaytxfee=        The minimum transaction fee in BTC/kB to be
 	                            considered a non-zero fee. (default: 1e-05)
+	    --minimumchainwork=     Minimum cumulative work in hex a chain of
+	                            headers must have for its headers to be stored
+	                            -- chains of headers with less work are first
+	                            synced without being stored
+	    --misbehavior=          Set how to react to a category of peer
+	                            misbehavior in the form category:reaction -- may
+	                            be specified multiple times.  Categories are
+	                            flood, notfound, protocol and malformed.
+	                            Reactions are ban (default), disconnect and
+	                            ignore
+	    --natpmp                Use NAT-PMP or PCP to map our listening port
+	                            outside of NAT
 	    --nobanning             Disable banning of misbehaving peers
 	    --nocfilters            Disable committed filtering (CF) support
 	    --nocheckpoints         Disable built-in checkpoints.  Don't do this
 	                            unless you know what you're doing.
 	    --nodnsseed             Disable DNS seeding for peers
+	    --dnsseed=              Add a DNS seed to query for peers in addition to
+	                            the built-in seeds of the network -- may be
+	                            specified multiple times
+	    --dnsseeder             Crawl the network and serve the addresses of good
+	                            peers as an authoritative DNS seed for
+	                            dnsseederhost
+	    --dnsseederhost=        Host name the DNS seeder serves -- It must be
+	                            delegated to this node with an NS record
+	    --dnsseederlisten=      Add an interface/port to listen for DNS queries
+	                            when running as a DNS seeder (default port: 53)
+	    --dnsseederns=          Host name of this node served in the NS records
+	                            of the DNS seeder
+	    --nofilelogging         Disable logging to files and only log to standard
+	                            output, such as when running in a container
 	    --nolisten              Disable listening for incoming connections --
 	                            NOTE: Listening is automatically disabled if the
 	                            --connect or --proxy options are used without
 	                            also specifying listen interfaces via --listen
+	    --nologcompress         Do not compress rotated log files with gzip
 	    --noonion               Disable connecting to tor hidden services
 	    --nopeerbloomfilters    Disable bloom filtering support
 	    --norelaypriority       Do not require free or low-fee transactions to
@@ -109,13 +257,35 @@ Application Options:
 	    --onion=                Connect to tor hidden services via SOCKS5 proxy
 	                            (eg. 127.0.0.1:9050)
 	    --onionpass=            Password for onion proxy server
+	    --onionpeers=           Number of outbound connections to tor hidden
+	                            services to maintain in addition to the regular
+	                            outbound connections -- Requires the --proxy or
+	                            --onion option (default: 0)
 	    --onionuser=            Username for onion proxy server
+	    --onlynet=              Only make automatic outbound connections to
+	                            peers on the specified network {ipv4, ipv6,
+	                            onion} -- may be specified multiple times
+	    --outboundpeers=        Number of regular outbound connections to
+	                            maintain (default: 8)
 	    --profile=              Enable HTTP profiling on given port -- NOTE port
 	                            must be between 1024 and 65536
 	    --proxy=                Connect via SOCKS5 proxy (eg. 127.0.0.1:9050)
 	    --proxypass=            Password for proxy server
 	    --proxyuser=            Username for proxy server
+	    --prunespendjournal=    Keep the spend journals, which are only needed
+	                            to disconnect blocks, for this number of blocks
+	                            at the tip of the main chain and delete the
+	                            older ones while keeping the blocks --
+	                            reorganizations deeper than this are refused
+	                            (minimum value of 288, default value of 0 will
+	                            keep all spend journals)
 	    --regtest               Use the regression test network
+	    --reindex               Rebuild the block database, including the block
+	                            index, chain state, and optional indexes, from
+	                            its block files at startup -- only supported by
+	                            the ffldb database type
+	    --reindexchainstate     Rebuild the chain state from the blocks in the
+	                            database at startup while keeping the block index
 	    --rejectnonstd          Reject non-standard transactions regardless of
 	                            the default settings for the active network.
 	    --relaynonstd           Relay non-standard transactions regardless of the
@@ -137,23 +307,75 @@ Application Options:
 	                            need to be worked around
 	-P, --rpcpass=              Password for RPC connections
 	-u, --rpcuser=              Username for RPC connections
+	    --scriptworkers=        Max number of goroutines used to validate the
+	                            scripts of a block concurrently -- 0 for three
+	                            times the number of processor cores
+	    --seedlisturl=          HTTPS URL of a signed seed list to fetch peers
+	                            from when none of the DNS seeds return any
+	    --seedlistpubkey=       Hex-encoded 32-byte x-only public key the seed
+	                            list must be signed with
+	    --shutdowntimeout=      Maximum time to wait for a graceful shutdown to
+	                            complete before forcing an exit with exit code
+	                            3.  Valid time units are {s, m, h}.  0 to wait
+	                            as long as needed
 	    --sigcachemaxsize=      The maximum number of entries in the signature
 	                            verification cache (default: 100000)
+	    --sigcacheeviction=     Which entry to evict from the full signature
+	                            verification cache to make room for a new one
+	                            {random, oldest} (default: random)
 	    --simnet                Use the simulation test network
+	    --spentindex            Maintain an index of the inputs which spent each
+	                            output which makes the getspentinfo RPC
+	                            available
+	    --staletipfactor=       Multiple of the target time between blocks
+	                            after which the best chain tip is considered
+	                            stale when no new block arrived -- headers are
+	                            then requested from all peers and the sync peer
+	                            is replaced.  0 to disable (default: 3)
 	    --testnet               Use the test network
+	    --torcontrol=           Create an onion service for incoming
+	                            connections via the specified tor control port
+	                            (eg. 127.0.0.1:9051)
 	    --torisolation          Enable Tor stream isolation by randomizing user
 	                            credentials for each connection.
+	    --torpassword=          Password for the tor control port
 	    --trickleinterval=      Minimum time between attempts to send new
 	                            inventory to a connected peer (default: 10s)
+	    --tricklescalepeers=    Number of connected peers above which the
+	                            trickle interval is scaled up in proportion to
+	                            the number of peers, up to four times the
+	                            trickleinterval option, to reduce redundant
+	                            announcements -- 0 to disable
+	    --txannouncedelay=      Minimum time new transactions are held back
+	                            before they are announced to a connected peer,
+	                            which gives the peer a chance to announce them
+	                            first
 	    --txindex               Maintain a full hash-based transaction index
 	                            which makes all transactions available via the
 	                            getrawtransaction RPC
 	    --uacomment=            Comment to add to the user agent -- See BIP 14
 	                            for more information.
 	    --upnp                  Use UPnP to map our listening port outside of NAT
+	    --utxocacheflushinterval=
+	                            Interval at which the UTXO cache is flushed to
+	                            the database once the chain is synced -- the
+	                            cache is always flushed when it is full and on
+	                            shutdown (default: 5m0s)
+	    --utxocachemaxsize=     The maximum size in MiB of the UTXO cache
+	                            (default: 250)
 	-V, --version               Display version information and exit
-	    --whitelist=            Add an IP network or IP that will not be banned.
-	                            (eg. 192.168.1.0/24 or ::1)
+	    --whitebind=            Add an interface/port to listen for connections
+	                            and grant permissions to the peers connecting to
+	                            it.  Permissions are given as a comma separated
+	                            list before an @ and default to
+	                            noban,relay,mempool (eg.
+	                            noban,bloomfilter@127.0.0.1:8333)
+	    --whitelist=            Add an IP network or IP and grant permissions to
+	                            the peers in it.  Valid permissions are noban,
+	                            forcerelay, relay, mempool, and bloomfilter.
+	                            They are given as a comma separated list before
+	                            an @ and default to noban,relay,mempool (eg.
+	                            192.168.1.0/24, ::1, or relay,mempool@10.0.0.1)
 
 Help Options:
 
@@ -0,0 +1,159 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"hash/maphash"
+	"sort"
+	"time"
+)
+
+const (
+	// evictionProtectNetGroups is the number of inbound peers selected by a
+	// keyed hash of their network group which are protected from eviction.
+	evictionProtectNetGroups = 4
+
+	// evictionProtectPing is the number of inbound peers with the lowest
+	// ping times which are protected from eviction.
+	evictionProtectPing = 8
+
+	// evictionProtectTx is the number of inbound peers which most recently
+	// sent new transactions which are protected from eviction.
+	evictionProtectTx = 4
+
+	// evictionProtectBlock is the number of inbound peers which most
+	// recently sent new blocks which are protected from eviction.
+	evictionProtectBlock = 4
+)
+
+// evictionCandidate houses the details of an inbound peer used to decide
+// which peer to evict when the maximum number of peers is reached.
+type evictionCandidate struct {
+	sp            *serverPeer
+	connected     time.Time
+	pingMicros    int64
+	lastTxTime    int64
+	lastBlockTime int64
+	group         string
+	keyedGroup    uint64
+}
+
+// protectCandidates sorts the passed candidates so the ones most deserving of
+// protection come first according to the passed function and returns the
+// remaining candidates after removing up to n of them.
+func protectCandidates(candidates []*evictionCandidate, n int,
+	better func(a, b *evictionCandidate) bool) []*evictionCandidate {
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return better(candidates[i], candidates[j])
+	})
+	if n > len(candidates) {
+		n = len(candidates)
+	}
+	return candidates[n:]
+}
+
+// selectEvictionCandidate returns the candidate which should be evicted to
+// make room for a new inbound peer or nil if all of them are protected.
+//
+// Peers are protected when they are in one of a few network groups selected
+// by a key an attacker can't predict, have the lowest ping times, have most
+// recently sent new transactions or blocks, or have been connected the
+// longest.  Since an attacker would have to beat honest peers on all of these
+// at once, this makes it hard to take over all inbound slots.  The peer which
+// connected last in the network group with the most remaining candidates is
+// evicted.
+func selectEvictionCandidate(candidates []*evictionCandidate) *evictionCandidate {
+	candidates = append([]*evictionCandidate(nil), candidates...)
+
+	candidates = protectCandidates(candidates, evictionProtectNetGroups,
+		func(a, b *evictionCandidate) bool {
+			return a.keyedGroup > b.keyedGroup
+		})
+
+	// Peers with unknown ping times are treated as the slowest.
+	candidates = protectCandidates(candidates, evictionProtectPing,
+		func(a, b *evictionCandidate) bool {
+			if a.pingMicros == 0 || b.pingMicros == 0 {
+				return a.pingMicros != 0
+			}
+			return a.pingMicros < b.pingMicros
+		})
+
+	candidates = protectCandidates(candidates, evictionProtectTx,
+		func(a, b *evictionCandidate) bool {
+			return a.lastTxTime > b.lastTxTime
+		})
+
+	candidates = protectCandidates(candidates, evictionProtectBlock,
+		func(a, b *evictionCandidate) bool {
+			return a.lastBlockTime > b.lastBlockTime
+		})
+
+	candidates = protectCandidates(candidates, len(candidates)/2,
+		func(a, b *evictionCandidate) bool {
+			return a.connected.Before(b.connected)
+		})
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	// The remaining candidates are sorted by their connection time, so the
+	// last candidate of each network group is its most recently connected
+	// one.  Find the network group with the most candidates, preferring the
+	// one with the most recent connection on ties, and evict its most
+	// recently connected peer.
+	groups := make(map[string][]*evictionCandidate)
+	for _, c := range candidates {
+		groups[c.group] = append(groups[c.group], c)
+	}
+	var evictGroup []*evictionCandidate
+	for _, group := range groups {
+		youngest := group[len(group)-1]
+		if len(group) > len(evictGroup) || (len(group) == len(evictGroup) &&
+			youngest.connected.After(
+				evictGroup[len(evictGroup)-1].connected)) {
+
+			evictGroup = group
+		}
+	}
+	return evictGroup[len(evictGroup)-1]
+}
+
+// evictInboundPeer disconnects the inbound peer chosen by
+// selectEvictionCandidate to make room for a new inbound peer and returns
+// whether a peer was evicted.  Peers with the noban permission are never
+// evicted.  It is invoked from the peerHandler goroutine.
+func (s *server) evictInboundPeer(state *peerState) bool {
+	candidates := make([]*evictionCandidate, 0, len(state.inboundPeers))
+	for _, sp := range state.inboundPeers {
+		if sp.permissions.has(permNoBan) || !sp.Connected() {
+			continue
+		}
+		stats := sp.StatsSnapshot()
+		group := s.addrManager.GroupKey(sp.NA())
+		candidates = append(candidates, &evictionCandidate{
+			sp:            sp,
+			connected:     stats.ConnTime,
+			pingMicros:    stats.LastPingMicros,
+			lastTxTime:    sp.lastTxTime.Load(),
+			lastBlockTime: sp.lastBlockTime.Load(),
+			group:         group,
+			keyedGroup:    maphash.String(s.evictionSeed, group),
+		})
+	}
+
+	evict := selectEvictionCandidate(candidates)
+	if evict == nil {
+		return false
+	}
+
+	srvrLog.Debugf("Evicting inbound peer %s to make room for a new peer",
+		evict.sp)
+	delete(state.inboundPeers, evict.sp.ID())
+	evict.sp.Disconnect()
+	return true
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestSelectEvictionCandidate ensures peers which are useful in any of the
+// protected ways are never evicted and that the most recently connected peer
+// of the largest network group is evicted otherwise.
+func TestSelectEvictionCandidate(t *testing.T) {
+	base := time.Unix(1700000000, 0)
+
+	// newCandidates returns num candidates which connected a minute apart
+	// in the order they are returned in.  The first four are in the network
+	// groups with the highest keys, the next eight have the lowest ping
+	// times, followed by four which recently sent new transactions and four
+	// which recently sent new blocks.  Each is in its own network group.
+	newCandidates := func(num int) []*evictionCandidate {
+		candidates := make([]*evictionCandidate, 0, num)
+		for i := 0; i < num; i++ {
+			c := &evictionCandidate{
+				connected:  base.Add(time.Duration(i) * time.Minute),
+				group:      fmt.Sprintf("group%d", i),
+				keyedGroup: uint64(i),
+			}
+			switch {
+			case i < 4:
+				c.keyedGroup = uint64(1000 + i)
+			case i < 12:
+				c.pingMicros = int64(i)
+			case i < 16:
+				c.lastTxTime = base.Unix() + int64(i)
+			case i < 20:
+				c.lastBlockTime = base.Unix() + int64(i)
+			}
+			candidates = append(candidates, c)
+		}
+		return candidates
+	}
+
+	// No peer is evicted when all of them are protected.
+	if evict := selectEvictionCandidate(newCandidates(20)); evict != nil {
+		t.Fatalf("selectEvictionCandidate: evicted protected peer %v",
+			evict.group)
+	}
+
+	// The half of the remaining peers which have been connected the longest
+	// are protected as well, which leaves the last five.  The last two
+	// groups of the same size are preferred since they contain the most
+	// recent connection.
+	candidates := newCandidates(30)
+	candidates[25].group = "a"
+	candidates[26].group = "a"
+	candidates[28].group = "b"
+	candidates[29].group = "b"
+	evict := selectEvictionCandidate(candidates)
+	if evict != candidates[29] {
+		t.Fatalf("selectEvictionCandidate: got %v, want %v", evict.connected,
+			candidates[29].connected)
+	}
+
+	// The largest group is preferred over the one with the most recent
+	// connection.
+	candidates[27].group = "a"
+	evict = selectEvictionCandidate(candidates)
+	if evict != candidates[27] {
+		t.Fatalf("selectEvictionCandidate: got %v, want %v", evict.connected,
+			candidates[27].connected)
+	}
+
+	// Peers protected by their network group aren't evicted even when they
+	// are in the largest group.
+	candidates = newCandidates(30)
+	for _, c := range candidates {
+		c.group = "a"
+	}
+	candidates[29].keyedGroup = 2000
+	evict = selectEvictionCandidate(candidates)
+	if evict != candidates[28] {
+		t.Fatalf("selectEvictionCandidate: got %v, want %v", evict.connected,
+			candidates[28].connected)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// This file is ignored during the regular tests due to the following build tag.
+//go:build rpctest
+// +build rpctest
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/address/v2"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/v2"
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/integration/rpctest"
+	"github.com/btcsuite/btcd/txscript/v2"
+	"github.com/btcsuite/btcd/wire/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAddressIndexRPCs checks that the getaddresstxids, getaddressbalance and
+// getaddressutxos RPCs return the transactions, balance and unspent outputs of
+// addresses which were paid to and spent from.
+func TestAddressIndexRPCs(t *testing.T) {
+	t.Parallel()
+
+	btcdCfg := []string{"--addrindex", "--txindex"}
+	r, err := rpctest.New(&chaincfg.SimNetParams, nil, btcdCfg, "")
+	require.NoError(t, err)
+	require.NoError(t, r.SetUp(true, 100))
+	t.Cleanup(func() {
+		require.NoError(t, r.TearDown())
+	})
+
+	// Pay to a new address twice in a single transaction.  Spending the
+	// mature coinbase outputs also spends from the mining address.
+	addr, err := r.NewAddress()
+	require.NoError(t, err)
+	pkScript, err := txscript.PayToAddrScript(addr)
+	require.NoError(t, err)
+	txid, err := r.SendOutputs([]*wire.TxOut{
+		wire.NewTxOut(100000000, pkScript),
+		wire.NewTxOut(200000000, pkScript),
+	}, 10)
+	require.NoError(t, err)
+	_, err = r.Client.Generate(1)
+	require.NoError(t, err)
+	_, bestHeight, err := r.Client.GetBestBlock()
+	require.NoError(t, err)
+
+	txids, err := r.Client.GetAddressTxIDs([]address.Address{addr}, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, []*chainhash.Hash{txid}, txids)
+
+	balance, err := r.Client.GetAddressBalance([]address.Address{addr})
+	require.NoError(t, err)
+	require.Equal(t, &btcjson.GetAddressBalanceResult{
+		Balance:  300000000,
+		Received: 300000000,
+	}, balance)
+
+	utxos, err := r.Client.GetAddressUtxos([]address.Address{addr})
+	require.NoError(t, err)
+	require.Equal(t, bestHeight, utxos.Height)
+	require.Len(t, utxos.Utxos, 2)
+	for i, utxo := range utxos.Utxos {
+		require.Equal(t, txid.String(), utxo.TxID)
+		require.Equal(t, uint32(i), utxo.OutputIndex)
+		require.Equal(t, bestHeight, utxo.Height)
+	}
+
+	// Look up the mining address from the coinbase of the first block.
+	blockHash, err := r.Client.GetBlockHash(1)
+	require.NoError(t, err)
+	block, err := r.Client.GetBlock(blockHash)
+	require.NoError(t, err)
+	coinbase := block.Transactions[0]
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(
+		coinbase.TxOut[0].PkScript, r.ActiveNet)
+	require.NoError(t, err)
+	require.Len(t, addrs, 1)
+	miningAddr := addrs[0]
+
+	// Only the coinbase of the first block is returned for its height.
+	start, end := int32(1), int32(1)
+	txids, err = r.Client.GetAddressTxIDs([]address.Address{miningAddr},
+		&start, &end)
+	require.NoError(t, err)
+	coinbaseHash := coinbase.TxHash()
+	require.Equal(t, []*chainhash.Hash{&coinbaseHash}, txids)
+
+	// The mining address spent some of the outputs it received and its
+	// balance is the value of its unspent outputs.
+	balance, err = r.Client.GetAddressBalance([]address.Address{miningAddr})
+	require.NoError(t, err)
+	require.Less(t, balance.Balance, balance.Received)
+	utxos, err = r.Client.GetAddressUtxos([]address.Address{miningAddr})
+	require.NoError(t, err)
+	var unspent int64
+	for _, utxo := range utxos.Utxos {
+		unspent += utxo.Satoshis
+	}
+	require.Equal(t, balance.Balance, unspent)
+}
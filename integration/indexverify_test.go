@@ -0,0 +1,65 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// This file is ignored during the regular tests due to the following build tag.
+//go:build rpctest
+// +build rpctest
+
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/v2"
+	"github.com/btcsuite/btcd/integration/rpctest"
+	"github.com/stretchr/testify/require"
+)
+
+// TestVerifyRebuildIndex checks that the verifyindex RPC verifies the enabled
+// indexes and the rebuildindex RPC builds the transaction index and the address
+// index which relies on it again.
+func TestVerifyRebuildIndex(t *testing.T) {
+	t.Parallel()
+
+	btcdCfg := []string{"--txindex", "--addrindex"}
+	r, err := rpctest.New(&chaincfg.SimNetParams, nil, btcdCfg, "")
+	require.NoError(t, err)
+	require.NoError(t, r.SetUp(true, 50))
+	t.Cleanup(func() {
+		require.NoError(t, r.TearDown())
+	})
+	_, bestHeight, err := r.Client.GetBestBlock()
+	require.NoError(t, err)
+
+	for _, name := range []string{"transaction index", "address index"} {
+		result, err := r.Client.VerifyIndexBlocks(name, 0)
+		require.NoError(t, err)
+		require.True(t, result.Valid, result.Mismatch)
+		require.Equal(t, bestHeight, result.Height)
+		require.Equal(t, bestHeight+1, result.Checked)
+	}
+	_, err = r.Client.VerifyIndex("spent output index")
+	require.Error(t, err)
+
+	require.NoError(t, r.Client.RebuildIndex("transaction index"))
+	require.Eventually(t, func() bool {
+		infos, err := r.Client.GetIndexInfo(nil)
+		require.NoError(t, err)
+		require.Contains(t, infos, "address index")
+		for _, info := range infos {
+			if !info.Synced || info.BestBlockHeight != bestHeight {
+				return false
+			}
+		}
+		return true
+	}, 10*time.Second, 100*time.Millisecond)
+
+	for _, name := range []string{"transaction index", "address index"} {
+		result, err := r.Client.VerifyIndex(name)
+		require.NoError(t, err)
+		require.True(t, result.Valid, result.Mismatch)
+		require.Equal(t, bestHeight+1, result.Checked)
+	}
+}
@@ -9,6 +9,7 @@
 package integration
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/btcsuite/btcd/chaincfg/v2"
@@ -37,4 +38,16 @@ func TestPrune(t *testing.T) {
 		t.Fatalf("expected the node to be pruned but the pruned "+
 			"boolean was %v", chainInfo.Pruned)
 	}
+	require.Zero(t, chainInfo.PruneHeight)
+
+	// The blocks close to the tip are never pruned, so no blocks are
+	// deleted on a short chain.
+	_, err = r.Client.Generate(10)
+	require.NoError(t, err)
+	height, err := json.Marshal(10)
+	require.NoError(t, err)
+	result, err := r.Client.RawRequest("pruneblockchain",
+		[]json.RawMessage{height})
+	require.NoError(t, err)
+	require.JSONEq(t, "-1", string(result))
 }
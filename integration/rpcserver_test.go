@@ -10,17 +10,24 @@ package integration
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"runtime/debug"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil/v2"
 	"github.com/btcsuite/btcd/chaincfg/v2"
 	"github.com/btcsuite/btcd/chainhash/v2"
 	"github.com/btcsuite/btcd/integration/rpctest"
 	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/btcsuite/btcd/txscript/v2"
+	"github.com/btcsuite/btcd/wire/v2"
 )
 
 func testGetBestBlock(r *rpctest.Harness, t *testing.T) {
@@ -289,6 +296,301 @@ func testGetNetworkHashPS3(r *rpctest.Harness, t *testing.T) {
 	}
 }
 
+func testGetBlockTemplateProposal(r *rpctest.Harness, t *testing.T) {
+	bestHash, bestHeight, err := r.Client.GetBestBlock()
+	if err != nil {
+		t.Fatalf("Call to `getbestblock` failed: %v", err)
+	}
+	msgBlock, err := r.Client.GetBlock(bestHash)
+	if err != nil {
+		t.Fatalf("Call to `getblock` failed: %v", err)
+	}
+	bestBlock := btcutil.NewBlock(msgBlock)
+	bestBlock.SetHeight(bestHeight)
+
+	// A valid block building on the tip is accepted.
+	addr, err := r.NewAddress()
+	if err != nil {
+		t.Fatalf("Unable to generate address: %v", err)
+	}
+	block, err := rpctest.CreateBlock(bestBlock, nil, rpctest.BlockVersion,
+		time.Time{}, addr, nil, r.ActiveNet)
+	if err != nil {
+		t.Fatalf("Unable to create block: %v", err)
+	}
+	reason, err := r.Client.ProposeBlock(block)
+	if err != nil {
+		t.Fatalf("Call to `getblocktemplate` failed: %v", err)
+	}
+	if reason != "" {
+		t.Fatalf("Valid block proposal rejected: %v", reason)
+	}
+
+	// The proposal must not have been added to the chain.
+	newBestHash, _, err := r.Client.GetBestBlock()
+	if err != nil {
+		t.Fatalf("Call to `getbestblock` failed: %v", err)
+	}
+	if !newBestHash.IsEqual(bestHash) {
+		t.Fatalf("Block proposal was added to the chain")
+	}
+
+	// A block with an invalid merkle root is rejected.
+	badBlock := btcutil.NewBlock(block.MsgBlock().Copy())
+	badBlock.MsgBlock().Header.MerkleRoot = chainhash.Hash{}
+	reason, err = r.Client.ProposeBlock(badBlock)
+	if err != nil {
+		t.Fatalf("Call to `getblocktemplate` failed: %v", err)
+	}
+	if reason != "bad-txnmrklroot" {
+		t.Fatalf("Unexpected rejection reason for invalid block "+
+			"proposal: got %q, want %q", reason, "bad-txnmrklroot")
+	}
+
+	// Proposing the current tip is reported as a duplicate.
+	reason, err = r.Client.ProposeBlock(bestBlock)
+	if err != nil {
+		t.Fatalf("Call to `getblocktemplate` failed: %v", err)
+	}
+	if reason != "duplicate" {
+		t.Fatalf("Unexpected rejection reason for duplicate block "+
+			"proposal: got %q, want %q", reason, "duplicate")
+	}
+}
+
+func testGetBlockTemplateCoinbaseTxn(r *rpctest.Harness, t *testing.T) {
+	// Mine blocks until segwit is active so the template commits to the
+	// witness data of its transactions.
+	for {
+		info, err := r.Client.GetDeploymentInfo()
+		if err != nil {
+			t.Fatalf("Call to `getdeploymentinfo` failed: %v", err)
+		}
+		if info.Deployments["segwit"].Active {
+			break
+		}
+		if _, err := r.Client.Generate(1); err != nil {
+			t.Fatalf("Call to `generate` failed: %v", err)
+		}
+	}
+
+	addr, err := r.NewAddress()
+	if err != nil {
+		t.Fatalf("Unable to generate address: %v", err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("Unable to create pay-to-addr script: %v", err)
+	}
+
+	// Add a transaction with witness data to the mempool so the template
+	// commits to it.
+	witnessScript := []byte{txscript.OP_TRUE}
+	scriptHash := sha256.Sum256(witnessScript)
+	witnessPkScript, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_0).AddData(scriptHash[:]).Script()
+	if err != nil {
+		t.Fatalf("Unable to create witness script: %v", err)
+	}
+	output := wire.NewTxOut(btcutil.SatoshiPerBitcoin, witnessPkScript)
+	fundHash, err := r.SendOutputs([]*wire.TxOut{output}, 10)
+	if err != nil {
+		t.Fatalf("Unable to send outputs: %v", err)
+	}
+	fundTx, err := r.Client.GetRawTransaction(fundHash)
+	if err != nil {
+		t.Fatalf("Call to `getrawtransaction` failed: %v", err)
+	}
+	spendTx := wire.NewMsgTx(wire.TxVersion)
+	for i, txOut := range fundTx.MsgTx().TxOut {
+		if bytes.Equal(txOut.PkScript, witnessPkScript) {
+			spendTx.AddTxIn(&wire.TxIn{
+				PreviousOutPoint: *wire.NewOutPoint(fundHash,
+					uint32(i)),
+				Witness:  wire.TxWitness{witnessScript},
+				Sequence: wire.MaxTxInSequenceNum,
+			})
+		}
+	}
+	spendTx.AddTxOut(wire.NewTxOut(btcutil.SatoshiPerBitcoin-10000,
+		pkScript))
+	if _, err := r.Client.SendRawTransaction(spendTx, true); err != nil {
+		t.Fatalf("Call to `sendrawtransaction` failed: %v", err)
+	}
+
+	result, err := r.Client.GetBlockTemplate(&btcjson.TemplateRequest{
+		Capabilities: []string{"coinbasetxn"},
+		Rules:        []string{"segwit"},
+	})
+	if err != nil {
+		t.Fatalf("Call to `getblocktemplate` failed: %v", err)
+	}
+	var generation bool
+	for _, mutable := range result.Mutable {
+		generation = generation || mutable == "generation"
+	}
+	if !generation || result.CoinbaseTxn == nil {
+		t.Fatalf("Template does not allow adding coinbase outputs: "+
+			"mutable %v", result.Mutable)
+	}
+
+	// Modify the coinbase like a pool would by appending to its signature
+	// script, paying part of the value to another output, and dropping its
+	// witness by serializing it without witness data.
+	coinbaseBytes, err := hex.DecodeString(result.CoinbaseTxn.Data)
+	if err != nil {
+		t.Fatalf("Unable to decode coinbase: %v", err)
+	}
+	var coinbase wire.MsgTx
+	if err := coinbase.Deserialize(bytes.NewReader(coinbaseBytes)); err != nil {
+		t.Fatalf("Unable to deserialize coinbase: %v", err)
+	}
+	coinbase.TxIn[0].SignatureScript = append(
+		coinbase.TxIn[0].SignatureScript, []byte("/pool/")...)
+	coinbase.TxOut[0].Value -= btcutil.SatoshiPerBitcoin
+	coinbase.AddTxOut(wire.NewTxOut(btcutil.SatoshiPerBitcoin, pkScript))
+	coinbase.TxIn[0].Witness = nil
+
+	// Assemble the block from the template.
+	prevHash, err := chainhash.NewHashFromStr(result.PreviousHash)
+	if err != nil {
+		t.Fatalf("Unable to decode previous hash: %v", err)
+	}
+	bits, err := strconv.ParseUint(result.Bits, 16, 32)
+	if err != nil {
+		t.Fatalf("Unable to decode bits: %v", err)
+	}
+	msgBlock := wire.NewMsgBlock(&wire.BlockHeader{
+		Version:   result.Version,
+		PrevBlock: *prevHash,
+		Timestamp: time.Unix(result.CurTime, 0),
+		Bits:      uint32(bits),
+	})
+	msgBlock.AddTransaction(&coinbase)
+	var hasWitness bool
+	for _, resultTx := range result.Transactions {
+		txBytes, err := hex.DecodeString(resultTx.Data)
+		if err != nil {
+			t.Fatalf("Unable to decode transaction: %v", err)
+		}
+		var tx wire.MsgTx
+		if err := tx.Deserialize(bytes.NewReader(txBytes)); err != nil {
+			t.Fatalf("Unable to deserialize transaction: %v", err)
+		}
+		hasWitness = hasWitness || tx.HasWitness()
+		msgBlock.AddTransaction(&tx)
+	}
+	if !hasWitness {
+		t.Fatal("Template does not include the witness transaction")
+	}
+	block := btcutil.NewBlock(msgBlock)
+	msgBlock.Header.MerkleRoot = blockchain.CalcMerkleRoot(
+		block.Transactions(), false)
+
+	// The witness commitment of the template must still be valid.
+	reason, err := r.Client.ProposeBlock(btcutil.NewBlock(msgBlock))
+	if err != nil {
+		t.Fatalf("Call to `getblocktemplate` failed: %v", err)
+	}
+	if reason != "" {
+		t.Fatalf("Block with modified coinbase rejected: %v", reason)
+	}
+}
+
+func testGenerateToAddress(r *rpctest.Harness, t *testing.T) {
+	addr, err := r.NewAddress()
+	if err != nil {
+		t.Fatalf("Unable to generate address: %v", err)
+	}
+	blockHashes, err := r.Client.GenerateToAddress(2, addr, nil)
+	if err != nil {
+		t.Fatalf("Call to `generatetoaddress` failed: %v", err)
+	}
+	if len(blockHashes) != 2 {
+		t.Fatalf("Unexpected number of generated blocks: got %d, want 2",
+			len(blockHashes))
+	}
+
+	// The coinbase of the generated blocks must pay to the address.
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("Unable to create pay-to-addr script: %v", err)
+	}
+	for _, blockHash := range blockHashes {
+		msgBlock, err := r.Client.GetBlock(blockHash)
+		if err != nil {
+			t.Fatalf("Call to `getblock` failed: %v", err)
+		}
+		coinbaseOut := msgBlock.Transactions[0].TxOut[0]
+		if !bytes.Equal(coinbaseOut.PkScript, pkScript) {
+			t.Fatalf("Coinbase of block %v pays to %x, want %x",
+				blockHash, coinbaseOut.PkScript, pkScript)
+		}
+	}
+}
+
+func testGenerateBlock(r *rpctest.Harness, t *testing.T) {
+	addr, err := r.NewAddress()
+	if err != nil {
+		t.Fatalf("Unable to generate address: %v", err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("Unable to create pay-to-addr script: %v", err)
+	}
+
+	// Include a transaction from the mempool by its hash followed by a
+	// transaction which was never broadcast.
+	output := wire.NewTxOut(btcutil.SatoshiPerBitcoin, pkScript)
+	mempoolTxHash, err := r.SendOutputs([]*wire.TxOut{output}, 10)
+	if err != nil {
+		t.Fatalf("Unable to send outputs: %v", err)
+	}
+	rawTx, err := r.CreateTransaction([]*wire.TxOut{output}, 10, true)
+	if err != nil {
+		t.Fatalf("Unable to create transaction: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := rawTx.Serialize(&buf); err != nil {
+		t.Fatalf("Unable to serialize transaction: %v", err)
+	}
+
+	blockHash, err := r.Client.GenerateBlock(addr, []string{
+		mempoolTxHash.String(), hex.EncodeToString(buf.Bytes()),
+	})
+	if err != nil {
+		t.Fatalf("Call to `generateblock` failed: %v", err)
+	}
+	msgBlock, err := r.Client.GetBlock(blockHash)
+	if err != nil {
+		t.Fatalf("Call to `getblock` failed: %v", err)
+	}
+	if len(msgBlock.Transactions) != 3 ||
+		msgBlock.Transactions[1].TxHash() != *mempoolTxHash ||
+		msgBlock.Transactions[2].TxHash() != rawTx.TxHash() {
+
+		t.Fatalf("Generated block does not contain the expected " +
+			"transactions")
+	}
+	bestHash, _, err := r.Client.GetBestBlock()
+	if err != nil {
+		t.Fatalf("Call to `getbestblock` failed: %v", err)
+	}
+	if !bestHash.IsEqual(blockHash) {
+		t.Fatalf("Generated block %v is not the best block %v",
+			blockHash, bestHash)
+	}
+
+	// Including the same transaction again is a double spend.
+	_, err = r.Client.GenerateBlock(addr, []string{
+		hex.EncodeToString(buf.Bytes()),
+	})
+	if err == nil {
+		t.Fatalf("Call to `generateblock` with a spent transaction " +
+			"succeeded")
+	}
+}
+
 var rpcTestCases = []rpctest.HarnessTestCase{
 	testGetBestBlock,
 	testGetBlockCount,
@@ -297,6 +599,116 @@ var rpcTestCases = []rpctest.HarnessTestCase{
 	testGetNetworkHashPS,
 	testGetNetworkHashPS2,
 	testGetNetworkHashPS3,
+	testGetBlockTemplateProposal,
+	testGenerateToAddress,
+	testGenerateBlock,
+	testSignalBits,
+	testSetBan,
+	testGetBlockTemplateCoinbaseTxn,
+}
+
+func testSetBan(r *rpctest.Harness, t *testing.T) {
+	if err := r.Client.SetBan("192.0.2.0/24", btcjson.SBAdd, nil,
+		nil); err != nil {
+
+		t.Fatalf("Call to `setban` failed: %v", err)
+	}
+	banTime := time.Now().Add(time.Hour).Unix()
+	if err := r.Client.SetBan("198.51.100.1", btcjson.SBAdd, &banTime,
+		btcjson.Bool(true)); err != nil {
+
+		t.Fatalf("Call to `setban` failed: %v", err)
+	}
+
+	banned, err := r.Client.ListBanned()
+	if err != nil {
+		t.Fatalf("Call to `listbanned` failed: %v", err)
+	}
+	if len(banned) != 2 || banned[0].Address != "192.0.2.0/24" ||
+		banned[1].Address != "198.51.100.1/32" ||
+		banned[1].BannedUntil != banTime {
+
+		t.Fatalf("Unexpected bans %+v", banned)
+	}
+
+	// Lifting a ban which doesn't exist must fail.
+	if err := r.Client.SetBan("203.0.113.1", btcjson.SBRemove, nil,
+		nil); err == nil {
+
+		t.Fatal("`setban remove` of an address which is not banned " +
+			"succeeded")
+	}
+	if err := r.Client.SetBan("192.0.2.0/24", btcjson.SBRemove, nil,
+		nil); err != nil {
+
+		t.Fatalf("Call to `setban remove` failed: %v", err)
+	}
+	banned, err = r.Client.ListBanned()
+	if err != nil {
+		t.Fatalf("Call to `listbanned` failed: %v", err)
+	}
+	if len(banned) != 1 {
+		t.Fatalf("Unexpected bans after removal %+v", banned)
+	}
+
+	if err := r.Client.ClearBanned(); err != nil {
+		t.Fatalf("Call to `clearbanned` failed: %v", err)
+	}
+	banned, err = r.Client.ListBanned()
+	if err != nil {
+		t.Fatalf("Call to `listbanned` failed: %v", err)
+	}
+	if len(banned) != 0 {
+		t.Fatalf("Unexpected bans after clearing %+v", banned)
+	}
+}
+
+func testSignalBits(r *rpctest.Harness, t *testing.T) {
+	// Bits above the highest signalling bit must be rejected.
+	if err := r.Client.SetSignalBit(29, true); err == nil {
+		t.Fatal("Call to `setsignalbit` with bit 29 unexpectedly succeeded")
+	}
+
+	if err := r.Client.SetSignalBit(5, true); err != nil {
+		t.Fatalf("Call to `setsignalbit` failed: %v", err)
+	}
+	defer func() {
+		if err := r.Client.SetSignalBit(5, false); err != nil {
+			t.Fatalf("Call to `setsignalbit` failed: %v", err)
+		}
+	}()
+
+	info, err := r.Client.GetDeploymentInfo()
+	if err != nil {
+		t.Fatalf("Call to `getdeploymentinfo` failed: %v", err)
+	}
+	if len(info.SignalBits) != 1 || info.SignalBits[0] != 5 {
+		t.Fatalf("Unexpected signal bits: got %v, want [5]",
+			info.SignalBits)
+	}
+	if _, ok := info.Deployments["segwit"]; !ok {
+		t.Fatal("Deployment segwit missing from `getdeploymentinfo`")
+	}
+	bip34, ok := info.Deployments["bip34"]
+	if !ok || bip34.Type != "buried" || !bip34.Active ||
+		bip34.Height == nil || *bip34.Height != 0 {
+
+		t.Fatalf("Unexpected buried deployment bip34: %+v", bip34)
+	}
+
+	// The generated block must signal the bit.
+	blockHashes, err := r.Client.Generate(1)
+	if err != nil {
+		t.Fatalf("Call to `generate` failed: %v", err)
+	}
+	header, err := r.Client.GetBlockHeader(blockHashes[0])
+	if err != nil {
+		t.Fatalf("Call to `getblockheader` failed: %v", err)
+	}
+	if header.Version&(1<<5) == 0 {
+		t.Fatalf("Generated block version %08x does not signal bit 5",
+			header.Version)
+	}
 }
 
 var primaryHarness *rpctest.Harness
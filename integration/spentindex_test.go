@@ -0,0 +1,77 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// This file is ignored during the regular tests due to the following build tag.
+//go:build rpctest
+// +build rpctest
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/v2"
+	"github.com/btcsuite/btcd/integration/rpctest"
+	"github.com/btcsuite/btcd/txscript/v2"
+	"github.com/btcsuite/btcd/wire/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetSpentInfo checks that the getspentinfo RPC returns the input which
+// spent an output both while the spending transaction is in the mempool and
+// once it is mined.
+func TestGetSpentInfo(t *testing.T) {
+	t.Parallel()
+
+	btcdCfg := []string{"--spentindex"}
+	r, err := rpctest.New(&chaincfg.SimNetParams, nil, btcdCfg, "")
+	require.NoError(t, err)
+	require.NoError(t, r.SetUp(true, 100))
+	t.Cleanup(func() {
+		require.NoError(t, r.TearDown())
+	})
+
+	addr, err := r.NewAddress()
+	require.NoError(t, err)
+	pkScript, err := txscript.PayToAddrScript(addr)
+	require.NoError(t, err)
+	txid, err := r.SendOutputs([]*wire.TxOut{
+		wire.NewTxOut(100000000, pkScript),
+	}, 10)
+	require.NoError(t, err)
+	tx, err := r.Client.GetRawTransaction(txid)
+	require.NoError(t, err)
+	txIns := tx.MsgTx().TxIn
+
+	// The spending transaction is still in the mempool.
+	for i, txIn := range txIns {
+		info, err := r.Client.GetSpentInfo(&txIn.PreviousOutPoint)
+		require.NoError(t, err)
+		require.Equal(t, &btcjson.GetSpentInfoResult{
+			TxID:   txid.String(),
+			Index:  uint32(i),
+			Height: -1,
+		}, info)
+	}
+
+	_, err = r.Client.Generate(1)
+	require.NoError(t, err)
+	_, bestHeight, err := r.Client.GetBestBlock()
+	require.NoError(t, err)
+
+	for i, txIn := range txIns {
+		info, err := r.Client.GetSpentInfo(&txIn.PreviousOutPoint)
+		require.NoError(t, err)
+		require.Equal(t, &btcjson.GetSpentInfoResult{
+			TxID:   txid.String(),
+			Index:  uint32(i),
+			Height: bestHeight,
+		}, info)
+	}
+
+	// The new output is unspent.
+	_, err = r.Client.GetSpentInfo(wire.NewOutPoint(txid, 0))
+	require.Error(t, err)
+}
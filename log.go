@@ -9,15 +9,18 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/btcsuite/btcd/addrmgr"
 	"github.com/btcsuite/btcd/blockchain"
 	"github.com/btcsuite/btcd/blockchain/indexers"
 	"github.com/btcsuite/btcd/connmgr"
 	"github.com/btcsuite/btcd/database"
+	"github.com/btcsuite/btcd/dnsseeder"
 	"github.com/btcsuite/btcd/mempool"
 	"github.com/btcsuite/btcd/mining"
 	"github.com/btcsuite/btcd/mining/cpuminer"
+	"github.com/btcsuite/btcd/mining/stratum"
 	"github.com/btcsuite/btcd/netsync"
 	"github.com/btcsuite/btcd/peer"
 	"github.com/btcsuite/btcd/txscript/v2"
@@ -28,15 +31,22 @@ import (
 )
 
 // logWriter implements an io.Writer that outputs to both standard output and
-// the write-end pipe of an initialized log rotator.
+// the write-end pipe of an initialized log rotator, unless logging to files is
+// disabled.
 type logWriter struct{}
 
 func (logWriter) Write(p []byte) (n int, err error) {
 	os.Stdout.Write(p)
-	logRotator.Write(p)
+	if logRotator != nil {
+		logRotator.Write(p)
+	}
 	return len(p), nil
 }
 
+// logPruneInterval is the maximum interval between the checks for roll files
+// of the log file which exceeded the maximum age.
+const logPruneInterval = time.Hour
+
 // Loggers per subsystem.  A single backend logger is created and all subsystem
 // loggers created from it will write to the backend.  When adding new
 // subsystems, add the subsystem logger variable here and to the
@@ -44,7 +54,7 @@ func (logWriter) Write(p []byte) (n int, err error) {
 //
 // Loggers can not be used before the log rotator has been initialized with a
 // log file.  This must be performed early during application startup by calling
-// initLogRotator.
+// initLogRotator, unless logging to files is disabled.
 var (
 	// backendLog is the logging backend used to create all subsystem loggers.
 	// The backend must not be used before the log rotator has been initialized,
@@ -52,7 +62,7 @@ var (
 	backendLog = btclog.NewBackend(logWriter{})
 
 	// logRotator is one of the logging outputs.  It should be closed on
-	// application shutdown.
+	// application shutdown.  It is nil when logging to files is disabled.
 	logRotator *rotator.Rotator
 
 	adxrLog = backendLog.Logger("ADXR")
@@ -67,6 +77,7 @@ var (
 	peerLog = backendLog.Logger("PEER")
 	rpcsLog = backendLog.Logger("RPCS")
 	scrpLog = backendLog.Logger("SCRP")
+	seedLog = backendLog.Logger("SEED")
 	srvrLog = backendLog.Logger("SRVR")
 	syncLog = backendLog.Logger("SYNC")
 	txmpLog = backendLog.Logger("TXMP")
@@ -82,8 +93,10 @@ func init() {
 	indexers.UseLogger(indxLog)
 	mining.UseLogger(minrLog)
 	cpuminer.UseLogger(minrLog)
+	stratum.UseLogger(minrLog)
 	peer.UseLogger(peerLog)
 	txscript.UseLogger(scrpLog)
+	dnsseeder.UseLogger(seedLog)
 	netsync.UseLogger(syncLog)
 	mempool.UseLogger(txmpLog)
 	v2transport.UseLogger(v2trLog)
@@ -103,6 +116,7 @@ var subsystemLoggers = map[string]btclog.Logger{
 	"PEER":                peerLog,
 	"RPCS":                rpcsLog,
 	"SCRP":                scrpLog,
+	"SEED":                seedLog,
 	"SRVR":                srvrLog,
 	"SYNC":                syncLog,
 	"TXMP":                txmpLog,
@@ -110,24 +124,72 @@ var subsystemLoggers = map[string]btclog.Logger{
 }
 
 // initLogRotator initializes the logging rotater to write logs to logFile and
-// create roll files in the same directory.  It must be called before the
-// package-global log rotater variables are used.
-func initLogRotator(logFile string) {
+// create roll files in the same directory.  The log file is rotated once it
+// reaches maxSizeMiB and at most maxRolls roll files are kept, or all of them
+// when it is zero.  The roll files are compressed with gzip when compress is
+// set.  It must be called before the package-global log rotater variables are
+// used.
+func initLogRotator(logFile string, maxSizeMiB, maxRolls int, compress bool) {
 	logDir, _ := filepath.Split(logFile)
 	err := os.MkdirAll(logDir, 0700)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to create log directory: %v\n", err)
 		os.Exit(1)
 	}
-	r, err := rotator.New(logFile, 10*1024, false, 3)
+	r, err := rotator.New(logFile, int64(maxSizeMiB)*1024, false, maxRolls)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to create file rotator: %v\n", err)
 		os.Exit(1)
 	}
+	if !compress {
+		r.SetCompressor(nil, "")
+	}
 
 	logRotator = r
 }
 
+// pruneLogFiles removes the roll files of the passed log file which were last
+// modified more than maxAge ago.
+func pruneLogFiles(logFile string, maxAge time.Duration) error {
+	rolls, err := filepath.Glob(logFile + ".*")
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	for _, roll := range rolls {
+		info, err := os.Stat(roll)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		if info.Mode().IsRegular() && info.ModTime().Before(cutoff) {
+			if err := os.Remove(roll); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// logPruneHandler removes the roll files of the passed log file which are
+// older than maxAge at startup and then periodically, since the log rotator
+// only limits the number of them.  It must be run as a goroutine.
+func logPruneHandler(logFile string, maxAge time.Duration) {
+	interval := min(maxAge, logPruneInterval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := pruneLogFiles(logFile, maxAge); err != nil {
+			btcdLog.Warnf("Unable to remove old log files: %v", err)
+		}
+		<-ticker.C
+	}
+}
+
 // setLogLevel sets the logging level for provided subsystem.  Invalid
 // subsystems are ignored.  Uninitialized subsystems are dynamically created as
 // needed.
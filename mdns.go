@@ -0,0 +1,303 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	// mdnsPort is the port multicast DNS queries and responses are sent
+	// to.
+	mdnsPort = 5353
+
+	// mdnsServiceName is the DNS-SD service name nodes advertise
+	// themselves under.
+	mdnsServiceName = "_btcd._tcp.local"
+
+	// mdnsQueryInterval is the interval at which the local network is
+	// queried for other nodes.
+	mdnsQueryInterval = time.Minute * 5
+
+	// mdnsTTL is the time to live of the records advertised.
+	mdnsTTL = 120
+
+	// mdnsMaxMessageLen is the maximum length of a multicast DNS message
+	// which is read.
+	mdnsMaxMessageLen = 9000
+)
+
+// These constants define the parts of DNS messages used for multicast DNS
+// service discovery as described by RFC 1035, RFC 6762 and RFC 6763.
+const (
+	dnsHeaderLen     = 12
+	dnsFlagResponse  = 1 << 15
+	dnsFlagAuthority = 1 << 10
+	dnsTypePTR       = 12
+	dnsTypeTXT       = 16
+	dnsTypeSRV       = 33
+	dnsTypeANY       = 255
+	dnsClassIN       = 1
+	dnsCacheFlush    = 1 << 15
+	dnsMaxPointers   = 16
+)
+
+var (
+	// mdnsIPv4Group and mdnsIPv6Group are the multicast groups used for
+	// multicast DNS.
+	mdnsIPv4Group = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: mdnsPort}
+	mdnsIPv6Group = &net.UDPAddr{IP: net.ParseIP("ff02::fb"), Port: mdnsPort}
+
+	// errMalformedDNSMessage indicates a multicast DNS message could not
+	// be parsed.
+	errMalformedDNSMessage = errors.New("malformed DNS message")
+)
+
+// mdnsService advertises the node on the local network and discovers the other
+// nodes on the same network using multicast DNS service discovery.
+//
+// Each node advertises a service instance with a random name.  The SRV record
+// of the instance holds the listening port and its TXT record the name of the
+// network, so nodes only connect to nodes on the same network.  The address of
+// a discovered node is the source address of its response, so no address
+// records are advertised.
+type mdnsService struct {
+	instance string
+	network  string
+	port     uint16
+	found    func(instance string, addr *net.TCPAddr)
+}
+
+// newMDNSService returns a service which advertises the passed listening port
+// for the passed network, or which only discovers other nodes when the port
+// is zero.  The passed function is called with the instance name and address
+// of every node discovered.
+func newMDNSService(network string, port uint16,
+	found func(instance string, addr *net.TCPAddr)) (*mdnsService, error) {
+
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+	return &mdnsService{
+		instance: "btcd-" + hex.EncodeToString(id[:]),
+		network:  network,
+		port:     port,
+		found:    found,
+	}, nil
+}
+
+// encodeDNSName encodes the passed domain name without compression.
+func encodeDNSName(name string) []byte {
+	encoded := make([]byte, 0, len(name)+2)
+	for _, label := range strings.Split(name, ".") {
+		encoded = append(encoded, byte(len(label)))
+		encoded = append(encoded, label...)
+	}
+	return append(encoded, 0)
+}
+
+// readDNSName reads the possibly compressed domain name starting at the passed
+// offset of the message and returns it in lower case along with the offset
+// directly following it.
+func readDNSName(msg []byte, offset int) (string, int, error) {
+	var labels []string
+	next := -1
+	for pointers := 0; ; {
+		if offset >= len(msg) {
+			return "", 0, errMalformedDNSMessage
+		}
+		labelLen := int(msg[offset])
+		switch {
+		case labelLen == 0:
+			if next == -1 {
+				next = offset + 1
+			}
+			name := strings.ToLower(strings.Join(labels, "."))
+			return name, next, nil
+
+		case labelLen&0xc0 == 0xc0:
+			if offset+2 > len(msg) || pointers == dnsMaxPointers {
+				return "", 0, errMalformedDNSMessage
+			}
+			if next == -1 {
+				next = offset + 2
+			}
+			offset = int(binary.BigEndian.Uint16(msg[offset:]) & 0x3fff)
+			pointers++
+
+		case labelLen&0xc0 != 0 || offset+1+labelLen > len(msg):
+			return "", 0, errMalformedDNSMessage
+
+		default:
+			labels = append(labels, string(msg[offset+1:offset+1+labelLen]))
+			offset += 1 + labelLen
+		}
+	}
+}
+
+// instanceName returns the name of the service instance of the node.
+func (m *mdnsService) instanceName() string {
+	return m.instance + "." + mdnsServiceName
+}
+
+// query returns a query for the instances of the service.
+func (m *mdnsService) query() []byte {
+	msg := make([]byte, dnsHeaderLen)
+	binary.BigEndian.PutUint16(msg[4:6], 1)
+	msg = append(msg, encodeDNSName(mdnsServiceName)...)
+	msg = binary.BigEndian.AppendUint16(msg, dnsTypePTR)
+	return binary.BigEndian.AppendUint16(msg, dnsClassIN)
+}
+
+// appendRecord appends a record with the passed name, type, class and data to
+// the passed message.
+func appendRecord(msg []byte, name string, rtype, class uint16,
+	data []byte) []byte {
+
+	msg = append(msg, encodeDNSName(name)...)
+	msg = binary.BigEndian.AppendUint16(msg, rtype)
+	msg = binary.BigEndian.AppendUint16(msg, class)
+	msg = binary.BigEndian.AppendUint32(msg, mdnsTTL)
+	msg = binary.BigEndian.AppendUint16(msg, uint16(len(data)))
+	return append(msg, data...)
+}
+
+// response returns the response advertising the service instance of the node.
+func (m *mdnsService) response() []byte {
+	msg := make([]byte, dnsHeaderLen)
+	binary.BigEndian.PutUint16(msg[2:4], dnsFlagResponse|dnsFlagAuthority)
+	binary.BigEndian.PutUint16(msg[6:8], 3)
+
+	instance := m.instanceName()
+	msg = appendRecord(msg, mdnsServiceName, dnsTypePTR, dnsClassIN,
+		encodeDNSName(instance))
+
+	srv := make([]byte, 6, 6+len(m.instance)+8)
+	binary.BigEndian.PutUint16(srv[4:6], m.port)
+	srv = append(srv, encodeDNSName(m.instance+".local")...)
+	msg = appendRecord(msg, instance, dnsTypeSRV,
+		dnsClassIN|dnsCacheFlush, srv)
+
+	txt := "net=" + m.network
+	msg = appendRecord(msg, instance, dnsTypeTXT, dnsClassIN|dnsCacheFlush,
+		append([]byte{byte(len(txt))}, txt...))
+	return msg
+}
+
+// isServiceInstance returns the label of the service instance with the
+// passed name and whether it is an instance of the service.
+func isServiceInstance(name string) (string, bool) {
+	label, found := strings.CutSuffix(name, "."+mdnsServiceName)
+	if !found || label == "" || strings.Contains(label, ".") {
+		return "", false
+	}
+	return label, true
+}
+
+// txtNetwork returns the network listed by the passed TXT record data.
+func txtNetwork(data []byte) string {
+	for len(data) > 0 {
+		entryLen := int(data[0])
+		if 1+entryLen > len(data) {
+			break
+		}
+		entry := string(data[1 : 1+entryLen])
+		if network, found := strings.CutPrefix(entry, "net="); found {
+			return network
+		}
+		data = data[1+entryLen:]
+	}
+	return ""
+}
+
+// handleMessage handles the passed multicast DNS message received from the
+// passed address.  It returns whether the service instance of the node must be
+// advertised in response to it.
+func (m *mdnsService) handleMessage(msg []byte, src *net.UDPAddr) (bool,
+	error) {
+
+	if len(msg) < dnsHeaderLen {
+		return false, errMalformedDNSMessage
+	}
+	flags := binary.BigEndian.Uint16(msg[2:4])
+	numQuestions := int(binary.BigEndian.Uint16(msg[4:6]))
+	numRecords := int(binary.BigEndian.Uint16(msg[6:8])) +
+		int(binary.BigEndian.Uint16(msg[8:10])) +
+		int(binary.BigEndian.Uint16(msg[10:12]))
+
+	// Answer queries for the service when the node is listening.
+	offset := dnsHeaderLen
+	if flags&dnsFlagResponse == 0 {
+		for i := 0; i < numQuestions; i++ {
+			name, next, err := readDNSName(msg, offset)
+			if err != nil || next+4 > len(msg) {
+				return false, errMalformedDNSMessage
+			}
+			qtype := binary.BigEndian.Uint16(msg[next:])
+			if name == mdnsServiceName && m.port != 0 &&
+				(qtype == dnsTypePTR || qtype == dnsTypeANY) {
+
+				return true, nil
+			}
+			offset = next + 4
+		}
+		return false, nil
+	}
+
+	// Collect the ports and networks of the service instances in the
+	// response.
+	for i := 0; i < numQuestions; i++ {
+		_, next, err := readDNSName(msg, offset)
+		if err != nil || next+4 > len(msg) {
+			return false, errMalformedDNSMessage
+		}
+		offset = next + 4
+	}
+	ports := make(map[string]uint16)
+	networks := make(map[string]string)
+	for i := 0; i < numRecords; i++ {
+		name, next, err := readDNSName(msg, offset)
+		if err != nil || next+10 > len(msg) {
+			return false, errMalformedDNSMessage
+		}
+		rtype := binary.BigEndian.Uint16(msg[next:])
+		dataLen := int(binary.BigEndian.Uint16(msg[next+8:]))
+		offset = next + 10 + dataLen
+		if offset > len(msg) {
+			return false, errMalformedDNSMessage
+		}
+		data := msg[next+10 : offset]
+
+		instance, ok := isServiceInstance(name)
+		if !ok || instance == m.instance {
+			continue
+		}
+		switch {
+		case rtype == dnsTypeSRV && dataLen >= 6:
+			ports[instance] = binary.BigEndian.Uint16(data[4:6])
+		case rtype == dnsTypeTXT:
+			networks[instance] = txtNetwork(data)
+		}
+	}
+
+	for instance, port := range ports {
+		if port == 0 || networks[instance] != m.network {
+			continue
+		}
+		m.found(instance, &net.TCPAddr{
+			IP:   src.IP,
+			Port: int(port),
+			Zone: src.Zone,
+		})
+	}
+	return false, nil
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net"
+	"testing"
+)
+
+// TestMDNSDiscovery ensures nodes answer queries for the service with their
+// instance and discover the other nodes of the same network from their
+// responses.
+func TestMDNSDiscovery(t *testing.T) {
+	type discovery struct {
+		instance string
+		addr     *net.TCPAddr
+	}
+	newService := func(network string, port uint16) (*mdnsService,
+		*[]discovery) {
+
+		var found []discovery
+		m, err := newMDNSService(network, port,
+			func(instance string, addr *net.TCPAddr) {
+				found = append(found, discovery{instance, addr})
+			})
+		if err != nil {
+			t.Fatalf("newMDNSService: %v", err)
+		}
+		return m, &found
+	}
+
+	src := &net.UDPAddr{IP: net.ParseIP("192.168.1.20"), Port: mdnsPort}
+	node, nodeFound := newService("mainnet", 9246)
+	other, otherFound := newService("mainnet", 19246)
+	testnet, _ := newService("testnet3", 9246)
+	discoverOnly, _ := newService("mainnet", 0)
+
+	// Nodes which are listening answer queries.
+	respond, err := node.handleMessage(other.query(), src)
+	if err != nil || !respond {
+		t.Fatalf("handleMessage(query): got (%v, %v), want (true, nil)",
+			respond, err)
+	}
+	respond, err = discoverOnly.handleMessage(other.query(), src)
+	if err != nil || respond {
+		t.Fatalf("handleMessage(query): got (%v, %v), want (false, nil)",
+			respond, err)
+	}
+
+	// Nodes of the same network are discovered at the source address of
+	// their response with the advertised port.
+	if _, err := other.handleMessage(node.response(), src); err != nil {
+		t.Fatalf("handleMessage(response): %v", err)
+	}
+	if len(*otherFound) != 1 || (*otherFound)[0].instance != node.instance ||
+		(*otherFound)[0].addr.String() != "192.168.1.20:9246" {
+
+		t.Fatalf("unexpected discovered nodes %+v", *otherFound)
+	}
+
+	// The own response and the responses of nodes of other networks and
+	// nodes which are not listening are ignored.
+	for _, m := range []*mdnsService{node, testnet, discoverOnly} {
+		if _, err := node.handleMessage(m.response(), src); err != nil {
+			t.Fatalf("handleMessage(response): %v", err)
+		}
+	}
+	if len(*nodeFound) != 0 {
+		t.Fatalf("unexpected discovered nodes %+v", *nodeFound)
+	}
+
+	// Truncated messages are rejected.
+	response := node.response()
+	for _, n := range []int{5, dnsHeaderLen + 10, len(response) - 1} {
+		if _, err := other.handleMessage(response[:n], src); err == nil {
+			t.Fatalf("handleMessage(response[:%d]): no error", n)
+		}
+	}
+}
+
+// TestReadDNSName ensures compressed domain names are read and pointer loops
+// are rejected.
+func TestReadDNSName(t *testing.T) {
+	msg := make([]byte, dnsHeaderLen)
+	msg = append(msg, encodeDNSName("_BTCD._tcp.local")...)
+	compressed := len(msg)
+	msg = append(msg, 4, 'n', 'o', 'd', 'e', 0xc0, dnsHeaderLen)
+	loop := len(msg)
+	msg = append(msg, 0xc0, byte(loop))
+
+	name, next, err := readDNSName(msg, dnsHeaderLen)
+	if err != nil || name != mdnsServiceName || next != compressed {
+		t.Fatalf("readDNSName: got (%q, %d, %v)", name, next, err)
+	}
+	name, next, err = readDNSName(msg, compressed)
+	if err != nil || name != "node."+mdnsServiceName || next != loop {
+		t.Fatalf("readDNSName: got (%q, %d, %v)", name, next, err)
+	}
+	if _, _, err := readDNSName(msg, loop); err == nil {
+		t.Fatal("readDNSName: no error for pointer loop")
+	}
+}
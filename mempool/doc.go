@@ -48,6 +48,8 @@ be an exhaustive list.
     2. Automatic addition of orphan transactions that are no longer orphans as new
     transactions are added to the pool
     3. Individual orphan transaction query support
+    4. Per-peer orphan quotas, total weight caps, and expiration
+    5. Orphan pool statistics and eviction counters
   - Configurable transaction acceptance policy
     1. Option to accept or reject standard transactions
     2. Option to accept or reject transactions based on priority calculations
@@ -56,6 +58,12 @@ be an exhaustive list.
     5. Max signature operations per transaction
     6. Max orphan transaction size
     7. Max number of orphan transactions allowed
+    8. Max number of orphan transactions allowed per peer
+    9. Max total weight of orphan transactions allowed
+    10. Configurable null data size, standard weight, dust fee, and bare
+    multisig acceptance
+    11. Fee rates based on the virtual size adjusted for the signature
+    operation cost
   - Additional metadata tracking for each transaction
     1. Timestamp when the transaction was added to the pool
     2. Most recent block height when the transaction was added to the pool
@@ -63,6 +71,11 @@ be an exhaustive list.
     4. The starting priority for the transaction
   - Manual control of transaction removal
     1. Recursive removal of all dependent transactions
+  - Expiration of transactions which remain in the pool too long
+  - Notifications for accepted, mined, conflicting, expired, and replaced
+    transactions
+  - Recording of properly signed double spends of non-replaceable transactions
+  - Policy hooks which may reject or annotate transactions before acceptance
 
 # Errors
 
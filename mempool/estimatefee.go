@@ -16,6 +16,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/btcsuite/btcd/blockchain"
 	"github.com/btcsuite/btcd/btcutil/v2"
 	"github.com/btcsuite/btcd/chainhash/v2"
 	"github.com/btcsuite/btcd/mining"
@@ -45,6 +46,15 @@ const (
 	// it will provide fee estimations.
 	DefaultEstimateFeeMinRegisteredBlocks = 3
 
+	// EstimateFeeMaxBlocks is the maximum number of blocks from now for
+	// which the fee estimator can provide fee estimations.
+	EstimateFeeMaxBlocks = estimateFeeDepth
+
+	// DefaultEstimateFeeBackfillDepth is the default number of recent
+	// blocks used to back-fill a fee estimator which has no data about
+	// them.
+	DefaultEstimateFeeBackfillDepth = estimateFeeDepth
+
 	bytePerKb = 1000
 
 	btcPerSatoshi = 1e-8
@@ -208,7 +218,7 @@ func (ef *FeeEstimator) ObserveTransaction(t *TxDesc) {
 
 	hash := *t.Tx.Hash()
 	if _, ok := ef.observed[hash]; !ok {
-		size := uint32(GetTxVirtualSize(t.Tx))
+		size := uint32(t.VirtualSize)
 
 		ef.observed[hash] = &observedTransaction{
 			hash:     hash,
@@ -224,6 +234,14 @@ func (ef *FeeEstimator) RegisterBlock(block *btcutil.Block) error {
 	ef.mtx.Lock()
 	defer ef.mtx.Unlock()
 
+	return ef.registerBlock(block)
+}
+
+// registerBlock is the internal function which implements the public
+// RegisterBlock.  See the comment for RegisterBlock for more details.
+//
+// This function MUST be called with the fee estimator lock held.
+func (ef *FeeEstimator) registerBlock(block *btcutil.Block) error {
 	// The previous sorted list is invalid, so delete it.
 	ef.cached = nil
 
@@ -335,6 +353,83 @@ func (ef *FeeEstimator) RegisterBlock(block *btcutil.Block) error {
 	return nil
 }
 
+// BackfillBlock informs the fee estimator of a block whose transactions were
+// not observed in the mempool, such as recent blocks connected while the node
+// was not running.  The spent outputs of the block, as returned by the spend
+// journal, are used to calculate the fee rates of its transactions.
+//
+// Since it is unknown when those transactions were first seen, they are
+// treated as having been observed in the block prior to the one they were
+// mined in.  That biases the estimates low, because the transactions which
+// actually waited several blocks count as confirming in the next one.  To
+// discount that, only the half of the transactions paying the highest fee
+// rates, which are the most likely to have confirmed in the next block, are
+// back-filled.  The remaining bias is preferable to providing no estimates at
+// all until enough blocks have been observed, and it fades as observed blocks
+// replace the back-filled transactions.  Transactions that were observed in
+// the mempool retain their actual observation height.
+func (ef *FeeEstimator) BackfillBlock(block *btcutil.Block,
+	stxos []blockchain.SpentTxOut) error {
+
+	ef.mtx.Lock()
+	defer ef.mtx.Unlock()
+
+	height := block.Height()
+	var candidates []*observedTransaction
+	var stxoIdx int
+	for _, tx := range block.Transactions()[1:] {
+		var totalIn int64
+		for range tx.MsgTx().TxIn {
+			if stxoIdx >= len(stxos) {
+				return fmt.Errorf("spent outputs of block %v "+
+					"do not match its inputs", block.Hash())
+			}
+			totalIn += stxos[stxoIdx].Amount
+			stxoIdx++
+		}
+		var totalOut int64
+		for _, txOut := range tx.MsgTx().TxOut {
+			totalOut += txOut.Value
+		}
+
+		hash := *tx.Hash()
+		if _, ok := ef.observed[hash]; ok {
+			continue
+		}
+		size := uint32(GetTxVirtualSize(tx))
+		candidates = append(candidates, &observedTransaction{
+			hash:     hash,
+			feeRate:  NewSatoshiPerByte(btcutil.Amount(totalIn-totalOut), size),
+			observed: height - 1,
+			mined:    mining.UnminedHeight,
+		})
+	}
+
+	// Only back-fill the half of the transactions paying the highest fee
+	// rates, rounded up so a lone transaction is included.
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].feeRate > candidates[j].feeRate
+	})
+	candidates = candidates[:(len(candidates)+1)/2]
+	backfilled := make([]chainhash.Hash, 0, len(candidates))
+	for _, o := range candidates {
+		ef.observed[o.hash] = o
+		backfilled = append(backfilled, o.hash)
+	}
+
+	err := ef.registerBlock(block)
+
+	// Forget about the back-filled transactions which did not make it into
+	// a bin since they will never be mined again.
+	for _, hash := range backfilled {
+		if o, ok := ef.observed[hash]; ok && o.mined == mining.UnminedHeight {
+			delete(ef.observed, hash)
+		}
+	}
+
+	return err
+}
+
 // LastKnownHeight returns the height of the last block which was registered.
 func (ef *FeeEstimator) LastKnownHeight() int32 {
 	ef.mtx.Lock()
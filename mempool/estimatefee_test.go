@@ -9,6 +9,7 @@ import (
 	"math/rand"
 	"testing"
 
+	"github.com/btcsuite/btcd/blockchain"
 	"github.com/btcsuite/btcd/btcutil/v2"
 	"github.com/btcsuite/btcd/chainhash/v2"
 	"github.com/btcsuite/btcd/mining"
@@ -48,15 +49,17 @@ type estimateFeeTester struct {
 
 func (eft *estimateFeeTester) testTx(fee btcutil.Amount) *TxDesc {
 	eft.version++
+	tx := btcutil.NewTx(&wire.MsgTx{
+		Version: eft.version,
+	})
 	return &TxDesc{
 		TxDesc: mining.TxDesc{
-			Tx: btcutil.NewTx(&wire.MsgTx{
-				Version: eft.version,
-			}),
+			Tx:     tx,
 			Height: eft.height,
 			Fee:    int64(fee),
 		},
 		StartingPriority: 0,
+		VirtualSize:      GetTxVirtualSize(tx),
 	}
 }
 
@@ -422,3 +425,88 @@ func TestDatabase(t *testing.T) {
 		eft.checkSaveAndRestore(estimateHistory[len(estimateHistory)-round-1])
 	}
 }
+
+// TestBackfillBlock ensures that blocks whose transactions were not observed
+// in the mempool can be used to provide fee estimates.
+func TestBackfillBlock(t *testing.T) {
+	ef := NewFeeEstimator(DefaultEstimateFeeMaxRollback,
+		DefaultEstimateFeeMinRegisteredBlocks)
+
+	// newBackfillBlock returns a block at the given height containing a
+	// transaction paying the given fee along with its spent outputs.
+	const inputValue = 100000
+	newBackfillBlock := func(height int32, fee int64) (*btcutil.Block,
+		[]blockchain.SpentTxOut) {
+
+		coinbase := wire.NewMsgTx(wire.TxVersion)
+		coinbase.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: wire.OutPoint{Index: wire.MaxPrevOutIndex},
+			SignatureScript:  []byte{byte(height)},
+		})
+		coinbase.AddTxOut(&wire.TxOut{Value: 5000000000})
+
+		tx := wire.NewMsgTx(wire.TxVersion)
+		tx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: wire.OutPoint{Index: uint32(height)},
+		})
+		tx.AddTxOut(&wire.TxOut{Value: inputValue - fee})
+
+		block := btcutil.NewBlock(&wire.MsgBlock{
+			Transactions: []*wire.MsgTx{coinbase, tx},
+		})
+		block.SetHeight(height)
+		stxos := []blockchain.SpentTxOut{{Amount: inputValue}}
+		return block, stxos
+	}
+
+	var feeRate SatoshiPerByte
+	for height := int32(1); height <= 3; height++ {
+		if _, err := ef.EstimateFee(1); err == nil {
+			t.Fatalf("estimate provided after %d blocks", height-1)
+		}
+
+		block, stxos := newBackfillBlock(height, 1000)
+		if err := ef.BackfillBlock(block, stxos); err != nil {
+			t.Fatalf("BackfillBlock: %v", err)
+		}
+		size := uint32(GetTxVirtualSize(block.Transactions()[1]))
+		feeRate = NewSatoshiPerByte(1000, size)
+	}
+
+	estimate, err := ef.EstimateFee(1)
+	if err != nil {
+		t.Fatalf("EstimateFee: %v", err)
+	}
+	if estimate != feeRate.ToBtcPerKb() {
+		t.Fatalf("estimated fee %v, want %v", estimate,
+			feeRate.ToBtcPerKb())
+	}
+
+	// Only the transactions paying the highest fee rates of a block are
+	// back-filled.
+	block, stxos := newBackfillBlock(4, 2000)
+	lowFee, _ := newBackfillBlock(5, 100)
+	block.MsgBlock().Transactions = append(block.MsgBlock().Transactions,
+		lowFee.MsgBlock().Transactions[1])
+	block = btcutil.NewBlock(block.MsgBlock())
+	block.SetHeight(4)
+	stxos = append(stxos, blockchain.SpentTxOut{Amount: inputValue})
+	if err := ef.BackfillBlock(block, stxos); err != nil {
+		t.Fatalf("BackfillBlock: %v", err)
+	}
+	for _, o := range ef.bin[0] {
+		if o.hash == *block.Transactions()[2].Hash() {
+			t.Fatal("BackfillBlock: low fee rate transaction " +
+				"back-filled")
+		}
+	}
+	if len(ef.bin[0]) != 4 {
+		t.Fatalf("bin has %d transactions, want 4", len(ef.bin[0]))
+	}
+
+	// Blocks whose spent outputs don't match their inputs are rejected.
+	block, _ = newBackfillBlock(6, 1000)
+	if err := ef.BackfillBlock(block, nil); err == nil {
+		t.Fatal("BackfillBlock: missing spent outputs accepted")
+	}
+}
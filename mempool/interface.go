@@ -68,4 +68,18 @@ type TxMempool interface {
 	// a transaction in the mempool. If that's the case the spending
 	// transaction will be returned, if not nil will be returned.
 	CheckSpend(op wire.OutPoint) *btcutil.Tx
+
+	// DoubleSpends returns the recorded transactions which were rejected
+	// for double spending outputs spent by the passed transaction in the
+	// pool.  The second return value is false when the transaction is not
+	// in the pool.
+	DoubleSpends(hash *chainhash.Hash) ([]*DoubleSpend, bool)
+
+	// OrphanStats returns statistics about the current state of the
+	// orphan pool along with counters of the orphans evicted from it.
+	OrphanStats() OrphanStats
+
+	// Subscribe registers a callback to be executed when transactions
+	// are added to or removed from the mempool.
+	Subscribe(callback NotificationCallback)
 }
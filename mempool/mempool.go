@@ -54,6 +54,20 @@ const (
 	// Transactions smaller than 65 non-witness bytes are not relayed to
 	// mitigate CVE-2017-12842.
 	MinStandardTxNonWitnessSize = 65
+
+	// maxDoubleSpendsPerTx is the maximum number of distinct double
+	// spending transactions that are recorded for a single transaction in
+	// the pool.  Further double spends are still rejected, but are not
+	// recorded or announced.
+	maxDoubleSpendsPerTx = 10
+
+	// maxDoubleSpendChecks is the limit of the exponentially decaying
+	// number of rejected double spends whose scripts are validated in
+	// order to record them.  The count decays with a one minute window,
+	// which allows bursts of this many checks and sustained rates of
+	// about this many per minute.  Double spends beyond the limit are
+	// still rejected, but are not recorded or announced.
+	maxDoubleSpendChecks = 100
 )
 
 // Tag represents an identifier to use for tagging orphan transactions.  The
@@ -109,6 +123,11 @@ type Config struct {
 	// FeeEstimator provides a feeEstimator. If it is not nil, the mempool
 	// records all new transactions it observes into the feeEstimator.
 	FeeEstimator *FeeEstimator
+
+	// PolicyHooks defines the policy hooks which are consulted, in order,
+	// before a transaction is accepted into the pool.  Any of them may
+	// reject or annotate the transaction.
+	PolicyHooks []*PolicyHook
 }
 
 // Policy houses the policy (configuration parameters) which is used to
@@ -141,11 +160,38 @@ type Policy struct {
 	// of big orphans.
 	MaxOrphanTxSize int
 
+	// MaxOrphanTxsPerTag is the maximum number of orphan transactions
+	// that can be queued for a single tag, which is typically the peer
+	// that relayed them.  The oldest orphan for the tag is evicted to make
+	// room for a new one once the limit is reached.  A value of zero
+	// means there is no per-tag limit.
+	MaxOrphanTxsPerTag int
+
+	// MaxOrphanWeight is the maximum total weight of all orphan
+	// transactions that can be queued.  A value of zero means there is no
+	// limit on the total weight.
+	MaxOrphanWeight int64
+
+	// OrphanTTL is the maximum amount of time an orphan is allowed to stay
+	// in the orphan pool before it expires and is evicted.  The default
+	// of 15 minutes is used when it is zero.
+	OrphanTTL time.Duration
+
+	// TxExpiry is the maximum amount of time a transaction is allowed to
+	// stay in the main pool before it is evicted along with any
+	// transactions which depend on it.  A value of zero disables expiry.
+	TxExpiry time.Duration
+
 	// MaxSigOpCostPerTx is the cumulative maximum cost of all the signature
 	// operations in a single transaction we will relay or mine.  It is a
 	// fraction of the max signature operations for a block.
 	MaxSigOpCostPerTx int
 
+	// BytesPerSigOp is the number of virtual bytes each unit of signature
+	// operation cost is considered to occupy when calculating the virtual
+	// size used for fee rates.  See GetSigOpAdjustedVirtualSize.
+	BytesPerSigOp int
+
 	// MinRelayTxFee defines the minimum transaction fee in BTC/kB to be
 	// considered a non-zero fee.
 	MinRelayTxFee btcutil.Amount
@@ -154,6 +200,49 @@ type Policy struct {
 	// transactions using the Replace-By-Fee (RBF) signaling policy into
 	// the mempool.
 	RejectReplacement bool
+
+	// DustRelayFee defines the fee rate in BTC/kB that is used to
+	// determine whether or not a transaction output is considered dust.
+	// When it is zero, MinRelayTxFee is used instead.
+	DustRelayFee btcutil.Amount
+
+	// MaxStandardTxWeight is the maximum weight permitted for a
+	// transaction to be considered standard.
+	MaxStandardTxWeight int
+
+	// MaxDataCarrierSize is the maximum number of bytes that may be
+	// pushed by a null data (OP_RETURN) output for it to be considered
+	// standard.
+	MaxDataCarrierSize int
+
+	// AcceptClaimScripts, if true, accepts transactions with claim,
+	// support or update outputs as standard.  They are non-standard
+	// otherwise since the script engine doesn't strip the claim prefix
+	// and therefore can't verify spends of these outputs.
+	AcceptClaimScripts bool
+
+	// MaxClaimValueSize is the maximum number of bytes the value of a
+	// claim, support or update output may have for it to be considered
+	// standard when AcceptClaimScripts is set.
+	MaxClaimValueSize int
+
+	// CheckClaimMetadata, if true, rejects transactions with claim,
+	// support or update outputs whose values aren't well-formed LBRY
+	// metadata as non-standard.
+	CheckClaimMetadata bool
+
+	// RejectBareMultisig, if true, rejects transactions which contain
+	// bare (non-P2SH) multi-signature outputs as non-standard.
+	RejectBareMultisig bool
+}
+
+// dustRelayFee returns the fee rate used to determine whether or not a
+// transaction output is considered dust.
+func (p *Policy) dustRelayFee() btcutil.Amount {
+	if p.DustRelayFee == 0 {
+		return p.MinRelayTxFee
+	}
+	return p.DustRelayFee
 }
 
 // TxDesc is a descriptor containing a transaction in the mempool along with
@@ -164,6 +253,17 @@ type TxDesc struct {
 	// StartingPriority is the priority of the transaction when it was added
 	// to the pool.
 	StartingPriority float64
+
+	// VirtualSize is the sigop-adjusted virtual size of the transaction
+	// which is used to calculate its fee rate.
+	VirtualSize int64
+
+	// SigOpCost is the total signature operation cost of the transaction.
+	SigOpCost int64
+
+	// Annotations are the notes attached to the transaction by the policy
+	// hooks when it was added to the pool.
+	Annotations []string
 }
 
 // orphanTx is normal transaction that references an ancestor transaction
@@ -172,9 +272,52 @@ type TxDesc struct {
 type orphanTx struct {
 	tx         *btcutil.Tx
 	tag        Tag
+	weight     int64
 	expiration time.Time
 }
 
+// OrphanStats houses statistics about the orphan pool along with counters of
+// the orphans that have been evicted from it.
+type OrphanStats struct {
+	// Count is the number of orphans currently in the pool.
+	Count int
+
+	// Weight is the total weight of all orphans currently in the pool.
+	Weight int64
+
+	// NumTags is the number of distinct tags (typically peers) that have
+	// orphans in the pool.
+	NumTags int
+
+	// Expired is the total number of orphans that have been evicted
+	// because they stayed in the pool longer than the orphan TTL.
+	Expired uint64
+
+	// EvictedTagLimit is the total number of orphans that have been
+	// evicted to keep a single tag within its orphan quota.
+	EvictedTagLimit uint64
+
+	// EvictedPoolLimit is the total number of orphans that have been
+	// evicted to keep the pool within its count and weight limits.
+	EvictedPoolLimit uint64
+}
+
+// DoubleSpend describes a transaction which was rejected because it spends
+// outputs already spent by a transaction in the pool which can't be replaced,
+// either because it doesn't signal replacement or because replacement is
+// disabled by the policy.
+type DoubleSpend struct {
+	// Tx is the rejected transaction.
+	Tx *btcutil.Tx
+
+	// Seen is the time the rejected transaction was received.
+	Seen time.Time
+
+	// Outpoints are the outputs spent by both the rejected transaction
+	// and the transaction in the pool.
+	Outpoints []wire.OutPoint
+}
+
 // TxPool is used as a source of transactions that need to be mined into blocks
 // and relayed to other peers.  It is safe for concurrent access from multiple
 // peers.
@@ -187,15 +330,30 @@ type TxPool struct {
 	pool          map[chainhash.Hash]*TxDesc
 	orphans       map[chainhash.Hash]*orphanTx
 	orphansByPrev map[wire.OutPoint]map[chainhash.Hash]*btcutil.Tx
+	orphansByTag  map[Tag]map[chainhash.Hash]*orphanTx
+	orphanWeight  int64
+	orphanStats   OrphanStats
 	outpoints     map[wire.OutPoint]*btcutil.Tx
+	doubleSpends  map[chainhash.Hash][]*DoubleSpend
 	pennyTotal    float64 // exponentially decaying total for penny spends.
 	lastPennyUnix int64   // unix time of last ``penny spend''
 
+	// doubleSpendChecks is the exponentially decaying number of rejected
+	// double spends whose scripts were validated, as of the unix time
+	// lastDoubleSpendCheck.  See maxDoubleSpendChecks.
+	doubleSpendChecks    float64
+	lastDoubleSpendCheck int64
+
 	// nextExpireScan is the time after which the orphan pool will be
 	// scanned in order to evict orphans.  This is NOT a hard deadline as
 	// the scan will only run when an orphan is added to the pool as opposed
 	// to on an unconditional timer.
 	nextExpireScan time.Time
+
+	// The following fields are used to deliver notifications to
+	// subscribers.  See Subscribe.
+	notificationsLock sync.RWMutex
+	notifications     []NotificationCallback
 }
 
 // Ensure the TxPool type implements the mining.TxSource interface.
@@ -241,7 +399,16 @@ func (mp *TxPool) removeOrphan(tx *btcutil.Tx, removeRedeemers bool) {
 		}
 	}
 
+	// Remove the reference from the tag index.
+	if tagOrphans, exists := mp.orphansByTag[otx.tag]; exists {
+		delete(tagOrphans, *txHash)
+		if len(tagOrphans) == 0 {
+			delete(mp.orphansByTag, otx.tag)
+		}
+	}
+
 	// Remove the transaction from the orphan pool.
+	mp.orphanWeight -= otx.weight
 	delete(mp.orphans, *txHash)
 }
 
@@ -262,67 +429,145 @@ func (mp *TxPool) RemoveOrphan(tx *btcutil.Tx) {
 func (mp *TxPool) RemoveOrphansByTag(tag Tag) uint64 {
 	var numEvicted uint64
 	mp.mtx.Lock()
-	for _, otx := range mp.orphans {
-		if otx.tag == tag {
-			mp.removeOrphan(otx.tx, true)
-			numEvicted++
+	for _, otx := range mp.orphansByTag[tag] {
+		// The orphan might have already been removed as a redeemer
+		// of a previously removed orphan.
+		if _, exists := mp.orphans[*otx.tx.Hash()]; !exists {
+			continue
 		}
+		mp.removeOrphan(otx.tx, true)
+		numEvicted++
 	}
 	mp.mtx.Unlock()
 	return numEvicted
 }
 
-// limitNumOrphans limits the number of orphan transactions by evicting a random
-// orphan if adding a new one would cause it to overflow the max allowed.
+// orphanLifetime returns the maximum amount of time an orphan is allowed to
+// stay in the orphan pool according to the policy.
+func (mp *TxPool) orphanLifetime() time.Duration {
+	if mp.cfg.Policy.OrphanTTL > 0 {
+		return mp.cfg.Policy.OrphanTTL
+	}
+	return orphanTTL
+}
+
+// expireOrphans removes any orphans which have expired from the orphan pool.
+// This is done for efficiency so the scan only happens periodically instead of
+// on every orphan added to the pool.
 //
 // This function MUST be called with the mempool lock held (for writes).
-func (mp *TxPool) limitNumOrphans() error {
-	// Scan through the orphan pool and remove any expired orphans when it's
-	// time.  This is done for efficiency so the scan only happens
-	// periodically instead of on every orphan added to the pool.
-	if now := time.Now(); now.After(mp.nextExpireScan) {
-		origNumOrphans := len(mp.orphans)
-		for _, otx := range mp.orphans {
-			if now.After(otx.expiration) {
-				// Remove redeemers too because the missing
-				// parents are very unlikely to ever materialize
-				// since the orphan has already been around more
-				// than long enough for them to be delivered.
-				mp.removeOrphan(otx.tx, true)
-			}
+func (mp *TxPool) expireOrphans() {
+	now := time.Now()
+	if !now.After(mp.nextExpireScan) {
+		return
+	}
+
+	origNumOrphans := len(mp.orphans)
+	for _, otx := range mp.orphans {
+		// The orphan might have already been removed as a redeemer of
+		// a previously expired orphan.
+		if _, exists := mp.orphans[*otx.tx.Hash()]; !exists {
+			continue
+		}
+		if now.After(otx.expiration) {
+			// Remove redeemers too because the missing parents are
+			// very unlikely to ever materialize since the orphan
+			// has already been around more than long enough for
+			// them to be delivered.
+			mp.removeOrphan(otx.tx, true)
 		}
+	}
 
-		// Set next expiration scan to occur after the scan interval.
-		mp.nextExpireScan = now.Add(orphanExpireScanInterval)
+	// Set next expiration scan to occur after the scan interval.
+	mp.nextExpireScan = now.Add(orphanExpireScanInterval)
 
-		numOrphans := len(mp.orphans)
-		if numExpired := origNumOrphans - numOrphans; numExpired > 0 {
-			log.Debugf("Expired %d %s (remaining: %d)", numExpired,
-				pickNoun(numExpired, "orphan", "orphans"),
-				numOrphans)
+	numOrphans := len(mp.orphans)
+	if numExpired := origNumOrphans - numOrphans; numExpired > 0 {
+		mp.orphanStats.Expired += uint64(numExpired)
+		log.Debugf("Expired %d %s (remaining: %d)", numExpired,
+			pickNoun(numExpired, "orphan", "orphans"), numOrphans)
+	}
+}
+
+// oldestOrphan returns the orphan in the passed set which is closest to
+// expiring.  It returns nil when the set is empty.
+func oldestOrphan(orphans map[chainhash.Hash]*orphanTx) *orphanTx {
+	var oldest *orphanTx
+	for _, otx := range orphans {
+		if oldest == nil || otx.expiration.Before(oldest.expiration) {
+			oldest = otx
 		}
 	}
+	return oldest
+}
 
-	// Nothing to do if adding another orphan will not cause the pool to
-	// exceed the limit.
-	if len(mp.orphans)+1 <= mp.cfg.Policy.MaxOrphanTxs {
-		return nil
+// heaviestOrphanTag returns the tag whose orphans have the largest combined
+// weight in the orphan pool.
+//
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) heaviestOrphanTag() Tag {
+	var (
+		heaviestTag    Tag
+		heaviestWeight int64 = -1
+	)
+	for tag, orphans := range mp.orphansByTag {
+		var weight int64
+		for _, otx := range orphans {
+			weight += otx.weight
+		}
+		if weight > heaviestWeight {
+			heaviestTag, heaviestWeight = tag, weight
+		}
 	}
+	return heaviestTag
+}
 
-	// Remove a random entry from the map.  For most compilers, Go's
-	// range statement iterates starting at a random item although
-	// that is not 100% guaranteed by the spec.  The iteration order
-	// is not important here because an adversary would have to be
-	// able to pull off preimage attacks on the hashing function in
-	// order to target eviction of specific entries anyways.
-	for _, otx := range mp.orphans {
-		// Don't remove redeemers in the case of a random eviction since
-		// it is quite possible it might be needed again shortly.
-		mp.removeOrphan(otx.tx, false)
-		break
+// limitOrphans makes room for a new orphan with the passed tag and weight by
+// expiring old orphans and evicting others as needed to keep the orphan pool
+// within the configured per-tag quota, total count, and total weight limits.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) limitOrphans(tag Tag, weight int64) {
+	// Scan through the orphan pool and remove any expired orphans when
+	// it's time.
+	mp.expireOrphans()
+
+	// Evict the oldest orphans from the same tag when adding another one
+	// would exceed its quota.  This prevents a single peer from being
+	// able to crowd out the orphans relayed by everyone else.
+	maxPerTag := mp.cfg.Policy.MaxOrphanTxsPerTag
+	for maxPerTag > 0 && len(mp.orphansByTag[tag])+1 > maxPerTag {
+		// Don't remove redeemers in the case of an eviction since it
+		// is quite possible they might be needed again shortly.
+		mp.removeOrphan(oldestOrphan(mp.orphansByTag[tag]).tx, false)
+		mp.orphanStats.EvictedTagLimit++
+	}
+
+	// Remove random entries from the map while adding another orphan
+	// would cause the pool to exceed the max allowed.  For most compilers,
+	// Go's range statement iterates starting at a random item although
+	// that is not 100% guaranteed by the spec.  The iteration order is not
+	// important here because an adversary would have to be able to pull
+	// off preimage attacks on the hashing function in order to target
+	// eviction of specific entries anyways.
+	for len(mp.orphans) > 0 && len(mp.orphans)+1 > mp.cfg.Policy.MaxOrphanTxs {
+		for _, otx := range mp.orphans {
+			mp.removeOrphan(otx.tx, false)
+			break
+		}
+		mp.orphanStats.EvictedPoolLimit++
 	}
 
-	return nil
+	// Evict the oldest orphans of whichever tag is using the most weight
+	// while adding the new orphan would exceed the total weight allowed.
+	maxWeight := mp.cfg.Policy.MaxOrphanWeight
+	for maxWeight > 0 && len(mp.orphans) > 0 &&
+		mp.orphanWeight+weight > maxWeight {
+
+		heaviest := mp.orphansByTag[mp.heaviestOrphanTag()]
+		mp.removeOrphan(oldestOrphan(heaviest).tx, false)
+		mp.orphanStats.EvictedPoolLimit++
+	}
 }
 
 // addOrphan adds an orphan transaction to the orphan pool.
@@ -334,16 +579,20 @@ func (mp *TxPool) addOrphan(tx *btcutil.Tx, tag Tag) {
 		return
 	}
 
-	// Limit the number orphan transactions to prevent memory exhaustion.
-	// This will periodically remove any expired orphans and evict a random
-	// orphan if space is still needed.
-	mp.limitNumOrphans()
+	// Limit the orphan transactions to prevent memory exhaustion.  This
+	// will periodically remove any expired orphans and evict others if
+	// space is still needed.
+	weight := blockchain.GetTransactionWeight(tx)
+	mp.limitOrphans(tag, weight)
 
-	mp.orphans[*tx.Hash()] = &orphanTx{
+	otx := &orphanTx{
 		tx:         tx,
 		tag:        tag,
-		expiration: time.Now().Add(orphanTTL),
+		weight:     weight,
+		expiration: time.Now().Add(mp.orphanLifetime()),
 	}
+	mp.orphans[*tx.Hash()] = otx
+	mp.orphanWeight += weight
 	for _, txIn := range tx.MsgTx().TxIn {
 		if _, exists := mp.orphansByPrev[txIn.PreviousOutPoint]; !exists {
 			mp.orphansByPrev[txIn.PreviousOutPoint] =
@@ -351,9 +600,13 @@ func (mp *TxPool) addOrphan(tx *btcutil.Tx, tag Tag) {
 		}
 		mp.orphansByPrev[txIn.PreviousOutPoint][*tx.Hash()] = tx
 	}
+	if _, exists := mp.orphansByTag[tag]; !exists {
+		mp.orphansByTag[tag] = make(map[chainhash.Hash]*orphanTx)
+	}
+	mp.orphansByTag[tag][*tx.Hash()] = otx
 
-	log.Debugf("Stored orphan transaction %v (total: %d)", tx.Hash(),
-		len(mp.orphans))
+	log.Debugf("Stored orphan transaction %v (total: %d, weight: %d)",
+		tx.Hash(), len(mp.orphans), mp.orphanWeight)
 }
 
 // maybeAddOrphan potentially adds an orphan to the orphan pool.
@@ -378,6 +631,18 @@ func (mp *TxPool) maybeAddOrphan(tx *btcutil.Tx, tag Tag) error {
 		return txRuleError(wire.RejectNonstandard, str)
 	}
 
+	// Likewise, ignore orphans that would exceed the total weight allowed
+	// for the orphan pool on their own.
+	maxWeight := mp.cfg.Policy.MaxOrphanWeight
+	if weight := blockchain.GetTransactionWeight(tx); maxWeight > 0 &&
+		weight > maxWeight {
+
+		str := fmt.Sprintf("orphan transaction weight of %d is larger "+
+			"than max allowed orphan pool weight of %d", weight,
+			maxWeight)
+		return txRuleError(wire.RejectNonstandard, str)
+	}
+
 	// Add the orphan if the none of the above disqualified it.
 	mp.addOrphan(tx, tag)
 
@@ -450,6 +715,21 @@ func (mp *TxPool) IsOrphanInPool(hash *chainhash.Hash) bool {
 	return inPool
 }
 
+// OrphanStats returns statistics about the current state of the orphan pool
+// along with counters of the orphans evicted from it.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) OrphanStats() OrphanStats {
+	mp.mtx.RLock()
+	stats := mp.orphanStats
+	stats.Count = len(mp.orphans)
+	stats.Weight = mp.orphanWeight
+	stats.NumTags = len(mp.orphansByTag)
+	mp.mtx.RUnlock()
+
+	return stats
+}
+
 // haveTransaction returns whether or not the passed transaction already exists
 // in the main pool or in the orphan pool.
 //
@@ -474,15 +754,21 @@ func (mp *TxPool) HaveTransaction(hash *chainhash.Hash) bool {
 // removeTransaction is the internal function which implements the public
 // RemoveTransaction.  See the comment for RemoveTransaction for more details.
 //
+// The reason and conflict are passed along to subscribers in the resulting
+// notifications, including those for any removed redeemers.
+//
 // This function MUST be called with the mempool lock held (for writes).
-func (mp *TxPool) removeTransaction(tx *btcutil.Tx, removeRedeemers bool) {
+func (mp *TxPool) removeTransaction(tx *btcutil.Tx, removeRedeemers bool,
+	reason NotificationType, conflict *chainhash.Hash) {
+
 	txHash := tx.Hash()
 	if removeRedeemers {
 		// Remove any transactions which rely on this one.
 		for i := uint32(0); i < uint32(len(tx.MsgTx().TxOut)); i++ {
 			prevOut := wire.OutPoint{Hash: *txHash, Index: i}
 			if txRedeemer, exists := mp.outpoints[prevOut]; exists {
-				mp.removeTransaction(txRedeemer, true)
+				mp.removeTransaction(txRedeemer, true, reason,
+					conflict)
 			}
 		}
 	}
@@ -500,7 +786,10 @@ func (mp *TxPool) removeTransaction(tx *btcutil.Tx, removeRedeemers bool) {
 			delete(mp.outpoints, txIn.PreviousOutPoint)
 		}
 		delete(mp.pool, *txHash)
+		delete(mp.doubleSpends, *txHash)
 		atomic.StoreInt64(&mp.lastUpdated, time.Now().Unix())
+
+		mp.sendNotification(reason, txDesc, conflict)
 	}
 }
 
@@ -513,10 +802,62 @@ func (mp *TxPool) removeTransaction(tx *btcutil.Tx, removeRedeemers bool) {
 func (mp *TxPool) RemoveTransaction(tx *btcutil.Tx, removeRedeemers bool) {
 	// Protect concurrent access.
 	mp.mtx.Lock()
-	mp.removeTransaction(tx, removeRedeemers)
+	mp.removeTransaction(tx, removeRedeemers, NTTxRemoved, nil)
 	mp.mtx.Unlock()
 }
 
+// RemoveMinedTransaction removes the passed transaction, which has been
+// included in a block connected to the main chain, from the mempool.  It
+// differs from RemoveTransaction only in the notification sent to subscribers
+// and never removes redeemers since they remain valid.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) RemoveMinedTransaction(tx *btcutil.Tx) {
+	// Protect concurrent access.
+	mp.mtx.Lock()
+	mp.removeTransaction(tx, false, NTTxRemovedBlock, nil)
+	mp.mtx.Unlock()
+}
+
+// ExpireTransactions removes all transactions which have been in the mempool
+// longer than the expiry configured by the policy, along with any transactions
+// which depend on them, and returns the number of transactions removed.  It
+// does nothing when expiry is disabled.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) ExpireTransactions() int {
+	expiry := mp.cfg.Policy.TxExpiry
+	if expiry <= 0 {
+		return 0
+	}
+
+	// Protect concurrent access.
+	mp.mtx.Lock()
+	defer mp.mtx.Unlock()
+
+	cutoff := time.Now().Add(-expiry)
+	origNumTxns := len(mp.pool)
+	for _, txDesc := range mp.pool {
+		// The transaction might have already been removed as a
+		// redeemer of a previously expired transaction.
+		if _, exists := mp.pool[*txDesc.Tx.Hash()]; !exists {
+			continue
+		}
+		if txDesc.Added.Before(cutoff) {
+			mp.removeTransaction(txDesc.Tx, true,
+				NTTxRemovedExpiry, nil)
+		}
+	}
+
+	numExpired := origNumTxns - len(mp.pool)
+	if numExpired > 0 {
+		log.Debugf("Expired %d %s from the mempool (remaining: %d)",
+			numExpired, pickNoun(numExpired, "transaction",
+				"transactions"), len(mp.pool))
+	}
+	return numExpired
+}
+
 // RemoveDoubleSpends removes all transactions which spend outputs spent by the
 // passed transaction from the memory pool.  Removing those transactions then
 // leads to removing all transactions which rely on them, recursively.  This is
@@ -530,7 +871,8 @@ func (mp *TxPool) RemoveDoubleSpends(tx *btcutil.Tx) {
 	for _, txIn := range tx.MsgTx().TxIn {
 		if txRedeemer, ok := mp.outpoints[txIn.PreviousOutPoint]; ok {
 			if !txRedeemer.Hash().IsEqual(tx.Hash()) {
-				mp.removeTransaction(txRedeemer, true)
+				mp.removeTransaction(txRedeemer, true,
+					NTTxRemovedConflict, tx.Hash())
 			}
 		}
 	}
@@ -542,18 +884,22 @@ func (mp *TxPool) RemoveDoubleSpends(tx *btcutil.Tx) {
 // helper for maybeAcceptTransaction.
 //
 // This function MUST be called with the mempool lock held (for writes).
-func (mp *TxPool) addTransaction(utxoView *blockchain.UtxoViewpoint, tx *btcutil.Tx, height int32, fee int64) *TxDesc {
+func (mp *TxPool) addTransaction(tx *btcutil.Tx, r *MempoolAcceptResult) *TxDesc {
 	// Add the transaction to the pool and mark the referenced outpoints
 	// as spent by the pool.
+	utxoView, height, fee := r.utxoView, r.bestHeight, int64(r.TxFee)
 	txD := &TxDesc{
 		TxDesc: mining.TxDesc{
 			Tx:       tx,
 			Added:    time.Now(),
 			Height:   height,
 			Fee:      fee,
-			FeePerKB: fee * 1000 / GetTxVirtualSize(tx),
+			FeePerKB: fee * 1000 / r.TxSize,
 		},
 		StartingPriority: mining.CalcPriority(tx.MsgTx(), utxoView, height),
+		VirtualSize:      r.TxSize,
+		SigOpCost:        r.SigOpCost,
+		Annotations:      r.Annotations,
 	}
 
 	mp.pool[*tx.Hash()] = txD
@@ -573,6 +919,8 @@ func (mp *TxPool) addTransaction(utxoView *blockchain.UtxoViewpoint, tx *btcutil
 		mp.cfg.FeeEstimator.ObserveTransaction(txD)
 	}
 
+	mp.sendNotification(NTTxAccepted, txD, nil)
+
 	return txD
 }
 
@@ -609,6 +957,179 @@ func (mp *TxPool) checkPoolDoubleSpend(tx *btcutil.Tx) (bool, error) {
 	return isReplacement, nil
 }
 
+// pendingDoubleSpend is a transaction which was rejected for double spending
+// transactions in the pool that can't be replaced, along with the outputs it
+// shares with each of them.  It is recorded by recordDoubleSpend once its
+// scripts are found to be valid.
+type pendingDoubleSpend struct {
+	tx       *btcutil.Tx
+	utxoView *blockchain.UtxoViewpoint
+	shared   map[chainhash.Hash][]wire.OutPoint
+}
+
+// checkDoubleSpend returns the passed transaction as a pending double spend
+// when it was rejected with the passed error for spending outputs already spent
+// by transactions in the pool which can't be replaced, and at least one of
+// those conflicts hasn't recorded it yet.  It returns nil for all other
+// rejections, for conflicts which have reached the limit of recorded double
+// spends and once the rate limit of the script checks is exceeded.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) checkDoubleSpend(tx *btcutil.Tx, rejectErr error) *pendingDoubleSpend {
+	// Only consider transactions which were rejected by the double spend
+	// check itself rather than for any other reason.
+	if _, err := mp.checkPoolDoubleSpend(tx); err == nil || err != rejectErr {
+		return nil
+	}
+
+	// Gather the transactions in the pool the transaction conflicts with
+	// which are not replaceable along with the outputs they share.
+	txHash := tx.Hash()
+	shared := make(map[chainhash.Hash][]wire.OutPoint)
+	for _, txIn := range tx.MsgTx().TxIn {
+		conflict, ok := mp.outpoints[txIn.PreviousOutPoint]
+		if !ok {
+			continue
+		}
+		if !mp.cfg.Policy.RejectReplacement &&
+			mp.signalsReplacement(conflict, nil) {
+
+			continue
+		}
+
+		// Ignore conflicts which have already recorded the transaction
+		// or have reached the limit of recorded double spends.
+		conflictHash := *conflict.Hash()
+		if !mp.canRecordDoubleSpend(conflictHash, txHash) {
+			continue
+		}
+		shared[conflictHash] = append(shared[conflictHash],
+			txIn.PreviousOutPoint)
+	}
+	if len(shared) == 0 {
+		return nil
+	}
+
+	// All of the inputs must be available to validate the scripts.
+	utxoView, err := mp.fetchInputUtxos(tx)
+	if err != nil {
+		return nil
+	}
+	for _, txIn := range tx.MsgTx().TxIn {
+		entry := utxoView.LookupEntry(txIn.PreviousOutPoint)
+		if entry == nil || entry.IsSpent() {
+			return nil
+		}
+	}
+
+	// Limit the rate of the script checks so peers can't use double spends
+	// to make the node validate scripts of transactions which are never
+	// accepted without bound.  The count decays with a one minute window.
+	nowUnix := time.Now().Unix()
+	mp.doubleSpendChecks *= math.Pow(1.0-1.0/60.0,
+		float64(nowUnix-mp.lastDoubleSpendCheck))
+	mp.lastDoubleSpendCheck = nowUnix
+	if mp.doubleSpendChecks >= maxDoubleSpendChecks {
+		log.Debugf("Not recording double spend %v: rate limit of "+
+			"double spend checks exceeded", txHash)
+		return nil
+	}
+	mp.doubleSpendChecks++
+
+	return &pendingDoubleSpend{
+		tx:       tx,
+		utxoView: utxoView,
+		shared:   shared,
+	}
+}
+
+// canRecordDoubleSpend returns whether the passed double spend can be recorded
+// against the passed transaction in the pool, which is the case when it hasn't
+// been recorded yet and the limit of recorded double spends isn't reached.
+//
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) canRecordDoubleSpend(conflictHash chainhash.Hash,
+	txHash *chainhash.Hash) bool {
+
+	records := mp.doubleSpends[conflictHash]
+	if len(records) >= maxDoubleSpendsPerTx {
+		return false
+	}
+	for _, ds := range records {
+		if ds.Tx.Hash().IsEqual(txHash) {
+			return false
+		}
+	}
+	return true
+}
+
+// recordDoubleSpend validates the scripts of the passed pending double spend
+// and, when they are valid, records the transaction against each transaction
+// in the pool it conflicts with and notifies subscribers about each newly
+// recorded conflict.  Only properly signed transactions are recorded so that
+// peers can't trigger alerts with transactions which could never be mined.
+//
+// The scripts are validated without holding the mempool lock, so the conflicts
+// are checked again once it is acquired.
+//
+// This function MUST be called without the mempool lock held.
+func (mp *TxPool) recordDoubleSpend(ds *pendingDoubleSpend) {
+	tx := ds.tx
+	err := blockchain.ValidateTransactionScripts(tx, ds.utxoView,
+		txscript.StandardVerifyFlags, mp.cfg.SigCache,
+		mp.cfg.HashCache)
+	if err != nil {
+		log.Debugf("Not recording double spend %v: %v", tx.Hash(), err)
+		return
+	}
+
+	mp.mtx.Lock()
+	defer mp.mtx.Unlock()
+
+	txHash := tx.Hash()
+	now := time.Now()
+	for conflictHash, outpoints := range ds.shared {
+		// Skip conflicts which left the pool or recorded further double
+		// spends while the scripts were validated.
+		txD, ok := mp.pool[conflictHash]
+		if !ok || !mp.canRecordDoubleSpend(conflictHash, txHash) {
+			continue
+		}
+
+		mp.doubleSpends[conflictHash] = append(
+			mp.doubleSpends[conflictHash], &DoubleSpend{
+				Tx:        tx,
+				Seen:      now,
+				Outpoints: outpoints,
+			},
+		)
+
+		log.Infof("Transaction %v double spends transaction %v in "+
+			"the mempool", txHash, conflictHash)
+
+		mp.sendNotification(NTTxDoubleSpend, txD, txHash)
+	}
+}
+
+// DoubleSpends returns the recorded transactions which were rejected for
+// double spending outputs spent by the passed transaction in the pool.  The
+// second return value is false when the transaction is not in the pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) DoubleSpends(hash *chainhash.Hash) ([]*DoubleSpend, bool) {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	if _, exists := mp.pool[*hash]; !exists {
+		return nil, false
+	}
+
+	records := mp.doubleSpends[*hash]
+	doubleSpends := make([]*DoubleSpend, len(records))
+	copy(doubleSpends, records)
+	return doubleSpends, true
+}
+
 // signalsReplacement determines if a transaction is signaling that it can be
 // replaced using the Replace-By-Fee (RBF) policy. This policy specifies two
 // ways a transaction can signal that it is replaceable:
@@ -831,6 +1352,24 @@ func (mp *TxPool) FetchTransaction(txHash *chainhash.Hash) (*btcutil.Tx, error)
 	return nil, fmt.Errorf("transaction is not in the pool")
 }
 
+// FetchTxDesc returns the descriptor of the requested transaction from the
+// transaction pool.  This only fetches from the main transaction pool and does
+// not include orphans.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) FetchTxDesc(txHash *chainhash.Hash) (*TxDesc, error) {
+	// Protect concurrent access.
+	mp.mtx.RLock()
+	txDesc, exists := mp.pool[*txHash]
+	mp.mtx.RUnlock()
+
+	if exists {
+		return txDesc, nil
+	}
+
+	return nil, fmt.Errorf("transaction is not in the pool")
+}
+
 // validateReplacement determines whether a transaction is deemed as a valid
 // replacement of all of its conflicts according to the RBF policy. If it is
 // valid, no error is returned. Otherwise, an error is returned indicating what
@@ -838,7 +1377,7 @@ func (mp *TxPool) FetchTransaction(txHash *chainhash.Hash) (*btcutil.Tx, error)
 //
 // This function MUST be called with the mempool lock held (for reads).
 func (mp *TxPool) validateReplacement(tx *btcutil.Tx,
-	txFee int64) (map[chainhash.Hash]*btcutil.Tx, error) {
+	txFee, txSize int64) (map[chainhash.Hash]*btcutil.Tx, error) {
 
 	// First, we'll make sure the set of conflicting transactions doesn't
 	// exceed the maximum allowed.
@@ -871,7 +1410,6 @@ func (mp *TxPool) validateReplacement(tx *btcutil.Tx,
 	// block. Requiring that the fee rate always be increased is also an
 	// easy-to-reason about way to prevent DoS attacks via replacements.
 	var (
-		txSize           = GetTxVirtualSize(tx)
 		txFeeRate        = txFee * 1000 / txSize
 		conflictsFee     int64
 		conflictsParents = make(map[chainhash.Hash]struct{})
@@ -960,9 +1498,9 @@ func (mp *TxPool) maybeAcceptTransaction(tx *btcutil.Tx, isNew, rateLimit,
 		// The conflict set should already include the descendants for
 		// each one, so we don't need to remove the redeemers within
 		// this call as they'll be removed eventually.
-		mp.removeTransaction(conflict, false)
+		mp.removeTransaction(conflict, false, NTTxReplaced, txHash)
 	}
-	txD := mp.addTransaction(r.utxoView, tx, r.bestHeight, int64(r.TxFee))
+	txD := mp.addTransaction(tx, r)
 
 	log.Debugf("Accepted transaction %v (pool size: %v)", txHash,
 		len(mp.pool))
@@ -985,8 +1523,19 @@ func (mp *TxPool) MaybeAcceptTransaction(tx *btcutil.Tx, isNew, rateLimit bool)
 	// Protect concurrent access.
 	mp.mtx.Lock()
 	hashes, txD, err := mp.maybeAcceptTransaction(tx, isNew, rateLimit, true)
+
+	// Keep track of new transactions which were rejected for double
+	// spending a transaction in the pool.
+	var ds *pendingDoubleSpend
+	if err != nil && isNew {
+		ds = mp.checkDoubleSpend(tx, err)
+	}
 	mp.mtx.Unlock()
 
+	if ds != nil {
+		mp.recordDoubleSpend(ds)
+	}
+
 	return hashes, txD, err
 }
 
@@ -1107,14 +1656,22 @@ func (mp *TxPool) ProcessTransaction(tx *btcutil.Tx, allowOrphan, rateLimit bool
 
 	// Protect concurrent access.
 	mp.mtx.Lock()
-	defer mp.mtx.Unlock()
 
 	// Potentially accept the transaction to the memory pool.
 	missingParents, txD, err := mp.maybeAcceptTransaction(tx, true, rateLimit,
 		true)
 	if err != nil {
+		// Keep track of transactions which were rejected for double
+		// spending a transaction in the pool.  Their scripts are
+		// validated after releasing the lock.
+		ds := mp.checkDoubleSpend(tx, err)
+		mp.mtx.Unlock()
+		if ds != nil {
+			mp.recordDoubleSpend(ds)
+		}
 		return nil, err
 	}
+	defer mp.mtx.Unlock()
 
 	if len(missingParents) == 0 {
 		// Accept any orphan transactions that depend on this
@@ -1246,7 +1803,7 @@ func (mp *TxPool) RawMempoolVerbose() map[string]*btcjson.GetRawMempoolVerboseRe
 
 		mpd := &btcjson.GetRawMempoolVerboseResult{
 			Size:             int32(tx.MsgTx().SerializeSize()),
-			Vsize:            int32(GetTxVirtualSize(tx)),
+			Vsize:            int32(desc.VirtualSize),
 			Weight:           int32(blockchain.GetTransactionWeight(tx)),
 			Fee:              btcutil.Amount(desc.Fee).ToBTC(),
 			Time:             desc.Added.Unix(),
@@ -1254,6 +1811,7 @@ func (mp *TxPool) RawMempoolVerbose() map[string]*btcjson.GetRawMempoolVerboseRe
 			StartingPriority: desc.StartingPriority,
 			CurrentPriority:  currentPriority,
 			Depends:          make([]string, 0),
+			Annotations:      desc.Annotations,
 		}
 		for _, txIn := range tx.MsgTx().TxIn {
 			hash := &txIn.PreviousOutPoint.Hash
@@ -1282,9 +1840,12 @@ type MempoolAcceptResult struct {
 	// TxFee is the fees paid in satoshi.
 	TxFee btcutil.Amount
 
-	// TxSize is the virtual size(vb) of the tx.
+	// TxSize is the sigop-adjusted virtual size(vb) of the tx.
 	TxSize int64
 
+	// SigOpCost is the total signature operation cost of the tx.
+	SigOpCost int64
+
 	// conflicts is a set of transactions whose inputs are spent by this
 	// transaction(RBF).
 	Conflicts map[chainhash.Hash]*btcutil.Tx
@@ -1298,6 +1859,10 @@ type MempoolAcceptResult struct {
 	// field is not nil, then other fields must be empty.
 	MissingParents []*chainhash.Hash
 
+	// Annotations are the notes attached to the transaction by the
+	// configured policy hooks.
+	Annotations []string
+
 	// utxoView is a set of the unspent transaction outputs referenced by
 	// the inputs to this transaction.
 	utxoView *blockchain.UtxoViewpoint
@@ -1507,11 +2072,16 @@ func (mp *TxPool) checkMempoolAcceptance(tx *btcutil.Tx,
 
 	// Don't allow transactions with an excessive number of signature
 	// operations which would result in making it impossible to mine.
-	if err := mp.validateSigCost(tx, utxoView); err != nil {
+	sigOpCost, err := mp.validateSigCost(tx, utxoView)
+	if err != nil {
 		return nil, err
 	}
 
-	txSize := GetTxVirtualSize(tx)
+	// Fee rates are based on the virtual size of the transaction adjusted
+	// for its signature operation cost.
+	txSize := GetSigOpAdjustedVirtualSize(
+		tx, int64(sigOpCost), int64(mp.cfg.Policy.BytesPerSigOp),
+	)
 
 	// Don't allow transactions with fees too low to get into a mined
 	// block.
@@ -1526,7 +2096,7 @@ func (mp *TxPool) checkMempoolAcceptance(tx *btcutil.Tx,
 	// then we're processing a potential replacement.
 	var conflicts map[chainhash.Hash]*btcutil.Tx
 	if isReplacement {
-		conflicts, err = mp.validateReplacement(tx, txFee)
+		conflicts, err = mp.validateReplacement(tx, txFee, txSize)
 		if err != nil {
 			return nil, err
 		}
@@ -1544,12 +2114,27 @@ func (mp *TxPool) checkMempoolAcceptance(tx *btcutil.Tx,
 		return nil, err
 	}
 
+	// Give the configured policy hooks a chance to veto or annotate the
+	// transaction now that it has passed all of the built-in checks.
+	annotations, err := mp.checkPolicyHooks(&PolicyRequest{
+		Tx:          tx,
+		Fee:         btcutil.Amount(txFee),
+		VirtualSize: txSize,
+		Height:      nextBlockHeight,
+		IsNew:       isNew,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	result := &MempoolAcceptResult{
-		TxFee:      btcutil.Amount(txFee),
-		TxSize:     txSize,
-		Conflicts:  conflicts,
-		utxoView:   utxoView,
-		bestHeight: bestHeight,
+		TxFee:       btcutil.Amount(txFee),
+		TxSize:      txSize,
+		SigOpCost:   int64(sigOpCost),
+		Conflicts:   conflicts,
+		Annotations: annotations,
+		utxoView:    utxoView,
+		bestHeight:  bestHeight,
 	}
 
 	return result, nil
@@ -1605,9 +2190,8 @@ func (mp *TxPool) validateStandardness(tx *btcutil.Tx, nextBlockHeight int32,
 	}
 
 	// Check the transaction standard.
-	err := CheckTransactionStandard(
-		tx, nextBlockHeight, medianTimePast,
-		mp.cfg.Policy.MinRelayTxFee, mp.cfg.Policy.MaxTxVersion,
+	err := CheckTransactionStandardWithPolicy(
+		tx, nextBlockHeight, medianTimePast, &mp.cfg.Policy,
 	)
 	if err != nil {
 		// Attempt to extract a reject code from the error so it can be
@@ -1643,9 +2227,9 @@ func (mp *TxPool) validateStandardness(tx *btcutil.Tx, nextBlockHeight int32,
 }
 
 // validateSigCost checks the cost to run the signature operations to make sure
-// the number of signatures are sane.
+// the number of signatures are sane.  The cost is returned when it is.
 func (mp *TxPool) validateSigCost(tx *btcutil.Tx,
-	utxoView *blockchain.UtxoViewpoint) error {
+	utxoView *blockchain.UtxoViewpoint) (int, error) {
 
 	// Since the coinbase address itself can contain signature operations,
 	// the maximum allowed signature operations per transaction is less
@@ -1657,21 +2241,21 @@ func (mp *TxPool) validateSigCost(tx *btcutil.Tx,
 	)
 	if err != nil {
 		if cerr, ok := err.(blockchain.RuleError); ok {
-			return chainRuleError(cerr)
+			return 0, chainRuleError(cerr)
 		}
 
-		return err
+		return 0, err
 	}
 
 	// Exit early if the sig cost is under limit.
 	if sigOpCost <= mp.cfg.Policy.MaxSigOpCostPerTx {
-		return nil
+		return sigOpCost, nil
 	}
 
 	str := fmt.Sprintf("transaction %v sigop cost is too high: %d > %d",
 		tx.Hash(), sigOpCost, mp.cfg.Policy.MaxSigOpCostPerTx)
 
-	return txRuleError(wire.RejectNonstandard, str)
+	return 0, txRuleError(wire.RejectNonstandard, str)
 }
 
 // validateRelayFeeMet checks that the min relay fee is covered by this
@@ -1763,7 +2347,9 @@ func New(cfg *Config) *TxPool {
 		pool:           make(map[chainhash.Hash]*TxDesc),
 		orphans:        make(map[chainhash.Hash]*orphanTx),
 		orphansByPrev:  make(map[wire.OutPoint]map[chainhash.Hash]*btcutil.Tx),
+		orphansByTag:   make(map[Tag]map[chainhash.Hash]*orphanTx),
 		nextExpireScan: time.Now().Add(orphanExpireScanInterval),
 		outpoints:      make(map[wire.OutPoint]*btcutil.Tx),
+		doubleSpends:   make(map[chainhash.Hash][]*DoubleSpend),
 	}
 }
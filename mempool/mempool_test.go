@@ -6,6 +6,7 @@ package mempool
 
 import (
 	"encoding/hex"
+	"errors"
 	"reflect"
 	"strings"
 	"sync"
@@ -325,6 +326,8 @@ func newPoolHarness(chainParams *chaincfg.Params) (*poolHarness, []spendableOutp
 				MaxSigOpCostPerTx:    blockchain.MaxBlockSigOpsCost / 4,
 				MinRelayTxFee:        1000, // 1 Satoshi per byte
 				MaxTxVersion:         1,
+				MaxStandardTxWeight:  DefaultMaxStandardTxWeight,
+				MaxDataCarrierSize:   DefaultMaxDataCarrierSize,
 			},
 			ChainParams:      chainParams,
 			FetchUtxoView:    chain.FetchUtxoView,
@@ -635,6 +638,140 @@ func TestOrphanEviction(t *testing.T) {
 	}
 }
 
+// TestOrphanTagEviction ensures that exceeding the maximum number of orphans
+// allowed for a single tag only evicts orphans with that same tag.
+func TestOrphanTagEviction(t *testing.T) {
+	t.Parallel()
+
+	const maxPerTag = 2
+	harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	harness.txPool.cfg.Policy.MaxOrphanTxsPerTag = maxPerTag
+	tc := &testContext{t, harness}
+
+	chainedTxns, err := harness.CreateTxChain(outputs[0], maxPerTag+3)
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+
+	// Add a single orphan from the first tag followed by more orphans
+	// than allowed from the second tag.
+	_, err = harness.txPool.ProcessTransaction(chainedTxns[1], true, false, 1)
+	if err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept valid orphan %v",
+			err)
+	}
+	for _, tx := range chainedTxns[2:] {
+		_, err := harness.txPool.ProcessTransaction(tx, true, false, 2)
+		if err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept valid "+
+				"orphan %v", err)
+		}
+	}
+
+	// The orphan from the first tag must not have been evicted while only
+	// the most recent orphans from the second tag remain.
+	testPoolMembership(tc, chainedTxns[1], true, false)
+	numTxns := len(chainedTxns)
+	for i, tx := range chainedTxns[2:] {
+		inPool := i+2 >= numTxns-maxPerTag
+		testPoolMembership(tc, tx, inPool, false)
+	}
+
+	stats := harness.txPool.OrphanStats()
+	if stats.Count != maxPerTag+1 {
+		t.Fatalf("unexpected orphan count -- got %d, want %d",
+			stats.Count, maxPerTag+1)
+	}
+	if stats.NumTags != 2 {
+		t.Fatalf("unexpected number of tags -- got %d, want %d",
+			stats.NumTags, 2)
+	}
+	if stats.EvictedTagLimit != 1 {
+		t.Fatalf("unexpected tag evictions -- got %d, want %d",
+			stats.EvictedTagLimit, 1)
+	}
+
+	// Removing the orphans by tag must remove all orphans for it.
+	harness.txPool.RemoveOrphansByTag(2)
+	stats = harness.txPool.OrphanStats()
+	if stats.Count != 1 || stats.NumTags != 1 {
+		t.Fatalf("unexpected orphan stats after tag removal: %+v",
+			stats)
+	}
+}
+
+// TestOrphanWeightEviction ensures that exceeding the maximum total weight of
+// the orphan pool evicts orphans from the tag using the most weight.
+func TestOrphanWeightEviction(t *testing.T) {
+	t.Parallel()
+
+	harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	tc := &testContext{t, harness}
+
+	chainedTxns, err := harness.CreateTxChain(outputs[0], 5)
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+
+	// Allow the orphan pool to only hold three of the orphans.  Their
+	// weights only differ by a few units due to signature encoding.
+	var txWeight int64
+	for _, tx := range chainedTxns[1:] {
+		txWeight = max(txWeight, blockchain.GetTransactionWeight(tx))
+	}
+	harness.txPool.cfg.Policy.MaxOrphanWeight = txWeight * 3
+
+	// Add two orphans from the first tag, one from the second, and then a
+	// final one from the second tag which requires an eviction.
+	tags := []Tag{1, 1, 2, 2}
+	for i, tx := range chainedTxns[1:] {
+		_, err := harness.txPool.ProcessTransaction(tx, true, false,
+			tags[i])
+		if err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept valid "+
+				"orphan %v", err)
+		}
+	}
+
+	// The oldest orphan from the first tag is evicted since that tag was
+	// using the most weight.
+	testPoolMembership(tc, chainedTxns[1], false, false)
+	for _, tx := range chainedTxns[2:] {
+		testPoolMembership(tc, tx, true, false)
+	}
+
+	var wantWeight int64
+	for _, tx := range chainedTxns[2:] {
+		wantWeight += blockchain.GetTransactionWeight(tx)
+	}
+	stats := harness.txPool.OrphanStats()
+	if stats.Weight != wantWeight {
+		t.Fatalf("unexpected orphan weight -- got %d, want %d",
+			stats.Weight, wantWeight)
+	}
+	if stats.EvictedPoolLimit != 1 {
+		t.Fatalf("unexpected pool evictions -- got %d, want %d",
+			stats.EvictedPoolLimit, 1)
+	}
+
+	// An orphan which is heavier than the entire orphan pool must be
+	// rejected outright.
+	harness.txPool.cfg.Policy.MaxOrphanWeight =
+		blockchain.GetTransactionWeight(chainedTxns[2]) - 1
+	harness.txPool.RemoveOrphan(chainedTxns[2])
+	_, err = harness.txPool.ProcessTransaction(chainedTxns[2], true, false, 3)
+	if err == nil {
+		t.Fatalf("ProcessTransaction: accepted orphan heavier than " +
+			"the orphan pool")
+	}
+}
+
 // TestBasicOrphanRemoval ensure that orphan removal works as expected when an
 // orphan that doesn't exist is removed  both when there is another orphan that
 // redeems it and when there is not.
@@ -1852,3 +1989,386 @@ func TestRBF(t *testing.T) {
 		}
 	}
 }
+
+// TestNotifications ensures that subscribers are notified of transactions
+// being accepted into and removed from the mempool along with the reason for
+// their removal.
+func TestNotifications(t *testing.T) {
+	t.Parallel()
+
+	const defaultFee = btcutil.SatoshiPerBitcoin
+
+	harness, _, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	ctx := &testContext{t, harness}
+	txPool := harness.txPool
+
+	type event struct {
+		typ      NotificationType
+		hash     chainhash.Hash
+		conflict *chainhash.Hash
+	}
+	var events []event
+	txPool.Subscribe(func(n *Notification) {
+		events = append(events, event{
+			typ:      n.Type,
+			hash:     *n.TxDesc.Tx.Hash(),
+			conflict: n.Conflict,
+		})
+	})
+
+	// checkEvents ensures the notifications received since the last check
+	// match the expected ones, ignoring their order.
+	checkEvents := func(want ...event) {
+		t.Helper()
+
+		if len(events) != len(want) {
+			t.Fatalf("got %d notifications, want %d: %v",
+				len(events), len(want), events)
+		}
+	next:
+		for _, w := range want {
+			for _, got := range events {
+				if got.typ != w.typ || got.hash != w.hash {
+					continue
+				}
+				if (got.conflict == nil) != (w.conflict == nil) ||
+					(w.conflict != nil &&
+						*got.conflict != *w.conflict) {
+
+					t.Fatalf("unexpected conflict for %v "+
+						"notification of %v", w.typ,
+						w.hash)
+				}
+				continue next
+			}
+			t.Fatalf("missing %v notification for %v", w.typ,
+				w.hash)
+		}
+		events = nil
+	}
+
+	// Accepting a transaction and its child produces a notification for
+	// each of them.
+	coinbase := ctx.addCoinbaseTx(2)
+	coinbaseOut := txOutToSpendableOut(coinbase, 0)
+	parent := ctx.addSignedTx(
+		[]spendableOutput{coinbaseOut}, 1, defaultFee, true, false,
+	)
+	child := ctx.addSignedTx(
+		[]spendableOutput{txOutToSpendableOut(parent, 0)}, 1,
+		defaultFee, false, false,
+	)
+	checkEvents(
+		event{typ: NTTxAccepted, hash: *parent.Hash()},
+		event{typ: NTTxAccepted, hash: *child.Hash()},
+	)
+
+	// Replacing the parent evicts it along with its child.
+	replacement := ctx.addSignedTx(
+		[]spendableOutput{coinbaseOut}, 1, defaultFee*3, false, false,
+	)
+	checkEvents(
+		event{typ: NTTxReplaced, hash: *parent.Hash(),
+			conflict: replacement.Hash()},
+		event{typ: NTTxReplaced, hash: *child.Hash(),
+			conflict: replacement.Hash()},
+		event{typ: NTTxAccepted, hash: *replacement.Hash()},
+	)
+
+	// A block transaction double spending a mempool transaction causes it
+	// to be removed as a conflict.
+	coinbaseOut = txOutToSpendableOut(coinbase, 1)
+	spender := ctx.addSignedTx(
+		[]spendableOutput{coinbaseOut}, 1, defaultFee, false, false,
+	)
+	blockTx, err := harness.CreateSignedTx(
+		[]spendableOutput{coinbaseOut}, 2, defaultFee, false,
+	)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	events = nil
+	txPool.RemoveDoubleSpends(blockTx)
+	checkEvents(event{typ: NTTxRemovedConflict, hash: *spender.Hash(),
+		conflict: blockTx.Hash()})
+
+	// Mined transactions are removed with their own reason.
+	txPool.RemoveMinedTransaction(replacement)
+	checkEvents(event{typ: NTTxRemovedBlock, hash: *replacement.Hash()})
+
+	// Transactions which have been in the pool longer than the expiry are
+	// evicted along with their descendants, while newer ones remain.
+	coinbase = ctx.addCoinbaseTx(2)
+	old := ctx.addSignedTx(
+		[]spendableOutput{txOutToSpendableOut(coinbase, 0)}, 1,
+		defaultFee, false, false,
+	)
+	oldChild := ctx.addSignedTx(
+		[]spendableOutput{txOutToSpendableOut(old, 0)}, 1,
+		defaultFee, false, false,
+	)
+	fresh := ctx.addSignedTx(
+		[]spendableOutput{txOutToSpendableOut(coinbase, 1)}, 1,
+		defaultFee, false, false,
+	)
+	events = nil
+	txPool.mtx.Lock()
+	txPool.pool[*old.Hash()].Added = time.Now().Add(-2 * time.Hour)
+	txPool.cfg.Policy.TxExpiry = time.Hour
+	txPool.mtx.Unlock()
+	if n := txPool.ExpireTransactions(); n != 2 {
+		t.Fatalf("expired %d transactions, want 2", n)
+	}
+	checkEvents(
+		event{typ: NTTxRemovedExpiry, hash: *old.Hash()},
+		event{typ: NTTxRemovedExpiry, hash: *oldChild.Hash()},
+	)
+
+	// Explicitly removed transactions use the generic reason.
+	txPool.RemoveTransaction(fresh, true)
+	checkEvents(event{typ: NTTxRemoved, hash: *fresh.Hash()})
+}
+
+// TestDoubleSpendRecording ensures that properly signed transactions which are
+// rejected for double spending a non-replaceable transaction in the pool are
+// recorded and announced, while other rejected conflicts are not.
+func TestDoubleSpendRecording(t *testing.T) {
+	t.Parallel()
+
+	const defaultFee = btcutil.SatoshiPerBitcoin
+
+	harness, _, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	ctx := &testContext{t, harness}
+	txPool := harness.txPool
+
+	var notifications []*Notification
+	txPool.Subscribe(func(n *Notification) {
+		if n.Type == NTTxDoubleSpend {
+			notifications = append(notifications, n)
+		}
+	})
+
+	coinbase := ctx.addCoinbaseTx(2)
+	outs := []spendableOutput{txOutToSpendableOut(coinbase, 0)}
+	original := ctx.addSignedTx(outs, 1, defaultFee, false, false)
+
+	// A properly signed double spend of a transaction that doesn't signal
+	// replacement is rejected and recorded.
+	doubleSpend, err := harness.CreateSignedTx(outs, 2, defaultFee, false)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		_, err = txPool.ProcessTransaction(doubleSpend, true, false, 0)
+		if err == nil {
+			t.Fatal("ProcessTransaction: double spend accepted")
+		}
+	}
+	records, ok := txPool.DoubleSpends(original.Hash())
+	if !ok {
+		t.Fatal("DoubleSpends: original transaction not found")
+	}
+	if len(records) != 1 {
+		t.Fatalf("got %d double spends, want 1", len(records))
+	}
+	if !records[0].Tx.Hash().IsEqual(doubleSpend.Hash()) {
+		t.Fatalf("recorded double spend %v, want %v",
+			records[0].Tx.Hash(), doubleSpend.Hash())
+	}
+	wantOutpoints := []wire.OutPoint{outs[0].outPoint}
+	if !reflect.DeepEqual(records[0].Outpoints, wantOutpoints) {
+		t.Fatalf("recorded outpoints %v, want %v",
+			records[0].Outpoints, wantOutpoints)
+	}
+	if len(notifications) != 1 {
+		t.Fatalf("got %d notifications, want 1", len(notifications))
+	}
+	if !notifications[0].TxDesc.Tx.Hash().IsEqual(original.Hash()) ||
+		!notifications[0].Conflict.IsEqual(doubleSpend.Hash()) {
+
+		t.Fatal("unexpected double spend notification")
+	}
+
+	// A double spend with an invalid signature is not recorded.
+	badSig, err := harness.CreateSignedTx(outs, 3, defaultFee, false)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	badSig.MsgTx().TxIn[0].SignatureScript = []byte{txscript.OP_TRUE}
+	badSig = btcutil.NewTx(badSig.MsgTx())
+	if _, err := txPool.ProcessTransaction(badSig, true, false, 0); err == nil {
+		t.Fatal("ProcessTransaction: double spend accepted")
+	}
+	records, _ = txPool.DoubleSpends(original.Hash())
+	if len(records) != 1 {
+		t.Fatalf("got %d double spends, want 1", len(records))
+	}
+
+	// Failed replacements of transactions signaling replacement are not
+	// double spends.
+	outs = []spendableOutput{txOutToSpendableOut(coinbase, 1)}
+	replaceable := ctx.addSignedTx(outs, 1, defaultFee, true, false)
+	lowFee, err := harness.CreateSignedTx(outs, 2, defaultFee/2, false)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	if _, err := txPool.ProcessTransaction(lowFee, true, false, 0); err == nil {
+		t.Fatal("ProcessTransaction: replacement accepted")
+	}
+	records, _ = txPool.DoubleSpends(replaceable.Hash())
+	if len(records) != 0 {
+		t.Fatalf("got %d double spends, want 0", len(records))
+	}
+	if len(notifications) != 1 {
+		t.Fatalf("got %d notifications, want 1", len(notifications))
+	}
+
+	// A double spend which is rejected for another reason before the
+	// conflict is detected, such as spending the same output twice, is not
+	// recorded.
+	outs = []spendableOutput{txOutToSpendableOut(coinbase, 0)}
+	dupInput, err := harness.CreateSignedTx(outs, 4, defaultFee, false)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	dupInput.MsgTx().AddTxIn(dupInput.MsgTx().TxIn[0])
+	dupInput = btcutil.NewTx(dupInput.MsgTx())
+	if _, err := txPool.ProcessTransaction(dupInput, true, false, 0); err == nil {
+		t.Fatal("ProcessTransaction: double spend accepted")
+	}
+	records, _ = txPool.DoubleSpends(original.Hash())
+	if len(records) != 1 {
+		t.Fatalf("got %d double spends, want 1", len(records))
+	}
+
+	// Double spends are not recorded once the rate limit of the script
+	// checks is exceeded, and are recorded again once it has decayed.
+	limited, err := harness.CreateSignedTx(outs, 5, defaultFee, false)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	txPool.mtx.Lock()
+	txPool.doubleSpendChecks = maxDoubleSpendChecks
+	txPool.lastDoubleSpendCheck = time.Now().Unix()
+	txPool.mtx.Unlock()
+	if _, err := txPool.ProcessTransaction(limited, true, false, 0); err == nil {
+		t.Fatal("ProcessTransaction: double spend accepted")
+	}
+	records, _ = txPool.DoubleSpends(original.Hash())
+	if len(records) != 1 {
+		t.Fatalf("got %d double spends, want 1", len(records))
+	}
+	txPool.mtx.Lock()
+	txPool.doubleSpendChecks = 0
+	txPool.mtx.Unlock()
+	if _, err := txPool.ProcessTransaction(limited, true, false, 0); err == nil {
+		t.Fatal("ProcessTransaction: double spend accepted")
+	}
+	records, _ = txPool.DoubleSpends(original.Hash())
+	if len(records) != 2 {
+		t.Fatalf("got %d double spends, want 2", len(records))
+	}
+
+	// The records are dropped along with the transaction.
+	txPool.RemoveTransaction(original, true)
+	if _, ok := txPool.DoubleSpends(original.Hash()); ok {
+		t.Fatal("DoubleSpends: removed transaction found")
+	}
+}
+
+// TestPolicyHooks ensures the configured policy hooks can reject and annotate
+// transactions and that failing hooks don't prevent acceptance.
+func TestPolicyHooks(t *testing.T) {
+	t.Parallel()
+
+	const defaultFee = btcutil.SatoshiPerBitcoin
+
+	harness, _, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	ctx := &testContext{t, harness}
+	txPool := harness.txPool
+
+	// The hooks reject any transaction paying more than twice the default
+	// fee, annotate every transaction, and always fail respectively.
+	var requests []*PolicyRequest
+	txPool.cfg.PolicyHooks = []*PolicyHook{{
+		Name: "highfee",
+		Check: func(req *PolicyRequest) (*PolicyVerdict, error) {
+			requests = append(requests, req)
+			if req.Fee > defaultFee*2 {
+				return &PolicyVerdict{
+					Reject: true,
+					Reason: "fee too high",
+				}, nil
+			}
+			return nil, nil
+		},
+	}, {
+		Name: "annotate",
+		Check: func(req *PolicyRequest) (*PolicyVerdict, error) {
+			return &PolicyVerdict{
+				Annotations: []string{"seen"},
+			}, nil
+		},
+	}, {
+		Name: "broken",
+		Check: func(req *PolicyRequest) (*PolicyVerdict, error) {
+			return &PolicyVerdict{Reject: true},
+				errors.New("service unavailable")
+		},
+	}}
+
+	// A transaction which passes the hooks is accepted along with the
+	// annotations.
+	coinbase := ctx.addCoinbaseTx(2)
+	accepted := ctx.addSignedTx(
+		[]spendableOutput{txOutToSpendableOut(coinbase, 0)}, 1,
+		defaultFee, false, false,
+	)
+	if len(requests) != 1 {
+		t.Fatalf("got %d policy requests, want 1", len(requests))
+	}
+	req := requests[0]
+	if *req.Tx.Hash() != *accepted.Hash() || req.Fee != defaultFee ||
+		req.VirtualSize != GetTxVirtualSize(accepted) || !req.IsNew ||
+		req.Height != harness.chain.BestHeight()+1 {
+
+		t.Fatalf("unexpected policy request: %+v", req)
+	}
+	verbose := txPool.RawMempoolVerbose()[accepted.Hash().String()]
+	if !reflect.DeepEqual(verbose.Annotations, []string{"seen"}) {
+		t.Fatalf("got annotations %v, want [seen]",
+			verbose.Annotations)
+	}
+
+	// A transaction which a hook rejects is not accepted, both when
+	// checking for acceptance and when processing it.
+	rejected, err := harness.CreateSignedTx(
+		[]spendableOutput{txOutToSpendableOut(coinbase, 1)}, 1,
+		defaultFee*3, false,
+	)
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	_, err = txPool.CheckMempoolAcceptance(rejected)
+	rerr, ok := err.(RuleError)
+	if !ok {
+		t.Fatalf("CheckMempoolAcceptance: got %v, want RuleError", err)
+	}
+	txErr, ok := rerr.Err.(TxRuleError)
+	if !ok || txErr.RejectCode != wire.RejectNonstandard {
+		t.Fatalf("CheckMempoolAcceptance: unexpected error %v", err)
+	}
+	if _, err := txPool.ProcessTransaction(rejected, true, false, 0); err == nil {
+		t.Fatal("ProcessTransaction: rejected transaction accepted")
+	}
+	testPoolMembership(ctx, rejected, false, false)
+}
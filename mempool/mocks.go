@@ -123,3 +123,30 @@ func (m *MockTxMempool) CheckSpend(op wire.OutPoint) *btcutil.Tx {
 
 	return args.Get(0).(*btcutil.Tx)
 }
+
+// DoubleSpends returns the recorded transactions which were rejected for
+// double spending outputs spent by the passed transaction in the pool.
+func (m *MockTxMempool) DoubleSpends(
+	hash *chainhash.Hash) ([]*DoubleSpend, bool) {
+
+	args := m.Called(hash)
+
+	if args.Get(0) == nil {
+		return nil, args.Bool(1)
+	}
+
+	return args.Get(0).([]*DoubleSpend), args.Bool(1)
+}
+
+// OrphanStats returns statistics about the current state of the orphan pool
+// along with counters of the orphans evicted from it.
+func (m *MockTxMempool) OrphanStats() OrphanStats {
+	args := m.Called()
+	return args.Get(0).(OrphanStats)
+}
+
+// Subscribe registers a callback to be executed when various mempool events
+// take place.
+func (m *MockTxMempool) Subscribe(callback NotificationCallback) {
+	m.Called(callback)
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mempool
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/chainhash/v2"
+)
+
+// NotificationType represents the type of a mempool notification message.
+type NotificationType int
+
+// NotificationCallback is used for a caller to provide a callback for
+// notifications about various mempool events.
+type NotificationCallback func(*Notification)
+
+// Constants for the type of a notification message.
+const (
+	// NTTxAccepted indicates the associated transaction was accepted into
+	// the mempool.
+	NTTxAccepted NotificationType = iota
+
+	// NTTxRemovedBlock indicates the associated transaction was removed
+	// from the mempool because it was included in a block connected to
+	// the main chain.
+	NTTxRemovedBlock
+
+	// NTTxRemovedConflict indicates the associated transaction was removed
+	// from the mempool because it, or one of its ancestors, conflicts with
+	// a transaction included in a block connected to the main chain.
+	NTTxRemovedConflict
+
+	// NTTxRemovedExpiry indicates the associated transaction was removed
+	// from the mempool because it stayed in the pool longer than allowed by
+	// the policy.
+	NTTxRemovedExpiry
+
+	// NTTxReplaced indicates the associated transaction, or one of its
+	// ancestors, was replaced by a transaction signaling Replace-By-Fee.
+	NTTxReplaced
+
+	// NTTxRemoved indicates the associated transaction was removed from
+	// the mempool for any other reason, such as being explicitly removed or
+	// failing to be re-accepted after a reorganization.
+	NTTxRemoved
+
+	// NTTxDoubleSpend indicates a transaction double spending the
+	// associated transaction was rejected because the associated
+	// transaction can't be replaced.  The hash of the rejected transaction
+	// is provided in the Conflict field.
+	NTTxDoubleSpend
+)
+
+// notificationTypeStrings is a map of notification types back to their constant
+// names for pretty printing.
+var notificationTypeStrings = map[NotificationType]string{
+	NTTxAccepted:        "NTTxAccepted",
+	NTTxRemovedBlock:    "NTTxRemovedBlock",
+	NTTxRemovedConflict: "NTTxRemovedConflict",
+	NTTxRemovedExpiry:   "NTTxRemovedExpiry",
+	NTTxReplaced:        "NTTxReplaced",
+	NTTxRemoved:         "NTTxRemoved",
+	NTTxDoubleSpend:     "NTTxDoubleSpend",
+}
+
+// String returns the NotificationType in human-readable form.
+func (n NotificationType) String() string {
+	if s, ok := notificationTypeStrings[n]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown Notification Type (%d)", int(n))
+}
+
+// Notification defines a notification that is sent to subscribers when the
+// contents of the mempool change.  TxDesc describes the transaction as it was
+// in the mempool.  Conflict is the hash of the transaction which caused the
+// removal for NTTxRemovedConflict and NTTxReplaced notifications, the hash of
+// the rejected transaction for NTTxDoubleSpend notifications, and nil
+// otherwise.
+type Notification struct {
+	Type     NotificationType
+	TxDesc   *TxDesc
+	Conflict *chainhash.Hash
+}
+
+// Subscribe to mempool notifications.  Registers a callback to be executed
+// when various events take place.  See the documentation on Notification and
+// NotificationType for details on the types and contents of notifications.
+//
+// NOTE: Callbacks are executed with the mempool lock held, so they must not
+// call back into the mempool and should return quickly.
+func (mp *TxPool) Subscribe(callback NotificationCallback) {
+	mp.notificationsLock.Lock()
+	mp.notifications = append(mp.notifications, callback)
+	mp.notificationsLock.Unlock()
+}
+
+// sendNotification sends a notification with the passed type and details to
+// all subscribers.
+func (mp *TxPool) sendNotification(typ NotificationType, txDesc *TxDesc,
+	conflict *chainhash.Hash) {
+
+	n := Notification{Type: typ, TxDesc: txDesc, Conflict: conflict}
+	mp.notificationsLock.RLock()
+	for _, callback := range mp.notifications {
+		callback(&n)
+	}
+	mp.notificationsLock.RUnlock()
+}
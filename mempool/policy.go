@@ -5,6 +5,8 @@
 package mempool
 
 import (
+	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -19,9 +21,9 @@ const (
 	// that are considered standard in a pay-to-script-hash script.
 	maxStandardP2SHSigOps = 15
 
-	// maxStandardTxCost is the max weight permitted by any transaction
+	// maxStandardTxWeight is the max weight permitted by any transaction
 	// according to the current default policy.
-	maxStandardTxWeight = 400000
+	maxStandardTxWeight = DefaultMaxStandardTxWeight
 
 	// maxStandardSigScriptSize is the maximum size allowed for a
 	// transaction input signature script to be considered standard.  This
@@ -49,10 +51,48 @@ const (
 	// for larger transactions.  This value is in Satoshi/1000 bytes.
 	DefaultMinRelayTxFee = btcutil.Amount(1000)
 
+	// DefaultMaxStandardTxWeight is the default maximum weight permitted
+	// for a transaction to be considered standard.
+	DefaultMaxStandardTxWeight = 400000
+
+	// DefaultMaxDataCarrierSize is the default maximum number of bytes
+	// that may be pushed by a null data (OP_RETURN) output for it to be
+	// considered standard.
+	DefaultMaxDataCarrierSize = txscript.MaxDataCarrierSize
+
+	// DefaultMaxClaimValueSize is the default maximum number of bytes the
+	// value of a claim, support or update output may have for it to be
+	// considered standard.  It leaves room for the metadata of typical
+	// claims while rejecting values which only bloat the claim trie.
+	DefaultMaxClaimValueSize = 4096
+
+	// DefaultBytesPerSigOp is the default number of virtual bytes each
+	// unit of signature operation cost is considered to occupy when
+	// calculating the sigop-adjusted virtual size of a transaction.  It
+	// matches the default of the reference implementation.
+	DefaultBytesPerSigOp = 20
+
 	// maxStandardMultiSigKeys is the maximum number of public keys allowed
 	// in a multi-signature transaction output script for it to be
 	// considered standard.
 	maxStandardMultiSigKeys = 3
+
+	// claimValueUnsigned and claimValueSigned are the first bytes of the
+	// values of claims, supports and updates which hold LBRY metadata.  An
+	// unsigned value is followed by the protobuf encoded metadata, while a
+	// signed value is followed by the ID of the signing channel claim, the
+	// signature and then the metadata.  Values starting with any other byte
+	// are legacy values which are either protobuf or JSON encoded.
+	claimValueUnsigned = 0x00
+	claimValueSigned   = 0x01
+
+	// claimValueSignatureSize is the size of the signature of a signed
+	// claim value.
+	claimValueSignatureSize = 64
+
+	// maxProtobufFieldNum is the largest field number allowed by the
+	// protobuf wire format.
+	maxProtobufFieldNum = 1<<29 - 1
 )
 
 // calcMinRequiredTxRelayFee returns the minimum transaction fee required for a
@@ -177,10 +217,18 @@ func checkInputsStandardWithView(tx *btcutil.Tx, utxoView utxoView) error {
 // script (public key script) to ensure it is a "standard" public key script.
 // A standard public key script is one that is a recognized form, and for
 // multi-signature scripts, only contains from 1 to maxStandardMultiSigKeys
-// public keys.
-func checkPkScriptStandard(pkScript []byte, scriptClass txscript.ScriptClass) error {
+// public keys.  Bare multi-signature scripts are rejected outright when
+// rejectBareMultisig is set.
+func checkPkScriptStandard(pkScript []byte, scriptClass txscript.ScriptClass,
+	rejectBareMultisig bool) error {
+
 	switch scriptClass {
 	case txscript.MultiSigTy:
+		if rejectBareMultisig {
+			return txRuleError(wire.RejectNonstandard,
+				"bare multi-signature script")
+		}
+
 		numPubKeys, numSigs, err := txscript.CalcMultiSigStats(pkScript)
 		if err != nil {
 			str := fmt.Sprintf("multi-signature script parse "+
@@ -223,6 +271,130 @@ func checkPkScriptStandard(pkScript []byte, scriptClass txscript.ScriptClass) er
 	return nil
 }
 
+// checkProtobufWireFormat returns an error if the passed message isn't a
+// well-formed sequence of protobuf fields.  Since the message is checked
+// without its schema, the contents of length-delimited fields, which may be
+// nested messages, are not checked.
+func checkProtobufWireFormat(msg []byte) error {
+	for len(msg) > 0 {
+		key, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return fmt.Errorf("malformed protobuf field key")
+		}
+		msg = msg[n:]
+
+		fieldNum, wireType := key>>3, key&0x07
+		if fieldNum == 0 || fieldNum > maxProtobufFieldNum {
+			return fmt.Errorf("invalid protobuf field number %d",
+				fieldNum)
+		}
+
+		switch wireType {
+		// Varint.
+		case 0:
+			_, n = binary.Uvarint(msg)
+			if n <= 0 {
+				return fmt.Errorf("malformed protobuf varint in "+
+					"field %d", fieldNum)
+			}
+			msg = msg[n:]
+
+		// Fixed 64-bit value.
+		case 1:
+			if len(msg) < 8 {
+				return fmt.Errorf("truncated protobuf field %d",
+					fieldNum)
+			}
+			msg = msg[8:]
+
+		// Length-delimited data.
+		case 2:
+			size, n := binary.Uvarint(msg)
+			if n <= 0 || size > uint64(len(msg)-n) {
+				return fmt.Errorf("truncated protobuf field %d",
+					fieldNum)
+			}
+			msg = msg[n+int(size):]
+
+		// Fixed 32-bit value.
+		case 5:
+			if len(msg) < 4 {
+				return fmt.Errorf("truncated protobuf field %d",
+					fieldNum)
+			}
+			msg = msg[4:]
+
+		// The deprecated group wire types aren't used by LBRY metadata.
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d in "+
+				"field %d", wireType, fieldNum)
+		}
+	}
+
+	return nil
+}
+
+// checkClaimMetadata returns an error if the passed claim, support or update
+// value isn't well-formed LBRY metadata.  See the comment on claimValueUnsigned
+// for the recognized forms.
+func checkClaimMetadata(value []byte) error {
+	if len(value) == 0 {
+		return fmt.Errorf("value is empty")
+	}
+
+	switch value[0] {
+	case claimValueUnsigned:
+		return checkProtobufWireFormat(value[1:])
+
+	case claimValueSigned:
+		const headerSize = 1 + txscript.ClaimIDSize +
+			claimValueSignatureSize
+		if len(value) < headerSize {
+			return fmt.Errorf("signed value of %d bytes is shorter "+
+				"than its %d byte header", len(value),
+				headerSize)
+		}
+		return checkProtobufWireFormat(value[headerSize:])
+	}
+
+	// Legacy values are either JSON or protobuf encoded.
+	if json.Valid(value) {
+		return nil
+	}
+	return checkProtobufWireFormat(value)
+}
+
+// checkClaimStandard performs a series of checks on the prefix of a claim,
+// support or update output script accepted by the policy to ensure its value is
+// standard.  The value
+// must not exceed the configured max size and, when the policy asks for it,
+// must be well-formed LBRY metadata.  Supports without a value are always
+// standard.
+//
+// These checks only concern relay.  The consensus rules accept any value which
+// fits in a claim script.
+func checkClaimStandard(claim *txscript.ClaimScript, policy *Policy) error {
+	if claim.Value == nil {
+		return nil
+	}
+
+	if len(claim.Value) > policy.MaxClaimValueSize {
+		str := fmt.Sprintf("claim value of %d bytes is more than the "+
+			"allowed max of %d", len(claim.Value),
+			policy.MaxClaimValueSize)
+		return txRuleError(wire.RejectNonstandard, str)
+	}
+
+	if policy.CheckClaimMetadata {
+		if err := checkClaimMetadata(claim.Value); err != nil {
+			str := fmt.Sprintf("malformed claim metadata: %v", err)
+			return txRuleError(wire.RejectNonstandard, str)
+		}
+	}
+
+	return nil
+}
+
 // GetDustThreshold calculates the dust limit for a *wire.TxOut by taking the
 // size of a typical spending transaction and multiplying it by 3 to account
 // for the minimum dust relay fee of 3000sat/kvb.
@@ -332,6 +504,35 @@ func IsDust(txOut *wire.TxOut, minRelayTxFee btcutil.Amount) bool {
 	return txOut.Value*1000/GetDustThreshold(txOut) < int64(minRelayTxFee)
 }
 
+// dataCarrierSize returns the number of bytes pushed by the passed public key
+// script and whether or not the script is a data carrier script.  A data
+// carrier script has the same form as txscript.NullDataTy, which is a single
+// OP_RETURN optionally followed by one data push or small integer.
+//
+// Unlike txscript.NullDataTy, this does not impose a limit on the amount of
+// data pushed so that the limit may be configured by policy.
+func dataCarrierSize(pkScript []byte) (int, bool) {
+	if len(pkScript) == 0 || pkScript[0] != txscript.OP_RETURN {
+		return 0, false
+	}
+
+	// Single OP_RETURN.
+	if len(pkScript) == 1 {
+		return 0, true
+	}
+
+	tokenizer := txscript.MakeScriptTokenizer(0, pkScript[1:])
+	if !tokenizer.Next() || !tokenizer.Done() {
+		return 0, false
+	}
+	opcode := tokenizer.Opcode()
+	if !txscript.IsSmallInt(opcode) && opcode > txscript.OP_PUSHDATA4 {
+		return 0, false
+	}
+
+	return len(tokenizer.Data()), true
+}
+
 // CheckTransactionStandard performs a series of checks on a transaction to
 // ensure it is a "standard" transaction.  A standard transaction is one that
 // conforms to several additional limiting cases over what is considered a
@@ -339,16 +540,37 @@ func IsDust(txOut *wire.TxOut, minRelayTxFee btcutil.Amount) bool {
 // finalized, conforming to more stringent size constraints, having scripts
 // of recognized forms, and not containing "dust" outputs (those that are
 // so small it costs more to process them than they are worth).
+//
+// The default policy limits are applied along with the passed minimum relay
+// fee and maximum transaction version.  Use CheckTransactionStandardWithPolicy
+// to apply the limits of a configured policy.
 func CheckTransactionStandard(tx *btcutil.Tx, height int32,
 	medianTimePast time.Time, minRelayTxFee btcutil.Amount,
 	maxTxVersion int32) error {
 
+	policy := Policy{
+		MaxTxVersion:        maxTxVersion,
+		MinRelayTxFee:       minRelayTxFee,
+		MaxStandardTxWeight: DefaultMaxStandardTxWeight,
+		MaxDataCarrierSize:  DefaultMaxDataCarrierSize,
+		MaxClaimValueSize:   DefaultMaxClaimValueSize,
+	}
+	return CheckTransactionStandardWithPolicy(
+		tx, height, medianTimePast, &policy,
+	)
+}
+
+// CheckTransactionStandardWithPolicy performs the same checks as
+// CheckTransactionStandard using the limits of the passed policy.
+func CheckTransactionStandardWithPolicy(tx *btcutil.Tx, height int32,
+	medianTimePast time.Time, policy *Policy) error {
+
 	// The transaction must be a currently supported version.
 	msgTx := tx.MsgTx()
-	if msgTx.Version > maxTxVersion || msgTx.Version < 1 {
+	if msgTx.Version > policy.MaxTxVersion || msgTx.Version < 1 {
 		str := fmt.Sprintf("transaction version %d is not in the "+
 			"valid range of %d-%d", msgTx.Version, 1,
-			maxTxVersion)
+			policy.MaxTxVersion)
 		return txRuleError(wire.RejectNonstandard, str)
 	}
 
@@ -364,9 +586,9 @@ func CheckTransactionStandard(tx *btcutil.Tx, height int32,
 	// size of a transaction.  This also helps mitigate CPU exhaustion
 	// attacks.
 	txWeight := blockchain.GetTransactionWeight(tx)
-	if txWeight > maxStandardTxWeight {
+	if txWeight > int64(policy.MaxStandardTxWeight) {
 		str := fmt.Sprintf("weight of transaction is larger than max "+
-			"allowed: %v > %v", txWeight, maxStandardTxWeight)
+			"allowed: %v > %v", txWeight, policy.MaxStandardTxWeight)
 		return txRuleError(wire.RejectNonstandard, str)
 	}
 
@@ -396,8 +618,42 @@ func CheckTransactionStandard(tx *btcutil.Tx, height int32,
 	// be "dust" (except when the script is a null data script).
 	numNullDataOutputs := 0
 	for i, txOut := range msgTx.TxOut {
-		scriptClass := txscript.GetScriptClass(txOut.PkScript)
-		err := checkPkScriptStandard(txOut.PkScript, scriptClass)
+		// Outputs which only carry data are checked against the
+		// configured data carrier size rather than the script class
+		// since the latter imposes its own fixed limit.
+		if size, ok := dataCarrierSize(txOut.PkScript); ok {
+			if size > policy.MaxDataCarrierSize {
+				str := fmt.Sprintf("transaction output %d: "+
+					"null data script pushes %d bytes which "+
+					"is more than the allowed max of %d", i,
+					size, policy.MaxDataCarrierSize)
+				return txRuleError(wire.RejectNonstandard, str)
+			}
+			numNullDataOutputs++
+			continue
+		}
+
+		// Claim, support and update outputs are only standard when the
+		// policy accepts them, since the script engine can't verify
+		// their spends.  Otherwise the prefix makes the script
+		// non-standard.  When accepted, the value must be standard and
+		// the script following the prefix is held to the same rules as
+		// any other output script.
+		pkScript := txOut.PkScript
+		claim, err := txscript.DecodeClaimScript(pkScript)
+		if err == nil && policy.AcceptClaimScripts {
+			if err := checkClaimStandard(claim, policy); err != nil {
+				str := fmt.Sprintf("transaction output %d: %v",
+					i, err)
+				return txRuleError(wire.RejectNonstandard, str)
+			}
+			pkScript = claim.PkScript
+		}
+
+		scriptClass := txscript.GetScriptClass(pkScript)
+		err = checkPkScriptStandard(
+			pkScript, scriptClass, policy.RejectBareMultisig,
+		)
 		if err != nil {
 			// Attempt to extract a reject code from the error so
 			// it can be retained.  When not possible, fall back to
@@ -410,12 +666,8 @@ func CheckTransactionStandard(tx *btcutil.Tx, height int32,
 			return txRuleError(rejectCode, str)
 		}
 
-		// Accumulate the number of outputs which only carry data.  For
-		// all other script types, ensure the output value is not
-		// "dust".
-		if scriptClass == txscript.NullDataTy {
-			numNullDataOutputs++
-		} else if IsDust(txOut, minRelayTxFee) {
+		// Ensure the output value is not "dust".
+		if IsDust(txOut, policy.dustRelayFee()) {
 			str := fmt.Sprintf("transaction output %d: payment is "+
 				"dust: %v", i, txOut.Value)
 			return txRuleError(wire.RejectDust, str)
@@ -444,3 +696,21 @@ func GetTxVirtualSize(tx *btcutil.Tx) int64 {
 	return (blockchain.GetTransactionWeight(tx) + (blockchain.WitnessScaleFactor - 1)) /
 		blockchain.WitnessScaleFactor
 }
+
+// GetSigOpAdjustedVirtualSize computes the virtual size of a given transaction
+// while treating each unit of its signature operation cost as occupying
+// bytesPerSigOp bytes of weight.  This prevents transactions with a large
+// number of signature operations relative to their size from paying fees as
+// if they were small since they use up the limited signature operation budget
+// of a block.  It is the size used for fee rate calculations by the policy and
+// matches the virtual size reported by the reference implementation.
+func GetSigOpAdjustedVirtualSize(tx *btcutil.Tx, sigOpCost,
+	bytesPerSigOp int64) int64 {
+
+	weight := blockchain.GetTransactionWeight(tx)
+	if sigOpWeight := sigOpCost * bytesPerSigOp; sigOpWeight > weight {
+		weight = sigOpWeight
+	}
+	return (weight + (blockchain.WitnessScaleFactor - 1)) /
+		blockchain.WitnessScaleFactor
+}
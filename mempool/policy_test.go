@@ -96,6 +96,77 @@ func TestCalcMinRequiredTxRelayFee(t *testing.T) {
 	}
 }
 
+// TestGetSigOpAdjustedVirtualSize tests the GetSigOpAdjustedVirtualSize API.
+func TestGetSigOpAdjustedVirtualSize(t *testing.T) {
+	tx := btcutil.NewTx(&wire.MsgTx{
+		Version: 1,
+		TxIn: []*wire.TxIn{{
+			PreviousOutPoint: wire.OutPoint{Index: 1},
+			SignatureScript:  bytes.Repeat([]byte{0x00}, 100),
+			Sequence:         wire.MaxTxInSequenceNum,
+		}},
+		TxOut: []*wire.TxOut{{
+			Value:    1000,
+			PkScript: bytes.Repeat([]byte{0x00}, 25),
+		}},
+	})
+	vsize := GetTxVirtualSize(tx)
+
+	tests := []struct {
+		name          string // test description.
+		sigOpCost     int64  // Signature operation cost of the tx.
+		bytesPerSigOp int64  // Virtual bytes per unit of sigop cost.
+		want          int64  // Expected virtual size.
+	}{
+		{
+			"no signature operations",
+			0,
+			DefaultBytesPerSigOp,
+			vsize,
+		},
+		{
+			"adjustment disabled",
+			1000,
+			0,
+			vsize,
+		},
+		{
+			"sigop weight below transaction weight",
+			4,
+			DefaultBytesPerSigOp,
+			vsize,
+		},
+		{
+			"sigop weight above transaction weight",
+			80,
+			DefaultBytesPerSigOp,
+			400,
+		},
+		{
+			"sigop weight rounded up",
+			81,
+			DefaultBytesPerSigOp,
+			405,
+		},
+		{
+			"sigop weight not a multiple of the scale factor",
+			201,
+			7,
+			352,
+		},
+	}
+
+	for _, test := range tests {
+		got := GetSigOpAdjustedVirtualSize(tx, test.sigOpCost,
+			test.bytesPerSigOp)
+		if got != test.want {
+			t.Errorf("TestGetSigOpAdjustedVirtualSize test '%s' "+
+				"failed: got %v want %v", test.name, got,
+				test.want)
+		}
+	}
+}
+
 // TestCheckPkScriptStandard tests the checkPkScriptStandard API.
 func TestCheckPkScriptStandard(t *testing.T) {
 	var pubKeys [][]byte
@@ -194,7 +265,7 @@ func TestCheckPkScriptStandard(t *testing.T) {
 				"failed: %v", test.name, err)
 		}
 		scriptClass := txscript.GetScriptClass(script)
-		got := checkPkScriptStandard(script, scriptClass)
+		got := checkPkScriptStandard(script, scriptClass, false)
 		if (test.isStandard && got != nil) ||
 			(!test.isStandard && got == nil) {
 
@@ -202,6 +273,15 @@ func TestCheckPkScriptStandard(t *testing.T) {
 				test.name)
 			return
 		}
+
+		// Bare multi-signature scripts must never be standard when
+		// they are rejected by policy.
+		got = checkPkScriptStandard(script, scriptClass, true)
+		if scriptClass == txscript.MultiSigTy && got == nil {
+			t.Fatalf("TestCheckPkScriptStandard test '%s' failed: "+
+				"bare multisig accepted when rejected by policy",
+				test.name)
+		}
 	}
 }
 
@@ -668,6 +748,481 @@ func TestCheckTransactionStandard(t *testing.T) {
 	}
 }
 
+// TestCheckTransactionStandardPolicy ensures the configurable standardness
+// policy limits are honored by CheckTransactionStandardWithPolicy.
+func TestCheckTransactionStandardPolicy(t *testing.T) {
+	prevOutHash, err := chainhash.NewHashFromStr("01")
+	if err != nil {
+		t.Fatalf("NewShaHashFromStr: unexpected error: %v", err)
+	}
+	dummyTxIn := wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Hash: *prevOutHash, Index: 1},
+		SignatureScript:  bytes.Repeat([]byte{0x00}, 65),
+		Sequence:         wire.MaxTxInSequenceNum,
+	}
+
+	// Create a null data script which pushes more data than allowed by
+	// default along with a 1-of-1 bare multisig script.
+	bigNullData, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_RETURN).
+		AddData(bytes.Repeat([]byte{0x01}, 200)).Script()
+	if err != nil {
+		t.Fatalf("unable to build null data script: %v", err)
+	}
+	privKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("NewPrivateKey: unexpected error: %v", err)
+	}
+	pubKey := privKey.PubKey().SerializeCompressed()
+	bareMultisig, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_1).AddData(pubKey).AddOp(txscript.OP_1).
+		AddOp(txscript.OP_CHECKMULTISIG).Script()
+	if err != nil {
+		t.Fatalf("unable to build multisig script: %v", err)
+	}
+
+	defaultPolicy := Policy{
+		MaxTxVersion:        1,
+		MinRelayTxFee:       DefaultMinRelayTxFee,
+		MaxStandardTxWeight: DefaultMaxStandardTxWeight,
+		MaxDataCarrierSize:  DefaultMaxDataCarrierSize,
+	}
+
+	tests := []struct {
+		name       string
+		txOut      wire.TxOut
+		policy     func(p *Policy)
+		isStandard bool
+		code       wire.RejectCode
+	}{
+		{
+			name:       "large null data with default policy",
+			txOut:      wire.TxOut{PkScript: bigNullData},
+			policy:     func(p *Policy) {},
+			isStandard: false,
+			code:       wire.RejectNonstandard,
+		},
+		{
+			name:  "large null data with raised data carrier size",
+			txOut: wire.TxOut{PkScript: bigNullData},
+			policy: func(p *Policy) {
+				p.MaxDataCarrierSize = 200
+			},
+			isStandard: true,
+		},
+		{
+			name: "null data with zero data carrier size",
+			txOut: wire.TxOut{
+				PkScript: []byte{txscript.OP_RETURN, txscript.OP_1},
+			},
+			policy: func(p *Policy) {
+				p.MaxDataCarrierSize = 0
+			},
+			isStandard: true,
+		},
+		{
+			name: "null data with multiple pushes",
+			txOut: wire.TxOut{
+				PkScript: []byte{txscript.OP_RETURN,
+					txscript.OP_DATA_1, 0x01,
+					txscript.OP_DATA_1, 0x02},
+			},
+			policy:     func(p *Policy) {},
+			isStandard: false,
+			code:       wire.RejectNonstandard,
+		},
+		{
+			name: "bare multisig permitted by default",
+			txOut: wire.TxOut{
+				Value:    100000000,
+				PkScript: bareMultisig,
+			},
+			policy:     func(p *Policy) {},
+			isStandard: true,
+		},
+		{
+			name: "bare multisig rejected by policy",
+			txOut: wire.TxOut{
+				Value:    100000000,
+				PkScript: bareMultisig,
+			},
+			policy: func(p *Policy) {
+				p.RejectBareMultisig = true
+			},
+			isStandard: false,
+			code:       wire.RejectNonstandard,
+		},
+		{
+			name: "output is dust with raised dust relay fee",
+			txOut: wire.TxOut{
+				Value:    1000,
+				PkScript: bareMultisig,
+			},
+			policy: func(p *Policy) {
+				p.DustRelayFee = 10000
+			},
+			isStandard: false,
+			code:       wire.RejectDust,
+		},
+		{
+			name: "output is dust with raised min relay fee",
+			txOut: wire.TxOut{
+				Value:    1000,
+				PkScript: bareMultisig,
+			},
+			policy: func(p *Policy) {
+				p.MinRelayTxFee = 10000
+			},
+			isStandard: false,
+			code:       wire.RejectDust,
+		},
+		{
+			name: "dust relay fee overrides min relay fee",
+			txOut: wire.TxOut{
+				Value:    1000,
+				PkScript: bareMultisig,
+			},
+			policy: func(p *Policy) {
+				p.MinRelayTxFee = 10000
+				p.DustRelayFee = 1000
+			},
+			isStandard: true,
+		},
+		{
+			name:  "transaction heavier than lowered max weight",
+			txOut: wire.TxOut{PkScript: bigNullData},
+			policy: func(p *Policy) {
+				p.MaxDataCarrierSize = 200
+				p.MaxStandardTxWeight = 400
+			},
+			isStandard: false,
+			code:       wire.RejectNonstandard,
+		},
+	}
+
+	for _, test := range tests {
+		policy := defaultPolicy
+		test.policy(&policy)
+
+		tx := wire.MsgTx{
+			Version: 1,
+			TxIn:    []*wire.TxIn{&dummyTxIn},
+			TxOut:   []*wire.TxOut{&test.txOut},
+		}
+		err := CheckTransactionStandardWithPolicy(
+			btcutil.NewTx(&tx), 300000, time.Now(), &policy,
+		)
+		if test.isStandard {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", test.name,
+					err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: standard when it should not be",
+				test.name)
+			continue
+		}
+		code, _ := extractRejectCode(err)
+		if code != test.code {
+			t.Errorf("%s: unexpected reject code - got %v, want %v",
+				test.name, code, test.code)
+		}
+	}
+}
+
+// TestCheckProtobufWireFormat ensures checkProtobufWireFormat accepts
+// well-formed protobuf messages and rejects malformed ones.
+func TestCheckProtobufWireFormat(t *testing.T) {
+	tests := []struct {
+		name  string
+		msg   []byte
+		valid bool
+	}{
+		{
+			name:  "empty message",
+			msg:   nil,
+			valid: true,
+		},
+		{
+			name: "all supported wire types",
+			msg: []byte{
+				0x0a, 0x03, 'a', 'b', 'c', // 1: "abc"
+				0x10, 0x96, 0x01, // 2: 150
+				0x1d, 0x01, 0x02, 0x03, 0x04, // 3: fixed32
+				0x21, 0x01, 0x02, 0x03, 0x04, // 4: fixed64
+				0x05, 0x06, 0x07, 0x08,
+			},
+			valid: true,
+		},
+		{
+			name:  "zero field number",
+			msg:   []byte{0x02, 0x00},
+			valid: false,
+		},
+		{
+			name:  "truncated varint",
+			msg:   []byte{0x10, 0x96},
+			valid: false,
+		},
+		{
+			name:  "length beyond end of message",
+			msg:   []byte{0x0a, 0x04, 'a', 'b', 'c'},
+			valid: false,
+		},
+		{
+			name:  "truncated fixed64",
+			msg:   []byte{0x21, 0x01, 0x02, 0x03},
+			valid: false,
+		},
+		{
+			name:  "group wire type",
+			msg:   []byte{0x0b, 0x0c},
+			valid: false,
+		},
+		{
+			name:  "invalid wire type",
+			msg:   []byte{0x0e, 0x00},
+			valid: false,
+		},
+	}
+
+	for _, test := range tests {
+		err := checkProtobufWireFormat(test.msg)
+		if test.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: well-formed when it should not be",
+				test.name)
+		}
+	}
+}
+
+// TestCheckClaimStandard ensures claim, support and update outputs are only
+// standard when the policy accepts them, that their values are then held to the
+// configured standardness policy and that the script following their prefix is
+// held to the same rules as other output scripts.
+func TestCheckClaimStandard(t *testing.T) {
+	prevOutHash, err := chainhash.NewHashFromStr("01")
+	if err != nil {
+		t.Fatalf("NewShaHashFromStr: unexpected error: %v", err)
+	}
+	dummyTxIn := wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Hash: *prevOutHash, Index: 1},
+		SignatureScript:  bytes.Repeat([]byte{0x00}, 65),
+		Sequence:         wire.MaxTxInSequenceNum,
+	}
+	addrHash := [20]byte{0x01}
+	addr, err := address.NewAddressPubKeyHash(addrHash[:],
+		&chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("NewAddressPubKeyHash: unexpected error: %v", err)
+	}
+	payScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("PayToAddrScript: unexpected error: %v", err)
+	}
+
+	// Build LBRY metadata values of the various forms.
+	metadata := []byte{0x0a, 0x03, 'a', 'b', 'c', 0x10, 0x96, 0x01}
+	unsigned := append([]byte{claimValueUnsigned}, metadata...)
+	signed := append([]byte{claimValueSigned}, bytes.Repeat([]byte{0x01},
+		txscript.ClaimIDSize+claimValueSignatureSize)...)
+	signed = append(signed, metadata...)
+	legacyJSON := []byte(`{"title": "abc"}`)
+	malformed := []byte{claimValueUnsigned, 0x0a, 0x10, 'a'}
+	shortSigned := []byte{claimValueSigned, 0x01, 0x02}
+	big := append([]byte{claimValueUnsigned}, 0x0a, 0xfd, 0x1f)
+	big = append(big, bytes.Repeat([]byte{0x01}, 4093)...)
+
+	claimID := bytes.Repeat([]byte{0x02}, txscript.ClaimIDSize)
+	claimScript := func(value []byte, pkScript []byte) []byte {
+		prefix, err := txscript.NewClaimScript([]byte("name"), value)
+		if err != nil {
+			t.Fatalf("NewClaimScript: unexpected error: %v", err)
+		}
+		return append(prefix, pkScript...)
+	}
+	supportScript := func(value []byte) []byte {
+		prefix, err := txscript.NewSupportScript([]byte("name"),
+			claimID, value)
+		if err != nil {
+			t.Fatalf("NewSupportScript: unexpected error: %v", err)
+		}
+		return append(prefix, payScript...)
+	}
+	updateScript := func(value []byte) []byte {
+		prefix, err := txscript.NewUpdateScript([]byte("name"),
+			claimID, value)
+		if err != nil {
+			t.Fatalf("NewUpdateScript: unexpected error: %v", err)
+		}
+		return append(prefix, payScript...)
+	}
+
+	defaultPolicy := Policy{
+		MaxTxVersion:        1,
+		MinRelayTxFee:       DefaultMinRelayTxFee,
+		MaxStandardTxWeight: DefaultMaxStandardTxWeight,
+		MaxDataCarrierSize:  DefaultMaxDataCarrierSize,
+		MaxClaimValueSize:   DefaultMaxClaimValueSize,
+		AcceptClaimScripts:  true,
+	}
+	checkMetadata := func(p *Policy) {
+		p.CheckClaimMetadata = true
+	}
+
+	tests := []struct {
+		name       string
+		pkScript   []byte
+		policy     func(p *Policy)
+		isStandard bool
+	}{
+		{
+			name:       "claim with default policy",
+			pkScript:   claimScript(unsigned, payScript),
+			policy:     func(p *Policy) {},
+			isStandard: true,
+		},
+		{
+			name:     "claim when claim scripts aren't accepted",
+			pkScript: claimScript(unsigned, payScript),
+			policy: func(p *Policy) {
+				p.AcceptClaimScripts = false
+			},
+			isStandard: false,
+		},
+		{
+			name:     "support when claim scripts aren't accepted",
+			pkScript: supportScript(nil),
+			policy: func(p *Policy) {
+				p.AcceptClaimScripts = false
+			},
+			isStandard: false,
+		},
+		{
+			name:       "claim paying a non-standard script",
+			pkScript:   claimScript(unsigned, []byte{txscript.OP_TRUE}),
+			policy:     func(p *Policy) {},
+			isStandard: false,
+		},
+		{
+			name:       "claim without a script following the prefix",
+			pkScript:   claimScript(unsigned, nil),
+			policy:     func(p *Policy) {},
+			isStandard: false,
+		},
+		{
+			name:       "claim value larger than default max",
+			pkScript:   claimScript(big, payScript),
+			policy:     func(p *Policy) {},
+			isStandard: false,
+		},
+		{
+			name:     "claim value within raised max",
+			pkScript: claimScript(big, payScript),
+			policy: func(p *Policy) {
+				p.MaxClaimValueSize = len(big)
+			},
+			isStandard: true,
+		},
+		{
+			name:     "update value larger than lowered max",
+			pkScript: updateScript(unsigned),
+			policy: func(p *Policy) {
+				p.MaxClaimValueSize = len(unsigned) - 1
+			},
+			isStandard: false,
+		},
+		{
+			name:     "support without value and zero max",
+			pkScript: supportScript(nil),
+			policy: func(p *Policy) {
+				p.MaxClaimValueSize = 0
+			},
+			isStandard: true,
+		},
+		{
+			name:       "malformed metadata without metadata checks",
+			pkScript:   claimScript(malformed, payScript),
+			policy:     func(p *Policy) {},
+			isStandard: true,
+		},
+		{
+			name:       "malformed metadata with metadata checks",
+			pkScript:   claimScript(malformed, payScript),
+			policy:     checkMetadata,
+			isStandard: false,
+		},
+		{
+			name:       "signed metadata with metadata checks",
+			pkScript:   updateScript(signed),
+			policy:     checkMetadata,
+			isStandard: true,
+		},
+		{
+			name:       "truncated signature with metadata checks",
+			pkScript:   updateScript(shortSigned),
+			policy:     checkMetadata,
+			isStandard: false,
+		},
+		{
+			name:       "legacy JSON metadata with metadata checks",
+			pkScript:   claimScript(legacyJSON, payScript),
+			policy:     checkMetadata,
+			isStandard: true,
+		},
+		{
+			name:       "empty claim value with metadata checks",
+			pkScript:   claimScript(nil, payScript),
+			policy:     checkMetadata,
+			isStandard: false,
+		},
+		{
+			name:       "support metadata with metadata checks",
+			pkScript:   supportScript(unsigned),
+			policy:     checkMetadata,
+			isStandard: true,
+		},
+	}
+
+	for _, test := range tests {
+		policy := defaultPolicy
+		test.policy(&policy)
+
+		tx := wire.MsgTx{
+			Version: 1,
+			TxIn:    []*wire.TxIn{&dummyTxIn},
+			TxOut: []*wire.TxOut{{
+				Value:    100000000,
+				PkScript: test.pkScript,
+			}},
+		}
+		err := CheckTransactionStandardWithPolicy(
+			btcutil.NewTx(&tx), 300000, time.Now(), &policy,
+		)
+		if test.isStandard {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", test.name,
+					err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: standard when it should not be",
+				test.name)
+			continue
+		}
+		code, _ := extractRejectCode(err)
+		if code != wire.RejectNonstandard {
+			t.Errorf("%s: unexpected reject code - got %v, want %v",
+				test.name, code, wire.RejectNonstandard)
+		}
+	}
+}
+
 // mockUtxoEntry mocks the utxoEntry interface using testify/mock.
 type mockUtxoEntry struct {
 	mock.Mock
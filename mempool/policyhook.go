@@ -0,0 +1,144 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mempool
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcutil/v2"
+	"github.com/btcsuite/btcd/wire/v2"
+)
+
+// PolicyRequest houses the details of a transaction which has passed all of
+// the built-in mempool acceptance checks and is about to be accepted.  It is
+// provided to policy hooks so they can veto or annotate the transaction.
+type PolicyRequest struct {
+	// Tx is the transaction being considered.
+	Tx *btcutil.Tx
+
+	// Fee is the total fee paid by the transaction.
+	Fee btcutil.Amount
+
+	// VirtualSize is the virtual size of the transaction.
+	VirtualSize int64
+
+	// Height is the height of the block the transaction would be mined in
+	// next.
+	Height int32
+
+	// IsNew is true when the transaction was received from a peer or
+	// submitted locally as opposed to being re-added after a block was
+	// disconnected.
+	IsNew bool
+}
+
+// PolicyVerdict is the result of a policy hook for a transaction.
+type PolicyVerdict struct {
+	// Reject indicates the transaction must not be accepted into the pool.
+	Reject bool
+
+	// Reason is a human-readable reason for rejecting the transaction.
+	Reason string
+
+	// Annotations are arbitrary notes about the transaction which are
+	// kept along with it while it stays in the pool.
+	Annotations []string
+}
+
+// PolicyHook describes an external policy which is consulted before a
+// transaction is accepted into the pool.  It allows custom acceptance rules to
+// be implemented without modifying the mempool itself.
+type PolicyHook struct {
+	// Name is the unique name of the policy hook.
+	Name string
+
+	// Check is invoked for each transaction which has passed the built-in
+	// acceptance checks.  A nil verdict accepts the transaction without
+	// annotations.  Errors are logged and the transaction is accepted so
+	// that a misbehaving hook can't stall transaction relay.
+	//
+	// NOTE: Check is invoked with the mempool lock held, so it must not
+	// call back into the mempool and should return quickly.
+	Check func(req *PolicyRequest) (*PolicyVerdict, error)
+}
+
+var (
+	// policyHooksMtx protects policyHooks.
+	policyHooksMtx sync.RWMutex
+
+	// policyHooks holds all of the registered policy hooks by name.
+	policyHooks = make(map[string]*PolicyHook)
+)
+
+// RegisterPolicyHook adds a policy hook to the available hooks so that it can
+// be enabled by name.  An error is returned if a hook with the same name is
+// already registered.  It is typically called from the init function of a
+// package which implements the hook.
+func RegisterPolicyHook(hook PolicyHook) error {
+	policyHooksMtx.Lock()
+	defer policyHooksMtx.Unlock()
+
+	if _, exists := policyHooks[hook.Name]; exists {
+		return fmt.Errorf("policy hook %q is already registered",
+			hook.Name)
+	}
+
+	policyHooks[hook.Name] = &hook
+	return nil
+}
+
+// LookupPolicyHook returns the registered policy hook with the given name or
+// nil if there is no such hook.
+func LookupPolicyHook(name string) *PolicyHook {
+	policyHooksMtx.RLock()
+	defer policyHooksMtx.RUnlock()
+
+	return policyHooks[name]
+}
+
+// PolicyHookNames returns the sorted names of all registered policy hooks.
+func PolicyHookNames() []string {
+	policyHooksMtx.RLock()
+	defer policyHooksMtx.RUnlock()
+
+	names := make([]string, 0, len(policyHooks))
+	for name := range policyHooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkPolicyHooks runs the passed request through all of the policy hooks
+// configured for the pool.  It returns the annotations from all hooks when
+// the transaction is accepted and a rule error for the first hook which
+// rejects it otherwise.
+//
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) checkPolicyHooks(req *PolicyRequest) ([]string, error) {
+	var annotations []string
+	for _, hook := range mp.cfg.PolicyHooks {
+		verdict, err := hook.Check(req)
+		if err != nil {
+			log.Warnf("Policy hook %s failed for transaction %v: %v",
+				hook.Name, req.Tx.Hash(), err)
+			continue
+		}
+		if verdict == nil {
+			continue
+		}
+		if verdict.Reject {
+			str := fmt.Sprintf("transaction %v rejected by policy "+
+				"hook %s: %s", req.Tx.Hash(), hook.Name,
+				verdict.Reason)
+			return nil, txRuleError(wire.RejectNonstandard, str)
+		}
+		annotations = append(annotations, verdict.Annotations...)
+	}
+
+	return annotations, nil
+}
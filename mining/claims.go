@@ -0,0 +1,106 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mining
+
+import (
+	"math"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil/v2"
+	"github.com/btcsuite/btcd/txscript/v2"
+)
+
+// ClaimCounts houses the number of transactions of a block template which make
+// each kind of claim operation.  A transaction with outputs making several
+// kinds of operations is counted once for each kind.
+type ClaimCounts struct {
+	// Claims is the number of transactions which claim names.
+	Claims int
+
+	// Supports is the number of transactions which support claims.
+	Supports int
+
+	// Updates is the number of transactions which update claims.
+	Updates int
+}
+
+// claimOps returns whether the passed transaction has outputs which claim
+// names, support claims and update claims respectively.
+func claimOps(tx *btcutil.Tx) (claim, support, update bool) {
+	for _, txOut := range tx.MsgTx().TxOut {
+		if !txscript.IsClaimScript(txOut.PkScript) {
+			continue
+		}
+		c, err := txscript.DecodeClaimScript(txOut.PkScript)
+		if err != nil {
+			continue
+		}
+		switch c.Opcode {
+		case txscript.OP_CLAIMNAME:
+			claim = true
+		case txscript.OP_SUPPORTCLAIM:
+			support = true
+		case txscript.OP_UPDATECLAIM:
+			update = true
+		}
+	}
+	return claim, support, update
+}
+
+// countClaimTxns returns the claim counts of the passed transactions.
+func countClaimTxns(txns []*btcutil.Tx) ClaimCounts {
+	var counts ClaimCounts
+	for _, tx := range txns {
+		claim, support, update := claimOps(tx)
+		if claim {
+			counts.Claims++
+		}
+		if support {
+			counts.Supports++
+		}
+		if update {
+			counts.Updates++
+		}
+	}
+	return counts
+}
+
+// spentClaimHeight returns the lowest height of the blocks containing the
+// claim outputs in the main chain which are spent by the passed transaction,
+// which is the height at which the oldest of the claims was made or last
+// updated, or math.MaxInt32 when it spends no such outputs.
+func spentClaimHeight(tx *btcutil.Tx, utxos *blockchain.UtxoViewpoint) int32 {
+	height := int32(math.MaxInt32)
+	for _, txIn := range tx.MsgTx().TxIn {
+		entry := utxos.LookupEntry(txIn.PreviousOutPoint)
+		if entry == nil || entry.IsSpent() ||
+			!txscript.IsClaimScript(entry.PkScript()) {
+
+			continue
+		}
+		if entry.BlockHeight() < height {
+			height = entry.BlockHeight()
+		}
+	}
+	return height
+}
+
+// txPQClaimsFirst returns a compare function for a txPriorityQueue which sorts
+// the transactions updating or supporting claims ahead of the others and the
+// ones spending the oldest claims first among them, so the claims closest to
+// their expiration are renewed first.  The remaining ties are sorted with the
+// passed compare function.
+func txPQClaimsFirst(next txPriorityQueueLessFunc) txPriorityQueueLessFunc {
+	return func(pq *txPriorityQueue, i, j int) bool {
+		a, b := pq.items[i].prepared, pq.items[j].prepared
+		if a.claimPriority != b.claimPriority {
+			return a.claimPriority
+		}
+		if a.claimPriority && a.claimHeight != b.claimHeight {
+			return a.claimHeight < b.claimHeight
+		}
+		return next(pq, i, j)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mining
+
+import (
+	"container/heap"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/v2"
+	"github.com/btcsuite/btcd/txscript/v2"
+	"github.com/btcsuite/btcd/wire/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCountClaimTxns ensures transactions are counted once for every kind of
+// claim operation made by their outputs.
+func TestCountClaimTxns(t *testing.T) {
+	pkScript := []byte{txscript.OP_TRUE}
+	claimID := make([]byte, txscript.ClaimIDSize)
+
+	claim, err := txscript.NewClaimScript([]byte("name"), []byte("value"))
+	require.NoError(t, err)
+	support, err := txscript.NewSupportScript([]byte("name"), claimID, nil)
+	require.NoError(t, err)
+	update, err := txscript.NewUpdateScript([]byte("name"), claimID,
+		[]byte("value"))
+	require.NoError(t, err)
+
+	newTx := func(prefixes ...[]byte) *btcutil.Tx {
+		msgTx := wire.NewMsgTx(wire.TxVersion)
+		msgTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+		for _, prefix := range prefixes {
+			script := append(append([]byte{}, prefix...), pkScript...)
+			msgTx.AddTxOut(wire.NewTxOut(1000, script))
+		}
+		return btcutil.NewTx(msgTx)
+	}
+
+	txns := []*btcutil.Tx{
+		newTx(nil),
+		newTx(claim, claim),
+		newTx(support, update),
+		newTx(support),
+	}
+	require.Equal(t, ClaimCounts{Claims: 1, Supports: 2, Updates: 1},
+		countClaimTxns(txns))
+}
+
+// TestTxPQClaimsFirst ensures the claims first compare function sorts the
+// transactions updating or supporting claims ahead of the others, the ones
+// spending the oldest claims first, and defers to the passed compare function
+// otherwise.
+func TestTxPQClaimsFirst(t *testing.T) {
+	items := []*txPrioItem{
+		{feePerKB: 5000, prepared: &preparedTx{}},
+		{feePerKB: 100, prepared: &preparedTx{
+			claimPriority: true,
+			claimHeight:   20,
+		}},
+		{feePerKB: 3000, prepared: &preparedTx{}},
+		{feePerKB: 200, prepared: &preparedTx{
+			claimPriority: true,
+			claimHeight:   10,
+		}},
+		{feePerKB: 300, prepared: &preparedTx{
+			claimPriority: true,
+			claimHeight:   20,
+		}},
+	}
+	pq := newTxPriorityQueue(len(items), true)
+	pq.SetLessFunc(txPQClaimsFirst(txPQByFee))
+	for _, item := range items {
+		heap.Push(pq, item)
+	}
+
+	want := []int64{200, 300, 100, 5000, 3000}
+	for i, feePerKB := range want {
+		item := heap.Pop(pq).(*txPrioItem)
+		require.Equal(t, feePerKB, item.feePerKB, "item %d", i)
+	}
+}
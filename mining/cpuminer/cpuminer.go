@@ -7,9 +7,11 @@ package cpuminer
 import (
 	"errors"
 	"fmt"
+	"math/big"
 	"math/rand"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/btcsuite/btcd/address/v2"
@@ -33,12 +35,24 @@ const (
 	// update to the hashes per second monitor.
 	hpsUpdateSecs = 10
 
-	// hashUpdateSec is the number of seconds each worker waits in between
+	// hashUpdateSec is the number of seconds the miner waits in between
 	// notifying the speed monitor with how many hashes have been completed
-	// while they are actively searching for a solution.  This is done to
-	// reduce the amount of syncs between the workers that must be done to
-	// keep track of the hashes per second.
+	// while the workers are actively searching for a solution.  This is
+	// done to reduce the amount of syncs that must be done to keep track of
+	// the hashes per second.
 	hashUpdateSecs = 15
+
+	// hashBatchSize is the number of nonces each worker tries in between
+	// checks for whether or not the search has to be aborted.  It keeps the
+	// overhead of the checks negligible while still allowing the workers to
+	// stop within a few milliseconds.
+	hashBatchSize = 1 << 16
+
+	// staleCheckInterval is the interval at which the miner checks whether
+	// or not the best block changed while the workers are searching for a
+	// solution, so that work on stale blocks is abandoned almost
+	// immediately.
+	staleCheckInterval = time.Millisecond * 100
 )
 
 var (
@@ -62,6 +76,10 @@ type Config struct {
 	// blocks.  Each generated block will randomly choose one of them.
 	MiningAddrs []address.Address
 
+	// MiningPayouts, when set, splits the coinbase of each generated block
+	// between the payouts instead of paying to one of the MiningAddrs.
+	MiningPayouts []mining.Payout
+
 	// ProcessBlock defines the function to call with any solved blocks.
 	// It typically must run the provided block through the same set of
 	// rules and handling as any other block coming from the network.
@@ -86,20 +104,20 @@ type Config struct {
 
 // CPUMiner provides facilities for solving blocks (mining) using the CPU in
 // a concurrency-safe manner.  It consists of two main goroutines -- a speed
-// monitor and a controller for worker goroutines which generate and solve
-// blocks.  The number of goroutines can be set via the SetMaxGoRoutines
-// function, but the default is based on the number of processor cores in the
-// system which is typically sufficient.
+// monitor and a generator which creates block templates and solves them with
+// a pool of worker goroutines that all search for a solution to the same
+// template.  The number of workers can be set via the SetNumWorkers function,
+// but the default is based on the number of processor cores in the system
+// which is typically sufficient.
 type CPUMiner struct {
 	sync.Mutex
 	g                 *mining.BlkTmplGenerator
 	cfg               Config
-	numWorkers        uint32
+	numWorkers        atomic.Uint32
 	started           bool
 	discreteMining    bool
 	submitBlockLock   sync.Mutex
 	wg                sync.WaitGroup
-	workerWg          sync.WaitGroup
 	updateNumWorkers  chan struct{}
 	queryHashesPerSec chan float64
 	updateHashes      chan uint64
@@ -196,20 +214,121 @@ func (m *CPUMiner) submitBlock(block *btcutil.Block) bool {
 	return true
 }
 
+// solveWork houses the state shared by the workers which search for a solution
+// to the same block template.
+type solveWork struct {
+	// nextExtraNonce is the extra nonce the next worker which runs out of
+	// nonces to try continues with.  Taking the extra nonces from a shared
+	// counter ensures the workers never repeat the work of another one and
+	// never run out of work while the others still have some.
+	nextExtraNonce atomic.Uint64
+
+	// hashesCompleted is the number of hashes the workers completed since
+	// it was last reported to the speed monitor.
+	hashesCompleted atomic.Uint64
+
+	// timestamp is the timestamp the workers use in the block header.  It
+	// is periodically updated while the workers are searching.
+	timestamp atomic.Int64
+
+	// header, coinbase and transactions are a snapshot of the block
+	// template taken before the workers are started.  They are never
+	// modified afterwards, so the workers can copy them without
+	// synchronization, and changes are only published to the workers via
+	// the atomic fields above.
+	header       wire.BlockHeader
+	coinbase     *wire.MsgTx
+	transactions []*wire.MsgTx
+
+	blockHeight int32
+	target      *big.Int
+	abort       chan struct{}
+	solved      chan *wire.MsgBlock
+}
+
+// solveWorker searches through the nonce range of extra nonces taken from the
+// shared work until it either finds a solution, which it hands back via the
+// solved channel of the work, or the work is aborted.
+//
+// It must be run as a goroutine.
+func (m *CPUMiner) solveWorker(work *solveWork) {
+	// Each worker modifies the coinbase and header of its own copy of the
+	// block template.
+	msgBlock := &wire.MsgBlock{
+		Header:       work.header,
+		Transactions: make([]*wire.MsgTx, len(work.transactions)),
+	}
+	copy(msgBlock.Transactions, work.transactions)
+	msgBlock.Transactions[0] = work.coinbase.Copy()
+	header := &msgBlock.Header
+
+	for {
+		// Update the extra nonce in the block with the next unused
+		// value by regenerating the coinbase script and setting the
+		// merkle root to the new value.  Note that overflow will wrap
+		// around 0 as provided by the Go spec.
+		extraNonce := work.nextExtraNonce.Add(1) - 1
+		err := m.g.UpdateExtraNonce(msgBlock, work.blockHeight, extraNonce)
+		if err != nil {
+			log.Errorf("Unable to update extra nonce: %v", err)
+			return
+		}
+
+		// Search through the entire nonce range for a solution in
+		// batches while checking whether the search has to be aborted
+		// and picking up timestamp updates in between them.
+		for batch := uint64(0); batch <= uint64(maxNonce); batch += hashBatchSize {
+			select {
+			case <-work.abort:
+				return
+			default:
+				// Non-blocking select to fall through
+			}
+			header.Timestamp = time.Unix(work.timestamp.Load(), 0)
+
+			for nonce := batch; nonce < batch+hashBatchSize; nonce++ {
+				// Update the nonce and hash the block header.
+				header.Nonce = uint32(nonce)
+				hash := header.BlockHash()
+
+				// The block is solved when the new block hash
+				// is less than the target difficulty.  Yay!
+				if blockchain.HashToBig(&hash).Cmp(work.target) <= 0 {
+					// Each hash is actually a double sha256
+					// (two hashes), so the number of hashes
+					// completed is twice the number of
+					// attempts.
+					work.hashesCompleted.Add(
+						(nonce - batch + 1) * 2)
+					select {
+					case work.solved <- msgBlock:
+					default:
+					}
+					return
+				}
+			}
+			work.hashesCompleted.Add(hashBatchSize * 2)
+		}
+	}
+}
+
 // solveBlock attempts to find some combination of a nonce, extra nonce, and
-// current timestamp which makes the passed block hash to a value less than the
-// target difficulty.  The timestamp is updated periodically and the passed
-// block is modified with all tweaks during this process.  This means that
-// when the function returns true, the block is ready for submission.
+// current timestamp which makes the block of the passed template hash to a
+// value less than the target difficulty using the passed number of workers.
+// The timestamp is updated periodically during this process.  The solved block, which is ready
+// for submission, is returned when a solution is found.
 //
-// This function will return early with false when conditions that trigger a
-// stale block such as a new block showing up or periodically when there are
-// new transactions and enough time has elapsed without finding a solution.
-func (m *CPUMiner) solveBlock(msgBlock *wire.MsgBlock, blockHeight int32,
-	ticker *time.Ticker, quit chan struct{}) bool {
-
-	// Choose a random extra nonce offset for this block template and
-	// worker.
+// This function will return early with nil when conditions that trigger a
+// stale block such as a new block showing up, which is detected almost
+// immediately, or periodically when there are new transactions and enough time
+// has elapsed without finding a solution.  It also returns early with nil when
+// the number of workers changes so the search can be restarted with the new
+// number of workers, and when the full template replacing an incomplete
+// template is available.
+func (m *CPUMiner) solveBlock(template *mining.BlockTemplate,
+	numWorkers uint32, quit chan struct{}) *wire.MsgBlock {
+
+	// Choose a random extra nonce offset for this block template.
 	enOffset, err := wire.RandomUint64()
 	if err != nil {
 		log.Errorf("Unexpected error while generating random "+
@@ -217,98 +336,133 @@ func (m *CPUMiner) solveBlock(msgBlock *wire.MsgBlock, blockHeight int32,
 		enOffset = 0
 	}
 
-	// Create some convenience variables.
-	header := &msgBlock.Header
-	targetDifficulty := blockchain.CompactToBig(header.Bits)
+	// Snapshot the header and the transactions of the template for the
+	// workers.  The timestamp updates are made to a separate copy of the
+	// header below, so the workers never read memory which is written
+	// while they are searching.
+	msgBlock := template.Block
+	work := &solveWork{
+		header:       msgBlock.Header,
+		coinbase:     msgBlock.Transactions[0].Copy(),
+		transactions: make([]*wire.MsgTx, len(msgBlock.Transactions)),
+		blockHeight:  template.Height,
+		target:       blockchain.CompactToBig(msgBlock.Header.Bits),
+		abort:        make(chan struct{}),
+		solved:       make(chan *wire.MsgBlock, 1),
+	}
+	copy(work.transactions, msgBlock.Transactions)
+	work.nextExtraNonce.Store(enOffset)
+	work.timestamp.Store(work.header.Timestamp.Unix())
+
+	// timeBlock holds the header the timestamp updates are made to.
+	timeBlock := &wire.MsgBlock{Header: work.header}
+	header := &timeBlock.Header
 
 	// Initial state.
 	lastGenerated := time.Now()
 	lastTxUpdate := m.g.TxSource().LastUpdated()
-	hashesCompleted := uint64(0)
-
-	// Note that the entire extra nonce range is iterated and the offset is
-	// added relying on the fact that overflow will wrap around 0 as
-	// provided by the Go spec.
-	for extraNonce := uint64(0); extraNonce < maxExtraNonce; extraNonce++ {
-		// Update the extra nonce in the block template with the
-		// new value by regenerating the coinbase script and
-		// setting the merkle root to the new value.
-		m.g.UpdateExtraNonce(msgBlock, blockHeight, extraNonce+enOffset)
-
-		// Search through the entire nonce range for a solution while
-		// periodically checking for early quit and stale block
-		// conditions along with updates to the speed monitor.
-		for i := uint32(0); i <= maxNonce; i++ {
-			select {
-			case <-quit:
-				return false
-
-			case <-ticker.C:
-				m.updateHashes <- hashesCompleted
-				hashesCompleted = 0
-
-				// The current block is stale if the best block
-				// has changed.
-				best := m.g.BestSnapshot()
-				if !header.PrevBlock.IsEqual(&best.Hash) {
-					return false
-				}
 
-				// The current block is stale if the memory pool
-				// has been updated since the block template was
-				// generated and it has been at least one
-				// minute.
-				if lastTxUpdate != m.g.TxSource().LastUpdated() &&
-					time.Now().After(lastGenerated.Add(time.Minute)) {
+	// Start the workers.
+	var wg sync.WaitGroup
+	wg.Add(int(numWorkers))
+	for i := uint32(0); i < numWorkers; i++ {
+		go func() {
+			m.solveWorker(work)
+			wg.Done()
+		}()
+	}
 
-					return false
-				}
+	ticker := time.NewTicker(time.Second * hashUpdateSecs)
+	defer ticker.Stop()
+	staleTicker := time.NewTicker(staleCheckInterval)
+	defer staleTicker.Stop()
 
-				m.g.UpdateBlockTime(msgBlock)
+	var solved *wire.MsgBlock
+out:
+	for {
+		select {
+		case solved = <-work.solved:
+			break out
 
-			default:
-				// Non-blocking select to fall through
+		case <-quit:
+			break out
+
+		case <-m.updateNumWorkers:
+			break out
+
+		case <-template.Completed():
+			break out
+
+		case <-staleTicker.C:
+			// The current block is stale if the best block has
+			// changed.
+			best := m.g.BestSnapshot()
+			if !header.PrevBlock.IsEqual(&best.Hash) {
+				break out
 			}
 
-			// Update the nonce and hash the block header.  Each
-			// hash is actually a double sha256 (two hashes), so
-			// increment the number of hashes completed for each
-			// attempt accordingly.
-			header.Nonce = i
-			hash := header.BlockHash()
-			hashesCompleted += 2
-
-			// The block is solved when the new block hash is less
-			// than the target difficulty.  Yay!
-			if blockchain.HashToBig(&hash).Cmp(targetDifficulty) <= 0 {
-				m.updateHashes <- hashesCompleted
-				return true
+		case <-ticker.C:
+			m.updateHashes <- work.hashesCompleted.Swap(0)
+
+			// The current block is stale if the memory pool has
+			// been updated since the block template was generated
+			// and it has been at least one minute.
+			if lastTxUpdate != m.g.TxSource().LastUpdated() &&
+				time.Now().After(lastGenerated.Add(time.Minute)) {
+
+				break out
+			}
+
+			// Update the timestamp the workers use.  The target
+			// difficulty can change along with it on networks
+			// which allow minimum difficulty blocks, in which case
+			// the search is restarted with the new target.
+			bits := header.Bits
+			m.g.UpdateBlockTime(timeBlock)
+			if header.Bits != bits {
+				break out
 			}
+			work.timestamp.Store(header.Timestamp.Unix())
 		}
 	}
 
-	return false
+	// Stop the workers and account for the hashes they completed since the
+	// last update.
+	close(work.abort)
+	wg.Wait()
+	m.updateHashes <- work.hashesCompleted.Swap(0)
+
+	return solved
 }
 
-// generateBlocks is a worker that is controlled by the miningWorkerController.
-// It is self contained in that it creates block templates and attempts to solve
-// them while detecting when it is performing stale work and reacting
-// accordingly by generating a new block template.  When a block is solved, it
-// is submitted.
+// newBlockTemplate returns a new block template which either splits the
+// coinbase between the configured payouts or pays to one of the configured
+// payment addresses chosen at random.
+func (m *CPUMiner) newBlockTemplate() (*mining.BlockTemplate, error) {
+	if len(m.cfg.MiningPayouts) > 0 {
+		return m.g.NewSplitBlockTemplate(m.cfg.MiningPayouts)
+	}
+
+	// Choose a payment address at random.
+	rand.Seed(time.Now().UnixNano())
+	payToAddr := m.cfg.MiningAddrs[rand.Intn(len(m.cfg.MiningAddrs))]
+	return m.g.NewBlockTemplate(payToAddr)
+}
+
+// generateBlocks creates block templates and attempts to solve them with the
+// configured number of workers while detecting when it is performing stale
+// work and reacting accordingly by generating a new block template.  When a
+// block is solved, it is submitted.
 //
 // It must be run as a goroutine.
-func (m *CPUMiner) generateBlocks(quit chan struct{}) {
+func (m *CPUMiner) generateBlocks() {
 	log.Tracef("Starting generate blocks worker")
 
-	// Start a ticker which is used to signal checks for stale work and
-	// updates to the speed monitor.
-	ticker := time.NewTicker(time.Second * hashUpdateSecs)
-	defer ticker.Stop()
 out:
 	for {
 		// Quit when the miner is stopped.
 		select {
-		case <-quit:
+		case <-m.quit:
 			break out
 		default:
 			// Non-blocking select to fall through
@@ -335,14 +489,10 @@ out:
 			continue
 		}
 
-		// Choose a payment address at random.
-		rand.Seed(time.Now().UnixNano())
-		payToAddr := m.cfg.MiningAddrs[rand.Intn(len(m.cfg.MiningAddrs))]
-
 		// Create a new block template using the available transactions
 		// in the memory pool as a source of transactions to potentially
 		// include in the block.
-		template, err := m.g.NewBlockTemplate(payToAddr)
+		template, err := m.newBlockTemplate()
 		m.submitBlockLock.Unlock()
 		if err != nil {
 			errStr := fmt.Sprintf("Failed to create new block "+
@@ -352,79 +502,20 @@ out:
 		}
 
 		// Attempt to solve the block.  The function will exit early
-		// with false when conditions that trigger a stale block, so
-		// a new block template can be generated.  When the return is
-		// true a solution was found, so submit the solved block.
-		if m.solveBlock(template.Block, curHeight+1, ticker, quit) {
-			block := btcutil.NewBlock(template.Block)
-			m.submitBlock(block)
+		// with nil when conditions that trigger a stale block, so a new
+		// block template can be generated.  When a solved block is
+		// returned, submit it.
+		solved := m.solveBlock(template, m.numWorkers.Load(), m.quit)
+		if solved != nil {
+			m.submitBlock(btcutil.NewBlock(solved))
 		}
 	}
 
-	m.workerWg.Done()
-	log.Tracef("Generate blocks worker done")
-}
-
-// miningWorkerController launches the worker goroutines that are used to
-// generate block templates and solve them.  It also provides the ability to
-// dynamically adjust the number of running worker goroutines.
-//
-// It must be run as a goroutine.
-func (m *CPUMiner) miningWorkerController() {
-	// launchWorkers groups common code to launch a specified number of
-	// workers for generating blocks.
-	var runningWorkers []chan struct{}
-	launchWorkers := func(numWorkers uint32) {
-		for i := uint32(0); i < numWorkers; i++ {
-			quit := make(chan struct{})
-			runningWorkers = append(runningWorkers, quit)
-
-			m.workerWg.Add(1)
-			go m.generateBlocks(quit)
-		}
-	}
-
-	// Launch the current number of workers by default.
-	runningWorkers = make([]chan struct{}, 0, m.numWorkers)
-	launchWorkers(m.numWorkers)
-
-out:
-	for {
-		select {
-		// Update the number of running workers.
-		case <-m.updateNumWorkers:
-			// No change.
-			numRunning := uint32(len(runningWorkers))
-			if m.numWorkers == numRunning {
-				continue
-			}
-
-			// Add new workers.
-			if m.numWorkers > numRunning {
-				launchWorkers(m.numWorkers - numRunning)
-				continue
-			}
-
-			// Signal the most recently created goroutines to exit.
-			for i := numRunning - 1; i >= m.numWorkers; i-- {
-				close(runningWorkers[i])
-				runningWorkers[i] = nil
-				runningWorkers = runningWorkers[:i]
-			}
-
-		case <-m.quit:
-			for _, quit := range runningWorkers {
-				close(quit)
-			}
-			break out
-		}
-	}
-
-	// Wait until all workers shut down to stop the speed monitor since
-	// they rely on being able to send updates to it.
-	m.workerWg.Wait()
+	// Stop the speed monitor now that the workers no longer send updates
+	// to it.
 	close(m.speedMonitorQuit)
 	m.wg.Done()
+	log.Tracef("Generate blocks worker done")
 }
 
 // Start begins the CPU mining process as well as the speed monitor used to
@@ -446,7 +537,7 @@ func (m *CPUMiner) Start() {
 	m.speedMonitorQuit = make(chan struct{})
 	m.wg.Add(2)
 	go m.speedMonitor()
-	go m.miningWorkerController()
+	go m.generateBlocks()
 
 	m.started = true
 	log.Infof("CPU miner started")
@@ -503,7 +594,8 @@ func (m *CPUMiner) HashesPerSecond() float64 {
 // SetNumWorkers sets the number of workers to create which solve blocks.  Any
 // negative values will cause a default number of workers to be used which is
 // based on the number of processor cores in the system.  A value of 0 will
-// cause all CPU mining to be stopped.
+// cause all CPU mining to be stopped.  The search for a solution to the
+// current block is restarted with the new number of workers.
 //
 // This function is safe for concurrent access.
 func (m *CPUMiner) SetNumWorkers(numWorkers int32) {
@@ -518,15 +610,18 @@ func (m *CPUMiner) SetNumWorkers(numWorkers int32) {
 
 	// Use default if provided value is negative.
 	if numWorkers < 0 {
-		m.numWorkers = defaultNumWorkers
+		m.numWorkers.Store(defaultNumWorkers)
 	} else {
-		m.numWorkers = uint32(numWorkers)
+		m.numWorkers.Store(uint32(numWorkers))
 	}
 
-	// When the miner is already running, notify the controller about the
-	// the change.
+	// When the miner is already running, notify it about the change unless
+	// a notification is already pending.
 	if m.started {
-		m.updateNumWorkers <- struct{}{}
+		select {
+		case m.updateNumWorkers <- struct{}{}:
+		default:
+		}
 	}
 }
 
@@ -537,7 +632,7 @@ func (m *CPUMiner) NumWorkers() int32 {
 	m.Lock()
 	defer m.Unlock()
 
-	return int32(m.numWorkers)
+	return int32(m.numWorkers.Load())
 }
 
 // GenerateNBlocks generates the requested number of blocks. It is self
@@ -546,6 +641,46 @@ func (m *CPUMiner) NumWorkers() int32 {
 // generating a new block template.  When a block is solved, it is submitted.
 // The function returns a list of the hashes of generated blocks.
 func (m *CPUMiner) GenerateNBlocks(n uint32) ([]*chainhash.Hash, error) {
+	return m.generateNBlocks(n, m.newBlockTemplate)
+}
+
+// GenerateNBlocksToAddress generates the requested number of blocks which pay
+// to the passed address instead of the configured payment addresses.  See
+// GenerateNBlocks for more details.
+func (m *CPUMiner) GenerateNBlocksToAddress(n uint32,
+	payToAddr address.Address) ([]*chainhash.Hash, error) {
+
+	return m.generateNBlocks(n, func() (*mining.BlockTemplate, error) {
+		return m.g.NewBlockTemplate(payToAddr)
+	})
+}
+
+// GenerateBlock generates a block which contains exactly the passed
+// transactions in the passed order after a coinbase paying to the passed
+// address.  An error is returned when the transactions do not form a valid
+// block on top of the current best chain.  The function returns the hash of
+// the generated block.
+func (m *CPUMiner) GenerateBlock(payToAddr address.Address,
+	txns []*btcutil.Tx) (*chainhash.Hash, error) {
+
+	blockHashes, err := m.generateNBlocks(1,
+		func() (*mining.BlockTemplate, error) {
+			return m.g.NewBlockTemplateFromTxns(payToAddr, txns)
+		})
+	if err != nil {
+		return nil, err
+	}
+	return blockHashes[0], nil
+}
+
+// generateNBlocks generates the requested number of blocks from the block
+// templates returned by the passed function.  The templates are solved with all
+// of the configured workers, and a new template is requested whenever the
+// current one becomes stale.  An error is returned when a template can't be
+// created or a solved block is rejected.
+func (m *CPUMiner) generateNBlocks(n uint32,
+	newBlockTemplate func() (*mining.BlockTemplate, error)) ([]*chainhash.Hash, error) {
+
 	m.Lock()
 
 	// Respond with an error if server is already mining.
@@ -564,20 +699,22 @@ func (m *CPUMiner) GenerateNBlocks(n uint32) ([]*chainhash.Hash, error) {
 
 	m.Unlock()
 
-	log.Tracef("Generating %d blocks", n)
-
-	i := uint32(0)
-	blockHashes := make([]*chainhash.Hash, n)
+	defer func() {
+		m.Lock()
+		close(m.speedMonitorQuit)
+		m.wg.Wait()
+		m.started = false
+		m.discreteMining = false
+		m.Unlock()
+	}()
 
-	// Start a ticker which is used to signal checks for stale work and
-	// updates to the speed monitor.
-	ticker := time.NewTicker(time.Second * hashUpdateSecs)
-	defer ticker.Stop()
+	log.Tracef("Generating %d blocks", n)
 
-	for {
+	blockHashes := make([]*chainhash.Hash, 0, n)
+	for uint32(len(blockHashes)) < n {
 		// Read updateNumWorkers in case someone tries a `setgenerate` while
-		// we're generating. We can ignore it as the `generate` RPC call only
-		// uses 1 worker.
+		// we're generating.  We can ignore it as the number of workers is
+		// read for every block anyways.
 		select {
 		case <-m.updateNumWorkers:
 		default:
@@ -587,57 +724,57 @@ func (m *CPUMiner) GenerateNBlocks(n uint32) ([]*chainhash.Hash, error) {
 		// be changing and this would otherwise end up building a new block
 		// template on a block that is in the process of becoming stale.
 		m.submitBlockLock.Lock()
-		curHeight := m.g.BestSnapshot().Height
-
-		// Choose a payment address at random.
-		rand.Seed(time.Now().UnixNano())
-		payToAddr := m.cfg.MiningAddrs[rand.Intn(len(m.cfg.MiningAddrs))]
-
-		// Create a new block template using the available transactions
-		// in the memory pool as a source of transactions to potentially
-		// include in the block.
-		template, err := m.g.NewBlockTemplate(payToAddr)
+		template, err := newBlockTemplate()
 		m.submitBlockLock.Unlock()
 		if err != nil {
-			errStr := fmt.Sprintf("Failed to create new block "+
+			return nil, fmt.Errorf("failed to create new block "+
 				"template: %v", err)
-			log.Errorf(errStr)
+		}
+
+		// Blocks generated on request are expected to include the
+		// available transactions, so wait for the full template when
+		// an empty one was handed out first.
+		if template.Incomplete {
+			<-template.Completed()
 			continue
 		}
 
-		// Attempt to solve the block.  The function will exit early
-		// with false when conditions that trigger a stale block, so
-		// a new block template can be generated.  When the return is
-		// true a solution was found, so submit the solved block.
-		if m.solveBlock(template.Block, curHeight+1, ticker, nil) {
-			block := btcutil.NewBlock(template.Block)
-			m.submitBlock(block)
-			blockHashes[i] = block.Hash()
-			i++
-			if i == n {
-				log.Tracef("Generated %d blocks", i)
-				m.Lock()
-				close(m.speedMonitorQuit)
-				m.wg.Wait()
-				m.started = false
-				m.discreteMining = false
-				m.Unlock()
-				return blockHashes, nil
-			}
+		// Attempt to solve the block using all of the workers, but at
+		// least one since the caller waits for the blocks.  The
+		// function will exit early with nil when conditions that
+		// trigger a stale block, so a new block template can be
+		// generated.  When a solved block is returned, submit it.
+		numWorkers := m.numWorkers.Load()
+		if numWorkers == 0 {
+			numWorkers = 1
 		}
+		solved := m.solveBlock(template, numWorkers, nil)
+		if solved == nil {
+			continue
+		}
+		block := btcutil.NewBlock(solved)
+		if !m.submitBlock(block) {
+			return nil, fmt.Errorf("generated block %v was rejected",
+				block.Hash())
+		}
+		blockHashes = append(blockHashes, block.Hash())
 	}
+
+	log.Tracef("Generated %d blocks", n)
+	return blockHashes, nil
 }
 
 // New returns a new instance of a CPU miner for the provided configuration.
 // Use Start to begin the mining process.  See the documentation for CPUMiner
 // type for more details.
 func New(cfg *Config) *CPUMiner {
-	return &CPUMiner{
+	m := &CPUMiner{
 		g:                 cfg.BlockTemplateGenerator,
 		cfg:               *cfg,
-		numWorkers:        defaultNumWorkers,
-		updateNumWorkers:  make(chan struct{}),
+		updateNumWorkers:  make(chan struct{}, 1),
 		queryHashesPerSec: make(chan float64),
 		updateHashes:      make(chan uint64),
 	}
+	m.numWorkers.Store(defaultNumWorkers)
+	return m
 }
@@ -0,0 +1,140 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package cpuminer
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil/v2"
+	"github.com/btcsuite/btcd/chaincfg/v2"
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/database"
+	_ "github.com/btcsuite/btcd/database/ffldb"
+	"github.com/btcsuite/btcd/mining"
+	"github.com/btcsuite/btcd/txscript/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// emptyTxSource is a mining.TxSource without any transactions.
+type emptyTxSource struct{}
+
+func (emptyTxSource) LastUpdated() time.Time               { return time.Time{} }
+func (emptyTxSource) MiningDescs() []*mining.TxDesc        { return nil }
+func (emptyTxSource) HaveTransaction(*chainhash.Hash) bool { return false }
+
+// newTestMiner returns a cpu miner which generates templates on top of a new
+// regression test chain along with the chain.  The hash rate updates of the
+// miner are drained for the duration of the test.
+func newTestMiner(t *testing.T) (*CPUMiner, *blockchain.BlockChain) {
+	t.Helper()
+
+	params := chaincfg.RegressionNetParams
+	db, err := database.Create("ffldb", filepath.Join(t.TempDir(), "db"),
+		params.Net)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	timeSource := blockchain.NewMedianTime()
+	sigCache := txscript.NewSigCache(1000)
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:          db,
+		ChainParams: &params,
+		TimeSource:  timeSource,
+		SigCache:    sigCache,
+	})
+	require.NoError(t, err)
+
+	policy := mining.Policy{BlockMaxWeight: blockchain.MaxBlockWeight}
+	g := mining.NewBlkTmplGenerator(&policy, &params, emptyTxSource{},
+		chain, timeSource, sigCache, txscript.NewHashCache(1000))
+	m := New(&Config{
+		ChainParams:            &params,
+		BlockTemplateGenerator: g,
+	})
+
+	quit := make(chan struct{})
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		close(quit)
+		<-done
+	})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-m.updateHashes:
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	return m, chain
+}
+
+// TestSolveBlock ensures multiple workers find a valid solution to a template
+// without modifying the template they share.
+func TestSolveBlock(t *testing.T) {
+	m, chain := newTestMiner(t)
+
+	template, err := m.g.NewBlockTemplate(nil)
+	require.NoError(t, err)
+	wantHeader := template.Block.Header
+	wantCoinbase := template.Block.Transactions[0].Copy()
+
+	solved := m.solveBlock(template, 4, nil)
+	require.NotNil(t, solved)
+	require.Equal(t, wantHeader, template.Block.Header)
+	require.Equal(t, wantCoinbase, template.Block.Transactions[0])
+
+	isMainChain, isOrphan, err := chain.ProcessBlock(
+		btcutil.NewBlock(solved), blockchain.BFNone)
+	require.NoError(t, err)
+	require.True(t, isMainChain)
+	require.False(t, isOrphan)
+}
+
+// TestSolveBlockNewTip ensures the workers stop searching for a solution to a
+// template once a new block extends the best chain.
+func TestSolveBlockNewTip(t *testing.T) {
+	m, chain := newTestMiner(t)
+
+	// Solve a block to connect while another template is being solved.
+	template, err := m.g.NewBlockTemplate(nil)
+	require.NoError(t, err)
+	block := m.solveBlock(template, 2, nil)
+	require.NotNil(t, block)
+
+	// Make the second template impossible to solve.
+	stale, err := m.g.NewBlockTemplate(nil)
+	require.NoError(t, err)
+	stale.Block.Header.Bits = 0x03000001
+
+	result := make(chan bool, 1)
+	go func() {
+		result <- m.solveBlock(stale, 4, nil) == nil
+	}()
+
+	// Give the workers a chance to start searching.
+	select {
+	case <-result:
+		t.Fatal("solveBlock returned before a new tip was connected")
+	case <-time.After(2 * staleCheckInterval):
+	}
+
+	_, _, err = chain.ProcessBlock(btcutil.NewBlock(block),
+		blockchain.BFNone)
+	require.NoError(t, err)
+
+	select {
+	case aborted := <-result:
+		require.True(t, aborted)
+	case <-time.After(10 * time.Second):
+		t.Fatal("solveBlock did not abort on a new tip")
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"bytes"
 	"container/heap"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/btcsuite/btcd/address/v2"
@@ -32,6 +34,11 @@ const (
 	// and is used to monitor BIP16 support as well as blocks that are
 	// generated via btcd.
 	CoinbaseFlags = "/P2SH/btcd/"
+
+	// MaxSignalBit is the highest version bit which can be signalled in
+	// generated blocks.  The bits above it are reserved by the version
+	// bits scheme.
+	MaxSignalBit = 28
 )
 
 // TxDesc is a descriptor about a transaction in a transaction source along with
@@ -87,6 +94,10 @@ type txPrioItem struct {
 	// transactions in the source pool and hence must come after them in
 	// a block.
 	dependsOn map[chainhash.Hash]struct{}
+
+	// prepared houses the cached details about the transaction which are
+	// reused by subsequent templates for the same block.
+	prepared *preparedTx
 }
 
 // txPriorityQueueLessFunc describes a function that can be used as a compare
@@ -218,19 +229,41 @@ type BlockTemplate struct {
 	// witness has been activated, and the block contains a transaction
 	// which has witness data.
 	WitnessCommitment []byte
+
+	// ClaimCounts contains the number of transactions in the generated
+	// template which claim names, support claims and update claims.
+	ClaimCounts ClaimCounts
+
+	// Incomplete indicates the template was handed out without any
+	// transactions while the transactions for the full template are still
+	// being selected.  See Completed for details.
+	Incomplete bool
+
+	// completed is closed once the full template which replaces an
+	// incomplete template is available.
+	completed chan struct{}
+}
+
+// Completed returns a channel which is closed once the full template for the
+// same block is available from the generator when the template is incomplete.
+// Callers are expected to request a new template at that point.  The returned
+// channel is nil, so it is never ready, for templates which are complete.
+func (t *BlockTemplate) Completed() <-chan struct{} {
+	return t.completed
 }
 
-// mergeUtxoView adds all of the entries in viewB to viewA.  The result is that
-// viewA will contain all of its original entries plus all of the entries
-// in viewB.  It will replace any entries in viewB which also exist in viewA
-// if the entry in viewA is spent.
+// mergeUtxoView adds copies of all of the entries in viewB to viewA.  The result
+// is that viewA will contain all of its original entries plus all of the
+// entries in viewB.  It will replace any entries in viewB which also exist in
+// viewA if the entry in viewA is spent.  The entries are copied so that
+// spending them in viewA does not modify viewB.
 func mergeUtxoView(viewA *blockchain.UtxoViewpoint, viewB *blockchain.UtxoViewpoint) {
 	viewAEntries := viewA.Entries()
 	for outpoint, entryB := range viewB.Entries() {
 		if entryA, exists := viewAEntries[outpoint]; !exists ||
 			entryA == nil || entryA.IsSpent() {
 
-			viewAEntries[outpoint] = entryB
+			viewAEntries[outpoint] = entryB.Clone()
 		}
 	}
 }
@@ -246,31 +279,35 @@ func standardCoinbaseScript(nextBlockHeight int32, extraNonce uint64) ([]byte, e
 }
 
 // createCoinbaseTx returns a coinbase transaction paying an appropriate subsidy
-// based on the passed block height to the provided address.  When the address
-// is nil, the coinbase transaction will instead be redeemable by anyone.
+// based on the passed block height split between the provided payouts.  When
+// there are no payouts, the coinbase transaction will instead be redeemable by
+// anyone.
 //
 // See the comment for NewBlockTemplate for more information about why the nil
 // address handling is useful.
 func createCoinbaseTx(params *chaincfg.Params, coinbaseScript []byte,
-	nextBlockHeight int32, addr address.Address) (*btcutil.Tx, error) {
+	nextBlockHeight int32, payouts []Payout) (*btcutil.Tx, error) {
 
-	// Create the script to pay to the provided payment address if one was
+	// Create the scripts to pay to the provided payouts if any were
 	// specified.  Otherwise create a script that allows the coinbase to be
 	// redeemable by anyone.
-	var pkScript []byte
-	if addr != nil {
-		var err error
-		pkScript, err = txscript.PayToAddrScript(addr)
-		if err != nil {
-			return nil, err
+	var pkScripts [][]byte
+	if len(payouts) > 0 {
+		pkScripts = make([][]byte, 0, len(payouts))
+		for _, payout := range payouts {
+			pkScript, err := txscript.PayToAddrScript(payout.Address)
+			if err != nil {
+				return nil, err
+			}
+			pkScripts = append(pkScripts, pkScript)
 		}
 	} else {
-		var err error
 		scriptBuilder := txscript.NewScriptBuilder()
-		pkScript, err = scriptBuilder.AddOp(txscript.OP_TRUE).Script()
+		pkScript, err := scriptBuilder.AddOp(txscript.OP_TRUE).Script()
 		if err != nil {
 			return nil, err
 		}
+		pkScripts = append(pkScripts, pkScript)
 	}
 
 	tx := wire.NewMsgTx(wire.TxVersion)
@@ -282,10 +319,13 @@ func createCoinbaseTx(params *chaincfg.Params, coinbaseScript []byte,
 		SignatureScript: coinbaseScript,
 		Sequence:        wire.MaxTxInSequenceNum,
 	})
-	tx.AddTxOut(&wire.TxOut{
-		Value:    blockchain.CalcBlockSubsidy(nextBlockHeight, params),
-		PkScript: pkScript,
-	})
+	subsidy := blockchain.CalcBlockSubsidy(nextBlockHeight, params)
+	for i, value := range splitCoinbaseValue(subsidy, payouts) {
+		tx.AddTxOut(&wire.TxOut{
+			Value:    value,
+			PkScript: pkScripts[i],
+		})
+	}
 	return btcutil.NewTx(tx), nil
 }
 
@@ -356,6 +396,15 @@ type BlkTmplGenerator struct {
 	timeSource  blockchain.MedianTimeSource
 	sigCache    *txscript.SigCache
 	hashCache   *txscript.HashCache
+
+	// cacheMtx protects cache and serializes the generation of templates
+	// so concurrent callers benefit from each other's work.
+	cacheMtx sync.Mutex
+	cache    *templateCache
+
+	// signalBits is the bitmask of the additional version bits to signal
+	// in generated blocks.
+	signalBits atomic.Uint32
 }
 
 // NewBlkTmplGenerator returns a new block template generator for the given
@@ -370,8 +419,15 @@ func NewBlkTmplGenerator(policy *Policy, params *chaincfg.Params,
 	sigCache *txscript.SigCache,
 	hashCache *txscript.HashCache) *BlkTmplGenerator {
 
-	return &BlkTmplGenerator{
-		policy:      policy,
+	// Limit the signature operation cost to the consensus limit when no
+	// limit is set.  The policy is copied so the caller's isn't modified.
+	policyCopy := *policy
+	if policyCopy.BlockMaxSigOpCost == 0 {
+		policyCopy.BlockMaxSigOpCost = blockchain.MaxBlockSigOpsCost
+	}
+
+	g := &BlkTmplGenerator{
+		policy:      &policyCopy,
 		chainParams: params,
 		txSource:    txSource,
 		chain:       chain,
@@ -379,6 +435,8 @@ func NewBlkTmplGenerator(policy *Policy, params *chaincfg.Params,
 		sigCache:    sigCache,
 		hashCache:   hashCache,
 	}
+	g.signalBits.Store(policy.SignalBits)
+	return g
 }
 
 // NewBlockTemplate returns a new block template that is ready to be solved
@@ -417,9 +475,20 @@ func NewBlkTmplGenerator(policy *Policy, params *chaincfg.Params,
 // nonzero, in which case the block will be filled with the low-fee/free
 // transactions until the block size reaches that minimum size.
 //
-// Any transactions which would cause the block to exceed the BlockMaxSize
-// policy setting, exceed the maximum allowed signature operations per block, or
-// otherwise cause the block to be invalid are skipped.
+// Transactions which pay less than the BlockMinTxFee policy setting are never
+// included, regardless of their priority.
+//
+// Any transactions which would cause the block to exceed the BlockMaxSize or
+// BlockMaxSigOpCost policy settings, or otherwise cause the block to be invalid
+// are skipped.
+//
+// The details about each transaction which only depend on the current best
+// block, such as the referenced outputs, the priority, and whether or not the
+// inputs and scripts are valid, are cached.  Subsequent templates for the same
+// block only need to look up and validate the transactions which were added to
+// the source pool since while transactions which left it are dropped.  The
+// assembled template itself is handed out again when the source pool has not
+// changed at all.
 //
 // Given the above, a block generated by this function is of the following form:
 //
@@ -446,12 +515,89 @@ func NewBlkTmplGenerator(policy *Policy, params *chaincfg.Params,
 func (g *BlkTmplGenerator) NewBlockTemplate(
 	payToAddress address.Address) (*BlockTemplate, error) {
 
+	var payouts []Payout
+	if payToAddress != nil {
+		payouts = []Payout{{
+			Address: payToAddress,
+			Weight:  PayoutWeightTotal,
+		}}
+	}
+	return g.NewSplitBlockTemplate(payouts)
+}
+
+// NewSplitBlockTemplate returns a new block template which is the same as the
+// one returned by NewBlockTemplate except that the coinbase value is split
+// between the provided payouts according to their weights instead of being
+// paid to a single address.  The weights must add up to PayoutWeightTotal.
+// When there are no payouts, the coinbase is redeemable by anyone.
+func (g *BlkTmplGenerator) NewSplitBlockTemplate(
+	payouts []Payout) (*BlockTemplate, error) {
+
+	if len(payouts) > 0 {
+		var totalWeight uint32
+		for _, payout := range payouts {
+			totalWeight += payout.Weight
+		}
+		if totalWeight != PayoutWeightTotal {
+			return nil, fmt.Errorf("payout weights add up to %d "+
+				"instead of %d", totalWeight, PayoutWeightTotal)
+		}
+	}
+
+	g.cacheMtx.Lock()
+	defer g.cacheMtx.Unlock()
+
 	// Extend the most recently known best block.
 	best := g.chain.BestSnapshot()
 	nextBlockHeight := best.Height + 1
 
+	// The cached state only applies to templates which build on the block
+	// it was created for, so start over when the best block changed.
+	// Otherwise, hand out the most recently assembled template again with
+	// an updated timestamp when the source pool has not changed since.
+	if g.cache == nil || g.cache.prevHash != best.Hash {
+		g.cache = newTemplateCache(&best.Hash)
+
+		// Hand out a template without any transactions right away when
+		// configured to do so and select the transactions for the full
+		// template in the background.  This allows miners to switch to
+		// the new block as quickly as possible.
+		if g.policy.EmptyBlockFirst {
+			template, err := g.newBlockTemplateFromTxns(payouts, nil)
+			if err != nil {
+				return nil, err
+			}
+			template.Incomplete = true
+			template.completed = make(chan struct{})
+			go g.completeTemplate(&best.Hash, payouts,
+				template.completed)
+
+			log.Debugf("Created empty block template for block "+
+				"height %d", template.Height)
+			return template, nil
+		}
+	}
+	lastUpdated := g.txSource.LastUpdated()
+	template := g.cache.reusableTemplate(payouts, lastUpdated,
+		time.Now())
+	if template != nil {
+		// The signal bits might have changed since the template was
+		// assembled.
+		version, err := g.nextBlockVersion()
+		if err != nil {
+			return nil, err
+		}
+		template.Block.Header.Version = version
+		if err := g.UpdateBlockTime(template.Block); err != nil {
+			return nil, err
+		}
+		log.Debugf("Reusing cached block template (%d transactions)",
+			len(template.Block.Transactions))
+		return template, nil
+	}
+
 	// Create a standard coinbase transaction paying to the provided
-	// address.  NOTE: The coinbase value will be updated to include the
+	// payouts.  NOTE: The coinbase value will be updated to include the
 	// fees from the selected transactions later after they have actually
 	// been selected.  It is created here to detect any errors early
 	// before potentially doing a lot of work below.  The extra nonce helps
@@ -464,7 +610,7 @@ func (g *BlkTmplGenerator) NewBlockTemplate(
 		return nil, err
 	}
 	coinbaseTx, err := createCoinbaseTx(g.chainParams, coinbaseScript,
-		nextBlockHeight, payToAddress)
+		nextBlockHeight, payouts)
 	if err != nil {
 		return nil, err
 	}
@@ -480,6 +626,13 @@ func (g *BlkTmplGenerator) NewBlockTemplate(
 	sortedByFee := g.policy.BlockPrioritySize == 0
 	priorityQueue := newTxPriorityQueue(len(sourceTxns), sortedByFee)
 
+	// Sort the transactions which update or support claims first while
+	// there is room reserved for them.
+	claimsFirst := g.policy.ClaimPriorityWeight > 0
+	if claimsFirst {
+		priorityQueue.SetLessFunc(txPQClaimsFirst(priorityQueue.lessFunc))
+	}
+
 	// Create a slice to hold the transactions to be included in the
 	// generated block with reserved space.  Also create a utxo view to
 	// house all of the input transactions so multiple lookups can be
@@ -509,6 +662,12 @@ func (g *BlkTmplGenerator) NewBlockTemplate(
 	log.Debugf("Considering %d transactions for inclusion to new block",
 		len(sourceTxns))
 
+	// seen tracks the witness hashes of all source transactions so the
+	// prepared details of transactions which left the source pool can be
+	// dropped from the cache.
+	seen := make(map[chainhash.Hash]struct{}, len(sourceTxns))
+	numPrepared := 0
+
 mempoolLoop:
 	for _, txDesc := range sourceTxns {
 		// A block can't have more than one coinbase or contain
@@ -525,22 +684,53 @@ mempoolLoop:
 			continue
 		}
 
-		// Fetch all of the utxos referenced by this transaction.
-		// NOTE: This intentionally does not fetch inputs from the
-		// mempool since a transaction which depends on other
-		// transactions in the mempool must come after those
-		// dependencies in the final generated block.
-		utxos, err := g.chain.FetchUtxoView(tx)
-		if err != nil {
-			log.Warnf("Unable to fetch utxo view for tx %s: %v",
-				tx.Hash(), err)
+		// Prepare the transaction unless it was already prepared for
+		// a previous template building on the same block.
+		witnessHash := *tx.WitnessHash()
+		seen[witnessHash] = struct{}{}
+		prepared, ok := g.cache.txns[witnessHash]
+		if !ok {
+			// Fetch all of the utxos referenced by this
+			// transaction.  NOTE: This intentionally does not fetch
+			// inputs from the mempool since a transaction which
+			// depends on other transactions in the mempool must
+			// come after those dependencies in the final generated
+			// block.
+			utxos, err := g.chain.FetchUtxoView(tx)
+			if err != nil {
+				log.Warnf("Unable to fetch utxo view for tx "+
+					"%s: %v", tx.Hash(), err)
+				continue
+			}
+
+			// Calculate the final transaction priority using the
+			// input value age sum as well as the adjusted
+			// transaction size.  The formula is:
+			// sum(inputValue * inputAge) / adjustedTxSize
+			prepared = &preparedTx{
+				utxos: utxos,
+				priority: CalcPriority(tx.MsgTx(), utxos,
+					nextBlockHeight),
+			}
+			_, support, update := claimOps(tx)
+			if support || update {
+				prepared.claimPriority = true
+				prepared.claimHeight = spentClaimHeight(tx, utxos)
+			}
+			g.cache.txns[witnessHash] = prepared
+			numPrepared++
+		}
+		if prepared.invalid {
+			log.Tracef("Skipping tx %s which failed validation",
+				tx.Hash())
 			continue
 		}
+		utxos := prepared.utxos
 
 		// Setup dependencies for any transactions which reference
 		// other transactions in the mempool so they can be properly
 		// ordered below.
-		prioItem := &txPrioItem{tx: tx}
+		prioItem := &txPrioItem{tx: tx, prepared: prepared}
 		for _, txIn := range tx.MsgTx().TxIn {
 			originHash := &txIn.PreviousOutPoint.Hash
 			entry := utxos.LookupEntry(txIn.PreviousOutPoint)
@@ -571,13 +761,9 @@ mempoolLoop:
 			}
 		}
 
-		// Calculate the final transaction priority using the input
-		// value age sum as well as the adjusted transaction size.  The
-		// formula is: sum(inputValue * inputAge) / adjustedTxSize
-		prioItem.priority = CalcPriority(tx.MsgTx(), utxos,
-			nextBlockHeight)
-
-		// Calculate the fee in Satoshi/kB.
+		// Use the priority calculated when the transaction was
+		// prepared and calculate the fee in Satoshi/kB.
+		prioItem.priority = prepared.priority
 		prioItem.feePerKB = txDesc.FeePerKB
 		prioItem.fee = txDesc.Fee
 
@@ -595,6 +781,8 @@ mempoolLoop:
 
 	log.Tracef("Priority queue len %d, dependers len %d",
 		priorityQueue.Len(), len(dependers))
+	log.Debugf("Prepared %d new transactions, reused %d prepared "+
+		"transactions", numPrepared, len(seen)-numPrepared)
 
 	// The starting block size is the size of the block header plus the max
 	// possible transaction count size, plus the size of the coinbase
@@ -665,6 +853,16 @@ mempoolLoop:
 		// Grab any transactions which depend on this one.
 		deps := dependers[*tx.Hash()]
 
+		// Skip transactions which do not pay the minimum fee required
+		// for inclusion regardless of their priority.
+		if prioItem.feePerKB < int64(g.policy.BlockMinTxFee) {
+			log.Tracef("Skipping tx %s with feePerKB %d < "+
+				"BlockMinTxFee %d", tx.Hash(), prioItem.feePerKB,
+				g.policy.BlockMinTxFee)
+			logSkippedDeps(tx, deps)
+			continue
+		}
+
 		// Enforce maximum block size.  Also check for overflow.
 		txWeight := uint32(blockchain.GetTransactionWeight(tx))
 		blockPlusTxWeight := blockWeight + txWeight
@@ -678,19 +876,28 @@ mempoolLoop:
 		}
 
 		// Enforce maximum signature operation cost per block.  Also
-		// check for overflow.
-		sigOpCost, err := blockchain.GetSigOpCost(tx, false,
-			blockUtxos, true, segwitActive)
-		if err != nil {
-			log.Tracef("Skipping tx %s due to error in "+
-				"GetSigOpCost: %v", tx.Hash(), err)
-			logSkippedDeps(tx, deps)
-			continue
+		// check for overflow.  The cost only depends on the outputs
+		// the transaction spends, so it is calculated once per block.
+		prepared := prioItem.prepared
+		if !prepared.sigOpCostKnown {
+			sigOpCost, err := blockchain.GetSigOpCost(tx, false,
+				blockUtxos, true, segwitActive)
+			if err != nil {
+				log.Tracef("Skipping tx %s due to error in "+
+					"GetSigOpCost: %v", tx.Hash(), err)
+				logSkippedDeps(tx, deps)
+				continue
+			}
+			prepared.sigOpCost = sigOpCost
+			prepared.sigOpCostKnown = true
 		}
+		sigOpCost := prepared.sigOpCost
 		if blockSigOpCost+int64(sigOpCost) < blockSigOpCost ||
-			blockSigOpCost+int64(sigOpCost) > blockchain.MaxBlockSigOpsCost {
+			blockSigOpCost+int64(sigOpCost) >
+				int64(g.policy.BlockMaxSigOpCost) {
+
 			log.Tracef("Skipping tx %s because it would "+
-				"exceed the maximum sigops per block", tx.Hash())
+				"exceed the max block sigop cost", tx.Hash())
 			logSkippedDeps(tx, deps)
 			continue
 		}
@@ -710,6 +917,28 @@ mempoolLoop:
 			continue
 		}
 
+		// Stop sorting the transactions which update or support claims
+		// first once the block is larger than the weight reserved for
+		// them.  The transaction is put back into the priority queue so
+		// it is sorted along with the others.
+		if claimsFirst &&
+			blockPlusTxWeight > g.policy.ClaimPriorityWeight {
+
+			log.Tracef("Switching to sort claim transactions "+
+				"with the others blockWeight %d > "+
+				"ClaimPriorityWeight %d", blockPlusTxWeight,
+				g.policy.ClaimPriorityWeight)
+
+			claimsFirst = false
+			if sortedByFee {
+				priorityQueue.SetLessFunc(txPQByFee)
+			} else {
+				priorityQueue.SetLessFunc(txPQByPriority)
+			}
+			heap.Push(priorityQueue, prioItem)
+			continue
+		}
+
 		// Prioritize by fee per kilobyte once the block is larger than
 		// the priority size or there are no more high-priority
 		// transactions.
@@ -723,7 +952,12 @@ mempoolLoop:
 				prioItem.priority, MinHighPriority)
 
 			sortedByFee = true
-			priorityQueue.SetLessFunc(txPQByFee)
+			if claimsFirst {
+				priorityQueue.SetLessFunc(
+					txPQClaimsFirst(txPQByFee))
+			} else {
+				priorityQueue.SetLessFunc(txPQByFee)
+			}
 
 			// Put the transaction back into the priority queue and
 			// skip it so it is re-priortized by fees if it won't
@@ -741,22 +975,31 @@ mempoolLoop:
 
 		// Ensure the transaction inputs pass all of the necessary
 		// preconditions before allowing it to be added to the block.
-		_, err = blockchain.CheckTransactionInputs(tx, nextBlockHeight,
-			blockUtxos, g.chainParams)
-		if err != nil {
-			log.Tracef("Skipping tx %s due to error in "+
-				"CheckTransactionInputs: %v", tx.Hash(), err)
-			logSkippedDeps(tx, deps)
-			continue
-		}
-		err = blockchain.ValidateTransactionScripts(tx, blockUtxos,
-			txscript.StandardVerifyFlags, g.sigCache,
-			g.hashCache)
-		if err != nil {
-			log.Tracef("Skipping tx %s due to error in "+
-				"ValidateTransactionScripts: %v", tx.Hash(), err)
-			logSkippedDeps(tx, deps)
-			continue
+		// The outcome only depends on the outputs the transaction
+		// spends, so it is only checked once per block.
+		if !prepared.validated {
+			_, err := blockchain.CheckTransactionInputs(tx,
+				nextBlockHeight, blockUtxos, g.chainParams)
+			if err != nil {
+				log.Tracef("Skipping tx %s due to error in "+
+					"CheckTransactionInputs: %v", tx.Hash(),
+					err)
+				logSkippedDeps(tx, deps)
+				prepared.invalid = true
+				continue
+			}
+			err = blockchain.ValidateTransactionScripts(tx,
+				blockUtxos, txscript.StandardVerifyFlags,
+				g.sigCache, g.hashCache)
+			if err != nil {
+				log.Tracef("Skipping tx %s due to error in "+
+					"ValidateTransactionScripts: %v",
+					tx.Hash(), err)
+				logSkippedDeps(tx, deps)
+				prepared.invalid = true
+				continue
+			}
+			prepared.validated = true
 		}
 
 		// Spend the transaction inputs in the block utxo view and add
@@ -797,7 +1040,10 @@ mempoolLoop:
 	blockWeight -= wire.MaxVarIntPayload -
 		(uint32(wire.VarIntSerializeSize(uint64(len(blockTxns)))) *
 			blockchain.WitnessScaleFactor)
-	coinbaseTx.MsgTx().TxOut[0].Value += totalFees
+	subsidy := blockchain.CalcBlockSubsidy(nextBlockHeight, g.chainParams)
+	for i, value := range splitCoinbaseValue(subsidy+totalFees, payouts) {
+		coinbaseTx.MsgTx().TxOut[i].Value = value
+	}
 	txFees[0] = -totalFees
 
 	// If segwit is active and we included transactions with witness data,
@@ -818,8 +1064,8 @@ mempoolLoop:
 	}
 
 	// Calculate the next expected block version based on the state of the
-	// rule change deployments.
-	nextBlockVersion, err := g.chain.CalcNextBlockVersion()
+	// rule change deployments and the configured signal bits.
+	nextBlockVersion, err := g.nextBlockVersion()
 	if err != nil {
 		return nil, err
 	}
@@ -853,13 +1099,217 @@ mempoolLoop:
 		"%064x)", len(msgBlock.Transactions), totalFees, blockSigOpCost,
 		blockWeight, blockchain.CompactToBig(msgBlock.Header.Bits))
 
+	template = &BlockTemplate{
+		Block:             &msgBlock,
+		Fees:              txFees,
+		SigOpCosts:        txSigOpCosts,
+		Height:            nextBlockHeight,
+		ValidPayAddress:   len(payouts) > 0,
+		WitnessCommitment: witnessCommitment,
+		ClaimCounts:       countClaimTxns(blockTxns),
+	}
+
+	// Keep a copy of the template so it can be handed out again while the
+	// source pool does not change and drop the prepared details of the
+	// transactions which left the source pool.
+	g.cache.template = copyBlockTemplate(template)
+	g.cache.payouts = append([]Payout(nil), payouts...)
+	g.cache.lastUpdated = lastUpdated
+	g.cache.created = time.Now()
+	g.cache.prune(seen)
+
+	return template, nil
+}
+
+// completeTemplate selects the transactions for the full template building on
+// the passed block after an empty template was handed out for it and closes the
+// passed channel once the full template is available.
+//
+// It must be run as a goroutine.
+func (g *BlkTmplGenerator) completeTemplate(prevHash *chainhash.Hash,
+	payouts []Payout, completed chan struct{}) {
+
+	defer close(completed)
+
+	// There is nothing to complete once the best block changed again.
+	if g.chain.BestSnapshot().Hash != *prevHash {
+		return
+	}
+	if _, err := g.NewSplitBlockTemplate(payouts); err != nil {
+		log.Errorf("Failed to create full block template: %v", err)
+	}
+}
+
+// NewBlockTemplateFromTxns returns a new block template which contains exactly
+// the passed transactions in the passed order after a coinbase paying to the
+// provided address.  Unlike NewBlockTemplate, no transactions are selected from
+// the transaction source and no mining policy is applied.  The transactions may
+// spend the outputs of the transactions before them while all other inputs
+// must be available in the main chain.  An error is returned when the block
+// does not connect to the current best chain.
+//
+// This is primarily useful for constructing specific blocks on test networks.
+func (g *BlkTmplGenerator) NewBlockTemplateFromTxns(payToAddress address.Address,
+	txns []*btcutil.Tx) (*BlockTemplate, error) {
+
+	var payouts []Payout
+	if payToAddress != nil {
+		payouts = []Payout{{
+			Address: payToAddress,
+			Weight:  PayoutWeightTotal,
+		}}
+	}
+	return g.newBlockTemplateFromTxns(payouts, txns)
+}
+
+// newBlockTemplateFromTxns returns a new block template which contains exactly
+// the passed transactions after a coinbase paying to the provided payouts.  See
+// NewBlockTemplateFromTxns for details.
+func (g *BlkTmplGenerator) newBlockTemplateFromTxns(payouts []Payout,
+	txns []*btcutil.Tx) (*BlockTemplate, error) {
+
+	// Extend the most recently known best block.
+	best := g.chain.BestSnapshot()
+	nextBlockHeight := best.Height + 1
+
+	// Create a standard coinbase transaction paying to the provided
+	// payouts.  NOTE: The coinbase value will be updated to include the
+	// fees of the passed transactions below.
+	coinbaseScript, err := standardCoinbaseScript(nextBlockHeight, 0)
+	if err != nil {
+		return nil, err
+	}
+	coinbaseTx, err := createCoinbaseTx(g.chainParams, coinbaseScript,
+		nextBlockHeight, payouts)
+	if err != nil {
+		return nil, err
+	}
+
+	segwitState, err := g.chain.ThresholdState(chaincfg.DeploymentSegwit)
+	if err != nil {
+		return nil, err
+	}
+	segwitActive := segwitState == blockchain.ThresholdActive
+
+	// Calculate the fees and signature operation costs of the transactions
+	// while keeping track of the outputs they create so later transactions
+	// are able to spend them.
+	blockTxns := make([]*btcutil.Tx, 0, len(txns)+1)
+	blockTxns = append(blockTxns, coinbaseTx)
+	blockUtxos := blockchain.NewUtxoViewpoint()
+	txFees := make([]int64, 0, len(txns)+1)
+	txFees = append(txFees, 0)
+	txSigOpCosts := make([]int64, 0, len(txns)+1)
+	txSigOpCosts = append(txSigOpCosts,
+		int64(blockchain.CountSigOps(coinbaseTx))*blockchain.WitnessScaleFactor)
+	totalFees := int64(0)
+	witnessIncluded := false
+	for _, tx := range txns {
+		if blockchain.IsCoinBase(tx) {
+			return nil, fmt.Errorf("transaction %v is a coinbase",
+				tx.Hash())
+		}
+		if tx.HasWitness() {
+			if !segwitActive {
+				return nil, fmt.Errorf("transaction %v has "+
+					"witness data before segwit is active",
+					tx.Hash())
+			}
+			witnessIncluded = true
+		}
+
+		// Only add the outputs which are not already known so the
+		// outputs spent or created by earlier transactions in the
+		// block are not replaced.
+		utxos, err := g.chain.FetchUtxoView(tx)
+		if err != nil {
+			return nil, err
+		}
+		blockUtxoEntries := blockUtxos.Entries()
+		for outpoint, entry := range utxos.Entries() {
+			if _, ok := blockUtxoEntries[outpoint]; !ok {
+				blockUtxoEntries[outpoint] = entry.Clone()
+			}
+		}
+
+		fee, err := blockchain.CheckTransactionInputs(tx,
+			nextBlockHeight, blockUtxos, g.chainParams)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %v: %v", tx.Hash(),
+				err)
+		}
+		sigOpCost, err := blockchain.GetSigOpCost(tx, false,
+			blockUtxos, true, segwitActive)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %v: %v", tx.Hash(),
+				err)
+		}
+		err = spendTransaction(blockUtxos, tx, nextBlockHeight)
+		if err != nil {
+			return nil, err
+		}
+
+		blockTxns = append(blockTxns, tx)
+		txFees = append(txFees, fee)
+		txSigOpCosts = append(txSigOpCosts, int64(sigOpCost))
+		totalFees += fee
+	}
+
+	// Update the coinbase value with the total fees and add the witness
+	// commitment when needed.
+	subsidy := blockchain.CalcBlockSubsidy(nextBlockHeight, g.chainParams)
+	for i, value := range splitCoinbaseValue(subsidy+totalFees, payouts) {
+		coinbaseTx.MsgTx().TxOut[i].Value = value
+	}
+	txFees[0] = -totalFees
+	var witnessCommitment []byte
+	if witnessIncluded {
+		witnessCommitment = AddWitnessCommitment(coinbaseTx, blockTxns)
+	}
+
+	// Calculate the required difficulty and version for the block.
+	ts := medianAdjustedTime(best, g.timeSource)
+	reqDifficulty, err := g.chain.CalcNextRequiredDifficulty(ts)
+	if err != nil {
+		return nil, err
+	}
+	nextBlockVersion, err := g.nextBlockVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a new block ready to be solved.
+	var msgBlock wire.MsgBlock
+	msgBlock.Header = wire.BlockHeader{
+		Version:    nextBlockVersion,
+		PrevBlock:  best.Hash,
+		MerkleRoot: blockchain.CalcMerkleRoot(blockTxns, false),
+		Timestamp:  ts,
+		Bits:       reqDifficulty,
+	}
+	for _, tx := range blockTxns {
+		if err := msgBlock.AddTransaction(tx.MsgTx()); err != nil {
+			return nil, err
+		}
+	}
+
+	// Finally, perform a full check on the created block against the chain
+	// consensus rules to ensure it properly connects to the current best
+	// chain with no issues.
+	block := btcutil.NewBlock(&msgBlock)
+	block.SetHeight(nextBlockHeight)
+	if err := g.chain.CheckConnectBlockTemplate(block); err != nil {
+		return nil, err
+	}
+
 	return &BlockTemplate{
 		Block:             &msgBlock,
 		Fees:              txFees,
 		SigOpCosts:        txSigOpCosts,
 		Height:            nextBlockHeight,
-		ValidPayAddress:   payToAddress != nil,
+		ValidPayAddress:   len(payouts) > 0,
 		WitnessCommitment: witnessCommitment,
+		ClaimCounts:       countClaimTxns(blockTxns),
 	}, nil
 }
 
@@ -904,6 +1354,32 @@ func AddWitnessCommitment(coinbaseTx *btcutil.Tx,
 	return witnessCommitment
 }
 
+// AddWitnessNonce sets the witness of the coinbase transaction of the passed
+// block to the default witness nonce used by AddWitnessCommitment when the
+// coinbase commits to the witness data of the block without providing a
+// witness.  This happens when miners modify the coinbase transaction of a block
+// template and serialize it without its witness.  Since the witness nonce is
+// not committed to by the block hash, the block remains the same.  It returns
+// whether or not the nonce was added.
+func AddWitnessNonce(msgBlock *wire.MsgBlock) bool {
+	if len(msgBlock.Transactions) == 0 {
+		return false
+	}
+	coinbaseTx := btcutil.NewTx(msgBlock.Transactions[0])
+	if len(coinbaseTx.MsgTx().TxIn) != 1 ||
+		len(coinbaseTx.MsgTx().TxIn[0].Witness) != 0 {
+
+		return false
+	}
+	if _, ok := blockchain.ExtractWitnessCommitment(coinbaseTx); !ok {
+		return false
+	}
+
+	var witnessNonce [blockchain.CoinbaseWitnessDataLen]byte
+	coinbaseTx.MsgTx().TxIn[0].Witness = wire.TxWitness{witnessNonce[:]}
+	return true
+}
+
 // UpdateBlockTime updates the timestamp in the header of the passed block to
 // the current time while taking into account the median time of the last
 // several blocks to ensure the new time is after that time per the chain
@@ -957,6 +1433,45 @@ func (g *BlkTmplGenerator) UpdateExtraNonce(msgBlock *wire.MsgBlock, blockHeight
 	return nil
 }
 
+// nextBlockVersion returns the version for the block after the end of the
+// current best chain based on the state of the rule change deployments with
+// the configured signal bits set.
+func (g *BlkTmplGenerator) nextBlockVersion() (int32, error) {
+	version, err := g.chain.CalcNextBlockVersion()
+	if err != nil {
+		return 0, err
+	}
+	return version | int32(g.signalBits.Load()), nil
+}
+
+// SignalBits returns the bitmask of the version bits which are signalled in
+// generated blocks in addition to the bits of the known deployments which are
+// currently being voted on.
+//
+// This function is safe for concurrent access.
+func (g *BlkTmplGenerator) SignalBits() uint32 {
+	return g.signalBits.Load()
+}
+
+// SetSignalBit sets whether or not the passed version bit is signalled in
+// generated blocks in addition to the bits of the known deployments which are
+// currently being voted on.  It only affects templates generated after the call.
+// An error is returned when the bit is above MaxSignalBit.
+//
+// This function is safe for concurrent access.
+func (g *BlkTmplGenerator) SetSignalBit(bit uint32, signal bool) error {
+	if bit > MaxSignalBit {
+		return fmt.Errorf("signal bit %d is above the highest "+
+			"signalling bit %d", bit, MaxSignalBit)
+	}
+	if signal {
+		g.signalBits.Or(1 << bit)
+	} else {
+		g.signalBits.And(^uint32(1 << bit))
+	}
+	return nil
+}
+
 // BestSnapshot returns information about the current best chain block and
 // related state as of the current point in time using the chain instance
 // associated with the block template generator.  The returned state must be
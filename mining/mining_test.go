@@ -5,11 +5,22 @@
 package mining
 
 import (
+	"bytes"
 	"container/heap"
 	"math/rand"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/btcsuite/btcd/blockchain"
 	"github.com/btcsuite/btcd/btcutil/v2"
+	"github.com/btcsuite/btcd/chaincfg/v2"
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/database"
+	_ "github.com/btcsuite/btcd/database/ffldb"
+	"github.com/btcsuite/btcd/txscript/v2"
+	"github.com/btcsuite/btcd/wire/v2"
+	"github.com/stretchr/testify/require"
 )
 
 // TestTxFeePrioHeap ensures the priority queue for transaction fees and
@@ -108,3 +119,247 @@ func TestTxFeePrioHeap(t *testing.T) {
 		highest = prioItem
 	}
 }
+
+// TestAddWitnessNonce ensures the default witness nonce is only added to
+// coinbase transactions which commit to witness data without providing a
+// witness, and that the witness commitment is valid afterwards.
+func TestAddWitnessNonce(t *testing.T) {
+	coinbase := wire.NewMsgTx(wire.TxVersion)
+	coinbase.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{},
+			wire.MaxPrevOutIndex),
+		SignatureScript: []byte{0x51, 0x51},
+		Sequence:        wire.MaxTxInSequenceNum,
+	})
+	coinbase.AddTxOut(wire.NewTxOut(5000000000, []byte{0x51}))
+	spend := wire.NewMsgTx(wire.TxVersion)
+	spend.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{0x01}, 0),
+		Witness:          wire.TxWitness{{0x51}},
+		Sequence:         wire.MaxTxInSequenceNum,
+	})
+	spend.AddTxOut(wire.NewTxOut(1000, []byte{0x51}))
+
+	// A coinbase without a witness commitment is left alone.
+	msgBlock := &wire.MsgBlock{Transactions: []*wire.MsgTx{coinbase}}
+	if AddWitnessNonce(msgBlock) {
+		t.Fatal("nonce added to coinbase without witness commitment")
+	}
+
+	// A coinbase which lost its witness gets the default nonce back.
+	msgBlock.AddTransaction(spend)
+	block := btcutil.NewBlock(msgBlock)
+	AddWitnessCommitment(block.Transactions()[0], block.Transactions())
+	coinbase.TxIn[0].Witness = nil
+	if !AddWitnessNonce(msgBlock) {
+		t.Fatal("nonce not added to coinbase without witness")
+	}
+	err := blockchain.ValidateWitnessCommitment(btcutil.NewBlock(msgBlock))
+	if err != nil {
+		t.Fatalf("invalid witness commitment: %v", err)
+	}
+
+	// A coinbase which already has a witness is left alone.
+	if AddWitnessNonce(msgBlock) {
+		t.Fatal("nonce added to coinbase with witness")
+	}
+}
+
+// testTxSource is a TxSource which provides a fixed set of transactions.
+type testTxSource struct {
+	descs   []*TxDesc
+	updated time.Time
+}
+
+// setDescs replaces the transactions provided by the source.
+func (s *testTxSource) setDescs(descs ...*TxDesc) {
+	s.descs = descs
+	s.updated = time.Now()
+}
+
+func (s *testTxSource) LastUpdated() time.Time { return s.updated }
+func (s *testTxSource) MiningDescs() []*TxDesc { return s.descs }
+func (s *testTxSource) HaveTransaction(hash *chainhash.Hash) bool {
+	for _, desc := range s.descs {
+		if desc.Tx.Hash().IsEqual(hash) {
+			return true
+		}
+	}
+	return false
+}
+
+// newTestGenerator returns a block template generator for the passed policy
+// on top of a new regression test chain with a mature coinbase output which
+// is redeemable by anyone.  The outpoint of that output along with its value
+// are returned as well.
+func newTestGenerator(t *testing.T, policy *Policy,
+	txSource *testTxSource) (*BlkTmplGenerator, wire.OutPoint, int64) {
+
+	t.Helper()
+
+	params := chaincfg.RegressionNetParams
+	db, err := database.Create("ffldb", filepath.Join(t.TempDir(), "db"),
+		params.Net)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	timeSource := blockchain.NewMedianTime()
+	sigCache := txscript.NewSigCache(1000)
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:          db,
+		ChainParams: &params,
+		TimeSource:  timeSource,
+		SigCache:    sigCache,
+	})
+	require.NoError(t, err)
+
+	g := NewBlkTmplGenerator(policy, &params, txSource, chain,
+		timeSource, sigCache, txscript.NewHashCache(1000))
+
+	// Generate enough empty blocks for the coinbase of the first one to
+	// mature.
+	var spend wire.OutPoint
+	var value int64
+	for i := 0; i <= int(params.CoinbaseMaturity); i++ {
+		template, err := g.NewBlockTemplate(nil)
+		require.NoError(t, err)
+
+		block := template.Block
+		target := blockchain.CompactToBig(block.Header.Bits)
+		for {
+			hash := block.Header.BlockHash()
+			if blockchain.HashToBig(&hash).Cmp(target) <= 0 {
+				break
+			}
+			block.Header.Nonce++
+		}
+		_, _, err = chain.ProcessBlock(btcutil.NewBlock(block),
+			blockchain.BFNone)
+		require.NoError(t, err)
+
+		if i == 0 {
+			coinbase := block.Transactions[0]
+			spend = wire.OutPoint{Hash: coinbase.TxHash()}
+			value = coinbase.TxOut[0].Value
+		}
+	}
+
+	return g, spend, value
+}
+
+// newTestTxDesc returns a mining descriptor for a transaction which spends
+// the passed output, pays the passed fee and has an output with the passed
+// number of signature operations.
+func newTestTxDesc(prevOut wire.OutPoint, value, fee int64,
+	numSigOps int) *TxDesc {
+
+	pkScript := bytes.Repeat([]byte{txscript.OP_CHECKSIG}, numSigOps)
+	pkScript = append(pkScript, txscript.OP_TRUE)
+
+	msgTx := wire.NewMsgTx(wire.TxVersion)
+	msgTx.AddTxIn(wire.NewTxIn(&prevOut, nil, nil))
+	msgTx.AddTxOut(wire.NewTxOut(value-fee, pkScript))
+	tx := btcutil.NewTx(msgTx)
+
+	return &TxDesc{
+		Tx:       tx,
+		Added:    time.Now(),
+		Fee:      fee,
+		FeePerKB: fee * 1000 / int64(msgTx.SerializeSize()),
+	}
+}
+
+// templateHasTx returns whether the passed template includes the transaction
+// of the passed descriptor.
+func templateHasTx(template *BlockTemplate, desc *TxDesc) bool {
+	for _, tx := range template.Block.Transactions {
+		if tx.TxHash() == *desc.Tx.Hash() {
+			return true
+		}
+	}
+	return false
+}
+
+// TestNewBlockTemplateMinTxFee ensures transactions which pay less than the
+// minimum block transaction fee are not included in block templates.
+func TestNewBlockTemplateMinTxFee(t *testing.T) {
+	txSource := &testTxSource{}
+	policy := Policy{
+		BlockMaxWeight: blockchain.MaxBlockWeight,
+		BlockMinTxFee:  10000,
+	}
+	g, spend, value := newTestGenerator(t, &policy, txSource)
+
+	// The transactions spend the same output, so only one of them can be
+	// included regardless.
+	lowFee := newTestTxDesc(spend, value, 100, 0)
+	highFee := newTestTxDesc(spend, value, 10000, 0)
+	require.Less(t, lowFee.FeePerKB, int64(policy.BlockMinTxFee))
+	require.GreaterOrEqual(t, highFee.FeePerKB,
+		int64(policy.BlockMinTxFee))
+
+	txSource.setDescs(lowFee)
+	template, err := g.NewBlockTemplate(nil)
+	require.NoError(t, err)
+	require.Len(t, template.Block.Transactions, 1)
+
+	txSource.setDescs(lowFee, highFee)
+	template, err = g.NewBlockTemplate(nil)
+	require.NoError(t, err)
+	require.Len(t, template.Block.Transactions, 2)
+	require.True(t, templateHasTx(template, highFee))
+}
+
+// TestNewBlockTemplateSigOpCost ensures the total signature operation cost of
+// the transactions included in block templates does not exceed the limit of
+// the policy, and that the consensus limit is used when it is not set.
+func TestNewBlockTemplateSigOpCost(t *testing.T) {
+	txSource := &testTxSource{}
+	policy := Policy{
+		BlockMaxWeight:    blockchain.MaxBlockWeight,
+		BlockMaxSigOpCost: 100 * blockchain.WitnessScaleFactor,
+	}
+	g, spend, value := newTestGenerator(t, &policy, txSource)
+
+	// Split the mature coinbase so there are two outputs to spend in the
+	// template.
+	const fee = 10000
+	split := wire.NewMsgTx(wire.TxVersion)
+	split.AddTxIn(wire.NewTxIn(&spend, nil, nil))
+	split.AddTxOut(wire.NewTxOut((value-fee)/2, []byte{txscript.OP_TRUE}))
+	split.AddTxOut(wire.NewTxOut((value-fee)/2, []byte{txscript.OP_TRUE}))
+	splitTx := btcutil.NewTx(split)
+	splitDesc := &TxDesc{
+		Tx:       splitTx,
+		Fee:      value - (value-fee)/2*2,
+		FeePerKB: 1000000,
+	}
+	outValue := split.TxOut[0].Value
+	spend0 := wire.OutPoint{Hash: *splitTx.Hash(), Index: 0}
+	spend1 := wire.OutPoint{Hash: *splitTx.Hash(), Index: 1}
+
+	// Each of the transactions spending the split outputs has a cost of
+	// 60 signature operations, so only one of them fits.
+	first := newTestTxDesc(spend0, outValue, fee, 60)
+	second := newTestTxDesc(spend1, outValue, fee, 60)
+	txSource.setDescs(splitDesc, first, second)
+
+	template, err := g.NewBlockTemplate(nil)
+	require.NoError(t, err)
+	require.Len(t, template.Block.Transactions, 3)
+	require.True(t, templateHasTx(template, splitDesc))
+	require.NotEqual(t, templateHasTx(template, first),
+		templateHasTx(template, second))
+
+	// Both transactions are included with the consensus limit.
+	policy.BlockMaxSigOpCost = 0
+	g = NewBlkTmplGenerator(&policy, g.chainParams, txSource, g.chain,
+		g.timeSource, g.sigCache, g.hashCache)
+	require.Zero(t, policy.BlockMaxSigOpCost)
+	require.EqualValues(t, blockchain.MaxBlockSigOpsCost,
+		g.policy.BlockMaxSigOpCost)
+
+	template, err = g.NewBlockTemplate(nil)
+	require.NoError(t, err)
+	require.Len(t, template.Block.Transactions, 4)
+}
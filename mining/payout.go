@@ -0,0 +1,69 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mining
+
+import (
+	"github.com/btcsuite/btcd/address/v2"
+)
+
+const (
+	// PayoutWeightTotal is the sum of the weights of the payouts a coinbase
+	// is split between.  The weights are expressed in hundredths of a
+	// percent, so a payout with a weight of 2500 receives 25% of the
+	// coinbase value.
+	PayoutWeightTotal = 10000
+)
+
+// Payout describes an address which receives a share of the coinbase value of
+// generated blocks.
+type Payout struct {
+	// Address is the address the share is paid to.
+	Address address.Address
+
+	// Weight is the share of the coinbase value paid to the address in
+	// hundredths of a percent.  The weights of all payouts of a block must
+	// add up to PayoutWeightTotal.
+	Weight uint32
+}
+
+// splitCoinbaseValue returns the passed coinbase value split between the
+// passed payouts according to their weights.  Any remainder due to rounding
+// goes to the first payout.  The entire value is returned as a single amount
+// when there are no payouts since the coinbase is then redeemable by anyone.
+func splitCoinbaseValue(value int64, payouts []Payout) []int64 {
+	if len(payouts) == 0 {
+		return []int64{value}
+	}
+
+	values := make([]int64, len(payouts))
+	remaining := value
+	for i := len(payouts) - 1; i > 0; i-- {
+		// Split the quotient and remainder separately to avoid
+		// overflowing for large values.
+		share := value/PayoutWeightTotal*int64(payouts[i].Weight) +
+			value%PayoutWeightTotal*int64(payouts[i].Weight)/
+				PayoutWeightTotal
+		values[i] = share
+		remaining -= share
+	}
+	values[0] = remaining
+	return values
+}
+
+// samePayouts returns whether or not the passed payouts pay the same shares to
+// the same destinations.
+func samePayouts(a, b []Payout) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Weight != b[i].Weight ||
+			a[i].Address.String() != b[i].Address.String() {
+
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mining
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestSplitCoinbaseValue ensures the coinbase value is split between payouts
+// according to their weights with any remainder going to the first payout.
+func TestSplitCoinbaseValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   int64
+		weights []uint32
+		want    []int64
+	}{{
+		name:  "no payouts",
+		value: 5000000000,
+		want:  []int64{5000000000},
+	}, {
+		name:    "single payout",
+		value:   5000000000,
+		weights: []uint32{10000},
+		want:    []int64{5000000000},
+	}, {
+		name:    "even split",
+		value:   5000000000,
+		weights: []uint32{5000, 5000},
+		want:    []int64{2500000000, 2500000000},
+	}, {
+		name:    "remainder to first",
+		value:   100,
+		weights: []uint32{3334, 3333, 3333},
+		want:    []int64{34, 33, 33},
+	}, {
+		name:    "large value",
+		value:   2100000000000000,
+		weights: []uint32{1, 9999},
+		want:    []int64{210000000000, 2099790000000000},
+	}}
+
+	for _, test := range tests {
+		var payouts []Payout
+		for _, weight := range test.weights {
+			payouts = append(payouts, Payout{Weight: weight})
+		}
+		got := splitCoinbaseValue(test.value, payouts)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
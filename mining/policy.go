@@ -41,10 +41,40 @@ type Policy struct {
 	// transactions to be used when generating a block template.
 	BlockPrioritySize uint32
 
+	// ClaimPriorityWeight is the weight of a block template reserved for
+	// transactions which update or support claims.  They are selected
+	// ahead of the other transactions until the block reaches this
+	// weight, the ones spending the oldest claims first.  Zero disables
+	// the prioritization.
+	ClaimPriorityWeight uint32
+
+	// BlockMaxSigOpCost is the maximum total signature operation cost of
+	// the transactions to be used when generating a block template.  It
+	// must not exceed blockchain.MaxBlockSigOpsCost, which is used when it
+	// is zero.
+	BlockMaxSigOpCost uint32
+
+	// BlockMinTxFee is the minimum fee in Satoshi/1000 bytes a transaction
+	// must pay to be included in a block template at all.  Unlike
+	// TxMinFreeFee, it also applies to high-priority transactions and
+	// transactions added to reach the minimum block size.
+	BlockMinTxFee btcutil.Amount
+
 	// TxMinFreeFee is the minimum fee in Satoshi/1000 bytes that is
 	// required for a transaction to be treated as free for mining purposes
 	// (block template generation).
 	TxMinFreeFee btcutil.Amount
+
+	// EmptyBlockFirst, when set, causes the first block template for a new
+	// best block to be handed out without any transactions right away
+	// while the transactions for the full template are selected in the
+	// background.
+	EmptyBlockFirst bool
+
+	// SignalBits is a bitmask of the version bits to signal in generated
+	// blocks in addition to the bits of the known deployments which are
+	// currently being voted on.  Only bits up to MaxSignalBit may be set.
+	SignalBits uint32
 }
 
 // minInt is a helper function to return the minimum of two ints.  This avoids
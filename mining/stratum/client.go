@@ -0,0 +1,459 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package stratum
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// maxMessageSize is the maximum size of a single message a client may
+	// send.  Clients which exceed it are disconnected.
+	maxMessageSize = 16 * 1024
+
+	// idleTimeout is the maximum amount of time a client may stay silent
+	// before it is disconnected.
+	idleTimeout = time.Minute * 10
+
+	// writeTimeout is the maximum amount of time to wait for a message to
+	// be written to a client.
+	writeTimeout = time.Second * 10
+
+	// maxWorkersPerClient is the maximum number of workers which may be
+	// authorized on a single connection.
+	maxWorkersPerClient = 16
+)
+
+// Stratum error codes as they are commonly used by pools and understood by
+// the mining software.
+var (
+	errOther          = &stratumError{20, "Other/Unknown"}
+	errJobNotFound    = &stratumError{21, "Job not found"}
+	errDuplicateShare = &stratumError{22, "Duplicate share"}
+	errLowDifficulty  = &stratumError{23, "Low difficulty share"}
+	errUnauthorized   = &stratumError{24, "Unauthorized worker"}
+	errNotSubscribed  = &stratumError{25, "Not subscribed"}
+)
+
+// stratumError is an error which is returned to a client in response to a
+// request.
+type stratumError struct {
+	code    int
+	message string
+}
+
+// Error satisfies the error interface.
+func (e *stratumError) Error() string {
+	return fmt.Sprintf("%d: %s", e.code, e.message)
+}
+
+// MarshalJSON encodes the error as the [code, message, traceback] array which
+// is expected by the mining software.
+func (e *stratumError) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{e.code, e.message, nil})
+}
+
+// request describes a request sent by a client.
+type request struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// response describes the response to a request.
+type response struct {
+	ID     json.RawMessage `json:"id"`
+	Result interface{}     `json:"result"`
+	Error  *stratumError   `json:"error"`
+}
+
+// notification describes a message sent to a client which is not a response to
+// a request.
+type notification struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params []interface{}   `json:"params"`
+}
+
+// stringParam returns the string parameter at the passed index.
+func stringParam(params []json.RawMessage, i int) (string, error) {
+	if i >= len(params) {
+		return "", fmt.Errorf("missing parameter %d", i+1)
+	}
+	var s string
+	if err := json.Unmarshal(params[i], &s); err != nil {
+		return "", fmt.Errorf("parameter %d is not a string", i+1)
+	}
+	return s, nil
+}
+
+// uint32Param returns the parameter at the passed index which must be a uint32
+// encoded as 8 hex characters.
+func uint32Param(params []json.RawMessage, i int) (uint32, error) {
+	s, err := stringParam(params, i)
+	if err != nil {
+		return 0, err
+	}
+	if len(s) != 8 {
+		return 0, fmt.Errorf("parameter %d is not 8 hex characters", i+1)
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("parameter %d is not 8 hex characters", i+1)
+	}
+	return uint32(v), nil
+}
+
+// client houses the state of a single stratum connection.
+type client struct {
+	server      *Server
+	conn        net.Conn
+	extraNonce1 []byte
+	writeMtx    sync.Mutex
+
+	// The following fields are protected by mtx.
+	mtx        sync.Mutex
+	subscribed bool
+	workers    map[string]struct{}
+	difficulty float64
+	prevDiff   float64
+	varDiff    *varDiff
+}
+
+// newClient returns a new client for the passed connection.
+func newClient(s *Server, conn net.Conn, extraNonce1 []byte) *client {
+	return &client{
+		server:      s,
+		conn:        conn,
+		extraNonce1: extraNonce1,
+		workers:     make(map[string]struct{}),
+		difficulty:  s.cfg.StartDifficulty,
+		varDiff: newVarDiff(s.cfg.TargetShareTime,
+			s.cfg.MinDifficulty, s.cfg.MaxDifficulty, time.Now()),
+	}
+}
+
+// send writes the passed message to the client.  The connection is closed on
+// failure which causes the input handler to disconnect the client.
+func (c *client) send(msg interface{}) {
+	c.writeMtx.Lock()
+	defer c.writeMtx.Unlock()
+
+	err := c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if err == nil {
+		// json.Encoder terminates each value with a newline.
+		err = json.NewEncoder(c.conn).Encode(msg)
+	}
+	if err != nil {
+		log.Debugf("Unable to write to stratum client %s: %v",
+			c.conn.RemoteAddr(), err)
+		c.conn.Close()
+	}
+}
+
+// sendDifficulty sends the passed share difficulty to the client.
+func (c *client) sendDifficulty(difficulty float64) {
+	c.send(&notification{
+		Method: "mining.set_difficulty",
+		Params: []interface{}{difficulty},
+	})
+}
+
+// sendJob sends the passed job to the client when it is subscribed.
+func (c *client) sendJob(j *job, clean bool) {
+	c.mtx.Lock()
+	subscribed := c.subscribed
+	if subscribed {
+		// Shares for the new job must meet the current difficulty.
+		c.prevDiff = 0
+	}
+	c.mtx.Unlock()
+
+	if subscribed {
+		c.send(&notification{
+			Method: "mining.notify",
+			Params: j.notifyParams(clean),
+		})
+	}
+}
+
+// shareDifficulty returns the difficulty the shares of the client must meet.
+// Since miners only apply a new difficulty to the next job, shares which meet
+// the previous difficulty are accepted until then.
+//
+// This function MUST be called with the client lock held.
+func (c *client) shareDifficulty() float64 {
+	if c.prevDiff != 0 && c.prevDiff < c.difficulty {
+		return c.prevDiff
+	}
+	return c.difficulty
+}
+
+// setDifficulty changes the share difficulty of the client and notifies it
+// about the change.
+func (c *client) setDifficulty(difficulty float64) {
+	c.mtx.Lock()
+	if c.prevDiff == 0 {
+		c.prevDiff = c.difficulty
+	}
+	c.difficulty = difficulty
+	subscribed := c.subscribed
+	workers := c.workerNames()
+	c.mtx.Unlock()
+
+	c.server.setWorkerDifficulty(workers, difficulty)
+	if subscribed {
+		c.sendDifficulty(difficulty)
+	}
+}
+
+// retarget adjusts the share difficulty of the client according to the rate
+// of the shares it submitted.
+func (c *client) retarget(now time.Time) {
+	c.mtx.Lock()
+	difficulty, changed := c.varDiff.retarget(now, c.difficulty)
+	c.mtx.Unlock()
+
+	if changed {
+		log.Debugf("Changing share difficulty of stratum client %s to %v",
+			c.conn.RemoteAddr(), difficulty)
+		c.setDifficulty(difficulty)
+	}
+}
+
+// workerNames returns the names of the workers authorized on the client.
+//
+// This function MUST be called with the client lock held.
+func (c *client) workerNames() []string {
+	names := make([]string, 0, len(c.workers))
+	for name := range c.workers {
+		names = append(names, name)
+	}
+	return names
+}
+
+// handleSubscribe handles the mining.subscribe request.
+func (c *client) handleSubscribe(params []json.RawMessage) (interface{}, error) {
+	c.mtx.Lock()
+	c.subscribed = true
+	c.mtx.Unlock()
+
+	subscriptionID := hex.EncodeToString(c.extraNonce1)
+	return []interface{}{
+		[]interface{}{
+			[]string{"mining.set_difficulty", subscriptionID},
+			[]string{"mining.notify", subscriptionID},
+		},
+		hex.EncodeToString(c.extraNonce1),
+		extraNonce2Size,
+	}, nil
+}
+
+// handleAuthorize handles the mining.authorize request.
+func (c *client) handleAuthorize(params []json.RawMessage) (interface{}, error) {
+	name, err := stringParam(params, 0)
+	if err != nil {
+		return nil, err
+	}
+	password, _ := stringParam(params, 1)
+	if name == "" {
+		return false, nil
+	}
+	if c.server.cfg.Password != "" && password != c.server.cfg.Password {
+		log.Infof("Stratum client %s failed to authorize worker %q",
+			c.conn.RemoteAddr(), name)
+		return false, nil
+	}
+
+	c.mtx.Lock()
+	_, exists := c.workers[name]
+	if !exists && len(c.workers) >= maxWorkersPerClient {
+		c.mtx.Unlock()
+		return false, nil
+	}
+	c.workers[name] = struct{}{}
+	difficulty := c.difficulty
+	c.mtx.Unlock()
+
+	if !exists {
+		c.server.addWorker(name, difficulty)
+		log.Debugf("Stratum client %s authorized worker %q",
+			c.conn.RemoteAddr(), name)
+	}
+	return true, nil
+}
+
+// handleSubmit handles the mining.submit request.
+func (c *client) handleSubmit(params []json.RawMessage) (interface{}, error) {
+	worker, err := stringParam(params, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mtx.Lock()
+	subscribed := c.subscribed
+	_, authorized := c.workers[worker]
+	difficulty := c.shareDifficulty()
+	c.mtx.Unlock()
+	if !subscribed {
+		return nil, errNotSubscribed
+	}
+	if !authorized {
+		return nil, errUnauthorized
+	}
+
+	jobID, err := stringParam(params, 1)
+	if err != nil {
+		return nil, err
+	}
+	extraNonce2, err := stringParam(params, 2)
+	if err != nil {
+		return nil, err
+	}
+	en2, err := hex.DecodeString(extraNonce2)
+	if err != nil || len(en2) != extraNonce2Size {
+		return nil, fmt.Errorf("extranonce2 is not %d hex encoded bytes",
+			extraNonce2Size)
+	}
+	ntime, err := uint32Param(params, 3)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := uint32Param(params, 4)
+	if err != nil {
+		return nil, err
+	}
+
+	extraNonce := make([]byte, 0, extraNonceSize)
+	extraNonce = append(extraNonce, c.extraNonce1...)
+	extraNonce = append(extraNonce, en2...)
+	err = c.server.submitShare(worker, jobID, extraNonce, ntime, nonce,
+		difficulty)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mtx.Lock()
+	c.varDiff.addShare()
+	c.mtx.Unlock()
+	return true, nil
+}
+
+// handleSuggestDifficulty handles the mining.suggest_difficulty request.  The
+// suggested difficulty is limited to the configured bounds.
+func (c *client) handleSuggestDifficulty(params []json.RawMessage) (interface{}, error) {
+	if len(params) == 0 {
+		return nil, errors.New("missing parameter 1")
+	}
+	var difficulty float64
+	if err := json.Unmarshal(params[0], &difficulty); err != nil ||
+		difficulty <= 0 {
+
+		return nil, errors.New("parameter 1 is not a positive number")
+	}
+	cfg := &c.server.cfg
+	if difficulty < cfg.MinDifficulty {
+		difficulty = cfg.MinDifficulty
+	}
+	if cfg.MaxDifficulty > 0 && difficulty > cfg.MaxDifficulty {
+		difficulty = cfg.MaxDifficulty
+	}
+
+	c.setDifficulty(difficulty)
+	return true, nil
+}
+
+// handleRequest dispatches the passed request to its handler and sends the
+// response to the client.
+func (c *client) handleRequest(req *request) {
+	var result interface{}
+	var err error
+	switch req.Method {
+	case "mining.subscribe":
+		result, err = c.handleSubscribe(req.Params)
+	case "mining.authorize":
+		result, err = c.handleAuthorize(req.Params)
+	case "mining.submit":
+		result, err = c.handleSubmit(req.Params)
+	case "mining.suggest_difficulty":
+		result, err = c.handleSuggestDifficulty(req.Params)
+	case "mining.extranonce.subscribe":
+		// The extra nonce of a client never changes.
+		result = true
+	default:
+		err = fmt.Errorf("unknown method %q", req.Method)
+	}
+
+	resp := response{ID: req.ID, Result: result}
+	if err != nil {
+		var serr *stratumError
+		if !errors.As(err, &serr) {
+			serr = &stratumError{errOther.code, err.Error()}
+		}
+		resp.Result = nil
+		resp.Error = serr
+	}
+	c.send(&resp)
+
+	switch {
+	// Provide the initial work to newly subscribed clients.
+	case req.Method == "mining.subscribe" && err == nil:
+		c.mtx.Lock()
+		difficulty := c.difficulty
+		c.mtx.Unlock()
+		c.sendDifficulty(difficulty)
+		if j := c.server.currentJob(); j != nil {
+			c.sendJob(j, true)
+		}
+
+	// Adjust the difficulty according to the rate of the shares.
+	case req.Method == "mining.submit" && err == nil:
+		c.retarget(time.Now())
+	}
+}
+
+// inHandler handles all incoming messages for the client.  It must be run as a
+// goroutine.
+func (c *client) inHandler() {
+	reader := bufio.NewReaderSize(c.conn, maxMessageSize)
+	for {
+		err := c.conn.SetReadDeadline(time.Now().Add(idleTimeout))
+		if err != nil {
+			break
+		}
+		line, err := reader.ReadSlice('\n')
+		if err != nil {
+			if err != io.EOF && !c.server.isShuttingDown() {
+				log.Debugf("Disconnecting stratum client %s: %v",
+					c.conn.RemoteAddr(), err)
+			}
+			break
+		}
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			log.Debugf("Disconnecting stratum client %s which sent "+
+				"a malformed message: %v", c.conn.RemoteAddr(), err)
+			break
+		}
+		c.handleRequest(&req)
+	}
+
+	c.conn.Close()
+	c.server.removeClient(c)
+}
@@ -0,0 +1,650 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package stratum
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/btcsuite/btcd/address/v2"
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil/v2"
+	"github.com/btcsuite/btcd/chaincfg/v2"
+	"github.com/btcsuite/btcd/mining"
+	"github.com/btcsuite/btcd/wire/v2"
+)
+
+const (
+	// jobCheckInterval is the interval at which the server checks whether
+	// a new job needs to be sent to the clients.
+	jobCheckInterval = time.Second
+
+	// jobRefreshInterval is the minimum amount of time between jobs for
+	// the same block in order to include new transactions from the memory
+	// pool.
+	jobRefreshInterval = time.Second * 30
+
+	// maxJobs is the maximum number of jobs for the same block for which
+	// shares are still accepted.
+	maxJobs = 8
+
+	// maxFutureTime is the maximum amount of time the time of a submitted
+	// share may be ahead of the current time.
+	maxFutureTime = time.Hour * 2
+
+	// hashRateWindow is the window over which the hash rate of the workers
+	// is estimated.
+	hashRateWindow = time.Minute * 10
+
+	// workerExpiry is the amount of time the statistics of workers without
+	// any connections are kept after their last activity.
+	workerExpiry = time.Hour
+)
+
+// Config is a descriptor containing the stratum server configuration.
+type Config struct {
+	// ChainParams identifies which chain parameters the stratum server is
+	// associated with.
+	ChainParams *chaincfg.Params
+
+	// BlockTemplateGenerator identifies the instance to use in order to
+	// generate the block templates the miners work on.
+	BlockTemplateGenerator *mining.BlkTmplGenerator
+
+	// MiningAddrs is a list of payment addresses to use for the generated
+	// blocks.  Each block template randomly chooses one of them.
+	MiningAddrs []address.Address
+
+	// MiningPayouts, when set, splits the coinbase of each block template
+	// between the payouts instead of paying to one of the MiningAddrs.
+	MiningPayouts []mining.Payout
+
+	// ProcessBlock defines the function to call with any solved blocks.
+	// It typically must run the provided block through the same set of
+	// rules and handling as any other block coming from the network.
+	ProcessBlock func(*btcutil.Block, blockchain.BehaviorFlags) (bool, error)
+
+	// IsCurrent defines the function to use to obtain whether or not the
+	// block chain is current.  No work is handed out before the chain is
+	// current since any solved blocks would end up orphaned anyways.
+	IsCurrent func() bool
+
+	// Listeners defines a slice of listeners for which the server will
+	// accept stratum connections.
+	Listeners []net.Listener
+
+	// Password is the password the workers must provide in order to be
+	// authorized.  Any password is accepted when it is empty.
+	Password string
+
+	// StartDifficulty is the share difficulty new clients start with.
+	StartDifficulty float64
+
+	// MinDifficulty and MaxDifficulty are the bounds of the share
+	// difficulty.  A maximum difficulty of zero means there is no upper
+	// limit.
+	MinDifficulty float64
+	MaxDifficulty float64
+
+	// TargetShareTime is the time between shares the share difficulty of
+	// each client is adjusted for.
+	TargetShareTime time.Duration
+}
+
+// WorkerInfo houses the share statistics of a worker.  The statistics are
+// aggregated over all connections the worker is authorized on.
+type WorkerInfo struct {
+	Name         string
+	Connections  int
+	Difficulty   float64
+	HashesPerSec float64
+	Accepted     uint64
+	Rejected     uint64
+	Stale        uint64
+	Blocks       uint64
+	LastShare    time.Time
+}
+
+// shareRecord records the time and difficulty of an accepted share in order to
+// estimate the hash rate of a worker.
+type shareRecord struct {
+	time       time.Time
+	difficulty float64
+}
+
+// workerStats houses the statistics of a worker.
+type workerStats struct {
+	info         WorkerInfo
+	firstSeen    time.Time
+	lastActivity time.Time
+	recent       []shareRecord
+}
+
+// pruneShares removes the shares which fall outside of the hash rate window.
+func (w *workerStats) pruneShares(now time.Time) {
+	cutoff := now.Add(-hashRateWindow)
+	i := 0
+	for i < len(w.recent) && w.recent[i].time.Before(cutoff) {
+		i++
+	}
+	w.recent = w.recent[i:]
+}
+
+// hashesPerSec returns the estimated hash rate of the worker based on the
+// shares accepted within the hash rate window.
+func (w *workerStats) hashesPerSec(now time.Time) float64 {
+	w.pruneShares(now)
+
+	var sum float64
+	for _, share := range w.recent {
+		sum += share.difficulty
+	}
+	window := hashRateWindow
+	if elapsed := now.Sub(w.firstSeen); elapsed < window {
+		window = elapsed
+	}
+	if window < time.Second {
+		return 0
+	}
+
+	// Each share of difficulty one takes 2^32 hashes on average.
+	return sum * math.Exp2(32) / window.Seconds()
+}
+
+// shareResult describes the outcome of a submitted share.
+type shareResult int
+
+const (
+	shareAccepted shareResult = iota
+	shareRejected
+	shareStale
+	shareBlock
+)
+
+// Server provides a Stratum v1 server which serves work derived from block
+// templates to miners in a concurrency-safe manner.  Each connection is
+// assigned a unique extra nonce prefix and share difficulty which is adjusted
+// to reach the configured share rate.  Shares which satisfy the target
+// difficulty of the network are submitted as blocks.
+type Server struct {
+	cfg         Config
+	g           *mining.BlkTmplGenerator
+	extraNonce1 uint32
+	shutdown    int32
+	newBlock    chan struct{}
+	wg          sync.WaitGroup
+	quit        chan struct{}
+
+	// The following fields are protected by mtx.
+	mtx       sync.Mutex
+	clients   map[*client]struct{}
+	workers   map[string]*workerStats
+	jobs      map[string]*job
+	jobOrder  []string
+	curJob    *job
+	nextJobID uint64
+}
+
+// isShuttingDown returns whether or not the server is shutting down.
+func (s *Server) isShuttingDown() bool {
+	return atomic.LoadInt32(&s.shutdown) != 0
+}
+
+// currentJob returns the most recent job or nil when there is none yet.
+func (s *Server) currentJob() *job {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return s.curJob
+}
+
+// addWorker records a new connection of the named worker.
+func (s *Server) addWorker(name string, difficulty float64) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	now := time.Now()
+	w, ok := s.workers[name]
+	if !ok {
+		w = &workerStats{
+			info:      WorkerInfo{Name: name},
+			firstSeen: now,
+		}
+		s.workers[name] = w
+	}
+	w.info.Connections++
+	w.info.Difficulty = difficulty
+	w.lastActivity = now
+}
+
+// setWorkerDifficulty updates the current share difficulty of the passed
+// workers.
+func (s *Server) setWorkerDifficulty(names []string, difficulty float64) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for _, name := range names {
+		if w, ok := s.workers[name]; ok {
+			w.info.Difficulty = difficulty
+		}
+	}
+}
+
+// recordShare updates the statistics of the named worker with the result of a
+// submitted share.
+func (s *Server) recordShare(name string, result shareResult, difficulty float64) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	w, ok := s.workers[name]
+	if !ok {
+		return
+	}
+	now := time.Now()
+	w.lastActivity = now
+	switch result {
+	case shareRejected:
+		w.info.Rejected++
+		return
+	case shareStale:
+		w.info.Stale++
+		return
+	case shareBlock:
+		w.info.Blocks++
+	}
+	w.info.Accepted++
+	w.info.LastShare = now
+	w.pruneShares(now)
+	w.recent = append(w.recent, shareRecord{now, difficulty})
+}
+
+// addClient registers a new connection and returns the client for it.  Nil is
+// returned when the server is shutting down.
+func (s *Server) addClient(conn net.Conn) *client {
+	var extraNonce1 [extraNonce1Size]byte
+	binary.BigEndian.PutUint32(extraNonce1[:],
+		atomic.AddUint32(&s.extraNonce1, 1))
+	c := newClient(s, conn, extraNonce1[:])
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	// The connections are closed under the lock when shutting down, so a
+	// connection which is accepted concurrently must not be added.
+	if s.isShuttingDown() {
+		return nil
+	}
+	s.clients[c] = struct{}{}
+	return c
+}
+
+// removeClient unregisters the passed client once it disconnected.
+func (s *Server) removeClient(c *client) {
+	c.mtx.Lock()
+	workers := c.workerNames()
+	c.mtx.Unlock()
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	delete(s.clients, c)
+	now := time.Now()
+	for _, name := range workers {
+		if w, ok := s.workers[name]; ok {
+			w.info.Connections--
+			w.lastActivity = now
+		}
+	}
+}
+
+// submitBlock submits the passed block to the network after ensuring it passes
+// all of the consensus validation rules.
+func (s *Server) submitBlock(block *btcutil.Block) bool {
+	// Ensure the block is not stale since a new block could have shown up
+	// while the solution was being found.
+	msgBlock := block.MsgBlock()
+	if !msgBlock.Header.PrevBlock.IsEqual(&s.g.BestSnapshot().Hash) {
+		log.Debugf("Block submitted via stratum with previous block %s "+
+			"is stale", msgBlock.Header.PrevBlock)
+		return false
+	}
+
+	// Process this block using the same rules as blocks coming from other
+	// nodes.  This will in turn relay it to the network like normal.
+	isOrphan, err := s.cfg.ProcessBlock(block, blockchain.BFNone)
+	if err != nil {
+		// Anything other than a rule violation is an unexpected error,
+		// so log that error as an internal error.
+		if _, ok := err.(blockchain.RuleError); !ok {
+			log.Errorf("Unexpected error while processing block "+
+				"submitted via stratum: %v", err)
+			return false
+		}
+
+		log.Debugf("Block submitted via stratum rejected: %v", err)
+		return false
+	}
+	if isOrphan {
+		log.Debugf("Block submitted via stratum is an orphan")
+		return false
+	}
+
+	// The block was accepted.
+	coinbaseTx := msgBlock.Transactions[0].TxOut[0]
+	log.Infof("Block submitted via stratum accepted (hash %s, amount %v)",
+		block.Hash(), btcutil.Amount(coinbaseTx.Value))
+	return true
+}
+
+// submitShare validates a share submitted by the named worker for the passed
+// job and submits the resulting block when it satisfies the target difficulty
+// of the network.
+func (s *Server) submitShare(worker, jobID string, extraNonce []byte,
+	ntime, nonce uint32, difficulty float64) error {
+
+	s.mtx.Lock()
+	j := s.jobs[jobID]
+	s.mtx.Unlock()
+	if j == nil {
+		s.recordShare(worker, shareStale, difficulty)
+		return errJobNotFound
+	}
+
+	minTime := j.template.Header.Timestamp.Unix()
+	maxTime := time.Now().Add(maxFutureTime).Unix()
+	if int64(ntime) < minTime || int64(ntime) > maxTime {
+		s.recordShare(worker, shareRejected, difficulty)
+		return fmt.Errorf("ntime out of range")
+	}
+
+	coinbase, header := j.solution(extraNonce, ntime, nonce)
+	hash := header.BlockHash()
+	hashNum := blockchain.HashToBig(&hash)
+	isBlock := hashNum.Cmp(j.target) <= 0
+	if !isBlock && hashNum.Cmp(difficultyToTarget(difficulty)) > 0 {
+		s.recordShare(worker, shareRejected, difficulty)
+		return errLowDifficulty
+	}
+
+	key := fmt.Sprintf("%x%08x%08x", extraNonce, ntime, nonce)
+	s.mtx.Lock()
+	_, duplicate := j.shares[key]
+	j.shares[key] = struct{}{}
+	s.mtx.Unlock()
+	if duplicate {
+		s.recordShare(worker, shareRejected, difficulty)
+		return errDuplicateShare
+	}
+
+	result := shareAccepted
+	if isBlock {
+		log.Infof("Stratum worker %q found a block at height %d", worker,
+			j.height)
+		if s.submitBlock(j.block(coinbase, header)) {
+			result = shareBlock
+
+			// Hand out work on top of the new block right away.
+			select {
+			case s.newBlock <- struct{}{}:
+			default:
+			}
+		}
+	}
+	s.recordShare(worker, result, difficulty)
+	return nil
+}
+
+// jobCompleted returns whether the passed job was created from a
+// transaction-free template whose full version is now available.
+func jobCompleted(j *job) bool {
+	if j.completed == nil {
+		return false
+	}
+	select {
+	case <-j.completed:
+		return true
+	default:
+		return false
+	}
+}
+
+// updateJob generates a new job when the best chain changed or when the memory
+// pool was updated and the current job is old enough, and sends it to all
+// subscribed clients.
+func (s *Server) updateJob() {
+	best := s.g.BestSnapshot()
+	cur := s.currentJob()
+	clean := cur == nil || cur.template.Header.PrevBlock != best.Hash
+	lastTxUpdate := s.g.TxSource().LastUpdated()
+	if !clean && !jobCompleted(cur) && (lastTxUpdate.Equal(cur.txUpdated) ||
+		time.Since(cur.created) < jobRefreshInterval) {
+
+		return
+	}
+
+	// No point in handing out work before the chain is synced.
+	if best.Height != 0 && !s.cfg.IsCurrent() {
+		return
+	}
+
+	// Split the coinbase between the configured payouts or choose a
+	// payment address at random.
+	var template *mining.BlockTemplate
+	var err error
+	if len(s.cfg.MiningPayouts) > 0 {
+		template, err = s.g.NewSplitBlockTemplate(s.cfg.MiningPayouts)
+	} else {
+		payToAddr := s.cfg.MiningAddrs[rand.Intn(len(s.cfg.MiningAddrs))]
+		template, err = s.g.NewBlockTemplate(payToAddr)
+	}
+	if err != nil {
+		log.Errorf("Failed to create new block template: %v", err)
+		return
+	}
+
+	s.mtx.Lock()
+	s.nextJobID++
+	j, err := newJob(fmt.Sprintf("%x", s.nextJobID), template)
+	if err != nil {
+		s.mtx.Unlock()
+		log.Errorf("Failed to create new stratum job: %v", err)
+		return
+	}
+	j.txUpdated = lastTxUpdate
+
+	// Shares for jobs which build on another block are stale, so they are
+	// all removed when the block changes.
+	if clean {
+		s.jobs = make(map[string]*job)
+		s.jobOrder = s.jobOrder[:0]
+	}
+	if len(s.jobOrder) >= maxJobs {
+		delete(s.jobs, s.jobOrder[0])
+		s.jobOrder = s.jobOrder[1:]
+	}
+	s.jobs[j.id] = j
+	s.jobOrder = append(s.jobOrder, j.id)
+	s.curJob = j
+	clients := make([]*client, 0, len(s.clients))
+	for c := range s.clients {
+		clients = append(clients, c)
+	}
+	s.mtx.Unlock()
+
+	log.Debugf("New stratum job %s for block at height %d", j.id, j.height)
+	for _, c := range clients {
+		c.sendJob(j, clean)
+	}
+}
+
+// maintain retargets the share difficulty of clients which do not submit any
+// shares and expires the statistics of workers which have gone away.
+func (s *Server) maintain(now time.Time) {
+	s.mtx.Lock()
+	clients := make([]*client, 0, len(s.clients))
+	for c := range s.clients {
+		clients = append(clients, c)
+	}
+	for name, w := range s.workers {
+		if w.info.Connections == 0 &&
+			now.Sub(w.lastActivity) > workerExpiry {
+
+			delete(s.workers, name)
+		}
+	}
+	s.mtx.Unlock()
+
+	for _, c := range clients {
+		c.retarget(now)
+	}
+}
+
+// jobHandler keeps the work of the clients up to date.  It must be run as a
+// goroutine.
+func (s *Server) jobHandler() {
+	ticker := time.NewTicker(jobCheckInterval)
+	defer ticker.Stop()
+
+	s.updateJob()
+out:
+	for {
+		select {
+		case <-ticker.C:
+			s.updateJob()
+			s.maintain(time.Now())
+
+		case <-s.newBlock:
+			s.updateJob()
+
+		case <-s.quit:
+			break out
+		}
+	}
+
+	s.wg.Done()
+}
+
+// listenHandler accepts stratum connections on the passed listener.  It must be
+// run as a goroutine.
+func (s *Server) listenHandler(listener net.Listener) {
+	log.Infof("Stratum server listening on %s", listener.Addr())
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			// Only log the error if not forcibly shutting down.
+			if !s.isShuttingDown() {
+				log.Errorf("Can't accept stratum connection: %v",
+					err)
+			}
+			break
+		}
+
+		log.Debugf("New stratum client %s", conn.RemoteAddr())
+		c := s.addClient(conn)
+		if c == nil {
+			conn.Close()
+			break
+		}
+		s.wg.Add(1)
+		go func() {
+			c.inHandler()
+			s.wg.Done()
+		}()
+	}
+	log.Tracef("Stratum listener done for %s", listener.Addr())
+
+	s.wg.Done()
+}
+
+// Start begins accepting stratum connections and handing out work.
+func (s *Server) Start() {
+	s.wg.Add(1)
+	go s.jobHandler()
+
+	for _, listener := range s.cfg.Listeners {
+		s.wg.Add(1)
+		go s.listenHandler(listener)
+	}
+}
+
+// Stop gracefully shuts down the stratum server by closing all listeners and
+// connections.
+func (s *Server) Stop() {
+	if !atomic.CompareAndSwapInt32(&s.shutdown, 0, 1) {
+		return
+	}
+
+	close(s.quit)
+	for _, listener := range s.cfg.Listeners {
+		listener.Close()
+	}
+	s.mtx.Lock()
+	for c := range s.clients {
+		c.conn.Close()
+	}
+	s.mtx.Unlock()
+
+	s.wg.Wait()
+	log.Infof("Stratum server stopped")
+}
+
+// NumClients returns the number of connected stratum clients.
+//
+// This function is safe for concurrent access.
+func (s *Server) NumClients() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return len(s.clients)
+}
+
+// Workers returns the statistics of all known workers sorted by name.
+//
+// This function is safe for concurrent access.
+func (s *Server) Workers() []WorkerInfo {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	now := time.Now()
+	workers := make([]WorkerInfo, 0, len(s.workers))
+	for _, w := range s.workers {
+		info := w.info
+		info.HashesPerSec = w.hashesPerSec(now)
+		workers = append(workers, info)
+	}
+	sort.Slice(workers, func(i, j int) bool {
+		return workers[i].Name < workers[j].Name
+	})
+	return workers
+}
+
+// New returns a new stratum server for the provided configuration.  Use Start
+// to begin accepting connections.
+func New(cfg *Config) *Server {
+	// Start the extra nonces at a random value so they are not reused by
+	// subsequent runs.
+	extraNonce1, err := wire.RandomUint64()
+	if err != nil {
+		extraNonce1 = uint64(time.Now().UnixNano())
+	}
+
+	return &Server{
+		cfg:         *cfg,
+		g:           cfg.BlockTemplateGenerator,
+		extraNonce1: uint32(extraNonce1),
+		newBlock:    make(chan struct{}, 1),
+		quit:        make(chan struct{}),
+		clients:     make(map[*client]struct{}),
+		workers:     make(map[string]*workerStats),
+		jobs:        make(map[string]*job),
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package stratum
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil/v2"
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/mining"
+	"github.com/btcsuite/btcd/wire/v2"
+)
+
+// testTemplate returns a block template at the passed height with the passed
+// number of transactions including the coinbase.
+func testTemplate(height int32, numTxns int) *mining.BlockTemplate {
+	msgBlock := &wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version:   0x20000000,
+			PrevBlock: chainhash.Hash{0x01, 0x02, 0x03, 0x04, 0x05},
+			Timestamp: time.Unix(1700000000, 0),
+			Bits:      0x207fffff,
+		},
+	}
+	coinbase := wire.NewMsgTx(wire.TxVersion)
+	coinbase.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{},
+			wire.MaxPrevOutIndex),
+		SignatureScript: []byte{0x51, 0x51},
+		Sequence:        wire.MaxTxInSequenceNum,
+	})
+	coinbase.AddTxOut(wire.NewTxOut(5000000000, []byte{0x51}))
+	msgBlock.AddTransaction(coinbase)
+	for i := 1; i < numTxns; i++ {
+		tx := wire.NewMsgTx(wire.TxVersion)
+		tx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: *wire.NewOutPoint(
+				&chainhash.Hash{byte(i)}, uint32(i)),
+			Sequence: wire.MaxTxInSequenceNum,
+		})
+		tx.AddTxOut(wire.NewTxOut(int64(i), []byte{0x51}))
+		msgBlock.AddTransaction(tx)
+	}
+	return &mining.BlockTemplate{Block: msgBlock, Height: height}
+}
+
+// TestMerkleBranch ensures the merkle root calculated from the coinbase hash and
+// merkle branch matches the merkle root of the full block.
+func TestMerkleBranch(t *testing.T) {
+	for numTxns := 1; numTxns <= 9; numTxns++ {
+		msgBlock := testTemplate(1, numTxns).Block
+		block := btcutil.NewBlock(msgBlock)
+		want := blockchain.CalcMerkleRoot(block.Transactions(), false)
+
+		branch := merkleBranch(msgBlock.Transactions)
+		got := merkleRoot(msgBlock.Transactions[0].TxHash(), branch)
+		if got != want {
+			t.Errorf("merkle root with %d transactions: got %v, want %v",
+				numTxns, got, want)
+		}
+	}
+}
+
+// TestJobSolution ensures the coinbase transaction assembled by miners from the
+// job matches the coinbase transaction of the rebuilt block.
+func TestJobSolution(t *testing.T) {
+	template := testTemplate(500000, 4)
+	j, err := newJob("1", template)
+	if err != nil {
+		t.Fatalf("newJob: unexpected error: %v", err)
+	}
+
+	extraNonce := []byte{0xde, 0xad, 0xbe, 0xef, 0x01, 0x02, 0x03, 0x04}
+	coinbase, header := j.solution(extraNonce, 1700000100, 0x12345678)
+
+	// The miners concatenate coinb1, the extra nonce, and coinb2.
+	var serialized []byte
+	serialized = append(serialized, j.coinb1...)
+	serialized = append(serialized, extraNonce...)
+	serialized = append(serialized, j.coinb2...)
+	var buf bytes.Buffer
+	if err := coinbase.SerializeNoWitness(&buf); err != nil {
+		t.Fatalf("SerializeNoWitness: unexpected error: %v", err)
+	}
+	if !bytes.Equal(serialized, buf.Bytes()) {
+		t.Fatalf("coinbase mismatch:\ngot  %x\nwant %x", serialized,
+			buf.Bytes())
+	}
+
+	// The template must not be modified and the rebuilt block must commit
+	// to the new coinbase.
+	if bytes.Contains(template.Block.Transactions[0].TxIn[0].SignatureScript,
+		extraNonce) {
+
+		t.Fatal("template coinbase was modified")
+	}
+	block := j.block(coinbase, header)
+	want := blockchain.CalcMerkleRoot(block.Transactions(), false)
+	if header.MerkleRoot != want {
+		t.Fatalf("merkle root: got %v, want %v", header.MerkleRoot, want)
+	}
+	if header.Nonce != 0x12345678 || header.Timestamp.Unix() != 1700000100 {
+		t.Fatalf("unexpected header nonce %x or time %v", header.Nonce,
+			header.Timestamp)
+	}
+
+	params := j.notifyParams(true)
+	if params[0] != "1" || params[5] != "20000000" ||
+		params[6] != "207fffff" || params[8] != true {
+
+		t.Fatalf("unexpected notify params %v", params)
+	}
+}
+
+// TestJobCompleted ensures jobs are only reported as completed once the full
+// version of the transaction-free template they were created from is available.
+func TestJobCompleted(t *testing.T) {
+	j, err := newJob("1", testTemplate(500000, 1))
+	if err != nil {
+		t.Fatalf("newJob: unexpected error: %v", err)
+	}
+	if jobCompleted(j) {
+		t.Fatal("job for complete template reported as completed")
+	}
+
+	completed := make(chan struct{})
+	j.completed = completed
+	if jobCompleted(j) {
+		t.Fatal("job reported as completed before the template was")
+	}
+	close(completed)
+	if !jobCompleted(j) {
+		t.Fatal("job not reported as completed")
+	}
+}
+
+// TestEncodePrevHash ensures the previous block hash is encoded with the bytes
+// of each 32-bit word reversed.
+func TestEncodePrevHash(t *testing.T) {
+	var hash chainhash.Hash
+	for i := range hash {
+		hash[i] = byte(i)
+	}
+	got := encodePrevHash(&hash)
+	want := "03020100070605040b0a09080f0e0d0c" +
+		"13121110171615141b1a19181f1e1d1c"
+	if got != want {
+		t.Fatalf("encodePrevHash: got %s, want %s", got, want)
+	}
+}
+
+// TestDifficultyToTarget ensures share difficulties are converted to the
+// expected targets.
+func TestDifficultyToTarget(t *testing.T) {
+	tests := []struct {
+		difficulty float64
+		want       string
+	}{
+		{1, "00000000ffff0000000000000000000000000000000000000000000000000000"},
+		{2, "000000007fff8000000000000000000000000000000000000000000000000000"},
+		{65536, "000000000000ffff000000000000000000000000000000000000000000000000"},
+	}
+
+	for _, test := range tests {
+		want, _ := hex.DecodeString(test.want)
+		got := difficultyToTarget(test.difficulty)
+		if got.Cmp(new(big.Int).SetBytes(want)) != 0 {
+			t.Errorf("difficulty %v: got %064x, want %s",
+				test.difficulty, got, test.want)
+		}
+	}
+}
+
+// TestVarDiff ensures the share difficulty is adjusted towards the target share
+// rate within the configured bounds.
+func TestVarDiff(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	target := time.Second * 10
+
+	tests := []struct {
+		name    string
+		shares  int
+		elapsed time.Duration
+		diff    float64
+		want    float64
+		changed bool
+	}{{
+		name:    "too early",
+		shares:  3,
+		elapsed: time.Second * 30,
+		diff:    8,
+		want:    8,
+	}, {
+		name:    "on target",
+		shares:  6,
+		elapsed: time.Second * 60,
+		diff:    8,
+		want:    8,
+	}, {
+		name:    "twice as fast",
+		shares:  12,
+		elapsed: time.Second * 60,
+		diff:    8,
+		want:    16,
+		changed: true,
+	}, {
+		name:    "fast retarget limited",
+		shares:  varDiffFastShares,
+		elapsed: time.Second,
+		diff:    8,
+		want:    32,
+		changed: true,
+	}, {
+		name:    "no shares",
+		shares:  0,
+		elapsed: time.Second * 60,
+		diff:    8,
+		want:    4,
+		changed: true,
+	}, {
+		name:    "limited by max",
+		shares:  24,
+		elapsed: time.Second * 60,
+		diff:    80,
+		want:    100,
+		changed: true,
+	}, {
+		name:    "limited by min",
+		shares:  0,
+		elapsed: time.Second * 60,
+		diff:    1.5,
+		want:    1,
+		changed: true,
+	}}
+
+	for _, test := range tests {
+		v := newVarDiff(target, 1, 100, start)
+		for i := 0; i < test.shares; i++ {
+			v.addShare()
+		}
+		got, changed := v.retarget(start.Add(test.elapsed), test.diff)
+		if got != test.want || changed != test.changed {
+			t.Errorf("%s: got (%v, %v), want (%v, %v)", test.name,
+				got, changed, test.want, test.changed)
+		}
+	}
+}
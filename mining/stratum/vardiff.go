@@ -0,0 +1,102 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package stratum
+
+import (
+	"time"
+)
+
+const (
+	// varDiffRetargetShares is the number of shares at the target share
+	// time between regular difficulty retargets.
+	varDiffRetargetShares = 6
+
+	// varDiffFastShares is the number of shares after which the difficulty
+	// is retargeted before the regular retarget time has elapsed.  This
+	// allows a start difficulty which is far too low to be corrected
+	// quickly.
+	varDiffFastShares = varDiffRetargetShares * 4
+
+	// varDiffMaxAdjust is the maximum factor by which the difficulty is
+	// changed in a single retarget.
+	varDiffMaxAdjust = 4.0
+
+	// varDiffTolerance is the relative deviation from the target share
+	// rate which is tolerated without changing the difficulty.
+	varDiffTolerance = 0.3
+)
+
+// varDiff adjusts the share difficulty of a client so that it submits shares at
+// roughly the configured target rate.  It is not safe for concurrent access.
+type varDiff struct {
+	targetTime   time.Duration
+	retargetTime time.Duration
+	minDiff      float64
+	maxDiff      float64
+	lastRetarget time.Time
+	shares       int
+}
+
+// newVarDiff returns a new variable difficulty state which targets the passed
+// time between shares while keeping the difficulty within the provided bounds.
+// A maximum difficulty of zero means there is no upper limit.
+func newVarDiff(targetTime time.Duration, minDiff, maxDiff float64,
+	now time.Time) *varDiff {
+
+	return &varDiff{
+		targetTime:   targetTime,
+		retargetTime: targetTime * varDiffRetargetShares,
+		minDiff:      minDiff,
+		maxDiff:      maxDiff,
+		lastRetarget: now,
+	}
+}
+
+// addShare records an accepted share.
+func (v *varDiff) addShare() {
+	v.shares++
+}
+
+// retarget returns the difficulty the client should use from now on given its
+// current difficulty along with whether or not it differs from the current one.
+// The difficulty is only changed once enough time has passed or enough shares
+// were submitted since the last retarget.
+func (v *varDiff) retarget(now time.Time, diff float64) (float64, bool) {
+	elapsed := now.Sub(v.lastRetarget)
+	if elapsed < v.retargetTime && v.shares < varDiffFastShares {
+		return diff, false
+	}
+	if elapsed <= 0 {
+		elapsed = time.Millisecond
+	}
+
+	// Halve the difficulty when no shares were submitted at all since
+	// there is no rate to base the new difficulty on.
+	ratio := 0.5
+	if v.shares > 0 {
+		ratio = float64(v.targetTime) * float64(v.shares) /
+			float64(elapsed)
+	}
+	v.lastRetarget = now
+	v.shares = 0
+
+	if ratio > 1-varDiffTolerance && ratio < 1+varDiffTolerance {
+		return diff, false
+	}
+	if ratio > varDiffMaxAdjust {
+		ratio = varDiffMaxAdjust
+	} else if ratio < 1/varDiffMaxAdjust {
+		ratio = 1 / varDiffMaxAdjust
+	}
+
+	newDiff := diff * ratio
+	if newDiff < v.minDiff {
+		newDiff = v.minDiff
+	}
+	if v.maxDiff > 0 && newDiff > v.maxDiff {
+		newDiff = v.maxDiff
+	}
+	return newDiff, newDiff != diff
+}
@@ -0,0 +1,258 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package stratum
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil/v2"
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/mining"
+	"github.com/btcsuite/btcd/txscript/v2"
+	"github.com/btcsuite/btcd/wire/v2"
+)
+
+const (
+	// extraNonce1Size is the size in bytes of the part of the extra nonce
+	// which is assigned to each client by the server.
+	extraNonce1Size = 4
+
+	// extraNonce2Size is the size in bytes of the part of the extra nonce
+	// which is iterated by the miners.
+	extraNonce2Size = 4
+
+	// extraNonceSize is the total size in bytes of the extra nonce which is
+	// reserved in the coinbase signature script.
+	extraNonceSize = extraNonce1Size + extraNonce2Size
+)
+
+var (
+	// diff1Target is the target which corresponds to a share difficulty of
+	// one.  It is the conventional pool difficulty 1 target of
+	// 0x00000000ffff0000000000000000000000000000000000000000000000000000.
+	diff1Target = blockchain.CompactToBig(0x1d00ffff)
+)
+
+// job houses a block template along with the details which are sent to the
+// miners in order to work on it and which are needed to rebuild the block
+// from the solutions they submit.
+type job struct {
+	id        string
+	template  *wire.MsgBlock
+	height    int32
+	target    *big.Int
+	created   time.Time
+	txUpdated time.Time
+
+	// completed is closed once the full version of a transaction-free
+	// template handed out first on a new tip is available.  It is nil for
+	// complete templates.
+	completed <-chan struct{}
+
+	// coinbase is the coinbase transaction of the template with the extra
+	// nonce in its signature script set to zero.  nonceOffset is the
+	// offset of the extra nonce within the signature script.
+	coinbase    *wire.MsgTx
+	nonceOffset int
+
+	// coinb1 and coinb2 are the serialized coinbase transaction before and
+	// after the extra nonce respectively.
+	coinb1 []byte
+	coinb2 []byte
+
+	// merkleBranch holds the hashes needed to calculate the merkle root
+	// from the hash of the coinbase transaction.
+	merkleBranch []chainhash.Hash
+
+	// shares tracks the accepted solutions in order to reject duplicates.
+	shares map[string]struct{}
+}
+
+// coinbaseScript returns a signature script for the coinbase transaction of a
+// block at the passed height which reserves room for the extra nonce along
+// with the offset of the extra nonce within the script.
+func coinbaseScript(height int32) ([]byte, int, error) {
+	prefix, err := txscript.NewScriptBuilder().AddInt64(int64(height)).
+		Script()
+	if err != nil {
+		return nil, 0, err
+	}
+	script, err := txscript.NewScriptBuilder().AddInt64(int64(height)).
+		AddData(make([]byte, extraNonceSize)).
+		AddData([]byte(mining.CoinbaseFlags)).Script()
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(script) > blockchain.MaxCoinbaseScriptLen {
+		return nil, 0, fmt.Errorf("coinbase transaction script length "+
+			"of %d is out of range (min: %d, max: %d)", len(script),
+			blockchain.MinCoinbaseScriptLen,
+			blockchain.MaxCoinbaseScriptLen)
+	}
+
+	// The extra nonce follows the height and its own data push opcode.
+	return script, len(prefix) + 1, nil
+}
+
+// merkleBranch returns the hashes which are needed to calculate the merkle root
+// of the passed transactions from the hash of the first transaction.  This
+// allows the miners to calculate the merkle root for the coinbase transactions
+// they create without having to know about the other transactions.
+func merkleBranch(txns []*wire.MsgTx) []chainhash.Hash {
+	// The first entry is a placeholder for the coinbase transaction which
+	// is never hashed with its siblings here.
+	level := make([]*chainhash.Hash, len(txns))
+	for i := 1; i < len(txns); i++ {
+		hash := txns[i].TxHash()
+		level[i] = &hash
+	}
+
+	var branch []chainhash.Hash
+	for len(level) > 1 {
+		branch = append(branch, *level[1])
+
+		// Duplicate the final hash of levels with an odd number of
+		// entries as the merkle tree calculation does.
+		if len(level)%2 != 0 {
+			level = append(level, level[len(level)-1])
+		}
+		next := make([]*chainhash.Hash, 1, len(level)/2)
+		for i := 2; i < len(level); i += 2 {
+			hash := blockchain.HashMerkleBranches(level[i], level[i+1])
+			next = append(next, &hash)
+		}
+		level = next
+	}
+
+	return branch
+}
+
+// merkleRoot returns the merkle root which results from the passed hash of the
+// coinbase transaction and merkle branch.
+func merkleRoot(coinbaseHash chainhash.Hash, branch []chainhash.Hash) chainhash.Hash {
+	root := coinbaseHash
+	for i := range branch {
+		root = blockchain.HashMerkleBranches(&root, &branch[i])
+	}
+	return root
+}
+
+// encodePrevHash returns the passed previous block hash encoded the way it is
+// expected by stratum miners.  That is the hex encoding of the hash in internal
+// byte order with the bytes of each 32-bit word reversed.
+func encodePrevHash(hash *chainhash.Hash) string {
+	var swapped [chainhash.HashSize]byte
+	for i := 0; i < chainhash.HashSize; i += 4 {
+		swapped[i] = hash[i+3]
+		swapped[i+1] = hash[i+2]
+		swapped[i+2] = hash[i+1]
+		swapped[i+3] = hash[i]
+	}
+	return hex.EncodeToString(swapped[:])
+}
+
+// difficultyToTarget returns the target which corresponds to the passed share
+// difficulty.
+func difficultyToTarget(difficulty float64) *big.Int {
+	target := new(big.Float).SetInt(diff1Target)
+	target.Quo(target, big.NewFloat(difficulty))
+	result, _ := target.Int(nil)
+	return result
+}
+
+// newJob returns a new job with the passed id for the provided block template.
+func newJob(id string, template *mining.BlockTemplate) (*job, error) {
+	msgBlock := template.Block
+	script, nonceOffset, err := coinbaseScript(template.Height)
+	if err != nil {
+		return nil, err
+	}
+	coinbase := msgBlock.Transactions[0].Copy()
+	coinbase.TxIn[0].SignatureScript = script
+
+	// The miners hash the coinbase transaction without the witness, so the
+	// split points are based on the serialization without it.  The
+	// signature script follows the version, the input count, the previous
+	// outpoint, and the script length.
+	var buf bytes.Buffer
+	buf.Grow(coinbase.SerializeSizeStripped())
+	if err := coinbase.SerializeNoWitness(&buf); err != nil {
+		return nil, err
+	}
+	serialized := buf.Bytes()
+	split := 4 + wire.VarIntSerializeSize(1) + 36 +
+		wire.VarIntSerializeSize(uint64(len(script))) + nonceOffset
+	if !bytes.Equal(serialized[split:split+extraNonceSize],
+		make([]byte, extraNonceSize)) {
+
+		return nil, fmt.Errorf("unable to locate the extra nonce in " +
+			"the coinbase transaction")
+	}
+
+	return &job{
+		id:           id,
+		template:     msgBlock,
+		height:       template.Height,
+		target:       blockchain.CompactToBig(msgBlock.Header.Bits),
+		created:      time.Now(),
+		completed:    template.Completed(),
+		coinbase:     coinbase,
+		nonceOffset:  nonceOffset,
+		coinb1:       serialized[:split],
+		coinb2:       serialized[split+extraNonceSize:],
+		merkleBranch: merkleBranch(msgBlock.Transactions),
+		shares:       make(map[string]struct{}),
+	}, nil
+}
+
+// notifyParams returns the parameters of the mining.notify message for the
+// job.  The clean flag instructs the miners to abandon any previous work.
+func (j *job) notifyParams(clean bool) []interface{} {
+	header := &j.template.Header
+	branch := make([]string, 0, len(j.merkleBranch))
+	for i := range j.merkleBranch {
+		branch = append(branch, hex.EncodeToString(j.merkleBranch[i][:]))
+	}
+	return []interface{}{
+		j.id,
+		encodePrevHash(&header.PrevBlock),
+		hex.EncodeToString(j.coinb1),
+		hex.EncodeToString(j.coinb2),
+		branch,
+		fmt.Sprintf("%08x", uint32(header.Version)),
+		fmt.Sprintf("%08x", header.Bits),
+		fmt.Sprintf("%08x", uint32(header.Timestamp.Unix())),
+		clean,
+	}
+}
+
+// solution returns the coinbase transaction and block header which result from
+// the passed extra nonce, time, and nonce submitted by a miner.
+func (j *job) solution(extraNonce []byte, ntime, nonce uint32) (*wire.MsgTx, *wire.BlockHeader) {
+	coinbase := j.coinbase.Copy()
+	copy(coinbase.TxIn[0].SignatureScript[j.nonceOffset:], extraNonce)
+
+	header := j.template.Header
+	header.MerkleRoot = merkleRoot(coinbase.TxHash(), j.merkleBranch)
+	header.Timestamp = time.Unix(int64(ntime), 0)
+	header.Nonce = nonce
+	return coinbase, &header
+}
+
+// block returns the block which results from the passed solution.
+func (j *job) block(coinbase *wire.MsgTx, header *wire.BlockHeader) *btcutil.Block {
+	msgBlock := &wire.MsgBlock{
+		Header:       *header,
+		Transactions: make([]*wire.MsgTx, len(j.template.Transactions)),
+	}
+	msgBlock.Transactions[0] = coinbase
+	copy(msgBlock.Transactions[1:], j.template.Transactions[1:])
+	return btcutil.NewBlock(msgBlock)
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mining
+
+import (
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/wire/v2"
+)
+
+const (
+	// templateCacheMaxAge is the maximum amount of time an assembled block
+	// template is handed out again while the transaction source has not
+	// changed.  This ensures transactions with time based lock times are
+	// picked up once they are final.
+	templateCacheMaxAge = time.Minute
+)
+
+// preparedTx houses the details about a source transaction which only depend
+// on the block the template builds on.  They are kept across templates for the
+// same block so only transactions which were not seen before need to be looked
+// up and validated.
+type preparedTx struct {
+	// utxos houses the outputs referenced by the transaction which are in
+	// the main chain.  The entries must not be modified.
+	utxos *blockchain.UtxoViewpoint
+
+	// priority is the priority of the transaction at the next height.
+	priority float64
+
+	// claimPriority is set when the transaction updates or supports claims
+	// and claimHeight is the height of the oldest claim output it spends.
+	// See txPQClaimsFirst for details.
+	claimPriority bool
+	claimHeight   int32
+
+	// sigOpCost is the signature operation cost of the transaction which
+	// is only valid when sigOpCostKnown is set.
+	sigOpCost      int
+	sigOpCostKnown bool
+
+	// validated is set once the transaction passed the input and script
+	// checks while invalid is set when it failed them.
+	validated bool
+	invalid   bool
+}
+
+// templateCache houses the state which is reused by subsequent block templates
+// for the same block.  This allows the templates to be updated incrementally as
+// transactions arrive and leave the transaction source instead of preparing
+// all of them from scratch each time.
+type templateCache struct {
+	// prevHash is the hash of the block the cached state builds on.
+	prevHash chainhash.Hash
+
+	// txns houses the prepared source transactions keyed by their witness
+	// hash so that transactions with a malleated witness are never
+	// confused with each other.
+	txns map[chainhash.Hash]*preparedTx
+
+	// template is the most recently assembled template along with the
+	// payouts of its coinbase, the last update time of the transaction
+	// source it was assembled from, and the time it was assembled.
+	template    *BlockTemplate
+	payouts     []Payout
+	lastUpdated time.Time
+	created     time.Time
+}
+
+// newTemplateCache returns an empty template cache for templates which build
+// on the passed block.
+func newTemplateCache(prevHash *chainhash.Hash) *templateCache {
+	return &templateCache{
+		prevHash: *prevHash,
+		txns:     make(map[chainhash.Hash]*preparedTx),
+	}
+}
+
+// reusableTemplate returns a copy of the cached template when it was assembled
+// from the same transaction source state for the same payouts recently enough
+// and nil otherwise.
+func (c *templateCache) reusableTemplate(payouts []Payout,
+	lastUpdated time.Time, now time.Time) *BlockTemplate {
+
+	// The last update time of the transaction source only has a resolution
+	// of one second, so the template might miss transactions which were
+	// added during the same second it was assembled in.
+	if c.template == nil || !c.lastUpdated.Equal(lastUpdated) ||
+		c.created.Sub(lastUpdated) < time.Second ||
+		now.Sub(c.created) > templateCacheMaxAge ||
+		!samePayouts(c.payouts, payouts) {
+
+		return nil
+	}
+	return copyBlockTemplate(c.template)
+}
+
+// prune removes all prepared transactions which are no longer in the passed
+// set of witness hashes.
+func (c *templateCache) prune(seen map[chainhash.Hash]struct{}) {
+	for hash := range c.txns {
+		if _, ok := seen[hash]; !ok {
+			delete(c.txns, hash)
+		}
+	}
+}
+
+// copyBlockTemplate returns a copy of the passed block template which can be
+// modified by the caller without affecting the original.  The coinbase
+// transaction and the header are copied while all other transactions are
+// shared since they are never modified.
+func copyBlockTemplate(template *BlockTemplate) *BlockTemplate {
+	msgBlock := &wire.MsgBlock{
+		Header:       template.Block.Header,
+		Transactions: make([]*wire.MsgTx, len(template.Block.Transactions)),
+	}
+	copy(msgBlock.Transactions, template.Block.Transactions)
+	msgBlock.Transactions[0] = template.Block.Transactions[0].Copy()
+
+	templateCopy := *template
+	templateCopy.Block = msgBlock
+	templateCopy.Fees = append([]int64(nil), template.Fees...)
+	templateCopy.SigOpCosts = append([]int64(nil), template.SigOpCosts...)
+	templateCopy.WitnessCommitment = append([]byte(nil),
+		template.WitnessCommitment...)
+	return &templateCopy
+}
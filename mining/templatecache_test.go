@@ -0,0 +1,132 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mining
+
+import (
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/address/v2"
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg/v2"
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/wire/v2"
+)
+
+// TestMergeUtxoViewCopies ensures merging a utxo view does not allow spending
+// entries in the merged view to modify the source view.
+func TestMergeUtxoViewCopies(t *testing.T) {
+	sourceTx := wire.NewMsgTx(wire.TxVersion)
+	sourceTx.AddTxOut(wire.NewTxOut(5000, []byte{0x51}))
+	source := newUtxoViewpoint([]*wire.MsgTx{sourceTx}, []int32{100})
+
+	merged := blockchain.NewUtxoViewpoint()
+	mergeUtxoView(merged, source)
+
+	outpoint := wire.OutPoint{Hash: sourceTx.TxHash(), Index: 0}
+	merged.LookupEntry(outpoint).Spend()
+	if !merged.LookupEntry(outpoint).IsSpent() {
+		t.Fatal("merged entry is not spent")
+	}
+	if source.LookupEntry(outpoint).IsSpent() {
+		t.Fatal("spending the merged entry modified the source view")
+	}
+}
+
+// TestTemplateCacheReuse ensures cached block templates are only handed out
+// again under the expected conditions and that the returned templates are
+// independent copies.
+func TestTemplateCacheReuse(t *testing.T) {
+	coinbase := wire.NewMsgTx(wire.TxVersion)
+	coinbase.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{},
+			wire.MaxPrevOutIndex),
+		SignatureScript: []byte{0x51, 0x51},
+	})
+	coinbase.AddTxOut(wire.NewTxOut(5000000000, []byte{0x51}))
+	msgBlock := &wire.MsgBlock{Transactions: []*wire.MsgTx{coinbase}}
+
+	addr, err := address.DecodeAddress("1L6fd93zGmtzkK6CsZFVVoCwzZV3MUtJ4F",
+		&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("DecodeAddress: unexpected error: %v", err)
+	}
+
+	lastUpdated := time.Unix(1700000000, 0)
+	created := lastUpdated.Add(time.Second * 2)
+	cache := newTemplateCache(&chainhash.Hash{0x01})
+	cache.template = &BlockTemplate{
+		Block:           msgBlock,
+		Fees:            []int64{-100},
+		SigOpCosts:      []int64{4},
+		ValidPayAddress: true,
+	}
+	payouts := []Payout{{Address: addr, Weight: PayoutWeightTotal}}
+	cache.payouts = payouts
+	cache.lastUpdated = lastUpdated
+	cache.created = created
+
+	tests := []struct {
+		name        string
+		payouts     bool
+		lastUpdated time.Time
+		now         time.Time
+		reused      bool
+	}{{
+		name:        "unchanged source",
+		payouts:     true,
+		lastUpdated: lastUpdated,
+		now:         created.Add(time.Second),
+		reused:      true,
+	}, {
+		name:        "updated source",
+		payouts:     true,
+		lastUpdated: lastUpdated.Add(time.Second),
+		now:         created.Add(time.Second),
+	}, {
+		name:        "different payouts",
+		lastUpdated: lastUpdated,
+		now:         created.Add(time.Second),
+	}, {
+		name:        "too old",
+		payouts:     true,
+		lastUpdated: lastUpdated,
+		now:         created.Add(templateCacheMaxAge * 2),
+	}}
+
+	for _, test := range tests {
+		var testPayouts []Payout
+		if test.payouts {
+			testPayouts = payouts
+		}
+		template := cache.reusableTemplate(testPayouts, test.lastUpdated,
+			test.now)
+		if (template != nil) != test.reused {
+			t.Errorf("%s: reused %v, want %v", test.name,
+				template != nil, test.reused)
+		}
+	}
+
+	// Templates assembled during the same second as the last update might
+	// miss transactions, so they must not be reused.
+	cache.created = lastUpdated.Add(time.Millisecond * 500)
+	if cache.reusableTemplate(payouts, lastUpdated, created) != nil {
+		t.Fatal("reused template assembled during the last update")
+	}
+	cache.created = created
+
+	// Modifying the returned template must not modify the cached one.
+	template := cache.reusableTemplate(payouts, lastUpdated, created)
+	template.Block.Header.Nonce = 1
+	template.Block.Transactions[0].TxOut[0].PkScript = []byte{0x52}
+	template.Fees[0] = 0
+	cached := cache.template
+	if cached.Block.Header.Nonce != 0 ||
+		cached.Block.Transactions[0].TxOut[0].PkScript[0] != 0x51 ||
+		cached.Fees[0] != -100 {
+
+		t.Fatal("modifying the reused template modified the cache")
+	}
+}
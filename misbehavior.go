@@ -0,0 +1,173 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/connmgr"
+)
+
+// maxMisbehaviorRecords is the maximum number of misbehavior records kept per
+// peer.  Older records are dropped first.
+const maxMisbehaviorRecords = 16
+
+// misbehaviorCategory classifies the ways in which a peer can misbehave.
+type misbehaviorCategory uint8
+
+const (
+	// misbehaviorFlood covers requests which exhaust resources when they
+	// are sent in bursts, such as mempool and large getdata requests.
+	misbehaviorFlood misbehaviorCategory = iota
+
+	// misbehaviorNotFound covers notfound messages for data which was
+	// announced by the peer.
+	misbehaviorNotFound
+
+	// misbehaviorProtocol covers messages which knowingly violate the
+	// negotiated protocol.
+	misbehaviorProtocol
+
+	// misbehaviorMalformed covers messages which could not be decoded,
+	// exceed the maximum payload size or have an unknown command.
+	misbehaviorMalformed
+
+	// numMisbehaviorCategories is the number of misbehavior categories.
+	numMisbehaviorCategories
+)
+
+// misbehaviorCategoryNames maps the misbehavior categories to the names used
+// for them in the options and the RPC server.
+var misbehaviorCategoryNames = [numMisbehaviorCategories]string{
+	misbehaviorFlood:     "flood",
+	misbehaviorNotFound:  "notfound",
+	misbehaviorProtocol:  "protocol",
+	misbehaviorMalformed: "malformed",
+}
+
+// String returns the name of the misbehavior category.
+func (c misbehaviorCategory) String() string {
+	if c >= numMisbehaviorCategories {
+		return fmt.Sprintf("unknown(%d)", uint8(c))
+	}
+	return misbehaviorCategoryNames[c]
+}
+
+// misbehaviorReaction describes how the server reacts to a category of peer
+// misbehavior.
+type misbehaviorReaction uint8
+
+const (
+	// reactBan increases the ban score of the peer and bans and
+	// disconnects it once the score exceeds the ban threshold.
+	reactBan misbehaviorReaction = iota
+
+	// reactDisconnect increases the ban score of the peer and only
+	// disconnects it once the score exceeds the ban threshold.
+	reactDisconnect
+
+	// reactIgnore records the misbehavior without increasing the ban
+	// score of the peer.
+	reactIgnore
+
+	// numMisbehaviorReactions is the number of misbehavior reactions.
+	numMisbehaviorReactions
+)
+
+// misbehaviorReactionNames maps the misbehavior reactions to the names used
+// for them in the options.
+var misbehaviorReactionNames = [numMisbehaviorReactions]string{
+	reactBan:        "ban",
+	reactDisconnect: "disconnect",
+	reactIgnore:     "ignore",
+}
+
+// parseMisbehaviorReaction parses an option value of the form
+// category:reaction.
+func parseMisbehaviorReaction(value string) (misbehaviorCategory,
+	misbehaviorReaction, error) {
+
+	categoryName, reactionName, found := strings.Cut(value, ":")
+	if !found {
+		return 0, 0, fmt.Errorf("missing reaction")
+	}
+
+	category := numMisbehaviorCategories
+	for c, name := range misbehaviorCategoryNames {
+		if name == categoryName {
+			category = misbehaviorCategory(c)
+			break
+		}
+	}
+	if category == numMisbehaviorCategories {
+		return 0, 0, fmt.Errorf("unknown category %q", categoryName)
+	}
+
+	for r, name := range misbehaviorReactionNames {
+		if name == reactionName {
+			return category, misbehaviorReaction(r), nil
+		}
+	}
+	return 0, 0, fmt.Errorf("unknown reaction %q", reactionName)
+}
+
+// misbehaviorRecord describes an instance of misbehavior of a peer.
+type misbehaviorRecord struct {
+	time     time.Time
+	category misbehaviorCategory
+	reason   string
+	banScore uint32
+}
+
+// misbehaviorTracker tracks the ban score of a peer along with its most
+// recent misbehavior.  It is safe for concurrent access.
+type misbehaviorTracker struct {
+	score connmgr.DynamicBanScore
+
+	mtx     sync.Mutex
+	records []misbehaviorRecord
+}
+
+// add records misbehavior of the passed category at the passed time and
+// increases the persistent and decaying ban score by the passed values.  It
+// returns the resulting ban score.
+func (m *misbehaviorTracker) add(category misbehaviorCategory, persistent,
+	transient uint32, reason string, now time.Time) uint32 {
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	score := m.score.Int()
+	if persistent != 0 || transient != 0 {
+		score = m.score.Increase(persistent, transient)
+	}
+	if len(m.records) == maxMisbehaviorRecords {
+		copy(m.records, m.records[1:])
+		m.records = m.records[:len(m.records)-1]
+	}
+	m.records = append(m.records, misbehaviorRecord{
+		time:     now,
+		category: category,
+		reason:   reason,
+		banScore: score,
+	})
+	return score
+}
+
+// BanScore returns the current ban score.
+func (m *misbehaviorTracker) BanScore() uint32 {
+	return m.score.Int()
+}
+
+// Records returns a copy of the misbehavior records from oldest to newest.
+func (m *misbehaviorTracker) Records() []misbehaviorRecord {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	return append([]misbehaviorRecord(nil), m.records...)
+}
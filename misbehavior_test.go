@@ -0,0 +1,120 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestParseMisbehaviorReaction ensures misbehavior option values are parsed
+// into the expected categories and reactions.
+func TestParseMisbehaviorReaction(t *testing.T) {
+	tests := []struct {
+		value    string
+		category misbehaviorCategory
+		reaction misbehaviorReaction
+		err      bool
+	}{
+		{"flood:ignore", misbehaviorFlood, reactIgnore, false},
+		{"notfound:disconnect", misbehaviorNotFound, reactDisconnect,
+			false},
+		{"protocol:ban", misbehaviorProtocol, reactBan, false},
+		{"malformed:ignore", misbehaviorMalformed, reactIgnore, false},
+		{"protocol", 0, 0, true},
+		{"spam:ban", 0, 0, true},
+		{"flood:kick", 0, 0, true},
+	}
+
+	for _, test := range tests {
+		category, reaction, err := parseMisbehaviorReaction(test.value)
+		if (err != nil) != test.err {
+			t.Errorf("parseMisbehaviorReaction(%q): unexpected "+
+				"error %v", test.value, err)
+			continue
+		}
+		if category != test.category || reaction != test.reaction {
+			t.Errorf("parseMisbehaviorReaction(%q): got (%v, %d), "+
+				"want (%v, %d)", test.value, category, reaction,
+				test.category, test.reaction)
+		}
+	}
+}
+
+// TestMisbehaving ensures misbehavior is recorded with its category and that
+// peers are banned, disconnected or left alone according to the reaction
+// configured for the category.
+func TestMisbehaving(t *testing.T) {
+	origCfg := cfg
+	defer func() { cfg = origCfg }()
+
+	cfg = &config{BanThreshold: 100}
+	cfg.misbehaviorReactions[misbehaviorNotFound] = reactDisconnect
+	cfg.misbehaviorReactions[misbehaviorFlood] = reactIgnore
+
+	tests := []struct {
+		category     misbehaviorCategory
+		permissions  peerPermissions
+		wantScore    uint32
+		disconnected bool
+		banned       bool
+	}{
+		{misbehaviorProtocol, 0, 101, true, true},
+		{misbehaviorNotFound, 0, 101, true, false},
+		{misbehaviorFlood, 0, 0, false, false},
+		{misbehaviorProtocol, permNoBan, 0, false, false},
+	}
+	for i, test := range tests {
+		s, sp := newTestServerPeer(t)
+		s.banPeers = make(chan *serverPeer, 1)
+		sp.permissions = test.permissions
+
+		disconnected := sp.misbehaving(test.category, 60, 0, "first")
+		disconnected = disconnected ||
+			sp.misbehaving(test.category, 41, 0, "second")
+		if disconnected != test.disconnected {
+			t.Errorf("test %d: got disconnected %v, want %v", i,
+				disconnected, test.disconnected)
+		}
+		if banned := len(s.banPeers) == 1; banned != test.banned {
+			t.Errorf("test %d: got banned %v, want %v", i, banned,
+				test.banned)
+		}
+
+		records := sp.misbehavior.Records()
+		if len(records) != 2 || records[1].category != test.category ||
+			records[1].reason != "second" ||
+			records[1].banScore != test.wantScore {
+
+			t.Errorf("test %d: unexpected records %+v", i, records)
+		}
+	}
+}
+
+// TestMisbehaviorTrackerRecords ensures only the most recent misbehavior is
+// kept.
+func TestMisbehaviorTrackerRecords(t *testing.T) {
+	var m misbehaviorTracker
+	now := time.Unix(1700000000, 0)
+	for i := 0; i < maxMisbehaviorRecords+2; i++ {
+		m.add(misbehaviorFlood, 0, 1, fmt.Sprint(i), now)
+	}
+
+	records := m.Records()
+	if len(records) != maxMisbehaviorRecords {
+		t.Fatalf("got %d records, want %d", len(records),
+			maxMisbehaviorRecords)
+	}
+	if records[0].reason != "2" ||
+		records[len(records)-1].reason != fmt.Sprint(maxMisbehaviorRecords+1) {
+
+		t.Fatalf("unexpected records %+v", records)
+	}
+	if m.BanScore() != maxMisbehaviorRecords+2 {
+		t.Fatalf("got ban score %d, want %d", m.BanScore(),
+			maxMisbehaviorRecords+2)
+	}
+}
@@ -0,0 +1,404 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// natpmpPort is the port NAT-PMP and PCP servers listen on.
+	natpmpPort = 5351
+
+	// natpmpInitialTimeout is the time to wait for the first response to a
+	// NAT-PMP or PCP request.  It is doubled for every retransmission as
+	// described by RFC 6886.
+	natpmpInitialTimeout = time.Millisecond * 250
+
+	// natpmpMaxAttempts is the maximum number of times a NAT-PMP or PCP
+	// request is sent before giving up.
+	natpmpMaxAttempts = 5
+
+	// NAT-PMP and PCP versions.
+	natpmpVersion = 0
+	pcpVersion    = 2
+
+	// NAT-PMP opcodes as described by RFC 6886.
+	natpmpOpExternalAddress = 0
+	natpmpOpMapUDP          = 1
+	natpmpOpMapTCP          = 2
+
+	// PCP opcodes as described by RFC 6887.
+	pcpOpAnnounce = 0
+	pcpOpMap      = 1
+
+	// natpmpResultUnsupportedVersion is the result code NAT-PMP and PCP
+	// servers respond with to requests for a version they don't support.
+	natpmpResultUnsupportedVersion = 1
+
+	// natpmpResponseFlag is set in the opcode of responses.
+	natpmpResponseFlag = 0x80
+
+	// pcpHeaderLen and pcpMapLen are the lengths of the PCP common header
+	// and the MAP opcode data.
+	pcpHeaderLen = 24
+	pcpMapLen    = 36
+)
+
+// natpmpRequest sends the passed NAT-PMP or PCP request to the gateway and
+// returns the first response from it which is at least minLen bytes long and
+// responds to the opcode of the request.  Requests are retransmitted with an
+// exponentially increasing timeout.  Responses which indicate the server only
+// supports another version are returned as well so the caller can fall back
+// to that version.
+func natpmpRequest(gateway *net.UDPAddr, req []byte, minLen int) ([]byte, error) {
+	conn, err := net.DialUDP("udp", nil, gateway)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	buf := make([]byte, 1100)
+	timeout := natpmpInitialTimeout
+	for i := 0; i < natpmpMaxAttempts; i++ {
+		if _, err := conn.Write(req); err != nil {
+			return nil, err
+		}
+
+		deadline := time.Now().Add(timeout)
+		timeout *= 2
+		if err := conn.SetReadDeadline(deadline); err != nil {
+			return nil, err
+		}
+		for {
+			n, err := conn.Read(buf)
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			resp := buf[:n]
+			if n < 4 || resp[1] != req[1]|natpmpResponseFlag {
+				continue
+			}
+
+			// Servers which don't support the version of the
+			// request respond with their own version.
+			if resp[0] != req[0] {
+				if natpmpResultCode(resp) ==
+					natpmpResultUnsupportedVersion {
+
+					return resp, nil
+				}
+				continue
+			}
+			if n < minLen {
+				continue
+			}
+			return resp, nil
+		}
+	}
+	return nil, errors.New("no response from gateway")
+}
+
+// natpmpResultCode returns the result code of the passed NAT-PMP or PCP
+// response.
+func natpmpResultCode(resp []byte) uint16 {
+	if resp[0] == pcpVersion {
+		return uint16(resp[3])
+	}
+	return binary.BigEndian.Uint16(resp[2:4])
+}
+
+// natpmpProtocolOpcode returns the NAT-PMP opcode to map ports of the passed
+// protocol.
+func natpmpProtocolOpcode(protocol string) (byte, error) {
+	switch strings.ToLower(protocol) {
+	case "tcp":
+		return natpmpOpMapTCP, nil
+	case "udp":
+		return natpmpOpMapUDP, nil
+	}
+	return 0, fmt.Errorf("unsupported protocol %q", protocol)
+}
+
+// natpmpNAT implements the NAT interface using NAT-PMP as described by RFC
+// 6886.
+type natpmpNAT struct {
+	gateway *net.UDPAddr
+}
+
+// Protocol implements the NAT interface by returning NAT-PMP.
+func (n *natpmpNAT) Protocol() string {
+	return "NAT-PMP"
+}
+
+// GetExternalAddress implements the NAT interface by requesting the external
+// address from the gateway.
+func (n *natpmpNAT) GetExternalAddress() (net.IP, error) {
+	resp, err := natpmpRequest(n.gateway,
+		[]byte{natpmpVersion, natpmpOpExternalAddress}, 12)
+	if err != nil {
+		return nil, err
+	}
+	if result := natpmpResultCode(resp); result != 0 {
+		return nil, fmt.Errorf("NAT-PMP error %d", result)
+	}
+	return net.IPv4(resp[8], resp[9], resp[10], resp[11]), nil
+}
+
+// mapPort requests a mapping from the passed external port to the passed
+// internal port for the passed lifetime in seconds and returns the mapped
+// external port.
+func (n *natpmpNAT) mapPort(protocol string, externalPort, internalPort,
+	lifetime int) (int, error) {
+
+	opcode, err := natpmpProtocolOpcode(protocol)
+	if err != nil {
+		return 0, err
+	}
+	req := make([]byte, 12)
+	req[0] = natpmpVersion
+	req[1] = opcode
+	binary.BigEndian.PutUint16(req[4:6], uint16(internalPort))
+	binary.BigEndian.PutUint16(req[6:8], uint16(externalPort))
+	binary.BigEndian.PutUint32(req[8:12], uint32(lifetime))
+
+	resp, err := natpmpRequest(n.gateway, req, 16)
+	if err != nil {
+		return 0, err
+	}
+	if result := natpmpResultCode(resp); result != 0 {
+		return 0, fmt.Errorf("NAT-PMP error %d", result)
+	}
+	return int(binary.BigEndian.Uint16(resp[10:12])), nil
+}
+
+// AddPortMapping implements the NAT interface by requesting a port mapping
+// from the gateway.
+func (n *natpmpNAT) AddPortMapping(protocol string, externalPort,
+	internalPort int, description string, timeout int) (int, error) {
+
+	return n.mapPort(protocol, externalPort, internalPort, timeout)
+}
+
+// DeletePortMapping implements the NAT interface by requesting the removal of
+// the port mapping from the gateway.
+func (n *natpmpNAT) DeletePortMapping(protocol string, externalPort,
+	internalPort int) error {
+
+	_, err := n.mapPort(protocol, 0, internalPort, 0)
+	return err
+}
+
+// pcpNAT implements the NAT interface using PCP as described by RFC 6887.
+type pcpNAT struct {
+	gateway  *net.UDPAddr
+	clientIP net.IP
+
+	// The following fields are protected by mtx.
+	mtx        sync.Mutex
+	nonces     map[string][12]byte
+	externalIP net.IP
+}
+
+// Protocol implements the NAT interface by returning PCP.
+func (n *pcpNAT) Protocol() string {
+	return "PCP"
+}
+
+// pcpHeader returns a PCP request header for the passed opcode and lifetime.
+func (n *pcpNAT) pcpHeader(opcode byte, lifetime int) []byte {
+	req := make([]byte, pcpHeaderLen, pcpHeaderLen+pcpMapLen)
+	req[0] = pcpVersion
+	req[1] = opcode
+	binary.BigEndian.PutUint32(req[4:8], uint32(lifetime))
+	copy(req[8:24], n.clientIP.To16())
+	return req
+}
+
+// mapPort sends a MAP request for the passed ports and lifetime in seconds
+// and returns the assigned external port and address.  The same nonce is used
+// for all requests for a protocol and internal port so mappings can be renewed
+// and deleted.
+func (n *pcpNAT) mapPort(protocol string, externalPort, internalPort,
+	lifetime int) (int, net.IP, error) {
+
+	var protocolNum byte
+	switch strings.ToLower(protocol) {
+	case "tcp":
+		protocolNum = 6
+	case "udp":
+		protocolNum = 17
+	default:
+		return 0, nil, fmt.Errorf("unsupported protocol %q", protocol)
+	}
+
+	n.mtx.Lock()
+	key := fmt.Sprintf("%s:%d", protocol, internalPort)
+	nonce, ok := n.nonces[key]
+	if !ok {
+		if _, err := rand.Read(nonce[:]); err != nil {
+			n.mtx.Unlock()
+			return 0, nil, err
+		}
+		n.nonces[key] = nonce
+	}
+	n.mtx.Unlock()
+
+	req := n.pcpHeader(pcpOpMap, lifetime)
+	var mapData [pcpMapLen]byte
+	copy(mapData[0:12], nonce[:])
+	mapData[12] = protocolNum
+	binary.BigEndian.PutUint16(mapData[16:18], uint16(internalPort))
+	binary.BigEndian.PutUint16(mapData[18:20], uint16(externalPort))
+	if n.clientIP.To4() != nil {
+		copy(mapData[20:36], net.IPv4zero.To16())
+	}
+	req = append(req, mapData[:]...)
+
+	resp, err := natpmpRequest(n.gateway, req, pcpHeaderLen+pcpMapLen)
+	if err != nil {
+		return 0, nil, err
+	}
+	if resp[0] != pcpVersion {
+		return 0, nil, errors.New("gateway does not support PCP")
+	}
+	if result := natpmpResultCode(resp); result != 0 {
+		return 0, nil, fmt.Errorf("PCP error %d", result)
+	}
+	if !bytes.Equal(resp[pcpHeaderLen:pcpHeaderLen+12], nonce[:]) {
+		return 0, nil, errors.New("PCP response nonce mismatch")
+	}
+	mapped := resp[pcpHeaderLen:]
+	port := int(binary.BigEndian.Uint16(mapped[18:20]))
+	ip := net.IP(append([]byte(nil), mapped[20:36]...))
+	return port, ip, nil
+}
+
+// GetExternalAddress implements the NAT interface by returning the external
+// address assigned to the most recent port mapping.  PCP has no request for
+// the external address, so a port mapping must have been added first.
+func (n *pcpNAT) GetExternalAddress() (net.IP, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+
+	if n.externalIP == nil {
+		return nil, errors.New("no PCP port mapping")
+	}
+	return n.externalIP, nil
+}
+
+// AddPortMapping implements the NAT interface by sending a PCP MAP request to
+// the gateway.
+func (n *pcpNAT) AddPortMapping(protocol string, externalPort,
+	internalPort int, description string, timeout int) (int, error) {
+
+	port, ip, err := n.mapPort(protocol, externalPort, internalPort, timeout)
+	if err != nil {
+		return 0, err
+	}
+
+	n.mtx.Lock()
+	n.externalIP = ip
+	n.mtx.Unlock()
+	return port, nil
+}
+
+// DeletePortMapping implements the NAT interface by sending a PCP MAP request
+// with a lifetime of zero to the gateway.
+func (n *pcpNAT) DeletePortMapping(protocol string, externalPort,
+	internalPort int) error {
+
+	_, _, err := n.mapPort(protocol, 0, internalPort, 0)
+	return err
+}
+
+// DiscoverNATPMP probes the passed gateway for PCP and NAT-PMP support and
+// returns a NAT for the first protocol it supports.  PCP is preferred since it
+// also supports IPv6.
+func DiscoverNATPMP(gateway *net.UDPAddr) (NAT, error) {
+	// Determine the address the gateway sees requests from, which PCP
+	// requests have to include.
+	conn, err := net.DialUDP("udp", nil, gateway)
+	if err != nil {
+		return nil, err
+	}
+	clientIP := conn.LocalAddr().(*net.UDPAddr).IP
+	conn.Close()
+
+	// Servers which only support NAT-PMP respond to the PCP announce
+	// request with an unsupported version error.
+	pcp := &pcpNAT{
+		gateway:  gateway,
+		clientIP: clientIP,
+		nonces:   make(map[string][12]byte),
+	}
+	resp, err := natpmpRequest(gateway, pcp.pcpHeader(pcpOpAnnounce, 0),
+		pcpHeaderLen)
+	if err != nil {
+		return nil, err
+	}
+	if resp[0] == pcpVersion && natpmpResultCode(resp) == 0 {
+		return pcp, nil
+	}
+
+	natpmp := &natpmpNAT{gateway: gateway}
+	if _, err := natpmp.GetExternalAddress(); err != nil {
+		return nil, err
+	}
+	return natpmp, nil
+}
+
+// defaultGateway returns the address of the default IPv4 gateway.  It is read
+// from the routing table on Linux.  Elsewhere, and when the routing table is
+// not available, the gateway is assumed to be the first address of the /24
+// network of the local address used to reach the internet.
+func defaultGateway() (net.IP, error) {
+	if f, err := os.Open("/proc/net/route"); err == nil {
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			// The destination and gateway are the second and third
+			// fields encoded as little-endian hex.
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 3 || fields[1] != "00000000" {
+				continue
+			}
+			gw, err := hex.DecodeString(fields[2])
+			if err != nil || len(gw) != 4 {
+				continue
+			}
+			return net.IPv4(gw[3], gw[2], gw[1], gw[0]), nil
+		}
+	}
+
+	// Dialing UDP doesn't send any packets, but picks the local address
+	// used to reach the passed address.
+	conn, err := net.Dial("udp4", "8.8.8.8:53")
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	localIP := conn.LocalAddr().(*net.UDPAddr).IP.To4()
+	if localIP == nil {
+		return nil, errors.New("unable to determine the default gateway")
+	}
+	return net.IPv4(localIP[0], localIP[1], localIP[2], 1), nil
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"sync"
+	"testing"
+)
+
+// fakeGateway is a NAT-PMP server which also supports PCP when pcp is set.  It
+// maps every requested internal port to the internal port plus one and records
+// the lifetimes of the requested mappings.
+type fakeGateway struct {
+	conn net.PacketConn
+	pcp  bool
+
+	mtx       sync.Mutex
+	lifetimes []uint32
+	nonces    [][]byte
+}
+
+// fakeExternalIP is the external address of the fake gateway.
+var fakeExternalIP = net.IPv4(203, 0, 113, 7)
+
+// newFakeGateway starts a fake gateway on a random local port.
+func newFakeGateway(t *testing.T, pcp bool) *fakeGateway {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	g := &fakeGateway{conn: conn, pcp: pcp}
+	t.Cleanup(func() { conn.Close() })
+	go g.serve()
+	return g
+}
+
+// addr returns the address of the fake gateway.
+func (g *fakeGateway) addr() *net.UDPAddr {
+	return g.conn.LocalAddr().(*net.UDPAddr)
+}
+
+// respond returns the response to the passed request.
+func (g *fakeGateway) respond(req []byte) []byte {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	switch {
+	case req[0] == pcpVersion && g.pcp:
+		resp := make([]byte, pcpHeaderLen)
+		resp[0] = pcpVersion
+		resp[1] = req[1] | natpmpResponseFlag
+		if req[1] != pcpOpMap {
+			return resp
+		}
+		lifetime := binary.BigEndian.Uint32(req[4:8])
+		g.lifetimes = append(g.lifetimes, lifetime)
+		g.nonces = append(g.nonces, append([]byte(nil),
+			req[pcpHeaderLen:pcpHeaderLen+12]...))
+		binary.BigEndian.PutUint32(resp[4:8], lifetime)
+
+		mapData := append([]byte(nil), req[pcpHeaderLen:]...)
+		internalPort := binary.BigEndian.Uint16(mapData[16:18])
+		binary.BigEndian.PutUint16(mapData[18:20], internalPort+1)
+		copy(mapData[20:36], fakeExternalIP.To16())
+		return append(resp, mapData...)
+
+	case req[0] == pcpVersion:
+		// Servers which only support NAT-PMP respond to PCP requests
+		// with an unsupported version error.
+		return []byte{natpmpVersion, req[1] | natpmpResponseFlag, 0,
+			natpmpResultUnsupportedVersion, 0, 0, 0, 0}
+
+	case req[1] == natpmpOpExternalAddress:
+		resp := make([]byte, 12)
+		resp[1] = natpmpResponseFlag
+		copy(resp[8:12], fakeExternalIP.To4())
+		return resp
+
+	default:
+		lifetime := binary.BigEndian.Uint32(req[8:12])
+		g.lifetimes = append(g.lifetimes, lifetime)
+		resp := make([]byte, 16)
+		resp[1] = req[1] | natpmpResponseFlag
+		internalPort := binary.BigEndian.Uint16(req[4:6])
+		binary.BigEndian.PutUint16(resp[8:10], internalPort)
+		binary.BigEndian.PutUint16(resp[10:12], internalPort+1)
+		binary.BigEndian.PutUint32(resp[12:16], lifetime)
+		return resp
+	}
+}
+
+// serve answers the requests to the fake gateway until it is closed.
+func (g *fakeGateway) serve() {
+	buf := make([]byte, 1100)
+	for {
+		n, addr, err := g.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		g.conn.WriteTo(g.respond(buf[:n]), addr)
+	}
+}
+
+// TestNATPMP ensures the protocol supported by the gateway is discovered and
+// that port mappings are added, renewed and deleted using it.
+func TestNATPMP(t *testing.T) {
+	for _, pcp := range []bool{true, false} {
+		g := newFakeGateway(t, pcp)
+		nat, err := DiscoverNATPMP(g.addr())
+		if err != nil {
+			t.Fatalf("DiscoverNATPMP (pcp %v): unexpected error: %v",
+				pcp, err)
+		}
+		wantProtocol := "NAT-PMP"
+		if pcp {
+			wantProtocol = "PCP"
+		}
+		if nat.Protocol() != wantProtocol {
+			t.Fatalf("DiscoverNATPMP: got protocol %s, want %s",
+				nat.Protocol(), wantProtocol)
+		}
+
+		// The port is mapped and renewed, and then deleted by requesting
+		// a lifetime of zero.
+		for i := 0; i < 2; i++ {
+			port, err := nat.AddPortMapping("tcp", 9246, 9246,
+				"test", 1200)
+			if err != nil {
+				t.Fatalf("%s: AddPortMapping: unexpected error: %v",
+					wantProtocol, err)
+			}
+			if port != 9247 {
+				t.Fatalf("%s: AddPortMapping: got port %d, want "+
+					"9247", wantProtocol, port)
+			}
+		}
+		ip, err := nat.GetExternalAddress()
+		if err != nil || !ip.Equal(fakeExternalIP) {
+			t.Fatalf("%s: GetExternalAddress: got %v (err %v), want "+
+				"%v", wantProtocol, ip, err, fakeExternalIP)
+		}
+		if err := nat.DeletePortMapping("tcp", 9247, 9246); err != nil {
+			t.Fatalf("%s: DeletePortMapping: unexpected error: %v",
+				wantProtocol, err)
+		}
+
+		g.mtx.Lock()
+		lifetimes := g.lifetimes
+		nonces := g.nonces
+		g.mtx.Unlock()
+		if len(lifetimes) != 3 || lifetimes[0] != 1200 ||
+			lifetimes[1] != 1200 || lifetimes[2] != 0 {
+
+			t.Fatalf("%s: got lifetimes %v, want [1200 1200 0]",
+				wantProtocol, lifetimes)
+		}
+
+		// PCP requires the same nonce for all requests for a mapping.
+		for _, nonce := range nonces {
+			if !bytes.Equal(nonce, nonces[0]) {
+				t.Fatalf("PCP: nonce changed between requests")
+			}
+		}
+	}
+}
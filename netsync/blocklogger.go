@@ -21,6 +21,7 @@ type blockProgressLogger struct {
 	receivedLogBlocks int64
 	receivedLogTx     int64
 	lastBlockLogTime  time.Time
+	lastScriptStats   blockchain.ScriptValidationStats
 
 	subsystemLogger btclog.Logger
 	progressAction  string
@@ -31,7 +32,8 @@ type blockProgressLogger struct {
 // The progress message is templated as follows:
 //
 //	{progressAction} {numProcessed} {blocks|block} in the last {timePeriod}
-//	({numTxs}, height {lastBlockHeight}, {lastBlockTimeStamp})
+//	({numTxs}, height {lastBlockHeight}, {lastBlockTimeStamp}, {cacheSize}
+//	cache, {scriptTime} validating {numInputs} inputs)
 func newBlockProgressLogger(progressMessage string, logger btclog.Logger) *blockProgressLogger {
 	return &blockProgressLogger{
 		lastBlockLogTime: time.Now(),
@@ -70,9 +72,18 @@ func (b *blockProgressLogger) LogBlockHeight(block *btcutil.Block, chain *blockc
 		txStr = "transaction"
 	}
 	cacheSizeStr := fmt.Sprintf("~%d MiB", chain.CachedStateSize()/1024/1024)
-	b.subsystemLogger.Infof("%s %d %s in the last %s (%d %s, height %d, %s, %s cache)",
-		b.progressAction, b.receivedLogBlocks, blockStr, tDuration, b.receivedLogTx,
-		txStr, block.Height(), block.MsgBlock().Header.Timestamp, cacheSizeStr)
+
+	// Include the time spent validating scripts since the last message.
+	scriptStats := chain.ScriptValidationStats()
+	scriptTime := scriptStats.Duration - b.lastScriptStats.Duration
+	scriptInputs := scriptStats.Inputs - b.lastScriptStats.Inputs
+	b.lastScriptStats = scriptStats
+
+	b.subsystemLogger.Infof("%s %d %s in the last %s (%d %s, height %d, %s, %s cache, "+
+		"%s validating %d inputs)", b.progressAction, b.receivedLogBlocks, blockStr,
+		tDuration, b.receivedLogTx, txStr, block.Height(),
+		block.MsgBlock().Header.Timestamp, cacheSizeStr,
+		scriptTime.Truncate(time.Millisecond), scriptInputs)
 
 	b.receivedLogBlocks = 0
 	b.receivedLogTx = 0
@@ -6,8 +6,14 @@
 Package netsync implements a concurrency safe block syncing protocol. The
 SyncManager communicates with connected peers to perform an initial block
 download, keep the chain and unconfirmed transaction pool in sync, and announce
-new blocks connected to the chain. Currently the sync manager selects a single
-sync peer that it downloads all blocks from until it is up to date with the
-longest chain the sync peer is aware of.
+new blocks connected to the chain. The sync manager selects a sync peer to
+download the headers of the longest chain from and then downloads the blocks
+from all suitable peers in parallel, reassigning the blocks of peers which
+stall the download, until it is up to date with the longest chain.
+Chains of headers with less than the required work are presynced without being
+stored and downloaded again once they are known to have enough work, so peers
+can't fill the block index with low-work headers.  When no new block arrives
+for a multiple of the target time between blocks, headers are requested from
+all peers and the sync peer is replaced so a stale tip is recovered from.
 */
 package netsync
@@ -37,5 +37,16 @@ type Config struct {
 	DisableCheckpoints bool
 	MaxPeers           int
 
+	// StaleTipFactor is the multiple of the target time per block after
+	// which the best chain tip is considered stale when no new block was
+	// connected to it.  Zero disables the stale tip detection.
+	StaleTipFactor int
+
 	FeeEstimator *mempool.FeeEstimator
+
+	// RepairBlock replaces the damaged stored copy of a block with the
+	// passed copy downloaded from a peer on behalf of RequestBlockRepair.
+	// It returns an error when the downloaded copy doesn't match the block
+	// either.  It may be nil when no blocks are repaired.
+	RepairBlock func(block *btcutil.Block) error
 }
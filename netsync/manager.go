@@ -5,6 +5,7 @@
 package netsync
 
 import (
+	"errors"
 	"math/rand"
 	"sync"
 	"sync/atomic"
@@ -45,11 +46,35 @@ const (
 	// stallSampleInterval the interval at which we will check to see if our
 	// sync has stalled.
 	stallSampleInterval = 30 * time.Second
+
+	// maxInFlightBlocksPerPeer is the maximum number of blocks requested
+	// from a single peer at a time.  During the initial block download the
+	// blocks are requested in contiguous ranges of up to this size from
+	// all sync candidates so they are downloaded from several peers in
+	// parallel.
+	maxInFlightBlocksPerPeer = 32
+
+	// blockDownloadWindow is the maximum number of blocks past the best
+	// chain block which are requested.  Blocks downloaded in parallel
+	// arrive out of order and are held in the orphan pool of the chain
+	// until their parents are processed, so the window is kept below the
+	// size of the orphan pool to avoid evicting them.
+	blockDownloadWindow = 96
+
+	// maxBlockStallDuration is the time after which a peer which has not
+	// delivered any of the blocks requested from it during the initial
+	// block download is disconnected and its blocks are requested from
+	// other peers.
+	maxBlockStallDuration = time.Minute
 )
 
 // zeroHash is the zero value hash (all zeros).  It is defined as a convenience.
 var zeroHash chainhash.Hash
 
+// ErrBlocksSuspended is returned by ProcessBlock while the processing of blocks
+// is suspended by SuspendBlocks.
+var ErrBlocksSuspended = errors.New("block processing is suspended")
+
 // newPeerMsg signifies a newly connected peer to the block handler.
 type newPeerMsg struct {
 	peer *peerpkg.Peer
@@ -136,6 +161,18 @@ type pauseMsg struct {
 	unpause <-chan struct{}
 }
 
+// repairBlockMsg is a message type to be sent across the message channel for
+// requesting a block with a damaged stored copy from peers so it is repaired.
+type repairBlockMsg struct {
+	hash chainhash.Hash
+}
+
+// suspendBlocksMsg is a message type to be sent across the message channel for
+// suspending or resuming the download and processing of blocks.
+type suspendBlocksMsg struct {
+	suspend bool
+}
+
 // headerNode is used as a node in a list of headers that are linked together
 // between checkpoints.
 type headerNode struct {
@@ -150,6 +187,15 @@ type peerSyncState struct {
 	requestQueue    []*wire.InvVect
 	requestedTxns   map[chainhash.Hash]struct{}
 	requestedBlocks map[chainhash.Hash]struct{}
+
+	// lastBlockTime is the time a requested block was last received from
+	// the peer, or the time blocks were requested from it when it had none
+	// in flight.  It is used to detect peers stalling the block download.
+	lastBlockTime time.Time
+
+	// headersSync presyncs the chain of headers sent by the peer when it
+	// has less than the work required to store it.
+	headersSync *blockchain.HeadersSync
 }
 
 // limitAdd is a helper function for maps that require a maximum limit by
@@ -199,8 +245,27 @@ type SyncManager struct {
 	// The following fields are used for the initial block download mode.
 	ibdMode bool
 
+	// The following fields are used to detect a stale best chain tip.
+	// staleTip must be accessed atomically.
+	staleTipTimeout   time.Duration
+	lastTipHash       chainhash.Hash
+	lastTipTime       time.Time
+	lastStaleTipCheck time.Time
+	staleTip          int32
+
 	// An optional fee estimator.
 	feeEstimator *mempool.FeeEstimator
+
+	// repairBlock repairs the stored blocks requested by repairBlocks.
+	// repairBlocks holds the blocks which are repaired along with the
+	// peers they were requested from and must only be accessed from the
+	// blockHandler thread.
+	repairBlock  func(block *btcutil.Block) error
+	repairBlocks map[chainhash.Hash]map[*peerpkg.Peer]struct{}
+
+	// blocksSuspended is set while the download and processing of blocks
+	// is suspended and must only be accessed from the blockHandler thread.
+	blocksSuspended bool
 }
 
 // findNextHeaderCheckpoint returns the next checkpoint after the passed height.
@@ -329,10 +394,9 @@ func (sm *SyncManager) startSync() {
 
 	// Pick randomly from the set of peers greater than our
 	// block height, falling back to a random peer of the same
-	// height if none are greater.
-	//
-	// TODO(conner): Use a better algorithm to ranking peers based on
-	// observed metrics and/or sync in parallel.
+	// height if none are greater.  The blocks are downloaded from all
+	// of these peers in parallel while the sync peer is the one used
+	// to detect whether the sync has stalled.
 	var bestPeer *peerpkg.Peer
 	switch {
 	case len(higherPeers) > 0:
@@ -352,9 +416,10 @@ func (sm *SyncManager) startSync() {
 	// event the progress time hasn't been updated recently.
 	sm.lastProgressTime = time.Now()
 
-	log.Infof("Syncing to block height %d from peer %v",
-		sm.syncPeer.LastBlock(), sm.syncPeer.Addr())
-	sm.fetchHeaderBlocks(sm.syncPeer)
+	log.Infof("Syncing to block height %d from peer %v and %d other "+
+		"peers", sm.syncPeer.LastBlock(), sm.syncPeer.Addr(),
+		len(higherPeers)-1)
+	sm.fetchBlocks()
 }
 
 // isSyncCandidate returns whether or not the peer is a candidate to consider
@@ -443,6 +508,10 @@ func (sm *SyncManager) handleNewPeerMsg(peer *peerpkg.Peer) {
 	if isSyncCandidate && sm.syncPeer == nil {
 		sm.startSync()
 	}
+
+	// Request the blocks which are repaired and couldn't be requested from
+	// any of the other peers.
+	sm.requestRepairBlocks()
 }
 
 // handleStallSample will switch to a new sync peer if the current one has
@@ -454,13 +523,18 @@ func (sm *SyncManager) handleStallSample() {
 		return
 	}
 
-	// If we don't have an active sync peer, exit early.
-	if sm.syncPeer == nil {
+	// If we don't have an active sync peer, exit early.  Peers don't stall
+	// either while no blocks are requested from them.
+	if sm.syncPeer == nil || sm.blocksSuspended {
 		return
 	}
 
-	// If the stall timeout has not elapsed, exit early.
+	// If the stall timeout has not elapsed, only check whether any of the
+	// peers blocks are downloaded from in parallel has stalled.
 	if time.Since(sm.lastProgressTime) <= maxStallDuration {
+		if sm.ibdMode {
+			sm.handleStalledBlockPeers()
+		}
 		return
 	}
 
@@ -476,6 +550,109 @@ func (sm *SyncManager) handleStallSample() {
 	sm.updateSyncPeer(disconnectSyncPeer)
 }
 
+// handleStalledBlockPeers disconnects the peers which have not delivered any of
+// the blocks requested from them within the maximum block stall duration.  As
+// the blocks are processed in order, a single slow peer would otherwise hold
+// up the blocks downloaded from all other peers.  The blocks requested from the
+// stalled peers are requested from the remaining peers instead.
+func (sm *SyncManager) handleStalledBlockPeers() {
+	var stalled, syncPeerStalled bool
+	for peer, state := range sm.peerStates {
+		if len(state.requestedBlocks) == 0 ||
+			time.Since(state.lastBlockTime) <= maxBlockStallDuration {
+
+			continue
+		}
+
+		log.Infof("Peer %s stalled the block download with %d blocks "+
+			"in flight -- disconnecting", peer,
+			len(state.requestedBlocks))
+
+		// The peer is no longer considered for syncing and its blocks
+		// are released so they can be requested from other peers.
+		sm.clearRequestedState(state)
+		state.requestedBlocks = make(map[chainhash.Hash]struct{})
+		state.syncCandidate = false
+		peer.Disconnect()
+
+		stalled = true
+		if peer == sm.syncPeer {
+			syncPeerStalled = true
+		}
+	}
+
+	switch {
+	case syncPeerStalled:
+		sm.updateSyncPeer(false)
+	case stalled:
+		sm.fetchBlocks()
+	}
+	if stalled {
+		sm.requestRepairBlocks()
+	}
+}
+
+// handleStaleTipSample detects a best chain tip to which no new block was
+// connected for the stale tip timeout.  As this happens when the sync peer
+// stopped relaying blocks outside of the initial block download, headers are
+// requested from all sync candidates and the sync peer is replaced.  This is
+// repeated every stale tip timeout until a new block is connected.
+func (sm *SyncManager) handleStaleTipSample() {
+	if sm.staleTipTimeout == 0 || atomic.LoadInt32(&sm.shutdown) != 0 {
+		return
+	}
+
+	best := sm.chain.BestSnapshot()
+	if best.Hash != sm.lastTipHash {
+		sm.lastTipHash = best.Hash
+		sm.lastTipTime = time.Now()
+		if atomic.SwapInt32(&sm.staleTip, 0) != 0 {
+			log.Infof("Best chain tip is no longer stale -- new "+
+				"block %v at height %d", best.Hash, best.Height)
+		}
+		return
+	}
+
+	// The stall handler takes care of a sync peer which stops making
+	// progress during the initial block download.
+	if sm.ibdMode && time.Since(sm.lastProgressTime) <= maxStallDuration {
+		return
+	}
+	if time.Since(sm.lastTipTime) <= sm.staleTipTimeout ||
+		time.Since(sm.lastStaleTipCheck) <= sm.staleTipTimeout {
+
+		return
+	}
+	sm.lastStaleTipCheck = time.Now()
+	atomic.StoreInt32(&sm.staleTip, 1)
+
+	locator, err := sm.chain.LatestBlockLocatorByHeader()
+	if err != nil {
+		log.Errorf("Failed to get block locator for the latest block "+
+			"header: %v", err)
+		return
+	}
+	var numPeers int
+	for peer, state := range sm.peerStates {
+		if !state.syncCandidate {
+			continue
+		}
+		peer.PushGetHeadersMsg(locator, &zeroHash)
+		numPeers++
+	}
+	log.Warnf("Potential stale tip detected -- no new block for %v at "+
+		"height %d, requesting headers from %d peers",
+		time.Since(sm.lastTipTime).Truncate(time.Second), best.Height,
+		numPeers)
+
+	if sm.syncPeer != nil {
+		if state, exists := sm.peerStates[sm.syncPeer]; exists {
+			sm.clearRequestedState(state)
+		}
+		sm.updateSyncPeer(false)
+	}
+}
+
 // shouldDCStalledSyncPeer determines whether or not we should disconnect a
 // stalled sync peer. If the peer has stalled and its reported height is greater
 // than our own best height, we will disconnect it. Otherwise, we will keep the
@@ -515,11 +692,19 @@ func (sm *SyncManager) handleDonePeerMsg(peer *peerpkg.Peer) {
 	log.Infof("Lost peer %s", peer)
 
 	sm.clearRequestedState(state)
+	sm.requestRepairBlocks()
 
 	if peer == sm.syncPeer {
 		// Update the sync peer. The server has already disconnected the
 		// peer before signaling to the sync manager.
 		sm.updateSyncPeer(false)
+		return
+	}
+
+	// Request the blocks which were in flight from the peer from the
+	// remaining peers.
+	if sm.ibdMode && len(state.requestedBlocks) > 0 {
+		sm.fetchBlocks()
 	}
 }
 
@@ -534,12 +719,30 @@ func (sm *SyncManager) clearRequestedState(state *peerSyncState) {
 	}
 
 	// Remove requested blocks from the global map so that they will be
-	// fetched from elsewhere next time we get an inv.
-	// TODO: we could possibly here check which peers have these blocks
-	// and request them now to speed things up a little.
+	// fetched from elsewhere next time we get an inv.  Blocks which have
+	// already been requested from another peer in the meantime are kept
+	// to avoid requesting them a second time.
 	for blockHash := range state.requestedBlocks {
-		delete(sm.requestedBlocks, blockHash)
+		if !sm.requestedFromOtherPeer(blockHash, state) {
+			delete(sm.requestedBlocks, blockHash)
+		}
+	}
+}
+
+// requestedFromOtherPeer returns whether the block with the passed hash is in
+// flight from a peer other than the one with the passed sync state.
+func (sm *SyncManager) requestedFromOtherPeer(blockHash chainhash.Hash,
+	state *peerSyncState) bool {
+
+	for _, otherState := range sm.peerStates {
+		if otherState == state {
+			continue
+		}
+		if _, exists := otherState.requestedBlocks[blockHash]; exists {
+			return true
+		}
 	}
+	return false
 }
 
 // updateSyncPeer choose a new sync peer to replace the current one. If
@@ -550,8 +753,13 @@ func (sm *SyncManager) updateSyncPeer(dcSyncPeer bool) {
 	log.Debugf("Updating sync peer, no progress for: %v",
 		time.Since(sm.lastProgressTime))
 
-	// First, disconnect the current sync peer if requested.
+	// First, disconnect the current sync peer if requested.  It is no
+	// longer considered for syncing so it is not selected again before the
+	// disconnect has been processed.
 	if dcSyncPeer {
+		if state, exists := sm.peerStates[sm.syncPeer]; exists {
+			state.syncCandidate = false
+		}
 		sm.syncPeer.Disconnect()
 	}
 
@@ -713,6 +921,24 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 		}
 	}
 
+	// Blocks which are requested to repair their stored copies are already
+	// part of the chain, so they are handed over for the repair instead of
+	// being processed.
+	if _, exists := sm.repairBlocks[*blockHash]; exists {
+		delete(state.requestedBlocks, *blockHash)
+		delete(sm.requestedBlocks, *blockHash)
+		state.lastBlockTime = time.Now()
+
+		if err := sm.repairBlock(bmsg.block); err != nil {
+			log.Warnf("Unable to repair block %v with the copy from "+
+				"%s: %v", blockHash, peer, err)
+			sm.requestRepairBlock(*blockHash)
+			return
+		}
+		delete(sm.repairBlocks, *blockHash)
+		return
+	}
+
 	// Check if the block is eligible for less validation since the headers
 	// have already been verified to link together and are valid up to the
 	// next checkpoint.
@@ -723,6 +949,15 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 	// will fail the insert and thus we'll retry next time we get an inv.
 	delete(state.requestedBlocks, *blockHash)
 	delete(sm.requestedBlocks, *blockHash)
+	state.lastBlockTime = time.Now()
+
+	// Drop the blocks which were already in flight when the processing of
+	// blocks was suspended.  They are requested again once it is resumed.
+	if sm.blocksSuspended {
+		log.Debugf("Dropping block %v from %s since block processing "+
+			"is suspended", blockHash, peer)
+		return
+	}
 
 	// Process the block to include validation, best chain selection, orphan
 	// handling, etc.
@@ -763,8 +998,14 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 	var heightUpdate int32
 	var blkHashUpdate *chainhash.Hash
 
+	// Blocks downloaded in parallel during the initial block download
+	// arrive out of order.  They are held as orphans until their parents,
+	// which are already being downloaded, are processed, so there is no
+	// need to request the parents.
+	ibdOrphan := isOrphan && sm.ibdMode && sm.chain.IsValidHeader(blockHash)
+
 	// Request the parents for the orphan block from the peer that sent it.
-	if isOrphan {
+	if isOrphan && !ibdOrphan {
 		// We've just received an orphan block from a peer. In order
 		// to update the height of the peer, we try to extract the
 		// block height from the scriptSig of the coinbase transaction.
@@ -793,10 +1034,10 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 		} else {
 			peer.PushGetBlocksMsg(locator, orphanRoot)
 		}
-	} else {
-		if peer == sm.syncPeer {
-			sm.lastProgressTime = time.Now()
-		}
+	} else if !isOrphan {
+		// Blocks are downloaded from several peers in parallel, so
+		// any connected block is progress of the sync.
+		sm.lastProgressTime = time.Now()
 
 		// When the block is not an orphan, log information about it and
 		// update the chain state.
@@ -831,12 +1072,21 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 		if err := sm.chain.FlushUtxoCache(blockchain.FlushPeriodic); err != nil {
 			log.Errorf("Error while flushing the blockchain cache: %v", err)
 		}
+
+		// Keep requesting blocks from the peer while the best header
+		// is further ahead than the blocks in flight.
+		_, lastHeight := sm.chain.BestHeader()
+		if sm.chain.BestSnapshot().Height < lastHeight &&
+			len(state.requestedBlocks) < minInFlightBlocks {
+
+			sm.fetchHeaderBlocks(peer)
+		}
 		return
 	}
 
 	// If we're on a checkpointed block, check if we still have checkpoints
 	// to let the user know if we're switching to normal mode.
-	if isCheckpointBlock {
+	if isCheckpointBlock && !isOrphan {
 		log.Infof("Continuing IBD, on checkpoint block %v(%v)",
 			bmsg.block.Hash(), bmsg.block.Height())
 		nextCheckpoint := sm.findNextHeaderCheckpoint(bmsg.block.Height())
@@ -846,20 +1096,36 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 		}
 	}
 
-	// Fetch more blocks if we're still not caught up to the best header and
-	// the number of in-flight blocks has dropped below the minimum threshold.
+	// Fetch more blocks if we're still not caught up to the best header.
+	// The block may have advanced the download window, so the blocks are
+	// requested from all peers whose number of in-flight blocks has
+	// dropped below the minimum threshold.
+	best := sm.chain.BestSnapshot()
 	_, lastHeight := sm.chain.BestHeader()
-	if bmsg.block.Height() < lastHeight &&
-		len(state.requestedBlocks) < minInFlightBlocks {
-		sm.fetchHeaderBlocks(sm.syncPeer)
+	if best.Height < lastHeight {
+		sm.fetchBlocks()
 		return
 	}
 
-	if bmsg.block.Height() >= lastHeight {
-		log.Infof("Finished the initial block download and "+
-			"caught up to block %v(%v) -- now listening to blocks.",
-			bmsg.block.Hash(), bmsg.block.Height())
-		sm.ibdMode = false
+	log.Infof("Finished the initial block download and caught up to "+
+		"block %v(%v) -- now listening to blocks.", best.Hash,
+		best.Height)
+	sm.ibdMode = false
+}
+
+// fetchBlocks requests the next blocks to be downloaded based on the current
+// list of headers from all sync candidates which have fewer than the minimum
+// number of blocks in flight.  Each peer is sent a request for the next range
+// of blocks which have not been requested yet, so the blocks are downloaded
+// from several peers in parallel.
+func (sm *SyncManager) fetchBlocks() {
+	best := sm.chain.BestSnapshot()
+	for _, peer := range sm.fetchHigherPeers(best.Height) {
+		state := sm.peerStates[peer]
+		if len(state.requestedBlocks) >= minInFlightBlocks {
+			continue
+		}
+		sm.fetchHeaderBlocks(peer)
 	}
 }
 
@@ -886,8 +1152,8 @@ func (sm *SyncManager) fetchHeaderBlocks(peer *peerpkg.Peer) {
 // blocks between the fork point and the current height on the new
 // chain are different and must also be downloaded.
 func (sm *SyncManager) buildBlockRequest(peer *peerpkg.Peer) *wire.MsgGetData {
-	// Return early if the peer is nil.
-	if peer == nil {
+	// Return early if the peer is nil or no blocks are to be downloaded.
+	if peer == nil || sm.blocksSuspended {
 		return wire.NewMsgGetDataSizeHint(0)
 	}
 
@@ -898,10 +1164,26 @@ func (sm *SyncManager) buildBlockRequest(peer *peerpkg.Peer) *wire.MsgGetData {
 		// that happens below.
 		return wire.NewMsgGetDataSizeHint(0)
 	}
-	length := bestHeaderHeight - forkHeight
+
+	// Only request blocks within the download window past the best chain
+	// block and up to the maximum number of blocks in flight per peer.
+	peerState := sm.peerStates[peer]
+	maxRequested := maxInFlightBlocksPerPeer - len(peerState.requestedBlocks)
+	if maxRequested <= 0 {
+		return wire.NewMsgGetDataSizeHint(0)
+	}
+	lastHeight := sm.chain.BestSnapshot().Height + blockDownloadWindow
+	if lastHeight > bestHeaderHeight {
+		lastHeight = bestHeaderHeight
+	}
+
+	length := lastHeight - forkHeight
+	if length < 0 {
+		length = 0
+	}
 	gdmsg := wire.NewMsgGetDataSizeHint(uint(length))
 	numRequested := 0
-	for h := forkHeight + 1; h <= bestHeaderHeight; h++ {
+	for h := forkHeight + 1; h <= lastHeight; h++ {
 		hash, err := sm.chain.HeaderHashByHeight(h)
 		if err != nil {
 			log.Warnf("error while fetching the block hash for height %v -- %v",
@@ -927,7 +1209,11 @@ func (sm *SyncManager) buildBlockRequest(peer *peerpkg.Peer) *wire.MsgGetData {
 				continue
 			}
 
-			peerState := sm.peerStates[peer]
+			// Start measuring the time until the peer delivers
+			// a block when it had none in flight.
+			if len(peerState.requestedBlocks) == 0 {
+				peerState.lastBlockTime = time.Now()
+			}
 
 			sm.requestedBlocks[*hash] = struct{}{}
 			peerState.requestedBlocks[*hash] = struct{}{}
@@ -943,7 +1229,7 @@ func (sm *SyncManager) buildBlockRequest(peer *peerpkg.Peer) *wire.MsgGetData {
 			numRequested++
 		}
 
-		if numRequested >= wire.MaxInvPerMsg {
+		if numRequested >= maxRequested {
 			break
 		}
 	}
@@ -955,7 +1241,7 @@ func (sm *SyncManager) buildBlockRequest(peer *peerpkg.Peer) *wire.MsgGetData {
 // is complete.
 func (sm *SyncManager) handleHeadersMsg(hmsg *headersMsg) {
 	peer := hmsg.peer
-	_, exists := sm.peerStates[peer]
+	state, exists := sm.peerStates[peer]
 	if !exists {
 		log.Warnf("Received headers message from unknown peer %s", peer)
 		return
@@ -968,7 +1254,53 @@ func (sm *SyncManager) handleHeadersMsg(hmsg *headersMsg) {
 		return
 	}
 
-	for _, blockHeader := range msg.Headers {
+	// Presync chains of headers which don't have enough work to be stored
+	// so a peer can't fill the block index with low-work headers.  Headers
+	// which don't connect are left to fail the header verification below.
+	headers := msg.Headers
+	full := numHeaders == wire.MaxBlockHeadersPerMsg
+	if state.headersSync == nil {
+		lowWork, err := sm.chain.IsLowWorkHeaderChain(headers)
+		if err == nil && lowWork {
+			if !full {
+				log.Debugf("Ignoring low-work chain of headers "+
+					"from peer %v", peer)
+				return
+			}
+			state.headersSync, err = sm.chain.NewHeadersSync(
+				&headers[0].PrevBlock)
+			if err != nil {
+				log.Warnf("Unable to presync headers from peer "+
+					"%v: %v", peer, err)
+				return
+			}
+			log.Infof("Presyncing low-work chain of headers from "+
+				"peer %v", peer)
+		}
+	}
+	var locator blockchain.BlockLocator
+	if state.headersSync != nil {
+		result, err := state.headersSync.ProcessHeaders(headers, full)
+		if err != nil {
+			log.Warnf("Failed to presync headers from peer %v: %v "+
+				"-- disconnecting", peer.Addr(), err)
+			state.headersSync = nil
+			peer.Disconnect()
+			return
+		}
+		headers = result.Headers
+		locator = result.Locator
+		if locator == nil {
+			// Nothing left to do when the chain of headers ended
+			// without enough work.
+			state.headersSync = nil
+			if len(headers) == 0 {
+				return
+			}
+		}
+	}
+
+	for _, blockHeader := range headers {
 		_, err := sm.chain.ProcessBlockHeader(
 			blockHeader, blockchain.BFNone, false,
 		)
@@ -983,6 +1315,16 @@ func (sm *SyncManager) handleHeadersMsg(hmsg *headersMsg) {
 		sm.progressLogger.SetLastLogTime(time.Now())
 	}
 
+	// Keep requesting headers from the peer while its chain of headers is
+	// presynced.
+	if locator != nil {
+		if peer == sm.syncPeer {
+			sm.lastProgressTime = time.Now()
+		}
+		peer.PushGetHeadersMsg(locator, &zeroHash)
+		return
+	}
+
 	bestHash, bestHeight := sm.chain.BestHeader()
 	if sm.ibdMode {
 		if sm.syncPeer == nil {
@@ -1007,6 +1349,13 @@ func (sm *SyncManager) handleHeadersMsg(hmsg *headersMsg) {
 	log.Infof("downloaded headers to %v(%v) from peer %v "+
 		"-- now fetching blocks",
 		bestHeaderHash, bestHeaderHeight, hmsg.peer.String())
+
+	// Download the blocks from all sync candidates in parallel during the
+	// initial block download.
+	if sm.ibdMode {
+		sm.fetchBlocks()
+		return
+	}
 	sm.fetchHeaderBlocks(peer)
 }
 
@@ -1039,6 +1388,72 @@ func (sm *SyncManager) handleNotFoundMsg(nfmsg *notFoundMsg) {
 			}
 		}
 	}
+
+	// Request the blocks which are repaired and which the peer doesn't
+	// have from other peers.
+	sm.requestRepairBlocks()
+}
+
+// handleRepairBlockMsg requests the block with the passed hash, whose stored
+// copy is damaged, from peers so it is repaired.
+func (sm *SyncManager) handleRepairBlockMsg(hash chainhash.Hash) {
+	if sm.repairBlock == nil {
+		log.Warnf("Unable to repair block %v: block repair is not "+
+			"supported", hash)
+		return
+	}
+
+	// Ignore blocks which are already being repaired.
+	if _, exists := sm.repairBlocks[hash]; exists {
+		return
+	}
+	sm.repairBlocks[hash] = make(map[*peerpkg.Peer]struct{})
+	sm.requestRepairBlock(hash)
+}
+
+// requestRepairBlock requests the passed block, which is being repaired, from
+// a full node peer it wasn't requested from yet.  The block stays pending when
+// there is no such peer until another peer connects.
+func (sm *SyncManager) requestRepairBlock(hash chainhash.Hash) {
+	tried := sm.repairBlocks[hash]
+	for peer, state := range sm.peerStates {
+		if _, exists := tried[peer]; exists {
+			continue
+		}
+		if !peer.Connected() || !peer.IsWitnessEnabled() ||
+			peer.Services()&wire.SFNodeNetwork != wire.SFNodeNetwork {
+
+			continue
+		}
+
+		log.Infof("Requesting block %v from %s to repair it", hash, peer)
+
+		// Start measuring the time until the peer delivers a block when
+		// it had none in flight.
+		if len(state.requestedBlocks) == 0 {
+			state.lastBlockTime = time.Now()
+		}
+		tried[peer] = struct{}{}
+		state.requestedBlocks[hash] = struct{}{}
+		sm.requestedBlocks[hash] = struct{}{}
+
+		gdmsg := wire.NewMsgGetData()
+		gdmsg.AddInvVect(wire.NewInvVect(wire.InvTypeWitnessBlock, &hash))
+		peer.QueueMessage(gdmsg, nil)
+		return
+	}
+
+	log.Debugf("No peer to request block %v from to repair it", hash)
+}
+
+// requestRepairBlocks requests the blocks which are being repaired and which
+// are not in flight from any peer.
+func (sm *SyncManager) requestRepairBlocks() {
+	for hash := range sm.repairBlocks {
+		if !sm.requestedFromOtherPeer(hash, nil) {
+			sm.requestRepairBlock(hash)
+		}
+	}
 }
 
 // haveInventory returns whether or not the inventory represented by the passed
@@ -1248,7 +1663,12 @@ func (sm *SyncManager) handleInvMsg(imsg *invMsg) {
 			fallthrough
 		case wire.InvTypeBlock:
 			// Request the block if there is not already a pending
-			// request.
+			// request.  The announced blocks are fetched along
+			// with the others once the processing of blocks is
+			// resumed when it is suspended.
+			if sm.blocksSuspended {
+				continue
+			}
 			if _, exists := sm.requestedBlocks[iv.Hash]; !exists {
 				limitAdd(sm.requestedBlocks, iv.Hash, maxRequestedBlocks)
 				limitAdd(state.requestedBlocks, iv.Hash, maxRequestedBlocks)
@@ -1291,6 +1711,24 @@ func (sm *SyncManager) handleInvMsg(imsg *invMsg) {
 	}
 }
 
+// handleSuspendBlocksMsg suspends or resumes the download and processing of
+// blocks.  The blocks which weren't downloaded while it was suspended are
+// requested once it is resumed.
+func (sm *SyncManager) handleSuspendBlocksMsg(suspend bool) {
+	if suspend == sm.blocksSuspended {
+		return
+	}
+	sm.blocksSuspended = suspend
+	if suspend {
+		log.Warnf("Suspending the download and processing of blocks")
+		return
+	}
+
+	log.Infof("Resuming the download and processing of blocks")
+	sm.lastProgressTime = time.Now()
+	sm.fetchBlocks()
+}
+
 // blockHandler is the main handler for the sync manager.  It must be run as a
 // goroutine.  It processes block and inv messages in a separate goroutine
 // from the peer handlers so the block (MsgBlock) messages are handled by a
@@ -1329,6 +1767,12 @@ out:
 			case *donePeerMsg:
 				sm.handleDonePeerMsg(msg.peer)
 
+			case repairBlockMsg:
+				sm.handleRepairBlockMsg(msg.hash)
+
+			case suspendBlocksMsg:
+				sm.handleSuspendBlocksMsg(msg.suspend)
+
 			case getSyncPeerMsg:
 				var peerID int32
 				if sm.syncPeer != nil {
@@ -1337,6 +1781,12 @@ out:
 				msg.reply <- peerID
 
 			case processBlockMsg:
+				if sm.blocksSuspended {
+					msg.reply <- processBlockResponse{
+						err: ErrBlocksSuspended,
+					}
+					continue
+				}
 				_, isOrphan, err := sm.chain.ProcessBlock(
 					msg.block, msg.flags)
 				if err != nil {
@@ -1365,15 +1815,18 @@ out:
 
 		case <-stallTicker.C:
 			sm.handleStallSample()
+			sm.handleStaleTipSample()
 
 		case <-sm.quit:
 			break out
 		}
 	}
 
-	log.Debug("Block handler shutting down: flushing blockchain caches...")
+	log.Info("Block handler shutting down: flushing blockchain caches...")
 	if err := sm.chain.FlushUtxoCache(blockchain.FlushRequired); err != nil {
 		log.Errorf("Error while flushing blockchain caches: %v", err)
+	} else {
+		log.Info("Blockchain caches flushed")
 	}
 
 	sm.wg.Done()
@@ -1427,7 +1880,7 @@ func (sm *SyncManager) handleBlockchainNotification(notification *blockchain.Not
 		// transaction are NOT removed recursively because they are still
 		// valid.
 		for _, tx := range block.Transactions()[1:] {
-			sm.txMemPool.RemoveTransaction(tx, false)
+			sm.txMemPool.RemoveMinedTransaction(tx)
 			sm.txMemPool.RemoveDoubleSpends(tx)
 			sm.txMemPool.RemoveOrphan(tx)
 			sm.peerNotifier.TransactionConfirmed(tx)
@@ -1435,6 +1888,10 @@ func (sm *SyncManager) handleBlockchainNotification(notification *blockchain.Not
 			sm.peerNotifier.AnnounceNewTransactions(acceptedTxs)
 		}
 
+		// Evict any transactions which have been in the pool for
+		// longer than allowed by the policy.
+		sm.txMemPool.ExpireTransactions()
+
 		// Register block with the fee estimator, if it exists.
 		if sm.feeEstimator != nil {
 			err := sm.feeEstimator.RegisterBlock(block)
@@ -1591,6 +2048,47 @@ func (sm *SyncManager) SyncPeerID() int32 {
 	return <-reply
 }
 
+// StaleTip returns whether no new block was connected to the best chain tip
+// for the stale tip timeout.
+//
+// This function is safe for concurrent access.
+func (sm *SyncManager) StaleTip() bool {
+	return atomic.LoadInt32(&sm.staleTip) != 0
+}
+
+// RequestBlockRepair requests the block with the passed hash from peers to
+// replace its damaged stored copy by means of the RepairBlock function of the
+// configuration.  Requesting a block which is already being repaired has no
+// effect.
+//
+// This function is safe for concurrent access.
+func (sm *SyncManager) RequestBlockRepair(hash chainhash.Hash) {
+	// Ignore if we are shutting down.
+	if atomic.LoadInt32(&sm.shutdown) != 0 {
+		return
+	}
+	select {
+	case sm.msgChan <- repairBlockMsg{hash: hash}:
+	case <-sm.quit:
+	}
+}
+
+// SuspendBlocks suspends or resumes the download and processing of blocks.
+// While it is suspended, no blocks are requested from peers, the blocks which
+// are still received are dropped and ProcessBlock returns ErrBlocksSuspended.
+//
+// This function is safe for concurrent access.
+func (sm *SyncManager) SuspendBlocks(suspend bool) {
+	// Ignore if we are shutting down.
+	if atomic.LoadInt32(&sm.shutdown) != 0 {
+		return
+	}
+	select {
+	case sm.msgChan <- suspendBlocksMsg{suspend: suspend}:
+	case <-sm.quit:
+	}
+}
+
 // ProcessBlock makes use of ProcessBlock on an internal instance of a block
 // chain.
 func (sm *SyncManager) ProcessBlock(block *btcutil.Block, flags blockchain.BehaviorFlags) (bool, error) {
@@ -1634,7 +2132,13 @@ func New(config *Config) (*SyncManager, error) {
 		msgChan:         make(chan interface{}, config.MaxPeers*3),
 		quit:            make(chan struct{}),
 		feeEstimator:    config.FeeEstimator,
+		lastTipHash:     config.Chain.BestSnapshot().Hash,
+		lastTipTime:     time.Now(),
+		repairBlock:     config.RepairBlock,
+		repairBlocks:    make(map[chainhash.Hash]map[*peerpkg.Peer]struct{}),
 	}
+	sm.staleTipTimeout = config.ChainParams.TargetTimePerBlock *
+		time.Duration(config.StaleTipFactor)
 
 	if config.DisableCheckpoints {
 		log.Info("Checkpoints are disabled")
@@ -6,8 +6,10 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math"
+	"math/big"
 	"os"
 	"path/filepath"
+	"sort"
 	"testing"
 	"time"
 
@@ -141,6 +143,7 @@ func makeMockSyncManager(t *testing.T,
 	sm, err := New(&Config{
 		PeerNotifier: noopPeerNotifier{},
 		Chain:        chain,
+		TxMemPool:    mempool.New(&mempool.Config{}),
 		ChainParams:  params,
 	})
 	require.NoError(t, err)
@@ -1281,3 +1284,331 @@ func TestIsSyncCandidateRegtest(t *testing.T) {
 		})
 	}
 }
+
+// sendTestHeaders delivers the headers of the passed blocks from the passed
+// peer to the sync manager.
+func sendTestHeaders(t *testing.T, sm *SyncManager, p *peer.Peer,
+	blocks []*btcutil.Block) {
+
+	t.Helper()
+
+	headers := wire.NewMsgHeaders()
+	for _, block := range blocks {
+		err := headers.AddBlockHeader(&block.MsgBlock().Header)
+		require.NoError(t, err)
+	}
+	sm.handleHeadersMsg(&headersMsg{headers: headers, peer: p})
+}
+
+// requestedHeights returns the sorted heights of the passed blocks which are
+// in flight from the passed peer.
+func requestedHeights(sm *SyncManager, p *peer.Peer,
+	blocks []*btcutil.Block) []int {
+
+	var heights []int
+	for i, block := range blocks {
+		_, exists := sm.peerStates[p].requestedBlocks[*block.Hash()]
+		if exists {
+			heights = append(heights, i+1)
+		}
+	}
+	return heights
+}
+
+// TestParallelBlockDownload verifies that the blocks are requested from all
+// sync candidates in disjoint contiguous ranges and that the initial block
+// download completes when the ranges are delivered out of order.
+func TestParallelBlockDownload(t *testing.T) {
+	t.Parallel()
+
+	params := chaincfg.RegressionNetParams
+	params.Checkpoints = nil
+
+	sm, tearDown := makeMockSyncManager(t, &params)
+	defer tearDown()
+
+	const totalBlocks = 2*maxInFlightBlocksPerPeer + 10
+	blocks := generateTestBlocks(t, &params, totalBlocks)
+
+	peers := []*peer.Peer{startIBD(t, sm, totalBlocks)}
+	for i := 0; i < 2; i++ {
+		peers = append(peers, newSyncCandidate(t, sm, totalBlocks))
+	}
+	sendTestHeaders(t, sm, peers[0], blocks)
+
+	// Every block is requested from exactly one peer and every peer is
+	// requested a contiguous range of blocks.
+	ranges := make([][]int, 0, len(peers))
+	var numRequested int
+	for _, p := range peers {
+		heights := requestedHeights(sm, p, blocks)
+		require.NotEmpty(t, heights)
+		require.LessOrEqual(t, len(heights), maxInFlightBlocksPerPeer)
+		require.Equal(t, len(heights)-1,
+			heights[len(heights)-1]-heights[0],
+			"blocks requested from a peer are not contiguous")
+		ranges = append(ranges, heights)
+		numRequested += len(heights)
+	}
+	require.Equal(t, totalBlocks, numRequested)
+	require.Len(t, sm.requestedBlocks, totalBlocks)
+
+	// Deliver the ranges starting with the highest one so the blocks
+	// arrive out of order.
+	order := []int{0, 1, 2}
+	sort.Slice(order, func(i, j int) bool {
+		return ranges[order[i]][0] > ranges[order[j]][0]
+	})
+	for _, i := range order {
+		for _, height := range ranges[i] {
+			require.True(t, sm.ibdMode)
+			sm.handleBlockMsg(&blockMsg{
+				block: blocks[height-1],
+				peer:  peers[i],
+				reply: make(chan struct{}, 1),
+			})
+		}
+	}
+
+	for _, p := range peers {
+		assertIBDComplete(t, sm, sm.peerStates[p], totalBlocks)
+	}
+}
+
+// TestBlockDownloadWindow verifies that blocks further than the download
+// window past the best chain block are not requested.
+func TestBlockDownloadWindow(t *testing.T) {
+	t.Parallel()
+
+	params := chaincfg.RegressionNetParams
+	params.Checkpoints = nil
+
+	sm, tearDown := makeMockSyncManager(t, &params)
+	defer tearDown()
+
+	const totalBlocks = blockDownloadWindow + 10
+	blocks := generateTestBlocks(t, &params, totalBlocks)
+
+	peers := []*peer.Peer{startIBD(t, sm, totalBlocks)}
+	for len(peers) < totalBlocks/maxInFlightBlocksPerPeer+1 {
+		peers = append(peers, newSyncCandidate(t, sm, totalBlocks))
+	}
+	sendTestHeaders(t, sm, peers[0], blocks)
+
+	require.Len(t, sm.requestedBlocks, blockDownloadWindow)
+	for _, block := range blocks[blockDownloadWindow:] {
+		require.NotContains(t, sm.requestedBlocks, *block.Hash())
+	}
+}
+
+// TestStalledBlockPeer verifies that a peer which does not deliver any of the
+// blocks requested from it is disconnected and that its blocks are requested
+// from the remaining peers.
+func TestStalledBlockPeer(t *testing.T) {
+	t.Parallel()
+
+	params := chaincfg.RegressionNetParams
+	params.Checkpoints = nil
+
+	sm, tearDown := makeMockSyncManager(t, &params)
+	defer tearDown()
+
+	const totalBlocks = maxInFlightBlocksPerPeer + 8
+	blocks := generateTestBlocks(t, &params, totalBlocks)
+
+	// The sync peer is requested the first range of blocks and the peer
+	// connecting afterwards the remaining blocks.
+	stalledPeer := startIBD(t, sm, totalBlocks)
+	sendTestHeaders(t, sm, stalledPeer, blocks)
+	otherPeer := newSyncCandidate(t, sm, totalBlocks)
+	sm.fetchBlocks()
+
+	stalledRequested := requestedHeights(sm, stalledPeer, blocks)
+	require.Len(t, stalledRequested, maxInFlightBlocksPerPeer)
+	require.Len(t, requestedHeights(sm, otherPeer, blocks), 8)
+
+	// Peers which recently received a request are not stalled.
+	sm.handleStallSample()
+	require.True(t, sm.syncPeer == stalledPeer)
+
+	sm.peerStates[stalledPeer].lastBlockTime = time.Now().Add(
+		-(maxBlockStallDuration + time.Minute))
+	sm.handleStallSample()
+
+	disconnected := make(chan struct{})
+	go func() {
+		stalledPeer.WaitForDisconnect()
+		close(disconnected)
+	}()
+	select {
+	case <-disconnected:
+	case <-time.After(time.Second):
+		t.Fatal("Disconnect() was not called on stalled peer")
+	}
+
+	// The other peer takes over as sync peer and is requested the blocks
+	// of the stalled peer up to its maximum number of blocks in flight.
+	require.True(t, sm.syncPeer == otherPeer)
+	require.Empty(t, sm.peerStates[stalledPeer].requestedBlocks)
+	require.Equal(t, stalledRequested[:maxInFlightBlocksPerPeer-8],
+		requestedHeights(sm, otherPeer, blocks)[:maxInFlightBlocksPerPeer-8])
+	require.Len(t, sm.requestedBlocks, maxInFlightBlocksPerPeer)
+
+	sm.handleDonePeerMsg(stalledPeer)
+
+	// The other peer delivers all blocks.
+	for len(sm.peerStates[otherPeer].requestedBlocks) > 0 {
+		heights := requestedHeights(sm, otherPeer, blocks)
+		sm.handleBlockMsg(&blockMsg{
+			block: blocks[heights[0]-1],
+			peer:  otherPeer,
+			reply: make(chan struct{}, 1),
+		})
+	}
+	assertIBDComplete(t, sm, sm.peerStates[otherPeer], totalBlocks)
+}
+
+// TestSuspendBlocks verifies that no blocks are requested or processed while
+// the processing of blocks is suspended and that the blocks which were dropped
+// are requested again once it is resumed.
+func TestSuspendBlocks(t *testing.T) {
+	t.Parallel()
+
+	params := chaincfg.RegressionNetParams
+	params.Checkpoints = nil
+
+	sm, tearDown := makeMockSyncManager(t, &params)
+	defer tearDown()
+
+	const totalBlocks = 8
+	blocks := generateTestBlocks(t, &params, totalBlocks)
+
+	syncPeer := startIBD(t, sm, totalBlocks)
+	sendTestHeaders(t, sm, syncPeer, blocks)
+	require.Len(t, sm.requestedBlocks, totalBlocks)
+
+	// The blocks in flight are dropped when they arrive after the
+	// processing of blocks was suspended and no blocks are requested.
+	sm.handleSuspendBlocksMsg(true)
+	for _, block := range blocks {
+		sm.handleBlockMsg(&blockMsg{
+			block: block,
+			peer:  syncPeer,
+			reply: make(chan struct{}, 1),
+		})
+	}
+	require.Equal(t, int32(0), sm.chain.BestSnapshot().Height)
+	sm.fetchBlocks()
+	require.Empty(t, sm.requestedBlocks)
+
+	// The blocks are requested again once it is resumed.
+	sm.handleSuspendBlocksMsg(false)
+	require.Len(t, sm.requestedBlocks, totalBlocks)
+}
+
+// TestLowWorkHeaders verifies that chains of headers with less than the
+// minimum chain work are not stored until they were presynced and downloaded
+// again.
+func TestLowWorkHeaders(t *testing.T) {
+	t.Parallel()
+
+	params := chaincfg.RegressionNetParams
+	params.Checkpoints = nil
+
+	const totalBlocks = wire.MaxBlockHeadersPerMsg + 10
+	db, tearDown, err := dbSetup(t, &params)
+	require.NoError(t, err)
+	defer tearDown()
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:          db,
+		ChainParams: &params,
+		TimeSource:  blockchain.NewMedianTime(),
+		SigCache:    txscript.NewSigCache(1000),
+		MinimumChainWork: new(big.Int).Mul(
+			blockchain.CalcWork(params.PowLimitBits),
+			big.NewInt(totalBlocks+1)),
+	})
+	require.NoError(t, err)
+	sm, err := New(&Config{
+		PeerNotifier: noopPeerNotifier{},
+		Chain:        chain,
+		TxMemPool:    mempool.New(&mempool.Config{}),
+		ChainParams:  &params,
+	})
+	require.NoError(t, err)
+
+	blocks := generateTestBlocks(t, &params, totalBlocks)
+	syncPeer := startIBD(t, sm, totalBlocks)
+	state := sm.peerStates[syncPeer]
+
+	// A low-work chain of headers which ends in the same message is
+	// ignored.
+	sendTestHeaders(t, sm, syncPeer, blocks[:10])
+	require.Nil(t, state.headersSync)
+	_, bestHeight := chain.BestHeader()
+	require.Zero(t, bestHeight)
+
+	// The headers are presynced and then downloaded again, and are only
+	// stored once the redownloaded chain reached the minimum chain work.
+	full := wire.MaxBlockHeadersPerMsg
+	for i := 0; i < 2; i++ {
+		sendTestHeaders(t, sm, syncPeer, blocks[:full])
+		require.NotNil(t, state.headersSync)
+		sendTestHeaders(t, sm, syncPeer, blocks[full:])
+		_, bestHeight = chain.BestHeader()
+		if i == 0 {
+			require.Zero(t, bestHeight)
+			require.Empty(t, sm.requestedBlocks)
+		}
+	}
+	require.Nil(t, state.headersSync)
+	require.Equal(t, int32(totalBlocks), bestHeight)
+	require.NotEmpty(t, sm.requestedBlocks)
+}
+
+// TestStaleTip verifies that a best chain tip to which no new block was
+// connected for the stale tip timeout is reported as stale, that the sync peer
+// is replaced and that the tip is no longer stale once a new block connects.
+func TestStaleTip(t *testing.T) {
+	t.Parallel()
+
+	params := chaincfg.RegressionNetParams
+	params.Checkpoints = nil
+
+	sm, tearDown := makeMockSyncManager(t, &params)
+	defer tearDown()
+
+	// The detection is disabled by default.
+	sm.lastTipTime = time.Now().Add(-24 * time.Hour)
+	sm.handleStaleTipSample()
+	require.False(t, sm.StaleTip())
+
+	sm.staleTipTimeout = 3 * params.TargetTimePerBlock
+	syncPeer := newSyncCandidate(t, sm, 0)
+	newSyncCandidate(t, sm, 0)
+	sm.syncPeer = syncPeer
+
+	sm.lastTipTime = time.Now()
+	sm.handleStaleTipSample()
+	require.False(t, sm.StaleTip())
+	require.True(t, sm.syncPeer == syncPeer)
+
+	sm.lastTipTime = time.Now().Add(-(sm.staleTipTimeout + time.Minute))
+	sm.handleStaleTipSample()
+	require.True(t, sm.StaleTip())
+	require.False(t, sm.syncPeer == syncPeer,
+		"the sync peer should be replaced")
+	lastCheck := sm.lastStaleTipCheck
+	require.False(t, lastCheck.IsZero())
+
+	// Headers are only requested again after another stale tip timeout.
+	sm.handleStaleTipSample()
+	require.Equal(t, lastCheck, sm.lastStaleTipCheck)
+
+	blocks := generateTestBlocks(t, &params, 1)
+	_, _, err := sm.chain.ProcessBlock(blocks[0], blockchain.BFNone)
+	require.NoError(t, err)
+	sm.handleStaleTipSample()
+	require.False(t, sm.StaleTip())
+	require.Equal(t, *blocks[0].Hash(), sm.lastTipHash)
+}
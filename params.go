@@ -18,6 +18,11 @@ var activeNetParams = &mainNetParams
 type params struct {
 	*chaincfg.Params
 	rpcPort string
+
+	// assumeValid is the hash of the block whose ancestors are assumed to
+	// have valid scripts by default.  It is updated with every release and
+	// is empty for networks which verify all scripts by default.
+	assumeValid string
 }
 
 // mainNetParams contains parameters specific to the main network
@@ -29,6 +34,9 @@ type params struct {
 var mainNetParams = params{
 	Params:  &chaincfg.MainNetParams,
 	rpcPort: "8334",
+
+	// Block 840000.
+	assumeValid: "0000000000000000000320283a032748cef8227873ff4872689bf23f1cda83a5",
 }
 
 // regressionNetParams contains parameters specific to the regression test
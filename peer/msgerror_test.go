@@ -0,0 +1,74 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package peer
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/btcsuite/btcd/wire/v2"
+)
+
+// TestHandleMessageError ensures the errors of reading messages are classified
+// and counted by kind, and that the listener is only notified of the errors
+// which are not allowed in regression test mode.
+func TestHandleMessageError(t *testing.T) {
+	t.Parallel()
+
+	var notified []MessageErrorKind
+	p := &Peer{
+		cfg: Config{
+			Listeners: MessageListeners{
+				OnMessageError: func(_ *Peer, kind MessageErrorKind,
+					_ error) {
+
+					notified = append(notified, kind)
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		err     error
+		allowed bool
+		kind    MessageErrorKind
+	}{
+		{wire.ErrUnknownMessage, false, MsgErrUnknown},
+		{fmt.Errorf("read: %w", wire.ErrUnknownMessage), false,
+			MsgErrUnknown},
+		{&wire.MessageError{Func: "ReadMessage", Description: "message " +
+			"payload is too large - header indicates 40000000 bytes"},
+			false, MsgErrOversized},
+		{&wire.MessageError{Func: "ReadMessage", Description: "payload " +
+			"exceeds max length - header indicates 2000 bytes"},
+			false, MsgErrOversized},
+		{&wire.MessageError{Func: "ReadMessage", Description: "payload " +
+			"checksum failed"}, false, MsgErrMalformed},
+		{errors.New("unexpected decode error"), false, MsgErrMalformed},
+		{&wire.MessageError{Func: "ReadMessage", Description: "payload " +
+			"checksum failed"}, true, MsgErrMalformed},
+	}
+	for i, test := range tests {
+		if kind := messageErrorKind(test.err); kind != test.kind {
+			t.Errorf("messageErrorKind #%d: got %v, want %v", i, kind,
+				test.kind)
+		}
+		p.handleMessageError(test.err, test.allowed)
+	}
+
+	stats := p.StatsSnapshot()
+	if stats.UnknownMsgs != 2 || stats.OversizedMsgs != 2 ||
+		stats.MalformedMsgs != 3 {
+
+		t.Fatalf("StatsSnapshot: got (%d, %d, %d) unknown, oversized "+
+			"and malformed messages, want (2, 2, 3)", stats.UnknownMsgs,
+			stats.OversizedMsgs, stats.MalformedMsgs)
+	}
+	if len(notified) != len(tests)-1 {
+		t.Fatalf("OnMessageError: got %d notifications, want %d",
+			len(notified), len(tests)-1)
+	}
+}
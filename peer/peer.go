@@ -15,6 +15,7 @@ import (
 	"net"
 	"runtime/debug"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -37,6 +38,10 @@ const (
 	// inv message to a peer.
 	DefaultTrickleInterval = 10 * time.Second
 
+	// DefaultMaxInvTrickleSize is the default maximum amount of inventory
+	// to send in a single message when trickling inventory to remote peers.
+	DefaultMaxInvTrickleSize = 1000
+
 	// MinAcceptableProtocolVersion is the lowest protocol version that a
 	// connected peer may support.
 	MinAcceptableProtocolVersion = wire.MultipleAddressVersion
@@ -44,10 +49,6 @@ const (
 	// outputBufferSize is the number of elements the output channels use.
 	outputBufferSize = 50
 
-	// invTrickleSize is the maximum amount of inventory to send in a single
-	// message when trickling inventory to remote peers.
-	maxInvTrickleSize = 1000
-
 	// maxKnownInventory is the maximum number of items to keep in the known
 	// inventory cache.
 	maxKnownInventory = 1000
@@ -216,6 +217,12 @@ type MessageListeners struct {
 	// not an error in the write occurred.  This can be useful for
 	// circumstances such as keeping track of server-wide byte counts.
 	OnWrite func(p *Peer, bytesWritten int, msg wire.Message, err error)
+
+	// OnMessageError is invoked when a message which is unknown, oversized
+	// or malformed is received from a peer.  The peer is disconnected
+	// after oversized and malformed messages regardless.  It is not
+	// invoked for the errors which are allowed in regression test mode.
+	OnMessageError func(p *Peer, kind MessageErrorKind, err error)
 }
 
 // Config is the struct to hold configuration options useful to Peer.
@@ -277,6 +284,24 @@ type Config struct {
 	// inventory to a peer.
 	TrickleInterval time.Duration
 
+	// TrickleIntervalFunc, when set, returns the trickle interval to use
+	// from the next trickle on, which allows the interval to be adapted to
+	// conditions such as the number of connected peers.  TrickleInterval
+	// is used when it returns a non-positive value.
+	TrickleIntervalFunc func() time.Duration
+
+	// TxAnnounceDelay is the minimum time inventory is held in the send
+	// queue before it is trickled to the peer.  Inventory the peer learns
+	// about meanwhile, for example because it announced the inventory
+	// itself, is not announced to it again.
+	TxAnnounceDelay time.Duration
+
+	// MaxInvTrickleSize is the maximum amount of inventory to send in a
+	// single message when trickling inventory to the peer.  Non-positive
+	// values select DefaultMaxInvTrickleSize and values above
+	// wire.MaxInvPerMsg are limited to it.
+	MaxInvTrickleSize int
+
 	// AllowSelfConns is only used to allow the tests to bypass the self
 	// connection detecting and disconnect logic since they intentionally
 	// do so for testing purposes.
@@ -397,6 +422,67 @@ type StatsSnap struct {
 	LastPingTime   time.Time
 	LastPingMicros int64
 	V2Connection   bool
+
+	// UnknownMsgs, OversizedMsgs and MalformedMsgs are the number of
+	// messages of each kind of message error received from the peer.
+	UnknownMsgs   uint64
+	OversizedMsgs uint64
+	MalformedMsgs uint64
+}
+
+// MessageErrorKind classifies the messages received from a peer which could
+// not be read.
+type MessageErrorKind uint8
+
+const (
+	// MsgErrUnknown indicates a message with an unknown command.  Unknown
+	// messages are ignored.
+	MsgErrUnknown MessageErrorKind = iota
+
+	// MsgErrOversized indicates a message with a payload which exceeds the
+	// maximum size of the protocol or of its type.
+	MsgErrOversized
+
+	// MsgErrMalformed indicates a message which could not be decoded, such
+	// as one with an invalid checksum or payload.
+	MsgErrMalformed
+)
+
+// msgErrorKindStrings maps the message error kinds to human-readable names.
+var msgErrorKindStrings = map[MessageErrorKind]string{
+	MsgErrUnknown:   "unknown",
+	MsgErrOversized: "oversized",
+	MsgErrMalformed: "malformed",
+}
+
+// String returns the MessageErrorKind in human-readable form.
+func (k MessageErrorKind) String() string {
+	if s, ok := msgErrorKindStrings[k]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown MessageErrorKind (%d)", uint8(k))
+}
+
+// messageErrorKind returns the kind of message error the passed error of
+// reading a message describes.
+func messageErrorKind(err error) MessageErrorKind {
+	if errors.Is(err, wire.ErrUnknownMessage) {
+		return MsgErrUnknown
+	}
+
+	// The wire package only describes the size limits which were exceeded
+	// in the description of the error.
+	var msgErr *wire.MessageError
+	if !errors.As(err, &msgErr) {
+		return MsgErrMalformed
+	}
+	desc := msgErr.Description
+	if strings.HasPrefix(desc, "message payload is too large") ||
+		strings.HasPrefix(desc, "payload exceeds max length") {
+
+		return MsgErrOversized
+	}
+	return MsgErrMalformed
 }
 
 // HashFunc is a function which returns a block hash, height and error
@@ -441,6 +527,9 @@ type Peer struct {
 	// The following variables must only be used atomically.
 	bytesReceived uint64
 	bytesSent     uint64
+	unknownMsgs   uint64
+	oversizedMsgs uint64
+	malformedMsgs uint64
 	lastRecv      int64
 	lastSend      int64
 	connected     int32
@@ -579,6 +668,9 @@ func (p *Peer) StatsSnapshot() *StatsSnap {
 		LastPingMicros: p.lastPingMicros,
 		LastPingTime:   p.lastPingTime,
 		V2Connection:   p.cfg.UsingV2Conn,
+		UnknownMsgs:    atomic.LoadUint64(&p.unknownMsgs),
+		OversizedMsgs:  atomic.LoadUint64(&p.oversizedMsgs),
+		MalformedMsgs:  atomic.LoadUint64(&p.malformedMsgs),
 	}
 
 	p.statsMtx.RUnlock()
@@ -1187,6 +1279,24 @@ func (p *Peer) writeMessage(msg wire.Message, enc wire.MessageEncoding) error {
 	return err
 }
 
+// handleMessageError counts the unknown, oversized or malformed message the
+// passed error of reading a message describes and notifies the listener of it
+// unless it is allowed in regression test mode.
+func (p *Peer) handleMessageError(err error, allowed bool) {
+	kind := messageErrorKind(err)
+	switch kind {
+	case MsgErrUnknown:
+		atomic.AddUint64(&p.unknownMsgs, 1)
+	case MsgErrOversized:
+		atomic.AddUint64(&p.oversizedMsgs, 1)
+	default:
+		atomic.AddUint64(&p.malformedMsgs, 1)
+	}
+	if !allowed && p.cfg.Listeners.OnMessageError != nil {
+		p.cfg.Listeners.OnMessageError(p, kind, err)
+	}
+}
+
 // isAllowedReadError returns whether or not the passed error is allowed without
 // disconnecting the peer.  In particular, regression tests need to be allowed
 // to send malformed messages without the peer being disconnected.
@@ -1464,6 +1574,7 @@ out:
 			// error is one of the allowed errors.
 			if p.isAllowedReadError(err) {
 				log.Errorf("Allowed test error from %s: %v", p, err)
+				p.handleMessageError(err, true)
 				idleTimer.Reset(idleTimeout)
 				continue
 			}
@@ -1477,6 +1588,7 @@ out:
 			if err == wire.ErrUnknownMessage {
 				log.Debugf("Received unknown message from %s:"+
 					" %v", p, err)
+				p.handleMessageError(err, false)
 				idleTimer.Reset(idleTimeout)
 				continue
 			}
@@ -1488,6 +1600,7 @@ out:
 				errMsg := fmt.Sprintf("Can't read message from %s: %v", p, err)
 				if err != io.ErrUnexpectedEOF {
 					log.Errorf(errMsg)
+					p.handleMessageError(err, false)
 				}
 
 				// Push a reject message for the malformed message and wait for
@@ -1674,6 +1787,23 @@ out:
 	}
 }
 
+// queuedInv is inventory in the inventory send queue along with the time it
+// was queued at.
+type queuedInv struct {
+	iv     *wire.InvVect
+	queued time.Time
+}
+
+// trickleInterval returns the interval to trickle inventory to the peer at.
+func (p *Peer) trickleInterval() time.Duration {
+	if p.cfg.TrickleIntervalFunc != nil {
+		if interval := p.cfg.TrickleIntervalFunc(); interval > 0 {
+			return interval
+		}
+	}
+	return p.cfg.TrickleInterval
+}
+
 // queueHandler handles the queuing of outgoing data for the peer. This runs as
 // a muxer for various sources of input so we can ensure that server and peer
 // handlers will not block on us sending a message.  That data is then passed on
@@ -1681,7 +1811,8 @@ out:
 func (p *Peer) queueHandler() {
 	pendingMsgs := list.New()
 	invSendQueue := list.New()
-	trickleTicker := time.NewTicker(p.cfg.TrickleInterval)
+	interval := p.trickleInterval()
+	trickleTicker := time.NewTicker(interval)
 	defer trickleTicker.Stop()
 
 	// We keep the waiting flag so that we know if we have a message queued
@@ -1739,11 +1870,20 @@ out:
 					waiting = queuePacket(outMsg{msg: invMsg},
 						pendingMsgs, waiting)
 				} else {
-					invSendQueue.PushBack(iv)
+					invSendQueue.PushBack(&queuedInv{
+						iv:     iv,
+						queued: time.Now(),
+					})
 				}
 			}
 
 		case <-trickleTicker.C:
+			// Adapt the ticker to changes of the trickle interval.
+			if newInterval := p.trickleInterval(); newInterval != interval {
+				interval = newInterval
+				trickleTicker.Reset(interval)
+			}
+
 			// Don't send anything if we're disconnecting or there
 			// is no queued inventory.
 			// version is known if send queue has any entries.
@@ -1753,10 +1893,20 @@ out:
 			}
 
 			// Create and send as many inv messages as needed to
-			// drain the inventory send queue.
+			// drain the inventory send queue of the inventory which
+			// was queued for at least the announcement delay.  The
+			// queue is ordered by the time the inventory was
+			// queued, so all inventory after the first which is
+			// held back is held back as well.
+			now := time.Now()
 			invMsg := wire.NewMsgInvSizeHint(uint(invSendQueue.Len()))
 			for e := invSendQueue.Front(); e != nil; e = invSendQueue.Front() {
-				iv := invSendQueue.Remove(e).(*wire.InvVect)
+				qi := e.Value.(*queuedInv)
+				if now.Sub(qi.queued) < p.cfg.TxAnnounceDelay {
+					break
+				}
+				invSendQueue.Remove(e)
+				iv := qi.iv
 
 				// Don't send inventory that became known after
 				// the initial check.
@@ -1765,7 +1915,7 @@ out:
 				}
 
 				invMsg.AddInvVect(iv)
-				if len(invMsg.InvList) >= maxInvTrickleSize {
+				if len(invMsg.InvList) >= p.cfg.MaxInvTrickleSize {
 					waiting = queuePacket(
 						outMsg{msg: invMsg},
 						pendingMsgs, waiting)
@@ -2534,6 +2684,15 @@ func newPeerBase(origCfg *Config, inbound bool) *Peer {
 		cfg.TrickleInterval = DefaultTrickleInterval
 	}
 
+	// Set the max inventory per trickled message if a non-positive value
+	// is specified and limit it to the max allowed by the protocol.
+	if cfg.MaxInvTrickleSize <= 0 {
+		cfg.MaxInvTrickleSize = DefaultMaxInvTrickleSize
+	}
+	if cfg.MaxInvTrickleSize > wire.MaxInvPerMsg {
+		cfg.MaxInvTrickleSize = wire.MaxInvPerMsg
+	}
+
 	p := Peer{
 		inbound:         inbound,
 		wireEncoding:    wire.BaseEncoding,
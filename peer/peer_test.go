@@ -926,6 +926,78 @@ func TestDuplicateVersionMsg(t *testing.T) {
 	}
 }
 
+// TestTxAnnounceDelay ensures queued inventory is held back for the
+// announcement delay before it is trickled to the peer, and that inventory the
+// peer learns about meanwhile is not announced to it.
+func TestTxAnnounceDelay(t *testing.T) {
+	const announceDelay = time.Millisecond * 300
+
+	verack := make(chan struct{})
+	invs := make(chan *wire.MsgInv, 1)
+	peerCfg := peer.Config{
+		Listeners: peer.MessageListeners{
+			OnVerAck: func(p *peer.Peer, msg *wire.MsgVerAck) {
+				verack <- struct{}{}
+			},
+			OnInv: func(p *peer.Peer, msg *wire.MsgInv) {
+				invs <- msg
+			},
+		},
+		UserAgentName:    "peer",
+		UserAgentVersion: "1.0",
+		ChainParams:      &chaincfg.MainNetParams,
+		Services:         0,
+		AllowSelfConns:   true,
+		TrickleInterval:  time.Millisecond * 10,
+	}
+	localPeerCfg := peerCfg
+	localPeerCfg.TxAnnounceDelay = announceDelay
+	localPeer, err := peer.NewOutboundPeer(&localPeerCfg, "10.0.0.2:8333")
+	if err != nil {
+		t.Fatalf("NewOutboundPeer: unexpected err: %v\n", err)
+	}
+	inPeer := peer.NewInboundPeer(&peerCfg)
+
+	err = setupPeerConnection(inPeer, localPeer)
+	if err != nil {
+		t.Fatalf("setupPeerConnection failed to connect: %v\n", err)
+	}
+
+	// Wait for the veracks from the initial protocol version negotiation.
+	for i := 0; i < 2; i++ {
+		select {
+		case <-verack:
+		case <-time.After(time.Second):
+			t.Fatal("verack timeout")
+		}
+	}
+
+	// Queue two transactions and mark the second one as known to the
+	// remote peer before the announcement delay elapsed.
+	queued := time.Now()
+	iv1 := wire.NewInvVect(wire.InvTypeTx, &chainhash.Hash{0x01})
+	iv2 := wire.NewInvVect(wire.InvTypeTx, &chainhash.Hash{0x02})
+	localPeer.QueueInventory(iv1)
+	localPeer.QueueInventory(iv2)
+	localPeer.AddKnownInventory(iv2)
+
+	select {
+	case msg := <-invs:
+		if elapsed := time.Since(queued); elapsed < announceDelay {
+			t.Fatalf("inventory announced after %v, want at least %v",
+				elapsed, announceDelay)
+		}
+		if len(msg.InvList) != 1 || *msg.InvList[0] != *iv1 {
+			t.Fatalf("unexpected announced inventory %v", msg.InvList)
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("inventory announcement timeout")
+	}
+
+	localPeer.Disconnect()
+	inPeer.Disconnect()
+}
+
 // TestUpdateLastBlockHeight ensures the last block height is set properly
 // during the initial version negotiation and is only allowed to advance to
 // higher values via the associated update function.
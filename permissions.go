@@ -0,0 +1,171 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// peerPermissions is a bitmask of the permissions granted to a peer by the
+// --whitelist and --whitebind options.
+type peerPermissions uint32
+
+const (
+	// permNoBan prevents the peer from being banned or disconnected for
+	// misbehaving.
+	permNoBan peerPermissions = 1 << iota
+
+	// permForceRelay relays the transactions of the peer even when they
+	// are already in the memory pool.  It implies permRelay.
+	permForceRelay
+
+	// permRelay accepts and relays the transactions of the peer even when
+	// --blocksonly is set and exempts the peer from the transaction relay
+	// rate limit.
+	permRelay
+
+	// permMempool allows the peer to request the contents of the memory
+	// pool even when bloom filters are disabled.
+	permMempool
+
+	// permBloomFilter allows the peer to use bloom filters even when
+	// they are disabled.
+	permBloomFilter
+)
+
+// implicitPermissions are the permissions granted to peers matching a
+// --whitelist or --whitebind option which does not list any.
+const implicitPermissions = permNoBan | permRelay | permMempool
+
+// peerPermissionNames maps the permissions to the names used for them in the
+// options and the RPC server in the order they are listed in.
+var peerPermissionNames = []struct {
+	perm peerPermissions
+	name string
+}{
+	{permNoBan, "noban"},
+	{permForceRelay, "forcerelay"},
+	{permRelay, "relay"},
+	{permMempool, "mempool"},
+	{permBloomFilter, "bloomfilter"},
+}
+
+// has returns whether all of the passed permissions are granted.
+func (p peerPermissions) has(perm peerPermissions) bool {
+	return p&perm == perm
+}
+
+// names returns the names of the granted permissions.
+func (p peerPermissions) names() []string {
+	names := make([]string, 0, len(peerPermissionNames))
+	for _, entry := range peerPermissionNames {
+		if p.has(entry.perm) {
+			names = append(names, entry.name)
+		}
+	}
+	return names
+}
+
+// parsePermissions splits the passed option value of the form
+// [permission,...@]address into the permissions and the address.  The implicit
+// permissions are returned when none are listed.
+func parsePermissions(value string) (peerPermissions, string, error) {
+	names, addr, found := strings.Cut(value, "@")
+	if !found {
+		return implicitPermissions, value, nil
+	}
+
+	var perms peerPermissions
+	for _, name := range strings.Split(names, ",") {
+		var known bool
+		for _, entry := range peerPermissionNames {
+			if entry.name == name {
+				perms |= entry.perm
+				known = true
+				break
+			}
+		}
+		if !known {
+			return 0, "", fmt.Errorf("unknown permission %q", name)
+		}
+	}
+	if perms.has(permForceRelay) {
+		perms |= permRelay
+	}
+	return perms, addr, nil
+}
+
+// whitelist houses an IP network along with the permissions granted to the
+// peers in it.
+type whitelist struct {
+	ipnet       *net.IPNet
+	permissions peerPermissions
+}
+
+// whitebind houses a listen address along with the permissions granted to the
+// inbound peers which connect to it.
+type whitebind struct {
+	addr        string
+	permissions peerPermissions
+}
+
+// whitelistPermissions returns the permissions granted to the peer with the
+// passed address by the whitelisted networks and IPs.
+func whitelistPermissions(addr net.Addr) peerPermissions {
+	if len(cfg.whitelists) == 0 {
+		return 0
+	}
+
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		srvrLog.Warnf("Unable to SplitHostPort on '%s': %v", addr, err)
+		return 0
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		srvrLog.Warnf("Unable to parse IP '%s'", addr)
+		return 0
+	}
+
+	var perms peerPermissions
+	for _, wl := range cfg.whitelists {
+		if wl.ipnet.Contains(ip) {
+			perms |= wl.permissions
+		}
+	}
+	return perms
+}
+
+// whitebindPermissions returns the permissions granted to inbound peers which
+// connected to the passed local address by the whitebind listeners.  Listeners
+// bound to all interfaces match any local address with the same port.
+func whitebindPermissions(localAddr net.Addr) peerPermissions {
+	if len(cfg.whitebinds) == 0 {
+		return 0
+	}
+
+	host, port, err := net.SplitHostPort(localAddr.String())
+	if err != nil {
+		return 0
+	}
+	ip := net.ParseIP(host)
+
+	var perms peerPermissions
+	for _, wb := range cfg.whitebinds {
+		bindHost, bindPort, err := net.SplitHostPort(wb.addr)
+		if err != nil || bindPort != port {
+			continue
+		}
+		bindIP := net.ParseIP(bindHost)
+		if bindHost == "" || (bindIP != nil && bindIP.IsUnspecified()) ||
+			(bindIP != nil && bindIP.Equal(ip)) {
+
+			perms |= wb.permissions
+		}
+	}
+	return perms
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+// TestParsePermissions ensures whitelist and whitebind option values are split
+// into the expected permissions and addresses.
+func TestParsePermissions(t *testing.T) {
+	tests := []struct {
+		value string
+		perms peerPermissions
+		addr  string
+		err   bool
+	}{
+		{"192.168.0.0/24", implicitPermissions, "192.168.0.0/24", false},
+		{"noban@::1", permNoBan, "::1", false},
+		{"bloomfilter,mempool@127.0.0.1:8333",
+			permBloomFilter | permMempool, "127.0.0.1:8333", false},
+		{"forcerelay@10.0.0.1", permForceRelay | permRelay, "10.0.0.1",
+			false},
+		{"@10.0.0.1", 0, "", true},
+		{"noban,download@10.0.0.1", 0, "", true},
+	}
+
+	for _, test := range tests {
+		perms, addr, err := parsePermissions(test.value)
+		if (err != nil) != test.err {
+			t.Errorf("parsePermissions(%q): unexpected error %v",
+				test.value, err)
+			continue
+		}
+		if perms != test.perms || addr != test.addr {
+			t.Errorf("parsePermissions(%q): got (%v, %q), want (%v, %q)",
+				test.value, perms.names(), addr, test.perms.names(),
+				test.addr)
+		}
+	}
+
+	want := []string{"noban", "relay", "mempool"}
+	if names := implicitPermissions.names(); !reflect.DeepEqual(names, want) {
+		t.Errorf("names: got %v, want %v", names, want)
+	}
+}
+
+// TestPeerPermissions ensures peers are granted the permissions of all
+// whitelists and whitebind listeners they match.
+func TestPeerPermissions(t *testing.T) {
+	origCfg := cfg
+	defer func() { cfg = origCfg }()
+
+	_, ipnet, _ := net.ParseCIDR("10.0.0.0/8")
+	_, ipnet2, _ := net.ParseCIDR("10.1.0.0/16")
+	cfg = &config{
+		whitelists: []whitelist{
+			{ipnet: ipnet, permissions: permNoBan},
+			{ipnet: ipnet2, permissions: permRelay},
+		},
+		whitebinds: []whitebind{
+			{addr: "127.0.0.1:8334", permissions: permMempool},
+			{addr: ":8335", permissions: permBloomFilter},
+		},
+	}
+
+	remoteTests := []struct {
+		addr  string
+		perms peerPermissions
+	}{
+		{"10.2.0.1:8333", permNoBan},
+		{"10.1.0.1:8333", permNoBan | permRelay},
+		{"192.168.0.1:8333", 0},
+	}
+	for _, test := range remoteTests {
+		addr, _ := net.ResolveTCPAddr("tcp", test.addr)
+		if perms := whitelistPermissions(addr); perms != test.perms {
+			t.Errorf("whitelistPermissions(%s): got %v, want %v",
+				test.addr, perms.names(), test.perms.names())
+		}
+	}
+
+	localTests := []struct {
+		addr  string
+		perms peerPermissions
+	}{
+		{"127.0.0.1:8334", permMempool},
+		{"127.0.0.2:8334", 0},
+		{"127.0.0.1:8333", 0},
+		{"192.168.0.1:8335", permBloomFilter},
+	}
+	for _, test := range localTests {
+		addr, _ := net.ResolveTCPAddr("tcp", test.addr)
+		if perms := whitebindPermissions(addr); perms != test.perms {
+			t.Errorf("whitebindPermissions(%s): got %v, want %v",
+				test.addr, perms.names(), test.perms.names())
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"time"
+
+	"github.com/btcsuite/btcd/mempool"
+)
+
+const (
+	// policySocketHookName is the name of the policy hook which consults an
+	// external policy service over a local socket.
+	policySocketHookName = "socket"
+
+	// policySocketTimeout is the maximum amount of time to wait for the
+	// external policy service to answer a single request.
+	policySocketTimeout = time.Second * 2
+)
+
+// policySocketRequest describes the JSON request sent to the external policy
+// service for each transaction.
+type policySocketRequest struct {
+	TxID   string `json:"txid"`
+	Hex    string `json:"hex"`
+	Fee    int64  `json:"fee"`
+	VSize  int64  `json:"vsize"`
+	Height int32  `json:"height"`
+	IsNew  bool   `json:"isnew"`
+}
+
+// policySocketResponse describes the JSON response expected from the external
+// policy service for each transaction.
+type policySocketResponse struct {
+	Reject      bool     `json:"reject"`
+	Reason      string   `json:"reason"`
+	Annotations []string `json:"annotations"`
+}
+
+// newPolicySocketHook returns a mempool policy hook which forwards each
+// transaction to the external policy service listening on the passed unix
+// socket path.  Every request is a single line of JSON answered by a single
+// line of JSON on a new connection.
+func newPolicySocketHook(path string) *mempool.PolicyHook {
+	return &mempool.PolicyHook{
+		Name: policySocketHookName,
+		Check: func(req *mempool.PolicyRequest) (*mempool.PolicyVerdict, error) {
+			var buf bytes.Buffer
+			buf.Grow(req.Tx.MsgTx().SerializeSize())
+			if err := req.Tx.MsgTx().Serialize(&buf); err != nil {
+				return nil, err
+			}
+			sreq := policySocketRequest{
+				TxID:   req.Tx.Hash().String(),
+				Hex:    hex.EncodeToString(buf.Bytes()),
+				Fee:    int64(req.Fee),
+				VSize:  req.VirtualSize,
+				Height: req.Height,
+				IsNew:  req.IsNew,
+			}
+
+			conn, err := net.DialTimeout("unix", path,
+				policySocketTimeout)
+			if err != nil {
+				return nil, err
+			}
+			defer conn.Close()
+			err = conn.SetDeadline(time.Now().Add(policySocketTimeout))
+			if err != nil {
+				return nil, err
+			}
+
+			// json.Encoder terminates each value with a newline.
+			if err := json.NewEncoder(conn).Encode(&sreq); err != nil {
+				return nil, err
+			}
+			line, err := bufio.NewReader(conn).ReadBytes('\n')
+			if err != nil {
+				return nil, err
+			}
+			var resp policySocketResponse
+			if err := json.Unmarshal(line, &resp); err != nil {
+				return nil, err
+			}
+
+			return &mempool.PolicyVerdict{
+				Reject:      resp.Reject,
+				Reason:      resp.Reason,
+				Annotations: resp.Annotations,
+			}, nil
+		},
+	}
+}
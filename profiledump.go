@@ -0,0 +1,185 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sync/atomic"
+	"time"
+
+	"github.com/btcsuite/btcd/btcjson"
+)
+
+const (
+	// profilesDirName is the name of the directory within the data
+	// directory the profiles dumped on request are written to.
+	profilesDirName = "profiles"
+
+	// defaultProfileDumpCPUDuration is the duration of the cpu profile
+	// which is dumped when a profile dump is requested by a signal.
+	defaultProfileDumpCPUDuration = 30 * time.Second
+
+	// maxProfileDumpCPUDuration is the maximum duration of the cpu profile
+	// which may be requested for a profile dump.
+	maxProfileDumpCPUDuration = 5 * time.Minute
+
+	// profileDumpTimeFormat is the format of the time at which a profile
+	// dump was requested in the names of the profile files.
+	profileDumpTimeFormat = "20060102-150405"
+)
+
+var (
+	// profileDumpSignals defines the signals which request the profiles to
+	// be dumped.  It is empty by default and set during init on platforms
+	// that support it.
+	profileDumpSignals []os.Signal
+
+	// profileDumping is set to 1 while profiles are being dumped.  It must
+	// be accessed atomically.
+	profileDumping int32
+
+	// errProfileDumpInProgress is returned by dumpProfiles when profiles are
+	// already being dumped.
+	errProfileDumpInProgress = errors.New("profiles are already being " +
+		"dumped")
+)
+
+// writeProfile writes the named runtime profile to the passed path using the
+// passed debug level of the profile.
+func writeProfile(name, path string, debug int) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := pprof.Lookup(name).WriteTo(f, debug); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// dumpProfiles writes a heap profile and the stack traces of all goroutines to
+// the passed directory, and starts a cpu profile which is written to it for the
+// passed duration in the background.  The names of the files contain the time
+// of the dump so later dumps don't overwrite earlier ones.  No cpu profile is
+// written when the duration is zero, or when one is already being written such
+// as when the cpuprofile option is used.  The cpu profile is cut short when the
+// passed quit channel is closed.
+//
+// The paths of the files are returned.  When an error occurs after some of the
+// profiles were written, their paths are returned along with the error.  Only a
+// single dump may be in progress at a time, and errProfileDumpInProgress is
+// returned otherwise.
+func dumpProfiles(dir string, cpuDuration time.Duration,
+	quit <-chan struct{}) (*btcjson.DumpProfilesResult, error) {
+
+	if !atomic.CompareAndSwapInt32(&profileDumping, 0, 1) {
+		return nil, errProfileDumpInProgress
+	}
+	cpuProfiling := false
+	defer func() {
+		if !cpuProfiling {
+			atomic.StoreInt32(&profileDumping, 0)
+		}
+	}()
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	stamp := time.Now().Format(profileDumpTimeFormat)
+	result := &btcjson.DumpProfilesResult{
+		Heap:      filepath.Join(dir, "heap-"+stamp+".pprof"),
+		Goroutine: filepath.Join(dir, "goroutine-"+stamp+".txt"),
+	}
+
+	// Run a garbage collection first so the heap profile is up to date.
+	runtime.GC()
+	if err := writeProfile("heap", result.Heap, 0); err != nil {
+		return nil, err
+	}
+	if err := writeProfile("goroutine", result.Goroutine, 2); err != nil {
+		result.Goroutine = ""
+		return result, err
+	}
+	btcdLog.Infof("Wrote heap profile to %s and goroutine stack traces "+
+		"to %s", result.Heap, result.Goroutine)
+
+	if cpuDuration <= 0 {
+		return result, nil
+	}
+
+	cpuPath := filepath.Join(dir, "cpu-"+stamp+".pprof")
+	f, err := os.Create(cpuPath)
+	if err != nil {
+		return result, err
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		os.Remove(cpuPath)
+		btcdLog.Warnf("Unable to start cpu profile: %v", err)
+		return result, nil
+	}
+	result.CPU = cpuPath
+	cpuProfiling = true
+
+	btcdLog.Infof("Writing cpu profile to %s for %v", cpuPath, cpuDuration)
+	go func() {
+		defer atomic.StoreInt32(&profileDumping, 0)
+
+		select {
+		case <-time.After(cpuDuration):
+		case <-quit:
+			btcdLog.Infof("Stopping cpu profile early due to shutdown")
+		}
+		pprof.StopCPUProfile()
+		if err := f.Close(); err != nil {
+			btcdLog.Errorf("Unable to write cpu profile: %v", err)
+			return
+		}
+		btcdLog.Infof("Wrote cpu profile to %s", cpuPath)
+	}()
+
+	return result, nil
+}
+
+// profileDumpListener dumps the profiles to the profiles directory within the
+// data directory whenever one of the profileDumpSignals is received until the
+// passed quit channel is closed.  It returns immediately on platforms without
+// such signals.
+func profileDumpListener(quit <-chan struct{}) {
+	if len(profileDumpSignals) == 0 {
+		return
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, profileDumpSignals...)
+	go func() {
+		defer signal.Stop(sigChan)
+
+		dir := filepath.Join(cfg.DataDir, profilesDirName)
+		for {
+			select {
+			case sig := <-sigChan:
+				btcdLog.Infof("Received signal (%s).  Dumping "+
+					"profiles to %s", sig, dir)
+				_, err := dumpProfiles(dir,
+					defaultProfileDumpCPUDuration, quit)
+				if err != nil {
+					btcdLog.Errorf("Unable to dump "+
+						"profiles: %v", err)
+				}
+
+			case <-quit:
+				return
+			}
+		}
+	}()
+}
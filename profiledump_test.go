@@ -0,0 +1,154 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcjson"
+)
+
+// TestDumpProfiles ensures the heap and goroutine profiles are written to the
+// passed directory, that the cpu profile is written in the background and that
+// only a single dump is allowed to be in progress at a time.
+func TestDumpProfiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), profilesDirName)
+
+	// Ensure only the heap and goroutine profiles are written without a
+	// cpu profile duration.
+	result, err := dumpProfiles(dir, 0, nil)
+	if err != nil {
+		t.Fatalf("dumpProfiles: unexpected error: %v", err)
+	}
+	if result.CPU != "" {
+		t.Fatalf("dumpProfiles: unexpected cpu profile %q", result.CPU)
+	}
+	for _, path := range []string{result.Heap, result.Goroutine} {
+		if filepath.Dir(path) != dir {
+			t.Fatalf("dumpProfiles: profile %q not in %q", path, dir)
+		}
+		fi, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("dumpProfiles: unable to stat profile: %v", err)
+		}
+		if fi.Size() == 0 {
+			t.Fatalf("dumpProfiles: profile %q is empty", path)
+		}
+	}
+
+	// Ensure another dump is rejected while the cpu profile is written.
+	result, err = dumpProfiles(dir, 500*time.Millisecond, nil)
+	if err != nil {
+		t.Fatalf("dumpProfiles: unexpected error: %v", err)
+	}
+	if result.CPU == "" {
+		t.Fatal("dumpProfiles: missing cpu profile")
+	}
+	if _, err := dumpProfiles(dir, 0, nil); err != errProfileDumpInProgress {
+		t.Fatalf("dumpProfiles: unexpected error -- got %v, want %v",
+			err, errProfileDumpInProgress)
+	}
+
+	// Wait for the cpu profile to be written.
+	deadline := time.Now().Add(10 * time.Second)
+	for atomic.LoadInt32(&profileDumping) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("dumpProfiles: cpu profile not written in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	fi, err := os.Stat(result.CPU)
+	if err != nil {
+		t.Fatalf("dumpProfiles: unable to stat cpu profile: %v", err)
+	}
+	if fi.Size() == 0 {
+		t.Fatalf("dumpProfiles: cpu profile %q is empty", result.CPU)
+	}
+}
+
+// TestDumpProfilesQuit ensures the cpu profile is cut short when the quit
+// channel is closed.
+func TestDumpProfilesQuit(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), profilesDirName)
+
+	quit := make(chan struct{})
+	result, err := dumpProfiles(dir, maxProfileDumpCPUDuration, quit)
+	if err != nil {
+		t.Fatalf("dumpProfiles: unexpected error: %v", err)
+	}
+	if result.CPU == "" {
+		t.Fatal("dumpProfiles: missing cpu profile")
+	}
+	close(quit)
+
+	deadline := time.Now().Add(10 * time.Second)
+	for atomic.LoadInt32(&profileDumping) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("dumpProfiles: cpu profile not stopped on quit")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := os.Stat(result.CPU); err != nil {
+		t.Fatalf("dumpProfiles: unable to stat cpu profile: %v", err)
+	}
+}
+
+// TestDumpProfilesCPUError ensures the paths of the heap and goroutine profiles
+// are returned along with the error when the cpu profile can't be created.
+func TestDumpProfilesCPUError(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), profilesDirName)
+
+	// Create directories in place of the cpu profiles of the next few
+	// seconds so creating the one of the dump fails.
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		stamp := now.Add(time.Duration(i) * time.Second).
+			Format(profileDumpTimeFormat)
+		path := filepath.Join(dir, "cpu-"+stamp+".pprof")
+		if err := os.MkdirAll(path, 0700); err != nil {
+			t.Fatalf("unable to create directory: %v", err)
+		}
+	}
+
+	result, err := dumpProfiles(dir, time.Second, nil)
+	if err == nil {
+		t.Fatal("dumpProfiles: expected error")
+	}
+	if result == nil {
+		t.Fatal("dumpProfiles: missing paths of the written profiles")
+	}
+	if result.CPU != "" {
+		t.Fatalf("dumpProfiles: unexpected cpu profile %q", result.CPU)
+	}
+	for _, path := range []string{result.Heap, result.Goroutine} {
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("dumpProfiles: unable to stat profile: %v", err)
+		}
+	}
+
+	// Ensure the failed dump doesn't prevent later ones.
+	if atomic.LoadInt32(&profileDumping) != 0 {
+		t.Fatal("dumpProfiles: dump still marked in progress")
+	}
+}
+
+// TestHandleDumpProfilesCPUSeconds ensures the dumpprofiles command rejects
+// cpu profile durations which are negative or exceed the maximum.
+func TestHandleDumpProfilesCPUSeconds(t *testing.T) {
+	maxSeconds := int(maxProfileDumpCPUDuration / time.Second)
+	for _, seconds := range []int{-1, maxSeconds + 1} {
+		cmd := btcjson.NewDumpProfilesCmd(btcjson.Int(seconds))
+		_, err := handleDumpProfiles(&rpcServer{}, cmd, nil)
+		rpcErr, ok := err.(*btcjson.RPCError)
+		if !ok || rpcErr.Code != btcjson.ErrRPCInvalidParameter {
+			t.Fatalf("handleDumpProfiles(%d): unexpected error: %v",
+				seconds, err)
+		}
+	}
+}
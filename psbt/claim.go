@@ -0,0 +1,111 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package psbt
+
+import (
+	"bytes"
+)
+
+// ClaimIdentifier is the identifier of the proprietary output key-value pairs
+// which describe the claim, support or update an output makes.  They allow
+// signers to show what an output does without decoding its script.
+var ClaimIdentifier = []byte("lbc")
+
+const (
+	// ClaimNameSubtype is the subtype of the proprietary output pair whose
+	// value is the claimed or supported name.
+	ClaimNameSubtype = 0x00
+
+	// ClaimIDSubtype is the subtype of the proprietary output pair whose
+	// value is the ID of the supported or updated claim.
+	ClaimIDSubtype = 0x01
+
+	// ClaimValueSubtype is the subtype of the proprietary output pair whose
+	// value is the value of the claim, support or update.
+	ClaimValueSubtype = 0x02
+)
+
+// OutputClaim houses the claim data of an output which claims a name, supports
+// a claim or updates a claim.
+type OutputClaim struct {
+	// Name is the claimed or supported name.
+	Name []byte
+
+	// ClaimID is the ID of the supported or updated claim.  It is nil for
+	// claims.
+	ClaimID []byte
+
+	// Value is the value of the claim, support or update.  It is nil for
+	// supports without a value.
+	Value []byte
+}
+
+// isClaimPair returns whether or not the passed unknown key-value pair is one
+// of the proprietary claim pairs along with its parsed form.
+func isClaimPair(u *Unknown) (*Proprietary, bool) {
+	p, ok := ParseProprietary(u)
+	if !ok || !bytes.Equal(p.Identifier, ClaimIdentifier) ||
+		len(p.KeyData) != 0 {
+
+		return nil, false
+	}
+	switch p.Subtype {
+	case ClaimNameSubtype, ClaimIDSubtype, ClaimValueSubtype:
+		return p, true
+	}
+	return nil, false
+}
+
+// SetClaim replaces the proprietary claim key-value pairs of the output with
+// ones describing the passed claim data.  Passing nil removes them.
+func (po *POutput) SetClaim(claim *OutputClaim) {
+	unknowns := po.Unknowns[:0:0]
+	for _, u := range po.Unknowns {
+		if _, ok := isClaimPair(u); !ok {
+			unknowns = append(unknowns, u)
+		}
+	}
+	if claim != nil {
+		add := func(subtype uint64, value []byte) {
+			if value == nil {
+				return
+			}
+			p := Proprietary{
+				Identifier: ClaimIdentifier,
+				Subtype:    subtype,
+				Value:      value,
+			}
+			unknowns = append(unknowns, p.Unknown())
+		}
+		add(ClaimNameSubtype, claim.Name)
+		add(ClaimIDSubtype, claim.ClaimID)
+		add(ClaimValueSubtype, claim.Value)
+	}
+	po.Unknowns = unknowns
+}
+
+// Claim returns the claim data described by the proprietary claim key-value
+// pairs of the output, or nil if it has no claim name pair.
+func (po *POutput) Claim() *OutputClaim {
+	var claim OutputClaim
+	for _, u := range po.Unknowns {
+		p, ok := isClaimPair(u)
+		if !ok {
+			continue
+		}
+		switch p.Subtype {
+		case ClaimNameSubtype:
+			claim.Name = p.Value
+		case ClaimIDSubtype:
+			claim.ClaimID = p.Value
+		case ClaimValueSubtype:
+			claim.Value = p.Value
+		}
+	}
+	if claim.Name == nil {
+		return nil
+	}
+	return &claim
+}
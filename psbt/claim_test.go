@@ -0,0 +1,92 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package psbt
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/txscript/v2"
+	"github.com/btcsuite/btcd/wire/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// TestProprietary ensures proprietary key-value pairs are encoded to and
+// parsed from unknown key-value pairs and that other keys aren't parsed as
+// proprietary ones.
+func TestProprietary(t *testing.T) {
+	t.Parallel()
+
+	p := &Proprietary{
+		Identifier: []byte("lbc"),
+		Subtype:    0x0102,
+		KeyData:    []byte{0xaa, 0xbb},
+		Value:      []byte{0x01},
+	}
+	u := p.Unknown()
+	require.Equal(t, []byte{
+		0xfc, 0x03, 'l', 'b', 'c', 0xfd, 0x02, 0x01, 0xaa, 0xbb,
+	}, u.Key)
+	require.Equal(t, []byte{0x01}, u.Value)
+
+	parsed, ok := ParseProprietary(u)
+	require.True(t, ok)
+	require.Equal(t, p, parsed)
+
+	invalid := []*Unknown{
+		{Key: nil},
+		{Key: []byte{0xf0, 0x03, 'l', 'b', 'c', 0x00}},
+		{Key: []byte{0xfc, 0x05, 'l', 'b', 'c', 0x00}},
+		{Key: []byte{0xfc, 0x03, 'l', 'b', 'c'}},
+	}
+	for _, u := range invalid {
+		_, ok := ParseProprietary(u)
+		require.False(t, ok, "key %x", u.Key)
+	}
+}
+
+// TestOutputClaim ensures the claim data of an output survives a round trip
+// through the serialization of a packet, replaces earlier claim data and leaves
+// other unknown pairs alone.
+func TestOutputClaim(t *testing.T) {
+	t.Parallel()
+
+	p, err := New(
+		[]*wire.OutPoint{{Hash: chainhash.Hash{0x01}}},
+		[]*wire.TxOut{wire.NewTxOut(1000, []byte{txscript.OP_TRUE})},
+		2, 0, []uint32{wire.MaxTxInSequenceNum},
+	)
+	require.NoError(t, err)
+	require.Nil(t, p.Outputs[0].Claim())
+
+	other := &Unknown{Key: []byte{0xf0}, Value: []byte{0x01}}
+	p.Outputs[0].Unknowns = []*Unknown{other}
+
+	// Set the data of a claim and replace it by the data of an update.
+	p.Outputs[0].SetClaim(&OutputClaim{
+		Name:  []byte("one"),
+		Value: []byte("first"),
+	})
+	update := &OutputClaim{
+		Name:    []byte("one"),
+		ClaimID: bytes.Repeat([]byte{0x01}, 20),
+		Value:   []byte{},
+	}
+	p.Outputs[0].SetClaim(update)
+	require.Len(t, p.Outputs[0].Unknowns, 4)
+
+	var buf bytes.Buffer
+	require.NoError(t, p.Serialize(&buf))
+	decoded, err := NewFromRawBytes(&buf, false)
+	require.NoError(t, err)
+	require.Equal(t, update, decoded.Outputs[0].Claim())
+	require.Equal(t, other, decoded.Outputs[0].Unknowns[0])
+
+	// Removing the claim data keeps the other pairs.
+	decoded.Outputs[0].SetClaim(nil)
+	require.Nil(t, decoded.Outputs[0].Claim())
+	require.Equal(t, []*Unknown{other}, decoded.Outputs[0].Unknowns)
+}
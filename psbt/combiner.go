@@ -0,0 +1,246 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package psbt
+
+import (
+	"bytes"
+	"errors"
+)
+
+// ErrDifferentTransactions indicates that the PSBTs passed to Combine are not
+// all for the same unsigned transaction.
+var ErrDifferentTransactions = errors.New("PSBTs are not for the same " +
+	"unsigned transaction")
+
+// Combine merges the key-value pairs of the passed packets into a new packet,
+// which makes it the Combiner role of BIP174.  All packets must be for the
+// same unsigned transaction, otherwise ErrDifferentTransactions is returned.
+// When more than one packet has a value for the same key, the value of the
+// first of them is kept.  The passed packets are not modified.
+func Combine(packets ...*Packet) (*Packet, error) {
+	if len(packets) == 0 {
+		return nil, ErrInvalidPsbtFormat
+	}
+
+	// Start out with a copy of the first packet, so the key-value pairs of
+	// the others can be merged into it without modifying the first one.
+	combined, err := copyPacket(packets[0])
+	if err != nil {
+		return nil, err
+	}
+
+	txHash := combined.UnsignedTx.TxHash()
+	for _, p := range packets[1:] {
+		// The merged key-value pairs are copied as well, so the combined
+		// packet doesn't share any data with the passed ones.
+		p, err := copyPacket(p)
+		if err != nil {
+			return nil, err
+		}
+		if p.UnsignedTx.TxHash() != txHash ||
+			len(p.Inputs) != len(combined.Inputs) ||
+			len(p.Outputs) != len(combined.Outputs) {
+
+			return nil, ErrDifferentTransactions
+		}
+
+		for _, xPub := range p.XPubs {
+			if !hasXPub(combined.XPubs, xPub.ExtendedKey) {
+				combined.XPubs = append(combined.XPubs, xPub)
+			}
+		}
+		combined.Unknowns = combineUnknowns(combined.Unknowns, p.Unknowns)
+		for i := range p.Inputs {
+			combineInput(&combined.Inputs[i], &p.Inputs[i])
+		}
+		for i := range p.Outputs {
+			combineOutput(&combined.Outputs[i], &p.Outputs[i])
+		}
+	}
+
+	return combined, nil
+}
+
+// copyPacket returns a deep copy of the passed packet.
+func copyPacket(p *Packet) (*Packet, error) {
+	var buf bytes.Buffer
+	if err := p.Serialize(&buf); err != nil {
+		return nil, err
+	}
+	return NewFromRawBytes(&buf, false)
+}
+
+// hasXPub returns whether or not the passed extended key is in the list of
+// xPubs.
+func hasXPub(xPubs []XPub, extendedKey []byte) bool {
+	for _, x := range xPubs {
+		if bytes.Equal(x.ExtendedKey, extendedKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// combineUnknowns returns the unknown key-value pairs in dst followed by the
+// ones in src whose keys are not in dst.
+func combineUnknowns(dst, src []*Unknown) []*Unknown {
+	for _, u := range src {
+		found := false
+		for _, x := range dst {
+			if bytes.Equal(x.Key, u.Key) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst = append(dst, u)
+		}
+	}
+	return dst
+}
+
+// combineInput adds the key-value pairs of the src input which are missing
+// from the dst input to it.
+func combineInput(dst, src *PInput) {
+	if dst.NonWitnessUtxo == nil {
+		dst.NonWitnessUtxo = src.NonWitnessUtxo
+	}
+	if dst.WitnessUtxo == nil {
+		dst.WitnessUtxo = src.WitnessUtxo
+	}
+	for _, sig := range src.PartialSigs {
+		found := false
+		for _, x := range dst.PartialSigs {
+			if bytes.Equal(x.PubKey, sig.PubKey) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst.PartialSigs = append(dst.PartialSigs, sig)
+		}
+	}
+	if dst.SighashType == 0 {
+		dst.SighashType = src.SighashType
+	}
+	if dst.RedeemScript == nil {
+		dst.RedeemScript = src.RedeemScript
+	}
+	if dst.WitnessScript == nil {
+		dst.WitnessScript = src.WitnessScript
+	}
+	dst.Bip32Derivation = combineBip32Derivations(dst.Bip32Derivation,
+		src.Bip32Derivation)
+	if dst.FinalScriptSig == nil {
+		dst.FinalScriptSig = src.FinalScriptSig
+	}
+	if dst.FinalScriptWitness == nil {
+		dst.FinalScriptWitness = src.FinalScriptWitness
+	}
+	if dst.TaprootKeySpendSig == nil {
+		dst.TaprootKeySpendSig = src.TaprootKeySpendSig
+	}
+	for _, sig := range src.TaprootScriptSpendSig {
+		found := false
+		for _, x := range dst.TaprootScriptSpendSig {
+			if bytes.Equal(x.XOnlyPubKey, sig.XOnlyPubKey) &&
+				bytes.Equal(x.LeafHash, sig.LeafHash) {
+
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst.TaprootScriptSpendSig = append(
+				dst.TaprootScriptSpendSig, sig,
+			)
+		}
+	}
+	for _, leaf := range src.TaprootLeafScript {
+		found := false
+		for _, x := range dst.TaprootLeafScript {
+			if bytes.Equal(x.ControlBlock, leaf.ControlBlock) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst.TaprootLeafScript = append(dst.TaprootLeafScript, leaf)
+		}
+	}
+	dst.TaprootBip32Derivation = combineTaprootBip32Derivations(
+		dst.TaprootBip32Derivation, src.TaprootBip32Derivation,
+	)
+	if dst.TaprootInternalKey == nil {
+		dst.TaprootInternalKey = src.TaprootInternalKey
+	}
+	if dst.TaprootMerkleRoot == nil {
+		dst.TaprootMerkleRoot = src.TaprootMerkleRoot
+	}
+	dst.Unknowns = combineUnknowns(dst.Unknowns, src.Unknowns)
+}
+
+// combineOutput adds the key-value pairs of the src output which are missing
+// from the dst output to it.
+func combineOutput(dst, src *POutput) {
+	if dst.RedeemScript == nil {
+		dst.RedeemScript = src.RedeemScript
+	}
+	if dst.WitnessScript == nil {
+		dst.WitnessScript = src.WitnessScript
+	}
+	dst.Bip32Derivation = combineBip32Derivations(dst.Bip32Derivation,
+		src.Bip32Derivation)
+	if dst.TaprootInternalKey == nil {
+		dst.TaprootInternalKey = src.TaprootInternalKey
+	}
+	if dst.TaprootTapTree == nil {
+		dst.TaprootTapTree = src.TaprootTapTree
+	}
+	dst.TaprootBip32Derivation = combineTaprootBip32Derivations(
+		dst.TaprootBip32Derivation, src.TaprootBip32Derivation,
+	)
+	dst.Unknowns = combineUnknowns(dst.Unknowns, src.Unknowns)
+}
+
+// combineBip32Derivations returns the derivations in dst followed by the ones
+// in src for public keys which are not in dst.
+func combineBip32Derivations(dst,
+	src []*Bip32Derivation) []*Bip32Derivation {
+
+	for _, d := range src {
+		found := false
+		for _, x := range dst {
+			if bytes.Equal(x.PubKey, d.PubKey) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst = append(dst, d)
+		}
+	}
+	return dst
+}
+
+// combineTaprootBip32Derivations returns the derivations in dst followed by the
+// ones in src for public keys which are not in dst.
+func combineTaprootBip32Derivations(dst,
+	src []*TaprootBip32Derivation) []*TaprootBip32Derivation {
+
+	for _, d := range src {
+		found := false
+		for _, x := range dst {
+			if bytes.Equal(x.XOnlyPubKey, d.XOnlyPubKey) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst = append(dst, d)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package psbt
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/txscript/v2"
+	"github.com/btcsuite/btcd/wire/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPartialSig returns a partial signature of the passed private key over
+// an arbitrary message.
+func newTestPartialSig(t *testing.T, key byte) *PartialSig {
+	t.Helper()
+
+	privKey, pubKey := btcec.PrivKeyFromBytes([]byte{key})
+	hash := chainhash.HashB([]byte("combine"))
+	sig := ecdsa.Sign(privKey, hash).Serialize()
+
+	return &PartialSig{
+		PubKey:    pubKey.SerializeCompressed(),
+		Signature: append(sig, byte(txscript.SigHashAll)),
+	}
+}
+
+// TestCombine ensures that Combine merges the key-value pairs of packets for
+// the same transaction, keeps the first value for duplicate keys and rejects
+// packets for different transactions.
+func TestCombine(t *testing.T) {
+	t.Parallel()
+
+	prevOut := wire.OutPoint{Hash: chainhash.Hash{0x01}, Index: 1}
+	newPacket := func(value int64) *Packet {
+		p, err := New(
+			[]*wire.OutPoint{&prevOut},
+			[]*wire.TxOut{wire.NewTxOut(value, []byte{txscript.OP_TRUE})},
+			2, 0, []uint32{wire.MaxTxInSequenceNum},
+		)
+		require.NoError(t, err)
+		return p
+	}
+
+	witnessUtxo := wire.NewTxOut(1000, []byte{
+		txscript.OP_0, txscript.OP_DATA_20,
+		0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a,
+		0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10, 0x11, 0x12, 0x13, 0x14,
+	})
+	sig1 := newTestPartialSig(t, 1)
+	sig2 := newTestPartialSig(t, 2)
+	globalUnknown := &Unknown{Key: []byte{0xf0, 0x01}, Value: []byte{0x01}}
+
+	// The first packet has the spent output, a signature and a global
+	// pair.
+	p1 := newPacket(900)
+	p1.Inputs[0].WitnessUtxo = witnessUtxo
+	p1.Inputs[0].PartialSigs = []*PartialSig{sig1}
+	p1.Unknowns = []*Unknown{globalUnknown}
+
+	// The second packet has the same signature, another one, a different
+	// value for the same global key and an output pair.
+	p2 := newPacket(900)
+	p2.Inputs[0].PartialSigs = []*PartialSig{sig1, sig2}
+	p2.Unknowns = []*Unknown{{Key: globalUnknown.Key, Value: []byte{0x02}}}
+	p2.Outputs[0].RedeemScript = []byte{txscript.OP_TRUE}
+
+	combined, err := Combine(p1, p2)
+	require.NoError(t, err)
+	require.Equal(t, witnessUtxo, combined.Inputs[0].WitnessUtxo)
+	require.Equal(t, []*PartialSig{sig1, sig2}, combined.Inputs[0].PartialSigs)
+	require.Equal(t, []*Unknown{globalUnknown}, combined.Unknowns)
+	require.Equal(t, []byte{txscript.OP_TRUE}, combined.Outputs[0].RedeemScript)
+
+	// The passed packets must not have been modified.
+	require.Len(t, p1.Inputs[0].PartialSigs, 1)
+	require.Nil(t, p1.Outputs[0].RedeemScript)
+	require.Nil(t, p2.Inputs[0].WitnessUtxo)
+
+	// A single packet is combined into a copy of itself.
+	combined, err = Combine(p2)
+	require.NoError(t, err)
+	require.Equal(t, p2.Inputs[0].PartialSigs, combined.Inputs[0].PartialSigs)
+
+	// Packets for different transactions can't be combined.
+	_, err = Combine(p1, newPacket(800))
+	require.ErrorIs(t, err, ErrDifferentTransactions)
+
+	// There must be at least one packet.
+	_, err = Combine()
+	require.ErrorIs(t, err, ErrInvalidPsbtFormat)
+}
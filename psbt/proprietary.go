@@ -0,0 +1,77 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package psbt
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/btcsuite/btcd/wire/v2"
+)
+
+// Proprietary is a proprietary key-value pair as defined by BIP174.  These are
+// kept in the Unknowns of the global section, an input or an output, since
+// their meaning is only known to the users of the identifier.
+type Proprietary struct {
+	// Identifier is the prefix which identifies the user of the pair.
+	Identifier []byte
+
+	// Subtype is the type of the pair as defined by the user.
+	Subtype uint64
+
+	// KeyData is the data in the key following the subtype.
+	KeyData []byte
+
+	// Value is the value of the pair.
+	Value []byte
+}
+
+// ParseProprietary returns the proprietary key-value pair in the passed
+// unknown key-value pair.  False is returned if the key isn't a well formed
+// proprietary key.
+func ParseProprietary(u *Unknown) (*Proprietary, bool) {
+	if len(u.Key) == 0 || u.Key[0] != ProprietaryGlobalType {
+		return nil, false
+	}
+
+	r := bytes.NewReader(u.Key[1:])
+	identifier, err := wire.ReadVarBytes(
+		r, 0, MaxPsbtKeyLength, "proprietary identifier",
+	)
+	if err != nil {
+		return nil, false
+	}
+	subtype, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, false
+	}
+	keyData, err := io.ReadAll(r)
+	if err != nil {
+		return nil, false
+	}
+
+	return &Proprietary{
+		Identifier: identifier,
+		Subtype:    subtype,
+		KeyData:    keyData,
+		Value:      u.Value,
+	}, true
+}
+
+// Unknown returns the proprietary key-value pair as an unknown key-value pair
+// so it can be added to the Unknowns of the global section, an input or an
+// output.
+func (p *Proprietary) Unknown() *Unknown {
+	var key bytes.Buffer
+	key.WriteByte(ProprietaryGlobalType)
+	_ = wire.WriteVarBytes(&key, 0, p.Identifier)
+	_ = wire.WriteVarInt(&key, 0, p.Subtype)
+	key.Write(p.KeyData)
+
+	return &Unknown{
+		Key:   key.Bytes(),
+		Value: p.Value,
+	}
+}
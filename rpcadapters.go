@@ -6,10 +6,12 @@ package main
 
 import (
 	"sync/atomic"
+	"time"
 
 	"github.com/btcsuite/btcd/blockchain"
 	"github.com/btcsuite/btcd/btcutil/v2"
 	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/connmgr"
 	"github.com/btcsuite/btcd/mempool"
 	"github.com/btcsuite/btcd/netsync"
 	"github.com/btcsuite/btcd/peer"
@@ -49,7 +51,16 @@ func (p *rpcPeer) IsTxRelayDisabled() bool {
 // This function is safe for concurrent access and is part of the rpcserverPeer
 // interface implementation.
 func (p *rpcPeer) BanScore() uint32 {
-	return (*serverPeer)(p).banScore.Int()
+	return (*serverPeer)(p).misbehavior.BanScore()
+}
+
+// Misbehavior returns the most recent misbehavior of the peer from oldest to
+// newest.
+//
+// This function is safe for concurrent access and is part of the rpcserverPeer
+// interface implementation.
+func (p *rpcPeer) Misbehavior() []misbehaviorRecord {
+	return (*serverPeer)(p).misbehavior.Records()
 }
 
 // FeeFilter returns the requested current minimum fee rate for which
@@ -61,6 +72,34 @@ func (p *rpcPeer) FeeFilter() int64 {
 	return atomic.LoadInt64(&(*serverPeer)(p).feeFilter)
 }
 
+// TxRelayStats returns the number of transactions announced by the peer, the
+// number of those announcements which were ignored due to the relay rate
+// limit, and the number of transactions received from the peer.
+//
+// This function is safe for concurrent access and is part of the rpcserverPeer
+// interface implementation.
+func (p *rpcPeer) TxRelayStats() (announced, throttled, received uint64) {
+	sp := (*serverPeer)(p)
+	return sp.txInvRecv.Load(), sp.txInvThrottled.Load(), sp.txRecv.Load()
+}
+
+// Permissions returns the names of the permissions granted to the peer by the
+// whitelist and whitebind options.
+//
+// This function is safe for concurrent access and is part of the rpcserverPeer
+// interface implementation.
+func (p *rpcPeer) Permissions() []string {
+	return (*serverPeer)(p).permissions.names()
+}
+
+// ConnectionType returns a description of the kind of connection to the peer.
+//
+// This function is safe for concurrent access and is part of the rpcserverPeer
+// interface implementation.
+func (p *rpcPeer) ConnectionType() string {
+	return (*serverPeer)(p).connectionType()
+}
+
 // rpcConnManager provides a connection manager for use with the RPC server and
 // implements the rpcserverConnManager interface.
 type rpcConnManager struct {
@@ -87,9 +126,9 @@ func (cm *rpcConnManager) Connect(addr string, permanent bool) error {
 	return <-replyChan
 }
 
-// RemoveByID removes the peer associated with the provided id from the list of
-// persistent peers.  Attempting to remove an id that does not exist will return
-// an error.
+// RemoveByID removes the added node the peer associated with the provided id
+// is connected to and disconnects the peer.  Attempting to remove an id that
+// does not exist will return an error.
 //
 // This function is safe for concurrent access and is part of the
 // rpcserverConnManager interface implementation.
@@ -102,15 +141,16 @@ func (cm *rpcConnManager) RemoveByID(id int32) error {
 	return <-replyChan
 }
 
-// RemoveByAddr removes the peer associated with the provided address from the
-// list of persistent peers.  Attempting to remove an address that does not
-// exist will return an error.
+// RemoveByAddr removes the added node with the provided address and disconnects
+// the peer connected to it, if any.  Attempting to remove an address that has
+// not been added will return an error.
 //
 // This function is safe for concurrent access and is part of the
 // rpcserverConnManager interface implementation.
 func (cm *rpcConnManager) RemoveByAddr(addr string) error {
 	replyChan := make(chan error)
 	cm.server.query <- removeNodeMsg{
+		addr:  addr,
 		cmp:   func(sp *serverPeer) bool { return sp.Addr() == addr },
 		reply: replyChan,
 	}
@@ -147,6 +187,56 @@ func (cm *rpcConnManager) DisconnectByAddr(addr string) error {
 	return <-replyChan
 }
 
+// Ban bans the provided subnet, IP address, or host until the given time and
+// disconnects the peers it covers.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) Ban(addr string, until time.Time) error {
+	replyChan := make(chan error)
+	cm.server.query <- banMsg{
+		addr:  addr,
+		until: until,
+		reply: replyChan,
+	}
+	return <-replyChan
+}
+
+// Unban lifts the ban of the provided subnet, IP address, or host.  Attempting
+// to unban an address which is not banned will return an error.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) Unban(addr string) error {
+	replyChan := make(chan error)
+	cm.server.query <- unbanMsg{
+		addr:  addr,
+		reply: replyChan,
+	}
+	return <-replyChan
+}
+
+// BannedAddrs returns an array consisting of all bans which have not expired
+// yet.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) BannedAddrs() []banEntry {
+	replyChan := make(chan []banEntry)
+	cm.server.query <- getBannedMsg{reply: replyChan}
+	return <-replyChan
+}
+
+// ClearBanned lifts all bans.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) ClearBanned() {
+	replyChan := make(chan struct{})
+	cm.server.query <- clearBannedMsg{reply: replyChan}
+	<-replyChan
+}
+
 // ConnectedCount returns the number of currently connected peers.
 //
 // This function is safe for concurrent access and is part of the
@@ -164,6 +254,23 @@ func (cm *rpcConnManager) NetTotals() (uint64, uint64) {
 	return cm.server.NetTotals()
 }
 
+// NetTotalsPerMsg returns the bytes sent and received across the network for
+// all peers per message command.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) NetTotalsPerMsg() (map[string]uint64, map[string]uint64) {
+	return cm.server.bandwidth.PerMsg()
+}
+
+// UploadTarget returns the state of the upload target in the current cycle.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) UploadTarget() uploadTarget {
+	return cm.server.bandwidth.UploadTarget(time.Now())
+}
+
 // ConnectedPeers returns an array consisting of all connected peers.
 //
 // This function is safe for concurrent access and is part of the
@@ -181,22 +288,15 @@ func (cm *rpcConnManager) ConnectedPeers() []rpcserverPeer {
 	return peers
 }
 
-// PersistentPeers returns an array consisting of all the added persistent
-// peers.
+// AddedNodes returns the manually added nodes in the order they were added
+// along with the peers connected to them.
 //
 // This function is safe for concurrent access and is part of the
 // rpcserverConnManager interface implementation.
-func (cm *rpcConnManager) PersistentPeers() []rpcserverPeer {
-	replyChan := make(chan []*serverPeer)
+func (cm *rpcConnManager) AddedNodes() []addedNodeInfo {
+	replyChan := make(chan []addedNodeInfo)
 	cm.server.query <- getAddedNodesMsg{reply: replyChan}
-	serverPeers := <-replyChan
-
-	// Convert to generic peers.
-	peers := make([]rpcserverPeer, 0, len(serverPeers))
-	for _, sp := range serverPeers {
-		peers = append(peers, (*rpcPeer)(sp))
-	}
-	return peers
+	return <-replyChan
 }
 
 // BroadcastMessage sends the provided message to all currently connected peers.
@@ -232,6 +332,51 @@ func (cm *rpcConnManager) NodeAddresses() []*wire.NetAddressV2 {
 	return cm.server.addrManager.AddressCache()
 }
 
+// NATMapping returns the state of the port mapping of the listening port and
+// whether NAT traversal is in use.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) NATMapping() (natMapping, bool) {
+	return cm.server.NATMapping()
+}
+
+// ConnectionTargets returns the number of outbound connections of each kind
+// which are maintained.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) ConnectionTargets() connmgr.Targets {
+	return cm.server.connManager.Targets()
+}
+
+// SetConnectionTargets changes the number of outbound connections of each kind
+// to maintain.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) SetConnectionTargets(targets connmgr.Targets) {
+	cm.server.connManager.SetTargets(targets)
+}
+
+// OnionReachable returns whether outbound connections to onion addresses can be
+// made.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) OnionReachable() bool {
+	return onionReachable()
+}
+
+// ClockSkew returns how far the local clock is off from the time of the network
+// along with the estimated offset of the latter.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) ClockSkew() (clockSkewState, time.Duration) {
+	return cm.server.clockSkew.State()
+}
+
 // rpcSyncMgr provides a block manager for use with the RPC server and
 // implements the rpcserverSyncManager interface.
 type rpcSyncMgr struct {
@@ -277,6 +422,15 @@ func (b *rpcSyncMgr) SyncPeerID() int32 {
 	return b.syncMgr.SyncPeerID()
 }
 
+// StaleTip returns whether no new block was connected to the best chain tip for
+// the stale tip timeout.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverSyncManager interface implementation.
+func (b *rpcSyncMgr) StaleTip() bool {
+	return b.syncMgr.StaleTip()
+}
+
 // LocateHeaders returns the hashes of the blocks after the first known block in
 // the provided locators until the provided stop hash or the current tip is
 // reached, up to a max of wire.MaxBlockHeadersPerMsg hashes.
@@ -286,3 +440,40 @@ func (b *rpcSyncMgr) SyncPeerID() int32 {
 func (b *rpcSyncMgr) LocateHeaders(locators []*chainhash.Hash, hashStop *chainhash.Hash) []wire.BlockHeader {
 	return b.server.chain.LocateHeaders(locators, hashStop)
 }
+
+// ImportBlocks starts importing the blocks of the provided block files in the
+// background.  It returns an error when an import is already running.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverSyncManager interface implementation.
+func (b *rpcSyncMgr) ImportBlocks(paths []string) error {
+	return b.server.importBlockFiles(paths)
+}
+
+// ChainVerifyWarning returns the warning about the problem found by the
+// verification of the chain at startup or an empty string when no problem was
+// found.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverSyncManager interface implementation.
+func (b *rpcSyncMgr) ChainVerifyWarning() string {
+	return b.server.ChainVerifyWarning()
+}
+
+// DiskSpaceWarning returns the warning about the free disk space being below
+// the minimum or an empty string when enough space is available.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverSyncManager interface implementation.
+func (b *rpcSyncMgr) DiskSpaceWarning() string {
+	return b.server.DiskSpaceWarning()
+}
+
+// BlockRepairs returns the recorded repairs of blocks whose stored copies were
+// found to be damaged from oldest to newest.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverSyncManager interface implementation.
+func (b *rpcSyncMgr) BlockRepairs() []blockRepairEvent {
+	return b.server.blockRepairer.repairs()
+}
@@ -291,6 +291,44 @@ func (c *Client) GetBlockCount() (int64, error) {
 	return c.GetBlockCountAsync().Receive()
 }
 
+// FutureGetDeploymentInfoResult is a future promise to deliver the result of a
+// GetDeploymentInfoAsync RPC invocation (or an applicable error).
+type FutureGetDeploymentInfoResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the state
+// of the soft-fork deployments.
+func (r FutureGetDeploymentInfoResult) Receive() (*btcjson.GetDeploymentInfoResult, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var deploymentInfo btcjson.GetDeploymentInfoResult
+	err = json.Unmarshal(res, &deploymentInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deploymentInfo, nil
+}
+
+// GetDeploymentInfoAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetDeploymentInfo for the blocking version and more details.
+func (c *Client) GetDeploymentInfoAsync() FutureGetDeploymentInfoResult {
+	cmd := btcjson.NewGetDeploymentInfoCmd()
+	return c.SendCmd(cmd)
+}
+
+// GetDeploymentInfo returns the state of the soft-fork deployments for the
+// block after the best block along with the signalling statistics of the
+// deployments which are being voted on.
+func (c *Client) GetDeploymentInfo() (*btcjson.GetDeploymentInfoResult, error) {
+	return c.GetDeploymentInfoAsync().Receive()
+}
+
 // FutureGetChainTxStatsResult is a future promise to deliver the result of a
 // GetChainTxStatsAsync RPC invocation (or an applicable error).
 type FutureGetChainTxStatsResult chan *Response
@@ -688,6 +726,407 @@ func (c *Client) GetChainTips() ([]*btcjson.GetChainTipsResult, error) {
 	return c.GetChainTipsAsync().Receive()
 }
 
+// FutureGetBlockBenchmarksResult is a future promise to deliver the result of
+// a GetBlockBenchmarks RPC invocation (or an applicable error).
+type FutureGetBlockBenchmarksResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the time
+// spent in each stage of processing the most recent blocks.
+func (r FutureGetBlockBenchmarksResult) Receive() ([]btcjson.GetBlockBenchmarksResult, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of benchmarks.
+	var benchmarks []btcjson.GetBlockBenchmarksResult
+	err = json.Unmarshal(res, &benchmarks)
+	if err != nil {
+		return nil, err
+	}
+
+	return benchmarks, nil
+}
+
+// GetBlockBenchmarksAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetBlockBenchmarks for the blocking version and more details.
+func (c *Client) GetBlockBenchmarksAsync(count *int) FutureGetBlockBenchmarksResult {
+	cmd := btcjson.NewGetBlockBenchmarksCmd(count)
+	return c.SendCmd(cmd)
+}
+
+// GetBlockBenchmarks returns the time spent in each stage of processing the
+// most recent blocks connected to the main chain ordered from the oldest to the
+// newest.  A nil count returns the 10 most recent blocks.
+//
+// NOTE: This is a btcd extension.
+func (c *Client) GetBlockBenchmarks(count *int) ([]btcjson.GetBlockBenchmarksResult, error) {
+	return c.GetBlockBenchmarksAsync(count).Receive()
+}
+
+// FutureGetBlockRepairsResult is a future promise to deliver the result of a
+// GetBlockRepairsAsync RPC invocation (or an applicable error).
+type FutureGetBlockRepairsResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the blocks
+// whose stored copies were found to be damaged.
+func (r FutureGetBlockRepairsResult) Receive() ([]btcjson.BlockRepairResult, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of getblockrepairs result objects.
+	var repairs []btcjson.BlockRepairResult
+	err = json.Unmarshal(res, &repairs)
+	if err != nil {
+		return nil, err
+	}
+
+	return repairs, nil
+}
+
+// GetBlockRepairsAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetBlockRepairs for the blocking version and more details.
+func (c *Client) GetBlockRepairsAsync() FutureGetBlockRepairsResult {
+	cmd := btcjson.NewGetBlockRepairsCmd()
+	return c.SendCmd(cmd)
+}
+
+// GetBlockRepairs returns the most recent blocks whose stored copies were found
+// to be damaged along with the status of their repairs.
+//
+// NOTE: This is a btcd extension.
+func (c *Client) GetBlockRepairs() ([]btcjson.BlockRepairResult, error) {
+	return c.GetBlockRepairsAsync().Receive()
+}
+
+// FutureGetIndexInfoResult is a future promise to deliver the result of a
+// GetIndexInfoAsync RPC invocation (or an applicable error).
+type FutureGetIndexInfoResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the state
+// of the enabled indexes keyed by their names.
+func (r FutureGetIndexInfoResult) Receive() (map[string]btcjson.GetIndexInfoResult, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a map of getindexinfo result objects.
+	var infos map[string]btcjson.GetIndexInfoResult
+	err = json.Unmarshal(res, &infos)
+	if err != nil {
+		return nil, err
+	}
+
+	return infos, nil
+}
+
+// GetIndexInfoAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GetIndexInfo for the blocking version and more details.
+func (c *Client) GetIndexInfoAsync(indexName *string) FutureGetIndexInfoResult {
+	cmd := btcjson.NewGetIndexInfoCmd(indexName)
+	return c.SendCmd(cmd)
+}
+
+// GetIndexInfo returns the state of the enabled indexes keyed by their names.
+// A nil index name returns all of them.
+func (c *Client) GetIndexInfo(indexName *string) (map[string]btcjson.GetIndexInfoResult, error) {
+	return c.GetIndexInfoAsync(indexName).Receive()
+}
+
+// FutureVerifyIndexResult is a future promise to deliver the result of a
+// VerifyIndexAsync or VerifyIndexBlocksAsync RPC invocation (or an applicable
+// error).
+type FutureVerifyIndexResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the
+// outcome of verifying the index.
+func (r FutureVerifyIndexResult) Receive() (*btcjson.VerifyIndexResult, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a verifyindex result object.
+	var result btcjson.VerifyIndexResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// VerifyIndexAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See VerifyIndex for the blocking version and more details.
+func (c *Client) VerifyIndexAsync(indexName string) FutureVerifyIndexResult {
+	cmd := btcjson.NewVerifyIndexCmd(indexName, nil)
+	return c.SendCmd(cmd)
+}
+
+// VerifyIndex requests the server to verify the entries of the index with the
+// passed name against the blocks in the database using the default number of
+// blocks to verify.
+//
+// See VerifyIndexBlocks to override the default.
+func (c *Client) VerifyIndex(indexName string) (*btcjson.VerifyIndexResult, error) {
+	return c.VerifyIndexAsync(indexName).Receive()
+}
+
+// VerifyIndexBlocksAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See VerifyIndexBlocks for the blocking version and more details.
+func (c *Client) VerifyIndexBlocksAsync(indexName string,
+	numBlocks int32) FutureVerifyIndexResult {
+
+	cmd := btcjson.NewVerifyIndexCmd(indexName, &numBlocks)
+	return c.SendCmd(cmd)
+}
+
+// VerifyIndexBlocks requests the server to verify the entries of the index with
+// the passed name for the passed number of blocks up to the last block which
+// was indexed.  Zero verifies all of them.
+func (c *Client) VerifyIndexBlocks(indexName string,
+	numBlocks int32) (*btcjson.VerifyIndexResult, error) {
+
+	return c.VerifyIndexBlocksAsync(indexName, numBlocks).Receive()
+}
+
+// FutureRebuildIndexResult is a future promise to deliver the result of a
+// RebuildIndexAsync RPC invocation (or an applicable error).
+type FutureRebuildIndexResult chan *Response
+
+// Receive waits for the Response promised by the future and returns an error
+// if the rebuild of the index couldn't be started.
+func (r FutureRebuildIndexResult) Receive() error {
+	_, err := ReceiveFuture(r)
+	return err
+}
+
+// RebuildIndexAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See RebuildIndex for the blocking version and more details.
+func (c *Client) RebuildIndexAsync(indexName string) FutureRebuildIndexResult {
+	cmd := btcjson.NewRebuildIndexCmd(indexName)
+	return c.SendCmd(cmd)
+}
+
+// RebuildIndex requests the server to drop the index with the passed name and
+// build it again in the background.  GetIndexInfo reports the index as synced
+// once it is rebuilt.
+func (c *Client) RebuildIndex(indexName string) error {
+	return c.RebuildIndexAsync(indexName).Receive()
+}
+
+// FutureGetDBInfoResult is a future promise to deliver the result of a
+// GetDBInfo RPC invocation (or an applicable error).
+type FutureGetDBInfoResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the disk
+// usage of the block database.
+func (r FutureGetDBInfoResult) Receive() (*btcjson.GetDBInfoResult, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a getdbinfo result object.
+	var info btcjson.GetDBInfoResult
+	err = json.Unmarshal(res, &info)
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// GetDBInfoAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GetDBInfo for the blocking version and more details.
+func (c *Client) GetDBInfoAsync() FutureGetDBInfoResult {
+	cmd := btcjson.NewGetDBInfoCmd()
+	return c.SendCmd(cmd)
+}
+
+// GetDBInfo returns the disk space used by the block files and the metadata of
+// the block database along with the approximate disk space used by each
+// top-level bucket of the metadata.
+//
+// NOTE: This is a btcd extension.
+func (c *Client) GetDBInfo() (*btcjson.GetDBInfoResult, error) {
+	return c.GetDBInfoAsync().Receive()
+}
+
+// FutureCompactDBResult is a future promise to deliver the result of a
+// CompactDB RPC invocation (or an applicable error).
+type FutureCompactDBResult chan *Response
+
+// Receive waits for the Response promised by the future and returns an error
+// if the compaction of the block database could not be started.
+func (r FutureCompactDBResult) Receive() error {
+	_, err := ReceiveFuture(r)
+	return err
+}
+
+// CompactDBAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See CompactDB for the blocking version and more details.
+func (c *Client) CompactDBAsync() FutureCompactDBResult {
+	cmd := btcjson.NewCompactDBCmd()
+	return c.SendCmd(cmd)
+}
+
+// CompactDB starts compacting the metadata of the block database in the
+// background.  GetDBInfo reports whether the compaction is still running.
+//
+// NOTE: This is a btcd extension.
+func (c *Client) CompactDB() error {
+	return c.CompactDBAsync().Receive()
+}
+
+// FutureDumpProfilesResult is a future promise to deliver the result of a
+// DumpProfiles RPC invocation (or an applicable error).
+type FutureDumpProfilesResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the paths
+// of the profiles written by the server.
+func (r FutureDumpProfilesResult) Receive() (*btcjson.DumpProfilesResult, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a dumpprofiles result object.
+	var result btcjson.DumpProfilesResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// DumpProfilesAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See DumpProfiles for the blocking version and more details.
+func (c *Client) DumpProfilesAsync(cpuSeconds *int) FutureDumpProfilesResult {
+	cmd := btcjson.NewDumpProfilesCmd(cpuSeconds)
+	return c.SendCmd(cmd)
+}
+
+// DumpProfiles writes heap and goroutine profiles to the data directory of the
+// server and starts writing a cpu profile for the passed number of seconds in
+// the background.  Passing nil uses the default of 30 seconds.
+//
+// NOTE: This is a btcd extension.
+func (c *Client) DumpProfiles(cpuSeconds *int) (*btcjson.DumpProfilesResult, error) {
+	return c.DumpProfilesAsync(cpuSeconds).Receive()
+}
+
+// FutureGetBlockRangeResult is a future promise to deliver the result of a
+// GetBlockRange RPC invocation (or an applicable error).
+type FutureGetBlockRangeResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the
+// serialized blocks of the requested range along with the outputs they spend.
+func (r FutureGetBlockRangeResult) Receive() ([]btcjson.GetBlockRangeResult, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of blocks.
+	var blocks []btcjson.GetBlockRangeResult
+	err = json.Unmarshal(res, &blocks)
+	if err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+// GetBlockRangeAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GetBlockRange for the blocking version and more details.
+func (c *Client) GetBlockRangeAsync(startHeight, endHeight int32) FutureGetBlockRangeResult {
+	cmd := btcjson.NewGetBlockRangeCmd(startHeight, endHeight)
+	return c.SendCmd(cmd)
+}
+
+// GetBlockRange returns the serialized blocks of the main chain from the start
+// height up to and including the end height along with the outputs spent by
+// their transactions.
+//
+// NOTE: This is a btcd extension.
+func (c *Client) GetBlockRange(startHeight, endHeight int32) ([]btcjson.GetBlockRangeResult, error) {
+	return c.GetBlockRangeAsync(startHeight, endHeight).Receive()
+}
+
+// FutureGetCheckpointsResult is a future promise to deliver the result of a
+// GetCheckpoints RPC invocation (or an applicable error).
+type FutureGetCheckpointsResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the
+// checkpoints which are enforced by the node.
+func (r FutureGetCheckpointsResult) Receive() ([]*btcjson.GetCheckpointsResult, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of checkpoints.
+	var checkpoints []*btcjson.GetCheckpointsResult
+	err = json.Unmarshal(res, &checkpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	return checkpoints, nil
+}
+
+// GetCheckpointsAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GetCheckpoints for the blocking version and more details.
+func (c *Client) GetCheckpointsAsync() FutureGetCheckpointsResult {
+	cmd := btcjson.NewGetCheckpointsCmd()
+	return c.SendCmd(cmd)
+}
+
+// GetCheckpoints returns the checkpoints which are enforced by the node ordered
+// by height, including those added with the addcheckpoint and checkpointfile
+// options of btcd.
+func (c *Client) GetCheckpoints() ([]*btcjson.GetCheckpointsResult, error) {
+	return c.GetCheckpointsAsync().Receive()
+}
+
 // FutureGetMempoolEntryResult is a future promise to deliver the result of a
 // GetMempoolEntryAsync RPC invocation (or an applicable error).
 type FutureGetMempoolEntryResult chan *Response
@@ -1057,7 +1496,7 @@ func (r FutureGetTxOutSetInfoResult) Receive() (*btcjson.GetTxOutSetInfoResult,
 //
 // See GetTxOutSetInfo for the blocking version and more details.
 func (c *Client) GetTxOutSetInfoAsync() FutureGetTxOutSetInfoResult {
-	cmd := btcjson.NewGetTxOutSetInfoCmd()
+	cmd := btcjson.NewGetTxOutSetInfoCmd(nil)
 	return c.SendCmd(cmd)
 }
 
@@ -1067,6 +1506,25 @@ func (c *Client) GetTxOutSetInfo() (*btcjson.GetTxOutSetInfoResult, error) {
 	return c.GetTxOutSetInfoAsync().Receive()
 }
 
+// GetTxOutSetInfoVerboseAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetTxOutSetInfoVerbose for the blocking version and more details.
+func (c *Client) GetTxOutSetInfoVerboseAsync() FutureGetTxOutSetInfoResult {
+	cmd := btcjson.NewGetTxOutSetInfoCmd(btcjson.Bool(true))
+	return c.SendCmd(cmd)
+}
+
+// GetTxOutSetInfoVerbose returns the statistics about the unspent transaction
+// output set including the number and value of the unspent outputs of each
+// script class.
+//
+// NOTE: This is a btcd extension.
+func (c *Client) GetTxOutSetInfoVerbose() (*btcjson.GetTxOutSetInfoResult, error) {
+	return c.GetTxOutSetInfoVerboseAsync().Receive()
+}
+
 // FutureGetTxOutProofResult is a future promise to deliver the result of a
 // GetTxOutProofAsync RPC invocation (or an applicable error).
 type FutureGetTxOutProofResult chan *Response
@@ -113,6 +113,52 @@ func (c *Client) GenerateToAddress(numBlocks int64,
 	return c.GenerateToAddressAsync(numBlocks, address, maxTries).Receive()
 }
 
+// FutureGenerateBlockResult is a future promise to deliver the result of a
+// GenerateBlockAsync RPC invocation (or an applicable error).
+type FutureGenerateBlockResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the hash
+// of the generated block.
+func (f FutureGenerateBlockResult) Receive() (*chainhash.Hash, error) {
+	res, err := ReceiveFuture(f)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a generateblock result object.
+	var result btcjson.GenerateBlockResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return chainhash.NewHashFromStr(result.Hash)
+}
+
+// GenerateBlockAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GenerateBlock for the blocking version and more details.
+func (c *Client) GenerateBlockAsync(address address.Address,
+	transactions []string) FutureGenerateBlockResult {
+
+	cmd := btcjson.NewGenerateBlockCmd(address.EncodeAddress(), transactions)
+	return c.SendCmd(cmd)
+}
+
+// GenerateBlock generates a block which pays to the given address and contains
+// exactly the given transactions in order, and returns its hash.  Each
+// transaction is either the hash of a transaction in the memory pool or a
+// serialized, hex-encoded transaction.
+//
+// NOTE: This is a btcd extension.
+func (c *Client) GenerateBlock(address address.Address,
+	transactions []string) (*chainhash.Hash, error) {
+
+	return c.GenerateBlockAsync(address, transactions).Receive()
+}
+
 // FutureGetGenerateResult is a future promise to deliver the result of a
 // GetGenerateAsync RPC invocation (or an applicable error).
 type FutureGetGenerateResult chan *Response
@@ -176,6 +222,37 @@ func (c *Client) SetGenerate(enable bool, numCPUs int) error {
 	return c.SetGenerateAsync(enable, numCPUs).Receive()
 }
 
+// FutureSetSignalBitResult is a future promise to deliver the result of a
+// SetSignalBitAsync RPC invocation (or an applicable error).
+type FutureSetSignalBitResult chan *Response
+
+// Receive waits for the Response promised by the future and returns an error if
+// any occurred when setting whether or not to signal the version bit.
+func (r FutureSetSignalBitResult) Receive() error {
+	_, err := ReceiveFuture(r)
+	return err
+}
+
+// SetSignalBitAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See SetSignalBit for the blocking version and more details.
+//
+// NOTE: This is a btcd extension.
+func (c *Client) SetSignalBitAsync(bit uint32, signal bool) FutureSetSignalBitResult {
+	cmd := btcjson.NewSetSignalBitCmd(bit, signal)
+	return c.SendCmd(cmd)
+}
+
+// SetSignalBit sets whether or not the server signals the given version bit in
+// the blocks it generates.
+//
+// NOTE: This is a btcd extension.
+func (c *Client) SetSignalBit(bit uint32, signal bool) error {
+	return c.SetSignalBitAsync(bit, signal).Receive()
+}
+
 // FutureGetHashesPerSecResult is a future promise to deliver the result of a
 // GetHashesPerSecAsync RPC invocation (or an applicable error).
 type FutureGetHashesPerSecResult chan *Response
@@ -502,3 +579,54 @@ func (c *Client) GetBlockTemplateAsync(req *btcjson.TemplateRequest) FutureGetBl
 func (c *Client) GetBlockTemplate(req *btcjson.TemplateRequest) (*btcjson.GetBlockTemplateResult, error) {
 	return c.GetBlockTemplateAsync(req).Receive()
 }
+
+// FutureProposeBlockResult is a future promise to deliver the result of a
+// ProposeBlockAsync RPC invocation (or an applicable error).
+type FutureProposeBlockResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the reason
+// the proposed block was rejected, or an empty string when it was accepted.
+func (r FutureProposeBlockResult) Receive() (string, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return "", err
+	}
+
+	if string(res) == "null" {
+		return "", nil
+	}
+
+	var reason string
+	err = json.Unmarshal(res, &reason)
+	if err != nil {
+		return "", err
+	}
+
+	return reason, nil
+}
+
+// ProposeBlockAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See ProposeBlock for the blocking version and more details.
+func (c *Client) ProposeBlockAsync(block *btcutil.Block) FutureProposeBlockResult {
+	blockBytes, err := block.Bytes()
+	if err != nil {
+		return newFutureError(err)
+	}
+
+	cmd := btcjson.NewGetBlockTemplateCmd(&btcjson.TemplateRequest{
+		Mode: "proposal",
+		Data: hex.EncodeToString(blockBytes),
+	})
+	return c.SendCmd(cmd)
+}
+
+// ProposeBlock submits the passed block to the getblocktemplate RPC in
+// proposal mode so it is fully validated without being relayed to the network.
+// The reason the block was rejected is returned, or an empty string when it
+// would be accepted.
+func (c *Client) ProposeBlock(block *btcutil.Block) (string, error) {
+	return c.ProposeBlockAsync(block).Receive()
+}
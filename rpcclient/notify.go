@@ -122,6 +122,13 @@ type NotificationHandlers struct {
 	// OnBlockDisconnected: it receives the block's height and header.
 	OnFilteredBlockDisconnected func(height int32, header *wire.BlockHeader)
 
+	// OnReorganization is invoked when the longest (best) chain has been
+	// reorganized, after the notifications for the disconnected and
+	// connected blocks.  It will only be invoked if a preceding call to
+	// NotifyBlocks has been made to register for the notification and the
+	// function is non-nil.
+	OnReorganization func(reorg *btcjson.ReorganizationResult)
+
 	// OnRecvTx is invoked when a transaction that receives funds to a
 	// registered address is received into the memory pool and also
 	// connected to the longest (best) chain.  It will only be invoked if a
@@ -291,6 +298,23 @@ func (c *Client) handleNotification(ntfn *rawNotification) {
 		c.ntfnHandlers.OnFilteredBlockDisconnected(blockHeight,
 			blockHeader)
 
+	// OnReorganization
+	case btcjson.ReorganizationNtfnMethod:
+		// Ignore the notification if the client is not interested in
+		// it.
+		if c.ntfnHandlers.OnReorganization == nil {
+			return
+		}
+
+		reorg, err := parseReorganizationNtfnParams(ntfn.Params)
+		if err != nil {
+			log.Warnf("Received invalid reorganization notification: "+
+				"%v", err)
+			return
+		}
+
+		c.ntfnHandlers.OnReorganization(reorg)
+
 	// OnRecvTx
 	case btcjson.RecvTxNtfnMethod:
 		// Ignore the notification if the client is not interested in
@@ -616,6 +640,25 @@ func parseFilteredBlockDisconnectedParams(params []json.RawMessage) (int32,
 	return blockHeight, &blockHeader, nil
 }
 
+// parseReorganizationNtfnParams parses out the details of a reorganization from
+// the parameters of a reorganization notification.
+func parseReorganizationNtfnParams(params []json.RawMessage) (
+	*btcjson.ReorganizationResult, error) {
+
+	if len(params) != 1 {
+		return nil, wrongNumParams(len(params))
+	}
+
+	// Unmarshal first parameter as a reorganization result object.
+	var reorg btcjson.ReorganizationResult
+	err := json.Unmarshal(params[0], &reorg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &reorg, nil
+}
+
 func parseHexParam(param json.RawMessage) ([]byte, error) {
 	var s string
 	err := json.Unmarshal(param, &s)
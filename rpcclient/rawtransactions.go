@@ -853,6 +853,250 @@ func (c *Client) SearchRawTransactionsVerbose(address address.Address, skip,
 		includePrevOut, reverse, &filterAddrs).Receive()
 }
 
+// encodeAddresses returns the encoded forms of the passed addresses.
+func encodeAddresses(addrs []address.Address) []string {
+	encoded := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		encoded = append(encoded, addr.EncodeAddress())
+	}
+	return encoded
+}
+
+// FutureGetAddressTxIDsResult is a future promise to deliver the result of a
+// GetAddressTxIDsAsync RPC invocation (or an applicable error).
+type FutureGetAddressTxIDsResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the hashes
+// of the transactions which involve the requested addresses.
+func (r FutureGetAddressTxIDsResult) Receive() ([]*chainhash.Hash, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal as an array of strings.
+	var txids []string
+	err = json.Unmarshal(res, &txids)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]*chainhash.Hash, 0, len(txids))
+	for _, txid := range txids {
+		hash, err := chainhash.NewHashFromStr(txid)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, nil
+}
+
+// GetAddressTxIDsAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetAddressTxIDs for the blocking version and more details.
+func (c *Client) GetAddressTxIDsAsync(addrs []address.Address, start,
+	end *int32) FutureGetAddressTxIDsResult {
+
+	cmd := btcjson.NewGetAddressTxIDsCmd(encodeAddresses(addrs), start, end)
+	return c.SendCmd(cmd)
+}
+
+// GetAddressTxIDs returns the hashes of the confirmed transactions which
+// involve the passed addresses in chain order, optionally limited to the blocks
+// within the passed range of heights.
+//
+// NOTE: This is a btcd extension and requires the address index.
+func (c *Client) GetAddressTxIDs(addrs []address.Address, start,
+	end *int32) ([]*chainhash.Hash, error) {
+
+	return c.GetAddressTxIDsAsync(addrs, start, end).Receive()
+}
+
+// FutureGetAddressBalanceResult is a future promise to deliver the result of a
+// GetAddressBalanceAsync RPC invocation (or an applicable error).
+type FutureGetAddressBalanceResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the
+// balance of the requested addresses.
+func (r FutureGetAddressBalanceResult) Receive() (*btcjson.GetAddressBalanceResult, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a getaddressbalance result object.
+	var balance btcjson.GetAddressBalanceResult
+	err = json.Unmarshal(res, &balance)
+	if err != nil {
+		return nil, err
+	}
+
+	return &balance, nil
+}
+
+// GetAddressBalanceAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetAddressBalance for the blocking version and more details.
+func (c *Client) GetAddressBalanceAsync(addrs []address.Address) FutureGetAddressBalanceResult {
+	cmd := btcjson.NewGetAddressBalanceCmd(encodeAddresses(addrs))
+	return c.SendCmd(cmd)
+}
+
+// GetAddressBalance returns the confirmed balance of the passed addresses and
+// the total amount they received.
+//
+// NOTE: This is a btcd extension and requires the address index.
+func (c *Client) GetAddressBalance(addrs []address.Address) (*btcjson.GetAddressBalanceResult, error) {
+	return c.GetAddressBalanceAsync(addrs).Receive()
+}
+
+// FutureGetAddressUtxosResult is a future promise to deliver the result of a
+// GetAddressUtxosAsync RPC invocation (or an applicable error).
+type FutureGetAddressUtxosResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the
+// unspent outputs paying to the requested addresses along with the best block
+// they are current as of.
+func (r FutureGetAddressUtxosResult) Receive() (*btcjson.GetAddressUtxosChainInfoResult, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a getaddressutxos result object.
+	var utxos btcjson.GetAddressUtxosChainInfoResult
+	err = json.Unmarshal(res, &utxos)
+	if err != nil {
+		return nil, err
+	}
+
+	return &utxos, nil
+}
+
+// GetAddressUtxosAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetAddressUtxos for the blocking version and more details.
+func (c *Client) GetAddressUtxosAsync(addrs []address.Address) FutureGetAddressUtxosResult {
+	cmd := btcjson.NewGetAddressUtxosCmd(encodeAddresses(addrs),
+		btcjson.Bool(true))
+	return c.SendCmd(cmd)
+}
+
+// GetAddressUtxos returns the confirmed unspent outputs paying to the passed
+// addresses in chain order along with the best block they are current as of.
+//
+// NOTE: This is a btcd extension and requires the address index.
+func (c *Client) GetAddressUtxos(addrs []address.Address) (*btcjson.GetAddressUtxosChainInfoResult, error) {
+	return c.GetAddressUtxosAsync(addrs).Receive()
+}
+
+// FutureGetSpentInfoResult is a future promise to deliver the result of a
+// GetSpentInfoAsync RPC invocation (or an applicable error).
+type FutureGetSpentInfoResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the input
+// which spent the requested output.
+func (r FutureGetSpentInfoResult) Receive() (*btcjson.GetSpentInfoResult, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a getspentinfo result object.
+	var info btcjson.GetSpentInfoResult
+	err = json.Unmarshal(res, &info)
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// GetSpentInfoAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GetSpentInfo for the blocking version and more details.
+func (c *Client) GetSpentInfoAsync(outPoint *wire.OutPoint) FutureGetSpentInfoResult {
+	cmd := btcjson.NewGetSpentInfoCmd(outPoint.Hash.String(), outPoint.Index)
+	return c.SendCmd(cmd)
+}
+
+// GetSpentInfo returns the input which spent the passed output.  The height of
+// the result is -1 when the output is spent by a transaction in the memory
+// pool.
+//
+// NOTE: This is a btcd extension and requires the spent output index.
+func (c *Client) GetSpentInfo(outPoint *wire.OutPoint) (*btcjson.GetSpentInfoResult, error) {
+	return c.GetSpentInfoAsync(outPoint).Receive()
+}
+
+// FutureGetClaimHistoryResult is a future promise to deliver the result of a
+// GetClaimHistoryAsync or GetNameHistoryAsync RPC invocation (or an applicable
+// error).
+type FutureGetClaimHistoryResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the claim,
+// support and update outputs of the requested claim or name.
+func (r FutureGetClaimHistoryResult) Receive() ([]btcjson.ClaimHistoryResult, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of claim history result objects.
+	var history []btcjson.ClaimHistoryResult
+	err = json.Unmarshal(res, &history)
+	if err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// GetClaimHistoryAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetClaimHistory for the blocking version and more details.
+func (c *Client) GetClaimHistoryAsync(claimID []byte) FutureGetClaimHistoryResult {
+	cmd := btcjson.NewGetClaimHistoryCmd(hex.EncodeToString(claimID))
+	return c.SendCmd(cmd)
+}
+
+// GetClaimHistory returns the claim, support and update outputs of the passed
+// claim in the main chain, oldest first.
+//
+// NOTE: This is a btcd extension and requires the claim index.
+func (c *Client) GetClaimHistory(claimID []byte) ([]btcjson.ClaimHistoryResult, error) {
+	return c.GetClaimHistoryAsync(claimID).Receive()
+}
+
+// GetNameHistoryAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GetNameHistory for the blocking version and more details.
+func (c *Client) GetNameHistoryAsync(name string) FutureGetClaimHistoryResult {
+	cmd := btcjson.NewGetNameHistoryCmd(name)
+	return c.SendCmd(cmd)
+}
+
+// GetNameHistory returns the claim, support and update outputs for the passed
+// name in the main chain, oldest first.
+//
+// NOTE: This is a btcd extension and requires the claim index.
+func (c *Client) GetNameHistory(name string) ([]btcjson.ClaimHistoryResult, error) {
+	return c.GetNameHistoryAsync(name).Receive()
+}
+
 // FutureDecodeScriptResult is a future promise to deliver the result
 // of a DecodeScriptAsync RPC invocation (or an applicable error).
 type FutureDecodeScriptResult chan *Response
@@ -1193,3 +1437,195 @@ func (c *Client) GetTxSpendingPrevOut(outpoints []wire.OutPoint) (
 
 	return c.GetTxSpendingPrevOutAsync(outpoints).Receive()
 }
+
+// FuturePsbtResult is a future promise to deliver the result of an RPC
+// invocation which returns a base64-encoded PSBT (or an applicable error).
+type FuturePsbtResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the
+// base64-encoded PSBT.
+func (r FuturePsbtResult) Receive() (string, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return "", err
+	}
+
+	// Unmarshal result as a string.
+	var b64Psbt string
+	err = json.Unmarshal(res, &b64Psbt)
+	if err != nil {
+		return "", err
+	}
+
+	return b64Psbt, nil
+}
+
+// FutureDecodePsbtResult is a future promise to deliver the result of a
+// DecodePsbtAsync RPC invocation (or an applicable error).
+type FutureDecodePsbtResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the
+// decoded PSBT.
+func (r FutureDecodePsbtResult) Receive() (*btcjson.DecodePsbtResult, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a decodepsbt result object.
+	var result btcjson.DecodePsbtResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// DecodePsbtAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See DecodePsbt for the blocking version and more details.
+func (c *Client) DecodePsbtAsync(b64Psbt string) FutureDecodePsbtResult {
+	cmd := btcjson.NewDecodePsbtCmd(b64Psbt)
+	return c.SendCmd(cmd)
+}
+
+// DecodePsbt returns information about the passed base64-encoded PSBT.
+func (c *Client) DecodePsbt(b64Psbt string) (*btcjson.DecodePsbtResult, error) {
+	return c.DecodePsbtAsync(b64Psbt).Receive()
+}
+
+// CombinePsbtAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See CombinePsbt for the blocking version and more details.
+func (c *Client) CombinePsbtAsync(b64Psbts []string) FuturePsbtResult {
+	cmd := btcjson.NewCombinePsbtCmd(b64Psbts)
+	return c.SendCmd(cmd)
+}
+
+// CombinePsbt combines the passed base64-encoded PSBTs for the same
+// transaction into one and returns it base64 encoded.
+func (c *Client) CombinePsbt(b64Psbts []string) (string, error) {
+	return c.CombinePsbtAsync(b64Psbts).Receive()
+}
+
+// ConvertToPsbtAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See ConvertToPsbt for the blocking version and more details.
+func (c *Client) ConvertToPsbtAsync(tx *wire.MsgTx,
+	permitSigData bool) FuturePsbtResult {
+
+	// Serialize the transaction and convert to hex string.
+	buf := bytes.NewBuffer(make([]byte, 0, tx.SerializeSize()))
+	if err := tx.Serialize(buf); err != nil {
+		return newFutureError(err)
+	}
+	txHex := hex.EncodeToString(buf.Bytes())
+
+	cmd := btcjson.NewConvertToPsbtCmd(txHex, &permitSigData, nil)
+	return c.SendCmd(cmd)
+}
+
+// ConvertToPsbt converts the passed transaction to a PSBT and returns it base64
+// encoded.  The transaction must not have any signature scripts or witnesses
+// unless permitSigData is true, in which case they are dropped.
+func (c *Client) ConvertToPsbt(tx *wire.MsgTx, permitSigData bool) (string, error) {
+	return c.ConvertToPsbtAsync(tx, permitSigData).Receive()
+}
+
+// FutureFinalizePsbtResult is a future promise to deliver the result of a
+// FinalizePsbtAsync RPC invocation (or an applicable error).
+type FutureFinalizePsbtResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the
+// finalized PSBT or the extracted transaction.
+func (r FutureFinalizePsbtResult) Receive() (*btcjson.FinalizePsbtResult, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a finalizepsbt result object.
+	var result btcjson.FinalizePsbtResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// FinalizePsbtAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See FinalizePsbt for the blocking version and more details.
+func (c *Client) FinalizePsbtAsync(b64Psbt string,
+	extract bool) FutureFinalizePsbtResult {
+
+	cmd := btcjson.NewFinalizePsbtCmd(b64Psbt, &extract)
+	return c.SendCmd(cmd)
+}
+
+// FinalizePsbt finalizes the inputs of the passed base64-encoded PSBT which
+// have all the data they need.  When all inputs are finalized and extract is
+// true, the signed transaction is returned instead of the PSBT.
+func (c *Client) FinalizePsbt(b64Psbt string,
+	extract bool) (*btcjson.FinalizePsbtResult, error) {
+
+	return c.FinalizePsbtAsync(b64Psbt, extract).Receive()
+}
+
+// FutureTraceScriptResult is a future promise to deliver the result of a
+// TraceScriptAsync RPC invocation (or an applicable error).
+type FutureTraceScriptResult chan *Response
+
+// Receive waits for the Response promised by the future and returns the trace
+// of the execution of the scripts of the input.
+func (r FutureTraceScriptResult) Receive() (*btcjson.TraceScriptResult, error) {
+	res, err := ReceiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a tracescript result object.
+	var result btcjson.TraceScriptResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// TraceScriptAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See TraceScript for the blocking version and more details.
+func (c *Client) TraceScriptAsync(tx *wire.MsgTx, vin uint32) FutureTraceScriptResult {
+	// Serialize the transaction and convert to hex string.
+	buf := bytes.NewBuffer(make([]byte, 0, tx.SerializeSize()))
+	if err := tx.Serialize(buf); err != nil {
+		return newFutureError(err)
+	}
+	txHex := hex.EncodeToString(buf.Bytes())
+
+	cmd := btcjson.NewTraceScriptCmd(txHex, vin)
+	return c.SendCmd(cmd)
+}
+
+// TraceScript executes the scripts of the input of the passed transaction at
+// the passed index and returns the state of the stacks before each opcode is
+// executed along with whether or not the input is valid.
+//
+// NOTE: This is a btcd extension.
+func (c *Client) TraceScript(tx *wire.MsgTx, vin uint32) (*btcjson.TraceScriptResult, error) {
+	return c.TraceScriptAsync(tx, vin).Receive()
+}
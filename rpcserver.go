@@ -10,16 +10,20 @@ import (
 	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"math/big"
 	"math/rand"
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -27,18 +31,24 @@ import (
 	"time"
 
 	"github.com/btcsuite/btcd/address/v2"
+	"github.com/btcsuite/btcd/address/v2/base58"
 	"github.com/btcsuite/btcd/blockchain"
 	"github.com/btcsuite/btcd/blockchain/indexers"
 	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/btcutil/v2"
+	"github.com/btcsuite/btcd/btcutil/v2/hdkeychain"
 	"github.com/btcsuite/btcd/chaincfg/v2"
 	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/connmgr"
 	"github.com/btcsuite/btcd/database"
+	"github.com/btcsuite/btcd/database/ffldb"
 	"github.com/btcsuite/btcd/mempool"
 	"github.com/btcsuite/btcd/mining"
 	"github.com/btcsuite/btcd/mining/cpuminer"
+	"github.com/btcsuite/btcd/mining/stratum"
 	"github.com/btcsuite/btcd/peer"
+	"github.com/btcsuite/btcd/psbt/v2"
 	"github.com/btcsuite/btcd/txscript/v2"
 	"github.com/btcsuite/btcd/wire/v2"
 	"github.com/btcsuite/websocket"
@@ -73,6 +83,23 @@ const (
 	// in the memory pool.
 	gbtRegenerateSeconds = 60
 
+	// gbtMinRegenerateSeconds is the number of seconds that must pass
+	// before a new template is generated when the previous block hash has
+	// not changed, but the transactions which arrived in the memory pool
+	// significantly increase the fees available to miners.  It is also the
+	// interval long poll clients check for such changes.
+	gbtMinRegenerateSeconds = 5
+
+	// gbtSignificantFeePercent is the percentage of the fees of the current
+	// template the fees of the transactions which arrived in the memory
+	// pool since it was generated must reach for the change to be
+	// considered significant.
+	gbtSignificantFeePercent = 10
+
+	// maxGetBlockRangeBlocks is the maximum number of blocks the
+	// getblockrange RPC returns at once.
+	maxGetBlockRangeBlocks = 100
+
 	// maxProtocolVersion is the max protocol version the server supports.
 	maxProtocolVersion = 70002
 
@@ -90,6 +117,15 @@ var (
 		"time", "transactions/add", "prevblock", "coinbase/append",
 	}
 
+	// gbtCoinbaseTxnMutableFields are the manipulations the server allows
+	// to be made to block templates which include a coinbase transaction.
+	// Outputs may be added to the coinbase in addition to the allowed
+	// manipulations of all templates.
+	gbtCoinbaseTxnMutableFields = []string{
+		"time", "transactions/add", "prevblock", "coinbase/append",
+		"generation",
+	}
+
 	// gbtCoinbaseAux describes additional data that miners should include
 	// in the coinbase signature script.  It is declared here to avoid the
 	// overhead of creating a new object on every invocation for constant
@@ -135,54 +171,91 @@ type commandHandler func(*rpcServer, interface{}, <-chan struct{}) (interface{},
 var rpcHandlers map[string]commandHandler
 var rpcHandlersBeforeInit = map[string]commandHandler{
 	"addnode":                handleAddNode,
+	"clearbanned":            handleClearBanned,
+	"combinepsbt":            handleCombinePsbt,
+	"compactdb":              handleCompactDB,
+	"converttopsbt":          handleConvertToPsbt,
 	"createrawtransaction":   handleCreateRawTransaction,
 	"debuglevel":             handleDebugLevel,
+	"decodepsbt":             handleDecodePsbt,
 	"decoderawtransaction":   handleDecodeRawTransaction,
 	"decodescript":           handleDecodeScript,
+	"dumpprofiles":           handleDumpProfiles,
 	"estimatefee":            handleEstimateFee,
+	"estimatesmartfee":       handleEstimateSmartFee,
+	"finalizepsbt":           handleFinalizePsbt,
 	"generate":               handleGenerate,
+	"generateblock":          handleGenerateBlock,
+	"generatetoaddress":      handleGenerateToAddress,
 	"getaddednodeinfo":       handleGetAddedNodeInfo,
+	"getaddressbalance":      handleGetAddressBalance,
+	"getaddresstxids":        handleGetAddressTxIDs,
+	"getaddressutxos":        handleGetAddressUtxos,
 	"getbestblock":           handleGetBestBlock,
 	"getbestblockhash":       handleGetBestBlockHash,
 	"getblock":               handleGetBlock,
 	"getblockchaininfo":      handleGetBlockChainInfo,
 	"getblockcount":          handleGetBlockCount,
+	"getblockbenchmarks":     handleGetBlockBenchmarks,
 	"getblockhash":           handleGetBlockHash,
 	"getblockheader":         handleGetBlockHeader,
+	"getblockrange":          handleGetBlockRange,
+	"getblockrepairs":        handleGetBlockRepairs,
 	"getblocktemplate":       handleGetBlockTemplate,
 	"getchaintips":           handleGetChainTips,
 	"getcfilter":             handleGetCFilter,
 	"getcfilterheader":       handleGetCFilterHeader,
+	"getcheckpoints":         handleGetCheckpoints,
+	"getclaimhistory":        handleGetClaimHistory,
+	"getconflicts":           handleGetConflicts,
 	"getconnectioncount":     handleGetConnectionCount,
+	"getconnectiontargets":   handleGetConnectionTargets,
 	"getcurrentnet":          handleGetCurrentNet,
+	"getdbinfo":              handleGetDBInfo,
+	"getdeploymentinfo":      handleGetDeploymentInfo,
 	"getdifficulty":          handleGetDifficulty,
 	"getgenerate":            handleGetGenerate,
 	"gethashespersec":        handleGetHashesPerSec,
 	"getheaders":             handleGetHeaders,
+	"getindexinfo":           handleGetIndexInfo,
 	"getinfo":                handleGetInfo,
 	"getmempoolinfo":         handleGetMempoolInfo,
 	"getmininginfo":          handleGetMiningInfo,
+	"getnamehistory":         handleGetNameHistory,
+	"getnatinfo":             handleGetNATInfo,
 	"getnettotals":           handleGetNetTotals,
 	"getnetworkhashps":       handleGetNetworkHashPS,
 	"getnodeaddresses":       handleGetNodeAddresses,
 	"getpeerinfo":            handleGetPeerInfo,
 	"getrawmempool":          handleGetRawMempool,
 	"getrawtransaction":      handleGetRawTransaction,
+	"getspentinfo":           handleGetSpentInfo,
+	"getstratuminfo":         handleGetStratumInfo,
 	"gettxout":               handleGetTxOut,
+	"gettxoutsetinfo":        handleGetTxOutSetInfo,
 	"help":                   handleHelp,
+	"importblocks":           handleImportBlocks,
 	"invalidateblock":        handleInvalidateBlock,
+	"listbanned":             handleListBanned,
 	"node":                   handleNode,
 	"ping":                   handlePing,
+	"pruneblockchain":        handlePruneBlockchain,
+	"rebuildindex":           handleRebuildIndex,
 	"reconsiderblock":        handleReconsiderBlock,
 	"searchrawtransactions":  handleSearchRawTransactions,
 	"sendrawtransaction":     handleSendRawTransaction,
+	"setban":                 handleSetBan,
+	"setconnectiontargets":   handleSetConnectionTargets,
 	"setgenerate":            handleSetGenerate,
+	"setsignalbit":           handleSetSignalBit,
 	"signmessagewithprivkey": handleSignMessageWithPrivKey,
 	"stop":                   handleStop,
 	"submitblock":            handleSubmitBlock,
+	"tracescript":            handleTraceScript,
 	"uptime":                 handleUptime,
 	"validateaddress":        handleValidateAddress,
 	"verifychain":            handleVerifyChain,
+	"verifyindex":            handleVerifyIndex,
 	"verifymessage":          handleVerifyMessage,
 	"version":                handleVersion,
 	"testmempoolaccept":      handleTestMempoolAccept,
@@ -209,7 +282,6 @@ var rpcAskWallet = map[string]struct{}{
 	"getreceivedbyaccount":   {},
 	"getreceivedbyaddress":   {},
 	"gettransaction":         {},
-	"gettxoutsetinfo":        {},
 	"getunconfirmedbalance":  {},
 	"getwalletinfo":          {},
 	"importprivkey":          {},
@@ -262,27 +334,46 @@ var rpcLimited = map[string]struct{}{
 	"help": {},
 
 	// HTTP/S-only commands
+	"combinepsbt":           {},
+	"converttopsbt":         {},
 	"createrawtransaction":  {},
+	"decodepsbt":            {},
 	"decoderawtransaction":  {},
 	"decodescript":          {},
 	"estimatefee":           {},
+	"estimatesmartfee":      {},
+	"finalizepsbt":          {},
+	"getaddressbalance":     {},
+	"getaddresstxids":       {},
+	"getaddressutxos":       {},
 	"getbestblock":          {},
 	"getbestblockhash":      {},
 	"getblock":              {},
 	"getblockcount":         {},
+	"getblockbenchmarks":    {},
 	"getblockhash":          {},
 	"getblockheader":        {},
+	"getblockrange":         {},
+	"getblockrepairs":       {},
 	"getchaintips":          {},
 	"getcfilter":            {},
 	"getcfilterheader":      {},
+	"getcheckpoints":        {},
+	"getclaimhistory":       {},
+	"getconflicts":          {},
+	"getconnectiontargets":  {},
 	"getcurrentnet":         {},
+	"getdbinfo":             {},
 	"getdifficulty":         {},
 	"getheaders":            {},
+	"getindexinfo":          {},
 	"getinfo":               {},
+	"getnamehistory":        {},
 	"getnettotals":          {},
 	"getnetworkhashps":      {},
 	"getrawmempool":         {},
 	"getrawtransaction":     {},
+	"getspentinfo":          {},
 	"gettxout":              {},
 	"invalidateblock":       {},
 	"reconsiderblock":       {},
@@ -344,6 +435,7 @@ type gbtWorkState struct {
 	sync.Mutex
 	lastTxUpdate  time.Time
 	lastGenerated time.Time
+	newFees       int64
 	prevHash      *chainhash.Hash
 	minTimestamp  time.Time
 	template      *mining.BlockTemplate
@@ -393,7 +485,20 @@ func handleAddNode(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (in
 		}
 	}
 
-	if err != nil {
+	switch {
+	case errors.Is(err, errNodeAlreadyAdded):
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCClientNodeAlreadyAdded,
+			Message: "Node already added",
+		}
+
+	case errors.Is(err, errNodeNotAdded):
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCClientNodeNotAdded,
+			Message: "Node has not been added",
+		}
+
+	case err != nil:
 		return nil, &btcjson.RPCError{
 			Code:    btcjson.ErrRPCInvalidParameter,
 			Message: err.Error(),
@@ -522,6 +627,171 @@ func messageToHex(msg wire.Message) (string, error) {
 	return hex.EncodeToString(buf.Bytes()), nil
 }
 
+// handleClearBanned implements the clearbanned command.
+func handleClearBanned(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	s.cfg.ConnMgr.ClearBanned()
+	return nil, nil
+}
+
+// decodePsbt decodes the passed base64-encoded PSBT.
+func decodePsbt(b64Psbt string) (*psbt.Packet, error) {
+	packet, err := psbt.NewFromRawBytes(strings.NewReader(b64Psbt), true)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCDeserialization,
+			Message: "PSBT decode failed: " + err.Error(),
+		}
+	}
+	return packet, nil
+}
+
+// encodePsbt returns the base64 encoding of the passed PSBT.
+func encodePsbt(packet *psbt.Packet) (string, error) {
+	b64Psbt, err := packet.B64Encode()
+	if err != nil {
+		context := "Failed to encode PSBT"
+		return "", internalRPCError(err.Error(), context)
+	}
+	return b64Psbt, nil
+}
+
+// handleCombinePsbt handles combinepsbt commands.
+func handleCombinePsbt(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.CombinePsbtCmd)
+
+	if len(c.Psbts) == 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "No PSBTs to combine",
+		}
+	}
+	packets := make([]*psbt.Packet, 0, len(c.Psbts))
+	for _, b64Psbt := range c.Psbts {
+		packet, err := decodePsbt(b64Psbt)
+		if err != nil {
+			return nil, err
+		}
+		packets = append(packets, packet)
+	}
+
+	combined, err := psbt.Combine(packets...)
+	if errors.Is(err, psbt.ErrDifferentTransactions) {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "PSBTs not compatible (different transactions)",
+		}
+	}
+	if err != nil {
+		context := "Failed to combine PSBTs"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	return encodePsbt(combined)
+}
+
+// handleCompactDB implements the compactdb command.
+func handleCompactDB(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	// Ensure the database supports being compacted before starting.
+	if _, err := ffldb.DiskUsage(s.cfg.DB); err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "Unable to compact the database: " + err.Error(),
+		}
+	}
+	if !atomic.CompareAndSwapInt32(&s.compacting, 0, 1) {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "The database is already being compacted",
+		}
+	}
+
+	// Compacting a large database takes a long time, so it is done in the
+	// background and logged once it is done.
+	go func() {
+		defer atomic.StoreInt32(&s.compacting, 0)
+
+		rpcsLog.Infof("Compacting the block database")
+		start := time.Now()
+		err := ffldb.Compact(s.cfg.DB)
+		if dbErr, ok := err.(database.Error); ok &&
+			dbErr.ErrorCode == database.ErrDbNotOpen {
+
+			return
+		}
+		if err != nil {
+			rpcsLog.Errorf("Unable to compact the block database: %v",
+				err)
+			return
+		}
+		compacted, err := ffldb.DiskUsage(s.cfg.DB)
+		if err != nil {
+			rpcsLog.Errorf("Unable to load the disk usage of the "+
+				"block database: %v", err)
+			return
+		}
+		rpcsLog.Infof("Compacted the block database in %v (%d bytes of "+
+			"metadata)", time.Since(start).Round(time.Millisecond),
+			compacted.MetadataSize)
+	}()
+	return nil, nil
+}
+
+// handleConvertToPsbt handles converttopsbt commands.
+func handleConvertToPsbt(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.ConvertToPsbtCmd)
+
+	// Deserialize the transaction.  The serialization with witnesses is
+	// detected by its marker unless the caller says which one it is.
+	hexStr := c.HexTx
+	if len(hexStr)%2 != 0 {
+		hexStr = "0" + hexStr
+	}
+	serializedTx, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return nil, rpcDecodeHexError(hexStr)
+	}
+	var mtx wire.MsgTx
+	r := bytes.NewReader(serializedTx)
+	switch {
+	case c.IsWitness == nil:
+		err = mtx.Deserialize(r)
+	case *c.IsWitness:
+		err = mtx.BtcDecode(r, 0, wire.WitnessEncoding)
+	default:
+		err = mtx.DeserializeNoWitness(r)
+	}
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCDeserialization,
+			Message: "TX decode failed: " + err.Error(),
+		}
+	}
+
+	// A PSBT holds the unsigned transaction, so any signature data is
+	// dropped when permitted.
+	for _, txIn := range mtx.TxIn {
+		if len(txIn.SignatureScript) == 0 && len(txIn.Witness) == 0 {
+			continue
+		}
+		if !*c.PermitSigData {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCDeserialization,
+				Message: "Inputs must not have scriptSigs and scriptWitnesses",
+			}
+		}
+		txIn.SignatureScript = nil
+		txIn.Witness = nil
+	}
+
+	packet, err := psbt.NewFromUnsignedTx(&mtx)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Failed to create PSBT: " + err.Error(),
+		}
+	}
+	return encodePsbt(packet)
+}
+
 // handleCreateRawTransaction handles createrawtransaction commands.
 func handleCreateRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.CreateRawTransactionCmd)
@@ -778,6 +1048,380 @@ func createTxRawResult(chainParams *chaincfg.Params, mtx *wire.MsgTx,
 	return txReply, nil
 }
 
+// psbtScript returns the passed redeem or witness script of a PSBT as a JSON
+// object.
+func psbtScript(script []byte) *btcjson.PsbtScript {
+	if script == nil {
+		return nil
+	}
+
+	// The disassembled string will contain [error] inline if the script
+	// doesn't fully parse, so ignore the error here.
+	disbuf, _ := txscript.DisasmString(script)
+	return &btcjson.PsbtScript{
+		Asm:  disbuf,
+		Hex:  hex.EncodeToString(script),
+		Type: txscript.GetScriptClass(script).String(),
+	}
+}
+
+// psbtFingerprint returns the passed master key fingerprint of a PSBT as it
+// is serialized, hex encoded.
+func psbtFingerprint(fingerprint uint32) string {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], fingerprint)
+	return hex.EncodeToString(b[:])
+}
+
+// psbtBip32Path returns the passed BIP32 derivation path of a PSBT in the
+// m/0'/1 notation.
+func psbtBip32Path(path []uint32) string {
+	var b strings.Builder
+	b.WriteString("m")
+	for _, index := range path {
+		if index >= hdkeychain.HardenedKeyStart {
+			fmt.Fprintf(&b, "/%d'", index-hdkeychain.HardenedKeyStart)
+			continue
+		}
+		fmt.Fprintf(&b, "/%d", index)
+	}
+	return b.String()
+}
+
+// psbtBip32Derivs returns the passed BIP32 derivations of a PSBT as JSON
+// objects.
+func psbtBip32Derivs(derivs []*psbt.Bip32Derivation) []btcjson.PsbtBip32Deriv {
+	var result []btcjson.PsbtBip32Deriv
+	for _, d := range derivs {
+		result = append(result, btcjson.PsbtBip32Deriv{
+			PubKey:            hex.EncodeToString(d.PubKey),
+			MasterFingerprint: psbtFingerprint(d.MasterKeyFingerprint),
+			Path:              psbtBip32Path(d.Bip32Path),
+		})
+	}
+	return result
+}
+
+// psbtTaprootBip32Derivs returns the passed taproot BIP32 derivations of a
+// PSBT as JSON objects.
+func psbtTaprootBip32Derivs(derivs []*psbt.TaprootBip32Derivation) []btcjson.PsbtTaprootBip32Deriv {
+	var result []btcjson.PsbtTaprootBip32Deriv
+	for _, d := range derivs {
+		leafHashes := make([]string, len(d.LeafHashes))
+		for i, leafHash := range d.LeafHashes {
+			leafHashes[i] = hex.EncodeToString(leafHash)
+		}
+		result = append(result, btcjson.PsbtTaprootBip32Deriv{
+			PubKey:            hex.EncodeToString(d.XOnlyPubKey),
+			MasterFingerprint: psbtFingerprint(d.MasterKeyFingerprint),
+			Path:              psbtBip32Path(d.Bip32Path),
+			LeafHashes:        leafHashes,
+		})
+	}
+	return result
+}
+
+// psbtUnknowns splits the passed unknown key-value pairs of a PSBT into the
+// proprietary ones and the others.  The others are returned keyed by their
+// hex-encoded keys.
+func psbtUnknowns(unknowns []*psbt.Unknown) ([]btcjson.PsbtProprietary, map[string]string) {
+	proprietary := make([]btcjson.PsbtProprietary, 0)
+	other := make(map[string]string)
+	for _, u := range unknowns {
+		p, ok := psbt.ParseProprietary(u)
+		if !ok {
+			other[hex.EncodeToString(u.Key)] = hex.EncodeToString(u.Value)
+			continue
+		}
+		proprietary = append(proprietary, btcjson.PsbtProprietary{
+			Identifier: hex.EncodeToString(p.Identifier),
+			Subtype:    p.Subtype,
+			Key:        hex.EncodeToString(p.KeyData),
+			Value:      hex.EncodeToString(p.Value),
+		})
+	}
+	return proprietary, other
+}
+
+// sigHashTypeString returns the passed signature hash type in the notation
+// used by signrawtransaction, such as ALL|ANYONECANPAY.
+func sigHashTypeString(hashType txscript.SigHashType) string {
+	var str string
+	switch hashType &^ txscript.SigHashAnyOneCanPay {
+	case txscript.SigHashDefault:
+		str = "DEFAULT"
+	case txscript.SigHashAll:
+		str = "ALL"
+	case txscript.SigHashNone:
+		str = "NONE"
+	case txscript.SigHashSingle:
+		str = "SINGLE"
+	default:
+		return fmt.Sprintf("%#x", uint32(hashType))
+	}
+	if hashType&txscript.SigHashAnyOneCanPay != 0 {
+		str += "|ANYONECANPAY"
+	}
+	return str
+}
+
+// parsePsbtWitness returns the stack items of the passed final script witness
+// of a PSBT input, hex encoded.
+func parsePsbtWitness(witness []byte) ([]string, error) {
+	r := bytes.NewReader(witness)
+	count, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if count > uint64(len(witness)) {
+		return nil, fmt.Errorf("witness has too many items: %d", count)
+	}
+	items := make([]string, 0, count)
+	for i := uint64(0); i < count; i++ {
+		item, err := wire.ReadVarBytes(r, 0, txscript.MaxScriptSize,
+			"witness item")
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, hex.EncodeToString(item))
+	}
+	return items, nil
+}
+
+// parsePsbtTaprootTree returns the leaves of the passed serialized taproot
+// tree of a PSBT output.
+func parsePsbtTaprootTree(tree []byte) ([]btcjson.PsbtTaprootTreeLeaf, error) {
+	var leaves []btcjson.PsbtTaprootTreeLeaf
+	r := bytes.NewReader(tree)
+	for r.Len() > 0 {
+		var depthAndVersion [2]byte
+		if _, err := io.ReadFull(r, depthAndVersion[:]); err != nil {
+			return nil, err
+		}
+		script, err := wire.ReadVarBytes(r, 0, txscript.MaxScriptSize,
+			"leaf script")
+		if err != nil {
+			return nil, err
+		}
+		leaves = append(leaves, btcjson.PsbtTaprootTreeLeaf{
+			Depth:   int(depthAndVersion[0]),
+			LeafVer: int(depthAndVersion[1]),
+			Script:  hex.EncodeToString(script),
+		})
+	}
+	return leaves, nil
+}
+
+// createDecodePsbtInput converts the passed PSBT input to a JSON object.
+func createDecodePsbtInput(pIn *psbt.PInput,
+	chainParams *chaincfg.Params) (btcjson.DecodePsbtInput, error) {
+
+	var in btcjson.DecodePsbtInput
+	if pIn.NonWitnessUtxo != nil {
+		utxo := createTxRawDecodeResult(pIn.NonWitnessUtxo, chainParams)
+		in.NonWitnessUtxo = &utxo
+	}
+	if pIn.WitnessUtxo != nil {
+		vout := createVoutList(&wire.MsgTx{
+			TxOut: []*wire.TxOut{pIn.WitnessUtxo},
+		}, chainParams, nil)[0]
+		in.WitnessUtxo = &btcjson.PsbtWitnessUtxo{
+			Amount:       vout.Value,
+			ScriptPubKey: vout.ScriptPubKey,
+		}
+	}
+	if len(pIn.PartialSigs) > 0 {
+		in.PartialSignatures = make(map[string]string)
+		for _, sig := range pIn.PartialSigs {
+			pubKey := hex.EncodeToString(sig.PubKey)
+			in.PartialSignatures[pubKey] = hex.EncodeToString(
+				sig.Signature)
+		}
+	}
+	if pIn.SighashType != 0 {
+		in.Sighash = sigHashTypeString(pIn.SighashType)
+	}
+	in.RedeemScript = psbtScript(pIn.RedeemScript)
+	in.WitnessScript = psbtScript(pIn.WitnessScript)
+	in.Bip32Derivs = psbtBip32Derivs(pIn.Bip32Derivation)
+	if pIn.FinalScriptSig != nil {
+		disbuf, _ := txscript.DisasmString(pIn.FinalScriptSig)
+		in.FinalScriptSig = &btcjson.ScriptSig{
+			Asm: disbuf,
+			Hex: hex.EncodeToString(pIn.FinalScriptSig),
+		}
+	}
+	if pIn.FinalScriptWitness != nil {
+		witness, err := parsePsbtWitness(pIn.FinalScriptWitness)
+		if err != nil {
+			return in, fmt.Errorf("invalid final script witness: %w",
+				err)
+		}
+		in.FinalScriptWitness = witness
+	}
+	if pIn.TaprootKeySpendSig != nil {
+		in.TaprootKeyPathSig = hex.EncodeToString(pIn.TaprootKeySpendSig)
+	}
+	for _, sig := range pIn.TaprootScriptSpendSig {
+		sigBytes := sig.Signature
+		if sig.SigHash != txscript.SigHashDefault {
+			sigBytes = append(sigBytes[:len(sigBytes):len(sigBytes)],
+				byte(sig.SigHash))
+		}
+		in.TaprootScriptPathSigs = append(in.TaprootScriptPathSigs,
+			btcjson.PsbtTaprootScriptPathSig{
+				PubKey:   hex.EncodeToString(sig.XOnlyPubKey),
+				LeafHash: hex.EncodeToString(sig.LeafHash),
+				Sig:      hex.EncodeToString(sigBytes),
+			})
+	}
+
+	// The leaf scripts are grouped by script and version with the control
+	// blocks which commit to them.
+	for _, leaf := range pIn.TaprootLeafScript {
+		script := hex.EncodeToString(leaf.Script)
+		controlBlock := hex.EncodeToString(leaf.ControlBlock)
+		found := false
+		for i := range in.TaprootScripts {
+			ts := &in.TaprootScripts[i]
+			if ts.Script == script && ts.LeafVer == int(leaf.LeafVersion) {
+				ts.ControlBlocks = append(ts.ControlBlocks,
+					controlBlock)
+				found = true
+				break
+			}
+		}
+		if !found {
+			in.TaprootScripts = append(in.TaprootScripts,
+				btcjson.PsbtTaprootScript{
+					Script:        script,
+					LeafVer:       int(leaf.LeafVersion),
+					ControlBlocks: []string{controlBlock},
+				})
+		}
+	}
+	in.TaprootBip32Derivs = psbtTaprootBip32Derivs(pIn.TaprootBip32Derivation)
+	if pIn.TaprootInternalKey != nil {
+		in.TaprootInternalKey = hex.EncodeToString(pIn.TaprootInternalKey)
+	}
+	if pIn.TaprootMerkleRoot != nil {
+		in.TaprootMerkleRoot = hex.EncodeToString(pIn.TaprootMerkleRoot)
+	}
+	in.Proprietary, in.Unknown = psbtUnknowns(pIn.Unknowns)
+	return in, nil
+}
+
+// createDecodePsbtOutput converts the passed PSBT output to a JSON object.
+func createDecodePsbtOutput(pOut *psbt.POutput) (btcjson.DecodePsbtOutput, error) {
+	var out btcjson.DecodePsbtOutput
+	out.RedeemScript = psbtScript(pOut.RedeemScript)
+	out.WitnessScript = psbtScript(pOut.WitnessScript)
+	out.Bip32Derivs = psbtBip32Derivs(pOut.Bip32Derivation)
+	if pOut.TaprootInternalKey != nil {
+		out.TaprootInternalKey = hex.EncodeToString(pOut.TaprootInternalKey)
+	}
+	if pOut.TaprootTapTree != nil {
+		leaves, err := parsePsbtTaprootTree(pOut.TaprootTapTree)
+		if err != nil {
+			return out, fmt.Errorf("invalid taproot tree: %w", err)
+		}
+		out.TaprootTree = leaves
+	}
+	out.TaprootBip32Derivs = psbtTaprootBip32Derivs(pOut.TaprootBip32Derivation)
+	if claim := pOut.Claim(); claim != nil {
+		out.Claim = &btcjson.PsbtClaim{
+			Name:    string(claim.Name),
+			ClaimID: hex.EncodeToString(claim.ClaimID),
+			Value:   hex.EncodeToString(claim.Value),
+		}
+	}
+	out.Proprietary, out.Unknown = psbtUnknowns(pOut.Unknowns)
+	return out, nil
+}
+
+// handleDecodePsbt handles decodepsbt commands.
+func handleDecodePsbt(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.DecodePsbtCmd)
+
+	packet, err := decodePsbt(c.Psbt)
+	if err != nil {
+		return nil, err
+	}
+
+	params := s.cfg.ChainParams
+	result := btcjson.DecodePsbtResult{
+		Tx:          createTxRawDecodeResult(packet.UnsignedTx, params),
+		GlobalXPubs: make([]btcjson.PsbtGlobalXPub, 0, len(packet.XPubs)),
+		Inputs:      make([]btcjson.DecodePsbtInput, 0, len(packet.Inputs)),
+		Outputs:     make([]btcjson.DecodePsbtOutput, 0, len(packet.Outputs)),
+	}
+	for _, xPub := range packet.XPubs {
+		// The extended key is serialized without the checksum of its
+		// string encoding.
+		extendedKey := make([]byte, 0, len(xPub.ExtendedKey)+4)
+		extendedKey = append(extendedKey, xPub.ExtendedKey...)
+		extendedKey = append(extendedKey,
+			chainhash.DoubleHashB(xPub.ExtendedKey)[:4]...)
+		result.GlobalXPubs = append(result.GlobalXPubs,
+			btcjson.PsbtGlobalXPub{
+				XPub:              base58.Encode(extendedKey),
+				MasterFingerprint: psbtFingerprint(xPub.MasterKeyFingerprint),
+				Path:              psbtBip32Path(xPub.Bip32Path),
+			})
+	}
+	result.Proprietary, result.Unknown = psbtUnknowns(packet.Unknowns)
+
+	for i := range packet.Inputs {
+		in, err := createDecodePsbtInput(&packet.Inputs[i], params)
+		if err != nil {
+			return nil, &btcjson.RPCError{
+				Code: btcjson.ErrRPCDeserialization,
+				Message: fmt.Sprintf("PSBT decode failed: input %d: %v",
+					i, err),
+			}
+		}
+		result.Inputs = append(result.Inputs, in)
+	}
+	for i := range packet.Outputs {
+		out, err := createDecodePsbtOutput(&packet.Outputs[i])
+		if err != nil {
+			return nil, &btcjson.RPCError{
+				Code: btcjson.ErrRPCDeserialization,
+				Message: fmt.Sprintf("PSBT decode failed: output %d: %v",
+					i, err),
+			}
+		}
+		result.Outputs = append(result.Outputs, out)
+	}
+
+	// The fee is only known when the outputs spent by all inputs are.
+	if fee, err := packet.GetTxFee(); err == nil {
+		feeBTC := fee.ToBTC()
+		result.Fee = &feeBTC
+	}
+
+	return result, nil
+}
+
+// createTxRawDecodeResult converts the passed transaction to a decoded raw
+// transaction JSON object.
+func createTxRawDecodeResult(mtx *wire.MsgTx,
+	chainParams *chaincfg.Params) btcjson.TxRawDecodeResult {
+
+	tx := btcutil.NewTx(mtx)
+	return btcjson.TxRawDecodeResult{
+		Txid:     mtx.TxHash().String(),
+		Hash:     mtx.WitnessHash().String(),
+		Size:     int32(mtx.SerializeSize()),
+		Vsize:    int32(mempool.GetTxVirtualSize(tx)),
+		Weight:   int32(blockchain.GetTransactionWeight(tx)),
+		Version:  mtx.Version,
+		Locktime: mtx.LockTime,
+		Vin:      createVinList(mtx),
+		Vout:     createVoutList(mtx, chainParams, nil),
+	}
+}
+
 // handleDecodeRawTransaction handles decoderawtransaction commands.
 func handleDecodeRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.DecodeRawTransactionCmd)
@@ -801,14 +1445,7 @@ func handleDecodeRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan
 	}
 
 	// Create and return the result.
-	txReply := btcjson.TxRawDecodeResult{
-		Txid:     mtx.TxHash().String(),
-		Version:  mtx.Version,
-		Locktime: mtx.LockTime,
-		Vin:      createVinList(&mtx),
-		Vout:     createVoutList(&mtx, s.cfg.ChainParams, nil),
-	}
-	return txReply, nil
+	return createTxRawDecodeResult(&mtx, s.cfg.ChainParams), nil
 }
 
 // handleDecodeScript handles decodescript commands.
@@ -866,6 +1503,55 @@ func handleDecodeScript(s *rpcServer, cmd interface{}, closeChan <-chan struct{}
 	return reply, nil
 }
 
+// handleDumpProfiles implements the dumpprofiles command.
+func handleDumpProfiles(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.DumpProfilesCmd)
+
+	cpuDuration := defaultProfileDumpCPUDuration
+	if c.CPUSeconds != nil {
+		if *c.CPUSeconds < 0 {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParameter,
+				Message: "The cpu profile duration must not be negative",
+			}
+		}
+		maxSeconds := int(maxProfileDumpCPUDuration / time.Second)
+		if *c.CPUSeconds > maxSeconds {
+			return nil, &btcjson.RPCError{
+				Code: btcjson.ErrRPCInvalidParameter,
+				Message: fmt.Sprintf("The cpu profile duration "+
+					"must not exceed %d seconds", maxSeconds),
+			}
+		}
+		cpuDuration = time.Duration(*c.CPUSeconds) * time.Second
+	}
+
+	dir := filepath.Join(cfg.DataDir, profilesDirName)
+	result, err := dumpProfiles(dir, cpuDuration, s.quit)
+	if err != nil {
+		message := "Unable to dump profiles: " + err.Error()
+
+		// Report the profiles which were written before the error.
+		if result != nil {
+			var written []string
+			for _, path := range []string{result.Heap, result.Goroutine} {
+				if path != "" {
+					written = append(written, path)
+				}
+			}
+			if len(written) > 0 {
+				message += " (wrote " + strings.Join(written, ", ") +
+					")"
+			}
+		}
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: message,
+		}
+	}
+	return result, nil
+}
+
 // handleEstimateFee handles estimatefee commands.
 func handleEstimateFee(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.EstimateFeeCmd)
@@ -888,10 +1574,78 @@ func handleEstimateFee(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 	return float64(feeRate), nil
 }
 
-// handleGenerate handles generate commands.
-func handleGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
-	// Respond with an error if there are no addresses to pay the
-	// created blocks to.
+// handleEstimateSmartFee handles estimatesmartfee commands.  The estimate
+// mode is accepted for compatibility, but there is only a single estimator so
+// it has no effect.
+func handleEstimateSmartFee(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.EstimateSmartFeeCmd)
+
+	if s.cfg.FeeEstimator == nil {
+		return nil, errors.New("Fee estimation disabled")
+	}
+
+	if c.ConfTarget <= 0 {
+		return nil, errors.New("Parameter ConfTarget must be positive")
+	}
+
+	// Clamp the target to the maximum the estimator is able to provide.
+	confTarget := c.ConfTarget
+	if confTarget > mempool.EstimateFeeMaxBlocks {
+		confTarget = mempool.EstimateFeeMaxBlocks
+	}
+
+	result := &btcjson.EstimateSmartFeeResult{Blocks: confTarget}
+	feeRate, err := s.cfg.FeeEstimator.EstimateFee(uint32(confTarget))
+	if err != nil {
+		result.Errors = []string{err.Error()}
+		return result, nil
+	}
+
+	rate := float64(feeRate)
+	result.FeeRate = &rate
+	return result, nil
+}
+
+// handleFinalizePsbt handles finalizepsbt commands.
+func handleFinalizePsbt(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.FinalizePsbtCmd)
+
+	packet, err := decodePsbt(c.Psbt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Finalize the inputs which have all the data they need.  The others
+	// are left as they are, so the PSBT can be completed later.
+	for i := range packet.UnsignedTx.TxIn {
+		_, _ = psbt.MaybeFinalize(packet, i)
+	}
+
+	result := btcjson.FinalizePsbtResult{Complete: packet.IsComplete()}
+	if result.Complete && *c.Extract {
+		tx, err := psbt.Extract(packet)
+		if err != nil {
+			context := "Failed to extract transaction"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		result.Hex, err = messageToHex(tx)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	result.Psbt, err = encodePsbt(packet)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// handleGenerate handles generate commands.
+func handleGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	// Respond with an error if there are no addresses to pay the
+	// created blocks to.
 	if len(cfg.miningAddrs) == 0 {
 		return nil, &btcjson.RPCError{
 			Code: btcjson.ErrRPCInternal.Code,
@@ -902,14 +1656,8 @@ func handleGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 
 	// Respond with an error if there's virtually 0 chance of mining a block
 	// with the CPU.
-	if !s.cfg.ChainParams.GenerateSupported {
-		return nil, &btcjson.RPCError{
-			Code: btcjson.ErrRPCDifficulty,
-			Message: fmt.Sprintf("No support for `generate` on "+
-				"the current network, %s, as it's unlikely to "+
-				"be possible to mine a block with the CPU.",
-				s.cfg.ChainParams.Net),
-		}
+	if err := checkGenerateSupported(s, "generate"); err != nil {
+		return nil, err
 	}
 
 	c := cmd.(*btcjson.GenerateCmd)
@@ -942,20 +1690,153 @@ func handleGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 	return reply, nil
 }
 
+// checkGenerateSupported returns an error for the passed method when there's
+// virtually 0 chance of mining a block with the CPU on the current network.
+func checkGenerateSupported(s *rpcServer, method string) error {
+	if !s.cfg.ChainParams.GenerateSupported {
+		return &btcjson.RPCError{
+			Code: btcjson.ErrRPCDifficulty,
+			Message: fmt.Sprintf("No support for `%s` on "+
+				"the current network, %s, as it's unlikely to "+
+				"be possible to mine a block with the CPU.",
+				method, s.cfg.ChainParams.Net),
+		}
+	}
+	return nil
+}
+
+// decodeGenerateAddress decodes the passed address which generated blocks pay
+// to and ensures it is for the current network.
+func decodeGenerateAddress(s *rpcServer, encodedAddr string) (address.Address, error) {
+	addr, err := address.DecodeAddress(encodedAddr, s.cfg.ChainParams)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidAddressOrKey,
+			Message: "Invalid address or key: " + err.Error(),
+		}
+	}
+	if !addr.IsForNet(s.cfg.ChainParams) {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidAddressOrKey,
+			Message: "Invalid address: " + encodedAddr +
+				" is for the wrong network",
+		}
+	}
+	return addr, nil
+}
+
+// handleGenerateToAddress handles generatetoaddress commands.
+func handleGenerateToAddress(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	if err := checkGenerateSupported(s, "generatetoaddress"); err != nil {
+		return nil, err
+	}
+
+	c := cmd.(*btcjson.GenerateToAddressCmd)
+
+	// Respond with an error if the client is requesting a nonpositive
+	// number of blocks to be generated.
+	if c.NumBlocks <= 0 || c.NumBlocks > math.MaxUint32 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Please request a positive number of blocks to generate.",
+		}
+	}
+	addr, err := decodeGenerateAddress(s, c.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	blockHashes, err := s.cfg.CPUMiner.GenerateNBlocksToAddress(
+		uint32(c.NumBlocks), addr)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInternal.Code,
+			Message: err.Error(),
+		}
+	}
+
+	reply := make([]string, 0, len(blockHashes))
+	for _, hash := range blockHashes {
+		reply = append(reply, hash.String())
+	}
+	return reply, nil
+}
+
+// handleGenerateBlock handles generateblock commands.
+func handleGenerateBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	if err := checkGenerateSupported(s, "generateblock"); err != nil {
+		return nil, err
+	}
+
+	c := cmd.(*btcjson.GenerateBlockCmd)
+	addr, err := decodeGenerateAddress(s, c.Output)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each transaction is either the hash of a transaction in the memory
+	// pool or a serialized, hex-encoded transaction.
+	txns := make([]*btcutil.Tx, 0, len(c.Transactions))
+	for _, str := range c.Transactions {
+		if len(str) == chainhash.MaxHashStringSize {
+			txHash, err := chainhash.NewHashFromStr(str)
+			if err != nil {
+				return nil, rpcDecodeHexError(str)
+			}
+			tx, err := s.cfg.TxMemPool.FetchTransaction(txHash)
+			if err != nil {
+				return nil, &btcjson.RPCError{
+					Code: btcjson.ErrRPCInvalidAddressOrKey,
+					Message: fmt.Sprintf("Transaction %v not "+
+						"in mempool", txHash),
+				}
+			}
+			txns = append(txns, tx)
+			continue
+		}
+
+		serializedTx, err := hex.DecodeString(str)
+		if err != nil {
+			return nil, rpcDecodeHexError(str)
+		}
+		var msgTx wire.MsgTx
+		err = msgTx.Deserialize(bytes.NewReader(serializedTx))
+		if err != nil {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCDeserialization,
+				Message: "TX decode failed: " + err.Error(),
+			}
+		}
+		txns = append(txns, btcutil.NewTx(&msgTx))
+	}
+
+	blockHash, err := s.cfg.CPUMiner.GenerateBlock(addr, txns)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCVerify,
+			Message: err.Error(),
+		}
+	}
+
+	return &btcjson.GenerateBlockResult{Hash: blockHash.String()}, nil
+}
+
 // handleGetAddedNodeInfo handles getaddednodeinfo commands.
 func handleGetAddedNodeInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.GetAddedNodeInfoCmd)
 
-	// Retrieve a list of persistent (added) peers from the server and
-	// filter the list of peers per the specified address (if any).
-	peers := s.cfg.ConnMgr.PersistentPeers()
+	// Retrieve a list of the added nodes from the server and filter the
+	// list per the specified address (if any).
+	nodes := s.cfg.ConnMgr.AddedNodes()
 	if c.Node != nil {
 		node := *c.Node
+		normalized := normalizeAddress(node, s.cfg.ChainParams.DefaultPort)
 		found := false
-		for i, peer := range peers {
-			if peer.ToPeer().Addr() == node {
-				peers = peers[i : i+1]
+		for i, info := range nodes {
+			if info.addr == node || info.addr == normalized {
+				nodes = nodes[i : i+1]
 				found = true
+				break
 			}
 		}
 		if !found {
@@ -969,30 +1850,37 @@ func handleGetAddedNodeInfo(s *rpcServer, cmd interface{}, closeChan <-chan stru
 	// Without the dns flag, the result is just a slice of the addresses as
 	// strings.
 	if !c.DNS {
-		results := make([]string, 0, len(peers))
-		for _, peer := range peers {
-			results = append(results, peer.ToPeer().Addr())
+		results := make([]string, 0, len(nodes))
+		for _, info := range nodes {
+			results = append(results, info.addr)
 		}
 		return results, nil
 	}
 
 	// With the dns flag, the result is an array of JSON objects which
-	// include the result of DNS lookups for each peer.
-	results := make([]*btcjson.GetAddedNodeInfoResult, 0, len(peers))
-	for _, rpcPeer := range peers {
-		// Set the "address" of the peer which could be an ip address
+	// include the result of DNS lookups for each node.
+	results := make([]*btcjson.GetAddedNodeInfoResult, 0, len(nodes))
+	for _, info := range nodes {
+		// Set the "address" of the node which could be an ip address
 		// or a domain name.
-		peer := rpcPeer.ToPeer()
 		var result btcjson.GetAddedNodeInfoResult
-		result.AddedNode = peer.Addr()
-		result.Connected = btcjson.Bool(peer.Connected())
+		result.AddedNode = info.addr
+		connected := info.peer != nil && info.peer.ToPeer().Connected()
+		result.Connected = btcjson.Bool(connected)
+
+		// The node is connected at the resolved address when it was
+		// added with a domain name.
+		var peerHost string
+		if connected {
+			peerHost, _, _ = net.SplitHostPort(info.peer.ToPeer().Addr())
+		}
 
 		// Split the address into host and port portions so we can do
 		// a DNS lookup against the host.  When no port is specified in
 		// the address, just use the address as the host.
-		host, _, err := net.SplitHostPort(peer.Addr())
+		host, _, err := net.SplitHostPort(info.addr)
 		if err != nil {
-			host = peer.Addr()
+			host = info.addr
 		}
 
 		var ipList []string
@@ -1021,8 +1909,9 @@ func handleGetAddedNodeInfo(s *rpcServer, cmd interface{}, closeChan <-chan stru
 			var addr btcjson.GetAddedNodeInfoResultAddr
 			addr.Address = ip
 			addr.Connected = "false"
-			if ip == host && peer.Connected() {
-				addr.Connected = directionString(peer.Inbound())
+			if connected && ip == peerHost {
+				addr.Connected = directionString(
+					info.peer.ToPeer().Inbound())
 			}
 			addrs = append(addrs, addr)
 		}
@@ -1032,6 +1921,264 @@ func handleGetAddedNodeInfo(s *rpcServer, cmd interface{}, closeChan <-chan stru
 	return results, nil
 }
 
+// addressIndexTx is a confirmed transaction involving one of the addresses of
+// an address index request along with the height of its block and its offset
+// within the block.
+type addressIndexTx struct {
+	tx     *btcutil.Tx
+	height int32
+	offset uint32
+}
+
+// addressOutput is an output paying to one of the addresses of an address index
+// request.
+type addressOutput struct {
+	outPoint wire.OutPoint
+	address  string
+	pkScript []byte
+	value    int64
+	height   int32
+}
+
+// decodeAddressIndexRequest returns the decoded addresses of the passed address
+// index request.  An error is returned when the address index is not enabled.
+func decodeAddressIndexRequest(s *rpcServer,
+	request *btcjson.AddressIndexRequest) ([]address.Address, error) {
+
+	if s.cfg.AddrIndex == nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "Address index must be enabled (--addrindex)",
+		}
+	}
+	if len(request.Addresses) == 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "No addresses specified",
+		}
+	}
+
+	addrs := make([]address.Address, 0, len(request.Addresses))
+	for _, encoded := range request.Addresses {
+		addr, err := address.DecodeAddress(encoded, s.cfg.ChainParams)
+		if err != nil {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidAddressOrKey,
+				Message: "Invalid address or key: " + err.Error(),
+			}
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
+// fetchAddressIndexTxns returns the confirmed transactions involving any of the
+// passed addresses in the order they appear in the main chain.  Transactions
+// involving several of the addresses are only returned once.
+func fetchAddressIndexTxns(s *rpcServer, addrs []address.Address) ([]addressIndexTx, error) {
+	var txns []addressIndexTx
+	seen := make(map[chainhash.Hash]struct{})
+	err := s.cfg.DB.View(func(dbTx database.Tx) error {
+		for _, addr := range addrs {
+			regions, _, err := s.cfg.AddrIndex.TxRegionsForAddress(
+				dbTx, addr, 0, math.MaxUint32, false)
+			if err != nil {
+				return err
+			}
+			serializedTxns, err := dbTx.FetchBlockRegions(regions)
+			if err != nil {
+				return err
+			}
+
+			for i, serializedTx := range serializedTxns {
+				// Skip the transactions of blocks which were
+				// disconnected since the index was read.
+				height, err := s.cfg.Chain.BlockHeightByHash(
+					regions[i].Hash)
+				if err != nil {
+					continue
+				}
+
+				tx, err := btcutil.NewTxFromBytes(serializedTx)
+				if err != nil {
+					return err
+				}
+				if _, ok := seen[*tx.Hash()]; ok {
+					continue
+				}
+				seen[*tx.Hash()] = struct{}{}
+				txns = append(txns, addressIndexTx{
+					tx:     tx,
+					height: height,
+					offset: regions[i].Offset,
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		context := "Failed to load address index entries"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	// The transactions of each address are already in chain order, so
+	// only the transactions of different addresses need to be merged.
+	sort.SliceStable(txns, func(i, j int) bool {
+		if txns[i].height != txns[j].height {
+			return txns[i].height < txns[j].height
+		}
+		return txns[i].offset < txns[j].offset
+	})
+	return txns, nil
+}
+
+// addressOutputs returns the outputs of the passed transactions which pay to
+// one of the passed addresses in chain order along with the ones which are
+// spent by the transactions.  Outputs whose scripts involve more than one
+// address, such as bare multisig outputs, are not attributed to any of them.
+func addressOutputs(txns []addressIndexTx, addrs []address.Address,
+	params *chaincfg.Params) ([]addressOutput, map[wire.OutPoint]struct{}) {
+
+	encodedAddrs := make(map[string]struct{}, len(addrs))
+	for _, addr := range addrs {
+		encodedAddrs[addr.EncodeAddress()] = struct{}{}
+	}
+
+	var outputs []addressOutput
+	funded := make(map[wire.OutPoint]struct{})
+	for _, atx := range txns {
+		for i, txOut := range atx.tx.MsgTx().TxOut {
+			_, outAddrs, _, _ := txscript.ExtractPkScriptAddrs(
+				txOut.PkScript, params)
+			if len(outAddrs) != 1 {
+				continue
+			}
+			encoded := outAddrs[0].EncodeAddress()
+			if _, ok := encodedAddrs[encoded]; !ok {
+				continue
+			}
+
+			outPoint := wire.OutPoint{
+				Hash:  *atx.tx.Hash(),
+				Index: uint32(i),
+			}
+			funded[outPoint] = struct{}{}
+			outputs = append(outputs, addressOutput{
+				outPoint: outPoint,
+				address:  encoded,
+				pkScript: txOut.PkScript,
+				value:    txOut.Value,
+				height:   atx.height,
+			})
+		}
+	}
+
+	// Every transaction spending one of the outputs involves its address,
+	// so the spent outputs are found among the inputs of the transactions.
+	spent := make(map[wire.OutPoint]struct{})
+	for _, atx := range txns {
+		for _, txIn := range atx.tx.MsgTx().TxIn {
+			if _, ok := funded[txIn.PreviousOutPoint]; ok {
+				spent[txIn.PreviousOutPoint] = struct{}{}
+			}
+		}
+	}
+	return outputs, spent
+}
+
+// handleGetAddressBalance implements the getaddressbalance command.
+func handleGetAddressBalance(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetAddressBalanceCmd)
+	addrs, err := decodeAddressIndexRequest(s, &c.Request)
+	if err != nil {
+		return nil, err
+	}
+	txns, err := fetchAddressIndexTxns(s, addrs)
+	if err != nil {
+		return nil, err
+	}
+
+	var result btcjson.GetAddressBalanceResult
+	outputs, spent := addressOutputs(txns, addrs, s.cfg.ChainParams)
+	for _, output := range outputs {
+		result.Received += output.value
+		if _, ok := spent[output.outPoint]; !ok {
+			result.Balance += output.value
+		}
+	}
+	return &result, nil
+}
+
+// handleGetAddressTxIDs implements the getaddresstxids command.
+func handleGetAddressTxIDs(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetAddressTxIDsCmd)
+	addrs, err := decodeAddressIndexRequest(s, &c.Request)
+	if err != nil {
+		return nil, err
+	}
+	txns, err := fetchAddressIndexTxns(s, addrs)
+	if err != nil {
+		return nil, err
+	}
+
+	txids := make([]string, 0, len(txns))
+	for _, atx := range txns {
+		if c.Request.Start != nil && atx.height < *c.Request.Start {
+			continue
+		}
+		if c.Request.End != nil && atx.height > *c.Request.End {
+			continue
+		}
+		txids = append(txids, atx.tx.Hash().String())
+	}
+	return txids, nil
+}
+
+// handleGetAddressUtxos implements the getaddressutxos command.
+func handleGetAddressUtxos(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetAddressUtxosCmd)
+	addrs, err := decodeAddressIndexRequest(s, &c.Request)
+	if err != nil {
+		return nil, err
+	}
+
+	// The transactions of blocks connected after the best block is loaded
+	// are ignored, so the outputs are consistent with it.
+	best := s.cfg.Chain.BestSnapshot()
+	txns, err := fetchAddressIndexTxns(s, addrs)
+	if err != nil {
+		return nil, err
+	}
+	for len(txns) > 0 && txns[len(txns)-1].height > best.Height {
+		txns = txns[:len(txns)-1]
+	}
+
+	utxos := make([]btcjson.AddressUtxoResult, 0)
+	outputs, spent := addressOutputs(txns, addrs, s.cfg.ChainParams)
+	for _, output := range outputs {
+		if _, ok := spent[output.outPoint]; ok {
+			continue
+		}
+		utxos = append(utxos, btcjson.AddressUtxoResult{
+			Address:     output.address,
+			TxID:        output.outPoint.Hash.String(),
+			OutputIndex: output.outPoint.Index,
+			Script:      hex.EncodeToString(output.pkScript),
+			Satoshis:    output.value,
+			Height:      output.height,
+		})
+	}
+
+	if c.Request.ChainInfo == nil || !*c.Request.ChainInfo {
+		return utxos, nil
+	}
+	return &btcjson.GetAddressUtxosChainInfoResult{
+		Utxos:  utxos,
+		Hash:   best.Hash.String(),
+		Height: best.Height,
+	}, nil
+}
+
 // handleGetBestBlock implements the getbestblock command.
 func handleGetBestBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	// All other "get block" commands give either the height, the
@@ -1191,6 +2338,12 @@ func softForkStatus(state blockchain.ThresholdState) (string, error) {
 	}
 }
 
+// formatWork returns the passed amount of work as a zero-padded 256-bit hex
+// number, which is how bitcoind reports chain work.
+func formatWork(work *big.Int) string {
+	return fmt.Sprintf("%064x", work)
+}
+
 // handleGetBlockChainInfo implements the getblockchaininfo command.
 func handleGetBlockChainInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	// Obtain a snapshot of the current best known blockchain state. We'll
@@ -1206,118 +2359,257 @@ func handleGetBlockChainInfo(s *rpcServer, cmd interface{}, closeChan <-chan str
 		BestBlockHash: chainSnapshot.Hash.String(),
 		Difficulty:    getDifficultyRatio(chainSnapshot.Bits, params),
 		MedianTime:    chainSnapshot.MedianTime.Unix(),
+		ChainWork:     formatWork(chainSnapshot.WorkSum),
+		BestBlockWork: formatWork(blockchain.CalcWork(chainSnapshot.Bits)),
 		Pruned:        cfg.Prune != 0,
 		SoftForks: &btcjson.SoftForks{
 			Bip9SoftForks: make(map[string]*btcjson.Bip9SoftForkDescription),
 		},
 	}
 
+	// Report the lowest block which is still stored when pruned.
+	if chainInfo.Pruned {
+		pruneHeight, err := chain.PruneHeight()
+		if err != nil {
+			context := "Failed to determine the prune height"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		chainInfo.PruneHeight = pruneHeight
+	}
+
+	// Warn when no new block was connected to the best chain tip for longer
+	// than expected.
+	if s.cfg.SyncMgr.StaleTip() {
+		chainInfo.Warnings = append(chainInfo.Warnings,
+			"No new block was received for longer than expected -- "+
+				"the best chain tip may be stale")
+	}
+
+	// Warn when the local clock is off from the time of the network.
+	if warning := clockSkewMessage(s); warning != "" {
+		chainInfo.Warnings = append(chainInfo.Warnings, warning)
+	}
+
+	// Warn when a fork of the main chain indicates the network may have
+	// split.
+	if warning := chain.ChainWarning(); warning != "" {
+		chainInfo.Warnings = append(chainInfo.Warnings, warning)
+	}
+
+	// Warn when the verification of the chain at startup found a problem.
+	if warning := s.cfg.SyncMgr.ChainVerifyWarning(); warning != "" {
+		chainInfo.Warnings = append(chainInfo.Warnings, warning)
+	}
+
+	// Warn when the processing of blocks is suspended for lack of free
+	// disk space.
+	if warning := s.cfg.SyncMgr.DiskSpaceWarning(); warning != "" {
+		chainInfo.Warnings = append(chainInfo.Warnings, warning)
+	}
+
 	// Next, populate the response with information describing the current
 	// status of soft-forks deployed via the super-majority block
 	// signalling mechanism.
 	height := chainSnapshot.Height
-	chainInfo.SoftForks.SoftForks = []*btcjson.SoftForkDescription{
-		{
-			ID:      "bip34",
-			Version: 2,
-			Reject: struct {
-				Status bool `json:"status"`
-			}{
-				Status: height >= params.BIP0034Height,
-			},
-		},
-		{
-			ID:      "bip66",
-			Version: 3,
-			Reject: struct {
-				Status bool `json:"status"`
-			}{
-				Status: height >= params.BIP0066Height,
-			},
-		},
-		{
-			ID:      "bip65",
-			Version: 4,
-			Reject: struct {
-				Status bool `json:"status"`
-			}{
-				Status: height >= params.BIP0065Height,
-			},
-		},
+	for _, deployment := range buriedDeployments(params) {
+		desc := &btcjson.SoftForkDescription{
+			ID:      deployment.name,
+			Version: deployment.version,
+		}
+		desc.Reject.Status = height >= deployment.height
+		chainInfo.SoftForks.SoftForks = append(
+			chainInfo.SoftForks.SoftForks, desc)
 	}
 
 	// Finally, query the BIP0009 version bits state for all currently
 	// defined BIP0009 soft-fork deployments.
-	for deployment, deploymentDetails := range params.Deployments {
-		// Map the integer deployment ID into a human readable
-		// fork-name.
-		var forkName string
-		switch deployment {
-		case chaincfg.DeploymentTestDummy:
-			forkName = "dummy"
+	deployments, err := bip9Deployments(chain, params, false)
+	if err != nil {
+		return nil, err
+	}
+	for forkName, info := range deployments {
+		chainInfo.SoftForks.Bip9SoftForks[forkName] = info.Bip9
+	}
+
+	return chainInfo, nil
+}
 
-		case chaincfg.DeploymentTestDummyMinActivation:
-			forkName = "dummy-min-activation"
+// deploymentName returns the human readable name of the passed deployment ID.
+func deploymentName(deployment int) (string, error) {
+	switch deployment {
+	case chaincfg.DeploymentTestDummy:
+		return "dummy", nil
 
-		case chaincfg.DeploymentTestDummyAlwaysActive:
-			forkName = "dummy-always-active"
+	case chaincfg.DeploymentTestDummyMinActivation:
+		return "dummy-min-activation", nil
 
-		case chaincfg.DeploymentCSV:
-			forkName = "csv"
+	case chaincfg.DeploymentTestDummyAlwaysActive:
+		return "dummy-always-active", nil
 
-		case chaincfg.DeploymentSegwit:
-			forkName = "segwit"
+	case chaincfg.DeploymentCSV:
+		return "csv", nil
 
-		case chaincfg.DeploymentTaproot:
-			forkName = "taproot"
+	case chaincfg.DeploymentSegwit:
+		return "segwit", nil
 
-		default:
-			return nil, &btcjson.RPCError{
-				Code: btcjson.ErrRPCInternal.Code,
-				Message: fmt.Sprintf("Unknown deployment %v "+
-					"detected", deployment),
-			}
+	case chaincfg.DeploymentTaproot:
+		return "taproot", nil
+
+	default:
+		return "", &btcjson.RPCError{
+			Code: btcjson.ErrRPCInternal.Code,
+			Message: fmt.Sprintf("Unknown deployment %v "+
+				"detected", deployment),
+		}
+	}
+}
+
+// buriedDeployment describes a soft-fork which was deployed using super-majority
+// block signalling and whose activation height is buried in the chain
+// parameters.
+type buriedDeployment struct {
+	name    string
+	version uint32
+	height  int32
+}
+
+// buriedDeployments returns the buried soft-fork deployments of the passed
+// network.
+func buriedDeployments(params *chaincfg.Params) []buriedDeployment {
+	return []buriedDeployment{
+		{name: "bip34", version: 2, height: params.BIP0034Height},
+		{name: "bip66", version: 3, height: params.BIP0066Height},
+		{name: "bip65", version: 4, height: params.BIP0065Height},
+	}
+}
+
+// bip9Deployments returns the state of the BIP0009 soft-fork deployments of the
+// passed network for the block after the best block keyed by their name.  The
+// signalling statistics of the current confirmation window are included for the
+// deployments which are being voted on when requested.
+func bip9Deployments(chain *blockchain.BlockChain, params *chaincfg.Params,
+	withStats bool) (map[string]*btcjson.DeploymentInfo, error) {
+
+	deployments := make(map[string]*btcjson.DeploymentInfo)
+	for deployment := range params.Deployments {
+		forkName, err := deploymentName(deployment)
+		if err != nil {
+			return nil, err
 		}
 
 		// Query the chain for the current status of the deployment as
 		// identified by its deployment ID.
-		deploymentStatus, err := chain.ThresholdState(uint32(deployment))
+		status, err := chain.ThresholdState(uint32(deployment))
 		if err != nil {
 			context := "Failed to obtain deployment status"
 			return nil, internalRPCError(err.Error(), context)
 		}
-
-		// Attempt to convert the current deployment status into a
-		// human readable string. If the status is unrecognized, then a
-		// non-nil error is returned.
-		statusString, err := softForkStatus(deploymentStatus)
+		desc, err := bip9SoftForkDescription(&params.Deployments[deployment],
+			status)
 		if err != nil {
-			return nil, &btcjson.RPCError{
-				Code: btcjson.ErrRPCInternal.Code,
-				Message: fmt.Sprintf("unknown deployment status: %v",
-					deploymentStatus),
+			return nil, err
+		}
+
+		// The statistics of the confirmation window of the next block
+		// are only of interest while the deployment is being voted on.
+		if withStats && status == blockchain.ThresholdStarted {
+			stats, err := chain.ThresholdStats(uint32(deployment))
+			if err != nil {
+				context := "Failed to obtain deployment statistics"
+				return nil, internalRPCError(err.Error(), context)
 			}
+			var percentage float64
+			if stats.Elapsed != 0 {
+				percentage = float64(stats.Count) * 100 /
+					float64(stats.Elapsed)
+			}
+			desc.Statistics = &btcjson.Bip9Statistics{
+				Period:     stats.Period,
+				Threshold:  stats.Threshold,
+				Elapsed:    stats.Elapsed,
+				Count:      stats.Count,
+				Possible:   stats.Possible,
+				Percentage: percentage,
+			}
+		}
+
+		deployments[forkName] = &btcjson.DeploymentInfo{
+			Type:   "bip9",
+			Active: status == blockchain.ThresholdActive,
+			Bip9:   desc,
 		}
+	}
+
+	return deployments, nil
+}
+
+// bip9SoftForkDescription returns the description of the passed deployment
+// given its current status.
+func bip9SoftForkDescription(deployment *chaincfg.ConsensusDeployment,
+	status blockchain.ThresholdState) (*btcjson.Bip9SoftForkDescription, error) {
 
-		// Finally, populate the soft-fork description with all the
-		// information gathered above.
-		var startTime, endTime int64
-		if starter, ok := deploymentDetails.DeploymentStarter.(*chaincfg.MedianTimeDeploymentStarter); ok {
-			startTime = starter.StartTime().Unix()
+	// Attempt to convert the current deployment status into a human
+	// readable string. If the status is unrecognized, then a non-nil error
+	// is returned.
+	statusString, err := softForkStatus(status)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInternal.Code,
+			Message: fmt.Sprintf("unknown deployment status: %v",
+				status),
 		}
-		if ender, ok := deploymentDetails.DeploymentEnder.(*chaincfg.MedianTimeDeploymentEnder); ok {
-			endTime = ender.EndTime().Unix()
+	}
+
+	var startTime, endTime int64
+	if starter, ok := deployment.DeploymentStarter.(*chaincfg.MedianTimeDeploymentStarter); ok {
+		startTime = starter.StartTime().Unix()
+	}
+	if ender, ok := deployment.DeploymentEnder.(*chaincfg.MedianTimeDeploymentEnder); ok {
+		endTime = ender.EndTime().Unix()
+	}
+	return &btcjson.Bip9SoftForkDescription{
+		Status:              strings.ToLower(statusString),
+		Bit:                 deployment.BitNumber,
+		StartTime2:          startTime,
+		Timeout:             endTime,
+		MinActivationHeight: int32(deployment.MinActivationHeight),
+	}, nil
+}
+
+// handleGetDeploymentInfo implements the getdeploymentinfo command.
+func handleGetDeploymentInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	params := s.cfg.ChainParams
+	chain := s.cfg.Chain
+	best := chain.BestSnapshot()
+
+	// The state is reported for the block after the current best block.
+	deployments, err := bip9Deployments(chain, params, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range buriedDeployments(params) {
+		height := deployment.height
+		deployments[deployment.name] = &btcjson.DeploymentInfo{
+			Type:   "buried",
+			Active: best.Height+1 >= height,
+			Height: &height,
 		}
-		chainInfo.SoftForks.Bip9SoftForks[forkName] = &btcjson.Bip9SoftForkDescription{
-			Status:              strings.ToLower(statusString),
-			Bit:                 deploymentDetails.BitNumber,
-			StartTime2:          startTime,
-			Timeout:             endTime,
-			MinActivationHeight: int32(deploymentDetails.MinActivationHeight),
+	}
+	result := &btcjson.GetDeploymentInfoResult{
+		Hash:        best.Hash.String(),
+		Height:      best.Height,
+		Deployments: deployments,
+	}
+
+	// Report the additional version bits signalled in generated blocks.
+	signalBits := s.cfg.Generator.SignalBits()
+	for bit := uint32(0); bit <= mining.MaxSignalBit; bit++ {
+		if signalBits&(1<<bit) != 0 {
+			result.SignalBits = append(result.SignalBits, bit)
 		}
 	}
 
-	return chainInfo, nil
+	return result, nil
 }
 
 // handleGetBlockCount implements the getblockcount command.
@@ -1326,6 +2618,52 @@ func handleGetBlockCount(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 	return int64(best.Height), nil
 }
 
+// handleGetBlockBenchmarks implements the getblockbenchmarks command.
+func handleGetBlockBenchmarks(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetBlockBenchmarksCmd)
+
+	count := 10
+	if c.Count != nil {
+		count = *c.Count
+	}
+	if count < 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Count must not be negative",
+		}
+	}
+
+	// Return the requested number of the most recent benchmarks with the
+	// durations in milliseconds.
+	benchmarks := s.cfg.Chain.BlockBenchmarks()
+	if count < len(benchmarks) {
+		benchmarks = benchmarks[len(benchmarks)-count:]
+	}
+	millis := func(d time.Duration) float64 {
+		return float64(d) / float64(time.Millisecond)
+	}
+	ret := make([]btcjson.GetBlockBenchmarksResult, 0, len(benchmarks))
+	for _, bench := range benchmarks {
+		ret = append(ret, btcjson.GetBlockBenchmarksResult{
+			Hash:       bench.Hash.String(),
+			Height:     bench.Height,
+			Txns:       bench.NumTxns,
+			Inputs:     bench.NumInputs,
+			Check:      millis(bench.Check),
+			Store:      millis(bench.Store),
+			LoadInputs: millis(bench.LoadInputs),
+			Scripts:    millis(bench.Scripts),
+			UtxoUpdate: millis(bench.UtxoUpdate),
+			Write:      millis(bench.Write),
+			Index:      millis(bench.Index),
+			Flush:      millis(bench.Flush),
+			Total:      millis(bench.Total),
+		})
+	}
+
+	return ret, nil
+}
+
 // handleGetBlockHash implements the getblockhash command.
 func handleGetBlockHash(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.GetBlockHashCmd)
@@ -1390,6 +2728,17 @@ func handleGetBlockHeader(s *rpcServer, cmd interface{}, closeChan <-chan struct
 		nextHashString = nextHash.String()
 	}
 
+	medianTime, err := s.cfg.Chain.MedianTimeByHash(hash)
+	if err != nil {
+		context := "Failed to obtain block median time"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	chainWork, err := s.cfg.Chain.ChainWorkByHash(hash)
+	if err != nil {
+		context := "Failed to obtain chain work"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
 	params := s.cfg.ChainParams
 	blockHeaderReply := btcjson.GetBlockHeaderVerboseResult{
 		Hash:          c.Hash,
@@ -1404,10 +2753,83 @@ func handleGetBlockHeader(s *rpcServer, cmd interface{}, closeChan <-chan struct
 		Time:          blockHeader.Timestamp.Unix(),
 		Bits:          strconv.FormatInt(int64(blockHeader.Bits), 16),
 		Difficulty:    getDifficultyRatio(blockHeader.Bits, params),
+		MedianTime:    medianTime.Unix(),
+		ChainWork:     formatWork(chainWork),
+		Work:          formatWork(blockchain.CalcWork(blockHeader.Bits)),
 	}
 	return blockHeaderReply, nil
 }
 
+// handleGetBlockRange implements the getblockrange command.
+func handleGetBlockRange(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetBlockRangeCmd)
+
+	best := s.cfg.Chain.BestSnapshot()
+	if c.StartHeight < 0 || c.EndHeight > best.Height {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCOutOfRange,
+			Message: "Block number out of range",
+		}
+	}
+	if c.StartHeight > c.EndHeight ||
+		c.EndHeight-c.StartHeight >= maxGetBlockRangeBlocks {
+
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("The end height must not be below "+
+				"the start height and at most %d blocks can be "+
+				"requested", maxGetBlockRangeBlocks),
+		}
+	}
+
+	// Return the serialized blocks along with the outputs they spend so the
+	// previous outputs of their inputs don't have to be looked up.
+	ret := make([]btcjson.GetBlockRangeResult, 0,
+		c.EndHeight-c.StartHeight+1)
+	err := s.cfg.Chain.ForEachBlock(c.StartHeight, c.EndHeight,
+		func(block *btcutil.Block, stxos []blockchain.SpentTxOut) error {
+			select {
+			case <-closeChan:
+				return ErrClientQuit
+			default:
+			}
+
+			blkBytes, err := block.Bytes()
+			if err != nil {
+				return err
+			}
+			spentOutputs := make([]btcjson.SpentOutputResult, 0,
+				len(stxos))
+			for _, stxo := range stxos {
+				spentOutputs = append(spentOutputs,
+					btcjson.SpentOutputResult{
+						Value:        btcutil.Amount(stxo.Amount).ToBTC(),
+						ScriptPubKey: hex.EncodeToString(stxo.PkScript),
+						Height:       stxo.Height,
+						Coinbase:     stxo.IsCoinBase,
+					})
+			}
+			ret = append(ret, btcjson.GetBlockRangeResult{
+				Hash:         block.Hash().String(),
+				Height:       block.Height(),
+				Hex:          hex.EncodeToString(blkBytes),
+				SpentOutputs: spentOutputs,
+			})
+			return nil
+		})
+	if err == ErrClientQuit {
+		return nil, err
+	}
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "Unable to load blocks: " + err.Error(),
+		}
+	}
+
+	return ret, nil
+}
+
 // encodeTemplateID encodes the passed details into an ID that can be used to
 // uniquely identify a block template.
 func encodeTemplateID(prevHash *chainhash.Hash, lastGenerated time.Time) string {
@@ -1493,15 +2915,42 @@ func (state *gbtWorkState) NotifyBlockConnected(blockHash *chainhash.Hash) {
 		state.Lock()
 		defer state.Unlock()
 
-		state.notifyLongPollers(blockHash, state.lastTxUpdate)
-	}()
+		state.notifyLongPollers(blockHash, state.lastTxUpdate)
+	}()
+}
+
+// notifyTemplateCompleted waits until the full template which replaces the
+// passed incomplete template is available and then notifies all long poll
+// clients waiting for a block template update for the passed block hash.
+//
+// It must be run as a goroutine.
+func (state *gbtWorkState) notifyTemplateCompleted(template *mining.BlockTemplate,
+	prevHash *chainhash.Hash) {
+
+	<-template.Completed()
+
+	state.Lock()
+	defer state.Unlock()
+
+	// Only the long poll clients which are waiting for an update of the
+	// incomplete template need to be notified.
+	if state.template != template {
+		return
+	}
+	if channels, ok := state.notifyMap[*prevHash]; ok {
+		for _, c := range channels {
+			close(c)
+		}
+		delete(state.notifyMap, *prevHash)
+	}
 }
 
 // NotifyMempoolTx uses the new last updated time for the transaction memory
-// pool to notify any long poll clients with a new block template when their
-// existing block template is stale due to enough time passing and the contents
-// of the memory pool changing.
-func (state *gbtWorkState) NotifyMempoolTx(lastUpdated time.Time) {
+// pool and the fee of the new transaction to notify any long poll clients with
+// a new block template when their existing block template is stale due to the
+// contents of the memory pool changing and enough time passing or the fees
+// available to miners increasing significantly.
+func (state *gbtWorkState) NotifyMempoolTx(lastUpdated time.Time, fee int64) {
 	go func() {
 		state.Lock()
 		defer state.Unlock()
@@ -1512,14 +2961,66 @@ func (state *gbtWorkState) NotifyMempoolTx(lastUpdated time.Time) {
 			return
 		}
 
-		if time.Now().After(state.lastGenerated.Add(time.Second *
-			gbtRegenerateSeconds)) {
-
+		state.newFees += fee
+		if state.regenerateDue(time.Now()) {
 			state.notifyLongPollers(state.prevHash, lastUpdated)
 		}
 	}()
 }
 
+// templateCompleted returns whether the passed block template is an incomplete
+// template whose full version is now available.
+func templateCompleted(template *mining.BlockTemplate) bool {
+	if !template.Incomplete {
+		return false
+	}
+	select {
+	case <-template.Completed():
+		return true
+	default:
+		return false
+	}
+}
+
+// regenerateDue returns whether or not a new block template should be
+// generated at the passed time when the transactions in the memory pool have
+// changed since the current template was generated.  This is the case once
+// gbtRegenerateSeconds have passed, or gbtMinRegenerateSeconds have passed and
+// the new transactions pay at least gbtSignificantFeePercent of the fees of
+// the current template.
+//
+// This function MUST be called with the state locked.
+func (state *gbtWorkState) regenerateDue(now time.Time) bool {
+	if now.After(state.lastGenerated.Add(time.Second *
+		gbtRegenerateSeconds)) {
+
+		return true
+	}
+	if state.template == nil || !now.After(state.lastGenerated.Add(
+		time.Second*gbtMinRegenerateSeconds)) {
+
+		return false
+	}
+
+	// The fee of the coinbase is the negative total of the fees of all
+	// other transactions in the template.
+	templateFees := -state.template.Fees[0]
+	return state.newFees > 0 &&
+		state.newFees*100 >= templateFees*gbtSignificantFeePercent
+}
+
+// mempoolChanged returns whether or not the transactions in the memory pool
+// have changed enough since the current block template was generated for long
+// poll clients to be given a new one.
+func (state *gbtWorkState) mempoolChanged(s *rpcServer) bool {
+	state.Lock()
+	defer state.Unlock()
+
+	lastTxUpdate := s.cfg.Generator.TxSource().LastUpdated()
+	return !lastTxUpdate.IsZero() && lastTxUpdate != state.lastTxUpdate &&
+		state.regenerateDue(time.Now())
+}
+
 // templateUpdateChan returns a channel that will be closed once the block
 // template associated with the passed previous hash and last generated time
 // is stale.  The function will return existing channels for duplicate
@@ -1569,8 +3070,17 @@ func (state *gbtWorkState) updateBlockTemplate(s *rpcServer, useCoinbaseValue bo
 
 	// Generate a new block template when the current best block has
 	// changed or the transactions in the memory pool have been updated and
-	// it has been at least gbtRegenerateSecond since the last template was
-	// generated.
+	// it has been at least gbtRegenerateSeconds since the last template was
+	// generated, or less when the new transactions significantly increase
+	// the available fees.
+	//
+	// Templates which are redeemable by anyone also have to be regenerated
+	// when the caller requires a full coinbase and the coinbase is split
+	// between several payouts since adding the extra outputs could make
+	// the block exceed the maximum weight.
+	//
+	// Templates which were handed out without any transactions on a new
+	// best block are replaced as soon as the full template is available.
 	var msgBlock *wire.MsgBlock
 	var targetDifficulty string
 	latestHash := &s.cfg.Chain.BestSnapshot().Hash
@@ -1578,20 +3088,29 @@ func (state *gbtWorkState) updateBlockTemplate(s *rpcServer, useCoinbaseValue bo
 	if template == nil || state.prevHash == nil ||
 		!state.prevHash.IsEqual(latestHash) ||
 		(state.lastTxUpdate != lastTxUpdate &&
-			time.Now().After(state.lastGenerated.Add(time.Second*
-				gbtRegenerateSeconds))) {
+			state.regenerateDue(time.Now())) ||
+		(!useCoinbaseValue && !template.ValidPayAddress &&
+			len(cfg.miningPayouts) > 0) || templateCompleted(template) {
 
 		// Reset the previous best hash the block template was generated
 		// against so any errors below cause the next invocation to try
 		// again.
 		state.prevHash = nil
 
-		// Choose a payment address at random if the caller requests a
-		// full coinbase as opposed to only the pertinent details needed
-		// to create their own coinbase.
-		var payAddr address.Address
+		// Split the coinbase between the configured payouts or choose a
+		// payment address at random if the caller requests a full
+		// coinbase as opposed to only the pertinent details needed to
+		// create their own coinbase.
+		var payouts []mining.Payout
 		if !useCoinbaseValue {
-			payAddr = cfg.miningAddrs[rand.Intn(len(cfg.miningAddrs))]
+			payouts = cfg.miningPayouts
+			if len(payouts) == 0 {
+				payAddr := cfg.miningAddrs[rand.Intn(len(cfg.miningAddrs))]
+				payouts = []mining.Payout{{
+					Address: payAddr,
+					Weight:  mining.PayoutWeightTotal,
+				}}
+			}
 		}
 
 		// Create a new block template that has a coinbase which anyone
@@ -1599,7 +3118,7 @@ func (state *gbtWorkState) updateBlockTemplate(s *rpcServer, useCoinbaseValue bo
 		// block template doesn't include the coinbase, so the caller
 		// will ultimately create their own coinbase which pays to the
 		// appropriate address(es).
-		blkTemplate, err := generator.NewBlockTemplate(payAddr)
+		blkTemplate, err := generator.NewSplitBlockTemplate(payouts)
 		if err != nil {
 			return internalRPCError("Failed to create new block "+
 				"template: "+err.Error(), "")
@@ -1620,6 +3139,7 @@ func (state *gbtWorkState) updateBlockTemplate(s *rpcServer, useCoinbaseValue bo
 		state.template = template
 		state.lastGenerated = time.Now()
 		state.lastTxUpdate = lastTxUpdate
+		state.newFees = 0
 		state.prevHash = latestHash
 		state.minTimestamp = minTimestamp
 
@@ -1631,6 +3151,12 @@ func (state *gbtWorkState) updateBlockTemplate(s *rpcServer, useCoinbaseValue bo
 		// Notify any clients that are long polling about the new
 		// template.
 		state.notifyLongPollers(latestHash, lastTxUpdate)
+
+		// Notify the clients that are long polling once the full
+		// template is available when the template is incomplete.
+		if template.Incomplete {
+			go state.notifyTemplateCompleted(template, latestHash)
+		}
 	} else {
 		// At this point, there is a saved block template and another
 		// request for a template was made, but either the available
@@ -1784,6 +3310,11 @@ func (state *gbtWorkState) blockTemplateResult(useCoinbaseValue bool, submitOld
 		Mutable:      gbtMutableFields,
 		NonceRange:   gbtNonceRange,
 		Capabilities: gbtCapabilities,
+		ClaimTxs: &btcjson.GetBlockTemplateResultClaims{
+			Claims:   int64(template.ClaimCounts.Claims),
+			Supports: int64(template.ClaimCounts.Supports),
+			Updates:  int64(template.ClaimCounts.Updates),
+		},
 	}
 	// If the generated block template includes transactions with witness
 	// data, then include the witness commitment in the GBT result.
@@ -1824,11 +3355,32 @@ func (state *gbtWorkState) blockTemplateResult(useCoinbaseValue bool, submitOld
 		}
 
 		reply.CoinbaseTxn = &resultTx
+		reply.Mutable = gbtCoinbaseTxnMutableFields
 	}
 
 	return &reply, nil
 }
 
+// handleGetBlockRepairs implements the getblockrepairs command.
+func handleGetBlockRepairs(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	events := s.cfg.SyncMgr.BlockRepairs()
+	ret := make([]btcjson.BlockRepairResult, 0, len(events))
+	for _, event := range events {
+		result := btcjson.BlockRepairResult{
+			Hash:     event.hash.String(),
+			Height:   event.height,
+			Detected: event.detected.Unix(),
+			Status:   "pending",
+		}
+		if !event.repaired.IsZero() {
+			result.Repaired = event.repaired.Unix()
+			result.Status = "repaired"
+		}
+		ret = append(ret, result)
+	}
+	return ret, nil
+}
+
 // handleGetBlockTemplateLongPoll is a helper for handleGetBlockTemplateRequest
 // which deals with handling long polling for block templates.  When a caller
 // sends a request with a long poll ID that was previously returned, a response
@@ -1894,15 +3446,28 @@ func handleGetBlockTemplateLongPoll(s *rpcServer, longPollID string, useCoinbase
 	longPollChan := state.templateUpdateChan(prevHash, lastGenerated)
 	state.Unlock()
 
-	select {
-	// When the client closes before it's time to send a reply, just return
-	// now so the goroutine doesn't hang around.
-	case <-closeChan:
-		return nil, ErrClientQuit
-
-	// Wait until signal received to send the reply.
-	case <-longPollChan:
-		// Fallthrough
+	// Periodically check the memory pool as well since it might have
+	// changed enough for a new template without any new transactions
+	// arriving afterwards to trigger a notification.
+	ticker := time.NewTicker(time.Second * gbtMinRegenerateSeconds)
+	defer ticker.Stop()
+out:
+	for {
+		select {
+		// When the client closes before it's time to send a reply, just
+		// return now so the goroutine doesn't hang around.
+		case <-closeChan:
+			return nil, ErrClientQuit
+
+		// Wait until signal received to send the reply.
+		case <-longPollChan:
+			break out
+
+		case <-ticker.C:
+			if state.mempoolChanged(s) {
+				break out
+			}
+		}
 	}
 
 	// Get the lastest block template
@@ -2148,8 +3713,33 @@ func handleGetBlockTemplateProposal(s *rpcServer, request *btcjson.TemplateReque
 			Message: "Block decode failed: " + err.Error(),
 		}
 	}
+
+	// Miners which modify the coinbase transaction might drop its witness,
+	// so restore the witness nonce the witness commitment is based on.
+	mining.AddWitnessNonce(&msgBlock)
 	block := btcutil.NewBlock(&msgBlock)
 
+	// Report blocks which are already known without validating them again.
+	// Blocks in the main chain are fully validated while the validity of
+	// side chain and orphan blocks is unknown unless they were found to be
+	// invalid.
+	blockHash := block.Hash()
+	haveBlock, err := s.cfg.Chain.HaveBlock(blockHash)
+	if err != nil {
+		context := "Failed to check for duplicate block proposal"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	if haveBlock {
+		switch {
+		case s.cfg.Chain.MainChainHasBlock(blockHash):
+			return "duplicate", nil
+		case s.cfg.Chain.IsKnownInvalidBlock(blockHash):
+			return "duplicate-invalid", nil
+		default:
+			return "duplicate-inconclusive", nil
+		}
+	}
+
 	// Ensure the block is building from the expected previous block.
 	expectedPrevHash := s.cfg.Chain.BestSnapshot().Hash
 	prevHash := &block.MsgBlock().Header.PrevBlock
@@ -2223,23 +3813,50 @@ func handleGetChainTips(s *rpcServer, cmd interface{}, closeChan <-chan struct{}
 	return ret, nil
 }
 
-// handleGetCFilter implements the getcfilter command.
-func handleGetCFilter(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+// rpcFilterIndex is implemented by the indexes which serve committed filters.
+type rpcFilterIndex interface {
+	FilterByBlockHash(*chainhash.Hash, wire.FilterType) ([]byte, error)
+	FilterHeaderByBlockHash(*chainhash.Hash, wire.FilterType) ([]byte, error)
+}
+
+// filterIndex returns the index which serves the committed filters of the
+// passed type or an error when the index is not enabled.
+func (s *rpcServer) filterIndex(filterType wire.FilterType) (rpcFilterIndex, error) {
+	if filterType == indexers.GCSFilterClaimName {
+		if s.cfg.ClaimFilters == nil {
+			return nil, &btcjson.RPCError{
+				Code: btcjson.ErrRPCNoCFIndex,
+				Message: "The claim name filter index must be " +
+					"enabled for this command (--claimfilters)",
+			}
+		}
+		return s.cfg.ClaimFilters, nil
+	}
 	if s.cfg.CfIndex == nil {
 		return nil, &btcjson.RPCError{
 			Code:    btcjson.ErrRPCNoCFIndex,
 			Message: "The CF index must be enabled for this command",
 		}
 	}
+	return s.cfg.CfIndex, nil
+}
 
+// handleGetCFilter implements the getcfilter command.
+func handleGetCFilter(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.GetCFilterCmd)
+	filterIndex, err := s.filterIndex(c.FilterType)
+	if err != nil {
+		return nil, err
+	}
+
 	hash, err := chainhash.NewHashFromStr(c.Hash)
 	if err != nil {
 		return nil, rpcDecodeHexError(c.Hash)
 	}
 
-	filterBytes, err := s.cfg.CfIndex.FilterByBlockHash(hash, c.FilterType)
-	if err != nil {
+	// The filters of pruned blocks are deleted along with them.
+	filterBytes, err := filterIndex.FilterByBlockHash(hash, c.FilterType)
+	if err != nil || len(filterBytes) == 0 {
 		rpcsLog.Debugf("Could not find committed filter for %v: %v",
 			hash, err)
 		return nil, &btcjson.RPCError{
@@ -2254,20 +3871,18 @@ func handleGetCFilter(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 
 // handleGetCFilterHeader implements the getcfilterheader command.
 func handleGetCFilterHeader(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
-	if s.cfg.CfIndex == nil {
-		return nil, &btcjson.RPCError{
-			Code:    btcjson.ErrRPCNoCFIndex,
-			Message: "The CF index must be enabled for this command",
-		}
+	c := cmd.(*btcjson.GetCFilterHeaderCmd)
+	filterIndex, err := s.filterIndex(c.FilterType)
+	if err != nil {
+		return nil, err
 	}
 
-	c := cmd.(*btcjson.GetCFilterHeaderCmd)
 	hash, err := chainhash.NewHashFromStr(c.Hash)
 	if err != nil {
 		return nil, rpcDecodeHexError(c.Hash)
 	}
 
-	headerBytes, err := s.cfg.CfIndex.FilterHeaderByBlockHash(hash, c.FilterType)
+	headerBytes, err := filterIndex.FilterHeaderByBlockHash(hash, c.FilterType)
 	if len(headerBytes) > 0 {
 		rpcsLog.Debugf("Found header of committed filter for %v", hash)
 	} else {
@@ -2283,16 +3898,162 @@ func handleGetCFilterHeader(s *rpcServer, cmd interface{}, closeChan <-chan stru
 	return hash.String(), nil
 }
 
+// handleGetCheckpoints implements the getcheckpoints command.
+func handleGetCheckpoints(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	checkpoints := s.cfg.Chain.Checkpoints()
+
+	ret := make([]btcjson.GetCheckpointsResult, 0, len(checkpoints))
+	for _, checkpoint := range checkpoints {
+		ret = append(ret, btcjson.GetCheckpointsResult{
+			Height:      checkpoint.Height,
+			Hash:        checkpoint.Hash.String(),
+			InMainChain: s.cfg.Chain.MainChainHasBlock(checkpoint.Hash),
+		})
+	}
+
+	return ret, nil
+}
+
+// claimHistoryResults converts the passed claim index entries to their
+// JSON-RPC representation.
+func claimHistoryResults(entries []indexers.ClaimEntry) []btcjson.ClaimHistoryResult {
+	results := make([]btcjson.ClaimHistoryResult, 0, len(entries))
+	for _, entry := range entries {
+		results = append(results, btcjson.ClaimHistoryResult{
+			ClaimID: hex.EncodeToString(entry.ClaimID),
+			Name:    string(entry.Name),
+			TxID:    entry.TxHash.String(),
+			Vout:    entry.Index,
+			Height:  entry.Height,
+			Type:    entry.Op.String(),
+		})
+	}
+	return results
+}
+
+// errClaimIndexDisabled is returned by the claim history commands when the
+// claim index is not enabled.
+var errClaimIndexDisabled = &btcjson.RPCError{
+	Code:    btcjson.ErrRPCMisc,
+	Message: "Claim index must be enabled (--claimindex)",
+}
+
+// handleGetClaimHistory implements the getclaimhistory command.
+func handleGetClaimHistory(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetClaimHistoryCmd)
+
+	if s.cfg.ClaimIndex == nil {
+		return nil, errClaimIndexDisabled
+	}
+	claimID, err := hex.DecodeString(c.ClaimID)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.ClaimID)
+	}
+	if len(claimID) != txscript.ClaimIDSize {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Claim ID must be %d bytes",
+				txscript.ClaimIDSize),
+		}
+	}
+
+	entries, err := s.cfg.ClaimIndex.ClaimHistoryByID(claimID)
+	if err != nil {
+		context := "Failed to fetch claim history"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	return claimHistoryResults(entries), nil
+}
+
+// handleGetConflicts implements the getconflicts command.
+func handleGetConflicts(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetConflictsCmd)
+
+	txHash, err := chainhash.NewHashFromStr(c.TxID)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.TxID)
+	}
+
+	doubleSpends, ok := s.cfg.TxMemPool.DoubleSpends(txHash)
+	if !ok {
+		return nil, rpcNoTxInfoError(txHash)
+	}
+
+	results := make([]btcjson.GetConflictsResult, 0, len(doubleSpends))
+	for _, ds := range doubleSpends {
+		hexStr, err := messageToHex(ds.Tx.MsgTx())
+		if err != nil {
+			return nil, err
+		}
+
+		outpoints := make([]string, 0, len(ds.Outpoints))
+		for _, op := range ds.Outpoints {
+			outpoints = append(outpoints, op.String())
+		}
+
+		results = append(results, btcjson.GetConflictsResult{
+			TxID:      ds.Tx.Hash().String(),
+			Hex:       hexStr,
+			Time:      ds.Seen.Unix(),
+			Outpoints: outpoints,
+		})
+	}
+
+	return results, nil
+}
+
 // handleGetConnectionCount implements the getconnectioncount command.
 func handleGetConnectionCount(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	return s.cfg.ConnMgr.ConnectedCount(), nil
 }
 
+// connectionTargetsResult returns the passed connection targets in the form
+// returned by the getconnectiontargets and setconnectiontargets commands.
+func connectionTargetsResult(targets connmgr.Targets) *btcjson.ConnectionTargetsResult {
+	return &btcjson.ConnectionTargetsResult{
+		Outbound:       targets.Outbound,
+		BlockRelayOnly: targets.BlockRelayOnly,
+		Onion:          targets.Onion,
+		MaxPeers:       cfg.MaxPeers,
+	}
+}
+
+// handleGetConnectionTargets implements the getconnectiontargets command.
+func handleGetConnectionTargets(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	return connectionTargetsResult(s.cfg.ConnMgr.ConnectionTargets()), nil
+}
+
 // handleGetCurrentNet implements the getcurrentnet command.
 func handleGetCurrentNet(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	return s.cfg.ChainParams.Net, nil
 }
 
+// handleGetDBInfo implements the getdbinfo command.
+func handleGetDBInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	usage, err := ffldb.DiskUsage(s.cfg.DB)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "Unable to load the disk usage: " + err.Error(),
+		}
+	}
+
+	buckets := make([]btcjson.DBBucketResult, 0, len(usage.Buckets))
+	for _, bucket := range usage.Buckets {
+		buckets = append(buckets, btcjson.DBBucketResult{
+			Name: bucket.Name,
+			Size: bucket.Size,
+		})
+	}
+	return &btcjson.GetDBInfoResult{
+		BlockFiles:     usage.BlockFiles,
+		BlockFilesSize: usage.BlockFilesSize,
+		MetadataSize:   usage.MetadataSize,
+		Buckets:        buckets,
+		Compacting:     atomic.LoadInt32(&s.compacting) != 0,
+	}, nil
+}
+
 // handleGetDifficulty implements the getdifficulty command.
 func handleGetDifficulty(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	best := s.cfg.Chain.BestSnapshot()
@@ -2350,6 +4111,31 @@ func handleGetHeaders(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 	return hexBlockHeaders, nil
 }
 
+// handleGetIndexInfo implements the getindexinfo command.
+func handleGetIndexInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetIndexInfoCmd)
+
+	result := make(map[string]btcjson.GetIndexInfoResult)
+	if s.cfg.IndexManager == nil {
+		return result, nil
+	}
+	infos, err := s.cfg.IndexManager.IndexInfo()
+	if err != nil {
+		context := "Failed to fetch index info"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	for _, info := range infos {
+		if c.IndexName != nil && *c.IndexName != info.Name {
+			continue
+		}
+		result[info.Name] = btcjson.GetIndexInfoResult{
+			Synced:          info.Synced,
+			BestBlockHeight: info.Height,
+		}
+	}
+	return result, nil
+}
+
 // handleGetInfo implements the getinfo command. We only return the fields
 // that are not related to wallet functionality.
 func handleGetInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
@@ -2364,23 +4150,48 @@ func handleGetInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (in
 		Difficulty:      getDifficultyRatio(best.Bits, s.cfg.ChainParams),
 		TestNet:         cfg.TestNet3 || cfg.TestNet4,
 		RelayFee:        cfg.minRelayTxFee.ToBTC(),
+		Errors:          clockSkewMessage(s),
+	}
+
+	// The lack of free disk space takes precedence over the clock skew
+	// since it keeps blocks from being processed at all.
+	if warning := s.cfg.SyncMgr.DiskSpaceWarning(); warning != "" {
+		ret.Errors = warning
 	}
 
 	return ret, nil
 }
 
+// clockSkewMessage returns the warning reported when the local clock is off
+// from the time of the network or an empty string when it is not.
+func clockSkewMessage(s *rpcServer) string {
+	state, offset := s.cfg.ConnMgr.ClockSkew()
+	if state != clockSkewWarning && state != clockSkewExceeded {
+		return ""
+	}
+	return "Please check your date and time are correct -- " +
+		describeClockSkew(offset)
+}
+
 // handleGetMempoolInfo implements the getmempoolinfo command.
 func handleGetMempoolInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	mempoolTxns := s.cfg.TxMemPool.TxDescs()
 
 	var numBytes int64
 	for _, txD := range mempoolTxns {
-		numBytes += int64(txD.Tx.MsgTx().SerializeSize())
+		numBytes += txD.VirtualSize
 	}
 
+	orphanStats := s.cfg.TxMemPool.OrphanStats()
 	ret := &btcjson.GetMempoolInfoResult{
-		Size:  int64(len(mempoolTxns)),
-		Bytes: numBytes,
+		Size:               int64(len(mempoolTxns)),
+		Bytes:              numBytes,
+		Orphans:            int64(orphanStats.Count),
+		OrphanWeight:       orphanStats.Weight,
+		OrphanPeers:        int64(orphanStats.NumTags),
+		OrphansExpired:     orphanStats.Expired,
+		OrphansEvictedPeer: orphanStats.EvictedTagLimit,
+		OrphansEvictedPool: orphanStats.EvictedPoolLimit,
 	}
 
 	return ret, nil
@@ -2422,13 +4233,76 @@ func handleGetMiningInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 	return &result, nil
 }
 
+// handleGetNameHistory implements the getnamehistory command.
+func handleGetNameHistory(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetNameHistoryCmd)
+
+	if s.cfg.ClaimIndex == nil {
+		return nil, errClaimIndexDisabled
+	}
+	if len(c.Name) > txscript.MaxClaimNameSize {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Name must be at most %d bytes",
+				txscript.MaxClaimNameSize),
+		}
+	}
+
+	entries, err := s.cfg.ClaimIndex.ClaimHistoryByName([]byte(c.Name))
+	if err != nil {
+		context := "Failed to fetch name history"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	return claimHistoryResults(entries), nil
+}
+
+// handleGetNATInfo implements the getnatinfo command.
+func handleGetNATInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	mapping, ok := s.cfg.ConnMgr.NATMapping()
+	if !ok {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCMisc,
+			Message: "No NAT gateway supporting UPnP, NAT-PMP or " +
+				"PCP is in use",
+		}
+	}
+
+	result := btcjson.GetNATInfoResult{
+		Protocol:     mapping.protocol,
+		ExternalPort: mapping.externalPort,
+		InternalPort: mapping.internalPort,
+	}
+	if mapping.externalIP != nil {
+		result.ExternalIP = mapping.externalIP.String()
+	}
+	if !mapping.expires.IsZero() {
+		result.Expires = mapping.expires.Unix()
+	}
+	if mapping.lastErr != nil {
+		result.LastError = mapping.lastErr.Error()
+	}
+	return &result, nil
+}
+
 // handleGetNetTotals implements the getnettotals command.
 func handleGetNetTotals(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	totalBytesRecv, totalBytesSent := s.cfg.ConnMgr.NetTotals()
+	bytesSentPerMsg, bytesRecvPerMsg := s.cfg.ConnMgr.NetTotalsPerMsg()
+	target := s.cfg.ConnMgr.UploadTarget()
 	reply := &btcjson.GetNetTotalsResult{
-		TotalBytesRecv: totalBytesRecv,
-		TotalBytesSent: totalBytesSent,
-		TimeMillis:     time.Now().UTC().UnixNano() / int64(time.Millisecond),
+		TotalBytesRecv:  totalBytesRecv,
+		TotalBytesSent:  totalBytesSent,
+		TimeMillis:      time.Now().UTC().UnixNano() / int64(time.Millisecond),
+		BytesRecvPerMsg: bytesRecvPerMsg,
+		BytesSentPerMsg: bytesSentPerMsg,
+		UploadTarget: btcjson.GetNetTotalsUploadTarget{
+			TimeFrame:             int64(uploadTargetTimeframe.Seconds()),
+			Target:                target.target,
+			TargetReached:         target.reached,
+			ServeHistoricalBlocks: !target.reached,
+			BytesLeftInCycle:      target.bytesLeft,
+			TimeLeftInCycle:       int64(target.timeLeft.Seconds()),
+		},
 	}
 	return reply, nil
 }
@@ -2568,6 +4442,7 @@ func handleGetPeerInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 	infos := make([]*btcjson.GetPeerInfoResult, 0, len(peers))
 	for _, p := range peers {
 		statsSnap := p.ToPeer().StatsSnapshot()
+		txInvRecv, txInvThrottled, txRecv := p.TxRelayStats()
 		info := &btcjson.GetPeerInfoResult{
 			ID:             statsSnap.ID,
 			Addr:           statsSnap.Addr,
@@ -2590,6 +4465,25 @@ func handleGetPeerInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 			FeeFilter:      p.FeeFilter(),
 			SyncNode:       statsSnap.ID == syncPeerID,
 			V2Connection:   statsSnap.V2Connection,
+			TxInvRecv:      txInvRecv,
+			TxInvThrottled: txInvThrottled,
+			TxRecv:         txRecv,
+			UnknownMsgs:    statsSnap.UnknownMsgs,
+			OversizedMsgs:  statsSnap.OversizedMsgs,
+			MalformedMsgs:  statsSnap.MalformedMsgs,
+			Permissions:    p.Permissions(),
+			ConnectionType: p.ConnectionType(),
+		}
+		records := p.Misbehavior()
+		info.Misbehavior = make([]btcjson.PeerMisbehavior, 0, len(records))
+		for _, record := range records {
+			info.Misbehavior = append(info.Misbehavior,
+				btcjson.PeerMisbehavior{
+					Time:     record.time.Unix(),
+					Category: record.category.String(),
+					Reason:   record.reason,
+					BanScore: int32(record.banScore),
+				})
 		}
 		if p.ToPeer().LastPingNonce() != 0 {
 			wait := float64(time.Since(statsSnap.LastPingTime).Nanoseconds())
@@ -2740,6 +4634,89 @@ func handleGetRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan str
 	return *rawTxn, nil
 }
 
+// handleGetSpentInfo implements the getspentinfo command.
+func handleGetSpentInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetSpentInfoCmd)
+
+	if s.cfg.SpentIndex == nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "Spent output index must be enabled (--spentindex)",
+		}
+	}
+	txHash, err := chainhash.NewHashFromStr(c.Request.TxID)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.Request.TxID)
+	}
+	outPoint := wire.OutPoint{Hash: *txHash, Index: c.Request.Index}
+
+	// Outputs spent by transactions in the memory pool aren't in the
+	// index yet, so look for them there first.
+	if spendingTx := s.cfg.TxMemPool.CheckSpend(outPoint); spendingTx != nil {
+		for i, txIn := range spendingTx.MsgTx().TxIn {
+			if txIn.PreviousOutPoint != outPoint {
+				continue
+			}
+			return &btcjson.GetSpentInfoResult{
+				TxID:   spendingTx.Hash().String(),
+				Index:  uint32(i),
+				Height: -1,
+			}, nil
+		}
+	}
+
+	info, err := s.cfg.SpentIndex.SpentInfo(&outPoint)
+	if err != nil {
+		context := "Failed to fetch spent info"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	if info == nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Unable to get spent info",
+		}
+	}
+	return &btcjson.GetSpentInfoResult{
+		TxID:   info.TxHash.String(),
+		Index:  info.InputIndex,
+		Height: info.Height,
+	}, nil
+}
+
+// handleGetStratumInfo implements the getstratuminfo command.
+func handleGetStratumInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	if s.cfg.Stratum == nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "The stratum server must be enabled for this command",
+		}
+	}
+
+	workers := s.cfg.Stratum.Workers()
+	result := btcjson.GetStratumInfoResult{
+		Clients: s.cfg.Stratum.NumClients(),
+		Workers: make([]btcjson.StratumWorkerResult, 0, len(workers)),
+	}
+	for _, w := range workers {
+		var lastShare int64
+		if !w.LastShare.IsZero() {
+			lastShare = w.LastShare.Unix()
+		}
+		result.Workers = append(result.Workers, btcjson.StratumWorkerResult{
+			Name:         w.Name,
+			Connections:  w.Connections,
+			Difficulty:   w.Difficulty,
+			HashesPerSec: w.HashesPerSec,
+			Accepted:     w.Accepted,
+			Rejected:     w.Rejected,
+			Stale:        w.Stale,
+			Blocks:       w.Blocks,
+			LastShare:    lastShare,
+		})
+	}
+	return &result, nil
+}
+
 // handleGetTxOut handles gettxout commands.
 func handleGetTxOut(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.GetTxOutCmd)
@@ -2838,22 +4815,102 @@ func handleGetTxOut(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 		address = addresses[0]
 	}
 
-	txOutReply := &btcjson.GetTxOutResult{
-		BestBlock:     bestBlockHash,
-		Confirmations: int64(confirmations),
-		Value:         btcutil.Amount(value).ToBTC(),
-		ScriptPubKey: btcjson.ScriptPubKeyResult{
-			Asm:       disbuf,
-			Hex:       hex.EncodeToString(pkScript),
-			ReqSigs:   int32(reqSigs),
-			Type:      scriptClass.String(),
-			Address:   address,
-			Addresses: addresses,
-		},
-		Coinbase: isCoinbase,
+	txOutReply := &btcjson.GetTxOutResult{
+		BestBlock:     bestBlockHash,
+		Confirmations: int64(confirmations),
+		Value:         btcutil.Amount(value).ToBTC(),
+		ScriptPubKey: btcjson.ScriptPubKeyResult{
+			Asm:       disbuf,
+			Hex:       hex.EncodeToString(pkScript),
+			ReqSigs:   int32(reqSigs),
+			Type:      scriptClass.String(),
+			Address:   address,
+			Addresses: addresses,
+		},
+		Coinbase: isCoinbase,
+	}
+
+	return txOutReply, nil
+}
+
+// handleGetTxOutSetInfo implements the gettxoutsetinfo command.
+func handleGetTxOutSetInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetTxOutSetInfoCmd)
+
+	stats, err := s.cfg.Chain.FetchUtxoSetStats()
+	if err != nil {
+		context := "Failed to fetch utxo set statistics"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	result := &btcjson.GetTxOutSetInfoResult{
+		Height:         int64(stats.Height),
+		BestBlock:      stats.Hash,
+		Transactions:   stats.Transactions,
+		TxOuts:         stats.TxOuts,
+		BogoSize:       stats.BogoSize,
+		HashSerialized: stats.SerializedHash,
+		DiskSize:       stats.DiskSize,
+		TotalAmount:    btcutil.Amount(stats.TotalAmount),
+	}
+	if c.Verbose != nil && *c.Verbose {
+		result.ScriptClasses = make(map[string]btcjson.TxOutSetScriptClass,
+			len(stats.ScriptClasses))
+		for class, classStats := range stats.ScriptClasses {
+			result.ScriptClasses[class.String()] = btcjson.TxOutSetScriptClass{
+				TxOuts:      classStats.TxOuts,
+				TotalAmount: btcutil.Amount(classStats.Amount).ToBTC(),
+			}
+		}
+
+		claimStats := map[string]blockchain.ScriptClassStats{
+			"claim":   stats.Claims,
+			"support": stats.Supports,
+			"update":  stats.Updates,
+		}
+		result.ClaimScripts = make(map[string]btcjson.TxOutSetScriptClass,
+			len(claimStats))
+		for kind, kindStats := range claimStats {
+			result.ClaimScripts[kind] = btcjson.TxOutSetScriptClass{
+				TxOuts:      kindStats.TxOuts,
+				TotalAmount: btcutil.Amount(kindStats.Amount).ToBTC(),
+			}
+		}
+	}
+	return result, nil
+}
+
+// handleImportBlocks implements the importblocks command.
+func handleImportBlocks(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.ImportBlocksCmd)
+
+	if len(c.Files) == 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "No block files given",
+		}
+	}
+
+	// Ensure all of the files can be opened before starting the import in
+	// the background.
+	for _, path := range c.Files {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParameter,
+				Message: "Unable to open block file: " + err.Error(),
+			}
+		}
+		f.Close()
 	}
 
-	return txOutReply, nil
+	if err := s.cfg.SyncMgr.ImportBlocks(c.Files); err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: err.Error(),
+		}
+	}
+	return nil, nil
 }
 
 // handleInvalidateBlock implements the invalidateblock command.
@@ -2873,6 +4930,23 @@ func handleInvalidateBlock(s *rpcServer, cmd interface{}, closeChan <-chan struc
 	return nil, err
 }
 
+// handleListBanned implements the listbanned command.
+func handleListBanned(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	banned := s.cfg.ConnMgr.BannedAddrs()
+	now := time.Now().Unix()
+	reply := make([]btcjson.ListBannedResult, 0, len(banned))
+	for _, ban := range banned {
+		reply = append(reply, btcjson.ListBannedResult{
+			Address:       ban.host,
+			BanCreated:    ban.created.Unix(),
+			BannedUntil:   ban.until.Unix(),
+			BanDuration:   ban.until.Unix() - ban.created.Unix(),
+			TimeRemaining: ban.until.Unix() - now,
+		})
+	}
+	return reply, nil
+}
+
 // handleHelp implements the help command.
 func handleHelp(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.HelpCmd)
@@ -2925,6 +4999,56 @@ func handlePing(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (inter
 	return nil, nil
 }
 
+// handlePruneBlockchain implements the pruneblockchain command.
+func handlePruneBlockchain(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.PruneBlockchainCmd)
+
+	// Pruning a node which is not in prune mode would leave it unable to
+	// start without the prune option.
+	if cfg.Prune == 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "Cannot prune blocks because node is not in prune mode",
+		}
+	}
+	if c.Height < 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Negative block height",
+		}
+	}
+	best := s.cfg.Chain.BestSnapshot()
+	if c.Height > int64(best.Height) {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Blockchain is shorter than the attempted prune height",
+		}
+	}
+
+	// Keep the blocks a node signaling NODE_NETWORK_LIMITED must be able to
+	// serve, which are also plenty to handle reorgs.
+	height := int32(c.Height)
+	maxHeight := best.Height - wire.NodeNetworkLimitedBlockThreshold
+	if height > maxHeight {
+		rpcsLog.Debugf("Attempt to prune blocks close to the tip -- "+
+			"pruning up to height %d instead", maxHeight)
+		height = maxHeight
+	}
+	if height >= 0 {
+		if err := s.cfg.Chain.PruneBlocks(height); err != nil {
+			context := "Failed to prune blocks"
+			return nil, internalRPCError(err.Error(), context)
+		}
+	}
+
+	pruneHeight, err := s.cfg.Chain.PruneHeight()
+	if err != nil {
+		context := "Failed to determine the prune height"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	return int64(pruneHeight) - 1, nil
+}
+
 // retrievedTx represents a transaction that was either loaded from the
 // transaction memory pool or from the database.  When a transaction is loaded
 // from the database, it is loaded with the raw serialized bytes while the
@@ -3148,6 +5272,70 @@ func fetchMempoolTxnsForAddress(s *rpcServer, addr address.Address, numToSkip,
 	return mpTxns[numToSkip:rangeEnd], numToSkip
 }
 
+// checkIndexEnabled returns an error when the index with the passed name isn't
+// enabled.
+func checkIndexEnabled(s *rpcServer, name string) error {
+	if s.cfg.IndexManager != nil {
+		infos, err := s.cfg.IndexManager.IndexInfo()
+		if err != nil {
+			context := "Failed to fetch index info"
+			return internalRPCError(err.Error(), context)
+		}
+		for _, info := range infos {
+			if info.Name == name {
+				return nil
+			}
+		}
+	}
+
+	return &btcjson.RPCError{
+		Code:    btcjson.ErrRPCInvalidParameter,
+		Message: fmt.Sprintf("The %s is not enabled", name),
+	}
+}
+
+// handleRebuildIndex implements the rebuildindex command.
+func handleRebuildIndex(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.RebuildIndexCmd)
+
+	if err := checkIndexEnabled(s, c.IndexName); err != nil {
+		return nil, err
+	}
+	if !atomic.CompareAndSwapInt32(&s.rebuilding, 0, 1) {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "An index is already being rebuilt",
+		}
+	}
+
+	// Rebuilding an index takes a long time, so it is done in the
+	// background and logged once it is done.  The rebuild is interrupted
+	// when the server shuts down and finished on the next start.
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer atomic.StoreInt32(&s.rebuilding, 0)
+
+		interrupt := make(chan struct{})
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			select {
+			case <-s.quit:
+				close(interrupt)
+			case <-done:
+			}
+		}()
+
+		err := s.cfg.IndexManager.RebuildIndex(c.IndexName, interrupt)
+		if err != nil && !interruptRequested(interrupt) {
+			rpcsLog.Errorf("Unable to rebuild the %s: %v", c.IndexName,
+				err)
+		}
+	}()
+	return nil, nil
+}
+
 // handleReconsiderBlock implements the reconsiderblock command.
 func handleReconsiderBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.ReconsiderBlockCmd)
@@ -3531,6 +5719,63 @@ func handleSendRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan st
 	return tx.Hash().String(), nil
 }
 
+// handleSetBan implements the setban command.
+func handleSetBan(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.SetBanCmd)
+
+	if _, err := parseBanTarget(c.SubNet); err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCClientInvalidIPOrSubnet,
+			Message: err.Error(),
+		}
+	}
+
+	switch c.Command {
+	case btcjson.SBAdd:
+		// Use the configured ban duration unless a ban time is
+		// provided.  The absolute flag indicates the ban time is a unix
+		// timestamp rather than a duration in seconds.
+		until := time.Now().Add(cfg.BanDuration)
+		if c.BanTime != nil && *c.BanTime > 0 {
+			if c.Absolute != nil && *c.Absolute {
+				until = time.Unix(*c.BanTime, 0)
+			} else {
+				until = time.Now().Add(time.Duration(*c.BanTime) *
+					time.Second)
+			}
+		}
+		if !until.After(time.Now()) {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParameter,
+				Message: "ban time is in the past",
+			}
+		}
+		if err := s.cfg.ConnMgr.Ban(c.SubNet, until); err != nil {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCClientInvalidIPOrSubnet,
+				Message: err.Error(),
+			}
+		}
+
+	case btcjson.SBRemove:
+		if err := s.cfg.ConnMgr.Unban(c.SubNet); err != nil {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCClientInvalidIPOrSubnet,
+				Message: err.Error(),
+			}
+		}
+
+	default:
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "invalid subcommand for setban",
+		}
+	}
+
+	// no data returned unless an error.
+	return nil, nil
+}
+
 // handleSetGenerate implements the setgenerate command.
 func handleSetGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.SetGenerateCmd)
@@ -3567,6 +5812,59 @@ func handleSetGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 	return nil, nil
 }
 
+// handleSetConnectionTargets implements the setconnectiontargets command.
+func handleSetConnectionTargets(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.SetConnectionTargetsCmd)
+
+	targets := s.cfg.ConnMgr.ConnectionTargets()
+	if c.Outbound != nil {
+		targets.Outbound = *c.Outbound
+	}
+	if c.BlockRelayOnly != nil {
+		targets.BlockRelayOnly = *c.BlockRelayOnly
+	}
+	if c.Onion != nil {
+		targets.Onion = *c.Onion
+	}
+
+	// The outbound connections of all kinds are limited to the max peers
+	// together.
+	total := uint64(targets.Outbound) + uint64(targets.BlockRelayOnly) +
+		uint64(targets.Onion)
+	if total > uint64(cfg.MaxPeers) {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("The targets add up to %d "+
+				"connections which exceeds the max peers of %d",
+				total, cfg.MaxPeers),
+		}
+	}
+	if targets.Onion != 0 && !s.cfg.ConnMgr.OnionReachable() {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: "Onion connections require the --proxy or " +
+				"--onion option and may not be combined with " +
+				"--noonion",
+		}
+	}
+
+	s.cfg.ConnMgr.SetConnectionTargets(targets)
+	return connectionTargetsResult(targets), nil
+}
+
+// handleSetSignalBit implements the setsignalbit command.
+func handleSetSignalBit(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.SetSignalBitCmd)
+
+	if err := s.cfg.Generator.SetSignalBit(c.Bit, c.Signal); err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: err.Error(),
+		}
+	}
+	return nil, nil
+}
+
 // Text used to signify that a signed message follows and to prevent
 // inadvertently signing a transaction.
 const messageSignatureHeader = "Bitcoin Signed Message:\n"
@@ -3637,6 +5935,13 @@ func handleSubmitBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 		}
 	}
 
+	// Miners which modify the coinbase transaction might drop its witness,
+	// so restore the witness nonce the witness commitment is based on.
+	// The block is recreated since the serialized bytes are cached.
+	if mining.AddWitnessNonce(block.MsgBlock()) {
+		block = btcutil.NewBlock(block.MsgBlock())
+	}
+
 	// Process this block using the same rules as blocks coming from other
 	// nodes.  This will in turn relay it to the network like normal.
 	_, err = s.cfg.SyncMgr.SubmitBlock(block, blockchain.BFNone)
@@ -3648,6 +5953,133 @@ func handleSubmitBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 	return nil, nil
 }
 
+// fetchPrevOut returns the output spent by the passed outpoint from the memory
+// pool or the utxo set.  It returns nil when the output is unknown or spent.
+func fetchPrevOut(s *rpcServer, outpoint wire.OutPoint) *wire.TxOut {
+	tx, err := s.cfg.TxMemPool.FetchTransaction(&outpoint.Hash)
+	if err == nil {
+		txOuts := tx.MsgTx().TxOut
+		if outpoint.Index >= uint32(len(txOuts)) {
+			return nil
+		}
+		return txOuts[outpoint.Index]
+	}
+
+	entry, err := s.cfg.Chain.FetchUtxoEntry(outpoint)
+	if err != nil || entry == nil || entry.IsSpent() {
+		return nil
+	}
+	return wire.NewTxOut(entry.Amount(), entry.PkScript())
+}
+
+// hexStack returns the passed stack items as hex-encoded strings.
+func hexStack(stack [][]byte) []string {
+	items := make([]string, len(stack))
+	for i, item := range stack {
+		items[i] = hex.EncodeToString(item)
+	}
+	return items
+}
+
+// handleTraceScript implements the tracescript command.
+func handleTraceScript(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.TraceScriptCmd)
+
+	// Deserialize the transaction.
+	hexStr := c.HexTx
+	if len(hexStr)%2 != 0 {
+		hexStr = "0" + hexStr
+	}
+	serializedTx, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return nil, rpcDecodeHexError(hexStr)
+	}
+	var mtx wire.MsgTx
+	err = mtx.Deserialize(bytes.NewReader(serializedTx))
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCDeserialization,
+			Message: "TX decode failed: " + err.Error(),
+		}
+	}
+	if c.Vin >= uint32(len(mtx.TxIn)) {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Transaction has no input %d",
+				c.Vin),
+		}
+	}
+
+	// The outputs spent by all inputs are needed since the signature hashes
+	// of taproot inputs commit to them.
+	prevOuts := txscript.NewMultiPrevOutFetcher(nil)
+	for i, txIn := range mtx.TxIn {
+		txOut := fetchPrevOut(s, txIn.PreviousOutPoint)
+		if txOut == nil {
+			return nil, &btcjson.RPCError{
+				Code: btcjson.ErrRPCNoTxInfo,
+				Message: fmt.Sprintf("Input %d spends unknown or "+
+					"spent output %v", i, txIn.PreviousOutPoint),
+			}
+		}
+		prevOuts.AddPrevOut(txIn.PreviousOutPoint, txOut)
+	}
+
+	// Record the state of the engine before each opcode is executed and
+	// after the last one.  The next opcode can't be disassembled once the
+	// execution is done.
+	result := &btcjson.TraceScriptResult{
+		Vin:     c.Vin,
+		Scripts: []string{},
+		Steps:   []btcjson.TraceScriptStep{},
+	}
+	var vm *txscript.Engine
+	trace := func(step *txscript.StepInfo) error {
+		var next string
+		if dis, err := vm.DisasmPC(); err == nil {
+			next = strings.SplitN(dis, ": ", 2)[1]
+		}
+		result.Steps = append(result.Steps, btcjson.TraceScriptStep{
+			Script:   step.ScriptIndex,
+			Opcode:   step.OpcodeIndex,
+			Next:     next,
+			Stack:    hexStack(step.Stack),
+			AltStack: hexStack(step.AltStack),
+		})
+		return nil
+	}
+	prevOut := prevOuts.FetchPrevOutput(mtx.TxIn[c.Vin].PreviousOutPoint)
+	vm, err = txscript.NewDebugEngine(prevOut.PkScript, &mtx, int(c.Vin),
+		txscript.StandardVerifyFlags, nil,
+		txscript.NewTxSigHashes(&mtx, prevOuts), prevOut.Value, prevOuts,
+		trace)
+	if err == nil {
+		err = vm.Execute()
+
+		// Disassemble the scripts the engine executed, which include
+		// the redeem or witness script once it has been reached.
+		for i := 0; ; i++ {
+			dis, disErr := vm.DisasmScript(i)
+			if disErr != nil && dis == "" {
+				break
+			}
+			var ops []string
+			for _, line := range strings.Split(dis, "\n") {
+				if line != "" {
+					ops = append(ops, strings.SplitN(line, ": ", 2)[1])
+				}
+			}
+			result.Scripts = append(result.Scripts,
+				strings.Join(ops, " "))
+		}
+	}
+	result.Valid = err == nil
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result, nil
+}
+
 // handleUptime implements the uptime command.
 func handleUptime(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	return time.Now().Unix() - s.cfg.StartupTime, nil
@@ -3710,26 +6142,11 @@ func verifyChain(s *rpcServer, level, depth int32) error {
 	rpcsLog.Infof("Verifying chain for %d blocks at level %d",
 		best.Height-finishHeight, level)
 
-	for height := best.Height; height > finishHeight; height-- {
-		// Level 0 just looks up the block.
-		block, err := s.cfg.Chain.BlockByHeight(height)
-		if err != nil {
-			rpcsLog.Errorf("Verify is unable to fetch block at "+
-				"height %d: %v", height, err)
-			return err
-		}
-
-		// Level 1 does basic chain sanity checks.
-		if level > 0 {
-			err := blockchain.CheckBlockSanity(block,
-				s.cfg.ChainParams.PowLimit, s.cfg.TimeSource)
-			if err != nil {
-				rpcsLog.Errorf("Verify is unable to validate "+
-					"block at hash %v height %d: %v",
-					block.Hash(), height, err)
-				return err
-			}
-		}
+	err := verifyChainBlocks(s.cfg.Chain, s.cfg.TimeSource, level,
+		finishHeight, nil)
+	if err != nil {
+		rpcsLog.Errorf("Verify is %v", err)
+		return err
 	}
 	rpcsLog.Infof("Chain verify completed successfully")
 
@@ -3752,6 +6169,39 @@ func handleVerifyChain(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 	return err == nil, nil
 }
 
+// handleVerifyIndex implements the verifyindex command.
+func handleVerifyIndex(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.VerifyIndexCmd)
+
+	if err := checkIndexEnabled(s, c.IndexName); err != nil {
+		return nil, err
+	}
+	var checkDepth int32
+	if c.CheckDepth != nil {
+		checkDepth = *c.CheckDepth
+	}
+	if checkDepth < 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Check depth must not be negative",
+		}
+	}
+
+	result, err := s.cfg.IndexManager.VerifyIndex(c.IndexName, checkDepth,
+		closeChan)
+	if err != nil {
+		context := "Failed to verify index"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	return &btcjson.VerifyIndexResult{
+		Name:     result.Name,
+		Height:   result.Height,
+		Checked:  result.Checked,
+		Valid:    result.Mismatch == "",
+		Mismatch: result.Mismatch,
+	}, nil
+}
+
 // handleVerifyMessage implements the verifymessage command.
 func handleVerifyMessage(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.VerifyMessageCmd)
@@ -3783,18 +6233,27 @@ func handleVerifyMessage(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 		}
 	}
 
+	return verifyMessage(c.Address, sig, c.Message, params), nil
+}
+
+// verifyMessage returns whether the passed signature is a valid signature of
+// the message by the key of the passed pay-to-pubkey-hash address in the
+// format produced by the signmessage and signmessagewithprivkey commands.
+func verifyMessage(addr string, sig []byte, message string,
+	params *chaincfg.Params) bool {
+
 	// Validate the signature - this just shows that it was valid at all.
 	// we will compare it with the key next.
 	var buf bytes.Buffer
 	wire.WriteVarString(&buf, 0, messageSignatureHeader)
-	wire.WriteVarString(&buf, 0, c.Message)
+	wire.WriteVarString(&buf, 0, message)
 	expectedMessageHash := chainhash.DoubleHashB(buf.Bytes())
 	pk, wasCompressed, err := ecdsa.RecoverCompact(sig,
 		expectedMessageHash)
 	if err != nil {
 		// Mirror Bitcoin Core behavior, which treats error in
 		// RecoverCompact as invalid signature.
-		return false, nil
+		return false
 	}
 
 	// Reconstruct the pubkey hash.
@@ -3808,11 +6267,11 @@ func handleVerifyMessage(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 	if err != nil {
 		// Again mirror Bitcoin Core behavior, which treats error in public key
 		// reconstruction as invalid signature.
-		return false, nil
+		return false
 	}
 
 	// Return boolean if addresses match.
-	return addr2.EncodeAddress() == c.Address, nil
+	return addr2.EncodeAddress() == addr
 }
 
 // handleVersion implements the version command.
@@ -4001,13 +6460,15 @@ type rpcServer struct {
 	limitauthsha           [sha256.Size]byte
 	ntfnMgr                *wsNotificationManager
 	numClients             int32
+	compacting             int32
+	rebuilding             int32
 	statusLines            map[int]string
 	statusLock             sync.RWMutex
 	wg                     sync.WaitGroup
 	gbtWorkState           *gbtWorkState
 	helpCacher             *helpCacher
 	requestProcessShutdown chan struct{}
-	quit                   chan int
+	quit                   chan struct{}
 }
 
 // httpStatusLine returns a response Status-Line (RFC 2616 Section 6.1)
@@ -4104,7 +6565,8 @@ func (s *rpcServer) NotifyNewTransactions(txns []*mempool.TxDesc) {
 
 		// Potentially notify any getblocktemplate long poll clients
 		// about stale block templates due to the new transaction.
-		s.gbtWorkState.NotifyMempoolTx(s.cfg.TxMemPool.LastUpdated())
+		s.gbtWorkState.NotifyMempoolTx(s.cfg.TxMemPool.LastUpdated(),
+			txD.Fee)
 	}
 }
 
@@ -4693,9 +7155,27 @@ type rpcserverPeer interface {
 	// the peer is to being banned.
 	BanScore() uint32
 
+	// Misbehavior returns the most recent misbehavior of the peer from
+	// oldest to newest.
+	Misbehavior() []misbehaviorRecord
+
 	// FeeFilter returns the requested current minimum fee rate for which
 	// transactions should be announced.
 	FeeFilter() int64
+
+	// TxRelayStats returns the number of transactions announced by the
+	// peer, the number of those announcements which were ignored due to
+	// the relay rate limit, and the number of transactions received from
+	// the peer.
+	TxRelayStats() (announced, throttled, received uint64)
+
+	// Permissions returns the names of the permissions granted to the
+	// peer.
+	Permissions() []string
+
+	// ConnectionType returns a description of the kind of connection to
+	// the peer.
+	ConnectionType() string
 }
 
 // rpcserverConnManager represents a connection manager for use with the RPC
@@ -4710,14 +7190,14 @@ type rpcserverConnManager interface {
 	// already existing peer will return an error.
 	Connect(addr string, permanent bool) error
 
-	// RemoveByID removes the peer associated with the provided id from the
-	// list of persistent peers.  Attempting to remove an id that does not
-	// exist will return an error.
+	// RemoveByID removes the added node the peer associated with the
+	// provided id is connected to and disconnects the peer.  Attempting to
+	// remove an id that does not exist will return an error.
 	RemoveByID(id int32) error
 
-	// RemoveByAddr removes the peer associated with the provided address
-	// from the list of persistent peers.  Attempting to remove an address
-	// that does not exist will return an error.
+	// RemoveByAddr removes the added node with the provided address and
+	// disconnects the peer connected to it, if any.  Attempting to remove
+	// an address that has not been added will return an error.
 	RemoveByAddr(addr string) error
 
 	// DisconnectByID disconnects the peer associated with the provided id.
@@ -4731,6 +7211,22 @@ type rpcserverConnManager interface {
 	// error.
 	DisconnectByAddr(addr string) error
 
+	// Ban bans the provided subnet, IP address, or host until the given
+	// time and disconnects the peers it covers.
+	Ban(addr string, until time.Time) error
+
+	// Unban lifts the ban of the provided subnet, IP address, or host.
+	// Attempting to unban an address which is not banned will return an
+	// error.
+	Unban(addr string) error
+
+	// BannedAddrs returns an array consisting of all bans which have not
+	// expired yet.
+	BannedAddrs() []banEntry
+
+	// ClearBanned lifts all bans.
+	ClearBanned()
+
 	// ConnectedCount returns the number of currently connected peers.
 	ConnectedCount() int32
 
@@ -4738,12 +7234,20 @@ type rpcserverConnManager interface {
 	// network for all peers.
 	NetTotals() (uint64, uint64)
 
+	// NetTotalsPerMsg returns the bytes sent and received across the
+	// network for all peers per message command.
+	NetTotalsPerMsg() (map[string]uint64, map[string]uint64)
+
+	// UploadTarget returns the state of the upload target in the current
+	// cycle.
+	UploadTarget() uploadTarget
+
 	// ConnectedPeers returns an array consisting of all connected peers.
 	ConnectedPeers() []rpcserverPeer
 
-	// PersistentPeers returns an array consisting of all the persistent
-	// peers.
-	PersistentPeers() []rpcserverPeer
+	// AddedNodes returns the manually added nodes in the order they were
+	// added along with the peers connected to them.
+	AddedNodes() []addedNodeInfo
 
 	// BroadcastMessage sends the provided message to all currently
 	// connected peers.
@@ -4761,6 +7265,26 @@ type rpcserverConnManager interface {
 	// NodeAddresses returns an array consisting node addresses which can
 	// potentially be used to find new nodes in the network.
 	NodeAddresses() []*wire.NetAddressV2
+
+	// NATMapping returns the state of the port mapping of the listening
+	// port and whether NAT traversal is in use.
+	NATMapping() (natMapping, bool)
+
+	// ConnectionTargets returns the number of outbound connections of
+	// each kind which are maintained.
+	ConnectionTargets() connmgr.Targets
+
+	// SetConnectionTargets changes the number of outbound connections of
+	// each kind to maintain.
+	SetConnectionTargets(targets connmgr.Targets)
+
+	// OnionReachable returns whether outbound connections to onion
+	// addresses can be made.
+	OnionReachable() bool
+
+	// ClockSkew returns how far the local clock is off from the time of
+	// the network along with the estimated offset of the latter.
+	ClockSkew() (clockSkewState, time.Duration)
 }
 
 // rpcserverSyncManager represents a sync manager for use with the RPC server.
@@ -4783,11 +7307,34 @@ type rpcserverSyncManager interface {
 	// used to sync from or 0 if there is none.
 	SyncPeerID() int32
 
+	// StaleTip returns whether no new block was connected to the best
+	// chain tip for the stale tip timeout.
+	StaleTip() bool
+
 	// LocateHeaders returns the headers of the blocks after the first known
 	// block in the provided locators until the provided stop hash or the
 	// current tip is reached, up to a max of wire.MaxBlockHeadersPerMsg
 	// hashes.
 	LocateHeaders(locators []*chainhash.Hash, hashStop *chainhash.Hash) []wire.BlockHeader
+
+	// ImportBlocks starts importing the blocks of the provided block files
+	// in the background.  It returns an error when an import is already
+	// running.
+	ImportBlocks(paths []string) error
+
+	// ChainVerifyWarning returns the warning about the problem found by
+	// the verification of the chain at startup or an empty string when no
+	// problem was found.
+	ChainVerifyWarning() string
+
+	// DiskSpaceWarning returns the warning about the free disk space being
+	// below the minimum or an empty string when enough space is
+	// available.
+	DiskSpaceWarning() string
+
+	// BlockRepairs returns the recorded repairs of blocks whose stored
+	// copies were found to be damaged from oldest to newest.
+	BlockRepairs() []blockRepairEvent
 }
 
 // rpcserverConfig is a descriptor containing the RPC server configuration.
@@ -4829,11 +7376,25 @@ type rpcserverConfig struct {
 	Generator *mining.BlkTmplGenerator
 	CPUMiner  *cpuminer.CPUMiner
 
+	// Stratum is the stratum server which hands out work to external
+	// miners.  It is nil when the stratum server is not enabled.
+	Stratum *stratum.Server
+
 	// These fields define any optional indexes the RPC server can make use
 	// of to provide additional data when queried.
-	TxIndex   *indexers.TxIndex
-	AddrIndex *indexers.AddrIndex
-	CfIndex   *indexers.CfIndex
+	TxIndex    *indexers.TxIndex
+	AddrIndex  *indexers.AddrIndex
+	CfIndex    *indexers.CfIndex
+	SpentIndex *indexers.SpentIndex
+	ClaimIndex *indexers.ClaimIndex
+
+	// ClaimFilters serves the committed filters of the claim names.  It is
+	// nil unless --claimfilters is set.
+	ClaimFilters *indexers.ClaimFilterIndex
+
+	// IndexManager manages the optional indexes.  It is nil when none of
+	// them are enabled.
+	IndexManager *indexers.Manager
 
 	// The fee estimator keeps track of how long transactions are left in
 	// the mempool before they are mined into blocks.
@@ -4848,7 +7409,7 @@ func newRPCServer(config *rpcserverConfig) (*rpcServer, error) {
 		gbtWorkState:           newGbtWorkState(config.TimeSource),
 		helpCacher:             newHelpCacher(),
 		requestProcessShutdown: make(chan struct{}),
-		quit:                   make(chan int),
+		quit:                   make(chan struct{}),
 	}
 	if cfg.RPCUser != "" && cfg.RPCPass != "" {
 		login := cfg.RPCUser + ":" + cfg.RPCPass
@@ -4862,10 +7423,20 @@ func newRPCServer(config *rpcserverConfig) (*rpcServer, error) {
 	}
 	rpc.ntfnMgr = newWsNotificationManager(&rpc)
 	rpc.cfg.Chain.Subscribe(rpc.handleBlockchainNotification)
+	rpc.cfg.TxMemPool.Subscribe(rpc.handleMempoolNotification)
 
 	return &rpc, nil
 }
 
+// Callback for notifications from the mempool.  It notifies clients that are
+// subscribed to mempool event or double spend websocket notifications.
+//
+// NOTE: This is invoked with the mempool lock held, so it must not call back
+// into the mempool.
+func (s *rpcServer) handleMempoolNotification(notification *mempool.Notification) {
+	s.ntfnMgr.NotifyMempoolEvent(notification)
+}
+
 // Callback for notifications from blockchain.  It notifies clients that are
 // long polling for changes or subscribed to websockets notifications.
 func (s *rpcServer) handleBlockchainNotification(notification *blockchain.Notification) {
@@ -4901,6 +7472,17 @@ func (s *rpcServer) handleBlockchainNotification(notification *blockchain.Notifi
 
 		// Notify registered websocket clients.
 		s.ntfnMgr.NotifyBlockDisconnected(block)
+
+	case blockchain.NTReorganization:
+		reorg, ok := notification.Data.(*blockchain.ReorganizationNtfnData)
+		if !ok {
+			rpcsLog.Warnf("Chain reorganization notification is not " +
+				"reorganization data.")
+			break
+		}
+
+		// Notify registered websocket clients.
+		s.ntfnMgr.NotifyReorganization(reorg)
 	}
 }
 
@@ -1,14 +1,20 @@
 package main
 
 import (
+	"bytes"
 	"encoding/hex"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/btcsuite/btcd/address/v2"
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/btcutil/v2"
+	"github.com/btcsuite/btcd/chaincfg/v2"
 	"github.com/btcsuite/btcd/chainhash/v2"
 	"github.com/btcsuite/btcd/mempool"
+	"github.com/btcsuite/btcd/mining"
+	"github.com/btcsuite/btcd/txscript/v2"
 	"github.com/btcsuite/btcd/wire/v2"
 	"github.com/stretchr/testify/require"
 )
@@ -495,3 +501,157 @@ func TestGetTxSpendingPrevOut(t *testing.T) {
 	require.NoError(err)
 	require.Equal(expectedResults, results)
 }
+
+// TestHandleGetMempoolInfo ensures the statistics of the orphan pool are
+// included in the result of getmempoolinfo.
+func TestHandleGetMempoolInfo(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+
+	// Create a mock mempool.
+	mm := &mempool.MockTxMempool{}
+	defer mm.AssertExpectations(t)
+
+	// Create a testing server with the mock mempool.
+	s := &rpcServer{cfg: rpcserverConfig{
+		TxMemPool: mm,
+	}}
+
+	mm.On("TxDescs").Return([]*mempool.TxDesc{
+		{VirtualSize: 100},
+		{VirtualSize: 250},
+	})
+	mm.On("OrphanStats").Return(mempool.OrphanStats{
+		Count:            3,
+		Weight:           2400,
+		NumTags:          2,
+		Expired:          4,
+		EvictedTagLimit:  5,
+		EvictedPoolLimit: 6,
+	})
+
+	result, err := handleGetMempoolInfo(s, nil, nil)
+	require.NoError(err)
+	require.Equal(&btcjson.GetMempoolInfoResult{
+		Size:               2,
+		Bytes:              350,
+		Orphans:            3,
+		OrphanWeight:       2400,
+		OrphanPeers:        2,
+		OrphansExpired:     4,
+		OrphansEvictedPeer: 5,
+		OrphansEvictedPool: 6,
+	}, result)
+}
+
+// TestGbtRegenerateDue ensures block templates are only regenerated due to
+// memory pool changes once enough time has passed or the new transactions
+// significantly increase the available fees.
+func TestGbtRegenerateDue(t *testing.T) {
+	t.Parallel()
+
+	generated := time.Unix(1700000000, 0)
+	state := newGbtWorkState(nil)
+	state.lastGenerated = generated
+	state.template = &mining.BlockTemplate{Fees: []int64{-10000, 10000}}
+
+	testCases := []struct {
+		name    string
+		elapsed time.Duration
+		newFees int64
+		want    bool
+	}{{
+		name:    "no new fees before min interval",
+		elapsed: time.Second,
+		want:    false,
+	}, {
+		name:    "significant fees before min interval",
+		elapsed: time.Second,
+		newFees: 5000,
+		want:    false,
+	}, {
+		name:    "insignificant fees after min interval",
+		elapsed: time.Second * (gbtMinRegenerateSeconds + 1),
+		newFees: 999,
+		want:    false,
+	}, {
+		name:    "significant fees after min interval",
+		elapsed: time.Second * (gbtMinRegenerateSeconds + 1),
+		newFees: 1000,
+		want:    true,
+	}, {
+		name:    "no new fees after regenerate interval",
+		elapsed: time.Second * (gbtRegenerateSeconds + 1),
+		want:    true,
+	}}
+
+	for _, tc := range testCases {
+		state.newFees = tc.newFees
+		got := state.regenerateDue(generated.Add(tc.elapsed))
+		require.Equal(t, tc.want, got, tc.name)
+	}
+}
+
+// TestAddressOutputs ensures the outputs paying to the queried addresses and
+// the ones spent by the transactions of the address index are found.
+func TestAddressOutputs(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+
+	params := &chaincfg.SimNetParams
+	newAddr := func(b byte) address.Address {
+		addr, err := address.NewAddressPubKeyHash(
+			bytes.Repeat([]byte{b}, 20), params)
+		require.NoError(err)
+		return addr
+	}
+	payTo := func(addr address.Address, value int64) *wire.TxOut {
+		pkScript, err := txscript.PayToAddrScript(addr)
+		require.NoError(err)
+		return wire.NewTxOut(value, pkScript)
+	}
+	addrA, addrB, addrC := newAddr(1), newAddr(2), newAddr(3)
+
+	// The first transaction pays to both queried addresses and the second
+	// one spends the output paying to the first address.
+	tx1 := wire.NewMsgTx(wire.TxVersion)
+	tx1.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: chainhash.Hash{9}},
+		nil, nil))
+	tx1.AddTxOut(payTo(addrA, 1000))
+	tx1.AddTxOut(payTo(addrB, 500))
+	tx1.AddTxOut(payTo(addrC, 200))
+	nullData, err := txscript.NullDataScript([]byte{1})
+	require.NoError(err)
+	tx1.AddTxOut(wire.NewTxOut(0, nullData))
+	tx2 := wire.NewMsgTx(wire.TxVersion)
+	tx2.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: tx1.TxHash()}, nil, nil))
+	tx2.AddTxOut(payTo(addrC, 900))
+
+	txns := []addressIndexTx{
+		{tx: btcutil.NewTx(tx1), height: 5},
+		{tx: btcutil.NewTx(tx2), height: 7},
+	}
+	outputs, spent := addressOutputs(txns,
+		[]address.Address{addrA, addrB}, params)
+
+	spentOutPoint := wire.OutPoint{Hash: tx1.TxHash(), Index: 0}
+	require.Equal([]addressOutput{
+		{
+			outPoint: spentOutPoint,
+			address:  addrA.EncodeAddress(),
+			pkScript: tx1.TxOut[0].PkScript,
+			value:    1000,
+			height:   5,
+		},
+		{
+			outPoint: wire.OutPoint{Hash: tx1.TxHash(), Index: 1},
+			address:  addrB.EncodeAddress(),
+			pkScript: tx1.TxOut[1].PkScript,
+			value:    500,
+			height:   5,
+		},
+	}, outputs)
+	require.Equal(map[wire.OutPoint]struct{}{spentOutPoint: {}}, spent)
+}
@@ -30,16 +30,35 @@ var helpDescsEnUS = map[string]string{
 	"debuglevel--result1":    "The list of subsystems",
 
 	// AddNodeCmd help.
-	"addnode--synopsis": "Attempts to add or remove a persistent peer.",
+	"addnode--synopsis": "Attempts to add or remove a persistent peer.  Added peers are kept connected to and survive restarts.",
 	"addnode-addr":      "IP address and port of the peer to operate on",
 	"addnode-subcmd":    "'add' to add a persistent peer, 'remove' to remove a persistent peer, or 'onetry' to try a single connection to a peer",
 
+	// ClearBannedCmd help.
+	"clearbanned--synopsis": "Lifts the bans of all subnets and addresses.",
+
+	// CombinePsbtCmd help.
+	"combinepsbt--synopsis": "Combines multiple partially signed transactions (PSBTs) for the same transaction into one, merging their inputs, signatures and other data.",
+	"combinepsbt-psbts":     "The base64-encoded PSBTs to combine",
+	"combinepsbt--result0":  "The base64-encoded combined PSBT",
+
+	// CompactDBCmd help.
+	"compactdb--synopsis": "Starts compacting the metadata of the block database in the background, which discards the data that was overwritten or deleted, such as spent outputs and the data of dropped indexes, and reclaims the disk space it used.\n" +
+		"The database remains usable meanwhile, although it is slower.  Compacting a large database takes a long time, and its completion is logged.",
+
 	// NodeCmd help.
 	"node--synopsis":     "Attempts to add or remove a peer.",
 	"node-subcmd":        "'disconnect' to remove all matching non-persistent peers, 'remove' to remove a persistent peer, or 'connect' to connect to a peer",
 	"node-target":        "Either the IP address and port of the peer to operate on, or a valid peer ID.",
 	"node-connectsubcmd": "'perm' to make the connected peer a permanent one, 'temp' to try a single connect to a peer",
 
+	// ConvertToPsbtCmd help.
+	"converttopsbt--synopsis":     "Converts a serialized, hex-encoded transaction to a partially signed transaction (PSBT) without any input data.",
+	"converttopsbt-hextx":         "Serialized, hex-encoded transaction",
+	"converttopsbt-permitsigdata": "Drop the signature scripts and witnesses of the inputs instead of failing when there are any",
+	"converttopsbt-iswitness":     "Whether the transaction is serialized with witnesses, which is detected when omitted",
+	"converttopsbt--result0":      "The base64-encoded PSBT",
+
 	// TransactionInput help.
 	"transactioninput-txid": "The hash of the input transaction",
 	"transactioninput-vout": "The specific output of the input transaction to redeem",
@@ -96,6 +115,10 @@ var helpDescsEnUS = map[string]string{
 
 	// TxRawDecodeResult help.
 	"txrawdecoderesult-txid":     "The hash of the transaction",
+	"txrawdecoderesult-hash":     "The witness hash of the transaction",
+	"txrawdecoderesult-size":     "The size of the transaction in bytes",
+	"txrawdecoderesult-vsize":    "The virtual size of the transaction in bytes",
+	"txrawdecoderesult-weight":   "The transaction's weight (between vsize*4-3 and vsize*4)",
 	"txrawdecoderesult-version":  "The transaction version",
 	"txrawdecoderesult-locktime": "The transaction lock time",
 	"txrawdecoderesult-vin":      "The transaction inputs as JSON objects",
@@ -105,6 +128,113 @@ var helpDescsEnUS = map[string]string{
 	"decoderawtransaction--synopsis": "Returns a JSON object representing the provided serialized, hex-encoded transaction.",
 	"decoderawtransaction-hextx":     "Serialized, hex-encoded transaction",
 
+	// PsbtScript help.
+	"psbtscript-asm":  "Disassembly of the script",
+	"psbtscript-hex":  "Hex-encoded bytes of the script",
+	"psbtscript-type": "The type of the script (e.g. 'multisig')",
+
+	// PsbtWitnessUtxo help.
+	"psbtwitnessutxo-amount":       "The value of the output in BTC",
+	"psbtwitnessutxo-scriptPubKey": "The public key script of the output as a JSON object",
+
+	// PsbtBip32Deriv help.
+	"psbtbip32deriv-pubkey":             "The hex-encoded public key",
+	"psbtbip32deriv-master_fingerprint": "The hex-encoded fingerprint of the master key",
+	"psbtbip32deriv-path":               "The derivation path of the public key",
+
+	// PsbtTaprootBip32Deriv help.
+	"psbttaprootbip32deriv-pubkey":             "The hex-encoded x-only public key",
+	"psbttaprootbip32deriv-master_fingerprint": "The hex-encoded fingerprint of the master key",
+	"psbttaprootbip32deriv-path":               "The derivation path of the public key",
+	"psbttaprootbip32deriv-leaf_hashes":        "The hex-encoded hashes of the leaves the public key is used in",
+
+	// PsbtTaprootScriptPathSig help.
+	"psbttaprootscriptpathsig-pubkey":    "The hex-encoded x-only public key of the signature",
+	"psbttaprootscriptpathsig-leaf_hash": "The hex-encoded hash of the leaf the signature is for",
+	"psbttaprootscriptpathsig-sig":       "The hex-encoded signature",
+
+	// PsbtTaprootScript help.
+	"psbttaprootscript-script":         "The hex-encoded leaf script",
+	"psbttaprootscript-leaf_ver":       "The version of the leaf",
+	"psbttaprootscript-control_blocks": "The hex-encoded control blocks of the leaf script",
+
+	// PsbtTaprootTreeLeaf help.
+	"psbttaproottreeleaf-depth":    "The depth of the leaf in the tree",
+	"psbttaproottreeleaf-leaf_ver": "The version of the leaf",
+	"psbttaproottreeleaf-script":   "The hex-encoded leaf script",
+
+	// PsbtProprietary help.
+	"psbtproprietary-identifier": "The hex-encoded identifier of the user of the pair",
+	"psbtproprietary-subtype":    "The subtype of the pair",
+	"psbtproprietary-key":        "The hex-encoded key data following the subtype",
+	"psbtproprietary-value":      "The hex-encoded value of the pair",
+
+	// PsbtClaim help.
+	"psbtclaim-name":    "The claimed or supported name",
+	"psbtclaim-claimid": "The hex-encoded ID of the supported or updated claim",
+	"psbtclaim-value":   "The hex-encoded value of the claim, support or update",
+
+	// DecodePsbtInput help.
+	"decodepsbtinput-non_witness_utxo":          "The decoded transaction the input spends an output of",
+	"decodepsbtinput-witness_utxo":              "The output the input spends",
+	"decodepsbtinput-partial_signatures":        "The partial signatures of the input",
+	"decodepsbtinput-partial_signatures--key":   "pubkey",
+	"decodepsbtinput-partial_signatures--value": "signature",
+	"decodepsbtinput-partial_signatures--desc":  "The hex-encoded public key as the key and the hex-encoded signature as the value",
+	"decodepsbtinput-sighash":                   "The signature hash type the input must be signed with",
+	"decodepsbtinput-redeem_script":             "The redeem script of the input",
+	"decodepsbtinput-witness_script":            "The witness script of the input",
+	"decodepsbtinput-bip32_derivs":              "The derivation paths of the public keys of the input",
+	"decodepsbtinput-final_scriptSig":           "The final signature script of the input",
+	"decodepsbtinput-final_scriptwitness":       "The hex-encoded items of the final witness of the input",
+	"decodepsbtinput-taproot_key_path_sig":      "The hex-encoded signature for a taproot key path spend",
+	"decodepsbtinput-taproot_script_path_sigs":  "The signatures for taproot script path spends",
+	"decodepsbtinput-taproot_scripts":           "The taproot leaf scripts of the input",
+	"decodepsbtinput-taproot_bip32_derivs":      "The derivation paths of the taproot public keys of the input",
+	"decodepsbtinput-taproot_internal_key":      "The hex-encoded taproot internal key",
+	"decodepsbtinput-taproot_merkle_root":       "The hex-encoded taproot merkle root",
+	"decodepsbtinput-proprietary":               "The proprietary key-value pairs of the input",
+	"decodepsbtinput-unknown":                   "The unknown key-value pairs of the input",
+	"decodepsbtinput-unknown--key":              "key",
+	"decodepsbtinput-unknown--value":            "value",
+	"decodepsbtinput-unknown--desc":             "The hex-encoded key as the key and the hex-encoded value as the value",
+
+	// DecodePsbtOutput help.
+	"decodepsbtoutput-redeem_script":        "The redeem script of the output",
+	"decodepsbtoutput-witness_script":       "The witness script of the output",
+	"decodepsbtoutput-bip32_derivs":         "The derivation paths of the public keys of the output",
+	"decodepsbtoutput-taproot_internal_key": "The hex-encoded taproot internal key",
+	"decodepsbtoutput-taproot_tree":         "The leaves of the taproot tree of the output in depth-first order",
+	"decodepsbtoutput-taproot_bip32_derivs": "The derivation paths of the taproot public keys of the output",
+	"decodepsbtoutput-claim":                "The claim, support or update the output makes, as described by its proprietary claim key-value pairs",
+	"decodepsbtoutput-proprietary":          "The proprietary key-value pairs of the output",
+	"decodepsbtoutput-unknown":              "The unknown key-value pairs of the output",
+	"decodepsbtoutput-unknown--key":         "key",
+	"decodepsbtoutput-unknown--value":       "value",
+	"decodepsbtoutput-unknown--desc":        "The hex-encoded key as the key and the hex-encoded value as the value",
+
+	// PsbtGlobalXPub help.
+	"psbtglobalxpub-xpub":               "The extended public key",
+	"psbtglobalxpub-master_fingerprint": "The hex-encoded fingerprint of the master key",
+	"psbtglobalxpub-path":               "The derivation path of the extended public key",
+
+	// DecodePsbtResult help.
+	"decodepsbtresult-tx":             "The decoded unsigned transaction",
+	"decodepsbtresult-global_xpubs":   "The extended public keys of the PSBT",
+	"decodepsbtresult-proprietary":    "The global proprietary key-value pairs",
+	"decodepsbtresult-unknown":        "The global unknown key-value pairs",
+	"decodepsbtresult-unknown--key":   "key",
+	"decodepsbtresult-unknown--value": "value",
+	"decodepsbtresult-unknown--desc":  "The hex-encoded key as the key and the hex-encoded value as the value",
+	"decodepsbtresult-inputs":         "The inputs of the PSBT",
+	"decodepsbtresult-outputs":        "The outputs of the PSBT",
+	"decodepsbtresult-fee":            "The fee of the transaction in BTC, only when the outputs spent by all inputs are known",
+
+	// DecodePsbtCmd help.
+	"decodepsbt--synopsis": "Returns a JSON object representing the provided base64-encoded partially signed transaction (PSBT).\n" +
+		"Outputs with the proprietary key-value pairs with the identifier 'lbc' describe the claim, support or update they make: subtype 0 holds the name, 1 the claim ID and 2 the value.",
+	"decodepsbt-psbt": "The base64-encoded PSBT",
+
 	// DecodeScriptResult help.
 	"decodescriptresult-asm":       "Disassembly of the script",
 	"decodescriptresult-reqSigs":   "(DEPRECATED) The number of required signatures",
@@ -117,6 +247,16 @@ var helpDescsEnUS = map[string]string{
 	"decodescript--synopsis": "Returns a JSON object with information about the provided hex-encoded script.",
 	"decodescript-hexscript": "Hex-encoded script",
 
+	// DumpProfilesCmd help.
+	"dumpprofiles--synopsis": "Writes a heap profile and the stack traces of all goroutines to the profiles directory within the data directory, and starts writing a cpu profile to it in the background.\n" +
+		"The names of the files contain the time of the dump.  The profiles are also dumped when btcd receives the SIGUSR1 signal.",
+	"dumpprofiles-cpuseconds": "The number of seconds to write the cpu profile for, at most 300, or 0 to skip it",
+
+	// DumpProfilesResult help.
+	"dumpprofilesresult-heap":      "The path of the heap profile",
+	"dumpprofilesresult-goroutine": "The path of the goroutine stack traces",
+	"dumpprofilesresult-cpu":       "The path of the cpu profile which is being written (only present when one is written)",
+
 	// EstimateFeeCmd help.
 	"estimatefee--synopsis": "Estimate the fee per kilobyte in satoshis " +
 		"required for a transaction to be mined before a certain number of " +
@@ -126,12 +266,53 @@ var helpDescsEnUS = map[string]string{
 	"estimatefee--result0": "Estimated fee per kilobyte in satoshis for a block to " +
 		"be mined in the next NumBlocks blocks.",
 
+	// EstimateSmartFeeCmd help.
+	"estimatesmartfee--synopsis": "Estimate the fee per kilobyte in BTC " +
+		"required for a transaction to be mined within a target number of blocks.",
+	"estimatesmartfee-conftarget": "The target number of blocks within which the " +
+		"transaction should be mined (capped at 25)",
+	"estimatesmartfee-estimatemode": "The estimate mode.  Accepted for compatibility, " +
+		"but has no effect",
+
+	// EstimateSmartFeeResult help.
+	"estimatesmartfeeresult-feerate": "Estimated fee per kilobyte in BTC, omitted when no estimate is available",
+	"estimatesmartfeeresult-errors":  "Errors encountered while estimating the fee",
+	"estimatesmartfeeresult-blocks":  "The number of blocks the estimate is valid for",
+
+	// FinalizePsbtCmd help.
+	"finalizepsbt--synopsis": "Finalizes the inputs of a partially signed transaction (PSBT) which have all the signatures and scripts they need.\n" +
+		"The signed transaction is extracted when all inputs are finalized.",
+	"finalizepsbt-psbt":    "The base64-encoded PSBT",
+	"finalizepsbt-extract": "Return the signed transaction instead of the PSBT when all inputs are finalized",
+
+	// FinalizePsbtResult help.
+	"finalizepsbtresult-psbt":     "The base64-encoded PSBT, unless the transaction was extracted",
+	"finalizepsbtresult-hex":      "The serialized, hex-encoded signed transaction, if it was extracted",
+	"finalizepsbtresult-complete": "Whether or not all inputs are finalized",
+
 	// GenerateCmd help
 	"generate--synopsis": "Generates a set number of blocks (simnet or regtest only) and returns a JSON\n" +
 		" array of their hashes.",
 	"generate-numblocks": "Number of blocks to generate",
 	"generate--result0":  "The hashes, in order, of blocks generated by the call",
 
+	// GenerateToAddressCmd help
+	"generatetoaddress--synopsis": "Generates a set number of blocks which pay to the specified address (simnet or regtest only)\n" +
+		" and returns a JSON array of their hashes.",
+	"generatetoaddress-numblocks": "Number of blocks to generate",
+	"generatetoaddress-address":   "The address the coinbase of the generated blocks pays to",
+	"generatetoaddress-maxtries":  "Accepted for compatibility, currently ignored",
+	"generatetoaddress--result0":  "The hashes, in order, of blocks generated by the call",
+
+	// GenerateBlockCmd help
+	"generateblock--synopsis": "Generates a block which contains exactly the specified transactions in the specified order\n" +
+		" (simnet or regtest only) and returns its hash.",
+	"generateblock-output":       "The address the coinbase of the generated block pays to",
+	"generateblock-transactions": "The transactions to include, each either the hash of a transaction in the memory pool or a serialized, hex-encoded transaction",
+
+	// GenerateBlockResult help.
+	"generateblockresult-hash": "The hash of the generated block",
+
 	// GetAddedNodeInfoResultAddr help.
 	"getaddednodeinforesultaddr-address":   "The ip address for this DNS entry",
 	"getaddednodeinforesultaddr-connected": "The connection 'direction' (inbound/outbound/false)",
@@ -142,13 +323,54 @@ var helpDescsEnUS = map[string]string{
 	"getaddednodeinforesult-addresses": "DNS lookup and connection information about the peer",
 
 	// GetAddedNodeInfo help.
-	"getaddednodeinfo--synopsis":   "Returns information about manually added (persistent) peers.",
+	"getaddednodeinfo--synopsis":   "Returns information about manually added (persistent) peers, including the ones which are not connected.",
 	"getaddednodeinfo-dns":         "Specifies whether the returned data is a JSON object including DNS and connection information, or just a list of added peers",
 	"getaddednodeinfo-node":        "Only return information about this specific peer instead of all added peers",
 	"getaddednodeinfo--condition0": "dns=false",
 	"getaddednodeinfo--condition1": "dns=true",
 	"getaddednodeinfo--result0":    "List of added peers",
 
+	// AddressIndexRequest help.
+	"addressindexrequest-addresses": "The addresses (a single address may also be passed as a string instead of the request object)",
+	"addressindexrequest-start":     "The height of the first block to return transactions of (getaddresstxids only)",
+	"addressindexrequest-end":       "The height of the last block to return transactions of (getaddresstxids only)",
+	"addressindexrequest-chainInfo": "Include the best block the outputs are current as of (getaddressutxos only)",
+
+	// GetAddressBalanceCmd help.
+	"getaddressbalance--synopsis": "Returns the confirmed balance of the given addresses and the total amount they received.\n" +
+		"Requires the address index (--addrindex).",
+	"getaddressbalance-request": "The addresses",
+
+	// GetAddressBalanceResult help.
+	"getaddressbalanceresult-balance":  "The confirmed balance in satoshi",
+	"getaddressbalanceresult-received": "The total amount received in satoshi, including the amount which was spent since",
+
+	// GetAddressTxIDsCmd help.
+	"getaddresstxids--synopsis": "Returns the IDs of the confirmed transactions which involve the given addresses, either by paying to them or by spending outputs paying to them, in the order they appear in the main chain.\n" +
+		"Requires the address index (--addrindex).",
+	"getaddresstxids-request":  "The addresses and the optional range of block heights",
+	"getaddresstxids--result0": "The transaction IDs",
+
+	// GetAddressUtxosCmd help.
+	"getaddressutxos--synopsis": "Returns the confirmed unspent outputs paying to the given addresses in the order they appear in the main chain.\n" +
+		"Requires the address index (--addrindex).",
+	"getaddressutxos-request":     "The addresses and whether to include the best block",
+	"getaddressutxos--condition0": "chainInfo=false",
+	"getaddressutxos--condition1": "chainInfo=true",
+
+	// AddressUtxoResult help.
+	"addressutxoresult-address":     "The address the output pays to",
+	"addressutxoresult-txid":        "The hash of the transaction which created the output",
+	"addressutxoresult-outputIndex": "The index of the output in the transaction",
+	"addressutxoresult-script":      "The hex-encoded public key script of the output",
+	"addressutxoresult-satoshis":    "The value of the output in satoshi",
+	"addressutxoresult-height":      "The height of the block containing the transaction",
+
+	// GetAddressUtxosChainInfoResult help.
+	"getaddressutxoschaininforesult-utxos":  "The unspent outputs",
+	"getaddressutxoschaininforesult-hash":   "The hash of the best block the outputs are current as of",
+	"getaddressutxoschaininforesult-height": "The height of the best block the outputs are current as of",
+
 	// GetBestBlockResult help.
 	"getbestblockresult-hash":   "Hex-encoded bytes of the best block hash",
 	"getbestblockresult-height": "Height of the best block",
@@ -182,13 +404,26 @@ var helpDescsEnUS = map[string]string{
 	"getblockchaininforesult-verificationprogress": "An estimate for how much of the best chain we've verified",
 	"getblockchaininforesult-pruned":               "A bool that indicates if the node is pruned or not",
 	"getblockchaininforesult-pruneheight":          "The lowest block retained in the current pruned chain",
-	"getblockchaininforesult-chainwork":            "The total cumulative work in the best chain",
+	"getblockchaininforesult-chainwork":            "The total cumulative work in the best chain as a hex number",
+	"getblockchaininforesult-bestblockwork":        "The work of the best block as a hex number",
 	"getblockchaininforesult-size_on_disk":         "The estimated size of the block and undo files on disk",
 	"getblockchaininforesult-initialblockdownload": "Estimate of whether this node is in Initial Block Download mode",
 	"getblockchaininforesult-softforks":            "The status of the super-majority soft-forks",
 	"getblockchaininforesult-unifiedsoftforks":     "The status of the super-majority soft-forks used by bitcoind on or after v0.19.0",
 	"getblockchaininforesult-warnings":             "Any network and blockchain warnings",
 
+	// GetDeploymentInfoCmd help.
+	"getdeploymentinfo--synopsis": "Returns the state of the buried and BIP0009 soft-fork deployments for the block after the best block, including signalling statistics for the deployments which are being voted on.",
+
+	// GetDeploymentInfoResult help.
+	"getdeploymentinforesult-hash":               "The hash of the best block",
+	"getdeploymentinforesult-height":             "The height of the best block",
+	"getdeploymentinforesult-deployments":        "JSON object describing the deployments",
+	"getdeploymentinforesult-deployments--key":   "deployments",
+	"getdeploymentinforesult-deployments--value": "An object with the type, whether or not it is active, and either the activation height of a buried deployment or the BIP0009 status and statistics of a deployment",
+	"getdeploymentinforesult-deployments--desc":  "The state of the deployments keyed by their name",
+	"getdeploymentinforesult-signalbits":         "The version bits signalled in generated blocks in addition to the bits of the deployments which are being voted on",
+
 	// SoftForkDescription help.
 	"softforkdescription-reject":  "The current activation status of the softfork",
 	"softforkdescription-version": "The block version that signals enforcement of this softfork",
@@ -263,6 +498,25 @@ var helpDescsEnUS = map[string]string{
 	"getblockcount--synopsis": "Returns the number of blocks in the longest block chain.",
 	"getblockcount--result0":  "The current block count",
 
+	// GetBlockBenchmarksCmd help.
+	"getblockbenchmarks--synopsis": "Returns the time spent in each stage of processing the most recent blocks connected to the end of the main chain ordered from the oldest to the newest.  Up to 100 blocks are kept, and blocks connected during a reorganization are not included.",
+	"getblockbenchmarks-count":     "The number of the most recent blocks to return",
+
+	// GetBlockBenchmarksResult help.
+	"getblockbenchmarksresult-hash":       "The hash of the block",
+	"getblockbenchmarksresult-height":     "The height of the block in the block chain",
+	"getblockbenchmarksresult-txns":       "The number of transactions in the block",
+	"getblockbenchmarksresult-inputs":     "The number of inputs whose scripts were validated",
+	"getblockbenchmarksresult-check":      "Milliseconds spent on the checks of the block which don't need the outputs it spends",
+	"getblockbenchmarksresult-store":      "Milliseconds spent storing the block in the database",
+	"getblockbenchmarksresult-loadinputs": "Milliseconds spent loading the outputs spent by the block to validate it",
+	"getblockbenchmarksresult-scripts":    "Milliseconds spent validating the scripts of the block",
+	"getblockbenchmarksresult-utxoupdate": "Milliseconds spent updating the utxo cache",
+	"getblockbenchmarksresult-write":      "Milliseconds spent writing the best chain state and the spend journal to the database",
+	"getblockbenchmarksresult-index":      "Milliseconds spent updating the optional indexes",
+	"getblockbenchmarksresult-flush":      "Milliseconds spent flushing the utxo cache when it needed to be flushed",
+	"getblockbenchmarksresult-total":      "Milliseconds spent in all of the stages",
+
 	// GetBlockHashCmd help.
 	"getblockhash--synopsis": "Returns hash of the block in best block chain at the given height.",
 	"getblockhash-index":     "The block height",
@@ -287,13 +541,44 @@ var helpDescsEnUS = map[string]string{
 	"getblockheaderverboseresult-nonce":             "The block nonce",
 	"getblockheaderverboseresult-bits":              "The bits which represent the block difficulty",
 	"getblockheaderverboseresult-difficulty":        "The proof-of-work difficulty as a multiple of the minimum difficulty",
+	"getblockheaderverboseresult-mediantime":        "The median time of the past blocks as of the block in seconds since 1 Jan 1970 GMT",
+	"getblockheaderverboseresult-chainwork":         "The total cumulative work in the chain up to and including the block as a hex number",
+	"getblockheaderverboseresult-work":              "The work of the block as a hex number",
 	"getblockheaderverboseresult-previousblockhash": "The hash of the previous block",
 	"getblockheaderverboseresult-nextblockhash":     "The hash of the next block (only if there is one)",
 
+	// GetBlockRangeCmd help.
+	"getblockrange--synopsis":   "Returns the serialized blocks of the main chain in the given range of heights along with the outputs spent by their transactions, so they can be analyzed without looking up the previous outputs.",
+	"getblockrange-startheight": "The height of the first block",
+	"getblockrange-endheight":   "The height of the last block (at most 100 blocks can be requested)",
+
+	// GetBlockRangeResult help.
+	"getblockrangeresult-hash":         "The hash of the block",
+	"getblockrangeresult-height":       "The height of the block in the block chain",
+	"getblockrangeresult-hex":          "The hex-encoded serialized block",
+	"getblockrangeresult-spentoutputs": "The outputs spent by the inputs of the transactions of the block except the coinbase, in the order of the inputs",
+
+	// GetBlockRepairsCmd help.
+	"getblockrepairs--synopsis": "Returns the most recent blocks whose stored copies were found to be damaged when they were read from the block database, ordered from oldest to newest.\n" +
+		"Damaged blocks are downloaded from peers again to repair them.",
+
+	// BlockRepairResult help.
+	"blockrepairresult-hash":     "The hash of the block",
+	"blockrepairresult-height":   "The height of the block in the main chain or -1 when it isn't part of the main chain",
+	"blockrepairresult-detected": "The time the damage was detected in seconds since 1 Jan 1970 GMT",
+	"blockrepairresult-repaired": "The time the block was repaired in seconds since 1 Jan 1970 GMT (only when it was repaired)",
+	"blockrepairresult-status":   "The status of the repair (pending or repaired)",
+
+	// SpentOutputResult help.
+	"spentoutputresult-value":        "The value of the output in BTC",
+	"spentoutputresult-scriptpubkey": "The hex-encoded public key script of the output",
+	"spentoutputresult-height":       "The height of the block containing the transaction which created the output",
+	"spentoutputresult-coinbase":     "Whether or not the output was created by a coinbase transaction",
+
 	// TemplateRequest help.
 	"templaterequest-mode":         "This is 'template', 'proposal', or omitted",
 	"templaterequest-capabilities": "List of capabilities",
-	"templaterequest-longpollid":   "The long poll ID of a job to monitor for expiration; required and valid only for long poll requests ",
+	"templaterequest-longpollid":   "The long poll ID of a job to monitor for expiration; required and valid only for long poll requests.  The request returns once the best block changes or the transactions in the memory pool change significantly",
 	"templaterequest-sigoplimit":   "Number of signature operations allowed in blocks (this parameter is ignored)",
 	"templaterequest-sizelimit":    "Number of bytes allowed in blocks (this parameter is ignored)",
 	"templaterequest-maxversion":   "Highest supported block version number (this parameter is ignored)",
@@ -340,6 +625,12 @@ var helpDescsEnUS = map[string]string{
 	"getblocktemplateresult-reject-reason":              "Reason the proposal was invalid as-is (only applies to proposal responses)",
 	"getblocktemplateresult-default_witness_commitment": "The witness commitment itself. Will be populated if the block has witness data",
 	"getblocktemplateresult-weightlimit":                "The current limit on the max allowed weight of a block",
+	"getblocktemplateresult-claimtxs":                   "The number of transactions in the template making each kind of claim operation (btcd extension)",
+
+	// GetBlockTemplateResultClaims help.
+	"getblocktemplateresultclaims-claims":   "The number of transactions which claim names",
+	"getblocktemplateresultclaims-supports": "The number of transactions which support claims",
+	"getblocktemplateresultclaims-updates":  "The number of transactions which update claims",
 
 	// GetBlockTemplateCmd help.
 	"getblocktemplate--synopsis": "Returns a JSON object with information necessary to construct a block to mine or accepts a proposal to validate.\n" +
@@ -359,15 +650,23 @@ var helpDescsEnUS = map[string]string{
 	// GetChainTipsCmd help.
 	"getchaintips--synopsis": "Returns information about all known tips in the block tree, including the main chain as well as orphaned branches.",
 
+	// GetCheckpointsResult help.
+	"getcheckpointsresult-height":      "The height of the checkpoint",
+	"getcheckpointsresult-hash":        "The block hash of the checkpoint",
+	"getcheckpointsresult-inmainchain": "Whether or not the checkpointed block is part of the main chain",
+
+	// GetCheckpointsCmd help.
+	"getcheckpoints--synopsis": "Returns the checkpoints which are enforced by the node ordered by height, including those added with the addcheckpoint and checkpointfile options.",
+
 	// GetCFilterCmd help.
 	"getcfilter--synopsis":  "Returns a block's committed filter given its hash.",
-	"getcfilter-filtertype": "The type of filter to return (0=regular)",
+	"getcfilter-filtertype": "The type of filter to return (0=regular, 1=claim names)",
 	"getcfilter-hash":       "The hash of the block",
 	"getcfilter--result0":   "The block's committed filter",
 
 	// GetCFilterHeaderCmd help.
 	"getcfilterheader--synopsis":  "Returns a block's compact filter header given its hash.",
-	"getcfilterheader-filtertype": "The type of filter header to return (0=regular)",
+	"getcfilterheader-filtertype": "The type of filter header to return (0=regular, 1=claim names)",
 	"getcfilterheader-hash":       "The hash of the block",
 	"getcfilterheader--result0":   "The block's gcs filter header",
 
@@ -375,10 +674,33 @@ var helpDescsEnUS = map[string]string{
 	"getconnectioncount--synopsis": "Returns the number of active connections to other peers.",
 	"getconnectioncount--result0":  "The number of connections",
 
+	// GetConnectionTargetsCmd help.
+	"getconnectiontargets--synopsis": "Returns the number of outbound connections of each kind the server maintains.",
+
+	// ConnectionTargetsResult help.
+	"connectiontargetsresult-outbound":       "The number of regular outbound connections to maintain",
+	"connectiontargetsresult-blockrelayonly": "The number of outbound connections which only relay blocks to maintain",
+	"connectiontargetsresult-onion":          "The number of outbound connections to onion addresses to maintain",
+	"connectiontargetsresult-maxpeers":       "The maximum number of inbound and outbound peers the targets are limited to together",
+
 	// GetCurrentNetCmd help.
 	"getcurrentnet--synopsis": "Get bitcoin network the server is running on.",
 	"getcurrentnet--result0":  "The network identifier",
 
+	// GetDBInfoCmd help.
+	"getdbinfo--synopsis": "Returns the disk space used by the block files and the metadata of the block database, and the approximate disk space used by each top-level bucket of the metadata, such as the utxo set, the spend journal, and the optional indexes.",
+
+	// GetDBInfoResult help.
+	"getdbinforesult-blockfiles":     "The number of block files",
+	"getdbinforesult-blockfilessize": "The total size of the block files in bytes",
+	"getdbinforesult-metadatasize":   "The size of the tables of the metadata in bytes excluding the recent changes which are still cached in memory",
+	"getdbinforesult-buckets":        "The approximate disk space used by each top-level bucket of the metadata ordered by name",
+	"getdbinforesult-compacting":     "Whether or not the metadata is being compacted",
+
+	// DBBucketResult help.
+	"dbbucketresult-name": "The name of the bucket",
+	"dbbucketresult-size": "The approximate disk space used by the bucket and its nested buckets in bytes",
+
 	// GetDifficultyCmd help.
 	"getdifficulty--synopsis": "Returns the proof-of-work difficulty as a multiple of the minimum difficulty.",
 	"getdifficulty--result0":  "The difficulty",
@@ -427,15 +749,57 @@ var helpDescsEnUS = map[string]string{
 	"getheaders-hashstop":      "Block hash to stop including block headers for; if not found, all headers to the latest known block are returned.",
 	"getheaders--result0":      "Serialized block headers of all located blocks, limited to some arbitrary maximum number of hashes (currently 2000, which matches the wire protocol headers message, but this is not guaranteed)",
 
+	// GetIndexInfoCmd help.
+	"getindexinfo--synopsis": "Returns the state of the enabled optional indexes.\n" +
+		"Indexes which are enabled on a node that already has blocks are built in the background and aren't synced until they catch up to the best chain tip.",
+	"getindexinfo-indexname":       "Only return the state of the index with this name",
+	"getindexinfo--result0--desc":  "Index states keyed by the index name",
+	"getindexinfo--result0--key":   "Index name",
+	"getindexinfo--result0--value": "Object containing the state of the index",
+
+	// GetIndexInfoResult help.
+	"getindexinforesult-synced":            "Whether the index is caught up to the best chain tip",
+	"getindexinforesult-best_block_height": "The height of the last block which was indexed",
+
 	// GetInfoCmd help.
 	"getinfo--synopsis": "Returns a JSON object containing various state info.",
 
+	// ClaimHistoryResult help.
+	"claimhistoryresult-claimid": "The claim ID in hex",
+	"claimhistoryresult-name":    "The name of the claim",
+	"claimhistoryresult-txid":    "The hash of the transaction containing the output",
+	"claimhistoryresult-vout":    "The index of the output in the transaction",
+	"claimhistoryresult-height":  "The height of the block containing the transaction",
+	"claimhistoryresult-type":    "The operation of the output (claim, support or update)",
+
+	// GetClaimHistoryCmd help.
+	"getclaimhistory--synopsis": "Returns the claim, support and update outputs of the given claim in the main chain, oldest first.\n" +
+		"Requires the claim index (--claimindex).",
+	"getclaimhistory-claimid": "The claim ID in hex",
+
+	// GetConflictsCmd help.
+	"getconflicts--synopsis": "Returns the transactions which were rejected for double spending outputs spent by the specified mempool transaction.\n" +
+		"Only double spends with valid signatures which conflict with a transaction that does not signal replacement are recorded.",
+	"getconflicts-txid": "The hash of the transaction in the mempool",
+
+	// GetConflictsResult help.
+	"getconflictsresult-txid":      "The hash of the double spending transaction",
+	"getconflictsresult-hex":       "Hex-encoded bytes of the serialized double spending transaction",
+	"getconflictsresult-time":      "The time the double spending transaction was received in seconds since 1 Jan 1970 GMT",
+	"getconflictsresult-outpoints": "The outputs spent by both transactions in the form txid:index",
+
 	// GetMempoolInfoCmd help.
 	"getmempoolinfo--synopsis": "Returns memory pool information",
 
 	// GetMempoolInfoResult help.
-	"getmempoolinforesult-bytes": "Size in bytes of the mempool",
-	"getmempoolinforesult-size":  "Number of transactions in the mempool",
+	"getmempoolinforesult-bytes":              "Sum of the sigop-adjusted virtual sizes of all transactions in the mempool",
+	"getmempoolinforesult-size":               "Number of transactions in the mempool",
+	"getmempoolinforesult-orphans":            "Number of orphan transactions in the orphan pool",
+	"getmempoolinforesult-orphanweight":       "Sum of the weights of all orphan transactions in the orphan pool",
+	"getmempoolinforesult-orphanpeers":        "Number of peers with orphan transactions in the orphan pool",
+	"getmempoolinforesult-orphansexpired":     "Number of orphan transactions evicted after expiring since the server started",
+	"getmempoolinforesult-orphansevictedpeer": "Number of orphan transactions evicted to keep a peer within its orphan quota since the server started",
+	"getmempoolinforesult-orphansevictedpool": "Number of orphan transactions evicted to keep the orphan pool within its limits since the server started",
 
 	// GetMiningInfoResult help.
 	"getmininginforesult-blocks":             "Height of the latest best block",
@@ -464,9 +828,26 @@ var helpDescsEnUS = map[string]string{
 	"getnettotals--synopsis": "Returns a JSON object containing network traffic statistics.",
 
 	// GetNetTotalsResult help.
-	"getnettotalsresult-totalbytesrecv": "Total bytes received",
-	"getnettotalsresult-totalbytessent": "Total bytes sent",
-	"getnettotalsresult-timemillis":     "Number of milliseconds since 1 Jan 1970 GMT",
+	"getnettotalsresult-totalbytesrecv":           "Total bytes received",
+	"getnettotalsresult-totalbytessent":           "Total bytes sent",
+	"getnettotalsresult-timemillis":               "Number of milliseconds since 1 Jan 1970 GMT",
+	"getnettotalsresult-bytesrecv_per_msg":        "Total bytes received per message command",
+	"getnettotalsresult-bytesrecv_per_msg--key":   "command",
+	"getnettotalsresult-bytesrecv_per_msg--value": "n",
+	"getnettotalsresult-bytesrecv_per_msg--desc":  "The bytes received for the message command",
+	"getnettotalsresult-bytessent_per_msg":        "Total bytes sent per message command",
+	"getnettotalsresult-bytessent_per_msg--key":   "command",
+	"getnettotalsresult-bytessent_per_msg--value": "n",
+	"getnettotalsresult-bytessent_per_msg--desc":  "The bytes sent for the message command",
+	"getnettotalsresult-uploadtarget":             "The state of the upload target",
+
+	// GetNetTotalsUploadTarget help.
+	"getnettotalsuploadtarget-timeframe":               "Length of the measuring timeframe in seconds",
+	"getnettotalsuploadtarget-target":                  "Target in bytes, or 0 when there is no limit",
+	"getnettotalsuploadtarget-target_reached":          "Whether the target is reached",
+	"getnettotalsuploadtarget-serve_historical_blocks": "Whether historical blocks are served to peers without the noban permission",
+	"getnettotalsuploadtarget-bytes_left_in_cycle":     "Bytes left in the current cycle",
+	"getnettotalsuploadtarget-time_left_in_cycle":      "Seconds left in the current cycle",
 
 	// GetNodeAddressesResult help.
 	"getnodeaddressesresult-time":     "Timestamp in seconds since epoch (Jan 1 1970 GMT) keeping track of when the node was last seen",
@@ -480,28 +861,43 @@ var helpDescsEnUS = map[string]string{
 	"getnodeaddresses--result0":  "List of node addresses",
 
 	// GetPeerInfoResult help.
-	"getpeerinforesult-id":             "A unique node ID",
-	"getpeerinforesult-addr":           "The ip address and port of the peer",
-	"getpeerinforesult-addrlocal":      "Local address",
-	"getpeerinforesult-services":       "Services bitmask which represents the services supported by the peer",
-	"getpeerinforesult-relaytxes":      "Peer has requested transactions be relayed to it",
-	"getpeerinforesult-lastsend":       "Time the last message was received in seconds since 1 Jan 1970 GMT",
-	"getpeerinforesult-lastrecv":       "Time the last message was sent in seconds since 1 Jan 1970 GMT",
-	"getpeerinforesult-bytessent":      "Total bytes sent",
-	"getpeerinforesult-bytesrecv":      "Total bytes received",
-	"getpeerinforesult-conntime":       "Time the connection was made in seconds since 1 Jan 1970 GMT",
-	"getpeerinforesult-timeoffset":     "The time offset of the peer",
-	"getpeerinforesult-pingtime":       "Number of microseconds the last ping took",
-	"getpeerinforesult-pingwait":       "Number of microseconds a queued ping has been waiting for a response",
-	"getpeerinforesult-version":        "The protocol version of the peer",
-	"getpeerinforesult-subver":         "The user agent of the peer",
-	"getpeerinforesult-inbound":        "Whether or not the peer is an inbound connection",
-	"getpeerinforesult-startingheight": "The latest block height the peer knew about when the connection was established",
-	"getpeerinforesult-currentheight":  "The current height of the peer",
-	"getpeerinforesult-banscore":       "The ban score",
-	"getpeerinforesult-feefilter":      "The requested minimum fee a transaction must have to be announced to the peer",
-	"getpeerinforesult-syncnode":       "Whether or not the peer is the sync peer",
-	"getpeerinforesult-v2_connection":  "Whether or not the peer is a v2 connection",
+	"getpeerinforesult-id":              "A unique node ID",
+	"getpeerinforesult-addr":            "The ip address and port of the peer",
+	"getpeerinforesult-addrlocal":       "Local address",
+	"getpeerinforesult-services":        "Services bitmask which represents the services supported by the peer",
+	"getpeerinforesult-relaytxes":       "Peer has requested transactions be relayed to it",
+	"getpeerinforesult-lastsend":        "Time the last message was received in seconds since 1 Jan 1970 GMT",
+	"getpeerinforesult-lastrecv":        "Time the last message was sent in seconds since 1 Jan 1970 GMT",
+	"getpeerinforesult-bytessent":       "Total bytes sent",
+	"getpeerinforesult-bytesrecv":       "Total bytes received",
+	"getpeerinforesult-conntime":        "Time the connection was made in seconds since 1 Jan 1970 GMT",
+	"getpeerinforesult-timeoffset":      "The time offset of the peer",
+	"getpeerinforesult-pingtime":        "Number of microseconds the last ping took",
+	"getpeerinforesult-pingwait":        "Number of microseconds a queued ping has been waiting for a response",
+	"getpeerinforesult-version":         "The protocol version of the peer",
+	"getpeerinforesult-subver":          "The user agent of the peer",
+	"getpeerinforesult-inbound":         "Whether or not the peer is an inbound connection",
+	"getpeerinforesult-startingheight":  "The latest block height the peer knew about when the connection was established",
+	"getpeerinforesult-currentheight":   "The current height of the peer",
+	"getpeerinforesult-banscore":        "The ban score",
+	"getpeerinforesult-misbehavior":     "The most recent misbehavior of the peer from oldest to newest",
+	"getpeerinforesult-feefilter":       "The requested minimum fee a transaction must have to be announced to the peer",
+	"getpeerinforesult-syncnode":        "Whether or not the peer is the sync peer",
+	"getpeerinforesult-v2_connection":   "Whether or not the peer is a v2 connection",
+	"getpeerinforesult-txinvrecv":       "The number of transactions announced by the peer",
+	"getpeerinforesult-txinvthrottled":  "The number of transaction announcements ignored because the peer exceeded the relay rate limit",
+	"getpeerinforesult-txrecv":          "The number of transactions received from the peer",
+	"getpeerinforesult-unknownmsgs":     "The number of messages with an unknown command received from the peer",
+	"getpeerinforesult-oversizedmsgs":   "The number of messages which exceeded the maximum payload size received from the peer",
+	"getpeerinforesult-malformedmsgs":   "The number of messages which could not be decoded received from the peer",
+	"getpeerinforesult-permissions":     "The permissions granted to the peer by the whitelist and whitebind options",
+	"getpeerinforesult-connection_type": "The kind of connection to the peer (inbound, manual, outbound-full-relay, block-relay-only or feeler)",
+
+	// PeerMisbehavior help.
+	"peermisbehavior-time":     "Time the misbehavior was recorded in seconds since 1 Jan 1970 GMT",
+	"peermisbehavior-category": "The category of the misbehavior (flood, notfound, protocol or malformed)",
+	"peermisbehavior-reason":   "The reason for the misbehavior",
+	"peermisbehavior-banscore": "The ban score after the misbehavior was recorded",
 
 	// GetPeerInfoCmd help.
 	"getpeerinfo--synopsis": "Returns data about each connected network peer as an array of json objects.",
@@ -514,8 +910,43 @@ var helpDescsEnUS = map[string]string{
 	"getrawmempoolverboseresult-startingpriority": "Priority when transaction entered the pool",
 	"getrawmempoolverboseresult-currentpriority":  "Current priority",
 	"getrawmempoolverboseresult-depends":          "Unconfirmed transactions used as inputs for this transaction",
-	"getrawmempoolverboseresult-vsize":            "The virtual size of a transaction",
+	"getrawmempoolverboseresult-vsize":            "The virtual size of a transaction adjusted for its signature operation cost",
 	"getrawmempoolverboseresult-weight":           "The transaction's weight (between vsize*4-3 and vsize*4)",
+	"getrawmempoolverboseresult-annotations":      "Notes attached to the transaction by the mempool policy hooks (omitted when there are none)",
+
+	// GetNameHistoryCmd help.
+	"getnamehistory--synopsis": "Returns the claim, support and update outputs for the given name in the main chain, oldest first.\n" +
+		"Requires the claim index (--claimindex).",
+	"getnamehistory-name": "The name",
+
+	// GetNATInfoCmd help.
+	"getnatinfo--synopsis": "Returns the state of the port mapping of the listening port on the NAT gateway.",
+
+	// GetNATInfoResult help.
+	"getnatinforesult-protocol":     "The NAT traversal protocol used to map the port (UPnP, NAT-PMP or PCP)",
+	"getnatinforesult-externalip":   "The external address of the gateway (omitted until the port has been mapped)",
+	"getnatinforesult-externalport": "The external port mapped to the listening port (omitted until the port has been mapped)",
+	"getnatinforesult-internalport": "The listening port which is mapped",
+	"getnatinforesult-expires":      "The time the current port mapping expires in seconds since 1 Jan 1970 GMT unless it is renewed (omitted until the port has been mapped)",
+	"getnatinforesult-lasterror":    "The error of the last attempt to map the port (omitted when it succeeded)",
+
+	// GetStratumInfoCmd help.
+	"getstratuminfo--synopsis": "Returns the share statistics of the workers connected to the stratum server.",
+
+	// GetStratumInfoResult help.
+	"getstratuminforesult-clients": "The number of connected stratum clients",
+	"getstratuminforesult-workers": "The share statistics of each worker",
+
+	// StratumWorkerResult help.
+	"stratumworkerresult-name":         "The name the worker authorized with",
+	"stratumworkerresult-connections":  "The number of connections the worker is authorized on",
+	"stratumworkerresult-difficulty":   "The current share difficulty of the worker",
+	"stratumworkerresult-hashespersec": "The hash rate of the worker estimated from the shares of the last 10 minutes",
+	"stratumworkerresult-accepted":     "The number of accepted shares",
+	"stratumworkerresult-rejected":     "The number of rejected shares",
+	"stratumworkerresult-stale":        "The number of shares for jobs which were no longer current",
+	"stratumworkerresult-blocks":       "The number of blocks found by the worker which were accepted",
+	"stratumworkerresult-lastshare":    "The time of the last accepted share in seconds since 1 Jan 1970 GMT (0 when there is none)",
 
 	// GetRawMempoolCmd help.
 	"getrawmempool--synopsis":   "Returns information about all of the transactions currently in the memory pool.",
@@ -532,6 +963,20 @@ var helpDescsEnUS = map[string]string{
 	"getrawtransaction--condition1": "verbose=true",
 	"getrawtransaction--result0":    "Hex-encoded bytes of the serialized transaction",
 
+	// SpentInfoRequest help.
+	"spentinforequest-txid":  "The hash of the transaction which created the output",
+	"spentinforequest-index": "The index of the output in the transaction",
+
+	// GetSpentInfoCmd help.
+	"getspentinfo--synopsis": "Returns the input which spent the given output.\n" +
+		"Requires the spent output index (--spentindex).",
+	"getspentinfo-request": "The output",
+
+	// GetSpentInfoResult help.
+	"getspentinforesult-txid":   "The hash of the spending transaction",
+	"getspentinforesult-index":  "The index of the spending input in the transaction",
+	"getspentinforesult-height": "The height of the block containing the spending transaction or -1 when it is in the memory pool",
+
 	// GetTxOutResult help.
 	"gettxoutresult-bestblock":     "The block hash that contains the transaction output",
 	"gettxoutresult-confirmations": "The number of confirmations",
@@ -546,10 +991,47 @@ var helpDescsEnUS = map[string]string{
 	"gettxout-vout":           "The index of the output",
 	"gettxout-includemempool": "Include the mempool when true",
 
+	// GetTxOutSetInfoCmd help.
+	"gettxoutsetinfo--synopsis": "Returns statistics about the unspent transaction output set as of the best block.\n" +
+		"Note this call may take some time since the whole unspent transaction output set is read.",
+	"gettxoutsetinfo-verbose": "Also break the unspent outputs down by the class of their public key scripts and by their claim, support or update prefix",
+
+	// GetTxOutSetInfoResult help.
+	"gettxoutsetinforesult-height":                "The height of the best block",
+	"gettxoutsetinforesult-bestblock":             "The hash of the best block",
+	"gettxoutsetinforesult-transactions":          "The number of transactions with unspent outputs",
+	"gettxoutsetinforesult-txouts":                "The number of unspent outputs",
+	"gettxoutsetinforesult-bogosize":              "A database independent metric for the size of the unspent output set",
+	"gettxoutsetinforesult-hash_serialized_2":     "The hash of the serialized unspent output set, computed the same way as by Bitcoin Core",
+	"gettxoutsetinforesult-disk_size":             "The size of the unspent output set in the database in bytes",
+	"gettxoutsetinforesult-total_amount":          "The total value of the unspent outputs in BTC",
+	"gettxoutsetinforesult-script_classes":        "The unspent outputs broken down by script class (only with verbose=true)",
+	"gettxoutsetinforesult-script_classes--key":   "class",
+	"gettxoutsetinforesult-script_classes--value": "Object containing the unspent outputs of the script class",
+	"gettxoutsetinforesult-script_classes--desc":  "Unspent outputs keyed by the script class, such as pubkeyhash or witness_v0_keyhash",
+	"gettxoutsetinforesult-claim_scripts":         "The unspent outputs with a claim, support or update prefix (only with verbose=true)",
+	"gettxoutsetinforesult-claim_scripts--key":    "kind",
+	"gettxoutsetinforesult-claim_scripts--value":  "Object containing the unspent outputs with the prefix",
+	"gettxoutsetinforesult-claim_scripts--desc":   "Unspent outputs keyed by the kind of prefix, which is claim, support or update",
+
+	// TxOutSetScriptClass help.
+	"txoutsetscriptclass-txouts":       "The number of unspent outputs of the script class",
+	"txoutsetscriptclass-total_amount": "The total value of the unspent outputs of the script class in BTC",
+
 	// InvalidateBlockCmd help.
 	"invalidateblock--synopsis": "Invalidates the block of the given block hash. To re-validate the invalidated block, use the reconsiderblock rpc",
 	"invalidateblock-blockhash": "The block hash of the block to invalidate",
 
+	// ListBannedResult help.
+	"listbannedresult-address":        "The banned subnet, or address for addresses which are not IP addresses",
+	"listbannedresult-ban_created":    "Time the ban was created in seconds since 1 Jan 1970 GMT",
+	"listbannedresult-banned_until":   "Time the ban expires in seconds since 1 Jan 1970 GMT",
+	"listbannedresult-ban_duration":   "The duration of the ban in seconds",
+	"listbannedresult-time_remaining": "The time remaining until the ban expires in seconds",
+
+	// ListBannedCmd help.
+	"listbanned--synopsis": "Returns the banned subnets and addresses.",
+
 	// HelpCmd help.
 	"help--synopsis":   "Returns a list of all commands or help for a specified command.",
 	"help-command":     "The command to retrieve help for",
@@ -558,10 +1040,23 @@ var helpDescsEnUS = map[string]string{
 	"help--result0":    "List of commands",
 	"help--result1":    "Help for specified command",
 
+	// ImportBlocksCmd help.
+	"importblocks--synopsis": "Starts importing the blocks of the given block files in the background, such as bootstrap.dat files or the blk*.dat files of another node.\n" +
+		"The blocks are fully validated and blocks which are already known are skipped.\n" +
+		"The progress is logged and only a single import may run at a time.",
+	"importblocks-files": "The paths of the block files on the server, which are imported in the given order",
+
 	// PingCmd help.
 	"ping--synopsis": "Queues a ping to be sent to each connected peer.\n" +
 		"Ping times are provided by getpeerinfo via the pingtime and pingwait fields.",
 
+	// PruneBlockchainCmd help.
+	"pruneblockchain--synopsis": "Deletes the stored blocks up to the given height along with their undo data when running in prune mode.\n" +
+		"Blocks are deleted by whole block files, so blocks stored along with a block above the height are kept.\n" +
+		"The last 288 blocks are always kept.",
+	"pruneblockchain-height":   "The height up to which blocks are deleted",
+	"pruneblockchain--result0": "The height of the last block which was deleted, or -1 when no blocks have been deleted",
+
 	// SearchRawTransactionsCmd help.
 	"searchrawtransactions--synopsis": "Returns raw data for transactions involving the passed address.\n" +
 		"Returned transactions are pulled from both the database, and transactions currently in the mempool.\n" +
@@ -586,6 +1081,24 @@ var helpDescsEnUS = map[string]string{
 	"sendrawtransaction--result0":     "The hash of the transaction",
 	"allowhighfeesormaxfeerate-value": "Either the boolean value for the allowhighfees parameter in bitcoind < v0.19.0 or the numerical value for the maxfeerate field in bitcoind v0.19.0 and later",
 
+	// SetConnectionTargetsCmd help.
+	"setconnectiontargets--synopsis":      "Changes the number of outbound connections of each kind the server maintains.  New connections are made right away when a target is raised, while connections exceeding a lowered target are kept but not replaced once they are lost.",
+	"setconnectiontargets-outbound":       "The number of regular outbound connections to maintain (keeps the current target when omitted)",
+	"setconnectiontargets-blockrelayonly": "The number of outbound connections which only relay blocks to maintain (keeps the current target when omitted)",
+	"setconnectiontargets-onion":          "The number of outbound connections to onion addresses to maintain (keeps the current target when omitted)",
+
+	// SetSignalBitCmd help.
+	"setsignalbit--synopsis": "Set whether or not the given version bit is signalled in generated blocks in addition to the bits of the known deployments which are being voted on.",
+	"setsignalbit-bit":       "The version bit (0-28)",
+	"setsignalbit-signal":    "Use true to signal the bit, false to stop signalling it",
+
+	// SetBanCmd help.
+	"setban--synopsis": "Bans a subnet or address and disconnects the peers it covers, or lifts its ban.  Bans are kept across restarts.",
+	"setban-subnet":    "The subnet in CIDR notation, IP address, or onion address to operate on",
+	"setban-command":   "'add' to ban the subnet or address, 'remove' to lift its ban",
+	"setban-bantime":   "The number of seconds to ban for, or the unix timestamp the ban expires at when absolute is true (0 for the configured ban duration)",
+	"setban-absolute":  "Whether the ban time is an absolute unix timestamp",
+
 	// SetGenerateCmd help.
 	"setgenerate--synopsis":    "Set the server to generate coins (mine) or not.",
 	"setgenerate-generate":     "Use true to enable generation, false to disable it",
@@ -612,6 +1125,27 @@ var helpDescsEnUS = map[string]string{
 	"submitblock--condition1": "Block rejected",
 	"submitblock--result1":    "The reason the block was rejected",
 
+	// TraceScriptCmd help.
+	"tracescript--synopsis": "Executes the scripts of a transaction input and returns the state of the stacks before each opcode is executed.\n" +
+		"The outputs spent by the transaction must be in the memory pool or the unspent transaction output set.\n" +
+		"A script which fails to execute is not an error, the result reports the failure instead.",
+	"tracescript-hextx": "Serialized, hex-encoded transaction",
+	"tracescript-vin":   "The index of the input to trace",
+
+	// TraceScriptResult help.
+	"tracescriptresult-vin":     "The index of the traced input",
+	"tracescriptresult-scripts": "The disassembled scripts executed for the input in order, which include the redeem or witness script when it was reached",
+	"tracescriptresult-steps":   "The state of the engine before each executed opcode and after the last one",
+	"tracescriptresult-valid":   "Whether or not the input is valid",
+	"tracescriptresult-error":   "The reason the input is invalid",
+
+	// TraceScriptStep help.
+	"tracescriptstep-script":   "The index of the script being executed",
+	"tracescriptstep-opcode":   "The index of the next opcode to be executed",
+	"tracescriptstep-next":     "The disassembled next opcode, omitted once the execution is done",
+	"tracescriptstep-stack":    "The hex-encoded items of the data stack, from bottom to top",
+	"tracescriptstep-altstack": "The hex-encoded items of the alternate stack, from bottom to top",
+
 	// ValidateAddressResult help.
 	"validateaddresschainresult-isvalid":         "Whether or not the address is valid",
 	"validateaddresschainresult-address":         "The bitcoin address (only when isvalid is true)",
@@ -634,6 +1168,19 @@ var helpDescsEnUS = map[string]string{
 	"verifychain-checkdepth": "The number of blocks to check",
 	"verifychain--result0":   "Whether or not the chain verified",
 
+	// VerifyIndexCmd help.
+	"verifyindex--synopsis": "Verifies the entries of an optional index against the blocks in the database.\n" +
+		"The index can be rebuilt with rebuildindex when an entry doesn't match its block.",
+	"verifyindex-indexname":  "The name of the index to verify, as returned by getindexinfo",
+	"verifyindex-checkdepth": "The number of blocks to check up to the last block which was indexed (0 = all)",
+
+	// VerifyIndexResult help.
+	"verifyindexresult-name":     "The name of the index",
+	"verifyindexresult-height":   "The height of the last block which was indexed",
+	"verifyindexresult-checked":  "The number of blocks which were checked",
+	"verifyindexresult-valid":    "Whether the entries of all checked blocks match the blocks",
+	"verifyindexresult-mismatch": "The first entry which doesn't match its block (only when the index is not valid)",
+
 	// VerifyMessageCmd help.
 	"verifymessage--synopsis": "Verify a signed message.",
 	"verifymessage-address":   "The bitcoin address to use for the signature",
@@ -653,6 +1200,18 @@ var helpDescsEnUS = map[string]string{
 	// StopNotifyBlocksCmd help.
 	"stopnotifyblocks--synopsis": "Cancel registered notifications for whenever a block is connected or disconnected from the main (best) chain.",
 
+	// NotifyDoubleSpendsCmd help.
+	"notifydoublespends--synopsis": "Send a doublespend notification whenever a transaction double spending a transaction in the mempool that does not signal replacement is received.",
+
+	// StopNotifyDoubleSpendsCmd help.
+	"stopnotifydoublespends--synopsis": "Stop sending doublespend notifications.",
+
+	// NotifyMempoolEventsCmd help.
+	"notifymempoolevents--synopsis": "Send a mempoolevent notification whenever a transaction is accepted into, replaced in, or removed from the mempool.",
+
+	// StopNotifyMempoolEventsCmd help.
+	"stopnotifymempoolevents--synopsis": "Stop sending mempoolevent notifications.",
+
 	// NotifyNewTransactionsCmd help.
 	"notifynewtransactions--synopsis": "Send either a txaccepted or a txacceptedverbose notification when a new transaction is accepted into the mempool.",
 	"notifynewtransactions-verbose":   "Specifies which type of notification to receive. If verbose is true, then the caller receives txacceptedverbose, otherwise the caller receives txaccepted",
@@ -687,6 +1246,12 @@ var helpDescsEnUS = map[string]string{
 	"loadtxfilter-addresses": "Array of addresses to add to the transaction filter",
 	"loadtxfilter-outpoints": "Array of outpoints to add to the transaction filter",
 
+	// RebuildIndexCmd help.
+	"rebuildindex--synopsis": "Starts dropping an optional index and building it again from the blocks in the database in the background while the other indexes are kept in step with the chain.\n" +
+		"Rebuilding the transaction index also rebuilds the address index, which relies on it.\n" +
+		"An interrupted rebuild is finished on the next start.",
+	"rebuildindex-indexname": "The name of the index to rebuild, as returned by getindexinfo",
+
 	// ReconsiderBlockCmd help.
 	"reconsiderblock--synopsis": "Reconsiders the block of the given block hash. Can be used to re-validate blocks invalidated with invalidateblock",
 	"reconsiderblock-blockhash": "The block hash of the block to reconsider",
@@ -762,54 +1327,91 @@ var helpDescsEnUS = map[string]string{
 // pointer to the type (or nil to indicate no return value).
 var rpcResultTypes = map[string][]interface{}{
 	"addnode":                nil,
+	"clearbanned":            nil,
+	"combinepsbt":            {(*string)(nil)},
+	"compactdb":              nil,
+	"converttopsbt":          {(*string)(nil)},
 	"createrawtransaction":   {(*string)(nil)},
 	"debuglevel":             {(*string)(nil), (*string)(nil)},
+	"decodepsbt":             {(*btcjson.DecodePsbtResult)(nil)},
 	"decoderawtransaction":   {(*btcjson.TxRawDecodeResult)(nil)},
 	"decodescript":           {(*btcjson.DecodeScriptResult)(nil)},
+	"dumpprofiles":           {(*btcjson.DumpProfilesResult)(nil)},
 	"estimatefee":            {(*float64)(nil)},
+	"estimatesmartfee":       {(*btcjson.EstimateSmartFeeResult)(nil)},
+	"finalizepsbt":           {(*btcjson.FinalizePsbtResult)(nil)},
 	"generate":               {(*[]string)(nil)},
+	"generateblock":          {(*btcjson.GenerateBlockResult)(nil)},
+	"generatetoaddress":      {(*[]string)(nil)},
 	"getaddednodeinfo":       {(*[]string)(nil), (*[]btcjson.GetAddedNodeInfoResult)(nil)},
+	"getaddressbalance":      {(*btcjson.GetAddressBalanceResult)(nil)},
+	"getaddresstxids":        {(*[]string)(nil)},
+	"getaddressutxos":        {(*[]btcjson.AddressUtxoResult)(nil), (*btcjson.GetAddressUtxosChainInfoResult)(nil)},
 	"getbestblock":           {(*btcjson.GetBestBlockResult)(nil)},
 	"getbestblockhash":       {(*string)(nil)},
 	"getblock":               {(*string)(nil), (*btcjson.GetBlockVerboseResult)(nil)},
 	"getblockcount":          {(*int64)(nil)},
+	"getblockbenchmarks":     {(*[]btcjson.GetBlockBenchmarksResult)(nil)},
 	"getblockhash":           {(*string)(nil)},
 	"getblockheader":         {(*string)(nil), (*btcjson.GetBlockHeaderVerboseResult)(nil)},
+	"getblockrange":          {(*[]btcjson.GetBlockRangeResult)(nil)},
+	"getblockrepairs":        {(*[]btcjson.BlockRepairResult)(nil)},
 	"getblocktemplate":       {(*btcjson.GetBlockTemplateResult)(nil), (*string)(nil), nil},
 	"getblockchaininfo":      {(*btcjson.GetBlockChainInfoResult)(nil)},
 	"getchaintips":           {(*[]btcjson.GetChainTipsResult)(nil)},
 	"getcfilter":             {(*string)(nil)},
 	"getcfilterheader":       {(*string)(nil)},
+	"getcheckpoints":         {(*[]btcjson.GetCheckpointsResult)(nil)},
+	"getclaimhistory":        {(*[]btcjson.ClaimHistoryResult)(nil)},
+	"getconflicts":           {(*[]btcjson.GetConflictsResult)(nil)},
 	"getconnectioncount":     {(*int32)(nil)},
+	"getconnectiontargets":   {(*btcjson.ConnectionTargetsResult)(nil)},
 	"getcurrentnet":          {(*uint32)(nil)},
+	"getdbinfo":              {(*btcjson.GetDBInfoResult)(nil)},
+	"getdeploymentinfo":      {(*btcjson.GetDeploymentInfoResult)(nil)},
 	"getdifficulty":          {(*float64)(nil)},
 	"getgenerate":            {(*bool)(nil)},
 	"gethashespersec":        {(*float64)(nil)},
 	"getheaders":             {(*[]string)(nil)},
+	"getindexinfo":           {(*map[string]btcjson.GetIndexInfoResult)(nil)},
 	"getinfo":                {(*btcjson.InfoChainResult)(nil)},
 	"getmempoolinfo":         {(*btcjson.GetMempoolInfoResult)(nil)},
 	"getmininginfo":          {(*btcjson.GetMiningInfoResult)(nil)},
+	"getnamehistory":         {(*[]btcjson.ClaimHistoryResult)(nil)},
+	"getnatinfo":             {(*btcjson.GetNATInfoResult)(nil)},
 	"getnettotals":           {(*btcjson.GetNetTotalsResult)(nil)},
 	"getnetworkhashps":       {(*float64)(nil)},
 	"getnodeaddresses":       {(*[]btcjson.GetNodeAddressesResult)(nil)},
 	"getpeerinfo":            {(*[]btcjson.GetPeerInfoResult)(nil)},
 	"getrawmempool":          {(*[]string)(nil), (*btcjson.GetRawMempoolVerboseResult)(nil)},
 	"getrawtransaction":      {(*string)(nil), (*btcjson.TxRawResult)(nil)},
+	"getspentinfo":           {(*btcjson.GetSpentInfoResult)(nil)},
+	"getstratuminfo":         {(*btcjson.GetStratumInfoResult)(nil)},
 	"gettxout":               {(*btcjson.GetTxOutResult)(nil)},
+	"gettxoutsetinfo":        {(*btcjson.GetTxOutSetInfoResult)(nil)},
 	"node":                   nil,
 	"help":                   {(*string)(nil), (*string)(nil)},
+	"importblocks":           nil,
 	"invalidateblock":        nil,
+	"listbanned":             {(*[]btcjson.ListBannedResult)(nil)},
 	"ping":                   nil,
+	"pruneblockchain":        {(*int64)(nil)},
+	"rebuildindex":           nil,
 	"reconsiderblock":        nil,
 	"searchrawtransactions":  {(*string)(nil), (*[]btcjson.SearchRawTransactionsResult)(nil)},
 	"sendrawtransaction":     {(*string)(nil)},
+	"setban":                 nil,
 	"setgenerate":            nil,
+	"setconnectiontargets":   {(*btcjson.ConnectionTargetsResult)(nil)},
+	"setsignalbit":           nil,
 	"signmessagewithprivkey": {(*string)(nil)},
 	"stop":                   {(*string)(nil)},
 	"submitblock":            {nil, (*string)(nil)},
+	"tracescript":            {(*btcjson.TraceScriptResult)(nil)},
 	"uptime":                 {(*int64)(nil)},
 	"validateaddress":        {(*btcjson.ValidateAddressChainResult)(nil)},
 	"verifychain":            {(*bool)(nil)},
+	"verifyindex":            {(*btcjson.VerifyIndexResult)(nil)},
 	"verifymessage":          {(*bool)(nil)},
 	"version":                {(*map[string]btcjson.VersionResult)(nil)},
 	"testmempoolaccept":      {(*[]btcjson.TestMempoolAcceptResult)(nil)},
@@ -820,6 +1422,10 @@ var rpcResultTypes = map[string][]interface{}{
 	"session":                   {(*btcjson.SessionResult)(nil)},
 	"notifyblocks":              nil,
 	"stopnotifyblocks":          nil,
+	"notifydoublespends":        nil,
+	"stopnotifydoublespends":    nil,
+	"notifymempoolevents":       nil,
+	"stopnotifymempoolevents":   nil,
 	"notifynewtransactions":     nil,
 	"stopnotifynewtransactions": nil,
 	"notifyreceived":            nil,
@@ -27,6 +27,7 @@ import (
 	"github.com/btcsuite/btcd/chaincfg/v2"
 	"github.com/btcsuite/btcd/chainhash/v2"
 	"github.com/btcsuite/btcd/database"
+	"github.com/btcsuite/btcd/mempool"
 	"github.com/btcsuite/btcd/txscript/v2"
 	"github.com/btcsuite/btcd/wire/v2"
 	"github.com/btcsuite/websocket"
@@ -67,11 +68,15 @@ var wsHandlersBeforeInit = map[string]wsCommandHandler{
 	"loadtxfilter":              handleLoadTxFilter,
 	"help":                      handleWebsocketHelp,
 	"notifyblocks":              handleNotifyBlocks,
+	"notifydoublespends":        handleNotifyDoubleSpends,
+	"notifymempoolevents":       handleNotifyMempoolEvents,
 	"notifynewtransactions":     handleNotifyNewTransactions,
 	"notifyreceived":            handleNotifyReceived,
 	"notifyspent":               handleNotifySpent,
 	"session":                   handleSession,
 	"stopnotifyblocks":          handleStopNotifyBlocks,
+	"stopnotifydoublespends":    handleStopNotifyDoubleSpends,
+	"stopnotifymempoolevents":   handleStopNotifyMempoolEvents,
 	"stopnotifynewtransactions": handleStopNotifyNewTransactions,
 	"stopnotifyspent":           handleStopNotifySpent,
 	"stopnotifyreceived":        handleStopNotifyReceived,
@@ -227,6 +232,19 @@ func (m *wsNotificationManager) NotifyBlockDisconnected(block *btcutil.Block) {
 	}
 }
 
+// NotifyReorganization passes a reorganization of the main chain to the
+// notification manager for block notification processing.
+func (m *wsNotificationManager) NotifyReorganization(reorg *blockchain.ReorganizationNtfnData) {
+	// As NotifyReorganization will be called by the block manager
+	// and the RPC server may no longer be running, use a select
+	// statement to unblock enqueuing the notification once the RPC
+	// server has begun shutting down.
+	select {
+	case m.queueNotification <- (*notificationReorganization)(reorg):
+	case <-m.quit:
+	}
+}
+
 // NotifyMempoolTx passes a transaction accepted by mempool to the
 // notification manager for transaction notification processing.  If
 // isNew is true, the tx is a new transaction, rather than one
@@ -247,6 +265,19 @@ func (m *wsNotificationManager) NotifyMempoolTx(tx *btcutil.Tx, isNew bool) {
 	}
 }
 
+// NotifyMempoolEvent passes a mempool event to the notification manager for
+// delivery to websocket clients subscribed to mempool events.
+func (m *wsNotificationManager) NotifyMempoolEvent(n *mempool.Notification) {
+	// As NotifyMempoolEvent will be called by mempool and the RPC server
+	// may no longer be running, use a select statement to unblock
+	// enqueuing the notification once the RPC server has begun
+	// shutting down.
+	select {
+	case m.queueNotification <- (*notificationMempoolEvent)(n):
+	case <-m.quit:
+	}
+}
+
 // wsClientFilter tracks relevant addresses for each websocket client for
 // the `rescanblocks` extension. It is modified by the `loadtxfilter` command.
 //
@@ -404,10 +435,12 @@ func (f *wsClientFilter) removeUnspentOutPoint(op *wire.OutPoint) {
 // Notification types
 type notificationBlockConnected btcutil.Block
 type notificationBlockDisconnected btcutil.Block
+type notificationReorganization blockchain.ReorganizationNtfnData
 type notificationTxAcceptedByMempool struct {
 	isNew bool
 	tx    *btcutil.Tx
 }
+type notificationMempoolEvent mempool.Notification
 
 // Notification control requests
 type notificationRegisterClient wsClient
@@ -416,6 +449,10 @@ type notificationRegisterBlocks wsClient
 type notificationUnregisterBlocks wsClient
 type notificationRegisterNewMempoolTxs wsClient
 type notificationUnregisterNewMempoolTxs wsClient
+type notificationRegisterMempoolEvents wsClient
+type notificationUnregisterMempoolEvents wsClient
+type notificationRegisterDoubleSpends wsClient
+type notificationUnregisterDoubleSpends wsClient
 type notificationRegisterSpent struct {
 	wsc *wsClient
 	ops []*wire.OutPoint
@@ -448,6 +485,8 @@ func (m *wsNotificationManager) notificationHandler() {
 	// since it is quite a bit more efficient than using the entire struct.
 	blockNotifications := make(map[chan struct{}]*wsClient)
 	txNotifications := make(map[chan struct{}]*wsClient)
+	mempoolEventNotifications := make(map[chan struct{}]*wsClient)
+	doubleSpendNotifications := make(map[chan struct{}]*wsClient)
 	watchedOutPoints := make(map[wire.OutPoint]map[chan struct{}]*wsClient)
 	watchedAddrs := make(map[string]map[chan struct{}]*wsClient)
 
@@ -489,6 +528,12 @@ out:
 						block)
 				}
 
+			case *notificationReorganization:
+				if len(blockNotifications) != 0 {
+					m.notifyReorganization(blockNotifications,
+						(*blockchain.ReorganizationNtfnData)(n))
+				}
+
 			case *notificationTxAcceptedByMempool:
 				if n.isNew && len(txNotifications) != 0 {
 					m.notifyForNewTx(txNotifications, n.tx)
@@ -496,6 +541,19 @@ out:
 				m.notifyForTx(watchedOutPoints, watchedAddrs, n.tx, nil)
 				m.notifyRelevantTxAccepted(n.tx, clients)
 
+			case *notificationMempoolEvent:
+				if n.Type == mempool.NTTxDoubleSpend {
+					if len(doubleSpendNotifications) != 0 {
+						m.notifyDoubleSpend(doubleSpendNotifications,
+							(*mempool.Notification)(n))
+					}
+					break
+				}
+				if len(mempoolEventNotifications) != 0 {
+					m.notifyMempoolEvent(mempoolEventNotifications,
+						(*mempool.Notification)(n))
+				}
+
 			case *notificationRegisterBlocks:
 				wsc := (*wsClient)(n)
 				blockNotifications[wsc.quit] = wsc
@@ -514,6 +572,8 @@ out:
 				// the client itself.
 				delete(blockNotifications, wsc.quit)
 				delete(txNotifications, wsc.quit)
+				delete(mempoolEventNotifications, wsc.quit)
+				delete(doubleSpendNotifications, wsc.quit)
 				for k := range wsc.spentRequests {
 					op := k
 					m.removeSpentRequest(watchedOutPoints, wsc, &op)
@@ -543,6 +603,22 @@ out:
 				wsc := (*wsClient)(n)
 				delete(txNotifications, wsc.quit)
 
+			case *notificationRegisterMempoolEvents:
+				wsc := (*wsClient)(n)
+				mempoolEventNotifications[wsc.quit] = wsc
+
+			case *notificationUnregisterMempoolEvents:
+				wsc := (*wsClient)(n)
+				delete(mempoolEventNotifications, wsc.quit)
+
+			case *notificationRegisterDoubleSpends:
+				wsc := (*wsClient)(n)
+				doubleSpendNotifications[wsc.quit] = wsc
+
+			case *notificationUnregisterDoubleSpends:
+				wsc := (*wsClient)(n)
+				delete(doubleSpendNotifications, wsc.quit)
+
 			default:
 				rpcsLog.Warn("Unhandled notification type")
 			}
@@ -687,6 +763,47 @@ func (*wsNotificationManager) notifyBlockDisconnected(clients map[chan struct{}]
 	}
 }
 
+// notifyReorganization notifies websocket clients that have registered for
+// block updates when the main chain is reorganized.  It is sent after the
+// notifications for the disconnected and connected blocks.
+func (*wsNotificationManager) notifyReorganization(clients map[chan struct{}]*wsClient,
+	reorg *blockchain.ReorganizationNtfnData) {
+
+	result := btcjson.ReorganizationResult{
+		OldHash:      reorg.OldHash.String(),
+		OldHeight:    reorg.OldHeight,
+		NewHash:      reorg.NewHash.String(),
+		NewHeight:    reorg.NewHeight,
+		ForkHash:     reorg.ForkHash.String(),
+		ForkHeight:   reorg.ForkHeight,
+		Disconnected: make([]string, 0, len(reorg.Disconnected)),
+		Connected:    make([]string, 0, len(reorg.Connected)),
+		ClaimNames:   make([]string, 0, len(reorg.ClaimNames)),
+	}
+	for i := range reorg.Disconnected {
+		result.Disconnected = append(result.Disconnected,
+			reorg.Disconnected[i].String())
+	}
+	for i := range reorg.Connected {
+		result.Connected = append(result.Connected,
+			reorg.Connected[i].String())
+	}
+	for _, name := range reorg.ClaimNames {
+		result.ClaimNames = append(result.ClaimNames, string(name))
+	}
+
+	ntfn := btcjson.NewReorganizationNtfn(result)
+	marshalledJSON, err := btcjson.MarshalCmd(btcjson.RpcVersion1, nil, ntfn)
+	if err != nil {
+		rpcsLog.Errorf("Failed to marshal reorganization notification: "+
+			"%v", err)
+		return
+	}
+	for _, wsc := range clients {
+		wsc.QueueNotification(marshalledJSON)
+	}
+}
+
 // notifyFilteredBlockConnected notifies websocket clients that have registered for
 // block updates when a block is connected to the main chain.
 func (m *wsNotificationManager) notifyFilteredBlockConnected(clients map[chan struct{}]*wsClient,
@@ -825,6 +942,89 @@ func (m *wsNotificationManager) notifyForNewTx(clients map[chan struct{}]*wsClie
 	}
 }
 
+// RegisterMempoolEventUpdates requests notifications to the passed websocket
+// client when transactions are added to or removed from the memory pool.
+func (m *wsNotificationManager) RegisterMempoolEventUpdates(wsc *wsClient) {
+	m.queueNotification <- (*notificationRegisterMempoolEvents)(wsc)
+}
+
+// UnregisterMempoolEventUpdates removes notifications to the passed websocket
+// client when transactions are added to or removed from the memory pool.
+func (m *wsNotificationManager) UnregisterMempoolEventUpdates(wsc *wsClient) {
+	m.queueNotification <- (*notificationUnregisterMempoolEvents)(wsc)
+}
+
+// mempoolEventNames maps mempool notification types to the event names used
+// in mempoolevent notifications.
+var mempoolEventNames = map[mempool.NotificationType]string{
+	mempool.NTTxAccepted:        btcjson.MempoolEventAccepted,
+	mempool.NTTxRemovedBlock:    btcjson.MempoolEventRemovedBlock,
+	mempool.NTTxRemovedConflict: btcjson.MempoolEventRemovedConflict,
+	mempool.NTTxRemovedExpiry:   btcjson.MempoolEventRemovedExpiry,
+	mempool.NTTxReplaced:        btcjson.MempoolEventReplaced,
+	mempool.NTTxRemoved:         btcjson.MempoolEventRemoved,
+}
+
+// notifyMempoolEvent notifies websocket clients that have registered for
+// mempool event updates of the passed mempool notification.
+func (m *wsNotificationManager) notifyMempoolEvent(clients map[chan struct{}]*wsClient,
+	n *mempool.Notification) {
+
+	event, ok := mempoolEventNames[n.Type]
+	if !ok {
+		rpcsLog.Warnf("Unhandled mempool notification type %v", n.Type)
+		return
+	}
+	var conflict string
+	if n.Conflict != nil {
+		conflict = n.Conflict.String()
+	}
+
+	ntfn := btcjson.NewMempoolEventNtfn(event, n.TxDesc.Tx.Hash().String(),
+		conflict)
+	marshalledJSON, err := btcjson.MarshalCmd(btcjson.RpcVersion1, nil, ntfn)
+	if err != nil {
+		rpcsLog.Errorf("Failed to marshal mempool event notification: "+
+			"%v", err)
+		return
+	}
+	for _, wsc := range clients {
+		wsc.QueueNotification(marshalledJSON)
+	}
+}
+
+// RegisterDoubleSpendUpdates requests notifications to the passed websocket
+// client when a transaction double spending a mempool transaction is
+// rejected.
+func (m *wsNotificationManager) RegisterDoubleSpendUpdates(wsc *wsClient) {
+	m.queueNotification <- (*notificationRegisterDoubleSpends)(wsc)
+}
+
+// UnregisterDoubleSpendUpdates removes notifications to the passed websocket
+// client when a transaction double spending a mempool transaction is
+// rejected.
+func (m *wsNotificationManager) UnregisterDoubleSpendUpdates(wsc *wsClient) {
+	m.queueNotification <- (*notificationUnregisterDoubleSpends)(wsc)
+}
+
+// notifyDoubleSpend notifies websocket clients that have registered for
+// double spend updates of the passed double spend notification.
+func (m *wsNotificationManager) notifyDoubleSpend(clients map[chan struct{}]*wsClient,
+	n *mempool.Notification) {
+
+	ntfn := btcjson.NewDoubleSpendNtfn(n.TxDesc.Tx.Hash().String(),
+		n.Conflict.String())
+	marshalledJSON, err := btcjson.MarshalCmd(btcjson.RpcVersion1, nil, ntfn)
+	if err != nil {
+		rpcsLog.Errorf("Failed to marshal double spend notification: "+
+			"%v", err)
+		return
+	}
+	for _, wsc := range clients {
+		wsc.QueueNotification(marshalledJSON)
+	}
+}
+
 // RegisterSpentRequests requests a notification when each of the passed
 // outpoints is confirmed spent (contained in a block connected to the main
 // chain) for the passed websocket client.  The request is automatically
@@ -2121,6 +2321,34 @@ func handleStopNotifyNewTransactions(wsc *wsClient, icmd interface{}) (interface
 	return nil, nil
 }
 
+// handleNotifyDoubleSpends implements the notifydoublespends command
+// extension for websocket connections.
+func handleNotifyDoubleSpends(wsc *wsClient, icmd interface{}) (interface{}, error) {
+	wsc.server.ntfnMgr.RegisterDoubleSpendUpdates(wsc)
+	return nil, nil
+}
+
+// handleStopNotifyDoubleSpends implements the stopnotifydoublespends command
+// extension for websocket connections.
+func handleStopNotifyDoubleSpends(wsc *wsClient, icmd interface{}) (interface{}, error) {
+	wsc.server.ntfnMgr.UnregisterDoubleSpendUpdates(wsc)
+	return nil, nil
+}
+
+// handleNotifyMempoolEvents implements the notifymempoolevents command
+// extension for websocket connections.
+func handleNotifyMempoolEvents(wsc *wsClient, icmd interface{}) (interface{}, error) {
+	wsc.server.ntfnMgr.RegisterMempoolEventUpdates(wsc)
+	return nil, nil
+}
+
+// handleStopNotifyMempoolEvents implements the stopnotifymempoolevents
+// command extension for websocket connections.
+func handleStopNotifyMempoolEvents(wsc *wsClient, icmd interface{}) (interface{}, error) {
+	wsc.server.ntfnMgr.UnregisterMempoolEventUpdates(wsc)
+	return nil, nil
+}
+
 // handleNotifyReceived implements the notifyreceived command extension for
 // websocket connections.
 func handleNotifyReceived(wsc *wsClient, icmd interface{}) (interface{}, error) {
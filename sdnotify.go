@@ -0,0 +1,107 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// sdNotify sends the passed state, such as READY=1, to the service manager
+// over the socket named by the NOTIFY_SOCKET environment variable.  It does
+// nothing when the variable isn't set, which is the case unless btcd is run by
+// systemd as a service of type notify.
+//
+// See sd_notify(3) for the states understood by systemd.
+func sdNotify(state string) error {
+	name := os.Getenv("NOTIFY_SOCKET")
+	if name == "" {
+		return nil
+	}
+
+	// A leading @ denotes a socket in the abstract namespace.
+	if name[0] == '@' {
+		name = "\x00" + name[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{
+		Name: name,
+		Net:  "unixgram",
+	})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(state))
+	return err
+}
+
+// sdWatchdogInterval returns the interval the service manager expects to be
+// notified at to consider btcd alive, as described by the WATCHDOG_USEC and
+// WATCHDOG_PID environment variables.  Zero is returned when the watchdog is
+// disabled or meant for another process.
+func sdWatchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" {
+		if pid != strconv.Itoa(os.Getpid()) {
+			return 0
+		}
+	}
+
+	return time.Duration(usec) * time.Microsecond
+}
+
+// watchdogHandler notifies the service manager that btcd is alive twice per
+// passed watchdog interval as long as the main loop of the sync manager, which
+// handles the blocks and transactions of all of the peers, keeps responding.
+// The notifications stop once it hangs, so the service manager restarts btcd.
+//
+// It must be run as a goroutine.
+func (s *server) watchdogHandler(interval time.Duration) {
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+
+	// alive receives a value once the sync manager answers a query.  Only
+	// a single query is outstanding at any time, so a hung sync manager
+	// doesn't accumulate goroutines.
+	alive := make(chan struct{}, 1)
+	pending := false
+
+out:
+	for {
+		select {
+		case <-ticker.C:
+			if pending {
+				srvrLog.Warnf("The sync manager hasn't responded " +
+					"within the watchdog interval")
+				continue
+			}
+			pending = true
+			go func() {
+				s.syncManager.IsCurrent()
+				alive <- struct{}{}
+			}()
+
+		case <-alive:
+			pending = false
+			if err := sdNotify("WATCHDOG=1"); err != nil {
+				srvrLog.Warnf("Unable to notify the service "+
+					"manager: %v", err)
+			}
+
+		case <-s.quit:
+			break out
+		}
+	}
+
+	s.wg.Done()
+	srvrLog.Tracef("Watchdog handler done")
+}
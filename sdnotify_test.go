@@ -0,0 +1,96 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestSdNotify ensures states are sent to the socket named by the
+// NOTIFY_SOCKET environment variable, and that nothing is sent without it.
+func TestSdNotify(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix datagram sockets are not supported on windows")
+	}
+
+	t.Setenv("NOTIFY_SOCKET", "")
+	if err := sdNotify("READY=1"); err != nil {
+		t.Fatalf("sdNotify: unexpected error without socket: %v", err)
+	}
+
+	// Use a short path since the length of socket paths is limited.
+	dir, err := os.MkdirTemp("", "sdnotify")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "notify")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{
+		Name: path,
+		Net:  "unixgram",
+	})
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	defer conn.Close()
+
+	t.Setenv("NOTIFY_SOCKET", path)
+	if err := sdNotify("READY=1"); err != nil {
+		t.Fatalf("sdNotify: unexpected error: %v", err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 64)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("unable to read notification: %v", err)
+	}
+	if got := string(buf[:n]); got != "READY=1" {
+		t.Fatalf("sdNotify: got state %q, want %q", got, "READY=1")
+	}
+
+	// Sending to a socket nobody listens on fails.
+	t.Setenv("NOTIFY_SOCKET", filepath.Join(dir, "missing"))
+	if err := sdNotify("READY=1"); err == nil {
+		t.Fatal("sdNotify: expected error for missing socket")
+	}
+}
+
+// TestSdWatchdogInterval ensures the watchdog interval is parsed from the
+// environment and only applies to this process.
+func TestSdWatchdogInterval(t *testing.T) {
+	pid := strconv.Itoa(os.Getpid())
+	tests := []struct {
+		name string
+		usec string
+		pid  string
+		want time.Duration
+	}{
+		{name: "disabled", want: 0},
+		{name: "invalid", usec: "abc", want: 0},
+		{name: "zero", usec: "0", want: 0},
+		{name: "negative", usec: "-1", want: 0},
+		{name: "no pid", usec: "30000000", want: 30 * time.Second},
+		{name: "this process", usec: "1500", pid: pid,
+			want: 1500 * time.Microsecond},
+		{name: "other process", usec: "30000000", pid: pid + "0",
+			want: 0},
+	}
+
+	for _, test := range tests {
+		t.Setenv("WATCHDOG_USEC", test.usec)
+		t.Setenv("WATCHDOG_PID", test.pid)
+		if got := sdWatchdogInterval(); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got,
+				test.want)
+		}
+	}
+}
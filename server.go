@@ -12,8 +12,11 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"hash/maphash"
 	"math"
 	"net"
+	"os"
+	"path/filepath"
 	"runtime"
 	"sort"
 	"strconv"
@@ -31,9 +34,12 @@ import (
 	"github.com/btcsuite/btcd/chainhash/v2"
 	"github.com/btcsuite/btcd/connmgr"
 	"github.com/btcsuite/btcd/database"
+	"github.com/btcsuite/btcd/database/ffldb"
+	"github.com/btcsuite/btcd/dnsseeder"
 	"github.com/btcsuite/btcd/mempool"
 	"github.com/btcsuite/btcd/mining"
 	"github.com/btcsuite/btcd/mining/cpuminer"
+	"github.com/btcsuite/btcd/mining/stratum"
 	"github.com/btcsuite/btcd/netsync"
 	"github.com/btcsuite/btcd/peer"
 	"github.com/btcsuite/btcd/txscript/v2"
@@ -51,13 +57,36 @@ const (
 	// required to be supported by outbound peers.
 	defaultRequiredServices = wire.SFNodeNetwork
 
-	// defaultTargetOutbound is the default number of outbound peers to target.
-	defaultTargetOutbound = 8
+	// feelerInterval is the interval at which feeler connections are made
+	// to test whether addresses which have never been connected to are
+	// reachable.
+	feelerInterval = time.Minute * 2
+
+	// natLeaseDuration is the lifetime requested for the port mapping of
+	// the listening port.  The mapping is renewed after half of it passed
+	// or after natRetryInterval when a renewal fails.
+	natLeaseDuration = time.Minute * 20
+	natRetryInterval = time.Minute
+
+	// dnsSeederFeelerInterval is the interval at which feeler connections
+	// are made when running as a DNS seeder so the network is crawled
+	// quickly enough to keep the served addresses fresh.
+	dnsSeederFeelerInterval = time.Second * 10
 
 	// connectionRetryInterval is the base amount of time to wait in between
 	// retries when connecting to persistent peers.  It is adjusted by the
 	// number of retries such that there is a retry backoff.
 	connectionRetryInterval = time.Second * 5
+
+	// feeEstimatorSaveInterval is the amount of time in between saves of
+	// the fee estimator state to the database so that it survives an
+	// unclean shutdown.
+	feeEstimatorSaveInterval = time.Minute * 10
+
+	// maxTrickleScale is the maximum factor the trickle interval is scaled
+	// up by when more peers than the tricklescalepeers option are
+	// connected.
+	maxTrickleScale = 4
 )
 
 var (
@@ -176,7 +205,7 @@ type peerState struct {
 	inboundPeers    map[int32]*serverPeer
 	outboundPeers   map[int32]*serverPeer
 	persistentPeers map[int32]*serverPeer
-	banned          map[string]time.Time
+	banned          *banList
 	outboundGroups  map[string]int
 }
 
@@ -213,6 +242,15 @@ type cfHeaderKV struct {
 	filterHeader chainhash.Hash
 }
 
+// cfilterIndex is implemented by the indexes which serve committed filters to
+// peers.
+type cfilterIndex interface {
+	FiltersByBlockHashes([]*chainhash.Hash, wire.FilterType) ([][]byte, error)
+	FilterHashesByBlockHashes([]*chainhash.Hash, wire.FilterType) ([][]byte, error)
+	FilterHeaderByBlockHash(*chainhash.Hash, wire.FilterType) ([]byte, error)
+	FilterHeadersByBlockHashes([]*chainhash.Hash, wire.FilterType) ([][]byte, error)
+}
+
 // server provides a bitcoin server for handling communications to and from
 // bitcoin peers.
 type server struct {
@@ -224,6 +262,8 @@ type server struct {
 	shutdown      int32
 	shutdownSched int32
 	startupTime   int64
+	numPeers      int32 // Number of peers added by the peerHandler.
+	importing     int32 // Set while block files are being imported.
 
 	chainParams          *chaincfg.Params
 	addrManager          *addrmgr.AddrManager
@@ -235,6 +275,8 @@ type server struct {
 	chain                *blockchain.BlockChain
 	txMemPool            *mempool.TxPool
 	cpuMiner             *cpuminer.CPUMiner
+	stratumServer        *stratum.Server
+	dnsSeeder            *dnsseeder.Server
 	modifyRebroadcastInv chan interface{}
 	p2pDowngrader        *peer.P2PDowngrader
 	peerLifecycle        chan peerLifecycleEvent
@@ -246,17 +288,49 @@ type server struct {
 	wg                   sync.WaitGroup
 	quit                 chan struct{}
 	nat                  NAT
+	onionTarget          string
+	mdnsPort             uint16
+	anchorsFile          string
+	evictionSeed         maphash.Seed
 	db                   database.DB
 	timeSource           blockchain.MedianTimeSource
 	services             wire.ServiceFlag
 
+	// addedNodes houses the manually added nodes.  It is only accessed by
+	// the peerHandler goroutine once the server is started.
+	addedNodes *addedNodeList
+
+	// clockSkew estimates how far the local clock is off from the time of
+	// the network.
+	clockSkew *clockSkewMonitor
+
+	// blockServeLimiter limits the rate at which historical blocks are
+	// served to peers without the noban permission.  It is nil when the
+	// rate is not limited.
+	blockServeLimiter *blockServeLimiter
+
+	// bandwidth tracks the bytes sent and received per message command
+	// and enforces the upload target.
+	bandwidth *bandwidthStats
+
 	// The following fields are used for optional indexes.  They will be nil
 	// if the associated index is not enabled.  These fields are set during
 	// initial creation of the server and never changed afterwards, so they
 	// do not need to be protected for concurrent access.
-	txIndex   *indexers.TxIndex
-	addrIndex *indexers.AddrIndex
-	cfIndex   *indexers.CfIndex
+	txIndex    *indexers.TxIndex
+	addrIndex  *indexers.AddrIndex
+	cfIndex    *indexers.CfIndex
+	spentIndex *indexers.SpentIndex
+	claimIndex *indexers.ClaimIndex
+
+	// claimFilterIndex serves the committed filters of the claim names.  It
+	// is nil unless --claimfilters is set.
+	claimFilterIndex *indexers.ClaimFilterIndex
+
+	// indexManager manages the optional indexes and catches up the ones
+	// which were behind the best chain tip at startup in the background.
+	// It is nil when none of the indexes are enabled.
+	indexManager *indexers.Manager
 
 	// The fee estimator keeps track of how long transactions are left in
 	// the mempool before they are mined into blocks.
@@ -267,6 +341,24 @@ type server struct {
 	cfCheckptCaches    map[wire.FilterType][]cfHeaderKV
 	cfCheckptCachesMtx sync.RWMutex
 
+	// natMapping describes the state of the port mapping of the listening
+	// port which is maintained by natUpdateThread.
+	natMapping natMapping
+	natMtx     sync.Mutex
+
+	// chainVerifyWarning describes the problem found by verifyChainHandler.
+	// It is empty when no problem was found.
+	chainVerifyWarning string
+	chainVerifyMtx     sync.Mutex
+
+	// diskSpaceWarning describes the lack of free disk space found by
+	// diskSpaceHandler.  It is empty when enough space is available.
+	diskSpaceWarning string
+	diskSpaceMtx     sync.Mutex
+
+	// blockRepairer repairs the blocks whose stored copies are damaged.
+	blockRepairer *blockRepairer
+
 	// agentBlacklist is a list of blacklisted substrings by which to filter
 	// user agents.
 	agentBlacklist []string
@@ -287,16 +379,18 @@ type serverPeer struct {
 	connReq        *connmgr.ConnReq
 	server         *server
 	persistent     bool
+	blockRelayOnly bool
+	feeler         bool
 	continueHash   *chainhash.Hash
 	relayMtx       sync.Mutex
 	disableRelayTx bool
 	sentAddrs      bool
-	isWhitelisted  bool
+	permissions    peerPermissions
 	filter         *bloom.Filter
 	addressesMtx   sync.RWMutex
 	knownAddresses lru.Cache
-	banScore       connmgr.DynamicBanScore
-	quit chan struct{}
+	misbehavior    misbehaviorTracker
+	quit           chan struct{}
 
 	// Closed by verAckOnce when OnVerAck fires.
 	verAckCh   chan struct{}
@@ -311,6 +405,23 @@ type serverPeer struct {
 	// select picked the disconnect case.
 	peerAdded atomic.Bool
 
+	// txRelayLimiter limits the rate at which transactions announced by
+	// the peer are requested from it.  It is nil when the peer isn't
+	// limited.
+	txRelayLimiter *txRelayLimiter
+
+	// The following counters track the transactions announced by and
+	// received from the peer.
+	txInvRecv      atomic.Uint64
+	txInvThrottled atomic.Uint64
+	txRecv         atomic.Uint64
+
+	// The following track the unix times the peer last sent a transaction
+	// which was accepted to the memory pool and a block which was not
+	// known before.  They are used to protect useful peers from eviction.
+	lastTxTime    atomic.Int64
+	lastBlockTime atomic.Int64
+
 	// The following chans are used to sync blockmanager and server.
 	txProcessed    chan struct{}
 	blockProcessed chan struct{}
@@ -375,6 +486,32 @@ func (sp *serverPeer) relayTxDisabled() bool {
 	return isDisabled
 }
 
+// txRelayAllowed returns whether transactions are accepted from the peer.
+// They are not accepted from block-relay-only peers or, unless the peer has
+// the relay permission, when running in blocks only mode.
+func (sp *serverPeer) txRelayAllowed() bool {
+	if sp.blockRelayOnly {
+		return false
+	}
+	return !cfg.BlocksOnly || sp.permissions.has(permRelay)
+}
+
+// connectionType returns a description of the kind of connection to the peer.
+func (sp *serverPeer) connectionType() string {
+	switch {
+	case sp.Inbound():
+		return "inbound"
+	case sp.persistent:
+		return "manual"
+	case sp.feeler:
+		return "feeler"
+	case sp.blockRelayOnly:
+		return "block-relay-only"
+	default:
+		return "outbound-full-relay"
+	}
+}
+
 // pushAddrMsg sends a legacy addr message to the connected peer using the
 // provided addresses.
 func (sp *serverPeer) pushAddrMsg(addresses []*wire.NetAddressV2) {
@@ -444,37 +581,50 @@ func (sp *serverPeer) pushAddrMsg(addresses []*wire.NetAddressV2) {
 	sp.addKnownAddresses(knownAddrs)
 }
 
-// addBanScore increases the persistent and decaying ban score fields by the
-// values passed as parameters. If the resulting score exceeds half of the ban
-// threshold, a warning is logged including the reason provided. Further, if
-// the score is above the ban threshold, the peer will be banned and
-// disconnected.
-func (sp *serverPeer) addBanScore(persistent, transient uint32, reason string) bool {
+// misbehaving records misbehavior of the passed category and increases the
+// persistent and decaying ban score fields by the values passed as parameters
+// unless the category is configured to be ignored. If the resulting score
+// exceeds half of the ban threshold, a warning is logged including the reason
+// provided. Further, if the score is above the ban threshold, the peer will be
+// disconnected and, unless the category is configured to only disconnect,
+// banned.
+func (sp *serverPeer) misbehaving(category misbehaviorCategory, persistent,
+	transient uint32, reason string) bool {
+
 	// No warning is logged and no score is calculated if banning is disabled.
 	if cfg.DisableBanning {
 		return false
 	}
-	if sp.isWhitelisted {
-		peerLog.Debugf("Misbehaving whitelisted peer %s: %s", sp, reason)
+	reaction := cfg.misbehaviorReactions[category]
+	if sp.permissions.has(permNoBan) || reaction == reactIgnore {
+		sp.misbehavior.add(category, 0, 0, reason, time.Now())
+		peerLog.Debugf("Misbehaving peer %s (%v ignored): %s", sp,
+			category, reason)
 		return false
 	}
 
 	warnThreshold := cfg.BanThreshold >> 1
+	score := sp.misbehavior.add(category, persistent, transient, reason,
+		time.Now())
 	if transient == 0 && persistent == 0 {
 		// The score is not being increased, but a warning message is still
 		// logged if the score is above the warn threshold.
-		score := sp.banScore.Int()
 		if score > warnThreshold {
 			peerLog.Warnf("Misbehaving peer %s: %s -- ban score is %d, "+
 				"it was not increased this time", sp, reason, score)
 		}
 		return false
 	}
-	score := sp.banScore.Increase(persistent, transient)
 	if score > warnThreshold {
 		peerLog.Warnf("Misbehaving peer %s: %s -- ban score increased to %d",
 			sp, reason, score)
 		if score > cfg.BanThreshold {
+			if reaction == reactDisconnect {
+				peerLog.Warnf("Misbehaving peer %s -- disconnecting",
+					sp)
+				sp.Disconnect()
+				return true
+			}
 			peerLog.Warnf("Misbehaving peer %s -- banning and disconnecting",
 				sp)
 			sp.server.BanPeer(sp)
@@ -561,6 +711,11 @@ func (sp *serverPeer) OnVersion(_ *peer.Peer, msg *wire.MsgVersion) *wire.MsgRej
 	// the local clock to keep the network time in sync.
 	sp.server.timeSource.AddTimeSample(sp.Addr(), msg.Timestamp)
 
+	// Also add it to the samples the clock skew is monitored with.
+	sp.server.clockSkew.addSample(sp.ID(), !sp.Inbound(), msg.Timestamp,
+		time.Now())
+	sp.server.checkClockSkew()
+
 	// Choose whether or not to relay transactions before a filter command
 	// is received.
 	sp.setDisableRelayTx(msg.DisableRelayTx)
@@ -577,25 +732,43 @@ func (sp *serverPeer) OnVerAck(_ *peer.Peer, _ *wire.MsgVerAck) {
 	sp.verAckOnce.Do(func() { close(sp.verAckCh) })
 }
 
+// OnPong is invoked when a peer receives a pong bitcoin message.  It updates
+// the latency the clock skew sample of the peer is corrected by with the round
+// trip time of the ping.
+func (sp *serverPeer) OnPong(_ *peer.Peer, _ *wire.MsgPong) {
+	rtt := time.Duration(sp.LastPingMicros()) * time.Microsecond
+	sp.server.clockSkew.updateLatency(sp.ID(), rtt)
+	sp.server.checkClockSkew()
+}
+
 // OnMemPool is invoked when a peer receives a mempool bitcoin message.
 // It creates and sends an inventory message with the contents of the memory
 // pool up to the maximum inventory allowed per message.  When the peer has a
 // bloom filter loaded, the contents are filtered accordingly.
 func (sp *serverPeer) OnMemPool(_ *peer.Peer, msg *wire.MsgMemPool) {
 	// Only allow mempool requests if the server has bloom filtering
-	// enabled.
-	if sp.server.services&wire.SFNodeBloom != wire.SFNodeBloom {
+	// enabled or the peer has the mempool permission.
+	if sp.server.services&wire.SFNodeBloom != wire.SFNodeBloom &&
+		!sp.permissions.has(permMempool) {
+
 		peerLog.Debugf("peer %v sent mempool request with bloom "+
 			"filtering disabled -- disconnecting", sp)
 		sp.Disconnect()
 		return
 	}
 
+	// Block-relay-only peers don't take part in transaction relay.
+	if sp.blockRelayOnly {
+		peerLog.Debugf("Ignoring mempool request from block-relay-only "+
+			"peer %v", sp)
+		return
+	}
+
 	// A decaying ban score increase is applied to prevent flooding.
 	// The ban score accumulates and passes the ban threshold if a burst of
 	// mempool messages comes from a peer. The score decays each minute to
 	// half of its value.
-	if sp.addBanScore(0, 33, "mempool") {
+	if sp.misbehaving(misbehaviorFlood, 0, 33, "mempool") {
 		return
 	}
 
@@ -632,9 +805,9 @@ func (sp *serverPeer) OnMemPool(_ *peer.Peer, msg *wire.MsgMemPool) {
 // handler this does not serialize all transactions through a single thread
 // transactions don't rely on the previous one in a linear fashion like blocks.
 func (sp *serverPeer) OnTx(_ *peer.Peer, msg *wire.MsgTx) {
-	if cfg.BlocksOnly {
-		peerLog.Tracef("Ignoring tx %v from %v - blocksonly enabled",
-			msg.TxHash(), sp)
+	if !sp.txRelayAllowed() {
+		peerLog.Tracef("Ignoring tx %v from %v - transaction relay "+
+			"disabled", msg.TxHash(), sp)
 		return
 	}
 
@@ -644,6 +817,14 @@ func (sp *serverPeer) OnTx(_ *peer.Peer, msg *wire.MsgTx) {
 	tx := btcutil.NewTx(msg)
 	iv := wire.NewInvVect(wire.InvTypeTx, tx.Hash())
 	sp.AddKnownInventory(iv)
+	sp.txRecv.Add(1)
+
+	// Transactions of peers with the forcerelay permission which are
+	// already in the memory pool are relayed again below since the sync
+	// manager only relays new ones.
+	txMemPool := sp.server.txMemPool
+	inPool := txMemPool.IsTransactionInPool(tx.Hash())
+	forceRelay := sp.permissions.has(permForceRelay) && inPool
 
 	// Queue the transaction up to be handled by the sync manager and
 	// intentionally block further receives until the transaction is fully
@@ -652,6 +833,19 @@ func (sp *serverPeer) OnTx(_ *peer.Peer, msg *wire.MsgTx) {
 	// being disconnected) and wasting memory.
 	sp.server.syncManager.QueueTx(tx, sp.Peer, sp.txProcessed)
 	<-sp.txProcessed
+
+	if !inPool && txMemPool.IsTransactionInPool(tx.Hash()) {
+		sp.lastTxTime.Store(time.Now().Unix())
+	}
+
+	if forceRelay {
+		txD, err := txMemPool.FetchTxDesc(tx.Hash())
+		if err != nil {
+			return
+		}
+		peerLog.Debugf("Force relaying tx %v from %v", tx.Hash(), sp)
+		sp.server.relayTransactions([]*mempool.TxDesc{txD})
+	}
 }
 
 // OnBlock is invoked when a peer receives a block bitcoin message.  It
@@ -665,6 +859,9 @@ func (sp *serverPeer) OnBlock(_ *peer.Peer, msg *wire.MsgBlock, buf []byte) {
 	iv := wire.NewInvVect(wire.InvTypeBlock, block.Hash())
 	sp.AddKnownInventory(iv)
 
+	chain := sp.server.chain
+	haveBlock, _ := chain.HaveBlock(block.Hash())
+
 	// Queue the block up to be handled by the block
 	// manager and intentionally block further receives
 	// until the bitcoin block is fully processed and known
@@ -678,6 +875,12 @@ func (sp *serverPeer) OnBlock(_ *peer.Peer, msg *wire.MsgBlock, buf []byte) {
 	// the bitcoin block has been fully processed.
 	sp.server.syncManager.QueueBlock(block, sp.Peer, sp.blockProcessed)
 	<-sp.blockProcessed
+
+	if !haveBlock {
+		if have, _ := chain.HaveBlock(block.Hash()); have {
+			sp.lastBlockTime.Store(time.Now().Unix())
+		}
+	}
 }
 
 // OnInv is invoked when a peer receives an inv bitcoin message and is
@@ -685,7 +888,8 @@ func (sp *serverPeer) OnBlock(_ *peer.Peer, msg *wire.MsgBlock, buf []byte) {
 // accordingly.  We pass the message down to blockmanager which will call
 // QueueMessage with any appropriate responses.
 func (sp *serverPeer) OnInv(_ *peer.Peer, msg *wire.MsgInv) {
-	if !cfg.BlocksOnly {
+	if sp.txRelayAllowed() {
+		msg = sp.throttleTxInv(msg)
 		if len(msg.InvList) > 0 {
 			sp.server.syncManager.QueueInv(msg, sp.Peer)
 		}
@@ -696,7 +900,7 @@ func (sp *serverPeer) OnInv(_ *peer.Peer, msg *wire.MsgInv) {
 	for _, invVect := range msg.InvList {
 		if invVect.Type == wire.InvTypeTx {
 			peerLog.Tracef("Ignoring tx %v in inv from %v -- "+
-				"blocksonly enabled", invVect.Hash, sp)
+				"transaction relay disabled", invVect.Hash, sp)
 			if sp.ProtocolVersion() >= wire.BIP0037Version {
 				peerLog.Infof("Peer %v is announcing "+
 					"transactions -- disconnecting", sp)
@@ -717,6 +921,48 @@ func (sp *serverPeer) OnInv(_ *peer.Peer, msg *wire.MsgInv) {
 	}
 }
 
+// throttleTxInv counts the transactions announced by the passed inventory
+// message and removes those which exceed the transaction relay rate limit of
+// the peer.  The message is returned unmodified when nothing was removed.
+func (sp *serverPeer) throttleTxInv(msg *wire.MsgInv) *wire.MsgInv {
+	var newInv *wire.MsgInv
+	var numTxs, numThrottled uint64
+	now := time.Now()
+	for i, invVect := range msg.InvList {
+		if invVect.Type == wire.InvTypeTx ||
+			invVect.Type == wire.InvTypeWitnessTx {
+
+			numTxs++
+			if sp.txRelayLimiter != nil &&
+				!sp.txRelayLimiter.Allow(now) {
+
+				// Copy the inventory allowed so far the first
+				// time a transaction is throttled.
+				if newInv == nil {
+					newInv = wire.NewMsgInvSizeHint(
+						uint(len(msg.InvList)))
+					newInv.InvList = append(newInv.InvList,
+						msg.InvList[:i]...)
+				}
+				numThrottled++
+				continue
+			}
+		}
+		if newInv != nil {
+			newInv.InvList = append(newInv.InvList, invVect)
+		}
+	}
+	sp.txInvRecv.Add(numTxs)
+	if newInv == nil {
+		return msg
+	}
+
+	sp.txInvThrottled.Add(numThrottled)
+	peerLog.Debugf("Ignoring %d of %d transactions announced by %v -- "+
+		"relay rate limit exceeded", numThrottled, numTxs, sp)
+	return newInv
+}
+
 // OnHeaders is invoked when a peer receives a headers bitcoin
 // message.  The message is passed down to the sync manager.
 func (sp *serverPeer) OnHeaders(_ *peer.Peer, msg *wire.MsgHeaders) {
@@ -741,7 +987,8 @@ func (sp *serverPeer) OnGetData(_ *peer.Peer, msg *wire.MsgGetData) {
 	// potentially ban peers performing IBD.
 	//
 	// This incremental score decays each minute to half of its value.
-	if sp.addBanScore(0, uint32(length)*99/wire.MaxInvPerMsg, "getdata") {
+	if sp.misbehaving(misbehaviorFlood, 0,
+		uint32(length)*99/wire.MaxInvPerMsg, "getdata") {
 		return
 	}
 
@@ -757,6 +1004,23 @@ func (sp *serverPeer) OnGetData(_ *peer.Peer, msg *wire.MsgGetData) {
 	doneChans := make([]chan struct{}, 0, numBuffered)
 
 	for i, iv := range msg.InvList {
+		// Historical blocks are not served to peers without the noban
+		// permission once the upload target is reached.  The peer is
+		// disconnected so it downloads them from another peer instead.
+		if !sp.mayRequestBlock(iv) {
+			peerLog.Infof("Upload target reached, disconnecting peer "+
+				"%v requesting historical block %v", sp, iv.Hash)
+			sp.Disconnect()
+			return
+		}
+
+		// Historical blocks are served to peers without the noban
+		// permission at the limited rate.
+		if !sp.waitBlockServeLimit(iv) {
+			peerLog.Debug("Peer disconnected in OnGetData")
+			return
+		}
+
 		// doneChan behaves like a semaphore - every time a msg is
 		// processed, either succeeded or failed, a signal is sent to
 		// this doneChan.
@@ -822,6 +1086,61 @@ func (sp *serverPeer) OnGetData(_ *peer.Peer, msg *wire.MsgGetData) {
 	}
 }
 
+// mayRequestBlock returns whether the peer may be sent the block requested by
+// the passed inventory vector according to the upload target.  Requests of
+// other inventory are always allowed.
+func (sp *serverPeer) mayRequestBlock(iv *wire.InvVect) bool {
+	switch iv.Type {
+	case wire.InvTypeBlock, wire.InvTypeWitnessBlock,
+		wire.InvTypeFilteredBlock, wire.InvTypeFilteredWitnessBlock:
+	default:
+		return true
+	}
+	if sp.permissions.has(permNoBan) {
+		return true
+	}
+
+	header, err := sp.server.chain.HeaderByHash(&iv.Hash)
+	if err != nil {
+		return true
+	}
+	return sp.server.bandwidth.ServeHistoricalBlock(header.Timestamp,
+		time.Now())
+}
+
+// waitBlockServeLimit waits until the block requested by the passed inventory
+// vector may be served according to the block serving rate limit when it is a
+// historical block and the peer does not have the noban permission.  It
+// returns false when the peer disconnects while waiting.
+func (sp *serverPeer) waitBlockServeLimit(iv *wire.InvVect) bool {
+	limiter := sp.server.blockServeLimiter
+	if limiter == nil || sp.permissions.has(permNoBan) {
+		return true
+	}
+	switch iv.Type {
+	case wire.InvTypeBlock, wire.InvTypeWitnessBlock,
+		wire.InvTypeFilteredBlock, wire.InvTypeFilteredWitnessBlock:
+	default:
+		return true
+	}
+
+	header, err := sp.server.chain.HeaderByHash(&iv.Hash)
+	if err != nil || time.Since(header.Timestamp) <= historicalBlockAge {
+		return true
+	}
+	for {
+		delay := limiter.Delay(time.Now())
+		if delay == 0 {
+			return true
+		}
+		select {
+		case <-time.After(delay):
+		case <-sp.quit:
+			return false
+		}
+	}
+}
+
 // pushInventory sends the requested inventory to the given peer.
 func (s *server) pushInventory(sp *serverPeer, iv *wire.InvVect,
 	doneChan chan<- struct{}) error {
@@ -930,6 +1249,19 @@ func (sp *serverPeer) OnGetHeaders(_ *peer.Peer, msg *wire.MsgGetHeaders) {
 	sp.QueueMessage(&wire.MsgHeaders{Headers: blockHeaders}, nil)
 }
 
+// filterIndex returns the index which serves the committed filters of the
+// passed type or nil when the filters of the type aren't served.
+func (s *server) filterIndex(filterType wire.FilterType) cfilterIndex {
+	switch {
+	case filterType == wire.GCSFilterRegular && s.cfIndex != nil:
+		return s.cfIndex
+	case filterType == indexers.GCSFilterClaimName &&
+		s.claimFilterIndex != nil:
+		return s.claimFilterIndex
+	}
+	return nil
+}
+
 // OnGetCFilters is invoked when a peer receives a getcfilters bitcoin message.
 func (sp *serverPeer) OnGetCFilters(_ *peer.Peer, msg *wire.MsgGetCFilters) {
 	// Ignore getcfilters requests if not in sync.
@@ -939,11 +1271,8 @@ func (sp *serverPeer) OnGetCFilters(_ *peer.Peer, msg *wire.MsgGetCFilters) {
 
 	// We'll also ensure that the remote party is requesting a set of
 	// filters that we actually currently maintain.
-	switch msg.FilterType {
-	case wire.GCSFilterRegular:
-		break
-
-	default:
+	filterIndex := sp.server.filterIndex(msg.FilterType)
+	if filterIndex == nil {
 		peerLog.Debug("Filter request for unknown filter: %v",
 			msg.FilterType)
 		return
@@ -964,7 +1293,7 @@ func (sp *serverPeer) OnGetCFilters(_ *peer.Peer, msg *wire.MsgGetCFilters) {
 		hashPtrs[i] = &hashes[i]
 	}
 
-	filters, err := sp.server.cfIndex.FiltersByBlockHashes(
+	filters, err := filterIndex.FiltersByBlockHashes(
 		hashPtrs, msg.FilterType,
 	)
 	if err != nil {
@@ -973,6 +1302,14 @@ func (sp *serverPeer) OnGetCFilters(_ *peer.Peer, msg *wire.MsgGetCFilters) {
 	}
 
 	for i, filterBytes := range filters {
+		// The filters of pruned blocks are deleted along with them, so
+		// requests for filters which are older than the stored blocks
+		// are ignored.
+		if len(filterBytes) == 0 && cfg.Prune != 0 {
+			peerLog.Debugf("Not serving cfilter for pruned block %v",
+				hashes[i])
+			return
+		}
 		if len(filterBytes) == 0 {
 			peerLog.Warnf("Could not obtain cfilter for %v",
 				hashes[i])
@@ -995,11 +1332,8 @@ func (sp *serverPeer) OnGetCFHeaders(_ *peer.Peer, msg *wire.MsgGetCFHeaders) {
 
 	// We'll also ensure that the remote party is requesting a set of
 	// headers for filters that we actually currently maintain.
-	switch msg.FilterType {
-	case wire.GCSFilterRegular:
-		break
-
-	default:
+	filterIndex := sp.server.filterIndex(msg.FilterType)
+	if filterIndex == nil {
 		peerLog.Debug("Filter request for unknown headers for "+
 			"filter: %v", msg.FilterType)
 		return
@@ -1039,7 +1373,7 @@ func (sp *serverPeer) OnGetCFHeaders(_ *peer.Peer, msg *wire.MsgGetCFHeaders) {
 	}
 
 	// Fetch the raw filter hash bytes from the database for all blocks.
-	filterHashes, err := sp.server.cfIndex.FilterHashesByBlockHashes(
+	filterHashes, err := filterIndex.FilterHashesByBlockHashes(
 		hashPtrs, msg.FilterType,
 	)
 	if err != nil {
@@ -1056,7 +1390,7 @@ func (sp *serverPeer) OnGetCFHeaders(_ *peer.Peer, msg *wire.MsgGetCFHeaders) {
 
 		// Fetch the raw committed filter header bytes from the
 		// database.
-		headerBytes, err := sp.server.cfIndex.FilterHeaderByBlockHash(
+		headerBytes, err := filterIndex.FilterHeaderByBlockHash(
 			prevBlockHash, msg.FilterType)
 		if err != nil {
 			peerLog.Errorf("Error retrieving CF header: %v", err)
@@ -1112,11 +1446,8 @@ func (sp *serverPeer) OnGetCFCheckpt(_ *peer.Peer, msg *wire.MsgGetCFCheckpt) {
 
 	// We'll also ensure that the remote party is requesting a set of
 	// checkpoints for filters that we actually currently maintain.
-	switch msg.FilterType {
-	case wire.GCSFilterRegular:
-		break
-
-	default:
+	filterIndex := sp.server.filterIndex(msg.FilterType)
+	if filterIndex == nil {
 		peerLog.Debug("Filter request for unknown checkpoints for "+
 			"filter: %v", msg.FilterType)
 		return
@@ -1209,7 +1540,7 @@ func (sp *serverPeer) OnGetCFCheckpt(_ *peer.Peer, msg *wire.MsgGetCFCheckpt) {
 	for i := forkIdx; i < len(blockHashes); i++ {
 		blockHashPtrs = append(blockHashPtrs, &blockHashes[i])
 	}
-	filterHeaders, err := sp.server.cfIndex.FilterHeadersByBlockHashes(
+	filterHeaders, err := filterIndex.FilterHeadersByBlockHashes(
 		blockHashPtrs, msg.FilterType,
 	)
 	if err != nil {
@@ -1255,16 +1586,19 @@ func (sp *serverPeer) OnGetCFCheckpt(_ *peer.Peer, msg *wire.MsgGetCFCheckpt) {
 }
 
 // enforceNodeBloomFlag disconnects the peer if the server is not configured to
-// allow bloom filters.  Additionally, if the peer has negotiated to a protocol
-// version  that is high enough to observe the bloom filter service support bit,
-// it will be banned since it is intentionally violating the protocol.
+// allow bloom filters and the peer does not have the bloomfilter permission.
+// Additionally, if the peer has negotiated to a protocol version  that is high
+// enough to observe the bloom filter service support bit, it will be banned
+// since it is intentionally violating the protocol.
 func (sp *serverPeer) enforceNodeBloomFlag(cmd string) bool {
-	if sp.server.services&wire.SFNodeBloom != wire.SFNodeBloom {
+	if sp.server.services&wire.SFNodeBloom != wire.SFNodeBloom &&
+		!sp.permissions.has(permBloomFilter) {
+
 		// Ban the peer if the protocol version is high enough that the
 		// peer is knowingly violating the protocol and banning is
 		// enabled.
 		//
-		// NOTE: Even though the addBanScore function already examines
+		// NOTE: Even though the misbehaving function already examines
 		// whether or not banning is enabled, it is checked here as well
 		// to ensure the violation is logged and the peer is
 		// disconnected regardless.
@@ -1273,7 +1607,7 @@ func (sp *serverPeer) enforceNodeBloomFlag(cmd string) bool {
 
 			// Disconnect the peer regardless of whether it was
 			// banned.
-			sp.addBanScore(100, 0, cmd)
+			sp.misbehaving(misbehaviorProtocol, 100, 0, cmd)
 			sp.Disconnect()
 			return false
 		}
@@ -1411,6 +1745,11 @@ func (sp *serverPeer) OnAddr(_ *peer.Peer, msg *wire.MsgAddr) {
 		return
 	}
 
+	// Block-relay-only peers don't take part in address relay.
+	if sp.blockRelayOnly {
+		return
+	}
+
 	// Ignore old style addresses which don't include a timestamp.
 	if sp.ProtocolVersion() < wire.NetAddressTimeVersion {
 		return
@@ -1465,6 +1804,11 @@ func (sp *serverPeer) OnAddrV2(_ *peer.Peer, msg *wire.MsgAddrV2) {
 		return
 	}
 
+	// Block-relay-only peers don't take part in address relay.
+	if sp.blockRelayOnly {
+		return
+	}
+
 	// An empty AddrV2 message is invalid.
 	if len(msg.AddrList) == 0 {
 		peerLog.Errorf("Command [%s] from %s does not contain any "+
@@ -1500,12 +1844,39 @@ func (sp *serverPeer) OnAddrV2(_ *peer.Peer, msg *wire.MsgAddrV2) {
 // the bytes received by the server.
 func (sp *serverPeer) OnRead(_ *peer.Peer, bytesRead int, msg wire.Message, err error) {
 	sp.server.AddBytesReceived(uint64(bytesRead))
+	sp.server.bandwidth.AddReceived(msg, uint64(bytesRead))
 }
 
 // OnWrite is invoked when a peer sends a message and it is used to update
 // the bytes sent by the server.
 func (sp *serverPeer) OnWrite(_ *peer.Peer, bytesWritten int, msg wire.Message, err error) {
 	sp.server.AddBytesSent(uint64(bytesWritten))
+	sp.server.bandwidth.AddSent(msg, uint64(bytesWritten), time.Now())
+
+	// Blocks sent to peers without the noban permission count towards the
+	// block serving rate limit.
+	limiter := sp.server.blockServeLimiter
+	if _, ok := msg.(*wire.MsgBlock); ok && limiter != nil &&
+		!sp.permissions.has(permNoBan) {
+
+		limiter.Consume(uint64(bytesWritten), time.Now())
+	}
+}
+
+// OnMessageError is invoked when a peer receives a message which is unknown,
+// oversized or malformed.  Unknown messages only increase the transient ban
+// score since implementations may send a few messages which are newer than
+// this node, while oversized and malformed messages can't be sent by a correct
+// implementation.
+func (sp *serverPeer) OnMessageError(_ *peer.Peer, kind peer.MessageErrorKind,
+	err error) {
+
+	if kind == peer.MsgErrUnknown {
+		sp.misbehaving(misbehaviorMalformed, 0, 1, "unknown message")
+		return
+	}
+	sp.misbehaving(misbehaviorMalformed, 100, 0,
+		fmt.Sprintf("%v message: %v", kind, err))
 }
 
 // OnNotFound is invoked when a peer sends a notfound message.
@@ -1535,14 +1906,16 @@ func (sp *serverPeer) OnNotFound(p *peer.Peer, msg *wire.MsgNotFound) {
 	if numBlocks > 0 {
 		blockStr := pickNoun(uint64(numBlocks), "block", "blocks")
 		reason := fmt.Sprintf("%d %v not found", numBlocks, blockStr)
-		if sp.addBanScore(20*numBlocks, 0, reason) {
+		if sp.misbehaving(misbehaviorNotFound, 20*numBlocks, 0,
+			reason) {
 			return
 		}
 	}
 	if numTxns > 0 {
 		txStr := pickNoun(uint64(numTxns), "transaction", "transactions")
 		reason := fmt.Sprintf("%d %v not found", numTxns, txStr)
-		if sp.addBanScore(0, 10*numTxns, reason) {
+		if sp.misbehaving(misbehaviorNotFound, 0, 10*numTxns,
+			reason) {
 			return
 		}
 	}
@@ -1823,22 +2196,34 @@ func (s *server) handleAddPeerMsg(state *peerState, sp *serverPeer) bool {
 		sp.Disconnect()
 		return false
 	}
-	if banEnd, ok := state.banned[host]; ok {
-		if time.Now().Before(banEnd) {
-			srvrLog.Debugf("Peer %s is banned for another %v - disconnecting",
-				host, time.Until(banEnd))
-			sp.Disconnect()
-			return false
-		}
+	if ban := state.banned.isBanned(host, time.Now()); ban != nil &&
+		!sp.permissions.has(permNoBan) {
+
+		srvrLog.Debugf("Peer %s is banned for another %v - disconnecting",
+			host, time.Until(ban.until))
+		sp.Disconnect()
+		return false
+	}
 
-		srvrLog.Infof("Peer %s is no longer banned", host)
-		delete(state.banned, host)
+	// Feeler connections only test that the address is reachable, so mark
+	// it as a known good address and disconnect now that the handshake is
+	// complete.
+	if sp.feeler {
+		srvrLog.Debugf("Feeler connection to %s succeeded", sp)
+		s.addrManager.Connected(sp.NA())
+		s.addrManager.Good(sp.NA())
+		sp.Disconnect()
+		return false
 	}
 
 	// TODO: Check for max peers from a single IP.
 
-	// Limit max number of total peers.
-	if state.Count() >= cfg.MaxPeers {
+	// Limit max number of total peers.  Room is made for new inbound peers
+	// by evicting an existing inbound peer when possible so well-behaved
+	// peers can always connect.
+	if state.Count() >= cfg.MaxPeers &&
+		!(sp.Inbound() && s.evictInboundPeer(state)) {
+
 		srvrLog.Infof("Max peers reached [%d] - disconnecting peer %s",
 			cfg.MaxPeers, sp)
 		sp.Disconnect()
@@ -1852,13 +2237,14 @@ func (s *server) handleAddPeerMsg(state *peerState, sp *serverPeer) bool {
 	if sp.Inbound() {
 		state.inboundPeers[sp.ID()] = sp
 	} else {
-		state.outboundGroups[addrmgr.GroupKey(sp.NA())]++
+		state.outboundGroups[s.addrManager.GroupKey(sp.NA())]++
 		if sp.persistent {
 			state.persistentPeers[sp.ID()] = sp
 		} else {
 			state.outboundPeers[sp.ID()] = sp
 		}
 	}
+	atomic.StoreInt32(&s.numPeers, int32(state.Count()))
 
 	// Update the address' last seen time if the peer has acknowledged
 	// our version and has sent us its version as well.
@@ -1877,8 +2263,11 @@ func (s *server) handleAddPeerMsg(state *peerState, sp *serverPeer) bool {
 	if !cfg.SimNet && !sp.Inbound() {
 		// Advertise the local address when the server accepts incoming
 		// connections and it believes itself to be close to the best
-		// known tip.
-		if !cfg.DisableListen && s.syncManager.IsCurrent() {
+		// known tip.  Block-relay-only peers don't take part in address
+		// relay, so they are neither sent addresses nor asked for them.
+		if !cfg.DisableListen && !sp.blockRelayOnly &&
+			s.syncManager.IsCurrent() {
+
 			// Get address that best matches.
 			lna := s.addrManager.GetBestLocalAddress(sp.NA())
 			if addrmgr.IsRoutable(lna) {
@@ -1892,7 +2281,9 @@ func (s *server) handleAddPeerMsg(state *peerState, sp *serverPeer) bool {
 		// more and the peer has a protocol version new enough to
 		// include a timestamp with addresses.
 		hasTimestamp := sp.ProtocolVersion() >= wire.NetAddressTimeVersion
-		if s.addrManager.NeedMoreAddresses() && hasTimestamp {
+		if s.addrManager.NeedMoreAddresses() && hasTimestamp &&
+			!sp.blockRelayOnly {
+
 			sp.QueueMessage(wire.NewMsgGetAddr(), nil)
 		}
 
@@ -1906,6 +2297,8 @@ func (s *server) handleAddPeerMsg(state *peerState, sp *serverPeer) bool {
 // handleDonePeerMsg deals with peers that have signalled they are done.  It is
 // invoked from the peerHandler goroutine.
 func (s *server) handleDonePeerMsg(state *peerState, sp *serverPeer) {
+	s.clockSkew.removeSample(sp.ID())
+
 	var list map[int32]*serverPeer
 	if sp.persistent {
 		list = state.persistentPeers
@@ -1920,6 +2313,11 @@ func (s *server) handleDonePeerMsg(state *peerState, sp *serverPeer) {
 	// process a peer's `done` message before its `add`.
 	if !sp.Inbound() {
 		switch {
+		// Nodes which were removed from the added nodes are not
+		// reconnected to.
+		case sp.persistent && s.addedNodes.findConnReq(sp.connReq) == nil:
+			s.connManager.Remove(sp.connReq.ID())
+
 		case sp.persistent:
 			s.connManager.Disconnect(sp.connReq.ID())
 
@@ -1932,16 +2330,16 @@ func (s *server) handleDonePeerMsg(state *peerState, sp *serverPeer) {
 			)
 
 		default:
-			s.connManager.Remove(sp.connReq.ID())
-			go s.connManager.NewConnReq()
+			s.replaceConnReq(sp.connReq)
 		}
 	}
 
 	if _, ok := list[sp.ID()]; ok {
 		if !sp.Inbound() && sp.VersionKnown() {
-			state.outboundGroups[addrmgr.GroupKey(sp.NA())]--
+			state.outboundGroups[s.addrManager.GroupKey(sp.NA())]--
 		}
 		delete(list, sp.ID())
+		atomic.StoreInt32(&s.numPeers, int32(state.Count()))
 		srvrLog.Debugf("Removed peer %s", sp)
 	}
 
@@ -1974,10 +2372,33 @@ func (s *server) handleBanPeerMsg(state *peerState, sp *serverPeer) {
 		srvrLog.Debugf("can't split ban peer %s %v", sp.Addr(), err)
 		return
 	}
+	entry, err := parseBanTarget(host)
+	if err != nil {
+		srvrLog.Debugf("can't ban peer %s %v", sp.Addr(), err)
+		return
+	}
 	direction := directionString(sp.Inbound())
 	srvrLog.Infof("Banned peer %s (%s) for %v", host, direction,
 		cfg.BanDuration)
-	state.banned[host] = time.Now().Add(cfg.BanDuration)
+	state.banned.add(entry, time.Now().Add(cfg.BanDuration))
+	s.saveBanList(state)
+}
+
+// saveBanList removes expired bans and writes the remaining ones to the
+// banlist file.  It is invoked from the peerHandler goroutine.
+func (s *server) saveBanList(state *peerState) {
+	state.banned.expire(time.Now())
+	if err := state.banned.save(); err != nil {
+		srvrLog.Warnf("Unable to save banlist: %v", err)
+	}
+}
+
+// saveAddedNodes saves the added nodes to the added nodes file, logging any
+// error.  It is invoked from the peerHandler goroutine.
+func (s *server) saveAddedNodes() {
+	if err := s.addedNodes.save(); err != nil {
+		srvrLog.Warnf("Unable to save added nodes: %v", err)
+	}
 }
 
 // handleRelayInvMsg deals with relaying inventory to peers that are not already
@@ -2010,8 +2431,9 @@ func (s *server) handleRelayInvMsg(state *peerState, msg relayMsg) {
 
 		if msg.invVect.Type == wire.InvTypeTx {
 			// Don't relay the transaction to the peer when it has
-			// transaction relaying disabled.
-			if sp.relayTxDisabled() {
+			// transaction relaying disabled or is a block-relay-only
+			// peer.
+			if sp.blockRelayOnly || sp.relayTxDisabled() {
 				return
 			}
 
@@ -2078,7 +2500,7 @@ type getOutboundGroup struct {
 }
 
 type getAddedNodesMsg struct {
-	reply chan []*serverPeer
+	reply chan []addedNodeInfo
 }
 
 type disconnectNodeMsg struct {
@@ -2092,11 +2514,36 @@ type connectNodeMsg struct {
 	reply     chan error
 }
 
+// lanPeerMsg requests a connection to a node discovered on the local network.
+type lanPeerMsg struct {
+	addr *net.TCPAddr
+}
+
 type removeNodeMsg struct {
+	addr  string
 	cmp   func(*serverPeer) bool
 	reply chan error
 }
 
+type banMsg struct {
+	addr  string
+	until time.Time
+	reply chan error
+}
+
+type unbanMsg struct {
+	addr  string
+	reply chan error
+}
+
+type getBannedMsg struct {
+	reply chan []banEntry
+}
+
+type clearBannedMsg struct {
+	reply chan struct{}
+}
+
 // handleQuery is the central handler for all queries and commands from other
 // goroutines related to peer state.
 func (s *server) handleQuery(state *peerState, querymsg interface{}) {
@@ -2122,20 +2569,19 @@ func (s *server) handleQuery(state *peerState, querymsg interface{}) {
 
 	case connectNodeMsg:
 		// TODO: duplicate oneshots?
-		// Limit max number of total peers.
-		if state.Count() >= cfg.MaxPeers {
-			msg.reply <- errors.New("max peers reached")
+		// Nodes are added even when the maximum number of peers is
+		// reached, so only single connection attempts are limited.
+		if s.addedNodes.find(msg.addr) != nil {
+			if msg.permanent {
+				msg.reply <- errNodeAlreadyAdded
+			} else {
+				msg.reply <- errors.New("peer exists as a permanent peer")
+			}
 			return
 		}
-		for _, peer := range state.persistentPeers {
-			if peer.Addr() == msg.addr {
-				if msg.permanent {
-					msg.reply <- errors.New("peer already connected")
-				} else {
-					msg.reply <- errors.New("peer exists as a permanent peer")
-				}
-				return
-			}
+		if !msg.permanent && state.Count() >= cfg.MaxPeers {
+			msg.reply <- errors.New("max peers reached")
+			return
 		}
 
 		netAddr, err := addrStringToNetAddr(msg.addr)
@@ -2145,23 +2591,117 @@ func (s *server) handleQuery(state *peerState, querymsg interface{}) {
 		}
 
 		// TODO: if too many, nuke a non-perm peer.
-		go s.connManager.Connect(&connmgr.ConnReq{
+		connReq := &connmgr.ConnReq{
 			Addr:      netAddr,
 			Permanent: msg.permanent,
-		})
+		}
+		if msg.permanent {
+			s.addedNodes.add(&addedNode{addr: msg.addr, connReq: connReq})
+			s.saveAddedNodes()
+		}
+		go s.connManager.Connect(connReq)
 		msg.reply <- nil
+
+	case lanPeerMsg:
+		if state.Count() >= cfg.MaxPeers {
+			return
+		}
+		if state.banned.isBanned(msg.addr.IP.String(), time.Now()) != nil {
+			return
+		}
+
+		// Only connect to nodes which are not connected yet.
+		connected := false
+		state.forAllPeers(func(sp *serverPeer) {
+			host, _, err := net.SplitHostPort(sp.Addr())
+			if err == nil && net.ParseIP(host).Equal(msg.addr.IP) {
+				connected = true
+			}
+		})
+		if connected {
+			return
+		}
+
+		srvrLog.Debugf("Connecting to %v discovered on the local network",
+			msg.addr)
+		go s.connManager.Connect(&connmgr.ConnReq{Addr: msg.addr})
 	case removeNodeMsg:
-		found := disconnectPeer(state.persistentPeers, msg.cmp, func(sp *serverPeer) {
+		// Find the added node either by its address or by the peer
+		// connected to it.
+		node := s.addedNodes.find(msg.addr)
+		for _, sp := range state.persistentPeers {
+			if node == nil && msg.cmp(sp) {
+				node = s.addedNodes.findConnReq(sp.connReq)
+			}
+		}
+		if node == nil {
+			msg.reply <- errNodeNotAdded
+			return
+		}
+		s.addedNodes.remove(node.addr)
+		s.saveAddedNodes()
+		srvrLog.Infof("Removed added node %s", node.addr)
+
+		// Disconnect the peer connected to the node, which is not
+		// reconnected to since the node is no longer added, or cancel
+		// the pending connection attempts otherwise.
+		found := disconnectPeer(state.persistentPeers, func(sp *serverPeer) bool {
+			return node.connReq != nil && sp.connReq == node.connReq
+		}, func(sp *serverPeer) {
 			// Keep group counts ok since we remove from
 			// the list now.
-			state.outboundGroups[addrmgr.GroupKey(sp.NA())]--
+			state.outboundGroups[s.addrManager.GroupKey(sp.NA())]--
 		})
+		if !found && node.connReq != nil {
+			s.connManager.Remove(node.connReq.ID())
+		}
+		msg.reply <- nil
+	case banMsg:
+		entry, err := parseBanTarget(msg.addr)
+		if err != nil {
+			msg.reply <- err
+			return
+		}
+		state.banned.add(entry, msg.until)
+		s.saveBanList(state)
+		srvrLog.Infof("Banned %s until %v", entry.host, msg.until)
 
-		if found {
-			msg.reply <- nil
-		} else {
-			msg.reply <- errors.New("peer not found")
+		// Disconnect the peers covered by the new ban.
+		state.forAllPeers(func(sp *serverPeer) {
+			host, _, err := net.SplitHostPort(sp.Addr())
+			if err == nil && entry.matches(host) {
+				sp.Disconnect()
+			}
+		})
+		msg.reply <- nil
+	case unbanMsg:
+		entry, err := parseBanTarget(msg.addr)
+		if err != nil {
+			msg.reply <- err
+			return
+		}
+		if err := state.banned.remove(entry); err != nil {
+			msg.reply <- err
+			return
+		}
+		s.saveBanList(state)
+		srvrLog.Infof("Unbanned %s", entry.host)
+		msg.reply <- nil
+	case getBannedMsg:
+		if state.banned.expire(time.Now()) {
+			s.saveBanList(state)
 		}
+		entries := state.banned.list()
+		banned := make([]banEntry, 0, len(entries))
+		for _, entry := range entries {
+			banned = append(banned, *entry)
+		}
+		msg.reply <- banned
+	case clearBannedMsg:
+		state.banned.clear()
+		s.saveBanList(state)
+		srvrLog.Infof("Cleared all bans")
+		msg.reply <- struct{}{}
 	case getOutboundGroup:
 		count, ok := state.outboundGroups[msg.key]
 		if ok {
@@ -2169,14 +2709,21 @@ func (s *server) handleQuery(state *peerState, querymsg interface{}) {
 		} else {
 			msg.reply <- 0
 		}
-	// Request a list of the persistent (added) peers.
+	// Request a list of the added nodes along with the peers connected to
+	// them.
 	case getAddedNodesMsg:
-		// Respond with a slice of the relevant peers.
-		peers := make([]*serverPeer, 0, len(state.persistentPeers))
-		for _, sp := range state.persistentPeers {
-			peers = append(peers, sp)
+		nodes := s.addedNodes.list()
+		infos := make([]addedNodeInfo, 0, len(nodes))
+		for _, node := range nodes {
+			info := addedNodeInfo{addr: node.addr}
+			for _, sp := range state.persistentPeers {
+				if node.connReq != nil && sp.connReq == node.connReq {
+					info.peer = (*rpcPeer)(sp)
+				}
+			}
+			infos = append(infos, info)
 		}
-		msg.reply <- peers
+		msg.reply <- infos
 	case disconnectNodeMsg:
 		// Check inbound peers. We pass a nil callback since we don't
 		// require any additional actions on disconnect for inbound peers.
@@ -2190,7 +2737,7 @@ func (s *server) handleQuery(state *peerState, querymsg interface{}) {
 		found = disconnectPeer(state.outboundPeers, msg.cmp, func(sp *serverPeer) {
 			// Keep group counts ok since we remove from
 			// the list now.
-			state.outboundGroups[addrmgr.GroupKey(sp.NA())]--
+			state.outboundGroups[s.addrManager.GroupKey(sp.NA())]--
 		})
 		if found {
 			// If there are multiple outbound connections to the same
@@ -2198,7 +2745,7 @@ func (s *server) handleQuery(state *peerState, querymsg interface{}) {
 			// peers are found.
 			for found {
 				found = disconnectPeer(state.outboundPeers, msg.cmp, func(sp *serverPeer) {
-					state.outboundGroups[addrmgr.GroupKey(sp.NA())]--
+					state.outboundGroups[s.addrManager.GroupKey(sp.NA())]--
 				})
 			}
 			msg.reply <- nil
@@ -2233,12 +2780,24 @@ func disconnectPeer(peerList map[int32]*serverPeer, compareFunc func(*serverPeer
 	return false
 }
 
+// peerServices returns the services advertised to the peer.  Peers with the
+// bloomfilter permission are told bloom filters are supported even when they
+// are disabled.
+func (sp *serverPeer) peerServices() wire.ServiceFlag {
+	services := sp.server.services
+	if sp.permissions.has(permBloomFilter) {
+		services |= wire.SFNodeBloom
+	}
+	return services
+}
+
 // newPeerConfig returns the configuration for the given serverPeer.
 func newPeerConfig(sp *serverPeer) *peer.Config {
 	return &peer.Config{
 		Listeners: peer.MessageListeners{
 			OnVersion:      sp.OnVersion,
 			OnVerAck:       sp.OnVerAck,
+			OnPong:         sp.OnPong,
 			OnMemPool:      sp.OnMemPool,
 			OnTx:           sp.OnTx,
 			OnBlock:        sp.OnBlock,
@@ -2259,6 +2818,7 @@ func newPeerConfig(sp *serverPeer) *peer.Config {
 			OnAddrV2:       sp.OnAddrV2,
 			OnRead:         sp.OnRead,
 			OnWrite:        sp.OnWrite,
+			OnMessageError: sp.OnMessageError,
 			OnNotFound:     sp.OnNotFound,
 		},
 		NewestBlock:         sp.newestBlock,
@@ -2268,10 +2828,13 @@ func newPeerConfig(sp *serverPeer) *peer.Config {
 		UserAgentVersion:    userAgentVersion,
 		UserAgentComments:   cfg.UserAgentComments,
 		ChainParams:         sp.server.chainParams,
-		Services:            sp.server.services,
-		DisableRelayTx:      cfg.BlocksOnly,
+		Services:            sp.peerServices(),
+		DisableRelayTx:      !sp.txRelayAllowed(),
 		ProtocolVersion:     peer.MaxProtocolVersion,
 		TrickleInterval:     cfg.TrickleInterval,
+		TrickleIntervalFunc: sp.server.trickleInterval,
+		TxAnnounceDelay:     cfg.TxAnnounceDelay,
+		MaxInvTrickleSize:   cfg.MaxInvBatch,
 		DisableStallHandler: cfg.DisableStallHandler,
 		UsingV2Conn:         cfg.V2Transport,
 	}
@@ -2283,7 +2846,9 @@ func newPeerConfig(sp *serverPeer) *peer.Config {
 // for disconnection.
 func (s *server) inboundPeerConnected(conn net.Conn) {
 	sp := newServerPeer(s, false)
-	sp.isWhitelisted = isWhitelisted(conn.RemoteAddr())
+	sp.permissions = whitelistPermissions(conn.RemoteAddr()) |
+		whitebindPermissions(conn.LocalAddr())
+	sp.txRelayLimiter = newPeerTxRelayLimiter(sp.permissions)
 	sp.Peer = peer.NewInboundPeer(newPeerConfig(sp))
 	sp.AssociateConnection(conn)
 	go s.peerLifecycleHandler(sp)
@@ -2298,6 +2863,10 @@ func (s *server) outboundPeerConnected(c *connmgr.ConnReq, conn net.Conn) {
 	// Just an alias.
 	peerAddr := c.Addr.String()
 	sp := newServerPeer(s, c.Permanent)
+	sp.blockRelayOnly = c.BlockRelayOnly
+	sp.feeler = c.Feeler
+	sp.permissions = whitelistPermissions(conn.RemoteAddr())
+	sp.txRelayLimiter = newPeerTxRelayLimiter(sp.permissions)
 
 	peerCfg := newPeerConfig(sp)
 
@@ -2318,19 +2887,33 @@ func (s *server) outboundPeerConnected(c *connmgr.ConnReq, conn net.Conn) {
 		if c.Permanent {
 			s.connManager.Disconnect(c.ID())
 		} else {
-			s.connManager.Remove(c.ID())
-			go s.connManager.NewConnReq()
+			s.replaceConnReq(c)
 		}
 		return
 	}
 
 	sp.Peer = p
 	sp.connReq = c
-	sp.isWhitelisted = isWhitelisted(conn.RemoteAddr())
 	sp.AssociateConnection(conn)
 	go s.peerLifecycleHandler(sp)
 }
 
+// replaceConnReq removes the passed non-persistent outbound connection request
+// from the connection manager and requests a new connection of the same kind
+// in its place.  Feeler connections are not replaced.
+func (s *server) replaceConnReq(c *connmgr.ConnReq) {
+	s.connManager.Remove(c.ID())
+	switch {
+	case c.Feeler:
+	case c.BlockRelayOnly:
+		go s.connManager.NewBlockRelayOnlyConnReq()
+	case c.Onion:
+		go s.connManager.NewOnionConnReq()
+	default:
+		go s.connManager.NewConnReq()
+	}
+}
+
 // peerLifecycleHandler is the sole sender of lifecycle events for a
 // given peer. It waits for either verack (handshake complete) or
 // disconnect (handshake failed/timed out), sends peerAdd if verack
@@ -2389,21 +2972,63 @@ func (s *server) peerHandler() {
 		inboundPeers:    make(map[int32]*serverPeer),
 		persistentPeers: make(map[int32]*serverPeer),
 		outboundPeers:   make(map[int32]*serverPeer),
-		banned:          make(map[string]time.Time),
 		outboundGroups:  make(map[string]int),
 	}
 
+	// Load the bans which survived the last shutdown.
+	var err error
+	state.banned, err = newBanList(filepath.Join(cfg.DataDir,
+		banlistFilename))
+	if err != nil {
+		srvrLog.Warnf("Unable to load banlist: %v", err)
+	}
+
 	if !cfg.DisableDNSSeed {
-		// Add peers discovered through DNS to the address manager.
-		connmgr.SeedFromDNS(activeNetParams.Params, defaultRequiredServices,
-			btcdLookup, func(addrs []*wire.NetAddressV2) {
+		extraSeeds := make([]chaincfg.DNSSeed, 0, len(cfg.DNSSeeds))
+		for _, host := range cfg.DNSSeeds {
+			extraSeeds = append(extraSeeds, chaincfg.DNSSeed{Host: host})
+		}
+
+		// Add peers discovered through DNS, or the seed list when the
+		// DNS seeds don't return any, to the address manager.
+		connmgr.Seed(&connmgr.SeedConfig{
+			ChainParams:    activeNetParams.Params,
+			ExtraSeeds:     extraSeeds,
+			ReqServices:    defaultRequiredServices,
+			Lookup:         btcdLookup,
+			SeedListURL:    cfg.SeedListURL,
+			SeedListPubKey: cfg.seedListPubKey,
+			HTTPClient:     seedListClient(),
+			OnSeed: func(addrs []*wire.NetAddressV2) {
 				// Bitcoind uses a lookup of the dns seeder here. This
 				// is rather strange since the values looked up by the
 				// DNS seed lookups will vary quite a lot.
 				// to replicate this behaviour we put all addresses as
 				// having come from the first one.
 				s.addrManager.AddAddresses(addrs, addrs[0])
+			},
+		})
+	}
+
+	// Reconnect to the anchors saved on the last shutdown before the
+	// connection manager makes any other outbound connections.
+	if s.anchorsFile != "" {
+		anchors, err := loadAnchors(s.anchorsFile)
+		if err != nil {
+			srvrLog.Warnf("Unable to load anchors: %v", err)
+		}
+		for _, anchor := range anchors {
+			netAddr, err := addrStringToNetAddr(anchor)
+			if err != nil {
+				srvrLog.Debugf("Skipping anchor %s: %v", anchor, err)
+				continue
+			}
+			srvrLog.Debugf("Reconnecting to anchor %s", anchor)
+			go s.connManager.Connect(&connmgr.ConnReq{
+				Addr:           netAddr,
+				BlockRelayOnly: true,
 			})
+		}
 	}
 	go s.connManager.Start()
 
@@ -2441,6 +3066,19 @@ out:
 			s.handleQuery(state, qmsg)
 
 		case <-s.quit:
+			// Save the longest connected block-relay-only peers as
+			// anchors to reconnect to on the next startup.
+			if s.anchorsFile != "" {
+				anchors := selectAnchors(state.outboundPeers)
+				if len(anchors) > 0 {
+					err := saveAnchors(s.anchorsFile, anchors)
+					if err != nil {
+						srvrLog.Warnf("Unable to save "+
+							"anchors: %v", err)
+					}
+				}
+			}
+
 			// Disconnect all peers on server shutdown.
 			state.forAllPeers(func(sp *serverPeer) {
 				srvrLog.Tracef("Shutdown peer %s", sp)
@@ -2492,6 +3130,27 @@ func (s *server) BroadcastMessage(msg wire.Message, exclPeers ...*serverPeer) {
 	s.broadcast <- bmsg
 }
 
+// trickleInterval returns the interval inventory is trickled to the peers at.
+// It is the configured trickle interval, which is scaled up in proportion to
+// the number of peers once more than the configured number of peers are
+// connected, up to maxTrickleScale times the configured interval.
+//
+// This function is safe for concurrent access.
+func (s *server) trickleInterval() time.Duration {
+	if cfg.TrickleScalePeers <= 0 {
+		return cfg.TrickleInterval
+	}
+	numPeers := atomic.LoadInt32(&s.numPeers)
+	if int(numPeers) <= cfg.TrickleScalePeers {
+		return cfg.TrickleInterval
+	}
+	scale := float64(numPeers) / float64(cfg.TrickleScalePeers)
+	if scale > maxTrickleScale {
+		scale = maxTrickleScale
+	}
+	return time.Duration(float64(cfg.TrickleInterval) * scale)
+}
+
 // ConnectedCount returns the number of currently connected peers.
 func (s *server) ConnectedCount() int32 {
 	replyChan := make(chan int32)
@@ -2596,8 +3255,100 @@ cleanup:
 	s.wg.Done()
 }
 
-// Start begins accepting connections from peers.
-func (s *server) Start() {
+// saveFeeEstimator stores the current fee estimator state in the database so
+// that it can be restored on the next startup.
+func (s *server) saveFeeEstimator() {
+	err := s.db.Update(func(tx database.Tx) error {
+		metadata := tx.Metadata()
+		return metadata.Put(mempool.EstimateFeeDatabaseKey,
+			s.feeEstimator.Save())
+	})
+	if err != nil {
+		srvrLog.Errorf("Unable to save fee estimator state: %v", err)
+	}
+}
+
+// feeEstimatorHandler periodically saves the fee estimator state so that
+// fee estimates remain available after an unclean shutdown.  It must be run
+// as a goroutine.
+func (s *server) feeEstimatorHandler() {
+	ticker := time.NewTicker(feeEstimatorSaveInterval)
+	defer ticker.Stop()
+
+out:
+	for {
+		select {
+		case <-ticker.C:
+			s.saveFeeEstimator()
+
+		case <-s.quit:
+			break out
+		}
+	}
+
+	s.wg.Done()
+}
+
+// indexBuildHandler catches up the optional indexes which were behind the best
+// chain tip at startup.  It must be run as a goroutine.
+func (s *server) indexBuildHandler() {
+	defer s.wg.Done()
+
+	err := s.indexManager.BuildIndexes(s.quit)
+	if err != nil {
+		select {
+		case <-s.quit:
+		default:
+			indxLog.Errorf("Unable to build indexes: %v", err)
+		}
+	}
+}
+
+// backfillFeeEstimator registers the most recent blocks of the main chain
+// which the fee estimator has not seen yet, so that it is able to provide
+// estimates shortly after startup instead of having to observe new blocks
+// first.  A fresh fee estimator is used when the blocks can't be loaded.
+func (s *server) backfillFeeEstimator() {
+	best := s.chain.BestSnapshot()
+	lastKnownHeight := s.feeEstimator.LastKnownHeight()
+	startHeight := lastKnownHeight + 1
+	if lastKnownHeight == mining.UnminedHeight {
+		startHeight = best.Height -
+			mempool.DefaultEstimateFeeBackfillDepth + 1
+		if startHeight < 1 {
+			startHeight = 1
+		}
+	}
+
+	for height := startHeight; height <= best.Height; height++ {
+		block, err := s.chain.BlockByHeight(height)
+		if err != nil {
+			srvrLog.Warnf("Unable to back-fill fee estimator: %v", err)
+			break
+		}
+		stxos, err := s.chain.FetchSpendJournal(block)
+		if err != nil {
+			srvrLog.Warnf("Unable to back-fill fee estimator: %v", err)
+			break
+		}
+		err = s.feeEstimator.BackfillBlock(block, stxos)
+		if err != nil {
+			srvrLog.Warnf("Unable to back-fill fee estimator: %v", err)
+			break
+		}
+	}
+
+	// Start over with a fresh fee estimator if it didn't catch up with the
+	// main chain.
+	if s.feeEstimator.LastKnownHeight() != best.Height {
+		s.feeEstimator = mempool.NewFeeEstimator(
+			mempool.DefaultEstimateFeeMaxRollback,
+			mempool.DefaultEstimateFeeMinRegisteredBlocks)
+	}
+}
+
+// Start begins accepting connections from peers.
+func (s *server) Start() {
 	// Already started?
 	if atomic.AddInt32(&s.started, 1) != 1 {
 		return
@@ -2615,7 +3366,67 @@ func (s *server) Start() {
 
 	if s.nat != nil {
 		s.wg.Add(1)
-		go s.upnpUpdateThread()
+		go s.natUpdateThread()
+	}
+
+	if s.onionTarget != "" {
+		s.wg.Add(1)
+		go s.torControlThread()
+	}
+
+	if cfg.MDNS {
+		s.wg.Add(1)
+		go s.mdnsThread()
+	}
+
+	s.wg.Add(1)
+	go s.feeEstimatorHandler()
+
+	// Catch up the optional indexes which are behind the best chain tip.
+	if s.indexManager != nil {
+		s.wg.Add(1)
+		go s.indexBuildHandler()
+	}
+
+	// Notify the service manager that btcd is alive when it watches it.
+	if interval := sdWatchdogInterval(); interval > 0 {
+		s.wg.Add(1)
+		go s.watchdogHandler(interval)
+	}
+
+	// Monitor the free disk space unless disabled.
+	if cfg.MinDiskSpaceMiB > 0 {
+		s.wg.Add(1)
+		go s.diskSpaceHandler()
+	}
+
+	// Verify the last blocks of the main chain in the background.
+	if cfg.CheckBlocks != 0 {
+		s.wg.Add(1)
+		go s.verifyChainHandler()
+	}
+
+	// Finish rebuilding the block database from the block files of the old
+	// one when a reindex is in progress, and then import the blocks of the
+	// block files given on the command line.
+	reindexPath := reindexDbPath()
+	if fileExists(reindexPath) || len(cfg.LoadBlocks) > 0 {
+		s.startBlockImport(func() {
+			if fileExists(reindexPath) {
+				err := s.reindexBlockFiles(reindexBlocksPath())
+				if errors.Is(err, errBlockImportInterrupted) {
+					return
+				}
+				if err == nil {
+					err = os.RemoveAll(reindexPath)
+				}
+				if err != nil {
+					srvrLog.Errorf("Unable to reindex the block "+
+						"database: %v", err)
+				}
+			}
+			s.loadBlockFiles(cfg.LoadBlocks)
+		})
 	}
 
 	if !cfg.DisableRPC {
@@ -2633,6 +3444,16 @@ func (s *server) Start() {
 	if cfg.Generate {
 		s.cpuMiner.Start()
 	}
+
+	// Start the stratum server if it is enabled.
+	if s.stratumServer != nil {
+		s.stratumServer.Start()
+	}
+
+	// Start the DNS seeder if it is enabled.
+	if s.dnsSeeder != nil {
+		s.dnsSeeder.Start()
+	}
 }
 
 // Stop gracefully shuts down the server by stopping and disconnecting all
@@ -2649,18 +3470,23 @@ func (s *server) Stop() error {
 	// Stop the CPU miner if needed
 	s.cpuMiner.Stop()
 
+	// Stop the stratum server if needed.
+	if s.stratumServer != nil {
+		s.stratumServer.Stop()
+	}
+
+	// Stop the DNS seeder if needed.
+	if s.dnsSeeder != nil {
+		s.dnsSeeder.Stop()
+	}
+
 	// Shutdown the RPC server if it's not disabled.
 	if !cfg.DisableRPC {
 		s.rpcServer.Stop()
 	}
 
 	// Save fee estimator state in the database.
-	s.db.Update(func(tx database.Tx) error {
-		metadata := tx.Metadata()
-		metadata.Put(mempool.EstimateFeeDatabaseKey, s.feeEstimator.Save())
-
-		return nil
-	})
+	s.saveFeeEstimator()
 
 	// Signal the remaining goroutines to quit.
 	close(s.quit)
@@ -2756,44 +3582,151 @@ func parseListeners(addrs []string) ([]net.Addr, error) {
 	return netAddrs, nil
 }
 
-func (s *server) upnpUpdateThread() {
-	// Go off immediately to prevent code duplication, thereafter we renew
-	// lease every 15 minutes.
-	timer := time.NewTimer(0 * time.Second)
-	lport, _ := strconv.ParseInt(activeNetParams.DefaultPort, 10, 16)
-	first := true
+// natMapping describes the state of the port mapping of the listening port
+// on the NAT gateway.
+type natMapping struct {
+	protocol     string
+	externalIP   net.IP
+	externalPort uint16
+	internalPort uint16
+	expires      time.Time
+	lastErr      error
+}
+
+// checkClockSkew evaluates how far the local clock is off from the time of the
+// network and logs when that changes.  A shutdown is requested when it is off
+// by more than the maximum clock skew.
+//
+// This function is safe for concurrent access.
+func (s *server) checkClockSkew() {
+	offset, state, prevState := s.clockSkew.evaluate()
+	if state == prevState {
+		return
+	}
+
+	switch state {
+	case clockSkewOK:
+		if prevState != clockSkewUnknown {
+			srvrLog.Infof("The local clock agrees with the time of "+
+				"the network again (%s)", describeClockSkew(offset))
+		}
+
+	case clockSkewWarning:
+		srvrLog.Warnf("Please check your date and time are correct -- "+
+			"%s", describeClockSkew(offset))
+
+	case clockSkewExceeded:
+		srvrLog.Criticalf("Shutting down since %s which exceeds the "+
+			"maximum clock skew of %v -- please correct your date "+
+			"and time", describeClockSkew(offset), cfg.MaxClockSkew)
+		go func() {
+			shutdownRequestChannel <- struct{}{}
+		}()
+	}
+}
+
+// NATMapping returns the state of the port mapping of the listening port and
+// whether NAT traversal is in use.
+//
+// This function is safe for concurrent access.
+func (s *server) NATMapping() (natMapping, bool) {
+	if s.nat == nil {
+		return natMapping{}, false
+	}
+
+	s.natMtx.Lock()
+	defer s.natMtx.Unlock()
+	return s.natMapping, true
+}
+
+// renewNATMapping adds or renews the port mapping of the passed internal port
+// and returns the external address of the mapping.
+func (s *server) renewNATMapping(internalPort uint16) (*wire.NetAddressV2, error) {
+	// Ask for the previously mapped external port so the external address
+	// stays the same.
+	s.natMtx.Lock()
+	externalPort := s.natMapping.externalPort
+	s.natMtx.Unlock()
+	if externalPort == 0 {
+		externalPort = internalPort
+	}
+
+	mappedPort, err := s.nat.AddPortMapping("tcp", int(externalPort),
+		int(internalPort), "btcd listen port",
+		int(natLeaseDuration/time.Second))
+	if err != nil {
+		return nil, err
+	}
+	expires := time.Now().Add(natLeaseDuration)
+	externalIP, err := s.nat.GetExternalAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	s.natMtx.Lock()
+	s.natMapping.externalIP = externalIP
+	s.natMapping.externalPort = uint16(mappedPort)
+	s.natMapping.expires = expires
+	s.natMtx.Unlock()
+
+	return wire.NetAddressV2FromBytes(time.Now(), s.services, externalIP,
+		uint16(mappedPort)), nil
+}
+
+// natUpdateThread maps the listening port on the NAT gateway, advertises the
+// external address of the mapping to peers, and keeps renewing the mapping
+// until the server is stopped, at which point the mapping is removed.  The
+// advertised address is replaced whenever the external address changes.  It
+// must be run as a goroutine.
+func (s *server) natUpdateThread() {
+	lport, _ := strconv.ParseUint(activeNetParams.DefaultPort, 10, 16)
+	protocol := s.nat.Protocol()
+	s.natMtx.Lock()
+	s.natMapping.protocol = protocol
+	s.natMapping.internalPort = uint16(lport)
+	s.natMtx.Unlock()
+
+	// Go off immediately to prevent code duplication, thereafter the
+	// mapping is renewed periodically.
+	timer := time.NewTimer(0)
+	var localAddr *wire.NetAddressV2
 out:
 	for {
 		select {
 		case <-timer.C:
-			// TODO: pick external port  more cleverly
-			// TODO: know which ports we are listening to on an external net.
-			// TODO: if specific listen port doesn't work then ask for wildcard
-			// listen port?
-			// XXX this assumes timeout is in seconds.
-			listenPort, err := s.nat.AddPortMapping("tcp", int(lport), int(lport),
-				"btcd listen port", 20*60)
+			na, err := s.renewNATMapping(uint16(lport))
+			s.natMtx.Lock()
+			s.natMapping.lastErr = err
+			s.natMtx.Unlock()
 			if err != nil {
-				srvrLog.Warnf("can't add UPnP port mapping: %v", err)
+				srvrLog.Warnf("Can't add %s port mapping: %v",
+					protocol, err)
+				timer.Reset(natRetryInterval)
+				continue
 			}
-			if first && err == nil {
-				// TODO: look this up periodically to see if upnp domain changed
-				// and so did ip.
-				externalip, err := s.nat.GetExternalAddress()
-				if err != nil {
-					srvrLog.Warnf("UPnP can't get external address: %v", err)
-					continue out
+
+			if localAddr == nil ||
+				addrmgr.NetAddressKey(localAddr) !=
+					addrmgr.NetAddressKey(na) {
+
+				if localAddr != nil {
+					s.addrManager.RemoveLocalAddress(localAddr)
 				}
-				na := wire.NetAddressV2FromBytes(time.Now(), s.services,
-					externalip, uint16(listenPort))
-				err = s.addrManager.AddLocalAddress(na, addrmgr.UpnpPrio)
+				err = s.addrManager.AddLocalAddress(na,
+					addrmgr.UpnpPrio)
 				if err != nil {
-					// XXX DeletePortMapping?
+					srvrLog.Warnf("Not advertising %s address "+
+						"%s: %v", protocol,
+						addrmgr.NetAddressKey(na), err)
+				} else {
+					srvrLog.Infof("Successfully bound via %s "+
+						"to %s", protocol,
+						addrmgr.NetAddressKey(na))
 				}
-				srvrLog.Warnf("Successfully bound via UPnP to %s", addrmgr.NetAddressKey(na))
-				first = false
+				localAddr = na
 			}
-			timer.Reset(time.Minute * 15)
+			timer.Reset(natLeaseDuration / 2)
+
 		case <-s.quit:
 			break out
 		}
@@ -2801,15 +3734,263 @@ out:
 
 	timer.Stop()
 
-	if err := s.nat.DeletePortMapping("tcp", int(lport), int(lport)); err != nil {
-		srvrLog.Warnf("unable to remove UPnP port mapping: %v", err)
+	s.natMtx.Lock()
+	externalPort := s.natMapping.externalPort
+	s.natMtx.Unlock()
+	if externalPort == 0 {
+		externalPort = uint16(lport)
+	}
+	err := s.nat.DeletePortMapping("tcp", int(externalPort), int(lport))
+	if err != nil {
+		srvrLog.Warnf("unable to remove %s port mapping: %v", protocol,
+			err)
 	} else {
-		srvrLog.Debugf("successfully disestablished UPnP port mapping")
+		srvrLog.Debugf("successfully disestablished %s port mapping",
+			protocol)
 	}
 
 	s.wg.Done()
 }
 
+// netAddressNetwork returns the name of the network the passed address belongs
+// to as used by the onlynet option.
+func netAddressNetwork(na *wire.NetAddressV2) string {
+	if na.IsTorV3() {
+		return "onion"
+	}
+	legacy := na.ToLegacy()
+	switch {
+	case addrmgr.IsOnionCatTor(legacy):
+		return "onion"
+	case addrmgr.IsIPv4(legacy):
+		return "ipv4"
+	}
+	return "ipv6"
+}
+
+// onionReachable returns whether outbound connections to onion addresses can be
+// made, which requires them to be made through tor.
+func onionReachable() bool {
+	if cfg.NoOnion || (cfg.Proxy == "" && cfg.OnionProxy == "") {
+		return false
+	}
+	_, ok := cfg.onlyNets["onion"]
+	return len(cfg.onlyNets) == 0 || ok
+}
+
+// onlyNetAllows returns whether automatic outbound connections to the passed
+// address are allowed by the onlynet option.
+func onlyNetAllows(na *wire.NetAddressV2) bool {
+	if len(cfg.onlyNets) == 0 {
+		return true
+	}
+	_, ok := cfg.onlyNets[netAddressNetwork(na)]
+	return ok
+}
+
+// torControlThread creates an onion service for incoming connections via the
+// tor control port and advertises its address to peers.  The onion service is
+// created again whenever the connection to the control port is lost.
+func (s *server) torControlThread() {
+	defer s.wg.Done()
+
+	for {
+		err := s.runOnionService()
+		if err != nil {
+			srvrLog.Warnf("Tor onion service: %v", err)
+		}
+
+		select {
+		case <-time.After(torControlRetryInterval):
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// runOnionService connects to the tor control port, creates an onion service
+// which forwards to the first listener, and advertises its address.  It
+// blocks until the server shuts down or the connection to the control port is
+// lost.  The private key of the onion service is saved in the data directory
+// so the node keeps its onion address across restarts.
+func (s *server) runOnionService() error {
+	conn, err := net.DialTimeout("tcp", cfg.TorControl, torControlTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	c := newTorController(conn)
+	if err := c.authenticate(cfg.TorPassword); err != nil {
+		return err
+	}
+
+	keyFile := filepath.Join(cfg.DataDir, onionKeyFilename)
+	var privateKey string
+	if key, err := os.ReadFile(keyFile); err == nil {
+		privateKey = strings.TrimSpace(string(key))
+	}
+	virtPort, _ := strconv.ParseUint(activeNetParams.DefaultPort, 10, 16)
+	host, newKey, err := c.addOnion(privateKey, uint16(virtPort),
+		s.onionTarget)
+	if err != nil {
+		return err
+	}
+	if privateKey == "" && newKey != "" {
+		err := os.WriteFile(keyFile, []byte(newKey+"\n"), 0600)
+		if err != nil {
+			srvrLog.Warnf("Unable to save onion service key: %v", err)
+		}
+	}
+
+	na, err := s.addrManager.HostToNetAddress(host, uint16(virtPort),
+		s.services)
+	if err != nil {
+		return err
+	}
+	if err := s.addrManager.AddLocalAddress(na, addrmgr.ManualPrio); err != nil {
+		return err
+	}
+	srvrLog.Infof("Accepting connections via onion service %s",
+		addrmgr.NetAddressKey(na))
+
+	// Tor does not send anything unless asked to, so any read returns
+	// once the connection is closed.
+	lost := make(chan struct{})
+	go func() {
+		c.reader.ReadLine()
+		close(lost)
+	}()
+
+	select {
+	case <-lost:
+		return errors.New("connection to the tor control port lost")
+	case <-s.quit:
+		return nil
+	}
+}
+
+// mdnsThread advertises the listening port on the local network and connects
+// to the nodes of the same network discovered there with multicast DNS.  It
+// must be run as a goroutine.
+func (s *server) mdnsThread() {
+	defer s.wg.Done()
+
+	// Every node is only connected to once per query interval, even when
+	// it responds on both multicast groups.
+	discovered := make(map[string]time.Time)
+	m, err := newMDNSService(activeNetParams.Name, s.mdnsPort,
+		func(instance string, addr *net.TCPAddr) {
+			if time.Since(discovered[instance]) < mdnsQueryInterval {
+				return
+			}
+			discovered[instance] = time.Now()
+
+			select {
+			case s.query <- lanPeerMsg{addr: addr}:
+			case <-s.quit:
+			}
+		})
+	if err != nil {
+		srvrLog.Errorf("Unable to start multicast DNS: %v", err)
+		return
+	}
+
+	type mdnsPacket struct {
+		msg   []byte
+		src   *net.UDPAddr
+		group *net.UDPAddr
+	}
+	packets := make(chan mdnsPacket)
+	conns := make(map[*net.UDPAddr]*net.UDPConn)
+	var readers sync.WaitGroup
+	for _, group := range []*net.UDPAddr{mdnsIPv4Group, mdnsIPv6Group} {
+		network := "udp4"
+		if group.IP.To4() == nil {
+			network = "udp6"
+		}
+		conn, err := net.ListenMulticastUDP(network, nil, group)
+		if err != nil {
+			srvrLog.Debugf("Unable to listen for multicast DNS on "+
+				"%v: %v", group, err)
+			continue
+		}
+		conns[group] = conn
+
+		readers.Add(1)
+		go func(conn *net.UDPConn, group *net.UDPAddr) {
+			defer readers.Done()
+			for {
+				buf := make([]byte, mdnsMaxMessageLen)
+				n, src, err := conn.ReadFromUDP(buf)
+				if err != nil {
+					return
+				}
+				select {
+				case packets <- mdnsPacket{buf[:n], src, group}:
+				case <-s.quit:
+					return
+				}
+			}
+		}(conn, group)
+	}
+	if len(conns) == 0 {
+		srvrLog.Warnf("Unable to listen for multicast DNS")
+		return
+	}
+	defer func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+		readers.Wait()
+	}()
+
+	send := func(msg []byte) {
+		for group, conn := range conns {
+			if _, err := conn.WriteToUDP(msg, group); err != nil {
+				srvrLog.Debugf("Unable to send multicast DNS "+
+					"message to %v: %v", group, err)
+			}
+		}
+	}
+
+	// Announce the node and query for the other nodes right away.
+	if s.mdnsPort != 0 {
+		send(m.response())
+	}
+	send(m.query())
+	srvrLog.Infof("Discovering peers on the local network with multicast " +
+		"DNS")
+
+	ticker := time.NewTicker(mdnsQueryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case packet := <-packets:
+			respond, err := m.handleMessage(packet.msg, packet.src)
+			if err != nil {
+				srvrLog.Tracef("Ignoring multicast DNS message "+
+					"from %v: %v", packet.src, err)
+				continue
+			}
+			if respond {
+				_, err := conns[packet.group].WriteToUDP(m.response(),
+					packet.group)
+				if err != nil {
+					srvrLog.Debugf("Unable to send multicast "+
+						"DNS response: %v", err)
+				}
+			}
+
+		case <-ticker.C:
+			send(m.query())
+
+		case <-s.quit:
+			return
+		}
+	}
+}
+
 // setupRPCListeners returns a slice of listeners that are configured for use
 // with the RPC server depending on the configuration settings for listen
 // addresses and TLS.
@@ -2859,6 +4040,51 @@ func setupRPCListeners() ([]net.Listener, error) {
 	return listeners, nil
 }
 
+// setupStratumListeners returns a slice of listeners that are configured for
+// use with the stratum server depending on the configuration settings for
+// stratum listen addresses.
+func setupStratumListeners() ([]net.Listener, error) {
+	netAddrs, err := parseListeners(cfg.StratumListeners)
+	if err != nil {
+		return nil, err
+	}
+
+	listeners := make([]net.Listener, 0, len(netAddrs))
+	for _, addr := range netAddrs {
+		listener, err := net.Listen(addr.Network(), addr.String())
+		if err != nil {
+			minrLog.Warnf("Can't listen on %s: %v", addr, err)
+			continue
+		}
+		listeners = append(listeners, listener)
+	}
+
+	return listeners, nil
+}
+
+// setupDNSSeederListeners returns a slice of UDP connections that are
+// configured for use with the DNS seeder depending on the configuration
+// settings for DNS seeder listen addresses.
+func setupDNSSeederListeners() ([]net.PacketConn, error) {
+	netAddrs, err := parseListeners(cfg.DNSSeederListeners)
+	if err != nil {
+		return nil, err
+	}
+
+	conns := make([]net.PacketConn, 0, len(netAddrs))
+	for _, addr := range netAddrs {
+		network := "udp" + strings.TrimPrefix(addr.Network(), "tcp")
+		conn, err := net.ListenPacket(network, addr.String())
+		if err != nil {
+			seedLog.Warnf("Can't listen on %s: %v", addr, err)
+			continue
+		}
+		conns = append(conns, conn)
+	}
+
+	return conns, nil
+}
+
 // newServer returns a new btcd server configured to listen on addr for the
 // bitcoin network type specified by chainParams.  Use start to begin accepting
 // connections from peers.
@@ -2873,6 +4099,9 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 	if cfg.NoCFilters {
 		services &^= wire.SFNodeCF
 	}
+	// Pruned nodes keep signaling SFNodeCF along with SFNodeNetworkLimited
+	// since they serve the filter headers of all blocks and the filters of
+	// the blocks they still store.
 	if cfg.Prune != 0 {
 		services &^= wire.SFNodeNetwork
 	}
@@ -2881,6 +4110,16 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 	}
 
 	amgr := addrmgr.New(cfg.DataDir, btcdLookup)
+	if cfg.ASMap != "" {
+		asmap, err := addrmgr.LoadASMap(cfg.ASMap)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load asmap %s: %v",
+				cfg.ASMap, err)
+		}
+		amgr.SetASMap(asmap)
+		srvrLog.Infof("Using asmap %s with hash %v", cfg.ASMap,
+			asmap.Hash())
+	}
 
 	var listeners []net.Listener
 	var nat NAT
@@ -2895,6 +4134,24 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		}
 	}
 
+	// Tor forwards connections to the onion service to the first listener.
+	var onionTarget string
+	if cfg.TorControl != "" && len(listeners) > 0 {
+		var err error
+		onionTarget, err = onionServiceTarget(listeners[0].Addr())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Multicast DNS advertises the port of the first listener.
+	var mdnsPort uint16
+	if cfg.MDNS && len(listeners) > 0 {
+		if addr, ok := listeners[0].Addr().(*net.TCPAddr); ok {
+			mdnsPort = uint16(addr.Port)
+		}
+	}
+
 	if len(agentBlacklist) > 0 {
 		srvrLog.Infof("User-agent blacklist %s", agentBlacklist)
 	}
@@ -2919,14 +4176,22 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		modifyRebroadcastInv: make(chan interface{}),
 		peerHeightsUpdate:    make(chan updatePeerHeightsMsg),
 		nat:                  nat,
+		onionTarget:          onionTarget,
+		mdnsPort:             mdnsPort,
 		db:                   db,
 		timeSource:           blockchain.NewMedianTime(),
+		clockSkew:            newClockSkewMonitor(cfg.MaxClockSkew),
 		services:             services,
-		sigCache:             txscript.NewSigCache(cfg.SigCacheMaxSize),
+		sigCache:             txscript.NewSigCacheWithPolicy(cfg.SigCacheMaxSize, cfg.sigCacheEviction),
 		hashCache:            txscript.NewHashCache(cfg.SigCacheMaxSize),
 		cfCheckptCaches:      make(map[wire.FilterType][]cfHeaderKV),
 		agentBlacklist:       agentBlacklist,
 		agentWhitelist:       agentWhitelist,
+		evictionSeed:         maphash.MakeSeed(),
+		bandwidth:            newBandwidthStats(cfg.MaxUploadTarget * 1024 * 1024),
+	}
+	if cfg.BlockServeRate > 0 {
+		s.blockServeLimiter = newBlockServeLimiter(cfg.BlockServeRate * 1024)
 	}
 
 	// Create the transaction and address indexes if needed.
@@ -2960,11 +4225,37 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		s.cfIndex = indexers.NewCfIndex(db, chainParams)
 		indexes = append(indexes, s.cfIndex)
 	}
+	if cfg.SpentIndex {
+		indxLog.Info("Spent output index is enabled")
+		s.spentIndex = indexers.NewSpentIndex(db)
+		indexes = append(indexes, s.spentIndex)
+	}
+	if cfg.ClaimIndex {
+		indxLog.Info("Claim index is enabled")
+		s.claimIndex = indexers.NewClaimIndex(db)
+		indexes = append(indexes, s.claimIndex)
+	}
+	if cfg.ClaimFilters {
+		indxLog.Info("Claim name filter index is enabled")
+		s.claimFilterIndex = indexers.NewClaimFilterIndex(db)
+		indexes = append(indexes, s.claimFilterIndex)
+	}
 
 	// Create an index manager if any of the optional indexes are enabled.
+	// The indexes which are behind the best chain tip are caught up in the
+	// background once the server is started so they can be enabled on a
+	// synced node without delaying its startup.  They are caught up before
+	// the server is started on pruned nodes instead since the blocks or
+	// spend journals they still need could be pruned in the meantime.
 	var indexManager blockchain.IndexManager
 	if len(indexes) > 0 {
-		indexManager = indexers.NewManager(db, indexes)
+		if cfg.Prune != 0 || cfg.PruneSpendJournal != 0 {
+			s.indexManager = indexers.NewManager(db, indexes)
+		} else {
+			s.indexManager = indexers.NewBackgroundManager(db,
+				indexes)
+		}
+		indexManager = s.indexManager
 	}
 
 	// Merge given checkpoints with the default ones unless they are disabled.
@@ -2973,6 +4264,12 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		checkpoints = mergeCheckpoints(s.chainParams.Checkpoints, cfg.addCheckpoints)
 	}
 
+	// Log the block whose ancestors are assumed to have valid scripts.
+	if cfg.assumeValid != nil {
+		btcdLog.Infof("Assuming ancestors of block %v have valid scripts",
+			cfg.assumeValid)
+	}
+
 	// Log that the node is pruned.
 	if cfg.Prune != 0 {
 		btcdLog.Infof("Prune set to %d MiB", cfg.Prune)
@@ -2981,31 +4278,38 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 	// Create a new block chain instance with the appropriate configuration.
 	var err error
 	s.chain, err = blockchain.New(&blockchain.Config{
-		DB:               s.db,
-		Interrupt:        interrupt,
-		ChainParams:      s.chainParams,
-		Checkpoints:      checkpoints,
-		TimeSource:       s.timeSource,
-		SigCache:         s.sigCache,
-		IndexManager:     indexManager,
-		HashCache:        s.hashCache,
-		Prune:            cfg.Prune * 1024 * 1024,
-		UtxoCacheMaxSize: uint64(cfg.UtxoCacheMaxSizeMiB) * 1024 * 1024,
+		DB:                     s.db,
+		Interrupt:              interrupt,
+		ChainParams:            s.chainParams,
+		Checkpoints:            checkpoints,
+		TimeSource:             s.timeSource,
+		SigCache:               s.sigCache,
+		IndexManager:           indexManager,
+		HashCache:              s.hashCache,
+		Prune:                  cfg.Prune * 1024 * 1024,
+		SpendJournalDepth:      cfg.PruneSpendJournal,
+		UtxoCacheMaxSize:       uint64(cfg.UtxoCacheMaxSizeMiB) * 1024 * 1024,
+		UtxoCacheFlushInterval: cfg.UtxoFlushInterval,
+		ReindexChainState:      cfg.ReindexChainState,
+		MinimumChainWork:       cfg.minimumChainWork,
+		AssumeValid:            cfg.assumeValid,
+		ScriptWorkers:          cfg.ScriptWorkers,
+		BatchSigVerify:         cfg.BatchSigVerify,
+		LogBlockBenchmarks:     cfg.Bench,
 	})
 	if err != nil {
 		return nil, err
 	}
+	if cfg.AlertNotify != "" {
+		s.chain.Subscribe(alertNotifyHandler(cfg.AlertNotify))
+	}
 
 	// Search for a FeeEstimator state in the database. If none can be found
 	// or if it cannot be loaded, create a new one.
-	db.Update(func(tx database.Tx) error {
+	db.View(func(tx database.Tx) error {
 		metadata := tx.Metadata()
 		feeEstimationData := metadata.Get(mempool.EstimateFeeDatabaseKey)
 		if feeEstimationData != nil {
-			// delete it from the database so that we don't try to restore the
-			// same thing again somehow.
-			metadata.Delete(mempool.EstimateFeeDatabaseKey)
-
 			// If there is an error, log it and make a new fee estimator.
 			var err error
 			s.feeEstimator, err = mempool.RestoreFeeEstimator(feeEstimationData)
@@ -3019,12 +4323,19 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 	})
 
 	// If no feeEstimator has been found, or if the one that has been found
-	// is behind somehow, create a new one and start over.
-	if s.feeEstimator == nil || s.feeEstimator.LastKnownHeight() != s.chain.BestSnapshot().Height {
+	// is ahead or too far behind somehow, create a new one and start over.
+	// Either way, back-fill it with the recent blocks it hasn't seen.
+	bestHeight := s.chain.BestSnapshot().Height
+	if s.feeEstimator == nil ||
+		s.feeEstimator.LastKnownHeight() > bestHeight ||
+		bestHeight-s.feeEstimator.LastKnownHeight() >
+			mempool.DefaultEstimateFeeBackfillDepth {
+
 		s.feeEstimator = mempool.NewFeeEstimator(
 			mempool.DefaultEstimateFeeMaxRollback,
 			mempool.DefaultEstimateFeeMinRegisteredBlocks)
 	}
+	s.backfillFeeEstimator()
 
 	txC := mempool.Config{
 		Policy: mempool.Policy{
@@ -3033,10 +4344,22 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 			FreeTxRelayLimit:     cfg.FreeTxRelayLimit,
 			MaxOrphanTxs:         cfg.MaxOrphanTxs,
 			MaxOrphanTxSize:      defaultMaxOrphanTxSize,
+			MaxOrphanTxsPerTag:   cfg.MaxOrphanTxsPerPeer,
+			MaxOrphanWeight:      cfg.MaxOrphanWeight,
+			OrphanTTL:            cfg.OrphanTTL,
+			TxExpiry:             cfg.MempoolExpiry,
 			MaxSigOpCostPerTx:    blockchain.MaxBlockSigOpsCost / 4,
 			MinRelayTxFee:        cfg.minRelayTxFee,
 			MaxTxVersion:         2,
 			RejectReplacement:    cfg.RejectReplacement,
+			DustRelayFee:         cfg.dustRelayFee,
+			MaxStandardTxWeight:  cfg.MaxStdTxWeight,
+			BytesPerSigOp:        cfg.BytesPerSigOp,
+			MaxDataCarrierSize:   cfg.DataCarrierSize,
+			AcceptClaimScripts:   cfg.AcceptClaimScripts,
+			MaxClaimValueSize:    cfg.MaxClaimValueSize,
+			CheckClaimMetadata:   cfg.CheckClaimMetadata,
+			RejectBareMultisig:   cfg.RejectBareMultisig,
 		},
 		ChainParams:    chainParams,
 		FetchUtxoView:  s.chain.FetchUtxoView,
@@ -3051,8 +4374,23 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		AddrIndex:          s.addrIndex,
 		FeeEstimator:       s.feeEstimator,
 	}
+	for _, name := range cfg.PolicyHooks {
+		txC.PolicyHooks = append(txC.PolicyHooks,
+			mempool.LookupPolicyHook(name))
+	}
+	if cfg.PolicySocket != "" {
+		txC.PolicyHooks = append(txC.PolicyHooks,
+			newPolicySocketHook(cfg.PolicySocket))
+	}
 	s.txMemPool = mempool.New(&txC)
 
+	// The blocks whose stored copies are found to be damaged are repaired
+	// by downloading them from peers again.
+	s.blockRepairer = newBlockRepairer(s.db, s.chain,
+		func(hash chainhash.Hash) {
+			s.syncManager.RequestBlockRepair(hash)
+		})
+
 	s.syncManager, err = netsync.New(&netsync.Config{
 		PeerNotifier:       &s,
 		Chain:              s.chain,
@@ -3060,11 +4398,17 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		ChainParams:        s.chainParams,
 		DisableCheckpoints: cfg.DisableCheckpoints,
 		MaxPeers:           cfg.MaxPeers,
+		StaleTipFactor:     cfg.StaleTipFactor,
 		FeeEstimator:       s.feeEstimator,
+		RepairBlock:        s.blockRepairer.repairBlock,
 	})
 	if err != nil {
 		return nil, err
 	}
+	err = ffldb.SetCorruptBlockHandler(s.db, s.blockRepairer.corruptBlock)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create the mining policy and block template generator based on the
 	// configuration options.
@@ -3072,12 +4416,17 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 	// NOTE: The CPU miner relies on the mempool, so the mempool has to be
 	// created before calling the function to create the CPU miner.
 	policy := mining.Policy{
-		BlockMinWeight:    cfg.BlockMinWeight,
-		BlockMaxWeight:    cfg.BlockMaxWeight,
-		BlockMinSize:      cfg.BlockMinSize,
-		BlockMaxSize:      cfg.BlockMaxSize,
-		BlockPrioritySize: cfg.BlockPrioritySize,
-		TxMinFreeFee:      cfg.minRelayTxFee,
+		BlockMinWeight:      cfg.BlockMinWeight,
+		BlockMaxWeight:      cfg.BlockMaxWeight,
+		BlockMinSize:        cfg.BlockMinSize,
+		BlockMaxSize:        cfg.BlockMaxSize,
+		BlockPrioritySize:   cfg.BlockPrioritySize,
+		ClaimPriorityWeight: cfg.ClaimPriorityWeight,
+		BlockMaxSigOpCost:   cfg.BlockMaxSigOpCost,
+		BlockMinTxFee:       cfg.blockMinTxFee,
+		TxMinFreeFee:        cfg.minRelayTxFee,
+		EmptyBlockFirst:     cfg.EmptyBlockFirst,
+		SignalBits:          cfg.signalBits,
 	}
 	blockTemplateGenerator := mining.NewBlkTmplGenerator(&policy,
 		s.chainParams, s.txMemPool, s.chain, s.timeSource,
@@ -3086,18 +4435,68 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		ChainParams:            chainParams,
 		BlockTemplateGenerator: blockTemplateGenerator,
 		MiningAddrs:            cfg.miningAddrs,
+		MiningPayouts:          cfg.miningPayouts,
 		ProcessBlock:           s.syncManager.ProcessBlock,
 		ConnectedCount:         s.ConnectedCount,
 		IsCurrent:              s.syncManager.IsCurrent,
 	})
 
+	// Setup the stratum server when any stratum listen addresses are
+	// configured.
+	if len(cfg.StratumListeners) > 0 {
+		stratumListeners, err := setupStratumListeners()
+		if err != nil {
+			return nil, err
+		}
+		if len(stratumListeners) == 0 {
+			return nil, errors.New("STRATUM: No valid listen address")
+		}
+
+		s.stratumServer = stratum.New(&stratum.Config{
+			ChainParams:            chainParams,
+			BlockTemplateGenerator: blockTemplateGenerator,
+			MiningAddrs:            cfg.miningAddrs,
+			MiningPayouts:          cfg.miningPayouts,
+			ProcessBlock:           s.syncManager.ProcessBlock,
+			IsCurrent:              s.syncManager.IsCurrent,
+			Listeners:              stratumListeners,
+			Password:               cfg.StratumPass,
+			StartDifficulty:        cfg.StratumDifficulty,
+			MinDifficulty:          cfg.StratumMinDifficulty,
+			MaxDifficulty:          cfg.StratumMaxDifficulty,
+			TargetShareTime:        cfg.StratumShareTime,
+		})
+	}
+
+	// Setup the DNS seeder when it is enabled.
+	if cfg.DNSSeeder {
+		seederListeners, err := setupDNSSeederListeners()
+		if err != nil {
+			return nil, err
+		}
+		if len(seederListeners) == 0 {
+			return nil, errors.New("SEED: No valid listen address")
+		}
+
+		defaultPort, _ := strconv.ParseUint(chainParams.DefaultPort, 10, 16)
+		s.dnsSeeder = dnsseeder.New(&dnsseeder.Config{
+			Hostname:        cfg.DNSSeederHost,
+			Nameserver:      cfg.DNSSeederNS,
+			DefaultPort:     uint16(defaultPort),
+			DefaultServices: wire.SFNodeNetwork,
+			Listeners:       seederListeners,
+			GoodAddresses:   s.addrManager.GoodAddresses,
+		})
+	}
+
 	// Only setup a function to return new addresses to connect to when
 	// not running in connect-only mode.  The simulation network is always
 	// in connect-only mode since it is only intended to connect to
 	// specified peers and actively avoid advertising and connecting to
 	// discovered peers in order to prevent it from becoming a public test
 	// network.
-	var newAddressFunc func() (net.Addr, error)
+	var newAddressFunc, feelerAddressFunc func() (net.Addr, error)
+	var newOnionAddressFunc func() (net.Addr, error)
 	if !cfg.SimNet && len(cfg.ConnectPeers) == 0 {
 		newAddressFunc = func() (net.Addr, error) {
 			for tries := 0; tries < 100; tries++ {
@@ -3112,7 +4511,7 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 				// in the same group so that we are not connecting
 				// to the same network segment at the expense of
 				// others.
-				key := addrmgr.GroupKey(addr.NetAddress())
+				key := s.addrManager.GroupKey(addr.NetAddress())
 				if s.OutboundGroupCount(key) != 0 {
 					continue
 				}
@@ -3129,6 +4528,12 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 					continue
 				}
 
+				// Only connect to networks allowed by the onlynet
+				// option.
+				if !onlyNetAllows(addr.NetAddress()) {
+					continue
+				}
+
 				// Mark an attempt for the valid address.
 				s.addrManager.Attempt(addr.NetAddress())
 
@@ -3138,30 +4543,124 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 
 			return nil, errors.New("no valid connect address")
 		}
+
+		// Feeler connections test addresses from the new table so the
+		// reachable ones are moved to the tried table.
+		feelerAddressFunc = func() (net.Addr, error) {
+			for tries := 0; tries < 100; tries++ {
+				addr := s.addrManager.GetNewAddress()
+				if addr == nil {
+					break
+				}
+
+				// Skip addresses which were attempted recently.
+				if time.Since(addr.LastAttempt()) < 10*time.Minute {
+					continue
+				}
+
+				// Only connect to networks allowed by the onlynet
+				// option.
+				if !onlyNetAllows(addr.NetAddress()) {
+					continue
+				}
+
+				// Mark an attempt for the valid address.
+				s.addrManager.Attempt(addr.NetAddress())
+
+				addrString := addrmgr.NetAddressKey(addr.NetAddress())
+				return addrStringToNetAddr(addrString)
+			}
+
+			return nil, errors.New("no valid feeler address")
+		}
+
+		// Onion connections are only made when tor hidden services can
+		// be reached.
+		if onionReachable() {
+			newOnionAddressFunc = func() (net.Addr, error) {
+				for tries := 0; tries < 100; tries++ {
+					addr := s.addrManager.GetAddress()
+					if addr == nil {
+						break
+					}
+
+					na := addr.NetAddress()
+					if netAddressNetwork(na) != "onion" ||
+						!onlyNetAllows(na) {
+
+						continue
+					}
+					key := s.addrManager.GroupKey(na)
+					if s.OutboundGroupCount(key) != 0 {
+						continue
+					}
+
+					// Mark an attempt for the valid address.
+					s.addrManager.Attempt(na)
+
+					addrString := addrmgr.NetAddressKey(na)
+					return addrStringToNetAddr(addrString)
+				}
+
+				return nil, errors.New("no valid onion address")
+			}
+		}
+
+		// Anchors are only used when outbound peers are chosen
+		// automatically.
+		s.anchorsFile = filepath.Join(cfg.DataDir, anchorsFilename)
 	}
 
-	// Create a connection manager.
-	targetOutbound := defaultTargetOutbound
+	// Create a connection manager.  The regular, block-relay-only and onion
+	// outbound connections are limited to the max peers together.
+	targetOutbound := cfg.OutboundPeers
 	if cfg.MaxPeers < targetOutbound {
 		targetOutbound = cfg.MaxPeers
 	}
+	targetBlockRelayOnly := cfg.BlockRelayOnlyPeers
+	if cfg.MaxPeers-targetOutbound < targetBlockRelayOnly {
+		targetBlockRelayOnly = cfg.MaxPeers - targetOutbound
+	}
+	targetOnion := cfg.OnionPeers
+	if cfg.MaxPeers-targetOutbound-targetBlockRelayOnly < targetOnion {
+		targetOnion = cfg.MaxPeers - targetOutbound - targetBlockRelayOnly
+	}
+
+	// Make feeler connections more often when running as a DNS seeder so
+	// the good addresses it serves are discovered quickly.
+	connFeelerInterval := feelerInterval
+	if cfg.DNSSeeder {
+		connFeelerInterval = dnsSeederFeelerInterval
+	}
 	cmgr, err := connmgr.New(&connmgr.Config{
-		Listeners:      listeners,
-		OnAccept:       s.inboundPeerConnected,
-		RetryDuration:  connectionRetryInterval,
-		TargetOutbound: uint32(targetOutbound),
-		Dial:           btcdDial,
-		OnConnection:   s.outboundPeerConnected,
-		GetNewAddress:  newAddressFunc,
+		Listeners:            listeners,
+		OnAccept:             s.inboundPeerConnected,
+		RetryDuration:        connectionRetryInterval,
+		TargetOutbound:       uint32(targetOutbound),
+		TargetBlockRelayOnly: uint32(targetBlockRelayOnly),
+		TargetOnion:          uint32(targetOnion),
+		Dial:                 btcdDial,
+		OnConnection:         s.outboundPeerConnected,
+		GetNewAddress:        newAddressFunc,
+		GetNewOnionAddress:   newOnionAddressFunc,
+		FeelerInterval:       connFeelerInterval,
+		GetFeelerAddress:     feelerAddressFunc,
 	})
 	if err != nil {
 		return nil, err
 	}
 	s.connManager = cmgr
 
-	s.p2pDowngrader = peer.NewP2PDowngrader(uint(targetOutbound) + 1)
+	s.p2pDowngrader = peer.NewP2PDowngrader(
+		uint(targetOutbound+targetBlockRelayOnly+targetOnion) + 1)
 
-	// Start up persistent peers.
+	// Start up persistent peers along with the nodes which were added via
+	// the RPC server before the last shutdown.
+	s.addedNodes, err = newAddedNodeList(filepath.Join(cfg.DataDir,
+		addedNodesFilename))
+	if err != nil {
+		srvrLog.Warnf("Unable to load added nodes: %v", err)
+	}
 	permanentPeers := cfg.ConnectPeers
 	if len(permanentPeers) == 0 {
 		permanentPeers = cfg.AddPeers
@@ -3172,11 +4671,30 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 			return nil, err
 		}
 
-		go s.connManager.Connect(&connmgr.ConnReq{
-			Addr:      netAddr,
-			Permanent: true,
+		if s.addedNodes.find(addr) != nil {
+			continue
+		}
+		s.addedNodes.add(&addedNode{
+			addr:    addr,
+			connReq: &connmgr.ConnReq{Addr: netAddr, Permanent: true},
+			config:  true,
 		})
 	}
+	for _, node := range s.addedNodes.list() {
+		if node.connReq == nil {
+			netAddr, err := addrStringToNetAddr(node.addr)
+			if err != nil {
+				srvrLog.Warnf("Unable to connect to added node "+
+					"%s: %v", node.addr, err)
+				continue
+			}
+			node.connReq = &connmgr.ConnReq{
+				Addr:      netAddr,
+				Permanent: true,
+			}
+		}
+		go s.connManager.Connect(node.connReq)
+	}
 
 	if !cfg.DisableRPC {
 		// Setup listeners for the configured RPC listen addresses and
@@ -3201,9 +4719,14 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 			TxMemPool:    s.txMemPool,
 			Generator:    blockTemplateGenerator,
 			CPUMiner:     s.cpuMiner,
+			Stratum:      s.stratumServer,
 			TxIndex:      s.txIndex,
 			AddrIndex:    s.addrIndex,
 			CfIndex:      s.cfIndex,
+			SpentIndex:   s.spentIndex,
+			ClaimIndex:   s.claimIndex,
+			ClaimFilters: s.claimFilterIndex,
+			IndexManager: s.indexManager,
 			FeeEstimator: s.feeEstimator,
 		})
 		if err != nil {
@@ -3220,9 +4743,41 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 	return &s, nil
 }
 
+// discoverNAT returns a NAT for the gateway of the local network using UPnP
+// or NAT-PMP/PCP depending on which of them are enabled, trying UPnP first.
+// It returns nil when none of them are supported.
+func discoverNAT() NAT {
+	if cfg.Upnp {
+		nat, err := Discover()
+		if err == nil {
+			return nat
+		}
+		srvrLog.Warnf("Can't discover upnp: %v", err)
+	}
+
+	if cfg.NATPMP {
+		gateway, err := defaultGateway()
+		if err != nil {
+			srvrLog.Warnf("Can't determine the default gateway: %v", err)
+			return nil
+		}
+		nat, err := DiscoverNATPMP(&net.UDPAddr{
+			IP:   gateway,
+			Port: natpmpPort,
+		})
+		if err == nil {
+			return nat
+		}
+		srvrLog.Warnf("Can't discover NAT-PMP or PCP on gateway %v: %v",
+			gateway, err)
+	}
+
+	return nil
+}
+
 // initListeners initializes the configured net listeners and adds any bound
 // addresses to the address manager. Returns the listeners and a NAT interface,
-// which is non-nil if UPnP is in use.
+// which is non-nil if UPnP, NAT-PMP or PCP is in use.
 func initListeners(amgr *addrmgr.AddrManager, listenAddrs []string, services wire.ServiceFlag) ([]net.Listener, NAT, error) {
 	// Listen for TCP connections at the configured addresses
 	netAddrs, err := parseListeners(listenAddrs)
@@ -3276,13 +4831,10 @@ func initListeners(amgr *addrmgr.AddrManager, listenAddrs []string, services wir
 			}
 		}
 	} else {
-		if cfg.Upnp {
-			var err error
-			nat, err = Discover()
-			if err != nil {
-				srvrLog.Warnf("Can't discover upnp: %v", err)
-			}
-			// nil nat here is fine, just means no upnp on network.
+		if cfg.Upnp || cfg.NATPMP {
+			// nil nat here is fine, just means no NAT traversal
+			// protocol is supported on the network.
+			nat = discoverNAT()
 		}
 
 		// Add bound addresses to address manager to be advertised to peers.
@@ -3416,32 +4968,6 @@ func dynamicTickDuration(remaining time.Duration) time.Duration {
 	return time.Hour
 }
 
-// isWhitelisted returns whether the IP address is included in the whitelisted
-// networks and IPs.
-func isWhitelisted(addr net.Addr) bool {
-	if len(cfg.whitelists) == 0 {
-		return false
-	}
-
-	host, _, err := net.SplitHostPort(addr.String())
-	if err != nil {
-		srvrLog.Warnf("Unable to SplitHostPort on '%s': %v", addr, err)
-		return false
-	}
-	ip := net.ParseIP(host)
-	if ip == nil {
-		srvrLog.Warnf("Unable to parse IP '%s'", addr)
-		return false
-	}
-
-	for _, ipnet := range cfg.whitelists {
-		if ipnet.Contains(ip) {
-			return true
-		}
-	}
-	return false
-}
-
 // checkpointSorter implements sort.Interface to allow a slice of checkpoints to
 // be sorted.
 type checkpointSorter []chaincfg.Checkpoint
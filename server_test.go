@@ -7,15 +7,18 @@ import (
 	"time"
 
 	"github.com/btcsuite/btcd/chaincfg/v2"
+	"github.com/btcsuite/btcd/chainhash/v2"
 	"github.com/btcsuite/btcd/peer"
+	"github.com/btcsuite/btcd/wire/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestMain(m *testing.M) {
-	// logRotator must be non-nil or any log write (e.g. from
-	// OnVerAck's double-call guard) panics via logWriter.Write.
-	initLogRotator(filepath.Join(os.TempDir(), "btcd-server-test.log"))
+	// Keep the log writes (e.g. from OnVerAck's double-call guard) out
+	// of the default log directory.
+	initLogRotator(filepath.Join(os.TempDir(), "btcd-server-test.log"),
+		defaultLogMaxSizeMiB, defaultLogMaxFiles, true)
 	os.Exit(m.Run())
 }
 
@@ -145,3 +148,149 @@ func TestPeerLifecycleSimultaneousReady(t *testing.T) {
 		"(both outcomes are valid per documented behavior)",
 		addEmitted, iterations)
 }
+
+// TestTxRelayLimiter ensures the transaction relay limiter allows bursts up to
+// the configured size and refills at the configured rate.
+func TestTxRelayLimiter(t *testing.T) {
+	t.Parallel()
+
+	l := newTxRelayLimiter(2, 3)
+	now := time.Unix(1700000000, 0)
+
+	// The bucket starts full.
+	for i := 0; i < 3; i++ {
+		require.True(t, l.Allow(now), "burst transaction %d", i)
+	}
+	require.False(t, l.Allow(now), "transaction above burst")
+
+	// Half a second refills a single token at a rate of two per second.
+	now = now.Add(time.Second / 2)
+	require.True(t, l.Allow(now))
+	require.False(t, l.Allow(now))
+
+	// The bucket never holds more than the burst size.
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		require.True(t, l.Allow(now), "refilled transaction %d", i)
+	}
+	require.False(t, l.Allow(now), "transaction above refilled burst")
+}
+
+// TestThrottleTxInv ensures transaction announcements above the relay rate
+// limit of a peer are removed from inventory messages while all other
+// inventory is kept.
+func TestThrottleTxInv(t *testing.T) {
+	t.Parallel()
+
+	_, sp := newTestServerPeer(t)
+
+	newInv := func(types ...wire.InvType) *wire.MsgInv {
+		msg := wire.NewMsgInv()
+		for i, typ := range types {
+			hash := chainhash.Hash{byte(i)}
+			require.NoError(t, msg.AddInvVect(
+				wire.NewInvVect(typ, &hash)))
+		}
+		return msg
+	}
+
+	// Peers without a limiter are never throttled.
+	msg := newInv(wire.InvTypeTx, wire.InvTypeTx, wire.InvTypeBlock)
+	require.Same(t, msg, sp.throttleTxInv(msg))
+	announced, throttled, received := (*rpcPeer)(sp).TxRelayStats()
+	assert.Equal(t, uint64(2), announced)
+	assert.Equal(t, uint64(0), throttled)
+	assert.Equal(t, uint64(0), received)
+
+	// Only the transactions within the burst are kept along with all of
+	// the other inventory.
+	sp.txRelayLimiter = newTxRelayLimiter(1, 2)
+	msg = newInv(wire.InvTypeTx, wire.InvTypeBlock, wire.InvTypeWitnessTx,
+		wire.InvTypeTx, wire.InvTypeBlock, wire.InvTypeTx)
+	got := sp.throttleTxInv(msg)
+	require.Equal(t, []*wire.InvVect{
+		msg.InvList[0], msg.InvList[1], msg.InvList[2], msg.InvList[4],
+	}, got.InvList)
+	announced, throttled, _ = (*rpcPeer)(sp).TxRelayStats()
+	assert.Equal(t, uint64(6), announced)
+	assert.Equal(t, uint64(2), throttled)
+}
+
+// TestConnectionKinds ensures block-relay-only peers never take part in
+// transaction relay and that each kind of connection is reported as expected.
+func TestConnectionKinds(t *testing.T) {
+	origCfg := cfg
+	defer func() { cfg = origCfg }()
+	cfg = &config{}
+
+	newOutbound := func(persistent, blockRelayOnly, feeler bool) *serverPeer {
+		sp := newServerPeer(&server{}, persistent)
+		sp.blockRelayOnly = blockRelayOnly
+		sp.feeler = feeler
+		p, err := peer.NewOutboundPeer(&peer.Config{
+			ChainParams: &chaincfg.SimNetParams,
+		}, "10.0.0.1:18555")
+		require.NoError(t, err)
+		sp.Peer = p
+		return sp
+	}
+
+	_, inbound := newTestServerPeer(t)
+	tests := []struct {
+		name           string
+		sp             *serverPeer
+		connectionType string
+		txRelay        bool
+	}{
+		{"inbound", inbound, "inbound", true},
+		{"manual", newOutbound(true, false, false), "manual", true},
+		{"full relay", newOutbound(false, false, false),
+			"outbound-full-relay", true},
+		{"block relay only", newOutbound(false, true, false),
+			"block-relay-only", false},
+		{"feeler", newOutbound(false, false, true), "feeler", true},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.connectionType, test.sp.connectionType(),
+			test.name)
+		assert.Equal(t, test.txRelay, test.sp.txRelayAllowed(), test.name)
+	}
+
+	// Transactions are only accepted from peers with the relay permission
+	// in blocks only mode, but never from block-relay-only peers.
+	cfg.BlocksOnly = true
+	assert.False(t, tests[2].sp.txRelayAllowed())
+	tests[2].sp.permissions = permRelay
+	assert.True(t, tests[2].sp.txRelayAllowed())
+	tests[3].sp.permissions = permRelay
+	assert.False(t, tests[3].sp.txRelayAllowed())
+}
+
+// TestTrickleInterval ensures the trickle interval is only scaled up once more
+// peers than the configured number are connected, and never by more than
+// maxTrickleScale.
+func TestTrickleInterval(t *testing.T) {
+	origCfg := cfg
+	defer func() { cfg = origCfg }()
+	cfg = &config{TrickleInterval: time.Second * 10}
+
+	s := &server{numPeers: 100}
+	assert.Equal(t, time.Second*10, s.trickleInterval(), "scaling disabled")
+
+	cfg.TrickleScalePeers = 8
+	tests := []struct {
+		numPeers int32
+		interval time.Duration
+	}{
+		{0, time.Second * 10},
+		{8, time.Second * 10},
+		{12, time.Second * 15},
+		{24, time.Second * 30},
+		{100, time.Second * 10 * maxTrickleScale},
+	}
+	for _, test := range tests {
+		s.numPeers = test.numPeers
+		assert.Equal(t, test.interval, s.trickleInterval(),
+			"%d peers", test.numPeers)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// shutdownTimeoutExitCode is the exit code of btcd when it is forced to
+	// exit because the shutdown didn't complete within the time allowed by
+	// the shutdowntimeout option.  It differs from the exit code of other
+	// errors and of runtime panics so it can be told apart by operators.
+	shutdownTimeoutExitCode = 3
+
+	// shutdownProgressInterval is the interval at which the progress of a
+	// shutdown is logged.
+	shutdownProgressInterval = 10 * time.Second
+)
+
+// shutdownMonitor tracks the progress of a shutdown.  Once a shutdown has
+// been requested, it periodically logs the stage the shutdown is in along with
+// how long it has taken so far, and forces btcd to exit when it doesn't
+// complete within the timeout.
+type shutdownMonitor struct {
+	timeout time.Duration
+	done    chan struct{}
+
+	// exit is called with shutdownTimeoutExitCode when the shutdown times
+	// out.  It is os.Exit other than in tests.
+	exit func(code int)
+
+	mtx   sync.Mutex
+	stage string
+}
+
+// newShutdownMonitor returns a new shutdown monitor which forces btcd to exit
+// when a shutdown takes longer than the passed timeout.  A timeout of zero
+// allows the shutdown to take as long as needed.
+func newShutdownMonitor(timeout time.Duration) *shutdownMonitor {
+	return &shutdownMonitor{
+		timeout: timeout,
+		done:    make(chan struct{}),
+		exit:    os.Exit,
+		stage:   "shutting down",
+	}
+}
+
+// SetStage sets the stage of the shutdown, such as flushing the database, which
+// is logged along with the progress of the shutdown.
+//
+// This function is safe for concurrent access.
+func (m *shutdownMonitor) SetStage(stage string) {
+	m.mtx.Lock()
+	m.stage = stage
+	m.mtx.Unlock()
+}
+
+// currentStage returns the stage of the shutdown.
+//
+// This function is safe for concurrent access.
+func (m *shutdownMonitor) currentStage() string {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.stage
+}
+
+// Done marks the shutdown as complete, which stops the monitor.
+func (m *shutdownMonitor) Done() {
+	close(m.done)
+}
+
+// Run waits for the passed interrupt channel to be closed and then monitors
+// the shutdown until it completes.  It must be run as a goroutine.
+func (m *shutdownMonitor) Run(interrupt <-chan struct{}) {
+	select {
+	case <-interrupt:
+	case <-m.done:
+		return
+	}
+
+	start := time.Now()
+	progressTicker := time.NewTicker(shutdownProgressInterval)
+	defer progressTicker.Stop()
+
+	var deadline <-chan time.Time
+	if m.timeout > 0 {
+		btcdLog.Infof("Allowing up to %v for the shutdown to complete",
+			m.timeout)
+		timer := time.NewTimer(m.timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	for {
+		select {
+		case <-progressTicker.C:
+			elapsed := time.Since(start).Round(time.Second)
+			btcdLog.Infof("Still shutting down after %v: %s", elapsed,
+				m.currentStage())
+
+		case <-deadline:
+			btcdLog.Errorf("Shutdown did not complete within %v while "+
+				"%s -- forcing exit.  The chain state will be "+
+				"recovered on the next start",
+				m.timeout, m.currentStage())
+			if logRotator != nil {
+				logRotator.Close()
+			}
+			m.exit(shutdownTimeoutExitCode)
+			return
+
+		case <-m.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// runShutdownMonitor runs the passed monitor with the passed interrupt channel
+// and returns a channel which is closed once it returns.
+func runShutdownMonitor(m *shutdownMonitor,
+	interrupt <-chan struct{}) <-chan struct{} {
+
+	returned := make(chan struct{})
+	go func() {
+		m.Run(interrupt)
+		close(returned)
+	}()
+	return returned
+}
+
+// TestShutdownMonitorDone ensures the monitor stops once the shutdown
+// completes, whether or not a shutdown was requested, without forcing an exit.
+func TestShutdownMonitorDone(t *testing.T) {
+	for _, interrupted := range []bool{false, true} {
+		m := newShutdownMonitor(time.Minute)
+		m.exit = func(code int) {
+			t.Errorf("unexpected exit with code %d", code)
+		}
+
+		interrupt := make(chan struct{})
+		returned := runShutdownMonitor(m, interrupt)
+		if interrupted {
+			close(interrupt)
+		}
+		m.SetStage("flushing the database")
+		if stage := m.currentStage(); stage != "flushing the database" {
+			t.Fatalf("got stage %q, want %q", stage,
+				"flushing the database")
+		}
+		m.Done()
+
+		select {
+		case <-returned:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("monitor did not stop (interrupted %v)",
+				interrupted)
+		}
+	}
+}
+
+// TestShutdownMonitorTimeout ensures the monitor forces an exit when the
+// shutdown doesn't complete within the timeout.
+func TestShutdownMonitorTimeout(t *testing.T) {
+	m := newShutdownMonitor(50 * time.Millisecond)
+	exitCode := make(chan int, 1)
+	m.exit = func(code int) {
+		exitCode <- code
+	}
+
+	interrupt := make(chan struct{})
+	returned := runShutdownMonitor(m, interrupt)
+
+	// The timeout only starts once a shutdown is requested.
+	select {
+	case code := <-exitCode:
+		t.Fatalf("unexpected exit with code %d before shutdown", code)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(interrupt)
+	select {
+	case code := <-exitCode:
+		if code != shutdownTimeoutExitCode {
+			t.Fatalf("got exit code %d, want %d", code,
+				shutdownTimeoutExitCode)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("monitor did not force an exit")
+	}
+	<-returned
+}
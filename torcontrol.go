@@ -0,0 +1,221 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// torControlTimeout is the maximum amount of time to wait for the tor
+	// control port to accept a connection or answer a command.
+	torControlTimeout = time.Second * 30
+
+	// torControlRetryInterval is the amount of time to wait before
+	// reconnecting to the tor control port after the connection failed or
+	// was lost.
+	torControlRetryInterval = time.Minute
+
+	// onionKeyFilename is the name of the file in the data directory the
+	// private key of the onion service is stored in so the node keeps the
+	// same onion address across restarts.
+	onionKeyFilename = "onion_v3_private_key"
+)
+
+// torController is a minimal client for the tor control protocol which is
+// able to authenticate and create an ephemeral onion service.  The onion
+// service is removed by tor once the connection is closed.
+type torController struct {
+	conn   net.Conn
+	reader *textproto.Reader
+}
+
+// newTorController returns a tor controller which uses the passed connection
+// to the tor control port.
+func newTorController(conn net.Conn) *torController {
+	return &torController{
+		conn:   conn,
+		reader: textproto.NewReader(bufio.NewReader(conn)),
+	}
+}
+
+// command sends the passed command to tor and returns the lines of the reply
+// without the status code.  An error is returned unless tor reports success.
+func (c *torController) command(cmd string) ([]string, error) {
+	// Only the keyword is used in errors since the arguments might
+	// contain secrets.
+	keyword := strings.SplitN(cmd, " ", 2)[0]
+
+	c.conn.SetDeadline(time.Now().Add(torControlTimeout))
+	defer c.conn.SetDeadline(time.Time{})
+
+	if _, err := c.conn.Write([]byte(cmd + "\r\n")); err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for {
+		line, err := c.reader.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+		if len(line) < 4 {
+			return nil, fmt.Errorf("malformed reply to %s: %q",
+				keyword, line)
+		}
+		if line[:3] != "250" {
+			return nil, fmt.Errorf("%s failed: %s", keyword, line)
+		}
+		lines = append(lines, line[4:])
+
+		switch line[3] {
+		case ' ':
+			return lines, nil
+
+		// Data replies are terminated by a line with a single dot.
+		case '+':
+			if _, err := c.reader.ReadDotLines(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// authenticate authenticates with tor using the first supported method the
+// control port offers.  The password is only used when tor accepts hashed
+// passwords.
+func (c *torController) authenticate(password string) error {
+	lines, err := c.command("PROTOCOLINFO 1")
+	if err != nil {
+		return err
+	}
+
+	var methods map[string]struct{}
+	var cookieFile string
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "AUTH ") {
+			continue
+		}
+		methods, cookieFile, err = parseTorAuthMethods(line)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, ok := methods["NULL"]; ok {
+		_, err := c.command("AUTHENTICATE")
+		return err
+	}
+	if _, ok := methods["HASHEDPASSWORD"]; ok && password != "" {
+		_, err := c.command("AUTHENTICATE " + strconv.Quote(password))
+		return err
+	}
+	if _, ok := methods["COOKIE"]; ok && cookieFile != "" {
+		cookie, err := os.ReadFile(cookieFile)
+		if err != nil {
+			return err
+		}
+		_, err = c.command("AUTHENTICATE " + hex.EncodeToString(cookie))
+		return err
+	}
+
+	return errors.New("the tor control port does not offer a supported " +
+		"authentication method")
+}
+
+// parseTorAuthMethods parses the AUTH line of a PROTOCOLINFO reply and returns
+// the offered authentication methods along with the path of the cookie file
+// if there is one.
+func parseTorAuthMethods(line string) (map[string]struct{}, string, error) {
+	methods := make(map[string]struct{})
+	var cookieFile string
+	for _, field := range strings.Fields(line) {
+		if !strings.HasPrefix(field, "METHODS=") {
+			continue
+		}
+		names := strings.TrimPrefix(field, "METHODS=")
+		for _, name := range strings.Split(names, ",") {
+			methods[name] = struct{}{}
+		}
+	}
+
+	// The cookie file is a quoted string which might contain spaces.
+	const cookieFilePrefix = "COOKIEFILE="
+	if i := strings.Index(line, cookieFilePrefix); i != -1 {
+		quoted, err := strconv.QuotedPrefix(line[i+len(cookieFilePrefix):])
+		if err != nil {
+			return nil, "", fmt.Errorf("malformed cookie file in %q",
+				line)
+		}
+		cookieFile, err = strconv.Unquote(quoted)
+		if err != nil {
+			return nil, "", fmt.Errorf("malformed cookie file in %q",
+				line)
+		}
+	}
+
+	return methods, cookieFile, nil
+}
+
+// addOnion creates an onion service which forwards connections to the passed
+// virtual port to the target address.  A new key is generated when no private
+// key is passed.  It returns the onion address of the service along with its
+// private key.
+func (c *torController) addOnion(privateKey string, virtPort uint16,
+	target string) (string, string, error) {
+
+	if privateKey == "" {
+		privateKey = "NEW:ED25519-V3"
+	}
+	cmd := fmt.Sprintf("ADD_ONION %s Port=%d,%s", privateKey, virtPort,
+		target)
+	lines, err := c.command(cmd)
+	if err != nil {
+		return "", "", err
+	}
+
+	var serviceID string
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "ServiceID="):
+			serviceID = strings.TrimPrefix(line, "ServiceID=")
+		case strings.HasPrefix(line, "PrivateKey="):
+			privateKey = strings.TrimPrefix(line, "PrivateKey=")
+		}
+	}
+	if serviceID == "" {
+		return "", "", errors.New("ADD_ONION reply is missing the " +
+			"service id")
+	}
+
+	return serviceID + ".onion", privateKey, nil
+}
+
+// onionServiceTarget returns the address tor should forward connections to
+// the onion service to for the passed listener address.  Listeners bound to
+// all interfaces are reached via the loopback address.
+func onionServiceTarget(addr net.Addr) (string, error) {
+	host, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return "", err
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+		if ip.To4() != nil {
+			host = "127.0.0.1"
+		} else {
+			host = "::1"
+		}
+	}
+	return net.JoinHostPort(host, port), nil
+}
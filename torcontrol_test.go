@@ -0,0 +1,172 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/wire/v2"
+)
+
+// fakeTorControl answers the commands read from the passed connection with
+// the replies for each expected command in order.  Unexpected commands are
+// answered with an error.
+func fakeTorControl(t *testing.T, conn net.Conn, replies map[string]string,
+	order []string) {
+
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for _, want := range order {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := line[:len(line)-2]
+		if cmd != want {
+			t.Errorf("unexpected command %q, want %q", cmd, want)
+			conn.Write([]byte("510 Unrecognized command\r\n"))
+			return
+		}
+		conn.Write([]byte(replies[cmd]))
+	}
+}
+
+// TestTorControlOnionService ensures the tor controller authenticates with the
+// offered methods and parses the created onion service.
+func TestTorControlOnionService(t *testing.T) {
+	cookieFile := filepath.Join(t.TempDir(), "control auth cookie")
+	if err := os.WriteFile(cookieFile, []byte{0x01, 0xab}, 0600); err != nil {
+		t.Fatalf("unable to write cookie: %v", err)
+	}
+	serviceID := "pg6mmjiyjmcrsslvykfwnntlaru7p5svn6y2ymmju6nubxndf4pscryd"
+
+	tests := []struct {
+		name     string
+		auth     string
+		password string
+		authCmd  string
+		key      string
+		addCmd   string
+	}{{
+		name:    "cookie",
+		auth:    `250-AUTH METHODS=COOKIE,SAFECOOKIE COOKIEFILE="` + cookieFile + `"`,
+		authCmd: "AUTHENTICATE 01ab",
+		addCmd:  "ADD_ONION NEW:ED25519-V3 Port=9246,127.0.0.1:9246",
+	}, {
+		name:     "password",
+		auth:     "250-AUTH METHODS=HASHEDPASSWORD",
+		password: `se"cret`,
+		authCmd:  `AUTHENTICATE "se\"cret"`,
+		key:      "ED25519-V3:key",
+		addCmd:   "ADD_ONION ED25519-V3:key Port=9246,127.0.0.1:9246",
+	}, {
+		name:    "null",
+		auth:    "250-AUTH METHODS=NULL",
+		authCmd: "AUTHENTICATE",
+		addCmd:  "ADD_ONION NEW:ED25519-V3 Port=9246,127.0.0.1:9246",
+	}}
+
+	for _, test := range tests {
+		client, server := net.Pipe()
+		replies := map[string]string{
+			"PROTOCOLINFO 1": "250-PROTOCOLINFO 1\r\n" + test.auth +
+				"\r\n250-VERSION Tor=\"0.4.8.9\"\r\n250 OK\r\n",
+			test.authCmd: "250 OK\r\n",
+			test.addCmd: "250-ServiceID=" + serviceID + "\r\n" +
+				"250-PrivateKey=ED25519-V3:newkey\r\n250 OK\r\n",
+		}
+		go fakeTorControl(t, server, replies, []string{
+			"PROTOCOLINFO 1", test.authCmd, test.addCmd,
+		})
+
+		c := newTorController(client)
+		if err := c.authenticate(test.password); err != nil {
+			t.Fatalf("%s: authenticate: unexpected error: %v",
+				test.name, err)
+		}
+		host, key, err := c.addOnion(test.key, 9246, "127.0.0.1:9246")
+		if err != nil {
+			t.Fatalf("%s: addOnion: unexpected error: %v", test.name,
+				err)
+		}
+		if host != serviceID+".onion" || key != "ED25519-V3:newkey" {
+			t.Fatalf("%s: unexpected onion service %s with key %s",
+				test.name, host, key)
+		}
+		client.Close()
+	}
+}
+
+// TestTorControlError ensures failed commands are reported without their
+// arguments.
+func TestTorControlError(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	go func() {
+		defer server.Close()
+		bufio.NewReader(server).ReadString('\n')
+		server.Write([]byte("515 Authentication failed\r\n"))
+	}()
+
+	c := newTorController(client)
+	_, err := c.command(`AUTHENTICATE "secret"`)
+	want := "AUTHENTICATE failed: 515 Authentication failed"
+	if err == nil || err.Error() != want {
+		t.Fatalf("unexpected error: got %v, want %s", err, want)
+	}
+}
+
+// TestOnionServiceTarget ensures listeners bound to all interfaces are reached
+// via the loopback address.
+func TestOnionServiceTarget(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"0.0.0.0:9246", "127.0.0.1:9246"},
+		{"[::]:9246", "[::1]:9246"},
+		{"10.0.0.1:9246", "10.0.0.1:9246"},
+	}
+
+	for _, test := range tests {
+		addr, err := net.ResolveTCPAddr("tcp", test.addr)
+		if err != nil {
+			t.Fatalf("unable to resolve %s: %v", test.addr, err)
+		}
+		got, err := onionServiceTarget(addr)
+		if err != nil || got != test.want {
+			t.Errorf("onionServiceTarget(%s): got (%s, %v), want %s",
+				test.addr, got, err, test.want)
+		}
+	}
+}
+
+// TestNetAddressNetwork ensures addresses are mapped to the networks used by
+// the onlynet option.
+func TestNetAddressNetwork(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		addr []byte
+		want string
+	}{
+		{net.ParseIP("1.2.3.4").To4(), "ipv4"},
+		{net.ParseIP("2001:db8::1"), "ipv6"},
+		{net.ParseIP("fd87:d87e:eb43::1"), "onion"},
+		{make([]byte, wire.TorV3Size), "onion"},
+	}
+
+	for _, test := range tests {
+		na := wire.NetAddressV2FromBytes(now, 0, test.addr, 9246)
+		if got := netAddressNetwork(na); got != test.want {
+			t.Errorf("netAddressNetwork(%x): got %s, want %s",
+				test.addr, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"time"
+)
+
+// txRelayLimiter limits the rate at which transactions announced by a single
+// peer are requested from it.  It is a token bucket which is refilled at a
+// fixed rate per second up to a maximum burst size.  Each requested
+// transaction consumes a token and announcements which arrive while the bucket
+// is empty are ignored.  This prevents a single peer from monopolizing the
+// transaction validation by flooding announcements.
+//
+// The limiter is not safe for concurrent access.  It is only used from the
+// input handler of the peer it belongs to.
+type txRelayLimiter struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTxRelayLimiter returns a new transaction relay limiter which allows the
+// given rate of transactions per second with bursts up to the provided size.
+// The bucket starts full.
+func newTxRelayLimiter(rate float64, burst int) *txRelayLimiter {
+	return &txRelayLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// Allow returns whether or not another transaction may be requested from the
+// peer at the given time and consumes a token if it may.
+func (l *txRelayLimiter) Allow(now time.Time) bool {
+	if !l.last.IsZero() {
+		elapsed := now.Sub(l.last).Seconds()
+		if elapsed > 0 {
+			l.tokens += elapsed * l.rate
+			if l.tokens > l.burst {
+				l.tokens = l.burst
+			}
+		}
+	}
+	l.last = now
+
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// newPeerTxRelayLimiter returns the transaction relay limiter to use for a new
+// peer according to the configuration.  Nil is returned when the rate limit is
+// disabled or the peer has the relay permission.
+func newPeerTxRelayLimiter(perms peerPermissions) *txRelayLimiter {
+	if cfg.MaxPeerTxRate <= 0 || perms.has(permRelay) {
+		return nil
+	}
+	return newTxRelayLimiter(cfg.MaxPeerTxRate, cfg.PeerTxBurst)
+}
@@ -0,0 +1,423 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txscript
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chainhash/v2"
+)
+
+const (
+	// batchWindow is the width of the windowed non-adjacent form the
+	// scalars of a batch are converted to.  Each point of a batch needs a
+	// table of 2^(batchWindow-2) odd multiples, so wider windows trade
+	// more precomputation for fewer additions.
+	batchWindow = 5
+
+	// batchTableSize is the number of odd multiples of each point of a
+	// batch which are precomputed.
+	batchTableSize = 1 << (batchWindow - 2)
+
+	// maxNAFLen is the maximum number of digits of the windowed
+	// non-adjacent form of a 256-bit scalar.
+	maxNAFLen = 257
+)
+
+// batchEntry houses a BIP 340 signature which was added to a BatchVerifier
+// along with the data needed to add it to the signature cache once it is known
+// to be valid.
+type batchEntry struct {
+	sigHash chainhash.Hash
+	r       btcec.FieldVal
+	s       btcec.ModNScalar
+	pubKey  *btcec.PublicKey
+
+	// fullSigBytes and pkBytes are the serialized signature and public key
+	// the entry is added to the signature cache with.  They are nil for
+	// entries which aren't cached.
+	fullSigBytes []byte
+	pkBytes      []byte
+}
+
+// BatchVerifier verifies BIP 340 Schnorr signatures in batches, which is
+// considerably faster than verifying each of them on its own since the
+// expensive point doublings are shared by all of the signatures of a batch.
+//
+// A BatchVerifier can be passed to NewBatchEngine, which defers the
+// verification of the taproot signatures of the executed script to the batch
+// instead of verifying them right away.  Since BIP 341 and BIP 342 require
+// every non-empty taproot signature to be valid, the result of the execution
+// only holds once Verify reports that all signatures of the batch are valid.
+// Verify only reports whether or not the batch as a whole is valid, so a
+// failed batch must be verified again without a BatchVerifier to find out
+// which of the signatures is invalid.
+//
+// NOTE: A BatchVerifier is NOT safe for concurrent access.  Each goroutine
+// must use its own.
+type BatchVerifier struct {
+	sigCache *SigCache
+	entries  []batchEntry
+}
+
+// NewBatchVerifier returns a new, empty BatchVerifier.  The signatures of the
+// batch are added to the passed signature cache once they are verified, unless
+// it is nil.  The signatures of engines created with the batch are looked up
+// in the signature cache of the engine before they are added to the batch.
+func NewBatchVerifier(sigCache *SigCache) *BatchVerifier {
+	return &BatchVerifier{
+		sigCache: sigCache,
+	}
+}
+
+// Len returns the number of signatures which have been added to the batch
+// since it was last verified or reset.
+func (b *BatchVerifier) Len() int {
+	return len(b.entries)
+}
+
+// Reset removes all signatures from the batch without verifying them.
+func (b *BatchVerifier) Reset() {
+	clear(b.entries)
+	b.entries = b.entries[:0]
+}
+
+// Add adds a signature of the passed message hash under the passed public key
+// to the batch.  The public key must have been parsed as an x-only public key
+// as defined by BIP 340, which means its y coordinate is even.
+func (b *BatchVerifier) Add(sigHash []byte, sig *schnorr.Signature,
+	pubKey *btcec.PublicKey) {
+
+	b.add(sigHash, sig, pubKey, nil, nil)
+}
+
+// add adds a signature to the batch along with the serialized signature and
+// public key it is added to the signature cache with once it is verified.
+func (b *BatchVerifier) add(sigHash []byte, sig *schnorr.Signature,
+	pubKey *btcec.PublicKey, fullSigBytes, pkBytes []byte) {
+
+	entry := batchEntry{
+		pubKey:       pubKey,
+		fullSigBytes: fullSigBytes,
+		pkBytes:      pkBytes,
+	}
+	copy(entry.sigHash[:], sigHash)
+
+	// The signature doesn't expose its components, but they are known to
+	// be in range since it was parsed.
+	rawSig := sig.Serialize()
+	entry.r.SetByteSlice(rawSig[:32])
+	entry.s.SetByteSlice(rawSig[32:])
+
+	b.entries = append(b.entries, entry)
+}
+
+// Verify returns whether or not all signatures of the batch are valid and
+// resets the batch.  The signatures are added to the signature cache of the
+// batch when they are valid.  An empty batch is valid.
+func (b *BatchVerifier) Verify() bool {
+	defer b.Reset()
+
+	var valid bool
+	switch len(b.entries) {
+	case 0:
+		return true
+
+	// There is nothing to gain from the batch equation for a single
+	// signature, so it is verified as usual.
+	case 1:
+		e := &b.entries[0]
+		sig := schnorr.NewSignature(&e.r, &e.s)
+		valid = sig.Verify(e.sigHash[:], e.pubKey)
+
+	default:
+		valid = verifyBatch(b.entries)
+	}
+	if !valid {
+		return false
+	}
+
+	if b.sigCache != nil {
+		for i := range b.entries {
+			e := &b.entries[i]
+			if e.fullSigBytes != nil {
+				b.sigCache.Add(e.sigHash, e.fullSigBytes, e.pkBytes)
+			}
+		}
+	}
+	return true
+}
+
+// verifyBatch returns whether or not all of the passed signatures are valid
+// using the batch verification algorithm of BIP 340.  That is, with the
+// points R_i lifted from the r values of the signatures, the challenges e_i
+// and random 128-bit coefficients a_i, except for a_0 which is one, it checks
+// that
+//
+//	(a_0*s_0 + ... + a_n*s_n)*G = a_0*R_0 + a_0*e_0*P_0 + ... + a_n*R_n + a_n*e_n*P_n
+//
+// which holds for all valid signatures and, thanks to the random coefficients,
+// for invalid ones with a negligible probability only.
+func verifyBatch(entries []batchEntry) bool {
+	// The coefficients are derived from a random seed, so they can't be
+	// predicted by whoever created the signatures.
+	var seed [32]byte
+	if _, err := rand.Read(seed[:]); err != nil {
+		return false
+	}
+
+	points := make([]btcec.JacobianPoint, 0, len(entries)*2)
+	scalars := make([]btcec.ModNScalar, 0, len(entries)*2)
+	var sum btcec.ModNScalar
+	for i := range entries {
+		e := &entries[i]
+
+		// Lift the x coordinate r to the point R with an even y
+		// coordinate.  The signature is invalid when there is no such
+		// point.
+		var R btcec.JacobianPoint
+		R.X.Set(&e.r)
+		if !btcec.DecompressY(&R.X, false, &R.Y) {
+			return false
+		}
+		R.Z.SetInt(1)
+
+		// e = int(tagged_hash("BIP0340/challenge", bytes(r) ||
+		// bytes(P) || m)) mod n.
+		var rBytes [32]byte
+		e.r.PutBytesUnchecked(rBytes[:])
+		commitment := chainhash.TaggedHash(
+			chainhash.TagBIP0340Challenge, rBytes[:],
+			schnorr.SerializePubKey(e.pubKey), e.sigHash[:],
+		)
+		var challenge btcec.ModNScalar
+		challenge.SetBytes((*[32]byte)(commitment))
+
+		var a btcec.ModNScalar
+		if i == 0 {
+			a.SetInt(1)
+		} else {
+			batchCoefficient(&seed, i, &a)
+		}
+
+		var as btcec.ModNScalar
+		as.Mul2(&a, &e.s)
+		sum.Add(&as)
+		challenge.Mul(&a)
+
+		var P btcec.JacobianPoint
+		e.pubKey.AsJacobian(&P)
+		points = append(points, R, P)
+		scalars = append(scalars, a, challenge)
+	}
+
+	var lhs, rhs btcec.JacobianPoint
+	btcec.ScalarBaseMultNonConst(&sum, &lhs)
+	multiScalarMultNonConst(scalars, points, &rhs)
+	return lhs.EquivalentNonConst(&rhs)
+}
+
+// batchCoefficient sets the passed scalar to the random 128-bit coefficient
+// for the signature with the passed index of a batch, which is derived from
+// the passed seed.
+func batchCoefficient(seed *[32]byte, index int, a *btcec.ModNScalar) {
+	var buf [36]byte
+	copy(buf[:], seed[:])
+	binary.LittleEndian.PutUint32(buf[32:], uint32(index))
+	hash := sha256.Sum256(buf[:])
+
+	// Only keep the low 128 bits, which is all that is needed for the
+	// security of the batch and halves the work on the R points.  A zero
+	// coefficient would drop the signature from the batch, so it is
+	// replaced with one, which happens with a negligible probability
+	// anyway.
+	clear(hash[:16])
+	if overflow := a.SetBytes(&hash); overflow != 0 || a.IsZero() {
+		a.SetInt(1)
+	}
+}
+
+// multiScalarMultNonConst computes the sum of the products of the passed
+// scalars and points and stores it in the passed result.  It uses the
+// interleaved windowed non-adjacent form method, which shares the point
+// doublings among all of the points, instead of multiplying each point on its
+// own.
+//
+// NOTE: The points must be normalized and must not be the point at infinity.
+// The resulting point will be normalized.
+func multiScalarMultNonConst(scalars []btcec.ModNScalar,
+	points []btcec.JacobianPoint, result *btcec.JacobianPoint) {
+
+	// Precompute the odd multiples P, 3P, 5P, ... of each point and
+	// convert its scalar to the windowed non-adjacent form.
+	tables := make([][batchTableSize]btcec.JacobianPoint, len(points))
+	nafs := make([][maxNAFLen]int8, len(points))
+	maxLen := 0
+	for i := range points {
+		table := &tables[i]
+		var double btcec.JacobianPoint
+		table[0].Set(&points[i])
+		btcec.DoubleNonConst(&points[i], &double)
+		for j := 1; j < batchTableSize; j++ {
+			btcec.AddNonConst(&table[j-1], &double, &table[j])
+		}
+
+		if n := wNAF(&scalars[i], &nafs[i]); n > maxLen {
+			maxLen = n
+		}
+	}
+	toAffineBatch(tables)
+
+	// Start out with the point at infinity and double it once per digit,
+	// adding or subtracting the multiple of each point its digit at that
+	// position calls for.
+	result.X.SetInt(0)
+	result.Y.SetInt(0)
+	result.Z.SetInt(0)
+	var neg btcec.JacobianPoint
+	for bit := maxLen - 1; bit >= 0; bit-- {
+		btcec.DoubleNonConst(result, result)
+		for i := range points {
+			digit := nafs[i][bit]
+			switch {
+			case digit > 0:
+				btcec.AddNonConst(result, &tables[i][digit/2], result)
+
+			case digit < 0:
+				neg.Set(&tables[i][-digit/2])
+				neg.Y.Negate(1).Normalize()
+				btcec.AddNonConst(result, &neg, result)
+			}
+		}
+	}
+}
+
+// toAffineBatch converts all of the points of the passed tables to affine
+// coordinates, which makes adding them faster.  It uses Montgomery's trick to
+// share a single field inversion among all of the points instead of inverting
+// the z coordinate of each point on its own.
+//
+// NOTE: The points must not be the point at infinity.
+func toAffineBatch(tables [][batchTableSize]btcec.JacobianPoint) {
+	// Compute the running products of the z coordinates.
+	products := make([]btcec.FieldVal, 0, len(tables)*batchTableSize)
+	var acc btcec.FieldVal
+	acc.SetInt(1)
+	for i := range tables {
+		for j := range tables[i] {
+			acc.Mul(&tables[i][j].Z)
+			products = append(products, acc)
+		}
+	}
+
+	// Invert the product of all z coordinates and walk back through the
+	// points, peeling off the z coordinate of one point at a time to get
+	// the inverse of the z coordinate of each point.
+	inv := acc.Inverse()
+	var zInv, zInv2 btcec.FieldVal
+	for i := len(tables) - 1; i >= 0; i-- {
+		for j := batchTableSize - 1; j >= 0; j-- {
+			p := &tables[i][j]
+			k := i*batchTableSize + j
+			if k > 0 {
+				zInv.Mul2(inv, &products[k-1])
+			} else {
+				zInv.Set(inv)
+			}
+			inv.Mul(&p.Z)
+
+			zInv2.SquareVal(&zInv)
+			p.X.Mul(&zInv2).Normalize()
+			p.Y.Mul(zInv2.Mul(&zInv)).Normalize()
+			p.Z.SetInt(1)
+		}
+	}
+}
+
+// wNAF converts the passed scalar to the windowed non-adjacent form with the
+// width batchWindow and stores its digits in the passed array, starting with
+// the least significant one.  Every digit is either zero or odd and less than
+// 2^(batchWindow-1) in absolute value, and there is at least batchWindow-1
+// zero digits between two non-zero ones.  The number of digits is returned.
+func wNAF(k *btcec.ModNScalar, naf *[maxNAFLen]int8) int {
+	const (
+		windowSize = 1 << batchWindow
+		windowMask = windowSize - 1
+		halfWindow = windowSize / 2
+	)
+
+	// Convert the scalar to little-endian 64-bit limbs with an extra limb
+	// which takes the carry of adding to the scalar below.
+	var limbs [5]uint64
+	b := k.Bytes()
+	for i := 0; i < 4; i++ {
+		limbs[i] = binary.BigEndian.Uint64(b[24-i*8:])
+	}
+
+	n := 0
+	for limbs != [5]uint64{} {
+		if limbs[0]&1 == 1 {
+			// Take the digit of the low bits of the window, which is
+			// odd, and subtract it from the scalar so the next
+			// batchWindow-1 bits are zero.  Negative digits are used
+			// for the upper half of the window so their absolute
+			// value stays below half the window size.
+			digit := int(limbs[0] & windowMask)
+			if digit >= halfWindow {
+				digit -= windowSize
+			}
+			naf[n] = int8(digit)
+
+			if digit > 0 {
+				subLimbs(&limbs, uint64(digit))
+			} else {
+				addLimbs(&limbs, uint64(-digit))
+			}
+		} else {
+			naf[n] = 0
+		}
+		n++
+
+		// Shift the scalar right by one bit.
+		for i := 0; i < len(limbs)-1; i++ {
+			limbs[i] = limbs[i]>>1 | limbs[i+1]<<63
+		}
+		limbs[len(limbs)-1] >>= 1
+	}
+
+	return n
+}
+
+// addLimbs adds the passed value to the number represented by the passed
+// little-endian limbs.
+func addLimbs(limbs *[5]uint64, v uint64) {
+	for i := range limbs {
+		sum := limbs[i] + v
+		carry := sum < limbs[i]
+		limbs[i] = sum
+		if !carry {
+			return
+		}
+		v = 1
+	}
+}
+
+// subLimbs subtracts the passed value from the number represented by the
+// passed little-endian limbs, which must not be less than the value.
+func subLimbs(limbs *[5]uint64, v uint64) {
+	for i := range limbs {
+		diff := limbs[i] - v
+		borrow := diff > limbs[i]
+		limbs[i] = diff
+		if !borrow {
+			return
+		}
+		v = 1
+	}
+}
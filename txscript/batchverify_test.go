@@ -0,0 +1,328 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txscript
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chainhash/v2"
+	"github.com/btcsuite/btcd/wire/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// testSchnorrSig houses a BIP 340 signature along with the message hash it
+// signs and the x-only public key it was made with.
+type testSchnorrSig struct {
+	sigHash [32]byte
+	sig     *schnorr.Signature
+	pubKey  *btcec.PublicKey
+}
+
+// genSchnorrSigs returns the passed number of valid BIP 340 signatures of
+// random messages under random keys.
+func genSchnorrSigs(t testing.TB, n int) []testSchnorrSig {
+	t.Helper()
+
+	sigs := make([]testSchnorrSig, n)
+	for i := range sigs {
+		privKey, err := btcec.NewPrivateKey()
+		require.NoError(t, err)
+		_, err = rand.Read(sigs[i].sigHash[:])
+		require.NoError(t, err)
+
+		sigs[i].sig, err = schnorr.Sign(privKey, sigs[i].sigHash[:])
+		require.NoError(t, err)
+
+		// Parse the x-only key the way the script engine does, which
+		// ensures its y coordinate is even.
+		sigs[i].pubKey, err = schnorr.ParsePubKey(
+			schnorr.SerializePubKey(privKey.PubKey()),
+		)
+		require.NoError(t, err)
+	}
+	return sigs
+}
+
+// TestBatchVerify ensures a batch is valid exactly when all of its signatures
+// are, that it is reset by verifying it and that it only adds the signatures
+// to the signature cache when they are valid.
+func TestBatchVerify(t *testing.T) {
+	t.Parallel()
+
+	for _, n := range []int{0, 1, 2, 3, 17, 64} {
+		t.Run(fmt.Sprintf("%d sigs", n), func(t *testing.T) {
+			sigs := genSchnorrSigs(t, n)
+			batch := NewBatchVerifier(nil)
+			for _, s := range sigs {
+				batch.Add(s.sigHash[:], s.sig, s.pubKey)
+			}
+			require.Equal(t, n, batch.Len())
+			require.True(t, batch.Verify())
+			require.Zero(t, batch.Len())
+
+			// Replace each signature in turn with a signature over
+			// another message, which must invalidate the batch.
+			for i := range sigs {
+				var other [32]byte
+				other[0] = 0x01
+				for j, s := range sigs {
+					sigHash := s.sigHash[:]
+					if i == j {
+						sigHash = other[:]
+					}
+					batch.Add(sigHash, s.sig, s.pubKey)
+				}
+				require.False(t, batch.Verify(), "sig %d", i)
+				require.Zero(t, batch.Len())
+			}
+		})
+	}
+
+	// A signature whose r value isn't the x coordinate of a point on the
+	// curve invalidates the batch.
+	sigs := genSchnorrSigs(t, 2)
+	var r, y btcec.FieldVal
+	for r.SetInt(1); btcec.DecompressY(&r, false, &y); {
+		r.AddInt(1).Normalize()
+	}
+	s := sigs[1].sig.Serialize()
+	var sVal btcec.ModNScalar
+	sVal.SetByteSlice(s[32:])
+	batch := NewBatchVerifier(nil)
+	batch.Add(sigs[0].sigHash[:], sigs[0].sig, sigs[0].pubKey)
+	batch.Add(sigs[1].sigHash[:], schnorr.NewSignature(&r, &sVal),
+		sigs[1].pubKey)
+	require.False(t, batch.Verify())
+
+	// Only the signatures of a valid batch are added to the cache.
+	sigCache := NewSigCache(10)
+	batch = NewBatchVerifier(sigCache)
+	for _, s := range sigs {
+		batch.add(
+			s.sigHash[:], s.sig, s.pubKey, s.sig.Serialize(),
+			schnorr.SerializePubKey(s.pubKey),
+		)
+	}
+	batch.add(
+		sigs[0].sigHash[:], sigs[1].sig, sigs[0].pubKey,
+		sigs[1].sig.Serialize(), schnorr.SerializePubKey(sigs[0].pubKey),
+	)
+	require.False(t, batch.Verify())
+	require.Zero(t, sigCache.validSigs.len())
+
+	for _, s := range sigs {
+		batch.add(
+			s.sigHash[:], s.sig, s.pubKey, s.sig.Serialize(),
+			schnorr.SerializePubKey(s.pubKey),
+		)
+	}
+	require.True(t, batch.Verify())
+	for _, s := range sigs {
+		require.True(t, sigCache.Exists(
+			s.sigHash, s.sig.Serialize(),
+			schnorr.SerializePubKey(s.pubKey),
+		))
+	}
+}
+
+// TestWNAF ensures the windowed non-adjacent form of scalars has the expected
+// form and represents the scalar.
+func TestWNAF(t *testing.T) {
+	t.Parallel()
+
+	// Test the edge cases along with random scalars.
+	var zero, one, maxScalar btcec.ModNScalar
+	one.SetInt(1)
+	maxScalar.SetInt(1)
+	maxScalar.Negate()
+	scalars := []btcec.ModNScalar{zero, one, maxScalar}
+	for i := 0; i < 100; i++ {
+		var b [32]byte
+		_, err := rand.Read(b[:])
+		require.NoError(t, err)
+		var k btcec.ModNScalar
+		k.SetBytes(&b)
+		scalars = append(scalars, k)
+	}
+
+	for _, k := range scalars {
+		var naf [maxNAFLen]int8
+		n := wNAF(&k, &naf)
+
+		value := new(big.Int)
+		lastNonZero := -batchWindow
+		for i := n - 1; i >= 0; i-- {
+			value.Lsh(value, 1)
+			value.Add(value, big.NewInt(int64(naf[i])))
+
+			digit := naf[i]
+			if digit == 0 {
+				continue
+			}
+			require.Equal(t, int8(1), digit&1, "even digit %d", digit)
+			require.Less(t, digit, int8(batchTableSize*2))
+			require.Greater(t, digit, -int8(batchTableSize*2))
+			if lastNonZero >= 0 {
+				require.GreaterOrEqual(t, lastNonZero-i, batchWindow)
+			}
+			lastNonZero = i
+		}
+		if n > 0 {
+			require.NotZero(t, naf[n-1], "leading zero digit")
+		}
+
+		kBytes := k.Bytes()
+		want := new(big.Int).SetBytes(kBytes[:])
+		require.Zero(t, want.Cmp(value), "scalar %x", kBytes)
+	}
+}
+
+// TestMultiScalarMult ensures the sum of the products of scalars and points
+// equals the sum of the individually multiplied points.
+func TestMultiScalarMult(t *testing.T) {
+	t.Parallel()
+
+	for _, n := range []int{1, 2, 5, 20} {
+		scalars := make([]btcec.ModNScalar, n)
+		points := make([]btcec.JacobianPoint, n)
+		var want btcec.JacobianPoint
+		for i := 0; i < n; i++ {
+			privKey, err := btcec.NewPrivateKey()
+			require.NoError(t, err)
+			privKey.PubKey().AsJacobian(&points[i])
+
+			var b [32]byte
+			_, err = rand.Read(b[:])
+			require.NoError(t, err)
+			scalars[i].SetBytes(&b)
+
+			var product btcec.JacobianPoint
+			btcec.ScalarMultNonConst(&scalars[i], &points[i], &product)
+			btcec.AddNonConst(&want, &product, &want)
+		}
+
+		var got btcec.JacobianPoint
+		multiScalarMultNonConst(scalars, points, &got)
+		require.True(t, got.EquivalentNonConst(&want), "%d points", n)
+	}
+
+	// The sum of a point and its negation is the point at infinity.
+	var one, minusOne btcec.ModNScalar
+	one.SetInt(1)
+	minusOne.SetInt(1)
+	minusOne.Negate()
+	var p btcec.JacobianPoint
+	btcec.GeneratorJacobian(&p)
+	var got btcec.JacobianPoint
+	multiScalarMultNonConst(
+		[]btcec.ModNScalar{one, minusOne},
+		[]btcec.JacobianPoint{p, p}, &got,
+	)
+	require.True(t, got.Z.IsZero())
+}
+
+// TestBatchEngine ensures an engine created with a batch adds taproot key
+// spend signatures to the batch instead of verifying them and only accepts
+// the spend once the batch has been verified.
+func TestBatchEngine(t *testing.T) {
+	t.Parallel()
+
+	privKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	pkScript, err := PayToTaprootScript(
+		ComputeTaprootKeyNoScript(privKey.PubKey()),
+	)
+	require.NoError(t, err)
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Hash: chainhash.Hash{0x01}},
+	})
+	tx.AddTxOut(&wire.TxOut{Value: 1e8, PkScript: pkScript})
+	prevFetcher := NewCannedPrevOutputFetcher(pkScript, 1e8)
+	sigHashes := NewTxSigHashes(tx, prevFetcher)
+
+	witness, err := TaprootWitnessSignature(
+		tx, sigHashes, 0, 1e8, pkScript, SigHashDefault, privKey,
+	)
+	require.NoError(t, err)
+	tx.TxIn[0].Witness = witness
+
+	execute := func(sigCache *SigCache, batch *BatchVerifier) error {
+		vm, err := NewBatchEngine(
+			pkScript, tx, 0, StandardVerifyFlags, sigCache,
+			sigHashes, 1e8, prevFetcher, batch,
+		)
+		require.NoError(t, err)
+		return vm.Execute()
+	}
+
+	// The valid signature is added to the batch, which adds it to the
+	// cache once it is verified.
+	sigCache := NewSigCache(10)
+	batch := NewBatchVerifier(sigCache)
+	require.NoError(t, execute(sigCache, batch))
+	require.Equal(t, 1, batch.Len())
+	require.True(t, batch.Verify())
+	require.Equal(t, 1, sigCache.validSigs.len())
+
+	// Cached signatures aren't added to the batch again.
+	require.NoError(t, execute(sigCache, batch))
+	require.Zero(t, batch.Len())
+
+	// An invalid signature passes the execution, but not the verification
+	// of the batch, while the engine without a batch rejects it.
+	badSig := append([]byte(nil), witness[0]...)
+	badSig[10] ^= 0x01
+	tx.TxIn[0].Witness = wire.TxWitness{badSig}
+	require.NoError(t, execute(nil, batch))
+	require.Equal(t, 1, batch.Len())
+	require.False(t, batch.Verify())
+	require.Error(t, execute(nil, nil))
+}
+
+// BenchmarkSchnorrVerify benchmarks verifying BIP 340 signatures one at a
+// time, which is the baseline for BenchmarkBatchVerify.
+func BenchmarkSchnorrVerify(b *testing.B) {
+	sigs := genSchnorrSigs(b, 64)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s := &sigs[i%len(sigs)]
+		if !s.sig.Verify(s.sigHash[:], s.pubKey) {
+			b.Fatal("invalid signature")
+		}
+	}
+}
+
+// BenchmarkBatchVerify benchmarks verifying BIP 340 signatures in batches of
+// various sizes.  The reported time is per signature, so it can be compared to
+// BenchmarkSchnorrVerify directly.
+func BenchmarkBatchVerify(b *testing.B) {
+	for _, n := range []int{2, 8, 32, 128} {
+		b.Run(fmt.Sprintf("batch=%d", n), func(b *testing.B) {
+			sigs := genSchnorrSigs(b, n)
+			batch := NewBatchVerifier(nil)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i += n {
+				for j := range sigs {
+					s := &sigs[j]
+					batch.Add(s.sigHash[:], s.sig, s.pubKey)
+				}
+				if !batch.Verify() {
+					b.Fatal("invalid batch")
+				}
+			}
+		})
+	}
+}
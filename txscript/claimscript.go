@@ -0,0 +1,301 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txscript
+
+import (
+	"fmt"
+)
+
+const (
+	// OP_CLAIMNAME, OP_SUPPORTCLAIM and OP_UPDATECLAIM mark the outputs
+	// which claim a name, support a claim and update a claim.  They reuse
+	// OP_NOP6, OP_NOP7 and OP_NOP8.
+	OP_CLAIMNAME    = OP_NOP6
+	OP_SUPPORTCLAIM = OP_NOP7
+	OP_UPDATECLAIM  = OP_NOP8
+
+	// MaxClaimNameSize is the maximum number of bytes allowed in a claim
+	// name.
+	MaxClaimNameSize = 255
+
+	// MaxClaimScriptSize is the maximum size of a script with a claim,
+	// support or update prefix, including the public key script following
+	// the prefix.
+	MaxClaimScriptSize = 8192
+
+	// ClaimIDSize is the size of the ID of a claim.
+	ClaimIDSize = 20
+)
+
+// ClaimScript houses the parts of a script which starts with a claim, support
+// or update prefix.
+//
+// The prefix is one of the following forms and is followed by the public key
+// script paying the output:
+//
+//	claim:   OP_CLAIMNAME <name> <value> OP_2DROP OP_DROP
+//	support: OP_SUPPORTCLAIM <name> <claim id> OP_2DROP OP_DROP
+//	support: OP_SUPPORTCLAIM <name> <claim id> <value> OP_2DROP OP_2DROP
+//	update:  OP_UPDATECLAIM <name> <claim id> <value> OP_2DROP OP_2DROP
+//
+// The prefix drops one more element than it pushes, so it isn't meant to be
+// executed.  LBRY nodes strip it and only execute the public key script when
+// the output is spent, which is also why the value may exceed
+// MaxScriptElementSize.  The script engine of this package does NOT strip the
+// prefix, so it can't verify spends of these outputs, and the prefix opcodes
+// are rejected by ScriptDiscourageUpgradableNops.
+type ClaimScript struct {
+	// Opcode is OP_CLAIMNAME, OP_SUPPORTCLAIM or OP_UPDATECLAIM.
+	Opcode byte
+
+	// Name is the claimed or supported name.
+	Name []byte
+
+	// ClaimID is the ID of the supported or updated claim.  It is nil for
+	// claims.
+	ClaimID []byte
+
+	// Value is the value of the claim or update, or the optional value of
+	// the support, which is nil when the support has none.
+	Value []byte
+
+	// PkScript is the public key script following the prefix.
+	PkScript []byte
+}
+
+// checkClaimName returns an error if the passed claim name is too large.
+func checkClaimName(name []byte) error {
+	if len(name) > MaxClaimNameSize {
+		str := fmt.Sprintf("claim name size %d is larger than max "+
+			"allowed size %d", len(name), MaxClaimNameSize)
+		return scriptError(ErrInvalidClaimData, str)
+	}
+	return nil
+}
+
+// checkClaimID returns an error if the passed claim ID doesn't have the size
+// of a claim ID.
+func checkClaimID(claimID []byte) error {
+	if len(claimID) != ClaimIDSize {
+		str := fmt.Sprintf("claim ID size %d is not %d", len(claimID),
+			ClaimIDSize)
+		return scriptError(ErrInvalidClaimData, str)
+	}
+	return nil
+}
+
+// checkClaimScriptSize returns an error if the passed claim script is too
+// large.
+func checkClaimScriptSize(script []byte) error {
+	if len(script) > MaxClaimScriptSize {
+		str := fmt.Sprintf("claim script size %d is larger than max "+
+			"allowed size %d", len(script), MaxClaimScriptSize)
+		return scriptError(ErrInvalidClaimData, str)
+	}
+	return nil
+}
+
+// finishClaimScript returns the script built by the passed builder after adding
+// the passed value, which may be larger than MaxScriptElementSize, and the
+// passed drop opcodes.  An error is returned if the script exceeds
+// MaxClaimScriptSize.
+func finishClaimScript(builder *ScriptBuilder, value []byte, drops ...byte) ([]byte, error) {
+	if value != nil {
+		builder.AddFullData(value)
+	}
+	for _, op := range drops {
+		builder.AddOp(op)
+	}
+	script, err := builder.Script()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkClaimScriptSize(script); err != nil {
+		return nil, err
+	}
+	return script, nil
+}
+
+// NewClaimScript returns the prefix of a script which claims the passed name
+// with the passed value.  The public key script paying the output must be
+// appended to it.  An Error with the error code ErrInvalidClaimData will be
+// returned if the name exceeds MaxClaimNameSize or the prefix exceeds
+// MaxClaimScriptSize.
+func NewClaimScript(name, value []byte) ([]byte, error) {
+	if err := checkClaimName(name); err != nil {
+		return nil, err
+	}
+	if value == nil {
+		value = []byte{}
+	}
+
+	builder := NewScriptBuilder().AddOp(OP_CLAIMNAME).AddData(name)
+	return finishClaimScript(builder, value, OP_2DROP, OP_DROP)
+}
+
+// NewSupportScript returns the prefix of a script which supports the claim with
+// the passed name and ID.  The value is optional and is left out of the script
+// when it is nil.  The public key script paying the output must be appended to
+// it.  An Error with the error code ErrInvalidClaimData will be returned if the
+// name exceeds MaxClaimNameSize, the claim ID isn't ClaimIDSize bytes or the
+// prefix exceeds MaxClaimScriptSize.
+func NewSupportScript(name, claimID, value []byte) ([]byte, error) {
+	if err := checkClaimName(name); err != nil {
+		return nil, err
+	}
+	if err := checkClaimID(claimID); err != nil {
+		return nil, err
+	}
+
+	builder := NewScriptBuilder().AddOp(OP_SUPPORTCLAIM).AddData(name).
+		AddData(claimID)
+	if value == nil {
+		return finishClaimScript(builder, nil, OP_2DROP, OP_DROP)
+	}
+	return finishClaimScript(builder, value, OP_2DROP, OP_2DROP)
+}
+
+// NewUpdateScript returns the prefix of a script which updates the claim with
+// the passed name and ID to the passed value.  The public key script paying the
+// output must be appended to it.  An Error with the error code
+// ErrInvalidClaimData will be returned if the name exceeds MaxClaimNameSize,
+// the claim ID isn't ClaimIDSize bytes or the prefix exceeds
+// MaxClaimScriptSize.
+func NewUpdateScript(name, claimID, value []byte) ([]byte, error) {
+	if err := checkClaimName(name); err != nil {
+		return nil, err
+	}
+	if err := checkClaimID(claimID); err != nil {
+		return nil, err
+	}
+	if value == nil {
+		value = []byte{}
+	}
+
+	builder := NewScriptBuilder().AddOp(OP_UPDATECLAIM).AddData(name).
+		AddData(claimID)
+	return finishClaimScript(builder, value, OP_2DROP, OP_2DROP)
+}
+
+// isClaimDataPush returns whether or not the passed opcode pushes data.  This
+// includes the small integer opcodes, which ScriptBuilder.AddData uses for
+// single bytes.
+func isClaimDataPush(op byte) bool {
+	return op <= OP_PUSHDATA4 || op == OP_1NEGATE ||
+		(op >= OP_1 && op <= OP_16)
+}
+
+// claimPushData returns the data pushed by the passed opcode, which must be a
+// data push, given the data of the opcode.
+func claimPushData(op byte, data []byte) []byte {
+	switch {
+	case op == OP_1NEGATE:
+		return []byte{0x81}
+	case op >= OP_1 && op <= OP_16:
+		return []byte{byte(AsSmallInt(op))}
+	case data == nil:
+		return []byte{}
+	}
+	return data
+}
+
+// IsClaimScript returns whether or not the passed script starts with a claim,
+// support or update prefix.
+func IsClaimScript(script []byte) bool {
+	_, err := DecodeClaimScript(script)
+	return err == nil
+}
+
+// DecodeClaimScript decodes the claim, support or update prefix the passed
+// script starts with and returns its parts along with the public key script
+// following it.  An Error with the error code ErrNotClaimScript will be
+// returned if the script doesn't start with one of the prefixes and one with
+// the error code ErrInvalidClaimData if the name or claim ID in the prefix has
+// an invalid size or the script exceeds MaxClaimScriptSize.
+//
+// NOTE: This function is only valid for version 0 scripts.
+func DecodeClaimScript(script []byte) (*ClaimScript, error) {
+	const scriptVersion = 0
+
+	notClaimScript := func(str string) error {
+		return scriptError(ErrNotClaimScript, str)
+	}
+
+	tokenizer := MakeScriptTokenizer(scriptVersion, script)
+	if !tokenizer.Next() {
+		return nil, notClaimScript("script is empty or malformed")
+	}
+	op := tokenizer.Opcode()
+	if op != OP_CLAIMNAME && op != OP_SUPPORTCLAIM && op != OP_UPDATECLAIM {
+		str := fmt.Sprintf("script starts with %s instead of a claim "+
+			"opcode", opcodeArray[op].name)
+		return nil, notClaimScript(str)
+	}
+
+	// Collect the data pushes up to the first opcode which isn't one.  A
+	// prefix has two or three of them.
+	var pushes [][]byte
+	for tokenizer.Next() && isClaimDataPush(tokenizer.Opcode()) {
+		if len(pushes) == 3 {
+			return nil, notClaimScript("too many data pushes in " +
+				"claim prefix")
+		}
+		pushes = append(pushes, claimPushData(tokenizer.Opcode(),
+			tokenizer.Data()))
+	}
+	if tokenizer.Err() != nil {
+		return nil, notClaimScript(tokenizer.Err().Error())
+	}
+	if tokenizer.Done() {
+		return nil, notClaimScript("claim prefix is not terminated")
+	}
+
+	// Two pushes are followed by OP_2DROP OP_DROP and three by OP_2DROP
+	// OP_2DROP.
+	var wantDrop byte
+	switch {
+	case len(pushes) == 2 && op != OP_UPDATECLAIM:
+		wantDrop = OP_DROP
+	case len(pushes) == 3 && op != OP_CLAIMNAME:
+		wantDrop = OP_2DROP
+	default:
+		str := fmt.Sprintf("%s followed by %d data pushes",
+			opcodeArray[op].name, len(pushes))
+		return nil, notClaimScript(str)
+	}
+	if tokenizer.Opcode() != OP_2DROP || !tokenizer.Next() ||
+		tokenizer.Opcode() != wantDrop {
+
+		return nil, notClaimScript("claim prefix doesn't drop its data")
+	}
+
+	claim := ClaimScript{
+		Opcode:   op,
+		Name:     pushes[0],
+		PkScript: script[tokenizer.ByteIndex():],
+	}
+	if op == OP_CLAIMNAME {
+		claim.Value = pushes[1]
+	} else {
+		claim.ClaimID = pushes[1]
+		if len(pushes) == 3 {
+			claim.Value = pushes[2]
+		}
+	}
+
+	if err := checkClaimName(claim.Name); err != nil {
+		return nil, err
+	}
+	if claim.ClaimID != nil {
+		if err := checkClaimID(claim.ClaimID); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkClaimScriptSize(script); err != nil {
+		return nil, err
+	}
+
+	return &claim, nil
+}
@@ -0,0 +1,315 @@
+// Copyright (c) 2026 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txscript
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// TestClaimScriptBuilders ensures the claim, support and update builders create
+// the expected prefixes, reject invalid data and that the prefixes decode back
+// to the passed data.
+func TestClaimScriptBuilders(t *testing.T) {
+	t.Parallel()
+
+	name := []byte("one")
+	value := []byte("hello")
+	claimID := hexToBytes("0102030405060708090a0b0c0d0e0f1011121314")
+	tooLongName := bytes.Repeat([]byte{0x61}, MaxClaimNameSize+1)
+	pkScript := mustParseShortForm("DUP HASH160 0x14 " +
+		"0x433ec2ac1ffa1b7b7d027f564529c57197f9ae88 EQUALVERIFY " +
+		"CHECKSIG")
+
+	// The claim prefix with the name "one" is 10 bytes plus the value.  The
+	// max value leaves room for the payment script.
+	maxValue := bytes.Repeat([]byte{0x62}, MaxClaimScriptSize-10-25)
+	tooLongValue := bytes.Repeat([]byte{0x62}, MaxClaimScriptSize-9)
+
+	tests := []struct {
+		name     string
+		op       byte
+		claim    []byte
+		claimID  []byte
+		value    []byte
+		expected []byte
+		err      error
+	}{{
+		name:  "claim",
+		op:    OP_CLAIMNAME,
+		claim: name,
+		value: value,
+		expected: mustParseShortForm("NOP6 0x03 0x6f6e65 " +
+			"0x05 0x68656c6c6f 2DROP DROP"),
+	}, {
+		name:     "claim with empty name and value",
+		op:       OP_CLAIMNAME,
+		claim:    []byte{},
+		value:    []byte{},
+		expected: mustParseShortForm("NOP6 0 0 2DROP DROP"),
+	}, {
+		name:     "claim with small int name and value",
+		op:       OP_CLAIMNAME,
+		claim:    []byte{0x05},
+		value:    []byte{0x81},
+		expected: mustParseShortForm("NOP6 5 -1 2DROP DROP"),
+	}, {
+		name:  "claim with max size name and large value",
+		op:    OP_CLAIMNAME,
+		claim: bytes.Repeat([]byte{0x61}, MaxClaimNameSize),
+		value: bytes.Repeat([]byte{0x62}, 600),
+		expected: append(append(append(append(
+			[]byte{OP_CLAIMNAME, OP_PUSHDATA1, 0xff},
+			bytes.Repeat([]byte{0x61}, MaxClaimNameSize)...),
+			OP_PUSHDATA2, 0x58, 0x02),
+			bytes.Repeat([]byte{0x62}, 600)...),
+			OP_2DROP, OP_DROP),
+	}, {
+		name:  "claim with max size script",
+		op:    OP_CLAIMNAME,
+		claim: name,
+		value: maxValue,
+		expected: append(append([]byte{OP_CLAIMNAME, OP_DATA_3, 0x6f,
+			0x6e, 0x65, OP_PUSHDATA2, 0xdd, 0x1f}, maxValue...),
+			OP_2DROP, OP_DROP),
+	}, {
+		name:  "claim with too long name",
+		op:    OP_CLAIMNAME,
+		claim: tooLongName,
+		value: value,
+		err:   scriptError(ErrInvalidClaimData, ""),
+	}, {
+		name:  "claim with too long value",
+		op:    OP_CLAIMNAME,
+		claim: name,
+		value: tooLongValue,
+		err:   scriptError(ErrInvalidClaimData, ""),
+	}, {
+		name:    "support",
+		op:      OP_SUPPORTCLAIM,
+		claim:   name,
+		claimID: claimID,
+		expected: mustParseShortForm("NOP7 0x03 0x6f6e65 0x14 " +
+			"0x0102030405060708090a0b0c0d0e0f1011121314 " +
+			"2DROP DROP"),
+	}, {
+		name:    "support with value",
+		op:      OP_SUPPORTCLAIM,
+		claim:   name,
+		claimID: claimID,
+		value:   value,
+		expected: mustParseShortForm("NOP7 0x03 0x6f6e65 0x14 " +
+			"0x0102030405060708090a0b0c0d0e0f1011121314 " +
+			"0x05 0x68656c6c6f 2DROP 2DROP"),
+	}, {
+		name:    "support with empty value",
+		op:      OP_SUPPORTCLAIM,
+		claim:   name,
+		claimID: claimID,
+		value:   []byte{},
+		expected: mustParseShortForm("NOP7 0x03 0x6f6e65 0x14 " +
+			"0x0102030405060708090a0b0c0d0e0f1011121314 " +
+			"0 2DROP 2DROP"),
+	}, {
+		name:    "support with too long name",
+		op:      OP_SUPPORTCLAIM,
+		claim:   tooLongName,
+		claimID: claimID,
+		err:     scriptError(ErrInvalidClaimData, ""),
+	}, {
+		name:    "support with short claim id",
+		op:      OP_SUPPORTCLAIM,
+		claim:   name,
+		claimID: claimID[1:],
+		err:     scriptError(ErrInvalidClaimData, ""),
+	}, {
+		name:    "support with too long value",
+		op:      OP_SUPPORTCLAIM,
+		claim:   name,
+		claimID: claimID,
+		value:   tooLongValue,
+		err:     scriptError(ErrInvalidClaimData, ""),
+	}, {
+		name:    "update",
+		op:      OP_UPDATECLAIM,
+		claim:   name,
+		claimID: claimID,
+		value:   value,
+		expected: mustParseShortForm("NOP8 0x03 0x6f6e65 0x14 " +
+			"0x0102030405060708090a0b0c0d0e0f1011121314 " +
+			"0x05 0x68656c6c6f 2DROP 2DROP"),
+	}, {
+		name:    "update with too long name",
+		op:      OP_UPDATECLAIM,
+		claim:   tooLongName,
+		claimID: claimID,
+		value:   value,
+		err:     scriptError(ErrInvalidClaimData, ""),
+	}, {
+		name:    "update with long claim id",
+		op:      OP_UPDATECLAIM,
+		claim:   name,
+		claimID: append(claimID, 0x15),
+		value:   value,
+		err:     scriptError(ErrInvalidClaimData, ""),
+	}, {
+		name:    "update with too long value",
+		op:      OP_UPDATECLAIM,
+		claim:   name,
+		claimID: claimID,
+		value:   tooLongValue,
+		err:     scriptError(ErrInvalidClaimData, ""),
+	}}
+
+	for _, test := range tests {
+		var script []byte
+		var err error
+		switch test.op {
+		case OP_CLAIMNAME:
+			script, err = NewClaimScript(test.claim, test.value)
+		case OP_SUPPORTCLAIM:
+			script, err = NewSupportScript(test.claim, test.claimID,
+				test.value)
+		case OP_UPDATECLAIM:
+			script, err = NewUpdateScript(test.claim, test.claimID,
+				test.value)
+		}
+		if e := tstCheckScriptError(err, test.err); e != nil {
+			t.Errorf("%s: %v", test.name, e)
+			continue
+		}
+		if test.err != nil {
+			continue
+		}
+		if !bytes.Equal(script, test.expected) {
+			t.Errorf("%s: wrong script\ngot: %x\nwant: %x", test.name,
+				script, test.expected)
+			continue
+		}
+
+		// The prefix followed by the payment script decodes back to
+		// the passed data.
+		script = append(script, pkScript...)
+		decoded, err := DecodeClaimScript(script)
+		if err != nil {
+			t.Errorf("%s: unexpected decode error: %v", test.name, err)
+			continue
+		}
+		want := &ClaimScript{
+			Opcode:   test.op,
+			Name:     test.claim,
+			ClaimID:  test.claimID,
+			Value:    test.value,
+			PkScript: pkScript,
+		}
+		if !reflect.DeepEqual(decoded, want) {
+			t.Errorf("%s: wrong decoded script\ngot: %+v\nwant: %+v",
+				test.name, decoded, want)
+			continue
+		}
+		if !IsClaimScript(script) {
+			t.Errorf("%s: not detected as claim script", test.name)
+		}
+	}
+}
+
+// TestDecodeClaimScriptErrors ensures scripts which don't start with a well
+// formed claim, support or update prefix are rejected.
+func TestDecodeClaimScriptErrors(t *testing.T) {
+	t.Parallel()
+
+	const claimID = "0x14 0x0102030405060708090a0b0c0d0e0f1011121314"
+	tests := []struct {
+		name   string
+		script []byte
+		err    error
+	}{{
+		name:   "empty script",
+		script: nil,
+		err:    scriptError(ErrNotClaimScript, ""),
+	}, {
+		name: "pay-to-pubkey-hash",
+		script: mustParseShortForm("DUP HASH160 0x14 " +
+			"0x433ec2ac1ffa1b7b7d027f564529c57197f9ae88 " +
+			"EQUALVERIFY CHECKSIG"),
+		err: scriptError(ErrNotClaimScript, ""),
+	}, {
+		name:   "other nop",
+		script: mustParseShortForm("NOP9 0x01 0x61 0x01 0x62 2DROP DROP"),
+		err:    scriptError(ErrNotClaimScript, ""),
+	}, {
+		name:   "claim with one push",
+		script: mustParseShortForm("NOP6 0x01 0x61 DROP"),
+		err:    scriptError(ErrNotClaimScript, ""),
+	}, {
+		name: "claim with three pushes",
+		script: mustParseShortForm("NOP6 0x01 0x61 " + claimID +
+			" 0x01 0x62 2DROP 2DROP"),
+		err: scriptError(ErrNotClaimScript, ""),
+	}, {
+		name: "too many pushes",
+		script: mustParseShortForm("NOP7 0x01 0x61 " + claimID +
+			" 0x01 0x62 0x01 0x63 2DROP 2DROP"),
+		err: scriptError(ErrNotClaimScript, ""),
+	}, {
+		name:   "update with two pushes",
+		script: mustParseShortForm("NOP8 0x01 0x61 " + claimID + " 2DROP DROP"),
+		err:    scriptError(ErrNotClaimScript, ""),
+	}, {
+		name:   "claim dropping too much",
+		script: mustParseShortForm("NOP6 0x01 0x61 0x01 0x62 2DROP 2DROP"),
+		err:    scriptError(ErrNotClaimScript, ""),
+	}, {
+		name: "support dropping too little",
+		script: mustParseShortForm("NOP7 0x01 0x61 " + claimID +
+			" 0x01 0x62 2DROP DROP"),
+		err: scriptError(ErrNotClaimScript, ""),
+	}, {
+		name:   "claim not dropping its data",
+		script: mustParseShortForm("NOP6 0x01 0x61 0x01 0x62 DROP DROP"),
+		err:    scriptError(ErrNotClaimScript, ""),
+	}, {
+		name:   "unterminated claim",
+		script: mustParseShortForm("NOP6 0x01 0x61 0x01 0x62"),
+		err:    scriptError(ErrNotClaimScript, ""),
+	}, {
+		name:   "claim terminated after first drop",
+		script: mustParseShortForm("NOP6 0x01 0x61 0x01 0x62 2DROP"),
+		err:    scriptError(ErrNotClaimScript, ""),
+	}, {
+		name:   "malformed push",
+		script: mustParseShortForm("NOP6 0x01 0x61 0x05 0x01"),
+		err:    scriptError(ErrNotClaimScript, ""),
+	}, {
+		name: "support with short claim id",
+		script: mustParseShortForm("NOP7 0x01 0x61 0x13 " +
+			"0x0102030405060708090a0b0c0d0e0f10111213 2DROP DROP"),
+		err: scriptError(ErrInvalidClaimData, ""),
+	}, {
+		name: "claim with too long name",
+		script: append(append([]byte{OP_CLAIMNAME, OP_PUSHDATA2, 0x00,
+			0x01}, bytes.Repeat([]byte{0x61}, 256)...),
+			OP_0, OP_2DROP, OP_DROP),
+		err: scriptError(ErrInvalidClaimData, ""),
+	}, {
+		name: "claim with too long script",
+		script: append(append([]byte{OP_CLAIMNAME, OP_0, OP_PUSHDATA2,
+			0xfb, 0x1f}, bytes.Repeat([]byte{0x61}, 8187)...),
+			OP_2DROP, OP_DROP, OP_TRUE),
+		err: scriptError(ErrInvalidClaimData, ""),
+	}}
+
+	for _, test := range tests {
+		_, err := DecodeClaimScript(test.script)
+		if e := tstCheckScriptError(err, test.err); e != nil {
+			t.Errorf("%s: %v", test.name, e)
+			continue
+		}
+		if IsClaimScript(test.script) {
+			t.Errorf("%s: detected as claim script", test.name)
+		}
+	}
+}
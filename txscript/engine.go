@@ -227,7 +227,8 @@ type Engine struct {
 	// prior to being mined, part of full block verification, etc).
 	//
 	// hashCache caches the midstate of segwit v0 and v1 sighashes to
-	// optimize worst-case hashing complexity.
+	// optimize worst-case hashing complexity.  It is computed on first use
+	// when the engine is created without it.
 	//
 	// prevOutFetcher is used to look up all the previous output of
 	// taproot transactions, as that information is hashed into the
@@ -296,6 +297,10 @@ type Engine struct {
 	// NOTE: This is only meant to be used in debugging, and SHOULD NOT BE
 	// USED during regular operation.
 	stepCallback func(*StepInfo) error
+
+	// batch is an optional batch the taproot signatures are added to
+	// instead of verifying them right away.  See NewBatchEngine.
+	batch *BatchVerifier
 }
 
 // StepInfo houses the current VM state information that is passed back to the
@@ -535,6 +540,16 @@ func (vm *Engine) checkValidPC() error {
 	return nil
 }
 
+// sigHashes returns the sighash midstate of the transaction being validated.
+// When the engine was created without one, it is computed the first time it is
+// needed and then reused by every later signature check of the engine.
+func (vm *Engine) sigHashes() *TxSigHashes {
+	if vm.hashCache == nil {
+		vm.hashCache = NewTxSigHashes(&vm.tx, vm.prevOutFetcher)
+	}
+	return vm.hashCache
+}
+
 // isWitnessVersionActive returns true if a witness program was extracted
 // during the initialization of the Engine, and the program's version matches
 // the specified version.
@@ -698,9 +713,10 @@ func (vm *Engine) verifyWitnessProgram(witness wire.TxWitness) error {
 			// removing the annex), we'll do normal taproot
 			// keyspend validation.
 			rawSig := witness[0]
-			err := VerifyTaprootKeySpend(
+			err := verifyTaprootKeySpend(
 				vm.witnessProgram, rawSig, &vm.tx, vm.txIdx,
-				vm.prevOutFetcher, vm.hashCache, vm.sigCache,
+				vm.prevOutFetcher, vm.sigHashes(), vm.sigCache,
+				vm.batch,
 			)
 			if err != nil {
 				// TODO(roasbeef): proper error
@@ -1726,3 +1742,26 @@ func NewDebugEngine(scriptPubKey []byte, tx *wire.MsgTx, txIdx int,
 	vm.stepCallback = stepCallback
 	return vm, nil
 }
+
+// NewBatchEngine returns a new script engine which adds the taproot signatures
+// it checks to the passed batch instead of verifying them right away, unless
+// they are found in the signature cache.  Since every non-empty taproot
+// signature must be valid, a successful execution of the script only means the
+// input is valid once the batch has been verified.  The signatures of other
+// script types are verified as usual.
+func NewBatchEngine(scriptPubKey []byte, tx *wire.MsgTx, txIdx int,
+	flags ScriptFlags, sigCache *SigCache, hashCache *TxSigHashes,
+	inputAmount int64, prevOutFetcher PrevOutputFetcher,
+	batch *BatchVerifier) (*Engine, error) {
+
+	vm, err := NewEngine(
+		scriptPubKey, tx, txIdx, flags, sigCache, hashCache,
+		inputAmount, prevOutFetcher,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	vm.batch = batch
+	return vm, nil
+}
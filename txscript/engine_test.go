@@ -7,8 +7,11 @@ package txscript
 
 import (
 	"crypto/sha256"
+	"reflect"
 	"testing"
 
+	"github.com/btcsuite/btcd/address/v2"
+	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/chainhash/v2"
 	"github.com/btcsuite/btcd/wire/v2"
 )
@@ -562,3 +565,84 @@ func TestCodeSepUnexecutedBranch(t *testing.T) {
 		})
 	}
 }
+
+// TestEngineSigHashes ensures an engine created without the sighash midstate
+// of the transaction computes it once and uses it for the witness v0 and
+// taproot signatures it checks.
+func TestEngineSigHashes(t *testing.T) {
+	t.Parallel()
+
+	v0Key, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+	v0PkScript, err := NewScriptBuilder().AddOp(OP_0).
+		AddData(address.Hash160(v0Key.PubKey().SerializeCompressed())).
+		Script()
+	if err != nil {
+		t.Fatalf("unable to build script: %v", err)
+	}
+	v1Key, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+	v1PkScript, err := PayToTaprootScript(
+		ComputeTaprootKeyNoScript(v1Key.PubKey()),
+	)
+	if err != nil {
+		t.Fatalf("unable to build script: %v", err)
+	}
+
+	// Create a transaction which spends a witness v0 and a taproot output.
+	tx := wire.NewMsgTx(2)
+	prevOuts := NewMultiPrevOutFetcher(nil)
+	for i, pkScript := range [][]byte{v0PkScript, v1PkScript} {
+		outPoint := wire.OutPoint{
+			Hash:  chainhash.Hash{0x01},
+			Index: uint32(i),
+		}
+		tx.AddTxIn(&wire.TxIn{PreviousOutPoint: outPoint})
+		prevOuts.AddPrevOut(outPoint, wire.NewTxOut(1e8, pkScript))
+	}
+	tx.AddTxOut(wire.NewTxOut(1e8, []byte{OP_TRUE}))
+
+	sigHashes := NewTxSigHashes(tx, prevOuts)
+	tx.TxIn[0].Witness, err = WitnessSignature(
+		tx, sigHashes, 0, 1e8, v0PkScript, SigHashAll, v0Key, true,
+	)
+	if err != nil {
+		t.Fatalf("unable to sign witness v0 input: %v", err)
+	}
+	tx.TxIn[1].Witness, err = TaprootWitnessSignature(
+		tx, sigHashes, 1, 1e8, v1PkScript, SigHashDefault, v1Key,
+	)
+	if err != nil {
+		t.Fatalf("unable to sign taproot input: %v", err)
+	}
+
+	for i, pkScript := range [][]byte{v0PkScript, v1PkScript} {
+		vm, err := NewEngine(
+			pkScript, tx, i, StandardVerifyFlags, nil, nil, 1e8,
+			prevOuts,
+		)
+		if err != nil {
+			t.Fatalf("input %d: unable to create engine: %v", i, err)
+		}
+		if err := vm.Execute(); err != nil {
+			t.Fatalf("input %d: unexpected error: %v", i, err)
+		}
+
+		// The engine keeps the midstate it computed, which matches the
+		// one computed up front.
+		if vm.hashCache == nil {
+			t.Fatalf("input %d: midstate wasn't kept", i)
+		}
+		if vm.sigHashes() != vm.hashCache {
+			t.Fatalf("input %d: midstate was computed again", i)
+		}
+		if !reflect.DeepEqual(vm.hashCache, sigHashes) {
+			t.Fatalf("input %d: got midstate %v, want %v", i,
+				vm.hashCache, sigHashes)
+		}
+	}
+}
@@ -52,6 +52,20 @@ const (
 	// version is passed to a function which deals with script analysis.
 	ErrUnsupportedScriptVersion
 
+	// ErrInvalidClaimData is returned when a claim name or claim ID passed
+	// to or found in a claim script has an invalid size, or the claim
+	// script exceeds MaxClaimScriptSize.
+	ErrInvalidClaimData
+
+	// ErrNotClaimScript is returned from DecodeClaimScript when the
+	// provided script doesn't start with a claim, support or update prefix.
+	ErrNotClaimScript
+
+	// ErrUnsupportedClaimScript is returned when signing an output with a
+	// claim, support or update prefix, which the engine doesn't strip
+	// and therefore can't verify.
+	ErrUnsupportedClaimScript
+
 	// ------------------------------------------
 	// Failures related to final execution state.
 	// ------------------------------------------
@@ -431,6 +445,9 @@ var errorCodeStrings = map[ErrorCode]string{
 	ErrTooManyRequiredSigs:                 "ErrTooManyRequiredSigs",
 	ErrTooMuchNullData:                     "ErrTooMuchNullData",
 	ErrUnsupportedScriptVersion:            "ErrUnsupportedScriptVersion",
+	ErrInvalidClaimData:                    "ErrInvalidClaimData",
+	ErrNotClaimScript:                      "ErrNotClaimScript",
+	ErrUnsupportedClaimScript:              "ErrUnsupportedClaimScript",
 	ErrEarlyReturn:                         "ErrEarlyReturn",
 	ErrEmptyStack:                          "ErrEmptyStack",
 	ErrEvalFalse:                           "ErrEvalFalse",
@@ -24,6 +24,9 @@ func TestErrorCodeStringer(t *testing.T) {
 		{ErrTooManyRequiredSigs, "ErrTooManyRequiredSigs"},
 		{ErrTooMuchNullData, "ErrTooMuchNullData"},
 		{ErrUnsupportedScriptVersion, "ErrUnsupportedScriptVersion"},
+		{ErrInvalidClaimData, "ErrInvalidClaimData"},
+		{ErrNotClaimScript, "ErrNotClaimScript"},
+		{ErrUnsupportedClaimScript, "ErrUnsupportedClaimScript"},
 		{ErrNotMultisigScript, "ErrNotMultisigScript"},
 		{ErrEarlyReturn, "ErrEarlyReturn"},
 		{ErrEmptyStack, "ErrEmptyStack"},
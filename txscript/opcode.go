@@ -14,7 +14,6 @@ import (
 	"hash"
 	"strings"
 
-	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
 	"github.com/btcsuite/btcd/chainhash/v2"
 	"github.com/btcsuite/btcd/wire/v2"
@@ -2396,7 +2395,7 @@ func opcodeCheckMultiSig(op *opcode, data []byte, vm *Engine) error {
 		}
 
 		// Parse the pubkey.
-		parsedPubKey, err := btcec.ParsePubKey(pubKey)
+		parsedPubKey, err := vm.sigCache.parsePubKey(pubKey)
 		if err != nil {
 			continue
 		}
@@ -2404,16 +2403,7 @@ func opcodeCheckMultiSig(op *opcode, data []byte, vm *Engine) error {
 		// Generate the signature hash based on the signature hash type.
 		var hash []byte
 		if vm.isWitnessVersionActive(0) {
-			var sigHashes *TxSigHashes
-			if vm.hashCache != nil {
-				sigHashes = vm.hashCache
-			} else {
-				sigHashes = NewTxSigHashes(
-					&vm.tx, vm.prevOutFetcher,
-				)
-			}
-
-			hash, err = calcWitnessSignatureHashRaw(script, sigHashes, hashType,
+			hash, err = calcWitnessSignatureHashRaw(script, vm.sigHashes(), hashType,
 				&vm.tx, vm.txIdx, vm.inputAmount)
 			if err != nil {
 				return err
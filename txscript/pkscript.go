@@ -83,6 +83,14 @@ type PkScript struct {
 // script type.
 func ParsePkScript(pkScript []byte) (PkScript, error) {
 	var outputScript PkScript
+
+	// The class of a script with a claim, support or update prefix is the
+	// one of the script following the prefix, which doesn't describe the
+	// whole script.
+	if IsClaimScript(pkScript) {
+		return outputScript, ErrUnsupportedScriptType
+	}
+
 	scriptClass, _, _, err := ExtractPkScriptAddrs(
 		pkScript, &chaincfg.MainNetParams,
 	)
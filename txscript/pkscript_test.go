@@ -160,6 +160,22 @@ func TestParsePkScript(t *testing.T) {
 		},
 	}
 
+	// A claim paying a P2PKH script isn't supported even though its
+	// addresses are those of the P2PKH script.
+	claimPrefix, err := NewClaimScript([]byte("name"), []byte("val"))
+	if err != nil {
+		t.Fatalf("unable to build claim script: %v", err)
+	}
+	tests = append(tests, struct {
+		name     string
+		pkScript []byte
+		valid    bool
+	}{
+		name:     "claim paying P2PKH",
+		pkScript: append(claimPrefix, tests[1].pkScript...),
+		valid:    false,
+	})
+
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			pkScript, err := ParsePkScript(test.pkScript)
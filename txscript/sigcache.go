@@ -6,11 +6,153 @@ package txscript
 
 import (
 	"bytes"
+	"fmt"
 	"sync"
 
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
 	"github.com/btcsuite/btcd/chainhash/v2"
 )
 
+// EvictionPolicy defines which entry is evicted from a full SigCache to make
+// room for a new one.
+type EvictionPolicy uint8
+
+const (
+	// EvictRandom evicts a randomly chosen entry.  This makes it
+	// impractical for an adversary to target the eviction of specific
+	// entries.
+	EvictRandom EvictionPolicy = iota
+
+	// EvictOldest evicts the entry which was added first.  This keeps the
+	// signatures of recently seen transactions, which are the ones most
+	// likely to be found in the next block, at the cost of making the
+	// evicted entries predictable.
+	EvictOldest
+)
+
+// evictionPolicyStrings is a map of eviction policies back to their constant
+// names for pretty printing.
+var evictionPolicyStrings = map[EvictionPolicy]string{
+	EvictRandom: "random",
+	EvictOldest: "oldest",
+}
+
+// String returns the EvictionPolicy in human-readable form.
+func (p EvictionPolicy) String() string {
+	if s, ok := evictionPolicyStrings[p]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown EvictionPolicy (%d)", uint8(p))
+}
+
+// boundedCache is a map which holds up to a maximum number of entries.  Adding
+// an entry to a full cache evicts an existing entry according to the eviction
+// policy of the cache.
+//
+// NOTE: All methods are safe for concurrent access.
+type boundedCache[K comparable, V any] struct {
+	sync.RWMutex
+	entries    map[K]V
+	maxEntries uint
+	policy     EvictionPolicy
+
+	// order houses the keys of the entries in the order they were added
+	// when the oldest entries are evicted first.  Once the cache is full
+	// it is used as a ring buffer where next is the index of the oldest
+	// key.
+	order []K
+	next  int
+}
+
+// newBoundedCache returns a new bounded cache which holds up to the passed
+// number of entries and evicts them according to the passed policy.
+func newBoundedCache[K comparable, V any](maxEntries uint,
+	policy EvictionPolicy) *boundedCache[K, V] {
+
+	c := &boundedCache[K, V]{
+		entries:    make(map[K]V, maxEntries),
+		maxEntries: maxEntries,
+		policy:     policy,
+	}
+	if policy == EvictOldest {
+		c.order = make([]K, 0, maxEntries)
+	}
+	return c
+}
+
+// get returns the value of the entry with the passed key and whether or not it
+// exists.
+func (c *boundedCache[K, V]) get(key K) (V, bool) {
+	c.RLock()
+	value, ok := c.entries[key]
+	c.RUnlock()
+	return value, ok
+}
+
+// add adds an entry to the cache or replaces the value of an existing entry
+// with the same key.  An existing entry is evicted according to the eviction
+// policy when the cache is full.
+func (c *boundedCache[K, V]) add(key K, value V) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.maxEntries == 0 {
+		return
+	}
+
+	// Replacing the value of an existing entry doesn't change the number
+	// of entries, nor its position in the eviction order.
+	if _, ok := c.entries[key]; ok {
+		c.entries[key] = value
+		return
+	}
+
+	// If adding this new entry will put us over the max number of allowed
+	// entries, then evict an entry.
+	if uint(len(c.entries)+1) > c.maxEntries {
+		switch c.policy {
+		case EvictOldest:
+			// The cache is full, so the ring buffer is as well and
+			// its next slot holds the key of the oldest entry.  The
+			// key of the new entry takes its place.
+			delete(c.entries, c.order[c.next])
+			c.order[c.next] = key
+			c.next = (c.next + 1) % len(c.order)
+			c.entries[key] = value
+			return
+
+		default:
+			// Remove a random entry from the map. Relying on the
+			// random starting point of Go's map iteration. It's
+			// worth noting that the random iteration starting point
+			// is not 100% guaranteed by the spec, however most Go
+			// compilers support it.  Ultimately, the iteration order
+			// isn't important here because in order to manipulate
+			// which items are evicted, an adversary would need to be
+			// able to execute preimage attacks on the hashing
+			// function in order to start eviction at a specific
+			// entry.
+			for k := range c.entries {
+				delete(c.entries, k)
+				break
+			}
+		}
+	}
+	if c.policy == EvictOldest {
+		c.order = append(c.order, key)
+	}
+	c.entries[key] = value
+}
+
+// len returns the number of entries in the cache.
+func (c *boundedCache[K, V]) len() int {
+	c.RLock()
+	n := len(c.entries)
+	c.RUnlock()
+	return n
+}
+
 // sigCacheEntry represents an entry in the SigCache. Entries within the
 // SigCache are keyed according to the sigHash of the signature. In the
 // scenario of a cache-hit (according to the sigHash), an additional comparison
@@ -23,8 +165,8 @@ type sigCacheEntry struct {
 }
 
 // SigCache implements an Schnorr+ECDSA signature verification cache with a
-// randomized entry eviction policy. Only valid signatures will be added to the
-// cache. The benefits of SigCache are two fold. Firstly, usage of SigCache
+// configurable entry eviction policy. Only valid signatures will be added to
+// the cache. The benefits of SigCache are two fold. Firstly, usage of SigCache
 // mitigates a DoS attack wherein an attack causes a victim's client to hang
 // due to worst-case behavior triggered while processing attacker crafted
 // invalid transactions. A detailed description of the mitigated DoS attack can
@@ -34,11 +176,14 @@ type sigCacheEntry struct {
 // optimization which speeds up the validation of transactions within a block,
 // if they've already been seen and verified within the mempool.
 //
-// TODO(roasbeef): use type params here after Go 1.18
+// The SigCache also keeps the parsed form of the public keys of the signatures
+// it verifies, which saves decompressing the same keys again when they are
+// used by more than one signature or their signatures are checked again, such
+// as when a transaction is validated in the mempool and later in a block.
 type SigCache struct {
-	sync.RWMutex
-	validSigs  map[chainhash.Hash]sigCacheEntry
-	maxEntries uint
+	validSigs   *boundedCache[chainhash.Hash, sigCacheEntry]
+	ecdsaKeys   *boundedCache[string, *btcec.PublicKey]
+	schnorrKeys *boundedCache[string, *btcec.PublicKey]
 }
 
 // NewSigCache creates and initializes a new instance of SigCache. Its sole
@@ -47,9 +192,24 @@ type SigCache struct {
 // to make room for new entries that would cause the number of entries in the
 // cache to exceed the max.
 func NewSigCache(maxEntries uint) *SigCache {
+	return NewSigCacheWithPolicy(maxEntries, EvictRandom)
+}
+
+// NewSigCacheWithPolicy creates and initializes a new instance of SigCache
+// which holds up to 'maxEntries' signatures and evicts them according to the
+// passed policy.  Up to the same number of parsed public keys of each type are
+// kept along with them.
+func NewSigCacheWithPolicy(maxEntries uint, policy EvictionPolicy) *SigCache {
 	return &SigCache{
-		validSigs:  make(map[chainhash.Hash]sigCacheEntry, maxEntries),
-		maxEntries: maxEntries,
+		validSigs: newBoundedCache[chainhash.Hash, sigCacheEntry](
+			maxEntries, policy,
+		),
+		ecdsaKeys: newBoundedCache[string, *btcec.PublicKey](
+			maxEntries, policy,
+		),
+		schnorrKeys: newBoundedCache[string, *btcec.PublicKey](
+			maxEntries, policy,
+		),
 	}
 }
 
@@ -59,44 +219,61 @@ func NewSigCache(maxEntries uint) *SigCache {
 // NOTE: This function is safe for concurrent access. Readers won't be blocked
 // unless there exists a writer, adding an entry to the SigCache.
 func (s *SigCache) Exists(sigHash chainhash.Hash, sig []byte, pubKey []byte) bool {
-	s.RLock()
-	entry, ok := s.validSigs[sigHash]
-	s.RUnlock()
-
+	entry, ok := s.validSigs.get(sigHash)
 	return ok && bytes.Equal(entry.pubKey, pubKey) && bytes.Equal(entry.sig, sig)
 }
 
 // Add adds an entry for a signature over 'sigHash' under public key 'pubKey'
 // to the signature cache. In the event that the SigCache is 'full', an
-// existing entry is randomly chosen to be evicted in order to make space for
-// the new entry.
+// existing entry is chosen according to the eviction policy to be evicted in
+// order to make space for the new entry.
 //
 // NOTE: This function is safe for concurrent access. Writers will block
 // simultaneous readers until function execution has concluded.
 func (s *SigCache) Add(sigHash chainhash.Hash, sig []byte, pubKey []byte) {
-	s.Lock()
-	defer s.Unlock()
+	s.validSigs.add(sigHash, sigCacheEntry{sig, pubKey})
+}
 
-	if s.maxEntries <= 0 {
-		return
+// parsePubKey parses the passed serialized public key the same way as
+// btcec.ParsePubKey, returning the cached result of an earlier call with the
+// same key if there is one.  The key is parsed without caching it when the
+// cache is nil.
+//
+// NOTE: This function is safe for concurrent access.
+func (s *SigCache) parsePubKey(pkBytes []byte) (*btcec.PublicKey, error) {
+	if s == nil {
+		return btcec.ParsePubKey(pkBytes)
 	}
 
-	// If adding this new entry will put us over the max number of allowed
-	// entries, then evict an entry.
-	if uint(len(s.validSigs)+1) > s.maxEntries {
-		// Remove a random entry from the map. Relying on the random
-		// starting point of Go's map iteration. It's worth noting that
-		// the random iteration starting point is not 100% guaranteed
-		// by the spec, however most Go compilers support it.
-		// Ultimately, the iteration order isn't important here because
-		// in order to manipulate which items are evicted, an adversary
-		// would need to be able to execute preimage attacks on the
-		// hashing function in order to start eviction at a specific
-		// entry.
-		for sigEntry := range s.validSigs {
-			delete(s.validSigs, sigEntry)
-			break
-		}
+	if pubKey, ok := s.ecdsaKeys.get(string(pkBytes)); ok {
+		return pubKey, nil
+	}
+	pubKey, err := btcec.ParsePubKey(pkBytes)
+	if err != nil {
+		return nil, err
+	}
+	s.ecdsaKeys.add(string(pkBytes), pubKey)
+	return pubKey, nil
+}
+
+// parseSchnorrPubKey parses the passed x-only public key the same way as
+// schnorr.ParsePubKey, returning the cached result of an earlier call with the
+// same key if there is one.  The key is parsed without caching it when the
+// cache is nil.
+//
+// NOTE: This function is safe for concurrent access.
+func (s *SigCache) parseSchnorrPubKey(pkBytes []byte) (*btcec.PublicKey, error) {
+	if s == nil {
+		return schnorr.ParsePubKey(pkBytes)
+	}
+
+	if pubKey, ok := s.schnorrKeys.get(string(pkBytes)); ok {
+		return pubKey, nil
+	}
+	pubKey, err := schnorr.ParsePubKey(pkBytes)
+	if err != nil {
+		return nil, err
 	}
-	s.validSigs[sigHash] = sigCacheEntry{sig, pubKey}
+	s.schnorrKeys.add(string(pkBytes), pubKey)
+	return pubKey, nil
 }
@@ -86,9 +86,9 @@ func TestSigCacheAddEvictEntry(t *testing.T) {
 	}
 
 	// The sigcache should now have sigCacheSize entries within it.
-	if uint(len(sigCache.validSigs)) != sigCacheSize {
+	if uint(sigCache.validSigs.len()) != sigCacheSize {
 		t.Fatalf("sigcache should now have %v entries, instead it has %v",
-			sigCacheSize, len(sigCache.validSigs))
+			sigCacheSize, sigCache.validSigs.len())
 	}
 
 	// Add a new entry, this should cause eviction of a randomly chosen
@@ -100,9 +100,9 @@ func TestSigCacheAddEvictEntry(t *testing.T) {
 	sigCache.Add(*msgNew, sigNew.Serialize(), keyNew.SerializeCompressed())
 
 	// The sigcache should still have sigCache entries.
-	if uint(len(sigCache.validSigs)) != sigCacheSize {
+	if uint(sigCache.validSigs.len()) != sigCacheSize {
 		t.Fatalf("sigcache should now have %v entries, instead it has %v",
-			sigCacheSize, len(sigCache.validSigs))
+			sigCacheSize, sigCache.validSigs.len())
 	}
 
 	// The entry added above should be found within the sigcache.
@@ -137,8 +137,131 @@ func TestSigCacheAddMaxEntriesZeroOrNegative(t *testing.T) {
 	}
 
 	// There shouldn't be any entries in the sigCache.
-	if len(sigCache.validSigs) != 0 {
+	if sigCache.validSigs.len() != 0 {
 		t.Errorf("%v items found in sigcache, no items should have"+
-			"been added", len(sigCache.validSigs))
+			"been added", sigCache.validSigs.len())
 	}
 }
+
+// TestSigCacheEvictOldest tests that a sigcache which evicts the oldest
+// entries keeps the entries which were added last.
+func TestSigCacheEvictOldest(t *testing.T) {
+	// Create a sigcache that can hold up to 10 entries and fill it up with
+	// twice as many random sig triplets.
+	const sigCacheSize = 10
+	sigCache := NewSigCacheWithPolicy(sigCacheSize, EvictOldest)
+
+	type triplet struct {
+		msg    chainhash.Hash
+		sig    []byte
+		pubKey []byte
+	}
+	triplets := make([]triplet, sigCacheSize*2)
+	for i := range triplets {
+		msg, sig, key, err := genRandomSig()
+		if err != nil {
+			t.Fatalf("unable to generate random signature test data")
+		}
+		triplets[i] = triplet{
+			*msg, sig.Serialize(), key.SerializeCompressed(),
+		}
+		sigCache.Add(*msg, triplets[i].sig, triplets[i].pubKey)
+
+		// Adding an entry which exists already must neither evict an
+		// entry nor change the eviction order.
+		sigCache.Add(*msg, triplets[i].sig, triplets[i].pubKey)
+	}
+
+	if sigCache.validSigs.len() != sigCacheSize {
+		t.Fatalf("sigcache should now have %v entries, instead it has %v",
+			sigCacheSize, sigCache.validSigs.len())
+	}
+
+	// Only the entries which were added last should be found.
+	for i, tr := range triplets {
+		want := i >= len(triplets)-sigCacheSize
+		if got := sigCache.Exists(tr.msg, tr.sig, tr.pubKey); got != want {
+			t.Errorf("entry %d: exists %v, want %v", i, got, want)
+		}
+	}
+}
+
+// TestSigCacheParsePubKey tests that the sigcache hands out the cached parsed
+// public keys and doesn't cache keys which fail to parse.
+func TestSigCacheParsePubKey(t *testing.T) {
+	sigCache := NewSigCache(10)
+
+	_, _, key, err := genRandomSig()
+	if err != nil {
+		t.Fatalf("unable to generate random signature test data")
+	}
+	pkBytes := key.SerializeCompressed()
+
+	// Parsing the same key twice should return the same parsed key.
+	pubKey, err := sigCache.parsePubKey(pkBytes)
+	if err != nil {
+		t.Fatalf("unable to parse key: %v", err)
+	}
+	if !pubKey.IsEqual(key) {
+		t.Fatalf("parsed key %x, want %x", pubKey.SerializeCompressed(),
+			pkBytes)
+	}
+	cached, err := sigCache.parsePubKey(pkBytes)
+	if err != nil {
+		t.Fatalf("unable to parse key: %v", err)
+	}
+	if cached != pubKey {
+		t.Fatalf("parsed key was not cached")
+	}
+
+	// The x-only form of the key is cached separately, since it is parsed
+	// differently.
+	xOnly := pkBytes[1:]
+	if _, err := sigCache.parsePubKey(xOnly); err == nil {
+		t.Fatalf("x-only key parsed as a serialized key")
+	}
+	schnorrKey, err := sigCache.parseSchnorrPubKey(xOnly)
+	if err != nil {
+		t.Fatalf("unable to parse x-only key: %v", err)
+	}
+	if schnorrKey == pubKey {
+		t.Fatalf("x-only key shares the parsed serialized key")
+	}
+
+	// Only the keys which parsed should be cached.
+	if n := sigCache.ecdsaKeys.len(); n != 1 {
+		t.Fatalf("%d keys cached, want 1", n)
+	}
+	if n := sigCache.schnorrKeys.len(); n != 1 {
+		t.Fatalf("%d x-only keys cached, want 1", n)
+	}
+
+	// A nil sigcache should still parse keys.
+	var nilCache *SigCache
+	if _, err := nilCache.parsePubKey(pkBytes); err != nil {
+		t.Fatalf("unable to parse key without a cache: %v", err)
+	}
+}
+
+// BenchmarkParsePubKey benchmarks parsing a compressed public key, which
+// involves decompressing its y coordinate, with and without a sigcache.
+func BenchmarkParsePubKey(b *testing.B) {
+	_, _, key, err := genRandomSig()
+	if err != nil {
+		b.Fatalf("unable to generate random signature test data")
+	}
+	pkBytes := key.SerializeCompressed()
+
+	b.Run("uncached", func(b *testing.B) {
+		var nilCache *SigCache
+		for i := 0; i < b.N; i++ {
+			_, _ = nilCache.parsePubKey(pkBytes)
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		sigCache := NewSigCache(10)
+		for i := 0; i < b.N; i++ {
+			_, _ = sigCache.parsePubKey(pkBytes)
+		}
+	})
+}
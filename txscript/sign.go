@@ -256,6 +256,16 @@ func sign(chainParams *chaincfg.Params, tx *wire.MsgTx, idx int,
 	subScript []byte, hashType SigHashType, kdb KeyDB, sdb ScriptDB) ([]byte,
 	ScriptClass, []address.Address, int, error) {
 
+	// ExtractPkScriptAddrs attributes claim, support and update outputs to
+	// the public key script following their prefix, but the engine doesn't
+	// strip the prefix, so a signature script for the public key script
+	// wouldn't be able to spend them.
+	if IsClaimScript(subScript) {
+		str := "unable to sign a claim, support or update script"
+		return nil, NonStandardTy, nil, 0,
+			scriptError(ErrUnsupportedClaimScript, str)
+	}
+
 	class, addresses, nrequired, err := ExtractPkScriptAddrs(subScript,
 		chainParams)
 	if err != nil {
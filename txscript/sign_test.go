@@ -1607,6 +1607,72 @@ var sigScriptTests = []tstSigScript{
 	},
 }
 
+// TestSignTxOutputClaimScript ensures outputs with a claim, support or update
+// prefix are not signed since the engine doesn't strip the prefix and the
+// resulting signature script wouldn't spend them.
+func TestSignTxOutputClaimScript(t *testing.T) {
+	t.Parallel()
+
+	key, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	addr, err := address.NewAddressPubKeyHash(
+		address.Hash160(key.PubKey().SerializeCompressed()),
+		&chaincfg.TestNet3Params,
+	)
+	require.NoError(t, err)
+	payScript, err := PayToAddrScript(addr)
+	require.NoError(t, err)
+
+	claimID := make([]byte, ClaimIDSize)
+	claimPrefix, err := NewClaimScript([]byte("name"), []byte("value"))
+	require.NoError(t, err)
+	supportPrefix, err := NewSupportScript([]byte("name"), claimID, nil)
+	require.NoError(t, err)
+	updatePrefix, err := NewUpdateScript(
+		[]byte("name"), claimID, []byte("value"),
+	)
+	require.NoError(t, err)
+
+	tx := &wire.MsgTx{
+		Version: 1,
+		TxIn: []*wire.TxIn{{
+			PreviousOutPoint: wire.OutPoint{Index: 0},
+			Sequence:         wire.MaxTxInSequenceNum,
+		}},
+		TxOut: []*wire.TxOut{{Value: 1}},
+	}
+	kdb := mkGetKey(map[string]addressToKey{
+		addr.EncodeAddress(): {key, true},
+	})
+
+	// Ensure the public key script itself is signed so the claim scripts
+	// are only rejected because of their prefix.
+	sigScript, err := SignTxOutput(&chaincfg.TestNet3Params, tx, 0,
+		payScript, SigHashAll, kdb, mkGetScript(nil), nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, sigScript)
+
+	for _, prefix := range [][]byte{claimPrefix, supportPrefix, updatePrefix} {
+		pkScript := append(append([]byte{}, prefix...), payScript...)
+		sigScript, err := SignTxOutput(&chaincfg.TestNet3Params, tx, 0,
+			pkScript, SigHashAll, kdb, mkGetScript(nil), nil)
+		require.Nil(t, sigScript)
+		require.True(t, IsErrorCode(err, ErrUnsupportedClaimScript),
+			"unexpected error: %v", err)
+
+		// Ensure the engine indeed can't verify a spend of the claim
+		// script with a signature script for the public key script.
+		tx.TxIn[0].SignatureScript, err = SignatureScript(
+			tx, 0, pkScript, SigHashAll, key, true,
+		)
+		require.NoError(t, err)
+		vm, err := NewEngine(pkScript, tx, 0, StandardVerifyFlags,
+			nil, nil, 1, nil)
+		require.NoError(t, err)
+		require.Error(t, vm.Execute())
+	}
+}
+
 // Test the sigscript generation for valid and invalid inputs, all
 // hashTypes, and with and without compression.  This test creates
 // sigscripts to spend fake coinbase inputs, as sigscripts cannot be
@@ -84,7 +84,7 @@ func parseBaseSigAndPubkey(pkBytes, fullSigBytes []byte,
 
 	// First, parse the public key, which we expect to be in the proper
 	// encoding.
-	pubKey, err := btcec.ParsePubKey(pkBytes)
+	pubKey, err := vm.sigCache.parsePubKey(pkBytes)
 	if err != nil {
 		return nil, nil, 0, err
 	}
@@ -201,15 +201,8 @@ func newBaseSegwitSigVerifier(pkBytes, fullSigBytes []byte,
 //
 // NOTE: This is part of the baseSigVerifier interface.
 func (s *baseSegwitSigVerifier) Verify() verifyResult {
-	var sigHashes *TxSigHashes
-	if s.vm.hashCache != nil {
-		sigHashes = s.vm.hashCache
-	} else {
-		sigHashes = NewTxSigHashes(&s.vm.tx, s.vm.prevOutFetcher)
-	}
-
 	sigHash, err := calcWitnessSignatureHashRaw(
-		s.subScript, sigHashes, s.hashType, &s.vm.tx, s.vm.txIdx,
+		s.subScript, s.vm.sigHashes(), s.hashType, &s.vm.tx, s.vm.txIdx,
 		s.vm.inputAmount,
 	)
 	if err != nil {
@@ -242,6 +235,10 @@ type taprootSigVerifier struct {
 	sigCache  *SigCache
 	hashCache *TxSigHashes
 
+	// batch is the batch the signature is added to instead of verifying
+	// it right away, if any.
+	batch *BatchVerifier
+
 	tx *wire.MsgTx
 
 	inputIndex int
@@ -254,12 +251,12 @@ type taprootSigVerifier struct {
 // parseTaprootSigAndPubKey attempts to parse the public key and signature for
 // a taproot spend that may be a keyspend or script path spend. This function
 // returns an error if the pubkey is invalid, or the sig is.
-func parseTaprootSigAndPubKey(pkBytes, rawSig []byte,
+func parseTaprootSigAndPubKey(pkBytes, rawSig []byte, sigCache *SigCache,
 ) (*btcec.PublicKey, *schnorr.Signature, SigHashType, error) {
 
 	// Now that we have the raw key, we'll parse it into a schnorr public
 	// key we can work with.
-	pubKey, err := schnorr.ParsePubKey(pkBytes)
+	pubKey, err := sigCache.parseSchnorrPubKey(pkBytes)
 	if err != nil {
 		return nil, nil, 0, err
 	}
@@ -316,7 +313,7 @@ func newTaprootSigVerifier(pkBytes []byte, fullSigBytes []byte,
 	annex []byte) (*taprootSigVerifier, error) {
 
 	pubKey, sig, sigHashType, err := parseTaprootSigAndPubKey(
-		pkBytes, fullSigBytes,
+		pkBytes, fullSigBytes, sigCache,
 	)
 	if err != nil {
 		return nil, err
@@ -349,6 +346,14 @@ func (t *taprootSigVerifier) verifySig(sigHash []byte) bool {
 		}
 	}
 
+	// When the signature is verified as part of a batch, it is added to
+	// the batch, which adds it to the cache once it is verified, and
+	// considered valid until then.
+	if t.batch != nil {
+		t.batch.add(sigHash, t.sig, t.pubKey, t.fullSigBytes, t.pkBytes)
+		return true
+	}
+
 	// If we didn't find the entry in the cache, then we'll perform full
 	// verification as normal, adding the entry to the cache if it's found
 	// to be valid.
@@ -421,11 +426,12 @@ func newBaseTapscriptSigVerifier(pkBytes, rawSig []byte,
 	case 32:
 		baseTaprootVerifier, err := newTaprootSigVerifier(
 			pkBytes, rawSig, &vm.tx, vm.txIdx, vm.prevOutFetcher,
-			vm.sigCache, vm.hashCache, vm.taprootCtx.annex,
+			vm.sigCache, vm.sigHashes(), vm.taprootCtx.annex,
 		)
 		if err != nil {
 			return nil, err
 		}
+		baseTaprootVerifier.batch = vm.batch
 
 		return &baseTapscriptSigVerifier{
 			taprootSigVerifier: baseTaprootVerifier,
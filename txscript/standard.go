@@ -1069,10 +1069,18 @@ func scriptHashToAddrs(hash []byte, params *chaincfg.Params) []address.Address {
 // signatures associated with the passed PkScript.  Note that it only works for
 // 'standard' transaction script types.  Any data such as public keys which are
 // invalid are omitted from the results.
+//
+// The prefix of a claim, support or update script is skipped, so the results
+// are those of the public key script following it.  This attributes the output
+// to the addresses it pays rather than treating it as non-standard.
 func ExtractPkScriptAddrs(pkScript []byte,
 	chainParams *chaincfg.Params) (ScriptClass, []address.Address, int,
 	error) {
 
+	if claim, err := DecodeClaimScript(pkScript); err == nil {
+		pkScript = claim.PkScript
+	}
+
 	// Check for pay-to-pubkey-hash script.
 	if hash := extractPubKeyHash(pkScript); hash != nil {
 		return PubKeyHashTy, pubKeyHashToAddrs(hash, chainParams), 1, nil
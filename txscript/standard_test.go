@@ -208,6 +208,29 @@ func TestExtractPkScriptAddrs(t *testing.T) {
 			reqSigs: 1,
 			class:   ScriptHashTy,
 		},
+		{
+			name: "claim paying p2pkh",
+			script: hexToBytes("b5046e616d650376616c6d7576a914ad06dd6" +
+				"ddee55cbca9a9e3713bd7587509a3056488ac"),
+			addrs: []address.Address{
+				newAddressPubKeyHash(hexToBytes("ad06dd6ddee5" +
+					"5cbca9a9e3713bd7587509a30564")),
+			},
+			reqSigs: 1,
+			class:   PubKeyHashTy,
+		},
+		{
+			name: "support paying p2sh",
+			script: hexToBytes("b6046e616d65140101010101010101010101" +
+				"0101010101010101016d75a91463bcc565f9e68ee0189dd5" +
+				"cc67f1b0e5f02f45cb87"),
+			addrs: []address.Address{
+				newAddressScriptHash(hexToBytes("63bcc565f9e6" +
+					"8ee0189dd5cc67f1b0e5f02f45cb")),
+			},
+			reqSigs: 1,
+			class:   ScriptHashTy,
+		},
 		// from real tx 60a20bd93aa49ab4b28d514ec10b06e1829ce6818ec06cd3aabd013ebcdc4bb1, vout 0
 		{
 			name: "standard 1 of 2 multisig",
@@ -364,6 +387,22 @@ func TestExtractPkScriptAddrs(t *testing.T) {
 			reqSigs: 0,
 			class:   NonStandardTy,
 		},
+		{
+			name:    "claim without a script following the prefix",
+			script:  hexToBytes("b5046e616d650376616c6d75"),
+			addrs:   nil,
+			reqSigs: 0,
+			class:   NonStandardTy,
+		},
+		{
+			name: "claim prefix following another claim prefix",
+			script: hexToBytes("b5046e616d650376616c6d75b5046e616d650" +
+				"376616c6d7576a914ad06dd6ddee55cbca9a9e3713bd75875" +
+				"09a3056488ac"),
+			addrs:   nil,
+			reqSigs: 0,
+			class:   NonStandardTy,
+		},
 	}
 
 	t.Logf("Running %d tests.", len(tests))
@@ -61,6 +61,20 @@ func VerifyTaprootKeySpend(witnessProgram []byte, rawSig []byte, tx *wire.MsgTx,
 	inputIndex int, prevOuts PrevOutputFetcher, hashCache *TxSigHashes,
 	sigCache *SigCache) error {
 
+	return verifyTaprootKeySpend(
+		witnessProgram, rawSig, tx, inputIndex, prevOuts, hashCache,
+		sigCache, nil,
+	)
+}
+
+// verifyTaprootKeySpend verifies a top-level taproot key spend the same way as
+// VerifyTaprootKeySpend, except that the signature is added to the passed
+// batch instead of verifying it right away when the batch isn't nil.
+func verifyTaprootKeySpend(witnessProgram []byte, rawSig []byte,
+	tx *wire.MsgTx, inputIndex int, prevOuts PrevOutputFetcher,
+	hashCache *TxSigHashes, sigCache *SigCache,
+	batch *BatchVerifier) error {
+
 	// First, we'll need to extract the public key from the witness
 	// program.
 	rawKey := witnessProgram
@@ -83,6 +97,7 @@ func VerifyTaprootKeySpend(witnessProgram []byte, rawSig []byte, tx *wire.MsgTx,
 	if err != nil {
 		return err
 	}
+	keySpendVerifier.batch = batch
 
 	result := keySpendVerifier.Verify()
 	if result.sigValid {
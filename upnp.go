@@ -49,6 +49,8 @@ import (
 // NAT-PMP. It provides methods to query and manipulate this traversal to allow
 // access to services.
 type NAT interface {
+	// Protocol returns the name of the protocol used for NAT traversal.
+	Protocol() string
 	// Get the external address from outside the NAT.
 	GetExternalAddress() (addr net.IP, err error)
 	// Add a port mapping for protocol ("udp" or "tcp") from external port to
@@ -332,6 +334,11 @@ type getExternalIPAddressResponse struct {
 	ExternalIPAddress string   `xml:"NewExternalIPAddress"`
 }
 
+// Protocol implements the NAT interface by returning UPnP.
+func (n *upnpNAT) Protocol() string {
+	return "UPnP"
+}
+
 // GetExternalAddress implements the NAT interface by fetching the external IP
 // from the UPnP router.
 func (n *upnpNAT) GetExternalAddress() (addr net.IP, err error) {